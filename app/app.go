@@ -0,0 +1,120 @@
+package app
+
+import (
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxevent"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/aisgo/ais-go-pkg/conf"
+	"github.com/aisgo/ais-go-pkg/logger"
+)
+
+/* ========================================================================
+ * App - 服务引导
+ * ========================================================================
+ * 职责: 加载配置源并组装 Fx 应用，业务只需选择所需的 Bundle
+ * 默认:
+ *   - 配置文件: ./configs/config.yaml（不存在时全部使用默认配置），环境变量前缀 APP
+ *   - 提供 conf.Source 与 logger.Bundle（*logger.Logger）
+ *   - Fx 自身事件日志以 debug 级别写入 *logger.Logger
+ *
+ * 使用示例:
+ *   app.New(
+ *       app.With(
+ *           postgres.Bundle,
+ *           cache.Bundle,
+ *           mq.Bundle,
+ *           http.Bundle,
+ *           fx.Invoke(registerRoutes),
+ *       ),
+ *   ).Run()
+ * ======================================================================== */
+
+// 默认配置文件位置与环境变量前缀
+const (
+	DefaultConfigPath = "./configs"
+	DefaultConfigName = "config"
+	DefaultConfigType = "yaml"
+	DefaultEnvPrefix  = "APP"
+)
+
+// Option 引导选项
+type Option func(*options)
+
+type options struct {
+	configPath string
+	configName string
+	configType string
+	envPrefix  string
+	source     conf.Source
+	fxOptions  []fx.Option
+}
+
+// WithConfig 指定配置文件目录、文件名（不含扩展名）与类型
+func WithConfig(path, name, typ string) Option {
+	return func(o *options) {
+		o.configPath = path
+		o.configName = name
+		o.configType = typ
+	}
+}
+
+// WithEnvPrefix 指定环境变量覆盖前缀
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+	}
+}
+
+// WithSource 直接使用给定配置源（不再读取配置文件，常用于测试）
+func WithSource(src conf.Source) Option {
+	return func(o *options) {
+		o.source = src
+	}
+}
+
+// With 追加 Fx 选项（Bundle / Provide / Invoke 等）
+func With(opts ...fx.Option) Option {
+	return func(o *options) {
+		o.fxOptions = append(o.fxOptions, opts...)
+	}
+}
+
+// New 创建 Fx 应用
+// 配置加载失败时返回的应用在 Err() / Start 中报告错误
+func New(opts ...Option) *fx.App {
+	return fx.New(Options(opts...))
+}
+
+// Options 返回 New 组装的 Fx 选项，便于嵌入自定义的 fx.New 或 fxtest
+func Options(opts ...Option) fx.Option {
+	o := options{
+		configPath: DefaultConfigPath,
+		configName: DefaultConfigName,
+		configType: DefaultConfigType,
+		envPrefix:  DefaultEnvPrefix,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	src := o.source
+	if src == nil {
+		loaded, err := conf.LoadSource(o.configPath, o.configName, o.configType, o.envPrefix)
+		if err != nil {
+			return fx.Error(err)
+		}
+		src = loaded
+	}
+
+	return fx.Options(
+		fx.Supply(fx.Annotate(src, fx.As(new(conf.Source)))),
+		logger.Bundle,
+		fx.WithLogger(func(log *logger.Logger) fxevent.Logger {
+			l := &fxevent.ZapLogger{Logger: log.Logger}
+			l.UseLogLevel(zapcore.DebugLevel)
+			return l
+		}),
+		fx.Options(o.fxOptions...),
+	)
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/conf"
+	"github.com/aisgo/ais-go-pkg/database/mysql"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/mq"
+	_ "github.com/aisgo/ais-go-pkg/mq/mqtest"
+	httpserver "github.com/aisgo/ais-go-pkg/transport/http"
+)
+
+func TestNewDecodesSectionsWithDefaults(t *testing.T) {
+	src := conf.NewMapSource(map[string]any{
+		"logger": map[string]any{"level": "debug"},
+		"mysql":  map[string]any{"host": "db.internal", "user": "svc"},
+		"mq":     map[string]any{"type": "memory"},
+	})
+
+	var (
+		logCfg   logger.Config
+		mysqlCfg mysql.Config
+		httpCfg  httpserver.Config
+		mqCfg    *mq.Config
+		producer mq.Producer
+	)
+	a := New(
+		WithSource(src),
+		With(
+			fx.Provide(conf.Section("mysql", mysql.DefaultConfig)),
+			httpserver.Bundle,
+			mq.Bundle,
+			fx.Populate(&logCfg, &mysqlCfg, &httpCfg, &mqCfg, &producer),
+		),
+	)
+	if err := a.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if logCfg.Level != "debug" || logCfg.Format != "json" {
+		t.Fatalf("unexpected logger config: %+v", logCfg)
+	}
+	if mysqlCfg.Host != "db.internal" || mysqlCfg.User != "svc" || mysqlCfg.Port != 3306 || mysqlCfg.Charset != mysql.DefaultCharset {
+		t.Fatalf("unexpected mysql config: %+v", mysqlCfg)
+	}
+	if httpCfg.Port != 8080 {
+		t.Fatalf("expected default http port, got %d", httpCfg.Port)
+	}
+	if mqCfg.Type != mq.TypeMemory || mqCfg.Kafka == nil {
+		t.Fatalf("unexpected mq config: %+v", mqCfg)
+	}
+	if producer == nil {
+		t.Fatalf("expected producer")
+	}
+}
+
+func TestNewLoadsConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	content := "logger:\n  level: warn\n  format: ${APPTEST_LOG_FORMAT:-console}\n"
+	if err := os.WriteFile(filepath.Join(dir, "service.yaml"), []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("APPTEST_LOGGER_LEVEL", "error")
+
+	var logCfg logger.Config
+	a := New(
+		WithConfig(dir, "service", "yaml"),
+		WithEnvPrefix("APPTEST"),
+		With(fx.Populate(&logCfg)),
+	)
+	if err := a.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if logCfg.Level != "error" || logCfg.Format != "console" || logCfg.Output != "stdout" {
+		t.Fatalf("unexpected logger config: %+v", logCfg)
+	}
+}
+
+func TestNewReportsConfigError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("logger: [\n"), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	a := New(WithConfig(dir, "config", "yaml"))
+	if a.Err() == nil {
+		t.Fatalf("expected config error")
+	}
+}
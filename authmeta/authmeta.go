@@ -0,0 +1,290 @@
+package authmeta
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"google.golang.org/grpc/metadata"
+)
+
+/* ========================================================================
+ * Auth Metadata - 身份/租户信息的跨进程透传约定
+ * ========================================================================
+ * 职责: 定义 repository.TenantContext 在 HTTP 头与 gRPC metadata 中的统一键名与编码，
+ *       网关→服务、服务→服务调用无论经 HTTP 还是 gRPC，序列化结果逐字节一致
+ * 键名（gRPC metadata 使用小写形式，HTTP 头大小写不敏感）:
+ *   X-Tenant-ID        租户 ID（ULID 规范字符串，必填）
+ *   X-User-ID          用户 ID（ULID）
+ *   X-Dept-ID          部门 ID（ULID）
+ *   X-Tenant-Admin     租户管理员，仅为 "true" 时写入
+ *   X-Data-Scope       数据范围（repository.DataScope，默认本部门时不写入）
+ *   X-Dept-IDs         自定义数据范围部门列表（ULID，逗号分隔，无空格）
+ *   X-Roles            角色列表（逗号分隔，含逗号或不可见字符的角色被丢弃）
+ *   X-Policy-Version   权限策略版本（十进制，0 时不写入）
+ * 解码:
+ *   - 未携带 X-Tenant-ID 时视为无身份（ok=false），其余键被忽略
+ *   - 任一键出现多个值或值非法时返回 Unauthenticated，避免歧义身份
+ * 安全: 这些键只应在可信链路上使用（网关认证后写入，内网服务间透传）；
+ *       对公网入口应由网关剥离客户端自带的同名头
+ *
+ * 使用示例:
+ *   // 客户端: 将 ctx 中的租户上下文写入 outgoing metadata
+ *   md := metadata.MD{}
+ *   authmeta.Inject(ctx, md)
+ *   ctx = metadata.NewOutgoingContext(ctx, md)
+ *
+ *   // 服务端: 从 incoming metadata 恢复
+ *   md, _ := metadata.FromIncomingContext(ctx)
+ *   tc, ok, err := authmeta.Extract(md)
+ *   if ok {
+ *       ctx = repository.WithTenantContext(ctx, tc)
+ *   }
+ *
+ *   // HTTP
+ *   authmeta.InjectHeader(ctx, req.Header)
+ *   tc, ok, err := authmeta.ExtractHeader(r.Header)
+ * ======================================================================== */
+
+// HTTP 头名称（gRPC metadata 键为其小写形式）
+const (
+	HeaderTenantID      = "X-Tenant-ID"
+	HeaderUserID        = "X-User-ID"
+	HeaderDeptID        = "X-Dept-ID"
+	HeaderAdmin         = "X-Tenant-Admin"
+	HeaderDataScope     = "X-Data-Scope"
+	HeaderDeptIDs       = "X-Dept-IDs"
+	HeaderRoles         = "X-Roles"
+	HeaderPolicyVersion = "X-Policy-Version"
+)
+
+// gRPC metadata 键（小写）
+const (
+	MetadataTenantID      = "x-tenant-id"
+	MetadataUserID        = "x-user-id"
+	MetadataDeptID        = "x-dept-id"
+	MetadataAdmin         = "x-tenant-admin"
+	MetadataDataScope     = "x-data-scope"
+	MetadataDeptIDs       = "x-dept-ids"
+	MetadataRoles         = "x-roles"
+	MetadataPolicyVersion = "x-policy-version"
+)
+
+// Headers 全部身份头（网关透传白名单、剥离客户端自带头时使用）
+var Headers = []string{
+	HeaderTenantID, HeaderUserID, HeaderDeptID, HeaderAdmin,
+	HeaderDataScope, HeaderDeptIDs, HeaderRoles, HeaderPolicyVersion,
+}
+
+const (
+	listSeparator = ","
+	adminValue    = "true"
+)
+
+// dataScopes 可接受的数据范围
+var dataScopes = []repository.DataScope{
+	repository.DataScopeDept, repository.DataScopeDeptAndChildren,
+	repository.DataScopeCustom, repository.DataScopeSelf, repository.DataScopeAll,
+}
+
+// Encode 将租户上下文编码为键值对，key 为 HTTP 头名称，零值字段不写入
+func Encode(tc repository.TenantContext, set func(key, value string)) {
+	set(HeaderTenantID, tc.TenantID.String())
+	if !tc.UserID.IsZero() {
+		set(HeaderUserID, tc.UserID.String())
+	}
+	if tc.DeptID != nil {
+		set(HeaderDeptID, tc.DeptID.String())
+	}
+	if tc.IsAdmin {
+		set(HeaderAdmin, adminValue)
+	}
+	if tc.DataScope != repository.DataScopeDept {
+		set(HeaderDataScope, string(tc.DataScope))
+	}
+	if len(tc.DeptIDs) > 0 {
+		ids := make([]string, len(tc.DeptIDs))
+		for i, id := range tc.DeptIDs {
+			ids[i] = id.String()
+		}
+		set(HeaderDeptIDs, strings.Join(ids, listSeparator))
+	}
+	var roles []string
+	for _, role := range tc.Roles {
+		if validRole(role) {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) > 0 {
+		set(HeaderRoles, strings.Join(roles, listSeparator))
+	}
+	if tc.PolicyVersion != 0 {
+		set(HeaderPolicyVersion, strconv.FormatInt(tc.PolicyVersion, 10))
+	}
+}
+
+// validRole 角色名可安全放入逗号分隔的头值：非空、仅可见 ASCII 且不含逗号
+func validRole(role string) bool {
+	if role == "" {
+		return false
+	}
+	for i := 0; i < len(role); i++ {
+		if role[i] < 0x21 || role[i] > 0x7e || role[i] == ',' {
+			return false
+		}
+	}
+	return true
+}
+
+// Decode 通过 get 回调读取各键的全部值并解码租户上下文
+// 未携带租户 ID 时返回 ok=false；值重复或非法时返回 Unauthenticated
+func Decode(get func(key string) []string) (repository.TenantContext, bool, error) {
+	var tc repository.TenantContext
+	value := func(key string) (string, error) {
+		values := get(key)
+		switch len(values) {
+		case 0:
+			return "", nil
+		case 1:
+			return values[0], nil
+		default:
+			return "", invalid(key)
+		}
+	}
+
+	raw, err := value(HeaderTenantID)
+	if err != nil || raw == "" {
+		return tc, false, err
+	}
+	if tc.TenantID, err = parseULID(HeaderTenantID, raw); err != nil {
+		return tc, false, err
+	}
+
+	if raw, err = value(HeaderUserID); err != nil {
+		return tc, false, err
+	} else if raw != "" {
+		if tc.UserID, err = parseULID(HeaderUserID, raw); err != nil {
+			return tc, false, err
+		}
+	}
+
+	if raw, err = value(HeaderDeptID); err != nil {
+		return tc, false, err
+	} else if raw != "" {
+		dept, err := parseULID(HeaderDeptID, raw)
+		if err != nil {
+			return tc, false, err
+		}
+		tc.DeptID = &dept
+	}
+
+	if raw, err = value(HeaderAdmin); err != nil {
+		return tc, false, err
+	}
+	switch raw {
+	case "", "false":
+	case adminValue:
+		tc.IsAdmin = true
+	default:
+		return tc, false, invalid(HeaderAdmin)
+	}
+
+	if raw, err = value(HeaderDataScope); err != nil {
+		return tc, false, err
+	}
+	tc.DataScope = repository.DataScope(raw)
+	if !slices.Contains(dataScopes, tc.DataScope) {
+		return tc, false, invalid(HeaderDataScope)
+	}
+
+	if raw, err = value(HeaderDeptIDs); err != nil {
+		return tc, false, err
+	} else if raw != "" {
+		for part := range strings.SplitSeq(raw, listSeparator) {
+			id, err := parseULID(HeaderDeptIDs, part)
+			if err != nil {
+				return tc, false, err
+			}
+			tc.DeptIDs = append(tc.DeptIDs, id)
+		}
+	}
+
+	if raw, err = value(HeaderRoles); err != nil {
+		return tc, false, err
+	} else if raw != "" {
+		for role := range strings.SplitSeq(raw, listSeparator) {
+			if !validRole(role) {
+				return tc, false, invalid(HeaderRoles)
+			}
+			tc.Roles = append(tc.Roles, role)
+		}
+	}
+
+	if raw, err = value(HeaderPolicyVersion); err != nil {
+		return tc, false, err
+	} else if raw != "" {
+		if tc.PolicyVersion, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return tc, false, invalid(HeaderPolicyVersion)
+		}
+	}
+	return tc, true, nil
+}
+
+// parseULID 严格解析规范 ULID 字符串（26 位大写 Crockford Base32）
+func parseULID(key, raw string) (ulidv2.ULID, error) {
+	id, err := ulidv2.ParseStrict(raw)
+	if err != nil || id.String() != raw {
+		return ulidv2.ULID{}, invalid(key)
+	}
+	return id, nil
+}
+
+func invalid(key string) error {
+	return errors.New(errors.ErrCodeUnauthenticated, "invalid auth metadata: "+key).WithDetail("key", key)
+}
+
+/* ========================================================================
+ * gRPC metadata / HTTP 头
+ * ======================================================================== */
+
+// Inject 将 ctx 中的租户上下文写入 md，先清除 md 中已有的身份键
+// ctx 中无租户上下文时不修改 md
+func Inject(ctx context.Context, md metadata.MD) {
+	tc, ok := repository.TenantFromContext(ctx)
+	if !ok {
+		return
+	}
+	for _, key := range Headers {
+		md.Delete(key)
+	}
+	Encode(tc, func(key, value string) { md.Set(key, value) })
+}
+
+// Extract 从 md 解码租户上下文
+func Extract(md metadata.MD) (repository.TenantContext, bool, error) {
+	return Decode(md.Get)
+}
+
+// InjectHeader 将 ctx 中的租户上下文写入 HTTP 头，先清除已有的身份头
+// ctx 中无租户上下文时不修改 h
+func InjectHeader(ctx context.Context, h http.Header) {
+	tc, ok := repository.TenantFromContext(ctx)
+	if !ok {
+		return
+	}
+	for _, key := range Headers {
+		h.Del(key)
+	}
+	Encode(tc, h.Set)
+}
+
+// ExtractHeader 从 HTTP 头解码租户上下文
+func ExtractHeader(h http.Header) (repository.TenantContext, bool, error) {
+	return Decode(h.Values)
+}
@@ -0,0 +1,122 @@
+package authmeta
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"google.golang.org/grpc/metadata"
+)
+
+func fullContext() repository.TenantContext {
+	dept := ulidv2.Make()
+	return repository.TenantContext{
+		TenantID:      ulidv2.Make(),
+		UserID:        ulidv2.Make(),
+		DeptID:        &dept,
+		IsAdmin:       true,
+		DataScope:     repository.DataScopeCustom,
+		DeptIDs:       []ulidv2.ULID{ulidv2.Make(), ulidv2.Make()},
+		Roles:         []string{"admin", "auditor"},
+		PolicyVersion: 7,
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tc := fullContext()
+	ctx := repository.WithTenantContext(context.Background(), tc)
+
+	md := metadata.MD{}
+	Inject(ctx, md)
+	got, ok, err := Extract(md)
+	if err != nil || !ok {
+		t.Fatalf("extract metadata: ok=%v err=%v", ok, err)
+	}
+	if !reflect.DeepEqual(got, tc) {
+		t.Fatalf("metadata round trip = %+v, want %+v", got, tc)
+	}
+
+	h := http.Header{}
+	InjectHeader(ctx, h)
+	got, ok, err = ExtractHeader(h)
+	if err != nil || !ok {
+		t.Fatalf("extract header: ok=%v err=%v", ok, err)
+	}
+	if !reflect.DeepEqual(got, tc) {
+		t.Fatalf("header round trip = %+v, want %+v", got, tc)
+	}
+}
+
+func TestHeaderMatchesMetadata(t *testing.T) {
+	ctx := repository.WithTenantContext(context.Background(), fullContext())
+	md := metadata.MD{}
+	Inject(ctx, md)
+	h := http.Header{}
+	InjectHeader(ctx, h)
+
+	if len(md) != len(h) || len(md) != len(Headers) {
+		t.Fatalf("md keys = %d, header keys = %d, want %d", len(md), len(h), len(Headers))
+	}
+	for _, key := range Headers {
+		if mv, hv := md.Get(key), h.Values(key); !reflect.DeepEqual(mv, hv) {
+			t.Fatalf("%s: metadata %q != header %q", key, mv, hv)
+		}
+		if _, ok := md[strings.ToLower(key)]; !ok {
+			t.Fatalf("metadata key %s not lower-cased", key)
+		}
+	}
+}
+
+func TestMinimalEncoding(t *testing.T) {
+	tenant := ulidv2.Make()
+	h := http.Header{}
+	h.Set(HeaderRoles, "stale")
+	InjectHeader(repository.WithTenantContext(context.Background(), repository.TenantContext{
+		TenantID: tenant,
+		Roles:    []string{"a,b", ""},
+	}), h)
+
+	if len(h) != 1 || h.Get(HeaderTenantID) != tenant.String() {
+		t.Fatalf("header = %v, want tenant only", h)
+	}
+
+	// ctx 中无租户上下文时不修改
+	md := metadata.Pairs(MetadataUserID, "keep")
+	Inject(context.Background(), md)
+	if md.Get(MetadataUserID)[0] != "keep" {
+		t.Fatalf("md = %v", md)
+	}
+}
+
+func TestExtractInvalid(t *testing.T) {
+	if _, ok, err := Extract(metadata.MD{}); ok || err != nil {
+		t.Fatalf("empty metadata: ok=%v err=%v", ok, err)
+	}
+
+	tenant := ulidv2.Make().String()
+	for name, md := range map[string]metadata.MD{
+		"lowercase ulid":  metadata.Pairs(MetadataTenantID, strings.ToLower(tenant)),
+		"duplicate":       metadata.Pairs(MetadataTenantID, tenant, MetadataTenantID, tenant),
+		"admin value":     metadata.Pairs(MetadataTenantID, tenant, MetadataAdmin, "1"),
+		"unknown scope":   metadata.Pairs(MetadataTenantID, tenant, MetadataDataScope, "everything"),
+		"dept ids spaces": metadata.Pairs(MetadataTenantID, tenant, MetadataDeptIDs, tenant+", "+tenant),
+		"empty role":      metadata.Pairs(MetadataTenantID, tenant, MetadataRoles, "a,,b"),
+		"policy version":  metadata.Pairs(MetadataTenantID, tenant, MetadataPolicyVersion, "v1"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, ok, err := Extract(md)
+			if ok {
+				t.Fatal("ok = true, want false")
+			}
+			if bizErr, isBiz := errors.AsBizError(err); !isBiz || bizErr.Code != errors.ErrCodeUnauthenticated {
+				t.Fatalf("err = %v, want Unauthenticated", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Build Info - 构建信息
+ * ========================================================================
+ * 职责: 统一提供版本、提交、构建时间与 Go 版本
+ * 来源: 优先使用 ldflags 注入的值，缺失时回退到 runtime/debug.ReadBuildInfo
+ *       （模块版本、vcs.revision、vcs.time）
+ * 集成:
+ *   - Prometheus: app_build_info{version,git_commit,build_time,go_version} = 1
+ *   - /healthz 与 /debug/build 输出
+ *   - 根 logger 自动附带 build 字段
+ *
+ * 构建注入:
+ *   go build -ldflags "-X github.com/aisgo/ais-go-pkg/buildinfo.Version=v1.2.3 \
+ *     -X github.com/aisgo/ais-go-pkg/buildinfo.GitCommit=$(git rev-parse HEAD) \
+ *     -X github.com/aisgo/ais-go-pkg/buildinfo.BuildTime=$(date -u +%FT%TZ)"
+ * ======================================================================== */
+
+// 构建信息（通过 -ldflags -X 注入）
+var (
+	Version   = ""
+	GitCommit = ""
+	BuildTime = ""
+)
+
+// DefaultVersion 无法获取版本时的默认值
+const DefaultVersion = "dev"
+
+// Info 构建信息
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildTime string `json:"build_time"`
+	GoVersion string `json:"go_version"`
+}
+
+var (
+	readOnce  sync.Once
+	buildInfo *debug.BuildInfo
+)
+
+// Get 返回当前构建信息（ldflags 优先，缺失字段回退到 ReadBuildInfo）
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+
+	readOnce.Do(func() {
+		buildInfo, _ = debug.ReadBuildInfo()
+	})
+	if bi := buildInfo; bi != nil {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildTime == "" {
+					info.BuildTime = s.Value
+				}
+			}
+		}
+	}
+
+	if info.Version == "" {
+		info.Version = DefaultVersion
+	}
+	return info
+}
+
+// ShortCommit 返回前 7 位提交哈希
+func (i Info) ShortCommit() string {
+	if len(i.GitCommit) > 7 {
+		return i.GitCommit[:7]
+	}
+	return i.GitCommit
+}
+
+// ZapField 返回附加到日志的 build 字段
+func ZapField() zap.Field {
+	info := Get()
+	return zap.Dict("build",
+		zap.String("version", info.Version),
+		zap.String("commit", info.ShortCommit()),
+	)
+}
+
+// NewCollector 创建 app_build_info 指标（值恒为 1，信息在标签中）
+func NewCollector() prometheus.Collector {
+	info := Get()
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: "app",
+		Name:      "build_info",
+		Help:      "Build information of the running binary",
+		ConstLabels: prometheus.Labels{
+			"version":    info.Version,
+			"git_commit": info.GitCommit,
+			"build_time": info.BuildTime,
+			"go_version": info.GoVersion,
+		},
+	}, func() float64 { return 1 })
+}
+
+var registerOnce sync.Once
+
+// RegisterMetrics 向默认注册表注册 app_build_info（重复调用安全）
+func RegisterMetrics() {
+	registerOnce.Do(func() {
+		_ = prometheus.DefaultRegisterer.Register(NewCollector())
+	})
+}
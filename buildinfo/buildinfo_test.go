@@ -0,0 +1,43 @@
+package buildinfo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestGetPrefersLdflags(t *testing.T) {
+	Version, GitCommit, BuildTime = "v1.2.3", "0123456789abcdef", "2026-01-02T03:04:05Z"
+	t.Cleanup(func() { Version, GitCommit, BuildTime = "", "", "" })
+
+	info := Get()
+	if info.Version != "v1.2.3" || info.GitCommit != "0123456789abcdef" || info.BuildTime != "2026-01-02T03:04:05Z" {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	if info.GoVersion == "" || info.ShortCommit() != "0123456" {
+		t.Fatalf("unexpected go version or short commit: %+v", info)
+	}
+}
+
+func TestGetDefaultsVersion(t *testing.T) {
+	if info := Get(); info.Version == "" {
+		t.Fatalf("expected fallback version, got %+v", info)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	Version = "v9.9.9"
+	t.Cleanup(func() { Version = "" })
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(NewCollector())
+	expected := `app_build_info{build_time="` + Get().BuildTime + `",git_commit="` + Get().GitCommit +
+		`",go_version="` + Get().GoVersion + `",version="v9.9.9"} 1`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(
+		"# HELP app_build_info Build information of the running binary\n# TYPE app_build_info gauge\n"+expected+"\n"),
+		"app_build_info"); err != nil {
+		t.Fatal(err)
+	}
+}
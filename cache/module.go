@@ -2,6 +2,7 @@ package cache
 
 import (
 	"github.com/aisgo/ais-go-pkg/cache/redis"
+	"github.com/aisgo/ais-go-pkg/conf"
 	"go.uber.org/fx"
 )
 
@@ -9,10 +10,22 @@ import (
  * Cache Module
  * ========================================================================
  * 职责: 提供 Redis 缓存依赖注入模块
+ * Bundle: 从 conf.Source 的 redis 节解码 Config（缺省见 redis.DefaultConfig）
  * ======================================================================== */
 
 // Module 缓存模块
-// 提供: *redis.Client
+// 提供: *redis.Client, redis.Cache
 var Module = fx.Module("cache",
-	fx.Provide(redis.NewClient),
+	fx.Provide(
+		redis.NewClient,
+		func(c *redis.Client) redis.Cache { return c },
+	),
+)
+
+// Bundle 带配置解码的缓存模块
+// 依赖: conf.Source, *logger.Logger
+// 提供: redis.Config, *redis.Client, redis.Cache
+var Bundle = fx.Module("cache-bundle",
+	fx.Provide(conf.Section("redis", redis.DefaultConfig)),
+	Module,
 )
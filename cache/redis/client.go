@@ -2,9 +2,11 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/aisgo/ais-go-pkg/clock"
 	"github.com/aisgo/ais-go-pkg/logger"
 
 	"github.com/redis/go-redis/v9"
@@ -29,10 +31,41 @@ type Config struct {
 	MinIdleConns int    `yaml:"min_idle_conns"`
 }
 
+// DefaultConfig 返回默认配置（本机 6379，DB 0）
+func DefaultConfig() Config {
+	return Config{
+		Host:     "127.0.0.1",
+		Port:     6379,
+		PoolSize: 10,
+	}
+}
+
+// Cache Redis 缓存操作接口
+// 业务代码依赖该接口，测试时可注入 redistest 创建的 miniredis 客户端
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value any, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value any, expiration time.Duration) (bool, error)
+	Del(ctx context.Context, keys ...string) error
+	Exists(ctx context.Context, keys ...string) (int64, error)
+	Expire(ctx context.Context, key string, expiration time.Duration) error
+
+	HGet(ctx context.Context, key, field string) (string, error)
+	HSet(ctx context.Context, key string, values ...any) error
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	HDel(ctx context.Context, key string, fields ...string) error
+
+	NewLock(key string, opts ...LockOption) *Lock
+	Ping(ctx context.Context) error
+}
+
+var _ Cache = (*Client)(nil)
+
 // Client Redis 客户端封装
 type Client struct {
-	rdb *redis.Client
-	log *logger.Logger
+	rdb   redis.UniversalClient
+	log   *logger.Logger
+	clock clock.Clock
 }
 
 type ClientParams struct {
@@ -40,6 +73,7 @@ type ClientParams struct {
 	Lc     fx.Lifecycle
 	Config Config
 	Logger *logger.Logger
+	Clock  clock.Clock `optional:"true"` // 锁重试/自动续期计时，默认真实时钟
 }
 
 // NewClient 创建 Redis 客户端
@@ -54,11 +88,9 @@ func NewClient(p ClientParams) *Client {
 		PoolSize:     p.Config.PoolSize,
 		MinIdleConns: p.Config.MinIdleConns,
 	})
+	rdb.AddHook(DeadlineHook{})
 
-	client := &Client{
-		rdb: rdb,
-		log: p.Logger,
-	}
+	client := NewClientFromRedis(rdb, p.Logger).WithClock(p.Clock)
 
 	p.Lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -74,15 +106,38 @@ func NewClient(p ClientParams) *Client {
 		},
 		OnStop: func(ctx context.Context) error {
 			p.Logger.Info("Closing Redis connection")
-			return rdb.Close()
+			// 可能已由 shutdown.Manager 提前关闭
+			if err := rdb.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+				return err
+			}
+			return nil
 		},
 	})
 
 	return client
 }
 
+// NewClientFromRedis 基于已有的 go-redis 客户端创建封装（不托管生命周期）
+// 用于哨兵/集群等自定义连接，或测试中注入 miniredis
+func NewClientFromRedis(rdb redis.UniversalClient, log *logger.Logger) *Client {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &Client{
+		rdb:   rdb,
+		log:   log,
+		clock: clock.Real(),
+	}
+}
+
+// WithClock 设置锁重试/自动续期使用的时钟（nil 表示真实时钟），需在创建锁之前调用
+func (c *Client) WithClock(clk clock.Clock) *Client {
+	c.clock = clock.OrReal(clk)
+	return c
+}
+
 // Raw 返回底层 Redis 客户端 (用于高级操作)
-func (c *Client) Raw() *redis.Client {
+func (c *Client) Raw() redis.UniversalClient {
 	return c.rdb
 }
 
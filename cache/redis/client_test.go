@@ -4,6 +4,9 @@ import (
 	"context"
 	"testing"
 	"time"
+
+	"github.com/aisgo/ais-go-pkg/ctxutil"
+	"github.com/aisgo/ais-go-pkg/errors"
 )
 
 func TestClientCacheOps(t *testing.T) {
@@ -70,3 +73,27 @@ func TestClientHashOps(t *testing.T) {
 		t.Fatalf("hdel: %v", err)
 	}
 }
+
+func TestDeadlineHook(t *testing.T) {
+	client := newTestClient(t)
+	client.rdb.AddHook(DeadlineHook{})
+	if err := ctxutil.SetMode(ctxutil.ModeEnforce); err != nil {
+		t.Fatalf("set mode: %v", err)
+	}
+	t.Cleanup(func() { _ = ctxutil.SetMode(ctxutil.ModeOff) })
+
+	if err := client.Set(context.Background(), "k", "v", 0); !errors.Is(err, ctxutil.ErrNoDeadline) {
+		t.Fatalf("set without deadline: %v", err)
+	}
+	pipe := client.rdb.Pipeline()
+	pipe.Get(context.Background(), "k")
+	if _, err := pipe.Exec(context.Background()); !errors.Is(err, ctxutil.ErrNoDeadline) {
+		t.Fatalf("pipeline without deadline: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := client.Set(ctx, "k", "v", 0); err != nil {
+		t.Fatalf("set with deadline: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/aisgo/ais-go-pkg/ctxutil"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/* ========================================================================
+ * Deadline Hook - 命令截止时间检查
+ * ========================================================================
+ * 职责: 命令与 Pipeline 执行前检查 ctx 的截止时间（见 ctxutil.RequireDeadline，操作名 redis）
+ * 说明:
+ *   - NewClient 创建的连接已自动添加；NewClientFromRedis 的自定义连接需手动添加
+ *   - 阻塞读取（BLPOP / XREADGROUP 等）的后台循环应使用 ctxutil.WithoutDeadlineCheck 标记 ctx
+ *
+ * 使用示例:
+ *   rdb := redis.NewFailoverClient(opts)
+ *   rdb.AddHook(cacheredis.DeadlineHook{})
+ *   client := cacheredis.NewClientFromRedis(rdb, log)
+ * ======================================================================== */
+
+// DeadlineHook go-redis 截止时间检查钩子
+type DeadlineHook struct{}
+
+var _ redis.Hook = DeadlineHook{}
+
+// DialHook 透传
+func (DeadlineHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+// ProcessHook 检查单条命令的 ctx
+func (DeadlineHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := ctxutil.RequireDeadline(ctx, "redis"); err != nil {
+			cmd.SetErr(err)
+			return err
+		}
+		return next(ctx, cmd)
+	}
+}
+
+// ProcessPipelineHook 检查 Pipeline / 事务的 ctx
+func (DeadlineHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := ctxutil.RequireDeadline(ctx, "redis"); err != nil {
+			for _, cmd := range cmds {
+				cmd.SetErr(err)
+			}
+			return err
+		}
+		return next(ctx, cmds)
+	}
+}
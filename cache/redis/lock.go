@@ -7,6 +7,9 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/aisgo/ais-go-pkg/clock"
 )
 
 /* ========================================================================
@@ -21,6 +24,33 @@ var (
 	ErrUnlockFailed = errors.New("failed to release lock")
 )
 
+// Lua 脚本: 仅当 value 匹配（持有锁）时执行，保证原子性
+var (
+	// releaseScript 如果 value 匹配则删除
+	releaseScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("DEL", KEYS[1])
+		else
+			return 0
+		end
+	`)
+
+	// extendScript 如果 value 匹配则延长过期时间
+	extendScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+		else
+			return 0
+		end
+	`)
+)
+
+// Scripts 返回本包使用的全部 Lua 脚本
+// 可在启动时 SCRIPT LOAD 预热，redistest 也据此预加载
+func Scripts() []*redis.Script {
+	return []*redis.Script{releaseScript, extendScript}
+}
+
 // Lock 分布式锁
 type Lock struct {
 	client       *Client
@@ -97,7 +127,7 @@ func (l *Lock) AcquireWithOption(ctx context.Context, opt LockOption) error {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(opt.RetryDelay):
+		case <-l.client.clock.After(opt.RetryDelay):
 		}
 	}
 
@@ -144,10 +174,10 @@ func (l *Lock) autoExtendLoop(ctx context.Context, extendFactor float64) {
 
 	// 添加最大生命周期保护（防止无限续期导致 goroutine 泄漏）
 	maxLifetime := l.ttl * 100 // 最多续期到 TTL 的 100 倍
-	deadlineCtx, deadlineCancel := context.WithTimeout(ctx, maxLifetime)
+	deadlineCtx, deadlineCancel := clock.WithTimeout(ctx, l.client.clock, maxLifetime)
 	defer deadlineCancel()
 
-	ticker := time.NewTicker(interval)
+	ticker := l.client.clock.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -156,7 +186,7 @@ func (l *Lock) autoExtendLoop(ctx context.Context, extendFactor float64) {
 			// 超过最大生命周期或被取消
 			return
 
-		case <-ticker.C:
+		case <-ticker.C():
 			// 尝试续期
 			if !l.tryExtend(deadlineCtx) {
 				// 续期失败，可能锁已丢失
@@ -169,6 +199,7 @@ func (l *Lock) autoExtendLoop(ctx context.Context, extendFactor float64) {
 // tryExtend 尝试续期，返回是否应继续
 func (l *Lock) tryExtend(ctx context.Context) bool {
 	for i := 0; i < 3; i++ {
+		// 单次网络调用超时按真实时间计算
 		extendCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		err := l.Extend(extendCtx, l.ttl)
 		cancel()
@@ -187,7 +218,7 @@ func (l *Lock) tryExtend(ctx context.Context) bool {
 		select {
 		case <-ctx.Done():
 			return false
-		case <-time.After(backoff):
+		case <-l.client.clock.After(backoff):
 			continue
 		}
 	}
@@ -202,16 +233,7 @@ func (l *Lock) Release(ctx context.Context) error {
 	// 停止自动续期 goroutine
 	l.stopAutoExtend()
 
-	// Lua 脚本: 如果 value 匹配则删除
-	script := `
-		if redis.call("GET", KEYS[1]) == ARGV[1] then
-			return redis.call("DEL", KEYS[1])
-		else
-			return 0
-		end
-	`
-
-	result, err := l.client.rdb.Eval(ctx, script, []string{l.key}, l.value).Int64()
+	result, err := releaseScript.Run(ctx, l.client.rdb, []string{l.key}, l.value).Int64()
 	if err != nil {
 		return err
 	}
@@ -223,16 +245,7 @@ func (l *Lock) Release(ctx context.Context) error {
 
 // Extend 延长锁时间
 func (l *Lock) Extend(ctx context.Context, ttl time.Duration) error {
-	// Lua 脚本: 如果 value 匹配则延长过期时间
-	script := `
-		if redis.call("GET", KEYS[1]) == ARGV[1] then
-			return redis.call("PEXPIRE", KEYS[1], ARGV[2])
-		else
-			return 0
-		end
-	`
-
-	result, err := l.client.rdb.Eval(ctx, script, []string{l.key}, l.value, ttl.Milliseconds()).Int64()
+	result, err := extendScript.Run(ctx, l.client.rdb, []string{l.key}, l.value, ttl.Milliseconds()).Int64()
 	if err != nil {
 		return err
 	}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/aisgo/ais-go-pkg/clock"
 )
 
 func TestLockAcquireRelease(t *testing.T) {
@@ -56,3 +58,29 @@ func TestLockAutoExtendIgnoresParentCancel(t *testing.T) {
 		t.Fatalf("release lock: %v", err)
 	}
 }
+
+func TestLockAutoExtendWithFakeClock(t *testing.T) {
+	client, server := newTestClientWithServer(t)
+	fake := clock.NewFake(time.Time{})
+	client.WithClock(fake)
+	ctx := context.Background()
+
+	lock := client.NewLock("fake", LockOption{TTL: time.Minute, RetryTimes: 1, AutoExtend: true, ExtendFactor: 0.5})
+	if err := lock.Acquire(ctx); err != nil {
+		t.Fatalf("acquire lock: %v", err)
+	}
+	defer func() { _ = lock.Release(ctx) }()
+
+	// 续期 goroutine 注册 ticker 与最大生命周期定时器
+	fake.BlockUntil(2)
+	server.FastForward(40 * time.Second)
+	fake.Advance(30 * time.Second)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for server.TTL(lock.key) <= 20*time.Second {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected lock ttl to be extended, got %v", server.TTL(lock.key))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
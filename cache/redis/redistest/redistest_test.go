@@ -0,0 +1,42 @@
+package redistest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cacheredis "github.com/aisgo/ais-go-pkg/cache/redis"
+)
+
+func TestNewPreloadsScripts(t *testing.T) {
+	client, server := New(t)
+	ctx := context.Background()
+
+	for _, script := range cacheredis.Scripts() {
+		exists, err := script.Exists(ctx, client.Raw()).Result()
+		if err != nil {
+			t.Fatalf("script exists: %v", err)
+		}
+		if len(exists) != 1 || !exists[0] {
+			t.Fatalf("expected script %s to be preloaded", script.Hash())
+		}
+	}
+
+	var cache cacheredis.Cache = client
+	lock := cache.NewLock("order:1", cacheredis.LockOption{TTL: time.Second, RetryTimes: 1})
+	if err := lock.Acquire(ctx); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	if err := lock.Extend(ctx, 2*time.Second); err != nil {
+		t.Fatalf("extend: %v", err)
+	}
+	if ttl := server.TTL("lock:order:1"); ttl != 2*time.Second {
+		t.Fatalf("unexpected ttl after extend: %v", ttl)
+	}
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if server.Exists("lock:order:1") {
+		t.Fatalf("expected lock key to be deleted")
+	}
+}
@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"errors"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/shutdown"
+)
+
+/* ========================================================================
+ * Redis Shutdown - 接入优雅关停
+ * ========================================================================
+ * 职责: 将 Redis 连接池注册到 shutdown.Manager (Priorities.Redis)
+ * ======================================================================== */
+
+// RegisterShutdown 注册 Redis 连接池关停钩子
+func RegisterShutdown(m *shutdown.Manager, client *Client) {
+	m.RegisterHookWithPriority("redis", shutdown.CloseHook(func() error {
+		if err := client.Raw().Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+			return err
+		}
+		return nil
+	}), *m.Priorities().Redis)
+}
+
+// ShutdownModule Redis 关停集成模块
+// 需与 shutdown.Module 一起使用
+var ShutdownModule = fx.Module("redis-shutdown",
+	fx.Invoke(func(lc fx.Lifecycle, m *shutdown.Manager, client *Client) {
+		RegisterShutdown(m, client)
+		m.ShutdownOnStop(lc)
+	}),
+)
@@ -23,7 +23,7 @@ func newTestClientWithServer(t *testing.T) (*Client, *miniredis.Miniredis) {
 		_ = rdb.Close()
 	})
 
-	return &Client{rdb: rdb, log: logger.NewNop()}, server
+	return NewClientFromRedis(rdb, logger.NewNop()), server
 }
 
 func newTestClient(t *testing.T) *Client {
@@ -0,0 +1,147 @@
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/* ========================================================================
+ * Clock - 时间抽象
+ * ========================================================================
+ * 职责: 统一获取当前时间与定时器，使时间相关逻辑在测试中可确定
+ * 实现:
+ *   - Real(): 基于标准库 time
+ *   - NewFake(start): 手动推进的假时钟（Advance / Set），见 fake.go
+ * 接入: Redis 锁自动续期、shutdown 超时、worker 重试退避、仓储时间戳
+ *       均可注入 Clock；Fx 场景提供 clock.Clock 即可被可选依赖获取
+ *
+ * 使用示例:
+ *   fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+ *   mgr := shutdown.NewManager(shutdown.ManagerParams{Logger: log, Config: cfg, Clock: fake})
+ *   fake.Advance(30 * time.Second)
+ * ======================================================================== */
+
+// Clock 时钟接口
+type Clock interface {
+	// Now 当前时间
+	Now() time.Time
+	// Since 自 t 以来经过的时间
+	Since(t time.Time) time.Duration
+	// After 等待 d 后向返回的通道发送当前时间
+	After(d time.Duration) <-chan time.Time
+	// NewTimer 创建定时器
+	NewTimer(d time.Duration) Timer
+	// NewTicker 创建周期定时器（d 必须大于 0）
+	NewTicker(d time.Duration) Ticker
+	// AfterFunc 等待 d 后在独立 goroutine（假时钟为 Advance 调用方）中执行 f
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer 定时器接口（语义同 time.Timer）
+type Timer interface {
+	// C 到期通道，AfterFunc 创建的定时器返回 nil
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker 周期定时器接口（语义同 time.Ticker）
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+	Reset(d time.Duration)
+}
+
+// OrReal 返回 c，c 为 nil 时返回真实时钟
+func OrReal(c Clock) Clock {
+	if c == nil {
+		return Real()
+	}
+	return c
+}
+
+// =============================================================================
+// 真实时钟
+// =============================================================================
+
+type realClock struct{}
+
+// Real 返回基于标准库 time 的时钟
+func Real() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) Since(t time.Time) time.Duration        { return time.Since(t) }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{t: time.NewTimer(d)}
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{t: time.NewTicker(d)}
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{t: time.AfterFunc(d, f)}
+}
+
+type realTimer struct{ t *time.Timer }
+
+func (r realTimer) C() <-chan time.Time        { return r.t.C }
+func (r realTimer) Stop() bool                 { return r.t.Stop() }
+func (r realTimer) Reset(d time.Duration) bool { return r.t.Reset(d) }
+
+type realTicker struct{ t *time.Ticker }
+
+func (r realTicker) C() <-chan time.Time   { return r.t.C }
+func (r realTicker) Stop()                 { r.t.Stop() }
+func (r realTicker) Reset(d time.Duration) { r.t.Reset(d) }
+
+// =============================================================================
+// Context 超时
+// =============================================================================
+
+// WithTimeout 按时钟 c 计时的 context.WithTimeout
+// 真实时钟直接使用标准库；其他时钟在 c 上计时，到期后 ctx.Err() 返回 context.DeadlineExceeded
+func WithTimeout(parent context.Context, c Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := OrReal(c).(realClock); ok {
+		return context.WithTimeout(parent, d)
+	}
+
+	inner, cancel := context.WithCancelCause(parent)
+	ctx := &timeoutCtx{Context: inner, deadline: c.Now().Add(d)}
+	if d <= 0 {
+		cancel(context.DeadlineExceeded)
+		return ctx, func() { cancel(context.Canceled) }
+	}
+
+	timer := c.AfterFunc(d, func() { cancel(context.DeadlineExceeded) })
+	var once sync.Once
+	return ctx, func() {
+		once.Do(func() {
+			timer.Stop()
+			cancel(context.Canceled)
+		})
+	}
+}
+
+// timeoutCtx 以时钟 c 的时间作为截止时间的上下文
+type timeoutCtx struct {
+	context.Context
+	deadline time.Time
+}
+
+func (c *timeoutCtx) Deadline() (time.Time, bool) {
+	return c.deadline, true
+}
+
+func (c *timeoutCtx) Err() error {
+	err := c.Context.Err()
+	if err != nil && context.Cause(c.Context) == context.DeadlineExceeded {
+		return context.DeadlineExceeded
+	}
+	return err
+}
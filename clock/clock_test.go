@@ -0,0 +1,149 @@
+package clock
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestFakeTimerAndAfter(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	after := f.After(2 * time.Second)
+
+	f.Advance(500 * time.Millisecond)
+	select {
+	case <-timer.C():
+		t.Fatalf("timer fired early")
+	default:
+	}
+
+	f.Advance(time.Second)
+	select {
+	case got := <-timer.C():
+		if !got.Equal(epoch.Add(time.Second)) {
+			t.Fatalf("unexpected fire time: %v", got)
+		}
+	default:
+		t.Fatalf("timer did not fire")
+	}
+	if !f.Now().Equal(epoch.Add(1500 * time.Millisecond)) {
+		t.Fatalf("unexpected now: %v", f.Now())
+	}
+
+	f.Set(epoch.Add(2 * time.Second))
+	select {
+	case <-after:
+	default:
+		t.Fatalf("after did not fire")
+	}
+	if f.Waiters() != 0 {
+		t.Fatalf("expected no waiters, got %d", f.Waiters())
+	}
+}
+
+func TestFakeTimerStopReset(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	if !timer.Stop() {
+		t.Fatalf("expected stop to report active timer")
+	}
+	if timer.Stop() {
+		t.Fatalf("second stop should report inactive")
+	}
+	if timer.Reset(2 * time.Second) {
+		t.Fatalf("reset of stopped timer should report inactive")
+	}
+	f.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Fatalf("reset timer fired early")
+	default:
+	}
+	f.Advance(time.Second)
+	select {
+	case <-timer.C():
+	default:
+		t.Fatalf("reset timer did not fire")
+	}
+}
+
+func TestFakeTickerAndAfterFunc(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var order []string
+	f.AfterFunc(1500*time.Millisecond, func() { order = append(order, "func") })
+
+	f.Advance(time.Second)
+	<-ticker.C()
+	order = append(order, "tick")
+
+	f.Advance(time.Second)
+	<-ticker.C()
+	if len(order) != 2 || order[0] != "tick" || order[1] != "func" {
+		t.Fatalf("unexpected order: %v", order)
+	}
+
+	// 未读取的 tick 被丢弃，不阻塞 Advance
+	f.Advance(5 * time.Second)
+	<-ticker.C()
+	select {
+	case <-ticker.C():
+		t.Fatalf("expected dropped ticks")
+	default:
+	}
+}
+
+func TestFakeBlockUntil(t *testing.T) {
+	f := NewFake(epoch)
+	var fired atomic.Bool
+	done := make(chan struct{})
+	go func() {
+		<-f.After(time.Minute)
+		fired.Store(true)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Minute)
+	<-done
+	if !fired.Load() {
+		t.Fatalf("expected waiter to fire")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	f := NewFake(epoch)
+	ctx, cancel := WithTimeout(context.Background(), f, time.Second)
+	defer cancel()
+
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(epoch.Add(time.Second)) {
+		t.Fatalf("unexpected deadline: %v %v", deadline, ok)
+	}
+	f.Advance(999 * time.Millisecond)
+	if ctx.Err() != nil {
+		t.Fatalf("expired early: %v", ctx.Err())
+	}
+	f.Advance(time.Millisecond)
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Fatalf("expected deadline exceeded, got %v", ctx.Err())
+	}
+
+	ctx, cancel = WithTimeout(context.Background(), f, time.Second)
+	cancel()
+	if ctx.Err() != context.Canceled || f.Waiters() != 0 {
+		t.Fatalf("expected canceled context and stopped timer: %v %d", ctx.Err(), f.Waiters())
+	}
+
+	realCtx, realCancel := WithTimeout(context.Background(), nil, time.Hour)
+	defer realCancel()
+	if deadline, ok := realCtx.Deadline(); !ok || time.Until(deadline) <= 0 {
+		t.Fatalf("expected real deadline")
+	}
+}
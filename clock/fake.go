@@ -0,0 +1,235 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+/* ========================================================================
+ * Fake Clock - 手动推进的假时钟
+ * ========================================================================
+ * 语义:
+ *   - 时间只在 Advance / Set 时前进
+ *   - Advance 按到期先后依次触发定时器，Ticker 在一次 Advance 内可多次触发
+ *   - 通道发送为非阻塞（缓冲 1，同 time.Ticker 丢弃未读的 tick）
+ *   - AfterFunc 的回调在 Advance 调用方 goroutine 中同步执行
+ *   - BlockUntil(n) 等待至少 n 个活跃定时器，用于确认被测 goroutine 已开始等待
+ * ======================================================================== */
+
+// Fake 假时钟
+type Fake struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	seq     uint64
+	waiters []*fakeWaiter
+}
+
+var _ Clock = (*Fake)(nil)
+
+type fakeWaiter struct {
+	clock  *Fake
+	id     uint64
+	fireAt time.Time
+	period time.Duration
+	ch     chan time.Time
+	fn     func()
+	active bool
+}
+
+// NewFake 创建起始时间为 start 的假时钟（start 为零值时使用当前时间）
+func NewFake(start time.Time) *Fake {
+	if start.IsZero() {
+		start = time.Now()
+	}
+	f := &Fake{now: start}
+	f.cond = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now 当前时间
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Since 自 t 以来经过的时间
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// After 等待 d 后向返回的通道发送当前时间
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer 创建定时器
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	return &fakeTimer{w: f.add(d, 0, make(chan time.Time, 1), nil)}
+}
+
+// NewTicker 创建周期定时器
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	return &fakeTicker{w: f.add(d, d, make(chan time.Time, 1), nil)}
+}
+
+// AfterFunc 等待 d 后在 Advance 调用方中执行 f
+func (f *Fake) AfterFunc(d time.Duration, fn func()) Timer {
+	return &fakeTimer{w: f.add(d, 0, nil, fn)}
+}
+
+// Advance 推进时间 d，并按顺序触发到期的定时器
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	target := f.now.Add(d)
+	for {
+		w := f.nextDue(target)
+		if w == nil {
+			break
+		}
+		f.now = w.fireAt
+		now := f.now
+		if w.period > 0 {
+			w.fireAt = w.fireAt.Add(w.period)
+			f.sortWaiters()
+		} else {
+			f.remove(w)
+		}
+		fn, ch := w.fn, w.ch
+		f.mu.Unlock()
+
+		if fn != nil {
+			fn()
+		} else {
+			select {
+			case ch <- now:
+			default:
+			}
+		}
+
+		f.mu.Lock()
+	}
+	if target.After(f.now) {
+		f.now = target
+	}
+	f.mu.Unlock()
+}
+
+// Set 将时间设置为 t（早于当前时间时忽略），并触发期间到期的定时器
+func (f *Fake) Set(t time.Time) {
+	if d := t.Sub(f.Now()); d > 0 {
+		f.Advance(d)
+	}
+}
+
+// Waiters 返回活跃定时器数量
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil 阻塞直到活跃定时器数量不少于 n
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.cond.Wait()
+	}
+}
+
+func (f *Fake) add(d, period time.Duration, ch chan time.Time, fn func()) *fakeWaiter {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.seq++
+	w := &fakeWaiter{clock: f, id: f.seq, fireAt: f.now.Add(d), period: period, ch: ch, fn: fn}
+	f.insert(w)
+	return w
+}
+
+// nextDue 返回最早到期（同时到期按创建顺序）且不晚于 target 的定时器（调用方持有锁）
+func (f *Fake) nextDue(target time.Time) *fakeWaiter {
+	if len(f.waiters) == 0 || f.waiters[0].fireAt.After(target) {
+		return nil
+	}
+	return f.waiters[0]
+}
+
+// insert 插入并保持按 (fireAt, id) 排序（调用方持有锁）
+func (f *Fake) insert(w *fakeWaiter) {
+	w.active = true
+	f.waiters = append(f.waiters, w)
+	f.sortWaiters()
+	f.cond.Broadcast()
+}
+
+// remove 移除定时器，返回是否处于活跃状态（调用方持有锁）
+func (f *Fake) remove(w *fakeWaiter) bool {
+	if !w.active {
+		return false
+	}
+	w.active = false
+	for i, cur := range f.waiters {
+		if cur == w {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+func (f *Fake) sortWaiters() {
+	sort.SliceStable(f.waiters, func(i, j int) bool {
+		a, b := f.waiters[i], f.waiters[j]
+		if a.fireAt.Equal(b.fireAt) {
+			return a.id < b.id
+		}
+		return a.fireAt.Before(b.fireAt)
+	})
+}
+
+// reschedule 重新设定到期时间，返回重设前是否处于活跃状态
+func (w *fakeWaiter) reschedule(d time.Duration) bool {
+	f := w.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	wasActive := f.remove(w)
+	w.fireAt = f.now.Add(d)
+	if w.period > 0 {
+		w.period = d
+	}
+	f.insert(w)
+	return wasActive
+}
+
+func (w *fakeWaiter) stop() bool {
+	f := w.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.remove(w)
+}
+
+type fakeTimer struct{ w *fakeWaiter }
+
+func (t *fakeTimer) C() <-chan time.Time        { return t.w.ch }
+func (t *fakeTimer) Stop() bool                 { return t.w.stop() }
+func (t *fakeTimer) Reset(d time.Duration) bool { return t.w.reschedule(d) }
+
+type fakeTicker struct{ w *fakeWaiter }
+
+func (t *fakeTicker) C() <-chan time.Time { return t.w.ch }
+func (t *fakeTicker) Stop()               { t.w.stop() }
+
+func (t *fakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("clock: non-positive interval for Ticker.Reset")
+	}
+	t.w.reschedule(d)
+}
@@ -0,0 +1,228 @@
+package codec
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"mime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shamaton/msgpack/v2"
+	"google.golang.org/protobuf/proto"
+)
+
+/* ========================================================================
+ * Codec - HTTP 请求/响应体编解码
+ * ========================================================================
+ * 职责: 按 Content-Type / Accept 选择编解码器，供 request.Bind 与 response 输出复用
+ * 内置:
+ *   - JSON      application/json
+ *   - MsgPack   application/msgpack（兼容 application/x-msgpack），字段名取 msgpack 标签，缺省为字段名
+ *   - Protobuf  application/x-protobuf（兼容 application/protobuf），值必须实现 proto.Message
+ * 扩展: Register 注册自定义编解码器（同 Content-Type 覆盖）
+ *
+ * 使用示例:
+ *   c := codec.Negotiate(r.Header.Get("Accept"), codec.JSON, codec.MsgPack)
+ *   data, err := c.Marshal(v)
+ * ======================================================================== */
+
+// ErrUnsupportedType 编解码器不支持该值类型（如非 proto.Message 使用 Protobuf）
+var ErrUnsupportedType = stderrors.New("codec: unsupported value type")
+
+// Codec 编解码器
+type Codec interface {
+	// Name 短名称（json / msgpack / protobuf），用于配置引用
+	Name() string
+	// ContentType 规范 Content-Type
+	ContentType() string
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// 内置编解码器
+var (
+	JSON     Codec = jsonCodec{}
+	MsgPack  Codec = msgpackCodec{}
+	Protobuf Codec = protobufCodec{}
+)
+
+var (
+	mu      sync.RWMutex
+	byName  = map[string]Codec{}
+	byType  = map[string]Codec{}
+	aliases = map[string]string{
+		"application/x-msgpack":    "application/msgpack",
+		"application/vnd.msgpack":  "application/msgpack",
+		"application/protobuf":     "application/x-protobuf",
+		"application/vnd.protobuf": "application/x-protobuf",
+	}
+)
+
+func init() {
+	Register(JSON)
+	Register(MsgPack)
+	Register(Protobuf)
+}
+
+// Register 注册编解码器（同名或同 Content-Type 覆盖）
+func Register(c Codec) {
+	mu.Lock()
+	defer mu.Unlock()
+	byName[c.Name()] = c
+	byType[c.ContentType()] = c
+}
+
+// ByName 按短名称查找
+func ByName(name string) (Codec, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := byName[strings.ToLower(name)]
+	return c, ok
+}
+
+// ByNames 按短名称列表查找，未注册的名称返回错误
+func ByNames(names ...string) ([]Codec, error) {
+	codecs := make([]Codec, 0, len(names))
+	for _, name := range names {
+		c, ok := ByName(name)
+		if !ok {
+			return nil, fmt.Errorf("codec: unknown codec %q", name)
+		}
+		codecs = append(codecs, c)
+	}
+	return codecs, nil
+}
+
+// ForContentType 按 Content-Type 查找（忽略参数，如 charset）
+func ForContentType(contentType string) (Codec, bool) {
+	mediaType := normalize(contentType)
+	mu.RLock()
+	defer mu.RUnlock()
+	c, ok := byType[mediaType]
+	return c, ok
+}
+
+// Match 在 allowed 中查找与 Content-Type 匹配的编解码器，allowed 为空时查全部已注册
+func Match(contentType string, allowed ...Codec) (Codec, bool) {
+	if len(allowed) == 0 {
+		return ForContentType(contentType)
+	}
+	mediaType := normalize(contentType)
+	for _, c := range allowed {
+		if c.ContentType() == mediaType {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// Negotiate 按 Accept 头（含 q 值）在 allowed 中选择响应编解码器
+// allowed 为空时视为 [JSON]；Accept 为空或包含 */* 时返回首选（allowed[0]）；无可接受项时返回 nil
+func Negotiate(accept string, allowed ...Codec) Codec {
+	if len(allowed) == 0 {
+		allowed = []Codec{JSON}
+	}
+	accept = strings.TrimSpace(accept)
+	if accept == "" {
+		return allowed[0]
+	}
+
+	type candidate struct {
+		mediaType string
+		q         float64
+		wildcard  bool
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		if alias, ok := aliases[mediaType]; ok {
+			mediaType = alias
+		}
+		candidates = append(candidates, candidate{mediaType: mediaType, q: q, wildcard: strings.HasSuffix(mediaType, "/*")})
+	}
+	// q 值降序，同 q 值时具体类型优先于通配
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].q != candidates[j].q {
+			return candidates[i].q > candidates[j].q
+		}
+		return !candidates[i].wildcard && candidates[j].wildcard
+	})
+
+	for _, cand := range candidates {
+		if cand.wildcard {
+			prefix := strings.TrimSuffix(cand.mediaType, "*")
+			for _, c := range allowed {
+				if cand.mediaType == "*/*" || strings.HasPrefix(c.ContentType(), prefix) {
+					return c
+				}
+			}
+			continue
+		}
+		for _, c := range allowed {
+			if c.ContentType() == cand.mediaType {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// normalize 去掉参数并小写，映射别名
+func normalize(contentType string) string {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	if alias, ok := aliases[mediaType]; ok {
+		return alias
+	}
+	return mediaType
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string                       { return "json" }
+func (jsonCodec) ContentType() string                { return "application/json" }
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+type msgpackCodec struct{}
+
+func (msgpackCodec) Name() string                       { return "msgpack" }
+func (msgpackCodec) ContentType() string                { return "application/msgpack" }
+func (msgpackCodec) Marshal(v any) ([]byte, error)      { return msgpack.Marshal(v) }
+func (msgpackCodec) Unmarshal(data []byte, v any) error { return msgpack.Unmarshal(data, v) }
+
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string        { return "protobuf" }
+func (protobufCodec) ContentType() string { return "application/x-protobuf" }
+
+func (protobufCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a proto.Message", ErrUnsupportedType, v)
+	}
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T is not a proto.Message", ErrUnsupportedType, v)
+	}
+	return proto.Unmarshal(data, m)
+}
@@ -0,0 +1,75 @@
+package codec
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		accept  string
+		allowed []Codec
+		want    Codec
+	}{
+		{"", nil, JSON},
+		{"", []Codec{MsgPack, JSON}, MsgPack},
+		{"application/json", []Codec{MsgPack, JSON}, JSON},
+		{"application/x-msgpack", []Codec{JSON, MsgPack}, MsgPack},
+		{"application/json;q=0.5, application/msgpack", []Codec{JSON, MsgPack}, MsgPack},
+		{"*/*, application/msgpack", []Codec{JSON, MsgPack}, MsgPack},
+		{"*/*", []Codec{Protobuf, JSON}, Protobuf},
+		{"application/*;q=0.1, text/html", []Codec{JSON}, JSON},
+		{"application/protobuf", []Codec{JSON, Protobuf}, Protobuf},
+		{"text/html", []Codec{JSON}, nil},
+		{"application/msgpack;q=0", []Codec{MsgPack}, nil},
+	}
+	for _, tc := range cases {
+		if got := Negotiate(tc.accept, tc.allowed...); got != tc.want {
+			t.Errorf("Negotiate(%q) = %v, want %v", tc.accept, got, tc.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if c, ok := ForContentType("application/json; charset=utf-8"); !ok || c != JSON {
+		t.Fatalf("ForContentType json = %v, %v", c, ok)
+	}
+	if c, ok := Match("Application/X-Protobuf", JSON, Protobuf); !ok || c != Protobuf {
+		t.Fatalf("Match protobuf = %v, %v", c, ok)
+	}
+	if _, ok := Match("application/msgpack", JSON); ok {
+		t.Fatal("msgpack should not match json-only route")
+	}
+	if _, err := ByNames("json", "yaml"); err == nil {
+		t.Fatal("expected unknown codec error")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	type item struct {
+		Name  string `msgpack:"name"`
+		Count int    `msgpack:"count"`
+	}
+	data, err := MsgPack.Marshal(item{Name: "a", Count: 3})
+	if err != nil {
+		t.Fatalf("msgpack marshal: %v", err)
+	}
+	var got item
+	if err := MsgPack.Unmarshal(data, &got); err != nil || got.Count != 3 {
+		t.Fatalf("msgpack unmarshal = %+v, %v", got, err)
+	}
+
+	data, err = Protobuf.Marshal(wrapperspb.String("hello"))
+	if err != nil {
+		t.Fatalf("protobuf marshal: %v", err)
+	}
+	var msg wrapperspb.StringValue
+	if err := Protobuf.Unmarshal(data, &msg); err != nil || msg.GetValue() != "hello" {
+		t.Fatalf("protobuf unmarshal = %v, %v", msg.GetValue(), err)
+	}
+	if _, err := Protobuf.Marshal(item{}); !errors.Is(err, ErrUnsupportedType) {
+		t.Fatalf("non-proto marshal err = %v", err)
+	}
+}
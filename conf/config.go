@@ -79,6 +79,15 @@ func NewLoaderWithEnvPrefix(configPath, configName, configType, envPrefix string
 }
 
 func (l *viperLoader) Load(config any) error {
+	v, err := l.read()
+	if err != nil {
+		return err
+	}
+	return v.Unmarshal(config, yamlTag)
+}
+
+// read 定位并读取配置文件，返回已展开环境变量占位符的 viper 实例
+func (l *viperLoader) read() (*viper.Viper, error) {
 	// 先让 viper 帮我们定位配置文件（支持 AddConfigPath + SetConfigName 的搜索逻辑）
 	finder := viper.New()
 	finder.AddConfigPath(l.configPath)
@@ -91,7 +100,7 @@ func (l *viperLoader) Load(config any) error {
 
 	if err := finder.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return err
+			return nil, err
 		}
 	}
 	configFile := finder.ConfigFileUsed()
@@ -105,17 +114,19 @@ func (l *viperLoader) Load(config any) error {
 	if configFile != "" {
 		raw, err := os.ReadFile(configFile)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		expanded := expandEnvPlaceholders(string(raw))
 
 		v.SetConfigType(l.configType)
 		if err := v.ReadConfig(bytes.NewBufferString(expanded)); err != nil {
-			return err
+			return nil, err
 		}
 	}
+	return v, nil
+}
 
-	return v.Unmarshal(config, func(dc *mapstructure.DecoderConfig) {
-		dc.TagName = "yaml"
-	})
+// yamlTag 解码时使用 yaml 标签（与配置结构体定义一致）
+func yamlTag(dc *mapstructure.DecoderConfig) {
+	dc.TagName = "yaml"
 }
@@ -0,0 +1,83 @@
+package conf
+
+import (
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+/* ========================================================================
+ * Config Source - 按节解码的配置源
+ * ========================================================================
+ * 职责: 一次性加载整份配置，各模块按 key 解码自己的配置节
+ * 语义:
+ *   - 加载规则同 Loader：${VAR} / ${VAR:-default} 展开 + 环境变量覆盖（已出现在文件中的键）
+ *   - 配置节缺失时保持默认值不变，仅覆盖文件中出现的字段
+ *
+ * 使用示例:
+ *   src, _ := conf.LoadSource("./configs", "config", "yaml", "APP")
+ *   fx.New(
+ *       fx.Supply(fx.Annotate(src, fx.As(new(conf.Source)))),
+ *       fx.Provide(conf.Section("mysql", mysql.DefaultConfig)),
+ *   )
+ * ======================================================================== */
+
+// Source 配置源
+type Source interface {
+	// Unmarshal 将 key 对应的配置节解码到 out（key 为空时解码整份配置）
+	// 配置节不存在时不修改 out
+	Unmarshal(key string, out any) error
+}
+
+type viperSource struct {
+	v *viper.Viper
+}
+
+// LoadSource 加载配置文件为 Source，配置文件不存在时返回空配置源
+func LoadSource(configPath, configName, configType, envPrefix string) (Source, error) {
+	l := &viperLoader{
+		configPath: configPath,
+		configName: configName,
+		configType: configType,
+		envPrefix:  envPrefix,
+	}
+	v, err := l.read()
+	if err != nil {
+		return nil, err
+	}
+	// 固化环境变量覆盖后的结果，使按节解码也能读取到覆盖值
+	return NewMapSource(v.AllSettings()), nil
+}
+
+// NewMapSource 基于嵌套 map 创建配置源（常用于测试）
+func NewMapSource(values map[string]any) Source {
+	v := viper.New()
+	_ = v.MergeConfigMap(values)
+	return &viperSource{v: v}
+}
+
+func (s *viperSource) Unmarshal(key string, out any) error {
+	if key == "" {
+		return s.v.Unmarshal(out, yamlTag)
+	}
+	if !s.v.IsSet(key) {
+		return nil
+	}
+	return s.v.UnmarshalKey(key, out, yamlTag)
+}
+
+// Section 返回从配置源 key 节解码配置的构造函数（可直接用于 fx.Provide）
+// defaults 提供默认值，配置中未出现的字段保留默认值；defaults 为 nil 时从零值开始
+func Section[T any](key string, defaults func() T) func(Source) (T, error) {
+	return func(src Source) (T, error) {
+		var cfg T
+		if defaults != nil {
+			cfg = defaults()
+		}
+		if err := src.Unmarshal(key, &cfg); err != nil {
+			var zero T
+			return zero, fmt.Errorf("decode config section %q: %w", key, err)
+		}
+		return cfg, nil
+	}
+}
@@ -0,0 +1,126 @@
+package ctxutil
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/metrics"
+)
+
+/* ========================================================================
+ * Context Deadline - 截止时间传播与检查
+ * ========================================================================
+ * 职责: 保证入站请求与下游 IO 调用的 context 带有截止时间，避免无 deadline 的调用卡死 goroutine
+ * 组成:
+ *   - WithTimeoutIfNone: ctx 未设置 deadline 时附加默认超时（已有 deadline 时保持不变）
+ *   - RequireDeadline: 仓储 / MQ 发送 / Redis 命令执行前检查 ctx，按 Mode 处理缺失 deadline 的调用
+ *   - WithoutDeadlineCheck: 标记长生命周期的后台循环（如阻塞取任务），跳过检查
+ * 模式（进程级，默认 off）:
+ *   - off      不检查（兼容现有行为）
+ *   - warn     计数 app_ctx_missing_deadline_total{operation}，调用照常执行
+ *   - enforce  计数并拒绝调用，返回 ErrNoDeadline（生产环境推荐）
+ * 入站:
+ *   - HTTP: transport/http 的 request_timeout（默认 30s）为每个请求设置 deadline
+ *   - gRPC: transport/grpc 的 request_timeout（默认 30s）为未携带 deadline 的 Unary 请求设置 deadline，
+ *     Stream 为长连接，不设置
+ *
+ * 使用示例:
+ *   // 配置（ctxutil.Bundle 从 ctxutil 节解码）
+ *   ctxutil:
+ *     deadline_mode: enforce
+ *
+ *   // 后台任务自行设置超时
+ *   ctx, cancel := ctxutil.WithTimeoutIfNone(ctx, 5*time.Second)
+ *   defer cancel()
+ *   err := repo.Create(ctx, order)
+ *
+ *   // 自定义 IO 组件接入检查
+ *   if err := ctxutil.RequireDeadline(ctx, "search"); err != nil {
+ *       return err
+ *   }
+ * ======================================================================== */
+
+// Mode 缺失 deadline 时的处理模式
+type Mode string
+
+const (
+	// ModeOff 不检查
+	ModeOff Mode = "off"
+	// ModeWarn 仅计数
+	ModeWarn Mode = "warn"
+	// ModeEnforce 计数并拒绝调用
+	ModeEnforce Mode = "enforce"
+)
+
+// ErrNoDeadline 调用 context 未设置截止时间（enforce 模式）
+var ErrNoDeadline = errors.New(errors.ErrCodeInternal, "context has no deadline")
+
+// MissingDeadlineTotal 缺失 deadline 的调用次数
+var MissingDeadlineTotal = metrics.NewCounter("app", "ctx", "missing_deadline_total",
+	"Total number of downstream calls made with a context without deadline", []string{"operation"})
+
+var mode atomic.Value
+
+func init() {
+	mode.Store(ModeOff)
+}
+
+// SetMode 设置进程级检查模式，未知模式返回错误
+func SetMode(m Mode) error {
+	switch m {
+	case "":
+		m = ModeOff
+	case ModeOff, ModeWarn, ModeEnforce:
+	default:
+		return fmt.Errorf("ctxutil: unknown deadline mode %q", m)
+	}
+	mode.Store(m)
+	return nil
+}
+
+// CurrentMode 返回当前检查模式
+func CurrentMode() Mode {
+	return mode.Load().(Mode)
+}
+
+// WithTimeoutIfNone ctx 未设置 deadline 时附加超时 d；已有 deadline 或 d <= 0 时原样返回
+func WithTimeoutIfNone(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || d <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, d)
+}
+
+// exemptKey 跳过检查标记的键
+type exemptKey struct{}
+
+// WithoutDeadlineCheck 标记 ctx 跳过 deadline 检查（用于长生命周期的后台循环）
+func WithoutDeadlineCheck(ctx context.Context) context.Context {
+	return context.WithValue(ctx, exemptKey{}, true)
+}
+
+// Exempt ctx 是否已标记跳过检查
+func Exempt(ctx context.Context) bool {
+	exempt, _ := ctx.Value(exemptKey{}).(bool)
+	return exempt
+}
+
+// RequireDeadline 检查 ctx 是否带有 deadline，op 为计数标签（如 repository、redis、mq.send）
+// warn 模式仅计数；enforce 模式计数并返回 ErrNoDeadline
+func RequireDeadline(ctx context.Context, op string) error {
+	m := CurrentMode()
+	if m == ModeOff {
+		return nil
+	}
+	if _, ok := ctx.Deadline(); ok || Exempt(ctx) {
+		return nil
+	}
+	MissingDeadlineTotal.WithLabelValues(op).Inc()
+	if m == ModeEnforce {
+		return ErrNoDeadline.WithDetail("operation", op)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package ctxutil
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func setMode(t *testing.T, m Mode) {
+	t.Helper()
+	if err := SetMode(m); err != nil {
+		t.Fatalf("set mode: %v", err)
+	}
+	t.Cleanup(func() { _ = SetMode(ModeOff) })
+}
+
+func TestWithTimeoutIfNone(t *testing.T) {
+	ctx, cancel := WithTimeoutIfNone(context.Background(), time.Minute)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > time.Minute {
+		t.Fatalf("deadline = %v, %v; want within 1m", deadline, ok)
+	}
+
+	// 已有 deadline 时保持不变（不延长也不缩短）
+	parent, cancelParent := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelParent()
+	got, cancel2 := WithTimeoutIfNone(parent, time.Second)
+	defer cancel2()
+	if got != parent {
+		t.Fatal("ctx with deadline was replaced")
+	}
+
+	if got, cancel3 := WithTimeoutIfNone(context.Background(), 0); got != context.Background() {
+		t.Fatal("d <= 0 should keep ctx")
+	} else {
+		cancel3()
+	}
+}
+
+func TestRequireDeadline(t *testing.T) {
+	bg := context.Background()
+	if err := RequireDeadline(bg, "test.off"); err != nil {
+		t.Fatalf("off: %v", err)
+	}
+
+	setMode(t, ModeWarn)
+	before := testutil.ToFloat64(MissingDeadlineTotal.WithLabelValues("test.warn"))
+	if err := RequireDeadline(bg, "test.warn"); err != nil {
+		t.Fatalf("warn: %v", err)
+	}
+	if got := testutil.ToFloat64(MissingDeadlineTotal.WithLabelValues("test.warn")); got != before+1 {
+		t.Fatalf("counter = %v, want %v", got, before+1)
+	}
+
+	setMode(t, ModeEnforce)
+	err := RequireDeadline(bg, "test.enforce")
+	if bizErr, ok := errors.AsBizError(err); !ok || bizErr.Code != errors.ErrCodeInternal || bizErr.Details["operation"] != "test.enforce" {
+		t.Fatalf("enforce err = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(bg, time.Second)
+	defer cancel()
+	if err := RequireDeadline(ctx, "test.enforce"); err != nil {
+		t.Fatalf("with deadline: %v", err)
+	}
+	if err := RequireDeadline(WithoutDeadlineCheck(bg), "test.enforce"); err != nil {
+		t.Fatalf("exempt: %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Cleanup(func() { _ = SetMode(ModeOff) })
+	if err := Apply(Config{DeadlineMode: "strict"}); err == nil {
+		t.Fatal("unknown mode accepted")
+	}
+	if err := Apply(Config{DeadlineMode: ModeWarn}); err != nil || CurrentMode() != ModeWarn {
+		t.Fatalf("apply warn: err=%v mode=%s", err, CurrentMode())
+	}
+	if err := Apply(Config{}); err != nil || CurrentMode() != ModeOff {
+		t.Fatalf("apply empty: err=%v mode=%s", err, CurrentMode())
+	}
+}
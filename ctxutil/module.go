@@ -0,0 +1,44 @@
+package ctxutil
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/conf"
+)
+
+/* ========================================================================
+ * Ctxutil Module
+ * ========================================================================
+ * 职责: 启动时按配置设置进程级 deadline 检查模式
+ * Bundle: 从 conf.Source 的 ctxutil 节解码 Config（缺省见 DefaultConfig）
+ * ======================================================================== */
+
+// Config 配置
+type Config struct {
+	// DeadlineMode 缺失 deadline 时的处理模式: off / warn / enforce，默认 off
+	DeadlineMode Mode `yaml:"deadline_mode"`
+}
+
+// DefaultConfig 返回默认配置（不检查）
+func DefaultConfig() Config {
+	return Config{DeadlineMode: ModeOff}
+}
+
+// Apply 应用配置，未知模式返回错误（阻止启动）
+func Apply(cfg Config) error {
+	return SetMode(cfg.DeadlineMode)
+}
+
+// Module ctxutil 模块
+// 依赖: ctxutil.Config
+var Module = fx.Module("ctxutil",
+	fx.Invoke(Apply),
+)
+
+// Bundle 带配置解码的 ctxutil 模块
+// 依赖: conf.Source
+// 提供: ctxutil.Config
+var Bundle = fx.Module("ctxutil-bundle",
+	fx.Provide(conf.Section("ctxutil", DefaultConfig)),
+	Module,
+)
@@ -2,12 +2,15 @@ package mysql
 
 import (
 	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/conf"
 )
 
 /* ========================================================================
  * MySQL Module
  * ========================================================================
  * 职责: 提供 MySQL 依赖注入模块
+ * Bundle: 从 conf.Source 的 mysql 节解码 Config（缺省见 DefaultConfig）
  * ======================================================================== */
 
 // Module MySQL 模块
@@ -15,3 +18,11 @@ import (
 var Module = fx.Module("mysql",
 	fx.Provide(NewDB),
 )
+
+// Bundle 带配置解码的 MySQL 模块
+// 依赖: conf.Source, *logger.Logger
+// 提供: mysql.Config, *gorm.DB
+var Bundle = fx.Module("mysql-bundle",
+	fx.Provide(conf.Section("mysql", DefaultConfig)),
+	Module,
+)
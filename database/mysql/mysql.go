@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/aisgo/ais-go-pkg/clock"
 	"github.com/aisgo/ais-go-pkg/database"
 	"github.com/aisgo/ais-go-pkg/logger"
 
@@ -50,12 +51,28 @@ type Config struct {
 	ConnMaxIdleTime  time.Duration `yaml:"conn_max_idle_time"` // 空闲连接最大时间
 }
 
+// DefaultConfig 返回默认配置（本机 3306，连接池使用默认值）
+func DefaultConfig() Config {
+	return Config{
+		Host:            "127.0.0.1",
+		Port:            3306,
+		Charset:         DefaultCharset,
+		ParseTime:       true,
+		Loc:             DefaultLoc,
+		MaxIdleConns:    DefaultMaxIdleConns,
+		MaxOpenConns:    DefaultMaxOpenConns,
+		ConnMaxLifetime: DefaultConnMaxLifetime,
+		ConnMaxIdleTime: DefaultConnMaxIdleTime,
+	}
+}
+
 // Params 依赖注入参数
 type Params struct {
 	fx.In
 	Lc     fx.Lifecycle
 	Config Config
 	Logger *logger.Logger
+	Clock  clock.Clock `optional:"true"` // GORM 自动时间戳（NowFunc）来源，默认真实时钟
 }
 
 // NewDB 初始化 MySQL 连接
@@ -64,6 +81,7 @@ func NewDB(p Params) (*gorm.DB, error) {
 	if log == nil {
 		log = logger.NewNop()
 	}
+	now := clock.OrReal(p.Clock)
 	// 设置默认值
 	charset := p.Config.Charset
 	if charset == "" {
@@ -100,7 +118,7 @@ func NewDB(p Params) (*gorm.DB, error) {
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
 		Logger: gormLog,
 		NowFunc: func() time.Time {
-			return time.Now().Local()
+			return now.Now().Local()
 		},
 	})
 	if err != nil {
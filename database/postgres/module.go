@@ -2,12 +2,15 @@ package postgres
 
 import (
 	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/conf"
 )
 
 /* ========================================================================
  * PostgreSQL Module
  * ========================================================================
  * 职责: 提供 PostgreSQL 依赖注入模块
+ * Bundle: 从 conf.Source 的 postgres 节解码 Config（缺省见 DefaultConfig）
  * ======================================================================== */
 
 // Module PostgreSQL 模块
@@ -15,3 +18,11 @@ import (
 var Module = fx.Module("postgres",
 	fx.Provide(NewDB),
 )
+
+// Bundle 带配置解码的 PostgreSQL 模块
+// 依赖: conf.Source, *logger.Logger
+// 提供: postgres.Config, *gorm.DB
+var Bundle = fx.Module("postgres-bundle",
+	fx.Provide(conf.Section("postgres", DefaultConfig)),
+	Module,
+)
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/aisgo/ais-go-pkg/clock"
 	"github.com/aisgo/ais-go-pkg/database"
 	"github.com/aisgo/ais-go-pkg/logger"
 
@@ -45,12 +46,26 @@ type Config struct {
 	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"` // 空闲连接最大时间
 }
 
+// DefaultConfig 返回默认配置（本机 5432，sslmode=disable，连接池使用默认值）
+func DefaultConfig() Config {
+	return Config{
+		Host:            "127.0.0.1",
+		Port:            5432,
+		SSLMode:         "disable",
+		MaxIdleConns:    DefaultMaxIdleConns,
+		MaxOpenConns:    DefaultMaxOpenConns,
+		ConnMaxLifetime: DefaultConnMaxLifetime,
+		ConnMaxIdleTime: DefaultConnMaxIdleTime,
+	}
+}
+
 // Params 依赖注入参数
 type Params struct {
 	fx.In
 	Lc     fx.Lifecycle
 	Config Config
 	Logger *logger.Logger
+	Clock  clock.Clock `optional:"true"` // GORM 自动时间戳（NowFunc）来源，默认真实时钟
 }
 
 // NewDB 初始化 Postgres 连接
@@ -59,21 +74,48 @@ func NewDB(p Params) (*gorm.DB, error) {
 	if log == nil {
 		log = logger.NewNop()
 	}
-	sslMode := p.Config.SSLMode
+	db, err := Open(p.Config, log, p.Clock)
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// 注册生命周期钩子
+	p.Lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			log.Info("Closing PostgreSQL connection pool", zap.String("db", p.Config.DBName))
+			return sqlDB.Close()
+		},
+	})
+
+	return db, nil
+}
+
+// Open 按配置打开 Postgres 连接池（不注册生命周期，由调用方关闭）
+// c 为 nil 时使用真实时钟
+func Open(cfg Config, log *logger.Logger, c clock.Clock) (*gorm.DB, error) {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	now := clock.OrReal(c)
+	sslMode := cfg.SSLMode
 	if sslMode == "" {
 		sslMode = "disable"
 	}
 
 	u := &url.URL{
 		Scheme: "postgres",
-		User:   url.UserPassword(p.Config.User, p.Config.Password),
-		Host:   fmt.Sprintf("%s:%d", p.Config.Host, p.Config.Port),
-		Path:   p.Config.DBName,
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Path:   cfg.DBName,
 	}
 	q := u.Query()
 	q.Set("sslmode", sslMode)
-	if p.Config.Schema != "" {
-		q.Set("search_path", p.Config.Schema)
+	if cfg.Schema != "" {
+		q.Set("search_path", cfg.Schema)
 	}
 	u.RawQuery = q.Encode()
 	dsn := u.String()
@@ -87,7 +129,7 @@ func NewDB(p Params) (*gorm.DB, error) {
 	}), &gorm.Config{
 		Logger: gormLog,
 		NowFunc: func() time.Time {
-			return time.Now().Local()
+			return now.Now().Local()
 		},
 	})
 	if err != nil {
@@ -100,22 +142,22 @@ func NewDB(p Params) (*gorm.DB, error) {
 	}
 
 	// 连接池配置（应用默认值）
-	maxIdleConns := p.Config.MaxIdleConns
+	maxIdleConns := cfg.MaxIdleConns
 	if maxIdleConns <= 0 {
 		maxIdleConns = DefaultMaxIdleConns
 	}
 
-	maxOpenConns := p.Config.MaxOpenConns
+	maxOpenConns := cfg.MaxOpenConns
 	if maxOpenConns <= 0 {
 		maxOpenConns = DefaultMaxOpenConns
 	}
 
-	connMaxLifetime := p.Config.ConnMaxLifetime
+	connMaxLifetime := cfg.ConnMaxLifetime
 	if connMaxLifetime <= 0 {
 		connMaxLifetime = DefaultConnMaxLifetime
 	}
 
-	connMaxIdleTime := p.Config.ConnMaxIdleTime
+	connMaxIdleTime := cfg.ConnMaxIdleTime
 	if connMaxIdleTime <= 0 {
 		connMaxIdleTime = DefaultConnMaxIdleTime
 	}
@@ -125,14 +167,6 @@ func NewDB(p Params) (*gorm.DB, error) {
 	sqlDB.SetConnMaxLifetime(connMaxLifetime)
 	sqlDB.SetConnMaxIdleTime(connMaxIdleTime)
 
-	// 注册生命周期钩子
-	p.Lc.Append(fx.Hook{
-		OnStop: func(ctx context.Context) error {
-			log.Info("Closing PostgreSQL connection pool", zap.String("db", p.Config.DBName))
-			return sqlDB.Close()
-		},
-	})
-
 	return db, nil
 }
 
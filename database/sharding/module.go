@@ -0,0 +1,104 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/conf"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+/* ========================================================================
+ * Sharding Module
+ * ========================================================================
+ * 职责: 提供分库依赖注入模块
+ * 依赖: *gorm.DB 作为控制库（租户分配表、不区分租户的模型），通常由 postgres.Bundle 提供
+ * Bundle: 从 conf.Source 的 sharding 节解码 Config（缺省见 DefaultConfig）
+ * 说明: 业务模型通过 Provisioner.AddModels 注册开通时需要迁移的表结构
+ * ======================================================================== */
+
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	Lc     fx.Lifecycle
+	Config Config
+	DB     *gorm.DB
+	Logger *logger.Logger `optional:"true"`
+	Clock  clock.Clock    `optional:"true"`
+}
+
+// Result 模块提供的组件
+type Result struct {
+	fx.Out
+
+	Registry    *Registry
+	Resolver    Resolver
+	Router      *Router
+	DBRouter    repository.DBRouter
+	Provisioner *Provisioner
+}
+
+// NewFromParams 从 FX 参数创建分库组件
+func NewFromParams(p Params) (Result, error) {
+	if err := p.Config.Validate(); err != nil {
+		return Result{}, err
+	}
+	log := p.Logger
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	var resolver Resolver
+	switch p.Config.Resolver {
+	case ResolverStatic, "":
+		r, err := NewStaticResolverFromConfig(p.Config)
+		if err != nil {
+			return Result{}, err
+		}
+		resolver = r
+	case ResolverTable:
+		resolver = NewTableResolver(p.DB, p.Config.Table, p.Config.CacheTTL)
+	default:
+		return Result{}, fmt.Errorf("sharding: unknown resolver %q", p.Config.Resolver)
+	}
+
+	registry := NewRegistry(PostgresOpener(p.Config.Shards, log, p.Clock))
+	p.Lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			log.Info("Closing shard connection pools", zap.Int("shards", len(p.Config.Shards)))
+			return registry.Close()
+		},
+	})
+
+	router := NewRouter(resolver, registry, p.DB)
+	return Result{
+		Registry: registry,
+		Resolver: resolver,
+		Router:   router,
+		DBRouter: router,
+		Provisioner: NewProvisioner(resolver, registry,
+			WithDefaultShard(p.Config.DefaultShard),
+			WithSchemaPrefix(p.Config.SchemaPrefix)),
+	}, nil
+}
+
+// Module 分库模块
+// 提供: *Registry, Resolver, *Router, repository.DBRouter, *Provisioner
+var Module = fx.Module("sharding",
+	fx.Provide(NewFromParams),
+)
+
+// Bundle 带配置解码的分库模块
+// 依赖: conf.Source, *gorm.DB
+// 提供: sharding.Config 及 Module 的全部组件
+var Bundle = fx.Module("sharding-bundle",
+	fx.Provide(conf.Section("sharding", DefaultConfig)),
+	Module,
+)
@@ -0,0 +1,156 @@
+package sharding
+
+import (
+	"context"
+	"strings"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+)
+
+/* ========================================================================
+ * Provisioner - 新租户开通
+ * ========================================================================
+ * 职责: 为新租户分配 Target、创建 schema、迁移表结构，最后登记到 Resolver
+ * 语义:
+ *   - 已分配的租户沿用现有 Target（幂等，可用于补齐新增模型的表结构）
+ *   - 未分配的租户分配到默认 shard，schema = 前缀 + 小写租户 ID（前缀为空时不分 schema）
+ *   - 登记在最后执行：中途失败时租户仍为未分配状态，可直接重试
+ *   - Resolver 需实现 Assigner（StaticResolver / TableResolver 均已实现）
+ *
+ * 使用示例:
+ *   p := sharding.NewProvisioner(resolver, registry,
+ *       sharding.WithDefaultShard("main"),
+ *       sharding.WithSchemaPrefix("tenant_"),
+ *       sharding.WithModels(&Order{}, &OrderItem{}))
+ *   target, err := p.Provision(ctx, tenantID)
+ * ======================================================================== */
+
+// SchemaCreator 在 shard 默认 schema 的连接上创建 schema
+type SchemaCreator func(ctx context.Context, db *gorm.DB, schema string) error
+
+// CreatePostgresSchema 创建 Postgres schema（已存在时忽略）
+func CreatePostgresSchema(ctx context.Context, db *gorm.DB, schema string) error {
+	return db.WithContext(ctx).Exec(`CREATE SCHEMA IF NOT EXISTS "` + schema + `"`).Error
+}
+
+// Provisioner 新租户开通
+type Provisioner struct {
+	resolver     Resolver
+	registry     *Registry
+	defaultShard string
+	schemaPrefix string
+	models       []any
+	createSchema SchemaCreator
+}
+
+// ProvisionOption 开通选项
+type ProvisionOption func(*Provisioner)
+
+// WithDefaultShard 设置新租户分配的物理库
+func WithDefaultShard(shard string) ProvisionOption {
+	return func(p *Provisioner) {
+		p.defaultShard = shard
+	}
+}
+
+// WithSchemaPrefix 设置新租户 schema 前缀（空值表示不分 schema）
+func WithSchemaPrefix(prefix string) ProvisionOption {
+	return func(p *Provisioner) {
+		p.schemaPrefix = prefix
+	}
+}
+
+// WithModels 追加开通时迁移的模型
+func WithModels(models ...any) ProvisionOption {
+	return func(p *Provisioner) {
+		p.models = append(p.models, models...)
+	}
+}
+
+// WithSchemaCreator 设置 schema 创建方式（默认 CreatePostgresSchema）
+func WithSchemaCreator(fn SchemaCreator) ProvisionOption {
+	return func(p *Provisioner) {
+		p.createSchema = fn
+	}
+}
+
+// NewProvisioner 创建新租户开通器
+func NewProvisioner(resolver Resolver, registry *Registry, opts ...ProvisionOption) *Provisioner {
+	p := &Provisioner{resolver: resolver, registry: registry, createSchema: CreatePostgresSchema}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(p)
+		}
+	}
+	return p
+}
+
+// AddModels 追加开通时迁移的模型（通常在应用启动时由各业务模块注册）
+func (p *Provisioner) AddModels(models ...any) {
+	p.models = append(p.models, models...)
+}
+
+// Provision 开通租户并返回其 Target
+func (p *Provisioner) Provision(ctx context.Context, tenantID ulidv2.ULID) (Target, error) {
+	if tenantID.IsZero() {
+		return Target{}, errors.ErrInvalidArgument
+	}
+
+	target, err := p.resolver.Resolve(ctx, tenantID)
+	assigned := err == nil
+	switch {
+	case assigned:
+	case errors.Is(err, ErrTenantNotAssigned):
+		if target, err = p.newTarget(tenantID); err != nil {
+			return Target{}, err
+		}
+	default:
+		return Target{}, err
+	}
+
+	if target.Schema != "" && p.createSchema != nil {
+		base, err := p.registry.Get(ctx, Target{Shard: target.Shard})
+		if err != nil {
+			return Target{}, err
+		}
+		if err := p.createSchema(ctx, base, target.Schema); err != nil {
+			return Target{}, errors.Wrap(errors.ErrCodeInternal, "failed to create schema "+target.Schema, err)
+		}
+	}
+
+	if len(p.models) > 0 {
+		db, err := p.registry.Get(ctx, target)
+		if err != nil {
+			return Target{}, err
+		}
+		if err := db.WithContext(ctx).AutoMigrate(p.models...); err != nil {
+			return Target{}, errors.Wrap(errors.ErrCodeInternal, "failed to migrate tenant "+tenantID.String(), err)
+		}
+	}
+
+	if !assigned {
+		assigner, ok := p.resolver.(Assigner)
+		if !ok {
+			return Target{}, errors.New(errors.ErrCodeInternal, "resolver does not support tenant assignment")
+		}
+		if err := assigner.Assign(ctx, tenantID, target); err != nil {
+			return Target{}, err
+		}
+	}
+	return target, nil
+}
+
+// newTarget 为新租户生成 Target
+func (p *Provisioner) newTarget(tenantID ulidv2.ULID) (Target, error) {
+	if p.defaultShard == "" {
+		return Target{}, errors.New(errors.ErrCodeInvalidArgument, "default shard is not configured")
+	}
+	target := Target{Shard: p.defaultShard}
+	if p.schemaPrefix != "" {
+		target.Schema = p.schemaPrefix + strings.ToLower(tenantID.String())
+	}
+	return target, target.Validate()
+}
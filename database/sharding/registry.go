@@ -0,0 +1,137 @@
+package sharding
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/database/postgres"
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/logger"
+)
+
+/* ========================================================================
+ * Registry - 连接注册表
+ * ========================================================================
+ * 职责: 按 Target 缓存 *gorm.DB，首次访问时通过 Opener 打开
+ * 语义:
+ *   - 同一 Target 并发首次访问只打开一次；打开失败不缓存，下次访问重试
+ *   - Register 登记外部创建的 DB（不随 Close 关闭，由创建方负责）
+ *   - Close 关闭 Registry 打开的全部连接池，之后的访问返回 Unavailable
+ * ======================================================================== */
+
+// Opener 打开 Target 对应的 DB
+type Opener func(ctx context.Context, target Target) (*gorm.DB, error)
+
+// registryEntry 连接项（ready 关闭后 db/err 可读）
+type registryEntry struct {
+	ready chan struct{}
+	db    *gorm.DB
+	err   error
+	owned bool
+}
+
+// Registry 连接注册表
+type Registry struct {
+	open Opener
+
+	mu      sync.Mutex
+	entries map[Target]*registryEntry
+	closed  bool
+}
+
+// NewRegistry 创建连接注册表
+func NewRegistry(open Opener) *Registry {
+	return &Registry{open: open, entries: make(map[Target]*registryEntry)}
+}
+
+// Register 登记外部创建的 DB（不随 Close 关闭）
+func (r *Registry) Register(target Target, db *gorm.DB) {
+	entry := &registryEntry{ready: make(chan struct{}), db: db}
+	close(entry.ready)
+	r.mu.Lock()
+	r.entries[target] = entry
+	r.mu.Unlock()
+}
+
+// Get 返回 Target 对应的 DB，未打开时通过 Opener 打开
+func (r *Registry) Get(ctx context.Context, target Target) (*gorm.DB, error) {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil, errors.New(errors.ErrCodeUnavailable, "shard registry is closed")
+	}
+	entry, ok := r.entries[target]
+	if !ok {
+		if r.open == nil {
+			r.mu.Unlock()
+			return nil, errors.New(errors.ErrCodeUnavailable, "shard is not registered: "+target.String())
+		}
+		entry = &registryEntry{ready: make(chan struct{}), owned: true}
+		r.entries[target] = entry
+		r.mu.Unlock()
+
+		// 打开连接不受调用方取消影响，避免一个请求的超时导致其他等待者失败
+		entry.db, entry.err = r.open(context.WithoutCancel(ctx), target)
+		if entry.err != nil {
+			r.mu.Lock()
+			delete(r.entries, target)
+			r.mu.Unlock()
+		}
+		close(entry.ready)
+	} else {
+		r.mu.Unlock()
+	}
+
+	select {
+	case <-entry.ready:
+	case <-ctx.Done():
+		return nil, errors.FromGORM(ctx.Err())
+	}
+	if entry.err != nil {
+		return nil, errors.Wrap(errors.ErrCodeUnavailable, "failed to open shard "+target.String(), entry.err)
+	}
+	return entry.db, nil
+}
+
+// Close 关闭 Registry 打开的全部连接池
+func (r *Registry) Close() error {
+	r.mu.Lock()
+	r.closed = true
+	entries := r.entries
+	r.entries = make(map[Target]*registryEntry)
+	r.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		<-entry.ready
+		if !entry.owned || entry.db == nil {
+			continue
+		}
+		sqlDB, err := entry.db.DB()
+		if err == nil {
+			err = sqlDB.Close()
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// PostgresOpener 按 shard 配置打开 Postgres 连接池，Target.Schema 作为 search_path
+func PostgresOpener(shards map[string]postgres.Config, log *logger.Logger, c clock.Clock) Opener {
+	return func(_ context.Context, target Target) (*gorm.DB, error) {
+		cfg, ok := shards[target.Shard]
+		if !ok {
+			return nil, errors.New(errors.ErrCodeInvalidArgument, "unknown shard: "+target.Shard)
+		}
+		if target.Schema != "" {
+			cfg.Schema = target.Schema
+		}
+		return postgres.Open(cfg, log, c)
+	}
+}
@@ -0,0 +1,168 @@
+package sharding
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+)
+
+/* ========================================================================
+ * Resolver - 租户映射
+ * ========================================================================
+ * StaticResolver: 配置文件中的固定映射，适合租户数量少、很少变化的部署
+ * TableResolver:  控制库中的租户分配表（tenant_id -> shard/schema），带本地缓存；
+ *                 Provisioner 开通新租户时写入，多实例部署时其他实例在缓存过期后可见
+ * ======================================================================== */
+
+var (
+	_ Resolver = (*StaticResolver)(nil)
+	_ Assigner = (*StaticResolver)(nil)
+	_ Resolver = (*TableResolver)(nil)
+	_ Assigner = (*TableResolver)(nil)
+)
+
+// StaticResolver 固定映射（并发安全，Assign 仅在当前进程内生效）
+type StaticResolver struct {
+	mu      sync.RWMutex
+	tenants map[ulidv2.ULID]Target
+}
+
+// NewStaticResolver 创建固定映射
+func NewStaticResolver(tenants map[ulidv2.ULID]Target) *StaticResolver {
+	m := make(map[ulidv2.ULID]Target, len(tenants))
+	for k, v := range tenants {
+		m[k] = v
+	}
+	return &StaticResolver{tenants: m}
+}
+
+// NewStaticResolverFromConfig 从配置的租户映射创建固定映射
+func NewStaticResolverFromConfig(cfg Config) (*StaticResolver, error) {
+	tenants := make(map[ulidv2.ULID]Target, len(cfg.Tenants))
+	for id, target := range cfg.Tenants {
+		tenant, err := ulidv2.ParseStrict(id)
+		if err != nil {
+			return nil, errors.Wrap(errors.ErrCodeInvalidArgument, "invalid tenant id: "+id, err)
+		}
+		tenants[tenant] = target
+	}
+	return NewStaticResolver(tenants), nil
+}
+
+// Resolve 返回租户的 Target
+func (r *StaticResolver) Resolve(_ context.Context, tenantID ulidv2.ULID) (Target, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	target, ok := r.tenants[tenantID]
+	if !ok {
+		return Target{}, ErrTenantNotAssigned
+	}
+	return target, nil
+}
+
+// Assign 登记租户的 Target
+func (r *StaticResolver) Assign(_ context.Context, tenantID ulidv2.ULID, target Target) error {
+	if err := target.Validate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tenants[tenantID] = target
+	return nil
+}
+
+// TenantShard 租户分配表记录
+type TenantShard struct {
+	TenantID   ulidv2.ULID `gorm:"column:tenant_id;type:char(26);primaryKey"`
+	Shard      string      `gorm:"column:shard;size:64;not null"`
+	SchemaName string      `gorm:"column:schema_name;size:63;not null;default:''"`
+	CreateTime time.Time   `gorm:"column:create_time;autoCreateTime"`
+}
+
+// cachedTarget 缓存项
+type cachedTarget struct {
+	target  Target
+	expires time.Time
+}
+
+// TableResolver 基于控制库租户分配表的映射
+type TableResolver struct {
+	db    *gorm.DB
+	table string
+	ttl   time.Duration
+
+	mu    sync.RWMutex
+	cache map[ulidv2.ULID]cachedTarget
+}
+
+// NewTableResolver 创建租户分配表映射
+// table 为空时使用 DefaultTable；ttl <= 0 时不缓存
+func NewTableResolver(db *gorm.DB, table string, ttl time.Duration) *TableResolver {
+	if table == "" {
+		table = DefaultTable
+	}
+	return &TableResolver{db: db, table: table, ttl: ttl, cache: make(map[ulidv2.ULID]cachedTarget)}
+}
+
+// Migrate 创建租户分配表
+func (r *TableResolver) Migrate(ctx context.Context) error {
+	return r.db.WithContext(ctx).Table(r.table).AutoMigrate(&TenantShard{})
+}
+
+// Resolve 返回租户的 Target（优先读取缓存）
+func (r *TableResolver) Resolve(ctx context.Context, tenantID ulidv2.ULID) (Target, error) {
+	now := time.Now()
+	r.mu.RLock()
+	c, ok := r.cache[tenantID]
+	r.mu.RUnlock()
+	if ok && now.Before(c.expires) {
+		return c.target, nil
+	}
+
+	var row TenantShard
+	err := r.db.WithContext(ctx).Table(r.table).Where("tenant_id = ?", tenantID).Take(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return Target{}, ErrTenantNotAssigned
+	}
+	if err != nil {
+		return Target{}, errors.FromGORM(err)
+	}
+
+	target := Target{Shard: row.Shard, Schema: row.SchemaName}
+	if r.ttl > 0 {
+		r.mu.Lock()
+		r.cache[tenantID] = cachedTarget{target: target, expires: now.Add(r.ttl)}
+		r.mu.Unlock()
+	}
+	return target, nil
+}
+
+// Assign 登记租户的 Target（已存在时覆盖）
+func (r *TableResolver) Assign(ctx context.Context, tenantID ulidv2.ULID, target Target) error {
+	if err := target.Validate(); err != nil {
+		return err
+	}
+	row := TenantShard{TenantID: tenantID, Shard: target.Shard, SchemaName: target.Schema}
+	err := r.db.WithContext(ctx).Table(r.table).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"shard", "schema_name"}),
+	}).Create(&row).Error
+	if err != nil {
+		return errors.FromGORM(err)
+	}
+	r.Invalidate(tenantID)
+	return nil
+}
+
+// Invalidate 清除租户的缓存
+func (r *TableResolver) Invalidate(tenantID ulidv2.ULID) {
+	r.mu.Lock()
+	delete(r.cache, tenantID)
+	r.mu.Unlock()
+}
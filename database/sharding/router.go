@@ -0,0 +1,59 @@
+package sharding
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+/* ========================================================================
+ * Router - 按租户选择物理库
+ * ========================================================================
+ * 职责: 实现 repository.DBRouter，将 ctx 中的租户经 Resolver 映射为 Target，
+ *       再从 Registry 获取对应 DB
+ * 错误:
+ *   - ctx 缺少租户上下文: Unauthenticated
+ *   - 租户未分配: NotFound（ErrTenantNotAssigned）
+ *   - 物理库打开失败: Unavailable
+ * ======================================================================== */
+
+var _ repository.DBRouter = (*Router)(nil)
+
+// Router 按租户选择物理库
+type Router struct {
+	resolver Resolver
+	registry *Registry
+	control  *gorm.DB
+}
+
+// NewRouter 创建租户路由
+// control 为控制库，用于解析模型与读写不区分租户的模型
+func NewRouter(resolver Resolver, registry *Registry, control *gorm.DB) *Router {
+	return &Router{resolver: resolver, registry: registry, control: control}
+}
+
+// Default 返回控制库
+func (r *Router) Default() *gorm.DB {
+	return r.control
+}
+
+// DB 返回 ctx 中租户对应的 DB
+func (r *Router) DB(ctx context.Context) (*gorm.DB, error) {
+	target, err := r.Target(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return r.registry.Get(ctx, target)
+}
+
+// Target 返回 ctx 中租户对应的 Target
+func (r *Router) Target(ctx context.Context) (Target, error) {
+	tc, ok := repository.TenantFromContext(ctx)
+	if !ok || tc.TenantID.IsZero() {
+		return Target{}, errors.ErrUnauthenticated
+	}
+	return r.resolver.Resolve(ctx, tc.TenantID)
+}
@@ -0,0 +1,142 @@
+package sharding
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+
+	"github.com/aisgo/ais-go-pkg/database/postgres"
+	"github.com/aisgo/ais-go-pkg/errors"
+)
+
+/* ========================================================================
+ * Sharding - 按租户分库 / 分 schema
+ * ========================================================================
+ * 职责: 将 TenantContext 映射到物理库（shard）与 schema，仓储按请求获取对应的 *gorm.DB
+ * 组成:
+ *   - Resolver: 租户 -> Target（StaticResolver 读配置，TableResolver 读控制库租户表）
+ *   - Registry: Target -> *gorm.DB 连接注册表（按需打开、并发安全、统一关闭）
+ *   - Router:   实现 repository.DBRouter，供 NewRoutedRepository / NewRoutedTxManager 使用
+ *   - Provisioner: 新租户开通时分配 Target、创建 schema、迁移表结构并登记
+ * 约定:
+ *   - Target.Schema 为空表示使用物理库的默认 schema（多租户共享表，依靠 tenant_id 隔离）
+ *   - Postgres 每个 (shard, schema) 使用独立连接池（search_path），连接池参数沿用 shard 配置
+ *
+ * 配置示例:
+ *   sharding:
+ *     resolver: table          # static | table
+ *     default_shard: main      # 新租户分配的物理库
+ *     schema_prefix: tenant_   # 新租户 schema 前缀，空值表示不分 schema
+ *     shards:
+ *       main:  {host: pg-main, port: 5432, user: app, dbname: app, max_open_conns: 5}
+ *       big:   {host: pg-big,  port: 5432, user: app, dbname: app}
+ *     tenants:                 # resolver=static 时的映射
+ *       01HZX...: {shard: big, schema: ""}
+ * ======================================================================== */
+
+// 租户映射来源
+const (
+	ResolverStatic = "static"
+	ResolverTable  = "table"
+)
+
+// 默认值
+const (
+	DefaultTable    = "tenant_shards"
+	DefaultCacheTTL = time.Minute
+)
+
+// ErrTenantNotAssigned 租户尚未分配物理库
+var ErrTenantNotAssigned = errors.New(errors.ErrCodeNotFound, "tenant is not assigned to a shard")
+
+// Target 租户数据所在的物理位置
+type Target struct {
+	// Shard 物理库名称（Config.Shards 的键）
+	Shard string `yaml:"shard"`
+	// Schema schema 名称，空值表示物理库默认 schema
+	Schema string `yaml:"schema"`
+}
+
+// String 返回 shard/schema 形式的描述
+func (t Target) String() string {
+	if t.Schema == "" {
+		return t.Shard
+	}
+	return t.Shard + "/" + t.Schema
+}
+
+// schemaPattern 允许的 schema 名称（小写标识符，避免拼接 DDL 时注入）
+var schemaPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// Validate 校验 Target
+func (t Target) Validate() error {
+	if t.Shard == "" {
+		return errors.New(errors.ErrCodeInvalidArgument, "shard is required")
+	}
+	if t.Schema != "" && !schemaPattern.MatchString(t.Schema) {
+		return errors.New(errors.ErrCodeInvalidArgument, "invalid schema name: "+t.Schema)
+	}
+	return nil
+}
+
+// Resolver 租户 -> Target 映射
+type Resolver interface {
+	// Resolve 返回租户的 Target，未分配时返回 ErrTenantNotAssigned
+	Resolve(ctx context.Context, tenantID ulidv2.ULID) (Target, error)
+}
+
+// Assigner 可选接口：登记新租户的 Target（Provisioner 使用）
+type Assigner interface {
+	Assign(ctx context.Context, tenantID ulidv2.ULID, target Target) error
+}
+
+// Config 分库配置
+type Config struct {
+	// Resolver 租户映射来源: static | table
+	Resolver string `yaml:"resolver"`
+	// Shards 物理库（名称 -> Postgres 配置）
+	Shards map[string]postgres.Config `yaml:"shards"`
+	// Tenants 静态映射（租户 ID -> Target），resolver=static 时使用
+	Tenants map[string]Target `yaml:"tenants"`
+	// DefaultShard 新租户开通时分配的物理库
+	DefaultShard string `yaml:"default_shard"`
+	// SchemaPrefix 新租户 schema 前缀（schema = 前缀 + 小写租户 ID），空值表示不分 schema
+	SchemaPrefix string `yaml:"schema_prefix"`
+	// Table 控制库中的租户分配表，resolver=table 时使用
+	Table string `yaml:"table"`
+	// CacheTTL 租户分配表查询结果缓存时间
+	CacheTTL time.Duration `yaml:"cache_ttl"`
+}
+
+// DefaultConfig 返回默认配置（静态映射，租户表 tenant_shards，缓存 1 分钟）
+func DefaultConfig() Config {
+	return Config{
+		Resolver: ResolverStatic,
+		Table:    DefaultTable,
+		CacheTTL: DefaultCacheTTL,
+	}
+}
+
+// Validate 校验配置
+func (c Config) Validate() error {
+	if c.DefaultShard != "" {
+		if _, ok := c.Shards[c.DefaultShard]; !ok {
+			return fmt.Errorf("sharding: default shard %q is not configured", c.DefaultShard)
+		}
+	}
+	for tenant, target := range c.Tenants {
+		if _, err := ulidv2.ParseStrict(tenant); err != nil {
+			return fmt.Errorf("sharding: invalid tenant id %q: %w", tenant, err)
+		}
+		if err := target.Validate(); err != nil {
+			return fmt.Errorf("sharding: tenant %s: %w", tenant, err)
+		}
+		if _, ok := c.Shards[target.Shard]; !ok {
+			return fmt.Errorf("sharding: tenant %s uses unknown shard %q", tenant, target.Shard)
+		}
+	}
+	return nil
+}
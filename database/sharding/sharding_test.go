@@ -0,0 +1,205 @@
+package sharding
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+type shardOrder struct {
+	ID       string      `gorm:"column:id;primaryKey"`
+	TenantID ulidv2.ULID `gorm:"column:tenant_id;type:char(26)"`
+	Amount   int         `gorm:"column:amount"`
+}
+
+// sqliteOpener 每个 Target 使用独立的 sqlite 文件
+func sqliteOpener(t *testing.T, opened *atomic.Int32) Opener {
+	dir := t.TempDir()
+	return func(_ context.Context, target Target) (*gorm.DB, error) {
+		opened.Add(1)
+		return gorm.Open(sqlite.Open(filepath.Join(dir, target.Shard+"_"+target.Schema+".db")), &gorm.Config{})
+	}
+}
+
+func openControl(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "control.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open control db: %v", err)
+	}
+	return db
+}
+
+func tenantCtx(tenant ulidv2.ULID) context.Context {
+	return repository.WithTenantContext(context.Background(), repository.TenantContext{TenantID: tenant, IsAdmin: true})
+}
+
+func expectCode(t *testing.T, err error, code errors.ErrorCode) {
+	t.Helper()
+	if got := errors.Code(err); got != code {
+		t.Fatalf("expected code %d, got %d (%v)", code, got, err)
+	}
+}
+
+func TestStaticRouting(t *testing.T) {
+	tenantA, tenantB := ulidv2.Make(), ulidv2.Make()
+	var opened atomic.Int32
+	registry := NewRegistry(sqliteOpener(t, &opened))
+	defer registry.Close()
+	resolver := NewStaticResolver(map[ulidv2.ULID]Target{
+		tenantA: {Shard: "main"},
+		tenantB: {Shard: "big"},
+	})
+	p := NewProvisioner(resolver, registry, WithModels(&shardOrder{}))
+	for _, tenant := range []ulidv2.ULID{tenantA, tenantB} {
+		if _, err := p.Provision(context.Background(), tenant); err != nil {
+			t.Fatalf("provision: %v", err)
+		}
+	}
+
+	router := NewRouter(resolver, registry, openControl(t))
+	repo := repository.NewRoutedRepository[shardOrder](router)
+	tm := repository.NewRoutedTxManager(router)
+
+	if err := repo.Create(tenantCtx(tenantA), &shardOrder{ID: "a1", Amount: 1}); err != nil {
+		t.Fatalf("create a: %v", err)
+	}
+	err := tm.RunInTx(tenantCtx(tenantB), func(ctx context.Context) error {
+		return repo.Create(ctx, &shardOrder{ID: "b1", Amount: 2})
+	})
+	if err != nil {
+		t.Fatalf("create b in tx: %v", err)
+	}
+
+	// 数据落在各自的物理库
+	for target, want := range map[Target]string{{Shard: "main"}: "a1", {Shard: "big"}: "b1"} {
+		db, err := registry.Get(context.Background(), target)
+		if err != nil {
+			t.Fatalf("get %s: %v", target, err)
+		}
+		var ids []string
+		if err := db.Model(&shardOrder{}).Pluck("id", &ids).Error; err != nil {
+			t.Fatalf("pluck: %v", err)
+		}
+		if len(ids) != 1 || ids[0] != want {
+			t.Fatalf("shard %s: expected [%s], got %v", target, want, ids)
+		}
+	}
+	if n, _ := repo.Count(tenantCtx(tenantA), ""); n != 1 {
+		t.Fatalf("expected 1 order for tenant A, got %d", n)
+	}
+	if opened.Load() != 2 {
+		t.Fatalf("expected each shard to be opened once, got %d", opened.Load())
+	}
+
+	// 缺少租户上下文 / 租户未分配
+	_, err = repo.FindByID(context.Background(), "a1")
+	expectCode(t, err, errors.ErrCodeUnauthenticated)
+	_, err = repo.Count(tenantCtx(ulidv2.Make()), "")
+	expectCode(t, err, errors.ErrCodeNotFound)
+	err = tm.RunInTx(tenantCtx(ulidv2.Make()), func(context.Context) error {
+		t.Fatal("fn must not run for an unassigned tenant")
+		return nil
+	})
+	expectCode(t, err, errors.ErrCodeNotFound)
+}
+
+func TestTableProvisioning(t *testing.T) {
+	control := openControl(t)
+	resolver := NewTableResolver(control, "", time.Minute)
+	if err := resolver.Migrate(context.Background()); err != nil {
+		t.Fatalf("migrate tenant table: %v", err)
+	}
+
+	var opened atomic.Int32
+	var schemas []string
+	registry := NewRegistry(sqliteOpener(t, &opened))
+	defer registry.Close()
+	p := NewProvisioner(resolver, registry,
+		WithDefaultShard("main"),
+		WithSchemaPrefix("tenant_"),
+		WithModels(&shardOrder{}),
+		WithSchemaCreator(func(_ context.Context, _ *gorm.DB, schema string) error {
+			schemas = append(schemas, schema)
+			return nil
+		}))
+
+	tenant := ulidv2.Make()
+	_, err := resolver.Resolve(context.Background(), tenant)
+	expectCode(t, err, errors.ErrCodeNotFound)
+
+	target, err := p.Provision(context.Background(), tenant)
+	if err != nil {
+		t.Fatalf("provision: %v", err)
+	}
+	want := Target{Shard: "main", Schema: "tenant_" + strings.ToLower(tenant.String())}
+	if target != want || len(schemas) != 1 || schemas[0] != want.Schema {
+		t.Fatalf("unexpected target %+v schemas %v", target, schemas)
+	}
+	if got, err := resolver.Resolve(context.Background(), tenant); err != nil || got != want {
+		t.Fatalf("resolve after provision: %+v %v", got, err)
+	}
+
+	// 重复开通沿用已有 Target
+	if again, err := p.Provision(context.Background(), tenant); err != nil || again != want {
+		t.Fatalf("re-provision: %+v %v", again, err)
+	}
+
+	repo := repository.NewRoutedRepository[shardOrder](NewRouter(resolver, registry, control))
+	if err := repo.Create(tenantCtx(tenant), &shardOrder{ID: "o1", Amount: 3}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if got, err := repo.FindByID(tenantCtx(tenant), "o1"); err != nil || got.Amount != 3 {
+		t.Fatalf("find: %+v %v", got, err)
+	}
+
+	// 改派后清除缓存立即生效
+	if err := resolver.Assign(context.Background(), tenant, Target{Shard: "big"}); err != nil {
+		t.Fatalf("assign: %v", err)
+	}
+	if got, _ := resolver.Resolve(context.Background(), tenant); got != (Target{Shard: "big"}) {
+		t.Fatalf("expected reassigned target, got %+v", got)
+	}
+	expectCode(t, resolver.Assign(context.Background(), tenant, Target{Shard: "big", Schema: `x"; drop`}), errors.ErrCodeInvalidArgument)
+}
+
+func TestRegistryClose(t *testing.T) {
+	var opened atomic.Int32
+	registry := NewRegistry(sqliteOpener(t, &opened))
+	external := openControl(t)
+	registry.Register(Target{Shard: "ext"}, external)
+	if _, err := registry.Get(context.Background(), Target{Shard: "main"}); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if err := registry.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	// 外部登记的 DB 不随 Registry 关闭
+	if err := external.Exec("SELECT 1").Error; err != nil {
+		t.Fatalf("external db must stay open: %v", err)
+	}
+	_, err := registry.Get(context.Background(), Target{Shard: "main"})
+	expectCode(t, err, errors.ErrCodeUnavailable)
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DefaultShard = "main"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for unknown default shard")
+	}
+	cfg.DefaultShard = ""
+	cfg.Tenants = map[string]Target{"not-a-ulid": {Shard: "main"}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for invalid tenant id")
+	}
+}
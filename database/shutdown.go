@@ -0,0 +1,34 @@
+package database
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/shutdown"
+)
+
+/* ========================================================================
+ * Database Shutdown - 接入优雅关停
+ * ========================================================================
+ * 职责: 将 Gorm 连接池注册到 shutdown.Manager (Priorities.Database)
+ * ======================================================================== */
+
+// RegisterShutdown 注册数据库连接池关停钩子
+func RegisterShutdown(m *shutdown.Manager, db *gorm.DB) {
+	m.RegisterHookWithPriority("database", shutdown.CloseHook(func() error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.Close()
+	}), *m.Priorities().Database)
+}
+
+// ShutdownModule 数据库关停集成模块
+// 需与 shutdown.Module 及 mysql / postgres 模块一起使用
+var ShutdownModule = fx.Module("database-shutdown",
+	fx.Invoke(func(lc fx.Lifecycle, m *shutdown.Manager, db *gorm.DB) {
+		RegisterShutdown(m, db)
+		m.ShutdownOnStop(lc)
+	}),
+)
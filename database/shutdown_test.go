@@ -0,0 +1,28 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/shutdown"
+)
+
+func TestRegisterShutdownClosesPool(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+
+	m := shutdown.NewManager(shutdown.ManagerParams{Logger: logger.NewNop()})
+	RegisterShutdown(m, db)
+	m.Shutdown(context.Background())
+
+	sqlDB, _ := db.DB()
+	if err := sqlDB.Ping(); err == nil {
+		t.Fatalf("expected database closed")
+	}
+}
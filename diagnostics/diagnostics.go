@@ -0,0 +1,309 @@
+package diagnostics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Diagnostics - 运行时资源监控
+ * ========================================================================
+ * 职责: 定期采样 goroutine 数、堆内存、GC 暂停、打开的文件描述符，
+ *       导出为 Prometheus Gauge，超过阈值时记录告警日志并可自动转储到对象存储
+ * 指标:
+ *   app_diagnostics_goroutines / heap_alloc_bytes / heap_inuse_bytes / heap_objects
+ *   app_diagnostics_gc_pause_max_seconds   采样间隔内最长 GC 暂停
+ *   app_diagnostics_open_fds               打开的文件描述符（读取 /proc/self/fd，不可用时为 -1）
+ *   app_diagnostics_threshold_breach_total{metric}
+ *   app_diagnostics_dump_total{result}
+ * 转储:
+ *   - 任一阈值被超过且距上次转储超过 cooldown 时，上传 heap profile（pprof 格式）与
+ *     goroutine 堆栈（文本）到 <prefix><instance>/<UTC 时间>-heap.pb.gz / -goroutine.txt
+ *   - 需要提供 storage.Bucket，未提供时仅记录日志
+ * 配置示例:
+ *   diagnostics:
+ *     enabled: true
+ *     interval: 15s
+ *     thresholds: {goroutines: 10000, heap_bytes: 2147483648, gc_pause: 100ms, open_fds: 50000}
+ *     dump: {enabled: true, prefix: diagnostics/, cooldown: 30m}
+ *
+ * 使用示例:
+ *   app.With(storage.Module, diagnostics.Bundle)
+ *
+ *   // 独立使用
+ *   m := diagnostics.New(cfg, bucket, log)
+ *   _ = m.Start(ctx)
+ *   defer m.Stop(ctx)
+ * ======================================================================== */
+
+// Config 监控配置
+type Config struct {
+	Enabled    bool          `yaml:"enabled"`
+	Interval   time.Duration `yaml:"interval"` // 采样间隔，默认 15s
+	Thresholds Thresholds    `yaml:"thresholds"`
+	Dump       DumpConfig    `yaml:"dump"`
+}
+
+// Thresholds 告警阈值，零值表示不告警
+type Thresholds struct {
+	Goroutines int           `yaml:"goroutines"` // goroutine 数量
+	HeapBytes  uint64        `yaml:"heap_bytes"` // 已分配堆内存（HeapAlloc）
+	GCPause    time.Duration `yaml:"gc_pause"`   // 采样间隔内最长 GC 暂停
+	OpenFDs    int           `yaml:"open_fds"`   // 打开的文件描述符数量
+}
+
+// DumpConfig 超过阈值时的自动转储配置
+type DumpConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Prefix   string        `yaml:"prefix"`   // 对象键前缀，默认 diagnostics/
+	Instance string        `yaml:"instance"` // 实例标识，默认 主机名-进程号
+	Cooldown time.Duration `yaml:"cooldown"` // 两次转储的最小间隔，默认 30m
+	Timeout  time.Duration `yaml:"timeout"`  // 单次上传超时，默认 1m
+}
+
+// DefaultConfig 返回默认配置（不启用，15s 采样，不告警）
+func DefaultConfig() Config {
+	return Config{Interval: 15 * time.Second}
+}
+
+func (c Config) withDefaults() Config {
+	if c.Interval <= 0 {
+		c.Interval = 15 * time.Second
+	}
+	if c.Dump.Prefix == "" {
+		c.Dump.Prefix = "diagnostics/"
+	}
+	if c.Dump.Instance == "" {
+		host, _ := os.Hostname()
+		c.Dump.Instance = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if c.Dump.Cooldown <= 0 {
+		c.Dump.Cooldown = 30 * time.Minute
+	}
+	if c.Dump.Timeout <= 0 {
+		c.Dump.Timeout = time.Minute
+	}
+	return c
+}
+
+// Sample 一次采样结果
+type Sample struct {
+	Time        time.Time
+	Goroutines  int
+	HeapAlloc   uint64
+	HeapInuse   uint64
+	HeapObjects uint64
+	NumGC       uint32
+	GCPauseMax  time.Duration // 自上次采样以来最长 GC 暂停
+	OpenFDs     int           // 不可用时为 -1
+}
+
+// Breach 超过阈值的指标
+type Breach struct {
+	Metric string
+	Value  float64
+	Limit  float64
+}
+
+// Breaches 返回 s 中超过阈值的指标
+func (t Thresholds) Breaches(s Sample) []Breach {
+	var out []Breach
+	if t.Goroutines > 0 && s.Goroutines > t.Goroutines {
+		out = append(out, Breach{Metric: "goroutines", Value: float64(s.Goroutines), Limit: float64(t.Goroutines)})
+	}
+	if t.HeapBytes > 0 && s.HeapAlloc > t.HeapBytes {
+		out = append(out, Breach{Metric: "heap_bytes", Value: float64(s.HeapAlloc), Limit: float64(t.HeapBytes)})
+	}
+	if t.GCPause > 0 && s.GCPauseMax > t.GCPause {
+		out = append(out, Breach{Metric: "gc_pause", Value: s.GCPauseMax.Seconds(), Limit: t.GCPause.Seconds()})
+	}
+	if t.OpenFDs > 0 && s.OpenFDs > t.OpenFDs {
+		out = append(out, Breach{Metric: "open_fds", Value: float64(s.OpenFDs), Limit: float64(t.OpenFDs)})
+	}
+	return out
+}
+
+// Monitor 运行时资源监控
+type Monitor struct {
+	cfg    Config
+	bucket storage.Bucket
+	log    *logger.Logger
+	clock  clock.Clock
+
+	mu        sync.Mutex
+	lastNumGC uint32
+	lastDump  time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New 创建监控，bucket 为 nil 时不转储
+func New(cfg Config, bucket storage.Bucket, log *logger.Logger) *Monitor {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &Monitor{
+		cfg:    cfg.withDefaults(),
+		bucket: bucket,
+		log:    log,
+		clock:  clock.Real(),
+	}
+}
+
+// WithClock 设置采样计时与转储时间戳使用的时钟（nil 使用真实时钟）
+func (m *Monitor) WithClock(clk clock.Clock) *Monitor {
+	m.clock = clock.OrReal(clk)
+	return m
+}
+
+// Start 立即采样一次并按间隔持续采样
+func (m *Monitor) Start(ctx context.Context) error {
+	if m.stop != nil {
+		return nil
+	}
+	m.Check(ctx)
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go m.loop(m.stop, m.done)
+	return nil
+}
+
+// Stop 停止采样，等待进行中的采样（含转储）完成或 ctx 结束
+func (m *Monitor) Stop(ctx context.Context) error {
+	if m.stop == nil {
+		return nil
+	}
+	close(m.stop)
+	m.stop = nil
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Monitor) loop(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := m.clock.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C():
+			ctx, cancel := context.WithTimeout(context.Background(), m.cfg.Dump.Timeout)
+			m.Check(ctx)
+			cancel()
+		}
+	}
+}
+
+// Check 采样一次：更新指标，超过阈值时记录告警并按配置转储
+func (m *Monitor) Check(ctx context.Context) Sample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s := m.sample()
+	goroutinesGauge.WithLabelValues().Set(float64(s.Goroutines))
+	heapAllocGauge.WithLabelValues().Set(float64(s.HeapAlloc))
+	heapInuseGauge.WithLabelValues().Set(float64(s.HeapInuse))
+	heapObjectsGauge.WithLabelValues().Set(float64(s.HeapObjects))
+	gcPauseGauge.WithLabelValues().Set(s.GCPauseMax.Seconds())
+	openFDsGauge.WithLabelValues().Set(float64(s.OpenFDs))
+
+	breaches := m.cfg.Thresholds.Breaches(s)
+	if len(breaches) == 0 {
+		return s
+	}
+	for _, b := range breaches {
+		breachTotal.WithLabelValues(b.Metric).Inc()
+		m.log.Warn("Diagnostics threshold exceeded",
+			zap.String("metric", b.Metric),
+			zap.Float64("value", b.Value),
+			zap.Float64("limit", b.Limit),
+		)
+	}
+	if m.cfg.Dump.Enabled && m.bucket != nil &&
+		(m.lastDump.IsZero() || m.clock.Since(m.lastDump) >= m.cfg.Dump.Cooldown) {
+		m.lastDump = m.clock.Now()
+		m.dump(ctx, s.Time)
+	}
+	return s
+}
+
+// sample 读取运行时统计，GC 暂停取自上次采样以来的最长值（最多回看 256 次 GC）
+func (m *Monitor) sample() Sample {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+
+	s := Sample{
+		Time:        m.clock.Now(),
+		Goroutines:  runtime.NumGoroutine(),
+		HeapAlloc:   ms.HeapAlloc,
+		HeapInuse:   ms.HeapInuse,
+		HeapObjects: ms.HeapObjects,
+		NumGC:       ms.NumGC,
+		OpenFDs:     openFDs(),
+	}
+	n := min(ms.NumGC-m.lastNumGC, uint32(len(ms.PauseNs)))
+	for i := range n {
+		pause := time.Duration(ms.PauseNs[(ms.NumGC-1-i)%uint32(len(ms.PauseNs))])
+		s.GCPauseMax = max(s.GCPauseMax, pause)
+	}
+	m.lastNumGC = ms.NumGC
+	return s
+}
+
+// openFDs 统计 /proc/self/fd 中的条目数，不可用时返回 -1
+func openFDs() int {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return -1
+	}
+	return len(entries)
+}
+
+// dump 上传 heap profile 与 goroutine 堆栈
+func (m *Monitor) dump(ctx context.Context, at time.Time) {
+	base := strings.TrimSuffix(m.cfg.Dump.Prefix, "/") + "/" + m.cfg.Dump.Instance + "/" +
+		at.UTC().Format("20060102T150405Z")
+	for _, p := range []struct {
+		profile     string
+		debug       int
+		suffix      string
+		contentType string
+	}{
+		{profile: "heap", debug: 0, suffix: "-heap.pb.gz", contentType: "application/octet-stream"},
+		{profile: "goroutine", debug: 2, suffix: "-goroutine.txt", contentType: "text/plain; charset=utf-8"},
+	} {
+		key := base + p.suffix
+		var buf bytes.Buffer
+		err := pprof.Lookup(p.profile).WriteTo(&buf, p.debug)
+		if err == nil {
+			_, err = m.bucket.Put(ctx, key, &buf, int64(buf.Len()), &storage.PutOptions{ContentType: p.contentType})
+		}
+		if err != nil {
+			dumpTotal.WithLabelValues("failure").Inc()
+			m.log.Warn("Diagnostics dump failed", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		dumpTotal.WithLabelValues("success").Inc()
+		m.log.Info("Diagnostics dump uploaded", zap.String("key", key))
+	}
+}
@@ -0,0 +1,121 @@
+package diagnostics
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/storage"
+)
+
+// memBucket 仅实现 Put 的测试存储桶
+type memBucket struct {
+	storage.Bucket
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (b *memBucket) Put(_ context.Context, key string, r io.Reader, _ int64, _ *storage.PutOptions) (*storage.ObjectInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.objects == nil {
+		b.objects = make(map[string][]byte)
+	}
+	b.objects[key] = data
+	return &storage.ObjectInfo{Key: key, Size: int64(len(data))}, nil
+}
+
+func (b *memBucket) keys() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var keys []string
+	for k := range b.objects {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func TestBreaches(t *testing.T) {
+	th := Thresholds{Goroutines: 10, HeapBytes: 100, GCPause: time.Millisecond, OpenFDs: 5}
+	if got := th.Breaches(Sample{Goroutines: 10, HeapAlloc: 100, GCPauseMax: time.Millisecond, OpenFDs: 5}); len(got) != 0 {
+		t.Fatalf("breaches at limit = %v", got)
+	}
+	got := th.Breaches(Sample{Goroutines: 11, HeapAlloc: 101, GCPauseMax: 2 * time.Millisecond, OpenFDs: -1})
+	if len(got) != 3 || got[0].Metric != "goroutines" || got[1].Metric != "heap_bytes" || got[2].Metric != "gc_pause" {
+		t.Fatalf("breaches = %+v", got)
+	}
+	if got := (Thresholds{}).Breaches(Sample{Goroutines: 1 << 20}); len(got) != 0 {
+		t.Fatalf("zero thresholds breaches = %v", got)
+	}
+}
+
+func TestCheckDumpsWithCooldown(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	bucket := &memBucket{}
+	m := New(Config{
+		Thresholds: Thresholds{Goroutines: 1},
+		Dump:       DumpConfig{Enabled: true, Instance: "node-1", Cooldown: time.Hour},
+	}, bucket, nil).WithClock(fake)
+
+	s := m.Check(context.Background())
+	if s.Goroutines < 1 || s.HeapAlloc == 0 || !s.Time.Equal(fake.Now()) {
+		t.Fatalf("sample = %+v", s)
+	}
+	keys := bucket.keys()
+	if len(keys) != 2 {
+		t.Fatalf("dump keys = %v", keys)
+	}
+	for _, k := range keys {
+		if !strings.HasPrefix(k, "diagnostics/node-1/20240101T000000Z-") {
+			t.Fatalf("dump key = %s", k)
+		}
+	}
+
+	// 冷却期内不重复转储
+	fake.Advance(time.Minute)
+	m.Check(context.Background())
+	if n := len(bucket.keys()); n != 2 {
+		t.Fatalf("dumps within cooldown = %d, want 2", n)
+	}
+	fake.Advance(time.Hour)
+	m.Check(context.Background())
+	if n := len(bucket.keys()); n != 4 {
+		t.Fatalf("dumps after cooldown = %d, want 4", n)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	bucket := &memBucket{}
+	m := New(Config{
+		Interval:   time.Second,
+		Thresholds: Thresholds{Goroutines: 1},
+		Dump:       DumpConfig{Enabled: true, Cooldown: time.Second},
+	}, bucket, nil).WithClock(fake)
+
+	if err := m.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(bucket.keys()) < 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("dump keys = %v, want 4 after tick", bucket.keys())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := m.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+}
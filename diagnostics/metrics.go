@@ -0,0 +1,41 @@
+package diagnostics
+
+import "github.com/aisgo/ais-go-pkg/metrics"
+
+/* ========================================================================
+ * Diagnostics Metrics
+ * ======================================================================== */
+
+var (
+	// goroutinesGauge goroutine 数量
+	goroutinesGauge = metrics.NewGauge("app", "diagnostics", "goroutines",
+		"Number of goroutines at the last sample", nil)
+
+	// heapAllocGauge 已分配且未释放的堆内存
+	heapAllocGauge = metrics.NewGauge("app", "diagnostics", "heap_alloc_bytes",
+		"Bytes of allocated heap objects at the last sample", nil)
+
+	// heapInuseGauge 使用中的堆 span
+	heapInuseGauge = metrics.NewGauge("app", "diagnostics", "heap_inuse_bytes",
+		"Bytes in in-use heap spans at the last sample", nil)
+
+	// heapObjectsGauge 堆对象数量
+	heapObjectsGauge = metrics.NewGauge("app", "diagnostics", "heap_objects",
+		"Number of allocated heap objects at the last sample", nil)
+
+	// gcPauseGauge 采样间隔内最长 GC 暂停
+	gcPauseGauge = metrics.NewGauge("app", "diagnostics", "gc_pause_max_seconds",
+		"Longest GC pause observed during the last sample interval", nil)
+
+	// openFDsGauge 打开的文件描述符数量（不支持的平台为 -1）
+	openFDsGauge = metrics.NewGauge("app", "diagnostics", "open_fds",
+		"Number of open file descriptors, -1 when unavailable", nil)
+
+	// breachTotal 超过告警阈值的次数
+	breachTotal = metrics.NewCounter("app", "diagnostics", "threshold_breach_total",
+		"Total number of samples exceeding an alert threshold", []string{"metric"})
+
+	// dumpTotal 自动转储次数（result: success / failure）
+	dumpTotal = metrics.NewCounter("app", "diagnostics", "dump_total",
+		"Total number of automatic heap/goroutine dumps", []string{"result"})
+)
@@ -0,0 +1,56 @@
+package diagnostics
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/conf"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/storage"
+)
+
+/* ========================================================================
+ * Diagnostics Module
+ * ========================================================================
+ * 职责: 提供 Monitor，Enabled 时随应用启动采样、停止时退出
+ * Bundle: 从 conf.Source 的 diagnostics 节解码 Config（缺省见 DefaultConfig）
+ * 说明: 存在 storage.Bucket 时支持自动转储
+ * ======================================================================== */
+
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	Lc     fx.Lifecycle
+	Config Config
+	Bucket storage.Bucket `optional:"true"`
+	Logger *logger.Logger `optional:"true"`
+	Clock  clock.Clock    `optional:"true"`
+}
+
+// NewFromParams 创建监控并挂载生命周期（未启用时不采样）
+func NewFromParams(p Params) *Monitor {
+	m := New(p.Config, p.Bucket, p.Logger).WithClock(p.Clock)
+	if p.Config.Enabled {
+		p.Lc.Append(fx.Hook{
+			OnStart: m.Start,
+			OnStop:  m.Stop,
+		})
+	}
+	return m
+}
+
+// Module 诊断模块
+// 提供: *Monitor
+var Module = fx.Module("diagnostics",
+	fx.Provide(NewFromParams),
+	fx.Invoke(func(*Monitor) {}),
+)
+
+// Bundle 带配置解码的诊断模块
+// 依赖: conf.Source
+// 提供: diagnostics.Config, *Monitor
+var Bundle = fx.Module("diagnostics-bundle",
+	fx.Provide(conf.Section("diagnostics", DefaultConfig)),
+	Module,
+)
@@ -0,0 +1,403 @@
+package dict
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+/* ========================================================================
+ * Dict - 字典 / 枚举查询
+ * ========================================================================
+ * 职责: 按类型读取字典项并缓存，提供 code -> label 查询，变更时失效缓存
+ * 缓存:
+ *   - 进程内缓存（LocalTTL），命中时无网络开销
+ *   - Redis 缓存（CacheTTL，可选），实例重启或本地过期后避免回源数据库
+ *   - 变更时删除 Redis 缓存并发布失效消息，其他实例收到后清除本地缓存（需 Start 订阅）
+ * 失效:
+ *   - 经 Service 的 SaveItems / DeleteItem 写入时立即失效
+ *   - 直接通过字典项仓储 Create/Update/Delete 写入时由仓储钩子触发失效
+ *   - 其他途径（手写 SQL）写入时调用 Invalidate / InvalidateAll
+ * 查询只返回未禁用的字典项，按 sort、code 升序
+ *
+ * 使用示例:
+ *   dicts := dict.New(
+ *       repository.NewRepository[dict.Type](db),
+ *       repository.NewRepository[dict.Item](db),
+ *       rdb, dict.Config{}, log)
+ *   label := dicts.Label(ctx, "order_status", order.Status) // 已支付
+ *   app.Get("/api/dicts/:type?", dicts.Handler())
+ * ======================================================================== */
+
+// 默认配置
+const (
+	DefaultLocalTTL  = time.Minute
+	DefaultCacheTTL  = time.Hour
+	DefaultKeyPrefix = "dict:"
+	DefaultChannel   = "dict:invalidate"
+)
+
+// invalidateAll 失效全部类型的消息
+const invalidateAll = "*"
+
+// Config 字典配置
+type Config struct {
+	LocalTTL  time.Duration `yaml:"local_ttl"`  // 进程内缓存时间，默认 1m，<0 关闭
+	CacheTTL  time.Duration `yaml:"cache_ttl"`  // Redis 缓存时间，默认 1h，<0 关闭
+	KeyPrefix string        `yaml:"key_prefix"` // Redis 键前缀，默认 dict:
+	Channel   string        `yaml:"channel"`    // 失效消息频道，默认 dict:invalidate
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() Config {
+	return Config{
+		LocalTTL:  DefaultLocalTTL,
+		CacheTTL:  DefaultCacheTTL,
+		KeyPrefix: DefaultKeyPrefix,
+		Channel:   DefaultChannel,
+	}
+}
+
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.LocalTTL == 0 {
+		c.LocalTTL = d.LocalTTL
+	}
+	if c.CacheTTL == 0 {
+		c.CacheTTL = d.CacheTTL
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = d.KeyPrefix
+	}
+	if c.Channel == "" {
+		c.Channel = d.Channel
+	}
+	return c
+}
+
+// localEntry 进程内缓存项
+type localEntry struct {
+	items   []Item
+	expires time.Time
+}
+
+// Service 字典服务
+type Service struct {
+	types repository.Repository[Type]
+	items repository.Repository[Item]
+	rdb   redis.UniversalClient
+	cfg   Config
+	log   *logger.Logger
+
+	mu    sync.RWMutex
+	local map[string]localEntry
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New 创建字典服务，rdb 为 nil 时只使用进程内缓存
+// 会在 items 仓储上注册钩子，使经仓储写入的变更自动失效缓存
+func New(types repository.Repository[Type], items repository.Repository[Item], rdb redis.UniversalClient, cfg Config, log *logger.Logger) *Service {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	s := &Service{
+		types: types,
+		items: items,
+		rdb:   rdb,
+		cfg:   cfg.withDefaults(),
+		log:   log,
+		local: make(map[string]localEntry),
+	}
+	invalidateItem := func(ctx context.Context, item *Item) error {
+		return s.Invalidate(ctx, item.TypeCode)
+	}
+	items.OnAfterCreate(invalidateItem)
+	items.OnAfterUpdate(invalidateItem)
+	// 删除钩子只有 ID，无法定位类型，失效全部
+	items.OnAfterDelete(func(ctx context.Context, _ []string) error {
+		return s.InvalidateAll(ctx)
+	})
+	return s
+}
+
+/* ========================================================================
+ * 查询
+ * ======================================================================== */
+
+// Items 返回类型下未禁用的字典项（类型不存在时返回空列表）
+func (s *Service) Items(ctx context.Context, typeCode string) ([]Item, error) {
+	if typeCode == "" {
+		return nil, errors.ErrInvalidArgument
+	}
+	if items, ok := s.getLocal(typeCode); ok {
+		return items, nil
+	}
+	if items, ok := s.getRemote(ctx, typeCode); ok {
+		s.setLocal(typeCode, items)
+		return items, nil
+	}
+
+	found, err := s.items.FindBySpec(ctx,
+		repository.And[Item](repository.Eq[Item]("type_code", typeCode), repository.Eq[Item]("disabled", false)),
+		repository.WithOrderBy("sort, code"))
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Item, len(found))
+	for i, m := range found {
+		items[i] = *m
+	}
+	s.setRemote(ctx, typeCode, items)
+	s.setLocal(typeCode, items)
+	return items, nil
+}
+
+// Many 批量返回多个类型的字典项
+func (s *Service) Many(ctx context.Context, typeCodes ...string) (map[string][]Item, error) {
+	result := make(map[string][]Item, len(typeCodes))
+	for _, code := range typeCodes {
+		items, err := s.Items(ctx, code)
+		if err != nil {
+			return nil, err
+		}
+		result[code] = items
+	}
+	return result, nil
+}
+
+// Lookup 返回字典项，不存在或已禁用时返回 NotFound
+func (s *Service) Lookup(ctx context.Context, typeCode, code string) (*Item, error) {
+	items, err := s.Items(ctx, typeCode)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		if items[i].Code == code {
+			return &items[i], nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "dict item not found: "+typeCode+"/"+code)
+}
+
+// Label 返回字典项名称，不存在或查询失败时返回 code 本身
+func (s *Service) Label(ctx context.Context, typeCode, code string) string {
+	item, err := s.Lookup(ctx, typeCode, code)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			s.log.Warn("Dict lookup failed", zap.String("type", typeCode), zap.Error(err))
+		}
+		return code
+	}
+	return item.Label
+}
+
+/* ========================================================================
+ * 维护
+ * ======================================================================== */
+
+// SaveType 创建或更新字典类型（按 code）
+func (s *Service) SaveType(ctx context.Context, t *Type) error {
+	if t == nil || t.Code == "" {
+		return errors.ErrInvalidArgument
+	}
+	return s.types.UpsertBatch(ctx, []*Type{t},
+		repository.WithConflictColumns("code"), repository.WithUpdateColumns("name", "remark"))
+}
+
+// SaveItems 创建或更新类型下的字典项（按 type_code + code）
+func (s *Service) SaveItems(ctx context.Context, typeCode string, items []*Item) error {
+	if typeCode == "" || len(items) == 0 {
+		return errors.ErrInvalidArgument
+	}
+	for _, item := range items {
+		if item == nil || item.Code == "" {
+			return errors.New(errors.ErrCodeInvalidArgument, "dict item code is required")
+		}
+		item.TypeCode = typeCode
+	}
+	err := s.items.UpsertBatch(ctx, items,
+		repository.WithConflictColumns("type_code", "code"),
+		repository.WithUpdateColumns("label", "sort", "tag", "disabled", "remark"))
+	if err != nil {
+		return err
+	}
+	return s.Invalidate(ctx, typeCode)
+}
+
+// DeleteItem 删除字典项
+func (s *Service) DeleteItem(ctx context.Context, typeCode, code string) error {
+	if err := s.items.DeleteByKey(ctx, map[string]any{"type_code": typeCode, "code": code}); err != nil {
+		return err
+	}
+	return s.Invalidate(ctx, typeCode)
+}
+
+/* ========================================================================
+ * 缓存
+ * ======================================================================== */
+
+func (s *Service) getLocal(typeCode string) ([]Item, bool) {
+	if s.cfg.LocalTTL < 0 {
+		return nil, false
+	}
+	s.mu.RLock()
+	e, ok := s.local[typeCode]
+	s.mu.RUnlock()
+	if !ok || time.Now().After(e.expires) {
+		return nil, false
+	}
+	return e.items, true
+}
+
+func (s *Service) setLocal(typeCode string, items []Item) {
+	if s.cfg.LocalTTL < 0 {
+		return
+	}
+	s.mu.Lock()
+	s.local[typeCode] = localEntry{items: items, expires: time.Now().Add(s.cfg.LocalTTL)}
+	s.mu.Unlock()
+}
+
+func (s *Service) dropLocal(typeCode string) {
+	s.mu.Lock()
+	if typeCode == invalidateAll {
+		clear(s.local)
+	} else {
+		delete(s.local, typeCode)
+	}
+	s.mu.Unlock()
+}
+
+func (s *Service) key(typeCode string) string {
+	return s.cfg.KeyPrefix + typeCode
+}
+
+func (s *Service) getRemote(ctx context.Context, typeCode string) ([]Item, bool) {
+	if s.rdb == nil || s.cfg.CacheTTL < 0 {
+		return nil, false
+	}
+	data, err := s.rdb.Get(ctx, s.key(typeCode)).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			s.log.Warn("Dict cache read failed", zap.String("type", typeCode), zap.Error(err))
+		}
+		return nil, false
+	}
+	var items []Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, false
+	}
+	return items, true
+}
+
+func (s *Service) setRemote(ctx context.Context, typeCode string, items []Item) {
+	if s.rdb == nil || s.cfg.CacheTTL < 0 {
+		return
+	}
+	data, err := json.Marshal(items)
+	if err != nil {
+		return
+	}
+	if err := s.rdb.Set(ctx, s.key(typeCode), data, s.cfg.CacheTTL).Err(); err != nil {
+		s.log.Warn("Dict cache write failed", zap.String("type", typeCode), zap.Error(err))
+	}
+}
+
+// Invalidate 失效类型的缓存（本实例、Redis 与其他实例）
+func (s *Service) Invalidate(ctx context.Context, typeCode string) error {
+	s.dropLocal(typeCode)
+	if s.rdb == nil {
+		return nil
+	}
+	if err := s.rdb.Del(ctx, s.key(typeCode)).Err(); err != nil {
+		return errors.Wrap(errors.ErrCodeUnavailable, "failed to invalidate dict cache", err)
+	}
+	return s.publish(ctx, typeCode)
+}
+
+// InvalidateAll 失效全部类型的缓存
+func (s *Service) InvalidateAll(ctx context.Context) error {
+	s.dropLocal(invalidateAll)
+	if s.rdb == nil {
+		return nil
+	}
+	types, err := s.types.FindByQuery(ctx, "")
+	if err != nil {
+		return err
+	}
+	if len(types) > 0 {
+		keys := make([]string, len(types))
+		for i, t := range types {
+			keys[i] = s.key(t.Code)
+		}
+		if err := s.rdb.Del(ctx, keys...).Err(); err != nil {
+			return errors.Wrap(errors.ErrCodeUnavailable, "failed to invalidate dict cache", err)
+		}
+	}
+	return s.publish(ctx, invalidateAll)
+}
+
+func (s *Service) publish(ctx context.Context, typeCode string) error {
+	if err := s.rdb.Publish(ctx, s.cfg.Channel, typeCode).Err(); err != nil {
+		return errors.Wrap(errors.ErrCodeUnavailable, "failed to publish dict invalidation", err)
+	}
+	return nil
+}
+
+/* ========================================================================
+ * 失效订阅
+ * ======================================================================== */
+
+// Start 订阅失效消息，清除本地缓存（未配置 Redis 时为空操作）
+func (s *Service) Start(ctx context.Context) error {
+	if s.rdb == nil || s.stop != nil {
+		return nil
+	}
+	sub := s.rdb.Subscribe(ctx, s.cfg.Channel)
+	// 等待订阅确认，保证 Start 返回后的失效消息不会丢失
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return errors.Wrap(errors.ErrCodeUnavailable, "failed to subscribe dict invalidation", err)
+	}
+
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		defer sub.Close()
+		ch := sub.Channel()
+		for {
+			select {
+			case <-s.stop:
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				s.dropLocal(msg.Payload)
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop 停止订阅
+func (s *Service) Stop(context.Context) error {
+	if s.stop == nil {
+		return nil
+	}
+	close(s.stop)
+	<-s.done
+	s.stop = nil
+	return nil
+}
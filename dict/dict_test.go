@@ -0,0 +1,191 @@
+package dict
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/cache/redis/redistest"
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "dict.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(Models()...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func newService(t *testing.T, db *gorm.DB, cfg Config) (*Service, repository.Repository[Item]) {
+	t.Helper()
+	client, _ := redistest.New(t)
+	items := repository.NewRepository[Item](db)
+	return New(repository.NewRepository[Type](db), items, client.Raw(), cfg, nil), items
+}
+
+func seedStatus(t *testing.T, s *Service) {
+	t.Helper()
+	ctx := context.Background()
+	if err := s.SaveType(ctx, &Type{Code: "order_status", Name: "订单状态"}); err != nil {
+		t.Fatalf("save type: %v", err)
+	}
+	err := s.SaveItems(ctx, "order_status", []*Item{
+		{Code: "paid", Label: "已支付", Sort: 2, Tag: "success"},
+		{Code: "created", Label: "待支付", Sort: 1},
+		{Code: "void", Label: "作废", Sort: 3, Disabled: true},
+	})
+	if err != nil {
+		t.Fatalf("save items: %v", err)
+	}
+}
+
+func TestLabel(t *testing.T) {
+	s, _ := newService(t, openDB(t), Config{})
+	seedStatus(t, s)
+	ctx := context.Background()
+
+	if got := s.Label(ctx, "order_status", "paid"); got != "已支付" {
+		t.Fatalf("unexpected label %q", got)
+	}
+	if got := s.Label(ctx, "order_status", "void"); got != "void" {
+		t.Fatalf("disabled item should fall back to code, got %q", got)
+	}
+	if got := s.Label(ctx, "unknown", "x"); got != "x" {
+		t.Fatalf("unknown type should fall back to code, got %q", got)
+	}
+	if _, err := s.Lookup(ctx, "order_status", "void"); !errors.IsNotFound(err) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+
+	items, err := s.Items(ctx, "order_status")
+	if err != nil {
+		t.Fatalf("items: %v", err)
+	}
+	if len(items) != 2 || items[0].Code != "created" || items[1].Code != "paid" {
+		t.Fatalf("unexpected items %+v", items)
+	}
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	db := openDB(t)
+	s, repo := newService(t, db, Config{})
+	seedStatus(t, s)
+	ctx := context.Background()
+	_ = s.Label(ctx, "order_status", "paid")
+
+	// 绕过仓储直接修改：缓存命中，直到显式失效
+	db.Model(&Item{}).Where("code = ?", "paid").Update("label", "Paid")
+	if got := s.Label(ctx, "order_status", "paid"); got != "已支付" {
+		t.Fatalf("expected cached label, got %q", got)
+	}
+	if err := s.Invalidate(ctx, "order_status"); err != nil {
+		t.Fatalf("invalidate: %v", err)
+	}
+	if got := s.Label(ctx, "order_status", "paid"); got != "Paid" {
+		t.Fatalf("expected fresh label, got %q", got)
+	}
+
+	// 经仓储写入：钩子自动失效
+	if err := repo.Create(ctx, &Item{TypeCode: "order_status", Code: "refunded", Label: "已退款"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if got := s.Label(ctx, "order_status", "refunded"); got != "已退款" {
+		t.Fatalf("expected hook invalidation, got %q", got)
+	}
+
+	if err := s.DeleteItem(ctx, "order_status", "refunded"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got := s.Label(ctx, "order_status", "refunded"); got != "refunded" {
+		t.Fatalf("expected deleted item to disappear, got %q", got)
+	}
+}
+
+func TestCrossInstanceInvalidation(t *testing.T) {
+	db := openDB(t)
+	client, _ := redistest.New(t)
+	ctx := context.Background()
+	newSvc := func() *Service {
+		s := New(repository.NewRepository[Type](db), repository.NewRepository[Item](db), client.Raw(), Config{}, nil)
+		if err := s.Start(ctx); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		t.Cleanup(func() { _ = s.Stop(ctx) })
+		return s
+	}
+	a, b := newSvc(), newSvc()
+	seedStatus(t, a)
+	if got := b.Label(ctx, "order_status", "paid"); got != "已支付" {
+		t.Fatalf("unexpected label %q", got)
+	}
+
+	if err := a.SaveItems(ctx, "order_status", []*Item{{Code: "paid", Label: "Paid", Sort: 2}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for b.Label(ctx, "order_status", "paid") != "Paid" {
+		if time.Now().After(deadline) {
+			t.Fatal("expected other instance to drop its local cache")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	s, _ := newService(t, openDB(t), Config{LocalTTL: -1})
+	seedStatus(t, s)
+	h := s.Handler()
+	app := fiber.New()
+	app.Get("/dicts", h)
+	app.Get("/dicts/:type", h)
+
+	get := func(target string, data any) int {
+		resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+		if err != nil {
+			t.Fatalf("request %s: %v", target, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		var out struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &out); err != nil {
+			t.Fatalf("decode %s: %v", body, err)
+		}
+		if data != nil {
+			if err := json.Unmarshal(out.Data, data); err != nil {
+				t.Fatalf("decode data %s: %v", out.Data, err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	var items []map[string]any
+	if code := get("/dicts/order_status", &items); code != 200 || len(items) != 2 || items[1]["label"] != "已支付" || items[1]["tag"] != "success" {
+		t.Fatalf("unexpected response %d %v", code, items)
+	}
+	if _, ok := items[0]["disabled"]; ok {
+		t.Fatalf("internal fields must not be exposed: %v", items[0])
+	}
+
+	var many map[string][]map[string]any
+	if code := get("/dicts?types=order_status,%20gender", &many); code != 200 || len(many["order_status"]) != 2 || len(many["gender"]) != 0 {
+		t.Fatalf("unexpected response %d %v", code, many)
+	}
+	if code := get("/dicts", nil); code != 400 {
+		t.Fatalf("expected bad request, got %d", code)
+	}
+}
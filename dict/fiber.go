@@ -0,0 +1,52 @@
+package dict
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/response"
+)
+
+/* ========================================================================
+ * Dict Handler - 字典 HTTP 接口
+ * ========================================================================
+ * 职责: 向前端输出字典项，用于下拉框、状态标签等
+ * 路由:
+ *   GET /dicts/:type            -> [{code, label, sort, tag}]
+ *   GET /dicts?types=a,b        -> {a: [...], b: [...]}
+ *
+ * 使用示例:
+ *   h := dicts.Handler()
+ *   app.Get("/api/dicts", h)
+ *   app.Get("/api/dicts/:type", h)
+ * ======================================================================== */
+
+// Handler 返回字典查询处理器
+func (s *Service) Handler() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if typeCode := c.Params("type"); typeCode != "" {
+			items, err := s.Items(c.Context(), typeCode)
+			if err != nil {
+				return response.Error(c, err)
+			}
+			return response.OkWithData(c, items)
+		}
+
+		var codes []string
+		for code := range strings.SplitSeq(c.Query("types"), ",") {
+			if code = strings.TrimSpace(code); code != "" {
+				codes = append(codes, code)
+			}
+		}
+		if len(codes) == 0 {
+			return response.Error(c, errors.New(errors.ErrCodeInvalidArgument, "dict type is required"))
+		}
+		result, err := s.Many(c.Context(), codes...)
+		if err != nil {
+			return response.Error(c, err)
+		}
+		return response.OkWithData(c, result)
+	}
+}
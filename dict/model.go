@@ -0,0 +1,81 @@
+package dict
+
+import (
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * Dict Model - 字典持久化模型
+ * ========================================================================
+ * 职责: 字典类型（order_status）与字典项（paid -> 已支付）
+ * 说明: 字典为平台级数据，不做租户隔离；字典项按 (type_code, code) 唯一
+ * ======================================================================== */
+
+// Type 字典类型
+type Type struct {
+	ID        string    `gorm:"column:id;type:char(26);primaryKey" json:"id"`
+	Code      string    `gorm:"column:code;size:64;not null;uniqueIndex" json:"code"`
+	Name      string    `gorm:"column:name;size:128" json:"name"`
+	Remark    string    `gorm:"column:remark;size:255" json:"remark,omitempty"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 表名
+func (Type) TableName() string {
+	return "dict_types"
+}
+
+// TenantIgnored 字典为平台级数据
+func (Type) TenantIgnored() bool {
+	return true
+}
+
+// BeforeCreate 自动生成 ID
+func (t *Type) BeforeCreate(*gorm.DB) error {
+	if t.ID == "" {
+		t.ID = ulid.GenerateString()
+	}
+	return nil
+}
+
+// Item 字典项（JSON 形式即对前端输出的结构）
+type Item struct {
+	ID        string    `gorm:"column:id;type:char(26);primaryKey" json:"-"`
+	TypeCode  string    `gorm:"column:type_code;size:64;not null;uniqueIndex:uk_dict_items_code,priority:1" json:"-"`
+	Code      string    `gorm:"column:code;size:64;not null;uniqueIndex:uk_dict_items_code,priority:2" json:"code"`
+	Label     string    `gorm:"column:label;size:128;not null" json:"label"`
+	Sort      int       `gorm:"column:sort;not null;default:0" json:"sort"`
+	Tag       string    `gorm:"column:tag;size:32" json:"tag,omitempty"` // 前端展示样式，如 success / warning
+	Disabled  bool      `gorm:"column:disabled;not null;default:false" json:"-"`
+	Remark    string    `gorm:"column:remark;size:255" json:"-"`
+	CreatedAt time.Time `gorm:"column:created_at" json:"-"`
+	UpdatedAt time.Time `gorm:"column:updated_at" json:"-"`
+}
+
+// TableName 表名
+func (Item) TableName() string {
+	return "dict_items"
+}
+
+// TenantIgnored 字典为平台级数据
+func (Item) TenantIgnored() bool {
+	return true
+}
+
+// BeforeCreate 自动生成 ID
+func (i *Item) BeforeCreate(*gorm.DB) error {
+	if i.ID == "" {
+		i.ID = ulid.GenerateString()
+	}
+	return nil
+}
+
+// Models 返回需要迁移的模型（AutoMigrate 或 sharding.WithModels）
+func Models() []any {
+	return []any{&Type{}, &Item{}}
+}
@@ -0,0 +1,60 @@
+package dict
+
+import (
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+
+	cacheredis "github.com/aisgo/ais-go-pkg/cache/redis"
+	"github.com/aisgo/ais-go-pkg/conf"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+/* ========================================================================
+ * Dict Module
+ * ========================================================================
+ * 职责: 提供字典服务；存在 Redis 客户端时启用 Redis 缓存与跨实例失效
+ * Bundle: 从 conf.Source 的 dict 节解码 Config（缺省见 DefaultConfig）
+ * ======================================================================== */
+
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	Lc     fx.Lifecycle
+	Config Config
+	DB     *gorm.DB
+	Redis  *cacheredis.Client `optional:"true"`
+	Logger *logger.Logger     `optional:"true"`
+}
+
+// NewFromParams 创建字典服务，启动时订阅失效消息
+func NewFromParams(p Params) *Service {
+	var s *Service
+	types := repository.NewRepository[Type](p.DB)
+	items := repository.NewRepository[Item](p.DB)
+	if p.Redis != nil {
+		s = New(types, items, p.Redis.Raw(), p.Config, p.Logger)
+	} else {
+		s = New(types, items, nil, p.Config, p.Logger)
+	}
+	p.Lc.Append(fx.Hook{
+		OnStart: s.Start,
+		OnStop:  s.Stop,
+	})
+	return s
+}
+
+// Module 字典模块
+// 提供: *Service
+var Module = fx.Module("dict",
+	fx.Provide(NewFromParams),
+)
+
+// Bundle 带配置解码的字典模块
+// 依赖: conf.Source, *gorm.DB
+// 提供: dict.Config, *Service
+var Bundle = fx.Module("dict-bundle",
+	fx.Provide(conf.Section("dict", DefaultConfig)),
+	Module,
+)
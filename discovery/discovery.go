@@ -0,0 +1,224 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Service Discovery - 服务注册与发现
+ * ========================================================================
+ * 职责: 服务实例注册（TTL 心跳）、注销与实例监听
+ * 生命周期:
+ *   - OnStart: 注册实例并按 heartbeat 间隔续约
+ *   - OnStop: 停止心跳并注销实例
+ * 后端: 仅内置 Redis 实现（RedisRegistry）；使用其他注册中心时需自行实现 Registry 接口
+ * 配置示例:
+ *   discovery:
+ *     enabled: true
+ *     service: user-service
+ *     addr: 10.0.0.12:9090
+ *     ttl: 15s
+ *     metadata: {grpc_port: "9090", http_port: "8080", shard: "s1"}
+ * ======================================================================== */
+
+// 常用元数据键
+const (
+	MetadataGRPCPort = "grpc_port"
+	MetadataHTTPPort = "http_port"
+	MetadataShard    = "shard"
+)
+
+// Instance 服务实例
+type Instance struct {
+	Service  string            `json:"service"`
+	ID       string            `json:"id"`
+	Addr     string            `json:"addr"` // 对外地址 host:port
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Equal 实例是否相同
+func (i Instance) Equal(o Instance) bool {
+	return i.Service == o.Service && i.ID == o.ID && i.Addr == o.Addr && maps.Equal(i.Metadata, o.Metadata)
+}
+
+// Registry 注册中心
+type Registry interface {
+	// Register 注册实例或续约，实例在 ttl 内未续约将被剔除
+	Register(ctx context.Context, ins Instance, ttl time.Duration) error
+	// Deregister 注销实例
+	Deregister(ctx context.Context, ins Instance) error
+	// Instances 返回服务当前存活实例
+	Instances(ctx context.Context, service string) ([]Instance, error)
+	// Watch 监听服务实例，建立时及实例变化时推送全量列表；ctx 取消后关闭通道
+	Watch(ctx context.Context, service string) (<-chan []Instance, error)
+}
+
+// Config 实例注册配置
+type Config struct {
+	Enabled    bool              `yaml:"enabled"`
+	Service    string            `yaml:"service"`     // 服务名
+	InstanceID string            `yaml:"instance_id"` // 实例 ID，默认 主机名-进程号
+	Addr       string            `yaml:"addr"`        // 对外地址 host:port
+	Metadata   map[string]string `yaml:"metadata"`    // grpc_port/http_port/shard 等
+	TTL        time.Duration     `yaml:"ttl"`         // 实例存活时间，默认 15s
+	Heartbeat  time.Duration     `yaml:"heartbeat"`   // 续约间隔，默认 TTL/3
+}
+
+// withDefaults 填充默认值
+func (c Config) withDefaults() Config {
+	if c.InstanceID == "" {
+		host, _ := os.Hostname()
+		c.InstanceID = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if c.TTL <= 0 {
+		c.TTL = 15 * time.Second
+	}
+	if c.Heartbeat <= 0 || c.Heartbeat >= c.TTL {
+		c.Heartbeat = c.TTL / 3
+	}
+	return c
+}
+
+// Registrar 实例注册器，负责注册、心跳与注销
+type Registrar struct {
+	registry Registry
+	instance Instance
+	cfg      Config
+	log      *logger.Logger
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type RegistrarParams struct {
+	fx.In
+	Lc       fx.Lifecycle
+	Config   Config
+	Registry Registry
+	Logger   *logger.Logger `optional:"true"`
+}
+
+// NewRegistrar 创建实例注册器并挂载生命周期
+func NewRegistrar(p RegistrarParams) (*Registrar, error) {
+	log := p.Logger
+	if log == nil {
+		log = logger.NewNop()
+	}
+	cfg := p.Config.withDefaults()
+
+	r := &Registrar{
+		registry: p.Registry,
+		cfg:      cfg,
+		log:      log,
+		instance: Instance{
+			Service:  cfg.Service,
+			ID:       cfg.InstanceID,
+			Addr:     cfg.Addr,
+			Metadata: cfg.Metadata,
+		},
+	}
+	if !cfg.Enabled {
+		return r, nil
+	}
+	if cfg.Service == "" || cfg.Addr == "" {
+		return nil, fmt.Errorf("discovery: service and addr are required")
+	}
+	if p.Registry == nil {
+		return nil, fmt.Errorf("discovery: registry is required")
+	}
+
+	p.Lc.Append(fx.Hook{
+		OnStart: r.Start,
+		OnStop:  r.Stop,
+	})
+	return r, nil
+}
+
+// Instance 返回当前实例信息
+func (r *Registrar) Instance() Instance {
+	return r.instance
+}
+
+// Start 注册实例并启动心跳
+func (r *Registrar) Start(ctx context.Context) error {
+	if err := r.registry.Register(ctx, r.instance, r.cfg.TTL); err != nil {
+		return fmt.Errorf("discovery: register %s: %w", r.instance.Service, err)
+	}
+	r.log.Info("Service instance registered",
+		zap.String("service", r.instance.Service),
+		zap.String("instance_id", r.instance.ID),
+		zap.String("addr", r.instance.Addr),
+	)
+
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.heartbeat()
+	return nil
+}
+
+// heartbeat 定期续约，失败时记录日志并在下次续约时重试
+func (r *Registrar) heartbeat() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.cfg.Heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Heartbeat)
+			if err := r.registry.Register(ctx, r.instance, r.cfg.TTL); err != nil {
+				r.log.Warn("Service instance heartbeat failed",
+					zap.String("service", r.instance.Service),
+					zap.String("instance_id", r.instance.ID),
+					zap.Error(err),
+				)
+			}
+			cancel()
+		}
+	}
+}
+
+// Stop 停止心跳并注销实例
+func (r *Registrar) Stop(ctx context.Context) error {
+	if r.stop == nil {
+		return nil
+	}
+	close(r.stop)
+	<-r.done
+	r.stop = nil
+
+	if err := r.registry.Deregister(ctx, r.instance); err != nil {
+		return fmt.Errorf("discovery: deregister %s: %w", r.instance.Service, err)
+	}
+	r.log.Info("Service instance deregistered",
+		zap.String("service", r.instance.Service),
+		zap.String("instance_id", r.instance.ID),
+	)
+	return nil
+}
+
+// sortInstances 按实例 ID 排序，便于比较变化
+func sortInstances(list []Instance) []Instance {
+	slices.SortFunc(list, func(a, b Instance) int {
+		switch {
+		case a.ID < b.ID:
+			return -1
+		case a.ID > b.ID:
+			return 1
+		}
+		return 0
+	})
+	return list
+}
@@ -0,0 +1,133 @@
+package discovery
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+)
+
+type testLifecycle struct {
+	hooks []fx.Hook
+}
+
+func (l *testLifecycle) Append(h fx.Hook) {
+	l.hooks = append(l.hooks, h)
+}
+
+func newTestRegistry(t *testing.T) (*RedisRegistry, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+	return NewRedisRegistry(rdb, RedisConfig{PollInterval: 20 * time.Millisecond}), mr
+}
+
+func TestRedisRegistryTTL(t *testing.T) {
+	reg, _ := newTestRegistry(t)
+	ctx := context.Background()
+	now := time.Unix(1000, 0)
+	reg.now = func() time.Time { return now }
+
+	a := Instance{Service: "user", ID: "a", Addr: "10.0.0.1:9090"}
+	b := Instance{Service: "user", ID: "b", Addr: "10.0.0.2:9090", Metadata: map[string]string{MetadataShard: "s1"}}
+	if err := reg.Register(ctx, a, 10*time.Second); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := reg.Register(ctx, b, 30*time.Second); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+
+	list, err := reg.Instances(ctx, "user")
+	if err != nil || len(list) != 2 || !list[1].Equal(b) {
+		t.Fatalf("unexpected instances: %+v %v", list, err)
+	}
+
+	// a 未续约过期
+	now = now.Add(20 * time.Second)
+	list, _ = reg.Instances(ctx, "user")
+	if len(list) != 1 || list[0].ID != "b" {
+		t.Fatalf("expected expired instance removed, got %+v", list)
+	}
+
+	if err := reg.Deregister(ctx, b); err != nil {
+		t.Fatalf("deregister: %v", err)
+	}
+	if list, _ = reg.Instances(ctx, "user"); len(list) != 0 {
+		t.Fatalf("expected no instances, got %+v", list)
+	}
+}
+
+func TestRegistrarLifecycleAndWatch(t *testing.T) {
+	reg, _ := newTestRegistry(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	updates, err := GRPCRegistry(reg).Watch(ctx, "order")
+	if err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	if first := <-updates; len(first) != 0 {
+		t.Fatalf("expected empty initial list, got %+v", first)
+	}
+
+	lc := &testLifecycle{}
+	r, err := NewRegistrar(RegistrarParams{
+		Lc: lc,
+		Config: Config{
+			Enabled:  true,
+			Service:  "order",
+			Addr:     "10.0.0.3:8080",
+			Metadata: map[string]string{MetadataGRPCPort: "9090"},
+			TTL:      time.Second,
+		},
+		Registry: reg,
+	})
+	if err != nil {
+		t.Fatalf("new registrar: %v", err)
+	}
+	if len(lc.hooks) != 1 {
+		t.Fatalf("expected lifecycle hook, got %d", len(lc.hooks))
+	}
+	if err := lc.hooks[0].OnStart(ctx); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	select {
+	case list := <-updates:
+		if len(list) != 1 || list[0].Addr != "10.0.0.3:9090" {
+			t.Fatalf("unexpected endpoints: %+v", list)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for register event")
+	}
+
+	if err := lc.hooks[0].OnStop(ctx); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	select {
+	case list := <-updates:
+		if len(list) != 0 {
+			t.Fatalf("expected instance removed, got %+v", list)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timeout waiting for deregister event")
+	}
+	if r.Instance().ID == "" {
+		t.Fatalf("expected default instance id")
+	}
+}
+
+func TestRegistrarValidation(t *testing.T) {
+	reg, _ := newTestRegistry(t)
+	if _, err := NewRegistrar(RegistrarParams{Lc: &testLifecycle{}, Config: Config{Enabled: true}, Registry: reg}); err == nil {
+		t.Fatalf("expected error for missing service/addr")
+	}
+	lc := &testLifecycle{}
+	if _, err := NewRegistrar(RegistrarParams{Lc: lc, Config: Config{}, Registry: reg}); err != nil || len(lc.hooks) != 0 {
+		t.Fatalf("disabled registrar should not register hooks: %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package discovery
+
+import (
+	"context"
+	"net"
+
+	grpcx "github.com/aisgo/ais-go-pkg/transport/grpc"
+)
+
+/* ========================================================================
+ * gRPC Resolver Adapter - 对接 gRPC ClientFactory
+ * ========================================================================
+ * 使用示例:
+ *   factory := grpcx.NewClientFactory(cfg, inProc, grpcx.WithRegistry(discovery.GRPCRegistry(reg)))
+ *   conn, _ := factory("registry:///user-service")
+ * ======================================================================== */
+
+// GRPCRegistry 将 Registry 适配为 gRPC ClientFactory 的实例来源
+func GRPCRegistry(r Registry) grpcx.Registry {
+	return grpcAdapter{registry: r}
+}
+
+type grpcAdapter struct {
+	registry Registry
+}
+
+// Watch 实现 grpcx.Registry，使用实例的 grpc_port 元数据（若有）替换地址端口
+func (a grpcAdapter) Watch(ctx context.Context, service string) (<-chan []grpcx.Endpoint, error) {
+	updates, err := a.registry.Watch(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan []grpcx.Endpoint, 1)
+	go func() {
+		defer close(ch)
+		for list := range updates {
+			endpoints := make([]grpcx.Endpoint, 0, len(list))
+			for _, ins := range list {
+				endpoints = append(endpoints, grpcx.Endpoint{Addr: grpcAddr(ins)})
+			}
+			select {
+			case ch <- endpoints:
+			case <-ctx.Done():
+				// 排空上游，等待其关闭
+				for range updates {
+				}
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// grpcAddr 实例的 gRPC 地址
+func grpcAddr(ins Instance) string {
+	port := ins.Metadata[MetadataGRPCPort]
+	if port == "" {
+		return ins.Addr
+	}
+	host, _, err := net.SplitHostPort(ins.Addr)
+	if err != nil {
+		host = ins.Addr
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,16 @@
+package discovery
+
+import "go.uber.org/fx"
+
+/* ========================================================================
+ * Discovery FX Module - 服务注册 FX 模块
+ * ========================================================================
+ * 职责: 提供 Registrar 并在应用启动时注册实例
+ * 说明: 需由应用提供 Config 与 Registry（如 NewRedisRegistry）
+ * ======================================================================== */
+
+// Module FX 模块
+var Module = fx.Module("discovery",
+	fx.Provide(NewRegistrar),
+	fx.Invoke(func(*Registrar) {}),
+)
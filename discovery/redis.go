@@ -0,0 +1,178 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/* ========================================================================
+ * Redis Registry - 基于 Redis 的注册中心
+ * ========================================================================
+ * 存储结构:
+ *   - <prefix>:<service>:expiry   ZSET  member=实例 ID, score=过期时间（毫秒）
+ *   - <prefix>:<service>:data     HASH  field=实例 ID, value=实例 JSON
+ *   - <prefix>:<service>:events   PUBSUB 实例上线/下线通知
+ * 说明: 过期实例在读取时剔除；Watch 结合订阅通知与定期轮询（兜底 TTL 过期）
+ * ======================================================================== */
+
+// RedisConfig Redis 注册中心配置
+type RedisConfig struct {
+	KeyPrefix    string        `yaml:"key_prefix"`    // 默认 "discovery"
+	PollInterval time.Duration `yaml:"poll_interval"` // Watch 轮询间隔，默认 5s
+}
+
+// RedisRegistry 基于 Redis 的注册中心
+type RedisRegistry struct {
+	rdb redis.UniversalClient
+	cfg RedisConfig
+	now func() time.Time
+}
+
+// NewRedisRegistry 创建 Redis 注册中心
+func NewRedisRegistry(rdb redis.UniversalClient, cfg RedisConfig) *RedisRegistry {
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "discovery"
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	return &RedisRegistry{rdb: rdb, cfg: cfg, now: time.Now}
+}
+
+func (r *RedisRegistry) key(service, suffix string) string {
+	return r.cfg.KeyPrefix + ":" + service + ":" + suffix
+}
+
+// Register 注册实例或续约
+func (r *RedisRegistry) Register(ctx context.Context, ins Instance, ttl time.Duration) error {
+	data, err := json.Marshal(ins)
+	if err != nil {
+		return err
+	}
+	expireAt := r.now().Add(ttl).UnixMilli()
+
+	var added *redis.IntCmd
+	_, err = r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		added = pipe.ZAdd(ctx, r.key(ins.Service, "expiry"), redis.Z{Score: float64(expireAt), Member: ins.ID})
+		pipe.HSet(ctx, r.key(ins.Service, "data"), ins.ID, data)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if added.Val() > 0 {
+		return r.rdb.Publish(ctx, r.key(ins.Service, "events"), "register:"+ins.ID).Err()
+	}
+	return nil
+}
+
+// Deregister 注销实例
+func (r *RedisRegistry) Deregister(ctx context.Context, ins Instance) error {
+	_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, r.key(ins.Service, "expiry"), ins.ID)
+		pipe.HDel(ctx, r.key(ins.Service, "data"), ins.ID)
+		pipe.Publish(ctx, r.key(ins.Service, "events"), "deregister:"+ins.ID)
+		return nil
+	})
+	return err
+}
+
+// Instances 返回服务当前存活实例（按 ID 排序），并清理过期实例
+func (r *RedisRegistry) Instances(ctx context.Context, service string) ([]Instance, error) {
+	expiryKey, dataKey := r.key(service, "expiry"), r.key(service, "data")
+	now := strconv.FormatInt(r.now().UnixMilli(), 10)
+
+	expired, err := r.rdb.ZRangeByScore(ctx, expiryKey, &redis.ZRangeBy{Min: "-inf", Max: "(" + now}).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(expired) > 0 {
+		members := make([]any, len(expired))
+		for i, id := range expired {
+			members[i] = id
+		}
+		_, err := r.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZRem(ctx, expiryKey, members...)
+			pipe.HDel(ctx, dataKey, expired...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ids, err := r.rdb.ZRangeByScore(ctx, expiryKey, &redis.ZRangeBy{Min: now, Max: "+inf"}).Result()
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+	values, err := r.rdb.HMGet(ctx, dataKey, ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]Instance, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var ins Instance
+		if err := json.Unmarshal([]byte(s), &ins); err != nil {
+			return nil, fmt.Errorf("invalid instance data: %w", err)
+		}
+		list = append(list, ins)
+	}
+	return sortInstances(list), nil
+}
+
+// Watch 监听服务实例变化
+func (r *RedisRegistry) Watch(ctx context.Context, service string) (<-chan []Instance, error) {
+	current, err := r.Instances(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := r.rdb.Subscribe(ctx, r.key(service, "events"))
+	if _, err := sub.Receive(ctx); err != nil {
+		_ = sub.Close()
+		return nil, err
+	}
+
+	ch := make(chan []Instance, 1)
+	ch <- current
+	go func() {
+		defer close(ch)
+		defer sub.Close()
+
+		ticker := time.NewTicker(r.cfg.PollInterval)
+		defer ticker.Stop()
+		events := sub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-events:
+			case <-ticker.C:
+			}
+
+			list, err := r.Instances(ctx, service)
+			if err != nil || slices.EqualFunc(list, current, Instance.Equal) {
+				continue
+			}
+			current = list
+			select {
+			case ch <- list:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,149 @@
+package errors
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/gofiber/fiber/v3"
+	"google.golang.org/grpc/codes"
+)
+
+/* ========================================================================
+ * Error Catalog - 错误码目录
+ * ========================================================================
+ * 职责: 登记业务错误码的所属模块、默认消息与 HTTP/gRPC 映射，并导出为机器可读目录，
+ *       供前端按目录生成客户端错误处理代码
+ * 约定:
+ *   - 通用错误码（1xxx）以模块 "common" 预先登记
+ *   - 业务模块在包级变量中调用 Register 登记并获得预定义错误；同一错误码重复登记会 panic
+ *   - 未指定 HTTP 状态码时沿用 RegisterHTTPStatus / 解析器 / 默认映射（兜底 500），
+ *     未指定 gRPC 状态码时为 Unknown
+ *
+ * 使用示例:
+ *   var ErrOrderPaid = errors.Register(20001, "order", "order already paid",
+ *       errors.WithHTTPStatus(409), errors.WithGRPCCode(codes.FailedPrecondition))
+ *
+ *   app.Get("/api/errors", errors.CatalogHandler())
+ *   // {"code":200,"msg":"ok","data":[{"code":1001,"module":"common","message":"invalid argument",
+ *   //   "http_status":400,"grpc_code":"InvalidArgument"}, ...]}
+ * ======================================================================== */
+
+// CommonModule 通用错误码所属模块
+const CommonModule = "common"
+
+// CatalogEntry 错误码目录项
+type CatalogEntry struct {
+	Code       ErrorCode `json:"code" doc:"业务错误码"`
+	Module     string    `json:"module" doc:"所属模块"`
+	Message    string    `json:"message" doc:"默认消息"`
+	HTTPStatus int       `json:"http_status" doc:"HTTP 状态码"`
+	GRPCCode   string    `json:"grpc_code" doc:"gRPC 状态码名称"`
+}
+
+// catalogDef 登记信息
+type catalogDef struct {
+	module  string
+	message string
+}
+
+// RegisterOption 错误码登记选项
+type RegisterOption func(*registration)
+
+type registration struct {
+	httpStatus int
+	grpcCode   *codes.Code
+}
+
+// WithHTTPStatus 指定 HTTP 状态码（等同 RegisterHTTPStatus）
+func WithHTTPStatus(status int) RegisterOption {
+	return func(r *registration) {
+		r.httpStatus = status
+	}
+}
+
+// WithGRPCCode 指定 gRPC 状态码
+func WithGRPCCode(code codes.Code) RegisterOption {
+	return func(r *registration) {
+		r.grpcCode = &code
+	}
+}
+
+var (
+	catalogMu         sync.RWMutex
+	catalog           = make(map[ErrorCode]catalogDef)
+	grpcCodeOverrides = make(map[ErrorCode]codes.Code)
+)
+
+func init() {
+	for _, e := range []*BizError{
+		New(ErrCodeUnknown, "unknown error"),
+		ErrInvalidArgument, ErrNotFound, ErrAlreadyExists, ErrPermissionDenied,
+		ErrUnauthenticated, ErrInternal, ErrUnavailable, ErrTimeout, ErrCanceled,
+	} {
+		catalog[e.Code] = catalogDef{module: CommonModule, message: e.Message}
+	}
+}
+
+// Register 登记业务错误码并返回对应的预定义错误（便于 errors.Is 判断）
+// 同一错误码重复登记时 panic
+func Register(code ErrorCode, module, message string, opts ...RegisterOption) *BizError {
+	var r registration
+	for _, opt := range opts {
+		opt(&r)
+	}
+
+	catalogMu.Lock()
+	if def, ok := catalog[code]; ok {
+		catalogMu.Unlock()
+		panic(fmt.Sprintf("errors: code %d already registered by module %q", code, def.module))
+	}
+	catalog[code] = catalogDef{module: module, message: message}
+	if r.grpcCode != nil {
+		grpcCodeOverrides[code] = *r.grpcCode
+	}
+	catalogMu.Unlock()
+
+	if r.httpStatus != 0 {
+		RegisterHTTPStatus(code, r.httpStatus)
+	}
+	return New(code, message)
+}
+
+// Catalog 返回已登记的全部错误码（按错误码升序）
+func Catalog() []CatalogEntry {
+	catalogMu.RLock()
+	entries := make([]CatalogEntry, 0, len(catalog))
+	for code, def := range catalog {
+		entries = append(entries, CatalogEntry{Code: code, Module: def.module, Message: def.message})
+	}
+	catalogMu.RUnlock()
+
+	for i := range entries {
+		entries[i].HTTPStatus = httpStatusOf(entries[i].Code)
+		entries[i].GRPCCode = grpcCodeOf(entries[i].Code).String()
+	}
+	slices.SortFunc(entries, func(a, b CatalogEntry) int { return int(a.Code) - int(b.Code) })
+	return entries
+}
+
+// CatalogHandler 以 JSON 输出错误码目录（统一响应结构，目录位于 data）
+func CatalogHandler() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		return c.JSON(HTTPResponse{Code: fiber.StatusOK, Msg: "ok", Data: Catalog()})
+	}
+}
+
+// grpcCodeOf 错误码对应的 gRPC 状态码：登记值优先，其次默认映射，兜底 Unknown
+func grpcCodeOf(code ErrorCode) codes.Code {
+	catalogMu.RLock()
+	grpcCode, ok := grpcCodeOverrides[code]
+	catalogMu.RUnlock()
+	if ok {
+		return grpcCode
+	}
+	if grpcCode, ok := errorCodeToGRPCCode[code]; ok {
+		return grpcCode
+	}
+	return codes.Unknown
+}
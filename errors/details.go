@@ -0,0 +1,184 @@
+package errors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+/* ========================================================================
+ * Error Details - 错误元数据与调用栈
+ * ========================================================================
+ * 职责: 为 BizError 提供结构化详情与可选的调用栈捕获
+ * 传输:
+ *   - HTTP: 响应体 data.details
+ *   - gRPC: status details 中的 errdetails.ErrorInfo（Metadata 为字符串）
+ * 使用示例:
+ *     return errors.ErrAlreadyExists.WithDetail("field", "email")
+ *     errors.EnableStackCapture(true) // 开发环境开启调用栈捕获
+ * ======================================================================== */
+
+// ErrorInfoDomain gRPC ErrorInfo 的 Domain
+const ErrorInfoDomain = "ais-go-pkg"
+
+// errorInfoCodeKey ErrorInfo.Metadata 中保存业务错误码的键
+const errorInfoCodeKey = "biz_code"
+
+// maxStackDepth 调用栈最大深度
+const maxStackDepth = 32
+
+var stackCaptureEnabled atomic.Bool
+
+// EnableStackCapture 开启/关闭调用栈捕获（默认关闭）
+// 开启后 New / Wrap / Wrapf 创建的错误会记录调用栈
+func EnableStackCapture(enabled bool) {
+	stackCaptureEnabled.Store(enabled)
+}
+
+// callers 捕获调用栈，skip 为需要跳过的栈帧数
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+1, pcs)
+	return pcs[:n]
+}
+
+// clone 复制错误（Details 深一层复制），避免修改共享的预定义错误
+func (e *BizError) clone() *BizError {
+	c := *e
+	c.Details = maps.Clone(e.Details)
+	return &c
+}
+
+// WithDetail 返回附加了详情的新错误（不修改原错误）
+func (e *BizError) WithDetail(key string, value any) *BizError {
+	c := e.clone()
+	if c.Details == nil {
+		c.Details = make(map[string]any)
+	}
+	c.Details[key] = value
+	return c
+}
+
+// WithDetails 返回批量附加详情的新错误（不修改原错误）
+func (e *BizError) WithDetails(details map[string]any) *BizError {
+	c := e.clone()
+	if c.Details == nil {
+		c.Details = make(map[string]any, len(details))
+	}
+	maps.Copy(c.Details, details)
+	return c
+}
+
+// WithStack 返回记录了当前调用栈的新错误（无论是否全局开启）
+func (e *BizError) WithStack() *BizError {
+	c := e.clone()
+	c.stack = callers(2)
+	return c
+}
+
+// StackTrace 返回格式化的调用栈，未捕获时返回空字符串
+func (e *BizError) StackTrace() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&sb, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// Format 实现 fmt.Formatter，%+v 输出详情与调用栈
+func (e *BizError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		_, _ = io.WriteString(s, e.Error())
+		if s.Flag('+') {
+			if len(e.Details) > 0 {
+				fmt.Fprintf(s, " details=%v", e.Details)
+			}
+			if stack := e.StackTrace(); stack != "" {
+				_, _ = io.WriteString(s, "\n"+stack)
+			}
+		}
+	case 's':
+		_, _ = io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// =============================================================================
+// gRPC 详情编解码
+// =============================================================================
+
+// toErrorInfo 将业务错误码与详情编码为 ErrorInfo
+func toErrorInfo(e *BizError) *errdetails.ErrorInfo {
+	metadata := make(map[string]string, len(e.Details)+1)
+	for k, v := range e.Details {
+		metadata[k] = detailString(v)
+	}
+	metadata[errorInfoCodeKey] = strconv.Itoa(int(e.Code))
+
+	return &errdetails.ErrorInfo{
+		Reason:   fmt.Sprintf("BIZ_%d", e.Code),
+		Domain:   ErrorInfoDomain,
+		Metadata: metadata,
+	}
+}
+
+// fromErrorInfo 从 gRPC status 中解析业务错误码与详情
+func fromErrorInfo(st *status.Status) (ErrorCode, map[string]any, bool) {
+	for _, d := range st.Details() {
+		info, ok := d.(*errdetails.ErrorInfo)
+		if !ok || info.GetDomain() != ErrorInfoDomain {
+			continue
+		}
+
+		var code ErrorCode
+		details := make(map[string]any, len(info.GetMetadata()))
+		for k, v := range info.GetMetadata() {
+			if k == errorInfoCodeKey {
+				if n, err := strconv.Atoi(v); err == nil {
+					code = ErrorCode(n)
+				}
+				continue
+			}
+			details[k] = v
+		}
+		if len(details) == 0 {
+			details = nil
+		}
+		return code, details, code != 0
+	}
+	return 0, nil, false
+}
+
+// detailString 将详情值转为字符串（非字符串值使用 JSON 编码）
+func detailString(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case fmt.Stringer:
+		return val.String()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
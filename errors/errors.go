@@ -44,9 +44,12 @@ const (
 
 // BizError 业务错误
 type BizError struct {
-	Code    ErrorCode // 业务错误码
-	Message string    // 错误消息
-	Cause   error     // 原始错误
+	Code    ErrorCode      // 业务错误码
+	Message string         // 错误消息
+	Cause   error          // 原始错误
+	Details map[string]any // 结构化详情（如冲突字段），随 HTTP/gRPC 响应返回
+
+	stack []uintptr // 调用栈（EnableStackCapture 开启或 WithStack 时记录）
 }
 
 // Error 实现 error 接口
@@ -77,28 +80,31 @@ func (e *BizError) Unwrap() error {
 
 // New 创建业务错误
 func New(code ErrorCode, message string) *BizError {
-	return &BizError{
-		Code:    code,
-		Message: message,
-	}
+	return newBizError(code, message, nil)
 }
 
 // Wrap 包装错误
 func Wrap(code ErrorCode, message string, cause error) *BizError {
-	return &BizError{
-		Code:    code,
-		Message: message,
-		Cause:   cause,
-	}
+	return newBizError(code, message, cause)
 }
 
 // Wrapf 格式化包装错误
 func Wrapf(code ErrorCode, cause error, format string, args ...any) *BizError {
-	return &BizError{
+	return newBizError(code, fmt.Sprintf(format, args...), cause)
+}
+
+// newBizError 创建业务错误，按需捕获调用栈
+func newBizError(code ErrorCode, message string, cause error) *BizError {
+	e := &BizError{
 		Code:    code,
-		Message: fmt.Sprintf(format, args...),
+		Message: message,
 		Cause:   cause,
 	}
+	if stackCaptureEnabled.Load() {
+		// 跳过 newBizError 与公开构造函数
+		e.stack = callers(3)
+	}
+	return e
 }
 
 // ========================================================================
@@ -178,6 +184,7 @@ var errorCodeToGRPCCode = map[ErrorCode]codes.Code{
 }
 
 // ToGRPCError 将业务错误转换为 gRPC 错误
+// 业务错误码与 Details 通过 errdetails.ErrorInfo 附加到 status details
 func ToGRPCError(err error) error {
 	if err == nil {
 		return nil
@@ -185,11 +192,11 @@ func ToGRPCError(err error) error {
 
 	var bizErr *BizError
 	if errors.As(err, &bizErr) {
-		grpcCode, ok := errorCodeToGRPCCode[bizErr.Code]
-		if !ok {
-			grpcCode = codes.Unknown
+		st := status.New(grpcCodeOf(bizErr.Code), bizErr.Message)
+		if withDetails, err := st.WithDetails(toErrorInfo(bizErr)); err == nil {
+			st = withDetails
 		}
-		return status.Error(grpcCode, bizErr.Message)
+		return st.Err()
 	}
 
 	// 非业务错误，返回 Internal
@@ -207,6 +214,13 @@ func FromGRPCError(err error) *BizError {
 		return Wrap(ErrCodeUnknown, "unknown error", err)
 	}
 
+	// 优先还原 ErrorInfo 中携带的业务错误码与详情
+	if code, details, found := fromErrorInfo(st); found {
+		bizErr := New(code, st.Message())
+		bizErr.Details = details
+		return bizErr
+	}
+
 	// gRPC 状态码到业务错误码映射
 	var code ErrorCode
 	switch st.Code() {
@@ -289,6 +303,17 @@ func resolveHTTPStatus(code ErrorCode) (int, bool) {
 	return 0, false
 }
 
+// httpStatusOf 错误码对应的 HTTP 状态码：注册映射与解析器优先，其次默认映射，兜底 500
+func httpStatusOf(code ErrorCode) int {
+	if status, ok := resolveHTTPStatus(code); ok {
+		return status
+	}
+	if status, ok := httpStatusCode[code]; ok {
+		return status
+	}
+	return 500
+}
+
 // HTTPResponse HTTP 响应结构
 type HTTPResponse struct {
 	Code int    `json:"code"`
@@ -297,6 +322,7 @@ type HTTPResponse struct {
 }
 
 // ToHTTPResponse 将业务错误转换为 HTTP 响应
+// BizError 带有 Details 时输出到 data.details
 func ToHTTPResponse(err error) (int, fiber.Map) {
 	if err == nil {
 		return 200, fiber.Map{"code": 0, "msg": "success"}
@@ -304,17 +330,15 @@ func ToHTTPResponse(err error) (int, fiber.Map) {
 
 	var bizErr *BizError
 	if errors.As(err, &bizErr) {
-		statusCode, ok := resolveHTTPStatus(bizErr.Code)
-		if !ok {
-			statusCode, ok = httpStatusCode[bizErr.Code]
-			if !ok {
-				statusCode = 500
-			}
-		}
-		return statusCode, fiber.Map{
+		statusCode := httpStatusOf(bizErr.Code)
+		body := fiber.Map{
 			"code": int(bizErr.Code),
 			"msg":  bizErr.Message,
 		}
+		if len(bizErr.Details) > 0 {
+			body["data"] = fiber.Map{"details": bizErr.Details}
+		}
+		return statusCode, body
 	}
 
 	// 非业务错误
@@ -1,11 +1,19 @@
 package errors
 
 import (
+	"context"
+	"encoding/json"
 	errorspkg "errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/go-sql-driver/mysql"
+	"github.com/gofiber/fiber/v3"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
 )
 
 func resetHTTPOverrides() {
@@ -83,3 +91,207 @@ func TestToHTTPResponse(t *testing.T) {
 		t.Fatalf("expected resolver status, got: %d", statusCode)
 	}
 }
+
+func TestWithDetailDoesNotMutateOriginal(t *testing.T) {
+	err := ErrAlreadyExists.WithDetail("field", "email")
+	if len(ErrAlreadyExists.Details) != 0 {
+		t.Fatalf("sentinel error should not be mutated: %v", ErrAlreadyExists.Details)
+	}
+	if err.Details["field"] != "email" {
+		t.Fatalf("unexpected details: %v", err.Details)
+	}
+	if !Is(err, ErrAlreadyExists) {
+		t.Fatalf("expected errors.Is to match sentinel")
+	}
+
+	more := err.WithDetails(map[string]any{"value": "a@b.c"})
+	if len(err.Details) != 1 || len(more.Details) != 2 {
+		t.Fatalf("unexpected details: %v / %v", err.Details, more.Details)
+	}
+}
+
+func TestToHTTPResponseDetails(t *testing.T) {
+	_, body := ToHTTPResponse(ErrAlreadyExists.WithDetail("field", "email"))
+	data, ok := body["data"].(fiber.Map)
+	if !ok {
+		t.Fatalf("expected data in body: %v", body)
+	}
+	details, ok := data["details"].(map[string]any)
+	if !ok || details["field"] != "email" {
+		t.Fatalf("unexpected data.details: %v", data)
+	}
+
+	_, body = ToHTTPResponse(New(ErrCodeNotFound, "missing"))
+	if _, ok := body["data"]; ok {
+		t.Fatalf("data should be omitted without details: %v", body)
+	}
+}
+
+func TestGRPCDetailsRoundTrip(t *testing.T) {
+	const code ErrorCode = 2001
+	err := New(code, "conflict").WithDetails(map[string]any{"field": "email", "count": 2})
+
+	grpcErr := ToGRPCError(err)
+	st, _ := status.FromError(grpcErr)
+	if st.Code() != codes.Unknown {
+		t.Fatalf("unexpected grpc code: %v", st.Code())
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("expected one status detail, got %d", len(st.Details()))
+	}
+
+	bizErr := FromGRPCError(grpcErr)
+	if bizErr.Code != code {
+		t.Fatalf("expected business code restored, got %v", bizErr.Code)
+	}
+	if bizErr.Details["field"] != "email" || bizErr.Details["count"] != "2" {
+		t.Fatalf("unexpected details: %v", bizErr.Details)
+	}
+}
+
+func TestStackCapture(t *testing.T) {
+	if New(ErrCodeInternal, "no stack").StackTrace() != "" {
+		t.Fatalf("stack should not be captured by default")
+	}
+
+	EnableStackCapture(true)
+	defer EnableStackCapture(false)
+
+	err := Wrap(ErrCodeInternal, "boom", errorspkg.New("root"))
+	if !strings.Contains(err.StackTrace(), "TestStackCapture") {
+		t.Fatalf("expected caller in stack trace:\n%s", err.StackTrace())
+	}
+	if !strings.Contains(fmt.Sprintf("%+v", err), "TestStackCapture") {
+		t.Fatalf("expected %%+v to include stack trace")
+	}
+	if fmt.Sprintf("%v", err) != err.Error() {
+		t.Fatalf("%%v should match Error()")
+	}
+}
+
+type fakePgError struct{ code string }
+
+func (e *fakePgError) Error() string    { return "pg error " + e.code }
+func (e *fakePgError) SQLState() string { return e.code }
+
+func TestFromGORM(t *testing.T) {
+	if FromGORM(nil) != nil {
+		t.Fatalf("expected nil for nil error")
+	}
+
+	cases := []struct {
+		name      string
+		err       error
+		code      ErrorCode
+		retryable bool
+	}{
+		{"not found", gorm.ErrRecordNotFound, ErrCodeNotFound, false},
+		{"wrapped not found", fmt.Errorf("query: %w", gorm.ErrRecordNotFound), ErrCodeNotFound, false},
+		{"gorm duplicated", gorm.ErrDuplicatedKey, ErrCodeAlreadyExists, false},
+		{"mysql duplicate", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}, ErrCodeAlreadyExists, false},
+		{"mysql fk", &mysql.MySQLError{Number: 1452}, ErrCodeInvalidArgument, false},
+		{"mysql deadlock", &mysql.MySQLError{Number: 1213}, ErrCodeUnavailable, true},
+		{"pg unique", &fakePgError{code: "23505"}, ErrCodeAlreadyExists, false},
+		{"pg fk", &fakePgError{code: "23503"}, ErrCodeInvalidArgument, false},
+		{"pg deadlock", &fakePgError{code: "40P01"}, ErrCodeUnavailable, true},
+		{"sqlite unique", errorspkg.New("UNIQUE constraint failed: users.email"), ErrCodeAlreadyExists, false},
+		{"timeout", context.DeadlineExceeded, ErrCodeTimeout, false},
+		{"other", errorspkg.New("connection reset"), ErrCodeInternal, false},
+	}
+	for _, tc := range cases {
+		err := FromGORM(tc.err)
+		if Code(err) != tc.code {
+			t.Fatalf("%s: expected code %d, got %d", tc.name, tc.code, Code(err))
+		}
+		if IsRetryable(err) != tc.retryable {
+			t.Fatalf("%s: unexpected retryable flag", tc.name)
+		}
+		if !errorspkg.Is(err, tc.err) {
+			t.Fatalf("%s: original error should be preserved", tc.name)
+		}
+	}
+
+	bizErr := ErrPermissionDenied
+	if FromGORM(bizErr) != error(bizErr) {
+		t.Fatalf("BizError should be returned as is")
+	}
+}
+
+func unregister(code ErrorCode) {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+	delete(catalog, code)
+	delete(grpcCodeOverrides, code)
+}
+
+func TestCatalogRegister(t *testing.T) {
+	resetHTTPOverrides()
+	t.Cleanup(resetHTTPOverrides)
+	t.Cleanup(func() { unregister(20001) })
+
+	errPaid := Register(20001, "order", "order already paid",
+		WithHTTPStatus(409), WithGRPCCode(codes.FailedPrecondition))
+	if !Is(Wrap(20001, "paid twice", nil), errPaid) {
+		t.Fatal("registered error should match by code")
+	}
+
+	var found bool
+	var prev ErrorCode
+	for _, e := range Catalog() {
+		if e.Code < prev {
+			t.Fatalf("catalog not sorted: %d after %d", e.Code, prev)
+		}
+		prev = e.Code
+		switch e.Code {
+		case ErrCodeNotFound:
+			if e.Module != CommonModule || e.Message != "resource not found" || e.HTTPStatus != 404 || e.GRPCCode != "NotFound" {
+				t.Fatalf("not found entry = %+v", e)
+			}
+		case 20001:
+			found = true
+			if e.Module != "order" || e.HTTPStatus != 409 || e.GRPCCode != "FailedPrecondition" {
+				t.Fatalf("order entry = %+v", e)
+			}
+		}
+	}
+	if !found {
+		t.Fatal("registered code missing from catalog")
+	}
+
+	if st, _ := status.FromError(ToGRPCError(errPaid)); st.Code() != codes.FailedPrecondition {
+		t.Fatalf("grpc code = %v, want FailedPrecondition", st.Code())
+	}
+	if code, _ := ToHTTPResponse(errPaid); code != 409 {
+		t.Fatalf("http status = %d, want 409", code)
+	}
+}
+
+func TestCatalogDuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on duplicate code")
+		}
+	}()
+	Register(ErrCodeNotFound, "order", "duplicate")
+}
+
+func TestCatalogHandler(t *testing.T) {
+	app := fiber.New()
+	app.Get("/errors", CatalogHandler())
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/errors", nil))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Code int            `json:"code"`
+		Data []CatalogEntry `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Code != 200 || len(body.Data) < 10 || body.Data[0].Code != ErrCodeUnknown {
+		t.Fatalf("body = %+v", body)
+	}
+}
@@ -0,0 +1,146 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * GORM Error Translation - 数据库错误转换
+ * ========================================================================
+ * 职责: 将 GORM / 驱动错误统一转换为 BizError，避免业务层字符串匹配
+ * 映射:
+ *   - gorm.ErrRecordNotFound          -> NotFound
+ *   - 唯一键冲突 (MySQL 1062 / PG 23505) -> AlreadyExists
+ *   - 外键约束 (MySQL 1451,1452 / PG 23503) -> InvalidArgument
+ *   - 死锁/锁等待 (MySQL 1213,1205 / PG 40P01,40001) -> Unavailable（可重试）
+ *   - context 超时/取消              -> Timeout / Canceled
+ *   - 其他                            -> Internal
+ * 原始错误保留在 Cause 中，errors.Is(err, gorm.ErrRecordNotFound) 仍然成立
+ * ======================================================================== */
+
+// RetryableDetailKey 可重试错误在 Details 中的标记键
+const RetryableDetailKey = "retryable"
+
+// MySQL 错误号
+const (
+	mysqlDuplicateEntry    = 1062
+	mysqlRowIsReferenced   = 1451
+	mysqlNoReferencedRow   = 1452
+	mysqlLockWaitTimeout   = 1205
+	mysqlDeadlockDetected  = 1213
+	mysqlRowIsReferenced2  = 1217
+	mysqlNoReferencedRow2  = 1216
+	mysqlCheckConstraint   = 3819
+	mysqlBadNullError      = 1048
+	mysqlDataTooLongForCol = 1406
+)
+
+// PostgreSQL SQLSTATE
+const (
+	pgUniqueViolation      = "23505"
+	pgForeignKeyViolation  = "23503"
+	pgNotNullViolation     = "23502"
+	pgCheckViolation       = "23514"
+	pgDeadlockDetected     = "40P01"
+	pgSerializationFailure = "40001"
+)
+
+// sqlStateError 暴露 SQLSTATE 的驱动错误（如 pgconn.PgError）
+type sqlStateError interface {
+	SQLState() string
+}
+
+// FromGORM 将 GORM 或数据库驱动错误转换为业务错误
+// err 为 nil 时返回 nil；已是 BizError 时原样返回
+func FromGORM(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var bizErr *BizError
+	if errors.As(err, &bizErr) {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return Wrap(ErrCodeNotFound, "resource not found", err)
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return Wrap(ErrCodeAlreadyExists, "resource already exists", err)
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return Wrap(ErrCodeInvalidArgument, "foreign key constraint violated", err)
+	case errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return Wrap(ErrCodeInvalidArgument, "check constraint violated", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return Wrap(ErrCodeTimeout, "database operation timeout", err)
+	case errors.Is(err, context.Canceled):
+		return Wrap(ErrCodeCanceled, "database operation canceled", err)
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlDuplicateEntry:
+			return Wrap(ErrCodeAlreadyExists, "resource already exists", err)
+		case mysqlRowIsReferenced, mysqlRowIsReferenced2, mysqlNoReferencedRow, mysqlNoReferencedRow2:
+			return Wrap(ErrCodeInvalidArgument, "foreign key constraint violated", err)
+		case mysqlCheckConstraint, mysqlBadNullError, mysqlDataTooLongForCol:
+			return Wrap(ErrCodeInvalidArgument, "constraint violated", err)
+		case mysqlDeadlockDetected, mysqlLockWaitTimeout:
+			return retryable(err)
+		}
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		switch stateErr.SQLState() {
+		case pgUniqueViolation:
+			return Wrap(ErrCodeAlreadyExists, "resource already exists", err)
+		case pgForeignKeyViolation:
+			return Wrap(ErrCodeInvalidArgument, "foreign key constraint violated", err)
+		case pgNotNullViolation, pgCheckViolation:
+			return Wrap(ErrCodeInvalidArgument, "constraint violated", err)
+		case pgDeadlockDetected, pgSerializationFailure:
+			return retryable(err)
+		}
+	}
+
+	// SQLite 驱动未开启 TranslateError 时只能通过消息识别
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "UNIQUE constraint failed"):
+		return Wrap(ErrCodeAlreadyExists, "resource already exists", err)
+	case strings.Contains(msg, "FOREIGN KEY constraint failed"):
+		return Wrap(ErrCodeInvalidArgument, "foreign key constraint violated", err)
+	case strings.Contains(msg, "database is locked"):
+		return retryable(err)
+	}
+
+	return Wrap(ErrCodeInternal, "database error", err)
+}
+
+// retryable 构造可重试的 Unavailable 错误
+func retryable(err error) *BizError {
+	return Wrap(ErrCodeUnavailable, "database busy, please retry", err).
+		WithDetail(RetryableDetailKey, true)
+}
+
+// IsRetryable 判断错误是否标记为可重试（兼容经 gRPC 传输后的字符串值）
+func IsRetryable(err error) bool {
+	bizErr, ok := AsBizError(err)
+	if !ok {
+		return false
+	}
+	switch v := bizErr.Details[RetryableDetailKey].(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true"
+	}
+	return false
+}
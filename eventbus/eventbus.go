@@ -0,0 +1,313 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/mq"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Event Bus - 领域事件总线
+ * ========================================================================
+ * 职责: 统一的领域事件发布/订阅，单体与微服务部署下业务代码一致
+ * 模式（与 transport/grpc 的 mode 取值一致）:
+ *   - monolith:     进程内分发，同步调用订阅者，无序列化开销
+ *   - microservice: 经 MQ 分发，每种事件一个 Topic（TopicPrefix + 事件名），
+ *                   事件以 JSON 序列化，订阅者失败时由 MQ 重投（至少一次）
+ * 说明: MQ 模式下须在 Consumer.Start 之前完成订阅（在 fx.Invoke 中订阅即可）
+ *
+ * 使用示例:
+ *   type OrderPaid struct { OrderID string `json:"order_id"` }
+ *   func (OrderPaid) EventName() string { return "order.paid" }
+ *
+ *   _ = eventbus.Subscribe(bus, func(ctx context.Context, e OrderPaid) error {
+ *       return notifyUser(ctx, e.OrderID)
+ *   })
+ *   _ = bus.Publish(ctx, OrderPaid{OrderID: "01H..."})
+ * ======================================================================== */
+
+// 部署模式
+const (
+	ModeMonolith     = "monolith"
+	ModeMicroservice = "microservice"
+)
+
+var (
+	// ErrBusClosed 事件总线已关闭
+	ErrBusClosed = errors.New("event bus is closed")
+	// ErrInvalidEvent 事件无效（nil 或事件名为空）
+	ErrInvalidEvent = errors.New("invalid event")
+)
+
+// Event 领域事件，EventName 需为值接收者（订阅时通过零值获取事件名）
+type Event interface {
+	EventName() string
+}
+
+// KeyedEvent 携带消息键的事件，MQ 模式下用于分区/顺序
+type KeyedEvent interface {
+	Event
+	EventKey() string
+}
+
+// Handler 事件处理函数
+type Handler[T Event] func(ctx context.Context, event T) error
+
+// Config 事件总线配置
+type Config struct {
+	// Mode monolith / microservice，为空时沿用 gRPC 配置的 mode，仍为空则为 monolith
+	Mode string `yaml:"mode"`
+	// TopicPrefix MQ Topic 前缀，默认 "event."
+	TopicPrefix string `yaml:"topic_prefix"`
+}
+
+// DefaultTopicPrefix 默认 Topic 前缀
+const DefaultTopicPrefix = "event."
+
+func (c Config) withDefaults() Config {
+	if c.Mode == "" {
+		c.Mode = ModeMonolith
+	}
+	if c.TopicPrefix == "" {
+		c.TopicPrefix = DefaultTopicPrefix
+	}
+	return c
+}
+
+// subscriber 类型擦除后的订阅者
+type subscriber struct {
+	handle func(ctx context.Context, event any) error
+	decode func(data []byte) (any, error)
+}
+
+// Bus 事件总线
+type Bus struct {
+	cfg      Config
+	log      *logger.Logger
+	producer mq.Producer
+	consumer mq.Consumer
+
+	mu     sync.RWMutex
+	subs   map[string][]subscriber
+	closed bool
+}
+
+// NewLocal 创建进程内事件总线
+func NewLocal(log *logger.Logger) *Bus {
+	bus, _ := New(Config{Mode: ModeMonolith}, nil, nil, log)
+	return bus
+}
+
+// New 创建事件总线
+// microservice 模式下 producer 必填，consumer 可为 nil（仅发布场景）
+func New(cfg Config, producer mq.Producer, consumer mq.Consumer, log *logger.Logger) (*Bus, error) {
+	cfg = cfg.withDefaults()
+	switch cfg.Mode {
+	case ModeMonolith:
+	case ModeMicroservice:
+		if producer == nil {
+			return nil, fmt.Errorf("eventbus: mq producer is required in %s mode", ModeMicroservice)
+		}
+	default:
+		return nil, fmt.Errorf("eventbus: unknown mode %q", cfg.Mode)
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &Bus{
+		cfg:      cfg,
+		log:      log,
+		producer: producer,
+		consumer: consumer,
+		subs:     make(map[string][]subscriber),
+	}, nil
+}
+
+// Mode 当前部署模式
+func (b *Bus) Mode() string {
+	return b.cfg.Mode
+}
+
+// Topic 返回事件对应的 MQ Topic
+func (b *Bus) Topic(eventName string) string {
+	return b.cfg.TopicPrefix + eventName
+}
+
+// Publish 发布事件
+// monolith 模式同步调用全部订阅者并返回合并后的错误；microservice 模式同步发送到 MQ
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	if event == nil || isNilEvent(event) {
+		return ErrInvalidEvent
+	}
+	name := event.EventName()
+	if name == "" {
+		return ErrInvalidEvent
+	}
+
+	b.mu.RLock()
+	closed := b.closed
+	subs := b.subs[name]
+	b.mu.RUnlock()
+	if closed {
+		return ErrBusClosed
+	}
+
+	if b.cfg.Mode == ModeMonolith {
+		return b.dispatch(ctx, name, subs, event)
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("eventbus: marshal %s: %w", name, err)
+	}
+	msg := mq.NewMessage(b.Topic(name), data).WithTag(name).WithProperty("event", name)
+	if keyed, ok := event.(KeyedEvent); ok {
+		msg.WithKey(keyed.EventKey())
+	}
+	if _, err := b.producer.SendSync(ctx, msg); err != nil {
+		return fmt.Errorf("eventbus: publish %s: %w", name, err)
+	}
+	return nil
+}
+
+// Subscribe 订阅事件类型 T
+func Subscribe[T Event](b *Bus, handler Handler[T]) error {
+	if handler == nil {
+		return fmt.Errorf("eventbus: handler is required")
+	}
+	name := eventName[T]()
+	if name == "" {
+		return fmt.Errorf("eventbus: %T has empty event name", *new(T))
+	}
+
+	sub := subscriber{
+		handle: func(ctx context.Context, event any) error {
+			e, ok := event.(T)
+			if !ok {
+				return fmt.Errorf("eventbus: unexpected event type %T for %s", event, name)
+			}
+			return handler(ctx, e)
+		},
+		decode: func(data []byte) (any, error) {
+			var e T
+			if t := reflect.TypeFor[T](); t.Kind() == reflect.Pointer {
+				e = reflect.New(t.Elem()).Interface().(T)
+				return e, json.Unmarshal(data, e)
+			}
+			err := json.Unmarshal(data, &e)
+			return e, err
+		},
+	}
+	return b.subscribe(name, sub)
+}
+
+func (b *Bus) subscribe(name string, sub subscriber) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrBusClosed
+	}
+
+	first := len(b.subs[name]) == 0
+	b.subs[name] = append(b.subs[name], sub)
+
+	// MQ 模式下每种事件只订阅一次 Topic，由本地订阅者列表扇出
+	if first && b.cfg.Mode == ModeMicroservice && b.consumer != nil {
+		if err := b.consumer.Subscribe(b.Topic(name), b.mqHandler(name)); err != nil {
+			b.subs[name] = b.subs[name][:0]
+			return fmt.Errorf("eventbus: subscribe %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// mqHandler MQ 消费回调：解码后分发给本地订阅者，任一失败则整体重投
+func (b *Bus) mqHandler(name string) mq.MessageHandler {
+	return func(ctx context.Context, msgs []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+		b.mu.RLock()
+		subs := b.subs[name]
+		b.mu.RUnlock()
+
+		for _, msg := range msgs {
+			var errs []error
+			for _, sub := range subs {
+				event, err := sub.decode(msg.Body)
+				if err != nil {
+					// 无法解析的消息不重投
+					b.log.Error("eventbus: failed to decode event",
+						zap.String("event", name), zap.String("msg_id", msg.MsgID), zap.Error(err))
+					continue
+				}
+				if err := b.invoke(ctx, name, sub, event); err != nil {
+					errs = append(errs, err)
+				}
+			}
+			if err := errors.Join(errs...); err != nil {
+				return mq.ConsumeRetryLater, err
+			}
+		}
+		return mq.ConsumeSuccess, nil
+	}
+}
+
+// dispatch 进程内分发
+func (b *Bus) dispatch(ctx context.Context, name string, subs []subscriber, event any) error {
+	var errs []error
+	for _, sub := range subs {
+		if err := b.invoke(ctx, name, sub, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// invoke 调用订阅者，panic 转换为错误
+func (b *Bus) invoke(ctx context.Context, name string, sub subscriber, event any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.log.Error("eventbus: handler panic recovered",
+				zap.String("event", name),
+				zap.Any("panic", r),
+				zap.String("stack", string(debug.Stack())),
+			)
+			err = fmt.Errorf("eventbus: handler panic: %v", r)
+		}
+	}()
+	if err = sub.handle(ctx, event); err != nil {
+		b.log.Warn("eventbus: handler failed", zap.String("event", name), zap.Error(err))
+	}
+	return err
+}
+
+// Close 关闭事件总线，之后的发布与订阅返回 ErrBusClosed
+// MQ Producer / Consumer 的生命周期由 mq.Module 管理
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	b.closed = true
+	b.mu.Unlock()
+	return nil
+}
+
+// eventName 通过 T 的零值获取事件名（指针类型使用新分配的元素）
+func eventName[T Event]() string {
+	var zero T
+	if t := reflect.TypeFor[T](); t.Kind() == reflect.Pointer {
+		zero = reflect.New(t.Elem()).Interface().(T)
+	}
+	return zero.EventName()
+}
+
+// isNilEvent 判断接口内是否为 nil 指针
+func isNilEvent(event Event) bool {
+	rv := reflect.ValueOf(event)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
@@ -0,0 +1,180 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+	grpcx "github.com/aisgo/ais-go-pkg/transport/grpc"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+)
+
+type orderPaid struct {
+	OrderID string `json:"order_id"`
+	Amount  int64  `json:"amount"`
+}
+
+func (orderPaid) EventName() string  { return "order.paid" }
+func (e orderPaid) EventKey() string { return e.OrderID }
+
+type userCreated struct {
+	UserID string `json:"user_id"`
+}
+
+func (*userCreated) EventName() string { return "user.created" }
+
+// fakeMQ 内存 MQ：SendSync 直接投递给订阅回调
+type fakeMQ struct {
+	mu       sync.Mutex
+	handlers map[string]mq.MessageHandler
+	sent     []*mq.Message
+	results  []mq.ConsumeResult
+}
+
+func newFakeMQ() *fakeMQ {
+	return &fakeMQ{handlers: map[string]mq.MessageHandler{}}
+}
+
+func (f *fakeMQ) SendSync(ctx context.Context, msg *mq.Message) (*mq.SendResult, error) {
+	f.mu.Lock()
+	f.sent = append(f.sent, msg)
+	h := f.handlers[msg.Topic]
+	f.mu.Unlock()
+	if h != nil {
+		res, _ := h(ctx, []*mq.ConsumedMessage{{Topic: msg.Topic, Body: msg.Body, Key: msg.Key, Tag: msg.Tag}})
+		f.mu.Lock()
+		f.results = append(f.results, res)
+		f.mu.Unlock()
+	}
+	return &mq.SendResult{Topic: msg.Topic}, nil
+}
+
+func (f *fakeMQ) SendAsync(ctx context.Context, msg *mq.Message, cb mq.SendCallback) error {
+	cb(f.SendSync(ctx, msg))
+	return nil
+}
+
+func (f *fakeMQ) Subscribe(topic string, handler mq.MessageHandler) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.handlers[topic]; ok {
+		return errors.New("duplicate subscription")
+	}
+	f.handlers[topic] = handler
+	return nil
+}
+
+func (f *fakeMQ) Start() error { return nil }
+func (f *fakeMQ) Close() error { return nil }
+
+func TestLocalBus(t *testing.T) {
+	bus := NewLocal(nil)
+
+	var got []string
+	if err := Subscribe(bus, func(ctx context.Context, e orderPaid) error {
+		got = append(got, "a:"+e.OrderID)
+		return nil
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := Subscribe(bus, func(ctx context.Context, e orderPaid) error {
+		got = append(got, "b:"+e.OrderID)
+		return errors.New("boom")
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := Subscribe(bus, func(ctx context.Context, e *userCreated) error {
+		panic("bad handler")
+	}); err != nil {
+		t.Fatalf("subscribe pointer event: %v", err)
+	}
+
+	err := bus.Publish(context.Background(), orderPaid{OrderID: "o1"})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected handler error, got %v", err)
+	}
+	if strings.Join(got, ",") != "a:o1,b:o1" {
+		t.Fatalf("unexpected dispatch: %v", got)
+	}
+
+	if err := bus.Publish(context.Background(), &userCreated{UserID: "u1"}); err == nil || !strings.Contains(err.Error(), "panic") {
+		t.Fatalf("expected panic to be converted to error, got %v", err)
+	}
+	var nilEvent *userCreated
+	if err := bus.Publish(context.Background(), nilEvent); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("expected ErrInvalidEvent, got %v", err)
+	}
+
+	_ = bus.Close()
+	if err := bus.Publish(context.Background(), orderPaid{}); !errors.Is(err, ErrBusClosed) {
+		t.Fatalf("expected ErrBusClosed, got %v", err)
+	}
+}
+
+func TestMQBus(t *testing.T) {
+	fake := newFakeMQ()
+	bus, err := New(Config{Mode: ModeMicroservice}, fake, fake, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	var got []orderPaid
+	for range 2 {
+		if err := Subscribe(bus, func(ctx context.Context, e orderPaid) error {
+			got = append(got, e)
+			return nil
+		}); err != nil {
+			t.Fatalf("subscribe: %v", err)
+		}
+	}
+	if err := Subscribe(bus, func(ctx context.Context, e *userCreated) error {
+		return errors.New("retry me")
+	}); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	if err := bus.Publish(context.Background(), orderPaid{OrderID: "o1", Amount: 100}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if len(fake.sent) != 1 || fake.sent[0].Topic != "event.order.paid" || fake.sent[0].Key != "o1" {
+		t.Fatalf("unexpected message: %+v", fake.sent)
+	}
+	if len(got) != 2 || got[0].Amount != 100 {
+		t.Fatalf("expected both subscribers to receive decoded event, got %+v", got)
+	}
+
+	if err := bus.Publish(context.Background(), &userCreated{UserID: "u1"}); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if fake.results[1] != mq.ConsumeRetryLater {
+		t.Fatalf("expected failed handler to request redelivery, got %v", fake.results[1])
+	}
+}
+
+func TestModuleInheritsGRPCMode(t *testing.T) {
+	if _, err := New(Config{Mode: ModeMicroservice}, nil, nil, nil); err == nil {
+		t.Fatalf("expected producer to be required")
+	}
+	if _, err := New(Config{Mode: "cluster"}, nil, nil, nil); err == nil {
+		t.Fatalf("expected unknown mode error")
+	}
+
+	var bus *Bus
+	app := fxtest.New(t,
+		fx.Supply(grpcx.Config{Mode: ModeMicroservice}),
+		fx.Provide(func() mq.Producer { return newFakeMQ() }),
+		Module,
+		fx.Populate(&bus),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	if bus.Mode() != ModeMicroservice {
+		t.Fatalf("unexpected mode %q", bus.Mode())
+	}
+}
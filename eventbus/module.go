@@ -0,0 +1,58 @@
+package eventbus
+
+import (
+	"context"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/mq"
+	grpcx "github.com/aisgo/ais-go-pkg/transport/grpc"
+
+	"go.uber.org/fx"
+)
+
+/* ========================================================================
+ * EventBus FX Module - 事件总线 FX 模块
+ * ========================================================================
+ * 职责: 按部署模式提供 *Bus
+ * 说明: Config.Mode 为空时沿用 gRPC 配置的 mode，保证两者一致；
+ *       microservice 模式需同时引入 mq.Module 提供 Producer / Consumer
+ * ======================================================================== */
+
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	Lc       fx.Lifecycle
+	Config   *Config        `optional:"true"`
+	GRPC     grpcx.Config   `optional:"true"`
+	Producer mq.Producer    `optional:"true"`
+	Consumer mq.Consumer    `optional:"true"`
+	Logger   *logger.Logger `optional:"true"`
+}
+
+// NewFromParams 从 FX 参数创建事件总线
+func NewFromParams(p Params) (*Bus, error) {
+	var cfg Config
+	if p.Config != nil {
+		cfg = *p.Config
+	}
+	if cfg.Mode == "" {
+		cfg.Mode = p.GRPC.Mode
+	}
+
+	bus, err := New(cfg, p.Producer, p.Consumer, p.Logger)
+	if err != nil {
+		return nil, err
+	}
+	p.Lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return bus.Close()
+		},
+	})
+	return bus, nil
+}
+
+// Module FX 模块
+var Module = fx.Module("eventbus",
+	fx.Provide(NewFromParams),
+)
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvWriter CSV 写入器
+type csvWriter struct {
+	w          *csv.Writer
+	record     []string
+	timeLayout string
+}
+
+func newCSVWriter(w io.Writer, headers []string, opts Options) (*csvWriter, error) {
+	if opts.BOM {
+		if _, err := io.WriteString(w, "\ufeff"); err != nil {
+			return nil, err
+		}
+	}
+	cw := &csvWriter{w: csv.NewWriter(w), record: make([]string, len(headers)), timeLayout: opts.TimeLayout}
+	if err := cw.w.Write(headers); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// WriteRow 写入一行，字符串值防御公式注入
+func (cw *csvWriter) WriteRow(values []any) error {
+	for i, v := range values {
+		s := formatValue(v, cw.timeLayout)
+		if _, ok := v.(string); ok {
+			s = escapeFormula(s)
+		}
+		cw.record[i] = s
+	}
+	return cw.w.Write(cw.record)
+}
+
+// Close 刷新缓冲
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// escapeFormula 以 = + - @ 等开头的文本在 Excel 中会被当作公式执行，前置单引号转义
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
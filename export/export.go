@@ -0,0 +1,235 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * Export - 仓储查询流式导出
+ * ========================================================================
+ * 职责: 按批次读取仓储查询结果，按列映射流式写出 CSV / XLSX，避免整表加载到内存
+ * 租户: 通过仓储 FindBySpec 查询，自动应用 ctx 中的租户/部门范围
+ * 限制: MaxRows 限制最大导出行数，超出时截断并在 Result.Truncated 中标记
+ *
+ * 使用示例:
+ *   cols := []export.Column[Order]{
+ *       export.Col("订单号", func(o *Order) any { return o.No }),
+ *       export.Col("金额", func(o *Order) any { return o.Amount }),
+ *       export.Col("创建时间", func(o *Order) any { return o.CreatedAt }),
+ *   }
+ *   res, err := export.Export(ctx, w, orderRepo, repository.Eq[Order]("status", "paid"), cols,
+ *       export.Options{Format: export.FormatXLSX, MaxRows: 50000})
+ *
+ *   // Fiber 下载
+ *   return export.Fiber(c, "orders.csv", orderRepo, spec, cols, export.Options{Format: export.FormatCSV})
+ * ======================================================================== */
+
+// Format 导出格式
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+)
+
+const (
+	// DefaultBatchSize 默认每批读取行数
+	DefaultBatchSize = 500
+	// DefaultMaxRows 默认最大导出行数
+	DefaultMaxRows = 100000
+	// DefaultTimeLayout 默认时间格式
+	DefaultTimeLayout = time.DateTime
+	// DefaultStreamTimeout Fiber 流式导出的默认超时
+	DefaultStreamTimeout = 10 * time.Minute
+)
+
+// Column 导出列
+type Column[T any] struct {
+	Header string
+	Value  func(model *T) any
+}
+
+// Col 创建导出列
+func Col[T any](header string, value func(model *T) any) Column[T] {
+	return Column[T]{Header: header, Value: value}
+}
+
+// Options 导出选项
+type Options struct {
+	Format     Format
+	BatchSize  int    // 每批读取行数，默认 500（受 repository.MaxPageSize 约束）
+	MaxRows    int    // 最大导出行数，默认 100000，<0 表示不限制
+	OrderBy    string // 排序（批次间需稳定），默认 "id ASC"
+	TimeLayout string // time.Time 格式，默认 "2006-01-02 15:04:05"
+	SheetName  string // XLSX 工作表名，默认 Sheet1
+	BOM        bool   // CSV 输出 UTF-8 BOM（兼容 Excel 打开中文）
+
+	// Timeout Fiber 流式导出的整体超时，默认 10 分钟，<0 表示不限制（不受请求超时约束）
+	Timeout time.Duration
+
+	// OnProgress 每批写出后回调，参数为累计行数
+	OnProgress func(rows int64)
+	// OnError 流式响应中途出错时回调（响应头已发送，仅能截断输出）
+	OnError func(err error)
+}
+
+func (o Options) withDefaults() Options {
+	if o.Format == "" {
+		o.Format = FormatCSV
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = DefaultBatchSize
+	}
+	if o.BatchSize > repository.MaxPageSize {
+		o.BatchSize = repository.MaxPageSize
+	}
+	if o.MaxRows == 0 {
+		o.MaxRows = DefaultMaxRows
+	}
+	if o.OrderBy == "" {
+		o.OrderBy = "id ASC"
+	}
+	if o.TimeLayout == "" {
+		o.TimeLayout = DefaultTimeLayout
+	}
+	if o.SheetName == "" {
+		o.SheetName = "Sheet1"
+	}
+	if o.Timeout == 0 {
+		o.Timeout = DefaultStreamTimeout
+	}
+	return o
+}
+
+// Result 导出结果
+type Result struct {
+	Rows      int64 // 写出的数据行数（不含表头）
+	Truncated bool  // 是否因 MaxRows 被截断
+}
+
+// rowWriter 格式写入器
+type rowWriter interface {
+	WriteRow(values []any) error
+	Close() error
+}
+
+// newRowWriter 创建格式写入器并写出表头
+func newRowWriter(w io.Writer, headers []string, opts Options) (rowWriter, error) {
+	switch opts.Format {
+	case FormatCSV:
+		return newCSVWriter(w, headers, opts)
+	case FormatXLSX:
+		return newXLSXWriter(w, headers, opts)
+	default:
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "unsupported export format: "+string(opts.Format))
+	}
+}
+
+// Export 将规约查询结果按批次流式写出到 w
+func Export[T any](ctx context.Context, w io.Writer, repo repository.SpecificationRepository[T], spec repository.Specification[T], cols []Column[T], opts Options) (*Result, error) {
+	opts = opts.withDefaults()
+	if len(cols) == 0 {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "export columns cannot be empty")
+	}
+
+	headers := make([]string, len(cols))
+	for i, col := range cols {
+		headers[i] = col.Header
+	}
+	rw, err := newRowWriter(w, headers, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{}
+	values := make([]any, len(cols))
+	for offset := 0; ; offset += opts.BatchSize {
+		if err := ctx.Err(); err != nil {
+			return res, err
+		}
+
+		limit := opts.BatchSize
+		if opts.MaxRows > 0 {
+			// 多取一行用于判断是否截断
+			limit = min(limit, opts.MaxRows-int(res.Rows)+1)
+		}
+		batch, err := repo.FindBySpec(ctx, pageSpec[T](spec, offset, limit), repository.WithOrderBy(opts.OrderBy))
+		if err != nil {
+			return res, err
+		}
+
+		for _, model := range batch {
+			if opts.MaxRows > 0 && res.Rows >= int64(opts.MaxRows) {
+				res.Truncated = true
+				break
+			}
+			for i, col := range cols {
+				values[i] = col.Value(model)
+			}
+			if err := rw.WriteRow(values); err != nil {
+				return res, fmt.Errorf("export: write row: %w", err)
+			}
+			res.Rows++
+		}
+		if opts.OnProgress != nil && len(batch) > 0 {
+			opts.OnProgress(res.Rows)
+		}
+		if res.Truncated || len(batch) < limit {
+			break
+		}
+	}
+
+	if err := rw.Close(); err != nil {
+		return res, fmt.Errorf("export: close writer: %w", err)
+	}
+	return res, nil
+}
+
+// pageSpec 在原规约上追加批次偏移
+func pageSpec[T any](spec repository.Specification[T], offset, limit int) repository.Specification[T] {
+	return repository.SpecFunc[T](func(db *gorm.DB) *gorm.DB {
+		if spec != nil {
+			db = spec.Apply(db)
+		}
+		return db.Offset(offset).Limit(limit)
+	})
+}
+
+// FormatValue 将单元格值格式化为字符串（与 CSV 输出一致，供其他输出格式复用）
+func FormatValue(v any, timeLayout string) string {
+	return formatValue(v, timeLayout)
+}
+
+// formatValue 将单元格值格式化为字符串
+func formatValue(v any, timeLayout string) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case time.Time:
+		if val.IsZero() {
+			return ""
+		}
+		return val.Format(timeLayout)
+	case *time.Time:
+		if val == nil || val.IsZero() {
+			return ""
+		}
+		return val.Format(timeLayout)
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return fmt.Sprint(val)
+	}
+}
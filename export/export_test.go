@@ -0,0 +1,219 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
+	httpserver "github.com/aisgo/ais-go-pkg/transport/http"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/fx/fxtest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type exportTestModel struct {
+	ID        string    `gorm:"column:id;primaryKey"`
+	Name      string    `gorm:"column:name"`
+	Amount    float64   `gorm:"column:amount"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (exportTestModel) TenantIgnored() bool {
+	return true
+}
+
+var exportCols = []Column[exportTestModel]{
+	Col("ID", func(m *exportTestModel) any { return m.ID }),
+	Col("名称", func(m *exportTestModel) any { return m.Name }),
+	Col("金额", func(m *exportTestModel) any { return m.Amount }),
+	Col("创建时间", func(m *exportTestModel) any { return m.CreatedAt }),
+}
+
+func newExportRepo(t *testing.T, n int) repository.Repository[exportTestModel] {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&exportTestModel{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := repository.NewRepository[exportTestModel](db)
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := range n {
+		name := fmt.Sprintf("user-%03d", i)
+		if i == 0 {
+			name = "=HYPERLINK(\"x\")"
+		}
+		m := &exportTestModel{ID: fmt.Sprintf("%03d", i), Name: name, Amount: float64(i) + 0.5, CreatedAt: created}
+		if err := repo.Create(context.Background(), m); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	return repo
+}
+
+func TestExportCSVInBatches(t *testing.T) {
+	repo := newExportRepo(t, 25)
+
+	var progress []int64
+	var buf bytes.Buffer
+	res, err := Export(context.Background(), &buf, repo, nil, exportCols, Options{
+		BatchSize:  10,
+		BOM:        true,
+		OnProgress: func(rows int64) { progress = append(progress, rows) },
+	})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if res.Rows != 25 || res.Truncated {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if fmt.Sprint(progress) != "[10 20 25]" {
+		t.Fatalf("unexpected progress: %v", progress)
+	}
+
+	if !strings.HasPrefix(buf.String(), "\ufeff") {
+		t.Fatalf("expected BOM prefix")
+	}
+	data := strings.TrimPrefix(buf.String(), "\ufeff")
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 26 || records[0][1] != "名称" {
+		t.Fatalf("unexpected csv: %v", records[:2])
+	}
+	if records[1][1] != "'=HYPERLINK(\"x\")" {
+		t.Fatalf("expected formula to be escaped, got %q", records[1][1])
+	}
+	if records[2][2] != "1.5" || records[2][3] != "2026-01-02 03:04:05" {
+		t.Fatalf("unexpected row: %v", records[2])
+	}
+}
+
+func TestExportMaxRowsAndSpec(t *testing.T) {
+	repo := newExportRepo(t, 30)
+
+	var buf bytes.Buffer
+	spec := repository.Where[exportTestModel]("amount > ?", 5)
+	res, err := Export(context.Background(), &buf, repo, spec, exportCols, Options{BatchSize: 4, MaxRows: 10})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if res.Rows != 10 || !res.Truncated {
+		t.Fatalf("expected truncated export of 10 rows, got %+v", res)
+	}
+	records, _ := csv.NewReader(&buf).ReadAll()
+	if len(records) != 11 || records[1][0] != "005" || records[10][0] != "014" {
+		t.Fatalf("unexpected rows: first=%v last=%v", records[1], records[len(records)-1])
+	}
+
+	buf.Reset()
+	res, err = Export(context.Background(), &buf, repo, spec, exportCols, Options{MaxRows: 25})
+	if err != nil || res.Rows != 25 || res.Truncated {
+		t.Fatalf("expected exact fit without truncation, got %+v %v", res, err)
+	}
+}
+
+func TestExportXLSX(t *testing.T) {
+	repo := newExportRepo(t, 3)
+
+	var buf bytes.Buffer
+	if _, err := Export(context.Background(), &buf, repo, nil, exportCols, Options{Format: FormatXLSX, SheetName: "订单"}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("open xlsx: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		files[f.Name] = string(data)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := files[name]; !ok {
+			t.Fatalf("missing part %s", name)
+		}
+	}
+	sheet := files["xl/worksheets/sheet1.xml"]
+	if strings.Count(sheet, "<row ") != 4 || !strings.Contains(sheet, `<c t="n"><v>2.5</v></c>`) ||
+		!strings.Contains(sheet, "=HYPERLINK(&#34;x&#34;)") {
+		t.Fatalf("unexpected sheet: %s", sheet)
+	}
+	if !strings.Contains(files["xl/workbook.xml"], `name="订单"`) {
+		t.Fatalf("unexpected workbook: %s", files["xl/workbook.xml"])
+	}
+}
+
+func TestFiberExport(t *testing.T) {
+	repo := newExportRepo(t, 5)
+	app := fiber.New()
+	app.Get("/export", func(c fiber.Ctx) error {
+		return Fiber(c, "users.csv", repo, nil, exportCols, Options{BatchSize: 2})
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/export", nil))
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if ct := resp.Header.Get(fiber.HeaderContentType); !strings.HasPrefix(ct, "text/csv") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	if cd := resp.Header.Get(fiber.HeaderContentDisposition); !strings.Contains(cd, "users.csv") {
+		t.Fatalf("unexpected content disposition %q", cd)
+	}
+	if lines := strings.Count(string(body), "\n"); lines != 6 {
+		t.Fatalf("expected header + 5 rows, got %d lines:\n%s", lines, body)
+	}
+}
+
+func TestFiberExportWithRequestTimeout(t *testing.T) {
+	repo := newExportRepo(t, 5)
+	// 默认中间件栈（含请求超时），导出耗时超过请求超时
+	app := httpserver.NewHTTPServer(httpserver.ServerParams{
+		Lc:     fxtest.NewLifecycle(t),
+		Logger: logger.NewNop(),
+		Config: httpserver.Config{RequestTimeout: 50 * time.Millisecond},
+	})
+	var exportErr error
+	app.Get("/export", func(c fiber.Ctx) error {
+		return Fiber(c, "users.csv", repo, nil, exportCols, Options{
+			BatchSize:  2,
+			OnProgress: func(int64) { time.Sleep(40 * time.Millisecond) },
+			OnError:    func(err error) { exportErr = err },
+		})
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/export", nil), fiber.TestConfig{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if exportErr != nil {
+		t.Fatalf("export aborted: %v", exportErr)
+	}
+	if lines := strings.Count(string(body), "\n"); lines != 6 {
+		t.Fatalf("expected header + 5 rows, got %d lines:\n%s", lines, body)
+	}
+}
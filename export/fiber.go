@@ -0,0 +1,51 @@
+package export
+
+import (
+	"bufio"
+	"context"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Fiber 以附件形式流式输出导出文件
+// 响应头发送后的错误只能截断输出，通过 Options.OnError 通知调用方
+// 响应体在 handler 返回后写出，查询使用脱离请求取消与截止时间的 ctx（保留租户等值），超时由 Options.Timeout 控制
+func Fiber[T any](c fiber.Ctx, filename string, repo repository.SpecificationRepository[T], spec repository.Specification[T], cols []Column[T], opts Options) error {
+	opts = opts.withDefaults()
+	switch opts.Format {
+	case FormatCSV:
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	case FormatXLSX:
+		c.Set(fiber.HeaderContentType, MIMEXLSX)
+	default:
+		return errors.New(errors.ErrCodeInvalidArgument, "unsupported export format: "+string(opts.Format))
+	}
+	if len(cols) == 0 {
+		return errors.New(errors.ErrCodeInvalidArgument, "export columns cannot be empty")
+	}
+	c.Attachment(filename)
+
+	base := context.WithoutCancel(c.Context())
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		ctx := base
+		if opts.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(base, opts.Timeout)
+			defer cancel()
+		}
+		progress := opts.OnProgress
+		opts.OnProgress = func(rows int64) {
+			// 每批刷新一次，及时推送给客户端
+			_ = w.Flush()
+			if progress != nil {
+				progress(rows)
+			}
+		}
+		if _, err := Export(ctx, w, repo, spec, cols, opts); err != nil && opts.OnError != nil {
+			opts.OnError(err)
+		}
+	})
+}
@@ -0,0 +1,155 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+/* ========================================================================
+ * XLSX Writer - 流式 Office Open XML 写入
+ * ========================================================================
+ * 职责: 以最小 SpreadsheetML 结构流式写出单工作表，单元格使用内联字符串，
+ *       数值写为数字单元格，无需依赖第三方 Excel 库
+ * ======================================================================== */
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`
+	xlsxSheetHeader = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetFooter = `</sheetData></worksheet>`
+)
+
+// MIMEXLSX XLSX 内容类型
+const MIMEXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// xlsxWriter XLSX 写入器
+type xlsxWriter struct {
+	zw         *zip.Writer
+	sheet      *bufio.Writer
+	timeLayout string
+	row        int
+}
+
+func newXLSXWriter(w io.Writer, headers []string, opts Options) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	workbook := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + escapeXML(opts.SheetName) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	for _, f := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(fw, f.body); err != nil {
+			return nil, err
+		}
+	}
+
+	fw, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{zw: zw, sheet: bufio.NewWriter(fw), timeLayout: opts.TimeLayout}
+	if _, err := xw.sheet.WriteString(xlsxSheetHeader); err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(headers))
+	for i, h := range headers {
+		values[i] = h
+	}
+	if err := xw.WriteRow(values); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+// WriteRow 写入一行
+func (xw *xlsxWriter) WriteRow(values []any) error {
+	xw.row++
+	buf := xw.sheet
+	buf.WriteString(`<row r="`)
+	buf.WriteString(strconv.Itoa(xw.row))
+	buf.WriteString(`">`)
+	for _, v := range values {
+		if num, ok := numericValue(v); ok {
+			buf.WriteString(`<c t="n"><v>`)
+			buf.WriteString(num)
+			buf.WriteString(`</v></c>`)
+			continue
+		}
+		buf.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		buf.WriteString(escapeXML(formatValue(v, xw.timeLayout)))
+		buf.WriteString(`</t></is></c>`)
+	}
+	_, err := buf.WriteString(`</row>`)
+	return err
+}
+
+// Close 写出工作表结尾与 zip 目录
+func (xw *xlsxWriter) Close() error {
+	if _, err := xw.sheet.WriteString(xlsxSheetFooter); err != nil {
+		return err
+	}
+	if err := xw.sheet.Flush(); err != nil {
+		return err
+	}
+	return xw.zw.Close()
+}
+
+// numericValue 数值类型写为数字单元格
+func numericValue(v any) (string, bool) {
+	switch n := v.(type) {
+	case int:
+		return strconv.Itoa(n), true
+	case int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(n), true
+	case float32:
+		return formatFloat(float64(n), 32)
+	case float64:
+		return formatFloat(n, 64)
+	default:
+		return "", false
+	}
+}
+
+// formatFloat 格式化浮点数，NaN/Inf 回退为文本单元格
+func formatFloat(f float64, bitSize int) (string, bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return "", false
+	}
+	return strconv.FormatFloat(f, 'f', -1, bitSize), true
+}
+
+// escapeXML 转义 XML 文本（XML 1.0 不允许的字符替换为 U+FFFD）
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"slices"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/request"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Batch - 批量接口
+ * ========================================================================
+ * 职责: 批量写入（如 POST /orders/batch、POST /orders:batchCreate）的分块写入与逐项结果报告
+ * 请求体: {"items": [...], "atomic": false}
+ * 响应体: {"succeeded": [{index, data}], "failed": [{index, code, msg}]}
+ * 模式:
+ *   - 默认（部分成功）: 每块在独立事务中批量写入；块失败时逐项重试以定位失败项，其余项照常提交
+ *   - atomic=true（全部成功或全部失败）: 所有块在同一事务中写入（块与逐项重试使用 SavePoint），
+ *     任一项校验或写入失败则整体回滚，succeeded 为空，failed 仅包含出错项
+ * 说明:
+ *   - 逐项校验失败的条目以 InvalidArgument 报告，不参与写入
+ *   - 请求级错误（请求体非法、条目为空或超过上限）直接返回错误响应
+ *
+ * 使用示例:
+ *   // 通过 CRUD 注册: POST /orders/batch
+ *   crud := handlers.NewCRUD(orderRepo, handlers.Options[Order]{
+ *       Actions:       []handlers.Action{handlers.ActionList, handlers.ActionBatchCreate},
+ *       BatchMaxItems: 500,
+ *   })
+ *   crud.Register(app.Group("/api/v1/orders"))
+ *
+ *   // 自定义方法风格路径需挂在路由组之外（Fiber 路由组会在子路径前补 "/"，":" 需转义）
+ *   app.Post("/api/v1/orders\\:batchCreate", crud.BatchCreate)
+ *
+ *   // 或在服务中直接使用（如导入任务）
+ *   result := handlers.WriteBatch(ctx, orderRepo, orders, handlers.BatchOptions{ChunkSize: 200},
+ *       func(ctx context.Context, chunk []*Order) error {
+ *           return orderRepo.UpsertBatch(ctx, chunk)
+ *       })
+ * ======================================================================== */
+
+const (
+	// DefaultBatchMaxItems 单次批量请求的默认条目上限
+	DefaultBatchMaxItems = 1000
+	// BatchCreatePath 批量创建路由（相对于注册的路由组）
+	BatchCreatePath = "/batch"
+)
+
+// BatchRequest 批量请求体
+type BatchRequest[T any] struct {
+	Items  []*T `json:"items" msgpack:"items" doc:"批量条目"`
+	Atomic bool `json:"atomic" msgpack:"atomic" doc:"全部成功或全部失败"`
+}
+
+// BatchSuccess 成功项
+type BatchSuccess struct {
+	Index int `json:"index" msgpack:"index" doc:"请求中的下标"`
+	Data  any `json:"data,omitempty" msgpack:"data,omitempty" doc:"写入结果"`
+}
+
+// BatchFailure 失败项
+type BatchFailure struct {
+	Index   int            `json:"index" msgpack:"index" doc:"请求中的下标"`
+	Code    int            `json:"code" msgpack:"code" doc:"错误码"`
+	Msg     string         `json:"msg" msgpack:"msg" doc:"错误消息"`
+	Details map[string]any `json:"details,omitempty" msgpack:"details,omitempty" doc:"错误详情（如字段校验错误）"`
+}
+
+// BatchResult 批量结果
+type BatchResult struct {
+	Succeeded []BatchSuccess `json:"succeeded" msgpack:"succeeded" doc:"成功项"`
+	Failed    []BatchFailure `json:"failed" msgpack:"failed" doc:"失败项"`
+}
+
+// BatchOptions 批量写入选项
+type BatchOptions struct {
+	ChunkSize int  // 每块条目数，<= 0 时使用 repository.DefaultBatchSize
+	Atomic    bool // 全部成功或全部失败
+}
+
+// BatchWriter 写入一块条目
+type BatchWriter[T any] func(ctx context.Context, chunk []*T) error
+
+var (
+	errBatchRollback = errors.New(errors.ErrCodeInternal, "batch rolled back")
+	errBatchNilItem  = errors.New(errors.ErrCodeInvalidArgument, "batch item is null")
+)
+
+// batchEntry 待写入条目及其在请求中的下标
+type batchEntry[T any] struct {
+	index int
+	item  *T
+}
+
+// NewBatchFailure 由错误构造失败项，BizError 使用其错误码与消息，其他错误按 500 报告
+func NewBatchFailure(index int, err error) BatchFailure {
+	if bizErr, ok := errors.AsBizError(err); ok {
+		return BatchFailure{Index: index, Code: int(bizErr.Code), Msg: bizErr.Message, Details: bizErr.Details}
+	}
+	return BatchFailure{Index: index, Code: http.StatusInternalServerError, Msg: err.Error()}
+}
+
+// WriteBatch 分块写入 items 并逐项报告结果，succeeded.data 为写入后的条目
+func WriteBatch[T any](ctx context.Context, repo repository.Repository[T], items []*T, opts BatchOptions, write BatchWriter[T]) BatchResult {
+	entries := make([]batchEntry[T], len(items))
+	for i, item := range items {
+		entries[i] = batchEntry[T]{index: i, item: item}
+	}
+	return writeEntries(ctx, repo, entries, nil, opts, write, func(item *T) any { return item })
+}
+
+// writeEntries 写入 entries，failed 为写入前已失败的条目（如校验失败）
+func writeEntries[T any](ctx context.Context, repo repository.Repository[T], entries []batchEntry[T], failed []BatchFailure,
+	opts BatchOptions, write BatchWriter[T], output func(*T) any) BatchResult {
+	chunkSize := opts.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = repository.DefaultBatchSize
+	}
+
+	if opts.Atomic {
+		if len(failed) == 0 && len(entries) > 0 {
+			err := repo.Execute(ctx, func(ctx context.Context) error {
+				failed = writeChunks(ctx, repo, entries, chunkSize, write)
+				if len(failed) > 0 {
+					return errBatchRollback
+				}
+				return nil
+			})
+			// 提交失败（如死锁、连接中断）时无法定位到具体条目，按全部失败报告
+			if err != nil && err != errBatchRollback {
+				for _, e := range entries {
+					failed = append(failed, NewBatchFailure(e.index, err))
+				}
+			}
+		}
+		if len(failed) > 0 {
+			return batchResult(nil, failed, output)
+		}
+		return batchResult(entries, nil, output)
+	}
+
+	failed = append(failed, writeChunks(ctx, repo, entries, chunkSize, write)...)
+	return batchResult(entries, failed, output)
+}
+
+// writeChunks 按块写入，块失败时逐项重试以定位失败项
+func writeChunks[T any](ctx context.Context, repo repository.Repository[T], entries []batchEntry[T], chunkSize int, write BatchWriter[T]) []BatchFailure {
+	var failed []BatchFailure
+	for chunk := range slices.Chunk(entries, chunkSize) {
+		items := make([]*T, len(chunk))
+		for i, e := range chunk {
+			items[i] = e.item
+		}
+		err := repo.Execute(ctx, func(ctx context.Context) error {
+			return write(ctx, items)
+		})
+		if err == nil {
+			continue
+		}
+		for _, e := range chunk {
+			err := repo.Execute(ctx, func(ctx context.Context) error {
+				return write(ctx, []*T{e.item})
+			})
+			if err != nil {
+				failed = append(failed, NewBatchFailure(e.index, err))
+			}
+		}
+	}
+	return failed
+}
+
+// batchResult 汇总结果，entries 中未出现在 failed 的条目视为成功
+func batchResult[T any](entries []batchEntry[T], failed []BatchFailure, output func(*T) any) BatchResult {
+	slices.SortFunc(failed, func(a, b BatchFailure) int { return a.Index - b.Index })
+	result := BatchResult{Succeeded: []BatchSuccess{}, Failed: []BatchFailure{}}
+	failedIdx := make(map[int]struct{}, len(failed))
+	for _, f := range failed {
+		result.Failed = append(result.Failed, f)
+		failedIdx[f.Index] = struct{}{}
+	}
+	for _, e := range entries {
+		if _, ok := failedIdx[e.index]; ok {
+			continue
+		}
+		result.Succeeded = append(result.Succeeded, BatchSuccess{Index: e.index, Data: output(e.item)})
+	}
+	return result
+}
+
+// BatchCreate 批量创建
+func (h *CRUD[T]) BatchCreate(c fiber.Ctx) error {
+	if err := h.prepare(c, ActionBatchCreate); err != nil {
+		return response.Error(c, err)
+	}
+	var req BatchRequest[T]
+	if err := request.Bind(c, &req); err != nil {
+		return response.Error(c, err)
+	}
+	maxItems := h.opts.BatchMaxItems
+	if maxItems <= 0 {
+		maxItems = DefaultBatchMaxItems
+	}
+	if len(req.Items) == 0 || len(req.Items) > maxItems {
+		return response.Error(c, errors.New(errors.ErrCodeInvalidArgument, "batch items must be between 1 and max items").
+			WithDetail("max_items", maxItems))
+	}
+
+	var (
+		entries []batchEntry[T]
+		failed  []BatchFailure
+	)
+	for i, item := range req.Items {
+		var err error = errBatchNilItem
+		if item != nil {
+			err = h.validate(c, item)
+		}
+		if err == nil && h.opts.BeforeCreate != nil {
+			err = h.opts.BeforeCreate(c, item)
+		}
+		if err != nil {
+			failed = append(failed, NewBatchFailure(i, err))
+			continue
+		}
+		entries = append(entries, batchEntry[T]{index: i, item: item})
+	}
+
+	opts := BatchOptions{ChunkSize: h.opts.BatchChunkSize, Atomic: req.Atomic}
+	write := func(ctx context.Context, chunk []*T) error {
+		return h.repo.CreateBatch(ctx, chunk, len(chunk))
+	}
+	result := writeEntries(c.Context(), h.repo, entries, failed, opts, write, func(item *T) any {
+		return h.output(c, item)
+	})
+	return response.OkWithData(c, result)
+}
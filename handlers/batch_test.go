@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+type sku struct {
+	repository.TenantModel
+	Code string `json:"code" gorm:"column:code;uniqueIndex" validate:"required"`
+}
+
+func batchCreate(t *testing.T, app *fiber.App, body string) (int, BatchResult) {
+	t.Helper()
+	status, env := do(t, app, fiber.MethodPost, "/notes/batch", body)
+	var result BatchResult
+	if status == fiber.StatusOK {
+		if err := json.Unmarshal(env.Data, &result); err != nil {
+			t.Fatalf("decode result: %v", err)
+		}
+	}
+	return status, result
+}
+
+func failedIndexes(result BatchResult) []int {
+	idx := make([]int, len(result.Failed))
+	for i, f := range result.Failed {
+		idx[i] = f.Index
+	}
+	return idx
+}
+
+func TestBatchCreatePartial(t *testing.T) {
+	app, repo := newApp(t, Options[sku]{Actions: []Action{ActionBatchCreate}, BatchChunkSize: 2})
+
+	// 下标 1 校验失败，下标 3 与下标 0 唯一键冲突
+	status, result := batchCreate(t, app, `{"items":[{"code":"a"},{},{"code":"b"},{"code":"a"},{"code":"c"}]}`)
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d", status)
+	}
+	if got := failedIndexes(result); len(got) != 2 || got[0] != 1 || got[1] != 3 {
+		t.Fatalf("failed = %+v", result.Failed)
+	}
+	if result.Failed[0].Code != int(errors.ErrCodeInvalidArgument) || result.Failed[0].Details["errors"] == nil {
+		t.Fatalf("validation failure = %+v", result.Failed[0])
+	}
+	if len(result.Succeeded) != 3 || result.Succeeded[2].Index != 4 {
+		t.Fatalf("succeeded = %+v", result.Succeeded)
+	}
+
+	n, err := repo.Count(adminCtx(), "")
+	if err != nil || n != 3 {
+		t.Fatalf("count = %d, %v; want 3", n, err)
+	}
+}
+
+func TestBatchCreateAtomic(t *testing.T) {
+	app, repo := newApp(t, Options[sku]{Actions: []Action{ActionBatchCreate}, BatchChunkSize: 2})
+
+	_, result := batchCreate(t, app, `{"atomic":true,"items":[{"code":"a"},{"code":"b"},{"code":"a"}]}`)
+	if len(result.Succeeded) != 0 {
+		t.Fatalf("succeeded = %+v, want none", result.Succeeded)
+	}
+	if got := failedIndexes(result); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("failed = %+v", result.Failed)
+	}
+	if n, _ := repo.Count(adminCtx(), ""); n != 0 {
+		t.Fatalf("count = %d, want rolled back", n)
+	}
+
+	_, result = batchCreate(t, app, `{"atomic":true,"items":[{"code":"a"},{"code":"b"},{"code":"c"}]}`)
+	if len(result.Succeeded) != 3 || len(result.Failed) != 0 {
+		t.Fatalf("result = %+v", result)
+	}
+	if n, _ := repo.Count(adminCtx(), ""); n != 3 {
+		t.Fatalf("count = %d, want 3", n)
+	}
+}
+
+func TestBatchCreateLimits(t *testing.T) {
+	app, _ := newApp(t, Options[sku]{Actions: []Action{ActionBatchCreate}, BatchMaxItems: 2})
+
+	if status, _ := batchCreate(t, app, `{"items":[]}`); status != fiber.StatusBadRequest {
+		t.Fatalf("empty status = %d, want 400", status)
+	}
+	if status, _ := batchCreate(t, app, `{"items":[{"code":"a"},{"code":"b"},{"code":"c"}]}`); status != fiber.StatusBadRequest {
+		t.Fatalf("oversized status = %d, want 400", status)
+	}
+}
+
+func TestWriteBatch(t *testing.T) {
+	repo := repository.NewMemoryRepository[sku]()
+	ctx := adminCtx()
+	items := []*sku{{Code: "a"}, {Code: "b"}, {Code: "c"}}
+	write := func(ctx context.Context, chunk []*sku) error {
+		for _, item := range chunk {
+			if item.Code == "b" {
+				return errors.New(errors.ErrCodeAlreadyExists, "duplicate")
+			}
+		}
+		return repo.CreateBatch(ctx, chunk, len(chunk))
+	}
+
+	result := WriteBatch(ctx, repo, items, BatchOptions{ChunkSize: 2}, write)
+	if got := failedIndexes(result); len(got) != 1 || got[0] != 1 || result.Failed[0].Code != int(errors.ErrCodeAlreadyExists) {
+		t.Fatalf("failed = %+v", result.Failed)
+	}
+	if len(result.Succeeded) != 2 {
+		t.Fatalf("succeeded = %+v", result.Succeeded)
+	}
+	if n, _ := repo.Count(ctx, ""); n != 2 {
+		t.Fatalf("count = %d, want 2", n)
+	}
+}
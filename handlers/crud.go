@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"slices"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/request"
+	"github.com/aisgo/ais-go-pkg/response"
+	"github.com/aisgo/ais-go-pkg/validator"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * CRUD Handlers - 基于仓储的通用 CRUD 接口
+ * ========================================================================
+ * 职责: 为 Repository[T] 生成标准 REST 路由，统一绑定、校验、分页、租户注入与响应格式
+ * 路由（相对于注册的路由组）:
+ *   GET    /       -> 分页列表（page / page_size / sort + 等值过滤查询参数）
+ *   GET    /:id    -> 详情
+ *   POST   /       -> 创建（绑定 T 并校验）
+ *   PUT    /:id    -> 部分更新（请求体合并到现有记录后校验，仅写入请求体中出现的字段）
+ *   PATCH  /:id    -> 同 PUT
+ *   DELETE /:id    -> 软删除
+ * 约定:
+ *   - 更新请求体的键需为列名（或结构体字段名），经 UpdatableFields 白名单后交给 UpdateByID
+ *   - 租户、部门、创建人等字段由仓储维护，客户端无法通过更新接口修改
+ *   - 校验失败返回 400，字段错误位于 data.details.errors
+ *   - Tenant 仅在 ctx 中尚无 TenantContext 时调用（如已由 API Key 中间件注入则跳过）
+ *
+ * 使用示例:
+ *   crud := handlers.NewCRUD(orderRepo, handlers.Options[Order]{
+ *       SortColumns:     []string{"created_at", "amount"},
+ *       FilterColumns:   []string{"status"},
+ *       UpdatableFields: []string{"status", "remark"},
+ *       Authorize: func(c fiber.Ctx, action handlers.Action) error {
+ *           if action == handlers.ActionDelete && !isAdmin(c) {
+ *               return errors.ErrPermissionDenied
+ *           }
+ *           return nil
+ *       },
+ *       Output: func(c fiber.Ctx, o *Order) any { return toOrderVO(o) },
+ *   })
+ *   crud.Register(rt.Group(app, "/api/v1/orders", router.PresetAuthed))
+ * ======================================================================== */
+
+// Action CRUD 操作类型
+type Action string
+
+const (
+	ActionList   Action = "list"
+	ActionGet    Action = "get"
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	// ActionBatchCreate 批量创建（POST /batch，见 batch.go），需显式启用
+	ActionBatchCreate Action = "batch_create"
+)
+
+// Options CRUD 配置
+type Options[T any] struct {
+	// Actions 注册的操作，为空时注册除批量创建外的全部操作
+	Actions []Action
+	// Validator 请求体校验器，为空时使用 validator.New()
+	Validator *validator.Validator
+	// Tenant 从请求解析租户上下文，ctx 中已有 TenantContext 时不调用
+	Tenant func(c fiber.Ctx) (repository.TenantContext, error)
+	// Authorize 按操作鉴权，返回错误时中止请求
+	Authorize func(c fiber.Ctx, action Action) error
+	// SortColumns 列表允许排序的列，为空时仅校验列名安全性
+	SortColumns []string
+	// FilterColumns 列表允许按查询参数等值过滤的列（参数名即列名，值按字符串比较）
+	FilterColumns []string
+	// Filter 追加列表过滤条件（如日期范围、关键字搜索）
+	Filter func(c fiber.Ctx) (repository.Specification[T], error)
+	// UpdatableFields 更新接口允许写入的字段，为空时允许全部可更新字段
+	UpdatableFields []string
+	// BeforeCreate 创建前回调（校验之后），可填充服务端字段或拒绝请求
+	BeforeCreate func(c fiber.Ctx, model *T) error
+	// BeforeUpdate 更新前回调（校验之后），可增删 updates 中的字段或拒绝请求
+	BeforeUpdate func(c fiber.Ctx, id string, updates map[string]any) error
+	// BatchChunkSize 批量创建每块条目数，<= 0 时使用 repository.DefaultBatchSize
+	BatchChunkSize int
+	// BatchMaxItems 批量创建单次请求条目上限，<= 0 时使用 DefaultBatchMaxItems
+	BatchMaxItems int
+	// Output 输出转换（字段过滤、脱敏、转 VO），为空时直接输出模型
+	Output func(c fiber.Ctx, model *T) any
+}
+
+// CRUD 通用 CRUD 处理器
+type CRUD[T any] struct {
+	repo repository.Repository[T]
+	opts Options[T]
+}
+
+// NewCRUD 创建通用 CRUD 处理器
+func NewCRUD[T any](repo repository.Repository[T], opts Options[T]) *CRUD[T] {
+	if opts.Validator == nil {
+		opts.Validator = validator.New()
+	}
+	if len(opts.Actions) == 0 {
+		opts.Actions = []Action{ActionList, ActionGet, ActionCreate, ActionUpdate, ActionDelete}
+	}
+	return &CRUD[T]{repo: repo, opts: opts}
+}
+
+// Register 在路由组上注册已启用的路由
+func (h *CRUD[T]) Register(r fiber.Router) {
+	if h.enabled(ActionList) {
+		r.Get("/", h.List)
+	}
+	if h.enabled(ActionGet) {
+		r.Get("/:id", h.Get)
+	}
+	if h.enabled(ActionCreate) {
+		r.Post("/", h.Create)
+	}
+	if h.enabled(ActionBatchCreate) {
+		r.Post(BatchCreatePath, h.BatchCreate)
+	}
+	if h.enabled(ActionUpdate) {
+		r.Put("/:id", h.Update)
+		r.Patch("/:id", h.Update)
+	}
+	if h.enabled(ActionDelete) {
+		r.Delete("/:id", h.Delete)
+	}
+}
+
+func (h *CRUD[T]) enabled(action Action) bool {
+	return slices.Contains(h.opts.Actions, action)
+}
+
+// List 分页列表
+func (h *CRUD[T]) List(c fiber.Ctx) error {
+	if err := h.prepare(c, ActionList); err != nil {
+		return response.Error(c, err)
+	}
+	page, err := request.ParsePage(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
+	sort, err := request.ParseSort(c, h.opts.SortColumns...)
+	if err != nil {
+		return response.Error(c, err)
+	}
+	spec, err := h.listSpec(c)
+	if err != nil {
+		return response.Error(c, err)
+	}
+
+	result, err := h.repo.PageBySpec(c.Context(), page, spec, sort)
+	if err != nil {
+		return response.Error(c, err)
+	}
+	return response.PageData(c, h.outputList(c, result.List), result.Total, result.Page, result.PageSize)
+}
+
+// listSpec 由过滤查询参数与自定义过滤组合列表条件
+func (h *CRUD[T]) listSpec(c fiber.Ctx) (repository.Specification[T], error) {
+	var specs []repository.Specification[T]
+	for _, column := range h.opts.FilterColumns {
+		if value := c.Query(column); value != "" {
+			specs = append(specs, repository.Eq[T](column, value))
+		}
+	}
+	if h.opts.Filter != nil {
+		spec, err := h.opts.Filter(c)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return repository.And(specs...), nil
+}
+
+// Get 详情
+func (h *CRUD[T]) Get(c fiber.Ctx) error {
+	if err := h.prepare(c, ActionGet); err != nil {
+		return response.Error(c, err)
+	}
+	model, err := h.repo.FindByID(c.Context(), c.Params("id"))
+	if err != nil {
+		return response.Error(c, err)
+	}
+	return response.OkWithData(c, h.output(c, model))
+}
+
+// Create 创建
+func (h *CRUD[T]) Create(c fiber.Ctx) error {
+	if err := h.prepare(c, ActionCreate); err != nil {
+		return response.Error(c, err)
+	}
+	model := new(T)
+	if err := request.Bind(c, model); err != nil {
+		return response.Error(c, err)
+	}
+	if err := h.validate(c, model); err != nil {
+		return response.Error(c, err)
+	}
+	if h.opts.BeforeCreate != nil {
+		if err := h.opts.BeforeCreate(c, model); err != nil {
+			return response.Error(c, err)
+		}
+	}
+
+	if err := h.repo.Create(c.Context(), model); err != nil {
+		return response.Error(c, err)
+	}
+	return response.OkWithData(c, h.output(c, model))
+}
+
+// Update 部分更新
+func (h *CRUD[T]) Update(c fiber.Ctx) error {
+	if err := h.prepare(c, ActionUpdate); err != nil {
+		return response.Error(c, err)
+	}
+	ctx := c.Context()
+	id := c.Params("id")
+
+	// 先读取现有记录：确认在当前租户/数据范围内可见，并用于合并后的整体校验
+	model, err := h.repo.FindByID(ctx, id)
+	if err != nil {
+		return response.Error(c, err)
+	}
+	updates := make(map[string]any)
+	if err := request.Bind(c, &updates); err != nil {
+		return response.Error(c, err)
+	}
+	if err := request.Bind(c, model); err != nil {
+		return response.Error(c, err)
+	}
+	if err := h.validate(c, model); err != nil {
+		return response.Error(c, err)
+	}
+	if h.opts.BeforeUpdate != nil {
+		if err := h.opts.BeforeUpdate(c, id, updates); err != nil {
+			return response.Error(c, err)
+		}
+	}
+
+	if err := h.repo.UpdateByID(ctx, id, updates, h.opts.UpdatableFields...); err != nil {
+		return response.Error(c, err)
+	}
+	model, err = h.repo.FindByID(ctx, id)
+	if err != nil {
+		return response.Error(c, err)
+	}
+	return response.OkWithData(c, h.output(c, model))
+}
+
+// Delete 软删除
+func (h *CRUD[T]) Delete(c fiber.Ctx) error {
+	if err := h.prepare(c, ActionDelete); err != nil {
+		return response.Error(c, err)
+	}
+	if err := h.repo.Delete(c.Context(), c.Params("id")); err != nil {
+		return response.Error(c, err)
+	}
+	return response.Ok(c)
+}
+
+// prepare 注入租户上下文并执行鉴权
+func (h *CRUD[T]) prepare(c fiber.Ctx, action Action) error {
+	if h.opts.Tenant != nil {
+		if _, ok := repository.TenantFromContext(c.Context()); !ok {
+			tc, err := h.opts.Tenant(c)
+			if err != nil {
+				return err
+			}
+			c.SetContext(repository.WithTenantContext(c.Context(), tc))
+		}
+	}
+	if h.opts.Authorize != nil {
+		return h.opts.Authorize(c, action)
+	}
+	return nil
+}
+
+// validate 校验模型，字段错误转为 InvalidArgument
+func (h *CRUD[T]) validate(c fiber.Ctx, model *T) error {
+	err := h.opts.Validator.ValidateCtx(c.Context(), model)
+	if err == nil {
+		return nil
+	}
+	var ve *validator.ValidationError
+	if errors.As(err, &ve) {
+		return errors.New(errors.ErrCodeInvalidArgument, "validation failed").WithDetail("errors", ve.Errors)
+	}
+	return err
+}
+
+func (h *CRUD[T]) output(c fiber.Ctx, model *T) any {
+	if h.opts.Output == nil {
+		return model
+	}
+	return h.opts.Output(c, model)
+}
+
+func (h *CRUD[T]) outputList(c fiber.Ctx, models []T) any {
+	if h.opts.Output == nil {
+		return models
+	}
+	list := make([]any, len(models))
+	for i := range models {
+		list[i] = h.opts.Output(c, &models[i])
+	}
+	return list
+}
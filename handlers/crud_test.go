@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+type note struct {
+	repository.TenantModel
+	Title  string `json:"title" gorm:"column:title" validate:"required"`
+	Status string `json:"status" gorm:"column:status"`
+	Secret string `json:"secret" gorm:"column:secret"`
+}
+
+var testTenant, testDept = ulidv2.Make(), ulidv2.Make()
+
+// adminCtx 测试租户的管理员上下文
+func adminCtx() context.Context {
+	return repository.WithTenantContext(context.Background(), repository.TenantContext{TenantID: testTenant, IsAdmin: true})
+}
+
+type envelope struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+func newApp[T any](t *testing.T, opts Options[T]) (*fiber.App, repository.Repository[T]) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "crud.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(new(T)); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := repository.NewRepository[T](db)
+
+	if opts.Tenant == nil {
+		opts.Tenant = func(fiber.Ctx) (repository.TenantContext, error) {
+			return repository.TenantContext{TenantID: testTenant, DeptID: &testDept}, nil
+		}
+	}
+	app := fiber.New()
+	NewCRUD(repo, opts).Register(app.Group("/notes"))
+	return app, repo
+}
+
+func do(t *testing.T, app *fiber.App, method, target, body string) (int, envelope) {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, reader)
+	if body != "" {
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, target, err)
+	}
+	defer resp.Body.Close()
+	var env envelope
+	if !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON) {
+		return resp.StatusCode, env
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		t.Fatalf("decode %s %s: %v", method, target, err)
+	}
+	return resp.StatusCode, env
+}
+
+func TestCRUDRoutes(t *testing.T) {
+	app, _ := newApp(t, Options[note]{
+		SortColumns:     []string{"title"},
+		FilterColumns:   []string{"status"},
+		UpdatableFields: []string{"title", "status"},
+	})
+
+	status, env := do(t, app, fiber.MethodPost, "/notes", `{"title":"b","status":"open"}`)
+	if status != fiber.StatusOK {
+		t.Fatalf("create status = %d, msg = %s", status, env.Msg)
+	}
+	var created note
+	if err := json.Unmarshal(env.Data, &created); err != nil {
+		t.Fatalf("decode created: %v", err)
+	}
+	if created.ID == "" || created.TenantID.IsZero() {
+		t.Fatalf("created = %+v, want id and tenant", created)
+	}
+	do(t, app, fiber.MethodPost, "/notes", `{"title":"a","status":"done"}`)
+
+	status, env = do(t, app, fiber.MethodGet, "/notes?sort=title&page_size=10", "")
+	if status != fiber.StatusOK {
+		t.Fatalf("list status = %d, msg = %s", status, env.Msg)
+	}
+	var page struct {
+		List  []note `json:"list"`
+		Total int64  `json:"total"`
+	}
+	if err := json.Unmarshal(env.Data, &page); err != nil {
+		t.Fatalf("decode page: %v", err)
+	}
+	if page.Total != 2 || page.List[0].Title != "a" {
+		t.Fatalf("page = %+v", page)
+	}
+
+	_, env = do(t, app, fiber.MethodGet, "/notes?status=open", "")
+	if err := json.Unmarshal(env.Data, &page); err != nil {
+		t.Fatalf("decode page: %v", err)
+	}
+	if page.Total != 1 || page.List[0].ID != created.ID {
+		t.Fatalf("filtered page = %+v", page)
+	}
+
+	if status, _ := do(t, app, fiber.MethodGet, "/notes?sort=secret", ""); status != fiber.StatusBadRequest {
+		t.Fatalf("sort by secret status = %d, want 400", status)
+	}
+
+	// secret 不在可更新字段中，被忽略
+	status, env = do(t, app, fiber.MethodPatch, "/notes/"+created.ID, `{"status":"done","secret":"x"}`)
+	if status != fiber.StatusOK {
+		t.Fatalf("update status = %d, msg = %s", status, env.Msg)
+	}
+	var updated note
+	if err := json.Unmarshal(env.Data, &updated); err != nil {
+		t.Fatalf("decode updated: %v", err)
+	}
+	if updated.Status != "done" || updated.Secret != "" || updated.Title != "b" {
+		t.Fatalf("updated = %+v", updated)
+	}
+
+	if status, _ := do(t, app, fiber.MethodDelete, "/notes/"+created.ID, ""); status != fiber.StatusOK {
+		t.Fatalf("delete status = %d", status)
+	}
+	if status, _ := do(t, app, fiber.MethodGet, "/notes/"+created.ID, ""); status != fiber.StatusNotFound {
+		t.Fatalf("get deleted status = %d, want 404", status)
+	}
+}
+
+func TestCRUDValidation(t *testing.T) {
+	app, _ := newApp(t, Options[note]{})
+
+	status, env := do(t, app, fiber.MethodPost, "/notes", `{"status":"open"}`)
+	if status != fiber.StatusBadRequest {
+		t.Fatalf("create status = %d, want 400", status)
+	}
+	var data struct {
+		Details struct {
+			Errors map[string][]string `json:"errors"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(env.Data, &data); err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	if len(data.Details.Errors) == 0 {
+		t.Fatalf("data = %s, want field errors", env.Data)
+	}
+
+	_, env = do(t, app, fiber.MethodPost, "/notes", `{"title":"a"}`)
+	var created note
+	if err := json.Unmarshal(env.Data, &created); err != nil {
+		t.Fatalf("decode created: %v", err)
+	}
+	// 合并后校验：清空必填字段被拒绝
+	if status, _ := do(t, app, fiber.MethodPut, "/notes/"+created.ID, `{"title":""}`); status != fiber.StatusBadRequest {
+		t.Fatalf("update status = %d, want 400", status)
+	}
+}
+
+func TestCRUDHooks(t *testing.T) {
+	var actions []Action
+	app, _ := newApp(t, Options[note]{
+		Actions: []Action{ActionList, ActionCreate, ActionDelete},
+		Authorize: func(c fiber.Ctx, action Action) error {
+			actions = append(actions, action)
+			if action == ActionDelete {
+				return errors.ErrPermissionDenied
+			}
+			return nil
+		},
+		BeforeCreate: func(c fiber.Ctx, n *note) error {
+			n.Status = "draft"
+			return nil
+		},
+		Output: func(c fiber.Ctx, n *note) any {
+			return fiber.Map{"id": n.ID, "title": n.Title, "status": n.Status}
+		},
+	})
+
+	_, env := do(t, app, fiber.MethodPost, "/notes", `{"title":"a","secret":"s"}`)
+	var out map[string]any
+	if err := json.Unmarshal(env.Data, &out); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if _, ok := out["secret"]; ok || out["status"] != "draft" {
+		t.Fatalf("output = %v", out)
+	}
+	id, _ := out["id"].(string)
+
+	if status, _ := do(t, app, fiber.MethodDelete, "/notes/"+id, ""); status != fiber.StatusForbidden {
+		t.Fatalf("delete status = %d, want 403", status)
+	}
+	// 未启用的路由不注册
+	if status, _ := do(t, app, fiber.MethodGet, "/notes/"+id, ""); status != fiber.StatusMethodNotAllowed {
+		t.Fatalf("get status = %d, want 405", status)
+	}
+	if len(actions) != 2 || actions[0] != ActionCreate || actions[1] != ActionDelete {
+		t.Fatalf("actions = %v", actions)
+	}
+}
+
+func TestCRUDTenantError(t *testing.T) {
+	app, _ := newApp(t, Options[note]{
+		Tenant: func(fiber.Ctx) (repository.TenantContext, error) {
+			return repository.TenantContext{}, errors.ErrUnauthenticated
+		},
+	})
+	if status, _ := do(t, app, fiber.MethodGet, "/notes", ""); status != fiber.StatusUnauthorized {
+		t.Fatalf("list status = %d, want 401", status)
+	}
+}
@@ -0,0 +1,381 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/metrics"
+	"github.com/aisgo/ais-go-pkg/requestid"
+	"github.com/aisgo/ais-go-pkg/resilience"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * HTTP Client - 服务间 REST 调用客户端
+ * ========================================================================
+ * 职责: 封装 net/http，统一超时、重试、签名、链路头透传与指标
+ * 特性:
+ *   - 单次请求超时（ctx 无 deadline 时生效）
+ *   - 幂等方法（GET/HEAD/OPTIONS/PUT/DELETE）或携带 Idempotency-Key 的请求
+ *     在网络错误与可重试状态码（默认 502/503/504）时按指数退避重试
+ *   - Signer 在每次发送前对请求签名（重试时重新签名）
+ *   - 自动透传 ctx 中的 X-Request-ID / X-Correlation-ID，HeaderFunc 透传其他链路头
+ *   - 连接池参数可配置
+ * 使用示例:
+ *   c := httpclient.New(httpclient.Config{BaseURL: "http://user-service:8080"}, log,
+ *       httpclient.WithSigner(signer))
+ *   var user User
+ *   err := c.DoJSON(ctx, http.MethodGet, "/api/users/1", nil, &user)
+ * ======================================================================== */
+
+var (
+	// requestDuration 请求耗时（含重试）
+	requestDuration = metrics.NewHistogram("app", "httpclient", "request_duration_seconds",
+		"Outbound HTTP request latency in seconds", []string{"host", "method", "status"}, nil)
+
+	// retryTotal 重试次数
+	retryTotal = metrics.NewCounter("app", "httpclient", "retry_total",
+		"Total number of outbound HTTP request retries", []string{"host", "method"})
+)
+
+// Config 客户端配置
+type Config struct {
+	BaseURL string        `yaml:"base_url"` // 相对路径请求的前缀
+	Timeout time.Duration `yaml:"timeout"`  // 单次请求超时（含重试），默认 10s
+
+	Retry RetryConfig `yaml:"retry"`
+
+	// 连接池
+	MaxIdleConns        int           `yaml:"max_idle_conns"`          // 默认 100
+	MaxIdleConnsPerHost int           `yaml:"max_idle_conns_per_host"` // 默认 20
+	MaxConnsPerHost     int           `yaml:"max_conns_per_host"`      // 0 表示不限制
+	IdleConnTimeout     time.Duration `yaml:"idle_conn_timeout"`       // 默认 90s
+	DialTimeout         time.Duration `yaml:"dial_timeout"`            // 默认 5s
+	TLSHandshakeTimeout time.Duration `yaml:"tls_handshake_timeout"`   // 默认 5s
+}
+
+// RetryConfig 重试配置
+type RetryConfig struct {
+	MaxAttempts int                      `yaml:"max_attempts"` // 总尝试次数（含首次），默认 3，1 表示不重试
+	Statuses    []int                    `yaml:"statuses"`     // 可重试状态码，默认 502/503/504
+	Backoff     resilience.BackoffConfig `yaml:"backoff"`
+}
+
+// withDefaults 填充默认值
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.Retry.MaxAttempts <= 0 {
+		c.Retry.MaxAttempts = 3
+	}
+	if len(c.Retry.Statuses) == 0 {
+		c.Retry.Statuses = []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+	}
+	if c.MaxIdleConns <= 0 {
+		c.MaxIdleConns = 100
+	}
+	if c.MaxIdleConnsPerHost <= 0 {
+		c.MaxIdleConnsPerHost = 20
+	}
+	if c.IdleConnTimeout <= 0 {
+		c.IdleConnTimeout = 90 * time.Second
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = 5 * time.Second
+	}
+	if c.TLSHandshakeTimeout <= 0 {
+		c.TLSHandshakeTimeout = 5 * time.Second
+	}
+	return c
+}
+
+// Signer 请求签名器（如 X-AIS-Auth-* 认证头），每次发送前调用
+type Signer interface {
+	Sign(req *http.Request) error
+}
+
+// SignerFunc 函数形式的 Signer
+type SignerFunc func(req *http.Request) error
+
+// Sign 实现 Signer
+func (f SignerFunc) Sign(req *http.Request) error {
+	return f(req)
+}
+
+// HeaderFunc 根据 ctx 写入透传请求头（如租户信息）
+type HeaderFunc func(ctx context.Context, header http.Header)
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithSigner 设置请求签名器
+func WithSigner(s Signer) Option {
+	return func(c *Client) {
+		c.signer = s
+	}
+}
+
+// WithHeaderFunc 添加透传请求头函数
+func WithHeaderFunc(fn HeaderFunc) Option {
+	return func(c *Client) {
+		c.headerFuncs = append(c.headerFuncs, fn)
+	}
+}
+
+// WithTransport 替换底层 RoundTripper（测试或自定义代理）
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.http.Transport = rt
+	}
+}
+
+// Client 服务间 HTTP 客户端
+type Client struct {
+	http        *http.Client
+	cfg         Config
+	log         *logger.Logger
+	signer      Signer
+	headerFuncs []HeaderFunc
+}
+
+// New 创建 HTTP 客户端
+func New(cfg Config, log *logger.Logger, opts ...Option) *Client {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	cfg = cfg.withDefaults()
+
+	dialer := &net.Dialer{Timeout: cfg.DialTimeout, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ExpectContinueTimeout: time.Second,
+	}
+
+	c := &Client{
+		http: &http.Client{Transport: transport},
+		cfg:  cfg,
+		log:  log,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// HTTPClient 返回底层 *http.Client
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+// NewRequest 创建请求，相对路径拼接 BaseURL
+func (c *Client) NewRequest(ctx context.Context, method, url string, body io.Reader) (*http.Request, error) {
+	if c.cfg.BaseURL != "" && strings.HasPrefix(url, "/") {
+		url = strings.TrimRight(c.cfg.BaseURL, "/") + url
+	}
+	return http.NewRequestWithContext(ctx, method, url, body)
+}
+
+// Do 发送请求，按配置超时、签名与重试
+// 调用方负责关闭返回的响应体；重试需要请求体可重放（req.GetBody 非空）
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	if _, ok := req.Context().Deadline(); ok {
+		return c.do(req)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), c.cfg.Timeout)
+	resp, err := c.do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// 超时 ctx 在响应体关闭时释放
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// do 执行请求（含重试与指标）
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	requestid.InjectHeader(req.Context(), req.Header)
+	for _, fn := range c.headerFuncs {
+		fn(req.Context(), req.Header)
+	}
+
+	attempts := 1
+	if c.retryable(req) {
+		attempts = c.cfg.Retry.MaxAttempts
+	}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			retryTotal.WithLabelValues(req.URL.Host, req.Method).Inc()
+			if werr := c.wait(req.Context(), attempt-1, resp); werr != nil {
+				resp, err = nil, werr
+				break
+			}
+			if req.GetBody != nil {
+				body, berr := req.GetBody()
+				if berr != nil {
+					return nil, berr
+				}
+				req.Body = body
+			}
+		}
+
+		if c.signer != nil {
+			if serr := c.signer.Sign(req); serr != nil {
+				return nil, fmt.Errorf("httpclient: sign request: %w", serr)
+			}
+		}
+
+		resp, err = c.http.Do(req)
+		if !c.shouldRetry(resp, err) || attempt == attempts-1 {
+			break
+		}
+		if resp != nil {
+			// 丢弃响应体以复用连接
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+		}
+		c.log.Debug("Retrying outbound HTTP request",
+			zap.String("method", req.Method),
+			zap.String("url", req.URL.Redacted()),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err),
+		)
+	}
+
+	status := "error"
+	if resp != nil && err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	requestDuration.WithLabelValues(req.URL.Host, req.Method, status).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// retryable 请求是否允许重试
+func (c *Client) retryable(req *http.Request) bool {
+	if c.cfg.Retry.MaxAttempts <= 1 {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// shouldRetry 结果是否需要重试
+func (c *Client) shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return slices.Contains(c.cfg.Retry.Statuses, resp.StatusCode)
+}
+
+// wait 等待退避间隔，优先使用 Retry-After
+func (c *Client) wait(ctx context.Context, attempt int, resp *http.Response) error {
+	delay := c.cfg.Retry.Backoff.Delay(attempt)
+	if resp != nil {
+		if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && secs >= 0 {
+			delay = time.Duration(secs) * time.Second
+		}
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// StatusError 非 2xx 响应
+type StatusError struct {
+	StatusCode int
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("httpclient: unexpected status %d: %s", e.StatusCode, bytes.TrimSpace(e.Body))
+}
+
+// DoJSON 发送 JSON 请求并解码响应，in/out 为 nil 时跳过编码/解码
+// 非 2xx 响应返回 *StatusError
+func (c *Client) DoJSON(ctx context.Context, method, url string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("httpclient: encode request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := c.NewRequest(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		return &StatusError{StatusCode: resp.StatusCode, Body: data}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil && err != io.EOF {
+		return fmt.Errorf("httpclient: decode response: %w", err)
+	}
+	return nil
+}
+
+// cancelBody 响应体关闭时释放超时 ctx
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
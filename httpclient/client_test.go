@@ -0,0 +1,150 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/requestid"
+	"github.com/aisgo/ais-go-pkg/resilience"
+)
+
+var fastRetry = RetryConfig{Backoff: resilience.BackoffConfig{Base: time.Millisecond, Max: time.Millisecond}}
+
+func TestRetryIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"echo":"` + string(body) + `","sig":"` + r.Header.Get("X-Sig") + `"}`))
+	}))
+	defer srv.Close()
+
+	var signs atomic.Int32
+	c := New(Config{BaseURL: srv.URL, Retry: fastRetry}, nil,
+		WithSigner(SignerFunc(func(req *http.Request) error {
+			req.Header.Set("X-Sig", "s")
+			signs.Add(1)
+			return nil
+		})))
+
+	var out struct {
+		Echo string `json:"echo"`
+		Sig  string `json:"sig"`
+	}
+	req, _ := c.NewRequest(context.Background(), http.MethodPut, "/items/1", strings.NewReader("payload"))
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 || signs.Load() != 3 {
+		t.Fatalf("unexpected result: status=%d calls=%d signs=%d", resp.StatusCode, calls.Load(), signs.Load())
+	}
+
+	calls.Store(0)
+	if err := c.DoJSON(context.Background(), http.MethodGet, "/items/1", nil, &out); err != nil {
+		t.Fatalf("do json: %v", err)
+	}
+	if out.Sig != "s" {
+		t.Fatalf("expected signed request, got %+v", out)
+	}
+}
+
+func TestNoRetryForNonIdempotentPost(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("down"))
+	}))
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL, Retry: fastRetry}, nil)
+	err := c.DoJSON(context.Background(), http.MethodPost, "/orders", map[string]int{"n": 1}, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected StatusError 503, got %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected single attempt for POST, got %d", calls.Load())
+	}
+
+	// 携带 Idempotency-Key 的 POST 允许重试
+	calls.Store(0)
+	req, _ := c.NewRequest(context.Background(), http.MethodPost, "/orders", strings.NewReader("{}"))
+	req.Header.Set("Idempotency-Key", "k1")
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	_ = resp.Body.Close()
+	if calls.Load() != 3 {
+		t.Fatalf("expected retries with idempotency key, got %d", calls.Load())
+	}
+}
+
+func TestHeaderFuncAndTimeout(t *testing.T) {
+	type ctxKey struct{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(r.Header.Get("X-Request-ID")))
+	}))
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL, Timeout: 50 * time.Millisecond, Retry: RetryConfig{MaxAttempts: 1}}, nil,
+		WithHeaderFunc(func(ctx context.Context, h http.Header) {
+			if id, ok := ctx.Value(ctxKey{}).(string); ok {
+				h.Set("X-Request-ID", id)
+			}
+		}))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "req-1")
+	req, _ := c.NewRequest(ctx, http.MethodGet, "/", nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "req-1" {
+		t.Fatalf("expected propagated request id, got %q", body)
+	}
+
+	req, _ = c.NewRequest(context.Background(), http.MethodGet, "/slow", nil)
+	if _, err := c.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.Header.Get(requestid.Header) + "," + r.Header.Get(requestid.CorrelationHeader)))
+	}))
+	defer srv.Close()
+
+	c := New(Config{BaseURL: srv.URL, Retry: RetryConfig{MaxAttempts: 1}}, nil)
+	ctx := requestid.WithCorrelationID(requestid.WithContext(context.Background(), "req-1"), "chain-1")
+	req, _ := c.NewRequest(ctx, http.MethodGet, "/", nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("do: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "req-1,chain-1" {
+		t.Fatalf("expected propagated ids, got %q", body)
+	}
+}
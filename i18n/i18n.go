@@ -0,0 +1,284 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/* ========================================================================
+ * I18n - 多语言翻译
+ * ========================================================================
+ * 职责: 提供消息目录管理、Accept-Language 解析与上下文语言传递
+ * 特性:
+ *   - 内置 zh / en 目录（校验消息 + 预定义错误消息）
+ *   - 支持追加用户目录（map / JSON / fs.FS）
+ *   - 占位符: {field} {param} {tag} 等，按 params 替换
+ * 使用示例:
+ *     i18n.Default.AddCatalog("en", map[string]string{"user.exists": "user {name} already exists"})
+ *     ctx = i18n.WithLocale(ctx, "en")
+ *     msg := i18n.T(ctx, "user.exists", map[string]any{"name": "bob"})
+ * ======================================================================== */
+
+const (
+	// LocaleZH 简体中文
+	LocaleZH = "zh"
+	// LocaleEN 英文
+	LocaleEN = "en"
+)
+
+//go:embed locales/*.json
+var embeddedLocales embed.FS
+
+// Default 全局默认翻译器（回退语言为 zh，已加载内置目录）
+var Default = newDefault()
+
+// Translator 翻译器
+type Translator struct {
+	mu       sync.RWMutex
+	catalogs map[string]map[string]string
+	fallback string
+}
+
+// New 创建空翻译器
+// fallback: 请求语言不受支持时使用的语言
+func New(fallback string) *Translator {
+	if fallback == "" {
+		fallback = LocaleZH
+	}
+	return &Translator{
+		catalogs: make(map[string]map[string]string),
+		fallback: normalize(fallback),
+	}
+}
+
+func newDefault() *Translator {
+	t := New(LocaleZH)
+	if err := t.LoadFS(embeddedLocales, "locales"); err != nil {
+		panic(fmt.Sprintf("i18n: failed to load embedded locales: %v", err))
+	}
+	return t
+}
+
+// AddCatalog 追加目录（同名 key 覆盖）
+func (t *Translator) AddCatalog(locale string, messages map[string]string) {
+	locale = normalize(locale)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	catalog, ok := t.catalogs[locale]
+	if !ok {
+		catalog = make(map[string]string, len(messages))
+		t.catalogs[locale] = catalog
+	}
+	for k, v := range messages {
+		catalog[k] = v
+	}
+}
+
+// LoadJSON 从 JSON 对象加载目录
+func (t *Translator) LoadJSON(locale string, data []byte) error {
+	var messages map[string]string
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return fmt.Errorf("failed to parse %s catalog: %w", locale, err)
+	}
+	t.AddCatalog(locale, messages)
+	return nil
+}
+
+// LoadFS 从目录加载 <locale>.json 文件
+func (t *Translator) LoadFS(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("failed to read locale dir: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		if err := t.LoadJSON(strings.TrimSuffix(entry.Name(), ".json"), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Locales 返回已加载的语言列表
+func (t *Translator) Locales() []string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	locales := make([]string, 0, len(t.catalogs))
+	for l := range t.catalogs {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Fallback 返回回退语言
+func (t *Translator) Fallback() string {
+	return t.fallback
+}
+
+// Resolve 将请求语言解析为已支持的语言，不支持时返回回退语言
+// 支持 "en-US" -> "en" 的前缀匹配
+func (t *Translator) Resolve(locale string) string {
+	if resolved, ok := t.Lookup(locale); ok {
+		return resolved
+	}
+	return t.fallback
+}
+
+// Lookup 将请求语言解析为已支持的语言，不支持时返回 ("", false)
+// 与 Resolve 不同，不会回退到 Fallback，便于调用方区分"命中"与"回退"
+func (t *Translator) Lookup(locale string) (string, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.lookupLocked(normalize(locale))
+}
+
+// lookupLocked 精确匹配后尝试基础语言匹配，调用方需持有读锁
+func (t *Translator) lookupLocked(locale string) (string, bool) {
+	if _, ok := t.catalogs[locale]; ok {
+		return locale, true
+	}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		if _, ok := t.catalogs[base]; ok {
+			return base, true
+		}
+	}
+	return "", false
+}
+
+// Translate 翻译 key，仅在解析后的语言目录中查找
+// 未命中时返回 ("", false)
+func (t *Translator) Translate(locale, key string, params map[string]any) (string, bool) {
+	locale = t.Resolve(locale)
+
+	t.mu.RLock()
+	msg, ok := t.catalogs[locale][key]
+	t.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+	return format(msg, params), true
+}
+
+// Message 翻译消息，未命中时原样返回 key
+func (t *Translator) Message(locale, key string, params map[string]any) string {
+	if msg, ok := t.Translate(locale, key, params); ok {
+		return msg
+	}
+	return format(key, params)
+}
+
+// MatchAcceptLanguage 按 Accept-Language（含 q 权重）选择最合适的已支持语言
+// 均不支持时返回回退语言
+func (t *Translator) MatchAcceptLanguage(header string) string {
+	if locale, ok := t.Match(header); ok {
+		return locale
+	}
+	return t.fallback
+}
+
+// Match 按 Accept-Language（含 q 权重）选择最合适的已支持语言
+// 均不支持（或 header 为空）时返回 ("", false)
+func (t *Translator) Match(header string) (string, bool) {
+	if header == "" {
+		return "", false
+	}
+
+	type candidate struct {
+		locale string
+		q      float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		candidates = append(candidates, candidate{locale: tag, q: q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, c := range candidates {
+		if locale, ok := t.lookupLocked(normalize(c.locale)); ok {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// =============================================================================
+// 上下文语言
+// =============================================================================
+
+type localeKey struct{}
+
+// WithLocale 在 ctx 中设置语言
+func WithLocale(ctx context.Context, locale string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, localeKey{}, normalize(locale))
+}
+
+// LocaleFromContext 从 ctx 获取语言
+func LocaleFromContext(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	locale, ok := ctx.Value(localeKey{}).(string)
+	return locale, ok && locale != ""
+}
+
+// T 使用 Default 翻译器与 ctx 中的语言翻译 key，未命中时返回 key
+func T(ctx context.Context, key string, params map[string]any) string {
+	locale, _ := LocaleFromContext(ctx)
+	return Default.Message(locale, key, params)
+}
+
+// =============================================================================
+// 内部工具
+// =============================================================================
+
+// normalize 统一语言标签格式: zh_CN / ZH-cn -> zh-cn
+func normalize(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// format 替换 {name} 占位符
+func format(msg string, params map[string]any) string {
+	if len(params) == 0 || !strings.Contains(msg, "{") {
+		return msg
+	}
+	pairs := make([]string, 0, len(params)*2)
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", fmt.Sprint(v))
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDefaultCatalogs(t *testing.T) {
+	msg, ok := Default.Translate("zh", "validator.required", map[string]any{"field": "Email"})
+	if !ok || msg != "Email为必填字段" {
+		t.Fatalf("unexpected zh message: %q", msg)
+	}
+	msg, ok = Default.Translate("en-US", "validator.required", map[string]any{"field": "Email"})
+	if !ok || msg != "Email is required" {
+		t.Fatalf("unexpected en message: %q", msg)
+	}
+	if _, ok := Default.Translate("en", "resource not found", nil); ok {
+		t.Fatalf("expected en catalog miss for error message")
+	}
+}
+
+func TestMatchAcceptLanguage(t *testing.T) {
+	tr := New(LocaleZH)
+	tr.AddCatalog("zh", map[string]string{"k": "值"})
+	tr.AddCatalog("en", map[string]string{"k": "value"})
+
+	cases := map[string]string{
+		"":                          "zh",
+		"en-US,en;q=0.9":            "en",
+		"fr-FR;q=0.9, en;q=0.8":     "en",
+		"ja, zh-CN;q=0.5, en;q=0.4": "zh",
+		"de":                        "zh",
+	}
+	for header, want := range cases {
+		if got := tr.MatchAcceptLanguage(header); got != want {
+			t.Fatalf("header %q: got=%s want=%s", header, got, want)
+		}
+	}
+
+	// Match / Lookup 不回退，便于调用方区分命中与回退
+	if locale, ok := tr.Match("de, fr;q=0.5"); ok {
+		t.Fatalf("expected no match, got %s", locale)
+	}
+	if locale, ok := tr.Match("fr, en-GB;q=0.5"); !ok || locale != "en" {
+		t.Fatalf("unexpected match: %s %v", locale, ok)
+	}
+	if _, ok := tr.Lookup("fr"); ok {
+		t.Fatalf("expected fr unsupported")
+	}
+	if locale, ok := tr.Lookup("zh_CN"); !ok || locale != "zh" {
+		t.Fatalf("unexpected lookup: %s %v", locale, ok)
+	}
+}
+
+func TestLoadFSAndContext(t *testing.T) {
+	tr := New(LocaleEN)
+	fsys := fstest.MapFS{
+		"i18n/en.json": {Data: []byte(`{"user.exists": "user {name} already exists"}`)},
+		"i18n/zh.json": {Data: []byte(`{"user.exists": "用户 {name} 已存在"}`)},
+	}
+	if err := tr.LoadFS(fsys, "i18n"); err != nil {
+		t.Fatalf("load fs: %v", err)
+	}
+	if got := tr.Message("zh", "user.exists", map[string]any{"name": "bob"}); got != "用户 bob 已存在" {
+		t.Fatalf("unexpected message: %q", got)
+	}
+	if got := tr.Message("zh", "missing.key", nil); got != "missing.key" {
+		t.Fatalf("expected key fallback, got: %q", got)
+	}
+
+	ctx := WithLocale(context.Background(), "EN_us")
+	locale, ok := LocaleFromContext(ctx)
+	if !ok || locale != "en-us" {
+		t.Fatalf("unexpected locale: %q", locale)
+	}
+	if got := T(ctx, "validator.required", map[string]any{"field": "Name"}); got != "Name is required" {
+		t.Fatalf("unexpected T result: %q", got)
+	}
+}
@@ -0,0 +1,235 @@
+package idgen
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/snowflake"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/uuidv7"
+)
+
+/* ========================================================================
+ * ID Generator - 统一 ID 生成器
+ * ========================================================================
+ * 职责: 以统一接口封装 ULID / Snowflake / UUIDv7，按配置切换策略；
+ *       提供带类型前缀的外部 ID（ord_01H...、usr_01H...）及前缀校验
+ * 配置示例:
+ *   idgen:
+ *     strategy: ulid      # ulid | snowflake | uuidv7
+ *     node_id: 1          # snowflake 节点 ID，0 时读取 SNOWFLAKE_NODE_ID
+ *
+ * 使用示例:
+ *   gen, _ := idgen.New(idgen.Config{Strategy: idgen.StrategyULID})
+ *   orders := idgen.NewPrefixed("ord", gen)
+ *   id := orders.Generate()            // ord_01HN3K8X9FQZM6Y8VWXQR2JNPT
+ *   raw, err := orders.Parse(id)       // 01HN3K8X9FQZM6Y8VWXQR2JNPT
+ *   err = orders.Validate("usr_01HN...") // 前缀不匹配
+ * ======================================================================== */
+
+// Strategy ID 生成策略
+type Strategy string
+
+const (
+	StrategyULID      Strategy = "ulid"
+	StrategySnowflake Strategy = "snowflake"
+	StrategyUUIDv7    Strategy = "uuidv7"
+)
+
+// Separator 前缀分隔符
+const Separator = "_"
+
+// Generator ID 生成器
+type Generator interface {
+	// Generate 生成 ID（字符串形式）
+	Generate() string
+	// Validate 校验 ID 格式是否符合当前策略
+	Validate(id string) error
+	// Strategy 返回生成策略
+	Strategy() Strategy
+}
+
+// Config 生成器配置
+type Config struct {
+	Strategy Strategy `yaml:"strategy"` // 默认 ulid
+	NodeID   int64    `yaml:"node_id"`  // snowflake 节点 ID
+}
+
+// New 按配置创建生成器
+func New(cfg Config) (Generator, error) {
+	switch cfg.Strategy {
+	case "", StrategyULID:
+		return ULID(), nil
+	case StrategySnowflake:
+		nodeID := cfg.NodeID
+		if nodeID == 0 {
+			if v, err := strconv.ParseInt(os.Getenv(snowflake.EnvNodeID), 10, 64); err == nil {
+				nodeID = v
+			}
+		}
+		return Snowflake(nodeID)
+	case StrategyUUIDv7:
+		return UUIDv7(), nil
+	default:
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "unsupported id strategy: "+string(cfg.Strategy))
+	}
+}
+
+// invalidID 格式错误
+func invalidID(strategy Strategy, id string) error {
+	return errors.New(errors.ErrCodeInvalidArgument, "invalid id").
+		WithDetail("strategy", string(strategy)).
+		WithDetail("id", id)
+}
+
+// ========================================================================
+// ULID
+// ========================================================================
+
+type ulidGenerator struct{}
+
+// ULID 创建 ULID 生成器（26 位 Crockford Base32，大写）
+func ULID() Generator { return ulidGenerator{} }
+
+func (ulidGenerator) Generate() string { return ulid.GenerateString() }
+
+func (ulidGenerator) Validate(id string) error {
+	if len(id) != 26 {
+		return invalidID(StrategyULID, id)
+	}
+	if _, err := ulid.ParseLoose(id); err != nil {
+		return invalidID(StrategyULID, id)
+	}
+	return nil
+}
+
+func (ulidGenerator) Strategy() Strategy { return StrategyULID }
+
+// ========================================================================
+// Snowflake
+// ========================================================================
+
+type snowflakeGenerator struct {
+	gen *snowflake.Generator
+}
+
+// Snowflake 创建雪花 ID 生成器（十进制字符串）
+func Snowflake(nodeID int64) (Generator, error) {
+	gen, err := snowflake.NewGenerator(nodeID)
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInvalidArgument, "invalid snowflake node id", err)
+	}
+	return snowflakeGenerator{gen: gen}, nil
+}
+
+func (g snowflakeGenerator) Generate() string { return g.gen.GenerateString() }
+
+func (snowflakeGenerator) Validate(id string) error {
+	if n, err := strconv.ParseInt(id, 10, 64); err != nil || n <= 0 {
+		return invalidID(StrategySnowflake, id)
+	}
+	return nil
+}
+
+func (snowflakeGenerator) Strategy() Strategy { return StrategySnowflake }
+
+// ========================================================================
+// UUIDv7
+// ========================================================================
+
+type uuidv7Generator struct{}
+
+// UUIDv7 创建 UUIDv7 生成器（时间有序的标准 UUID 字符串）
+func UUIDv7() Generator { return uuidv7Generator{} }
+
+func (uuidv7Generator) Generate() string { return uuidv7.GenerateString() }
+
+func (uuidv7Generator) Validate(id string) error {
+	if _, err := uuidv7.Parse(id); err != nil {
+		return invalidID(StrategyUUIDv7, id)
+	}
+	return nil
+}
+
+func (uuidv7Generator) Strategy() Strategy { return StrategyUUIDv7 }
+
+// ========================================================================
+// 带前缀的 ID
+// ========================================================================
+
+// Prefixed 带类型前缀的 ID 生成器
+type Prefixed struct {
+	prefix string
+	gen    Generator
+}
+
+// NewPrefixed 创建带前缀的生成器，前缀仅允许小写字母与数字
+func NewPrefixed(prefix string, gen Generator) *Prefixed {
+	if !validPrefix(prefix) {
+		panic(fmt.Sprintf("idgen: invalid prefix %q", prefix))
+	}
+	return &Prefixed{prefix: prefix, gen: gen}
+}
+
+// Prefix 返回前缀
+func (p *Prefixed) Prefix() string {
+	return p.prefix
+}
+
+// Generate 生成带前缀的 ID
+func (p *Prefixed) Generate() string {
+	return p.prefix + Separator + p.gen.Generate()
+}
+
+// Parse 校验前缀与格式，返回去除前缀的原始 ID
+func (p *Prefixed) Parse(id string) (string, error) {
+	prefix, raw, err := Split(id)
+	if err != nil {
+		return "", err
+	}
+	if prefix != p.prefix {
+		return "", errors.New(errors.ErrCodeInvalidArgument, "id prefix mismatch").
+			WithDetail("expected", p.prefix).
+			WithDetail("actual", prefix)
+	}
+	if err := p.gen.Validate(raw); err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// Validate 校验带前缀的 ID
+func (p *Prefixed) Validate(id string) error {
+	_, err := p.Parse(id)
+	return err
+}
+
+// Wrap 为已有的原始 ID 添加前缀
+func (p *Prefixed) Wrap(raw string) string {
+	return p.prefix + Separator + raw
+}
+
+// Split 拆分带前缀的 ID
+func Split(id string) (prefix, raw string, err error) {
+	prefix, raw, ok := strings.Cut(id, Separator)
+	if !ok || !validPrefix(prefix) || raw == "" {
+		return "", "", errors.New(errors.ErrCodeInvalidArgument, "invalid prefixed id").WithDetail("id", id)
+	}
+	return prefix, raw, nil
+}
+
+// validPrefix 前缀仅允许 1-16 位小写字母与数字
+func validPrefix(prefix string) bool {
+	if prefix == "" || len(prefix) > 16 {
+		return false
+	}
+	for _, r := range prefix {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
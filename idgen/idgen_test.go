@@ -0,0 +1,88 @@
+package idgen
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"go.uber.org/fx"
+)
+
+func TestStrategies(t *testing.T) {
+	for _, cfg := range []Config{
+		{},
+		{Strategy: StrategySnowflake, NodeID: 3},
+		{Strategy: StrategyUUIDv7},
+	} {
+		gen, err := New(cfg)
+		if err != nil {
+			t.Fatalf("new %q: %v", cfg.Strategy, err)
+		}
+		a, b := gen.Generate(), gen.Generate()
+		if a == b {
+			t.Fatalf("%s: expected unique ids", gen.Strategy())
+		}
+		if err := gen.Validate(a); err != nil {
+			t.Fatalf("%s: generated id %q failed validation: %v", gen.Strategy(), a, err)
+		}
+		if err := gen.Validate("not-an-id"); errors.Code(err) != errors.ErrCodeInvalidArgument {
+			t.Fatalf("%s: expected invalid argument, got %v", gen.Strategy(), err)
+		}
+	}
+
+	if _, err := New(Config{Strategy: "uuidv4"}); err == nil {
+		t.Fatalf("expected unsupported strategy error")
+	}
+	if _, err := New(Config{Strategy: StrategySnowflake, NodeID: 5000}); err == nil {
+		t.Fatalf("expected invalid node id error")
+	}
+}
+
+func TestPrefixed(t *testing.T) {
+	orders := NewPrefixed("ord", ULID())
+	users := NewPrefixed("usr", ULID())
+
+	id := orders.Generate()
+	if !strings.HasPrefix(id, "ord_") || len(id) != 30 {
+		t.Fatalf("unexpected id %q", id)
+	}
+	raw, err := orders.Parse(id)
+	if err != nil || orders.Wrap(raw) != id {
+		t.Fatalf("parse: %q %v", raw, err)
+	}
+	if err := users.Validate(id); errors.Code(err) != errors.ErrCodeInvalidArgument {
+		t.Fatalf("expected prefix mismatch, got %v", err)
+	}
+	for _, bad := range []string{"", "ord", "ord_", "ORD_" + raw, "ord_123"} {
+		if err := orders.Validate(bad); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected invalid prefix to panic")
+		}
+	}()
+	NewPrefixed("Bad-Prefix", ULID())
+}
+
+func TestModule(t *testing.T) {
+	var gen Generator
+	app := fx.New(
+		fx.NopLogger,
+		Module,
+		fx.Supply(Config{Strategy: StrategyUUIDv7}),
+		fx.Populate(&gen),
+	)
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer app.Stop(context.Background())
+
+	if gen.Strategy() != StrategyUUIDv7 {
+		t.Fatalf("unexpected strategy %s", gen.Strategy())
+	}
+}
@@ -0,0 +1,27 @@
+package idgen
+
+import "go.uber.org/fx"
+
+/* ========================================================================
+ * IDGen FX Module - ID 生成器 FX 模块
+ * ========================================================================
+ * 职责: 按 Config 提供 Generator
+ * 说明: 需由应用提供 Config，带前缀的生成器由业务模块通过 NewPrefixed 构建
+ * ======================================================================== */
+
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	Config Config `optional:"true"`
+}
+
+// NewFromParams 从 FX 参数创建生成器
+func NewFromParams(p Params) (Generator, error) {
+	return New(p.Config)
+}
+
+// Module FX 模块
+var Module = fx.Module("idgen",
+	fx.Provide(NewFromParams),
+)
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/aisgo/ais-go-pkg/buildinfo"
+	"github.com/aisgo/ais-go-pkg/requestid"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -24,6 +27,11 @@ type Config struct {
 	Output string `yaml:"output"` // stdout, file
 }
 
+// DefaultConfig 返回默认配置（info 级别，JSON 输出到 stdout）
+func DefaultConfig() Config {
+	return Config{Level: "info", Format: "json", Output: "stdout"}
+}
+
 // Logger 封装 Zap Logger
 type Logger struct {
 	*zap.Logger
@@ -72,7 +80,8 @@ func NewLogger(cfg Config) *Logger {
 		level,
 	)
 
-	logger := zap.New(core, zap.AddCaller())
+	// 根 logger 附带构建信息，便于按版本排查
+	logger := zap.New(core, zap.AddCaller()).With(buildinfo.ZapField())
 	return &Logger{Logger: logger}
 }
 
@@ -99,12 +108,20 @@ func NewNop() *Logger {
 	return &Logger{Logger: zap.NewNop()}
 }
 
-// WithContext 从 Context 提取 TraceID (后续实现) 并注入 Logger
+// WithContext 从 Context 提取请求 ID / 关联 ID 并注入 Logger
 func (l *Logger) WithContext(ctx context.Context) *zap.Logger {
-	// 占位: 后续集成 TraceID
-	// traceID := ctx.Value("trace_id")
-	// if traceID != nil {
-	// 	return l.Logger.With(zap.Any("trace_id", traceID))
-	// }
-	return l.Logger
+	if ctx == nil {
+		return l.Logger
+	}
+	var fields []zap.Field
+	if id := requestid.FromContext(ctx); id != "" {
+		fields = append(fields, zap.String("request_id", id))
+	}
+	if id := requestid.CorrelationIDFromContext(ctx); id != "" && id != requestid.FromContext(ctx) {
+		fields = append(fields, zap.String("correlation_id", id))
+	}
+	if len(fields) == 0 {
+		return l.Logger
+	}
+	return l.Logger.With(fields...)
 }
@@ -1,11 +1,15 @@
 package logger
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/aisgo/ais-go-pkg/requestid"
+
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestValidateConfig(t *testing.T) {
@@ -53,3 +57,24 @@ func TestNewLoggerFileOutput(t *testing.T) {
 		t.Fatalf("expected log file not empty")
 	}
 }
+
+func TestWithContextRequestID(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := &Logger{Logger: zap.New(core)}
+
+	ctx := requestid.WithContext(context.Background(), "req-1")
+	log.WithContext(ctx).Info("a")
+	log.WithContext(requestid.WithCorrelationID(ctx, "chain-1")).Info("b")
+	log.WithContext(context.Background()).Info("c")
+
+	entries := logs.All()
+	if got := entries[0].ContextMap(); got["request_id"] != "req-1" || got["correlation_id"] != nil {
+		t.Fatalf("unexpected fields: %v", got)
+	}
+	if got := entries[1].ContextMap(); got["correlation_id"] != "chain-1" {
+		t.Fatalf("unexpected fields: %v", got)
+	}
+	if len(entries[2].Context) != 0 {
+		t.Fatalf("expected no fields, got %v", entries[2].Context)
+	}
+}
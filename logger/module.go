@@ -2,12 +2,15 @@ package logger
 
 import (
 	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/conf"
 )
 
 /* ========================================================================
  * Logger Module
  * ========================================================================
  * 职责: 提供 Logger 依赖注入模块
+ * Bundle: 从 conf.Source 的 logger 节解码 Config（缺省见 DefaultConfig）
  * ======================================================================== */
 
 // Module Logger 模块
@@ -15,3 +18,11 @@ import (
 var Module = fx.Module("logger",
 	fx.Provide(NewLogger),
 )
+
+// Bundle 带配置解码的 Logger 模块
+// 依赖: conf.Source
+// 提供: logger.Config, *logger.Logger
+var Bundle = fx.Module("logger-bundle",
+	fx.Provide(conf.Section("logger", DefaultConfig)),
+	Module,
+)
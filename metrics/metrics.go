@@ -1,6 +1,10 @@
 package metrics
 
 import (
+	"net/http"
+
+	"github.com/aisgo/ais-go-pkg/buildinfo"
+
 	"github.com/gofiber/fiber/v3"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -12,6 +16,10 @@ import (
  * Prometheus Metrics - 可观测性指标
  * ========================================================================
  * 职责: 提供 Prometheus 指标注册和暴露
+ * 注册表:
+ *   - 默认使用全局注册表（prometheus.DefaultRegisterer），包级指标与 NewCounter 等均注册于此
+ *   - 隔离场景（同进程多个应用、并行测试）使用 With(reg) 在指定注册表上创建指标，
+ *     NewRegistry 创建含运行时与内置指标的独立注册表，NewTestRegistry 用于测试
  * ======================================================================== */
 
 var (
@@ -85,55 +93,44 @@ var (
 	)
 )
 
-// RegisterMetricsEndpoint 注册 /metrics 端点
+// RegisterMetricsEndpoint 注册 /metrics 端点（同时注册 app_build_info）
+// 客户端 Accept 协商 OpenMetrics 时输出 exemplar
 func RegisterMetricsEndpoint(app *fiber.App) {
-	// 使用 fasthttpadaptor 将 promhttp.Handler 适配到 Fiber
-	handler := fasthttpadaptor.NewFastHTTPHandler(promhttp.Handler())
+	buildinfo.RegisterMetrics()
+	mountHandler(app, promhttp.InstrumentMetricHandler(prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true})))
+}
+
+// RegisterMetricsEndpointFor 注册 /metrics 端点，输出 gatherer 中的指标
+// gatherer 为 nil 或默认注册表时等同 RegisterMetricsEndpoint
+func RegisterMetricsEndpointFor(app *fiber.App, gatherer prometheus.Gatherer) {
+	if gatherer == nil || gatherer == prometheus.DefaultGatherer {
+		RegisterMetricsEndpoint(app)
+		return
+	}
+	mountHandler(app, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}))
+}
+
+// mountHandler 使用 fasthttpadaptor 将 promhttp handler 适配到 Fiber
+func mountHandler(app *fiber.App, h http.Handler) {
+	handler := fasthttpadaptor.NewFastHTTPHandler(h)
 	app.Get("/metrics", func(c fiber.Ctx) error {
 		handler(c.RequestCtx())
 		return nil
 	})
 }
 
-// NewCounter 创建自定义 Counter
+// NewCounter 创建自定义 Counter（注册到默认注册表）
 func NewCounter(namespace, subsystem, name, help string, labels []string) *prometheus.CounterVec {
-	return promauto.NewCounterVec(
-		prometheus.CounterOpts{
-			Namespace: namespace,
-			Subsystem: subsystem,
-			Name:      name,
-			Help:      help,
-		},
-		labels,
-	)
+	return With(prometheus.DefaultRegisterer).NewCounter(namespace, subsystem, name, help, labels)
 }
 
-// NewGauge 创建自定义 Gauge
+// NewGauge 创建自定义 Gauge（注册到默认注册表）
 func NewGauge(namespace, subsystem, name, help string, labels []string) *prometheus.GaugeVec {
-	return promauto.NewGaugeVec(
-		prometheus.GaugeOpts{
-			Namespace: namespace,
-			Subsystem: subsystem,
-			Name:      name,
-			Help:      help,
-		},
-		labels,
-	)
+	return With(prometheus.DefaultRegisterer).NewGauge(namespace, subsystem, name, help, labels)
 }
 
-// NewHistogram 创建自定义 Histogram
+// NewHistogram 创建自定义 Histogram（注册到默认注册表），buckets 为 nil 时使用默认分桶
 func NewHistogram(namespace, subsystem, name, help string, labels []string, buckets []float64) *prometheus.HistogramVec {
-	if buckets == nil {
-		buckets = prometheus.DefBuckets
-	}
-	return promauto.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Namespace: namespace,
-			Subsystem: subsystem,
-			Name:      name,
-			Help:      help,
-			Buckets:   buckets,
-		},
-		labels,
-	)
+	return With(prometheus.DefaultRegisterer).NewHistogram(namespace, subsystem, name, help, labels, buckets)
 }
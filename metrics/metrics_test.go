@@ -1,13 +1,20 @@
 package metrics
 
 import (
+	"context"
+	stderrors "errors"
 	"io"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/requestid"
+
 	"github.com/gofiber/fiber/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 func TestRegisterMetricsEndpoint(t *testing.T) {
@@ -36,3 +43,85 @@ func TestRegisterMetricsEndpoint(t *testing.T) {
 		t.Fatalf("expected metrics output to include test_unit_total")
 	}
 }
+
+func TestRegistryIsolation(t *testing.T) {
+	// 同名指标可在不同注册表上各自注册
+	regA, regB := NewTestRegistry(), NewTestRegistry()
+	counterA := With(regA).NewCounter("test", "isolation", "total", "isolation counter", nil)
+	counterA.WithLabelValues().Add(2)
+	With(regB).NewCounter("test", "isolation", "total", "isolation counter", nil).WithLabelValues().Inc()
+
+	if got := testutil.ToFloat64(counterA); got != 2 {
+		t.Fatalf("regA = %v, want 2", got)
+	}
+	if n, err := testutil.GatherAndCount(regA, "test_isolation_total"); err != nil || n != 1 {
+		t.Fatalf("regA count = %d, err = %v", n, err)
+	}
+	if n, err := testutil.GatherAndCount(prometheus.DefaultGatherer, "test_isolation_total"); err != nil || n != 0 {
+		t.Fatalf("default registry count = %d, err = %v", n, err)
+	}
+
+	app := fiber.New()
+	RegisterMetricsEndpointFor(app, regB)
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "test_isolation_total 1") || strings.Contains(string(body), "app_build_info") {
+		t.Fatalf("metrics body = %s", body)
+	}
+}
+
+func TestNewRegistry(t *testing.T) {
+	reg := NewRegistry()
+	HTTPRequestTotal.WithLabelValues("GET", "/registry", "200").Inc()
+	for _, name := range []string{"app_http_request_total", "app_build_info", "go_goroutines"} {
+		if n, err := testutil.GatherAndCount(reg, name); err != nil || n == 0 {
+			t.Fatalf("%s count = %d, err = %v", name, n, err)
+		}
+	}
+
+	if r := ProvideRegistry(Params{Registry: reg}); r.Registerer != reg || r.Gatherer != reg {
+		t.Fatalf("provided = %+v, want custom registry", r)
+	}
+	if r := ProvideRegistry(Params{}); r.Registerer != prometheus.DefaultRegisterer || r.Gatherer != prometheus.DefaultGatherer {
+		t.Fatalf("provided = %+v, want default registry", r)
+	}
+}
+
+func TestRecordOperation(t *testing.T) {
+	for err, want := range map[error]string{
+		nil:                      OutcomeSuccess,
+		errors.ErrNotFound:       OutcomeClientError,
+		errors.ErrInternal:       OutcomeError,
+		stderrors.New("db down"): OutcomeError,
+	} {
+		if got := Outcome(err); got != want {
+			t.Fatalf("Outcome(%v) = %s, want %s", err, got, want)
+		}
+	}
+
+	RecordOperation("order", "cancel", errors.ErrInvalidArgument, 20*time.Millisecond)
+	if got := testutil.ToFloat64(SLIOperationTotal.WithLabelValues("order", "cancel", OutcomeClientError)); got != 1 {
+		t.Fatalf("client_error total = %v, want 1", got)
+	}
+
+	ctx := requestid.WithContext(context.Background(), "req-sli-1")
+	RecordOperationCtx(ctx, "order", "create", nil, 10*time.Millisecond)
+
+	app := fiber.New()
+	RegisterMetricsEndpoint(app)
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `app_sli_operation_total{module="order",operation="create",outcome="success"} 1.0 # {request_id="req-sli-1"} 1.0`) {
+		t.Fatalf("exemplar not found in metrics output")
+	}
+}
@@ -1,19 +1,42 @@
 package metrics
 
 import (
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/fx"
 )
 
 /* ========================================================================
  * Metrics Module
  * ========================================================================
- * 职责: 提供 Prometheus 指标依赖注入模块
+ * 职责: 提供 Prometheus 注册表依赖注入模块
+ * 说明: 应用提供 *prometheus.Registry 时使用该注册表，否则使用全局默认注册表
  * ======================================================================== */
 
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	Registry *prometheus.Registry `optional:"true"`
+}
+
+// Result 注册表
+type Result struct {
+	fx.Out
+
+	Registerer prometheus.Registerer
+	Gatherer   prometheus.Gatherer
+}
+
+// ProvideRegistry 提供 Registerer / Gatherer
+func ProvideRegistry(p Params) Result {
+	if p.Registry == nil {
+		return Result{Registerer: prometheus.DefaultRegisterer, Gatherer: prometheus.DefaultGatherer}
+	}
+	return Result{Registerer: p.Registry, Gatherer: p.Registry}
+}
+
 // Module 指标模块
+// 提供: prometheus.Registerer, prometheus.Gatherer
 var Module = fx.Module("metrics",
-	// Metrics 通常在 HTTP Server 启动时自动注册，此处导出一些自定义 Collector (如有)
-	fx.Invoke(func() {
-		// 执行一些初始化的指标注册
-	}),
+	fx.Provide(ProvideRegistry),
 )
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"github.com/aisgo/ais-go-pkg/buildinfo"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+/* ========================================================================
+ * Registry - 注册表隔离
+ * ========================================================================
+ * 职责: 在指定注册表上创建指标，提供独立注册表与测试注册表
+ *
+ * 使用示例:
+ *   reg := metrics.NewTestRegistry()
+ *   orders := metrics.With(reg).NewCounter("app", "order", "created_total", "Orders created", nil)
+ *
+ *   // 应用使用独立注册表: 提供 *prometheus.Registry，metrics.Module 据此提供 Registerer/Gatherer，
+ *   // transport/http 的 /metrics 输出该注册表
+ *   fx.Provide(metrics.NewRegistry)
+ * ======================================================================== */
+
+// Factory 在指定注册表上创建指标
+type Factory struct {
+	reg prometheus.Registerer
+}
+
+// With 返回在 reg 上注册指标的 Factory，reg 为 nil 时创建的指标不注册
+// 重复注册同名指标时 panic（与 promauto 一致）
+func With(reg prometheus.Registerer) Factory {
+	return Factory{reg: reg}
+}
+
+// NewCounter 创建 Counter
+func (f Factory) NewCounter(namespace, subsystem, name, help string, labels []string) *prometheus.CounterVec {
+	return promauto.With(f.reg).NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+		},
+		labels,
+	)
+}
+
+// NewGauge 创建 Gauge
+func (f Factory) NewGauge(namespace, subsystem, name, help string, labels []string) *prometheus.GaugeVec {
+	return promauto.With(f.reg).NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+		},
+		labels,
+	)
+}
+
+// NewHistogram 创建 Histogram，buckets 为 nil 时使用默认分桶
+func (f Factory) NewHistogram(namespace, subsystem, name, help string, labels []string, buckets []float64) *prometheus.HistogramVec {
+	if buckets == nil {
+		buckets = prometheus.DefBuckets
+	}
+	return promauto.With(f.reg).NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: subsystem,
+			Name:      name,
+			Help:      help,
+			Buckets:   buckets,
+		},
+		labels,
+	)
+}
+
+// Builtin 返回包级内置指标（HTTP/gRPC/DB/Cache/SLI），用于注册到独立注册表
+func Builtin() []prometheus.Collector {
+	return []prometheus.Collector{
+		HTTPRequestDuration, HTTPRequestTotal,
+		GRPCRequestDuration, GRPCRequestTotal,
+		DBQueryDuration, CacheHitTotal,
+		SLIOperationDuration, SLIOperationTotal,
+	}
+}
+
+// NewRegistry 创建独立注册表，包含 Go 运行时、进程、app_build_info 与内置指标
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		buildinfo.NewCollector(),
+	)
+	reg.MustRegister(Builtin()...)
+	return reg
+}
+
+// NewTestRegistry 创建测试用的空注册表（pedantic 模式，注册时校验指标描述一致性）
+func NewTestRegistry() *prometheus.Registry {
+	return prometheus.NewPedanticRegistry()
+}
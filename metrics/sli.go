@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/requestid"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+/* ========================================================================
+ * SLI - 业务操作延迟与错误率
+ * ========================================================================
+ * 职责: 以统一的指标名记录业务操作（模块 + 操作）的耗时与结果，SLO 看板无需按服务映射指标名
+ * 指标:
+ *   app_sli_operation_duration_seconds{module, operation, outcome}  耗时直方图
+ *   app_sli_operation_total{module, operation, outcome}             次数
+ * 结果分类（outcome）:
+ *   - success       err 为 nil
+ *   - client_error  BizError 且对应 HTTP 状态码 < 500（参数错误、未找到等，不计入错误预算）
+ *   - error         其他错误（计入错误预算）
+ * Exemplar: RecordOperationCtx 在 ctx 携带请求 ID 时附加 exemplar {request_id}，
+ *           /metrics 以 OpenMetrics 格式输出时可从看板跳转到对应日志
+ *
+ * 使用示例:
+ *   start := time.Now()
+ *   err := svc.CreateOrder(ctx, req)
+ *   metrics.RecordOperationCtx(ctx, "order", "create", err, time.Since(start))
+ *
+ *   // 错误率: sum(rate(app_sli_operation_total{outcome="error"}[5m])) by (module, operation)
+ *   //        / sum(rate(app_sli_operation_total[5m])) by (module, operation)
+ * ======================================================================== */
+
+// 操作结果分类
+const (
+	OutcomeSuccess     = "success"
+	OutcomeClientError = "client_error"
+	OutcomeError       = "error"
+)
+
+// exemplarLabel exemplar 中请求 ID 的标签名
+const exemplarLabel = "request_id"
+
+var (
+	// SLIOperationDuration 业务操作耗时
+	SLIOperationDuration = NewHistogram("app", "sli", "operation_duration_seconds",
+		"Business operation duration in seconds", []string{"module", "operation", "outcome"}, nil)
+
+	// SLIOperationTotal 业务操作次数
+	SLIOperationTotal = NewCounter("app", "sli", "operation_total",
+		"Total number of business operations", []string{"module", "operation", "outcome"})
+)
+
+// Outcome 返回 err 对应的结果分类
+func Outcome(err error) string {
+	if err == nil {
+		return OutcomeSuccess
+	}
+	if _, ok := errors.AsBizError(err); ok {
+		if status, _ := errors.ToHTTPResponse(err); status < 500 {
+			return OutcomeClientError
+		}
+	}
+	return OutcomeError
+}
+
+// RecordOperation 记录业务操作的耗时与结果
+func RecordOperation(module, op string, err error, duration time.Duration) {
+	RecordOperationCtx(context.Background(), module, op, err, duration)
+}
+
+// RecordOperationCtx 记录业务操作的耗时与结果，ctx 携带请求 ID 时附加 exemplar
+func RecordOperationCtx(ctx context.Context, module, op string, err error, duration time.Duration) {
+	outcome := Outcome(err)
+	histogram := SLIOperationDuration.WithLabelValues(module, op, outcome)
+	counter := SLIOperationTotal.WithLabelValues(module, op, outcome)
+
+	exemplar := exemplarOf(ctx)
+	if exemplar == nil {
+		histogram.Observe(duration.Seconds())
+		counter.Inc()
+		return
+	}
+	histogram.(prometheus.ExemplarObserver).ObserveWithExemplar(duration.Seconds(), exemplar)
+	counter.(prometheus.ExemplarAdder).AddWithExemplar(1, exemplar)
+}
+
+// exemplarOf 以 ctx 中的请求 ID 构造 exemplar，ID 缺失或超出 exemplar 长度限制时返回 nil
+func exemplarOf(ctx context.Context) prometheus.Labels {
+	id := requestid.FromContext(ctx)
+	if !requestid.Valid(id) || len(exemplarLabel)+len(id) > prometheus.ExemplarMaxRunes {
+		return nil
+	}
+	return prometheus.Labels{exemplarLabel: id}
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/utils/mask"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Access Log Middleware - 访问日志
+ * ========================================================================
+ * 职责: 记录请求方法/路径/状态码/耗时/客户端 IP/请求 ID
+ * 脱敏: 查询参数按 mask_query 配置的脱敏类型处理（如 phone=138****8000），
+ *       未配置的敏感参数名（token/password 等）默认整体遮盖
+ * 错误: 链路返回的错误先交给 App ErrorHandler 写入响应，记录最终状态码
+ * 配置示例:
+ *   access_log:
+ *     skip_paths: ["/healthz", "/readyz", "/metrics"]
+ *     mask_query:
+ *       phone: phone
+ *       email: email
+ *
+ * 使用示例:
+ *   app.Use(middleware.AccessLog(cfg.AccessLog, log))
+ * ======================================================================== */
+
+// AccessLogConfig 访问日志配置
+type AccessLogConfig struct {
+	SkipPaths []string          `yaml:"skip_paths"` // 不记录的路径
+	MaskQuery map[string]string `yaml:"mask_query"` // 查询参数名 -> 脱敏类型
+}
+
+// sensitiveQueryParams 默认整体遮盖的查询参数
+var sensitiveQueryParams = map[string]string{
+	"token":         mask.KindSecret,
+	"access_token":  mask.KindSecret,
+	"refresh_token": mask.KindSecret,
+	"password":      mask.KindSecret,
+	"secret":        mask.KindSecret,
+	"api_key":       mask.KindSecret,
+	"signature":     mask.KindSecret,
+}
+
+// AccessLog 创建访问日志中间件
+func AccessLog(cfg AccessLogConfig, log *logger.Logger) fiber.Handler {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	rules := make(map[string]string, len(sensitiveQueryParams)+len(cfg.MaskQuery))
+	for k, v := range sensitiveQueryParams {
+		rules[k] = v
+	}
+	for k, v := range cfg.MaskQuery {
+		rules[k] = v
+	}
+
+	return func(c fiber.Ctx) error {
+		if slices.Contains(cfg.SkipPaths, c.Path()) {
+			return c.Next()
+		}
+
+		start := time.Now()
+		err := c.Next()
+		if err != nil {
+			// 先交给 ErrorHandler 写入响应，以记录最终状态码
+			if handleErr := c.App().ErrorHandler(c, err); handleErr != nil {
+				_ = c.SendStatus(fiber.StatusInternalServerError)
+			}
+		}
+		status := c.Response().StatusCode()
+
+		// fiber 的字符串引用可复用的请求缓冲区，写入日志前需拷贝
+		fields := []zap.Field{
+			zap.String("method", strings.Clone(c.Method())),
+			zap.String("path", strings.Clone(c.Path())),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("ip", strings.Clone(c.IP())),
+		}
+		if q := maskQuery(string(c.Request().URI().QueryString()), rules); q != "" {
+			fields = append(fields, zap.String("query", q))
+		}
+		if id := RequestIDFromCtx(c); id != "" {
+			fields = append(fields, zap.String("request_id", strings.Clone(id)))
+		}
+		if err != nil {
+			fields = append(fields, zap.Error(err))
+		}
+
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			log.Error("HTTP request", fields...)
+		case status >= fiber.StatusBadRequest:
+			log.Warn("HTTP request", fields...)
+		default:
+			log.Info("HTTP request", fields...)
+		}
+		return nil
+	}
+}
+
+// maskQuery 按规则对查询参数脱敏
+func maskQuery(raw string, rules map[string]string) string {
+	if raw == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return mask.Secret(raw)
+	}
+	for key, vals := range values {
+		kind, ok := rules[key]
+		if !ok {
+			continue
+		}
+		for i, v := range vals {
+			vals[i] = mask.Mask(kind, v)
+		}
+	}
+	return values.Encode()
+}
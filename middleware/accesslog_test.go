@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLog(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	log := &logger.Logger{Logger: zap.New(core)}
+
+	app := fiber.New(fiber.Config{ErrorHandler: response.NewFiberErrorHandler(logger.NewNop())})
+	app.Use(AccessLog(AccessLogConfig{
+		SkipPaths: []string{"/healthz"},
+		MaskQuery: map[string]string{"phone": "phone"},
+	}, log))
+	app.Get("/healthz", func(c fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/users", func(c fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/denied", func(c fiber.Ctx) error {
+		return errors.New(errors.ErrCodePermissionDenied, "denied")
+	})
+
+	for _, target := range []string{"/healthz", "/users?phone=13800138000&token=abc&page=2", "/denied"} {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set(response.RequestIDHeader, "req-1")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request %s: %v", target, err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+
+	ok := entries[0].ContextMap()
+	if ok["path"] != "/users" || ok["status"] != int64(200) || ok["request_id"] != "req-1" {
+		t.Fatalf("unexpected entry: %v", ok)
+	}
+	query, _ := ok["query"].(string)
+	if !strings.Contains(query, "phone=138%2A%2A%2A%2A8000") || strings.Contains(query, "abc") ||
+		!strings.Contains(query, "page=2") {
+		t.Fatalf("unexpected masked query: %q", query)
+	}
+
+	denied := entries[1]
+	if denied.Level != zap.WarnLevel || denied.ContextMap()["status"] != int64(fiber.StatusForbidden) {
+		t.Fatalf("unexpected error entry: %v %v", denied.Level, denied.ContextMap())
+	}
+}
@@ -1,12 +1,17 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -118,3 +123,98 @@ func TestAPIKeyAuthBearer(t *testing.T) {
 		t.Fatalf("unexpected status: %d", resp.StatusCode)
 	}
 }
+
+type fakeKeyResolver func(ctx context.Context, apiKey string) (*APIKeyInfo, error)
+
+func (f fakeKeyResolver) ResolveAPIKey(ctx context.Context, apiKey string) (*APIKeyInfo, error) {
+	return f(ctx, apiKey)
+}
+
+func TestAPIKeyAuthScopesAndResolver(t *testing.T) {
+	auth := NewAPIKeyAuth(&APIKeyConfig{
+		Enabled: true,
+		Keys:    map[string]string{"reader": "sk_reader", "admin": "sk_admin"},
+		Scopes:  map[string][]string{"reader": {"orders:read"}, "admin": {ScopeAll}},
+	}, logger.NewNop())
+
+	app := fiber.New()
+	app.Delete("/orders", auth.Authenticate(), RequireScopes("orders:delete"), func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	for key, want := range map[string]int{"sk_reader": fiber.StatusForbidden, "sk_admin": fiber.StatusOK} {
+		req := httptest.NewRequest("DELETE", "/orders", nil)
+		req.Header.Set("X-API-Key", key)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("%s: expected %d, got %d", key, want, resp.StatusCode)
+		}
+	}
+
+	past := time.Now().Add(-time.Second)
+	results := map[string]func() (*APIKeyInfo, error){
+		"expired": func() (*APIKeyInfo, error) { return &APIKeyInfo{KeyID: "k", ExpiresAt: &past}, nil },
+		"down":    func() (*APIKeyInfo, error) { return nil, errors.New("db down") },
+	}
+	resolver := fakeKeyResolver(func(_ context.Context, apiKey string) (*APIKeyInfo, error) {
+		return results[apiKey]()
+	})
+	app = fiber.New()
+	app.Use(NewAPIKeyAuthWithResolver(&APIKeyConfig{Enabled: true}, resolver, logger.NewNop()).Authenticate())
+	app.Get("/", func(c fiber.Ctx) error { return c.SendString("ok") })
+	for key, want := range map[string]int{"expired": fiber.StatusUnauthorized, "down": fiber.StatusServiceUnavailable} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", key)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("%s: expected %d, got %d", key, want, resp.StatusCode)
+		}
+	}
+}
+
+func TestAPIKeyAuthTenantContext(t *testing.T) {
+	tenantID := "01HZX3M5Q8W2T9V6B4N7K1J0PA"
+	auth := NewAPIKeyAuth(&APIKeyConfig{
+		Enabled: true,
+		Keys:    map[string]string{"svc": "sk_svc", "broken": "sk_broken", "plain": "sk_plain"},
+		Tenants: map[string]APIKeyTenant{
+			"svc":    {TenantID: tenantID, IsAdmin: true, Roles: []string{"view_pii"}},
+			"broken": {TenantID: "not-a-ulid"},
+		},
+	}, logger.NewNop())
+
+	app := fiber.New()
+	app.Use(auth.Authenticate())
+	app.Get("/", func(c fiber.Ctx) error {
+		tc, ok := repository.TenantFromContext(c.Context())
+		if !ok {
+			return c.SendString("none")
+		}
+		return c.SendString(tc.TenantID.String() + "," + strings.Join(tc.Roles, ","))
+	})
+
+	for key, want := range map[string]string{
+		"sk_svc":    tenantID + ",view_pii",
+		"sk_broken": "none",
+		"sk_plain":  "none",
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", key)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if string(body) != want {
+			t.Fatalf("%s: expected %q, got %q", key, want, body)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package apikeystore
+
+import (
+	"strings"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/middleware"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * API Key Model - API Key 持久化模型
+ * ========================================================================
+ * 职责: 存储 API Key 散列、scope、绑定租户、过期/吊销时间与最近使用时间
+ * 安全: 仅保存 SHA256 散列与明文前缀（便于识别），原始 Key 只在创建时返回一次
+ * ======================================================================== */
+
+// APIKey API Key 记录
+type APIKey struct {
+	ID         string     `gorm:"column:id;type:char(26);primaryKey" json:"id"` // 即 key_id
+	Name       string     `gorm:"column:name;size:128" json:"name"`
+	Prefix     string     `gorm:"column:prefix;size:16" json:"prefix"`             // 明文前缀，如 sk_AbCd
+	KeyHash    string     `gorm:"column:key_hash;size:64;uniqueIndex" json:"-"`    // SHA256 十六进制
+	Scopes     string     `gorm:"column:scopes;size:1024" json:"scopes"`           // 逗号分隔
+	TenantID   string     `gorm:"column:tenant_id;size:26;index" json:"tenant_id"` // 绑定租户，空表示不绑定
+	DeptID     string     `gorm:"column:dept_id;size:26" json:"dept_id"`
+	IsAdmin    bool       `gorm:"column:is_admin" json:"is_admin"`
+	Roles      string     `gorm:"column:roles;size:1024" json:"roles"` // 逗号分隔，写入 TenantContext.Roles
+	ExpiresAt  *time.Time `gorm:"column:expires_at" json:"expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at" json:"last_used_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at" json:"revoked_at"`
+	CreatedAt  time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 表名
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+// TenantIgnored API Key 在认证阶段（租户未知）查询，不做租户隔离
+func (APIKey) TenantIgnored() bool {
+	return true
+}
+
+// ScopeList 返回 scope 列表
+func (k *APIKey) ScopeList() []string {
+	return splitList(k.Scopes)
+}
+
+// TenantContext 返回绑定的租户上下文，未绑定租户时返回 nil
+// UserID 取 Key ID，便于审计追踪到具体 Key
+func (k *APIKey) TenantContext() (*repository.TenantContext, error) {
+	if k.TenantID == "" {
+		return nil, nil
+	}
+	tc, err := middleware.APIKeyTenant{
+		TenantID: k.TenantID,
+		DeptID:   k.DeptID,
+		IsAdmin:  k.IsAdmin,
+		Roles:    splitList(k.Roles),
+	}.TenantContext()
+	if err != nil {
+		return nil, err
+	}
+	if id, err := ulid.ParseLoose(k.ID); err == nil {
+		tc.UserID = id
+	}
+	return &tc, nil
+}
+
+// splitList 拆分逗号分隔列表
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// Active 在 now 时刻是否可用（未吊销且未过期）
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// Migrate 创建/更新 api_keys 表
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&APIKey{})
+}
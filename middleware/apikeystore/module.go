@@ -0,0 +1,44 @@
+package apikeystore
+
+import (
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * API Key Store FX Module
+ * ========================================================================
+ * 职责: 提供 *Store，并注册为 middleware.KeyResolver，
+ *       与 middleware.Module 一起引入时 APIKeyAuth 自动改用数据库后端
+ * 说明: 表结构需通过 Migrate 或迁移脚本创建
+ * ======================================================================== */
+
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	DB     *gorm.DB
+	Config *Config        `optional:"true"`
+	Logger *logger.Logger `optional:"true"`
+}
+
+// NewFromParams 从 FX 参数创建存储
+func NewFromParams(p Params) *Store {
+	var cfg Config
+	if p.Config != nil {
+		cfg = *p.Config
+	}
+	return New(repository.NewRepository[APIKey](p.DB), cfg, p.Logger)
+}
+
+// Module FX 模块
+var Module = fx.Module("apikeystore",
+	fx.Provide(
+		NewFromParams,
+		func(s *Store) middleware.KeyResolver { return s },
+	),
+)
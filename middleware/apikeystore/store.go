@@ -0,0 +1,316 @@
+package apikeystore
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * API Key Store - 数据库 API Key 存储
+ * ========================================================================
+ * 职责: 创建/轮换/吊销 API Key，并作为 middleware.KeyResolver 为认证中间件解析 Key
+ * 特性:
+ *   - 按 SHA256 散列查询，原始 Key 只在 Create / Rotate 时返回一次
+ *   - 进程内缓存解析结果（CacheTTL），吊销在本实例立即生效，其他实例最多延迟一个 TTL
+ *   - last_used_at 按 TouchInterval 节流更新，避免每次请求写库
+ *   - Rotate 生成新 Key，旧 Key 在宽限期后过期，平滑切换
+ *   - Key 可绑定租户，认证后注入 TenantContext（UserID 为 Key ID）
+ *
+ * 使用示例:
+ *   store := apikeystore.New(repository.NewRepository[apikeystore.APIKey](db), apikeystore.Config{}, log)
+ *   raw, key, _ := store.Create(ctx, apikeystore.CreateInput{Name: "partner-a", Scopes: []string{"orders:read"}})
+ *   auth := middleware.NewAPIKeyAuthWithResolver(&middleware.APIKeyConfig{Enabled: true}, store, log)
+ *
+ *   // Fx: 引入 apikeystore.Module 后 middleware.Module 自动使用数据库后端
+ * ======================================================================== */
+
+const (
+	// DefaultCacheTTL 默认解析结果缓存时间
+	DefaultCacheTTL = time.Minute
+	// DefaultTouchInterval 默认 last_used_at 更新间隔
+	DefaultTouchInterval = time.Minute
+	// DefaultKeyPrefix 默认 Key 前缀
+	DefaultKeyPrefix = "sk_"
+)
+
+// Config 存储配置
+type Config struct {
+	CacheTTL      time.Duration `yaml:"cache_ttl"`      // 解析结果缓存时间，默认 1m，<0 关闭缓存
+	TouchInterval time.Duration `yaml:"touch_interval"` // last_used_at 最小更新间隔，默认 1m，<0 不记录
+	KeyPrefix     string        `yaml:"key_prefix"`     // 生成 Key 的前缀，默认 sk_
+}
+
+func (c Config) withDefaults() Config {
+	if c.CacheTTL == 0 {
+		c.CacheTTL = DefaultCacheTTL
+	}
+	if c.TouchInterval == 0 {
+		c.TouchInterval = DefaultTouchInterval
+	}
+	if c.KeyPrefix == "" {
+		c.KeyPrefix = DefaultKeyPrefix
+	}
+	return c
+}
+
+// CreateInput 创建参数
+type CreateInput struct {
+	Name      string
+	Scopes    []string
+	ExpiresAt *time.Time // nil 表示永不过期
+
+	// Tenant 绑定的租户（使用 TenantID/DeptID/IsAdmin/Roles），nil 表示不绑定
+	Tenant *repository.TenantContext
+}
+
+// cacheEntry 缓存项
+type cacheEntry struct {
+	info     *middleware.APIKeyInfo
+	cachedAt time.Time
+}
+
+// Store 数据库 API Key 存储
+type Store struct {
+	repo repository.Repository[APIKey]
+	cfg  Config
+	log  *logger.Logger
+	now  func() time.Time
+
+	mu      sync.Mutex
+	cache   map[string]cacheEntry // key_hash -> 解析结果
+	touched map[string]time.Time  // key_id -> 最近一次写入 last_used_at 的时间
+}
+
+var _ middleware.KeyResolver = (*Store)(nil)
+
+// New 创建存储
+func New(repo repository.Repository[APIKey], cfg Config, log *logger.Logger) *Store {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &Store{
+		repo:    repo,
+		cfg:     cfg.withDefaults(),
+		log:     log,
+		now:     time.Now,
+		cache:   make(map[string]cacheEntry),
+		touched: make(map[string]time.Time),
+	}
+}
+
+// Create 创建 API Key，返回原始 Key（仅此一次）与记录
+func (s *Store) Create(ctx context.Context, in CreateInput) (string, *APIKey, error) {
+	raw, err := s.generate()
+	if err != nil {
+		return "", nil, err
+	}
+	key := &APIKey{
+		ID:        ulid.GenerateString(),
+		Name:      in.Name,
+		Prefix:    raw[:min(len(raw), len(s.cfg.KeyPrefix)+4)],
+		KeyHash:   hashKey(raw),
+		Scopes:    joinList(in.Scopes),
+		ExpiresAt: in.ExpiresAt,
+	}
+	if tc := in.Tenant; tc != nil {
+		if ulid.IsZero(tc.TenantID) {
+			return "", nil, errors.New(errors.ErrCodeInvalidArgument, "tenant_id is required when binding a tenant")
+		}
+		key.TenantID = tc.TenantID.String()
+		if tc.DeptID != nil {
+			key.DeptID = tc.DeptID.String()
+		}
+		key.IsAdmin = tc.IsAdmin
+		key.Roles = joinList(tc.Roles)
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return raw, key, nil
+}
+
+// Rotate 为已有 Key 生成替代 Key（沿用名称、scope 与过期时间），旧 Key 在 grace 后过期
+// grace <= 0 时旧 Key 立即吊销
+func (s *Store) Rotate(ctx context.Context, id string, grace time.Duration) (string, *APIKey, error) {
+	old, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	if old.RevokedAt != nil {
+		return "", nil, errors.New(errors.ErrCodeInvalidArgument, "api key already revoked")
+	}
+
+	tenant, err := old.TenantContext()
+	if err != nil {
+		return "", nil, err
+	}
+
+	var (
+		raw string
+		key *APIKey
+	)
+	err = s.repo.Execute(ctx, func(ctx context.Context) error {
+		var err error
+		raw, key, err = s.Create(ctx, CreateInput{Name: old.Name, Scopes: old.ScopeList(), ExpiresAt: old.ExpiresAt, Tenant: tenant})
+		if err != nil {
+			return err
+		}
+		now := s.now()
+		if grace <= 0 {
+			return s.repo.UpdateByID(ctx, id, map[string]any{"revoked_at": now})
+		}
+		expiresAt := now.Add(grace)
+		if old.ExpiresAt != nil && old.ExpiresAt.Before(expiresAt) {
+			return nil
+		}
+		return s.repo.UpdateByID(ctx, id, map[string]any{"expires_at": expiresAt})
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	s.invalidate(old.KeyHash)
+	return raw, key, nil
+}
+
+// Revoke 吊销 API Key
+func (s *Store) Revoke(ctx context.Context, id string) error {
+	key, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if key.RevokedAt != nil {
+		return nil
+	}
+	if err := s.repo.UpdateByID(ctx, id, map[string]any{"revoked_at": s.now()}); err != nil {
+		return err
+	}
+	s.invalidate(key.KeyHash)
+	return nil
+}
+
+// List 按创建时间倒序列出全部 Key（不含散列）
+func (s *Store) List(ctx context.Context) ([]*APIKey, error) {
+	return s.repo.FindByQueryWithOpts(ctx, "1 = 1", []repository.Option{repository.WithOrderBy("created_at DESC")})
+}
+
+// ResolveAPIKey 实现 middleware.KeyResolver
+func (s *Store) ResolveAPIKey(ctx context.Context, apiKey string) (*middleware.APIKeyInfo, error) {
+	hash := hashKey(apiKey)
+	now := s.now()
+
+	if info, ok := s.cached(hash, now); ok {
+		s.touch(ctx, info.KeyID, now)
+		return info, nil
+	}
+
+	key, err := s.repo.FindOne(ctx, "key_hash = ?", hash)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, middleware.ErrAPIKeyInvalid
+		}
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, middleware.ErrAPIKeyInvalid
+	}
+	if !key.Active(now) {
+		return nil, middleware.ErrAPIKeyExpired
+	}
+
+	tenant, err := key.TenantContext()
+	if err != nil {
+		return nil, err
+	}
+	info := &middleware.APIKeyInfo{KeyID: key.ID, Scopes: key.ScopeList(), ExpiresAt: key.ExpiresAt, Tenant: tenant}
+	if s.cfg.CacheTTL > 0 {
+		s.mu.Lock()
+		s.cache[hash] = cacheEntry{info: info, cachedAt: now}
+		s.mu.Unlock()
+	}
+	s.touch(ctx, key.ID, now)
+	return info, nil
+}
+
+// cached 读取未过期的缓存项
+func (s *Store) cached(hash string, now time.Time) (*middleware.APIKeyInfo, bool) {
+	if s.cfg.CacheTTL <= 0 {
+		return nil, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.cache[hash]
+	if !ok {
+		return nil, false
+	}
+	if now.Sub(entry.cachedAt) >= s.cfg.CacheTTL {
+		delete(s.cache, hash)
+		return nil, false
+	}
+	return entry.info, true
+}
+
+// invalidate 清除缓存项
+func (s *Store) invalidate(hash string) {
+	s.mu.Lock()
+	delete(s.cache, hash)
+	s.mu.Unlock()
+}
+
+// touch 节流更新 last_used_at，失败仅记录日志
+func (s *Store) touch(ctx context.Context, id string, now time.Time) {
+	if s.cfg.TouchInterval < 0 {
+		return
+	}
+	s.mu.Lock()
+	last, ok := s.touched[id]
+	if ok && now.Sub(last) < s.cfg.TouchInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.touched[id] = now
+	s.mu.Unlock()
+
+	if err := s.repo.UpdateByID(ctx, id, map[string]any{"last_used_at": now}); err != nil {
+		s.log.Warn("failed to update api key last_used_at", zap.String("key_id", id), zap.Error(err))
+	}
+}
+
+// generate 生成原始 Key: 前缀 + 32 字节随机数（base64url）
+func (s *Store) generate() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(errors.ErrCodeInternal, "generate api key", err)
+	}
+	return s.cfg.KeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashKey 计算 Key 的 SHA256 十六进制散列
+func hashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// joinList 规范化并拼接 scope / 角色列表
+func joinList(items []string) string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return strings.Join(out, ",")
+}
@@ -0,0 +1,146 @@
+package apikeystore
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+
+	"github.com/gofiber/fiber/v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newTestStore(t *testing.T, cfg Config) (*Store, repository.Repository[APIKey]) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := repository.NewRepository[APIKey](db)
+	return New(repo, cfg, logger.NewNop()), repo
+}
+
+func TestStoreResolve(t *testing.T) {
+	store, repo := newTestStore(t, Config{})
+	ctx := context.Background()
+
+	raw, key, err := store.Create(ctx, CreateInput{Name: "partner", Scopes: []string{"orders:read", " ", "orders:write"}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !strings.HasPrefix(raw, DefaultKeyPrefix) || !strings.HasPrefix(raw, key.Prefix) || key.KeyHash == raw {
+		t.Fatalf("unexpected key: raw=%q prefix=%q", raw, key.Prefix)
+	}
+
+	info, err := store.ResolveAPIKey(ctx, raw)
+	if err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	if info.KeyID != key.ID || !info.HasScope("orders:write") || info.HasScope("users:read") {
+		t.Fatalf("unexpected info: %+v", info)
+	}
+	stored, _ := repo.FindByID(ctx, key.ID)
+	if stored.LastUsedAt == nil {
+		t.Fatalf("expected last_used_at to be recorded")
+	}
+
+	if _, err := store.ResolveAPIKey(ctx, "sk_unknown"); !stderrors.Is(err, middleware.ErrAPIKeyInvalid) {
+		t.Fatalf("expected invalid key, got %v", err)
+	}
+
+	if err := store.Revoke(ctx, key.ID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if _, err := store.ResolveAPIKey(ctx, raw); !stderrors.Is(err, middleware.ErrAPIKeyInvalid) {
+		t.Fatalf("expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestStoreExpiryAndRotate(t *testing.T) {
+	store, _ := newTestStore(t, Config{CacheTTL: -1})
+	ctx := context.Background()
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	past := now.Add(-time.Minute)
+	expired, _, _ := store.Create(ctx, CreateInput{Name: "old", ExpiresAt: &past})
+	if _, err := store.ResolveAPIKey(ctx, expired); !stderrors.Is(err, middleware.ErrAPIKeyExpired) {
+		t.Fatalf("expected expired key, got %v", err)
+	}
+
+	tenantID := ulid.Generate()
+	oldRaw, oldKey, _ := store.Create(ctx, CreateInput{Name: "svc", Scopes: []string{"*"},
+		Tenant: &repository.TenantContext{TenantID: tenantID, Roles: []string{"billing"}}})
+	newRaw, newKey, err := store.Rotate(ctx, oldKey.ID, time.Hour)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if newKey.ID == oldKey.ID || newKey.Scopes != "*" || newKey.TenantID != tenantID.String() || newKey.Roles != "billing" || newRaw == oldRaw {
+		t.Fatalf("unexpected rotated key: %+v", newKey)
+	}
+	info, err := store.ResolveAPIKey(ctx, newRaw)
+	if err != nil || info.Tenant == nil || info.Tenant.TenantID != tenantID || info.Tenant.UserID.String() != newKey.ID {
+		t.Fatalf("expected tenant bound to rotated key, got %+v %v", info, err)
+	}
+
+	// 宽限期内新旧 Key 均可用
+	for _, raw := range []string{oldRaw, newRaw} {
+		if _, err := store.ResolveAPIKey(ctx, raw); err != nil {
+			t.Fatalf("expected key usable during grace period: %v", err)
+		}
+	}
+	now = now.Add(2 * time.Hour)
+	if _, err := store.ResolveAPIKey(ctx, oldRaw); !stderrors.Is(err, middleware.ErrAPIKeyExpired) {
+		t.Fatalf("expected old key to expire after grace period, got %v", err)
+	}
+	if _, err := store.ResolveAPIKey(ctx, newRaw); err != nil {
+		t.Fatalf("expected new key to stay valid: %v", err)
+	}
+
+	keys, err := store.List(ctx)
+	if err != nil || len(keys) != 3 {
+		t.Fatalf("expected 3 keys, got %d %v", len(keys), err)
+	}
+}
+
+func TestAPIKeyAuthWithStore(t *testing.T) {
+	store, _ := newTestStore(t, Config{})
+	raw, _, _ := store.Create(context.Background(), CreateInput{Name: "reader", Scopes: []string{"orders:read"}})
+
+	auth := middleware.NewAPIKeyAuthWithResolver(&middleware.APIKeyConfig{Enabled: true}, store, logger.NewNop())
+	app := fiber.New()
+	app.Use(auth.Authenticate())
+	app.Get("/orders", middleware.RequireScopes("orders:read"), func(c fiber.Ctx) error { return c.SendString("ok") })
+	app.Post("/orders", middleware.RequireScopes("orders:write"), func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	for _, tc := range []struct {
+		method, key string
+		want        int
+	}{
+		{"GET", raw, fiber.StatusOK},
+		{"POST", raw, fiber.StatusForbidden},
+		{"GET", "sk_bad", fiber.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest(tc.method, "/orders", nil)
+		req.Header.Set("X-API-Key", tc.key)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Fatalf("%s with %q: expected %d, got %d", tc.method, tc.key, tc.want, resp.StatusCode)
+		}
+	}
+}
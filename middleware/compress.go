@@ -0,0 +1,298 @@
+package middleware
+
+import (
+	"bytes"
+	stderrors "errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+/* ========================================================================
+ * Compression Middleware - 响应压缩与请求体解压
+ * ========================================================================
+ * 职责:
+ *   - Compress:   按 Accept-Encoding 协商 zstd / br / gzip 压缩响应体
+ *                 （服务端偏好顺序由 encodings 决定，客户端 q=0 的编码不会使用）
+ *   - Decompress: 按请求 Content-Encoding 解压请求体（gzip / deflate / br / zstd），
+ *                 解压后超过上限返回 413，不支持的编码返回 415，数据损坏返回 400
+ * 跳过压缩:
+ *   - 响应体小于 min_size、流式响应、已设置 Content-Encoding
+ *   - Content-Type 不匹配 content_types 前缀
+ *   - HEAD、1xx/204/206/304、Range 请求、Cache-Control: no-transform
+ * 压缩后强 ETag 降级为弱 ETag（W/），并追加 Vary: Accept-Encoding
+ * 注意: 全局配置通过 transport/http Config 的 compression 挂载，解压上限沿用 max_body_size
+ *
+ * 使用示例:
+ *   // compression:
+ *   //   enabled: true
+ *   //   encodings: ["zstd", "br", "gzip"]
+ *   //   level: best_speed
+ *   //   min_size: 1024
+ *   //   decompress: true
+ *   app.Use(middleware.Decompress(4<<20), middleware.Compress(cfg))
+ * ======================================================================== */
+
+const (
+	// defaultCompressMinSize 默认最小压缩字节数，更小的响应压缩收益不足以抵消开销
+	defaultCompressMinSize = 1024
+)
+
+// 压缩级别
+const (
+	CompressLevelDefault         = "default"
+	CompressLevelBestSpeed       = "best_speed"
+	CompressLevelBestCompression = "best_compression"
+)
+
+// 支持的编码
+const (
+	EncodingZstd    = "zstd"
+	EncodingBrotli  = "br"
+	EncodingGzip    = "gzip"
+	EncodingDeflate = "deflate"
+)
+
+// defaultCompressEncodings 默认服务端偏好顺序
+var defaultCompressEncodings = []string{EncodingZstd, EncodingBrotli, EncodingGzip}
+
+// defaultCompressContentTypes 默认可压缩的 Content-Type 前缀
+var defaultCompressContentTypes = []string{
+	"text/",
+	"application/json",
+	"application/problem+json",
+	"application/javascript",
+	"application/xml",
+	"application/x-ndjson",
+	"image/svg+xml",
+}
+
+// ErrUnsupportedEncoding 请求体编码不受支持
+var ErrUnsupportedEncoding = errors.New(errors.ErrCodeInvalidArgument, "unsupported content encoding")
+
+// ErrInvalidEncodedBody 请求体解压失败
+var ErrInvalidEncodedBody = errors.New(errors.ErrCodeInvalidArgument, "invalid encoded request body")
+
+// CompressionConfig 压缩配置
+type CompressionConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Encodings    []string `yaml:"encodings"`     // 服务端偏好顺序，默认 [zstd, br, gzip]
+	Level        string   `yaml:"level"`         // default / best_speed / best_compression，默认 default
+	MinSize      int      `yaml:"min_size"`      // 最小压缩字节数，默认 1024
+	ContentTypes []string `yaml:"content_types"` // 可压缩的 Content-Type 前缀，默认文本、JSON、XML、JS、SVG
+	Decompress   bool     `yaml:"decompress"`    // 是否解压带 Content-Encoding 的请求体
+}
+
+// compressor 单个编码的压缩函数
+type compressor func(dst, src []byte) []byte
+
+// newCompressor 按编码与级别返回压缩函数，不支持的编码返回 nil
+func newCompressor(encoding, level string) compressor {
+	levels := map[string][3]int{ // gzip, br, zstd
+		CompressLevelDefault:         {fasthttp.CompressDefaultCompression, fasthttp.CompressBrotliDefaultCompression, fasthttp.CompressZstdDefault},
+		CompressLevelBestSpeed:       {fasthttp.CompressBestSpeed, fasthttp.CompressBrotliBestSpeed, fasthttp.CompressZstdBestSpeed},
+		CompressLevelBestCompression: {fasthttp.CompressBestCompression, fasthttp.CompressBrotliBestCompression, fasthttp.CompressZstdBestCompression},
+	}
+	l, ok := levels[level]
+	if !ok {
+		l = levels[CompressLevelDefault]
+	}
+	switch encoding {
+	case EncodingGzip:
+		return func(dst, src []byte) []byte { return fasthttp.AppendGzipBytesLevel(dst, src, l[0]) }
+	case EncodingBrotli:
+		return func(dst, src []byte) []byte { return fasthttp.AppendBrotliBytesLevel(dst, src, l[1]) }
+	case EncodingZstd:
+		return func(dst, src []byte) []byte { return fasthttp.AppendZstdBytesLevel(dst, src, l[2]) }
+	default:
+		return nil
+	}
+}
+
+// Compress 创建响应压缩中间件
+func Compress(cfg CompressionConfig) fiber.Handler {
+	encodings := cfg.Encodings
+	if len(encodings) == 0 {
+		encodings = defaultCompressEncodings
+	}
+	compressors := make(map[string]compressor, len(encodings))
+	preferred := make([]string, 0, len(encodings))
+	for _, enc := range encodings {
+		enc = strings.ToLower(strings.TrimSpace(enc))
+		if fn := newCompressor(enc, cfg.Level); fn != nil && compressors[enc] == nil {
+			compressors[enc] = fn
+			preferred = append(preferred, enc)
+		}
+	}
+	minSize := cfg.MinSize
+	if minSize <= 0 {
+		minSize = defaultCompressMinSize
+	}
+	contentTypes := cfg.ContentTypes
+	if len(contentTypes) == 0 {
+		contentTypes = defaultCompressContentTypes
+	}
+
+	return func(c fiber.Ctx) error {
+		if err := c.Next(); err != nil {
+			return err
+		}
+
+		resp := c.Response()
+		if !compressible(c, minSize, contentTypes) {
+			return nil
+		}
+		appendVary(c, fiber.HeaderAcceptEncoding)
+
+		enc := negotiateEncoding(c.Get(fiber.HeaderAcceptEncoding), preferred)
+		if enc == "" {
+			return nil
+		}
+		body := resp.Body()
+		compressed := compressors[enc](nil, body)
+		if len(compressed) >= len(body) {
+			return nil
+		}
+
+		resp.SetBodyRaw(compressed)
+		resp.Header.Set(fiber.HeaderContentEncoding, enc)
+		if tag := c.GetRespHeader(fiber.HeaderETag); tag != "" && !strings.HasPrefix(tag, "W/") {
+			c.Set(fiber.HeaderETag, "W/"+tag)
+		}
+		return nil
+	}
+}
+
+// compressible 响应是否适合压缩
+func compressible(c fiber.Ctx, minSize int, contentTypes []string) bool {
+	resp := c.Response()
+	status := resp.StatusCode()
+	switch {
+	case c.Method() == fiber.MethodHead,
+		status < 200, status == fiber.StatusNoContent,
+		status == fiber.StatusPartialContent, status == fiber.StatusNotModified,
+		resp.IsBodyStream(),
+		len(resp.Body()) < minSize,
+		len(resp.Header.ContentEncoding()) > 0,
+		c.Get(fiber.HeaderRange) != "",
+		hasHeaderToken(c.Get(fiber.HeaderCacheControl), "no-transform"),
+		hasHeaderToken(c.GetRespHeader(fiber.HeaderCacheControl), "no-transform"):
+		return false
+	}
+
+	contentType := strings.ToLower(string(resp.Header.ContentType()))
+	for _, prefix := range contentTypes {
+		if strings.HasPrefix(contentType, strings.ToLower(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// negotiateEncoding 按服务端偏好顺序选择客户端接受（q > 0）的编码，无可用编码时返回空
+func negotiateEncoding(accept string, preferred []string) string {
+	if accept == "" {
+		return ""
+	}
+	qualities := make(map[string]float64)
+	for part := range strings.SplitSeq(accept, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		qualities[name] = q
+	}
+	for _, enc := range preferred {
+		q, ok := qualities[enc]
+		if !ok {
+			q, ok = qualities["*"]
+		}
+		if ok && q > 0 {
+			return enc
+		}
+	}
+	return ""
+}
+
+// hasHeaderToken 逗号分隔的头中是否包含 token（忽略大小写）
+func hasHeaderToken(header, token string) bool {
+	for part := range strings.SplitSeq(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// appendVary 向 Vary 响应头追加字段（已存在或为 * 时不重复追加）
+func appendVary(c fiber.Ctx, field string) {
+	vary := c.GetRespHeader(fiber.HeaderVary)
+	switch {
+	case vary == "":
+		c.Set(fiber.HeaderVary, field)
+	case hasHeaderToken(vary, "*"), hasHeaderToken(vary, field):
+	default:
+		c.Set(fiber.HeaderVary, vary+", "+field)
+	}
+}
+
+// errDecompressLimit 解压后超过上限
+var errDecompressLimit = stderrors.New("decompressed body exceeds limit")
+
+// limitedBuffer 超过上限时返回错误的缓冲区，避免压缩炸弹耗尽内存
+type limitedBuffer struct {
+	bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && b.Len()+len(p) > b.limit {
+		return 0, errDecompressLimit
+	}
+	return b.Buffer.Write(p)
+}
+
+// Decompress 创建请求体解压中间件，maxSize 为解压后的最大字节数（<= 0 表示不限制）
+func Decompress(maxSize int) fiber.Handler {
+	decoders := map[string]func(io.Writer, []byte) (int, error){
+		EncodingGzip:    fasthttp.WriteGunzip,
+		EncodingDeflate: fasthttp.WriteInflate,
+		EncodingBrotli:  fasthttp.WriteUnbrotli,
+		EncodingZstd:    fasthttp.WriteUnzstd,
+	}
+
+	return func(c fiber.Ctx) error {
+		req := c.Request()
+		enc := strings.ToLower(strings.TrimSpace(string(req.Header.ContentEncoding())))
+		if enc == "" || enc == "identity" {
+			return c.Next()
+		}
+		decode, ok := decoders[enc]
+		if !ok {
+			return response.ErrorWithCode(c, fiber.StatusUnsupportedMediaType, ErrUnsupportedEncoding)
+		}
+
+		buf := &limitedBuffer{limit: maxSize}
+		if _, err := decode(buf, req.Body()); err != nil {
+			if stderrors.Is(err, errDecompressLimit) {
+				return response.ErrorWithCode(c, fiber.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+			}
+			return response.ErrorWithCode(c, fiber.StatusBadRequest, ErrInvalidEncodedBody)
+		}
+
+		req.SetBodyRaw(buf.Bytes())
+		req.Header.Del(fiber.HeaderContentEncoding)
+		req.Header.SetContentLength(buf.Len())
+		return c.Next()
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCompress(t *testing.T) {
+	large := strings.Repeat(`{"name":"ais","value":42},`, 100)
+	app := fiber.New()
+	app.Use(Compress(CompressionConfig{Enabled: true, MinSize: 512}))
+	app.Get("/json", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderETag, `"v1"`)
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.SendString(large)
+	})
+	app.Get("/small", func(c fiber.Ctx) error { return c.SendString("tiny") })
+	app.Get("/png", func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderContentType, "image/png")
+		return c.SendString(large)
+	})
+
+	cases := []struct {
+		path, accept string
+		wantEncoding string
+	}{
+		{"/json", "gzip, deflate, br, zstd", EncodingZstd}, // 服务端偏好
+		{"/json", "gzip, br", EncodingBrotli},
+		{"/json", "gzip", EncodingGzip},
+		{"/json", "zstd;q=0, br;q=0, *", EncodingGzip}, // q=0 排除
+		{"/json", "identity", ""},
+		{"/json", "", ""},
+		{"/small", "gzip", ""}, // 小于 min_size
+		{"/png", "gzip", ""},   // Content-Type 不匹配
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.accept != "" {
+			req.Header.Set(fiber.HeaderAcceptEncoding, tc.accept)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if got := resp.Header.Get(fiber.HeaderContentEncoding); got != tc.wantEncoding {
+			t.Fatalf("%s with %q: expected encoding %q, got %q", tc.path, tc.accept, tc.wantEncoding, got)
+		}
+		if tc.wantEncoding == "" {
+			continue
+		}
+		if resp.Header.Get(fiber.HeaderVary) != fiber.HeaderAcceptEncoding {
+			t.Fatalf("missing Vary header: %q", resp.Header.Get(fiber.HeaderVary))
+		}
+		if resp.Header.Get(fiber.HeaderETag) != `W/"v1"` {
+			t.Fatalf("expected weak etag, got %q", resp.Header.Get(fiber.HeaderETag))
+		}
+		var decoded bytes.Buffer
+		switch tc.wantEncoding {
+		case EncodingZstd:
+			_, err = fasthttp.WriteUnzstd(&decoded, body)
+		case EncodingBrotli:
+			_, err = fasthttp.WriteUnbrotli(&decoded, body)
+		case EncodingGzip:
+			_, err = fasthttp.WriteGunzip(&decoded, body)
+		}
+		if err != nil || decoded.String() != large {
+			t.Fatalf("%s: decode %s failed: %v", tc.path, tc.wantEncoding, err)
+		}
+	}
+}
+
+func TestDecompress(t *testing.T) {
+	payload := strings.Repeat("a", 2048)
+	app := fiber.New()
+	app.Use(Decompress(4096))
+	app.Post("/echo", func(c fiber.Ctx) error {
+		if c.Get(fiber.HeaderContentEncoding) != "" {
+			return fiber.ErrInternalServerError
+		}
+		return c.Send(c.Body())
+	})
+
+	cases := []struct {
+		name     string
+		encoding string
+		body     []byte
+		want     int
+	}{
+		{"plain", "", []byte(payload), fiber.StatusOK},
+		{"gzip", EncodingGzip, fasthttp.AppendGzipBytes(nil, []byte(payload)), fiber.StatusOK},
+		{"deflate", EncodingDeflate, fasthttp.AppendDeflateBytes(nil, []byte(payload)), fiber.StatusOK},
+		{"br", EncodingBrotli, fasthttp.AppendBrotliBytes(nil, []byte(payload)), fiber.StatusOK},
+		{"zstd", EncodingZstd, fasthttp.AppendZstdBytes(nil, []byte(payload)), fiber.StatusOK},
+		{"too large", EncodingGzip, fasthttp.AppendGzipBytes(nil, []byte(strings.Repeat("a", 8192))), fiber.StatusRequestEntityTooLarge},
+		{"corrupted", EncodingGzip, []byte("not gzip"), fiber.StatusBadRequest},
+		{"unsupported", "compress", []byte(payload), fiber.StatusUnsupportedMediaType},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("POST", "/echo", bytes.NewReader(tc.body))
+		if tc.encoding != "" {
+			req.Header.Set(fiber.HeaderContentEncoding, tc.encoding)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("%s: request: %v", tc.name, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Fatalf("%s: expected %d, got %d (%s)", tc.name, tc.want, resp.StatusCode, body)
+		}
+		if tc.want == fiber.StatusOK && string(body) != payload {
+			t.Fatalf("%s: unexpected body length %d", tc.name, len(body))
+		}
+	}
+}
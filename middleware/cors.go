@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * CORS Middleware - 跨域资源共享
+ * ========================================================================
+ * 职责: 处理跨域预检与响应头
+ * 特性:
+ *   - Origin 支持精确匹配、通配子域名（https://*.example.com）与 "*"
+ *   - 允许携带凭证时回显请求 Origin；"*" 不与凭证同时生效，
+ *     允许所有 Origin 的策略（含继承全局 allow_credentials 的路由）始终不发送凭证头
+ *   - 按路由前缀覆盖配置（Routes，最长前缀优先），也可在路由组上单独挂载
+ *
+ * 使用示例:
+ *   // YAML
+ *   // cors:
+ *   //   enabled: true
+ *   //   allow_origins: ["https://*.example.com"]
+ *   //   allow_credentials: true
+ *   //   routes:
+ *   //     - prefix: /open
+ *   //       allow_origins: ["*"]
+ *   app.Use(middleware.CORS(cfg))
+ *
+ *   // 路由组单独配置
+ *   api := app.Group("/partner", middleware.CORS(middleware.CORSConfig{
+ *       AllowOrigins: []string{"https://partner.com"},
+ *   }))
+ * ======================================================================== */
+
+// CORSConfig CORS 配置
+type CORSConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	AllowOrigins     []string      `yaml:"allow_origins"`     // 允许的 Origin，默认 "*"
+	AllowMethods     []string      `yaml:"allow_methods"`     // 允许的方法，默认常用方法
+	AllowHeaders     []string      `yaml:"allow_headers"`     // 允许的请求头，为空时回显预检请求头
+	ExposeHeaders    []string      `yaml:"expose_headers"`    // 暴露给浏览器的响应头
+	AllowCredentials bool          `yaml:"allow_credentials"` // 是否允许携带凭证（allow_origins 含 "*" 时无效）
+	MaxAge           time.Duration `yaml:"max_age"`           // 预检结果缓存时间，0 表示不设置
+
+	// Routes 按路由前缀覆盖配置，未设置的字段继承全局配置
+	Routes []CORSRouteConfig `yaml:"routes"`
+}
+
+// CORSRouteConfig 路由级 CORS 覆盖配置
+type CORSRouteConfig struct {
+	Prefix           string        `yaml:"prefix"`
+	AllowOrigins     []string      `yaml:"allow_origins"`
+	AllowMethods     []string      `yaml:"allow_methods"`
+	AllowHeaders     []string      `yaml:"allow_headers"`
+	ExposeHeaders    []string      `yaml:"expose_headers"`
+	AllowCredentials *bool         `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+// defaultCORSMethods 默认允许的方法
+var defaultCORSMethods = []string{
+	fiber.MethodGet, fiber.MethodPost, fiber.MethodPut,
+	fiber.MethodPatch, fiber.MethodDelete, fiber.MethodHead,
+}
+
+// corsPolicy 预处理后的 CORS 策略
+type corsPolicy struct {
+	allowAll         bool
+	exact            map[string]struct{}
+	wildcards        []originPattern
+	allowMethods     string
+	allowHeaders     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+// corsRoute 路由级策略
+type corsRoute struct {
+	prefix string
+	policy *corsPolicy
+}
+
+// originPattern 通配子域名模式，如 https://*.example.com 拆为 "https://" 与 ".example.com"
+type originPattern struct {
+	prefix string
+	suffix string
+}
+
+// CORS 创建 CORS 中间件
+func CORS(cfg CORSConfig) fiber.Handler {
+	global := newCORSPolicy(cfg)
+
+	routes := make([]corsRoute, 0, len(cfg.Routes))
+	for _, rc := range cfg.Routes {
+		routes = append(routes, corsRoute{prefix: rc.Prefix, policy: newCORSPolicy(rc.merge(cfg))})
+	}
+	// 最长前缀优先
+	slices.SortFunc(routes, func(a, b corsRoute) int {
+		return len(b.prefix) - len(a.prefix)
+	})
+
+	return func(c fiber.Ctx) error {
+		policy := global
+		path := c.Path()
+		for _, r := range routes {
+			if strings.HasPrefix(path, r.prefix) {
+				policy = r.policy
+				break
+			}
+		}
+		return policy.handle(c)
+	}
+}
+
+// merge 将路由覆盖合并到全局配置
+func (rc CORSRouteConfig) merge(base CORSConfig) CORSConfig {
+	merged := base
+	merged.Routes = nil
+	if rc.AllowOrigins != nil {
+		merged.AllowOrigins = rc.AllowOrigins
+	}
+	if rc.AllowMethods != nil {
+		merged.AllowMethods = rc.AllowMethods
+	}
+	if rc.AllowHeaders != nil {
+		merged.AllowHeaders = rc.AllowHeaders
+	}
+	if rc.ExposeHeaders != nil {
+		merged.ExposeHeaders = rc.ExposeHeaders
+	}
+	if rc.AllowCredentials != nil {
+		merged.AllowCredentials = *rc.AllowCredentials
+	}
+	if rc.MaxAge > 0 {
+		merged.MaxAge = rc.MaxAge
+	}
+	return merged
+}
+
+// newCORSPolicy 预处理配置
+func newCORSPolicy(cfg CORSConfig) *corsPolicy {
+	p := &corsPolicy{exact: make(map[string]struct{})}
+
+	origins := cfg.AllowOrigins
+	if len(origins) == 0 {
+		origins = []string{"*"}
+	}
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		switch {
+		case origin == "*":
+			p.allowAll = true
+		case strings.Contains(origin, "*."):
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			p.wildcards = append(p.wildcards, originPattern{prefix: prefix, suffix: suffix})
+		case origin != "":
+			p.exact[origin] = struct{}{}
+		}
+	}
+
+	// 允许任意 Origin 时携带凭证等同于向所有站点开放用户会话
+	p.allowCredentials = cfg.AllowCredentials && !p.allowAll
+
+	methods := cfg.AllowMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+	p.allowMethods = strings.ToUpper(strings.Join(methods, ","))
+	p.allowHeaders = strings.Join(cfg.AllowHeaders, ",")
+	p.exposeHeaders = strings.Join(cfg.ExposeHeaders, ",")
+	if cfg.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+	return p
+}
+
+// allowOrigin 判断 Origin 是否允许
+func (p *corsPolicy) allowOrigin(origin string) bool {
+	if p.allowAll {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	if _, ok := p.exact[origin]; ok {
+		return true
+	}
+	for _, w := range p.wildcards {
+		if len(origin) <= len(w.prefix)+len(w.suffix) {
+			continue
+		}
+		if !strings.HasPrefix(origin, w.prefix) || !strings.HasSuffix(origin, w.suffix) {
+			continue
+		}
+		// 通配部分只能是子域名标签
+		sub := origin[len(w.prefix) : len(origin)-len(w.suffix)]
+		if !strings.ContainsAny(sub, "/:@") {
+			return true
+		}
+	}
+	return false
+}
+
+// handle 处理请求
+func (p *corsPolicy) handle(c fiber.Ctx) error {
+	origin := c.Get(fiber.HeaderOrigin)
+	preflight := c.Method() == fiber.MethodOptions && c.Get(fiber.HeaderAccessControlRequestMethod) != ""
+
+	if origin == "" || !p.allowOrigin(origin) {
+		if preflight {
+			// 不允许的跨域预检不返回 CORS 头，由浏览器拦截
+			return c.SendStatus(fiber.StatusNoContent)
+		}
+		return c.Next()
+	}
+
+	if p.allowAll {
+		c.Set(fiber.HeaderAccessControlAllowOrigin, "*")
+	} else {
+		c.Set(fiber.HeaderAccessControlAllowOrigin, origin)
+		c.Vary(fiber.HeaderOrigin)
+	}
+	if p.allowCredentials {
+		c.Set(fiber.HeaderAccessControlAllowCredentials, "true")
+	}
+
+	if !preflight {
+		if p.exposeHeaders != "" {
+			c.Set(fiber.HeaderAccessControlExposeHeaders, p.exposeHeaders)
+		}
+		return c.Next()
+	}
+
+	c.Set(fiber.HeaderAccessControlAllowMethods, p.allowMethods)
+	if p.allowHeaders != "" {
+		c.Set(fiber.HeaderAccessControlAllowHeaders, p.allowHeaders)
+	} else if reqHeaders := c.Get(fiber.HeaderAccessControlRequestHeaders); reqHeaders != "" {
+		c.Set(fiber.HeaderAccessControlAllowHeaders, reqHeaders)
+		c.Vary(fiber.HeaderAccessControlRequestHeaders)
+	}
+	if p.maxAge != "" {
+		c.Set(fiber.HeaderAccessControlMaxAge, p.maxAge)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestCORS(t *testing.T) {
+	app := fiber.New()
+	app.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com", "https://*.example.org"},
+		AllowCredentials: true,
+		ExposeHeaders:    []string{"X-Request-ID"},
+		MaxAge:           10 * time.Minute,
+		Routes: []CORSRouteConfig{
+			{Prefix: "/open", AllowOrigins: []string{"*"}, AllowCredentials: new(bool)},
+		},
+	}))
+	app.Get("/api", func(c fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/open", func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	cases := []struct {
+		name        string
+		method      string
+		path        string
+		origin      string
+		wantOrigin  string
+		wantStatus  int
+		wantMaxAge  string
+		wantExposed string
+	}{
+		{"exact", "GET", "/api", "https://app.example.com", "https://app.example.com", fiber.StatusOK, "", "X-Request-ID"},
+		{"wildcard", "GET", "/api", "https://a.b.example.org", "https://a.b.example.org", fiber.StatusOK, "", "X-Request-ID"},
+		{"wildcard apex", "GET", "/api", "https://example.org", "", fiber.StatusOK, "", ""},
+		{"denied", "GET", "/api", "https://evil.com", "", fiber.StatusOK, "", ""},
+		{"preflight", "OPTIONS", "/api", "https://app.example.com", "https://app.example.com", fiber.StatusNoContent, "600", ""},
+		{"route override", "GET", "/open", "https://evil.com", "*", fiber.StatusOK, "", "X-Request-ID"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("Origin", tc.origin)
+		if tc.method == "OPTIONS" {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+		}
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", tc.name, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.wantStatus {
+			t.Fatalf("%s: unexpected status: %d", tc.name, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Access-Control-Allow-Origin"); got != tc.wantOrigin {
+			t.Fatalf("%s: unexpected allow origin: %q", tc.name, got)
+		}
+		if got := resp.Header.Get("Access-Control-Max-Age"); got != tc.wantMaxAge {
+			t.Fatalf("%s: unexpected max age: %q", tc.name, got)
+		}
+		if got := resp.Header.Get("Access-Control-Expose-Headers"); got != tc.wantExposed {
+			t.Fatalf("%s: unexpected expose headers: %q", tc.name, got)
+		}
+	}
+}
+
+func TestCORSWildcardNeverAllowsCredentials(t *testing.T) {
+	app := fiber.New()
+	app.Use(CORS(CORSConfig{
+		AllowOrigins:     []string{"https://app.example.com"},
+		AllowCredentials: true,
+		Routes:           []CORSRouteConfig{{Prefix: "/open", AllowOrigins: []string{"*"}}},
+	}))
+	app.Get("/api", func(c fiber.Ctx) error { return c.SendString("ok") })
+	app.Get("/open", func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	all := fiber.New()
+	all.Use(CORS(CORSConfig{AllowOrigins: []string{"*"}, AllowCredentials: true}))
+	all.Get("/", func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	cases := []struct {
+		name       string
+		app        *fiber.App
+		path       string
+		origin     string
+		wantOrigin string
+		wantCreds  string
+	}{
+		{"exact keeps credentials", app, "/api", "https://app.example.com", "https://app.example.com", "true"},
+		{"inherited wildcard route", app, "/open", "https://evil.com", "*", ""},
+		{"global wildcard", all, "/", "https://evil.com", "*", ""},
+	}
+	for _, tc := range cases {
+		for _, method := range []string{"GET", "OPTIONS"} {
+			req := httptest.NewRequest(method, tc.path, nil)
+			req.Header.Set("Origin", tc.origin)
+			if method == "OPTIONS" {
+				req.Header.Set("Access-Control-Request-Method", "POST")
+			}
+			resp, err := tc.app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+			if err != nil {
+				t.Fatalf("%s %s: app.Test: %v", tc.name, method, err)
+			}
+			resp.Body.Close()
+
+			if got := resp.Header.Get("Access-Control-Allow-Origin"); got != tc.wantOrigin {
+				t.Fatalf("%s %s: unexpected allow origin: %q", tc.name, method, got)
+			}
+			if got := resp.Header.Get("Access-Control-Allow-Credentials"); got != tc.wantCreds {
+				t.Fatalf("%s %s: unexpected allow credentials: %q", tc.name, method, got)
+			}
+		}
+	}
+}
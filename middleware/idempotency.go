@@ -0,0 +1,249 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stderrors "errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Idempotency-Key Middleware - 幂等请求
+ * ========================================================================
+ * 职责: 对携带 Idempotency-Key 的非安全请求（默认 POST/PATCH）去重
+ * 流程:
+ *   1. 以 调用方（Scope）+ Key 为维度 SETNX 占位（处理中）
+ *   2. 首次执行完成后将响应（状态码/Content-Type/Body）写入 Redis 并设置 TTL；
+ *      handler 返回的错误先交给 App ErrorHandler 写入响应，4xx 错误响应同样保存
+ *   3. 重试请求直接回放已保存的响应（响应头 Idempotent-Replayed: true）
+ *   4. 同一 Key 并发请求返回 409；同一 Key 请求体不同返回 422
+ *   5. 响应为 5xx 时删除占位，允许客户端重试
+ * 说明: Scope 为空（无租户、无 API Key 的匿名请求）时不做去重，
+ *       避免不同调用方共用同一 Key 空间而互相回放响应；
+ *       匿名接口需要去重时可按会话、客户端 IP 等自定义 Scope
+ *
+ * 使用示例:
+ *   idem := middleware.NewIdempotency(rdb, middleware.IdempotencyConfig{TTL: 24 * time.Hour}, log)
+ *   app.Post("/orders", idem.Handler(), createOrder)
+ * ======================================================================== */
+
+const (
+	// HeaderIdempotencyKey 幂等键请求头
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed 回放响应标记头
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+const (
+	idempotencyStateProcessing = "processing"
+	idempotencyStateCompleted  = "completed"
+)
+
+// IdempotencyConfig 幂等中间件配置
+type IdempotencyConfig struct {
+	TTL          time.Duration `yaml:"ttl"`            // 响应保存时间，默认 24h
+	LockTTL      time.Duration `yaml:"lock_ttl"`       // 处理中占位的过期时间，默认 30s
+	KeyPrefix    string        `yaml:"key_prefix"`     // Redis key 前缀，默认 "idempotency"
+	Methods      []string      `yaml:"methods"`        // 生效的方法，默认 POST、PATCH
+	Required     bool          `yaml:"required"`       // 是否强制要求携带 Idempotency-Key
+	MaxKeyLength int           `yaml:"max_key_length"` // Key 最大长度，默认 255
+
+	// Scope 幂等维度，默认取租户 ID，其次取 API Key ID；返回空字符串时不做去重
+	Scope func(c fiber.Ctx) string `yaml:"-"`
+}
+
+// idempotencyRecord Redis 中保存的记录
+type idempotencyRecord struct {
+	State       string `json:"state"`
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Idempotency 幂等中间件
+type Idempotency struct {
+	rdb redis.UniversalClient
+	cfg IdempotencyConfig
+	log *logger.Logger
+}
+
+// NewIdempotency 创建幂等中间件
+func NewIdempotency(rdb redis.UniversalClient, cfg IdempotencyConfig, log *logger.Logger) *Idempotency {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = 24 * time.Hour
+	}
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = 30 * time.Second
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "idempotency"
+	}
+	if len(cfg.Methods) == 0 {
+		cfg.Methods = []string{fiber.MethodPost, fiber.MethodPatch}
+	}
+	if cfg.MaxKeyLength <= 0 {
+		cfg.MaxKeyLength = 255
+	}
+	if cfg.Scope == nil {
+		cfg.Scope = defaultIdempotencyScope
+	}
+
+	return &Idempotency{rdb: rdb, cfg: cfg, log: log}
+}
+
+// defaultIdempotencyScope 默认幂等维度：租户 ID > API Key ID
+func defaultIdempotencyScope(c fiber.Ctx) string {
+	if tc, ok := repository.TenantFromContext(c.Context()); ok {
+		return tc.TenantID.String()
+	}
+	if keyID, ok := KeyIDFromContext(c); ok {
+		return keyID
+	}
+	return ""
+}
+
+// Handler 返回 Fiber 中间件
+func (i *Idempotency) Handler() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if !slices.Contains(i.cfg.Methods, c.Method()) {
+			return c.Next()
+		}
+
+		key := strings.TrimSpace(c.Get(HeaderIdempotencyKey))
+		if key == "" {
+			if i.cfg.Required {
+				return response.Error(c, errors.New(errors.ErrCodeInvalidArgument, "missing idempotency key"))
+			}
+			return c.Next()
+		}
+		if len(key) > i.cfg.MaxKeyLength {
+			return response.Error(c, errors.New(errors.ErrCodeInvalidArgument, "idempotency key too long"))
+		}
+
+		scope := i.cfg.Scope(c)
+		if scope == "" {
+			return c.Next()
+		}
+
+		ctx := c.Context()
+		redisKey := i.redisKey(scope, key)
+		fingerprint := requestFingerprint(c)
+
+		placeholder, _ := json.Marshal(idempotencyRecord{
+			State:       idempotencyStateProcessing,
+			Fingerprint: fingerprint,
+		})
+		acquired, err := i.rdb.SetNX(ctx, redisKey, placeholder, i.cfg.LockTTL).Result()
+		if err != nil {
+			// Redis 不可用时降级为直接执行
+			i.log.Warn("Idempotency store unavailable", zap.Error(err), zap.String("path", c.Path()))
+			return c.Next()
+		}
+		if !acquired {
+			return i.replay(c, redisKey, fingerprint)
+		}
+
+		if err := c.Next(); err != nil {
+			// 先交给 ErrorHandler 写入响应，按最终状态码决定保存或释放
+			if handleErr := c.App().ErrorHandler(c, err); handleErr != nil {
+				i.release(ctx, redisKey)
+				return handleErr
+			}
+		}
+
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			i.release(ctx, redisKey)
+			return nil
+		}
+
+		record, _ := json.Marshal(idempotencyRecord{
+			State:       idempotencyStateCompleted,
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: string(c.Response().Header.ContentType()),
+			Body:        slices.Clone(c.Response().Body()),
+		})
+		if err := i.rdb.Set(ctx, redisKey, record, i.cfg.TTL).Err(); err != nil {
+			i.log.Warn("Failed to save idempotent response", zap.Error(err), zap.String("key", redisKey))
+		}
+		return nil
+	}
+}
+
+// replay 回放已保存的响应
+func (i *Idempotency) replay(c fiber.Ctx, redisKey, fingerprint string) error {
+	data, err := i.rdb.Get(c.Context(), redisKey).Bytes()
+	if err != nil {
+		if stderrors.Is(err, redis.Nil) {
+			// 占位恰好过期或被释放，按并发冲突处理，由客户端重试
+			return i.conflict(c)
+		}
+		return response.Error(c, errors.Wrap(errors.ErrCodeUnavailable, "idempotency store unavailable", err))
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return response.Error(c, errors.Wrap(errors.ErrCodeInternal, "invalid idempotency record", err))
+	}
+
+	if record.Fingerprint != fingerprint {
+		return response.ErrorWithCode(c, fiber.StatusUnprocessableEntity,
+			errors.New(errors.ErrCodeInvalidArgument, "idempotency key reused with different request"))
+	}
+	if record.State != idempotencyStateCompleted {
+		return i.conflict(c)
+	}
+
+	c.Set(HeaderIdempotentReplayed, "true")
+	if record.ContentType != "" {
+		c.Set(fiber.HeaderContentType, record.ContentType)
+	}
+	return c.Status(record.Status).Send(record.Body)
+}
+
+// conflict 返回并发冲突 409
+func (i *Idempotency) conflict(c fiber.Ctx) error {
+	return response.ErrorWithCode(c, fiber.StatusConflict,
+		errors.New(errors.ErrCodeAlreadyExists, "request with the same idempotency key is in progress"))
+}
+
+// release 删除占位，允许重试
+func (i *Idempotency) release(ctx context.Context, redisKey string) {
+	if err := i.rdb.Del(context.WithoutCancel(ctx), redisKey).Err(); err != nil {
+		i.log.Warn("Failed to release idempotency key", zap.Error(err), zap.String("key", redisKey))
+	}
+}
+
+// redisKey 构造 Redis key: <prefix>:<scope>:<key>
+func (i *Idempotency) redisKey(scope, key string) string {
+	return i.cfg.KeyPrefix + ":" + scope + ":" + key
+}
+
+// requestFingerprint 请求指纹（方法 + 路径 + 请求体）
+func requestFingerprint(c fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method()))
+	h.Write([]byte{0})
+	h.Write([]byte(c.Path()))
+	h.Write([]byte{0})
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gofiber/fiber/v3"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestIdempotency(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	defer server.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer rdb.Close()
+
+	calls := 0
+	idem := NewIdempotency(rdb, IdempotencyConfig{Scope: func(fiber.Ctx) string { return "t1" }}, logger.NewNop())
+	app := fiber.New()
+	app.Post("/orders", idem.Handler(), func(c fiber.Ctx) error {
+		calls++
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"order": calls})
+	})
+
+	do := func(key, body string) (*http.Response, string) {
+		req := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(HeaderIdempotencyKey, key)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp, string(data)
+	}
+
+	resp, first := do("k1", `{"amount":1}`)
+	if resp.StatusCode != fiber.StatusCreated || calls != 1 {
+		t.Fatalf("unexpected first response: %d calls=%d", resp.StatusCode, calls)
+	}
+
+	resp, replayed := do("k1", `{"amount":1}`)
+	if calls != 1 || replayed != first || resp.StatusCode != fiber.StatusCreated {
+		t.Fatalf("expected replay, got status=%d body=%q calls=%d", resp.StatusCode, replayed, calls)
+	}
+	if resp.Header.Get(HeaderIdempotentReplayed) != "true" {
+		t.Fatalf("expected replay header")
+	}
+
+	resp, _ = do("k1", `{"amount":2}`)
+	if resp.StatusCode != fiber.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for different payload, got %d", resp.StatusCode)
+	}
+
+	// 模拟并发请求：占位处于处理中
+	sum := sha256.Sum256([]byte("POST\x00/orders\x00{}"))
+	fp := hex.EncodeToString(sum[:])
+	placeholder, _ := json.Marshal(idempotencyRecord{State: idempotencyStateProcessing, Fingerprint: fp})
+	if err := server.Set("idempotency:t1:k2", string(placeholder)); err != nil {
+		t.Fatalf("seed placeholder: %v", err)
+	}
+	resp, _ = do("k2", `{}`)
+	if resp.StatusCode != fiber.StatusConflict {
+		t.Fatalf("expected 409 for in-flight duplicate, got %d", resp.StatusCode)
+	}
+
+	do("", `{}`)
+	if calls != 2 {
+		t.Fatalf("request without key should pass through, calls=%d", calls)
+	}
+}
+
+func TestIdempotencyWithoutScopeSkipsDedup(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	defer server.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer rdb.Close()
+
+	calls := 0
+	idem := NewIdempotency(rdb, IdempotencyConfig{}, logger.NewNop())
+	app := fiber.New()
+	app.Post("/orders", idem.Handler(), func(c fiber.Ctx) error {
+		calls++
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	// 匿名调用方之间不共享 Key 空间，相同 Key 也不会回放他人的响应
+	for range 2 {
+		req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{}`))
+		req.Header.Set(HeaderIdempotencyKey, "k1")
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		if resp.Header.Get(HeaderIdempotentReplayed) != "" {
+			t.Fatalf("anonymous request must not be replayed")
+		}
+	}
+	if calls != 2 || len(server.Keys()) != 0 {
+		t.Fatalf("expected no dedup without scope, calls=%d keys=%v", calls, server.Keys())
+	}
+}
+
+func TestIdempotencyRecordsErrorHandlerResponses(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	defer server.Close()
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer rdb.Close()
+
+	calls := 0
+	idem := NewIdempotency(rdb, IdempotencyConfig{Scope: func(fiber.Ctx) string { return "t1" }}, logger.NewNop())
+	app := fiber.New()
+	app.Post("/orders", idem.Handler(), func(c fiber.Ctx) error {
+		calls++
+		if calls == 1 {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid order")
+		}
+		return fiber.NewError(fiber.StatusServiceUnavailable, "busy")
+	})
+
+	do := func(key string) *http.Response {
+		req := httptest.NewRequest("POST", "/orders", strings.NewReader(`{}`))
+		req.Header.Set(HeaderIdempotencyKey, key)
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		return resp
+	}
+
+	// 4xx 错误经 ErrorHandler 写入后保存，重试直接回放
+	for range 2 {
+		if resp := do("k1"); resp.StatusCode != fiber.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", resp.StatusCode)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("expected 4xx error response replayed, calls=%d", calls)
+	}
+
+	// 5xx 释放占位，允许重试
+	do("k2")
+	do("k2")
+	if calls != 3 {
+		t.Fatalf("expected 5xx to release key, calls=%d", calls)
+	}
+}
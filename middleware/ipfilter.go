@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/aisgo/ais-go-pkg/metrics"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * IP Filter Middleware - IP 白名单/黑名单
+ * ========================================================================
+ * 职责: 按客户端 IP 放行或拒绝请求（403），用于管理端点、Webhook 来源限制等
+ * 规则:
+ *   - deny 优先：命中 deny 直接拒绝
+ *   - allow 非空时仅放行命中 allow 的 IP；为空时默认放行
+ *   - 条目支持 CIDR 与单个 IP（视为 /32 或 /128）
+ *   - Routes 按路由前缀覆盖（最长前缀优先，按路径段匹配：/admin 匹配 /admin 与 /admin/x，
+ *     不匹配 /administrator），未设置的字段继承全局配置
+ * 客户端 IP:
+ *   - transport/http 配置了 proxy（可信代理）时直接使用其还原的 c.IP()，忽略 trusted_proxies，
+ *     推荐统一使用 http.proxy 配置可信代理
+ *   - 否则默认取 TCP 对端地址；对端命中 trusted_proxies 时解析 X-Forwarded-For：
+ *     forwarded_depth > 0 取从右数第 N 个条目（1 为最近一跳代理写入的地址），
+ *     条目数不足 N 时说明请求未经过预期的代理链，使用对端地址；
+ *     否则从右向左跳过可信代理，取第一个不可信地址
+ * 指标: app_http_ip_blocked_total{rule, reason}
+ *
+ * 使用示例:
+ *   // ip_filter:
+ *   //   enabled: true
+ *   //   deny: ["203.0.113.0/24"]
+ *   //   trusted_proxies: ["10.0.0.0/8"]
+ *   //   routes:
+ *   //     - prefix: /admin
+ *   //       allow: ["10.1.0.0/16"]
+ *   //     - prefix: /webhooks/stripe
+ *   //       allow: ["3.18.12.63", "3.130.192.231"]
+ *   filter, err := middleware.IPFilter(cfg)
+ *   app.Use(filter)
+ * ======================================================================== */
+
+// IPFilterConfig IP 过滤配置
+type IPFilterConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Allow          []string `yaml:"allow"`           // 白名单，为空表示不限制
+	Deny           []string `yaml:"deny"`            // 黑名单，优先于白名单
+	TrustedProxies []string `yaml:"trusted_proxies"` // 可信代理网段，仅对这些对端解析 X-Forwarded-For（客户端 IP 已由 http.proxy 还原时忽略）
+	ForwardedDepth int      `yaml:"forwarded_depth"` // X-Forwarded-For 从右数第 N 个为客户端，0 表示按可信代理跳过
+
+	// Routes 按路由前缀覆盖配置
+	Routes []IPFilterRouteConfig `yaml:"routes"`
+}
+
+// IPFilterRouteConfig 路由级 IP 过滤覆盖配置
+type IPFilterRouteConfig struct {
+	Prefix string   `yaml:"prefix"`
+	Allow  []string `yaml:"allow"`
+	Deny   []string `yaml:"deny"`
+}
+
+// ipBlockedTotal 被拦截请求计数
+var ipBlockedTotal = metrics.NewCounter("app", "http", "ip_blocked_total",
+	"Total number of requests blocked by IP filter", []string{"rule", "reason"})
+
+// ipRule 预处理后的规则
+type ipRule struct {
+	name  string // 指标标签：global 或路由前缀
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// IPFilter 创建 IP 过滤中间件，配置中的网段非法时返回错误
+func IPFilter(cfg IPFilterConfig) (fiber.Handler, error) {
+	global, err := newIPRule("global", cfg.Allow, cfg.Deny)
+	if err != nil {
+		return nil, err
+	}
+	trusted, err := ParseCIDRs(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trusted_proxies: %w", err)
+	}
+
+	type route struct {
+		prefix string
+		rule   *ipRule
+	}
+	routes := make([]route, 0, len(cfg.Routes))
+	for _, rc := range cfg.Routes {
+		allow, deny := cfg.Allow, cfg.Deny
+		if rc.Allow != nil {
+			allow = rc.Allow
+		}
+		if rc.Deny != nil {
+			deny = rc.Deny
+		}
+		rule, err := newIPRule(rc.Prefix, allow, deny)
+		if err != nil {
+			return nil, fmt.Errorf("ip filter route %q: %w", rc.Prefix, err)
+		}
+		routes = append(routes, route{prefix: strings.TrimSuffix(rc.Prefix, "/"), rule: rule})
+	}
+	// 最长前缀优先
+	slices.SortFunc(routes, func(a, b route) int {
+		return len(b.prefix) - len(a.prefix)
+	})
+
+	return func(c fiber.Ctx) error {
+		rule := global
+		path := c.Path()
+		for _, r := range routes {
+			if matchPathPrefix(path, r.prefix) {
+				rule = r.rule
+				break
+			}
+		}
+
+		ip := clientIP(c, trusted, cfg.ForwardedDepth)
+		if reason := rule.check(ip); reason != "" {
+			ipBlockedTotal.WithLabelValues(rule.name, reason).Inc()
+			return fiber.ErrForbidden
+		}
+		return c.Next()
+	}, nil
+}
+
+// matchPathPrefix 按路径段匹配前缀（prefix 已去除末尾的 "/"，空前缀匹配全部）
+func matchPathPrefix(path, prefix string) bool {
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// newIPRule 解析规则
+func newIPRule(name string, allow, deny []string) (*ipRule, error) {
+	allowNets, err := ParseCIDRs(allow)
+	if err != nil {
+		return nil, fmt.Errorf("invalid allow: %w", err)
+	}
+	denyNets, err := ParseCIDRs(deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deny: %w", err)
+	}
+	return &ipRule{name: name, allow: allowNets, deny: denyNets}, nil
+}
+
+// check 返回拒绝原因，放行时返回空字符串
+func (r *ipRule) check(ip net.IP) string {
+	if ip == nil {
+		if len(r.allow) > 0 {
+			return "unknown_ip"
+		}
+		return ""
+	}
+	if containsIP(r.deny, ip) {
+		return "denied"
+	}
+	if len(r.allow) > 0 && !containsIP(r.allow, ip) {
+		return "not_allowed"
+	}
+	return ""
+}
+
+// clientIPResolvedLocalKey 客户端 IP 已由上游还原的标记
+const clientIPResolvedLocalKey = "client_ip_resolved"
+
+// MarkClientIPResolved 标记请求的对端地址已还原为真实客户端 IP（由 transport/http 的可信代理解析调用），
+// 之后 IPFilter 直接使用 c.IP()，不再自行解析 X-Forwarded-For
+func MarkClientIPResolved(c fiber.Ctx) {
+	c.Locals(clientIPResolvedLocalKey, true)
+}
+
+// clientIP 解析客户端 IP（仅信任来自可信代理的 X-Forwarded-For）
+func clientIP(c fiber.Ctx, trusted []*net.IPNet, depth int) net.IP {
+	peer := c.RequestCtx().RemoteIP()
+	if resolved, _ := c.Locals(clientIPResolvedLocalKey).(bool); resolved {
+		return peer
+	}
+	if len(trusted) == 0 || !containsIP(trusted, peer) {
+		return peer
+	}
+
+	header := c.Get(fiber.HeaderXForwardedFor)
+	if header == "" {
+		return peer
+	}
+	hops := strings.Split(header, ",")
+
+	if depth > 0 {
+		// 条目不足时无法确定客户端写入的位置，最左侧条目可被客户端伪造
+		if depth > len(hops) {
+			return peer
+		}
+		return net.ParseIP(strings.TrimSpace(hops[len(hops)-depth]))
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := net.ParseIP(strings.TrimSpace(hops[i]))
+		if ip == nil {
+			return nil
+		}
+		if !containsIP(trusted, ip) {
+			return ip
+		}
+	}
+	// 全部为可信代理时取最左侧地址
+	return net.ParseIP(strings.TrimSpace(hops[0]))
+}
+
+// ParseCIDRs 解析 CIDR 列表，单个 IP 视为 /32（IPv4）或 /128（IPv6）
+func ParseCIDRs(entries []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cidr %q: %w", entry, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// containsIP 判断 IP 是否命中任一网段
+func containsIP(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestIPFilter(t *testing.T) {
+	// app.Test 的对端地址为 0.0.0.0，将其配置为可信代理以便通过 X-Forwarded-For 模拟客户端
+	filter, err := IPFilter(IPFilterConfig{
+		Enabled:        true,
+		Deny:           []string{"203.0.113.0/24"},
+		TrustedProxies: []string{"0.0.0.0", "10.0.0.0/8"},
+		Routes: []IPFilterRouteConfig{
+			{Prefix: "/admin", Allow: []string{"192.168.1.0/24"}},
+			{Prefix: "/admin/open", Allow: []string{}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("ip filter: %v", err)
+	}
+	app := fiber.New()
+	app.Use(filter)
+	app.Get("/*", func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	before := testutil.ToFloat64(ipBlockedTotal.WithLabelValues("/admin", "not_allowed"))
+	cases := []struct {
+		path, xff string
+		want      int
+	}{
+		{"/public", "198.51.100.7", fiber.StatusOK},
+		{"/public", "203.0.113.9", fiber.StatusForbidden},
+		{"/public", "203.0.113.9, 10.0.0.2", fiber.StatusForbidden},        // 跳过可信代理
+		{"/public", "203.0.113.9, 198.51.100.7, 10.0.0.2", fiber.StatusOK}, // 伪造的最左侧地址被忽略
+		{"/admin/users", "192.168.1.20, 10.0.0.2", fiber.StatusOK},         // 路由白名单
+		{"/admin/users", "198.51.100.7", fiber.StatusForbidden},            // 不在白名单
+		{"/admin/open/status", "198.51.100.7", fiber.StatusOK},             // 更长前缀清空白名单
+		{"/admin/open/status", "203.0.113.1", fiber.StatusForbidden},       // deny 继承全局
+		{"/admin", "198.51.100.7", fiber.StatusForbidden},                  // 前缀本身
+		{"/administrator", "198.51.100.7", fiber.StatusOK},                 // 按路径段匹配，不命中 /admin
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Header.Set(fiber.HeaderXForwardedFor, tc.xff)
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Fatalf("%s from %q: expected %d, got %d", tc.path, tc.xff, tc.want, resp.StatusCode)
+		}
+	}
+	if got := testutil.ToFloat64(ipBlockedTotal.WithLabelValues("/admin", "not_allowed")) - before; got != 2 {
+		t.Fatalf("expected 2 blocked admin requests, got %v", got)
+	}
+}
+
+func TestIPFilterForwardedDepth(t *testing.T) {
+	filter, err := IPFilter(IPFilterConfig{
+		Allow:          []string{"198.51.100.7"},
+		TrustedProxies: []string{"0.0.0.0"},
+		ForwardedDepth: 2,
+	})
+	if err != nil {
+		t.Fatalf("ip filter: %v", err)
+	}
+	app := fiber.New()
+	app.Use(filter)
+	app.Get("/", func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	for xff, want := range map[string]int{
+		"1.1.1.1, 198.51.100.7, 172.16.0.1": fiber.StatusOK,
+		"198.51.100.7, 1.1.1.1, 172.16.0.1": fiber.StatusForbidden,
+		"":                                  fiber.StatusForbidden, // 无转发头时使用对端地址
+		"198.51.100.7":                      fiber.StatusForbidden, // 条目不足 N 时使用对端地址
+	} {
+		req := httptest.NewRequest("GET", "/", nil)
+		if xff != "" {
+			req.Header.Set(fiber.HeaderXForwardedFor, xff)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Fatalf("xff %q: expected %d, got %d", xff, want, resp.StatusCode)
+		}
+	}
+
+	if _, err := IPFilter(IPFilterConfig{Deny: []string{"not-an-ip"}}); err == nil {
+		t.Fatalf("expected invalid cidr error")
+	}
+}
+
+func TestIPFilterResolvedClientIP(t *testing.T) {
+	filter, err := IPFilter(IPFilterConfig{
+		Allow:          []string{"198.51.100.7"},
+		TrustedProxies: []string{"0.0.0.0"},
+	})
+	if err != nil {
+		t.Fatalf("ip filter: %v", err)
+	}
+	app := fiber.New()
+	// 上游已还原客户端 IP（app.Test 的对端地址 0.0.0.0 即视为客户端）
+	app.Use(func(c fiber.Ctx) error {
+		MarkClientIPResolved(c)
+		return c.Next()
+	})
+	app.Use(filter)
+	app.Get("/", func(c fiber.Ctx) error { return c.SendString("ok") })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(fiber.HeaderXForwardedFor, "198.51.100.7")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != fiber.StatusForbidden {
+		t.Fatalf("expected forwarded header to be ignored, got %d", resp.StatusCode)
+	}
+}
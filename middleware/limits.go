@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	stderrors "errors"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Request Limits Middleware - 请求超时与请求体大小限制
+ * ========================================================================
+ * 职责:
+ *   - Timeout: 为 handler 设置 context 截止时间，超时返回 504
+ *   - MaxBodySize: 请求体超过限制返回 413
+ * 注意:
+ *   - Timeout 是协作式的: 仅通过 ctx 通知超时，handler 需透传 c.Context() 到下游调用；
+ *     忽略 ctx 的 handler 不会被中断，且只有 handler 返回错误时才返回 504
+ *   - 流式响应（SendStreamWriter，如 response.NDJSON / SSE、export.Fiber）在 handler 返回后写出，
+ *     此时不取消 ctx，但截止时间仍然生效；长时间的流式输出应基于 context.WithoutCancel 派生独立 ctx
+ *   - 全局默认值通过 transport/http Config 的 request_timeout / max_body_size 配置
+ *   - max_body_size 同时是 Fiber BodyLimit，由 fasthttp 在任何中间件之前强制执行，
+ *     路由级 MaxBodySize 只能收紧而不能放宽该上限；需要大上传的路由应调大全局值并收紧其他路由
+ *
+ * 使用示例:
+ *   // max_body_size: 33554432
+ *   app.Use("/api", middleware.MaxBodySize(1<<20))
+ *   app.Post("/upload", middleware.Timeout(2*time.Minute), upload)
+ * ======================================================================== */
+
+// ErrRequestTimeout 请求处理超时
+var ErrRequestTimeout = errors.New(errors.ErrCodeTimeout, "request timeout")
+
+// ErrBodyTooLarge 请求体过大
+var ErrBodyTooLarge = errors.New(errors.ErrCodeInvalidArgument, "request body too large")
+
+// Timeout 创建请求超时中间件，d <= 0 时不生效
+// 协作式超时: handler 返回后若响应体为流，ctx 保留至截止时间，不随 handler 返回而取消
+func Timeout(d time.Duration) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if d <= 0 {
+			return c.Next()
+		}
+
+		parent := c.Context()
+		ctx, cancel := context.WithTimeout(parent, d)
+
+		c.SetContext(ctx)
+		err := c.Next()
+		c.SetContext(parent)
+
+		if c.Response().IsBodyStream() {
+			// 流式响应体在 handler 返回后才写出，截止时间到达时再释放
+			context.AfterFunc(ctx, cancel)
+		} else {
+			cancel()
+		}
+
+		if stderrors.Is(err, context.DeadlineExceeded) ||
+			(err != nil && stderrors.Is(ctx.Err(), context.DeadlineExceeded)) {
+			return response.Error(c, ErrRequestTimeout)
+		}
+		return err
+	}
+}
+
+// MaxBodySize 创建请求体大小限制中间件，limit <= 0 时不生效
+// limit 大于全局 max_body_size（Fiber BodyLimit）时无效，超出全局上限的请求在中间件之前即被拒绝
+func MaxBodySize(limit int) fiber.Handler {
+	return func(c fiber.Ctx) error {
+		if limit <= 0 {
+			return c.Next()
+		}
+		if c.Request().Header.ContentLength() > limit || len(c.Body()) > limit {
+			return response.ErrorWithCode(c, fiber.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestTimeoutAndMaxBodySize(t *testing.T) {
+	app := fiber.New()
+	app.Get("/slow", Timeout(20*time.Millisecond), func(c fiber.Ctx) error {
+		select {
+		case <-c.Context().Done():
+			return c.Context().Err()
+		case <-time.After(time.Second):
+			return c.SendString("late")
+		}
+	})
+	app.Get("/fast", Timeout(time.Second), func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	app.Post("/upload", MaxBodySize(8), func(c fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+
+	cases := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"GET", "/slow", "", fiber.StatusGatewayTimeout},
+		{"GET", "/fast", "", fiber.StatusOK},
+		{"POST", "/upload", "small", fiber.StatusOK},
+		{"POST", "/upload", "this body is too large", fiber.StatusRequestEntityTooLarge},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", tc.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Fatalf("%s %q: unexpected status: got=%d want=%d", tc.path, tc.body, resp.StatusCode, tc.status)
+		}
+	}
+}
+
+func TestTimeoutKeepsStreamContext(t *testing.T) {
+	app := fiber.New()
+	app.Get("/stream", Timeout(time.Second), func(c fiber.Ctx) error {
+		ctx := c.Context()
+		return c.SendStreamWriter(func(w *bufio.Writer) {
+			// 流式响应体在 handler 返回后写出，ctx 不应已被取消
+			if _, ok := ctx.Deadline(); !ok || ctx.Err() != nil {
+				w.WriteString("cancelled")
+				return
+			}
+			w.WriteString("streaming")
+		})
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/stream", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "streaming" {
+		t.Fatalf("unexpected stream body: %q", body)
+	}
+}
+
+func TestMaxBodySizeWithGlobalLimit(t *testing.T) {
+	large := bytes.Repeat([]byte("x"), 5<<20)
+	handler := func(c fiber.Ctx) error { return c.SendString("ok") }
+
+	// 全局上限为负数（不限制）时，BodyLimit 设为最大值，超过 Fiber 默认 4MB 的请求体可通过
+	unlimited := fiber.New(fiber.Config{BodyLimit: math.MaxInt})
+	unlimited.Use(MaxBodySize(-1))
+	unlimited.Post("/upload", handler)
+
+	// 路由级上限大于全局上限时无效，超出全局上限的请求在中间件之前即被拒绝
+	limited := fiber.New(fiber.Config{BodyLimit: 1 << 20})
+	limited.Use(MaxBodySize(1 << 20))
+	limited.Post("/upload", MaxBodySize(8<<20), handler)
+
+	resp, err := unlimited.Test(httptest.NewRequest("POST", "/upload", bytes.NewReader(large)), fiber.TestConfig{Timeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("negative global: app.Test: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("negative global: unexpected status: %d", resp.StatusCode)
+	}
+
+	// fasthttp 读取请求体时即拒绝（app.Test 返回错误，真实连接收到 413）
+	resp, err = limited.Test(httptest.NewRequest("POST", "/upload", bytes.NewReader(large)), fiber.TestConfig{Timeout: 5 * time.Second})
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusRequestEntityTooLarge {
+			t.Fatalf("route above global: unexpected status: %d", resp.StatusCode)
+		}
+	}
+}
@@ -12,5 +12,5 @@ import (
 
 // Module 中间件模块
 var Module = fx.Module("middleware",
-	fx.Provide(NewAPIKeyAuth),
+	fx.Provide(NewAPIKeyAuthFromParams),
 )
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/metrics"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Rate Limit Middleware - 单实例令牌桶限流
+ * ========================================================================
+ * 职责: 按客户端维度限制请求速率，超限返回 429 并设置 Retry-After
+ * 维度:
+ *   - ip（默认）: 客户端 IP（c.IP()）
+ *   - api_key: 已认证 API Key 的 key_id，未认证时回退到 IP；需挂载在 Authenticate 之后
+ *   - KeyFunc: 自定义维度（如租户），优先于 key_by
+ * 注意: 桶状态保存在进程内存中，多副本部署时总配额为 rate × 副本数
+ * 指标: app_http_rate_limited_total{name}
+ *
+ * 使用示例:
+ *   // rate_limit:
+ *   //   enabled: true
+ *   //   rate: 20      # 每秒补充令牌数
+ *   //   burst: 40     # 桶容量
+ *   //   key_by: api_key
+ *   api := app.Group("/api", auth.Authenticate(), middleware.RateLimit(cfg))
+ * ======================================================================== */
+
+// ErrRateLimited 请求过于频繁
+var ErrRateLimited = errors.New(errors.ErrCodeUnavailable, "too many requests")
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Enabled bool    `yaml:"enabled"`
+	Name    string  `yaml:"name"`   // 指标标签，默认 default
+	Rate    float64 `yaml:"rate"`   // 每秒补充令牌数
+	Burst   int     `yaml:"burst"`  // 桶容量，默认 ceil(rate)，至少为 1
+	KeyBy   string  `yaml:"key_by"` // ip（默认）/ api_key
+
+	// KeyFunc 自定义限流维度，优先于 KeyBy
+	KeyFunc func(c fiber.Ctx) string `yaml:"-"`
+}
+
+// rateLimitedTotal 被限流请求计数
+var rateLimitedTotal = metrics.NewCounter("app", "http", "rate_limited_total",
+	"Total number of requests rejected by rate limiter", []string{"name"})
+
+// tokenBucket 令牌桶
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter 按维度管理令牌桶
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// RateLimit 创建限流中间件，rate <= 0 时不生效
+func RateLimit(cfg RateLimitConfig) fiber.Handler {
+	if cfg.Rate <= 0 {
+		return func(c fiber.Ctx) error { return c.Next() }
+	}
+	name := cfg.Name
+	if name == "" {
+		name = "default"
+	}
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = max(int(math.Ceil(cfg.Rate)), 1)
+	}
+	keyFunc := cfg.KeyFunc
+	if keyFunc == nil {
+		keyFunc = rateLimitKeyFunc(cfg.KeyBy)
+	}
+
+	limiter := &rateLimiter{
+		rate:    cfg.Rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+
+	return func(c fiber.Ctx) error {
+		wait, ok := limiter.allow(keyFunc(c), time.Now())
+		if !ok {
+			rateLimitedTotal.WithLabelValues(name).Inc()
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			return response.ErrorWithCode(c, fiber.StatusTooManyRequests, ErrRateLimited)
+		}
+		return c.Next()
+	}
+}
+
+// rateLimitKeyFunc 内置限流维度
+func rateLimitKeyFunc(keyBy string) func(c fiber.Ctx) string {
+	if keyBy == "api_key" {
+		return func(c fiber.Ctx) string {
+			if id, ok := KeyIDFromContext(c); ok {
+				return "key:" + id
+			}
+			return "ip:" + c.IP()
+		}
+	}
+	return func(c fiber.Ctx) string {
+		return "ip:" + c.IP()
+	}
+}
+
+// allow 消耗一个令牌；令牌不足时返回需等待的时长
+func (l *rateLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+		b.last = now
+	}
+
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// sweep 定期清理已回满的桶，避免维度过多时内存持续增长
+func (l *rateLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.burst / l.rate * float64(time.Second))
+	interval := max(refill, time.Minute)
+	if now.Sub(l.lastSweep) < interval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
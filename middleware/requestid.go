@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/aisgo/ais-go-pkg/requestid"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Request ID Middleware - 请求 ID / 关联 ID
+ * ========================================================================
+ * 职责: 接收或生成 X-Request-ID，写入 c.Context()、Locals 与响应头
+ * 特性:
+ *   - 入站 ID 校验失败（超长/不可见字符）时重新生成，防止日志注入
+ *   - X-Correlation-ID 缺失时与请求 ID 相同，并回写到响应头
+ *   - 响应信封（response.Ok / Error）与访问日志自动带上 request_id
+ * 注意: 需挂载在其他中间件之前，后续中间件与 handler 才能读到 ID
+ *
+ * 使用示例:
+ *   app.Use(middleware.RequestID(middleware.RequestIDConfig{}))
+ *   id := middleware.RequestIDFromCtx(c)
+ * ======================================================================== */
+
+// RequestIDConfig 请求 ID 中间件配置
+type RequestIDConfig struct {
+	// Disabled 关闭自动挂载（transport/http 默认挂载）
+	Disabled bool `yaml:"disabled"`
+	// IgnoreIncoming 忽略客户端传入的 ID，总是重新生成（面向公网入口时可开启）
+	IgnoreIncoming bool `yaml:"ignore_incoming"`
+
+	// Generator 自定义 ID 生成器，默认 requestid.New（ULID）
+	Generator func() string `yaml:"-"`
+}
+
+// RequestID 创建请求 ID 中间件
+func RequestID(cfg RequestIDConfig) fiber.Handler {
+	generate := cfg.Generator
+	if generate == nil {
+		generate = requestid.New
+	}
+
+	return func(c fiber.Ctx) error {
+		var id, correlationID string
+		if !cfg.IgnoreIncoming {
+			// 请求头引用 fasthttp 复用缓冲区，写入 context 前需复制
+			if v := c.Get(requestid.Header); requestid.Valid(v) {
+				id = strings.Clone(v)
+			}
+			if v := c.Get(requestid.CorrelationHeader); requestid.Valid(v) {
+				correlationID = strings.Clone(v)
+			}
+		}
+		if id == "" {
+			id = generate()
+		}
+		if correlationID == "" {
+			correlationID = id
+		}
+
+		ctx := requestid.WithContext(c.Context(), id)
+		ctx = requestid.WithCorrelationID(ctx, correlationID)
+		c.SetContext(ctx)
+		c.Locals(requestid.LocalsKey, id)
+		c.Set(requestid.Header, id)
+		c.Set(requestid.CorrelationHeader, correlationID)
+		return c.Next()
+	}
+}
+
+// RequestIDFromCtx 读取当前请求 ID（优先 Locals，其次响应头与请求头）
+func RequestIDFromCtx(c fiber.Ctx) string {
+	if id, ok := c.Locals(requestid.LocalsKey).(string); ok && id != "" {
+		return id
+	}
+	if id := c.GetRespHeader(requestid.Header); id != "" {
+		return id
+	}
+	return c.Get(requestid.Header)
+}
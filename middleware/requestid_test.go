@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/requestid"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestRequestID(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: response.NewFiberErrorHandler(logger.NewNop())})
+	app.Use(RequestID(RequestIDConfig{Generator: func() string { return "generated" }}))
+	app.Get("/ok", func(c fiber.Ctx) error {
+		if requestid.FromContext(c.Context()) != RequestIDFromCtx(c) {
+			t.Errorf("context and locals disagree")
+		}
+		return response.OkWithData(c, requestid.CorrelationIDFromContext(c.Context()))
+	})
+	app.Get("/fail", func(c fiber.Ctx) error {
+		return errors.New(errors.ErrCodeNotFound, "missing")
+	})
+
+	cases := []struct {
+		path, incoming, correlation string
+		wantID, wantCorrelation     string
+	}{
+		{"/ok", "", "", "generated", "generated"},
+		{"/ok", "abc-123", "", "abc-123", "abc-123"},
+		{"/ok", "abc-123", "chain-1", "abc-123", "chain-1"},
+		{"/ok", "bad id\twith spaces", "", "generated", "generated"},
+		{"/ok", strings.Repeat("x", requestid.MaxLength+1), "", "generated", "generated"},
+		{"/fail", "abc-123", "", "abc-123", "abc-123"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.incoming != "" {
+			req.Header.Set(requestid.Header, tc.incoming)
+		}
+		if tc.correlation != "" {
+			req.Header.Set(requestid.CorrelationHeader, tc.correlation)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if got := resp.Header.Get(requestid.Header); got != tc.wantID {
+			t.Fatalf("%s %q: expected response header %q, got %q", tc.path, tc.incoming, tc.wantID, got)
+		}
+		if got := resp.Header.Get(requestid.CorrelationHeader); got != tc.wantCorrelation {
+			t.Fatalf("%s %q: expected correlation header %q, got %q", tc.path, tc.incoming, tc.wantCorrelation, got)
+		}
+		var result response.Result
+		if err := json.Unmarshal(body, &result); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		if result.RequestID != tc.wantID {
+			t.Fatalf("%s: expected envelope request_id %q, got %q", tc.path, tc.wantID, result.RequestID)
+		}
+		if tc.path == "/ok" && result.Data != tc.wantCorrelation {
+			t.Fatalf("expected correlation id in context %q, got %v", tc.wantCorrelation, result.Data)
+		}
+	}
+}
+
+func TestRequestIDIgnoreIncoming(t *testing.T) {
+	app := fiber.New()
+	app.Use(RequestID(RequestIDConfig{IgnoreIncoming: true}))
+	app.Get("/", func(c fiber.Ctx) error { return c.SendString(RequestIDFromCtx(c)) })
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(requestid.Header, "client-id")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) == "client-id" || len(body) != 26 || resp.Header.Get(requestid.Header) != string(body) {
+		t.Fatalf("expected generated ulid, got %q", body)
+	}
+}
@@ -0,0 +1,239 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Webhook Signature Middleware - 入站 Webhook 签名校验
+ * ========================================================================
+ * 职责: 按路由校验第三方回调的 HMAC-SHA256 签名，拒绝伪造与重放请求
+ * 方案:
+ *   - hmac:   签名头 = HMAC(secret, "{timestamp}.{raw_body}")，时间戳头为 Unix 秒；
+ *             Tolerance < 0（不校验重放）时时间戳头可省略，此时签名内容为原始请求体
+ *   - stripe: Stripe-Signature: t={ts},v1={hex}[,v1=...]，签名内容 "{t}.{raw_body}"
+ *   - github: X-Hub-Signature-256: sha256={hex}，签名内容为原始请求体（无时间戳）
+ * 安全:
+ *   - 基于原始请求体（BodyRaw，未解压/未解析）计算，写入 Locals 供后续读取
+ *   - 时间戳超出 Tolerance（默认 5m）视为重放
+ *   - 支持多个密钥（轮换期间新旧并存），hmac.Equal 常量时间比较
+ *
+ * 使用示例:
+ *   stripe := middleware.WebhookSignature(middleware.WebhookConfig{
+ *       Scheme:  middleware.WebhookSchemeStripe,
+ *       Secrets: []string{cfg.StripeSecret},
+ *   })
+ *   app.Post("/webhooks/stripe", stripe, func(c fiber.Ctx) error {
+ *       body := middleware.WebhookRawBody(c)
+ *       ...
+ *   })
+ * ======================================================================== */
+
+// WebhookScheme 签名方案
+type WebhookScheme string
+
+const (
+	WebhookSchemeHMAC   WebhookScheme = "hmac"
+	WebhookSchemeStripe WebhookScheme = "stripe"
+	WebhookSchemeGitHub WebhookScheme = "github"
+)
+
+const (
+	// DefaultWebhookTolerance 默认时间戳容忍窗口
+	DefaultWebhookTolerance = 5 * time.Minute
+
+	webhookRawBodyLocalKey = "webhook_raw_body"
+)
+
+// WebhookConfig Webhook 签名校验配置
+type WebhookConfig struct {
+	Scheme          WebhookScheme `yaml:"scheme"`           // hmac（默认）/ stripe / github
+	Secrets         []string      `yaml:"secrets"`          // 签名密钥，任一匹配即通过
+	SignatureHeader string        `yaml:"signature_header"` // 签名头，按方案默认 X-Signature / Stripe-Signature / X-Hub-Signature-256
+	TimestampHeader string        `yaml:"timestamp_header"` // hmac 方案时间戳头，默认 X-Timestamp
+	SignaturePrefix string        `yaml:"signature_prefix"` // hmac 方案签名值前缀（如 "sha256="），校验前去除
+	Encoding        string        `yaml:"encoding"`         // hmac 方案签名编码: hex（默认）/ base64
+	Tolerance       time.Duration `yaml:"tolerance"`        // 时间戳容忍窗口，默认 5m，<0 不校验（hmac 方案时间戳可省略）
+
+	// Now 当前时间（测试用）
+	Now func() time.Time `yaml:"-"`
+}
+
+func (c WebhookConfig) withDefaults() WebhookConfig {
+	if c.Scheme == "" {
+		c.Scheme = WebhookSchemeHMAC
+	}
+	if c.SignatureHeader == "" {
+		switch c.Scheme {
+		case WebhookSchemeStripe:
+			c.SignatureHeader = "Stripe-Signature"
+		case WebhookSchemeGitHub:
+			c.SignatureHeader = "X-Hub-Signature-256"
+		default:
+			c.SignatureHeader = "X-Signature"
+		}
+	}
+	if c.TimestampHeader == "" {
+		c.TimestampHeader = "X-Timestamp"
+	}
+	if c.Encoding == "" {
+		c.Encoding = "hex"
+	}
+	if c.Tolerance == 0 {
+		c.Tolerance = DefaultWebhookTolerance
+	}
+	if c.Now == nil {
+		c.Now = time.Now
+	}
+	return c
+}
+
+// WebhookSignature 创建 Webhook 签名校验中间件
+// 校验失败返回 401；未配置密钥时拒绝全部请求
+func WebhookSignature(cfg WebhookConfig) fiber.Handler {
+	cfg = cfg.withDefaults()
+	return func(c fiber.Ctx) error {
+		// BodyRaw 引用 fasthttp 缓冲区，复制后供 handler 在解析后继续使用
+		body := append([]byte(nil), c.BodyRaw()...)
+		if err := verifyWebhook(cfg, c.Get(cfg.SignatureHeader), c.Get(cfg.TimestampHeader), body); err != nil {
+			return response.Error(c, errors.New(errors.ErrCodeUnauthenticated, "invalid webhook signature: "+err.Error()))
+		}
+		c.Locals(webhookRawBodyLocalKey, body)
+		return c.Next()
+	}
+}
+
+// WebhookRawBody 读取签名校验时捕获的原始请求体，未经过 WebhookSignature 时返回 c.BodyRaw()
+func WebhookRawBody(c fiber.Ctx) []byte {
+	if body, ok := c.Locals(webhookRawBodyLocalKey).([]byte); ok {
+		return body
+	}
+	return c.BodyRaw()
+}
+
+// SignWebhook 按配置生成签名头的值（用于测试或向下游发送 Webhook）
+// hmac 方案 timestamp 为零值时仅对请求体签名（不发送时间戳头，接收方需 Tolerance < 0）
+func SignWebhook(cfg WebhookConfig, secret string, timestamp time.Time, body []byte) string {
+	cfg = cfg.withDefaults()
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	if timestamp.IsZero() && cfg.Scheme == WebhookSchemeHMAC {
+		ts = ""
+	}
+	switch cfg.Scheme {
+	case WebhookSchemeStripe:
+		return "t=" + ts + ",v1=" + hex.EncodeToString(webhookMAC(secret, ts, body))
+	case WebhookSchemeGitHub:
+		return "sha256=" + hex.EncodeToString(webhookMAC(secret, "", body))
+	default:
+		return cfg.SignaturePrefix + encodeWebhookMAC(cfg.Encoding, webhookMAC(secret, ts, body))
+	}
+}
+
+// verifyWebhook 校验签名
+func verifyWebhook(cfg WebhookConfig, header, timestamp string, body []byte) error {
+	if len(cfg.Secrets) == 0 {
+		return fmt.Errorf("no secret configured")
+	}
+	if header == "" {
+		return fmt.Errorf("missing %s header", cfg.SignatureHeader)
+	}
+
+	var candidates [][]byte
+	switch cfg.Scheme {
+	case WebhookSchemeStripe:
+		timestamp = ""
+		for _, part := range strings.Split(header, ",") {
+			k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+			switch k {
+			case "t":
+				timestamp = v
+			case "v1":
+				if sig, err := hex.DecodeString(v); err == nil {
+					candidates = append(candidates, sig)
+				}
+			}
+		}
+	case WebhookSchemeGitHub:
+		sig, err := hex.DecodeString(strings.TrimPrefix(header, "sha256="))
+		if err != nil {
+			return fmt.Errorf("malformed signature")
+		}
+		candidates = append(candidates, sig)
+		timestamp = ""
+	case WebhookSchemeHMAC:
+		sig, err := decodeWebhookMAC(cfg.Encoding, strings.TrimPrefix(header, cfg.SignaturePrefix))
+		if err != nil {
+			return fmt.Errorf("malformed signature")
+		}
+		candidates = append(candidates, sig)
+	default:
+		return fmt.Errorf("unsupported scheme %q", cfg.Scheme)
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("malformed signature")
+	}
+
+	// hmac 方案不校验重放时时间戳可省略，省略时仅对请求体签名
+	optional := cfg.Scheme == WebhookSchemeHMAC && cfg.Tolerance < 0
+	if cfg.Scheme != WebhookSchemeGitHub && (timestamp != "" || !optional) {
+		if timestamp == "" {
+			return fmt.Errorf("missing timestamp")
+		}
+		if cfg.Tolerance > 0 {
+			ts, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				return fmt.Errorf("malformed timestamp")
+			}
+			if d := cfg.Now().Sub(time.Unix(ts, 0)); d > cfg.Tolerance || d < -cfg.Tolerance {
+				return fmt.Errorf("timestamp outside tolerance")
+			}
+		}
+	}
+
+	for _, secret := range cfg.Secrets {
+		expected := webhookMAC(secret, timestamp, body)
+		for _, sig := range candidates {
+			if hmac.Equal(expected, sig) {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("signature mismatch")
+}
+
+// webhookMAC 计算 HMAC-SHA256，timestamp 非空时签名内容为 "{timestamp}.{body}"
+func webhookMAC(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	if timestamp != "" {
+		mac.Write([]byte(timestamp))
+		mac.Write([]byte("."))
+	}
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func encodeWebhookMAC(encoding string, sum []byte) string {
+	if encoding == "base64" {
+		return base64.StdEncoding.EncodeToString(sum)
+	}
+	return hex.EncodeToString(sum)
+}
+
+func decodeWebhookMAC(encoding, s string) ([]byte, error) {
+	if encoding == "base64" {
+		return base64.StdEncoding.DecodeString(s)
+	}
+	return hex.DecodeString(s)
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	now := time.Unix(1767225600, 0)
+	clock := func() time.Time { return now }
+	body := `{"id":"evt_1","type":"payment.succeeded"}`
+
+	schemes := map[string]WebhookConfig{
+		"/hmac":   {Scheme: WebhookSchemeHMAC, Secrets: []string{"old", "new"}, SignaturePrefix: "sha256=", Now: clock},
+		"/base64": {Scheme: WebhookSchemeHMAC, Secrets: []string{"new"}, Encoding: "base64", Now: clock},
+		"/stripe": {Scheme: WebhookSchemeStripe, Secrets: []string{"new"}, Now: clock},
+		"/github": {Scheme: WebhookSchemeGitHub, Secrets: []string{"new"}, Now: clock},
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: response.NewFiberErrorHandler(logger.NewNop())})
+	for path, cfg := range schemes {
+		app.Post(path, WebhookSignature(cfg), func(c fiber.Ctx) error {
+			var payload map[string]any
+			if err := c.Bind().JSON(&payload); err != nil {
+				return err
+			}
+			return c.Send(WebhookRawBody(c))
+		})
+	}
+
+	send := func(path, sig string, ts time.Time, payload string) (int, string) {
+		t.Helper()
+		cfg := schemes[path].withDefaults()
+		req := httptest.NewRequest("POST", path, strings.NewReader(payload))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		req.Header.Set(cfg.SignatureHeader, sig)
+		req.Header.Set(cfg.TimestampHeader, strconv.FormatInt(ts.Unix(), 10))
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return resp.StatusCode, string(data)
+	}
+
+	for path, cfg := range schemes {
+		sig := SignWebhook(cfg, "new", now, []byte(body))
+		if status, got := send(path, sig, now, body); status != fiber.StatusOK || got != body {
+			t.Fatalf("%s: expected valid signature to pass, got %d %s", path, status, got)
+		}
+		if status, _ := send(path, sig, now, body+" "); status != fiber.StatusUnauthorized {
+			t.Fatalf("%s: expected tampered body to fail, got %d", path, status)
+		}
+		if status, _ := send(path, SignWebhook(cfg, "wrong", now, []byte(body)), now, body); status != fiber.StatusUnauthorized {
+			t.Fatalf("%s: expected wrong secret to fail, got %d", path, status)
+		}
+	}
+
+	// 轮换期间旧密钥仍可用
+	if status, _ := send("/hmac", SignWebhook(schemes["/hmac"], "old", now, []byte(body)), now, body); status != fiber.StatusOK {
+		t.Fatalf("expected previous secret to pass, got %d", status)
+	}
+
+	// 超出容忍窗口视为重放（github 方案无时间戳不受影响）
+	stale := now.Add(-10 * time.Minute)
+	for _, path := range []string{"/hmac", "/stripe"} {
+		if status, _ := send(path, SignWebhook(schemes[path], "new", stale, []byte(body)), stale, body); status != fiber.StatusUnauthorized {
+			t.Fatalf("%s: expected stale timestamp to fail, got %d", path, status)
+		}
+	}
+	if status, _ := send("/stripe", "t=1,v1=zz", now, body); status != fiber.StatusUnauthorized {
+		t.Fatalf("expected malformed stripe header to fail, got %d", status)
+	}
+
+	// 不校验重放时 hmac 方案可省略时间戳，仅对请求体签名
+	lax := WebhookConfig{Scheme: WebhookSchemeHMAC, Secrets: []string{"new"}, Tolerance: -1, Now: clock}
+	app.Post("/lax", WebhookSignature(lax), func(c fiber.Ctx) error {
+		return c.Send(WebhookRawBody(c))
+	})
+	postLax := func(sig, ts string) int {
+		t.Helper()
+		cfg := lax.withDefaults()
+		req := httptest.NewRequest("POST", "/lax", strings.NewReader(body))
+		req.Header.Set(cfg.SignatureHeader, sig)
+		if ts != "" {
+			req.Header.Set(cfg.TimestampHeader, ts)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	if status := postLax(SignWebhook(lax, "new", time.Time{}, []byte(body)), ""); status != fiber.StatusOK {
+		t.Fatalf("expected body-only signature to pass, got %d", status)
+	}
+	if status := postLax(SignWebhook(lax, "new", stale, []byte(body)), strconv.FormatInt(stale.Unix(), 10)); status != fiber.StatusOK {
+		t.Fatalf("expected stale timestamp to pass without tolerance, got %d", status)
+	}
+	if status := postLax(SignWebhook(lax, "new", now, []byte(body)), ""); status != fiber.StatusUnauthorized {
+		t.Fatalf("expected timestamped signature without header to fail, got %d", status)
+	}
+	if status, _ := send("/hmac", SignWebhook(schemes["/hmac"], "new", time.Time{}, []byte(body)), time.Time{}, body); status != fiber.StatusUnauthorized {
+		t.Fatalf("expected body-only signature to fail with tolerance, got %d", status)
+	}
+}
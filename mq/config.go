@@ -5,12 +5,12 @@ import "time"
 /* ========================================================================
  * MQ 统一配置
  * ========================================================================
- * 职责: 定义 RocketMQ 和 Kafka 的统一配置结构
+ * 职责: 定义 RocketMQ、Kafka 与 Redis Streams 的统一配置结构
  * ======================================================================== */
 
 // Config MQ 统一配置
 type Config struct {
-	// Type MQ 类型: rocketmq / kafka
+	// Type MQ 类型: rocketmq / kafka / redis / memory（测试）
 	Type Type `yaml:"type" mapstructure:"type"`
 
 	// RocketMQ 特有配置
@@ -18,6 +18,15 @@ type Config struct {
 
 	// Kafka 特有配置
 	Kafka *KafkaConfig `yaml:"kafka" mapstructure:"kafka"`
+
+	// Redis Streams 特有配置
+	Redis *RedisStreamConfig `yaml:"redis" mapstructure:"redis"`
+
+	// Payload 消息体压缩与加密（Fx 模块自动应用，见 payload.go）
+	Payload PayloadConfig `yaml:"payload" mapstructure:"payload"`
+
+	// Spill Broker 不可用时的本地落盘缓冲（Fx 模块自动应用，见 spill.go）
+	Spill SpillConfig `yaml:"spill" mapstructure:"spill"`
 }
 
 // DefaultConfig 返回默认配置
@@ -26,6 +35,7 @@ func DefaultConfig() *Config {
 		Type:     TypeRocketMQ,
 		RocketMQ: DefaultRocketMQConfig(),
 		Kafka:    DefaultKafkaConfig(),
+		Redis:    DefaultRedisStreamConfig(),
 	}
 }
 
@@ -52,6 +62,11 @@ type RocketMQProducerConfig struct {
 	RetryTimesOnFailed int           `yaml:"retry_times_on_failed" mapstructure:"retry_times_on_failed"`
 	MaxMessageSize     int           `yaml:"max_message_size" mapstructure:"max_message_size"`
 	CompressLevel      int           `yaml:"compress_level" mapstructure:"compress_level"`
+
+	// DelayMode Message.DelayTime 映射方式: timer（5.x 定时消息，默认）/ level（4.x 延迟级别向上取整）
+	DelayMode string `yaml:"delay_mode" mapstructure:"delay_mode"`
+	// DelayLevels level 模式使用的级别表，需与 Broker messageDelayLevel 一致，默认 1s 5s 10s ... 2h
+	DelayLevels []time.Duration `yaml:"delay_levels" mapstructure:"delay_levels"`
 }
 
 // RocketMQConsumerConfig RocketMQ 消费者配置
@@ -76,6 +91,7 @@ func DefaultRocketMQConfig() *RocketMQConfig {
 			RetryTimesOnFailed: 2,
 			MaxMessageSize:     4 * 1024 * 1024,
 			CompressLevel:      5,
+			DelayMode:          "timer",
 		},
 		Consumer: RocketMQConsumerConfig{
 			GroupName:              "default_consumer_group",
@@ -133,6 +149,22 @@ type KafkaProducerConfig struct {
 	Compression     string        `yaml:"compression" mapstructure:"compression"` // none / gzip / snappy / lz4 / zstd
 	Idempotent      bool          `yaml:"idempotent" mapstructure:"idempotent"`
 	RetryMax        int           `yaml:"retry_max" mapstructure:"retry_max"`
+	// Partitioner 分区器: hash（默认，FNV-1a）/ murmur2（与 Java 客户端一致）/ random / round_robin / manual
+	// 消息通过 Message.Partition 指定分区时总是优先使用指定值
+	Partitioner string `yaml:"partitioner" mapstructure:"partitioner"`
+
+	// Flush 异步发送的批量触发条件（任一满足即发送），仅作用于 SendAsync
+	Flush KafkaFlushConfig `yaml:"flush" mapstructure:"flush"`
+	// ChannelBufferSize 内部 channel 缓冲大小，0 使用 sarama 默认值 256
+	ChannelBufferSize int `yaml:"channel_buffer_size" mapstructure:"channel_buffer_size"`
+}
+
+// KafkaFlushConfig Kafka 异步生产者批量配置，0 表示不按该条件触发（sarama 默认）
+type KafkaFlushConfig struct {
+	Bytes       int           `yaml:"bytes" mapstructure:"bytes"`               // 累计字节数
+	Messages    int           `yaml:"messages" mapstructure:"messages"`         // 累计消息数
+	Frequency   time.Duration `yaml:"frequency" mapstructure:"frequency"`       // 最长等待时间
+	MaxMessages int           `yaml:"max_messages" mapstructure:"max_messages"` // 单批最大消息数，0 不限制
 }
 
 // KafkaConsumerConfig Kafka 消费者配置
@@ -148,6 +180,20 @@ type KafkaConsumerConfig struct {
 	FetchMin           int32         `yaml:"fetch_min" mapstructure:"fetch_min"`
 	FetchMax           int32         `yaml:"fetch_max" mapstructure:"fetch_max"`
 	FetchDefault       int32         `yaml:"fetch_default" mapstructure:"fetch_default"`
+
+	// Priority 多主题优先级消费，订阅时通过 mq.WithPriority 声明优先级后生效
+	Priority KafkaPriorityConfig `yaml:"priority" mapstructure:"priority"`
+
+	// InstanceID 实例标识，广播订阅使用消费组 <GroupID>-broadcast-<InstanceID>，默认 hostname-pid
+	InstanceID string `yaml:"instance_id" mapstructure:"instance_id"`
+}
+
+// KafkaPriorityConfig Kafka 优先级消费配置
+// 任一订阅声明非 0 优先级时，所有主题的消息共享 Concurrency 个处理槽，
+// 空闲槽优先分配给高优先级主题；低优先级消息最多被插队 MaxStarvation 次
+type KafkaPriorityConfig struct {
+	Concurrency   int `yaml:"concurrency" mapstructure:"concurrency"`       // 并发处理槽数，默认 1
+	MaxStarvation int `yaml:"max_starvation" mapstructure:"max_starvation"` // 最大插队次数，默认 10
 }
 
 // DefaultKafkaConfig 返回 Kafka 默认配置
@@ -162,6 +208,7 @@ func DefaultKafkaConfig() *KafkaConfig {
 			Compression:     "none",
 			Idempotent:      false,
 			RetryMax:        3,
+			Partitioner:     "hash",
 		},
 		Consumer: KafkaConsumerConfig{
 			GroupID:            "default_consumer_group",
@@ -175,6 +222,60 @@ func DefaultKafkaConfig() *KafkaConfig {
 			FetchMin:           1,
 			FetchMax:           10485760,
 			FetchDefault:       1048576,
+			Priority: KafkaPriorityConfig{
+				Concurrency:   1,
+				MaxStarvation: 10,
+			},
+		},
+	}
+}
+
+// =============================================================================
+// Redis Streams 配置
+// =============================================================================
+
+// RedisStreamConfig Redis Streams 配置
+type RedisStreamConfig struct {
+	Addrs    []string `yaml:"addrs" mapstructure:"addrs"` // 单机填一个地址，多个地址按集群连接
+	Password string   `yaml:"password" mapstructure:"password"`
+	DB       int      `yaml:"db" mapstructure:"db"`
+
+	// KeyPrefix Stream Key 前缀，Stream Key = KeyPrefix + Topic
+	KeyPrefix string `yaml:"key_prefix" mapstructure:"key_prefix"`
+	// MaxLen 每个 Stream 保留的近似最大长度（XADD MAXLEN ~），0 表示不裁剪
+	MaxLen int64 `yaml:"max_len" mapstructure:"max_len"`
+
+	Consumer RedisStreamConsumerConfig `yaml:"consumer" mapstructure:"consumer"`
+}
+
+// RedisStreamConsumerConfig Redis Streams 消费者配置
+type RedisStreamConsumerConfig struct {
+	Group            string        `yaml:"group" mapstructure:"group"`
+	Name             string        `yaml:"name" mapstructure:"name"`                             // 组内消费者名称，默认 hostname
+	StartID          string        `yaml:"start_id" mapstructure:"start_id"`                     // 新建消费组的起始位置: $（仅新消息）/ 0（全部）
+	BatchSize        int64         `yaml:"batch_size" mapstructure:"batch_size"`                 // 每次读取条数
+	Block            time.Duration `yaml:"block" mapstructure:"block"`                           // 无消息时阻塞等待时长
+	ClaimMinIdle     time.Duration `yaml:"claim_min_idle" mapstructure:"claim_min_idle"`         // 未确认消息空闲超过该时长后被认领重投
+	ClaimInterval    time.Duration `yaml:"claim_interval" mapstructure:"claim_interval"`         // 认领检查间隔
+	MaxRetries       int64         `yaml:"max_retries" mapstructure:"max_retries"`               // 超过投递次数后转入死信 Stream
+	DeadLetterSuffix string        `yaml:"dead_letter_suffix" mapstructure:"dead_letter_suffix"` // 死信 Stream 后缀，默认 ":dlq"
+}
+
+// DefaultRedisStreamConfig 返回 Redis Streams 默认配置
+func DefaultRedisStreamConfig() *RedisStreamConfig {
+	return &RedisStreamConfig{
+		Addrs:     []string{"127.0.0.1:6379"},
+		KeyPrefix: "mq:",
+		MaxLen:    100000,
+		Consumer: RedisStreamConsumerConfig{
+			Group:            "default_consumer_group",
+			StartID:          "$",
+			BatchSize:        16,
+			Block:            time.Second,
+			ClaimMinIdle:     30 * time.Second,
+			ClaimInterval:    5 * time.Second,
+			MaxRetries:       16,
+			DeadLetterSuffix: ":dlq",
 		},
 	}
 }
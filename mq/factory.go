@@ -53,7 +53,7 @@ func NewProducer(cfg *Config, logger *zap.Logger) (Producer, error) {
 	factory, ok := producerFactories[cfg.Type]
 	factoryMu.RUnlock()
 	if !ok {
-		return nil, fmt.Errorf("unsupported MQ type: %s, available: rocketmq, kafka", cfg.Type)
+		return nil, fmt.Errorf("unsupported MQ type: %s, available: rocketmq, kafka, redis", cfg.Type)
 	}
 
 	logger.Info("creating MQ producer",
@@ -76,7 +76,7 @@ func NewConsumer(cfg *Config, logger *zap.Logger) (Consumer, error) {
 	factory, ok := consumerFactories[cfg.Type]
 	factoryMu.RUnlock()
 	if !ok {
-		return nil, fmt.Errorf("unsupported MQ type: %s, available: rocketmq, kafka", cfg.Type)
+		return nil, fmt.Errorf("unsupported MQ type: %s, available: rocketmq, kafka, redis", cfg.Type)
 	}
 
 	logger.Info("creating MQ consumer",
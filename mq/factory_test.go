@@ -6,6 +6,8 @@ import (
 	"sort"
 	"testing"
 
+	"github.com/aisgo/ais-go-pkg/requestid"
+
 	"go.uber.org/zap"
 )
 
@@ -132,3 +134,18 @@ func TestFactoryPropagatesError(t *testing.T) {
 		t.Fatalf("unexpected error: %v", err)
 	}
 }
+
+func TestRequestIDPropagation(t *testing.T) {
+	ctx := requestid.WithContext(context.Background(), "req-1")
+	msg := NewMessage("orders", nil).WithProperty(requestid.CorrelationHeader, "explicit")
+	InjectRequestID(ctx, msg)
+	if msg.Properties[requestid.Header] != "req-1" || msg.Properties[requestid.CorrelationHeader] != "explicit" {
+		t.Fatalf("unexpected properties: %v", msg.Properties)
+	}
+
+	consumed := []*ConsumedMessage{{Properties: map[string]string{}}, {Properties: msg.Properties}}
+	got := ContextWithRequestID(context.Background(), consumed...)
+	if requestid.FromContext(got) != "req-1" || requestid.CorrelationIDFromContext(got) != "explicit" {
+		t.Fatalf("unexpected context ids: %q %q", requestid.FromContext(got), requestid.CorrelationIDFromContext(got))
+	}
+}
@@ -5,12 +5,18 @@ import (
 
 	"go.uber.org/fx"
 	"go.uber.org/zap"
+
+	"github.com/aisgo/ais-go-pkg/conf"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/utils/crypto"
 )
 
 /* ========================================================================
  * Fx 模块 - 统一 MQ 依赖注入
  * ========================================================================
  * 职责: 提供 Fx 依赖注入支持
+ * Bundle: 从 conf.Source 的 mq 节解码 *Config（缺省见 DefaultConfig），
+ *         并以 *logger.Logger 提供模块内部所需的 *zap.Logger
  * ======================================================================== */
 
 // Module Fx 模块（根据配置自动选择 RocketMQ 或 Kafka）
@@ -21,12 +27,24 @@ var Module = fx.Module("mq",
 	),
 )
 
+// Bundle 带配置解码的 MQ 模块
+// 依赖: conf.Source, *logger.Logger
+// 提供: *mq.Config, mq.Producer, mq.Consumer
+var Bundle = fx.Module("mq-bundle",
+	fx.Provide(conf.Section("mq", DefaultConfig)),
+	fx.Provide(fx.Private, func(l *logger.Logger) *zap.Logger { return l.Logger }),
+	Module,
+)
+
 // ProducerParams Producer 依赖参数
 type ProducerParams struct {
 	fx.In
 
 	Config *Config
 	Logger *zap.Logger
+
+	// Keyring 消息体加密密钥环，Config.Payload.Encrypt 为 true 时必须提供
+	Keyring *crypto.Keyring `optional:"true"`
 }
 
 // ProducerResult Producer 返回结果
@@ -42,9 +60,30 @@ func ProvideProducer(lc fx.Lifecycle, params ProducerParams) (ProducerResult, er
 	if err != nil {
 		return ProducerResult{}, err
 	}
+	if params.Config.Spill.Enabled {
+		spill, err := NewSpillProducer(producer, params.Config.Spill, params.Logger)
+		if err != nil {
+			_ = producer.Close()
+			return ProducerResult{}, err
+		}
+		producer = spill
+	}
+	if params.Config.Payload.Enabled() {
+		codec, err := NewPayloadCodec(params.Config.Payload, params.Keyring)
+		if err != nil {
+			_ = producer.Close()
+			return ProducerResult{}, err
+		}
+		producer = WrapProducer(producer, codec.ProducerMiddleware())
+	}
+	producer = WrapProducer(producer, DeadlineMiddleware())
 
 	lc.Append(fx.Hook{
 		OnStop: func(ctx context.Context) error {
+			// 先等待异步消息发送完成，超时则直接关闭
+			if err := Flush(ctx, producer); err != nil {
+				params.Logger.Warn("flush mq producer before close failed", zap.Error(err))
+			}
 			return producer.Close()
 		},
 	})
@@ -58,6 +97,12 @@ type ConsumerParams struct {
 
 	Config *Config
 	Logger *zap.Logger
+
+	// Subscriptions 启动前统一订阅的提供者，见 AsSubscriptionProvider
+	Subscriptions []SubscriptionProvider `group:"mq_subscriptions"`
+
+	// Keyring 消息体解密密钥环，消费加密消息时必须提供
+	Keyring *crypto.Keyring `optional:"true"`
 }
 
 // ConsumerResult Consumer 返回结果
@@ -67,21 +112,22 @@ type ConsumerResult struct {
 	Consumer Consumer
 }
 
-// ProvideConsumer 提供 Consumer（用于 Fx）
+// ProvideConsumer 提供 Consumer（用于 Fx），生命周期见 ManageConsumer
 func ProvideConsumer(lc fx.Lifecycle, params ConsumerParams) (ConsumerResult, error) {
 	consumer, err := NewConsumer(params.Config, params.Logger)
 	if err != nil {
 		return ConsumerResult{}, err
 	}
+	if params.Config.Payload.Enabled() || params.Keyring != nil {
+		codec, err := NewPayloadCodec(params.Config.Payload, params.Keyring)
+		if err != nil {
+			_ = consumer.Close()
+			return ConsumerResult{}, err
+		}
+		consumer = WrapConsumer(consumer, codec.ConsumerMiddleware())
+	}
 
-	lc.Append(fx.Hook{
-		OnStart: func(ctx context.Context) error {
-			return consumer.Start()
-		},
-		OnStop: func(ctx context.Context) error {
-			return consumer.Close()
-		},
-	})
+	ManageConsumer(lc, consumer, params.Subscriptions, params.Logger)
 
 	return ConsumerResult{Consumer: consumer}, nil
 }
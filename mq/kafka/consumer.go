@@ -3,7 +3,10 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/IBM/sarama"
@@ -17,6 +20,9 @@ import (
  * ========================================================================
  * 职责: 实现 mq.Consumer 接口
  * 技术: IBM/sarama
+ * 优先级: 订阅声明 mq.WithPriority 后按主题优先级共享处理槽，见 priority.go
+ * 广播: mq.WithBroadcast 订阅的主题由独立消费组（<group>-broadcast-<instance_id>）
+ *       消费，该组从最新位点开始，Start / WaitReady / Stats / Close 与主消费组一并处理
  * ======================================================================== */
 
 // 消费者配置常量
@@ -29,6 +35,12 @@ const (
 // 注册工厂
 // =============================================================================
 
+var (
+	_ mq.ReadyWaiter      = (*ConsumerAdapter)(nil)
+	_ mq.OptionSubscriber = (*ConsumerAdapter)(nil)
+	_ mq.StatsProvider    = (*ConsumerAdapter)(nil)
+)
+
 func init() {
 	mq.RegisterConsumerFactory(mq.TypeKafka, NewConsumerAdapter)
 }
@@ -39,16 +51,37 @@ func init() {
 
 // ConsumerAdapter Kafka 消费者适配器
 type ConsumerAdapter struct {
-	client    sarama.ConsumerGroup
-	logger    *zap.Logger
-	config    *mq.KafkaConfig
-	handlers  map[string]mq.MessageHandler
-	topics    []string
-	cancel    context.CancelFunc
-	wg        sync.WaitGroup
-	mu        sync.RWMutex
-	ready     chan struct{}
-	readyOnce sync.Once
+	client      sarama.ConsumerGroup
+	logger      *zap.Logger
+	config      *mq.KafkaConfig
+	handlers    map[string]mq.MessageHandler
+	priority    map[string]int
+	broadcast   map[string]bool
+	topics      []string
+	scheduler   *priorityScheduler // 任一订阅声明优先级时启用
+	broadcaster *ConsumerAdapter   // 广播订阅的独立消费组，Start 时按需创建
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+	mu          sync.RWMutex
+	ready       chan struct{}
+	readyOnce   sync.Once
+	closed      bool
+
+	// 当前会话的分区分配与消费位点，用于 Stats
+	statsMu    sync.RWMutex
+	memberID   string
+	partitions map[topicPartition]*partitionState
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+// partitionState 分区消费状态
+type partitionState struct {
+	offset atomic.Int64 // 下一条待消费消息的位点，-1 表示未知
+	claim  atomic.Pointer[sarama.ConsumerGroupClaim]
 }
 
 // NewConsumerAdapter 创建 Kafka 消费者适配器
@@ -57,8 +90,10 @@ func NewConsumerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Consumer, error)
 		return nil, fmt.Errorf("kafka config is required")
 	}
 
-	kafkaCfg := cfg.Kafka
+	return newConsumerAdapter(cfg.Kafka, logger)
+}
 
+func newConsumerAdapter(kafkaCfg *mq.KafkaConfig, logger *zap.Logger) (*ConsumerAdapter, error) {
 	// 构建 Sarama 配置
 	saramaCfg, err := buildConsumerConfig(kafkaCfg)
 	if err != nil {
@@ -77,20 +112,29 @@ func NewConsumerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Consumer, error)
 	)
 
 	return &ConsumerAdapter{
-		client:   client,
-		logger:   logger,
-		config:   kafkaCfg,
-		handlers: make(map[string]mq.MessageHandler),
-		topics:   make([]string, 0),
-		ready:    make(chan struct{}),
+		client:    client,
+		logger:    logger,
+		config:    kafkaCfg,
+		handlers:  make(map[string]mq.MessageHandler),
+		priority:  make(map[string]int),
+		broadcast: make(map[string]bool),
+		topics:    make([]string, 0),
+		ready:     make(chan struct{}),
 	}, nil
 }
 
 // Subscribe 订阅主题
 func (c *ConsumerAdapter) Subscribe(topic string, handler mq.MessageHandler) error {
+	return c.SubscribeWithOptions(topic, handler)
+}
+
+// SubscribeWithOptions 按选项订阅主题（实现 mq.OptionSubscriber）
+// 优先级与消费模式需在 Start 之前声明
+func (c *ConsumerAdapter) SubscribeWithOptions(topic string, handler mq.MessageHandler, opts ...mq.SubscribeOption) error {
 	if handler == nil {
 		return fmt.Errorf("handler is required")
 	}
+	o := mq.ApplySubscribeOptions(opts...)
 
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -99,8 +143,14 @@ func (c *ConsumerAdapter) Subscribe(topic string, handler mq.MessageHandler) err
 		c.topics = append(c.topics, topic)
 	}
 	c.handlers[topic] = handler
+	c.priority[topic] = o.Priority
+	c.broadcast[topic] = o.Broadcast()
 
-	c.logger.Info("subscribed to topic", zap.String("topic", topic))
+	c.logger.Info("subscribed to topic",
+		zap.String("topic", topic),
+		zap.Int("priority", o.Priority),
+		zap.Bool("broadcast", o.Broadcast()),
+	)
 	return nil
 }
 
@@ -110,23 +160,47 @@ func (c *ConsumerAdapter) signalReady() {
 	})
 }
 
-// Start 启动消费者
+// Start 启动消费者（广播订阅的消费组先于主消费组启动）
 func (c *ConsumerAdapter) Start() error {
 	c.mu.Lock()
-	if c.cancel != nil {
+	if c.cancel != nil || c.broadcaster != nil {
 		c.mu.Unlock()
 		return fmt.Errorf("consumer already started")
 	}
-	topics := append([]string(nil), c.topics...)
+	var topics, broadcastTopics []string
+	for _, topic := range c.topics {
+		if c.broadcast[topic] {
+			broadcastTopics = append(broadcastTopics, topic)
+		} else {
+			topics = append(topics, topic)
+		}
+	}
+	c.scheduler = nil
+	for topic, p := range c.priority {
+		if p != 0 && !c.broadcast[topic] {
+			c.scheduler = newPriorityScheduler(c.config.Consumer.Priority.Concurrency, c.config.Consumer.Priority.MaxStarvation)
+			break
+		}
+	}
 	c.ready = make(chan struct{})
 	c.readyOnce = sync.Once{}
 	readyCh := c.ready
 	c.mu.Unlock()
 
-	if len(topics) == 0 {
+	if len(topics) == 0 && len(broadcastTopics) == 0 {
 		return fmt.Errorf("no topics subscribed")
 	}
 
+	if len(broadcastTopics) > 0 {
+		if err := c.startBroadcast(broadcastTopics); err != nil {
+			return err
+		}
+	}
+	if len(topics) == 0 {
+		c.signalReady()
+		return nil
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	c.mu.Lock()
 	c.cancel = cancel
@@ -180,17 +254,144 @@ func (c *ConsumerAdapter) Start() error {
 	case err := <-startErr:
 		cancel()
 		c.wg.Wait()
+		c.stopBroadcast()
 		return err
 	case <-time.After(30 * time.Second):
 		cancel()
 		c.wg.Wait()
+		c.stopBroadcast()
 		return fmt.Errorf("kafka consumer start timeout")
 	}
 }
 
+// startBroadcast 以独立消费组启动广播订阅
+func (c *ConsumerAdapter) startBroadcast(topics []string) error {
+	cfg := broadcastConfig(c.config)
+	child, err := newConsumerAdapter(cfg, c.logger.With(zap.String("model", string(mq.ModelBroadcasting))))
+	if err != nil {
+		return fmt.Errorf("failed to create broadcast consumer: %w", err)
+	}
+
+	c.mu.RLock()
+	for _, topic := range topics {
+		_ = child.SubscribeWithOptions(topic, c.handlers[topic], mq.WithPriority(c.priority[topic]))
+	}
+	c.mu.RUnlock()
+
+	if err := child.Start(); err != nil {
+		_ = child.Close()
+		return fmt.Errorf("failed to start broadcast consumer: %w", err)
+	}
+
+	c.mu.Lock()
+	c.broadcaster = child
+	c.mu.Unlock()
+	return nil
+}
+
+// stopBroadcast 关闭广播消费组
+func (c *ConsumerAdapter) stopBroadcast() error {
+	c.mu.Lock()
+	child := c.broadcaster
+	c.broadcaster = nil
+	c.mu.Unlock()
+	if child == nil {
+		return nil
+	}
+	return child.Close()
+}
+
+// WaitReady 等待消费组完成分区分配（实现 mq.ReadyWaiter）
+func (c *ConsumerAdapter) WaitReady(ctx context.Context) error {
+	c.mu.RLock()
+	readyCh := c.ready
+	child := c.broadcaster
+	c.mu.RUnlock()
+
+	select {
+	case <-readyCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if child != nil {
+		return child.WaitReady(ctx)
+	}
+	return nil
+}
+
+// Stats 返回当前会话的分区分配、位点与 lag（实现 mq.StatsProvider）
+func (c *ConsumerAdapter) Stats() mq.ConsumerStats {
+	c.statsMu.RLock()
+	stats := mq.ConsumerStats{
+		Type:       mq.TypeKafka,
+		Group:      c.config.Consumer.GroupID,
+		MemberID:   c.memberID,
+		Partitions: make([]mq.PartitionStats, 0, len(c.partitions)),
+	}
+	for tp, state := range c.partitions {
+		offset, hwm := state.offset.Load(), int64(-1)
+		if claim := state.claim.Load(); claim != nil {
+			hwm = (*claim).HighWaterMarkOffset()
+		}
+		stats.Partitions = append(stats.Partitions, mq.PartitionStats{
+			Topic:         tp.topic,
+			Partition:     tp.partition,
+			Offset:        offset,
+			HighWatermark: hwm,
+			Lag:           mq.PartitionLag(offset, hwm),
+		})
+	}
+	c.statsMu.RUnlock()
+
+	c.mu.RLock()
+	child := c.broadcaster
+	c.mu.RUnlock()
+	if child != nil {
+		stats.Partitions = append(stats.Partitions, child.Stats().Partitions...)
+	}
+
+	sort.Slice(stats.Partitions, func(i, j int) bool {
+		a, b := stats.Partitions[i], stats.Partitions[j]
+		if a.Topic != b.Topic {
+			return a.Topic < b.Topic
+		}
+		return a.Partition < b.Partition
+	})
+	stats.Topics = mq.SummarizeTopics(stats.Partitions)
+	return stats
+}
+
+// resetAssignment 按新会话的分区分配重置统计状态，claims 为 nil 时清空
+func (c *ConsumerAdapter) resetAssignment(memberID string, claims map[string][]int32) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+
+	c.memberID = memberID
+	c.partitions = make(map[topicPartition]*partitionState)
+	for topic, partitions := range claims {
+		for _, partition := range partitions {
+			state := &partitionState{}
+			state.offset.Store(-1)
+			c.partitions[topicPartition{topic: topic, partition: partition}] = state
+		}
+	}
+}
+
+// partitionState 返回分区状态（不在当前分配中时返回 nil）
+func (c *ConsumerAdapter) partitionState(topic string, partition int32) *partitionState {
+	c.statsMu.RLock()
+	defer c.statsMu.RUnlock()
+	return c.partitions[topicPartition{topic: topic, partition: partition}]
+}
+
 // Close 关闭消费者
 func (c *ConsumerAdapter) Close() error {
 	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
 	cancel := c.cancel
 	c.cancel = nil
 	c.mu.Unlock()
@@ -200,10 +401,14 @@ func (c *ConsumerAdapter) Close() error {
 
 	c.wg.Wait()
 
+	broadcastErr := c.stopBroadcast()
 	if err := c.client.Close(); err != nil {
 		c.logger.Error("failed to close consumer", zap.Error(err))
 		return err
 	}
+	if broadcastErr != nil {
+		return broadcastErr
+	}
 
 	c.logger.Info("Kafka consumer closed")
 	return nil
@@ -218,6 +423,7 @@ type consumerGroupHandler struct {
 }
 
 func (h *consumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	h.adapter.resetAssignment(session.MemberID(), session.Claims())
 	h.adapter.signalReady()
 	h.adapter.logger.Debug("consumer group setup",
 		zap.Int32("generation_id", session.GenerationID()),
@@ -226,6 +432,7 @@ func (h *consumerGroupHandler) Setup(session sarama.ConsumerGroupSession) error
 }
 
 func (h *consumerGroupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	h.adapter.resetAssignment("", nil)
 	h.adapter.logger.Debug("consumer group cleanup",
 		zap.Int32("generation_id", session.GenerationID()),
 	)
@@ -237,6 +444,8 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 
 	h.adapter.mu.RLock()
 	handler, ok := h.adapter.handlers[topic]
+	priority := h.adapter.priority[topic]
+	scheduler := h.adapter.scheduler
 	h.adapter.mu.RUnlock()
 
 	if !ok {
@@ -244,6 +453,20 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 		return nil
 	}
 
+	state := h.adapter.partitionState(topic, claim.Partition())
+	if state != nil {
+		state.claim.Store(&claim)
+		if initial := claim.InitialOffset(); initial >= 0 {
+			state.offset.Store(initial)
+		}
+	}
+
+	var src *prioritySource
+	if scheduler != nil {
+		src = scheduler.register(priority, func() int { return len(claim.Messages()) })
+		defer scheduler.unregister(src)
+	}
+
 	for {
 		select {
 		case msg, ok := <-claim.Messages():
@@ -251,58 +474,20 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 				return nil
 			}
 
-			// 转换消息
-			convertedMsg := convertFromKafkaMessage(msg)
-
-			// 带重试的消息处理
-			var lastErr error
-			var finalResult mq.ConsumeResult
-
-			for retry := 0; retry < defaultMaxRetries; retry++ {
-				result, err := handler(session.Context(), []*mq.ConsumedMessage{convertedMsg})
-				if err == nil && result != mq.ConsumeRetryLater {
-					finalResult = result
-					lastErr = nil
-					break
-				}
-				if err == nil {
-					err = fmt.Errorf("consume retry later")
-				}
-				lastErr = err
-
-				h.adapter.logger.Warn("message handling failed, retrying",
-					zap.String("topic", topic),
-					zap.Int32("partition", msg.Partition),
-					zap.Int64("offset", msg.Offset),
-					zap.Int("retry", retry+1),
-					zap.Int("max_retries", defaultMaxRetries),
-					zap.Error(err),
-				)
-
-				// 指数退避
-				select {
-				case <-session.Context().Done():
+			if src != nil {
+				if scheduler.acquire(session.Context(), src) != nil {
 					return nil
-				case <-time.After(defaultRetryBaseDelay * time.Duration(retry+1)):
 				}
 			}
-
-			if lastErr != nil {
-				h.adapter.logger.Error("message handling failed after all retries, stopping consumer to prevent data loss",
-					zap.String("topic", topic),
-					zap.Int32("partition", msg.Partition),
-					zap.Int64("offset", msg.Offset),
-					zap.Error(lastErr),
-				)
-				// 返回错误给 Sarama，这将停止当前分区的消费并触发重平衡
-				// 确保 offset 不会被错误地提交
-				return lastErr
+			stop, err := h.handleMessage(session, msg, handler)
+			if src != nil {
+				scheduler.release(src)
 			}
-
-			// 只有成功处理才标记消息已消费
-			session.MarkMessage(msg, "")
-			if finalResult == mq.ConsumeCommit {
-				session.Commit()
+			if err == nil && !stop && state != nil {
+				state.offset.Store(msg.Offset + 1)
+			}
+			if stop || err != nil {
+				return err
 			}
 
 		case <-session.Context().Done():
@@ -311,10 +496,91 @@ func (h *consumerGroupHandler) ConsumeClaim(session sarama.ConsumerGroupSession,
 	}
 }
 
+// handleMessage 带重试处理单条消息，返回 true 表示应结束当前 claim
+func (h *consumerGroupHandler) handleMessage(session sarama.ConsumerGroupSession, msg *sarama.ConsumerMessage, handler mq.MessageHandler) (bool, error) {
+	topic := msg.Topic
+
+	// 转换消息
+	convertedMsg := convertFromKafkaMessage(msg)
+	msgCtx := mq.ContextWithRequestID(session.Context(), convertedMsg)
+
+	// 带重试的消息处理
+	var lastErr error
+	var finalResult mq.ConsumeResult
+
+	for retry := 0; retry < defaultMaxRetries; retry++ {
+		result, err := handler(msgCtx, []*mq.ConsumedMessage{convertedMsg})
+		if err == nil && result != mq.ConsumeRetryLater {
+			finalResult = result
+			lastErr = nil
+			break
+		}
+		if err == nil {
+			err = fmt.Errorf("consume retry later")
+		}
+		lastErr = err
+
+		h.adapter.logger.Warn("message handling failed, retrying",
+			zap.String("topic", topic),
+			zap.Int32("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Int("retry", retry+1),
+			zap.Int("max_retries", defaultMaxRetries),
+			zap.Error(err),
+		)
+
+		// 指数退避
+		select {
+		case <-session.Context().Done():
+			return true, nil
+		case <-time.After(defaultRetryBaseDelay * time.Duration(retry+1)):
+		}
+	}
+
+	if lastErr != nil {
+		h.adapter.logger.Error("message handling failed after all retries, stopping consumer to prevent data loss",
+			zap.String("topic", topic),
+			zap.Int32("partition", msg.Partition),
+			zap.Int64("offset", msg.Offset),
+			zap.Error(lastErr),
+		)
+		// 返回错误给 Sarama，这将停止当前分区的消费并触发重平衡
+		// 确保 offset 不会被错误地提交
+		return true, lastErr
+	}
+
+	// 只有成功处理才标记消息已消费
+	session.MarkMessage(msg, "")
+	if finalResult == mq.ConsumeCommit {
+		session.Commit()
+	}
+	return false, nil
+}
+
 // =============================================================================
 // 辅助函数
 // =============================================================================
 
+// broadcastConfig 返回广播订阅使用的配置：实例独立的消费组，从最新位点开始
+func broadcastConfig(cfg *mq.KafkaConfig) *mq.KafkaConfig {
+	out := *cfg
+	out.Consumer.GroupID = fmt.Sprintf("%s-broadcast-%s", cfg.Consumer.GroupID, instanceID(cfg.Consumer.InstanceID))
+	out.Consumer.InitialOffset = "newest"
+	return &out
+}
+
+// instanceID 返回实例标识，未配置时为 hostname-pid
+func instanceID(configured string) string {
+	if configured != "" {
+		return configured
+	}
+	host, _ := os.Hostname()
+	if host == "" {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
 func buildConsumerConfig(cfg *mq.KafkaConfig) (*sarama.Config, error) {
 	saramaCfg := sarama.NewConfig()
 
@@ -0,0 +1,71 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+type fakeClaim struct {
+	topic     string
+	partition int32
+	initial   int64
+	hwm       int64
+}
+
+func (c *fakeClaim) Topic() string                            { return c.topic }
+func (c *fakeClaim) Partition() int32                         { return c.partition }
+func (c *fakeClaim) InitialOffset() int64                     { return c.initial }
+func (c *fakeClaim) HighWaterMarkOffset() int64               { return c.hwm }
+func (c *fakeClaim) Messages() <-chan *sarama.ConsumerMessage { return nil }
+
+func TestConsumerAdapterStats(t *testing.T) {
+	c := &ConsumerAdapter{
+		logger: zap.NewNop(),
+		config: &mq.KafkaConfig{Consumer: mq.KafkaConsumerConfig{GroupID: "jobs"}},
+	}
+	c.resetAssignment("member-1", map[string][]int32{"orders": {1, 0}, "audit": {0}})
+
+	var claim sarama.ConsumerGroupClaim = &fakeClaim{topic: "orders", partition: 0, initial: 5, hwm: 12}
+	state := c.partitionState("orders", 0)
+	state.claim.Store(&claim)
+	state.offset.Store(8)
+
+	stats := c.Stats()
+	if stats.Group != "jobs" || stats.MemberID != "member-1" || len(stats.Partitions) != 3 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	first := stats.Partitions[0]
+	if first.Topic != "audit" || first.Offset != -1 || first.Lag != -1 {
+		t.Fatalf("expected unknown audit partition first, got %+v", first)
+	}
+	orders := stats.Partitions[1]
+	if orders.Topic != "orders" || orders.Partition != 0 || orders.HighWatermark != 12 || orders.Lag != 4 {
+		t.Fatalf("unexpected orders partition: %+v", orders)
+	}
+	if len(stats.Topics) != 2 || stats.Topics[1].Topic != "orders" || stats.Topics[1].Lag != 4 || stats.Topics[1].Partitions != 2 {
+		t.Fatalf("unexpected topic summary: %+v", stats.Topics)
+	}
+
+	c.resetAssignment("", nil)
+	if got := c.Stats(); len(got.Partitions) != 0 {
+		t.Fatalf("expected empty assignment after cleanup, got %+v", got.Partitions)
+	}
+}
+
+func TestBroadcastConfig(t *testing.T) {
+	cfg := &mq.KafkaConfig{Consumer: mq.KafkaConsumerConfig{GroupID: "cache", InstanceID: "pod-1", InitialOffset: "oldest"}}
+	got := broadcastConfig(cfg)
+	if got.Consumer.GroupID != "cache-broadcast-pod-1" || got.Consumer.InitialOffset != "newest" {
+		t.Fatalf("unexpected broadcast config: %+v", got.Consumer)
+	}
+	if cfg.Consumer.GroupID != "cache" || cfg.Consumer.InitialOffset != "oldest" {
+		t.Fatalf("original config mutated: %+v", cfg.Consumer)
+	}
+	if id := instanceID(""); id == "" || id == instanceID("explicit") {
+		t.Fatalf("unexpected default instance id %q", id)
+	}
+}
@@ -0,0 +1,167 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+/* ========================================================================
+ * 分区器 - 按配置选择分区策略
+ * ========================================================================
+ * 职责: 将 KafkaProducerConfig.Partitioner 映射为 sarama 分区器
+ * 策略:
+ *   hash        sarama 默认 FNV-1a 哈希（历史行为）
+ *   murmur2     与 Java 客户端 DefaultPartitioner 一致
+ *               (toPositive(murmur2(key)) % n)，保证与 Java 服务共分区
+ *   random      随机分区
+ *   round_robin 轮询分区
+ *   manual      仅使用 Message.Partition，未指定时发送失败
+ * 语义: 无论选择哪种策略，Message.Partition 非空时都使用指定分区；
+ *       无 Key 的消息在哈希策略下随机分区
+ * ======================================================================== */
+
+// 分区器名称
+const (
+	PartitionerHash       = "hash"
+	PartitionerMurmur2    = "murmur2"
+	PartitionerRandom     = "random"
+	PartitionerRoundRobin = "round_robin"
+	PartitionerManual     = "manual"
+)
+
+// unassignedPartition 表示消息未指定分区（写入 sarama.ProducerMessage.Partition）
+const unassignedPartition int32 = -1
+
+// newPartitioner 返回配置对应的分区器构造函数
+func newPartitioner(name string) (sarama.PartitionerConstructor, error) {
+	var base sarama.PartitionerConstructor
+	switch strings.ToLower(name) {
+	case "", PartitionerHash:
+		base = sarama.NewHashPartitioner
+	case PartitionerMurmur2:
+		base = sarama.NewCustomPartitioner(
+			sarama.WithAbsFirst(),
+			sarama.WithCustomHashFunction(newMurmur2),
+		)
+	case PartitionerRandom:
+		base = sarama.NewRandomPartitioner
+	case PartitionerRoundRobin:
+		base = sarama.NewRoundRobinPartitioner
+	case PartitionerManual:
+		base = nil
+	default:
+		return nil, fmt.Errorf("unsupported kafka partitioner %q", name)
+	}
+
+	return func(topic string) sarama.Partitioner {
+		p := &explicitPartitioner{}
+		if base != nil {
+			p.base = base(topic)
+		}
+		return p
+	}, nil
+}
+
+// explicitPartitioner 优先使用消息指定的分区，否则委托给 base
+type explicitPartitioner struct {
+	base sarama.Partitioner // nil 表示 manual
+}
+
+var _ sarama.DynamicConsistencyPartitioner = (*explicitPartitioner)(nil)
+
+func (p *explicitPartitioner) Partition(msg *sarama.ProducerMessage, numPartitions int32) (int32, error) {
+	if msg.Partition != unassignedPartition || p.base == nil {
+		if msg.Partition < 0 || msg.Partition >= numPartitions {
+			return -1, fmt.Errorf("invalid partition %d for topic %s with %d partitions: %w",
+				msg.Partition, msg.Topic, numPartitions, sarama.ErrInvalidPartition)
+		}
+		return msg.Partition, nil
+	}
+	return p.base.Partition(msg, numPartitions)
+}
+
+func (p *explicitPartitioner) RequiresConsistency() bool {
+	return p.base == nil || p.base.RequiresConsistency()
+}
+
+// MessageRequiresConsistency 指定分区或按 Key 哈希时必须基于完整分区列表计算
+func (p *explicitPartitioner) MessageRequiresConsistency(msg *sarama.ProducerMessage) bool {
+	if msg.Partition != unassignedPartition || p.base == nil {
+		return true
+	}
+	if dc, ok := p.base.(sarama.DynamicConsistencyPartitioner); ok {
+		return dc.MessageRequiresConsistency(msg)
+	}
+	return p.base.RequiresConsistency()
+}
+
+// =============================================================================
+// murmur2（与 org.apache.kafka.common.utils.Utils.murmur2 一致）
+// =============================================================================
+
+type murmur2 struct {
+	buf []byte
+}
+
+func newMurmur2() hash.Hash32 {
+	return &murmur2{}
+}
+
+func (h *murmur2) Write(p []byte) (int, error) {
+	h.buf = append(h.buf, p...)
+	return len(p), nil
+}
+
+func (h *murmur2) Sum(b []byte) []byte {
+	return binary.BigEndian.AppendUint32(b, h.Sum32())
+}
+
+func (h *murmur2) Reset()         { h.buf = h.buf[:0] }
+func (h *murmur2) Size() int      { return 4 }
+func (h *murmur2) BlockSize() int { return 4 }
+
+func (h *murmur2) Sum32() uint32 {
+	return murmur2Hash(h.buf)
+}
+
+func murmur2Hash(data []byte) uint32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
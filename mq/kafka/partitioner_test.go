@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IBM/sarama"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+func TestMurmur2MatchesJavaClient(t *testing.T) {
+	// 取自 Kafka Java 客户端 UtilsTest.testMurmur2
+	cases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+	for in, want := range cases {
+		if got := int32(murmur2Hash([]byte(in))); got != want {
+			t.Errorf("murmur2(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func partitionOf(t *testing.T, name string, msg *mq.Message, numPartitions int32) (int32, error) {
+	t.Helper()
+	ctor, err := newPartitioner(name)
+	if err != nil {
+		t.Fatalf("new partitioner: %v", err)
+	}
+	return ctor(msg.Topic).Partition(convertToKafkaMessage(msg), numPartitions)
+}
+
+func TestMurmur2Partitioner(t *testing.T) {
+	for _, key := range []string{"order-1", "order-2", "user-42", "21"} {
+		want := (int32(murmur2Hash([]byte(key))) & 0x7fffffff) % 12
+		got, err := partitionOf(t, PartitionerMurmur2, mq.NewMessage("t", nil).WithKey(key), 12)
+		if err != nil {
+			t.Fatalf("partition: %v", err)
+		}
+		if got != want {
+			t.Fatalf("key %q: got partition %d, want %d", key, got, want)
+		}
+	}
+}
+
+func TestExplicitPartitionOverridesPartitioner(t *testing.T) {
+	for _, name := range []string{PartitionerHash, PartitionerMurmur2, PartitionerRandom, PartitionerRoundRobin, PartitionerManual} {
+		got, err := partitionOf(t, name, mq.NewMessage("t", nil).WithKey("k").WithPartition(3), 6)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got != 3 {
+			t.Fatalf("%s: got partition %d, want 3", name, got)
+		}
+	}
+
+	if _, err := partitionOf(t, PartitionerHash, mq.NewMessage("t", nil).WithPartition(6), 6); !errors.Is(err, sarama.ErrInvalidPartition) {
+		t.Fatalf("expected invalid partition error, got %v", err)
+	}
+}
+
+func TestManualPartitionerRequiresPartition(t *testing.T) {
+	if _, err := partitionOf(t, PartitionerManual, mq.NewMessage("t", nil).WithKey("k"), 6); !errors.Is(err, sarama.ErrInvalidPartition) {
+		t.Fatalf("expected invalid partition error, got %v", err)
+	}
+}
+
+func TestPartitionerConfig(t *testing.T) {
+	cfg := mq.DefaultKafkaConfig()
+	cfg.Producer.Partitioner = "sticky"
+	if _, err := buildSaramaConfig(cfg); err == nil {
+		t.Fatal("expected error for unsupported partitioner")
+	}
+
+	cfg.Producer.Partitioner = PartitionerMurmur2
+	saramaCfg, err := buildSaramaConfig(cfg)
+	if err != nil {
+		t.Fatalf("build config: %v", err)
+	}
+	p := saramaCfg.Producer.Partitioner("t")
+	dc := p.(sarama.DynamicConsistencyPartitioner)
+	if !dc.MessageRequiresConsistency(convertToKafkaMessage(mq.NewMessage("t", nil).WithKey("k"))) {
+		t.Fatal("keyed messages must be partitioned over all partitions")
+	}
+	if dc.MessageRequiresConsistency(convertToKafkaMessage(mq.NewMessage("t", nil))) {
+		t.Fatal("unkeyed messages may use writable partitions only")
+	}
+}
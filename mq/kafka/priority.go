@@ -0,0 +1,162 @@
+package kafka
+
+import (
+	"context"
+	"sync"
+)
+
+/* ========================================================================
+ * 优先级调度 - 多主题共享处理槽
+ * ========================================================================
+ * 职责: 在同一 ConsumerAdapter 的多个分区间按主题优先级分配处理槽
+ * 语义:
+ *   - 每个分区 claim 注册为一个来源，处理每条消息前 acquire、处理后 release
+ *   - 空闲槽分配给等待中优先级最高的来源（同优先级按等待先后）
+ *   - 更高优先级来源空闲且仍有积压（消息已拉取但尚未请求槽）时暂留空槽，
+ *     使高优先级主题先被排空
+ *   - 低优先级来源每被更高优先级插队一次计数一次，达到 maxStarvation
+ *     后下一次必定获得槽，保证有界饥饿
+ * ======================================================================== */
+
+type priorityScheduler struct {
+	mu            sync.Mutex
+	slots         int
+	inUse         int
+	maxStarvation int
+	waiters       []*priorityWaiter
+	sources       map[*prioritySource]struct{}
+}
+
+// prioritySource 调度来源（一个分区 claim）
+type prioritySource struct {
+	priority int
+	backlog  func() int // 已拉取未处理的消息数
+	waiting  bool       // 正在等待槽
+	active   bool       // 正在占用槽
+}
+
+type priorityWaiter struct {
+	src     *prioritySource
+	skipped int
+	granted chan struct{}
+}
+
+func newPriorityScheduler(slots, maxStarvation int) *priorityScheduler {
+	if slots <= 0 {
+		slots = 1
+	}
+	if maxStarvation <= 0 {
+		maxStarvation = 10
+	}
+	return &priorityScheduler{
+		slots:         slots,
+		maxStarvation: maxStarvation,
+		sources:       make(map[*prioritySource]struct{}),
+	}
+}
+
+// register 注册来源，backlog 可为 nil
+func (s *priorityScheduler) register(priority int, backlog func() int) *prioritySource {
+	src := &prioritySource{priority: priority, backlog: backlog}
+	s.mu.Lock()
+	s.sources[src] = struct{}{}
+	s.mu.Unlock()
+	return src
+}
+
+// unregister 注销来源，并重新调度可能为其暂留的槽
+func (s *priorityScheduler) unregister(src *prioritySource) {
+	s.mu.Lock()
+	delete(s.sources, src)
+	s.schedule()
+	s.mu.Unlock()
+}
+
+// acquire 等待处理槽，ctx 结束时返回 ctx.Err()
+func (s *priorityScheduler) acquire(ctx context.Context, src *prioritySource) error {
+	w := &priorityWaiter{src: src, granted: make(chan struct{})}
+
+	s.mu.Lock()
+	src.waiting = true
+	s.waiters = append(s.waiters, w)
+	s.schedule()
+	s.mu.Unlock()
+
+	select {
+	case <-w.granted:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-w.granted:
+		// 取消与授予并发发生：归还已授予的槽
+		s.inUse--
+		src.active = false
+	default:
+		s.removeWaiter(w)
+	}
+	src.waiting = false
+	s.schedule()
+	return ctx.Err()
+}
+
+// release 归还 src 占用的处理槽
+func (s *priorityScheduler) release(src *prioritySource) {
+	s.mu.Lock()
+	s.inUse--
+	src.active = false
+	s.schedule()
+	s.mu.Unlock()
+}
+
+// schedule 分配空闲槽（调用方持有锁）
+func (s *priorityScheduler) schedule() {
+	for s.inUse < s.slots && len(s.waiters) > 0 {
+		w := s.pick()
+		if w == nil {
+			return
+		}
+		s.removeWaiter(w)
+		for _, other := range s.waiters {
+			if other.src.priority < w.src.priority {
+				other.skipped++
+			}
+		}
+		w.src.waiting = false
+		w.src.active = true
+		s.inUse++
+		close(w.granted)
+	}
+}
+
+// pick 选择下一个获得槽的等待者，返回 nil 表示为更高优先级积压暂留空槽
+func (s *priorityScheduler) pick() *priorityWaiter {
+	var best *priorityWaiter
+	for _, w := range s.waiters {
+		if w.skipped >= s.maxStarvation {
+			return w
+		}
+		if best == nil || w.src.priority > best.src.priority {
+			best = w
+		}
+	}
+
+	for src := range s.sources {
+		if src.priority > best.src.priority && !src.waiting && !src.active && src.backlog != nil && src.backlog() > 0 {
+			return nil
+		}
+	}
+	return best
+}
+
+func (s *priorityScheduler) removeWaiter(w *priorityWaiter) {
+	for i, cur := range s.waiters {
+		if cur == w {
+			s.waiters = append(s.waiters[:i], s.waiters[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package kafka
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func granted(t *testing.T, ch <-chan error) bool {
+	t.Helper()
+	select {
+	case err := <-ch:
+		if err != nil {
+			t.Fatalf("unexpected acquire error: %v", err)
+		}
+		return true
+	case <-time.After(20 * time.Millisecond):
+		return false
+	}
+}
+
+func acquireAsync(s *priorityScheduler, ctx context.Context, src *prioritySource) <-chan error {
+	ch := make(chan error, 1)
+	go func() { ch <- s.acquire(ctx, src) }()
+	return ch
+}
+
+func TestPrioritySchedulerPrefersHighPriority(t *testing.T) {
+	s := newPriorityScheduler(1, 10)
+	ctx := context.Background()
+	high := s.register(10, nil)
+	low := s.register(0, nil)
+
+	if err := s.acquire(ctx, low); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	lowCh := acquireAsync(s, ctx, low)
+	waitWaiters(t, s, 1)
+	highCh := acquireAsync(s, ctx, high)
+	waitWaiters(t, s, 2)
+
+	s.release(low)
+	if !granted(t, highCh) {
+		t.Fatalf("expected high priority to be granted first")
+	}
+	if granted(t, lowCh) {
+		t.Fatalf("low priority granted while slot busy")
+	}
+	s.release(high)
+	if !granted(t, lowCh) {
+		t.Fatalf("expected low priority to be granted after release")
+	}
+}
+
+func TestPrioritySchedulerHoldsSlotForHighBacklog(t *testing.T) {
+	s := newPriorityScheduler(1, 10)
+	ctx := context.Background()
+	var backlog atomic.Int64
+	backlog.Store(1)
+	high := s.register(10, func() int { return int(backlog.Load()) })
+	low := s.register(0, nil)
+
+	lowCh := acquireAsync(s, ctx, low)
+	if granted(t, lowCh) {
+		t.Fatalf("slot should be held for high priority backlog")
+	}
+	if err := s.acquire(ctx, high); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	backlog.Store(0)
+	s.release(high)
+	if !granted(t, lowCh) {
+		t.Fatalf("expected low priority granted once backlog drained")
+	}
+	s.release(low)
+
+	// 来源注销后不再暂留
+	backlog.Store(5)
+	s.unregister(high)
+	if err := s.acquire(ctx, low); err != nil {
+		t.Fatalf("acquire after unregister: %v", err)
+	}
+}
+
+func TestPrioritySchedulerBoundedStarvation(t *testing.T) {
+	s := newPriorityScheduler(1, 2)
+	ctx := context.Background()
+	high := s.register(10, nil)
+	low := s.register(0, nil)
+
+	if err := s.acquire(ctx, high); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	lowCh := acquireAsync(s, ctx, low)
+	waitWaiters(t, s, 1)
+
+	grants := 0
+	for {
+		highCh := acquireAsync(s, ctx, high)
+		waitWaiters(t, s, 2)
+		s.release(high)
+		if granted(t, lowCh) {
+			break
+		}
+		if !granted(t, highCh) {
+			t.Fatalf("expected a grant")
+		}
+		grants++
+		if grants > 2 {
+			t.Fatalf("low priority starved for %d grants", grants)
+		}
+	}
+	if grants != 2 {
+		t.Fatalf("expected low priority after 2 skips, got %d", grants)
+	}
+}
+
+func TestPrioritySchedulerAcquireCanceled(t *testing.T) {
+	s := newPriorityScheduler(1, 10)
+	src := s.register(0, nil)
+	if err := s.acquire(context.Background(), src); err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := acquireAsync(s, ctx, src)
+	waitWaiters(t, s, 1)
+	cancel()
+	if err := <-ch; err != context.Canceled {
+		t.Fatalf("expected canceled, got %v", err)
+	}
+	if waiters(s) != 0 {
+		t.Fatalf("expected canceled waiter removed")
+	}
+}
+
+func waiters(s *priorityScheduler) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.waiters)
+}
+
+func waitWaiters(t *testing.T, s *priorityScheduler, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for waiters(s) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d waiters", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"os"
 	"sync"
@@ -20,12 +21,16 @@ import (
  * ========================================================================
  * 职责: 实现 mq.Producer 接口
  * 技术: IBM/sarama
+ * 批量: producer.flush 仅作用于异步生产者（同步发送不受批量等待影响）；
+ *       Flush(ctx) 等待调用前提交的异步消息全部得到成功/失败回执
  * ======================================================================== */
 
 // =============================================================================
 // 注册工厂
 // =============================================================================
 
+var _ mq.Flusher = (*ProducerAdapter)(nil)
+
 func init() {
 	mq.RegisterProducerFactory(mq.TypeKafka, NewProducerAdapter)
 }
@@ -44,6 +49,23 @@ type ProducerAdapter struct {
 	mu            sync.RWMutex
 	ctx           context.Context
 	cancel        context.CancelFunc
+
+	// 异步消息回执跟踪（按 Flush 调用划分批次），用于 Flush
+	flushMu      sync.Mutex
+	epoch        uint64
+	inflight     map[uint64]int
+	flushWaiters []*flushWaiter
+}
+
+// asyncMetadata 随异步消息传递的元数据
+type asyncMetadata struct {
+	callback mq.SendCallback
+	epoch    uint64
+}
+
+type flushWaiter struct {
+	epoch uint64
+	done  chan struct{}
 }
 
 // NewProducerAdapter 创建 Kafka 生产者适配器
@@ -66,8 +88,8 @@ func NewProducerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Producer, error)
 		return nil, fmt.Errorf("failed to create kafka sync producer: %w", err)
 	}
 
-	// 创建异步生产者
-	asyncProducer, err := sarama.NewAsyncProducer(kafkaCfg.Brokers, saramaCfg)
+	// 创建异步生产者（批量配置仅作用于异步生产者）
+	asyncProducer, err := sarama.NewAsyncProducer(kafkaCfg.Brokers, buildAsyncConfig(saramaCfg, kafkaCfg))
 	if err != nil {
 		syncProducer.Close()
 		return nil, fmt.Errorf("failed to create kafka async producer: %w", err)
@@ -81,6 +103,7 @@ func NewProducerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Producer, error)
 		logger:        logger,
 		ctx:           ctx,
 		cancel:        cancel,
+		inflight:      make(map[uint64]int),
 	}
 
 	// 启动异步错误处理
@@ -104,20 +127,23 @@ func (p *ProducerAdapter) handleAsyncErrors() {
 			if !ok {
 				return
 			}
-			if cb, ok := err.Msg.Metadata.(mq.SendCallback); ok && cb != nil {
-				cb(nil, err.Err)
+			meta, _ := err.Msg.Metadata.(*asyncMetadata)
+			if meta != nil && meta.callback != nil {
+				meta.callback(nil, sendError(err.Err))
 			} else {
 				p.logger.Error("async producer error",
 					zap.String("topic", err.Msg.Topic),
 					zap.Error(err.Err),
 				)
 			}
+			p.complete(meta)
 		case msg, ok := <-p.asyncProducer.Successes():
 			if !ok {
 				return
 			}
-			if cb, ok := msg.Metadata.(mq.SendCallback); ok && cb != nil {
-				cb(&mq.SendResult{
+			meta, _ := msg.Metadata.(*asyncMetadata)
+			if meta != nil && meta.callback != nil {
+				meta.callback(&mq.SendResult{
 					MsgID:     fmt.Sprintf("%s-%d-%d", msg.Topic, msg.Partition, msg.Offset),
 					Topic:     msg.Topic,
 					Partition: msg.Partition,
@@ -131,6 +157,7 @@ func (p *ProducerAdapter) handleAsyncErrors() {
 					zap.Int64("offset", msg.Offset),
 				)
 			}
+			p.complete(meta)
 		case <-p.ctx.Done():
 			return
 		}
@@ -146,6 +173,7 @@ func (p *ProducerAdapter) SendSync(ctx context.Context, msg *mq.Message) (*mq.Se
 	}
 	p.mu.RUnlock()
 
+	mq.InjectRequestID(ctx, msg)
 	kafkaMsg := convertToKafkaMessage(msg)
 
 	partition, offset, err := p.syncProducer.SendMessage(kafkaMsg)
@@ -154,7 +182,7 @@ func (p *ProducerAdapter) SendSync(ctx context.Context, msg *mq.Message) (*mq.Se
 			zap.String("topic", msg.Topic),
 			zap.Error(err),
 		)
-		return nil, err
+		return nil, sendError(err)
 	}
 
 	p.logger.Debug("message sent",
@@ -172,6 +200,27 @@ func (p *ProducerAdapter) SendSync(ctx context.Context, msg *mq.Message) (*mq.Se
 	}, nil
 }
 
+// permanentErrors 重试不会成功的 Kafka 错误码
+var permanentErrors = []sarama.KError{
+	sarama.ErrInvalidMessage,
+	sarama.ErrMessageSizeTooLarge,
+	sarama.ErrInvalidTopic,
+	sarama.ErrInvalidRequiredAcks,
+	sarama.ErrTopicAuthorizationFailed,
+	sarama.ErrUnsupportedForMessageFormat,
+	sarama.ErrInvalidRecord,
+}
+
+// sendError 将永久性错误标记为 mq.ErrPermanent（SpillProducer 不会落盘）
+func sendError(err error) error {
+	for _, kerr := range permanentErrors {
+		if errors.Is(err, kerr) {
+			return mq.Permanent(err)
+		}
+	}
+	return err
+}
+
 // SendAsync 异步发送消息
 func (p *ProducerAdapter) SendAsync(ctx context.Context, msg *mq.Message, callback mq.SendCallback) error {
 	p.mu.RLock()
@@ -181,8 +230,10 @@ func (p *ProducerAdapter) SendAsync(ctx context.Context, msg *mq.Message, callba
 	}
 	p.mu.RUnlock()
 
+	mq.InjectRequestID(ctx, msg)
 	kafkaMsg := convertToKafkaMessage(msg)
-	kafkaMsg.Metadata = callback
+	meta := p.track(callback)
+	kafkaMsg.Metadata = meta
 
 	// 注意：Sarama 的异步 Producer 不支持单消息回调
 	// 回调通过 Successes() 和 Errors() channel 处理（使用 ProducerMessage.Metadata 关联）
@@ -190,10 +241,82 @@ func (p *ProducerAdapter) SendAsync(ctx context.Context, msg *mq.Message, callba
 	case p.asyncProducer.Input() <- kafkaMsg:
 		return nil
 	case <-ctx.Done():
+		p.complete(meta)
 		return ctx.Err()
 	}
 }
 
+// Flush 等待调用前提交的异步消息全部得到回执（实现 mq.Flusher）
+// 之后提交的消息不在等待范围内，持续发送时也能按时返回
+func (p *ProducerAdapter) Flush(ctx context.Context) error {
+	p.flushMu.Lock()
+	target := p.epoch
+	p.epoch++
+	if !p.pendingUpTo(target) {
+		p.flushMu.Unlock()
+		return nil
+	}
+	w := &flushWaiter{epoch: target, done: make(chan struct{})}
+	p.flushWaiters = append(p.flushWaiters, w)
+	p.flushMu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		p.flushMu.Lock()
+		for i, cur := range p.flushWaiters {
+			if cur == w {
+				p.flushWaiters = append(p.flushWaiters[:i], p.flushWaiters[i+1:]...)
+				break
+			}
+		}
+		p.flushMu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// track 登记一条待回执的异步消息
+func (p *ProducerAdapter) track(callback mq.SendCallback) *asyncMetadata {
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+	meta := &asyncMetadata{callback: callback, epoch: p.epoch}
+	p.inflight[meta.epoch]++
+	return meta
+}
+
+// complete 记录异步消息已得到回执，并唤醒已满足条件的 Flush
+func (p *ProducerAdapter) complete(meta *asyncMetadata) {
+	if meta == nil {
+		return
+	}
+	p.flushMu.Lock()
+	defer p.flushMu.Unlock()
+
+	if p.inflight[meta.epoch]--; p.inflight[meta.epoch] <= 0 {
+		delete(p.inflight, meta.epoch)
+	}
+	waiters := p.flushWaiters[:0]
+	for _, w := range p.flushWaiters {
+		if p.pendingUpTo(w.epoch) {
+			waiters = append(waiters, w)
+		} else {
+			close(w.done)
+		}
+	}
+	p.flushWaiters = waiters
+}
+
+// pendingUpTo 是否存在批次不晚于 epoch 的未回执消息（调用方持有 flushMu）
+func (p *ProducerAdapter) pendingUpTo(epoch uint64) bool {
+	for e := range p.inflight {
+		if e <= epoch {
+			return true
+		}
+	}
+	return false
+}
+
 // Close 关闭生产者
 func (p *ProducerAdapter) Close() error {
 	p.mu.Lock()
@@ -281,6 +404,18 @@ func buildSaramaConfig(cfg *mq.KafkaConfig) (*sarama.Config, error) {
 		saramaCfg.Net.MaxOpenRequests = 1
 	}
 
+	// 分区器
+	partitioner, err := newPartitioner(cfg.Producer.Partitioner)
+	if err != nil {
+		return nil, err
+	}
+	saramaCfg.Producer.Partitioner = partitioner
+
+	// 内部 channel 缓冲
+	if cfg.Producer.ChannelBufferSize > 0 {
+		saramaCfg.ChannelBufferSize = cfg.Producer.ChannelBufferSize
+	}
+
 	// 消息大小
 	if cfg.Producer.MaxMessageBytes > 0 {
 		saramaCfg.Producer.MaxMessageBytes = cfg.Producer.MaxMessageBytes
@@ -347,12 +482,35 @@ func buildTLSConfig(cfg mq.KafkaTLSConfig) (*tls.Config, error) {
 	return tlsConfig, nil
 }
 
+// buildAsyncConfig 在公共配置基础上应用异步生产者的批量设置
+func buildAsyncConfig(base *sarama.Config, cfg *mq.KafkaConfig) *sarama.Config {
+	asyncCfg := *base
+	flush := cfg.Producer.Flush
+	if flush.Bytes > 0 {
+		asyncCfg.Producer.Flush.Bytes = flush.Bytes
+	}
+	if flush.Messages > 0 {
+		asyncCfg.Producer.Flush.Messages = flush.Messages
+	}
+	if flush.Frequency > 0 {
+		asyncCfg.Producer.Flush.Frequency = flush.Frequency
+	}
+	if flush.MaxMessages > 0 {
+		asyncCfg.Producer.Flush.MaxMessages = flush.MaxMessages
+	}
+	return &asyncCfg
+}
+
 func convertToKafkaMessage(msg *mq.Message) *sarama.ProducerMessage {
 	kafkaMsg := &sarama.ProducerMessage{
 		Topic:     msg.Topic,
 		Value:     sarama.ByteEncoder(msg.Body),
+		Partition: unassignedPartition,
 		Timestamp: time.Now(),
 	}
+	if msg.Partition != nil {
+		kafkaMsg.Partition = *msg.Partition
+	}
 
 	// Key
 	if msg.Key != "" {
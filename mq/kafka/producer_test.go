@@ -0,0 +1,160 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/IBM/sarama/mocks"
+	"go.uber.org/zap"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+func newTestProducer(t *testing.T, async sarama.AsyncProducer) *ProducerAdapter {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &ProducerAdapter{
+		asyncProducer: async,
+		syncProducer:  mocks.NewSyncProducer(t, nil),
+		logger:        zap.NewNop(),
+		ctx:           ctx,
+		cancel:        cancel,
+		inflight:      make(map[uint64]int),
+	}
+	p.wg.Add(1)
+	go p.handleAsyncErrors()
+	return p
+}
+
+func TestProducerFlushWaitsForAsyncAcks(t *testing.T) {
+	cfg := mocks.NewTestConfig()
+	cfg.Producer.Return.Successes = true
+	async := mocks.NewAsyncProducer(t, cfg)
+	async.ExpectInputAndSucceed()
+	async.ExpectInputAndFail(errors.New("broker down"))
+	p := newTestProducer(t, async)
+
+	var acked, failed atomic.Int32
+	callback := func(_ *mq.SendResult, err error) {
+		if err != nil {
+			failed.Add(1)
+		} else {
+			acked.Add(1)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if err := p.SendAsync(context.Background(), mq.NewMessage("t", []byte("x")), callback); err != nil {
+			t.Fatalf("send async: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := p.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	if acked.Load() != 1 || failed.Load() != 1 {
+		t.Fatalf("expected all callbacks before flush returns, got acked=%d failed=%d", acked.Load(), failed.Load())
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+func TestProducerFlushOnlyWaitsForEarlierMessages(t *testing.T) {
+	p := &ProducerAdapter{inflight: make(map[uint64]int)}
+
+	earlier := p.track(nil)
+	done := make(chan error, 1)
+	go func() { done <- p.Flush(context.Background()) }()
+
+	// 等待 Flush 登记后再提交新消息
+	deadline := time.Now().Add(time.Second)
+	for {
+		p.flushMu.Lock()
+		n := len(p.flushWaiters)
+		p.flushMu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("flush did not register")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	later := p.track(nil)
+
+	p.complete(earlier)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("flush must not wait for messages submitted after it")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Flush(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if len(p.flushWaiters) != 0 {
+		t.Fatal("timed out waiter must be removed")
+	}
+	p.complete(later)
+	if err := p.Flush(context.Background()); err != nil {
+		t.Fatalf("flush with nothing in flight: %v", err)
+	}
+}
+
+func TestBuildAsyncConfigAppliesFlushOnlyToAsync(t *testing.T) {
+	cfg := mq.DefaultKafkaConfig()
+	cfg.Producer.ChannelBufferSize = 1024
+	cfg.Producer.Flush = mq.KafkaFlushConfig{Bytes: 64 << 10, Messages: 100, Frequency: 50 * time.Millisecond, MaxMessages: 500}
+
+	base, err := buildSaramaConfig(cfg)
+	if err != nil {
+		t.Fatalf("build config: %v", err)
+	}
+	async := buildAsyncConfig(base, cfg)
+
+	if base.Producer.Flush.Messages != 0 || base.Producer.Flush.Frequency != 0 {
+		t.Fatal("sync producer config must not batch")
+	}
+	if async.Producer.Flush.Bytes != 64<<10 || async.Producer.Flush.Messages != 100 ||
+		async.Producer.Flush.Frequency != 50*time.Millisecond || async.Producer.Flush.MaxMessages != 500 {
+		t.Fatalf("unexpected async flush config %+v", async.Producer.Flush)
+	}
+	if base.ChannelBufferSize != 1024 || async.ChannelBufferSize != 1024 {
+		t.Fatal("channel buffer size must apply to both producers")
+	}
+	if err := async.Validate(); err != nil {
+		t.Fatalf("async config invalid: %v", err)
+	}
+}
+
+func TestProducerMarksPermanentErrors(t *testing.T) {
+	syncProducer := mocks.NewSyncProducer(t, nil)
+	syncProducer.ExpectSendMessageAndFail(sarama.ErrMessageSizeTooLarge)
+	syncProducer.ExpectSendMessageAndFail(sarama.ErrNotLeaderForPartition)
+	p := newTestProducer(t, mocks.NewAsyncProducer(t, nil))
+	p.syncProducer = syncProducer
+
+	_, err := p.SendSync(context.Background(), mq.NewMessage("t", []byte("x")))
+	if !errors.Is(err, mq.ErrPermanent) || !errors.Is(err, sarama.ErrMessageSizeTooLarge) {
+		t.Fatalf("expected permanent message size error, got %v", err)
+	}
+	_, err = p.SendSync(context.Background(), mq.NewMessage("t", []byte("x")))
+	if err == nil || errors.Is(err, mq.ErrPermanent) {
+		t.Fatalf("expected retryable error, got %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
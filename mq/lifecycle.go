@@ -0,0 +1,134 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Consumer 生命周期 - 由 Fx 管理订阅、启动与关闭
+ * ========================================================================
+ * 职责: 收集 fx group 中的 SubscriptionProvider，启动前统一订阅
+ * 语义:
+ *   - OnStart: 依次订阅所有 Subscription -> Start -> 等待就绪（ReadyWaiter）
+ *     fx.Invoke 中直接调用 Consumer.Subscribe 的订阅同样在 Start 之前生效
+ *   - OnStop: 在 ctx 限制内 Close
+ *   - 顺序: SubscriptionProvider 的依赖（DB / Redis 等）先于 Consumer 构造，
+ *     fx 按逆序停止，因此 Consumer 先于这些依赖关闭；
+ *     接入 ShutdownModule 时另按优先级在 DB / Redis 之前关闭
+ *
+ * 使用示例:
+ *   fx.Provide(mq.AsSubscriptionProvider(NewOrderHandlers))
+ *
+ *   func (h *OrderHandlers) Subscriptions() []mq.Subscription {
+ *       return []mq.Subscription{{Topic: "order.created", Handler: h.onCreated}}
+ *   }
+ * ======================================================================== */
+
+// SubscriptionGroup SubscriptionProvider 的 fx group 名称
+const SubscriptionGroup = "mq_subscriptions"
+
+// Subscription 订阅声明
+type Subscription struct {
+	Topic   string
+	Handler MessageHandler
+	Options []SubscribeOption
+}
+
+// SubscriptionProvider 订阅提供者
+type SubscriptionProvider interface {
+	Subscriptions() []Subscription
+}
+
+// SubscriptionFunc 函数形式的 SubscriptionProvider
+type SubscriptionFunc func() []Subscription
+
+// Subscriptions 实现 SubscriptionProvider
+func (f SubscriptionFunc) Subscriptions() []Subscription {
+	return f()
+}
+
+// AsSubscriptionProvider 将返回 SubscriptionProvider 实现的构造函数标注为订阅提供者
+//
+//	fx.Provide(mq.AsSubscriptionProvider(NewOrderHandlers))
+func AsSubscriptionProvider(constructor any) any {
+	return fx.Annotate(constructor,
+		fx.As(new(SubscriptionProvider)),
+		fx.ResultTags(`group:"`+SubscriptionGroup+`"`),
+	)
+}
+
+// ReadyWaiter 可选接口：Start 返回后仍需等待就绪的消费者实现
+type ReadyWaiter interface {
+	// WaitReady 阻塞直到消费者就绪或 ctx 结束
+	WaitReady(ctx context.Context) error
+}
+
+// ManageConsumer 将消费者接入 fx 生命周期
+// 适用于 Module 之外自行创建的消费者（如不同消费组的第二个 Consumer）
+func ManageConsumer(lc fx.Lifecycle, consumer Consumer, providers []SubscriptionProvider, logger *zap.Logger) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			topics, err := subscribeAll(consumer, providers)
+			if err != nil {
+				return err
+			}
+			if err := runWithContext(ctx, consumer.Start); err != nil {
+				_ = consumer.Close()
+				return fmt.Errorf("start mq consumer: %w", err)
+			}
+			if w, ok := consumer.(ReadyWaiter); ok {
+				if err := w.WaitReady(ctx); err != nil {
+					_ = consumer.Close()
+					return fmt.Errorf("wait mq consumer ready: %w", err)
+				}
+			}
+			logger.Info("mq consumer started", zap.Strings("topics", topics))
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return runWithContext(ctx, consumer.Close)
+		},
+	})
+}
+
+// subscribeAll 按提供者顺序订阅，返回订阅的主题
+func subscribeAll(consumer Consumer, providers []SubscriptionProvider) ([]string, error) {
+	var topics []string
+	for _, p := range providers {
+		if p == nil {
+			continue
+		}
+		for _, sub := range p.Subscriptions() {
+			if sub.Topic == "" || sub.Handler == nil {
+				return nil, fmt.Errorf("invalid mq subscription for topic %q", sub.Topic)
+			}
+			if err := Subscribe(consumer, sub.Topic, sub.Handler, sub.Options...); err != nil {
+				return nil, fmt.Errorf("subscribe topic %s: %w", sub.Topic, err)
+			}
+			topics = append(topics, sub.Topic)
+		}
+	}
+	return topics, nil
+}
+
+// runWithContext 在 ctx 限制内执行 fn（ctx 结束后 fn 仍在后台完成）
+func runWithContext(ctx context.Context, fn func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
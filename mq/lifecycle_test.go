@@ -0,0 +1,203 @@
+package mq_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/mq/mqtest"
+)
+
+// recorder 记录生命周期事件顺序
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *recorder) add(e string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, e)
+}
+
+func (r *recorder) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// fakeDB 模拟订阅处理器依赖的资源
+type fakeDB struct{}
+
+func newFakeDB(lc fx.Lifecycle, rec *recorder) *fakeDB {
+	lc.Append(fx.Hook{OnStop: func(context.Context) error {
+		rec.add("db-close")
+		return nil
+	}})
+	return &fakeDB{}
+}
+
+type orderHandlers struct {
+	db  *fakeDB
+	rec *recorder
+}
+
+func newOrderHandlers(db *fakeDB, rec *recorder) *orderHandlers {
+	return &orderHandlers{db: db, rec: rec}
+}
+
+func (h *orderHandlers) Subscriptions() []mq.Subscription {
+	return []mq.Subscription{{
+		Topic: "order.created",
+		Handler: func(ctx context.Context, msgs []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+			for _, m := range msgs {
+				h.rec.add("handle:" + string(m.Body))
+			}
+			return mq.ConsumeSuccess, nil
+		},
+	}}
+}
+
+// recordingConsumer 记录 Subscribe/Start/WaitReady/Close 调用
+type recordingConsumer struct {
+	rec      *recorder
+	startErr error
+}
+
+func (c *recordingConsumer) Subscribe(topic string, _ mq.MessageHandler) error {
+	c.rec.add("subscribe:" + topic)
+	return nil
+}
+
+func (c *recordingConsumer) Start() error {
+	c.rec.add("start")
+	return c.startErr
+}
+
+func (c *recordingConsumer) WaitReady(context.Context) error {
+	c.rec.add("ready")
+	return nil
+}
+
+func (c *recordingConsumer) Close() error {
+	c.rec.add("consumer-close")
+	return nil
+}
+
+func TestModuleSubscribesProvidersBeforeStart(t *testing.T) {
+	broker := mqtest.Default()
+	broker.Reset()
+	t.Cleanup(broker.Reset)
+
+	rec := &recorder{}
+	app := fxtest.New(t,
+		fx.Supply(rec, &mq.Config{Type: mq.TypeMemory}, zap.NewNop()),
+		fx.Provide(newFakeDB, mq.AsSubscriptionProvider(newOrderHandlers)),
+		mq.Module,
+		fx.Invoke(func(p mq.Producer, _ mq.Consumer) error {
+			// 启动前发送的消息在订阅生效后投递
+			_, err := p.SendSync(context.Background(), mq.NewMessage("order.created", []byte("1")))
+			return err
+		}),
+	)
+	if got := rec.snapshot(); len(got) != 0 {
+		t.Fatalf("expected no delivery before start, got %v", got)
+	}
+
+	app.RequireStart()
+	if got := rec.snapshot(); len(got) != 1 || got[0] != "handle:1" {
+		t.Fatalf("unexpected events after start: %v", got)
+	}
+	app.RequireStop()
+
+	if got := broker.Pending(); got != 0 {
+		t.Fatalf("expected no pending messages, got %d", got)
+	}
+}
+
+func TestManageConsumerOrdering(t *testing.T) {
+	rec := &recorder{}
+	consumer := &recordingConsumer{rec: rec}
+
+	app := fxtest.New(t,
+		fx.Supply(rec),
+		fx.Provide(newFakeDB, mq.AsSubscriptionProvider(newOrderHandlers)),
+		fx.Invoke(func(lc fx.Lifecycle, p struct {
+			fx.In
+			Providers []mq.SubscriptionProvider `group:"mq_subscriptions"`
+		}) {
+			mq.ManageConsumer(lc, consumer, p.Providers, nil)
+		}),
+	)
+	app.RequireStart().RequireStop()
+
+	want := []string{"subscribe:order.created", "start", "ready", "consumer-close", "db-close"}
+	got := rec.snapshot()
+	if len(got) != len(want) {
+		t.Fatalf("unexpected events: %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("unexpected events: %v", got)
+		}
+	}
+}
+
+func TestManageConsumerStartFailure(t *testing.T) {
+	rec := &recorder{}
+	consumer := &recordingConsumer{rec: rec, startErr: errors.New("boom")}
+
+	app := fx.New(
+		fx.NopLogger,
+		fx.Invoke(func(lc fx.Lifecycle) {
+			mq.ManageConsumer(lc, consumer, []mq.SubscriptionProvider{
+				mq.SubscriptionFunc(func() []mq.Subscription {
+					return []mq.Subscription{{Topic: "t", Handler: func(context.Context, []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+						return mq.ConsumeSuccess, nil
+					}}}
+				}),
+			}, nil)
+		}),
+	)
+	if err := app.Start(context.Background()); err == nil {
+		t.Fatalf("expected start error")
+	}
+	got := rec.snapshot()
+	if len(got) != 3 || got[2] != "consumer-close" {
+		t.Fatalf("expected consumer closed after failed start, got %v", got)
+	}
+}
+
+func TestSubscribeBroadcastRequiresSupport(t *testing.T) {
+	noop := func(context.Context, []*mq.ConsumedMessage) (mq.ConsumeResult, error) { return mq.ConsumeSuccess, nil }
+
+	rec := &recorder{}
+	plain := &recordingConsumer{rec: rec}
+	if err := mq.Subscribe(plain, "cache.invalidate", noop, mq.WithBroadcast()); !errors.Is(err, mq.ErrBroadcastUnsupported) {
+		t.Fatalf("expected ErrBroadcastUnsupported, got %v", err)
+	}
+	// 优先级可降级为普通订阅
+	if err := mq.Subscribe(plain, "job.batch", noop, mq.WithPriority(5)); err != nil {
+		t.Fatalf("subscribe with priority: %v", err)
+	}
+	if got := rec.snapshot(); len(got) != 1 || got[0] != "subscribe:job.batch" {
+		t.Fatalf("unexpected events: %v", got)
+	}
+
+	// 中间件包装后的消费者沿用底层实现的能力
+	wrapped := mq.WrapConsumer(plain, func(next mq.MessageHandler) mq.MessageHandler { return next })
+	if err := mq.Subscribe(wrapped, "cache.invalidate", noop, mq.WithBroadcast()); !errors.Is(err, mq.ErrBroadcastUnsupported) {
+		t.Fatalf("expected ErrBroadcastUnsupported through wrapper, got %v", err)
+	}
+
+	broker := mqtest.NewBroker()
+	if err := mq.Subscribe(broker.Consumer(), "cache.invalidate", noop, mq.WithBroadcast()); err != nil {
+		t.Fatalf("mqtest consumer should accept broadcast: %v", err)
+	}
+}
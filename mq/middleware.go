@@ -0,0 +1,149 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/aisgo/ais-go-pkg/ctxutil"
+)
+
+/* ========================================================================
+ * 中间件 - Producer / Consumer 装饰
+ * ========================================================================
+ * 职责: 在发送前与 handler 执行前统一处理消息（如压缩、加密）
+ * 语义:
+ *   - ProducerMiddleware 按声明顺序包裹，第一个最先处理待发送消息
+ *   - ConsumerMiddleware 按声明顺序包裹 handler，第一个最先处理收到的消息
+ *   - 包装后的 Producer 透传 Flusher
+ *   - 包装后的 Consumer 透传 OptionSubscriber / ReadyWaiter / StatsProvider
+ *   - ProvideProducer 在最外层应用 DeadlineMiddleware，启用 Spill 时落盘缓冲位于最内层
+ *     （落盘的是编码/加密后的消息）
+ *
+ * 使用示例:
+ *   producer = mq.WrapProducer(producer, codec.ProducerMiddleware())
+ *   consumer = mq.WrapConsumer(consumer, codec.ConsumerMiddleware())
+ * ======================================================================== */
+
+// ProducerMiddleware 生产者中间件
+type ProducerMiddleware func(Producer) Producer
+
+// ConsumerMiddleware 消费者中间件（包裹 handler）
+type ConsumerMiddleware func(MessageHandler) MessageHandler
+
+// WrapProducer 依次应用生产者中间件
+func WrapProducer(p Producer, mws ...ProducerMiddleware) Producer {
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] != nil {
+			p = mws[i](p)
+		}
+	}
+	return p
+}
+
+// SendFunc 基于发送前处理函数构造的生产者中间件
+// transform 返回待发送的消息（可为新对象），返回错误时不发送
+func SendFunc(transform func(ctx context.Context, msg *Message) (*Message, error)) ProducerMiddleware {
+	return func(next Producer) Producer {
+		return &transformProducer{next: next, transform: transform}
+	}
+}
+
+// DeadlineMiddleware 发送前检查 ctx 的截止时间（见 ctxutil.RequireDeadline，操作名 mq.send）
+// ctxutil enforce 模式下 ctx 未设置 deadline 时返回 ctxutil.ErrNoDeadline，不发送
+func DeadlineMiddleware() ProducerMiddleware {
+	return SendFunc(func(ctx context.Context, msg *Message) (*Message, error) {
+		if err := ctxutil.RequireDeadline(ctx, "mq.send"); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	})
+}
+
+var _ Flusher = (*transformProducer)(nil)
+
+type transformProducer struct {
+	next      Producer
+	transform func(ctx context.Context, msg *Message) (*Message, error)
+}
+
+func (p *transformProducer) SendSync(ctx context.Context, msg *Message) (*SendResult, error) {
+	out, err := p.transform(ctx, msg)
+	if err != nil {
+		return nil, err
+	}
+	return p.next.SendSync(ctx, out)
+}
+
+func (p *transformProducer) SendAsync(ctx context.Context, msg *Message, callback SendCallback) error {
+	out, err := p.transform(ctx, msg)
+	if err != nil {
+		return err
+	}
+	return p.next.SendAsync(ctx, out, callback)
+}
+
+func (p *transformProducer) Flush(ctx context.Context) error {
+	return Flush(ctx, p.next)
+}
+
+func (p *transformProducer) Close() error {
+	return p.next.Close()
+}
+
+// WrapConsumer 为消费者的所有订阅应用中间件
+func WrapConsumer(c Consumer, mws ...ConsumerMiddleware) Consumer {
+	if len(mws) == 0 {
+		return c
+	}
+	return &wrappedConsumer{next: c, mws: mws}
+}
+
+type wrappedConsumer struct {
+	next Consumer
+	mws  []ConsumerMiddleware
+}
+
+var (
+	_ OptionSubscriber = (*wrappedConsumer)(nil)
+	_ ReadyWaiter      = (*wrappedConsumer)(nil)
+	_ StatsProvider    = (*wrappedConsumer)(nil)
+)
+
+func (c *wrappedConsumer) wrap(handler MessageHandler) MessageHandler {
+	if handler == nil {
+		return nil
+	}
+	for i := len(c.mws) - 1; i >= 0; i-- {
+		if c.mws[i] != nil {
+			handler = c.mws[i](handler)
+		}
+	}
+	return handler
+}
+
+func (c *wrappedConsumer) Subscribe(topic string, handler MessageHandler) error {
+	return c.next.Subscribe(topic, c.wrap(handler))
+}
+
+func (c *wrappedConsumer) SubscribeWithOptions(topic string, handler MessageHandler, opts ...SubscribeOption) error {
+	return Subscribe(c.next, topic, c.wrap(handler), opts...)
+}
+
+func (c *wrappedConsumer) Start() error {
+	return c.next.Start()
+}
+
+func (c *wrappedConsumer) Close() error {
+	return c.next.Close()
+}
+
+func (c *wrappedConsumer) WaitReady(ctx context.Context) error {
+	if w, ok := c.next.(ReadyWaiter); ok {
+		return w.WaitReady(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConsumer) Stats() ConsumerStats {
+	stats, _ := StatsOf(c.next)
+	return stats
+}
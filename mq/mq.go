@@ -2,6 +2,8 @@ package mq
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -24,6 +26,32 @@ type Producer interface {
 	Close() error
 }
 
+// Flusher 可选接口：支持等待异步消息发送完成的生产者
+type Flusher interface {
+	// Flush 等待调用前已提交的异步消息全部完成（成功或失败）
+	Flush(ctx context.Context) error
+}
+
+// Flush 等待生产者的异步消息发送完成，生产者不支持时直接返回 nil
+func Flush(ctx context.Context, producer Producer) error {
+	if f, ok := producer.(Flusher); ok {
+		return f.Flush(ctx)
+	}
+	return nil
+}
+
+// ErrPermanent 永久性发送错误（消息过大、主题非法、无权限等），重试不会成功
+// 后端以 Permanent 包装此类错误，SpillProducer 不会将其落盘
+var ErrPermanent = errors.New("mq: permanent send error")
+
+// Permanent 将 err 标记为永久性发送错误，原错误仍可通过 errors.Is / errors.As 匹配
+func Permanent(err error) error {
+	if err == nil || errors.Is(err, ErrPermanent) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", ErrPermanent, err)
+}
+
 // Consumer 消息消费者接口
 type Consumer interface {
 	// Subscribe 订阅主题
@@ -49,6 +77,7 @@ type Message struct {
 	Properties map[string]string // 自定义属性
 	DelayLevel int               // 延迟级别（RocketMQ 特有）
 	DelayTime  time.Duration     // 延迟时间（Kafka 可通过 header 实现）
+	Partition  *int32            // 指定分区（Kafka 特有，优先于分区器）
 }
 
 // NewMessage 创建消息
@@ -92,6 +121,12 @@ func (m *Message) WithProperties(props map[string]string) *Message {
 	return m
 }
 
+// WithPartition 指定分区（Kafka）
+func (m *Message) WithPartition(partition int32) *Message {
+	m.Partition = &partition
+	return m
+}
+
 // WithDelayLevel 设置延迟级别（RocketMQ）
 func (m *Message) WithDelayLevel(level int) *Message {
 	m.DelayLevel = level
@@ -144,6 +179,8 @@ const (
 	SendStatusFlushSlaveTimeout
 	SendStatusSlaveNotAvailable
 	SendStatusUnknownError
+	// SendStatusSpilled Broker 不可用，消息已写入本地缓冲等待重放（见 SpillProducer）
+	SendStatusSpilled
 )
 
 // SendCallback 异步发送回调
@@ -175,4 +212,6 @@ type Type string
 const (
 	TypeRocketMQ Type = "rocketmq"
 	TypeKafka    Type = "kafka"
+	TypeRedis    Type = "redis"  // Redis Streams（mq/redisstream，轻量部署）
+	TypeMemory   Type = "memory" // 内存实现（mq/mqtest，仅用于测试）
 )
@@ -0,0 +1,347 @@
+package mqtest
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+/* ========================================================================
+ * MQ Test Kit - 内存 Producer / Consumer
+ * ========================================================================
+ * 职责: 提供无需 Broker / testcontainers 的 mq.Producer / mq.Consumer 实现，
+ *       并记录已发布消息供断言
+ * 语义:
+ *   - 默认同步投递: SendSync/SendAsync 返回前已完成 handler 调用
+ *   - WithManualFlush: 消息先入队，调用 Flush() 时才投递
+ *   - 每个 Consumer 相当于独立消费组，同一主题的多个 Consumer 各收到一份
+ *     （集群与广播订阅行为一致，订阅选项被接受但不影响投递）
+ *   - 主题无订阅者时消息被保留，首个订阅者 Start 后投递
+ *   - handler 返回 error 或 ConsumeRetryLater 时重投（ReconsumeCnt 递增），
+ *     超过最大重试次数后进入 DeadLetters()
+ *   - DelayLevel / DelayTime 不模拟，消息立即可投递
+ * 注册: 导入本包即注册 mq.TypeMemory 工厂（使用 Default() Broker）
+ *
+ * 使用示例:
+ *   import _ "github.com/aisgo/ais-go-pkg/mq/mqtest"
+ *
+ *   broker := mqtest.Default()
+ *   broker.Reset()
+ *   producer, _ := mq.NewProducer(&mq.Config{Type: mq.TypeMemory}, nil)
+ *   _ = svc.PlaceOrder(ctx) // 内部通过 producer 发送消息
+ *   if err := broker.ExpectPublished("order-events", mqtest.HasKey("order-1")); err != nil {
+ *       t.Fatal(err)
+ *   }
+ * ======================================================================== */
+
+// 默认最大重试次数
+const defaultMaxReconsume int32 = 3
+
+// =============================================================================
+// 注册工厂
+// =============================================================================
+
+var defaultBroker = NewBroker()
+
+func init() {
+	Register(defaultBroker)
+}
+
+// Default 返回 mq.TypeMemory 工厂默认使用的 Broker
+func Default() *Broker {
+	return defaultBroker
+}
+
+// Register 将 mq.TypeMemory 工厂切换到指定 Broker
+// 用于需要 WithManualFlush 等选项的场景
+func Register(b *Broker) {
+	mq.RegisterProducerFactory(mq.TypeMemory, func(cfg *mq.Config, logger *zap.Logger) (mq.Producer, error) {
+		return b.Producer(), nil
+	})
+	mq.RegisterConsumerFactory(mq.TypeMemory, func(cfg *mq.Config, logger *zap.Logger) (mq.Consumer, error) {
+		return b.Consumer(), nil
+	})
+}
+
+// =============================================================================
+// Broker
+// =============================================================================
+
+// Option Broker 选项
+type Option func(*Broker)
+
+// WithManualFlush 关闭同步投递，消息在调用 Flush() 时才投递
+func WithManualFlush() Option {
+	return func(b *Broker) {
+		b.manual = true
+	}
+}
+
+// WithMaxReconsume 设置消费失败后的最大重试次数（默认 3，0 表示不重试）
+func WithMaxReconsume(n int32) Option {
+	return func(b *Broker) {
+		if n >= 0 {
+			b.maxReconsume = n
+		}
+	}
+}
+
+// Broker 内存消息代理
+type Broker struct {
+	mu           sync.Mutex
+	manual       bool
+	maxReconsume int32
+	dispatching  bool
+
+	seq         int64
+	offsets     map[string]int64
+	published   []*mq.Message
+	retained    map[string][]*mq.ConsumedMessage
+	subs        map[string][]*subscription
+	queue       []delivery
+	deadLetters []*mq.ConsumedMessage
+}
+
+type subscription struct {
+	consumer *Consumer
+	topic    string
+	handler  mq.MessageHandler
+}
+
+type delivery struct {
+	sub *subscription
+	msg *mq.ConsumedMessage
+}
+
+// NewBroker 创建内存 Broker
+func NewBroker(opts ...Option) *Broker {
+	b := &Broker{maxReconsume: defaultMaxReconsume}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(b)
+		}
+	}
+	b.reset()
+	return b
+}
+
+// Producer 创建绑定到该 Broker 的生产者
+func (b *Broker) Producer() *Producer {
+	return &Producer{broker: b}
+}
+
+// Consumer 创建绑定到该 Broker 的消费者
+func (b *Broker) Consumer() *Consumer {
+	return &Consumer{broker: b, handlers: make(map[string]mq.MessageHandler)}
+}
+
+// Flush 投递所有待投递消息（含重试），直到队列为空
+func (b *Broker) Flush() {
+	b.dispatch()
+}
+
+// Pending 返回待投递消息数
+func (b *Broker) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.queue)
+}
+
+// Published 返回已发布到指定主题的消息（topic 为空时返回全部），按发布顺序排列
+func (b *Broker) Published(topic string) []*mq.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make([]*mq.Message, 0, len(b.published))
+	for _, msg := range b.published {
+		if topic == "" || msg.Topic == topic {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// DeadLetters 返回超过最大重试次数仍消费失败的消息
+func (b *Broker) DeadLetters() []*mq.ConsumedMessage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*mq.ConsumedMessage(nil), b.deadLetters...)
+}
+
+// Reset 清空已发布记录、待投递队列、保留消息与订阅关系
+// 已 Start 的 Consumer 不再接收消息，需重新创建
+func (b *Broker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reset()
+}
+
+func (b *Broker) reset() {
+	b.seq = 0
+	b.offsets = make(map[string]int64)
+	b.published = nil
+	b.retained = make(map[string][]*mq.ConsumedMessage)
+	b.subs = make(map[string][]*subscription)
+	b.queue = nil
+	b.deadLetters = nil
+}
+
+// =============================================================================
+// 发布与投递
+// =============================================================================
+
+func (b *Broker) publish(ctx context.Context, msg *mq.Message) (*mq.SendResult, error) {
+	if msg == nil {
+		return nil, fmt.Errorf("message is required")
+	}
+	if msg.Topic == "" {
+		return nil, fmt.Errorf("message topic is required")
+	}
+
+	stored := cloneMessage(msg)
+	mq.InjectRequestID(ctx, stored)
+
+	b.mu.Lock()
+	b.seq++
+	offset := b.offsets[stored.Topic]
+	b.offsets[stored.Topic] = offset + 1
+	b.published = append(b.published, stored)
+
+	msgID := "mem-" + strconv.FormatInt(b.seq, 10)
+	subs := b.subs[stored.Topic]
+	if len(subs) == 0 {
+		b.retained[stored.Topic] = append(b.retained[stored.Topic], consumedMessage(stored, msgID, offset))
+	}
+	for _, sub := range subs {
+		b.queue = append(b.queue, delivery{sub: sub, msg: consumedMessage(stored, msgID, offset)})
+	}
+	manual := b.manual
+	b.mu.Unlock()
+
+	if !manual {
+		b.dispatch()
+	}
+
+	return &mq.SendResult{
+		MsgID:  msgID,
+		Topic:  stored.Topic,
+		Offset: offset,
+		Status: mq.SendStatusOK,
+	}, nil
+}
+
+// dispatch 依次投递队列中的消息
+// handler 内再次发布的消息追加到队尾，由当前循环继续投递，避免递归
+func (b *Broker) dispatch() {
+	b.mu.Lock()
+	if b.dispatching {
+		b.mu.Unlock()
+		return
+	}
+	b.dispatching = true
+
+	for len(b.queue) > 0 {
+		d := b.queue[0]
+		b.queue = b.queue[1:]
+		b.mu.Unlock()
+
+		ctx := mq.ContextWithRequestID(context.Background(), d.msg)
+		result, err := d.sub.handler(ctx, []*mq.ConsumedMessage{d.msg})
+
+		b.mu.Lock()
+		if err == nil && result != mq.ConsumeRetryLater {
+			continue
+		}
+		if d.msg.ReconsumeCnt >= b.maxReconsume {
+			b.deadLetters = append(b.deadLetters, d.msg)
+			continue
+		}
+		retry := *d.msg
+		retry.ReconsumeCnt++
+		if b.attached(d.sub) {
+			b.queue = append(b.queue, delivery{sub: d.sub, msg: &retry})
+		}
+	}
+
+	b.dispatching = false
+	b.mu.Unlock()
+}
+
+// attach 挂载订阅，并将该主题的保留消息投递给它（调用方持有锁）
+func (b *Broker) attach(sub *subscription) {
+	b.subs[sub.topic] = append(b.subs[sub.topic], sub)
+	for _, msg := range b.retained[sub.topic] {
+		b.queue = append(b.queue, delivery{sub: sub, msg: msg})
+	}
+	delete(b.retained, sub.topic)
+}
+
+// detach 移除消费者的全部订阅与待投递消息
+func (b *Broker) detach(c *Consumer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for topic, subs := range b.subs {
+		kept := subs[:0]
+		for _, sub := range subs {
+			if sub.consumer != c {
+				kept = append(kept, sub)
+			}
+		}
+		if len(kept) == 0 {
+			delete(b.subs, topic)
+		} else {
+			b.subs[topic] = kept
+		}
+	}
+
+	kept := b.queue[:0]
+	for _, d := range b.queue {
+		if d.sub.consumer != c {
+			kept = append(kept, d)
+		}
+	}
+	b.queue = kept
+}
+
+func (b *Broker) attached(target *subscription) bool {
+	for _, sub := range b.subs[target.topic] {
+		if sub == target {
+			return true
+		}
+	}
+	return false
+}
+
+func cloneMessage(msg *mq.Message) *mq.Message {
+	cloned := *msg
+	cloned.Body = append([]byte(nil), msg.Body...)
+	cloned.Properties = make(map[string]string, len(msg.Properties))
+	for k, v := range msg.Properties {
+		cloned.Properties[k] = v
+	}
+	return &cloned
+}
+
+func consumedMessage(msg *mq.Message, msgID string, offset int64) *mq.ConsumedMessage {
+	props := make(map[string]string, len(msg.Properties))
+	for k, v := range msg.Properties {
+		props[k] = v
+	}
+	return &mq.ConsumedMessage{
+		Topic:      msg.Topic,
+		Body:       msg.Body,
+		Key:        msg.Key,
+		Tag:        msg.Tag,
+		Properties: props,
+		MsgID:      msgID,
+		Offset:     offset,
+		BornTime:   time.Now(),
+	}
+}
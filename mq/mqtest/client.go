@@ -0,0 +1,197 @@
+package mqtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+/* ========================================================================
+ * 内存 Producer / Consumer
+ * ========================================================================
+ * 职责: 实现 mq.Producer / mq.Consumer 接口，消息经由所属 Broker 投递
+ * ======================================================================== */
+
+var (
+	_ mq.Producer         = (*Producer)(nil)
+	_ mq.Consumer         = (*Consumer)(nil)
+	_ mq.StatsProvider    = (*Consumer)(nil)
+	_ mq.OptionSubscriber = (*Consumer)(nil)
+)
+
+// =============================================================================
+// Producer
+// =============================================================================
+
+// Producer 内存生产者
+type Producer struct {
+	broker *Broker
+	mu     sync.RWMutex
+	closed bool
+}
+
+// SendSync 同步发送消息（同步投递模式下返回前已完成消费）
+func (p *Producer) SendSync(ctx context.Context, msg *mq.Message) (*mq.SendResult, error) {
+	if p.isClosed() {
+		return nil, fmt.Errorf("producer is closed")
+	}
+	return p.broker.publish(ctx, msg)
+}
+
+// SendAsync 发送消息并在当前 goroutine 中回调，便于测试断言
+func (p *Producer) SendAsync(ctx context.Context, msg *mq.Message, callback mq.SendCallback) error {
+	if p.isClosed() {
+		return fmt.Errorf("producer is closed")
+	}
+	result, err := p.broker.publish(ctx, msg)
+	if callback != nil {
+		callback(result, err)
+	}
+	return nil
+}
+
+// Close 关闭生产者
+func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	return nil
+}
+
+func (p *Producer) isClosed() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.closed
+}
+
+// =============================================================================
+// Consumer
+// =============================================================================
+
+// Consumer 内存消费者
+type Consumer struct {
+	broker   *Broker
+	mu       sync.Mutex
+	handlers map[string]mq.MessageHandler
+	started  bool
+	closed   bool
+}
+
+// Subscribe 订阅主题（Start 之后订阅立即生效）
+func (c *Consumer) Subscribe(topic string, handler mq.MessageHandler) error {
+	if topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+	if handler == nil {
+		return fmt.Errorf("handler is required")
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return fmt.Errorf("consumer is closed")
+	}
+	if _, exists := c.handlers[topic]; exists {
+		c.mu.Unlock()
+		return fmt.Errorf("topic %s already subscribed", topic)
+	}
+	c.handlers[topic] = handler
+	started := c.started
+	c.mu.Unlock()
+
+	if started {
+		c.attach(map[string]mq.MessageHandler{topic: handler})
+	}
+	return nil
+}
+
+// SubscribeWithOptions 按选项订阅主题（实现 mq.OptionSubscriber）
+// 每个 Consumer 本就各收一份消息，优先级与消费模式不影响投递
+func (c *Consumer) SubscribeWithOptions(topic string, handler mq.MessageHandler, _ ...mq.SubscribeOption) error {
+	return c.Subscribe(topic, handler)
+}
+
+// Start 启动消费者，投递此前保留的消息
+func (c *Consumer) Start() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return fmt.Errorf("consumer is closed")
+	}
+	if c.started {
+		c.mu.Unlock()
+		return nil
+	}
+	c.started = true
+	handlers := make(map[string]mq.MessageHandler, len(c.handlers))
+	for topic, handler := range c.handlers {
+		handlers[topic] = handler
+	}
+	c.mu.Unlock()
+
+	c.attach(handlers)
+	return nil
+}
+
+// Close 关闭消费者，丢弃尚未投递给它的消息
+func (c *Consumer) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	c.broker.detach(c)
+	return nil
+}
+
+// Stats 返回已订阅主题的待投递消息数（实现 mq.StatsProvider）
+func (c *Consumer) Stats() mq.ConsumerStats {
+	c.mu.Lock()
+	topics := make([]string, 0, len(c.handlers))
+	for topic := range c.handlers {
+		topics = append(topics, topic)
+	}
+	started := c.started
+	c.mu.Unlock()
+	sort.Strings(topics)
+
+	b := c.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := mq.ConsumerStats{Type: mq.TypeMemory, Topics: make([]mq.TopicStats, 0, len(topics))}
+	for _, topic := range topics {
+		var lag int64
+		if !started {
+			lag = int64(len(b.retained[topic]))
+		}
+		for _, d := range b.queue {
+			if d.sub.consumer == c && d.sub.topic == topic {
+				lag++
+			}
+		}
+		stats.Topics = append(stats.Topics, mq.TopicStats{Topic: topic, Lag: lag})
+	}
+	return stats
+}
+
+func (c *Consumer) attach(handlers map[string]mq.MessageHandler) {
+	b := c.broker
+
+	b.mu.Lock()
+	for topic, handler := range handlers {
+		b.attach(&subscription{consumer: c, topic: topic, handler: handler})
+	}
+	manual := b.manual
+	b.mu.Unlock()
+
+	if !manual {
+		b.dispatch()
+	}
+}
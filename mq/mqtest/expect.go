@@ -0,0 +1,92 @@
+package mqtest
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+/* ========================================================================
+ * 断言辅助
+ * ========================================================================
+ * 职责: 基于 Broker 的发布记录断言消息是否已发送
+ * 说明: 断言返回 error 而非直接 t.Fatal，调用方自行决定失败方式
+ * ======================================================================== */
+
+// Matcher 消息匹配函数
+type Matcher func(msg *mq.Message) bool
+
+// Any 匹配任意消息
+func Any() Matcher {
+	return func(*mq.Message) bool { return true }
+}
+
+// All 所有匹配器均满足时匹配
+func All(matchers ...Matcher) Matcher {
+	return func(msg *mq.Message) bool {
+		for _, m := range matchers {
+			if m != nil && !m(msg) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// HasKey 匹配消息键
+func HasKey(key string) Matcher {
+	return func(msg *mq.Message) bool { return msg.Key == key }
+}
+
+// HasTag 匹配标签
+func HasTag(tag string) Matcher {
+	return func(msg *mq.Message) bool { return msg.Tag == tag }
+}
+
+// HasProperty 匹配属性值
+func HasProperty(key, value string) Matcher {
+	return func(msg *mq.Message) bool {
+		v, ok := msg.Properties[key]
+		return ok && v == value
+	}
+}
+
+// BodyEqual 匹配完整消息体
+func BodyEqual(body []byte) Matcher {
+	return func(msg *mq.Message) bool { return bytes.Equal(msg.Body, body) }
+}
+
+// BodyContains 匹配包含指定片段的消息体
+func BodyContains(part string) Matcher {
+	return func(msg *mq.Message) bool { return bytes.Contains(msg.Body, []byte(part)) }
+}
+
+// ExpectPublished 断言指定主题上至少有一条消息满足 matcher（nil 表示任意消息）
+func (b *Broker) ExpectPublished(topic string, matcher Matcher) error {
+	published := b.Published(topic)
+	if findMatch(published, matcher) != nil {
+		return nil
+	}
+	return fmt.Errorf("mqtest: no matching message published to topic %q (%d published)", topic, len(published))
+}
+
+// ExpectNotPublished 断言指定主题上没有消息满足 matcher（nil 表示任意消息）
+func (b *Broker) ExpectNotPublished(topic string, matcher Matcher) error {
+	if msg := findMatch(b.Published(topic), matcher); msg != nil {
+		return fmt.Errorf("mqtest: unexpected message published to topic %q (key=%q)", topic, msg.Key)
+	}
+	return nil
+}
+
+func findMatch(msgs []*mq.Message, matcher Matcher) *mq.Message {
+	if matcher == nil {
+		matcher = Any()
+	}
+	for _, msg := range msgs {
+		if matcher(msg) {
+			return msg
+		}
+	}
+	return nil
+}
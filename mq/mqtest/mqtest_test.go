@@ -0,0 +1,207 @@
+package mqtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/requestid"
+)
+
+type recorder struct {
+	msgs []*mq.ConsumedMessage
+	ctxs []context.Context
+}
+
+func (r *recorder) handle(ctx context.Context, msgs []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+	r.msgs = append(r.msgs, msgs...)
+	r.ctxs = append(r.ctxs, ctx)
+	return mq.ConsumeSuccess, nil
+}
+
+func TestSyncDelivery(t *testing.T) {
+	b := NewBroker()
+	consumer := b.Consumer()
+	var rec recorder
+	if err := consumer.Subscribe("orders", rec.handle); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	ctx := requestid.WithContext(context.Background(), "req-1")
+	res, err := b.Producer().SendSync(ctx, mq.NewMessage("orders", []byte(`{"id":1}`)).WithKey("o-1"))
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if res.MsgID == "" || res.Offset != 0 || res.Status != mq.SendStatusOK {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if len(rec.msgs) != 1 || rec.msgs[0].Key != "o-1" || rec.msgs[0].MsgID != res.MsgID {
+		t.Fatalf("expected synchronous delivery, got %+v", rec.msgs)
+	}
+	if requestid.FromContext(rec.ctxs[0]) != "req-1" {
+		t.Fatalf("request id not propagated")
+	}
+
+	if err := b.ExpectPublished("orders", All(HasKey("o-1"), BodyContains(`"id":1`))); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.ExpectPublished("orders", HasKey("o-2")); err == nil {
+		t.Fatalf("expected mismatch error")
+	}
+	if err := b.ExpectNotPublished("payments", nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManualFlush(t *testing.T) {
+	b := NewBroker(WithManualFlush())
+	consumer := b.Consumer()
+	var rec recorder
+	_ = consumer.Subscribe("orders", rec.handle)
+	_ = consumer.Start()
+
+	var callbackErr error
+	called := false
+	err := b.Producer().SendAsync(context.Background(), mq.NewMessage("orders", []byte("a")), func(_ *mq.SendResult, err error) {
+		called, callbackErr = true, err
+	})
+	if err != nil || !called || callbackErr != nil {
+		t.Fatalf("unexpected async send: %v %v %v", err, called, callbackErr)
+	}
+	if len(rec.msgs) != 0 || b.Pending() != 1 {
+		t.Fatalf("expected message to wait for flush: delivered=%d pending=%d", len(rec.msgs), b.Pending())
+	}
+
+	b.Flush()
+	if len(rec.msgs) != 1 || b.Pending() != 0 {
+		t.Fatalf("expected delivery on flush: delivered=%d pending=%d", len(rec.msgs), b.Pending())
+	}
+}
+
+func TestRetainedUntilStart(t *testing.T) {
+	b := NewBroker()
+	producer := b.Producer()
+	_, _ = producer.SendSync(context.Background(), mq.NewMessage("orders", []byte("early")))
+
+	consumer := b.Consumer()
+	var rec recorder
+	_ = consumer.Subscribe("orders", rec.handle)
+	if len(rec.msgs) != 0 {
+		t.Fatalf("expected no delivery before start")
+	}
+	_ = consumer.Start()
+	if len(rec.msgs) != 1 || string(rec.msgs[0].Body) != "early" {
+		t.Fatalf("expected retained message after start, got %d", len(rec.msgs))
+	}
+}
+
+func TestFanOutAndHandlerPublish(t *testing.T) {
+	b := NewBroker()
+	producer := b.Producer()
+
+	var first, second, replies recorder
+	c1 := b.Consumer()
+	_ = c1.Subscribe("orders", func(ctx context.Context, msgs []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+		_, _ = first.handle(ctx, msgs)
+		_, err := producer.SendSync(ctx, mq.NewMessage("replies", msgs[0].Body))
+		return mq.ConsumeSuccess, err
+	})
+	_ = c1.Start()
+
+	c2 := b.Consumer()
+	_ = c2.Subscribe("orders", second.handle)
+	_ = c2.Subscribe("replies", replies.handle)
+	_ = c2.Start()
+
+	_, _ = producer.SendSync(context.Background(), mq.NewMessage("orders", []byte("x")))
+	if len(first.msgs) != 1 || len(second.msgs) != 1 {
+		t.Fatalf("expected fan-out to both consumers: %d %d", len(first.msgs), len(second.msgs))
+	}
+	if len(replies.msgs) != 1 || string(replies.msgs[0].Body) != "x" {
+		t.Fatalf("expected reply published from handler to be delivered")
+	}
+}
+
+func TestRetryAndDeadLetter(t *testing.T) {
+	b := NewBroker(WithMaxReconsume(2))
+	consumer := b.Consumer()
+	attempts := 0
+	_ = consumer.Subscribe("orders", func(ctx context.Context, msgs []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+		if msgs[0].ReconsumeCnt != int32(attempts) {
+			t.Errorf("unexpected reconsume count %d at attempt %d", msgs[0].ReconsumeCnt, attempts)
+		}
+		attempts++
+		return mq.ConsumeRetryLater, errors.New("boom")
+	})
+	_ = consumer.Start()
+
+	_, _ = b.Producer().SendSync(context.Background(), mq.NewMessage("orders", []byte("x")))
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+	if dl := b.DeadLetters(); len(dl) != 1 || dl[0].ReconsumeCnt != 2 {
+		t.Fatalf("unexpected dead letters: %+v", dl)
+	}
+}
+
+func TestCloseAndReset(t *testing.T) {
+	b := NewBroker()
+	producer := b.Producer()
+	consumer := b.Consumer()
+	var rec recorder
+	_ = consumer.Subscribe("orders", rec.handle)
+	_ = consumer.Start()
+	_ = consumer.Close()
+
+	_, _ = producer.SendSync(context.Background(), mq.NewMessage("orders", nil))
+	if len(rec.msgs) != 0 {
+		t.Fatalf("closed consumer should not receive messages")
+	}
+	if err := consumer.Subscribe("payments", rec.handle); err == nil {
+		t.Fatalf("expected subscribe error after close")
+	}
+
+	b.Reset()
+	if len(b.Published("")) != 0 {
+		t.Fatalf("expected reset to clear published messages")
+	}
+
+	_ = producer.Close()
+	if _, err := producer.SendSync(context.Background(), mq.NewMessage("orders", nil)); err == nil {
+		t.Fatalf("expected send error after close")
+	}
+	if _, err := b.Producer().SendSync(context.Background(), &mq.Message{}); err == nil {
+		t.Fatalf("expected error for empty topic")
+	}
+}
+
+func TestFactoryRegistration(t *testing.T) {
+	broker := Default()
+	broker.Reset()
+	t.Cleanup(broker.Reset)
+
+	cfg := &mq.Config{Type: mq.TypeMemory}
+	producer, err := mq.NewProducer(cfg, nil)
+	if err != nil {
+		t.Fatalf("new producer: %v", err)
+	}
+	consumer, err := mq.NewConsumer(cfg, nil)
+	if err != nil {
+		t.Fatalf("new consumer: %v", err)
+	}
+	var rec recorder
+	_ = consumer.Subscribe("orders", rec.handle)
+	_ = consumer.Start()
+
+	_, _ = producer.SendSync(context.Background(), mq.NewMessage("orders", []byte("x")).WithTag("created"))
+	if len(rec.msgs) != 1 {
+		t.Fatalf("expected delivery through factory-created pair")
+	}
+	if err := broker.ExpectPublished("orders", HasTag("created")); err != nil {
+		t.Fatal(err)
+	}
+}
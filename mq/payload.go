@@ -0,0 +1,263 @@
+package mq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/aisgo/ais-go-pkg/utils/crypto"
+)
+
+/* ========================================================================
+ * 消息体编码 - 压缩与加密
+ * ========================================================================
+ * 职责: 发送前对 Body 压缩（超过阈值）并 AES-256-GCM 加密，消费前按属性还原
+ * 属性（跨版本兼容，消费端仅依据属性还原，无属性的消息原样交给 handler）:
+ *   X-MQ-Content-Encoding: gzip / zstd
+ *   X-MQ-Encryption:       aes-256-gcm
+ *   X-MQ-Key-Version:      加密使用的密钥版本（crypto.Keyring）
+ * 顺序: 发送 压缩 -> 加密；消费 解密 -> 解压
+ * 说明: 密钥来自 crypto.Keyring，轮换时保留旧版本即可解密历史消息
+ *
+ * 配置示例:
+ *   mq:
+ *     payload:
+ *       compression: zstd        # none / gzip / zstd
+ *       compress_threshold: 1024 # 字节，Body 不小于该值才压缩
+ *       encrypt: true            # 需提供 *crypto.Keyring
+ * ======================================================================== */
+
+// 消息体编码属性
+const (
+	PropertyContentEncoding = "X-MQ-Content-Encoding"
+	PropertyEncryption      = "X-MQ-Encryption"
+	PropertyKeyVersion      = "X-MQ-Key-Version"
+)
+
+// 压缩算法
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+	CompressionZstd = "zstd"
+)
+
+// EncryptionAESGCM 加密算法标识
+const EncryptionAESGCM = "aes-256-gcm"
+
+// DefaultCompressThreshold 默认压缩阈值（字节）
+const DefaultCompressThreshold = 1024
+
+// PayloadConfig 消息体编码配置
+type PayloadConfig struct {
+	Compression       string `yaml:"compression" mapstructure:"compression"`               // none / gzip / zstd
+	CompressThreshold int    `yaml:"compress_threshold" mapstructure:"compress_threshold"` // 默认 1024
+	Encrypt           bool   `yaml:"encrypt" mapstructure:"encrypt"`
+}
+
+// Enabled 是否启用任一编码
+func (c PayloadConfig) Enabled() bool {
+	return (c.Compression != "" && c.Compression != CompressionNone) || c.Encrypt
+}
+
+// PayloadCodec 消息体编解码器（并发安全）
+type PayloadCodec struct {
+	compression string
+	threshold   int
+	keyring     *crypto.Keyring
+	encrypt     bool
+}
+
+// NewPayloadCodec 创建编解码器
+// keyring 用于加密与解密；cfg.Encrypt 为 true 时必须提供
+func NewPayloadCodec(cfg PayloadConfig, keyring *crypto.Keyring) (*PayloadCodec, error) {
+	compression := strings.ToLower(cfg.Compression)
+	switch compression {
+	case "", CompressionNone:
+		compression = ""
+	case CompressionGzip, CompressionZstd:
+	default:
+		return nil, fmt.Errorf("unsupported mq compression %q", cfg.Compression)
+	}
+	if cfg.Encrypt && keyring == nil {
+		return nil, errors.New("mq payload encryption requires a crypto.Keyring")
+	}
+	threshold := cfg.CompressThreshold
+	if threshold <= 0 {
+		threshold = DefaultCompressThreshold
+	}
+	return &PayloadCodec{
+		compression: compression,
+		threshold:   threshold,
+		keyring:     keyring,
+		encrypt:     cfg.Encrypt,
+	}, nil
+}
+
+// Encode 返回编码后的消息副本，原消息不变
+func (c *PayloadCodec) Encode(msg *Message) (*Message, error) {
+	if msg == nil {
+		return nil, errors.New("message is required")
+	}
+	if _, encoded := msg.Properties[PropertyContentEncoding]; encoded {
+		return msg, nil
+	}
+	if _, encrypted := msg.Properties[PropertyEncryption]; encrypted {
+		return msg, nil
+	}
+
+	out := *msg
+	out.Properties = make(map[string]string, len(msg.Properties)+3)
+	for k, v := range msg.Properties {
+		out.Properties[k] = v
+	}
+
+	body := msg.Body
+	if c.compression != "" && len(body) >= c.threshold {
+		compressed, err := compress(c.compression, body)
+		if err != nil {
+			return nil, fmt.Errorf("compress message body: %w", err)
+		}
+		body = compressed
+		out.Properties[PropertyContentEncoding] = c.compression
+	}
+	if c.encrypt {
+		version, sealed, err := c.keyring.Seal(body, nil)
+		if err != nil {
+			return nil, fmt.Errorf("encrypt message body: %w", err)
+		}
+		body = sealed
+		out.Properties[PropertyEncryption] = EncryptionAESGCM
+		out.Properties[PropertyKeyVersion] = version
+	}
+	out.Body = body
+	return &out, nil
+}
+
+// Decode 返回还原后的消息副本（移除编码属性），未编码的消息原样返回
+func (c *PayloadCodec) Decode(msg *ConsumedMessage) (*ConsumedMessage, error) {
+	if msg == nil {
+		return nil, nil
+	}
+	encryption := msg.Properties[PropertyEncryption]
+	encoding := msg.Properties[PropertyContentEncoding]
+	if encryption == "" && encoding == "" {
+		return msg, nil
+	}
+
+	body := msg.Body
+	if encryption != "" {
+		if encryption != EncryptionAESGCM {
+			return nil, fmt.Errorf("unsupported mq encryption %q", encryption)
+		}
+		if c.keyring == nil {
+			return nil, errors.New("mq payload is encrypted but no crypto.Keyring is configured")
+		}
+		plain, err := c.keyring.Open(msg.Properties[PropertyKeyVersion], body, nil)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt message body: %w", err)
+		}
+		body = plain
+	}
+	if encoding != "" {
+		plain, err := decompress(encoding, body)
+		if err != nil {
+			return nil, fmt.Errorf("decompress message body: %w", err)
+		}
+		body = plain
+	}
+
+	out := *msg
+	out.Body = body
+	out.Properties = make(map[string]string, len(msg.Properties))
+	for k, v := range msg.Properties {
+		switch k {
+		case PropertyContentEncoding, PropertyEncryption, PropertyKeyVersion:
+		default:
+			out.Properties[k] = v
+		}
+	}
+	return &out, nil
+}
+
+// ProducerMiddleware 发送前编码
+func (c *PayloadCodec) ProducerMiddleware() ProducerMiddleware {
+	return SendFunc(func(_ context.Context, msg *Message) (*Message, error) {
+		return c.Encode(msg)
+	})
+}
+
+// ConsumerMiddleware 消费前解码，解码失败时返回 ConsumeRetryLater 与错误
+func (c *PayloadCodec) ConsumerMiddleware() ConsumerMiddleware {
+	return func(next MessageHandler) MessageHandler {
+		return func(ctx context.Context, msgs []*ConsumedMessage) (ConsumeResult, error) {
+			decoded := make([]*ConsumedMessage, len(msgs))
+			for i, msg := range msgs {
+				out, err := c.Decode(msg)
+				if err != nil {
+					return ConsumeRetryLater, err
+				}
+				decoded[i] = out
+			}
+			return next(ctx, decoded)
+		}
+	}
+}
+
+// =============================================================================
+// 压缩算法
+// =============================================================================
+
+var (
+	zstdEncoderOnce sync.Once
+	zstdEncoder     *zstd.Encoder
+	zstdDecoderOnce sync.Once
+	zstdDecoder     *zstd.Decoder
+)
+
+func compress(algo string, body []byte) ([]byte, error) {
+	switch algo {
+	case CompressionGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		zstdEncoderOnce.Do(func() {
+			zstdEncoder, _ = zstd.NewWriter(nil)
+		})
+		return zstdEncoder.EncodeAll(body, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", algo)
+	}
+}
+
+func decompress(algo string, body []byte) ([]byte, error) {
+	switch algo {
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return io.ReadAll(r)
+	case CompressionZstd:
+		zstdDecoderOnce.Do(func() {
+			zstdDecoder, _ = zstd.NewReader(nil)
+		})
+		return zstdDecoder.DecodeAll(body, nil)
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", algo)
+	}
+}
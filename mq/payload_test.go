@@ -0,0 +1,161 @@
+package mq_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/mq/mqtest"
+	"github.com/aisgo/ais-go-pkg/utils/crypto"
+)
+
+func testKeyring(t *testing.T, primary string, versions ...string) *crypto.Keyring {
+	t.Helper()
+	keys := make(map[string][]byte, len(versions))
+	for _, v := range versions {
+		key := make([]byte, crypto.KeySize)
+		if _, err := rand.Read(key); err != nil {
+			t.Fatalf("rand: %v", err)
+		}
+		keys[v] = key
+	}
+	kr, err := crypto.NewKeyring(primary, keys)
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	return kr
+}
+
+func consumed(msg *mq.Message) *mq.ConsumedMessage {
+	return &mq.ConsumedMessage{Topic: msg.Topic, Body: msg.Body, Properties: msg.Properties}
+}
+
+func TestPayloadCodecRoundTrip(t *testing.T) {
+	large := []byte(strings.Repeat("payload-", 512))
+	kr := testKeyring(t, "1", "1")
+
+	for _, algo := range []string{mq.CompressionGzip, mq.CompressionZstd} {
+		codec, err := mq.NewPayloadCodec(mq.PayloadConfig{Compression: algo, Encrypt: true}, kr)
+		if err != nil {
+			t.Fatalf("%s: new codec: %v", algo, err)
+		}
+		msg := mq.NewMessage("t", large).WithProperty("k", "v")
+		encoded, err := codec.Encode(msg)
+		if err != nil {
+			t.Fatalf("%s: encode: %v", algo, err)
+		}
+		if !bytes.Equal(msg.Body, large) || len(msg.Properties) != 1 {
+			t.Fatalf("%s: original message mutated", algo)
+		}
+		if encoded.Properties[mq.PropertyContentEncoding] != algo ||
+			encoded.Properties[mq.PropertyEncryption] != mq.EncryptionAESGCM ||
+			encoded.Properties[mq.PropertyKeyVersion] != "1" {
+			t.Fatalf("%s: unexpected properties: %v", algo, encoded.Properties)
+		}
+		if len(encoded.Body) >= len(large) {
+			t.Fatalf("%s: expected compressed body", algo)
+		}
+
+		decoded, err := codec.Decode(consumed(encoded))
+		if err != nil {
+			t.Fatalf("%s: decode: %v", algo, err)
+		}
+		if !bytes.Equal(decoded.Body, large) || decoded.Properties["k"] != "v" || len(decoded.Properties) != 1 {
+			t.Fatalf("%s: unexpected decoded message: %v", algo, decoded.Properties)
+		}
+	}
+}
+
+func TestPayloadCodecThresholdAndPassthrough(t *testing.T) {
+	codec, err := mq.NewPayloadCodec(mq.PayloadConfig{Compression: mq.CompressionGzip, CompressThreshold: 100}, nil)
+	if err != nil {
+		t.Fatalf("new codec: %v", err)
+	}
+	small, err := codec.Encode(mq.NewMessage("t", []byte("tiny")))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if _, ok := small.Properties[mq.PropertyContentEncoding]; ok || string(small.Body) != "tiny" {
+		t.Fatalf("expected small body untouched: %v", small.Properties)
+	}
+
+	plain := &mq.ConsumedMessage{Body: []byte("legacy")}
+	out, err := codec.Decode(plain)
+	if err != nil || out != plain {
+		t.Fatalf("expected passthrough for unencoded message: %v", err)
+	}
+
+	if _, err := mq.NewPayloadCodec(mq.PayloadConfig{Encrypt: true}, nil); err == nil {
+		t.Fatalf("expected error without keyring")
+	}
+	if _, err := mq.NewPayloadCodec(mq.PayloadConfig{Compression: "lz4"}, nil); err == nil {
+		t.Fatalf("expected error for unsupported compression")
+	}
+}
+
+func TestPayloadCodecKeyRotation(t *testing.T) {
+	kr := testKeyring(t, "1", "1", "2")
+	codec, _ := mq.NewPayloadCodec(mq.PayloadConfig{Encrypt: true}, kr)
+
+	old, err := codec.Encode(mq.NewMessage("t", []byte("secret")))
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if err := kr.SetPrimary("2"); err != nil {
+		t.Fatalf("set primary: %v", err)
+	}
+	decoded, err := codec.Decode(consumed(old))
+	if err != nil || string(decoded.Body) != "secret" {
+		t.Fatalf("expected old key version to decrypt: %v", err)
+	}
+
+	// 仅配置新版本密钥的消费端无法解密旧消息
+	other, _ := mq.NewPayloadCodec(mq.PayloadConfig{}, testKeyring(t, "2", "2"))
+	if _, err := other.Decode(consumed(old)); err == nil {
+		t.Fatalf("expected unknown key version error")
+	}
+}
+
+func TestModuleAppliesPayloadCodec(t *testing.T) {
+	broker := mqtest.Default()
+	broker.Reset()
+	t.Cleanup(broker.Reset)
+
+	kr := testKeyring(t, "1", "1")
+	cfg := &mq.Config{Type: mq.TypeMemory, Payload: mq.PayloadConfig{Compression: mq.CompressionZstd, CompressThreshold: 1, Encrypt: true}}
+
+	var got []string
+	app := fxtest.New(t,
+		fx.Supply(cfg, kr, zap.NewNop()),
+		mq.Module,
+		fx.Invoke(func(c mq.Consumer) error {
+			return c.Subscribe("secure", func(_ context.Context, msgs []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+				for _, m := range msgs {
+					got = append(got, string(m.Body))
+				}
+				return mq.ConsumeSuccess, nil
+			})
+		}),
+		fx.Invoke(func(p mq.Producer) error {
+			_, err := p.SendSync(context.Background(), mq.NewMessage("secure", []byte("card=4111")))
+			return err
+		}),
+	)
+	app.RequireStart()
+	defer app.RequireStop()
+
+	published := broker.Published("secure")
+	if len(published) != 1 || bytes.Contains(published[0].Body, []byte("4111")) {
+		t.Fatalf("expected encrypted payload on the wire")
+	}
+	if len(got) != 1 || got[0] != "card=4111" {
+		t.Fatalf("unexpected consumed bodies: %v", got)
+	}
+}
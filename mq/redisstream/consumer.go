@@ -0,0 +1,416 @@
+package redisstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+/* ========================================================================
+ * Redis Streams Consumer - 轻量消息消费者
+ * ========================================================================
+ * 职责: 实现 mq.Consumer 接口，基于消费组（XREADGROUP）集群消费
+ * 语义:
+ *   - handler 成功后 XACK；失败或返回 ConsumeRetryLater 时保留在 PEL 中
+ *   - 空闲超过 ClaimMinIdle 的未确认消息（包括崩溃实例遗留的）定期通过
+ *     XAUTOCLAIM 认领并重投，ReconsumeCnt 为此前的投递次数
+ *   - 投递次数超过 MaxRetries 的消息转入死信 Stream（Key + DeadLetterSuffix）后确认
+ *   - 单个消费者内按读取顺序串行处理
+ *   - mq.WithBroadcast 订阅使用实例独立的消费组（<group>-<name>，仅消费新消息），
+ *     与集群订阅分别在各自的循环中消费
+ * ======================================================================== */
+
+// =============================================================================
+// 注册工厂
+// =============================================================================
+
+func init() {
+	mq.RegisterConsumerFactory(mq.TypeRedis, NewConsumerAdapter)
+}
+
+// =============================================================================
+// Consumer 适配器
+// =============================================================================
+
+// ConsumerAdapter Redis Streams 消费者适配器
+type ConsumerAdapter struct {
+	client    redis.UniversalClient
+	owned     bool
+	prefix    string
+	config    mq.RedisStreamConsumerConfig
+	logger    *zap.Logger
+	handlers  map[string]mq.MessageHandler
+	broadcast map[string]bool
+	topics    []string
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	mu        sync.RWMutex
+	closed    bool
+}
+
+// NewConsumerAdapter 按配置创建 Redis Streams 消费者适配器
+func NewConsumerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Consumer, error) {
+	if cfg.Redis == nil {
+		return nil, fmt.Errorf("redis stream config is required")
+	}
+	client, err := newClient(cfg.Redis)
+	if err != nil {
+		return nil, err
+	}
+	c := NewConsumer(client, cfg.Redis, logger)
+	c.owned = true
+
+	logger.Info("Redis stream consumer created",
+		zap.String("group", c.config.Group),
+		zap.String("name", c.config.Name),
+		zap.Strings("addrs", cfg.Redis.Addrs),
+	)
+	return c, nil
+}
+
+// NewConsumer 基于已有 Redis 客户端创建消费者（Close 不会关闭 client）
+func NewConsumer(client redis.UniversalClient, cfg *mq.RedisStreamConfig, logger *zap.Logger) *ConsumerAdapter {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	consumerCfg := cfg.Consumer
+	defaults := mq.DefaultRedisStreamConfig().Consumer
+	if consumerCfg.Group == "" {
+		consumerCfg.Group = defaults.Group
+	}
+	if consumerCfg.Name == "" {
+		consumerCfg.Name, _ = os.Hostname()
+		if consumerCfg.Name == "" {
+			consumerCfg.Name = fmt.Sprintf("consumer-%d", os.Getpid())
+		}
+	}
+	if consumerCfg.StartID == "" {
+		consumerCfg.StartID = defaults.StartID
+	}
+	if consumerCfg.BatchSize <= 0 {
+		consumerCfg.BatchSize = defaults.BatchSize
+	}
+	if consumerCfg.Block <= 0 {
+		consumerCfg.Block = defaults.Block
+	}
+	if consumerCfg.ClaimMinIdle <= 0 {
+		consumerCfg.ClaimMinIdle = defaults.ClaimMinIdle
+	}
+	if consumerCfg.ClaimInterval <= 0 {
+		consumerCfg.ClaimInterval = defaults.ClaimInterval
+	}
+	if consumerCfg.MaxRetries <= 0 {
+		consumerCfg.MaxRetries = defaults.MaxRetries
+	}
+	if consumerCfg.DeadLetterSuffix == "" {
+		consumerCfg.DeadLetterSuffix = defaults.DeadLetterSuffix
+	}
+
+	return &ConsumerAdapter{
+		client:    client,
+		prefix:    cfg.KeyPrefix,
+		config:    consumerCfg,
+		logger:    logger,
+		handlers:  make(map[string]mq.MessageHandler),
+		broadcast: make(map[string]bool),
+	}
+}
+
+var _ mq.OptionSubscriber = (*ConsumerAdapter)(nil)
+
+// Subscribe 订阅主题（需在 Start 之前调用）
+func (c *ConsumerAdapter) Subscribe(topic string, handler mq.MessageHandler) error {
+	return c.SubscribeWithOptions(topic, handler)
+}
+
+// SubscribeWithOptions 按选项订阅主题（实现 mq.OptionSubscriber），优先级被忽略
+func (c *ConsumerAdapter) SubscribeWithOptions(topic string, handler mq.MessageHandler, opts ...mq.SubscribeOption) error {
+	if handler == nil {
+		return fmt.Errorf("handler is required")
+	}
+	broadcast := mq.ApplySubscribeOptions(opts...).Broadcast()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, exists := c.handlers[topic]; !exists {
+		c.topics = append(c.topics, topic)
+	}
+	c.handlers[topic] = handler
+	c.broadcast[topic] = broadcast
+
+	c.logger.Info("subscribed to topic", zap.String("topic", topic), zap.Bool("broadcast", broadcast))
+	return nil
+}
+
+// Start 创建消费组并启动消费
+func (c *ConsumerAdapter) Start() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return fmt.Errorf("consumer is closed")
+	}
+	if c.cancel != nil {
+		c.mu.Unlock()
+		return fmt.Errorf("consumer already started")
+	}
+	var topics, broadcastTopics []string
+	for _, topic := range c.topics {
+		if c.broadcast[topic] {
+			broadcastTopics = append(broadcastTopics, topic)
+		} else {
+			topics = append(topics, topic)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(topics) == 0 && len(broadcastTopics) == 0 {
+		return fmt.Errorf("no topics subscribed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := c.createGroups(ctx, c.config.Group, c.config.StartID, topics); err != nil {
+		cancel()
+		return err
+	}
+	// 广播消费组按实例区分，只消费新消息
+	if err := c.createGroups(ctx, c.BroadcastGroup(), "$", broadcastTopics); err != nil {
+		cancel()
+		return err
+	}
+
+	c.mu.Lock()
+	c.cancel = cancel
+	c.mu.Unlock()
+
+	if len(topics) > 0 {
+		c.wg.Add(1)
+		go c.run(ctx, c.config.Group, topics)
+	}
+	if len(broadcastTopics) > 0 {
+		c.wg.Add(1)
+		go c.run(ctx, c.BroadcastGroup(), broadcastTopics)
+	}
+
+	c.logger.Info("Redis stream consumer started",
+		zap.Strings("topics", topics),
+		zap.Strings("broadcast_topics", broadcastTopics),
+	)
+	return nil
+}
+
+// BroadcastGroup 广播订阅使用的消费组名（<group>-<name>）
+func (c *ConsumerAdapter) BroadcastGroup() string {
+	return c.config.Group + "-" + c.config.Name
+}
+
+func (c *ConsumerAdapter) createGroups(ctx context.Context, group, startID string, topics []string) error {
+	for _, topic := range topics {
+		err := c.client.XGroupCreateMkStream(ctx, c.prefix+topic, group, startID).Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("failed to create consumer group for %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// Close 停止消费并等待当前消息处理完成
+func (c *ConsumerAdapter) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	cancel := c.cancel
+	c.cancel = nil
+	c.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+
+	c.wg.Wait()
+
+	if c.owned {
+		if err := c.client.Close(); err != nil {
+			c.logger.Error("failed to close redis client", zap.Error(err))
+			return err
+		}
+	}
+	c.logger.Info("Redis stream consumer closed")
+	return nil
+}
+
+// =============================================================================
+// 消费循环
+// =============================================================================
+
+// run 在 group 内交替执行认领与读取，直到 ctx 取消
+func (c *ConsumerAdapter) run(ctx context.Context, group string, topics []string) {
+	defer c.wg.Done()
+
+	streams := make([]string, 0, len(topics)*2)
+	for _, topic := range topics {
+		streams = append(streams, c.prefix+topic)
+	}
+	for range topics {
+		streams = append(streams, ">")
+	}
+
+	var lastClaim time.Time
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= c.config.ClaimInterval {
+			for _, topic := range topics {
+				c.claim(ctx, group, topic)
+			}
+			lastClaim = time.Now()
+		}
+
+		res, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: c.config.Name,
+			Streams:  streams,
+			Count:    c.config.BatchSize,
+			Block:    c.config.Block,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) || ctx.Err() != nil {
+				continue
+			}
+			c.logger.Error("consumer error", zap.Error(err))
+			// 防止 Redis 不可用时空转
+			select {
+			case <-ctx.Done():
+			case <-time.After(2 * time.Second):
+			}
+			continue
+		}
+
+		for _, stream := range res {
+			topic := strings.TrimPrefix(stream.Stream, c.prefix)
+			for _, entry := range stream.Messages {
+				if ctx.Err() != nil {
+					return
+				}
+				c.handle(ctx, group, topic, entry, 0)
+			}
+		}
+	}
+}
+
+// claim 认领空闲超时的未确认消息并重投，超过最大投递次数的转入死信
+func (c *ConsumerAdapter) claim(ctx context.Context, group, topic string) {
+	stream := c.prefix + topic
+	start := "0-0"
+	for ctx.Err() == nil {
+		entries, next, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    group,
+			Consumer: c.config.Name,
+			MinIdle:  c.config.ClaimMinIdle,
+			Start:    start,
+			Count:    c.config.BatchSize,
+		}).Result()
+		if err != nil {
+			if ctx.Err() == nil {
+				c.logger.Error("failed to claim pending messages", zap.String("topic", topic), zap.Error(err))
+			}
+			return
+		}
+
+		for _, entry := range entries {
+			if ctx.Err() != nil {
+				return
+			}
+			deliveries := c.deliveryCount(ctx, group, stream, entry.ID)
+			if deliveries > c.config.MaxRetries {
+				c.deadLetter(ctx, group, topic, entry, deliveries)
+				continue
+			}
+			c.handle(ctx, group, topic, entry, int32(max(deliveries-1, 0)))
+		}
+
+		if next == "" || next == "0-0" {
+			return
+		}
+		start = next
+	}
+}
+
+// deliveryCount 查询消息的投递次数（含本次认领），查询失败时返回 0
+func (c *ConsumerAdapter) deliveryCount(ctx context.Context, group, stream, id string) int64 {
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Start:  id,
+		End:    id,
+		Count:  1,
+	}).Result()
+	if err != nil || len(pending) == 0 {
+		return 0
+	}
+	return pending[0].RetryCount
+}
+
+// handle 处理单条消息，成功后确认
+func (c *ConsumerAdapter) handle(ctx context.Context, group, topic string, entry redis.XMessage, reconsume int32) {
+	c.mu.RLock()
+	handler, ok := c.handlers[topic]
+	c.mu.RUnlock()
+	if !ok {
+		c.logger.Warn("no handler for topic", zap.String("topic", topic))
+		return
+	}
+
+	msg := decodeMessage(topic, entry)
+	msg.ReconsumeCnt = reconsume
+	msgCtx := mq.ContextWithRequestID(ctx, msg)
+
+	result, err := handler(msgCtx, []*mq.ConsumedMessage{msg})
+	if err == nil && result != mq.ConsumeRetryLater {
+		if err := c.client.XAck(ctx, c.prefix+topic, group, entry.ID).Err(); err != nil {
+			c.logger.Error("failed to ack message", zap.String("topic", topic), zap.String("id", entry.ID), zap.Error(err))
+		}
+		return
+	}
+
+	if err == nil {
+		err = fmt.Errorf("consume retry later")
+	}
+	c.logger.Warn("message handling failed, will be redelivered after claim_min_idle",
+		zap.String("topic", topic),
+		zap.String("id", entry.ID),
+		zap.Int32("reconsume", reconsume),
+		zap.Error(err),
+	)
+}
+
+// deadLetter 将消息写入死信 Stream 并确认
+func (c *ConsumerAdapter) deadLetter(ctx context.Context, group, topic string, entry redis.XMessage, deliveries int64) {
+	stream := c.prefix + topic
+	dlq := stream + c.config.DeadLetterSuffix
+	if err := c.client.XAdd(ctx, &redis.XAddArgs{Stream: dlq, Values: entry.Values}).Err(); err != nil {
+		c.logger.Error("failed to move message to dead letter stream",
+			zap.String("topic", topic), zap.String("id", entry.ID), zap.Error(err))
+		return
+	}
+	if err := c.client.XAck(ctx, stream, group, entry.ID).Err(); err != nil {
+		c.logger.Error("failed to ack dead letter message", zap.String("topic", topic), zap.String("id", entry.ID), zap.Error(err))
+		return
+	}
+	c.logger.Error("message moved to dead letter stream after max retries",
+		zap.String("topic", topic),
+		zap.String("id", entry.ID),
+		zap.String("dead_letter_stream", dlq),
+		zap.Int64("deliveries", deliveries),
+	)
+}
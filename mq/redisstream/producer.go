@@ -0,0 +1,228 @@
+package redisstream
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+/* ========================================================================
+ * Redis Streams Producer - 轻量消息生产者
+ * ========================================================================
+ * 职责: 实现 mq.Producer 接口，适合没有 Kafka / RocketMQ 的小型部署
+ * 技术: go-redis/v9（XADD）
+ * 存储: 每个 Topic 对应一个 Stream（Key = KeyPrefix + Topic），
+ *       配置 MaxLen 后按近似长度裁剪（MAXLEN ~）
+ * 限制: 不支持延迟消息（DelayLevel / DelayTime 非零时返回错误）
+ *
+ * 配置示例:
+ *   mq:
+ *     type: redis
+ *     redis:
+ *       addrs: ["127.0.0.1:6379"]
+ *       key_prefix: "mq:"
+ *       max_len: 100000
+ * ======================================================================== */
+
+// Stream 条目字段
+const (
+	fieldBody           = "body"
+	fieldKey            = "key"
+	fieldTag            = "tag"
+	fieldBornTime       = "born"
+	fieldPropertyPrefix = "prop:"
+)
+
+// =============================================================================
+// 注册工厂
+// =============================================================================
+
+func init() {
+	mq.RegisterProducerFactory(mq.TypeRedis, NewProducerAdapter)
+}
+
+// =============================================================================
+// Producer 适配器
+// =============================================================================
+
+// ProducerAdapter Redis Streams 生产者适配器
+type ProducerAdapter struct {
+	client redis.UniversalClient
+	owned  bool // client 由适配器创建，Close 时一并关闭
+	prefix string
+	maxLen int64
+	logger *zap.Logger
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewProducerAdapter 按配置创建 Redis Streams 生产者适配器
+func NewProducerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Producer, error) {
+	if cfg.Redis == nil {
+		return nil, fmt.Errorf("redis stream config is required")
+	}
+	client, err := newClient(cfg.Redis)
+	if err != nil {
+		return nil, err
+	}
+	p := NewProducer(client, cfg.Redis, logger)
+	p.owned = true
+
+	logger.Info("Redis stream producer started", zap.Strings("addrs", cfg.Redis.Addrs))
+	return p, nil
+}
+
+// NewProducer 基于已有 Redis 客户端创建生产者（Close 不会关闭 client）
+func NewProducer(client redis.UniversalClient, cfg *mq.RedisStreamConfig, logger *zap.Logger) *ProducerAdapter {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ProducerAdapter{
+		client: client,
+		prefix: cfg.KeyPrefix,
+		maxLen: cfg.MaxLen,
+		logger: logger,
+	}
+}
+
+// SendSync 同步发送消息
+func (p *ProducerAdapter) SendSync(ctx context.Context, msg *mq.Message) (*mq.SendResult, error) {
+	p.mu.RLock()
+	if p.closed {
+		p.mu.RUnlock()
+		return nil, fmt.Errorf("producer is closed")
+	}
+	p.mu.RUnlock()
+
+	if msg == nil || msg.Topic == "" {
+		return nil, fmt.Errorf("message topic is required")
+	}
+	if msg.DelayLevel > 0 || msg.DelayTime > 0 {
+		return nil, fmt.Errorf("redis streams do not support delayed messages")
+	}
+
+	mq.InjectRequestID(ctx, msg)
+	id, err := p.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: p.prefix + msg.Topic,
+		MaxLen: p.maxLen,
+		Approx: p.maxLen > 0,
+		Values: encodeMessage(msg),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return &mq.SendResult{
+		MsgID:  id,
+		Topic:  msg.Topic,
+		Status: mq.SendStatusOK,
+	}, nil
+}
+
+// SendAsync 异步发送消息
+func (p *ProducerAdapter) SendAsync(ctx context.Context, msg *mq.Message, callback mq.SendCallback) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return fmt.Errorf("producer is closed")
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		result, err := p.SendSync(context.WithoutCancel(ctx), msg)
+		if callback != nil {
+			callback(result, err)
+		} else if err != nil {
+			p.logger.Error("async producer error", zap.String("topic", msg.Topic), zap.Error(err))
+		}
+	}()
+	return nil
+}
+
+// Close 等待异步发送完成后关闭生产者
+func (p *ProducerAdapter) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	p.wg.Wait()
+	if p.owned {
+		if err := p.client.Close(); err != nil {
+			p.logger.Error("failed to close redis client", zap.Error(err))
+			return err
+		}
+	}
+	p.logger.Info("Redis stream producer closed")
+	return nil
+}
+
+// =============================================================================
+// 辅助函数
+// =============================================================================
+
+func newClient(cfg *mq.RedisStreamConfig) (redis.UniversalClient, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, fmt.Errorf("redis stream addrs are required")
+	}
+	return redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:    cfg.Addrs,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	}), nil
+}
+
+func encodeMessage(msg *mq.Message) map[string]any {
+	values := make(map[string]any, len(msg.Properties)+4)
+	values[fieldBody] = msg.Body
+	values[fieldBornTime] = strconv.FormatInt(time.Now().UnixMilli(), 10)
+	if msg.Key != "" {
+		values[fieldKey] = msg.Key
+	}
+	if msg.Tag != "" {
+		values[fieldTag] = msg.Tag
+	}
+	for k, v := range msg.Properties {
+		values[fieldPropertyPrefix+k] = v
+	}
+	return values
+}
+
+func decodeMessage(topic string, entry redis.XMessage) *mq.ConsumedMessage {
+	msg := &mq.ConsumedMessage{
+		Topic:      topic,
+		MsgID:      entry.ID,
+		Properties: make(map[string]string),
+	}
+	for k, raw := range entry.Values {
+		v, _ := raw.(string)
+		switch {
+		case k == fieldBody:
+			msg.Body = []byte(v)
+		case k == fieldKey:
+			msg.Key = v
+		case k == fieldTag:
+			msg.Tag = v
+		case k == fieldBornTime:
+			if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+				msg.BornTime = time.UnixMilli(ms)
+			}
+		case strings.HasPrefix(k, fieldPropertyPrefix):
+			msg.Properties[strings.TrimPrefix(k, fieldPropertyPrefix)] = v
+		}
+	}
+	return msg
+}
@@ -0,0 +1,331 @@
+package redisstream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+func newTestConfig(server *miniredis.Miniredis) *mq.RedisStreamConfig {
+	cfg := mq.DefaultRedisStreamConfig()
+	cfg.Addrs = []string{server.Addr()}
+	cfg.Consumer.StartID = "0"
+	cfg.Consumer.Block = 20 * time.Millisecond
+	cfg.Consumer.ClaimMinIdle = 10 * time.Millisecond
+	cfg.Consumer.ClaimInterval = 10 * time.Millisecond
+	return cfg
+}
+
+func newTestClient(t *testing.T) (redis.UniversalClient, *miniredis.Miniredis) {
+	t.Helper()
+
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return client, server
+}
+
+// collector 收集消费到的消息
+type collector struct {
+	mu   sync.Mutex
+	msgs []*mq.ConsumedMessage
+	fail func(*mq.ConsumedMessage) bool
+}
+
+func (c *collector) handle(_ context.Context, msgs []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, m := range msgs {
+		c.msgs = append(c.msgs, m)
+		if c.fail != nil && c.fail(m) {
+			return mq.ConsumeRetryLater, errors.New("boom")
+		}
+	}
+	return mq.ConsumeSuccess, nil
+}
+
+func (c *collector) snapshot() []*mq.ConsumedMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*mq.ConsumedMessage(nil), c.msgs...)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func startConsumer(t *testing.T, client redis.UniversalClient, cfg *mq.RedisStreamConfig, topic string, c *collector) *ConsumerAdapter {
+	t.Helper()
+
+	consumer := NewConsumer(client, cfg, zap.NewNop())
+	if err := consumer.Subscribe(topic, c.handle); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() { _ = consumer.Close() })
+	return consumer
+}
+
+func TestProduceConsume(t *testing.T) {
+	client, server := newTestClient(t)
+	cfg := newTestConfig(server)
+	producer := NewProducer(client, cfg, zap.NewNop())
+	defer producer.Close()
+
+	c := &collector{}
+	startConsumer(t, client, cfg, "orders", c)
+
+	msg := mq.NewMessage("orders", []byte("hello")).WithKey("k1").WithTag("created").WithProperty("trace", "t1")
+	result, err := producer.SendSync(context.Background(), msg)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	waitFor(t, func() bool { return len(c.snapshot()) == 1 })
+	got := c.snapshot()[0]
+	if got.MsgID != result.MsgID || string(got.Body) != "hello" || got.Key != "k1" || got.Tag != "created" {
+		t.Fatalf("unexpected message %+v", got)
+	}
+	if got.Properties["trace"] != "t1" || got.BornTime.IsZero() {
+		t.Fatalf("unexpected properties/born time %+v", got)
+	}
+
+	waitFor(t, func() bool {
+		pending, err := client.XPending(context.Background(), "mq:orders", cfg.Consumer.Group).Result()
+		return err == nil && pending.Count == 0
+	})
+}
+
+func TestSendAsync(t *testing.T) {
+	client, server := newTestClient(t)
+	producer := NewProducer(client, newTestConfig(server), zap.NewNop())
+
+	done := make(chan error, 1)
+	if err := producer.SendAsync(context.Background(), mq.NewMessage("async", []byte("x")), func(_ *mq.SendResult, err error) {
+		done <- err
+	}); err != nil {
+		t.Fatalf("send async: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("callback error: %v", err)
+	}
+	if err := producer.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := producer.SendSync(context.Background(), mq.NewMessage("async", nil)); err == nil {
+		t.Fatal("expected error after close")
+	}
+}
+
+func TestDelayedMessageRejected(t *testing.T) {
+	client, server := newTestClient(t)
+	producer := NewProducer(client, newTestConfig(server), zap.NewNop())
+	defer producer.Close()
+
+	if _, err := producer.SendSync(context.Background(), mq.NewMessage("t", nil).WithDelayTime(time.Second)); err == nil {
+		t.Fatal("expected delayed message to be rejected")
+	}
+}
+
+func TestMaxLenTrimming(t *testing.T) {
+	client, server := newTestClient(t)
+	cfg := newTestConfig(server)
+	cfg.MaxLen = 5
+	producer := NewProducer(client, cfg, zap.NewNop())
+	defer producer.Close()
+
+	for i := 0; i < 20; i++ {
+		if _, err := producer.SendSync(context.Background(), mq.NewMessage("trim", []byte("x"))); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	n, err := client.XLen(context.Background(), "mq:trim").Result()
+	if err != nil {
+		t.Fatalf("xlen: %v", err)
+	}
+	if n >= 20 {
+		t.Fatalf("expected stream to be trimmed, got length %d", n)
+	}
+}
+
+func TestFailedMessageIsClaimedAndRedelivered(t *testing.T) {
+	client, server := newTestClient(t)
+	cfg := newTestConfig(server)
+	producer := NewProducer(client, cfg, zap.NewNop())
+	defer producer.Close()
+
+	c := &collector{fail: func(m *mq.ConsumedMessage) bool { return m.ReconsumeCnt == 0 }}
+	startConsumer(t, client, cfg, "retry", c)
+
+	if _, err := producer.SendSync(context.Background(), mq.NewMessage("retry", []byte("x"))); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	waitFor(t, func() bool { return len(c.snapshot()) >= 2 })
+	if got := c.snapshot()[1].ReconsumeCnt; got != 1 {
+		t.Fatalf("expected ReconsumeCnt 1 on redelivery, got %d", got)
+	}
+	waitFor(t, func() bool {
+		pending, err := client.XPending(context.Background(), "mq:retry", cfg.Consumer.Group).Result()
+		return err == nil && pending.Count == 0
+	})
+}
+
+func TestCrashedConsumerPendingIsRecovered(t *testing.T) {
+	client, server := newTestClient(t)
+	cfg := newTestConfig(server)
+	ctx := context.Background()
+
+	// 模拟崩溃实例：读取但未确认
+	if err := client.XGroupCreateMkStream(ctx, "mq:jobs", cfg.Consumer.Group, "0").Err(); err != nil {
+		t.Fatalf("create group: %v", err)
+	}
+	if err := client.XAdd(ctx, &redis.XAddArgs{Stream: "mq:jobs", Values: map[string]any{fieldBody: "job"}}).Err(); err != nil {
+		t.Fatalf("xadd: %v", err)
+	}
+	if err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group: cfg.Consumer.Group, Consumer: "crashed", Streams: []string{"mq:jobs", ">"}, Count: 1,
+	}).Err(); err != nil {
+		t.Fatalf("xreadgroup: %v", err)
+	}
+
+	cfg.Consumer.Name = "survivor"
+	c := &collector{}
+	startConsumer(t, client, cfg, "jobs", c)
+
+	waitFor(t, func() bool { return len(c.snapshot()) == 1 })
+	if got := c.snapshot()[0]; string(got.Body) != "job" || got.ReconsumeCnt != 1 {
+		t.Fatalf("unexpected recovered message %+v", got)
+	}
+}
+
+func TestDeadLetterAfterMaxRetries(t *testing.T) {
+	client, server := newTestClient(t)
+	cfg := newTestConfig(server)
+	cfg.Consumer.MaxRetries = 2
+	producer := NewProducer(client, cfg, zap.NewNop())
+	defer producer.Close()
+
+	c := &collector{fail: func(*mq.ConsumedMessage) bool { return true }}
+	startConsumer(t, client, cfg, "poison", c)
+
+	if _, err := producer.SendSync(context.Background(), mq.NewMessage("poison", []byte("bad"))); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	waitFor(t, func() bool {
+		n, err := client.XLen(context.Background(), "mq:poison:dlq").Result()
+		return err == nil && n == 1
+	})
+	if got := len(c.snapshot()); got != 2 {
+		t.Fatalf("expected 2 deliveries before dead letter, got %d", got)
+	}
+	pending, err := client.XPending(context.Background(), "mq:poison", cfg.Consumer.Group).Result()
+	if err != nil || pending.Count != 0 {
+		t.Fatalf("expected no pending messages, got %+v, %v", pending, err)
+	}
+}
+
+func TestBroadcastSubscription(t *testing.T) {
+	client, server := newTestClient(t)
+	producer := NewProducer(client, newTestConfig(server), zap.NewNop())
+	defer producer.Close()
+
+	collectors := make([]*collector, 2)
+	for i, name := range []string{"node-a", "node-b"} {
+		cfg := newTestConfig(server)
+		cfg.Consumer.Name = name
+		collectors[i] = &collector{}
+
+		consumer := NewConsumer(client, cfg, zap.NewNop())
+		if err := mq.Subscribe(consumer, "cache.invalidate", collectors[i].handle, mq.WithBroadcast()); err != nil {
+			t.Fatalf("subscribe broadcast: %v", err)
+		}
+		if err := consumer.Subscribe("orders", collectors[i].handle); err != nil {
+			t.Fatalf("subscribe: %v", err)
+		}
+		if err := consumer.Start(); err != nil {
+			t.Fatalf("start: %v", err)
+		}
+		t.Cleanup(func() { _ = consumer.Close() })
+	}
+
+	for _, topic := range []string{"cache.invalidate", "orders", "orders"} {
+		if _, err := producer.SendSync(context.Background(), mq.NewMessage(topic, []byte(topic))); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	// 广播消息每个实例各收到一次，集群消息在实例间分摊
+	count := func(topic string) (total int, perNode []int) {
+		perNode = make([]int, len(collectors))
+		for i, c := range collectors {
+			for _, m := range c.snapshot() {
+				if m.Topic == topic {
+					perNode[i]++
+					total++
+				}
+			}
+		}
+		return total, perNode
+	}
+	waitFor(t, func() bool {
+		broadcast, _ := count("cache.invalidate")
+		orders, _ := count("orders")
+		return broadcast == 2 && orders == 2
+	})
+	if _, perNode := count("cache.invalidate"); perNode[0] != 1 || perNode[1] != 1 {
+		t.Fatalf("expected every node to receive the broadcast once, got %v", perNode)
+	}
+
+	groups, err := client.XInfoGroups(context.Background(), "mq:cache.invalidate").Result()
+	if err != nil {
+		t.Fatalf("xinfo groups: %v", err)
+	}
+	if len(groups) != 2 {
+		t.Fatalf("expected per-instance broadcast groups, got %+v", groups)
+	}
+}
+
+func TestFactoryRegistration(t *testing.T) {
+	_, server := newTestClient(t)
+	cfg := &mq.Config{Type: mq.TypeRedis, Redis: newTestConfig(server)}
+
+	producer, err := mq.NewProducer(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("new producer: %v", err)
+	}
+	defer producer.Close()
+
+	consumer, err := mq.NewConsumer(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("new consumer: %v", err)
+	}
+	defer consumer.Close()
+
+	if err := consumer.Start(); err == nil {
+		t.Fatal("expected start without subscriptions to fail")
+	}
+}
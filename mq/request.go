@@ -0,0 +1,266 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+/* ========================================================================
+ * Request / Reply - 基于消息的同步调用
+ * ========================================================================
+ * 职责: 以关联 ID 匹配请求与回复，为 Kafka / RocketMQ 提供同步调用语义
+ * 约定（消息属性）:
+ *   X-MQ-Correlation-ID: 请求唯一 ID，回复原样带回
+ *   X-MQ-Reply-To:       回复 Topic
+ *   X-MQ-Reply-Error:    响应方处理失败时的错误信息
+ * 回复 Topic:
+ *   - 默认每个实例独立（mq_reply_<hostname>），回复只会被发起方实例消费
+ *   - 多实例共享同一回复 Topic 时需每个实例都能收到全部回复（广播消费），
+ *     未知关联 ID 的回复被忽略
+ * 注意: 回复订阅须在 Consumer.Start 之前建立（EnableReplies / NewRequester）
+ *
+ * 使用示例:
+ *   // 请求方（启动前）
+ *   _, _ = mq.EnableReplies(producer, consumer, "")
+ *   reply, err := mq.Request(ctx, producer, consumer, "inventory.reserve", body, 3*time.Second)
+ *
+ *   // 响应方
+ *   consumer.Subscribe("inventory.reserve", mq.ReplyHandler(producer, func(ctx context.Context, req *mq.ConsumedMessage) ([]byte, error) {
+ *       return reserve(ctx, req.Body)
+ *   }))
+ * ======================================================================== */
+
+// Request / Reply 消息属性
+const (
+	PropertyCorrelationID = "X-MQ-Correlation-ID"
+	PropertyReplyTo       = "X-MQ-Reply-To"
+	PropertyReplyError    = "X-MQ-Reply-Error"
+)
+
+var (
+	// ErrRequestTimeout 等待回复超时
+	ErrRequestTimeout = errors.New("mq: request timed out")
+	// ErrNoReplyTo 请求消息未携带回复 Topic
+	ErrNoReplyTo = errors.New("mq: message has no reply-to")
+)
+
+// ReplyError 响应方返回的错误
+type ReplyError struct {
+	Message string
+}
+
+func (e *ReplyError) Error() string {
+	return "mq: remote error: " + e.Message
+}
+
+// DefaultReplyTopic 返回本实例默认回复 Topic（mq_reply_<hostname>）
+func DefaultReplyTopic() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = uuid.NewString()
+	}
+	return "mq_reply_" + sanitizeTopic(host)
+}
+
+// sanitizeTopic 将非 [a-zA-Z0-9_-] 字符替换为 _（兼容 Kafka 与 RocketMQ 的 Topic 规则）
+func sanitizeTopic(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
+
+// =============================================================================
+// 请求方
+// =============================================================================
+
+// Requester 请求方：发送请求并等待关联回复（并发安全）
+type Requester struct {
+	producer   Producer
+	replyTopic string
+
+	mu      sync.Mutex
+	pending map[string]chan *ConsumedMessage
+}
+
+// NewRequester 创建请求方并订阅回复 Topic（replyTopic 为空时使用 DefaultReplyTopic）
+func NewRequester(producer Producer, consumer Consumer, replyTopic string) (*Requester, error) {
+	if producer == nil || consumer == nil {
+		return nil, errors.New("mq: producer and consumer are required")
+	}
+	if replyTopic == "" {
+		replyTopic = DefaultReplyTopic()
+	}
+	r := &Requester{
+		producer:   producer,
+		replyTopic: replyTopic,
+		pending:    make(map[string]chan *ConsumedMessage),
+	}
+	if err := consumer.Subscribe(replyTopic, r.handleReplies); err != nil {
+		return nil, fmt.Errorf("mq: subscribe reply topic %s: %w", replyTopic, err)
+	}
+	return r, nil
+}
+
+// ReplyTopic 回复 Topic
+func (r *Requester) ReplyTopic() string {
+	return r.replyTopic
+}
+
+// Request 发送请求并等待回复，timeout <= 0 时仅受 ctx 限制
+func (r *Requester) Request(ctx context.Context, topic string, payload []byte, timeout time.Duration) (*ConsumedMessage, error) {
+	return r.RequestMessage(ctx, NewMessage(topic, payload), timeout)
+}
+
+// RequestMessage 发送自定义请求消息（可设置 Key / Tag / 属性）并等待回复
+func (r *Requester) RequestMessage(ctx context.Context, msg *Message, timeout time.Duration) (*ConsumedMessage, error) {
+	if msg == nil {
+		return nil, errors.New("mq: message is required")
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	id := uuid.NewString()
+	ch := make(chan *ConsumedMessage, 1)
+	r.mu.Lock()
+	r.pending[id] = ch
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		delete(r.pending, id)
+		r.mu.Unlock()
+	}()
+
+	req := *msg
+	req.Properties = make(map[string]string, len(msg.Properties)+2)
+	for k, v := range msg.Properties {
+		req.Properties[k] = v
+	}
+	req.Properties[PropertyCorrelationID] = id
+	req.Properties[PropertyReplyTo] = r.replyTopic
+
+	if _, err := r.producer.SendSync(ctx, &req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case reply := <-ch:
+		if msg := reply.Properties[PropertyReplyError]; msg != "" {
+			return reply, &ReplyError{Message: msg}
+		}
+		return reply, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: %s", ErrRequestTimeout, msg.Topic)
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// handleReplies 按关联 ID 分发回复；未知或已超时的回复被忽略
+func (r *Requester) handleReplies(_ context.Context, msgs []*ConsumedMessage) (ConsumeResult, error) {
+	for _, msg := range msgs {
+		id := msg.Properties[PropertyCorrelationID]
+		r.mu.Lock()
+		ch, ok := r.pending[id]
+		r.mu.Unlock()
+		if !ok {
+			continue
+		}
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+	return ConsumeSuccess, nil
+}
+
+// =============================================================================
+// 包级便捷函数（按 Consumer 复用 Requester）
+// =============================================================================
+
+var (
+	requestersMu sync.Mutex
+	requesters   = make(map[Consumer]*Requester)
+)
+
+// EnableReplies 为 consumer 建立回复订阅（须在 Start 之前调用），重复调用返回同一 Requester
+// replyTopic 为空时使用 DefaultReplyTopic；与已建立的回复 Topic 不一致时返回错误
+func EnableReplies(producer Producer, consumer Consumer, replyTopic string) (*Requester, error) {
+	requestersMu.Lock()
+	defer requestersMu.Unlock()
+
+	if r, ok := requesters[consumer]; ok {
+		if replyTopic != "" && replyTopic != r.replyTopic {
+			return nil, fmt.Errorf("mq: replies already enabled on topic %s", r.replyTopic)
+		}
+		return r, nil
+	}
+	r, err := NewRequester(producer, consumer, replyTopic)
+	if err != nil {
+		return nil, err
+	}
+	requesters[consumer] = r
+	return r, nil
+}
+
+// Request 发送请求并等待回复
+// 首次调用时为 consumer 建立默认回复订阅；Kafka / RocketMQ 需在 Start 前调用 EnableReplies
+func Request(ctx context.Context, producer Producer, consumer Consumer, topic string, payload []byte, timeout time.Duration) (*ConsumedMessage, error) {
+	r, err := EnableReplies(producer, consumer, "")
+	if err != nil {
+		return nil, err
+	}
+	return r.Request(ctx, topic, payload, timeout)
+}
+
+// =============================================================================
+// 响应方
+// =============================================================================
+
+// NewReply 根据请求创建回复消息；replyErr 非 nil 时回复携带错误信息
+func NewReply(req *ConsumedMessage, body []byte, replyErr error) (*Message, error) {
+	if req == nil || req.Properties[PropertyReplyTo] == "" {
+		return nil, ErrNoReplyTo
+	}
+	id := req.Properties[PropertyCorrelationID]
+	msg := NewMessage(req.Properties[PropertyReplyTo], body).
+		WithKey(id).
+		WithProperty(PropertyCorrelationID, id)
+	if replyErr != nil {
+		msg.WithProperty(PropertyReplyError, replyErr.Error())
+	}
+	return msg, nil
+}
+
+// ReplyHandler 将请求处理函数包装为 MessageHandler，处理结果作为回复发送
+// 未携带回复 Topic 的消息仅执行 fn；回复发送失败时返回 ConsumeRetryLater
+func ReplyHandler(producer Producer, fn func(ctx context.Context, req *ConsumedMessage) ([]byte, error)) MessageHandler {
+	return func(ctx context.Context, msgs []*ConsumedMessage) (ConsumeResult, error) {
+		for _, req := range msgs {
+			body, err := fn(ctx, req)
+			reply, rerr := NewReply(req, body, err)
+			if errors.Is(rerr, ErrNoReplyTo) {
+				continue
+			}
+			if _, err := producer.SendSync(ctx, reply); err != nil {
+				return ConsumeRetryLater, fmt.Errorf("mq: send reply: %w", err)
+			}
+		}
+		return ConsumeSuccess, nil
+	}
+}
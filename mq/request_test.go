@@ -0,0 +1,124 @@
+package mq_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/mq/mqtest"
+)
+
+func startResponder(t *testing.T, broker *mqtest.Broker, topic string, fn func(context.Context, *mq.ConsumedMessage) ([]byte, error)) {
+	t.Helper()
+
+	server := broker.Consumer()
+	if err := server.Subscribe(topic, mq.ReplyHandler(broker.Producer(), fn)); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := server.Start(); err != nil {
+		t.Fatalf("start responder: %v", err)
+	}
+	t.Cleanup(func() { _ = server.Close() })
+}
+
+func TestRequestReply(t *testing.T) {
+	broker := mqtest.NewBroker()
+	startResponder(t, broker, "echo", func(_ context.Context, req *mq.ConsumedMessage) ([]byte, error) {
+		return append([]byte("echo:"), req.Body...), nil
+	})
+
+	producer, consumer := broker.Producer(), broker.Consumer()
+	r, err := mq.EnableReplies(producer, consumer, "replies")
+	if err != nil {
+		t.Fatalf("enable replies: %v", err)
+	}
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer consumer.Close()
+
+	reply, err := mq.Request(context.Background(), producer, consumer, "echo", []byte("hi"), time.Second)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	if string(reply.Body) != "echo:hi" {
+		t.Fatalf("unexpected reply body %q", reply.Body)
+	}
+
+	again, err := mq.EnableReplies(producer, consumer, "")
+	if err != nil || again != r {
+		t.Fatalf("expected the same requester, got %v, %v", again, err)
+	}
+	if _, err := mq.EnableReplies(producer, consumer, "other"); err == nil {
+		t.Fatal("expected error for a different reply topic")
+	}
+
+	if err := broker.ExpectPublished("echo", mqtest.HasProperty(mq.PropertyReplyTo, "replies")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRequestRemoteError(t *testing.T) {
+	broker := mqtest.NewBroker()
+	startResponder(t, broker, "fail", func(context.Context, *mq.ConsumedMessage) ([]byte, error) {
+		return nil, errors.New("out of stock")
+	})
+
+	consumer := broker.Consumer()
+	r, err := mq.NewRequester(broker.Producer(), consumer, "replies")
+	if err != nil {
+		t.Fatalf("new requester: %v", err)
+	}
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer consumer.Close()
+
+	_, err = r.Request(context.Background(), "fail", nil, time.Second)
+	var remote *mq.ReplyError
+	if !errors.As(err, &remote) || remote.Message != "out of stock" {
+		t.Fatalf("expected remote error, got %v", err)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	broker := mqtest.NewBroker()
+	consumer := broker.Consumer()
+	r, err := mq.NewRequester(broker.Producer(), consumer, "replies")
+	if err != nil {
+		t.Fatalf("new requester: %v", err)
+	}
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer consumer.Close()
+
+	_, err = r.Request(context.Background(), "nobody", []byte("x"), 20*time.Millisecond)
+	if !errors.Is(err, mq.ErrRequestTimeout) {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+
+	// 超时后到达的回复被忽略
+	late := &mq.ConsumedMessage{Topic: "nobody", Properties: map[string]string{
+		mq.PropertyReplyTo:       "replies",
+		mq.PropertyCorrelationID: "gone",
+	}}
+	msg, err := mq.NewReply(late, []byte("late"), nil)
+	if err != nil {
+		t.Fatalf("new reply: %v", err)
+	}
+	if _, err := broker.Producer().SendSync(context.Background(), msg); err != nil {
+		t.Fatalf("send late reply: %v", err)
+	}
+	if len(broker.DeadLetters()) != 0 {
+		t.Fatal("late reply must not be retried")
+	}
+}
+
+func TestNewReplyWithoutReplyTo(t *testing.T) {
+	if _, err := mq.NewReply(&mq.ConsumedMessage{}, nil, nil); !errors.Is(err, mq.ErrNoReplyTo) {
+		t.Fatalf("expected ErrNoReplyTo, got %v", err)
+	}
+}
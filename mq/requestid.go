@@ -0,0 +1,39 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/aisgo/ais-go-pkg/requestid"
+)
+
+/* ========================================================================
+ * Request ID 透传
+ * ========================================================================
+ * 职责: 生产时将 ctx 中的请求 ID / 关联 ID 写入消息属性，
+ *       消费时从消息属性恢复到 handler ctx
+ * 说明: Kafka / RocketMQ 适配器已自动调用，自定义 Producer/Consumer 实现可复用
+ * ======================================================================== */
+
+// InjectRequestID 将 ctx 中的请求 ID / 关联 ID 写入消息属性（不覆盖已有值）
+func InjectRequestID(ctx context.Context, msg *Message) {
+	if msg == nil {
+		return
+	}
+	requestid.Inject(ctx, func(key, value string) {
+		if _, ok := msg.Properties[key]; !ok {
+			msg.WithProperty(key, value)
+		}
+	})
+}
+
+// ContextWithRequestID 从消息属性恢复请求 ID / 关联 ID 到 ctx
+// 批量消费时取第一条携带请求 ID 的消息
+func ContextWithRequestID(ctx context.Context, msgs ...*ConsumedMessage) context.Context {
+	for _, msg := range msgs {
+		if msg == nil || msg.Properties[requestid.Header] == "" {
+			continue
+		}
+		return requestid.Extract(ctx, func(key string) string { return msg.Properties[key] })
+	}
+	return ctx
+}
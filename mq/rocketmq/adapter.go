@@ -3,6 +3,8 @@ package rocketmq
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/apache/rocketmq-client-go/v2"
@@ -38,6 +40,11 @@ func init() {
 type ProducerAdapter struct {
 	producer rocketmq.Producer
 	logger   *zap.Logger
+	delay    delayStrategy
+
+	// closeOnce 保证 Close 幂等（fx OnStop 与 shutdown 钩子可能先后调用）
+	closeOnce sync.Once
+	closeErr  error
 }
 
 // NewProducerAdapter 创建 RocketMQ 生产者适配器
@@ -48,6 +55,11 @@ func NewProducerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Producer, error)
 
 	rmqCfg := cfg.RocketMQ
 
+	delay, err := newDelayStrategy(rmqCfg.Producer)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建生产者选项
 	opts := []producer.Option{
 		producer.WithNameServer(rmqCfg.NameServers),
@@ -88,19 +100,14 @@ func NewProducerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Producer, error)
 	return &ProducerAdapter{
 		producer: p,
 		logger:   logger,
+		delay:    delay,
 	}, nil
 }
 
 // SendSync 同步发送消息
 func (p *ProducerAdapter) SendSync(ctx context.Context, msg *mq.Message) (*mq.SendResult, error) {
-	if msg.DelayTime > 0 && msg.DelayLevel == 0 {
-		p.logger.Warn("RocketMQ adapter does not support arbitrary DelayTime, please use DelayLevel instead",
-			zap.String("topic", msg.Topic),
-			zap.Duration("delay_time", msg.DelayTime),
-		)
-	}
-
-	rmqMsg := convertToRocketMQMessage(msg)
+	mq.InjectRequestID(ctx, msg)
+	rmqMsg := p.convertMessage(msg)
 
 	result, err := p.producer.SendSync(ctx, rmqMsg)
 	if err != nil {
@@ -121,14 +128,8 @@ func (p *ProducerAdapter) SendSync(ctx context.Context, msg *mq.Message) (*mq.Se
 
 // SendAsync 异步发送消息
 func (p *ProducerAdapter) SendAsync(ctx context.Context, msg *mq.Message, callback mq.SendCallback) error {
-	if msg.DelayTime > 0 && msg.DelayLevel == 0 {
-		p.logger.Warn("RocketMQ adapter does not support arbitrary DelayTime, please use DelayLevel instead",
-			zap.String("topic", msg.Topic),
-			zap.Duration("delay_time", msg.DelayTime),
-		)
-	}
-
-	rmqMsg := convertToRocketMQMessage(msg)
+	mq.InjectRequestID(ctx, msg)
+	rmqMsg := p.convertMessage(msg)
 
 	err := p.producer.SendAsync(ctx, func(ctx context.Context, result *primitive.SendResult, err error) {
 		if callback != nil {
@@ -147,14 +148,17 @@ func (p *ProducerAdapter) SendAsync(ctx context.Context, msg *mq.Message, callba
 	return nil
 }
 
-// Close 关闭生产者
+// Close 关闭生产者，重复调用返回首次结果
 func (p *ProducerAdapter) Close() error {
-	if err := p.producer.Shutdown(); err != nil {
-		p.logger.Error("failed to shutdown producer", zap.Error(err))
-		return err
-	}
-	p.logger.Info("RocketMQ producer closed")
-	return nil
+	p.closeOnce.Do(func() {
+		if err := p.producer.Shutdown(); err != nil {
+			p.logger.Error("failed to shutdown producer", zap.Error(err))
+			p.closeErr = err
+			return
+		}
+		p.logger.Info("RocketMQ producer closed")
+	})
+	return p.closeErr
 }
 
 // =============================================================================
@@ -165,8 +169,23 @@ func (p *ProducerAdapter) Close() error {
 type ConsumerAdapter struct {
 	consumer rocketmq.PushConsumer
 	logger   *zap.Logger
+	group    string
+	config   *mq.RocketMQConfig
+
+	// broadcaster mq.WithBroadcast 订阅使用的 BroadCasting 消费者（<group>_BROADCAST），按需创建
+	mu          sync.Mutex
+	broadcaster rocketmq.PushConsumer
+
+	// closeOnce 保证 Close 幂等（fx OnStop 与 shutdown 钩子可能先后调用）
+	closeOnce sync.Once
+	closeErr  error
 }
 
+var (
+	_ mq.StatsProvider    = (*ConsumerAdapter)(nil)
+	_ mq.OptionSubscriber = (*ConsumerAdapter)(nil)
+)
+
 // NewConsumerAdapter 创建 RocketMQ 消费者适配器
 func NewConsumerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Consumer, error) {
 	if cfg.RocketMQ == nil {
@@ -183,6 +202,26 @@ func NewConsumerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Consumer, error)
 		consumeMode = consumer.Clustering
 	}
 
+	c, err := newPushConsumer(rmqCfg, rmqCfg.Consumer.GroupName, consumeMode)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("RocketMQ consumer created",
+		zap.String("group", rmqCfg.Consumer.GroupName),
+		zap.Strings("name_servers", rmqCfg.NameServers),
+	)
+
+	return &ConsumerAdapter{
+		consumer: c,
+		logger:   logger,
+		group:    rmqCfg.Consumer.GroupName,
+		config:   rmqCfg,
+	}, nil
+}
+
+// newPushConsumer 按配置创建 PushConsumer
+func newPushConsumer(rmqCfg *mq.RocketMQConfig, group string, model consumer.MessageModel) (rocketmq.PushConsumer, error) {
 	// 消费位置
 	var consumeFromWhere consumer.ConsumeFromWhere
 	switch rmqCfg.Consumer.ConsumeFromWhere {
@@ -196,8 +235,8 @@ func NewConsumerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Consumer, error)
 
 	opts := []consumer.Option{
 		consumer.WithNameServer(rmqCfg.NameServers),
-		consumer.WithGroupName(rmqCfg.Consumer.GroupName),
-		consumer.WithConsumerModel(consumeMode),
+		consumer.WithGroupName(group),
+		consumer.WithConsumerModel(model),
 		consumer.WithConsumeFromWhere(consumeFromWhere),
 		consumer.WithConsumeMessageBatchMaxSize(rmqCfg.Consumer.ConsumeMessageBatchMax),
 		consumer.WithPullBatchSize(rmqCfg.Consumer.PullBatchSize),
@@ -224,28 +263,50 @@ func NewConsumerAdapter(cfg *mq.Config, logger *zap.Logger) (mq.Consumer, error)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create rocketmq consumer: %w", err)
 	}
+	return c, nil
+}
 
-	logger.Info("RocketMQ consumer created",
-		zap.String("group", rmqCfg.Consumer.GroupName),
-		zap.Strings("name_servers", rmqCfg.NameServers),
-	)
-
-	return &ConsumerAdapter{
-		consumer: c,
-		logger:   logger,
-	}, nil
+// BroadcastGroup 返回广播订阅使用的消费组名
+func BroadcastGroup(group string) string {
+	return group + "_BROADCAST"
 }
 
 // Subscribe 订阅主题
 func (c *ConsumerAdapter) Subscribe(topic string, handler mq.MessageHandler) error {
-	err := c.consumer.Subscribe(topic, consumer.MessageSelector{}, func(ctx context.Context, msgs ...*primitive.MessageExt) (consumer.ConsumeResult, error) {
+	return c.subscribe(c.consumer, topic, handler)
+}
+
+// SubscribeWithOptions 按选项订阅主题（实现 mq.OptionSubscriber）
+// 广播订阅由独立的 BroadCasting 消费者处理，需在 Start 之前声明；优先级被忽略
+func (c *ConsumerAdapter) SubscribeWithOptions(topic string, handler mq.MessageHandler, opts ...mq.SubscribeOption) error {
+	if !mq.ApplySubscribeOptions(opts...).Broadcast() || c.config.Consumer.Model == "Broadcasting" {
+		return c.Subscribe(topic, handler)
+	}
+
+	c.mu.Lock()
+	if c.broadcaster == nil {
+		b, err := newPushConsumer(c.config, BroadcastGroup(c.group), consumer.BroadCasting)
+		if err != nil {
+			c.mu.Unlock()
+			return err
+		}
+		c.broadcaster = b
+	}
+	b := c.broadcaster
+	c.mu.Unlock()
+
+	return c.subscribe(b, topic, handler)
+}
+
+func (c *ConsumerAdapter) subscribe(pc rocketmq.PushConsumer, topic string, handler mq.MessageHandler) error {
+	err := pc.Subscribe(topic, consumer.MessageSelector{}, func(ctx context.Context, msgs ...*primitive.MessageExt) (consumer.ConsumeResult, error) {
 		// 转换消息
 		convertedMsgs := make([]*mq.ConsumedMessage, len(msgs))
 		for i, msg := range msgs {
 			convertedMsgs[i] = convertFromRocketMQMessageExt(msg)
 		}
 
-		result, err := handler(ctx, convertedMsgs)
+		result, err := handler(mq.ContextWithRequestID(ctx, convertedMsgs...), convertedMsgs)
 		if err != nil {
 			c.logger.Error("failed to handle messages",
 				zap.String("topic", topic),
@@ -262,7 +323,7 @@ func (c *ConsumerAdapter) Subscribe(topic string, handler mq.MessageHandler) err
 		return fmt.Errorf("failed to subscribe topic %s: %w", topic, err)
 	}
 
-	c.logger.Info("subscribed to topic", zap.String("topic", topic))
+	c.logger.Info("subscribed to topic", zap.String("topic", topic), zap.Bool("broadcast", pc != c.consumer))
 	return nil
 }
 
@@ -271,24 +332,81 @@ func (c *ConsumerAdapter) Start() error {
 	if err := c.consumer.Start(); err != nil {
 		return fmt.Errorf("failed to start consumer: %w", err)
 	}
+	c.mu.Lock()
+	b := c.broadcaster
+	c.mu.Unlock()
+	if b != nil {
+		if err := b.Start(); err != nil {
+			_ = c.consumer.Shutdown()
+			return fmt.Errorf("failed to start broadcast consumer: %w", err)
+		}
+	}
 	c.logger.Info("RocketMQ consumer started")
 	return nil
 }
 
-// Close 关闭消费者
-func (c *ConsumerAdapter) Close() error {
-	if err := c.consumer.Shutdown(); err != nil {
-		c.logger.Error("failed to shutdown consumer", zap.Error(err))
-		return err
+// Stats 返回按主题汇总的队列积压（实现 mq.StatsProvider）
+// RocketMQ 不暴露分区级位点，Partitions 为空
+func (c *ConsumerAdapter) Stats() mq.ConsumerStats {
+	diff := c.consumer.GetOffsetDiffMap()
+	c.mu.Lock()
+	b := c.broadcaster
+	c.mu.Unlock()
+	if b != nil {
+		for topic, lag := range b.GetOffsetDiffMap() {
+			diff[topic] += lag
+		}
 	}
-	c.logger.Info("RocketMQ consumer closed")
-	return nil
+	stats := mq.ConsumerStats{
+		Type:   mq.TypeRocketMQ,
+		Group:  c.group,
+		Topics: make([]mq.TopicStats, 0, len(diff)),
+	}
+	for topic, lag := range diff {
+		stats.Topics = append(stats.Topics, mq.TopicStats{Topic: topic, Lag: lag})
+	}
+	sort.Slice(stats.Topics, func(i, j int) bool { return stats.Topics[i].Topic < stats.Topics[j].Topic })
+	return stats
+}
+
+// Close 关闭消费者，重复调用返回首次结果
+func (c *ConsumerAdapter) Close() error {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		b := c.broadcaster
+		c.broadcaster = nil
+		c.mu.Unlock()
+		if b != nil {
+			if err := b.Shutdown(); err != nil {
+				c.logger.Error("failed to shutdown broadcast consumer", zap.Error(err))
+			}
+		}
+		if err := c.consumer.Shutdown(); err != nil {
+			c.logger.Error("failed to shutdown consumer", zap.Error(err))
+			c.closeErr = err
+			return
+		}
+		c.logger.Info("RocketMQ consumer closed")
+	})
+	return c.closeErr
 }
 
 // =============================================================================
 // 转换函数
 // =============================================================================
 
+// convertMessage 转换消息并按延迟策略映射 DelayTime
+func (p *ProducerAdapter) convertMessage(msg *mq.Message) *primitive.Message {
+	rmqMsg := convertToRocketMQMessage(msg)
+	if p.delay.apply(rmqMsg, msg, time.Now()) {
+		p.logger.Warn("delay time exceeds the max rocketmq delay level, using the max level",
+			zap.String("topic", msg.Topic),
+			zap.Duration("delay_time", msg.DelayTime),
+		)
+	}
+	return rmqMsg
+}
+
 func convertToRocketMQMessage(msg *mq.Message) *primitive.Message {
 	rmqMsg := primitive.NewMessage(msg.Topic, msg.Body)
 
@@ -0,0 +1,58 @@
+package rocketmq
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/apache/rocketmq-client-go/v2"
+	"go.uber.org/zap"
+)
+
+// countingProducer 统计 Shutdown 调用次数
+type countingProducer struct {
+	rocketmq.Producer
+	calls int
+	err   error
+}
+
+func (p *countingProducer) Shutdown() error {
+	p.calls++
+	return p.err
+}
+
+// countingConsumer 统计 Shutdown 调用次数
+type countingConsumer struct {
+	rocketmq.PushConsumer
+	calls int
+}
+
+func (c *countingConsumer) Shutdown() error {
+	c.calls++
+	return nil
+}
+
+func TestAdapterCloseIsIdempotent(t *testing.T) {
+	shutdownErr := errors.New("shutdown failed")
+	p := &countingProducer{err: shutdownErr}
+	producer := &ProducerAdapter{producer: p, logger: zap.NewNop()}
+	for range 2 {
+		if err := producer.Close(); !errors.Is(err, shutdownErr) {
+			t.Fatalf("expected first close error, got %v", err)
+		}
+	}
+	if p.calls != 1 {
+		t.Fatalf("expected producer shutdown once, got %d", p.calls)
+	}
+
+	c := &countingConsumer{}
+	b := &countingConsumer{}
+	consumer := &ConsumerAdapter{consumer: c, broadcaster: b, logger: zap.NewNop()}
+	for range 2 {
+		if err := consumer.Close(); err != nil {
+			t.Fatalf("close consumer: %v", err)
+		}
+	}
+	if c.calls != 1 || b.calls != 1 {
+		t.Fatalf("expected consumer shutdown once, got %d/%d", c.calls, b.calls)
+	}
+}
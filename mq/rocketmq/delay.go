@@ -0,0 +1,104 @@
+package rocketmq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+/* ========================================================================
+ * 延迟消息 - Message.DelayTime 映射
+ * ========================================================================
+ * 职责: 让统一 Message API 的 WithDelayTime 在 RocketMQ 上生效
+ * 模式（producer.delay_mode）:
+ *   timer  RocketMQ 5.x 定时消息（默认）：设置 TIMER_DELIVER_MS = 发送时间 + DelayTime，
+ *          精确到毫秒；4.x Broker 会忽略该属性并立即投递
+ *   level  RocketMQ 4.x 延迟级别：向上取整到不小于 DelayTime 的最小级别（消息不会早于期望时间投递），
+ *          超过最大级别时使用最大级别；级别表需与 Broker messageDelayLevel 一致
+ * 优先级: 显式设置的 DelayLevel 优先于 DelayTime
+ *
+ * 配置示例:
+ *   mq:
+ *     rocketmq:
+ *       producer:
+ *         delay_mode: level
+ *         delay_levels: [1s, 5s, 10s, 30s, 1m, 2m, 3m, 4m, 5m, 6m, 7m, 8m, 9m, 10m, 20m, 30m, 1h, 2h]
+ * ======================================================================== */
+
+// PropertyTimerDeliverMS RocketMQ 5.x 定时消息投递时间属性（Unix 毫秒）
+const PropertyTimerDeliverMS = "TIMER_DELIVER_MS"
+
+// 延迟模式
+const (
+	DelayModeTimer = "timer"
+	DelayModeLevel = "level"
+)
+
+// DefaultDelayLevels Broker 默认 messageDelayLevel（级别 1-18）
+var DefaultDelayLevels = []time.Duration{
+	time.Second, 5 * time.Second, 10 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 3 * time.Minute, 4 * time.Minute, 5 * time.Minute,
+	6 * time.Minute, 7 * time.Minute, 8 * time.Minute, 9 * time.Minute, 10 * time.Minute,
+	20 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour,
+}
+
+// DelayLevelFor 返回不小于 d 的最小延迟级别（从 1 开始）
+// d 超过最大级别时返回最大级别与 false；levels 为空时使用 DefaultDelayLevels
+func DelayLevelFor(d time.Duration, levels []time.Duration) (int, bool) {
+	if len(levels) == 0 {
+		levels = DefaultDelayLevels
+	}
+	for i, level := range levels {
+		if level >= d {
+			return i + 1, true
+		}
+	}
+	return len(levels), false
+}
+
+// delayStrategy DelayTime 映射策略
+type delayStrategy struct {
+	mode   string
+	levels []time.Duration
+}
+
+func newDelayStrategy(cfg mq.RocketMQProducerConfig) (delayStrategy, error) {
+	mode := strings.ToLower(cfg.DelayMode)
+	switch mode {
+	case "":
+		mode = DelayModeTimer
+	case DelayModeTimer, DelayModeLevel:
+	default:
+		return delayStrategy{}, fmt.Errorf("unsupported rocketmq delay mode %q", cfg.DelayMode)
+	}
+	levels := cfg.DelayLevels
+	if len(levels) == 0 {
+		levels = DefaultDelayLevels
+	}
+	for i := 1; i < len(levels); i++ {
+		if levels[i] <= levels[i-1] {
+			return delayStrategy{}, fmt.Errorf("rocketmq delay levels must be strictly increasing")
+		}
+	}
+	return delayStrategy{mode: mode, levels: levels}, nil
+}
+
+// apply 将 msg.DelayTime 写入 RocketMQ 消息，返回是否按最大级别截断
+func (s delayStrategy) apply(rmqMsg *primitive.Message, msg *mq.Message, now time.Time) (truncated bool) {
+	if msg.DelayLevel > 0 || msg.DelayTime <= 0 {
+		return false
+	}
+	if s.mode == DelayModeLevel {
+		level, ok := DelayLevelFor(msg.DelayTime, s.levels)
+		rmqMsg.WithDelayTimeLevel(level)
+		return !ok
+	}
+	deliverAt := now.Add(msg.DelayTime).UnixMilli()
+	rmqMsg.WithProperty(PropertyTimerDeliverMS, strconv.FormatInt(deliverAt, 10))
+	return false
+}
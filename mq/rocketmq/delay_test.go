@@ -0,0 +1,105 @@
+package rocketmq
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/apache/rocketmq-client-go/v2/primitive"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+func TestDelayLevelFor(t *testing.T) {
+	cases := []struct {
+		delay time.Duration
+		level int
+		ok    bool
+	}{
+		{500 * time.Millisecond, 1, true},
+		{time.Second, 1, true},
+		{6 * time.Second, 3, true},
+		{90 * time.Second, 6, true},
+		{15 * time.Minute, 15, true},
+		{2 * time.Hour, 18, true},
+		{3 * time.Hour, 18, false},
+	}
+	for _, c := range cases {
+		level, ok := DelayLevelFor(c.delay, nil)
+		if level != c.level || ok != c.ok {
+			t.Errorf("DelayLevelFor(%s) = %d, %v; want %d, %v", c.delay, level, ok, c.level, c.ok)
+		}
+	}
+}
+
+func TestDelayStrategyTimer(t *testing.T) {
+	s, err := newDelayStrategy(mq.RocketMQProducerConfig{})
+	if err != nil {
+		t.Fatalf("new strategy: %v", err)
+	}
+	now := time.UnixMilli(1_700_000_000_000)
+	msg := mq.NewMessage("t", nil).WithDelayTime(90 * time.Second)
+	rmqMsg := convertToRocketMQMessage(msg)
+
+	if s.apply(rmqMsg, msg, now) {
+		t.Fatal("timer mode never truncates")
+	}
+	want := strconv.FormatInt(now.Add(90*time.Second).UnixMilli(), 10)
+	if got := rmqMsg.GetProperty(PropertyTimerDeliverMS); got != want {
+		t.Fatalf("deliver ms = %q, want %q", got, want)
+	}
+	if got := rmqMsg.GetProperty(primitive.PropertyDelayTimeLevel); got != "" {
+		t.Fatalf("timer mode must not set delay level, got %q", got)
+	}
+}
+
+func TestDelayStrategyLevel(t *testing.T) {
+	s, err := newDelayStrategy(mq.RocketMQProducerConfig{DelayMode: DelayModeLevel})
+	if err != nil {
+		t.Fatalf("new strategy: %v", err)
+	}
+
+	msg := mq.NewMessage("t", nil).WithDelayTime(45 * time.Second)
+	rmqMsg := convertToRocketMQMessage(msg)
+	if s.apply(rmqMsg, msg, time.Now()) {
+		t.Fatal("45s fits within the default levels")
+	}
+	if got := rmqMsg.GetProperty(primitive.PropertyDelayTimeLevel); got != "5" {
+		t.Fatalf("delay level = %q, want 5 (1m)", got)
+	}
+
+	msg = mq.NewMessage("t", nil).WithDelayTime(5 * time.Hour)
+	rmqMsg = convertToRocketMQMessage(msg)
+	if !s.apply(rmqMsg, msg, time.Now()) {
+		t.Fatal("expected truncation beyond the max level")
+	}
+	if got := rmqMsg.GetProperty(primitive.PropertyDelayTimeLevel); got != "18" {
+		t.Fatalf("delay level = %q, want 18", got)
+	}
+}
+
+func TestDelayStrategyExplicitLevelWins(t *testing.T) {
+	s, _ := newDelayStrategy(mq.RocketMQProducerConfig{})
+	msg := mq.NewMessage("t", nil).WithDelayLevel(3).WithDelayTime(time.Hour)
+	rmqMsg := convertToRocketMQMessage(msg)
+	s.apply(rmqMsg, msg, time.Now())
+
+	if got := rmqMsg.GetProperty(PropertyTimerDeliverMS); got != "" {
+		t.Fatalf("explicit delay level must win, got deliver ms %q", got)
+	}
+	if got := rmqMsg.GetProperty(primitive.PropertyDelayTimeLevel); got != "3" {
+		t.Fatalf("delay level = %q, want 3", got)
+	}
+}
+
+func TestDelayStrategyConfigValidation(t *testing.T) {
+	if _, err := newDelayStrategy(mq.RocketMQProducerConfig{DelayMode: "cron"}); err == nil {
+		t.Fatal("expected error for unsupported mode")
+	}
+	if _, err := newDelayStrategy(mq.RocketMQProducerConfig{
+		DelayMode:   DelayModeLevel,
+		DelayLevels: []time.Duration{time.Minute, time.Second},
+	}); err == nil {
+		t.Fatal("expected error for unordered levels")
+	}
+}
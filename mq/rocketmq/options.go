@@ -1,6 +1,9 @@
 package rocketmq
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/apache/rocketmq-client-go/v2/primitive"
 )
 
@@ -59,6 +62,13 @@ func WithDelayTimeLevel(level int) MessageOption {
 	}
 }
 
+// WithDeliverTime 设置定时投递时间（RocketMQ 5.x 定时消息，4.x Broker 忽略）
+func WithDeliverTime(t time.Time) MessageOption {
+	return func(msg *primitive.Message) {
+		msg.WithProperty(PropertyTimerDeliverMS, strconv.FormatInt(t.UnixMilli(), 10))
+	}
+}
+
 // WithShardingKey 设置分区键（顺序消息）
 func WithShardingKey(key string) MessageOption {
 	return func(msg *primitive.Message) {
@@ -0,0 +1,49 @@
+package mq
+
+import (
+	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/shutdown"
+)
+
+/* ========================================================================
+ * MQ Shutdown - 接入优雅关停
+ * ========================================================================
+ * 职责: 将 Consumer / Producer 注册到 shutdown.Manager
+ * 顺序: 先停止消费 (Priorities.MQConsumer)，再刷新并关闭生产者 (Priorities.MQProducer)
+ * ======================================================================== */
+
+// RegisterConsumerShutdown 注册 Consumer 关停钩子
+func RegisterConsumerShutdown(m *shutdown.Manager, consumer Consumer) {
+	m.RegisterHookWithPriority("mq-consumer", shutdown.CloseHook(consumer.Close), *m.Priorities().MQConsumer)
+}
+
+// RegisterProducerShutdown 注册 Producer 关停钩子（关闭时刷新未发送消息）
+func RegisterProducerShutdown(m *shutdown.Manager, producer Producer) {
+	m.RegisterHookWithPriority("mq-producer", shutdown.CloseHook(producer.Close), *m.Priorities().MQProducer)
+}
+
+// ShutdownParams 关停集成依赖参数
+// Consumer / Producer 均为可选，仅注册容器中已提供的组件
+type ShutdownParams struct {
+	fx.In
+
+	Lc       fx.Lifecycle
+	Manager  *shutdown.Manager
+	Consumer Consumer `optional:"true"`
+	Producer Producer `optional:"true"`
+}
+
+// ShutdownModule MQ 关停集成模块
+// 需与 shutdown.Module 一起使用
+var ShutdownModule = fx.Module("mq-shutdown",
+	fx.Invoke(func(p ShutdownParams) {
+		if p.Consumer != nil {
+			RegisterConsumerShutdown(p.Manager, p.Consumer)
+		}
+		if p.Producer != nil {
+			RegisterProducerShutdown(p.Manager, p.Producer)
+		}
+		p.Manager.ShutdownOnStop(p.Lc)
+	}),
+)
@@ -0,0 +1,48 @@
+package mq_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/shutdown"
+)
+
+// recordingProducer 记录 Close 调用
+type recordingProducer struct {
+	rec *recorder
+}
+
+func (p *recordingProducer) SendSync(context.Context, *mq.Message) (*mq.SendResult, error) {
+	return nil, nil
+}
+
+func (p *recordingProducer) SendAsync(context.Context, *mq.Message, mq.SendCallback) error {
+	return nil
+}
+
+func (p *recordingProducer) Close() error {
+	p.rec.add("producer-close")
+	return nil
+}
+
+func TestShutdownClosesConsumerBeforeProducer(t *testing.T) {
+	m := shutdown.NewManager(shutdown.ManagerParams{Logger: logger.NewNop()})
+
+	rec := &recorder{}
+	mq.RegisterProducerShutdown(m, &recordingProducer{rec: rec})
+	mq.RegisterConsumerShutdown(m, &recordingConsumer{rec: rec})
+	m.RegisterHookWithPriority("after-mq", func(context.Context) error {
+		rec.add("after-mq")
+		return nil
+	}, shutdown.PriorityLast)
+
+	m.Shutdown(context.Background())
+
+	want := []string{"consumer-close", "producer-close", "after-mq"}
+	if got := rec.snapshot(); !slices.Equal(got, want) {
+		t.Fatalf("unexpected order: %v", got)
+	}
+}
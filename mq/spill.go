@@ -0,0 +1,584 @@
+package mq
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/metrics"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Spill - 本地落盘缓冲
+ * ========================================================================
+ * 职责: Broker 不可用时将待发送消息追加到本地文件，连接恢复后按顺序重放，
+ *       避免消息丢失或阻塞请求路径
+ * 语义:
+ *   - 缓冲为空时直接发送，发送失败则落盘并返回 Status 为 SendStatusSpilled 的结果；
+ *     调用方 ctx 已结束、消息无效或永久性错误（ErrPermanent）不落盘，直接返回调用方
+ *   - 缓冲非空时新消息直接落盘（不再等待 Broker 超时），保证同一 goroutine 发送的消息有序
+ *   - 后台按 ReplayInterval 从最早的消息开始重放，遇到失败即停止等待下次重放
+ *   - 重放遇到永久性错误立即丢弃；其他错误达到 MaxAttempts 次后丢弃（默认 1000，
+ *     按默认间隔约 80 分钟；负数表示不限，失败消息会阻塞后续重放）
+ *   - 至少一次: 进程在发送成功与记录重放位点之间崩溃时，重启后该消息会被重复发送
+ *   - DelayTime 落盘时换算为绝对投递时间，重放时只延迟剩余部分（已过期则立即投递）；
+ *     DelayLevel 为 Broker 侧的相对级别，重放时按原级别重新计时
+ *   - 缓冲超过 MaxBytes 时返回 ErrSpillFull，消息不落盘
+ *   - Close 停止重放，未发送的消息保留在磁盘上，下次启动时继续重放
+ * 文件:
+ *   <dir>/spill.log     记录: 4 字节长度 + 4 字节 CRC32 + JSON 编码的 Message（含绝对投递时间）
+ *   <dir>/spill.offset  已重放位点，缓冲清空后两个文件均截断
+ *   启动时校验记录，丢弃末尾不完整或损坏的记录
+ * 指标:
+ *   app_mq_spill_depth{dir} / app_mq_spill_bytes{dir}  各缓冲目录当前的消息数与字节数
+ *   app_mq_spill_total{result}                         spilled / replayed / dropped / full
+ * 配置示例:
+ *   mq:
+ *     spill:
+ *       enabled: true
+ *       dir: /var/lib/app/mq-spill
+ *       max_bytes: 268435456
+ *       replay_interval: 5s
+ *
+ * 使用示例:
+ *   spill, err := mq.NewSpillProducer(producer, cfg.Spill, log)
+ *   if err != nil { ... }
+ *   defer spill.Close()
+ * ======================================================================== */
+
+var (
+	// ErrSpillFull 缓冲已满
+	ErrSpillFull = errors.New("mq: spill buffer is full")
+	// ErrSpillClosed 缓冲已关闭
+	ErrSpillClosed = errors.New("mq: spill producer is closed")
+)
+
+var (
+	// spillDepthGauge 缓冲中的消息数
+	spillDepthGauge = metrics.NewGauge("app", "mq", "spill_depth",
+		"Number of messages buffered on local disk", []string{"dir"})
+
+	// spillBytesGauge 缓冲文件大小
+	spillBytesGauge = metrics.NewGauge("app", "mq", "spill_bytes",
+		"Size in bytes of the local spill buffer", []string{"dir"})
+
+	// spillTotal 落盘与重放次数
+	spillTotal = metrics.NewCounter("app", "mq", "spill_total",
+		"Total number of spill buffer operations", []string{"result"})
+)
+
+const (
+	spillLogFile    = "spill.log"
+	spillOffsetFile = "spill.offset"
+	// spillHeaderSize 记录头: 长度 + CRC32
+	spillHeaderSize = 8
+)
+
+// spillRecord 落盘记录，DeliverAt 为 DelayTime 换算后的绝对投递时间
+// Message 内嵌以保持与仅编码 Message 的旧记录兼容
+type spillRecord struct {
+	*Message
+	DeliverAt time.Time `json:",omitzero"`
+}
+
+// SpillConfig 本地落盘缓冲配置
+type SpillConfig struct {
+	Enabled bool `yaml:"enabled" mapstructure:"enabled"`
+	// Dir 缓冲目录（必填），同一目录只能被一个进程使用
+	Dir string `yaml:"dir" mapstructure:"dir"`
+	// MaxBytes 缓冲文件上限，默认 256MB
+	MaxBytes int64 `yaml:"max_bytes" mapstructure:"max_bytes"`
+	// ReplayInterval 重放间隔，默认 5s
+	ReplayInterval time.Duration `yaml:"replay_interval" mapstructure:"replay_interval"`
+	// ReplayTimeout 重放单条消息的超时，默认 10s
+	ReplayTimeout time.Duration `yaml:"replay_timeout" mapstructure:"replay_timeout"`
+	// MaxAttempts 单条消息最大重放次数，超过后丢弃，默认 1000，负数表示不限
+	MaxAttempts int `yaml:"max_attempts" mapstructure:"max_attempts"`
+	// NoSync 落盘后不调用 fsync（吞吐更高，机器掉电可能丢失最近的消息）
+	NoSync bool `yaml:"no_sync" mapstructure:"no_sync"`
+}
+
+func (c SpillConfig) withDefaults() SpillConfig {
+	if c.MaxBytes <= 0 {
+		c.MaxBytes = 256 << 20
+	}
+	if c.ReplayInterval <= 0 {
+		c.ReplayInterval = 5 * time.Second
+	}
+	if c.ReplayTimeout <= 0 {
+		c.ReplayTimeout = 10 * time.Second
+	}
+	if c.MaxAttempts == 0 {
+		c.MaxAttempts = 1000
+	}
+	return c
+}
+
+var _ Flusher = (*SpillProducer)(nil)
+
+// SpillProducer 带本地落盘缓冲的 Producer
+type SpillProducer struct {
+	next Producer
+	cfg  SpillConfig
+	log  *zap.Logger
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64 // 文件有效长度
+	offset   int64 // 已重放位点
+	depth    int   // 未重放的消息数
+	attempts int   // 当前首条消息的重放失败次数
+	closed   bool
+
+	replayMu sync.Mutex // 保证同一时刻只有一个重放
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewSpillProducer 创建带落盘缓冲的 Producer，加载目录中已有的缓冲并启动后台重放
+func NewSpillProducer(next Producer, cfg SpillConfig, log *zap.Logger) (*SpillProducer, error) {
+	if next == nil {
+		return nil, errors.New("mq: spill requires a producer")
+	}
+	if cfg.Dir == "" {
+		return nil, errors.New("mq: spill dir is required")
+	}
+	if log == nil {
+		log = zap.NewNop()
+	}
+	cfg = cfg.withDefaults()
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("mq: create spill dir: %w", err)
+	}
+
+	p := &SpillProducer{
+		next: next,
+		cfg:  cfg,
+		log:  log,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+	if p.depth > 0 {
+		log.Info("mq spill buffer loaded", zap.Int("depth", p.depth), zap.Int64("bytes", p.size-p.offset))
+	}
+	go p.loop()
+	return p, nil
+}
+
+// load 打开缓冲文件，读取重放位点并校验记录，截断末尾不完整的记录
+func (p *SpillProducer) load() error {
+	f, err := os.OpenFile(filepath.Join(p.cfg.Dir, spillLogFile), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("mq: open spill file: %w", err)
+	}
+	offset, err := p.readOffset()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("mq: stat spill file: %w", err)
+	}
+	if offset > info.Size() {
+		offset = 0
+	}
+
+	r := bufio.NewReader(io.NewSectionReader(f, offset, info.Size()-offset))
+	end, depth := offset, 0
+	for {
+		n, err := skipRecord(r, info.Size()-end)
+		if err != nil {
+			break
+		}
+		end += n
+		depth++
+	}
+	if end < info.Size() {
+		p.log.Warn("mq spill file has a truncated or corrupt tail, discarding",
+			zap.Int64("valid", end), zap.Int64("size", info.Size()))
+		if err := f.Truncate(end); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("mq: truncate spill file: %w", err)
+		}
+	}
+
+	p.file, p.size, p.offset, p.depth = f, end, offset, depth
+	if depth == 0 {
+		if err := p.resetLocked(); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	p.updateGauges()
+	return nil
+}
+
+// SendSync 发送消息，Broker 不可用或缓冲非空时落盘
+func (p *SpillProducer) SendSync(ctx context.Context, msg *Message) (*SendResult, error) {
+	if err := validateSpillMessage(msg); err != nil {
+		return nil, err
+	}
+	spilled, err := p.spillIfBacklog(msg)
+	if err != nil {
+		return nil, err
+	}
+	if spilled {
+		return spilledResult(msg), nil
+	}
+
+	result, sendErr := p.next.SendSync(ctx, msg)
+	if sendErr == nil {
+		return result, nil
+	}
+	if !spillable(ctx, sendErr) {
+		return nil, sendErr
+	}
+	if err := p.spill(msg, sendErr); err != nil {
+		return nil, errors.Join(sendErr, err)
+	}
+	return spilledResult(msg), nil
+}
+
+// SendAsync 异步发送消息，Broker 不可用或缓冲非空时落盘并以 SendStatusSpilled 回调
+func (p *SpillProducer) SendAsync(ctx context.Context, msg *Message, callback SendCallback) error {
+	if err := validateSpillMessage(msg); err != nil {
+		return err
+	}
+	if callback == nil {
+		callback = func(*SendResult, error) {}
+	}
+	spilled, err := p.spillIfBacklog(msg)
+	if err != nil {
+		return err
+	}
+	if spilled {
+		callback(spilledResult(msg), nil)
+		return nil
+	}
+
+	sendErr := p.next.SendAsync(ctx, msg, func(result *SendResult, err error) {
+		if err == nil || errors.Is(err, ErrPermanent) {
+			callback(result, err)
+			return
+		}
+		if spillErr := p.spill(msg, err); spillErr != nil {
+			callback(nil, errors.Join(err, spillErr))
+			return
+		}
+		callback(spilledResult(msg), nil)
+	})
+	if sendErr == nil {
+		return nil
+	}
+	if !spillable(ctx, sendErr) {
+		return sendErr
+	}
+	if err := p.spill(msg, sendErr); err != nil {
+		return errors.Join(sendErr, err)
+	}
+	callback(spilledResult(msg), nil)
+	return nil
+}
+
+// Flush 等待下游异步发送完成，缓冲中的消息由后台重放
+func (p *SpillProducer) Flush(ctx context.Context) error {
+	return Flush(ctx, p.next)
+}
+
+// Close 停止重放并关闭下游 Producer，未发送的消息保留在磁盘上
+func (p *SpillProducer) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	close(p.stop)
+	<-p.done
+
+	p.mu.Lock()
+	fileErr := p.file.Close()
+	p.mu.Unlock()
+	return errors.Join(p.next.Close(), fileErr)
+}
+
+// Depth 返回缓冲中的消息数
+func (p *SpillProducer) Depth() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.depth
+}
+
+// Replay 按顺序重放缓冲中的消息，直到缓冲清空、发送失败或 ctx 结束
+// 返回首个发送失败的错误（达到 MaxAttempts 被丢弃的消息不返回错误）
+func (p *SpillProducer) Replay(ctx context.Context) error {
+	p.replayMu.Lock()
+	defer p.replayMu.Unlock()
+
+	for ctx.Err() == nil {
+		p.mu.Lock()
+		if p.closed || p.depth == 0 {
+			p.mu.Unlock()
+			return nil
+		}
+		msg, next, err := p.readLocked(p.offset)
+		p.mu.Unlock()
+		if err != nil {
+			return err
+		}
+
+		sendCtx, cancel := context.WithTimeout(ctx, p.cfg.ReplayTimeout)
+		_, sendErr := p.next.SendSync(sendCtx, msg)
+		cancel()
+
+		p.mu.Lock()
+		if sendErr != nil {
+			p.attempts++
+			permanent := errors.Is(sendErr, ErrPermanent)
+			if !permanent && (p.cfg.MaxAttempts < 0 || p.attempts < p.cfg.MaxAttempts) {
+				p.mu.Unlock()
+				return sendErr
+			}
+			spillTotal.WithLabelValues("dropped").Inc()
+			p.log.Error("mq spill message dropped",
+				zap.String("topic", msg.Topic), zap.Int("attempts", p.attempts),
+				zap.Bool("permanent", permanent), zap.Error(sendErr))
+		} else {
+			spillTotal.WithLabelValues("replayed").Inc()
+		}
+		err = p.advanceLocked(next)
+		p.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+func (p *SpillProducer) loop() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.cfg.ReplayInterval)
+	defer ticker.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			if err := p.Replay(ctx); err != nil && ctx.Err() == nil {
+				p.log.Warn("mq spill replay failed", zap.Int("depth", p.Depth()), zap.Error(err))
+			}
+		}
+	}
+}
+
+// spillIfBacklog 缓冲非空时落盘，返回是否已落盘
+func (p *SpillProducer) spillIfBacklog(msg *Message) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false, ErrSpillClosed
+	}
+	if p.depth == 0 {
+		return false, nil
+	}
+	return true, p.appendLocked(msg)
+}
+
+// spill 发送失败后落盘
+func (p *SpillProducer) spill(msg *Message, cause error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrSpillClosed
+	}
+	if err := p.appendLocked(msg); err != nil {
+		return err
+	}
+	p.log.Warn("mq send failed, message spilled to disk",
+		zap.String("topic", msg.Topic), zap.Int("depth", p.depth), zap.Error(cause))
+	return nil
+}
+
+func (p *SpillProducer) appendLocked(msg *Message) error {
+	rec := spillRecord{Message: msg}
+	if msg.DelayTime > 0 {
+		rec.DeliverAt = time.Now().Add(msg.DelayTime)
+	}
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("mq: encode spill record: %w", err)
+	}
+	record := make([]byte, spillHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[spillHeaderSize:], payload)
+
+	if p.size+int64(len(record)) > p.cfg.MaxBytes {
+		spillTotal.WithLabelValues("full").Inc()
+		return ErrSpillFull
+	}
+	if _, err := p.file.WriteAt(record, p.size); err != nil {
+		// 写入不完整时回退到原长度，避免留下损坏的记录
+		_ = p.file.Truncate(p.size)
+		return fmt.Errorf("mq: write spill record: %w", err)
+	}
+	if !p.cfg.NoSync {
+		if err := p.file.Sync(); err != nil {
+			_ = p.file.Truncate(p.size)
+			return fmt.Errorf("mq: sync spill file: %w", err)
+		}
+	}
+	p.size += int64(len(record))
+	p.depth++
+	spillTotal.WithLabelValues("spilled").Inc()
+	p.updateGauges()
+	return nil
+}
+
+// readLocked 读取 offset 处的记录，返回消息与下一条记录的位点
+func (p *SpillProducer) readLocked(offset int64) (*Message, int64, error) {
+	var header [spillHeaderSize]byte
+	if _, err := p.file.ReadAt(header[:], offset); err != nil {
+		return nil, 0, fmt.Errorf("mq: read spill record: %w", err)
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[0:4]))
+	if _, err := p.file.ReadAt(payload, offset+spillHeaderSize); err != nil {
+		return nil, 0, fmt.Errorf("mq: read spill record: %w", err)
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errors.New("mq: spill record checksum mismatch")
+	}
+	rec := spillRecord{Message: &Message{}}
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return nil, 0, fmt.Errorf("mq: decode spill record: %w", err)
+	}
+	msg := rec.Message
+	if !rec.DeliverAt.IsZero() {
+		// 只保留剩余的延迟，避免从重放时刻重新计时
+		msg.DelayTime = max(time.Until(rec.DeliverAt), 0)
+	}
+	return msg, offset + spillHeaderSize + int64(len(payload)), nil
+}
+
+// advanceLocked 记录已重放位点，缓冲清空时截断文件
+func (p *SpillProducer) advanceLocked(next int64) error {
+	p.offset = next
+	p.depth--
+	p.attempts = 0
+	defer p.updateGauges()
+	if p.depth == 0 {
+		return p.resetLocked()
+	}
+	return p.writeOffset(p.offset)
+}
+
+// resetLocked 清空缓冲文件与位点
+func (p *SpillProducer) resetLocked() error {
+	if err := p.file.Truncate(0); err != nil {
+		return fmt.Errorf("mq: truncate spill file: %w", err)
+	}
+	p.size, p.offset = 0, 0
+	return p.writeOffset(0)
+}
+
+func (p *SpillProducer) updateGauges() {
+	spillDepthGauge.WithLabelValues(p.cfg.Dir).Set(float64(p.depth))
+	spillBytesGauge.WithLabelValues(p.cfg.Dir).Set(float64(p.size - p.offset))
+}
+
+func (p *SpillProducer) readOffset() (int64, error) {
+	data, err := os.ReadFile(filepath.Join(p.cfg.Dir, spillOffsetFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("mq: read spill offset: %w", err)
+	}
+	if len(data) != 8 {
+		return 0, nil
+	}
+	return int64(binary.BigEndian.Uint64(data)), nil
+}
+
+// writeOffset 通过临时文件 + rename 原子更新位点
+func (p *SpillProducer) writeOffset(offset int64) error {
+	var data [8]byte
+	binary.BigEndian.PutUint64(data[:], uint64(offset))
+	path := filepath.Join(p.cfg.Dir, spillOffsetFile)
+	if err := os.WriteFile(path+".tmp", data[:], 0o644); err != nil {
+		return fmt.Errorf("mq: write spill offset: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("mq: write spill offset: %w", err)
+	}
+	return nil
+}
+
+// skipRecord 校验并跳过一条记录，返回记录长度，remaining 为文件剩余字节数
+func skipRecord(r *bufio.Reader, remaining int64) (int64, error) {
+	var header [spillHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+	if int64(binary.BigEndian.Uint32(header[0:4])) > remaining-spillHeaderSize {
+		return 0, io.ErrUnexpectedEOF
+	}
+	payload := make([]byte, binary.BigEndian.Uint32(header[0:4]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, err
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return 0, errors.New("checksum mismatch")
+	}
+	return spillHeaderSize + int64(len(payload)), nil
+}
+
+// validateSpillMessage 校验消息，无效消息直接返回调用方
+func validateSpillMessage(msg *Message) error {
+	if msg == nil {
+		return errors.New("mq: message is required")
+	}
+	if msg.Topic == "" {
+		return errors.New("mq: message topic is required")
+	}
+	return nil
+}
+
+// spillable 判断发送错误是否落盘: 调用方 ctx 已结束或永久性错误直接返回，
+// 其余视为 Broker 暂时不可用
+func spillable(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && !errors.Is(err, ErrPermanent)
+}
+
+func spilledResult(msg *Message) *SendResult {
+	return &SendResult{Topic: msg.Topic, Status: SendStatusSpilled}
+}
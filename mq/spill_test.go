@@ -0,0 +1,303 @@
+package mq_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/mq/mqtest"
+)
+
+// flakyProducer down 为 true 时发送失败，消息体为 reject 时返回永久性错误
+type flakyProducer struct {
+	mq.Producer
+	down   atomic.Bool
+	reject string
+}
+
+func (p *flakyProducer) SendSync(ctx context.Context, msg *mq.Message) (*mq.SendResult, error) {
+	if err := p.check(msg); err != nil {
+		return nil, err
+	}
+	return p.Producer.SendSync(ctx, msg)
+}
+
+func (p *flakyProducer) SendAsync(ctx context.Context, msg *mq.Message, callback mq.SendCallback) error {
+	if err := p.check(msg); err != nil {
+		return err
+	}
+	return p.Producer.SendAsync(ctx, msg, callback)
+}
+
+func (p *flakyProducer) check(msg *mq.Message) error {
+	if p.down.Load() {
+		return errors.New("broker unavailable")
+	}
+	if p.reject != "" && string(msg.Body) == p.reject {
+		return mq.Permanent(errors.New("message too large"))
+	}
+	return nil
+}
+
+func (p *flakyProducer) Close() error { return nil }
+
+func newSpill(t *testing.T, next mq.Producer, cfg mq.SpillConfig) *mq.SpillProducer {
+	t.Helper()
+	cfg.ReplayInterval = time.Hour
+	spill, err := mq.NewSpillProducer(next, cfg, nil)
+	if err != nil {
+		t.Fatalf("new spill producer: %v", err)
+	}
+	t.Cleanup(func() { _ = spill.Close() })
+	return spill
+}
+
+func TestSpillProducerReplaysInOrder(t *testing.T) {
+	broker := mqtest.NewBroker()
+	next := &flakyProducer{Producer: broker.Producer()}
+	next.down.Store(true)
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	spill := newSpill(t, next, mq.SpillConfig{Dir: dir})
+	for _, body := range []string{"a", "b"} {
+		res, err := spill.SendSync(ctx, &mq.Message{Topic: "orders", Body: []byte(body), Properties: map[string]string{"k": body}})
+		if err != nil || res.Status != mq.SendStatusSpilled {
+			t.Fatalf("send %s: res=%+v err=%v", body, res, err)
+		}
+	}
+	var async *mq.SendResult
+	if err := spill.SendAsync(ctx, &mq.Message{Topic: "orders", Body: []byte("c")}, func(r *mq.SendResult, err error) {
+		async = r
+	}); err != nil || async == nil || async.Status != mq.SendStatusSpilled {
+		t.Fatalf("send async: res=%+v err=%v", async, err)
+	}
+
+	// 重启后从磁盘恢复缓冲
+	if err := spill.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	spill = newSpill(t, next, mq.SpillConfig{Dir: dir})
+	if got := spill.Depth(); got != 3 {
+		t.Fatalf("depth after reopen = %d, want 3", got)
+	}
+
+	// 缓冲非空时新消息排在缓冲之后
+	next.down.Store(false)
+	if res, err := spill.SendSync(ctx, &mq.Message{Topic: "orders", Body: []byte("d")}); err != nil || res.Status != mq.SendStatusSpilled {
+		t.Fatalf("send with backlog: res=%+v err=%v", res, err)
+	}
+	if err := spill.Replay(ctx); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	published := broker.Published("orders")
+	if len(published) != 4 {
+		t.Fatalf("published = %d, want 4", len(published))
+	}
+	for i, want := range []string{"a", "b", "c", "d"} {
+		if string(published[i].Body) != want {
+			t.Fatalf("published[%d] = %q, want %q", i, published[i].Body, want)
+		}
+	}
+	if published[1].Properties["k"] != "b" {
+		t.Fatalf("properties not preserved: %+v", published[1].Properties)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "spill.log")); err != nil || info.Size() != 0 || spill.Depth() != 0 {
+		t.Fatalf("spill not drained: depth=%d info=%v err=%v", spill.Depth(), info, err)
+	}
+
+	// 缓冲清空后直接发送
+	if res, err := spill.SendSync(ctx, &mq.Message{Topic: "orders", Body: []byte("e")}); err != nil || res.Status != mq.SendStatusOK {
+		t.Fatalf("direct send: res=%+v err=%v", res, err)
+	}
+}
+
+func TestSpillProducerLimits(t *testing.T) {
+	broker := mqtest.NewBroker()
+	next := &flakyProducer{Producer: broker.Producer()}
+	next.down.Store(true)
+	ctx := context.Background()
+
+	spill := newSpill(t, next, mq.SpillConfig{Dir: t.TempDir(), MaxBytes: 200, MaxAttempts: 2})
+	if _, err := spill.SendSync(ctx, &mq.Message{Topic: "t", Body: []byte("small")}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	_, err := spill.SendSync(ctx, &mq.Message{Topic: "t", Body: make([]byte, 200)})
+	if !errors.Is(err, mq.ErrSpillFull) {
+		t.Fatalf("expected ErrSpillFull, got %v", err)
+	}
+
+	// 达到最大重放次数后丢弃
+	if err := spill.Replay(ctx); err == nil || spill.Depth() != 1 {
+		t.Fatalf("first replay: err=%v depth=%d", err, spill.Depth())
+	}
+	if err := spill.Replay(ctx); err != nil || spill.Depth() != 0 {
+		t.Fatalf("second replay: err=%v depth=%d", err, spill.Depth())
+	}
+	if n := len(broker.Published("t")); n != 0 {
+		t.Fatalf("published = %d, want 0", n)
+	}
+}
+
+func TestSpillProducerReturnsNonRetryableErrors(t *testing.T) {
+	broker := mqtest.NewBroker()
+	next := &flakyProducer{Producer: broker.Producer(), reject: "poison"}
+	ctx := context.Background()
+
+	spill := newSpill(t, next, mq.SpillConfig{Dir: t.TempDir()})
+	if _, err := spill.SendSync(ctx, &mq.Message{Topic: "t", Body: []byte("poison")}); !errors.Is(err, mq.ErrPermanent) {
+		t.Fatalf("expected permanent error, got %v", err)
+	}
+	if err := spill.SendAsync(ctx, &mq.Message{Body: []byte("x")}, nil); err == nil {
+		t.Fatal("expected error for message without topic")
+	}
+
+	// 调用方 ctx 已结束时不落盘
+	next.down.Store(true)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := spill.SendSync(cancelled, &mq.Message{Topic: "t", Body: []byte("late")}); err == nil {
+		t.Fatal("expected error for cancelled ctx")
+	}
+	if got := spill.Depth(); got != 0 {
+		t.Fatalf("depth = %d, want 0", got)
+	}
+
+	// 缓冲中的永久性错误消息重放时立即丢弃，不阻塞后续消息
+	for _, body := range []string{"a", "poison", "b"} {
+		if _, err := spill.SendSync(ctx, &mq.Message{Topic: "t", Body: []byte(body)}); err != nil {
+			t.Fatalf("send %s: %v", body, err)
+		}
+	}
+	next.down.Store(false)
+	if err := spill.Replay(ctx); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	published := broker.Published("t")
+	if len(published) != 2 || string(published[0].Body) != "a" || string(published[1].Body) != "b" || spill.Depth() != 0 {
+		t.Fatalf("published = %d depth = %d", len(published), spill.Depth())
+	}
+}
+
+func TestSpillProducerDiscardsCorruptTail(t *testing.T) {
+	broker := mqtest.NewBroker()
+	next := &flakyProducer{Producer: broker.Producer()}
+	next.down.Store(true)
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	spill := newSpill(t, next, mq.SpillConfig{Dir: dir})
+	if _, err := spill.SendSync(ctx, &mq.Message{Topic: "t", Body: []byte("ok")}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	_ = spill.Close()
+
+	f, err := os.OpenFile(filepath.Join(dir, "spill.log"), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	_, _ = f.Write([]byte{0, 0, 0, 64, 1, 2})
+	_ = f.Close()
+
+	next.down.Store(false)
+	spill = newSpill(t, next, mq.SpillConfig{Dir: dir})
+	if got := spill.Depth(); got != 1 {
+		t.Fatalf("depth = %d, want 1", got)
+	}
+	if err := spill.Replay(ctx); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if published := broker.Published("t"); len(published) != 1 || string(published[0].Body) != "ok" {
+		t.Fatalf("published = %+v", published)
+	}
+}
+
+func TestSpillProducerKeepsDeliverAt(t *testing.T) {
+	broker := mqtest.NewBroker()
+	next := &flakyProducer{Producer: broker.Producer()}
+	next.down.Store(true)
+	ctx := context.Background()
+
+	spill := newSpill(t, next, mq.SpillConfig{Dir: t.TempDir()})
+	for _, delay := range []time.Duration{20 * time.Millisecond, time.Hour} {
+		if _, err := spill.SendSync(ctx, &mq.Message{Topic: "t", Body: []byte("x"), DelayTime: delay}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	next.down.Store(false)
+	if err := spill.Replay(ctx); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	published := broker.Published("t")
+	if len(published) != 2 {
+		t.Fatalf("published = %d, want 2", len(published))
+	}
+	// 已过期的延迟立即投递，未到期的只保留剩余部分
+	if got := published[0].DelayTime; got != 0 {
+		t.Fatalf("expired delay = %v, want 0", got)
+	}
+	if got := published[1].DelayTime; got <= 0 || got > time.Hour-50*time.Millisecond {
+		t.Fatalf("remaining delay = %v, want less than %v", got, time.Hour-50*time.Millisecond)
+	}
+}
+
+func TestSpillProducerGaugesPerDir(t *testing.T) {
+	broker := mqtest.NewBroker()
+	next := &flakyProducer{Producer: broker.Producer()}
+	next.down.Store(true)
+	ctx := context.Background()
+
+	dirA, dirB := t.TempDir(), t.TempDir()
+	a := newSpill(t, next, mq.SpillConfig{Dir: dirA})
+	b := newSpill(t, next, mq.SpillConfig{Dir: dirB})
+	for _, body := range []string{"1", "2"} {
+		if _, err := a.SendSync(ctx, &mq.Message{Topic: "t", Body: []byte(body)}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+	if _, err := b.SendSync(ctx, &mq.Message{Topic: "t", Body: []byte("3")}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	// 多个缓冲的指标互不覆盖
+	if got := spillGauge(t, "app_mq_spill_depth", dirA); got != 2 {
+		t.Fatalf("depth[a] = %v, want 2", got)
+	}
+	if got := spillGauge(t, "app_mq_spill_depth", dirB); got != 1 {
+		t.Fatalf("depth[b] = %v, want 1", got)
+	}
+	if spillGauge(t, "app_mq_spill_bytes", dirA) <= spillGauge(t, "app_mq_spill_bytes", dirB) {
+		t.Fatal("expected spill bytes to be tracked per dir")
+	}
+}
+
+func spillGauge(t *testing.T, name, dir string) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "dir" && l.GetValue() == dir {
+					return m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	t.Fatalf("metric %s for dir %s not found", name, dir)
+	return 0
+}
@@ -0,0 +1,198 @@
+package mq
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/fx"
+)
+
+/* ========================================================================
+ * Consumer 统计 - 分区分配 / 位点 / 积压
+ * ========================================================================
+ * 职责: 暴露消费者的分区分配与消费积压，并提供 Prometheus Collector
+ * 实现:
+ *   - Kafka: 当前会话分配的分区、下一消费位点、高水位与 lag（按主题汇总）
+ *   - RocketMQ: 按主题汇总的队列积压（最大位点 - 消费位点）
+ *   - mqtest: 按主题汇总的待投递消息数
+ * 指标（抓取时实时计算）:
+ *   app_mq_consumer_partition_offset / _high_watermark / _lag
+ *       {type, group, topic, partition}
+ *   app_mq_consumer_topic_lag / app_mq_consumer_assigned_partitions
+ *       {type, group, topic}
+ *
+ * 使用示例:
+ *   if stats, ok := mq.StatsOf(consumer); ok { ... }
+ *   prometheus.MustRegister(mq.NewStatsCollector(consumer))
+ * ======================================================================== */
+
+// ConsumerStats 消费者统计快照
+type ConsumerStats struct {
+	Type     Type   // MQ 类型
+	Group    string // 消费组
+	MemberID string // 组成员 ID（Kafka）
+
+	// Partitions 当前分配的分区（Kafka），未加入消费组时为空
+	Partitions []PartitionStats
+	// Topics 按主题汇总的积压
+	Topics []TopicStats
+}
+
+// PartitionStats 分区统计
+type PartitionStats struct {
+	Topic         string
+	Partition     int32
+	Offset        int64 // 下一条待消费消息的位点，未知时为 -1
+	HighWatermark int64 // 分区高水位，未知时为 -1
+	Lag           int64 // HighWatermark - Offset，未知时为 -1
+}
+
+// TopicStats 主题统计
+type TopicStats struct {
+	Topic      string
+	Partitions int   // 分配的分区/队列数，未知时为 0
+	Lag        int64 // 积压消息数
+}
+
+// StatsProvider 可选接口：支持统计的消费者实现
+type StatsProvider interface {
+	Stats() ConsumerStats
+}
+
+// StatsOf 获取消费者统计，消费者不支持时返回 false
+func StatsOf(consumer Consumer) (ConsumerStats, bool) {
+	p, ok := consumer.(StatsProvider)
+	if !ok {
+		return ConsumerStats{}, false
+	}
+	return p.Stats(), true
+}
+
+// PartitionLag 计算分区 lag，位点未知时返回 -1
+func PartitionLag(offset, highWatermark int64) int64 {
+	if offset < 0 || highWatermark < 0 {
+		return -1
+	}
+	if lag := highWatermark - offset; lag > 0 {
+		return lag
+	}
+	return 0
+}
+
+// SummarizeTopics 按主题汇总分区统计（忽略 lag 未知的分区）
+func SummarizeTopics(partitions []PartitionStats) []TopicStats {
+	index := make(map[string]int)
+	var topics []TopicStats
+	for _, p := range partitions {
+		i, ok := index[p.Topic]
+		if !ok {
+			i = len(topics)
+			index[p.Topic] = i
+			topics = append(topics, TopicStats{Topic: p.Topic})
+		}
+		topics[i].Partitions++
+		if p.Lag > 0 {
+			topics[i].Lag += p.Lag
+		}
+	}
+	return topics
+}
+
+// =============================================================================
+// Prometheus Collector
+// =============================================================================
+
+var (
+	partitionLabels = []string{"type", "group", "topic", "partition"}
+	topicLabels     = []string{"type", "group", "topic"}
+
+	partitionOffsetDesc = prometheus.NewDesc("app_mq_consumer_partition_offset",
+		"Next offset to be consumed per assigned partition", partitionLabels, nil)
+	partitionHighWatermarkDesc = prometheus.NewDesc("app_mq_consumer_partition_high_watermark",
+		"High watermark per assigned partition", partitionLabels, nil)
+	partitionLagDesc = prometheus.NewDesc("app_mq_consumer_partition_lag",
+		"Consumer lag per assigned partition", partitionLabels, nil)
+	topicLagDesc = prometheus.NewDesc("app_mq_consumer_topic_lag",
+		"Consumer lag (backlog) per topic", topicLabels, nil)
+	assignedPartitionsDesc = prometheus.NewDesc("app_mq_consumer_assigned_partitions",
+		"Number of partitions or queues assigned per topic", topicLabels, nil)
+)
+
+type statsCollector struct {
+	consumers []Consumer
+}
+
+// NewStatsCollector 创建消费者统计 Collector，不支持统计的消费者被忽略
+func NewStatsCollector(consumers ...Consumer) prometheus.Collector {
+	return &statsCollector{consumers: consumers}
+}
+
+func (c *statsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- partitionOffsetDesc
+	ch <- partitionHighWatermarkDesc
+	ch <- partitionLagDesc
+	ch <- topicLagDesc
+	ch <- assignedPartitionsDesc
+}
+
+func (c *statsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, consumer := range c.consumers {
+		stats, ok := StatsOf(consumer)
+		if !ok {
+			continue
+		}
+		typ := string(stats.Type)
+
+		for _, p := range stats.Partitions {
+			labels := []string{typ, stats.Group, p.Topic, strconv.FormatInt(int64(p.Partition), 10)}
+			if p.Offset >= 0 {
+				ch <- prometheus.MustNewConstMetric(partitionOffsetDesc, prometheus.GaugeValue, float64(p.Offset), labels...)
+			}
+			if p.HighWatermark >= 0 {
+				ch <- prometheus.MustNewConstMetric(partitionHighWatermarkDesc, prometheus.GaugeValue, float64(p.HighWatermark), labels...)
+			}
+			if p.Lag >= 0 {
+				ch <- prometheus.MustNewConstMetric(partitionLagDesc, prometheus.GaugeValue, float64(p.Lag), labels...)
+			}
+		}
+		for _, t := range stats.Topics {
+			labels := []string{typ, stats.Group, t.Topic}
+			ch <- prometheus.MustNewConstMetric(topicLagDesc, prometheus.GaugeValue, float64(t.Lag), labels...)
+			if t.Partitions > 0 {
+				ch <- prometheus.MustNewConstMetric(assignedPartitionsDesc, prometheus.GaugeValue, float64(t.Partitions), labels...)
+			}
+		}
+	}
+}
+
+// StatsParams 统计指标注册依赖参数
+type StatsParams struct {
+	fx.In
+
+	Consumer   Consumer              `optional:"true"`
+	Registerer prometheus.Registerer `optional:"true"`
+}
+
+// StatsModule 将 Module 提供的 Consumer 统计注册到 Prometheus（默认注册表）
+var StatsModule = fx.Module("mq-stats",
+	fx.Invoke(RegisterStats),
+)
+
+// RegisterStats 注册消费者统计 Collector（重复注册安全）
+func RegisterStats(p StatsParams) error {
+	if p.Consumer == nil {
+		return nil
+	}
+	reg := p.Registerer
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	if err := reg.Register(NewStatsCollector(p.Consumer)); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if !errors.As(err, &already) {
+			return err
+		}
+	}
+	return nil
+}
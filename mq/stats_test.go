@@ -0,0 +1,90 @@
+package mq_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/mq/mqtest"
+)
+
+func TestPartitionLagAndSummary(t *testing.T) {
+	if got := mq.PartitionLag(-1, 10); got != -1 {
+		t.Fatalf("expected unknown lag, got %d", got)
+	}
+	if got := mq.PartitionLag(12, 10); got != 0 {
+		t.Fatalf("expected clamped lag, got %d", got)
+	}
+
+	topics := mq.SummarizeTopics([]mq.PartitionStats{
+		{Topic: "a", Partition: 0, Lag: 3},
+		{Topic: "b", Partition: 0, Lag: -1},
+		{Topic: "a", Partition: 1, Lag: 4},
+	})
+	if len(topics) != 2 || topics[0].Topic != "a" || topics[0].Lag != 7 || topics[0].Partitions != 2 || topics[1].Lag != 0 {
+		t.Fatalf("unexpected summary: %+v", topics)
+	}
+}
+
+func TestStatsCollector(t *testing.T) {
+	broker := mqtest.NewBroker(mqtest.WithManualFlush())
+	consumer := broker.Consumer()
+	noop := func(context.Context, []*mq.ConsumedMessage) (mq.ConsumeResult, error) { return mq.ConsumeSuccess, nil }
+	if err := consumer.Subscribe("orders", noop); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+	if err := consumer.Start(); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	producer := broker.Producer()
+	for i := 0; i < 2; i++ {
+		if _, err := producer.SendSync(context.Background(), mq.NewMessage("orders", []byte("x"))); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+	}
+
+	stats, ok := mq.StatsOf(consumer)
+	if !ok || len(stats.Topics) != 1 || stats.Topics[0].Lag != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	reg := prometheus.NewRegistry()
+	if err := mq.RegisterStats(mq.StatsParams{Consumer: consumer, Registerer: reg}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := mq.RegisterStats(mq.StatsParams{Consumer: consumer, Registerer: reg}); err != nil {
+		t.Fatalf("duplicate register should be ignored: %v", err)
+	}
+	if got := topicLag(t, reg, "orders"); got != 2 {
+		t.Fatalf("expected topic lag 2, got %v", got)
+	}
+
+	broker.Flush()
+	if got := topicLag(t, reg, "orders"); got != 0 {
+		t.Fatalf("expected topic lag 0 after flush, got %v", got)
+	}
+}
+
+func topicLag(t *testing.T, reg *prometheus.Registry, topic string) float64 {
+	t.Helper()
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("gather: %v", err)
+	}
+	for _, mf := range families {
+		if mf.GetName() != "app_mq_consumer_topic_lag" {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "topic" && l.GetValue() == topic {
+					return m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	t.Fatalf("metric for topic %s not found", topic)
+	return 0
+}
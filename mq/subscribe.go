@@ -0,0 +1,99 @@
+package mq
+
+import "errors"
+
+/* ========================================================================
+ * 订阅选项
+ * ========================================================================
+ * 职责: 为单个订阅声明可选参数（消费优先级、消费模式）
+ * 语义:
+ *   - 实现 OptionSubscriber 的消费者按选项订阅，其余消费者忽略优先级
+ *   - 广播订阅无法退化为集群消费：消费者不支持选项时返回 ErrBroadcastUnsupported
+ *   - 统一通过 mq.Subscribe 调用，调用方无需关心具体实现
+ * 广播实现:
+ *   - Kafka: 每个实例使用独立消费组（<group>-broadcast-<instance_id>），从最新位点开始
+ *   - RocketMQ: 独立的 BroadCasting 消费者（<group>_BROADCAST）
+ *   - Redis Streams: 每个实例使用独立消费组（<group>-<name>），仅消费新消息
+ *
+ * 使用示例:
+ *   mq.Subscribe(consumer, "job.urgent", handleUrgent, mq.WithPriority(10))
+ *   mq.Subscribe(consumer, "job.batch", handleBatch) // 默认优先级 0
+ *   mq.Subscribe(consumer, "cache.invalidate", evict, mq.WithBroadcast()) // 每个副本都消费
+ * ======================================================================== */
+
+// ConsumeModel 消费模式
+type ConsumeModel string
+
+const (
+	// ModelClustering 集群消费：同组实例分摊消息（默认）
+	ModelClustering ConsumeModel = "clustering"
+	// ModelBroadcasting 广播消费：每个实例都消费全部消息
+	ModelBroadcasting ConsumeModel = "broadcasting"
+)
+
+// ErrBroadcastUnsupported 消费者不支持广播订阅
+var ErrBroadcastUnsupported = errors.New("mq: consumer does not support broadcast subscriptions")
+
+// SubscribeOptions 订阅参数
+type SubscribeOptions struct {
+	// Priority 消费优先级，数值越大越先消费（仅 Kafka 支持，见 KafkaPriorityConfig）
+	Priority int
+	// Model 消费模式，空值等同 ModelClustering
+	Model ConsumeModel
+}
+
+// Broadcast 是否为广播订阅
+func (o SubscribeOptions) Broadcast() bool {
+	return o.Model == ModelBroadcasting
+}
+
+// SubscribeOption 订阅选项
+type SubscribeOption func(*SubscribeOptions)
+
+// WithPriority 设置订阅的消费优先级
+func WithPriority(priority int) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.Priority = priority
+	}
+}
+
+// WithConsumeModel 设置订阅的消费模式
+func WithConsumeModel(model ConsumeModel) SubscribeOption {
+	return func(o *SubscribeOptions) {
+		o.Model = model
+	}
+}
+
+// WithBroadcast 声明广播订阅（每个实例都消费全部消息）
+func WithBroadcast() SubscribeOption {
+	return WithConsumeModel(ModelBroadcasting)
+}
+
+// ApplySubscribeOptions 应用订阅选项（供 Consumer 实现使用）
+func ApplySubscribeOptions(opts ...SubscribeOption) SubscribeOptions {
+	var o SubscribeOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
+
+// OptionSubscriber 可选接口：支持订阅选项的消费者实现
+type OptionSubscriber interface {
+	SubscribeWithOptions(topic string, handler MessageHandler, opts ...SubscribeOption) error
+}
+
+// Subscribe 按选项订阅主题，消费者不支持选项时退化为 Consumer.Subscribe（广播订阅除外）
+func Subscribe(consumer Consumer, topic string, handler MessageHandler, opts ...SubscribeOption) error {
+	if len(opts) > 0 {
+		if s, ok := consumer.(OptionSubscriber); ok {
+			return s.SubscribeWithOptions(topic, handler, opts...)
+		}
+		if ApplySubscribeOptions(opts...).Broadcast() {
+			return ErrBroadcastUnsupported
+		}
+	}
+	return consumer.Subscribe(topic, handler)
+}
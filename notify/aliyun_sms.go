@@ -0,0 +1,153 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+)
+
+// DefaultAliyunSMSEndpoint 阿里云短信默认接入点
+const DefaultAliyunSMSEndpoint = "https://dysmsapi.aliyuncs.com"
+
+// AliyunSMSConfig 阿里云短信配置
+type AliyunSMSConfig struct {
+	AccessKeyID     string            `yaml:"access_key_id"`
+	AccessKeySecret string            `yaml:"access_key_secret"`
+	SignName        string            `yaml:"sign_name"`
+	RegionID        string            `yaml:"region_id"` // 默认 cn-hangzhou
+	Endpoint        string            `yaml:"endpoint"`  // 默认 https://dysmsapi.aliyuncs.com
+	Templates       map[string]string `yaml:"templates"` // 模板名 -> 短信模板编码，未配置时直接使用模板名
+	Timeout         time.Duration     `yaml:"timeout"`   // 请求超时，默认 10s
+}
+
+func (c AliyunSMSConfig) withDefaults() AliyunSMSConfig {
+	if c.RegionID == "" {
+		c.RegionID = "cn-hangzhou"
+	}
+	if c.Endpoint == "" {
+		c.Endpoint = DefaultAliyunSMSEndpoint
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	return c
+}
+
+// AliyunSMSProvider 阿里云短信 Provider（RPC 签名 V1，无需 SDK）
+// 消息 Data 作为短信模板参数（TemplateParam），To 为手机号列表
+type AliyunSMSProvider struct {
+	cfg    AliyunSMSConfig
+	client *http.Client
+}
+
+// NewAliyunSMSProvider 创建阿里云短信 Provider
+func NewAliyunSMSProvider(cfg AliyunSMSConfig) *AliyunSMSProvider {
+	cfg = cfg.withDefaults()
+	return &AliyunSMSProvider{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Channel 实现 Provider
+func (p *AliyunSMSProvider) Channel() Channel { return ChannelSMS }
+
+// Name 实现 Provider
+func (p *AliyunSMSProvider) Name() string { return "aliyun_sms" }
+
+type aliyunSMSResponse struct {
+	Code      string `json:"Code"`
+	Message   string `json:"Message"`
+	BizID     string `json:"BizId"`
+	RequestID string `json:"RequestId"`
+}
+
+// Send 实现 Provider
+func (p *AliyunSMSProvider) Send(ctx context.Context, msg *Message, _ Content) (string, error) {
+	code := msg.Template
+	if mapped, ok := p.cfg.Templates[msg.Template]; ok {
+		code = mapped
+	}
+	if code == "" {
+		return "", fmt.Errorf("%w: sms template is required", ErrInvalidMessage)
+	}
+	param := "{}"
+	if len(msg.Data) > 0 {
+		raw, err := json.Marshal(msg.Data)
+		if err != nil {
+			return "", fmt.Errorf("notify: encode sms params: %w", err)
+		}
+		param = string(raw)
+	}
+
+	query := url.Values{
+		"AccessKeyId":      {p.cfg.AccessKeyID},
+		"Action":           {"SendSms"},
+		"Format":           {"JSON"},
+		"PhoneNumbers":     {strings.Join(msg.To, ",")},
+		"RegionId":         {p.cfg.RegionID},
+		"SignName":         {p.cfg.SignName},
+		"SignatureMethod":  {"HMAC-SHA1"},
+		"SignatureNonce":   {ulid.GenerateString()},
+		"SignatureVersion": {"1.0"},
+		"TemplateCode":     {code},
+		"TemplateParam":    {param},
+		"Timestamp":        {time.Now().UTC().Format("2006-01-02T15:04:05Z")},
+		"Version":          {"2017-05-25"},
+	}
+	query.Set("Signature", aliyunSign(http.MethodGet, query, p.cfg.AccessKeySecret))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.cfg.Endpoint+"/?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("notify: aliyun sms request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out aliyunSMSResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&out); err != nil {
+		return "", fmt.Errorf("notify: aliyun sms response (status %d): %w", resp.StatusCode, err)
+	}
+	if out.Code != "OK" {
+		return "", fmt.Errorf("notify: aliyun sms failed: %s %s (request %s)", out.Code, out.Message, out.RequestID)
+	}
+	return out.BizID, nil
+}
+
+// aliyunSign 计算阿里云 RPC 签名 V1
+func aliyunSign(method string, query url.Values, secret string) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, aliyunEncode(k)+"="+aliyunEncode(query.Get(k)))
+	}
+	stringToSign := method + "&" + aliyunEncode("/") + "&" + aliyunEncode(strings.Join(pairs, "&"))
+
+	mac := hmac.New(sha1.New, []byte(secret+"&"))
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// aliyunEncode 阿里云 POP 编码（RFC 3986）
+func aliyunEncode(s string) string {
+	s = url.QueryEscape(s)
+	s = strings.ReplaceAll(s, "+", "%20")
+	s = strings.ReplaceAll(s, "*", "%2A")
+	return strings.ReplaceAll(s, "%7E", "~")
+}
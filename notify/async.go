@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/worker"
+
+	"go.uber.org/zap"
+)
+
+// DefaultTaskType worker 异步发送任务类型 / MQ 默认主题
+const DefaultTaskType = "notify.send"
+
+// attemptKey ctx 中的投递次数
+type attemptKey struct{}
+
+// withAttempt 记录当前投递次数（1 起）
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+func attemptFromContext(ctx context.Context) int {
+	if n, ok := ctx.Value(attemptKey{}).(int); ok {
+		return n
+	}
+	return 1
+}
+
+// ========================================================================
+// worker.Pool
+// ========================================================================
+
+type workerDispatcher struct {
+	pool *worker.Pool
+}
+
+func (d *workerDispatcher) dispatch(ctx context.Context, msg *Message) error {
+	task, err := worker.NewTask(DefaultTaskType, msg)
+	if err != nil {
+		return err
+	}
+	return d.pool.Submit(ctx, task)
+}
+
+// UseWorker 通过任务池异步发送；发送失败时返回错误由任务池按配置重试
+func (n *Notifier) UseWorker(pool *worker.Pool) {
+	pool.RegisterFunc(DefaultTaskType, func(ctx context.Context, task *worker.Task) error {
+		var msg Message
+		if err := task.Decode(&msg); err != nil {
+			return fmt.Errorf("notify: decode task: %w", err)
+		}
+		_, err := n.deliver(withAttempt(ctx, task.Attempts), &msg)
+		return err
+	})
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.async = &workerDispatcher{pool: pool}
+}
+
+// ========================================================================
+// MQ
+// ========================================================================
+
+type mqDispatcher struct {
+	producer mq.Producer
+	topic    string
+}
+
+func (d *mqDispatcher) dispatch(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("notify: encode message: %w", err)
+	}
+	_, err = d.producer.SendSync(ctx, mq.NewMessage(d.topic, body).WithKey(msg.ID))
+	return err
+}
+
+// UseMQ 通过消息队列异步发送，consumer 为 nil 时仅投递（由其他服务消费）
+// 需在 consumer.Start 之前调用；发送失败时返回 ConsumeRetryLater 由 MQ 重投
+func (n *Notifier) UseMQ(producer mq.Producer, consumer mq.Consumer, topic string) error {
+	if topic == "" {
+		topic = DefaultTaskType
+	}
+	if consumer != nil {
+		if err := consumer.Subscribe(topic, n.HandleMQ); err != nil {
+			return fmt.Errorf("notify: subscribe %s: %w", topic, err)
+		}
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.async = &mqDispatcher{producer: producer, topic: topic}
+	return nil
+}
+
+// HandleMQ MQ 消费处理器，可在独立消费服务中直接订阅
+// 消息体无法解析时丢弃，避免毒消息反复重投
+func (n *Notifier) HandleMQ(ctx context.Context, msgs []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+	for _, m := range msgs {
+		var msg Message
+		if err := json.Unmarshal(m.Body, &msg); err != nil {
+			n.log.Error("drop malformed notification", zap.String("msg_id", m.MsgID), zap.Error(err))
+			continue
+		}
+		if _, err := n.deliver(withAttempt(ctx, int(m.ReconsumeCnt)+1), &msg); err != nil {
+			return mq.ConsumeRetryLater, err
+		}
+	}
+	return mq.ConsumeSuccess, nil
+}
@@ -0,0 +1,75 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+)
+
+// Async 异步投递方式
+const (
+	AsyncNone   = ""
+	AsyncWorker = "worker"
+	AsyncMQ     = "mq"
+)
+
+// ProvidersConfig 渠道 Provider 配置，未配置的渠道不注册
+type ProvidersConfig struct {
+	SMTP      *SMTPConfig      `yaml:"smtp"`
+	AliyunSMS *AliyunSMSConfig `yaml:"aliyun_sms"`
+	Webhook   *WebhookConfig   `yaml:"webhook"`
+}
+
+// Config 通知配置
+type Config struct {
+	ProvidersConfig `yaml:",inline"`
+
+	// Tenants 租户专属 Provider（按租户 ID），未配置的渠道回退全局配置
+	Tenants map[string]ProvidersConfig `yaml:"tenants"`
+
+	Async        string `yaml:"async"`         // 异步投递方式: worker / mq，为空时 SendAsync 不可用
+	Topic        string `yaml:"topic"`         // MQ 主题，默认 notify.send
+	TemplatesDir string `yaml:"templates_dir"` // 模板目录（按 Renderer.AddFS 约定加载）
+}
+
+// providers 按配置创建 Provider
+func (c ProvidersConfig) providers(log *logger.Logger) []Provider {
+	var out []Provider
+	if c.SMTP != nil {
+		out = append(out, NewSMTPProvider(*c.SMTP))
+	}
+	if c.AliyunSMS != nil {
+		out = append(out, NewAliyunSMSProvider(*c.AliyunSMS))
+	}
+	if c.Webhook != nil {
+		out = append(out, NewWebhookProvider(*c.Webhook, log))
+	}
+	return out
+}
+
+// NewFromConfig 按配置创建通知发送器（不含异步投递）
+func NewFromConfig(cfg *Config, renderer *Renderer, log *logger.Logger) (*Notifier, error) {
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	if renderer == nil {
+		renderer = NewRenderer()
+	}
+	if cfg.TemplatesDir != "" {
+		if err := renderer.AddFS(os.DirFS(cfg.TemplatesDir)); err != nil {
+			return nil, fmt.Errorf("notify: load templates: %w", err)
+		}
+	}
+
+	n := New(renderer, log)
+	for _, p := range cfg.providers(n.log) {
+		n.Register(p)
+	}
+	for tenantID, tc := range cfg.Tenants {
+		for _, p := range tc.providers(n.log) {
+			n.RegisterTenant(tenantID, p)
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,69 @@
+package notify
+
+import (
+	"fmt"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/worker"
+
+	"go.uber.org/fx"
+)
+
+/* ========================================================================
+ * Notify FX Module
+ * ========================================================================
+ * 职责: 按 *Config 提供 *Notifier
+ * 说明:
+ *   - Config.Async = worker 时需要 *worker.Pool（如 worker.Module）
+ *   - Config.Async = mq 时需要 mq.Producer；提供 mq.Consumer 时同进程消费
+ *   - 可选提供 *Renderer 预注册模板
+ * ======================================================================== */
+
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	Config   *Config        `optional:"true"`
+	Renderer *Renderer      `optional:"true"`
+	Pool     *worker.Pool   `optional:"true"`
+	Producer mq.Producer    `optional:"true"`
+	Consumer mq.Consumer    `optional:"true"`
+	Logger   *logger.Logger `optional:"true"`
+}
+
+// NewFromParams 通过 FX 参数创建通知发送器
+func NewFromParams(p Params) (*Notifier, error) {
+	n, err := NewFromConfig(p.Config, p.Renderer, p.Logger)
+	if err != nil {
+		return nil, err
+	}
+	cfg := p.Config
+	if cfg == nil {
+		return n, nil
+	}
+
+	switch cfg.Async {
+	case AsyncNone:
+	case AsyncWorker:
+		if p.Pool == nil {
+			return nil, fmt.Errorf("notify: async worker requires *worker.Pool")
+		}
+		n.UseWorker(p.Pool)
+	case AsyncMQ:
+		if p.Producer == nil {
+			return nil, fmt.Errorf("notify: async mq requires mq.Producer")
+		}
+		if err := n.UseMQ(p.Producer, p.Consumer, cfg.Topic); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("notify: unknown async mode %q", cfg.Async)
+	}
+	return n, nil
+}
+
+// Module FX 模块
+var Module = fx.Module("notify",
+	fx.Provide(NewFromParams),
+)
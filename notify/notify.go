@@ -0,0 +1,293 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Notify - 邮件/短信/Webhook 通知
+ * ========================================================================
+ * 职责: 统一通知发送入口，按渠道选择 Provider，模板渲染后投递
+ * 特性:
+ *   - Provider: SMTP 邮件、阿里云短信、Webhook（HMAC 签名，可由 middleware.WebhookSignature 校验）
+ *   - 模板: 主题/纯文本为 text/template，HTML 为 html/template，支持布局
+ *   - 租户: 按租户注册 Provider 或通过 ProviderLookup 动态查找，缺省回退全局 Provider；
+ *           Message.TenantID 为空时取 ctx 中的 repository.TenantContext
+ *   - 异步: SendAsync 经 worker.Pool 或 MQ 投递，由后台执行发送
+ *   - 回调: OnResult 注册投递结果回调（同步与异步均触发）
+ *
+ * 使用示例:
+ *   r := notify.NewRenderer()
+ *   _ = r.AddLayout("base", `<html><body>{{template "content" .}}</body></html>`)
+ *   _ = r.Add("welcome", notify.Template{Subject: "欢迎 {{.Name}}", HTML: `<p>Hi {{.Name}}</p>`, Layout: "base"})
+ *
+ *   n := notify.New(r, log)
+ *   n.Register(notify.NewSMTPProvider(smtpCfg))
+ *   n.UseWorker(pool)
+ *   err := n.SendAsync(ctx, &notify.Message{Channel: notify.ChannelEmail, To: []string{"a@b.com"},
+ *       Template: "welcome", Data: map[string]any{"Name": "Alice"}})
+ * ======================================================================== */
+
+// Channel 通知渠道
+type Channel string
+
+const (
+	ChannelEmail   Channel = "email"
+	ChannelSMS     Channel = "sms"
+	ChannelWebhook Channel = "webhook"
+)
+
+var (
+	// ErrInvalidMessage 消息不合法
+	ErrInvalidMessage = errors.New("notify: invalid message")
+	// ErrNoProvider 渠道未注册 Provider
+	ErrNoProvider = errors.New("notify: no provider for channel")
+	// ErrTemplateNotFound 模板不存在
+	ErrTemplateNotFound = errors.New("notify: template not found")
+	// ErrAsyncNotConfigured 未配置异步投递
+	ErrAsyncNotConfigured = errors.New("notify: async dispatcher not configured")
+)
+
+// Message 通知消息（可序列化，用于异步投递）
+type Message struct {
+	ID       string            `json:"id"`
+	Channel  Channel           `json:"channel"`
+	TenantID string            `json:"tenant_id,omitempty"`
+	To       []string          `json:"to"`
+	Template string            `json:"template,omitempty"` // 模板名（短信 Provider 可映射为模板编码）
+	Data     map[string]any    `json:"data,omitempty"`     // 模板数据 / 短信模板参数
+	Subject  string            `json:"subject,omitempty"`  // 未使用模板时直接指定
+	Text     string            `json:"text,omitempty"`
+	HTML     string            `json:"html,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"` // 透传给回调与 Webhook
+}
+
+// Content 渲染后的内容
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Provider 通知渠道提供方
+type Provider interface {
+	// Channel 所属渠道
+	Channel() Channel
+	// Name 提供方名称（用于日志与结果）
+	Name() string
+	// Send 发送消息，返回提供方消息 ID（可能为空）
+	Send(ctx context.Context, msg *Message, content Content) (string, error)
+}
+
+// ProviderLookup 动态查找租户 Provider（如从数据库读取租户配置），未配置时返回 nil
+type ProviderLookup func(ctx context.Context, tenantID string, channel Channel) (Provider, error)
+
+// Result 投递结果
+type Result struct {
+	MessageID     string
+	Channel       Channel
+	TenantID      string
+	Provider      string
+	ProviderMsgID string
+	Attempt       int // 投递次数（异步重试时递增）
+	Err           error
+	SentAt        time.Time
+}
+
+// Callback 投递结果回调
+type Callback func(ctx context.Context, msg *Message, res Result)
+
+// dispatcher 异步投递
+type dispatcher interface {
+	dispatch(ctx context.Context, msg *Message) error
+}
+
+// Notifier 通知发送器
+type Notifier struct {
+	renderer *Renderer
+	log      *logger.Logger
+
+	mu        sync.RWMutex
+	providers map[Channel]Provider
+	tenants   map[string]map[Channel]Provider
+	lookup    ProviderLookup
+	callbacks []Callback
+	async     dispatcher
+}
+
+// New 创建通知发送器，renderer 为 nil 时仅支持直接指定内容的消息
+func New(renderer *Renderer, log *logger.Logger) *Notifier {
+	if renderer == nil {
+		renderer = NewRenderer()
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &Notifier{
+		renderer:  renderer,
+		log:       log,
+		providers: make(map[Channel]Provider),
+		tenants:   make(map[string]map[Channel]Provider),
+	}
+}
+
+// Renderer 返回模板渲染器
+func (n *Notifier) Renderer() *Renderer {
+	return n.renderer
+}
+
+// Register 注册全局 Provider（同渠道后注册者覆盖）
+func (n *Notifier) Register(p Provider) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.providers[p.Channel()] = p
+}
+
+// RegisterTenant 注册租户专属 Provider
+func (n *Notifier) RegisterTenant(tenantID string, p Provider) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.tenants[tenantID] == nil {
+		n.tenants[tenantID] = make(map[Channel]Provider)
+	}
+	n.tenants[tenantID][p.Channel()] = p
+}
+
+// SetLookup 设置动态租户 Provider 查找（优先于 RegisterTenant）
+func (n *Notifier) SetLookup(fn ProviderLookup) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.lookup = fn
+}
+
+// OnResult 注册投递结果回调
+func (n *Notifier) OnResult(cb Callback) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.callbacks = append(n.callbacks, cb)
+}
+
+// Send 同步发送
+func (n *Notifier) Send(ctx context.Context, msg *Message) (Result, error) {
+	if err := n.prepare(ctx, msg); err != nil {
+		return Result{}, err
+	}
+	return n.deliver(ctx, msg)
+}
+
+// SendAsync 异步发送，投递结果通过 OnResult 回调获取
+func (n *Notifier) SendAsync(ctx context.Context, msg *Message) error {
+	if err := n.prepare(ctx, msg); err != nil {
+		return err
+	}
+	n.mu.RLock()
+	async := n.async
+	n.mu.RUnlock()
+	if async == nil {
+		return ErrAsyncNotConfigured
+	}
+	return async.dispatch(ctx, msg)
+}
+
+// prepare 校验消息并补全 ID / 租户
+func (n *Notifier) prepare(ctx context.Context, msg *Message) error {
+	if msg == nil || msg.Channel == "" {
+		return fmt.Errorf("%w: channel is required", ErrInvalidMessage)
+	}
+	// Webhook 可使用 Provider 配置的默认地址
+	if len(msg.To) == 0 && msg.Channel != ChannelWebhook {
+		return fmt.Errorf("%w: recipients are required", ErrInvalidMessage)
+	}
+	if msg.ID == "" {
+		msg.ID = ulid.GenerateString()
+	}
+	if msg.TenantID == "" {
+		if tc, ok := repository.TenantFromContext(ctx); ok {
+			msg.TenantID = tc.TenantID.String()
+		}
+	}
+	return nil
+}
+
+// deliver 渲染并发送，触发回调
+func (n *Notifier) deliver(ctx context.Context, msg *Message) (Result, error) {
+	res := Result{MessageID: msg.ID, Channel: msg.Channel, TenantID: msg.TenantID, Attempt: attemptFromContext(ctx)}
+
+	provider, err := n.provider(ctx, msg.TenantID, msg.Channel)
+	if err == nil {
+		res.Provider = provider.Name()
+		var content Content
+		if content, err = n.render(msg); err == nil {
+			res.ProviderMsgID, err = provider.Send(ctx, msg, content)
+		}
+	}
+	res.Err = err
+	res.SentAt = time.Now()
+
+	if err != nil {
+		n.log.WithContext(ctx).Warn("notification failed",
+			zap.String("id", msg.ID),
+			zap.String("channel", string(msg.Channel)),
+			zap.String("provider", res.Provider),
+			zap.Error(err),
+		)
+	}
+
+	n.mu.RLock()
+	callbacks := n.callbacks
+	n.mu.RUnlock()
+	for _, cb := range callbacks {
+		cb(ctx, msg, res)
+	}
+	return res, err
+}
+
+// provider 按 租户 -> 全局 顺序查找 Provider
+func (n *Notifier) provider(ctx context.Context, tenantID string, ch Channel) (Provider, error) {
+	n.mu.RLock()
+	lookup := n.lookup
+	tenant := n.tenants[tenantID]
+	global := n.providers[ch]
+	n.mu.RUnlock()
+
+	if tenantID != "" {
+		if lookup != nil {
+			p, err := lookup(ctx, tenantID, ch)
+			if err != nil {
+				return nil, err
+			}
+			if p != nil {
+				return p, nil
+			}
+		}
+		if p, ok := tenant[ch]; ok {
+			return p, nil
+		}
+	}
+	if global != nil {
+		return global, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoProvider, ch)
+}
+
+// render 渲染模板；短信等由 Provider 自行处理模板编码时允许模板未注册
+func (n *Notifier) render(msg *Message) (Content, error) {
+	content := Content{Subject: msg.Subject, Text: msg.Text, HTML: msg.HTML}
+	if msg.Template == "" || !n.renderer.Has(msg.Template) {
+		if msg.Template != "" && msg.Channel == ChannelEmail && content.Text == "" && content.HTML == "" {
+			return content, fmt.Errorf("%w: %s", ErrTemplateNotFound, msg.Template)
+		}
+		return content, nil
+	}
+	return n.renderer.Render(msg.Template, msg.Data)
+}
@@ -0,0 +1,373 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+	"github.com/aisgo/ais-go-pkg/worker"
+)
+
+type fakeProvider struct {
+	name    string
+	channel Channel
+	fail    int
+
+	mu       sync.Mutex
+	calls    int
+	contents []Content
+}
+
+func (p *fakeProvider) Channel() Channel { return p.channel }
+func (p *fakeProvider) Name() string     { return p.name }
+
+func (p *fakeProvider) Send(_ context.Context, msg *Message, content Content) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.calls <= p.fail {
+		return "", errors.New("temporary failure")
+	}
+	p.contents = append(p.contents, content)
+	return p.name + "-" + msg.ID, nil
+}
+
+func (p *fakeProvider) count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
+
+func TestRendererLayoutAndEscaping(t *testing.T) {
+	r := NewRenderer()
+	if err := r.AddLayout("base", `<html><body>{{template "content" .}}</body></html>`); err != nil {
+		t.Fatalf("add layout: %v", err)
+	}
+	if err := r.Add("welcome", Template{
+		Subject: "欢迎 {{.Name}}\r\nBcc: evil@x.com",
+		Text:    "Hi {{.Name}}",
+		HTML:    `<p>Hi {{.Name}}</p>`,
+		Layout:  "base",
+	}); err != nil {
+		t.Fatalf("add template: %v", err)
+	}
+	if err := r.Add("bad", Template{HTML: "x", Layout: "missing"}); err == nil {
+		t.Fatalf("expected missing layout error")
+	}
+
+	content, err := r.Render("welcome", map[string]any{"Name": "<b>Alice</b>"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if content.Subject != "欢迎 <b>Alice</b> Bcc: evil@x.com" {
+		t.Fatalf("subject must be single line, got %q", content.Subject)
+	}
+	if content.HTML != "<html><body><p>Hi &lt;b&gt;Alice&lt;/b&gt;</p></body></html>" {
+		t.Fatalf("unexpected html: %q", content.HTML)
+	}
+	if content.Text != "Hi <b>Alice</b>" {
+		t.Fatalf("unexpected text: %q", content.Text)
+	}
+	if _, err := r.Render("missing", nil); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expected ErrTemplateNotFound, got %v", err)
+	}
+}
+
+func TestRendererAddFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/default.html": {Data: []byte(`<div>{{template "content" .}}</div>`)},
+		"reset.subject.txt":    {Data: []byte("重置密码")},
+		"reset.html":           {Data: []byte(`<a href="{{.URL}}">reset</a>`)},
+		"reset.txt":            {Data: []byte("open {{.URL}}")},
+		"README.md":            {Data: []byte("ignored")},
+	}
+	r := NewRenderer()
+	if err := r.AddFS(fsys); err != nil {
+		t.Fatalf("add fs: %v", err)
+	}
+	content, err := r.Render("reset", map[string]string{"URL": "https://x/r"})
+	if err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	if content.Subject != "重置密码" || content.Text != "open https://x/r" ||
+		content.HTML != `<div><a href="https://x/r">reset</a></div>` {
+		t.Fatalf("unexpected content: %+v", content)
+	}
+}
+
+func TestNotifierTenantProvidersAndCallbacks(t *testing.T) {
+	global := &fakeProvider{name: "global", channel: ChannelEmail}
+	tenantP := &fakeProvider{name: "tenant", channel: ChannelEmail}
+	tenantID := ulid.Generate()
+
+	n := New(nil, nil)
+	_ = n.Renderer().Add("hello", Template{Subject: "Hello {{.Name}}", Text: "body"})
+	n.Register(global)
+	n.RegisterTenant(tenantID.String(), tenantP)
+
+	var results []Result
+	n.OnResult(func(_ context.Context, _ *Message, res Result) { results = append(results, res) })
+
+	msg := &Message{Channel: ChannelEmail, To: []string{"a@b.com"}, Template: "hello", Data: map[string]any{"Name": "Bob"}}
+	res, err := n.Send(context.Background(), msg)
+	if err != nil || res.Provider != "global" || msg.ID == "" {
+		t.Fatalf("expected global provider, got %+v %v", res, err)
+	}
+
+	ctx := repository.WithTenantContext(context.Background(), repository.TenantContext{TenantID: tenantID})
+	res, err = n.Send(ctx, &Message{Channel: ChannelEmail, To: []string{"a@b.com"}, Template: "hello"})
+	if err != nil || res.Provider != "tenant" || res.TenantID != tenantID.String() {
+		t.Fatalf("expected tenant provider, got %+v %v", res, err)
+	}
+	if tenantP.contents[0].Subject != "Hello <no value>" || global.contents[0].Subject != "Hello Bob" {
+		t.Fatalf("unexpected contents: %+v %+v", global.contents, tenantP.contents)
+	}
+
+	// 动态查找优先于静态注册
+	lookupP := &fakeProvider{name: "lookup", channel: ChannelEmail}
+	n.SetLookup(func(_ context.Context, id string, ch Channel) (Provider, error) {
+		if id == tenantID.String() && ch == ChannelEmail {
+			return lookupP, nil
+		}
+		return nil, nil
+	})
+	if res, _ = n.Send(ctx, &Message{Channel: ChannelEmail, To: []string{"a@b.com"}, Text: "x"}); res.Provider != "lookup" {
+		t.Fatalf("expected lookup provider, got %+v", res)
+	}
+
+	if _, err := n.Send(ctx, &Message{Channel: ChannelSMS, To: []string{"13800000000"}}); !errors.Is(err, ErrNoProvider) {
+		t.Fatalf("expected ErrNoProvider, got %v", err)
+	}
+	if _, err := n.Send(ctx, &Message{Channel: ChannelEmail, To: []string{"a@b.com"}, Template: "nope"}); !errors.Is(err, ErrTemplateNotFound) {
+		t.Fatalf("expected ErrTemplateNotFound, got %v", err)
+	}
+	if _, err := n.Send(ctx, &Message{Channel: ChannelEmail}); !errors.Is(err, ErrInvalidMessage) {
+		t.Fatalf("expected ErrInvalidMessage, got %v", err)
+	}
+	if len(results) != 5 || results[3].Err == nil || results[4].Err == nil {
+		t.Fatalf("expected callbacks for every delivery, got %+v", results)
+	}
+	if err := n.SendAsync(ctx, &Message{Channel: ChannelEmail, To: []string{"a@b.com"}}); !errors.Is(err, ErrAsyncNotConfigured) {
+		t.Fatalf("expected ErrAsyncNotConfigured, got %v", err)
+	}
+}
+
+func TestNotifierWorkerRetries(t *testing.T) {
+	p := &fakeProvider{name: "sms", channel: ChannelSMS, fail: 1}
+	n := New(nil, nil)
+	n.Register(p)
+
+	pool := worker.New(&worker.Config{Workers: 1, MaxRetries: 2, RetryBackoff: time.Millisecond}, nil, nil)
+	n.UseWorker(pool)
+	pool.Start()
+	defer pool.Stop(context.Background())
+
+	done := make(chan Result, 4)
+	n.OnResult(func(_ context.Context, _ *Message, res Result) { done <- res })
+
+	if err := n.SendAsync(context.Background(), &Message{Channel: ChannelSMS, To: []string{"13800000000"}, Template: "SMS_1"}); err != nil {
+		t.Fatalf("send async: %v", err)
+	}
+	for want := 1; want <= 2; want++ {
+		select {
+		case res := <-done:
+			if res.Attempt != want || (want == 1) != (res.Err != nil) {
+				t.Fatalf("unexpected result for attempt %d: %+v", want, res)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for attempt %d", want)
+		}
+	}
+}
+
+type fakeProducer struct {
+	msgs []*mq.Message
+}
+
+func (p *fakeProducer) SendSync(_ context.Context, msg *mq.Message) (*mq.SendResult, error) {
+	p.msgs = append(p.msgs, msg)
+	return &mq.SendResult{}, nil
+}
+
+func (p *fakeProducer) SendAsync(ctx context.Context, msg *mq.Message, cb mq.SendCallback) error {
+	res, err := p.SendSync(ctx, msg)
+	cb(res, err)
+	return nil
+}
+
+func (p *fakeProducer) Close() error { return nil }
+
+type fakeConsumer struct {
+	handlers map[string]mq.MessageHandler
+}
+
+func (c *fakeConsumer) Subscribe(topic string, h mq.MessageHandler) error {
+	c.handlers[topic] = h
+	return nil
+}
+func (c *fakeConsumer) Start() error { return nil }
+func (c *fakeConsumer) Close() error { return nil }
+
+func TestNotifierMQ(t *testing.T) {
+	p := &fakeProvider{name: "hook", channel: ChannelWebhook, fail: 1}
+	n := New(nil, nil)
+	n.Register(p)
+
+	producer := &fakeProducer{}
+	consumer := &fakeConsumer{handlers: map[string]mq.MessageHandler{}}
+	if err := n.UseMQ(producer, consumer, ""); err != nil {
+		t.Fatalf("use mq: %v", err)
+	}
+	if err := n.SendAsync(context.Background(), &Message{Channel: ChannelWebhook, Data: map[string]any{"k": "v"}}); err != nil {
+		t.Fatalf("send async: %v", err)
+	}
+	if len(producer.msgs) != 1 || producer.msgs[0].Topic != DefaultTaskType {
+		t.Fatalf("unexpected produced messages: %+v", producer.msgs)
+	}
+
+	handler := consumer.handlers[DefaultTaskType]
+	consumed := []*mq.ConsumedMessage{{Body: producer.msgs[0].Body}}
+	if res, err := handler(context.Background(), consumed); res != mq.ConsumeRetryLater || err == nil {
+		t.Fatalf("expected retry on failure, got %v %v", res, err)
+	}
+	consumed[0].ReconsumeCnt = 1
+	if res, err := handler(context.Background(), consumed); res != mq.ConsumeSuccess || err != nil {
+		t.Fatalf("expected success, got %v %v", res, err)
+	}
+	if res, _ := handler(context.Background(), []*mq.ConsumedMessage{{Body: []byte("{")}}); res != mq.ConsumeSuccess {
+		t.Fatalf("malformed messages should be dropped")
+	}
+	if p.count() != 2 {
+		t.Fatalf("expected 2 provider calls, got %d", p.count())
+	}
+}
+
+func TestWebhookProviderSignsRequests(t *testing.T) {
+	var (
+		gotBody []byte
+		gotHdr  http.Header
+		calls   int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHdr = r.Header.Clone()
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	p := NewWebhookProvider(WebhookConfig{URL: srv.URL, Secret: "s3cret", Headers: map[string]string{"X-App": "ais"}}, nil)
+	msg := &Message{ID: "01J0000000000000000000000A", Channel: ChannelWebhook, Data: map[string]any{"order": 1}}
+	if _, err := p.Send(context.Background(), msg, Content{Subject: "paid"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expected retry on 503, got %d calls", calls)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(gotHdr.Get("X-Timestamp") + "."))
+	mac.Write(gotBody)
+	if got := gotHdr.Get("X-Signature"); got != hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("unexpected signature %q", got)
+	}
+	if gotHdr.Get("Idempotency-Key") != msg.ID || gotHdr.Get("X-App") != "ais" {
+		t.Fatalf("unexpected headers: %v", gotHdr)
+	}
+	var payload WebhookPayload
+	if err := json.Unmarshal(gotBody, &payload); err != nil || payload.Subject != "paid" || payload.ID != msg.ID {
+		t.Fatalf("unexpected payload %s: %v", gotBody, err)
+	}
+}
+
+func TestAliyunSMSProvider(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = io.WriteString(w, `{"Code":"OK","Message":"OK","BizId":"biz-1","RequestId":"req-1"}`)
+	}))
+	defer srv.Close()
+
+	p := NewAliyunSMSProvider(AliyunSMSConfig{
+		AccessKeyID:     "ak",
+		AccessKeySecret: "sk",
+		SignName:        "阿里云",
+		Endpoint:        srv.URL,
+		Templates:       map[string]string{"verify": "SMS_123"},
+	})
+	id, err := p.Send(context.Background(), &Message{To: []string{"13800000000", "13900000000"}, Template: "verify",
+		Data: map[string]any{"code": "1234"}}, Content{})
+	if err != nil || id != "biz-1" {
+		t.Fatalf("send: %q %v", id, err)
+	}
+	if query.Get("TemplateCode") != "SMS_123" || query.Get("PhoneNumbers") != "13800000000,13900000000" ||
+		query.Get("TemplateParam") != `{"code":"1234"}` {
+		t.Fatalf("unexpected query: %v", query)
+	}
+
+	sig := query.Get("Signature")
+	query.Del("Signature")
+	if want := aliyunSign(http.MethodGet, query, "sk"); sig != want {
+		t.Fatalf("signature mismatch: %q != %q", sig, want)
+	}
+}
+
+func TestAliyunSign(t *testing.T) {
+	// 阿里云 RPC 签名文档示例
+	query := url.Values{
+		"AccessKeyId":      {"testid"},
+		"Action":           {"DescribeRegions"},
+		"Format":           {"XML"},
+		"SignatureMethod":  {"HMAC-SHA1"},
+		"SignatureNonce":   {"3ee8c1b8-83d3-44af-a94f-4e0ad82fd6cf"},
+		"SignatureVersion": {"1.0"},
+		"Timestamp":        {"2016-02-23T12:46:24Z"},
+		"Version":          {"2014-05-26"},
+	}
+	if got := aliyunSign(http.MethodGet, query, "testsecret"); got != "OLeaidS1JvxuMvnyHOwuJ+uX5qY=" {
+		t.Fatalf("unexpected signature %q", got)
+	}
+}
+
+func TestBuildMIME(t *testing.T) {
+	msg := &Message{ID: "01J0000000000000000000000A", To: []string{"a@b.com", "c@d.com"}}
+	raw, err := buildMIME("Sender <noreply@x.com>", msg, Content{Subject: "你好", Text: "plain", HTML: "<p>html</p>"})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	body := string(raw)
+	for _, want := range []string{
+		"From: Sender <noreply@x.com>\r\n",
+		"To: a@b.com, c@d.com\r\n",
+		"Subject: =?utf-8?q?",
+		`multipart/alternative; boundary="notify-` + msg.ID + `"`,
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Type: text/html; charset=utf-8",
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("missing %q in:\n%s", want, body)
+		}
+	}
+	if addr, _ := parseAddress("Sender <noreply@x.com>"); addr != "noreply@x.com" {
+		t.Fatalf("unexpected address %q", addr)
+	}
+}
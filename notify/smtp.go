@@ -0,0 +1,190 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig SMTP 邮件配置
+type SMTPConfig struct {
+	Host     string        `yaml:"host"`
+	Port     int           `yaml:"port"` // 默认 587；465 时使用隐式 TLS
+	Username string        `yaml:"username"`
+	Password string        `yaml:"password"`
+	From     string        `yaml:"from"`     // 发件人地址，可为 "名称 <addr>"
+	Timeout  time.Duration `yaml:"timeout"`  // 连接超时，默认 10s
+	Insecure bool          `yaml:"insecure"` // 跳过 TLS 证书校验（仅测试环境）
+	NoTLS    bool          `yaml:"no_tls"`   // 禁用 STARTTLS（仅内网中继）
+}
+
+func (c SMTPConfig) withDefaults() SMTPConfig {
+	if c.Port == 0 {
+		c.Port = 587
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	return c
+}
+
+// SMTPProvider SMTP 邮件 Provider
+type SMTPProvider struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPProvider 创建 SMTP Provider
+func NewSMTPProvider(cfg SMTPConfig) *SMTPProvider {
+	return &SMTPProvider{cfg: cfg.withDefaults()}
+}
+
+// Channel 实现 Provider
+func (p *SMTPProvider) Channel() Channel { return ChannelEmail }
+
+// Name 实现 Provider
+func (p *SMTPProvider) Name() string { return "smtp" }
+
+// Send 实现 Provider
+func (p *SMTPProvider) Send(ctx context.Context, msg *Message, content Content) (string, error) {
+	for _, addr := range msg.To {
+		if strings.ContainsAny(addr, "\r\n") {
+			return "", fmt.Errorf("%w: invalid recipient", ErrInvalidMessage)
+		}
+	}
+	from := p.cfg.From
+	if addr, err := parseAddress(from); err == nil {
+		from = addr
+	}
+	body, err := buildMIME(p.cfg.From, msg, content)
+	if err != nil {
+		return "", err
+	}
+
+	addr := net.JoinHostPort(p.cfg.Host, strconv.Itoa(p.cfg.Port))
+	dialer := &net.Dialer{Timeout: p.cfg.Timeout}
+	tlsCfg := &tls.Config{ServerName: p.cfg.Host, InsecureSkipVerify: p.cfg.Insecure} //nolint:gosec // 仅测试环境显式开启
+
+	var conn net.Conn
+	if p.cfg.Port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsCfg}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return "", fmt.Errorf("notify: smtp dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, p.cfg.Host)
+	if err != nil {
+		_ = conn.Close()
+		return "", fmt.Errorf("notify: smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && !p.cfg.NoTLS && p.cfg.Port != 465 {
+		if err := client.StartTLS(tlsCfg); err != nil {
+			return "", fmt.Errorf("notify: smtp starttls: %w", err)
+		}
+	}
+	if p.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", p.cfg.Username, p.cfg.Password, p.cfg.Host)); err != nil {
+			return "", fmt.Errorf("notify: smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(from); err != nil {
+		return "", fmt.Errorf("notify: smtp mail from: %w", err)
+	}
+	for _, rcpt := range msg.To {
+		if err := client.Rcpt(rcpt); err != nil {
+			return "", fmt.Errorf("notify: smtp rcpt %s: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return "", fmt.Errorf("notify: smtp data: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		_ = w.Close()
+		return "", fmt.Errorf("notify: smtp write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("notify: smtp data: %w", err)
+	}
+	_ = client.Quit()
+	return msg.ID, nil
+}
+
+// parseAddress 提取 "名称 <addr>" 中的地址
+func parseAddress(s string) (string, error) {
+	if i := strings.LastIndex(s, "<"); i >= 0 {
+		if j := strings.LastIndex(s, ">"); j > i {
+			return strings.TrimSpace(s[i+1 : j]), nil
+		}
+		return "", fmt.Errorf("invalid address %q", s)
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// buildMIME 构造邮件内容，同时存在纯文本与 HTML 时使用 multipart/alternative
+func buildMIME(from string, msg *Message, content Content) ([]byte, error) {
+	var buf bytes.Buffer
+	writeHeader := func(k, v string) {
+		buf.WriteString(k)
+		buf.WriteString(": ")
+		buf.WriteString(v)
+		buf.WriteString("\r\n")
+	}
+	writeHeader("From", from)
+	writeHeader("To", strings.Join(msg.To, ", "))
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", content.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", "<"+msg.ID+"@notify>")
+	writeHeader("MIME-Version", "1.0")
+
+	writePart := func(contentType, body string) error {
+		buf.WriteString("Content-Type: " + contentType + "; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(body)); err != nil {
+			return err
+		}
+		if err := qp.Close(); err != nil {
+			return err
+		}
+		buf.WriteString("\r\n")
+		return nil
+	}
+
+	switch {
+	case content.Text != "" && content.HTML != "":
+		boundary := "notify-" + msg.ID
+		buf.WriteString(`Content-Type: multipart/alternative; boundary="` + boundary + "\"\r\n\r\n")
+		for _, part := range []struct{ ct, body string }{{"text/plain", content.Text}, {"text/html", content.HTML}} {
+			buf.WriteString("--" + boundary + "\r\n")
+			if err := writePart(part.ct, part.body); err != nil {
+				return nil, err
+			}
+		}
+		buf.WriteString("--" + boundary + "--\r\n")
+	case content.HTML != "":
+		if err := writePart("text/html", content.HTML); err != nil {
+			return nil, err
+		}
+	default:
+		if err := writePart("text/plain", content.Text); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
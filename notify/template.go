@@ -0,0 +1,219 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+)
+
+/* ========================================================================
+ * Renderer - 通知模板渲染
+ * ========================================================================
+ * 模板组成:
+ *   - Subject: 主题（text/template）
+ *   - Text:    纯文本正文 / 短信内容（text/template）
+ *   - HTML:    HTML 正文（html/template，自动转义），可指定 Layout
+ * 布局: 布局模板通过 {{template "content" .}} 引用正文
+ *
+ * 目录加载（AddFS）约定:
+ *   layouts/<layout>.html     布局
+ *   <name>.subject.txt        主题
+ *   <name>.txt                纯文本正文
+ *   <name>.html               HTML 正文（使用名为 "default" 的布局，如存在）
+ * ======================================================================== */
+
+// DefaultLayout AddFS 加载的 HTML 模板默认使用的布局名
+const DefaultLayout = "default"
+
+// Template 通知模板
+type Template struct {
+	Subject string
+	Text    string
+	HTML    string
+	Layout  string // 布局名（仅作用于 HTML）
+}
+
+type compiledTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// Renderer 模板渲染器（并发安全）
+type Renderer struct {
+	mu        sync.RWMutex
+	layouts   map[string]string
+	templates map[string]*compiledTemplate
+}
+
+// NewRenderer 创建模板渲染器
+func NewRenderer() *Renderer {
+	return &Renderer{
+		layouts:   make(map[string]string),
+		templates: make(map[string]*compiledTemplate),
+	}
+}
+
+// AddLayout 注册布局，需在引用该布局的模板之前注册
+func (r *Renderer) AddLayout(name, src string) error {
+	if _, err := htmltemplate.New(name).Parse(src); err != nil {
+		return fmt.Errorf("notify: parse layout %s: %w", name, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.layouts[name] = src
+	return nil
+}
+
+// Add 注册模板（同名覆盖）
+func (r *Renderer) Add(name string, tpl Template) error {
+	r.mu.RLock()
+	layout, hasLayout := r.layouts[tpl.Layout]
+	r.mu.RUnlock()
+	if tpl.Layout != "" && !hasLayout {
+		return fmt.Errorf("notify: template %s: layout %s not found", name, tpl.Layout)
+	}
+
+	ct := &compiledTemplate{}
+	var err error
+	if tpl.Subject != "" {
+		if ct.subject, err = texttemplate.New(name + ".subject").Parse(tpl.Subject); err != nil {
+			return fmt.Errorf("notify: parse subject of %s: %w", name, err)
+		}
+	}
+	if tpl.Text != "" {
+		if ct.text, err = texttemplate.New(name + ".text").Parse(tpl.Text); err != nil {
+			return fmt.Errorf("notify: parse text of %s: %w", name, err)
+		}
+	}
+	if tpl.HTML != "" {
+		if tpl.Layout != "" {
+			ct.html, err = htmltemplate.New(tpl.Layout).Parse(layout)
+			if err == nil {
+				_, err = ct.html.New("content").Parse(tpl.HTML)
+			}
+		} else {
+			ct.html, err = htmltemplate.New("content").Parse(tpl.HTML)
+		}
+		if err != nil {
+			return fmt.Errorf("notify: parse html of %s: %w", name, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.templates[name] = ct
+	return nil
+}
+
+// AddFS 按目录约定从文件系统加载布局与模板
+func (r *Renderer) AddFS(fsys fs.FS) error {
+	layouts, err := fs.Glob(fsys, "layouts/*.html")
+	if err != nil {
+		return err
+	}
+	for _, file := range layouts {
+		src, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		if err := r.AddLayout(strings.TrimSuffix(path.Base(file), ".html"), string(src)); err != nil {
+			return err
+		}
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+	tpls := make(map[string]*Template)
+	get := func(name string) *Template {
+		if tpls[name] == nil {
+			tpls[name] = &Template{}
+		}
+		return tpls[name]
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		file := e.Name()
+		var field *string
+		switch {
+		case strings.HasSuffix(file, ".subject.txt"):
+			field = &get(strings.TrimSuffix(file, ".subject.txt")).Subject
+		case strings.HasSuffix(file, ".txt"):
+			field = &get(strings.TrimSuffix(file, ".txt")).Text
+		case strings.HasSuffix(file, ".html"):
+			field = &get(strings.TrimSuffix(file, ".html")).HTML
+		default:
+			continue
+		}
+		src, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		*field = string(src)
+	}
+
+	r.mu.RLock()
+	_, hasDefault := r.layouts[DefaultLayout]
+	r.mu.RUnlock()
+	for name, tpl := range tpls {
+		if tpl.HTML != "" && hasDefault {
+			tpl.Layout = DefaultLayout
+		}
+		if err := r.Add(name, *tpl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Has 模板是否已注册
+func (r *Renderer) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.templates[name]
+	return ok
+}
+
+// Render 渲染模板
+func (r *Renderer) Render(name string, data any) (Content, error) {
+	r.mu.RLock()
+	ct, ok := r.templates[name]
+	r.mu.RUnlock()
+	if !ok {
+		return Content{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+
+	var content Content
+	var buf bytes.Buffer
+	if ct.subject != nil {
+		if err := ct.subject.Execute(&buf, data); err != nil {
+			return Content{}, fmt.Errorf("notify: render subject of %s: %w", name, err)
+		}
+		// 主题不允许换行，防止邮件头注入
+		content.Subject = strings.Join(strings.Fields(buf.String()), " ")
+		buf.Reset()
+	}
+	if ct.text != nil {
+		if err := ct.text.Execute(&buf, data); err != nil {
+			return Content{}, fmt.Errorf("notify: render text of %s: %w", name, err)
+		}
+		content.Text = buf.String()
+		buf.Reset()
+	}
+	if ct.html != nil {
+		if err := ct.html.Execute(&buf, data); err != nil {
+			return Content{}, fmt.Errorf("notify: render html of %s: %w", name, err)
+		}
+		content.HTML = buf.String()
+	}
+	return content, nil
+}
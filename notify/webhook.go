@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/httpclient"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+)
+
+// WebhookConfig Webhook 通知配置
+type WebhookConfig struct {
+	URL             string            `yaml:"url"`              // 默认目标地址，消息 To 为空时使用
+	Secret          string            `yaml:"secret"`           // HMAC 签名密钥，为空时不签名
+	SignatureHeader string            `yaml:"signature_header"` // 默认 X-Signature
+	TimestampHeader string            `yaml:"timestamp_header"` // 默认 X-Timestamp
+	Headers         map[string]string `yaml:"headers"`          // 附加请求头
+
+	HTTP httpclient.Config `yaml:"http"`
+}
+
+func (c WebhookConfig) withDefaults() WebhookConfig {
+	if c.SignatureHeader == "" {
+		c.SignatureHeader = "X-Signature"
+	}
+	if c.TimestampHeader == "" {
+		c.TimestampHeader = "X-Timestamp"
+	}
+	return c
+}
+
+// WebhookPayload Webhook 请求体
+type WebhookPayload struct {
+	ID        string            `json:"id"`
+	TenantID  string            `json:"tenant_id,omitempty"`
+	Template  string            `json:"template,omitempty"`
+	Subject   string            `json:"subject,omitempty"`
+	Text      string            `json:"text,omitempty"`
+	HTML      string            `json:"html,omitempty"`
+	Data      map[string]any    `json:"data,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Timestamp int64             `json:"timestamp"`
+}
+
+// WebhookProvider Webhook Provider
+// 以 JSON POST 推送，签名与 middleware.WebhookSignature 默认 hmac 方案一致；
+// 请求携带 Idempotency-Key（消息 ID），网络错误与 5xx 时按 httpclient 配置重试
+type WebhookProvider struct {
+	cfg    WebhookConfig
+	client *httpclient.Client
+}
+
+// NewWebhookProvider 创建 Webhook Provider
+func NewWebhookProvider(cfg WebhookConfig, log *logger.Logger) *WebhookProvider {
+	cfg = cfg.withDefaults()
+	p := &WebhookProvider{cfg: cfg}
+	var opts []httpclient.Option
+	if cfg.Secret != "" {
+		opts = append(opts, httpclient.WithSigner(httpclient.SignerFunc(p.sign)))
+	}
+	p.client = httpclient.New(cfg.HTTP, log, opts...)
+	return p
+}
+
+// Channel 实现 Provider
+func (p *WebhookProvider) Channel() Channel { return ChannelWebhook }
+
+// Name 实现 Provider
+func (p *WebhookProvider) Name() string { return "webhook" }
+
+// Send 实现 Provider，依次推送到每个目标地址
+func (p *WebhookProvider) Send(ctx context.Context, msg *Message, content Content) (string, error) {
+	targets := msg.To
+	if len(targets) == 0 && p.cfg.URL != "" {
+		targets = []string{p.cfg.URL}
+	}
+	if len(targets) == 0 {
+		return "", fmt.Errorf("%w: webhook url is required", ErrInvalidMessage)
+	}
+
+	body, err := json.Marshal(WebhookPayload{
+		ID:        msg.ID,
+		TenantID:  msg.TenantID,
+		Template:  msg.Template,
+		Subject:   content.Subject,
+		Text:      content.Text,
+		HTML:      content.HTML,
+		Data:      msg.Data,
+		Metadata:  msg.Metadata,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("notify: encode webhook payload: %w", err)
+	}
+
+	for _, target := range targets {
+		if err := p.post(ctx, target, msg.ID, body); err != nil {
+			return "", err
+		}
+	}
+	return msg.ID, nil
+}
+
+func (p *WebhookProvider) post(ctx context.Context, target, id string, body []byte) error {
+	req, err := p.client.NewRequest(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", id)
+	for k, v := range p.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("notify: webhook %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("notify: webhook %s: %w", target, &httpclient.StatusError{StatusCode: resp.StatusCode, Body: data})
+	}
+	return nil
+}
+
+// sign 每次发送（含重试）前以当前时间戳重新签名
+func (p *WebhookProvider) sign(req *http.Request) error {
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		body, err = io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	req.Header.Set(p.cfg.TimestampHeader, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(p.cfg.SignatureHeader, middleware.SignWebhook(middleware.WebhookConfig{}, p.cfg.Secret, now, body))
+	return nil
+}
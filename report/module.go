@@ -0,0 +1,42 @@
+package report
+
+import (
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/notify"
+	"github.com/aisgo/ais-go-pkg/storage"
+	"github.com/aisgo/ais-go-pkg/worker"
+
+	"go.uber.org/fx"
+)
+
+/* ========================================================================
+ * Report FX Module
+ * ========================================================================
+ * 职责: 提供 *Service
+ * 说明: 提供 storage.Bucket 与 *worker.Pool 时启用异步生成，提供 *notify.Notifier 时发送完成通知
+ * ======================================================================== */
+
+// ServiceParams 依赖参数
+type ServiceParams struct {
+	fx.In
+
+	Config   *Config          `optional:"true"`
+	Bucket   storage.Bucket   `optional:"true"`
+	Notifier *notify.Notifier `optional:"true"`
+	Pool     *worker.Pool     `optional:"true"`
+	Logger   *logger.Logger   `optional:"true"`
+}
+
+// NewFromParams 通过 FX 参数创建报表服务
+func NewFromParams(p ServiceParams) *Service {
+	s := NewService(p.Config, p.Bucket, p.Notifier, p.Logger)
+	if p.Pool != nil {
+		s.UseWorker(p.Pool)
+	}
+	return s
+}
+
+// Module FX 模块
+var Module = fx.Module("report",
+	fx.Provide(NewFromParams),
+)
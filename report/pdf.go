@@ -0,0 +1,131 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/httpclient"
+	"github.com/aisgo/ais-go-pkg/logger"
+)
+
+// PDFConverter HTML → PDF 转换器
+type PDFConverter interface {
+	ConvertHTML(ctx context.Context, html []byte, w io.Writer) error
+}
+
+// PDFConverterFunc 函数形式的 PDFConverter
+type PDFConverterFunc func(ctx context.Context, html []byte, w io.Writer) error
+
+// ConvertHTML 实现 PDFConverter
+func (f PDFConverterFunc) ConvertHTML(ctx context.Context, html []byte, w io.Writer) error {
+	return f(ctx, html, w)
+}
+
+// GotenbergConfig Gotenberg 配置
+type GotenbergConfig struct {
+	URL       string        `yaml:"url"`        // 如 http://gotenberg:3000
+	Timeout   time.Duration `yaml:"timeout"`    // 转换超时，默认 60s
+	PaperSize string        `yaml:"paper_size"` // A4（默认）/ Letter
+	Landscape bool          `yaml:"landscape"`  // 横向
+}
+
+// Gotenberg 基于 Gotenberg（Chromium）服务的 PDF 转换器
+// 接口: POST /forms/chromium/convert/html（multipart，文件名 index.html）
+type Gotenberg struct {
+	cfg    GotenbergConfig
+	client *httpclient.Client
+}
+
+// NewGotenberg 创建 Gotenberg 转换器
+func NewGotenberg(cfg GotenbergConfig, log *logger.Logger, opts ...httpclient.Option) *Gotenberg {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+	return &Gotenberg{
+		cfg:    cfg,
+		client: httpclient.New(httpclient.Config{BaseURL: cfg.URL, Timeout: cfg.Timeout}, log, opts...),
+	}
+}
+
+// paperSizes 纸张尺寸（英寸）
+var paperSizes = map[string][2]string{
+	"A4":     {"8.27", "11.7"},
+	"LETTER": {"8.5", "11"},
+}
+
+// ConvertHTML 实现 PDFConverter
+func (g *Gotenberg) ConvertHTML(ctx context.Context, html []byte, w io.Writer) error {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	fw, err := mw.CreateFormFile("files", "index.html")
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(html); err != nil {
+		return err
+	}
+	size, ok := paperSizes[strings.ToUpper(g.cfg.PaperSize)]
+	if !ok {
+		size = paperSizes["A4"]
+	}
+	fields := map[string]string{"paperWidth": size[0], "paperHeight": size[1], "printBackground": "true"}
+	if g.cfg.Landscape {
+		fields["landscape"] = "true"
+	}
+	for k, v := range fields {
+		if err := mw.WriteField(k, v); err != nil {
+			return err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return err
+	}
+
+	req, err := g.client.NewRequest(ctx, http.MethodPost, "/forms/chromium/convert/html", bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("report: gotenberg: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("report: gotenberg: %w", &httpclient.StatusError{StatusCode: resp.StatusCode, Body: data})
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("report: read pdf: %w", err)
+	}
+	return nil
+}
+
+// defaultTemplate 内置表格模板
+var defaultTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<style>
+body{font-family:"Noto Sans CJK SC","PingFang SC","Microsoft YaHei",sans-serif;font-size:11px;margin:24px}
+h1{font-size:18px;margin:0 0 4px}
+.meta{color:#666;margin-bottom:12px}
+table{border-collapse:collapse;width:100%}
+th,td{border:1px solid #ccc;padding:4px 6px;text-align:left}
+th{background:#f3f3f3}
+thead{display:table-header-group}
+tr{page-break-inside:avoid}
+</style></head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">{{.GeneratedAt.Format "2006-01-02 15:04:05"}}{{if .Truncated}} · 仅显示前 {{len .Rows}} 行{{end}}</div>
+<table>
+<thead><tr>{{range .Headers}}<th>{{.}}</th>{{end}}</tr></thead>
+<tbody>{{range .Rows}}<tr>{{range .}}<td>{{.}}</td>{{end}}</tr>{{end}}</tbody>
+</table>
+</body></html>`))
@@ -0,0 +1,213 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/export"
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * Report - 模板化报表生成
+ * ========================================================================
+ * 职责: 将仓储查询结果渲染为 XLSX / CSV（流式，复用 export）或 PDF（HTML 模板 → PDF）
+ * 异步: Service.Submit 经 worker.Pool 后台生成，上传到 storage.Bucket，
+ *       生成预签名下载链接并通过 notify 发送通知
+ * 租户: 同步生成使用 ctx 中的租户范围；异步任务提交时捕获 TenantContext 并在后台恢复
+ *
+ * 使用示例:
+ *   svc.Register(&report.Table[Order]{
+ *       ReportName: "orders",
+ *       Title:      "订单报表",
+ *       Repo:       orderRepo,
+ *       Columns:    cols,
+ *       Spec: func(ctx context.Context, p report.Params) (repository.Specification[Order], error) {
+ *           return repository.Eq[Order]("status", p.String("status")), nil
+ *       },
+ *   })
+ *   jobID, err := svc.Submit(ctx, report.Job{Report: "orders", Format: report.FormatXLSX,
+ *       Params: report.Params{"status": "paid"},
+ *       Notify: &notify.Message{Channel: notify.ChannelEmail, To: []string{"a@b.com"}, Template: "report_ready"}})
+ * ======================================================================== */
+
+// Format 报表格式
+type Format string
+
+const (
+	FormatXLSX Format = Format(export.FormatXLSX)
+	FormatCSV  Format = Format(export.FormatCSV)
+	FormatPDF  Format = "pdf"
+)
+
+// DefaultPDFMaxRows PDF 报表默认最大行数（PDF 需整体渲染，不宜过大）
+const DefaultPDFMaxRows = 2000
+
+// Ext 文件扩展名
+func (f Format) Ext() string {
+	return "." + string(f)
+}
+
+// ContentType 内容类型
+func (f Format) ContentType() string {
+	switch f {
+	case FormatXLSX:
+		return export.MIMEXLSX
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Params 报表参数（异步任务中以 JSON 传递）
+type Params map[string]any
+
+// String 读取字符串参数
+func (p Params) String(key string) string {
+	if v, ok := p[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// Result 生成结果
+type Result = export.Result
+
+// Report 报表
+type Report interface {
+	// Name 报表名称（异步任务按名称查找）
+	Name() string
+	// Render 按格式渲染报表到 w
+	Render(ctx context.Context, w io.Writer, format Format, params Params) (*Result, error)
+}
+
+// Table 基于仓储查询的表格报表
+type Table[T any] struct {
+	ReportName string
+	Title      string
+	Repo       repository.SpecificationRepository[T]
+	Columns    []export.Column[T]
+	// Spec 按参数构建查询规约，为空时查询全部
+	Spec func(ctx context.Context, params Params) (repository.Specification[T], error)
+	// Options XLSX/CSV 导出选项（Format 由调用方指定）
+	Options export.Options
+
+	// Template PDF HTML 模板，为空时使用内置表格模板；数据为 *PageData
+	Template *template.Template
+	// PDF HTML → PDF 转换器，为空时不支持 PDF
+	PDF PDFConverter
+	// PDFMaxRows PDF 最大行数，默认 2000
+	PDFMaxRows int
+}
+
+// PageData PDF 模板数据
+type PageData struct {
+	Title       string
+	Headers     []string
+	Rows        [][]string
+	Params      Params
+	Truncated   bool
+	GeneratedAt time.Time
+}
+
+// Name 实现 Report
+func (t *Table[T]) Name() string {
+	return t.ReportName
+}
+
+// Render 实现 Report
+func (t *Table[T]) Render(ctx context.Context, w io.Writer, format Format, params Params) (*Result, error) {
+	var spec repository.Specification[T]
+	if t.Spec != nil {
+		var err error
+		if spec, err = t.Spec(ctx, params); err != nil {
+			return nil, err
+		}
+	}
+
+	switch format {
+	case FormatXLSX, FormatCSV:
+		opts := t.Options
+		opts.Format = export.Format(format)
+		if opts.SheetName == "" && t.Title != "" {
+			opts.SheetName = t.Title
+		}
+		return export.Export(ctx, w, t.Repo, spec, t.Columns, opts)
+	case FormatPDF:
+		return t.renderPDF(ctx, w, spec, params)
+	default:
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "unsupported report format: "+string(format))
+	}
+}
+
+// renderPDF 查询数据渲染 HTML 后转换为 PDF
+func (t *Table[T]) renderPDF(ctx context.Context, w io.Writer, spec repository.Specification[T], params Params) (*Result, error) {
+	if t.PDF == nil {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "report "+t.ReportName+" does not support pdf")
+	}
+	maxRows := t.PDFMaxRows
+	if maxRows <= 0 {
+		maxRows = DefaultPDFMaxRows
+	}
+	orderBy := t.Options.OrderBy
+	if orderBy == "" {
+		orderBy = "id ASC"
+	}
+	timeLayout := t.Options.TimeLayout
+	if timeLayout == "" {
+		timeLayout = export.DefaultTimeLayout
+	}
+
+	// 多取一行用于判断是否截断
+	models, err := t.Repo.FindBySpec(ctx, limitSpec[T](spec, maxRows+1), repository.WithOrderBy(orderBy))
+	if err != nil {
+		return nil, err
+	}
+
+	data := &PageData{Title: t.Title, Params: params, GeneratedAt: time.Now()}
+	for _, col := range t.Columns {
+		data.Headers = append(data.Headers, col.Header)
+	}
+	if len(models) > maxRows {
+		models, data.Truncated = models[:maxRows], true
+	}
+	for _, model := range models {
+		row := make([]string, len(t.Columns))
+		for i, col := range t.Columns {
+			row[i] = export.FormatValue(col.Value(model), timeLayout)
+		}
+		data.Rows = append(data.Rows, row)
+	}
+
+	tpl := t.Template
+	if tpl == nil {
+		tpl = defaultTemplate
+	}
+	var html bytes.Buffer
+	if err := tpl.Execute(&html, data); err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInternal, "render report template", err)
+	}
+	if err := t.PDF.ConvertHTML(ctx, html.Bytes(), w); err != nil {
+		return nil, err
+	}
+	return &Result{Rows: int64(len(data.Rows)), Truncated: data.Truncated}, nil
+}
+
+// limitSpec 在原规约上追加行数限制
+func limitSpec[T any](spec repository.Specification[T], limit int) repository.Specification[T] {
+	return repository.SpecFunc[T](func(db *gorm.DB) *gorm.DB {
+		if spec != nil {
+			db = spec.Apply(db)
+		}
+		return db.Limit(limit)
+	})
+}
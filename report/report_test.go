@@ -0,0 +1,243 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/export"
+	"github.com/aisgo/ais-go-pkg/httpclient"
+	"github.com/aisgo/ais-go-pkg/notify"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/storage"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+	"github.com/aisgo/ais-go-pkg/worker"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type reportTestOrder struct {
+	ID     string  `gorm:"column:id;primaryKey"`
+	Status string  `gorm:"column:status"`
+	Amount float64 `gorm:"column:amount"`
+}
+
+func (reportTestOrder) TenantIgnored() bool {
+	return true
+}
+
+func newOrdersTable(t *testing.T, pdf PDFConverter) *Table[reportTestOrder] {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&reportTestOrder{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := repository.NewRepository[reportTestOrder](db)
+	for i := range 6 {
+		status := "paid"
+		if i%2 == 1 {
+			status = "open"
+		}
+		if err := repo.Create(context.Background(), &reportTestOrder{ID: fmt.Sprintf("%02d", i), Status: status, Amount: float64(i)}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	return &Table[reportTestOrder]{
+		ReportName: "orders",
+		Title:      "订单<报表>",
+		Repo:       repo,
+		Columns: []export.Column[reportTestOrder]{
+			export.Col("订单号", func(o *reportTestOrder) any { return o.ID }),
+			export.Col("金额", func(o *reportTestOrder) any { return o.Amount }),
+		},
+		Spec: func(_ context.Context, p Params) (repository.Specification[reportTestOrder], error) {
+			if s := p.String("status"); s != "" {
+				return repository.Where[reportTestOrder]("status = ?", s), nil
+			}
+			return nil, nil
+		},
+		PDF:        pdf,
+		PDFMaxRows: 2,
+	}
+}
+
+func TestGenerateCSVAndPDF(t *testing.T) {
+	var html string
+	pdf := PDFConverterFunc(func(_ context.Context, data []byte, w io.Writer) error {
+		html = string(data)
+		_, err := io.WriteString(w, "%PDF-1.7")
+		return err
+	})
+	svc := NewService(nil, nil, nil, nil)
+	svc.Register(newOrdersTable(t, pdf))
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	res, err := svc.Generate(ctx, "orders", FormatCSV, Params{"status": "paid"}, &buf)
+	if err != nil || res.Rows != 3 {
+		t.Fatalf("generate csv: %+v %v", res, err)
+	}
+	records, _ := csv.NewReader(&buf).ReadAll()
+	if len(records) != 4 || records[1][0] != "00" || records[3][0] != "04" {
+		t.Fatalf("unexpected csv: %v", records)
+	}
+
+	buf.Reset()
+	res, err = svc.Generate(ctx, "orders", FormatPDF, nil, &buf)
+	if err != nil || res.Rows != 2 || !res.Truncated || buf.String() != "%PDF-1.7" {
+		t.Fatalf("generate pdf: %+v %v %q", res, err, buf.String())
+	}
+	if !strings.Contains(html, "<h1>订单&lt;报表&gt;</h1>") || !strings.Contains(html, "<td>01</td><td>1</td>") ||
+		strings.Contains(html, "<td>02</td>") || !strings.Contains(html, "仅显示前 2 行") {
+		t.Fatalf("unexpected html:\n%s", html)
+	}
+
+	if _, err := svc.Generate(ctx, "missing", FormatCSV, nil, &buf); err == nil {
+		t.Fatalf("expected unknown report error")
+	}
+	if _, err := svc.Generate(ctx, "orders", "doc", nil, &buf); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+}
+
+// memBucket 内存存储桶（仅实现报表用到的方法）
+type memBucket struct {
+	storage.Bucket
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	opts    map[string]*storage.PutOptions
+}
+
+func (b *memBucket) Put(_ context.Context, key string, r io.Reader, _ int64, opts *storage.PutOptions) (*storage.ObjectInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.objects[key], b.opts[key] = data, opts
+	return &storage.ObjectInfo{Key: key, Size: int64(len(data))}, nil
+}
+
+func (b *memBucket) PresignGet(_ context.Context, key string, expires time.Duration) (string, error) {
+	return "https://files.example.com/" + key + "?expires=" + expires.String(), nil
+}
+
+type captureProvider struct {
+	msgs chan *notify.Message
+}
+
+func (p *captureProvider) Channel() notify.Channel { return notify.ChannelEmail }
+func (p *captureProvider) Name() string            { return "capture" }
+func (p *captureProvider) Send(_ context.Context, msg *notify.Message, _ notify.Content) (string, error) {
+	p.msgs <- msg
+	return msg.ID, nil
+}
+
+func TestSubmitUploadsAndNotifies(t *testing.T) {
+	bucket := &memBucket{objects: map[string][]byte{}, opts: map[string]*storage.PutOptions{}}
+	provider := &captureProvider{msgs: make(chan *notify.Message, 1)}
+	notifier := notify.New(nil, nil)
+	notifier.Register(provider)
+
+	pool := worker.New(&worker.Config{Workers: 1, MaxRetries: 0}, nil, nil)
+	svc := NewFromParams(ServiceParams{Config: &Config{URLExpiry: time.Hour}, Bucket: bucket, Notifier: notifier, Pool: pool})
+	svc.Register(newOrdersTable(t, nil))
+	pool.Start()
+	defer pool.Stop(context.Background())
+
+	done := make(chan *JobResult, 2)
+	svc.OnComplete(func(_ context.Context, res *JobResult) { done <- res })
+
+	tenantID := ulid.Generate()
+	ctx := repository.WithTenantContext(context.Background(), repository.TenantContext{TenantID: tenantID, IsAdmin: true})
+	jobID, err := svc.Submit(ctx, Job{
+		Report:   "orders",
+		Format:   FormatXLSX,
+		FileName: "订单.xlsx",
+		Notify:   &notify.Message{Channel: notify.ChannelEmail, To: []string{"ops@example.com"}, Subject: "报表已生成"},
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	var res *JobResult
+	select {
+	case res = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for job")
+	}
+	wantKey := "reports/" + tenantID.String() + "/" + jobID + ".xlsx"
+	if res.Err != nil || res.NotifyErr != nil || res.Key != wantKey || res.Rows != 6 || res.Size == 0 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if data := bucket.objects[wantKey]; !bytes.HasPrefix(data, []byte("PK")) || int64(len(data)) != res.Size {
+		t.Fatalf("expected uploaded xlsx of %d bytes, got %d", res.Size, len(data))
+	}
+	if opts := bucket.opts[wantKey]; opts.ContentType != export.MIMEXLSX || !strings.Contains(opts.ContentDisposition, "utf-8''%E8%AE%A2%E5%8D%95.xlsx") {
+		t.Fatalf("unexpected put options: %+v", opts)
+	}
+
+	msg := <-provider.msgs
+	if msg.Data["url"] != res.URL || msg.Data["job_id"] != jobID || msg.TenantID != tenantID.String() || !strings.Contains(res.URL, "expires=1h0m0s") {
+		t.Fatalf("unexpected notification: %+v (url %s)", msg, res.URL)
+	}
+
+	if _, err := svc.Submit(ctx, Job{Report: "missing", Format: FormatCSV}); err == nil {
+		t.Fatalf("expected unknown report error")
+	}
+	local := NewService(nil, nil, nil, nil)
+	local.Register(newOrdersTable(t, nil))
+	if _, err := local.Submit(ctx, Job{Report: "orders", Format: FormatCSV}); err == nil {
+		t.Fatalf("expected async not configured error")
+	}
+	if _, err := local.Run(ctx, Job{Report: "orders", Format: FormatPDF}); err == nil {
+		t.Fatalf("expected run without storage to fail")
+	}
+}
+
+func TestGotenbergConvert(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/forms/chromium/convert/html" {
+			http.NotFound(w, r)
+			return
+		}
+		f, hdr, err := r.FormFile("files")
+		if err != nil || hdr.Filename != "index.html" || r.FormValue("landscape") != "true" || r.FormValue("paperWidth") != "8.27" {
+			http.Error(w, "bad form", http.StatusBadRequest)
+			return
+		}
+		html, _ := io.ReadAll(f)
+		fmt.Fprintf(w, "%%PDF:%s", html)
+	}))
+	defer srv.Close()
+
+	g := NewGotenberg(GotenbergConfig{URL: srv.URL, Landscape: true}, nil)
+	var out bytes.Buffer
+	if err := g.ConvertHTML(context.Background(), []byte("<p>x</p>"), &out); err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if out.String() != "%PDF:<p>x</p>" {
+		t.Fatalf("unexpected output %q", out.String())
+	}
+
+	g = NewGotenberg(GotenbergConfig{URL: srv.URL}, nil)
+	var se *httpclient.StatusError
+	if err := g.ConvertHTML(context.Background(), nil, &out); !errors.As(err, &se) || se.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected status error, got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package report
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/notify"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/storage"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+	"github.com/aisgo/ais-go-pkg/worker"
+
+	"go.uber.org/zap"
+)
+
+// TaskType worker 异步生成任务类型
+const TaskType = "report.generate"
+
+// Config 报表服务配置
+type Config struct {
+	KeyPrefix string           `yaml:"key_prefix"` // 存储对象键前缀，默认 reports/
+	URLExpiry time.Duration    `yaml:"url_expiry"` // 下载链接有效期，默认 24h
+	TempDir   string           `yaml:"temp_dir"`   // 生成临时文件目录，默认系统临时目录
+	Gotenberg *GotenbergConfig `yaml:"gotenberg"`  // PDF 转换服务，未配置时 Service.PDF 返回 nil
+}
+
+func (c *Config) withDefaults() Config {
+	var cfg Config
+	if c != nil {
+		cfg = *c
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "reports/"
+	}
+	if cfg.URLExpiry <= 0 {
+		cfg.URLExpiry = 24 * time.Hour
+	}
+	return cfg
+}
+
+// Job 异步报表任务
+type Job struct {
+	ID       string                    `json:"id"`
+	Report   string                    `json:"report"`
+	Format   Format                    `json:"format"`
+	Params   Params                    `json:"params,omitempty"`
+	FileName string                    `json:"file_name,omitempty"` // 下载文件名，默认 报表名_时间.扩展名
+	Tenant   *repository.TenantContext `json:"tenant,omitempty"`    // 提交时自动从 ctx 捕获
+	// Notify 完成通知模板（渠道/收件人/模板），Data 中追加 report/format/url/rows/truncated/expires_at/job_id
+	Notify *notify.Message `json:"notify,omitempty"`
+}
+
+// JobResult 任务结果
+type JobResult struct {
+	JobID     string
+	Report    string
+	Format    Format
+	Key       string // 存储对象键
+	URL       string // 预签名下载链接
+	ExpiresAt time.Time
+	Rows      int64
+	Truncated bool
+	Size      int64
+	Err       error // 生成或上传失败
+	NotifyErr error // 通知失败（不影响任务结果）
+}
+
+// Service 报表服务
+type Service struct {
+	cfg      Config
+	bucket   storage.Bucket
+	notifier *notify.Notifier
+	pdf      PDFConverter
+	log      *logger.Logger
+
+	mu        sync.RWMutex
+	reports   map[string]Report
+	pool      *worker.Pool
+	callbacks []func(ctx context.Context, res *JobResult)
+}
+
+// NewService 创建报表服务；bucket 为 nil 时仅支持同步生成
+func NewService(cfg *Config, bucket storage.Bucket, notifier *notify.Notifier, log *logger.Logger) *Service {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	s := &Service{
+		cfg:      cfg.withDefaults(),
+		bucket:   bucket,
+		notifier: notifier,
+		log:      log,
+		reports:  make(map[string]Report),
+	}
+	if s.cfg.Gotenberg != nil {
+		s.pdf = NewGotenberg(*s.cfg.Gotenberg, log)
+	}
+	return s
+}
+
+// PDF 返回配置的 PDF 转换器（供 Table.PDF 使用），未配置时为 nil
+func (s *Service) PDF() PDFConverter {
+	return s.pdf
+}
+
+// Register 注册报表（同名覆盖）
+func (s *Service) Register(r Report) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reports[r.Name()] = r
+}
+
+// OnComplete 注册异步任务完成回调（成功与失败均触发）
+func (s *Service) OnComplete(fn func(ctx context.Context, res *JobResult)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callbacks = append(s.callbacks, fn)
+}
+
+func (s *Service) report(name string) (Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.reports[name]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "report not found: "+name)
+	}
+	return r, nil
+}
+
+// Generate 同步生成报表到 w（使用 ctx 中的租户范围）
+func (s *Service) Generate(ctx context.Context, name string, format Format, params Params, w io.Writer) (*Result, error) {
+	r, err := s.report(name)
+	if err != nil {
+		return nil, err
+	}
+	return r.Render(ctx, w, format, params)
+}
+
+// UseWorker 通过任务池异步生成；生成或上传失败时由任务池按配置重试
+func (s *Service) UseWorker(pool *worker.Pool) {
+	pool.RegisterFunc(TaskType, func(ctx context.Context, task *worker.Task) error {
+		var job Job
+		if err := task.Decode(&job); err != nil {
+			return fmt.Errorf("report: decode job: %w", err)
+		}
+		_, err := s.Run(ctx, job)
+		return err
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pool = pool
+}
+
+// Submit 提交异步报表任务，返回任务 ID
+func (s *Service) Submit(ctx context.Context, job Job) (string, error) {
+	s.mu.RLock()
+	pool := s.pool
+	s.mu.RUnlock()
+	if pool == nil || s.bucket == nil {
+		return "", errors.New(errors.ErrCodeUnavailable, "report async generation is not configured")
+	}
+	if _, err := s.report(job.Report); err != nil {
+		return "", err
+	}
+	if job.ID == "" {
+		job.ID = ulid.GenerateString()
+	}
+	if job.Tenant == nil {
+		if tc, ok := repository.TenantFromContext(ctx); ok {
+			job.Tenant = &tc
+		}
+	}
+
+	task, err := worker.NewTask(TaskType, job)
+	if err != nil {
+		return "", err
+	}
+	if err := pool.Submit(ctx, task); err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// Run 执行报表任务：生成 → 上传 → 预签名链接 → 通知 → 回调
+func (s *Service) Run(ctx context.Context, job Job) (*JobResult, error) {
+	if job.ID == "" {
+		job.ID = ulid.GenerateString()
+	}
+	if job.Tenant != nil {
+		ctx = repository.WithTenantContext(ctx, *job.Tenant)
+	}
+
+	res := &JobResult{JobID: job.ID, Report: job.Report, Format: job.Format}
+	res.Err = s.run(ctx, job, res)
+	if res.Err == nil && job.Notify != nil && s.notifier != nil {
+		res.NotifyErr = s.notify(ctx, job, res)
+	}
+	if res.Err != nil {
+		s.log.WithContext(ctx).Error("report job failed",
+			zap.String("job_id", job.ID),
+			zap.String("report", job.Report),
+			zap.Error(res.Err),
+		)
+	}
+
+	s.mu.RLock()
+	callbacks := s.callbacks
+	s.mu.RUnlock()
+	for _, fn := range callbacks {
+		fn(ctx, res)
+	}
+	return res, res.Err
+}
+
+// run 生成到临时文件并上传
+func (s *Service) run(ctx context.Context, job Job, res *JobResult) error {
+	if s.bucket == nil {
+		return errors.New(errors.ErrCodeUnavailable, "report storage is not configured")
+	}
+	r, err := s.report(job.Report)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(s.cfg.TempDir, "report-*"+job.Format.Ext())
+	if err != nil {
+		return fmt.Errorf("report: create temp file: %w", err)
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	out, err := r.Render(ctx, f, job.Format, job.Params)
+	if err != nil {
+		return err
+	}
+	res.Rows, res.Truncated = out.Rows, out.Truncated
+	if res.Size, err = f.Seek(0, io.SeekCurrent); err != nil {
+		return err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	fileName := job.FileName
+	if fileName == "" {
+		fileName = job.Report + "_" + time.Now().Format("20060102150405") + job.Format.Ext()
+	}
+	res.Key = s.cfg.KeyPrefix
+	if job.Tenant != nil {
+		res.Key += job.Tenant.TenantID.String() + "/"
+	}
+	res.Key += job.ID + job.Format.Ext()
+
+	if _, err := storage.Upload(ctx, s.bucket, res.Key, f, storage.UploadOptions{PutOptions: storage.PutOptions{
+		ContentType:        job.Format.ContentType(),
+		ContentDisposition: mime.FormatMediaType("attachment", map[string]string{"filename": fileName}),
+	}}); err != nil {
+		return err
+	}
+
+	if res.URL, err = s.bucket.PresignGet(ctx, res.Key, s.cfg.URLExpiry); err != nil {
+		return err
+	}
+	res.ExpiresAt = time.Now().Add(s.cfg.URLExpiry)
+	return nil
+}
+
+// notify 发送完成通知
+func (s *Service) notify(ctx context.Context, job Job, res *JobResult) error {
+	msg := *job.Notify
+	msg.ID = ""
+	msg.Data = maps.Clone(msg.Data)
+	if msg.Data == nil {
+		msg.Data = make(map[string]any)
+	}
+	msg.Data["job_id"] = res.JobID
+	msg.Data["report"] = res.Report
+	msg.Data["format"] = string(res.Format)
+	msg.Data["url"] = res.URL
+	msg.Data["rows"] = res.Rows
+	msg.Data["truncated"] = res.Truncated
+	msg.Data["expires_at"] = res.ExpiresAt.Format(time.DateTime)
+	if msg.TenantID == "" && job.Tenant != nil {
+		msg.TenantID = job.Tenant.TenantID.String()
+	}
+
+	_, err := s.notifier.Send(ctx, &msg)
+	return err
+}
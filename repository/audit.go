@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm/schema"
+)
+
+/* ========================================================================
+ * Audit - 审计字段
+ * ========================================================================
+ * 职责: 经仓储写入时自动维护 created_by / updated_by / updated_at，避免业务代码手动赋值
+ * 语义:
+ *   - created_by: 创建时为零值则填充 TenantContext.UserID（所有模型，见 owner.go），之后不可修改
+ *   - updated_by: 模型实现 Auditable 时，Create / Update / UpdateByID / UpsertBatch 写入当前用户
+ *     （ctx 中无用户时保持不变）
+ *   - updated_at: 字段名为 UpdatedAt 或带 autoUpdateTime 时由 GORM 维护；
+ *     Auditable 模型的 updated_at 列（time.Time）未声明 autoUpdateTime 时由仓储写入当前时间
+ * 列类型: ULID、*ULID 或 string（char(26)）
+ *
+ * 使用示例:
+ *   type Order struct {
+ *       repository.BaseModel
+ *       repository.AuditFields
+ *       Amount int64 `gorm:"column:amount"`
+ *   }
+ *
+ *   // 或自行声明列并实现接口
+ *   func (Order) Audited() bool { return true }
+ * ======================================================================== */
+
+var timeType = reflect.TypeOf(time.Time{})
+
+const (
+	updatedByColumn = "updated_by"
+	updatedAtColumn = "updated_at"
+)
+
+// Auditable 启用 updated_by / updated_at 自动维护的模型
+type Auditable interface {
+	Audited() bool
+}
+
+// AuditFields 可嵌入的审计字段（实现 Auditable）
+type AuditFields struct {
+	CreatedBy string `json:"created_by,omitempty" gorm:"column:created_by;size:26;comment:创建人"`
+	UpdatedBy string `json:"updated_by,omitempty" gorm:"column:updated_by;size:26;comment:更新人"`
+}
+
+// Audited 实现 Auditable
+func (AuditFields) Audited() bool {
+	return true
+}
+
+// isAudited 模型是否启用审计字段
+func (r *RepositoryImpl[T]) isAudited() bool {
+	audited, ok := any(r.newModelPtr()).(Auditable)
+	return ok && audited.Audited()
+}
+
+// auditColumns 需要由仓储写入的审计字段
+func (r *RepositoryImpl[T]) auditColumns(s *schema.Schema) []*schema.Field {
+	if !r.isAudited() {
+		return nil
+	}
+	var fields []*schema.Field
+	if f, ok := s.FieldsByDBName[updatedByColumn]; ok {
+		fields = append(fields, f)
+	}
+	if f, ok := s.FieldsByDBName[updatedAtColumn]; ok && f.AutoUpdateTime == 0 && f.IndirectFieldType == timeType {
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// auditValue 返回审计字段的写入值，ok=false 表示不写入
+func (r *RepositoryImpl[T]) auditValue(ctx context.Context, field *schema.Field) (any, bool) {
+	if field.DBName == updatedAtColumn {
+		return r.db.NowFunc(), true
+	}
+	tc, _ := TenantFromContext(ctx)
+	if tc.UserID.IsZero() {
+		return nil, false
+	}
+	return ownerValue(field, tc), true
+}
+
+// setUserFields 创建时填充归属与审计字段
+func (r *RepositoryImpl[T]) setUserFields(ctx context.Context, model any) error {
+	if err := r.setOwnerFields(ctx, model); err != nil {
+		return err
+	}
+	return r.setAuditFields(ctx, model)
+}
+
+// setAuditFields 在模型上写入 updated_by / updated_at
+func (r *RepositoryImpl[T]) setAuditFields(ctx context.Context, model any) error {
+	s, err := r.getSchema()
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(model)
+	for _, field := range r.auditColumns(s) {
+		value, ok := r.auditValue(ctx, field)
+		if !ok {
+			continue
+		}
+		if field.FieldType.Kind() == reflect.Ptr {
+			ptr := reflect.New(field.IndirectFieldType)
+			ptr.Elem().Set(reflect.ValueOf(value).Convert(field.IndirectFieldType))
+			value = ptr.Interface()
+		}
+		if err := field.Set(ctx, rv, value); err != nil {
+			return errors.Wrap(errors.ErrCodeInternal, "failed to set "+field.DBName, err)
+		}
+	}
+	return nil
+}
+
+// auditUpdates 为 UpdateByID 的更新字段补充 updated_by / updated_at
+func (r *RepositoryImpl[T]) auditUpdates(ctx context.Context, updates map[string]any) error {
+	s, err := r.getSchema()
+	if err != nil {
+		return err
+	}
+	for _, field := range r.auditColumns(s) {
+		if value, ok := r.auditValue(ctx, field); ok {
+			updates[field.DBName] = value
+		}
+	}
+	return nil
+}
+
+// appendAuditFields 将审计字段加入 Upsert 更新列
+func (r *RepositoryImpl[T]) appendAuditFields(s *schema.Schema, fields []*schema.Field) []*schema.Field {
+	for _, f := range r.auditColumns(s) {
+		if !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
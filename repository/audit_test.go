@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// auditModel 嵌入审计字段，updated_at 未声明 autoUpdateTime
+type auditModel struct {
+	ID         string     `gorm:"column:id;primaryKey"`
+	Name       string     `gorm:"column:name"`
+	ModifiedAt *time.Time `gorm:"column:updated_at"`
+	AuditFields
+}
+
+func (auditModel) TenantIgnored() bool { return true }
+
+func TestAuditFields(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo Repository[auditModel], _ *gorm.DB) {
+		alice, bob, carol := ulidv2.Make(), ulidv2.Make(), ulidv2.Make()
+		as := func(user ulidv2.ULID) context.Context {
+			return WithTenantContext(context.Background(), TenantContext{UserID: user})
+		}
+		load := func() *auditModel {
+			t.Helper()
+			m, err := repo.FindByID(context.Background(), "a")
+			if err != nil {
+				t.Fatalf("find: %v", err)
+			}
+			return m
+		}
+
+		if err := repo.Create(as(alice), &auditModel{ID: "a", Name: "v1"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		m := load()
+		if m.CreatedBy != alice.String() || m.UpdatedBy != alice.String() || m.ModifiedAt == nil {
+			t.Fatalf("unexpected audit fields after create: %+v", m)
+		}
+
+		if err := repo.Update(as(bob), &auditModel{ID: "a", Name: "v2"}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		if m = load(); m.CreatedBy != alice.String() || m.UpdatedBy != bob.String() {
+			t.Fatalf("unexpected audit fields after update: %+v", m)
+		}
+
+		if err := repo.UpdateByID(as(carol), "a", map[string]any{"name": "v3"}); err != nil {
+			t.Fatalf("update by id: %v", err)
+		}
+		if m = load(); m.UpdatedBy != carol.String() {
+			t.Fatalf("unexpected audit fields after update by id: %+v", m)
+		}
+
+		err := repo.UpsertBatch(as(bob), []*auditModel{{ID: "a", Name: "v4"}}, WithConflictColumns("id"), WithUpdateColumns("name"))
+		if err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+		if m = load(); m.Name != "v4" || m.CreatedBy != alice.String() || m.UpdatedBy != bob.String() {
+			t.Fatalf("unexpected audit fields after upsert: %+v", m)
+		}
+
+		// 无用户时保持不变
+		if err := repo.UpdateByID(context.Background(), "a", map[string]any{"name": "v5"}); err != nil {
+			t.Fatalf("update without user: %v", err)
+		}
+		if m = load(); m.UpdatedBy != bob.String() {
+			t.Fatalf("updated_by should be kept without user: %+v", m)
+		}
+	})
+}
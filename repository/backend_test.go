@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// openTestDB 打开临时 sqlite 数据库并迁移 models
+func openTestDB(t *testing.T, models ...any) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "repository.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(models...); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// newBackendRepo 按 db 创建仓储：db 为 nil 时使用内存实现
+func newBackendRepo[T any](db *gorm.DB) Repository[T] {
+	if db == nil {
+		return NewMemoryRepository[T]()
+	}
+	return NewRepository[T](db)
+}
+
+// forEachBackend 分别在 gorm (sqlite) 与内存仓储上运行 fn
+// gorm 子测试的 db 已迁移 T 及 models，便于同库创建其他模型的仓储（见 newBackendRepo）；
+// memory 子测试的 db 为 nil
+func forEachBackend[T any](t *testing.T, fn func(t *testing.T, repo Repository[T], db *gorm.DB), models ...any) {
+	t.Run("gorm", func(t *testing.T) {
+		db := openTestDB(t, append([]any{new(T)}, models...)...)
+		fn(t, newBackendRepo[T](db), db)
+	})
+	t.Run("memory", func(t *testing.T) {
+		fn(t, newBackendRepo[T](nil), nil)
+	})
+}
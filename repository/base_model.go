@@ -34,3 +34,43 @@ func (m *BaseModel) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+/* ========================================================================
+ * Model / TenantModel - 字符串主键基础模型
+ * ========================================================================
+ * 职责: 新服务推荐的基础模型，主键为 26 位 ULID 字符串，可直接用于 FindByID
+ * 字段: id / created_at / updated_at / deleted_at（GORM 软删除，带索引）
+ * 说明: BaseModel 为兼容其他微服务 BaseEntity（create_time / deleted 标记）保留
+ *
+ * 使用示例:
+ *   type Order struct {
+ *       repository.TenantModel
+ *       Amount int64 `gorm:"column:amount"`
+ *   }
+ *
+ *   err := repo.Create(ctx, order)         // 自动生成 ID，填充 tenant_id / dept_id
+ *   found, err := repo.FindByID(ctx, order.ID)
+ * ======================================================================== */
+
+// Model 字符串 ULID 主键的基础模型
+type Model struct {
+	ID        string         `json:"id" gorm:"column:id;type:char(26);primaryKey;comment:主键ID(ULID)"`
+	CreatedAt time.Time      `json:"created_at" gorm:"column:created_at;comment:创建时间"`
+	UpdatedAt time.Time      `json:"updated_at" gorm:"column:updated_at;comment:更新时间"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"column:deleted_at;index;comment:删除时间"`
+}
+
+// BeforeCreate GORM 钩子：在创建记录前自动生成 ULID 字符串
+func (m *Model) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = ulid.GenerateString()
+	}
+	return nil
+}
+
+// TenantModel 带租户与部门列的基础模型（列名与租户隔离约定一致）
+type TenantModel struct {
+	Model
+	TenantID ulidv2.ULID  `json:"tenant_id" gorm:"column:tenant_id;type:char(26);not null;index;comment:租户ID"`
+	DeptID   *ulidv2.ULID `json:"dept_id,omitempty" gorm:"column:dept_id;type:char(26);index;comment:部门ID"`
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// tenantOrder 使用 TenantModel 的模型
+type tenantOrder struct {
+	TenantModel
+	Amount int64 `gorm:"column:amount"`
+}
+
+func TestTenantModel(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo Repository[tenantOrder], db *gorm.DB) {
+		tenant, dept := ulidv2.Make(), ulidv2.Make()
+		ctx := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, DeptID: &dept})
+
+		order := &tenantOrder{Amount: 100}
+		if err := repo.Create(ctx, order); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if _, err := ulidv2.ParseStrict(order.ID); err != nil {
+			t.Fatalf("expected ulid id, got %q", order.ID)
+		}
+		if order.TenantID != tenant || order.DeptID == nil || *order.DeptID != dept || order.CreatedAt.IsZero() {
+			t.Fatalf("unexpected fields %+v", order)
+		}
+
+		found, err := repo.FindByID(ctx, order.ID)
+		if err != nil || found.Amount != 100 {
+			t.Fatalf("find: %+v %v", found, err)
+		}
+		if err := repo.Delete(ctx, order.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		_, err = repo.FindByID(ctx, order.ID)
+		expectCode(t, err, errors.ErrCodeNotFound)
+
+		if db != nil {
+			var n int64
+			db.Unscoped().Model(&tenantOrder{}).Where("id = ? AND deleted_at IS NOT NULL", order.ID).Count(&n)
+			if n != 1 {
+				t.Fatalf("expected soft-deleted row, got %d", n)
+			}
+			if !db.Migrator().HasIndex(&tenantOrder{}, "DeletedAt") {
+				t.Fatalf("expected deleted_at index")
+			}
+		}
+	})
+}
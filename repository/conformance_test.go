@@ -0,0 +1,769 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+	"gorm.io/plugin/soft_delete"
+)
+
+// conformanceModel 两种仓储实现共同使用的测试模型
+type conformanceModel struct {
+	ID         string                `gorm:"column:id;type:char(26);primaryKey"`
+	TenantID   ulidv2.ULID           `gorm:"column:tenant_id;type:char(26);not null"`
+	DeptID     *ulidv2.ULID          `gorm:"column:dept_id;type:char(26)"`
+	Code       string                `gorm:"column:code;uniqueIndex"`
+	Name       string                `gorm:"column:name"`
+	Status     string                `gorm:"column:status"`
+	Amount     float64               `gorm:"column:amount"`
+	Score      *int                  `gorm:"column:score"`
+	PaidAt     time.Time             `gorm:"column:paid_at"`
+	UpdateTime time.Time             `gorm:"column:update_time;autoUpdateTime"`
+	Deleted    soft_delete.DeletedAt `gorm:"column:deleted;default:0;softDelete:flag"`
+}
+
+// conformanceFixture 预置数据
+type conformanceFixture struct {
+	repo            Repository[conformanceModel]
+	admin, d1, d2   context.Context // 租户 A 的管理员与两个部门
+	other           context.Context // 租户 B 的管理员
+	a1, a2, a3, a4  *conformanceModel
+	b1              *conformanceModel
+	tenantA, deptD1 ulidv2.ULID
+}
+
+func intPtr(v int) *int { return &v }
+
+func conformanceDay(d, h int) time.Time { return time.Date(2026, 3, d, h, 30, 0, 0, time.UTC) }
+
+// newConformanceFixture 租户 A: a1/a3 属于 d1，a2/a4 属于 d2；租户 B: b1
+func newConformanceFixture(t *testing.T, repo Repository[conformanceModel]) *conformanceFixture {
+	t.Helper()
+	tenantA, tenantB := ulidv2.Make(), ulidv2.Make()
+	d1, d2 := ulidv2.Make(), ulidv2.Make()
+	f := &conformanceFixture{
+		repo:    repo,
+		admin:   WithTenantContext(context.Background(), TenantContext{TenantID: tenantA, IsAdmin: true}),
+		d1:      WithTenantContext(context.Background(), TenantContext{TenantID: tenantA, DeptID: &d1}),
+		d2:      WithTenantContext(context.Background(), TenantContext{TenantID: tenantA, DeptID: &d2}),
+		other:   WithTenantContext(context.Background(), TenantContext{TenantID: tenantB, IsAdmin: true}),
+		tenantA: tenantA,
+		deptD1:  d1,
+	}
+
+	f.a1 = &conformanceModel{ID: ulidv2.Make().String(), Code: "a1", Name: "Alice", Status: "paid", Amount: 10, Score: intPtr(5), PaidAt: conformanceDay(2, 9)}
+	f.a2 = &conformanceModel{ID: ulidv2.Make().String(), Code: "a2", Name: "bob", Status: "paid", Amount: 20, PaidAt: conformanceDay(2, 18)}
+	f.a3 = &conformanceModel{ID: ulidv2.Make().String(), Code: "a3", Name: "Carol", Status: "void", Amount: 35, Score: intPtr(7), PaidAt: conformanceDay(4, 8)}
+	f.a4 = &conformanceModel{ID: ulidv2.Make().String(), Code: "a4", Name: "dave", Status: "pending", Amount: 40, Score: intPtr(9), PaidAt: conformanceDay(9, 1)}
+	f.b1 = &conformanceModel{ID: ulidv2.Make().String(), Code: "b1", Name: "Alice", Status: "paid", Amount: 100, PaidAt: conformanceDay(2, 9)}
+
+	for _, seed := range []struct {
+		ctx   context.Context
+		model *conformanceModel
+	}{{f.d1, f.a1}, {f.d2, f.a2}, {f.d1, f.a3}, {f.d2, f.a4}, {f.other, f.b1}} {
+		if err := repo.Create(seed.ctx, seed.model); err != nil {
+			t.Fatalf("seed %s: %v", seed.model.Code, err)
+		}
+	}
+	return f
+}
+
+// codes 提取记录的 code（保持顺序）
+func codes(models []*conformanceModel) []string {
+	out := make([]string, len(models))
+	for i, m := range models {
+		out[i] = m.Code
+	}
+	return out
+}
+
+func sortedCodes(models []*conformanceModel) []string {
+	out := codes(models)
+	slices.Sort(out)
+	return out
+}
+
+func expectCode(t *testing.T, err error, code errors.ErrorCode) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected error %v, got nil", code)
+	}
+	if errors.Code(err) != code {
+		t.Fatalf("expected error %v, got %v", code, err)
+	}
+}
+
+// runRepositoryConformance 仓储实现必须满足的行为
+func runRepositoryConformance(t *testing.T, newRepo func(t *testing.T) Repository[conformanceModel]) {
+	t.Run("CRUD", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		repo, ctx := f.repo, f.admin
+
+		got, err := repo.FindByID(ctx, f.a1.ID)
+		if err != nil {
+			t.Fatalf("find by id: %v", err)
+		}
+		if got.Name != "Alice" || got.TenantID != f.tenantA || got.DeptID == nil || *got.DeptID != f.deptD1 {
+			t.Fatalf("unexpected record: %+v", got)
+		}
+		if got.UpdateTime.IsZero() || f.a1.UpdateTime.IsZero() {
+			t.Fatalf("expected update_time to be set")
+		}
+
+		// Update 忽略零值字段
+		if err := repo.Update(ctx, &conformanceModel{ID: f.a1.ID, Name: "Alicia"}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		got, _ = repo.FindByID(ctx, f.a1.ID)
+		if got.Name != "Alicia" || got.Status != "paid" || got.Amount != 10 {
+			t.Fatalf("update should keep zero-value fields: %+v", got)
+		}
+		expectCode(t, repo.Update(ctx, &conformanceModel{ID: ulidv2.Make().String(), Name: "x"}), errors.ErrCodeNotFound)
+		expectCode(t, repo.Update(ctx, &conformanceModel{Name: "x"}), errors.ErrCodeInvalidArgument)
+
+		// UpdateByID 白名单与租户字段保护
+		if err := repo.UpdateByID(ctx, f.a1.ID, map[string]any{"status": "void", "name": "ignored", "tenant_id": ulidv2.Make()}, "status", "tenant_id"); err != nil {
+			t.Fatalf("update by id: %v", err)
+		}
+		got, _ = repo.FindByID(ctx, f.a1.ID)
+		if got.Status != "void" || got.Name != "Alicia" || got.TenantID != f.tenantA {
+			t.Fatalf("unexpected record after update by id: %+v", got)
+		}
+		if err := repo.UpdateByID(ctx, f.a1.ID, map[string]any{"Amount": 12.5}); err != nil {
+			t.Fatalf("update by struct field name: %v", err)
+		}
+		if got, _ = repo.FindByID(ctx, f.a1.ID); got.Amount != 12.5 {
+			t.Fatalf("expected amount 12.5, got %v", got.Amount)
+		}
+		expectCode(t, repo.UpdateByID(ctx, f.a1.ID, map[string]any{"name": "x"}, "status"), errors.ErrCodeInvalidArgument)
+		expectCode(t, repo.UpdateByID(ctx, f.a1.ID, map[string]any{"unknown": 1}), errors.ErrCodeInvalidArgument)
+		expectCode(t, repo.UpdateByID(ctx, ulidv2.Make().String(), map[string]any{"name": "x"}), errors.ErrCodeNotFound)
+
+		// 软删除
+		if err := repo.Delete(ctx, f.a1.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		_, err = repo.FindByID(ctx, f.a1.ID)
+		expectCode(t, err, errors.ErrCodeNotFound)
+		expectCode(t, repo.Delete(ctx, f.a1.ID), errors.ErrCodeNotFound)
+		expectCode(t, repo.UpdateByID(ctx, f.a1.ID, map[string]any{"name": "x"}), errors.ErrCodeNotFound)
+		if n, _ := repo.Count(ctx, ""); n != 3 {
+			t.Fatalf("expected 3 records after soft delete, got %d", n)
+		}
+
+		// 硬删除包含已软删除的记录
+		if err := repo.HardDelete(ctx, f.a1.ID); err != nil {
+			t.Fatalf("hard delete soft-deleted record: %v", err)
+		}
+		expectCode(t, repo.HardDelete(ctx, f.a1.ID), errors.ErrCodeNotFound)
+
+		if err := repo.DeleteBatch(ctx, []string{f.a2.ID, f.a3.ID, f.b1.ID}); err != nil {
+			t.Fatalf("delete batch: %v", err)
+		}
+		if n, _ := repo.Count(ctx, ""); n != 1 {
+			t.Fatalf("expected 1 record after delete batch, got %d", n)
+		}
+		if n, _ := repo.Count(f.other, ""); n != 1 {
+			t.Fatalf("delete batch must not cross tenants, got %d", n)
+		}
+		expectCode(t, repo.DeleteBatch(ctx, nil), errors.ErrCodeInvalidArgument)
+		expectCode(t, repo.Create(ctx, nil), errors.ErrCodeInvalidArgument)
+	})
+
+	t.Run("Unique", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		expectCode(t, f.repo.Create(f.admin, &conformanceModel{ID: ulidv2.Make().String(), Code: "a1"}), errors.ErrCodeAlreadyExists)
+		expectCode(t, f.repo.Create(f.admin, &conformanceModel{ID: f.a2.ID, Code: "new"}), errors.ErrCodeAlreadyExists)
+		if n, _ := f.repo.Count(f.admin, ""); n != 4 {
+			t.Fatalf("failed creates must not insert, got %d", n)
+		}
+		// 软删除的记录仍占用唯一键
+		if err := f.repo.Delete(f.admin, f.a2.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		expectCode(t, f.repo.Create(f.admin, &conformanceModel{ID: ulidv2.Make().String(), Code: "a2"}), errors.ErrCodeAlreadyExists)
+		expectCode(t, f.repo.UpdateByID(f.admin, f.a3.ID, map[string]any{"code": "a4"}), errors.ErrCodeAlreadyExists)
+	})
+
+	t.Run("TenantScope", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		repo := f.repo
+
+		_, err := repo.FindByQuery(context.Background(), "")
+		expectCode(t, err, errors.ErrCodeUnauthenticated)
+		expectCode(t, repo.Create(context.Background(), &conformanceModel{ID: ulidv2.Make().String()}), errors.ErrCodeUnauthenticated)
+		noDept := WithTenantContext(context.Background(), TenantContext{TenantID: f.tenantA})
+		_, err = repo.Count(noDept, "")
+		expectCode(t, err, errors.ErrCodeUnauthenticated)
+
+		_, err = repo.FindByID(f.admin, f.b1.ID)
+		expectCode(t, err, errors.ErrCodeNotFound)
+		expectCode(t, repo.UpdateByID(f.admin, f.b1.ID, map[string]any{"name": "x"}), errors.ErrCodeNotFound)
+		expectCode(t, repo.Update(f.admin, &conformanceModel{ID: f.b1.ID, Name: "x"}), errors.ErrCodeNotFound)
+		expectCode(t, repo.Delete(f.admin, f.b1.ID), errors.ErrCodeNotFound)
+
+		all, err := repo.FindByQuery(f.admin, "")
+		if err != nil || !slices.Equal(sortedCodes(all), []string{"a1", "a2", "a3", "a4"}) {
+			t.Fatalf("admin should see tenant records: %v %v", codes(all), err)
+		}
+		d1, err := repo.FindByQuery(f.d1, "")
+		if err != nil || !slices.Equal(sortedCodes(d1), []string{"a1", "a3"}) {
+			t.Fatalf("dept user should see dept records: %v %v", codes(d1), err)
+		}
+		if _, err := repo.FindByID(f.d1, f.a2.ID); errors.Code(err) != errors.ErrCodeNotFound {
+			t.Fatalf("dept user must not see other dept record: %v", err)
+		}
+		if n, _ := repo.Count(f.d2, "status = ?", "paid"); n != 1 {
+			t.Fatalf("expected 1 paid record in d2, got %d", n)
+		}
+		if sum, _ := repo.Sum(f.other, "amount", ""); sum != 100 {
+			t.Fatalf("expected tenant B sum 100, got %v", sum)
+		}
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		repo, ctx := f.repo, f.admin
+
+		cases := []struct {
+			query string
+			args  []any
+			want  []string
+		}{
+			{"", nil, []string{"a1", "a2", "a3", "a4"}},
+			{"status = ? AND amount > ?", []any{"paid", 15}, []string{"a2"}},
+			{"status IN ?", []any{[]string{"void", "pending"}}, []string{"a3", "a4"}},
+			{"status NOT IN (?, ?)", []any{"void", "pending"}, []string{"a1", "a2"}},
+			{"name LIKE ?", []any{"al%"}, []string{"a1"}},
+			{"name NOT LIKE ?", []any{"%o%"}, []string{"a1", "a4"}},
+			{"score IS NULL", nil, []string{"a2"}},
+			{"score IS NOT NULL AND score <= ?", []any{7}, []string{"a1", "a3"}},
+			{"score > ?", []any{6}, []string{"a3", "a4"}},
+			{"NOT (score > ?)", []any{6}, []string{"a1"}},
+			{"score > ? OR status = ?", []any{8, "paid"}, []string{"a1", "a2", "a4"}},
+			{"(status = 'paid' OR status = 'void') AND amount <> 20", nil, []string{"a1", "a3"}},
+			{"amount BETWEEN ? AND ?", []any{15, 35}, []string{"a2", "a3"}},
+			{"paid_at >= ? AND paid_at < ?", []any{conformanceDay(2, 0), conformanceDay(4, 0)}, []string{"a1", "a2"}},
+			{"id = ?", []any{f.a4.ID}, []string{"a4"}},
+			{"LOWER(name) = ?", []any{"carol"}, []string{"a3"}},
+		}
+		for _, tc := range cases {
+			got, err := repo.FindByQuery(ctx, tc.query, tc.args...)
+			if err != nil {
+				t.Fatalf("%q: %v", tc.query, err)
+			}
+			if !slices.Equal(sortedCodes(got), tc.want) {
+				t.Fatalf("%q: expected %v, got %v", tc.query, tc.want, codes(got))
+			}
+			count, err := repo.Count(ctx, tc.query, tc.args...)
+			if err != nil || count != int64(len(tc.want)) {
+				t.Fatalf("%q: expected count %d, got %d (%v)", tc.query, len(tc.want), count, err)
+			}
+		}
+
+		ordered, err := repo.FindByQueryWithOpts(ctx, "amount > ?", []Option{WithOrderBy("amount DESC")}, 15)
+		if err != nil || !slices.Equal(codes(ordered), []string{"a4", "a3", "a2"}) {
+			t.Fatalf("order by: %v %v", codes(ordered), err)
+		}
+		ordered, _ = repo.FindByQueryWithOpts(ctx, "", []Option{WithOrderBy("status ASC, amount DESC")})
+		if !slices.Equal(codes(ordered), []string{"a2", "a1", "a4", "a3"}) {
+			t.Fatalf("multi-column order by: %v", codes(ordered))
+		}
+
+		selected, err := repo.FindByIDs(ctx, []string{f.a1.ID, f.a2.ID, f.b1.ID}, WithSelect("id", "code"))
+		if err != nil || !slices.Equal(sortedCodes(selected), []string{"a1", "a2"}) {
+			t.Fatalf("find by ids: %v %v", codes(selected), err)
+		}
+		for _, m := range selected {
+			if m.Name != "" || m.Amount != 0 {
+				t.Fatalf("select should only load selected columns: %+v", m)
+			}
+		}
+		if empty, err := repo.FindByIDs(ctx, nil); err != nil || len(empty) != 0 {
+			t.Fatalf("find by empty ids: %v %v", empty, err)
+		}
+
+		scoped, err := repo.FindByQueryWithOpts(ctx, "", []Option{
+			WithOrderBy("amount"),
+			WithScopes(func(db *gorm.DB) *gorm.DB { return db.Where("amount < ?", 38).Limit(2) }),
+		})
+		if err != nil || !slices.Equal(codes(scoped), []string{"a1", "a2"}) {
+			t.Fatalf("scopes: %v %v", codes(scoped), err)
+		}
+
+		one, err := repo.FindOne(ctx, "status = ?", "paid")
+		if err != nil || one.Code != "a1" {
+			t.Fatalf("find one should return first by primary key: %+v %v", one, err)
+		}
+		one, err = repo.FindOneWithOpts(ctx, "status = ?", []Option{WithOrderBy("amount DESC")}, "paid")
+		if err != nil || one.Code != "a2" {
+			t.Fatalf("find one with order: %+v %v", one, err)
+		}
+		_, err = repo.FindOne(ctx, "status = ?", "missing")
+		expectCode(t, err, errors.ErrCodeNotFound)
+
+		if ok, _ := repo.Exists(ctx, "name = ?", "dave"); !ok {
+			t.Fatalf("expected dave to exist")
+		}
+		if ok, _ := repo.Exists(ctx, "amount > ?", 100); ok {
+			t.Fatalf("expected no record with amount > 100")
+		}
+	})
+
+	t.Run("Page", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		repo, ctx := f.repo, f.admin
+
+		page, err := repo.FindPageWithOpts(ctx, 2, 3, "", []Option{WithOrderBy("amount DESC")})
+		if err != nil {
+			t.Fatalf("find page: %v", err)
+		}
+		if page.Total != 4 || page.Pages != 2 || page.Page != 2 || page.PageSize != 3 || len(page.List) != 1 || page.List[0].Code != "a1" {
+			t.Fatalf("unexpected page: %+v", page)
+		}
+
+		page, err = repo.FindPage(ctx, 0, 0, "status = ?", "paid")
+		if err != nil {
+			t.Fatalf("find page normalize: %v", err)
+		}
+		if page.Page != 1 || page.PageSize != DefaultPageSize || page.Total != 2 || page.Pages != 1 || len(page.List) != 2 {
+			t.Fatalf("unexpected normalized page: %+v", page)
+		}
+
+		page, err = repo.FindPage(ctx, 5, 2, "")
+		if err != nil || page.Total != 4 || page.Pages != 2 || len(page.List) != 0 {
+			t.Fatalf("page beyond range: %+v %v", page, err)
+		}
+
+		page, err = repo.PageBySpec(ctx, PageRequest{Page: 1, PageSize: 2}, Eq[conformanceModel]("status", "paid"), WithOrderBy("amount DESC"))
+		if err != nil || page.Total != 2 || page.Pages != 1 || len(page.List) != 2 || page.List[0].Code != "a2" {
+			t.Fatalf("page by spec: %+v %v", page, err)
+		}
+	})
+
+	t.Run("Specification", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		repo, ctx := f.repo, f.admin
+
+		cases := []struct {
+			name string
+			spec Specification[conformanceModel]
+			want []string
+		}{
+			{"nil", nil, []string{"a1", "a2", "a3", "a4"}},
+			{"eq", Eq[conformanceModel]("status", "paid"), []string{"a1", "a2"}},
+			{"in", In[conformanceModel]("code", []string{"a1", "a4", "b1"}), []string{"a1", "a4"}},
+			{"and", And[conformanceModel](Eq[conformanceModel]("status", "paid"), Where[conformanceModel]("amount > ?", 15)), []string{"a2"}},
+			{"or", Or[conformanceModel](Eq[conformanceModel]("status", "void"), Where[conformanceModel]("amount < ?", 15)), []string{"a1", "a3"}},
+			{"not", Not[conformanceModel](In[conformanceModel]("status", []string{"paid", "void"})), []string{"a4"}},
+			// GORM 对可取反的条件逐个取反: status <> 'paid' AND name <> 'bob'
+			{"not and", Not[conformanceModel](And[conformanceModel](Eq[conformanceModel]("status", "paid"), Eq[conformanceModel]("name", "bob"))), []string{"a3", "a4"}},
+			{"nested", And[conformanceModel](Or[conformanceModel](Eq[conformanceModel]("status", "paid"), Eq[conformanceModel]("status", "pending")), Not[conformanceModel](Eq[conformanceModel]("name", "bob"))), []string{"a1", "a4"}},
+			{"map", Where[conformanceModel](map[string]any{"status": "void"}), []string{"a3"}},
+			{"struct", Where[conformanceModel](&conformanceModel{Status: "paid", Name: "bob"}), []string{"a2"}},
+		}
+		for _, tc := range cases {
+			got, err := repo.FindBySpec(ctx, tc.spec)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			if !slices.Equal(sortedCodes(got), tc.want) {
+				t.Fatalf("%s: expected %v, got %v", tc.name, tc.want, codes(got))
+			}
+			if n, err := repo.CountBySpec(ctx, tc.spec); err != nil || n != int64(len(tc.want)) {
+				t.Fatalf("%s: expected count %d, got %d (%v)", tc.name, len(tc.want), n, err)
+			}
+		}
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		repo, ctx := f.repo, f.admin
+
+		if sum, err := repo.Sum(ctx, "amount", ""); err != nil || sum != 105 {
+			t.Fatalf("sum: %v %v", sum, err)
+		}
+		if sum, _ := repo.Sum(ctx, "amount", "status = ?", "paid"); sum != 30 {
+			t.Fatalf("sum paid: %v", sum)
+		}
+		if sum, _ := repo.Sum(ctx, "amount", "status = ?", "missing"); sum != 0 {
+			t.Fatalf("sum empty: %v", sum)
+		}
+		if avg, _ := repo.Avg(ctx, "score", ""); avg != 7 {
+			t.Fatalf("avg should skip NULL: %v", avg)
+		}
+		if maxAmount, err := repo.Max(ctx, "amount", ""); err != nil || maxAmount != float64(40) {
+			t.Fatalf("max: %#v %v", maxAmount, err)
+		}
+		if minScore, _ := repo.Min(ctx, "score", ""); minScore != int64(5) {
+			t.Fatalf("min: %#v", minScore)
+		}
+		if v, _ := repo.Max(ctx, "amount", "status = ?", "missing"); v != nil {
+			t.Fatalf("max of no rows should be nil, got %#v", v)
+		}
+		_, err := repo.Sum(ctx, "amount; DROP TABLE x", "")
+		expectCode(t, err, errors.ErrCodeInvalidArgument)
+
+		rows, err := repo.Aggregate(ctx, AggregateSpec{
+			GroupBy: []string{"status"},
+			Metrics: []Metric{Sum("amount"), Count(), Avg("score").As("avg_score")},
+			OrderBy: []string{"-sum_amount"},
+		})
+		if err != nil {
+			t.Fatalf("aggregate: %v", err)
+		}
+		var statuses []string
+		for _, row := range rows {
+			statuses = append(statuses, row.GroupString("status"))
+		}
+		if !slices.Equal(statuses, []string{"pending", "void", "paid"}) {
+			t.Fatalf("unexpected aggregate order: %v", statuses)
+		}
+		if rows[2].Value("sum_amount") != 30 || rows[2].Value("count") != 2 || rows[2].Value("avg_score") != 5 {
+			t.Fatalf("unexpected paid row: %+v", rows[2])
+		}
+
+		rows, err = repo.Aggregate(ctx, AggregateSpec{
+			GroupBy: []string{"status"},
+			Metrics: []Metric{Sum("amount")},
+			Query:   "amount > ?",
+			Args:    []any{5},
+			Having:  []Having{{Metric: Sum("amount"), Op: ">", Value: 31}},
+			OrderBy: []string{"status"},
+			Limit:   1,
+		})
+		if err != nil || len(rows) != 1 || rows[0].GroupString("status") != "pending" {
+			t.Fatalf("aggregate with having: %+v %v", rows, err)
+		}
+
+		rows, err = repo.Aggregate(ctx, AggregateSpec{Metrics: []Metric{Count(), CountDistinct("status")}})
+		if err != nil || len(rows) != 1 || rows[0].Value("count") != 4 || rows[0].Value("count_distinct_status") != 3 {
+			t.Fatalf("aggregate without group: %+v %v", rows, err)
+		}
+
+		type statusTotal struct {
+			Status string  `gorm:"column:status"`
+			Total  float64 `gorm:"column:total"`
+			Count  int64   `gorm:"column:count"`
+		}
+		var totals []statusTotal
+		if err := repo.AggregateInto(ctx, AggregateSpec{
+			GroupBy: []string{"status"},
+			Metrics: []Metric{Sum("amount").As("total"), Count()},
+			OrderBy: []string{"status"},
+		}, &totals); err != nil {
+			t.Fatalf("aggregate into: %v", err)
+		}
+		if len(totals) != 3 || totals[0] != (statusTotal{Status: "paid", Total: 30, Count: 2}) {
+			t.Fatalf("unexpected aggregate into result: %+v", totals)
+		}
+
+		_, err = repo.Aggregate(ctx, AggregateSpec{GroupBy: []string{"status"}, Metrics: []Metric{Count()}, OrderBy: []string{"name"}})
+		expectCode(t, err, errors.ErrCodeInvalidArgument)
+	})
+
+	t.Run("TimeBucket", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		repo, ctx := f.repo, f.admin
+
+		series, err := repo.SumByTimeBucket(ctx, "amount", BucketDay, conformanceDay(2, 0), conformanceDay(5, 0),
+			WithBucketTimeColumn("paid_at"))
+		if err != nil {
+			t.Fatalf("sum by day: %v", err)
+		}
+		var values []float64
+		for _, p := range series {
+			values = append(values, p.Value)
+		}
+		// 范围起点为 2 日 00:30，截断到 2 日 00:00，共 4 个桶
+		if !slices.Equal(values, []float64{30, 0, 35, 0}) || !series[0].Bucket.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("unexpected day series: %+v", series)
+		}
+
+		weekly, err := repo.AggregateByTimeBucket(ctx, Count(), BucketWeek, conformanceDay(1, 0), conformanceDay(15, 0),
+			WithBucketTimeColumn("paid_at"), WithBucketWhere("status <> ?", "void"), WithoutBucketFill())
+		if err != nil {
+			t.Fatalf("count by week: %v", err)
+		}
+		if len(weekly) != 2 || weekly[0].Value != 2 || weekly[1].Value != 1 || weekly[1].Bucket.Weekday() != time.Monday {
+			t.Fatalf("unexpected week series: %+v", weekly)
+		}
+
+		_, err = repo.SumByTimeBucket(ctx, "amount", BucketDay, conformanceDay(5, 0), conformanceDay(2, 0), WithBucketTimeColumn("paid_at"))
+		expectCode(t, err, errors.ErrCodeInvalidArgument)
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		repo, ctx := f.repo, f.admin
+
+		existing, err := repo.FindByID(ctx, f.a1.ID)
+		if err != nil {
+			t.Fatalf("find: %v", err)
+		}
+		existing.Name = "Alice Upserted"
+		fresh := &conformanceModel{ID: ulidv2.Make().String(), Code: "a5", Name: "eve", Status: "paid", Amount: 5}
+		if err := repo.UpsertBatch(ctx, []*conformanceModel{existing, fresh, nil}); err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+		if n, _ := repo.Count(ctx, ""); n != 5 {
+			t.Fatalf("expected 5 records after upsert, got %d", n)
+		}
+		got, _ := repo.FindByID(ctx, f.a1.ID)
+		if got.Name != "Alice Upserted" || got.Amount != 10 {
+			t.Fatalf("unexpected upserted record: %+v", got)
+		}
+		if got, err := repo.FindByID(ctx, fresh.ID); err != nil || got.TenantID != f.tenantA {
+			t.Fatalf("upsert should insert with tenant: %+v %v", got, err)
+		}
+		expectCode(t, repo.UpsertBatch(ctx, nil), errors.ErrCodeInvalidArgument)
+
+		// 按唯一索引冲突：保留原主键，只更新指定列
+		byCode := &conformanceModel{ID: ulidv2.Make().String(), Code: "a2", Name: "ignored", Amount: 99}
+		if err := repo.UpsertBatch(ctx, []*conformanceModel{byCode}, WithConflictColumns("code"), WithUpdateColumns("amount")); err != nil {
+			t.Fatalf("upsert by code: %v", err)
+		}
+		got, err = repo.FindByKey(ctx, map[string]any{"code": "a2"})
+		if err != nil || got.ID != f.a2.ID || got.Amount != 99 || got.Name != "bob" {
+			t.Fatalf("unexpected record after upsert by code: %+v %v", got, err)
+		}
+		if n, _ := repo.Count(ctx, ""); n != 5 {
+			t.Fatalf("upsert by code must not insert, got %d", n)
+		}
+		expectCode(t, repo.UpsertBatch(ctx, []*conformanceModel{byCode}, WithConflictColumns("name")), errors.ErrCodeInvalidArgument)
+		expectCode(t, repo.UpsertBatch(ctx, []*conformanceModel{byCode}, WithUpdateColumns("missing")), errors.ErrCodeInvalidArgument)
+		expectCode(t, repo.UpsertBatch(ctx, []*conformanceModel{byCode}, WithUpdateColumns("id")), errors.ErrCodeInvalidArgument)
+	})
+
+	t.Run("CreateBatch", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := WithTenantContext(context.Background(), TenantContext{TenantID: ulidv2.Make(), IsAdmin: true})
+		models := []*conformanceModel{
+			{ID: ulidv2.Make().String(), Code: "c1"},
+			nil,
+			{ID: ulidv2.Make().String(), Code: "c2"},
+		}
+		if err := repo.CreateBatch(ctx, models, 1); err != nil {
+			t.Fatalf("create batch: %v", err)
+		}
+		if n, _ := repo.Count(ctx, ""); n != 2 {
+			t.Fatalf("expected 2 records, got %d", n)
+		}
+		// 批次内冲突整体失败
+		err := repo.CreateBatch(ctx, []*conformanceModel{
+			{ID: ulidv2.Make().String(), Code: "c3"},
+			{ID: ulidv2.Make().String(), Code: "c3"},
+		}, 10)
+		expectCode(t, err, errors.ErrCodeAlreadyExists)
+		if n, _ := repo.Count(ctx, ""); n != 2 {
+			t.Fatalf("failed batch must not insert, got %d", n)
+		}
+		expectCode(t, repo.CreateBatch(ctx, nil, 10), errors.ErrCodeInvalidArgument)
+		if err := repo.CreateBatch(ctx, []*conformanceModel{nil}, 10); err != nil {
+			t.Fatalf("all-nil batch should be a no-op: %v", err)
+		}
+	})
+
+	t.Run("Execute", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		repo := f.repo
+
+		errRollback := stderrors.New("rollback")
+		err := repo.Execute(f.admin, func(ctx context.Context) error {
+			if err := repo.Create(ctx, &conformanceModel{ID: ulidv2.Make().String(), Code: "tx"}); err != nil {
+				return err
+			}
+			if err := repo.UpdateByID(ctx, f.a1.ID, map[string]any{"name": "changed"}); err != nil {
+				return err
+			}
+			if err := repo.Delete(ctx, f.a2.ID); err != nil {
+				return err
+			}
+			return errRollback
+		})
+		if !stderrors.Is(err, errRollback) {
+			t.Fatalf("expected rollback error, got %v", err)
+		}
+		if n, _ := repo.Count(f.admin, ""); n != 4 {
+			t.Fatalf("expected rollback to restore 4 records, got %d", n)
+		}
+		if got, _ := repo.FindByID(f.admin, f.a1.ID); got == nil || got.Name != "Alice" {
+			t.Fatalf("expected rollback to restore name: %+v", got)
+		}
+
+		if err := repo.Execute(f.admin, func(ctx context.Context) error {
+			return repo.Create(ctx, &conformanceModel{ID: ulidv2.Make().String(), Code: "tx"})
+		}); err != nil {
+			t.Fatalf("execute commit: %v", err)
+		}
+		if n, _ := repo.Count(f.admin, ""); n != 5 {
+			t.Fatalf("expected committed record, got %d", n)
+		}
+	})
+
+	t.Run("Hooks", func(t *testing.T) {
+		repo := newRepo(t)
+		ctx := WithTenantContext(context.Background(), TenantContext{TenantID: ulidv2.Make(), IsAdmin: true})
+
+		var events []string
+		repo.OnBeforeCreate(func(_ context.Context, m *conformanceModel) error {
+			if m.Code == "" {
+				return stderrors.New("code required")
+			}
+			events = append(events, "before_create:"+m.Code)
+			return nil
+		})
+		repo.OnAfterCreate(func(_ context.Context, m *conformanceModel) error {
+			events = append(events, "after_create:"+m.Code)
+			return nil
+		})
+		repo.OnBeforeUpdate(func(_ context.Context, m *conformanceModel) error {
+			events = append(events, "before_update:"+m.Name)
+			return nil
+		})
+		repo.OnAfterUpdate(func(_ context.Context, m *conformanceModel) error {
+			events = append(events, "after_update:"+m.Name)
+			return nil
+		})
+		repo.OnBeforeDelete(func(_ context.Context, ids []string) error {
+			events = append(events, "before_delete")
+			return nil
+		})
+		repo.OnAfterDelete(func(_ context.Context, ids []string) error {
+			events = append(events, "after_delete")
+			return nil
+		})
+
+		m := &conformanceModel{ID: ulidv2.Make().String(), Code: "h1", Name: "old"}
+		if err := repo.Create(ctx, m); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if err := repo.Create(ctx, &conformanceModel{ID: ulidv2.Make().String()}); err == nil {
+			t.Fatalf("expected before create hook to abort")
+		}
+		if err := repo.UpdateByID(ctx, m.ID, map[string]any{"name": "new"}); err != nil {
+			t.Fatalf("update by id: %v", err)
+		}
+		if err := repo.Delete(ctx, m.ID); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		want := []string{"before_create:h1", "after_create:h1", "before_update:old", "after_update:new", "before_delete", "after_delete"}
+		if !slices.Equal(events, want) {
+			t.Fatalf("unexpected events:\n got %v\nwant %v", events, want)
+		}
+		if n, _ := repo.Count(ctx, ""); n != 0 {
+			t.Fatalf("aborted create must not insert, got %d", n)
+		}
+	})
+}
+
+func TestRepositoryConformance(t *testing.T) {
+	runRepositoryConformance(t, func(t *testing.T) Repository[conformanceModel] {
+		return NewRepository[conformanceModel](openTestDB(t, &conformanceModel{}))
+	})
+}
+
+func TestMemoryRepositoryConformance(t *testing.T) {
+	runRepositoryConformance(t, func(*testing.T) Repository[conformanceModel] {
+		return NewMemoryRepository[conformanceModel]()
+	})
+}
+
+// memoryBaseModel 使用 BaseModel 的模型（ULID 主键由 GORM 钩子生成）
+type memoryBaseModel struct {
+	BaseModel
+	Name string `gorm:"column:name"`
+}
+
+func (memoryBaseModel) TenantIgnored() bool { return true }
+
+func TestMemoryRepositoryBaseModel(t *testing.T) {
+	repo := NewMemoryRepository[memoryBaseModel]()
+	ctx := context.Background()
+
+	m := &memoryBaseModel{Name: "alice"}
+	if err := repo.Create(ctx, m); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if m.ID == (ulidv2.ULID{}) || m.CreateTime.IsZero() || m.UpdateTime.IsZero() {
+		t.Fatalf("expected id and timestamps to be generated: %+v", m)
+	}
+
+	got, err := repo.FindByID(ctx, m.ID.String())
+	if err != nil || got.Name != "alice" {
+		t.Fatalf("find by ulid string: %+v %v", got, err)
+	}
+	got.Name = "mutated"
+	if again, _ := repo.FindByID(ctx, m.ID.String()); again.Name != "alice" {
+		t.Fatalf("returned records must be copies")
+	}
+
+	if err := repo.Delete(ctx, m.ID.String()); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, m.ID.String()); !errors.IsNotFound(err) {
+		t.Fatalf("expected soft-deleted record to be hidden: %v", err)
+	}
+	if repo.GetDB() != nil || repo.WithTx(nil) != repo {
+		t.Fatalf("memory repository has no underlying db")
+	}
+}
+
+func TestMemoryRepositoryClock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	repo := NewMemoryRepository[memoryBaseModel](WithMemoryClock(fake))
+	ctx := context.Background()
+
+	m := &memoryBaseModel{Name: "alice"}
+	if err := repo.Create(ctx, m); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !m.CreateTime.Equal(start) || !m.UpdateTime.Equal(start) {
+		t.Fatalf("expected timestamps from fake clock: %v %v", m.CreateTime, m.UpdateTime)
+	}
+
+	fake.Advance(time.Hour)
+	m.Name = "bob"
+	if err := repo.Update(ctx, m); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	got, err := repo.FindByID(ctx, m.ID.String())
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if !got.CreateTime.Equal(start) || !got.UpdateTime.Equal(start.Add(time.Hour)) {
+		t.Fatalf("unexpected timestamps after update: %v %v", got.CreateTime, got.UpdateTime)
+	}
+}
+
+func TestMemoryRepositoryUnsupported(t *testing.T) {
+	repo := NewMemoryRepository[memoryBaseModel]()
+	ctx := context.Background()
+
+	cases := []struct {
+		name string
+		run  func() error
+	}{
+		{"joins", func() error {
+			_, err := repo.FindByQueryWithOpts(ctx, "", []Option{WithJoins("JOIN other ON other.id = id")})
+			return err
+		}},
+		{"unknown column", func() error { _, err := repo.FindByQuery(ctx, "missing = ?", 1); return err }},
+		{"named args", func() error { _, err := repo.FindByQuery(ctx, "name = @name", 1); return err }},
+		{"args mismatch", func() error { _, err := repo.FindByQuery(ctx, "name = ? AND id = ?", "x"); return err }},
+	}
+	for _, tc := range cases {
+		if err := tc.run(); errors.Code(err) != errors.ErrCodeInvalidArgument {
+			t.Fatalf("%s: expected invalid argument, got %v", tc.name, err)
+		}
+	}
+}
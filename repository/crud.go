@@ -5,10 +5,10 @@ import (
 	"reflect"
 	"sync"
 
+	"github.com/aisgo/ais-go-pkg/ctxutil"
 	"github.com/aisgo/ais-go-pkg/errors"
 
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 	"gorm.io/gorm/schema"
 )
 
@@ -78,6 +78,15 @@ const (
 type RepositoryImpl[T any] struct {
 	db *gorm.DB
 
+	// router 按请求选择物理库（可选，见 NewRoutedRepository）
+	router DBRouter
+
+	// partition 按时间分表（可选，见 WithTableSuffixFromTime）
+	partition *timePartition
+
+	// hooks 仓储级钩子（WithTx 派生的仓储共享）
+	hooks *repoHooks[T]
+
 	// Schema 缓存（线程安全）
 	schemaOnce sync.Once
 	schema     *schema.Schema
@@ -85,8 +94,10 @@ type RepositoryImpl[T any] struct {
 }
 
 // NewRepository 创建新的仓储实例
-func NewRepository[T any](db *gorm.DB) Repository[T] {
-	return &RepositoryImpl[T]{db: db}
+func NewRepository[T any](db *gorm.DB, opts ...RepositoryOption) Repository[T] {
+	r := &RepositoryImpl[T]{db: db, hooks: &repoHooks[T]{}}
+	r.applyOptions(opts)
+	return r
 }
 
 // GetDB 获取底层 GORM DB 实例
@@ -100,9 +111,28 @@ func (r *RepositoryImpl[T]) newModelPtr() *T {
 	return &model
 }
 
-// withContext 返回带 context 的 DB (自动识别事务)
+// withContext 返回带 context 的 DB (自动识别事务、租户路由与时间分表)
 func (r *RepositoryImpl[T]) withContext(ctx context.Context) *gorm.DB {
-	return getDBFromContext(ctx, r.db)
+	db := r.connDB(ctx)
+	if r.partition != nil {
+		db = r.partition.scope(db, partitionTime(ctx, r.now))
+	}
+	return db
+}
+
+// connDB 返回 ctx 对应的连接（自动识别事务与租户路由，不指定表）
+// ctx 未设置 deadline 且 ctxutil 处于 enforce 模式时，返回的 DB 携带 ErrNoDeadline
+func (r *RepositoryImpl[T]) connDB(ctx context.Context) *gorm.DB {
+	var db *gorm.DB
+	if r.router != nil && !InTx(ctx) && !r.isTenantIgnored(r.newModelPtr()) {
+		db = routeDB(ctx, r.router, r.db)
+	} else {
+		db = getDBFromContext(ctx, r.db)
+	}
+	if err := ctxutil.RequireDeadline(ctx, "repository"); err != nil {
+		_ = db.AddError(err)
+	}
+	return db
 }
 
 // getSchema 获取缓存的 Schema（线程安全）
@@ -130,8 +160,18 @@ func (r *RepositoryImpl[T]) Create(ctx context.Context, model *T) error {
 	if err := r.setTenantFields(ctx, model); err != nil {
 		return err
 	}
+	if err := r.runBeforeCreate(ctx, model); err != nil {
+		return err
+	}
 
-	return r.withContext(ctx).Create(model).Error
+	db, err := r.writeDB(ctx, model)
+	if err != nil {
+		return err
+	}
+	if err := db.Create(model).Error; err != nil {
+		return errors.FromGORM(err)
+	}
+	return r.runAfterCreate(ctx, model)
 }
 
 // CreateBatch 批量创建记录
@@ -160,9 +200,22 @@ func (r *RepositoryImpl[T]) CreateBatch(ctx context.Context, models []*T, batchS
 		if err := r.setTenantFields(ctx, m); err != nil {
 			return err
 		}
+		if err := r.runBeforeCreate(ctx, m); err != nil {
+			return err
+		}
 	}
 
-	return r.withContext(ctx).CreateInBatches(validModels, batchSize).Error
+	if err := r.eachPartition(ctx, validModels, func(db *gorm.DB, batch []*T) error {
+		return db.CreateInBatches(batch, batchSize).Error
+	}); err != nil {
+		return errors.FromGORM(err)
+	}
+	for _, m := range validModels {
+		if err := r.runAfterCreate(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 /* ========================================================================
@@ -179,18 +232,27 @@ func (r *RepositoryImpl[T]) Update(ctx context.Context, model *T) error {
 	if err := r.ensurePrimaryKeySet(ctx, model); err != nil {
 		return err
 	}
+	if err := r.setAuditFields(ctx, model); err != nil {
+		return err
+	}
+	if err := r.runBeforeUpdate(ctx, model); err != nil {
+		return err
+	}
 
-	db := r.applyTenantScope(ctx, r.withContext(ctx))
-	result := db.Model(model).Updates(model)
+	db, err := r.writeDB(ctx, model)
+	if err != nil {
+		return err
+	}
+	result := r.applyTenantScope(ctx, db).Model(model).Updates(model)
 	if result.Error != nil {
-		return result.Error
+		return errors.FromGORM(result.Error)
 	}
 
 	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+		return errors.FromGORM(gorm.ErrRecordNotFound)
 	}
 
-	return nil
+	return r.runAfterUpdate(ctx, model)
 }
 
 // UpdateByID 根据 ID 更新指定字段
@@ -208,17 +270,39 @@ func (r *RepositoryImpl[T]) UpdateByID(ctx context.Context, id string, updates m
 	if len(filteredUpdates) == 0 {
 		return errors.ErrInvalidArgument
 	}
+	if err := r.auditUpdates(ctx, filteredUpdates); err != nil {
+		return err
+	}
+
+	// 注册了更新钩子时加载更新前记录
+	needBefore, needAfter := r.hasUpdateHooks()
+	if needBefore {
+		current, err := r.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := r.runBeforeUpdate(ctx, current); err != nil {
+			return err
+		}
+	}
 
 	model := r.newModelPtr()
 	result := r.applyTenantScope(ctx, r.withContext(ctx)).Model(model).Where("id = ?", id).Updates(filteredUpdates)
 	if result.Error != nil {
-		return result.Error
+		return errors.FromGORM(result.Error)
 	}
 
 	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+		return errors.FromGORM(gorm.ErrRecordNotFound)
 	}
 
+	if needAfter {
+		updated, err := r.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return r.runAfterUpdate(ctx, updated)
+	}
 	return nil
 }
 
@@ -248,7 +332,7 @@ func (r *RepositoryImpl[T]) filterUpdates(updates map[string]any, allowedFields
 
 		// 优先匹配数据库列名 (DB Name)
 		if field, ok := schema.FieldsByDBName[k]; ok {
-			if field.DBName == tenantColumn || field.DBName == deptColumn {
+			if field.DBName == tenantColumn || field.DBName == deptColumn || field.DBName == createdByColumn {
 				continue
 			}
 			if !field.PrimaryKey && field.Updatable {
@@ -258,7 +342,7 @@ func (r *RepositoryImpl[T]) filterUpdates(updates map[string]any, allowedFields
 		}
 		// 尝试匹配结构体字段名 (Struct Field Name)
 		if field, ok := schema.FieldsByName[k]; ok {
-			if field.DBName == tenantColumn || field.DBName == deptColumn {
+			if field.DBName == tenantColumn || field.DBName == deptColumn || field.DBName == createdByColumn {
 				continue
 			}
 			if !field.PrimaryKey && field.Updatable {
@@ -273,12 +357,17 @@ func (r *RepositoryImpl[T]) filterUpdates(updates map[string]any, allowedFields
 
 // UpsertBatch 批量更新或插入记录
 // 注意：此方法使用 Upsert 语义（如果记录不存在则插入，存在则更新所有字段）。
-// 对应 MySQL: INSERT ... ON DUPLICATE KEY UPDATE
+// 冲突列与更新列可通过 WithConflictColumns / WithUpdateColumns 配置（见 key.go）。
+// 对应 MySQL: INSERT ... ON DUPLICATE KEY UPDATE（MySQL 忽略冲突列，按任一唯一键冲突）
 // 对应 Postgres: INSERT ... ON CONFLICT DO UPDATE
-func (r *RepositoryImpl[T]) UpsertBatch(ctx context.Context, models []*T) error {
+func (r *RepositoryImpl[T]) UpsertBatch(ctx context.Context, models []*T, opts ...UpsertOption) error {
 	if len(models) == 0 {
 		return errors.ErrInvalidArgument
 	}
+	onConflict, err := r.upsertClause(ApplyUpsertOptions(opts))
+	if err != nil {
+		return err
+	}
 
 	// 过滤 nil 模型
 	validModels := make([]*T, 0, len(models))
@@ -299,10 +388,10 @@ func (r *RepositoryImpl[T]) UpsertBatch(ctx context.Context, models []*T) error
 	}
 
 	// 使用 Upsert 实现高效批量更新
-	if err := r.withContext(ctx).Clauses(clause.OnConflict{
-		UpdateAll: true,
-	}).Save(validModels).Error; err != nil {
-		return err
+	if err := r.eachPartition(ctx, validModels, func(db *gorm.DB, batch []*T) error {
+		return db.Clauses(onConflict).Save(batch).Error
+	}); err != nil {
+		return errors.FromGORM(err)
 	}
 
 	return nil
@@ -314,17 +403,22 @@ func (r *RepositoryImpl[T]) UpsertBatch(ctx context.Context, models []*T) error
 
 // Delete 软删除记录（设置 deleted_at）
 func (r *RepositoryImpl[T]) Delete(ctx context.Context, id string) error {
+	ids := []string{id}
+	if err := r.runBeforeDelete(ctx, ids); err != nil {
+		return err
+	}
+
 	model := r.newModelPtr()
 	result := r.applyTenantScope(ctx, r.withContext(ctx)).Delete(model, "id = ?", id)
 	if result.Error != nil {
-		return result.Error
+		return errors.FromGORM(result.Error)
 	}
 
 	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+		return errors.FromGORM(gorm.ErrRecordNotFound)
 	}
 
-	return nil
+	return r.runAfterDelete(ctx, ids)
 }
 
 // DeleteBatch 批量软删除记录
@@ -333,23 +427,35 @@ func (r *RepositoryImpl[T]) DeleteBatch(ctx context.Context, ids []string) error
 		return errors.ErrInvalidArgument
 	}
 
+	if err := r.runBeforeDelete(ctx, ids); err != nil {
+		return err
+	}
+
 	model := r.newModelPtr()
-	return r.applyTenantScope(ctx, r.withContext(ctx)).Delete(model, "id IN ?", ids).Error
+	if err := r.applyTenantScope(ctx, r.withContext(ctx)).Delete(model, "id IN ?", ids).Error; err != nil {
+		return errors.FromGORM(err)
+	}
+	return r.runAfterDelete(ctx, ids)
 }
 
 // HardDelete 硬删除记录（从数据库移除）
 func (r *RepositoryImpl[T]) HardDelete(ctx context.Context, id string) error {
+	ids := []string{id}
+	if err := r.runBeforeDelete(ctx, ids); err != nil {
+		return err
+	}
+
 	model := r.newModelPtr()
 	result := r.applyTenantScope(ctx, r.withContext(ctx)).Unscoped().Delete(model, "id = ?", id)
 	if result.Error != nil {
-		return result.Error
+		return errors.FromGORM(result.Error)
 	}
 
 	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+		return errors.FromGORM(gorm.ErrRecordNotFound)
 	}
 
-	return nil
+	return r.runAfterDelete(ctx, ids)
 }
 
 func (r *RepositoryImpl[T]) ensurePrimaryKeySet(ctx context.Context, model any) error {
@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * Data Scope - 数据权限范围
+ * ========================================================================
+ * 职责: 非管理员在租户内可见的数据范围（仅对带 dept_id 列的模型生效）
+ * 范围:
+ *   - DataScopeDept（默认）: 本部门，dept_id = DeptID
+ *   - DataScopeDeptAndChildren: 本部门及下级部门，子树由 ctx 中的 DeptTreeResolver 解析
+ *   - DataScopeCustom: 自定义部门列表，dept_id IN DeptIDs（空列表不可见任何数据）
+ *   - DataScopeSelf: 仅本人，模型有归属列（owner_id / created_by，见 owner.go）时按归属过滤，
+ *     否则同本部门；既无归属列也无部门列时不可见任何数据
+ *   - DataScopeAll: 租户内全部部门
+ * 说明:
+ *   - 管理员（IsAdmin）不受数据范围限制
+ *   - 本部门 / 本部门及下级 / 按部门回退的仅本人范围要求提供 DeptID，全部与自定义范围不要求
+ *   - 数据范围只影响读取、更新与删除；创建时仍写入 DeptID，非管理员必须提供 DeptID
+ *
+ * 使用示例:
+ *   depts := repository.NewDeptTree[Dept](deptRepo, time.Minute)
+ *   ctx = repository.WithDeptTree(ctx, depts)
+ *   ctx = repository.WithTenantContext(ctx, repository.TenantContext{
+ *       TenantID:  tenantID,
+ *       DeptID:    &deptID,
+ *       DataScope: repository.DataScopeDeptAndChildren,
+ *   })
+ *   orders, err := orderRepo.FindByQuery(ctx, "status = ?", "paid") // 本部门及下级部门的订单
+ * ======================================================================== */
+
+// DataScope 数据权限范围
+type DataScope string
+
+const (
+	DataScopeDept            DataScope = ""                  // 本部门（默认）
+	DataScopeDeptAndChildren DataScope = "dept_and_children" // 本部门及下级部门
+	DataScopeCustom          DataScope = "custom"            // 自定义部门列表
+	DataScopeSelf            DataScope = "self"              // 仅本人
+	DataScopeAll             DataScope = "all"               // 租户内全部
+)
+
+// DeptTreeResolver 部门子树解析器
+type DeptTreeResolver interface {
+	// Subtree 返回 dept 及其全部下级部门 ID
+	Subtree(ctx context.Context, tenantID, deptID ulidv2.ULID) ([]ulidv2.ULID, error)
+}
+
+type deptTreeCtxKey struct{}
+
+// WithDeptTree 注入部门子树解析器
+func WithDeptTree(ctx context.Context, resolver DeptTreeResolver) context.Context {
+	return context.WithValue(ctx, deptTreeCtxKey{}, resolver)
+}
+
+// DeptTreeFromContext 读取部门子树解析器
+func DeptTreeFromContext(ctx context.Context) (DeptTreeResolver, bool) {
+	resolver, ok := ctx.Value(deptTreeCtxKey{}).(DeptTreeResolver)
+	return resolver, ok && resolver != nil
+}
+
+// applyDataScope 按数据范围过滤（调用方保证 tc 为非管理员）
+func (r *RepositoryImpl[T]) applyDataScope(ctx context.Context, db *gorm.DB, tc TenantContext, hasDept bool) *gorm.DB {
+	if tc.DataScope == DataScopeSelf {
+		s, err := r.getSchema()
+		if err != nil {
+			db.AddError(err)
+			return db
+		}
+		if ownerField(s) != nil {
+			return r.whereOwner(db, tc)
+		}
+		if !hasDept {
+			// 既无归属列也无部门列时不可见任何数据，避免退化为租户内全部可见
+			return db.Where("1 = 0")
+		}
+	}
+	if !hasDept {
+		return db
+	}
+
+	switch tc.DataScope {
+	case DataScopeAll:
+		return db
+	case DataScopeCustom:
+		return db.Where(deptColumn+" IN ?", tc.DeptIDs)
+	case DataScopeDept, DataScopeSelf, DataScopeDeptAndChildren:
+		// 基于本部门的范围必须提供 DeptID
+		if tc.DeptID == nil {
+			db.AddError(errors.New(errors.ErrCodeUnauthenticated, "non-admin user must provide dept_id"))
+			return db
+		}
+	default:
+		db.AddError(errors.New(errors.ErrCodeUnauthenticated, "unknown data scope: "+string(tc.DataScope)))
+		return db
+	}
+
+	if tc.DataScope != DataScopeDeptAndChildren {
+		return db.Where(deptColumn+" = ?", *tc.DeptID)
+	}
+	resolver, ok := DeptTreeFromContext(ctx)
+	if !ok {
+		db.AddError(errors.New(errors.ErrCodeInternal, "dept tree resolver is not configured"))
+		return db
+	}
+	depts, err := resolver.Subtree(ctx, tc.TenantID, *tc.DeptID)
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+	return db.Where(deptColumn+" IN ?", depts)
+}
+
+/* ========================================================================
+ * DeptTree - 基于部门仓储的子树解析
+ * ======================================================================== */
+
+// DeptTree 基于部门树仓储（FindDescendants）的子树解析器，按租户缓存
+// 部门模型需有 id（ULID 字符串）与 parent_id 列，见 TreeOption
+type DeptTree[T any] struct {
+	repo Repository[T]
+	ttl  time.Duration
+	opts []TreeOption
+
+	mu    sync.Mutex
+	cache map[deptTreeKey]deptTreeEntry
+}
+
+type deptTreeKey struct {
+	tenant ulidv2.ULID
+	dept   ulidv2.ULID
+}
+
+type deptTreeEntry struct {
+	depts   []ulidv2.ULID
+	expires time.Time
+}
+
+// NewDeptTree 创建部门子树解析器，ttl <= 0 时不缓存
+func NewDeptTree[T any](repo Repository[T], ttl time.Duration, opts ...TreeOption) *DeptTree[T] {
+	return &DeptTree[T]{repo: repo, ttl: ttl, opts: opts, cache: make(map[deptTreeKey]deptTreeEntry)}
+}
+
+// Subtree 实现 DeptTreeResolver
+func (t *DeptTree[T]) Subtree(ctx context.Context, tenantID, deptID ulidv2.ULID) ([]ulidv2.ULID, error) {
+	key := deptTreeKey{tenant: tenantID, dept: deptID}
+	if t.ttl > 0 {
+		t.mu.Lock()
+		e, ok := t.cache[key]
+		t.mu.Unlock()
+		if ok && time.Now().Before(e.expires) {
+			return e.depts, nil
+		}
+	}
+
+	ts, err := newTreeSchema(t.repo, t.opts)
+	if err != nil {
+		return nil, err
+	}
+	// 以租户管理员身份读取部门树，避免部门模型自身的数据范围限制
+	adminCtx := adminContext(ctx, tenantID)
+	descendants, err := FindDescendants(adminCtx, t.repo, deptID.String(), t.opts...)
+	if err != nil {
+		return nil, err
+	}
+	depts := make([]ulidv2.ULID, 0, len(descendants)+1)
+	depts = append(depts, deptID)
+	for _, d := range descendants {
+		id, err := ulidv2.ParseStrict(ts.key(ctx, ts.id, d))
+		if err != nil {
+			return nil, errors.Wrap(errors.ErrCodeInternal, "invalid dept id", err)
+		}
+		depts = append(depts, id)
+	}
+
+	if t.ttl > 0 {
+		t.mu.Lock()
+		t.cache[key] = deptTreeEntry{depts: depts, expires: time.Now().Add(t.ttl)}
+		t.mu.Unlock()
+	}
+	return depts, nil
+}
+
+// Invalidate 清除租户的子树缓存（部门调整后调用）
+func (t *DeptTree[T]) Invalidate(tenantID ulidv2.ULID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.cache {
+		if key.tenant == tenantID {
+			delete(t.cache, key)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// scopeDept 租户内部门树
+type scopeDept struct {
+	ID       string      `gorm:"column:id;type:char(26);primaryKey"`
+	TenantID ulidv2.ULID `gorm:"column:tenant_id;type:char(26);not null"`
+	ParentID *string     `gorm:"column:parent_id;type:char(26)"`
+}
+
+// scopeDoc 带部门与创建人的业务数据
+type scopeDoc struct {
+	ID        string       `gorm:"column:id;type:char(26);primaryKey"`
+	TenantID  ulidv2.ULID  `gorm:"column:tenant_id;type:char(26);not null"`
+	DeptID    *ulidv2.ULID `gorm:"column:dept_id;type:char(26)"`
+	CreatedBy ulidv2.ULID  `gorm:"column:created_by;type:char(26)"`
+	Name      string       `gorm:"column:name"`
+}
+
+func TestDataScope(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, depts Repository[scopeDept], db *gorm.DB) {
+		docs := newBackendRepo[scopeDoc](db)
+		tenant := ulidv2.Make()
+		root, sales, east, hr := ulidv2.Make(), ulidv2.Make(), ulidv2.Make(), ulidv2.Make()
+		alice, bob := ulidv2.Make(), ulidv2.Make()
+
+		admin := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, IsAdmin: true})
+		parent := func(id ulidv2.ULID) *string { s := id.String(); return &s }
+		for _, d := range []*scopeDept{
+			{ID: root.String()},
+			{ID: sales.String(), ParentID: parent(root)},
+			{ID: east.String(), ParentID: parent(sales)},
+			{ID: hr.String(), ParentID: parent(root)},
+		} {
+			if err := depts.Create(admin, d); err != nil {
+				t.Fatalf("create dept: %v", err)
+			}
+		}
+		for _, d := range []struct {
+			dept ulidv2.ULID
+			user ulidv2.ULID
+			name string
+		}{
+			{sales, alice, "sales-alice"},
+			{sales, bob, "sales-bob"},
+			{east, alice, "east-alice"},
+			{hr, bob, "hr-bob"},
+		} {
+			dept := d.dept
+			doc := &scopeDoc{ID: ulidv2.Make().String(), DeptID: &dept, CreatedBy: d.user, Name: d.name}
+			if err := docs.Create(admin, doc); err != nil {
+				t.Fatalf("create doc: %v", err)
+			}
+		}
+
+		tree := NewDeptTree(depts, time.Minute)
+		visible := func(tc TenantContext) ([]string, error) {
+			tc.TenantID = tenant
+			ctx := WithTenantContext(WithDeptTree(context.Background(), tree), tc)
+			found, err := docs.FindByQuery(ctx, "")
+			var names []string
+			for _, d := range found {
+				names = append(names, d.Name)
+			}
+			slices.Sort(names)
+			return names, err
+		}
+		expect := func(tc TenantContext, want ...string) {
+			t.Helper()
+			got, err := visible(tc)
+			if err != nil || !slices.Equal(got, want) {
+				t.Fatalf("scope %q: got %v %v, want %v", tc.DataScope, got, err, want)
+			}
+		}
+
+		expect(TenantContext{DeptID: &sales}, "sales-alice", "sales-bob")
+		expect(TenantContext{DeptID: &sales, DataScope: DataScopeDeptAndChildren}, "east-alice", "sales-alice", "sales-bob")
+		expect(TenantContext{DeptID: &root, DataScope: DataScopeDeptAndChildren}, "east-alice", "hr-bob", "sales-alice", "sales-bob")
+		expect(TenantContext{DeptID: &sales, DataScope: DataScopeCustom, DeptIDs: []ulidv2.ULID{east, hr}}, "east-alice", "hr-bob")
+		expect(TenantContext{DeptID: &sales, DataScope: DataScopeCustom})
+		expect(TenantContext{DataScope: DataScopeSelf, UserID: alice}, "east-alice", "sales-alice")
+		expect(TenantContext{DeptID: &hr, DataScope: DataScopeAll}, "east-alice", "hr-bob", "sales-alice", "sales-bob")
+		// 全部与自定义范围不依赖本部门
+		expect(TenantContext{DataScope: DataScopeAll}, "east-alice", "hr-bob", "sales-alice", "sales-bob")
+		expect(TenantContext{DataScope: DataScopeCustom, DeptIDs: []ulidv2.ULID{hr}}, "hr-bob")
+
+		// 更新同样受数据范围限制
+		ctx := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, DeptID: &hr, DataScope: DataScopeSelf, UserID: alice})
+		all, _ := docs.FindByQuery(admin, "name = ?", "hr-bob")
+		err := docs.UpdateByID(ctx, all[0].ID, map[string]any{"name": "changed"})
+		expectCode(t, err, errors.ErrCodeNotFound)
+
+		// 未配置解析器或范围未知时拒绝访问
+		_, err = docs.FindByQuery(WithTenantContext(context.Background(),
+			TenantContext{TenantID: tenant, DeptID: &sales, DataScope: DataScopeDeptAndChildren}), "")
+		expectCode(t, err, errors.ErrCodeInternal)
+		_, err = visible(TenantContext{DeptID: &sales, DataScope: "everything"})
+		expectCode(t, err, errors.ErrCodeUnauthenticated)
+		_, err = visible(TenantContext{DataScope: DataScopeDeptAndChildren})
+		expectCode(t, err, errors.ErrCodeUnauthenticated)
+	}, &scopeDoc{})
+}
+
+// scopeNote 无部门列与归属列的租户数据
+type scopeNote struct {
+	ID       string      `gorm:"column:id;type:char(26);primaryKey"`
+	TenantID ulidv2.ULID `gorm:"column:tenant_id;type:char(26);not null"`
+	Name     string      `gorm:"column:name"`
+}
+
+func TestDataScopeSelfWithoutOwnerOrDept(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, notes Repository[scopeNote], _ *gorm.DB) {
+		tenant := ulidv2.Make()
+		admin := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, IsAdmin: true})
+		if err := notes.Create(admin, &scopeNote{ID: ulidv2.Make().String(), Name: "note"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		// 无法按本人或部门过滤时不可见任何数据，而不是退化为租户内全部
+		self := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, UserID: ulidv2.Make(), DataScope: DataScopeSelf})
+		found, err := notes.FindByQuery(self, "")
+		if err != nil || len(found) != 0 {
+			t.Fatalf("self scope without owner/dept: got %d %v, want none", len(found), err)
+		}
+		all := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, DataScope: DataScopeAll})
+		if found, err := notes.FindByQuery(all, ""); err != nil || len(found) != 1 {
+			t.Fatalf("all scope: got %d %v, want 1", len(found), err)
+		}
+	})
+}
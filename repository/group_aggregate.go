@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * Group Aggregate - 多指标分组聚合
+ * ========================================================================
+ * 职责: GROUP BY 多列 + 多个聚合指标 + HAVING + 排序 + 限制条数
+ * 安全: 分组列/指标列/别名均做白名单校验，HAVING 运算符白名单，值使用占位符
+ * 租户: 与其他查询一致，自动追加租户/部门过滤
+ *
+ * 使用示例:
+ *   rows, err := repo.Aggregate(ctx, repository.AggregateSpec{
+ *       GroupBy: []string{"status"},
+ *       Metrics: []repository.Metric{repository.Sum("amount"), repository.Count()},
+ *       Having:  []repository.Having{{Metric: repository.Sum("amount"), Op: ">", Value: 100}},
+ *       OrderBy: []string{"-sum_amount"},
+ *       Limit:   10,
+ *   })
+ *   for _, row := range rows {
+ *       fmt.Println(row.GroupString("status"), row.Value("sum_amount"), row.Value("count"))
+ *   }
+ * ======================================================================== */
+
+// Metric 聚合指标
+type Metric struct {
+	Func   string // SUM / AVG / MAX / MIN / COUNT / COUNT_DISTINCT
+	Column string // COUNT 时可为空，表示 COUNT(*)
+	Alias  string // 结果列名，默认 func_column（如 sum_amount），COUNT(*) 为 count
+}
+
+// Sum 求和指标
+func Sum(column string) Metric { return Metric{Func: "SUM", Column: column} }
+
+// Avg 平均值指标
+func Avg(column string) Metric { return Metric{Func: "AVG", Column: column} }
+
+// Max 最大值指标
+func Max(column string) Metric { return Metric{Func: "MAX", Column: column} }
+
+// Min 最小值指标
+func Min(column string) Metric { return Metric{Func: "MIN", Column: column} }
+
+// Count 计数指标 COUNT(*)
+func Count() Metric { return Metric{Func: "COUNT"} }
+
+// CountDistinct 去重计数指标
+func CountDistinct(column string) Metric { return Metric{Func: "COUNT_DISTINCT", Column: column} }
+
+// As 设置结果列名
+func (m Metric) As(alias string) Metric {
+	m.Alias = alias
+	return m
+}
+
+// name 结果列名
+func (m Metric) name() string {
+	if m.Alias != "" {
+		return m.Alias
+	}
+	if m.Column == "" {
+		return strings.ToLower(m.Func)
+	}
+	return strings.ToLower(m.Func) + "_" + strings.ReplaceAll(m.Column, ".", "_")
+}
+
+// expr 构建聚合表达式
+func (m Metric) expr() (string, error) {
+	if m.Column != "" {
+		if err := validateColumn(m.Column); err != nil {
+			return "", err
+		}
+	}
+	switch m.Func {
+	case "SUM", "AVG", "MAX", "MIN":
+		if m.Column == "" {
+			return "", errors.New(errors.ErrCodeInvalidArgument, "metric column cannot be empty").
+				WithDetail("func", m.Func)
+		}
+		return m.Func + "(" + m.Column + ")", nil
+	case "COUNT":
+		if m.Column == "" {
+			return "COUNT(*)", nil
+		}
+		return "COUNT(" + m.Column + ")", nil
+	case "COUNT_DISTINCT":
+		if m.Column == "" {
+			return "", errors.New(errors.ErrCodeInvalidArgument, "metric column cannot be empty").
+				WithDetail("func", m.Func)
+		}
+		return "COUNT(DISTINCT " + m.Column + ")", nil
+	default:
+		return "", errors.New(errors.ErrCodeInvalidArgument, "unsupported metric func: "+m.Func)
+	}
+}
+
+// Having 分组过滤条件
+type Having struct {
+	Metric Metric
+	Op     string // = / != / <> / > / >= / < / <=
+	Value  any
+}
+
+// havingOps HAVING 允许的运算符
+var havingOps = map[string]bool{"=": true, "!=": true, "<>": true, ">": true, ">=": true, "<": true, "<=": true}
+
+// AggregateSpec 分组聚合规格
+type AggregateSpec struct {
+	GroupBy []string // 分组列，可为空（整表聚合）
+	Metrics []Metric // 聚合指标，至少一个
+	Query   string   // WHERE 条件（可选）
+	Args    []any    // WHERE 参数
+	Having  []Having // HAVING 条件（AND 连接）
+	OrderBy []string // 排序，取值为分组列或指标别名，前缀 "-" 表示降序
+	Limit   int      // 返回条数，<=0 表示不限制
+}
+
+// AggregateRow 分组聚合结果行
+type AggregateRow struct {
+	Groups map[string]any     // 分组列 -> 值
+	Values map[string]float64 // 指标别名 -> 值（NULL 为 0）
+}
+
+// Group 获取分组列的值
+func (r AggregateRow) Group(column string) any {
+	return r.Groups[column]
+}
+
+// GroupString 获取分组列的字符串值
+func (r AggregateRow) GroupString(column string) string {
+	switch v := r.Groups[column].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Value 获取指标值
+func (r AggregateRow) Value(alias string) float64 {
+	return r.Values[alias]
+}
+
+// Aggregate 分组聚合查询
+func (r *RepositoryImpl[T]) Aggregate(ctx context.Context, spec AggregateSpec) ([]AggregateRow, error) {
+	selects, err := buildAggregateSelect(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.aggregateQuery(ctx, spec, selects).Rows()
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInternal, "failed to aggregate records", err)
+	}
+	defer rows.Close()
+
+	groups := len(spec.GroupBy)
+	result := make([]AggregateRow, 0)
+	for rows.Next() {
+		groupVals := make([]any, groups)
+		metricVals := make([]sql.NullFloat64, len(spec.Metrics))
+		dest := make([]any, 0, groups+len(spec.Metrics))
+		for i := range groupVals {
+			dest = append(dest, &groupVals[i])
+		}
+		for i := range metricVals {
+			dest = append(dest, &metricVals[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, errors.Wrap(errors.ErrCodeInternal, "failed to scan aggregate row", err)
+		}
+
+		row := AggregateRow{
+			Groups: make(map[string]any, groups),
+			Values: make(map[string]float64, len(spec.Metrics)),
+		}
+		for i, col := range spec.GroupBy {
+			if b, ok := groupVals[i].([]byte); ok {
+				groupVals[i] = string(b)
+			}
+			row.Groups[col] = groupVals[i]
+		}
+		for i, m := range spec.Metrics {
+			row.Values[m.name()] = metricVals[i].Float64
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInternal, "failed to aggregate records", err)
+	}
+	return result, nil
+}
+
+// AggregateInto 分组聚合查询并扫描到自定义结构体切片
+// dest 字段通过 gorm column 标签匹配分组列与指标别名
+func (r *RepositoryImpl[T]) AggregateInto(ctx context.Context, spec AggregateSpec, dest any) error {
+	selects, err := buildAggregateSelect(spec)
+	if err != nil {
+		return err
+	}
+	if err := r.aggregateQuery(ctx, spec, selects).Scan(dest).Error; err != nil {
+		return errors.Wrap(errors.ErrCodeInternal, "failed to aggregate records", err)
+	}
+	return nil
+}
+
+// buildAggregateSelect 校验规格（列名/别名/HAVING 运算符/排序列）并构建 SELECT 列表
+func buildAggregateSelect(spec AggregateSpec) (string, error) {
+	if len(spec.Metrics) == 0 {
+		return "", errors.New(errors.ErrCodeInvalidArgument, "at least one metric is required")
+	}
+
+	parts := make([]string, 0, len(spec.GroupBy)+len(spec.Metrics))
+	allowed := make(map[string]bool, cap(parts))
+	for _, col := range spec.GroupBy {
+		if err := validateColumn(col); err != nil {
+			return "", err
+		}
+		parts = append(parts, col)
+		allowed[col] = true
+	}
+	for _, m := range spec.Metrics {
+		expr, err := m.expr()
+		if err != nil {
+			return "", err
+		}
+		alias := m.name()
+		if err := validateColumn(alias); err != nil {
+			return "", err
+		}
+		parts = append(parts, expr+" AS "+alias)
+		allowed[alias] = true
+	}
+
+	for _, h := range spec.Having {
+		if _, err := h.Metric.expr(); err != nil {
+			return "", err
+		}
+		if !havingOps[h.Op] {
+			return "", errors.New(errors.ErrCodeInvalidArgument, "unsupported having operator: "+h.Op)
+		}
+	}
+	for _, o := range spec.OrderBy {
+		if col := strings.TrimPrefix(o, "-"); !allowed[col] {
+			return "", errors.New(errors.ErrCodeInvalidArgument, "invalid aggregate order column: "+col)
+		}
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// aggregateQuery 构建带租户范围的聚合查询（规格已由 buildAggregateSelect 校验）
+func (r *RepositoryImpl[T]) aggregateQuery(ctx context.Context, spec AggregateSpec, selects string) *gorm.DB {
+	db := r.applyTenantScope(ctx, r.withContext(ctx)).Model(r.newModelPtr()).Select(selects)
+
+	if spec.Query != "" {
+		db = db.Where(spec.Query, spec.Args...)
+	}
+	if len(spec.GroupBy) > 0 {
+		db = db.Group(strings.Join(spec.GroupBy, ", "))
+	}
+
+	for _, h := range spec.Having {
+		expr, _ := h.Metric.expr()
+		db = db.Having(expr+" "+h.Op+" ?", h.Value)
+	}
+	for _, o := range spec.OrderBy {
+		if col, ok := strings.CutPrefix(o, "-"); ok {
+			db = db.Order(col + " DESC")
+		} else {
+			db = db.Order(o + " ASC")
+		}
+	}
+
+	if spec.Limit > 0 {
+		db = db.Limit(spec.Limit)
+	}
+	return db
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+)
+
+func TestAggregateGroupByWithHaving(t *testing.T) {
+	db := openAggregateTestDB(t)
+	repo := NewRepository[tenantAggregateTestModel](db)
+
+	tenantA := ulidv2.Make()
+	ctxA := WithTenantContext(context.Background(), TenantContext{TenantID: tenantA, IsAdmin: true})
+	ctxB := WithTenantContext(context.Background(), TenantContext{TenantID: ulidv2.Make(), IsAdmin: true})
+
+	seed := []struct {
+		ctx    context.Context
+		status string
+		amount float64
+	}{
+		{ctxA, "paid", 100}, {ctxA, "paid", 50}, {ctxA, "pending", 30},
+		{ctxA, "refunded", 500}, {ctxB, "paid", 1000},
+	}
+	for _, s := range seed {
+		m := &tenantAggregateTestModel{ID: ulidv2.Make().String(), Status: s.status, Amount: s.amount}
+		if err := repo.Create(s.ctx, m); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	spec := AggregateSpec{
+		GroupBy: []string{"status"},
+		Metrics: []Metric{Sum("amount"), Count(), Max("amount").As("top")},
+		Query:   "status <> ?",
+		Args:    []any{"refunded"},
+		Having:  []Having{{Metric: Sum("amount"), Op: ">=", Value: 30}},
+		OrderBy: []string{"-sum_amount"},
+	}
+	rows, err := repo.Aggregate(ctxA, spec)
+	if err != nil {
+		t.Fatalf("aggregate: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 groups, got %+v", rows)
+	}
+	if rows[0].GroupString("status") != "paid" || rows[0].Value("sum_amount") != 150 ||
+		rows[0].Value("count") != 2 || rows[0].Value("top") != 100 {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].GroupString("status") != "pending" || rows[1].Value("sum_amount") != 30 {
+		t.Fatalf("unexpected second row: %+v", rows[1])
+	}
+
+	spec.Having[0].Value = 100
+	spec.Limit = 1
+	var typed []struct {
+		Status    string  `gorm:"column:status"`
+		SumAmount float64 `gorm:"column:sum_amount"`
+		Count     int64   `gorm:"column:count"`
+	}
+	if err := repo.AggregateInto(ctxA, spec, &typed); err != nil {
+		t.Fatalf("aggregate into: %v", err)
+	}
+	if len(typed) != 1 || typed[0].Status != "paid" || typed[0].SumAmount != 150 || typed[0].Count != 2 {
+		t.Fatalf("unexpected typed rows: %+v", typed)
+	}
+}
+
+func TestAggregateRejectsUnsafeSpec(t *testing.T) {
+	db := openAggregateTestDB(t)
+	repo := NewRepository[tenantAggregateTestModel](db)
+	ctx := WithTenantContext(context.Background(), TenantContext{TenantID: ulidv2.Make(), IsAdmin: true})
+
+	cases := []AggregateSpec{
+		{},
+		{GroupBy: []string{"status; DROP TABLE x"}, Metrics: []Metric{Count()}},
+		{Metrics: []Metric{Sum("")}},
+		{Metrics: []Metric{Count().As("n) --")}},
+		{Metrics: []Metric{Count()}, Having: []Having{{Metric: Count(), Op: "OR 1=1 --"}}},
+		{Metrics: []Metric{Count()}, OrderBy: []string{"amount"}},
+	}
+	for i, spec := range cases {
+		_, err := repo.Aggregate(ctx, spec)
+		if errors.Code(err) != errors.ErrCodeInvalidArgument {
+			t.Fatalf("case %d: expected invalid argument, got %v", i, err)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"sync"
+)
+
+/* ========================================================================
+ * Repository Hooks - 仓储生命周期钩子
+ * ========================================================================
+ * 职责: 在 CRUD 操作前后回调，用于发布领域事件、维护搜索索引、失效缓存
+ * 两种方式:
+ *   - 仓储级注册: repo.OnAfterCreate(fn) 等
+ *   - 模型级接口: 模型实现 BeforeCreateHook / AfterCreateHook 等
+ * 触发点:
+ *   - Create / CreateBatch: create 钩子（逐条）
+ *   - Update / UpdateByID: update 钩子（UpdateByID 在注册了钩子时加载更新前/后的记录）
+ *   - Delete / DeleteBatch / HardDelete: delete 钩子（按 ID）
+ * 语义:
+ *   - 钩子使用操作的 ctx 执行，位于 TxManager/Execute 事务内时与操作同事务
+ *   - before 钩子返回错误时中止操作；after 钩子返回错误时原样返回（事务内将回滚）
+ *   - 执行顺序: 模型级接口 -> 仓储级钩子（按注册顺序）
+ *
+ * 使用示例:
+ *   repo.OnAfterCreate(func(ctx context.Context, u *User) error {
+ *       return outbox.Publish(ctx, UserCreated{ID: u.ID})
+ *   })
+ *   repo.OnAfterDelete(func(ctx context.Context, ids []string) error {
+ *       return cache.Del(ctx, userKeys(ids)...)
+ *   })
+ * ======================================================================== */
+
+// HookFunc 模型钩子
+type HookFunc[T any] func(ctx context.Context, model *T) error
+
+// DeleteHookFunc 删除钩子（按 ID）
+type DeleteHookFunc func(ctx context.Context, ids []string) error
+
+// BeforeCreateHook 模型级创建前钩子
+type BeforeCreateHook interface {
+	OnBeforeCreate(ctx context.Context) error
+}
+
+// AfterCreateHook 模型级创建后钩子
+type AfterCreateHook interface {
+	OnAfterCreate(ctx context.Context) error
+}
+
+// BeforeUpdateHook 模型级更新前钩子
+type BeforeUpdateHook interface {
+	OnBeforeUpdate(ctx context.Context) error
+}
+
+// AfterUpdateHook 模型级更新后钩子
+type AfterUpdateHook interface {
+	OnAfterUpdate(ctx context.Context) error
+}
+
+// HookRepository 钩子注册接口
+type HookRepository[T any] interface {
+	OnBeforeCreate(fn HookFunc[T])
+	OnAfterCreate(fn HookFunc[T])
+	OnBeforeUpdate(fn HookFunc[T])
+	OnAfterUpdate(fn HookFunc[T])
+	OnBeforeDelete(fn DeleteHookFunc)
+	OnAfterDelete(fn DeleteHookFunc)
+}
+
+// repoHooks 仓储级钩子集合（WithTx 派生的仓储共享同一集合）
+type repoHooks[T any] struct {
+	mu           sync.RWMutex
+	beforeCreate []HookFunc[T]
+	afterCreate  []HookFunc[T]
+	beforeUpdate []HookFunc[T]
+	afterUpdate  []HookFunc[T]
+	beforeDelete []DeleteHookFunc
+	afterDelete  []DeleteHookFunc
+}
+
+func (h *repoHooks[T]) add(fn func(h *repoHooks[T])) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fn(h)
+}
+
+// snapshot 读取钩子列表
+func (h *repoHooks[T]) snapshot(get func(h *repoHooks[T]) []HookFunc[T]) []HookFunc[T] {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return get(h)
+}
+
+func (h *repoHooks[T]) deleteSnapshot(get func(h *repoHooks[T]) []DeleteHookFunc) []DeleteHookFunc {
+	if h == nil {
+		return nil
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return get(h)
+}
+
+// OnBeforeCreate 注册创建前钩子
+func (r *RepositoryImpl[T]) OnBeforeCreate(fn HookFunc[T]) {
+	r.hooks.add(func(h *repoHooks[T]) { h.beforeCreate = append(h.beforeCreate, fn) })
+}
+
+// OnAfterCreate 注册创建后钩子
+func (r *RepositoryImpl[T]) OnAfterCreate(fn HookFunc[T]) {
+	r.hooks.add(func(h *repoHooks[T]) { h.afterCreate = append(h.afterCreate, fn) })
+}
+
+// OnBeforeUpdate 注册更新前钩子
+func (r *RepositoryImpl[T]) OnBeforeUpdate(fn HookFunc[T]) {
+	r.hooks.add(func(h *repoHooks[T]) { h.beforeUpdate = append(h.beforeUpdate, fn) })
+}
+
+// OnAfterUpdate 注册更新后钩子
+func (r *RepositoryImpl[T]) OnAfterUpdate(fn HookFunc[T]) {
+	r.hooks.add(func(h *repoHooks[T]) { h.afterUpdate = append(h.afterUpdate, fn) })
+}
+
+// OnBeforeDelete 注册删除前钩子
+func (r *RepositoryImpl[T]) OnBeforeDelete(fn DeleteHookFunc) {
+	r.hooks.add(func(h *repoHooks[T]) { h.beforeDelete = append(h.beforeDelete, fn) })
+}
+
+// OnAfterDelete 注册删除后钩子
+func (r *RepositoryImpl[T]) OnAfterDelete(fn DeleteHookFunc) {
+	r.hooks.add(func(h *repoHooks[T]) { h.afterDelete = append(h.afterDelete, fn) })
+}
+
+// runBeforeCreate 执行创建前钩子
+func (r *RepositoryImpl[T]) runBeforeCreate(ctx context.Context, model *T) error {
+	if h, ok := any(model).(BeforeCreateHook); ok {
+		if err := h.OnBeforeCreate(ctx); err != nil {
+			return err
+		}
+	}
+	return runHooks(ctx, model, r.hooks.snapshot(func(h *repoHooks[T]) []HookFunc[T] { return h.beforeCreate }))
+}
+
+// runAfterCreate 执行创建后钩子
+func (r *RepositoryImpl[T]) runAfterCreate(ctx context.Context, model *T) error {
+	if h, ok := any(model).(AfterCreateHook); ok {
+		if err := h.OnAfterCreate(ctx); err != nil {
+			return err
+		}
+	}
+	return runHooks(ctx, model, r.hooks.snapshot(func(h *repoHooks[T]) []HookFunc[T] { return h.afterCreate }))
+}
+
+// runBeforeUpdate 执行更新前钩子
+func (r *RepositoryImpl[T]) runBeforeUpdate(ctx context.Context, model *T) error {
+	if h, ok := any(model).(BeforeUpdateHook); ok {
+		if err := h.OnBeforeUpdate(ctx); err != nil {
+			return err
+		}
+	}
+	return runHooks(ctx, model, r.hooks.snapshot(func(h *repoHooks[T]) []HookFunc[T] { return h.beforeUpdate }))
+}
+
+// runAfterUpdate 执行更新后钩子
+func (r *RepositoryImpl[T]) runAfterUpdate(ctx context.Context, model *T) error {
+	if h, ok := any(model).(AfterUpdateHook); ok {
+		if err := h.OnAfterUpdate(ctx); err != nil {
+			return err
+		}
+	}
+	return runHooks(ctx, model, r.hooks.snapshot(func(h *repoHooks[T]) []HookFunc[T] { return h.afterUpdate }))
+}
+
+// hasUpdateHooks 是否需要为 UpdateByID 加载记录
+func (r *RepositoryImpl[T]) hasUpdateHooks() (before, after bool) {
+	var model T
+	_, modelBefore := any(&model).(BeforeUpdateHook)
+	_, modelAfter := any(&model).(AfterUpdateHook)
+	before = modelBefore || len(r.hooks.snapshot(func(h *repoHooks[T]) []HookFunc[T] { return h.beforeUpdate })) > 0
+	after = modelAfter || len(r.hooks.snapshot(func(h *repoHooks[T]) []HookFunc[T] { return h.afterUpdate })) > 0
+	return before, after
+}
+
+// runBeforeDelete 执行删除前钩子
+func (r *RepositoryImpl[T]) runBeforeDelete(ctx context.Context, ids []string) error {
+	return runDeleteHooks(ctx, ids, r.hooks.deleteSnapshot(func(h *repoHooks[T]) []DeleteHookFunc { return h.beforeDelete }))
+}
+
+// runAfterDelete 执行删除后钩子
+func (r *RepositoryImpl[T]) runAfterDelete(ctx context.Context, ids []string) error {
+	return runDeleteHooks(ctx, ids, r.hooks.deleteSnapshot(func(h *repoHooks[T]) []DeleteHookFunc { return h.afterDelete }))
+}
+
+func runHooks[T any](ctx context.Context, model *T, hooks []HookFunc[T]) error {
+	for _, fn := range hooks {
+		if err := fn(ctx, model); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runDeleteHooks(ctx context.Context, ids []string, hooks []DeleteHookFunc) error {
+	for _, fn := range hooks {
+		if err := fn(ctx, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+)
+
+type hookTestModel struct {
+	ID   string `gorm:"column:id;type:char(26);primaryKey"`
+	Name string `gorm:"column:name"`
+
+	events *[]string `gorm:"-"`
+}
+
+func (hookTestModel) TenantIgnored() bool {
+	return true
+}
+
+func (m *hookTestModel) OnBeforeCreate(ctx context.Context) error {
+	if m.Name == "" {
+		return errors.New("name required")
+	}
+	if m.events != nil {
+		*m.events = append(*m.events, "model:before_create")
+	}
+	return nil
+}
+
+func TestRepositoryHooks(t *testing.T) {
+	db := openTxTestDB(t)
+	if err := db.AutoMigrate(&hookTestModel{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewRepository[hookTestModel](db)
+	ctx := context.Background()
+
+	var events []string
+	repo.OnAfterCreate(func(ctx context.Context, m *hookTestModel) error {
+		events = append(events, "after_create:"+m.Name)
+		return nil
+	})
+	repo.OnBeforeUpdate(func(ctx context.Context, m *hookTestModel) error {
+		events = append(events, "before_update:"+m.Name)
+		return nil
+	})
+	repo.OnAfterUpdate(func(ctx context.Context, m *hookTestModel) error {
+		events = append(events, "after_update:"+m.Name)
+		return nil
+	})
+	repo.OnAfterDelete(func(ctx context.Context, ids []string) error {
+		events = append(events, "after_delete")
+		return nil
+	})
+
+	id := ulidv2.Make().String()
+	if err := repo.Create(ctx, &hookTestModel{ID: id, Name: "a", events: &events}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Create(ctx, &hookTestModel{ID: ulidv2.Make().String()}); err == nil {
+		t.Fatalf("expected model-level before hook to abort create")
+	}
+	if err := repo.UpdateByID(ctx, id, map[string]any{"name": "b"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.Delete(ctx, id); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	want := []string{"model:before_create", "after_create:a", "before_update:a", "after_update:b", "after_delete"}
+	if !slices.Equal(events, want) {
+		t.Fatalf("unexpected hook order:\n got %v\nwant %v", events, want)
+	}
+}
+
+func TestAfterHookErrorRollsBackTx(t *testing.T) {
+	db := openTxTestDB(t)
+	repo := NewRepository[txTestModel](db)
+	boom := errors.New("index unavailable")
+	repo.OnAfterCreate(func(ctx context.Context, m *txTestModel) error {
+		return boom
+	})
+
+	err := NewTxManager(db).RunInTx(context.Background(), func(ctx context.Context) error {
+		// WithTx 派生的仓储共享钩子
+		return repo.WithTx(DBFromContext(ctx, db)).Create(ctx, &txTestModel{ID: ulidv2.Make().String(), Name: "x"})
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected hook error, got %v", err)
+	}
+	if n := countTxModels(t, db); n != 0 {
+		t.Fatalf("expected rollback, got %d rows", n)
+	}
+}
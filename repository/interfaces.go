@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -74,13 +75,39 @@ func ApplyOptions(opts []Option) *QueryOption {
 	return o
 }
 
+// 分页参数默认值与上限
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 1000
+)
+
+// PageRequest 分页请求参数
+type PageRequest struct {
+	Page     int `json:"page" doc:"页码（从 1 开始）"`
+	PageSize int `json:"page_size" doc:"每页大小"`
+}
+
+// Normalize 修正越界的分页参数（与 FindPage 的规则一致）
+func (p PageRequest) Normalize() PageRequest {
+	if p.Page < 1 {
+		p.Page = 1
+	}
+	if p.PageSize < 1 {
+		p.PageSize = DefaultPageSize
+	}
+	if p.PageSize > MaxPageSize {
+		p.PageSize = MaxPageSize
+	}
+	return p
+}
+
 // PageResult 分页结果
 type PageResult[T any] struct {
-	List     []T   `json:"list" doc:"数据列表"`
-	Total    int64 `json:"total" doc:"总记录数"`
-	Page     int   `json:"page" doc:"当前页码"`
-	PageSize int   `json:"page_size" doc:"每页大小"`
-	Pages    int64 `json:"pages" doc:"总页数"`
+	List     []T   `json:"list" msgpack:"list" doc:"数据列表"`
+	Total    int64 `json:"total" msgpack:"total" doc:"总记录数"`
+	Page     int   `json:"page" msgpack:"page" doc:"当前页码"`
+	PageSize int   `json:"page_size" msgpack:"page_size" doc:"每页大小"`
+	Pages    int64 `json:"pages" msgpack:"pages" doc:"总页数"`
 }
 
 // CRUDRepository CRUD 操作接口
@@ -98,8 +125,8 @@ type CRUDRepository[T any] interface {
 	// UpdateByID 根据 ID 更新指定字段
 	UpdateByID(ctx context.Context, id string, updates map[string]any, allowedFields ...string) error
 
-	// UpsertBatch 批量更新或插入记录 (Upsert)
-	UpsertBatch(ctx context.Context, models []*T) error
+	// UpsertBatch 批量更新或插入记录 (Upsert)，可指定冲突列与更新列
+	UpsertBatch(ctx context.Context, models []*T, opts ...UpsertOption) error
 
 	// Delete 软删除记录（设置 deleted_at）
 	Delete(ctx context.Context, id string) error
@@ -109,6 +136,9 @@ type CRUDRepository[T any] interface {
 
 	// HardDelete 硬删除记录（从数据库移除）
 	HardDelete(ctx context.Context, id string) error
+
+	// DeleteByKey 根据复合主键或唯一键软删除记录
+	DeleteByKey(ctx context.Context, keys map[string]any) error
 }
 
 // QueryRepository 查询操作接口
@@ -119,6 +149,9 @@ type QueryRepository[T any] interface {
 	// FindByIDs 根据 ID 列表查找多条记录
 	FindByIDs(ctx context.Context, ids []string, opts ...Option) ([]*T, error)
 
+	// FindByKey 根据复合主键或唯一键查找记录（keys: 列名 -> 值）
+	FindByKey(ctx context.Context, keys map[string]any, opts ...Option) (*T, error)
+
 	// FindOne 查找单条记录（使用自定义条件）
 	FindOne(ctx context.Context, query string, args ...any) (*T, error)
 
@@ -147,6 +180,18 @@ type PageRepository[T any] interface {
 	FindPageWithOpts(ctx context.Context, page, pageSize int, query string, opts []Option, args ...any) (*PageResult[T], error)
 }
 
+// SpecificationRepository 规约查询接口
+type SpecificationRepository[T any] interface {
+	// FindBySpec 按规约查询多条记录
+	FindBySpec(ctx context.Context, spec Specification[T], opts ...Option) ([]*T, error)
+
+	// CountBySpec 按规约统计记录数
+	CountBySpec(ctx context.Context, spec Specification[T]) (int64, error)
+
+	// PageBySpec 按规约分页查询
+	PageBySpec(ctx context.Context, page PageRequest, spec Specification[T], opts ...Option) (*PageResult[T], error)
+}
+
 // AggregateRepository 聚合查询接口
 type AggregateRepository[T any] interface {
 	// Sum 求和
@@ -160,6 +205,18 @@ type AggregateRepository[T any] interface {
 
 	// Min 最小值
 	Min(ctx context.Context, column string, query string, args ...any) (any, error)
+
+	// Aggregate 多指标分组聚合（GROUP BY + HAVING + 排序 + 限制）
+	Aggregate(ctx context.Context, spec AggregateSpec) ([]AggregateRow, error)
+
+	// AggregateInto 多指标分组聚合并扫描到自定义结构体切片
+	AggregateInto(ctx context.Context, spec AggregateSpec, dest any) error
+
+	// SumByTimeBucket 按时间分桶求和（小时/天/周/月时间序列）
+	SumByTimeBucket(ctx context.Context, column string, interval TimeBucket, rangeStart, rangeEnd time.Time, opts ...BucketOption) ([]TimeBucketPoint, error)
+
+	// AggregateByTimeBucket 按时间分桶计算任意指标
+	AggregateByTimeBucket(ctx context.Context, metric Metric, interval TimeBucket, rangeStart, rangeEnd time.Time, opts ...BucketOption) ([]TimeBucketPoint, error)
 }
 
 // TransactionRepository 事务支持接口
@@ -180,8 +237,10 @@ type Repository[T any] interface {
 	CRUDRepository[T]
 	QueryRepository[T]
 	PageRepository[T]
+	SpecificationRepository[T]
 	AggregateRepository[T]
 	TransactionRepository[T]
+	HookRepository[T]
 
 	// GetDB 获取底层 GORM DB 实例（用于复杂查询）
 	GetDB() *gorm.DB
@@ -0,0 +1,324 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+/* ========================================================================
+ * Composite Keys - 复合主键 / 唯一键
+ * ========================================================================
+ * 职责: 按复合主键（如 tenant_id + code）或复合唯一索引定位记录，无需退回 GetDB()
+ * 语义:
+ *   - keys 的键为列名或结构体字段名，必须恰好覆盖主键或某个唯一索引的全部列，
+ *     避免 DeleteByKey 误删多条记录
+ *   - 租户/部门范围照常生效
+ *   - DeleteByKey 的删除钩子 ids：模型有单一主键时为记录主键，否则为 "col=value" 形式的键描述
+ *   - UpsertBatch 默认按主键冲突；WithConflictColumns 指定冲突列（需有对应唯一约束），
+ *     WithUpdateColumns 限定冲突时更新的列（autoUpdateTime 字段始终更新）
+ *
+ * 使用示例:
+ *   type Dict struct {
+ *       TenantID ulid.ULID `gorm:"column:tenant_id;type:char(26);primaryKey"`
+ *       Code     string    `gorm:"column:code;primaryKey"`
+ *       Label    string    `gorm:"column:label"`
+ *   }
+ *
+ *   dict, err := repo.FindByKey(ctx, map[string]any{"tenant_id": tenantID, "code": "gender"})
+ *   err = repo.DeleteByKey(ctx, map[string]any{"tenant_id": tenantID, "code": "gender"})
+ *   err = repo.UpsertBatch(ctx, dicts,
+ *       repository.WithConflictColumns("tenant_id", "code"),
+ *       repository.WithUpdateColumns("label"))
+ * ======================================================================== */
+
+// UpsertOptions Upsert 冲突处理参数
+type UpsertOptions struct {
+	// ConflictColumns 冲突判定列，空值表示主键
+	ConflictColumns []string
+	// UpdateColumns 冲突时更新的列，空值表示除主键与创建时间外的所有列
+	UpdateColumns []string
+}
+
+// UpsertOption Upsert 选项
+type UpsertOption func(*UpsertOptions)
+
+// WithConflictColumns 指定冲突判定列（列名或字段名）
+func WithConflictColumns(columns ...string) UpsertOption {
+	return func(o *UpsertOptions) {
+		o.ConflictColumns = append(o.ConflictColumns, columns...)
+	}
+}
+
+// WithUpdateColumns 限定冲突时更新的列（列名或字段名）
+func WithUpdateColumns(columns ...string) UpsertOption {
+	return func(o *UpsertOptions) {
+		o.UpdateColumns = append(o.UpdateColumns, columns...)
+	}
+}
+
+// ApplyUpsertOptions 应用 Upsert 选项
+func ApplyUpsertOptions(opts []UpsertOption) *UpsertOptions {
+	o := &UpsertOptions{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	return o
+}
+
+// uniqueConstraints 唯一约束（主键 + 唯一索引 + unique 字段）
+func uniqueConstraints(s *schema.Schema) [][]*schema.Field {
+	var out [][]*schema.Field
+	if len(s.PrimaryFields) > 0 {
+		out = append(out, s.PrimaryFields)
+	}
+	for _, idx := range s.ParseIndexes() {
+		if idx.Class != "UNIQUE" {
+			continue
+		}
+		fields := make([]*schema.Field, 0, len(idx.Fields))
+		for _, opt := range idx.Fields {
+			if opt.Field != nil {
+				fields = append(fields, opt.Field)
+			}
+		}
+		if len(fields) > 0 {
+			out = append(out, fields)
+		}
+	}
+	for _, field := range s.Fields {
+		if field.Unique && !field.PrimaryKey {
+			out = append(out, []*schema.Field{field})
+		}
+	}
+	return out
+}
+
+// lookupFields 将列名或字段名解析为字段（去重，保持顺序）
+func lookupFields(s *schema.Schema, columns []string) ([]*schema.Field, error) {
+	fields := make([]*schema.Field, 0, len(columns))
+	for _, column := range columns {
+		field := s.LookUpField(column)
+		if field == nil || field.DBName == "" {
+			return nil, errors.New(errors.ErrCodeInvalidArgument, "unknown column: "+column)
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// matchConstraint 返回与 fields 恰好一致的唯一约束，不存在时返回 InvalidArgument
+func matchConstraint(s *schema.Schema, fields []*schema.Field) ([]*schema.Field, error) {
+	for _, constraint := range uniqueConstraints(s) {
+		if len(constraint) != len(fields) {
+			continue
+		}
+		if !slices.ContainsFunc(constraint, func(f *schema.Field) bool { return !slices.Contains(fields, f) }) {
+			return constraint, nil
+		}
+	}
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.DBName
+	}
+	return nil, errors.New(errors.ErrCodeInvalidArgument, "columns do not form a primary key or unique index: "+strings.Join(names, ", "))
+}
+
+// resolveKeys 校验 keys 并转换为按列名的查询条件
+func (r *RepositoryImpl[T]) resolveKeys(keys map[string]any) (map[string]any, error) {
+	if len(keys) == 0 {
+		return nil, errors.ErrInvalidArgument
+	}
+	s, err := r.getSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]string, 0, len(keys))
+	for column := range keys {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+	fields, err := lookupFields(s, columns)
+	if err != nil {
+		return nil, err
+	}
+	if len(fields) != len(keys) {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "duplicate key column")
+	}
+	if _, err := matchConstraint(s, fields); err != nil {
+		return nil, err
+	}
+
+	conds := make(map[string]any, len(keys))
+	for i, column := range columns {
+		conds[fields[i].DBName] = keys[column]
+	}
+	return conds, nil
+}
+
+// keyString 键描述（按列名排序的 col=value，以逗号分隔）
+func keyString(conds map[string]any) string {
+	columns := make([]string, 0, len(conds))
+	for column := range conds {
+		columns = append(columns, column)
+	}
+	slices.Sort(columns)
+	parts := make([]string, len(columns))
+	for i, column := range columns {
+		parts[i] = fmt.Sprintf("%s=%v", column, conds[column])
+	}
+	return strings.Join(parts, ",")
+}
+
+// hasDeleteHooks 是否注册了删除钩子
+func (r *RepositoryImpl[T]) hasDeleteHooks() bool {
+	return len(r.hooks.deleteSnapshot(func(h *repoHooks[T]) []DeleteHookFunc { return h.beforeDelete })) > 0 ||
+		len(r.hooks.deleteSnapshot(func(h *repoHooks[T]) []DeleteHookFunc { return h.afterDelete })) > 0
+}
+
+// keyHookIDs DeleteByKey 传给删除钩子的 ids
+func (r *RepositoryImpl[T]) keyHookIDs(ctx context.Context, model *T, conds map[string]any) []string {
+	s, err := r.getSchema()
+	if err == nil && len(s.PrimaryFields) == 1 && model != nil {
+		if v, zero := s.PrimaryFields[0].ValueOf(ctx, reflect.ValueOf(model)); !zero {
+			return []string{fmt.Sprint(v)}
+		}
+	}
+	return []string{keyString(conds)}
+}
+
+// upsertClause 构建 ON CONFLICT 子句
+func (r *RepositoryImpl[T]) upsertClause(opts *UpsertOptions) (clause.OnConflict, error) {
+	if len(opts.ConflictColumns) == 0 && len(opts.UpdateColumns) == 0 {
+		return clause.OnConflict{UpdateAll: true}, nil
+	}
+	s, err := r.getSchema()
+	if err != nil {
+		return clause.OnConflict{}, err
+	}
+
+	fields, err := r.conflictFields(s, opts.ConflictColumns)
+	if err != nil {
+		return clause.OnConflict{}, err
+	}
+	var onConflict clause.OnConflict
+	for _, f := range fields {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: f.DBName})
+	}
+	if len(opts.UpdateColumns) == 0 {
+		onConflict.UpdateAll = true
+		return onConflict, nil
+	}
+
+	fields, err = r.upsertUpdateFields(s, opts.UpdateColumns)
+	if err != nil {
+		return clause.OnConflict{}, err
+	}
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = f.DBName
+	}
+	onConflict.DoUpdates = clause.AssignmentColumns(columns)
+	onConflict.DoNothing = len(columns) == 0
+	return onConflict, nil
+}
+
+// conflictFields 冲突判定字段，未指定时为主键
+func (r *RepositoryImpl[T]) conflictFields(s *schema.Schema, columns []string) ([]*schema.Field, error) {
+	if len(columns) == 0 {
+		if len(s.PrimaryFields) == 0 {
+			return nil, errors.New(errors.ErrCodeInvalidArgument, "model has no primary key")
+		}
+		return s.PrimaryFields, nil
+	}
+	fields, err := lookupFields(s, columns)
+	if err != nil {
+		return nil, err
+	}
+	return matchConstraint(s, fields)
+}
+
+// upsertUpdateFields 冲突时更新的字段（忽略租户/部门列，附加 autoUpdateTime 字段，拒绝主键）
+func (r *RepositoryImpl[T]) upsertUpdateFields(s *schema.Schema, columns []string) ([]*schema.Field, error) {
+	fields, err := lookupFields(s, columns)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if f.PrimaryKey {
+			return nil, errors.New(errors.ErrCodeInvalidArgument, "cannot update primary key column: "+f.DBName)
+		}
+	}
+	fields = slices.DeleteFunc(fields, func(f *schema.Field) bool {
+		return f.DBName == tenantColumn || f.DBName == deptColumn || f.DBName == createdByColumn
+	})
+	for _, f := range s.Fields {
+		if f.AutoUpdateTime > 0 && f.DBName != "" && !slices.Contains(fields, f) {
+			fields = append(fields, f)
+		}
+	}
+	return r.appendAuditFields(s, fields), nil
+}
+
+/* ========================================================================
+ * FindByKey / DeleteByKey
+ * ======================================================================== */
+
+// FindByKey 根据复合主键或唯一键查找记录
+func (r *RepositoryImpl[T]) FindByKey(ctx context.Context, keys map[string]any, opts ...Option) (*T, error) {
+	conds, err := r.resolveKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	model := r.newModelPtr()
+	query := r.buildQuery(ctx, ApplyOptions(opts))
+	if err := query.Where(conds).First(model).Error; err != nil {
+		return nil, errors.FromGORM(err)
+	}
+	return model, nil
+}
+
+// DeleteByKey 根据复合主键或唯一键软删除记录
+func (r *RepositoryImpl[T]) DeleteByKey(ctx context.Context, keys map[string]any) error {
+	conds, err := r.resolveKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	// 注册了删除钩子时先加载记录以确定 ids
+	ids := []string{keyString(conds)}
+	if r.hasDeleteHooks() {
+		current, err := r.FindByKey(ctx, keys)
+		if err != nil {
+			return err
+		}
+		ids = r.keyHookIDs(ctx, current, conds)
+	}
+	if err := r.runBeforeDelete(ctx, ids); err != nil {
+		return err
+	}
+
+	result := r.applyTenantScope(ctx, r.withContext(ctx)).Where(conds).Delete(r.newModelPtr())
+	if result.Error != nil {
+		return errors.FromGORM(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return errors.FromGORM(gorm.ErrRecordNotFound)
+	}
+
+	return r.runAfterDelete(ctx, ids)
+}
@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/plugin/soft_delete"
+)
+
+// dictModel 复合主键（tenant_id + code）模型
+type dictModel struct {
+	TenantID ulidv2.ULID           `gorm:"column:tenant_id;type:char(26);primaryKey"`
+	Code     string                `gorm:"column:code;primaryKey"`
+	Label    string                `gorm:"column:label"`
+	Sort     int                   `gorm:"column:sort"`
+	Deleted  soft_delete.DeletedAt `gorm:"column:deleted;default:0;softDelete:flag"`
+}
+
+func runCompositeKeyConformance(t *testing.T, newRepo func(t *testing.T) Repository[dictModel]) {
+	t.Run("FindAndDelete", func(t *testing.T) {
+		repo := newRepo(t)
+		tenant, other := ulidv2.Make(), ulidv2.Make()
+		ctx := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, IsAdmin: true})
+		otherCtx := WithTenantContext(context.Background(), TenantContext{TenantID: other, IsAdmin: true})
+
+		for _, c := range []struct {
+			ctx   context.Context
+			model *dictModel
+		}{
+			{ctx, &dictModel{Code: "gender", Label: "Gender"}},
+			{ctx, &dictModel{Code: "status", Label: "Status"}},
+			{otherCtx, &dictModel{Code: "gender", Label: "Other Gender"}},
+		} {
+			if err := repo.Create(c.ctx, c.model); err != nil {
+				t.Fatalf("create: %v", err)
+			}
+		}
+
+		got, err := repo.FindByKey(ctx, map[string]any{"tenant_id": tenant, "Code": "gender"})
+		if err != nil || got.Label != "Gender" {
+			t.Fatalf("find by key: %+v %v", got, err)
+		}
+		// 租户范围照常生效
+		_, err = repo.FindByKey(otherCtx, map[string]any{"tenant_id": tenant, "code": "gender"})
+		expectCode(t, err, errors.ErrCodeNotFound)
+
+		// 键必须恰好覆盖主键或唯一索引
+		_, err = repo.FindByKey(ctx, map[string]any{"code": "gender"})
+		expectCode(t, err, errors.ErrCodeInvalidArgument)
+		_, err = repo.FindByKey(ctx, map[string]any{"tenant_id": tenant, "code": "gender", "label": "Gender"})
+		expectCode(t, err, errors.ErrCodeInvalidArgument)
+		_, err = repo.FindByKey(ctx, map[string]any{"tenant_id": tenant, "code; drop": "x"})
+		expectCode(t, err, errors.ErrCodeInvalidArgument)
+		expectCode(t, repo.DeleteByKey(ctx, nil), errors.ErrCodeInvalidArgument)
+
+		var deleted []string
+		repo.OnAfterDelete(func(_ context.Context, ids []string) error {
+			deleted = append(deleted, ids...)
+			return nil
+		})
+		if err := repo.DeleteByKey(ctx, map[string]any{"tenant_id": tenant, "code": "gender"}); err != nil {
+			t.Fatalf("delete by key: %v", err)
+		}
+		if want := []string{"code=gender,tenant_id=" + tenant.String()}; !slices.Equal(deleted, want) {
+			t.Fatalf("unexpected hook ids: %v", deleted)
+		}
+		_, err = repo.FindByKey(ctx, map[string]any{"tenant_id": tenant, "code": "gender"})
+		expectCode(t, err, errors.ErrCodeNotFound)
+		expectCode(t, repo.DeleteByKey(ctx, map[string]any{"tenant_id": tenant, "code": "gender"}), errors.ErrCodeNotFound)
+		if got, err := repo.FindByKey(otherCtx, map[string]any{"tenant_id": other, "code": "gender"}); err != nil || got.Label != "Other Gender" {
+			t.Fatalf("other tenant record must survive: %+v %v", got, err)
+		}
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		repo := newRepo(t)
+		tenant := ulidv2.Make()
+		ctx := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, IsAdmin: true})
+
+		if err := repo.Create(ctx, &dictModel{Code: "gender", Label: "Gender", Sort: 1}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		err := repo.UpsertBatch(ctx, []*dictModel{
+			{Code: "gender", Label: "Sex", Sort: 9},
+			{Code: "status", Label: "Status", Sort: 2},
+		}, WithConflictColumns("tenant_id", "code"), WithUpdateColumns("label"))
+		if err != nil {
+			t.Fatalf("upsert: %v", err)
+		}
+
+		got, err := repo.FindByKey(ctx, map[string]any{"tenant_id": tenant, "code": "gender"})
+		if err != nil || got.Label != "Sex" || got.Sort != 1 {
+			t.Fatalf("expected only label to be updated: %+v %v", got, err)
+		}
+		if got, err := repo.FindByKey(ctx, map[string]any{"tenant_id": tenant, "code": "status"}); err != nil || got.Sort != 2 {
+			t.Fatalf("expected status to be inserted: %+v %v", got, err)
+		}
+
+		// 默认按完整主键冲突并更新全部列
+		if err := repo.UpsertBatch(ctx, []*dictModel{{Code: "gender", Label: "Gender", Sort: 5}}); err != nil {
+			t.Fatalf("upsert by primary key: %v", err)
+		}
+		if got, _ := repo.FindByKey(ctx, map[string]any{"tenant_id": tenant, "code": "gender"}); got == nil || got.Sort != 5 || got.Label != "Gender" {
+			t.Fatalf("unexpected record after default upsert: %+v", got)
+		}
+		if n, _ := repo.Count(ctx, ""); n != 2 {
+			t.Fatalf("expected 2 records, got %d", n)
+		}
+	})
+}
+
+func TestCompositeKey(t *testing.T) {
+	runCompositeKeyConformance(t, func(t *testing.T) Repository[dictModel] {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "key.db")), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		if err := db.AutoMigrate(&dictModel{}); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+		return NewRepository[dictModel](db)
+	})
+}
+
+func TestMemoryCompositeKey(t *testing.T) {
+	runCompositeKeyConformance(t, func(*testing.T) Repository[dictModel] {
+		return NewMemoryRepository[dictModel]()
+	})
+}
@@ -0,0 +1,1430 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+	"gorm.io/gorm/clause"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"gorm.io/plugin/soft_delete"
+)
+
+/* ========================================================================
+ * Memory Repository - 内存仓储
+ * ========================================================================
+ * 职责: 基于内存的 Repository 实现，供业务服务单元测试使用，无需 sqlite / 数据库连接
+ * 语义: 与 RepositoryImpl 保持一致（由 conformance 测试在两种实现上共同验证）
+ *   - 租户/部门隔离、Update 忽略零值、UpdateByID 字段白名单、软删除、仓储级与模型级钩子
+ *   - GORM 模型钩子（BeforeCreate 等，如 BaseModel 生成 ULID）、autoCreateTime / autoUpdateTime
+ *   - 主键与唯一索引冲突返回 AlreadyExists；UpsertBatch 支持冲突列/更新列选项
+ *   - FindPage / PageBySpec 的页码修正与总页数、FindOne 按主键取第一条
+ *   - 条件复用 GORM 的条件构建（字符串条件、map/struct 条件、Specification、WithScopes），
+ *     在内存中按 SQL 三值逻辑求值，支持的语法见 memory_cond.go
+ *   - Execute / Transaction 出错或 panic 时回滚到执行前的快照
+ * 限制:
+ *   - 不支持 Joins、子查询、命名参数（返回 InvalidArgument）；Preloads 被忽略
+ *   - LIKE 不区分大小写（与 sqlite / MySQL 默认排序规则一致）
+ *   - 事务之间没有隔离；GetDB 返回 nil，Transaction 的 tx 参数为 nil，WithTx 返回自身
+ *   - 写入与读取均为浅拷贝，指针/切片字段与调用方共享
+ *
+ * 使用示例:
+ *   repo := repository.NewMemoryRepository[User]()
+ *   svc := NewUserService(repo)
+ *
+ *   ctx := repository.WithTenantContext(context.Background(), repository.TenantContext{
+ *       TenantID: tenantID,
+ *       IsAdmin:  true,
+ *   })
+ *   err := svc.Register(ctx, "alice")
+ *   users, _ := repo.FindByQuery(ctx, "name = ?", "alice")
+ * ======================================================================== */
+
+var (
+	memoryDBOnce sync.Once
+	memoryDB     *gorm.DB
+	memoryDBErr  error
+)
+
+// memoryDialector 空方言，仅用于解析模型与收集查询条件，不执行任何 SQL
+type memoryDialector struct{}
+
+func (memoryDialector) Name() string                    { return "memory" }
+func (memoryDialector) Initialize(*gorm.DB) error       { return nil }
+func (memoryDialector) Migrator(*gorm.DB) gorm.Migrator { return nil }
+func (memoryDialector) DataTypeOf(*schema.Field) string { return "" }
+func (memoryDialector) DefaultValueOf(*schema.Field) clause.Expression {
+	return clause.Expr{SQL: "DEFAULT"}
+}
+func (memoryDialector) BindVarTo(w clause.Writer, _ *gorm.Statement, _ any) { _ = w.WriteByte('?') }
+func (memoryDialector) QuoteTo(w clause.Writer, s string)                   { _, _ = w.WriteString(s) }
+func (memoryDialector) Explain(sql string, _ ...any) string                 { return sql }
+
+// conditionDB 共享的 DryRun DB（各仓储通过 Session 隔离）
+func conditionDB() (*gorm.DB, error) {
+	memoryDBOnce.Do(func() {
+		memoryDB, memoryDBErr = gorm.Open(memoryDialector{}, &gorm.Config{
+			DryRun:                 true,
+			SkipDefaultTransaction: true,
+			Logger:                 gormlogger.Discard,
+		})
+	})
+	return memoryDB, memoryDBErr
+}
+
+// MemoryRepository 内存仓储
+type MemoryRepository[T any] struct {
+	// base 复用 schema 解析、租户字段、更新字段过滤与钩子（不执行 SQL）
+	base *RepositoryImpl[T]
+
+	mu   sync.RWMutex
+	rows []*memoryRow[T] // 按插入顺序保存
+	seq  int64           // 整数自增主键
+
+	uniqueOnce sync.Once
+	uniques    [][]*schema.Field
+}
+
+// memoryRow 单条记录
+type memoryRow[T any] struct {
+	model   *T
+	deleted bool
+}
+
+// memoryOptions 内存仓储选项
+type memoryOptions struct {
+	clock clock.Clock
+}
+
+// MemoryOption 内存仓储选项函数
+type MemoryOption func(*memoryOptions)
+
+// WithMemoryClock 设置自动时间戳（创建/更新/软删除时间）使用的时钟
+func WithMemoryClock(c clock.Clock) MemoryOption {
+	return func(o *memoryOptions) {
+		o.clock = c
+	}
+}
+
+// NewMemoryRepository 创建内存仓储
+func NewMemoryRepository[T any](opts ...MemoryOption) Repository[T] {
+	var o memoryOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+
+	db, err := conditionDB()
+	if err != nil {
+		panic(fmt.Sprintf("repository: failed to init memory repository: %v", err))
+	}
+	if o.clock != nil {
+		db = db.Session(&gorm.Session{NowFunc: o.clock.Now})
+	}
+	return &MemoryRepository[T]{base: &RepositoryImpl[T]{db: db, hooks: &repoHooks[T]{}}}
+}
+
+// GetDB 内存仓储没有底层 DB，返回 nil
+func (r *MemoryRepository[T]) GetDB() *gorm.DB {
+	return nil
+}
+
+// schema 获取模型 Schema
+func (r *MemoryRepository[T]) schema() (*schema.Schema, error) {
+	return r.base.getSchema()
+}
+
+// gormDB 供 GORM 模型钩子使用的 DB（DryRun）
+func (r *MemoryRepository[T]) gormDB(ctx context.Context) *gorm.DB {
+	return r.base.db.Session(&gorm.Session{NewDB: true, Context: ctx})
+}
+
+func cloneModel[T any](m *T) *T {
+	c := *m
+	return &c
+}
+
+/* ========================================================================
+ * 条件编译
+ * ======================================================================== */
+
+// memoryOrder 排序列
+type memoryOrder struct {
+	column string
+	desc   bool
+}
+
+// memoryQuery 编译后的查询
+type memoryQuery struct {
+	where    memoryCond
+	orders   []memoryOrder
+	limit    *int
+	offset   int
+	selects  []string
+	unscoped bool
+}
+
+// compile 使用 GORM 构建条件（租户范围 -> 选项 -> build），再编译为内存条件
+func (r *MemoryRepository[T]) compile(ctx context.Context, opts *QueryOption, build func(db *gorm.DB) *gorm.DB) (*memoryQuery, error) {
+	db := r.gormDB(ctx).Model(r.base.newModelPtr())
+	db = r.base.applyTenantScope(ctx, db)
+
+	q := &memoryQuery{}
+	if opts != nil {
+		if len(opts.Joins) > 0 {
+			return nil, unsupportedCondition("joins")
+		}
+		q.selects = append(q.selects, opts.Select...)
+		if opts.OrderBy != "" {
+			db = db.Order(opts.OrderBy)
+		}
+		for _, scope := range opts.Scopes {
+			db = scope(db)
+		}
+	}
+	if build != nil {
+		db = build(db)
+	}
+	if db.Error != nil {
+		return nil, db.Error
+	}
+
+	stmt := db.Statement
+	if len(stmt.Joins) > 0 {
+		return nil, unsupportedCondition("joins")
+	}
+	q.unscoped = stmt.Unscoped
+	for _, sel := range stmt.Selects {
+		q.selects = append(q.selects, strings.Split(sel, ",")...)
+	}
+
+	var exprs []clause.Expression
+	if c, ok := stmt.Clauses["WHERE"]; ok {
+		if where, ok := c.Expression.(clause.Where); ok {
+			exprs = where.Exprs
+		}
+	}
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	where, err := compileWhere(s, exprs)
+	if err != nil {
+		return nil, err
+	}
+	q.where = where
+
+	if c, ok := stmt.Clauses["ORDER BY"]; ok {
+		if orderBy, ok := c.Expression.(clause.OrderBy); ok {
+			q.orders = parseOrders(orderBy)
+		}
+	}
+	if c, ok := stmt.Clauses["LIMIT"]; ok {
+		if limit, ok := c.Expression.(clause.Limit); ok {
+			q.limit, q.offset = limit.Limit, limit.Offset
+		}
+	}
+	return q, nil
+}
+
+// parseOrders 解析 ORDER BY（支持 "a DESC, b" 形式的原始字符串）
+func parseOrders(orderBy clause.OrderBy) []memoryOrder {
+	var orders []memoryOrder
+	for _, col := range orderBy.Columns {
+		if !col.Column.Raw && !strings.ContainsAny(col.Column.Name, " ,") {
+			orders = append(orders, memoryOrder{column: col.Column.Name, desc: col.Desc})
+			continue
+		}
+		for part := range strings.SplitSeq(col.Column.Name, ",") {
+			fields := strings.Fields(part)
+			if len(fields) == 0 {
+				continue
+			}
+			orders = append(orders, memoryOrder{
+				column: fields[0],
+				desc:   col.Desc || (len(fields) > 1 && strings.EqualFold(fields[1], "DESC")),
+			})
+		}
+	}
+	return orders
+}
+
+// whereID 按主键过滤（与 RepositoryImpl 一致使用 id 列或键条件 map）
+func whereID(query any, args ...any) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB { return db.Where(query, args...) }
+}
+
+// whereQuery 自定义条件，空字符串表示不过滤
+func whereQuery(query string, args []any) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if query == "" && len(args) == 0 {
+			return db
+		}
+		return db.Where(query, args...)
+	}
+}
+
+/* ========================================================================
+ * 读取
+ * ======================================================================== */
+
+// match 返回匹配的记录（调用方持有锁）
+func (r *MemoryRepository[T]) match(ctx context.Context, q *memoryQuery) ([]*memoryRow[T], error) {
+	var matched []*memoryRow[T]
+	for _, row := range r.rows {
+		if row.deleted && !q.unscoped {
+			continue
+		}
+		v, err := q.where(memoryRecord{ctx: ctx, value: reflect.ValueOf(row.model)})
+		if err != nil {
+			return nil, err
+		}
+		if v == triTrue {
+			matched = append(matched, row)
+		}
+	}
+	return matched, nil
+}
+
+// find 查询记录：过滤 -> 排序 -> 分页 -> 字段选择，返回副本
+func (r *MemoryRepository[T]) find(ctx context.Context, q *memoryQuery, first bool) ([]*T, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	matched, err := r.match(ctx, q)
+	models := make([]*T, len(matched))
+	for i, row := range matched {
+		models[i] = cloneModel(row.model)
+	}
+	r.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	orders := q.orders
+	if first && s.PrioritizedPrimaryField != nil {
+		orders = append(slices.Clone(orders), memoryOrder{column: s.PrioritizedPrimaryField.DBName})
+	}
+	if err := sortModels(ctx, s, models, orders); err != nil {
+		return nil, err
+	}
+
+	models = paginate(models, q.offset, q.limit)
+	if first && len(models) > 1 {
+		models = models[:1]
+	}
+	if len(q.selects) > 0 {
+		if err := selectFields(ctx, s, models, q.selects); err != nil {
+			return nil, err
+		}
+	}
+	return models, nil
+}
+
+// first 查询第一条记录，不存在时返回 NotFound
+func (r *MemoryRepository[T]) first(ctx context.Context, q *memoryQuery) (*T, error) {
+	models, err := r.find(ctx, q, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, errors.FromGORM(gorm.ErrRecordNotFound)
+	}
+	return models[0], nil
+}
+
+// count 统计匹配数量（忽略分页）
+func (r *MemoryRepository[T]) count(ctx context.Context, q *memoryQuery) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	matched, err := r.match(ctx, q)
+	return int64(len(matched)), err
+}
+
+// sortModels 稳定排序，NULL 视为最小值
+func sortModels[T any](ctx context.Context, s *schema.Schema, models []*T, orders []memoryOrder) error {
+	if len(orders) == 0 {
+		return nil
+	}
+	fields := make([]*schema.Field, len(orders))
+	for i, o := range orders {
+		field, err := lookupColumn(s, o.column)
+		if err != nil {
+			return err
+		}
+		fields[i] = field
+	}
+	slices.SortStableFunc(models, func(a, b *T) int {
+		for i, field := range fields {
+			av, _ := field.ValueOf(ctx, reflect.ValueOf(a))
+			bv, _ := field.ValueOf(ctx, reflect.ValueOf(b))
+			c := compareForSort(normalizeValue(av), normalizeValue(bv))
+			if orders[i].desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+// compareForSort 排序比较（NULL 最小，不可比较时相等）
+func compareForSort(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	c, _ := compareValues(a, b)
+	return c
+}
+
+// paginate 应用 OFFSET / LIMIT
+func paginate[E any](items []E, offset int, limit *int) []E {
+	if offset > 0 {
+		if offset >= len(items) {
+			return items[:0]
+		}
+		items = items[offset:]
+	}
+	if limit != nil && *limit >= 0 && *limit < len(items) {
+		items = items[:*limit]
+	}
+	return items
+}
+
+// selectFields 仅保留选择的列，其余字段置零
+func selectFields[T any](ctx context.Context, s *schema.Schema, models []*T, selects []string) error {
+	var fields []*schema.Field
+	for _, sel := range selects {
+		sel = strings.TrimSpace(sel)
+		if sel == "*" {
+			return nil
+		}
+		field, err := lookupColumn(s, sel)
+		if err != nil {
+			return err
+		}
+		fields = append(fields, field)
+	}
+	for i, m := range models {
+		selected := new(T)
+		for _, field := range fields {
+			v, zero := field.ValueOf(ctx, reflect.ValueOf(m))
+			if zero {
+				continue
+			}
+			if err := field.Set(ctx, reflect.ValueOf(selected), v); err != nil {
+				return errors.Wrap(errors.ErrCodeInternal, "failed to select field "+field.DBName, err)
+			}
+		}
+		models[i] = selected
+	}
+	return nil
+}
+
+/* ========================================================================
+ * 写入辅助
+ * ======================================================================== */
+
+// softDeleteField 软删除字段（gorm.DeletedAt / soft_delete.DeletedAt），无则为 nil
+func softDeleteField(s *schema.Schema) *schema.Field {
+	deletedAtType := reflect.TypeOf(gorm.DeletedAt{})
+	flagType := reflect.TypeOf(soft_delete.DeletedAt(0))
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		if ft := field.FieldType; ft == deletedAtType || ft == flagType {
+			return field
+		}
+	}
+	return nil
+}
+
+// markDeleted 设置软删除字段的值
+func (r *MemoryRepository[T]) markDeleted(ctx context.Context, field *schema.Field, model *T) error {
+	var value any
+	now := r.base.db.NowFunc()
+	if field.FieldType == reflect.TypeOf(gorm.DeletedAt{}) {
+		value = now
+	} else {
+		switch {
+		case field.TagSettings["SOFTDELETE"] == "flag" || strings.Contains(strings.ToLower(field.TagSettings["SOFTDELETE"]), "flag"):
+			value = 1
+		case strings.Contains(strings.ToLower(field.TagSettings["SOFTDELETE"]), "milli"):
+			value = now.UnixMilli()
+		case strings.Contains(strings.ToLower(field.TagSettings["SOFTDELETE"]), "nano"):
+			value = now.UnixNano()
+		default:
+			value = now.Unix()
+		}
+	}
+	return field.Set(ctx, reflect.ValueOf(model), value)
+}
+
+// isDeleted 记录的软删除字段是否已设置
+func isDeleted[T any](ctx context.Context, s *schema.Schema, model *T) bool {
+	field := softDeleteField(s)
+	if field == nil {
+		return false
+	}
+	_, zero := field.ValueOf(ctx, reflect.ValueOf(model))
+	return !zero
+}
+
+// uniqueKeys 唯一约束（主键 + 唯一索引 + unique 字段）
+func (r *MemoryRepository[T]) uniqueKeys(s *schema.Schema) [][]*schema.Field {
+	r.uniqueOnce.Do(func() {
+		r.uniques = uniqueConstraints(s)
+	})
+	return r.uniques
+}
+
+// uniqueKey 唯一约束的键，包含 NULL 时返回 false（NULL 不参与唯一性冲突）
+func uniqueKey[T any](ctx context.Context, fields []*schema.Field, model *T) (string, bool) {
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		v, _ := field.ValueOf(ctx, reflect.ValueOf(model))
+		nv := normalizeValue(v)
+		if nv == nil {
+			return "", false
+		}
+		parts[i] = fmt.Sprintf("%T:%v", nv, nv)
+	}
+	return strings.Join(parts, "\x00"), true
+}
+
+// checkUnique 检查待写入记录与现有记录（排除 skip）是否冲突（调用方持有锁）
+func (r *MemoryRepository[T]) checkUnique(ctx context.Context, s *schema.Schema, models []*T, skip map[*memoryRow[T]]bool) error {
+	for _, fields := range r.uniqueKeys(s) {
+		seen := make(map[string]bool, len(r.rows)+len(models))
+		for _, row := range r.rows {
+			if skip[row] {
+				continue
+			}
+			if key, ok := uniqueKey(ctx, fields, row.model); ok {
+				seen[key] = true
+			}
+		}
+		for _, m := range models {
+			key, ok := uniqueKey(ctx, fields, m)
+			if !ok {
+				continue
+			}
+			if seen[key] {
+				return errors.FromGORM(gorm.ErrDuplicatedKey)
+			}
+			seen[key] = true
+		}
+	}
+	return nil
+}
+
+// prepareCreate 执行 GORM 创建前钩子并填充主键与时间字段
+func (r *MemoryRepository[T]) prepareCreate(ctx context.Context, s *schema.Schema, model *T) error {
+	db := r.gormDB(ctx)
+	if h, ok := any(model).(callbacks.BeforeSaveInterface); ok {
+		if err := h.BeforeSave(db); err != nil {
+			return errors.FromGORM(err)
+		}
+	}
+	if h, ok := any(model).(callbacks.BeforeCreateInterface); ok {
+		if err := h.BeforeCreate(db); err != nil {
+			return errors.FromGORM(err)
+		}
+	}
+
+	rv := reflect.ValueOf(model)
+	now := r.base.db.NowFunc()
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		_, zero := field.ValueOf(ctx, rv)
+		var err error
+		switch {
+		case field.AutoIncrement || (field == s.PrioritizedPrimaryField && isIntegerKind(field.FieldType)):
+			r.mu.Lock()
+			if zero {
+				r.seq++
+				err = field.Set(ctx, rv, r.seq)
+			} else if v, ok := normalizeValue(reflectValue(ctx, field, rv)).(int64); ok && v > r.seq {
+				r.seq = v // 显式指定的主键推进自增序列
+			}
+			r.mu.Unlock()
+		case field.AutoCreateTime > 0 || field.AutoUpdateTime > 0:
+			if zero {
+				err = field.Set(ctx, rv, autoTimeValue(field, now))
+			}
+		case zero && field.DefaultValueInterface != nil:
+			err = field.Set(ctx, rv, field.DefaultValueInterface)
+		}
+		if err != nil {
+			return errors.Wrap(errors.ErrCodeInternal, "failed to set field "+field.DBName, err)
+		}
+	}
+	return nil
+}
+
+// afterSave 执行 GORM 保存后钩子
+func (r *MemoryRepository[T]) afterSave(ctx context.Context, model *T, created bool) error {
+	db := r.gormDB(ctx)
+	if created {
+		if h, ok := any(model).(callbacks.AfterCreateInterface); ok {
+			if err := h.AfterCreate(db); err != nil {
+				return errors.FromGORM(err)
+			}
+		}
+	} else if h, ok := any(model).(callbacks.AfterUpdateInterface); ok {
+		if err := h.AfterUpdate(db); err != nil {
+			return errors.FromGORM(err)
+		}
+	}
+	if h, ok := any(model).(callbacks.AfterSaveInterface); ok {
+		if err := h.AfterSave(db); err != nil {
+			return errors.FromGORM(err)
+		}
+	}
+	return nil
+}
+
+// beforeUpdate 执行 GORM 更新前钩子
+func (r *MemoryRepository[T]) beforeUpdate(ctx context.Context, model *T) error {
+	db := r.gormDB(ctx)
+	if h, ok := any(model).(callbacks.BeforeSaveInterface); ok {
+		if err := h.BeforeSave(db); err != nil {
+			return errors.FromGORM(err)
+		}
+	}
+	if h, ok := any(model).(callbacks.BeforeUpdateInterface); ok {
+		if err := h.BeforeUpdate(db); err != nil {
+			return errors.FromGORM(err)
+		}
+	}
+	return nil
+}
+
+func reflectValue(ctx context.Context, field *schema.Field, rv reflect.Value) any {
+	v, _ := field.ValueOf(ctx, rv)
+	return v
+}
+
+func isIntegerKind(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	default:
+		return false
+	}
+}
+
+// autoTimeValue autoCreateTime / autoUpdateTime 的取值（time.Time 或 Unix 秒/毫秒/纳秒）
+func autoTimeValue(field *schema.Field, now time.Time) any {
+	track := field.AutoCreateTime
+	if track == 0 {
+		track = field.AutoUpdateTime
+	}
+	if field.DataType == schema.Time || !isIntegerKind(field.FieldType) {
+		return now
+	}
+	switch track {
+	case schema.UnixNanosecond:
+		return now.UnixNano()
+	case schema.UnixMillisecond:
+		return now.UnixMilli()
+	default:
+		return now.Unix()
+	}
+}
+
+// insert 写入新记录（全部成功或全部失败）
+func (r *MemoryRepository[T]) insert(ctx context.Context, models []*T) error {
+	s, err := r.schema()
+	if err != nil {
+		return err
+	}
+	for _, m := range models {
+		if err := r.prepareCreate(ctx, s, m); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	if err := r.checkUnique(ctx, s, models, nil); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	for _, m := range models {
+		r.rows = append(r.rows, &memoryRow[T]{model: cloneModel(m), deleted: isDeleted(ctx, s, m)})
+	}
+	r.mu.Unlock()
+
+	for _, m := range models {
+		if err := r.afterSave(ctx, m, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* ========================================================================
+ * Create 操作
+ * ======================================================================== */
+
+// Create 创建单条记录
+func (r *MemoryRepository[T]) Create(ctx context.Context, model *T) error {
+	if model == nil {
+		return errors.ErrInvalidArgument
+	}
+	if err := r.base.setTenantFields(ctx, model); err != nil {
+		return err
+	}
+	if err := r.base.runBeforeCreate(ctx, model); err != nil {
+		return err
+	}
+	if err := r.insert(ctx, []*T{model}); err != nil {
+		return err
+	}
+	return r.base.runAfterCreate(ctx, model)
+}
+
+// CreateBatch 批量创建记录（batchSize 仅为接口兼容）
+func (r *MemoryRepository[T]) CreateBatch(ctx context.Context, models []*T, _ int) error {
+	if len(models) == 0 {
+		return errors.ErrInvalidArgument
+	}
+
+	validModels := make([]*T, 0, len(models))
+	for _, m := range models {
+		if m != nil {
+			validModels = append(validModels, m)
+		}
+	}
+	if len(validModels) == 0 {
+		return nil
+	}
+
+	for _, m := range validModels {
+		if err := r.base.setTenantFields(ctx, m); err != nil {
+			return err
+		}
+		if err := r.base.runBeforeCreate(ctx, m); err != nil {
+			return err
+		}
+	}
+	if err := r.insert(ctx, validModels); err != nil {
+		return err
+	}
+	for _, m := range validModels {
+		if err := r.base.runAfterCreate(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/* ========================================================================
+ * Update 操作
+ * ======================================================================== */
+
+// Update 更新记录（根据主键，忽略零值字段）
+func (r *MemoryRepository[T]) Update(ctx context.Context, model *T) error {
+	if model == nil {
+		return errors.ErrInvalidArgument
+	}
+	if err := r.base.ensurePrimaryKeySet(ctx, model); err != nil {
+		return err
+	}
+	if err := r.base.setAuditFields(ctx, model); err != nil {
+		return err
+	}
+	if err := r.base.runBeforeUpdate(ctx, model); err != nil {
+		return err
+	}
+
+	s, err := r.schema()
+	if err != nil {
+		return err
+	}
+	pk := s.PrioritizedPrimaryField
+	id, _ := pk.ValueOf(ctx, reflect.ValueOf(model))
+	q, err := r.compile(ctx, nil, func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Name: pk.DBName}, Value: id})
+	})
+	if err != nil {
+		return err
+	}
+	if err := r.beforeUpdate(ctx, model); err != nil {
+		return err
+	}
+
+	src := reflect.ValueOf(model)
+	now := r.base.db.NowFunc()
+	r.mu.Lock()
+	matched, err := r.match(ctx, q)
+	if err == nil && len(matched) == 0 {
+		err = errors.FromGORM(gorm.ErrRecordNotFound)
+	}
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+
+	updated := make([]*T, len(matched))
+	for i, row := range matched {
+		updated[i] = cloneModel(row.model)
+		dst := reflect.ValueOf(updated[i])
+		for _, field := range s.Fields {
+			if field.DBName == "" || field.PrimaryKey || !field.Updatable {
+				continue
+			}
+			var value any
+			if field.AutoUpdateTime > 0 {
+				value = autoTimeValue(field, now)
+				if err = field.Set(ctx, src, value); err != nil {
+					break
+				}
+			} else {
+				v, zero := field.ValueOf(ctx, src)
+				if zero {
+					continue
+				}
+				value = v
+			}
+			if err = field.Set(ctx, dst, value); err != nil {
+				break
+			}
+		}
+		if err != nil {
+			r.mu.Unlock()
+			return errors.Wrap(errors.ErrCodeInternal, "failed to update record", err)
+		}
+	}
+	if err := r.checkUnique(ctx, s, updated, rowSet(matched)); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	for i, row := range matched {
+		row.model = updated[i]
+	}
+	r.mu.Unlock()
+
+	if err := r.afterSave(ctx, model, false); err != nil {
+		return err
+	}
+	return r.base.runAfterUpdate(ctx, model)
+}
+
+func rowSet[T any](rows []*memoryRow[T]) map[*memoryRow[T]]bool {
+	set := make(map[*memoryRow[T]]bool, len(rows))
+	for _, row := range rows {
+		set[row] = true
+	}
+	return set
+}
+
+// UpdateByID 根据 ID 更新指定字段
+func (r *MemoryRepository[T]) UpdateByID(ctx context.Context, id string, updates map[string]any, allowedFields ...string) error {
+	if len(updates) == 0 {
+		return errors.ErrInvalidArgument
+	}
+
+	filteredUpdates, err := r.base.filterUpdates(updates, allowedFields)
+	if err != nil {
+		return err
+	}
+	if len(filteredUpdates) == 0 {
+		return errors.ErrInvalidArgument
+	}
+	if err := r.base.auditUpdates(ctx, filteredUpdates); err != nil {
+		return err
+	}
+
+	needBefore, needAfter := r.base.hasUpdateHooks()
+	if needBefore {
+		current, err := r.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := r.base.runBeforeUpdate(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	s, err := r.schema()
+	if err != nil {
+		return err
+	}
+	q, err := r.compile(ctx, nil, whereID("id = ?", id))
+	if err != nil {
+		return err
+	}
+
+	now := r.base.db.NowFunc()
+	r.mu.Lock()
+	matched, err := r.match(ctx, q)
+	if err == nil && len(matched) == 0 {
+		err = errors.FromGORM(gorm.ErrRecordNotFound)
+	}
+	if err != nil {
+		r.mu.Unlock()
+		return err
+	}
+
+	updated := make([]*T, len(matched))
+	for i, row := range matched {
+		updated[i] = cloneModel(row.model)
+		dst := reflect.ValueOf(updated[i])
+		for column, value := range filteredUpdates {
+			if err = s.FieldsByDBName[column].Set(ctx, dst, value); err != nil {
+				break
+			}
+		}
+		for _, field := range s.Fields {
+			if err != nil {
+				break
+			}
+			if _, ok := filteredUpdates[field.DBName]; !ok && field.AutoUpdateTime > 0 {
+				err = field.Set(ctx, dst, autoTimeValue(field, now))
+			}
+		}
+		if err != nil {
+			r.mu.Unlock()
+			return errors.Wrap(errors.ErrCodeInvalidArgument, "failed to update record", err)
+		}
+	}
+	if err := r.checkUnique(ctx, s, updated, rowSet(matched)); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	for i, row := range matched {
+		row.model = updated[i]
+	}
+	r.mu.Unlock()
+
+	if needAfter {
+		updated, err := r.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		return r.base.runAfterUpdate(ctx, updated)
+	}
+	return nil
+}
+
+// UpsertBatch 批量更新或插入记录（默认主键冲突时更新除主键与创建时间外的所有字段）
+func (r *MemoryRepository[T]) UpsertBatch(ctx context.Context, models []*T, opts ...UpsertOption) error {
+	if len(models) == 0 {
+		return errors.ErrInvalidArgument
+	}
+	o := ApplyUpsertOptions(opts)
+
+	validModels := make([]*T, 0, len(models))
+	for _, m := range models {
+		if m != nil {
+			validModels = append(validModels, m)
+		}
+	}
+	if len(validModels) == 0 {
+		return nil
+	}
+
+	for _, m := range validModels {
+		if err := r.base.setTenantFields(ctx, m); err != nil {
+			return err
+		}
+	}
+
+	s, err := r.schema()
+	if err != nil {
+		return err
+	}
+	if len(s.PrimaryFields) == 0 && len(o.ConflictColumns) == 0 {
+		return r.insert(ctx, validModels)
+	}
+	conflict, err := r.base.conflictFields(s, o.ConflictColumns)
+	if err != nil {
+		return err
+	}
+	var updates []*schema.Field
+	if len(o.UpdateColumns) > 0 {
+		if updates, err = r.base.upsertUpdateFields(s, o.UpdateColumns); err != nil {
+			return err
+		}
+	}
+	for _, m := range validModels {
+		if err := r.prepareCreate(ctx, s, m); err != nil {
+			return err
+		}
+	}
+
+	now := r.base.db.NowFunc()
+	r.mu.Lock()
+	byKey := make(map[string]*memoryRow[T], len(r.rows))
+	for _, row := range r.rows {
+		if key, ok := uniqueKey(ctx, conflict, row.model); ok {
+			byKey[key] = row
+		}
+	}
+	next := make([]*T, 0, len(validModels))
+	replaced := make(map[*memoryRow[T]]bool)
+	targets := make([]*memoryRow[T], 0, len(validModels))
+	for _, m := range validModels {
+		key, ok := uniqueKey(ctx, conflict, m)
+		row, exists := byKey[key]
+		if !ok || !exists {
+			row = &memoryRow[T]{}
+			if ok {
+				byKey[key] = row
+			}
+			next = append(next, cloneModel(m))
+			targets = append(targets, row)
+			continue
+		}
+		base := row.model
+		if i := slices.Index(targets, row); i >= 0 {
+			base = next[i]
+		}
+		merged := r.mergeUpsert(ctx, s, base, m, updates, now)
+		if i := slices.Index(targets, row); i >= 0 {
+			next[i] = merged
+		} else {
+			replaced[row] = true
+			next = append(next, merged)
+			targets = append(targets, row)
+		}
+	}
+
+	if err := r.checkUnique(ctx, s, next, replaced); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	for i, row := range targets {
+		row.model = next[i]
+		row.deleted = isDeleted(ctx, s, next[i])
+		if !replaced[row] {
+			r.rows = append(r.rows, row)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, m := range validModels {
+		if err := r.afterSave(ctx, m, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeUpsert 冲突更新后的记录：updates 为空时以新记录为准（保留主键与创建时间），否则只更新 updates 字段
+func (r *MemoryRepository[T]) mergeUpsert(ctx context.Context, s *schema.Schema, base, incoming *T, updates []*schema.Field, now time.Time) *T {
+	if len(updates) > 0 {
+		merged := cloneModel(base)
+		for _, field := range updates {
+			v, _ := field.ValueOf(ctx, reflect.ValueOf(incoming))
+			_ = field.Set(ctx, reflect.ValueOf(merged), v)
+		}
+		return merged
+	}
+
+	merged := cloneModel(incoming)
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		if field.PrimaryKey || field.AutoCreateTime > 0 {
+			v, _ := field.ValueOf(ctx, reflect.ValueOf(base))
+			_ = field.Set(ctx, reflect.ValueOf(merged), v)
+		} else if field.AutoUpdateTime > 0 {
+			_ = field.Set(ctx, reflect.ValueOf(merged), autoTimeValue(field, now))
+		}
+	}
+	return merged
+}
+
+/* ========================================================================
+ * Delete 操作
+ * ======================================================================== */
+
+// remove 删除匹配记录，hard 为 false 且模型有软删除字段时执行软删除（调用方持有锁）
+func (r *MemoryRepository[T]) remove(ctx context.Context, matched []*memoryRow[T], hard bool) error {
+	s, err := r.schema()
+	if err != nil {
+		return err
+	}
+	if field := softDeleteField(s); field != nil && !hard {
+		for _, row := range matched {
+			deleted := cloneModel(row.model)
+			if err := r.markDeleted(ctx, field, deleted); err != nil {
+				return errors.Wrap(errors.ErrCodeInternal, "failed to delete record", err)
+			}
+			row.model, row.deleted = deleted, true
+		}
+		return nil
+	}
+	drop := rowSet(matched)
+	r.rows = slices.DeleteFunc(r.rows, func(row *memoryRow[T]) bool { return drop[row] })
+	return nil
+}
+
+// deleteWhere 按条件删除，返回删除条数
+func (r *MemoryRepository[T]) deleteWhere(ctx context.Context, build func(db *gorm.DB) *gorm.DB, hard bool) (int, error) {
+	q, err := r.compile(ctx, nil, build)
+	if err != nil {
+		return 0, err
+	}
+	q.unscoped = hard
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched, err := r.match(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+	return len(matched), r.remove(ctx, matched, hard)
+}
+
+// Delete 软删除记录（模型无软删除字段时直接删除）
+func (r *MemoryRepository[T]) Delete(ctx context.Context, id string) error {
+	ids := []string{id}
+	if err := r.base.runBeforeDelete(ctx, ids); err != nil {
+		return err
+	}
+
+	n, err := r.deleteWhere(ctx, whereID("id = ?", id), false)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.FromGORM(gorm.ErrRecordNotFound)
+	}
+	return r.base.runAfterDelete(ctx, ids)
+}
+
+// DeleteByKey 根据复合主键或唯一键软删除记录
+func (r *MemoryRepository[T]) DeleteByKey(ctx context.Context, keys map[string]any) error {
+	conds, err := r.base.resolveKeys(keys)
+	if err != nil {
+		return err
+	}
+
+	ids := []string{keyString(conds)}
+	if r.base.hasDeleteHooks() {
+		current, err := r.FindByKey(ctx, keys)
+		if err != nil {
+			return err
+		}
+		ids = r.base.keyHookIDs(ctx, current, conds)
+	}
+	if err := r.base.runBeforeDelete(ctx, ids); err != nil {
+		return err
+	}
+
+	n, err := r.deleteWhere(ctx, whereID(conds), false)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.FromGORM(gorm.ErrRecordNotFound)
+	}
+	return r.base.runAfterDelete(ctx, ids)
+}
+
+// DeleteBatch 批量软删除记录
+func (r *MemoryRepository[T]) DeleteBatch(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return errors.ErrInvalidArgument
+	}
+	if err := r.base.runBeforeDelete(ctx, ids); err != nil {
+		return err
+	}
+	if _, err := r.deleteWhere(ctx, whereID("id IN ?", ids), false); err != nil {
+		return err
+	}
+	return r.base.runAfterDelete(ctx, ids)
+}
+
+// HardDelete 硬删除记录（包含已软删除的记录）
+func (r *MemoryRepository[T]) HardDelete(ctx context.Context, id string) error {
+	ids := []string{id}
+	if err := r.base.runBeforeDelete(ctx, ids); err != nil {
+		return err
+	}
+
+	n, err := r.deleteWhere(ctx, whereID("id = ?", id), true)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errors.FromGORM(gorm.ErrRecordNotFound)
+	}
+	return r.base.runAfterDelete(ctx, ids)
+}
+
+/* ========================================================================
+ * 查询操作
+ * ======================================================================== */
+
+// FindByID 根据 ID 查找记录
+func (r *MemoryRepository[T]) FindByID(ctx context.Context, id string, opts ...Option) (*T, error) {
+	q, err := r.compile(ctx, ApplyOptions(opts), whereID("id = ?", id))
+	if err != nil {
+		return nil, err
+	}
+	return r.first(ctx, q)
+}
+
+// FindByIDs 根据 ID 列表查找多条记录
+func (r *MemoryRepository[T]) FindByIDs(ctx context.Context, ids []string, opts ...Option) ([]*T, error) {
+	if len(ids) == 0 {
+		return []*T{}, nil
+	}
+	q, err := r.compile(ctx, ApplyOptions(opts), whereID("id IN ?", ids))
+	if err != nil {
+		return nil, err
+	}
+	return r.find(ctx, q, false)
+}
+
+// FindByKey 根据复合主键或唯一键查找记录
+func (r *MemoryRepository[T]) FindByKey(ctx context.Context, keys map[string]any, opts ...Option) (*T, error) {
+	conds, err := r.base.resolveKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	q, err := r.compile(ctx, ApplyOptions(opts), whereID(conds))
+	if err != nil {
+		return nil, err
+	}
+	return r.first(ctx, q)
+}
+
+// FindOne 查找单条记录（使用自定义条件）
+func (r *MemoryRepository[T]) FindOne(ctx context.Context, query string, args ...any) (*T, error) {
+	return r.FindOneWithOpts(ctx, query, nil, args...)
+}
+
+// FindOneWithOpts 查找单条记录（带选项）
+func (r *MemoryRepository[T]) FindOneWithOpts(ctx context.Context, query string, opts []Option, args ...any) (*T, error) {
+	q, err := r.compile(ctx, optionsOrNil(opts), whereQuery(query, args))
+	if err != nil {
+		return nil, err
+	}
+	return r.first(ctx, q)
+}
+
+// FindByQuery 查找多条记录（使用自定义条件）
+func (r *MemoryRepository[T]) FindByQuery(ctx context.Context, query string, args ...any) ([]*T, error) {
+	return r.FindByQueryWithOpts(ctx, query, nil, args...)
+}
+
+// FindByQueryWithOpts 查找多条记录（带选项）
+func (r *MemoryRepository[T]) FindByQueryWithOpts(ctx context.Context, query string, opts []Option, args ...any) ([]*T, error) {
+	q, err := r.compile(ctx, optionsOrNil(opts), whereQuery(query, args))
+	if err != nil {
+		return nil, err
+	}
+	return r.find(ctx, q, false)
+}
+
+// Count 统计记录数
+func (r *MemoryRepository[T]) Count(ctx context.Context, query string, args ...any) (int64, error) {
+	q, err := r.compile(ctx, nil, whereQuery(query, args))
+	if err != nil {
+		return 0, err
+	}
+	return r.count(ctx, q)
+}
+
+// Exists 检查记录是否存在
+func (r *MemoryRepository[T]) Exists(ctx context.Context, query string, args ...any) (bool, error) {
+	count, err := r.Count(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// optionsOrNil 与 RepositoryImpl 一致，未传选项时不应用默认选项
+func optionsOrNil(opts []Option) *QueryOption {
+	if len(opts) == 0 {
+		return nil
+	}
+	return ApplyOptions(opts)
+}
+
+/* ========================================================================
+ * 分页与规约查询
+ * ======================================================================== */
+
+// page 分页查询
+func (r *MemoryRepository[T]) page(ctx context.Context, pr PageRequest, opts *QueryOption, build func(db *gorm.DB) *gorm.DB) (*PageResult[T], error) {
+	pr = pr.Normalize()
+	q, err := r.compile(ctx, opts, build)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := r.count(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := pr.PageSize
+	q.offset, q.limit = (pr.Page-1)*pr.PageSize, &limit
+	models, err := r.find(ctx, q, false)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]T, len(models))
+	for i, m := range models {
+		list[i] = *m
+	}
+
+	return &PageResult[T]{
+		List:     list,
+		Total:    total,
+		Page:     pr.Page,
+		PageSize: pr.PageSize,
+		Pages:    int64(math.Ceil(float64(total) / float64(pr.PageSize))),
+	}, nil
+}
+
+// FindPage 分页查询
+func (r *MemoryRepository[T]) FindPage(ctx context.Context, page, pageSize int, query string, args ...any) (*PageResult[T], error) {
+	return r.FindPageWithOpts(ctx, page, pageSize, query, nil, args...)
+}
+
+// FindPageWithOpts 分页查询（带选项）
+func (r *MemoryRepository[T]) FindPageWithOpts(ctx context.Context, page, pageSize int, query string, opts []Option, args ...any) (*PageResult[T], error) {
+	return r.page(ctx, PageRequest{Page: page, PageSize: pageSize}, optionsOrNil(opts), func(db *gorm.DB) *gorm.DB {
+		if query != "" {
+			db = db.Where(query, args...)
+		}
+		return db
+	})
+}
+
+// FindBySpec 按规约查询多条记录
+func (r *MemoryRepository[T]) FindBySpec(ctx context.Context, spec Specification[T], opts ...Option) ([]*T, error) {
+	q, err := r.compile(ctx, ApplyOptions(opts), func(db *gorm.DB) *gorm.DB { return applySpec[T](db, spec) })
+	if err != nil {
+		return nil, err
+	}
+	return r.find(ctx, q, false)
+}
+
+// CountBySpec 按规约统计记录数
+func (r *MemoryRepository[T]) CountBySpec(ctx context.Context, spec Specification[T]) (int64, error) {
+	q, err := r.compile(ctx, nil, func(db *gorm.DB) *gorm.DB { return applySpec[T](db, spec) })
+	if err != nil {
+		return 0, err
+	}
+	return r.count(ctx, q)
+}
+
+// PageBySpec 按规约分页查询
+func (r *MemoryRepository[T]) PageBySpec(ctx context.Context, page PageRequest, spec Specification[T], opts ...Option) (*PageResult[T], error) {
+	return r.page(ctx, page, ApplyOptions(opts), func(db *gorm.DB) *gorm.DB { return applySpec[T](db, spec) })
+}
+
+/* ========================================================================
+ * 事务
+ * ======================================================================== */
+
+// snapshot 复制当前数据（用于回滚）
+func (r *MemoryRepository[T]) snapshot() ([]*memoryRow[T], int64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rows := make([]*memoryRow[T], len(r.rows))
+	for i, row := range r.rows {
+		rows[i] = &memoryRow[T]{model: cloneModel(row.model), deleted: row.deleted}
+	}
+	return rows, r.seq
+}
+
+// restore 恢复快照
+func (r *MemoryRepository[T]) restore(rows []*memoryRow[T], seq int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rows, r.seq = rows, seq
+}
+
+// atomically 执行 fn，返回错误或 panic 时回滚
+func (r *MemoryRepository[T]) atomically(fn func() error) (err error) {
+	rows, seq := r.snapshot()
+	defer func() {
+		if p := recover(); p != nil {
+			r.restore(rows, seq)
+			panic(p)
+		}
+		if err != nil {
+			r.restore(rows, seq)
+		}
+	}()
+	return fn()
+}
+
+// Execute 执行操作，返回错误时回滚本仓储的数据
+func (r *MemoryRepository[T]) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	return r.atomically(func() error { return fn(ctx) })
+}
+
+// Transaction 执行操作，返回错误时回滚本仓储的数据（tx 参数为 nil）
+// Deprecated: 请使用 Execute 方法
+func (r *MemoryRepository[T]) Transaction(_ context.Context, fn func(tx *gorm.DB) error) error {
+	return r.atomically(func() error { return fn(nil) })
+}
+
+// WithTx 内存仓储没有事务 DB，返回自身
+func (r *MemoryRepository[T]) WithTx(*gorm.DB) Repository[T] {
+	return r
+}
+
+/* ========================================================================
+ * 钩子
+ * ======================================================================== */
+
+// OnBeforeCreate 注册创建前钩子
+func (r *MemoryRepository[T]) OnBeforeCreate(fn HookFunc[T]) { r.base.OnBeforeCreate(fn) }
+
+// OnAfterCreate 注册创建后钩子
+func (r *MemoryRepository[T]) OnAfterCreate(fn HookFunc[T]) { r.base.OnAfterCreate(fn) }
+
+// OnBeforeUpdate 注册更新前钩子
+func (r *MemoryRepository[T]) OnBeforeUpdate(fn HookFunc[T]) { r.base.OnBeforeUpdate(fn) }
+
+// OnAfterUpdate 注册更新后钩子
+func (r *MemoryRepository[T]) OnAfterUpdate(fn HookFunc[T]) { r.base.OnAfterUpdate(fn) }
+
+// OnBeforeDelete 注册删除前钩子
+func (r *MemoryRepository[T]) OnBeforeDelete(fn DeleteHookFunc) { r.base.OnBeforeDelete(fn) }
+
+// OnAfterDelete 注册删除后钩子
+func (r *MemoryRepository[T]) OnAfterDelete(fn DeleteHookFunc) { r.base.OnAfterDelete(fn) }
@@ -0,0 +1,440 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+/* ========================================================================
+ * Memory Aggregate - 内存仓储的聚合查询
+ * ========================================================================
+ * 职责: 在内存中计算 Sum / Avg / Max / Min、分组聚合与时间分桶
+ * 语义: 校验规则与 RepositoryImpl 一致（复用 validateColumn / buildAggregateSelect）
+ *   - NULL 不参与 SUM / AVG / MAX / MIN / COUNT(column)，无值时 Sum/Avg 返回 0、Max/Min 返回 nil
+ *   - 分组结果默认按分组列升序，OrderBy 指定时按其排序
+ *   - 时间分桶在 rangeStart 所在时区内进行，周以周一为起点
+ * ======================================================================== */
+
+// columnValues 读取记录上某列的归一化值
+func (r *MemoryRepository[T]) columnValues(ctx context.Context, models []*T, column string) ([]any, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	field, err := lookupColumn(s, column)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(models))
+	for i, m := range models {
+		v, _ := field.ValueOf(ctx, reflect.ValueOf(m))
+		values[i] = normalizeValue(v)
+	}
+	return values, nil
+}
+
+// aggregateValues 按条件查询并读取列值
+func (r *MemoryRepository[T]) aggregateValues(ctx context.Context, column, query string, args []any) ([]any, error) {
+	if err := validateColumn(column); err != nil {
+		return nil, err
+	}
+	q, err := r.compile(ctx, nil, whereQuery(query, args))
+	if err != nil {
+		return nil, err
+	}
+	models, err := r.find(ctx, q, false)
+	if err != nil {
+		return nil, err
+	}
+	return r.columnValues(ctx, models, column)
+}
+
+// Sum 求和
+func (r *MemoryRepository[T]) Sum(ctx context.Context, column string, query string, args ...any) (float64, error) {
+	values, err := r.aggregateValues(ctx, column, query, args)
+	if err != nil {
+		return 0, err
+	}
+	sum, _ := Sum(column).compute(values)
+	return sum, nil
+}
+
+// Avg 平均值
+func (r *MemoryRepository[T]) Avg(ctx context.Context, column string, query string, args ...any) (float64, error) {
+	values, err := r.aggregateValues(ctx, column, query, args)
+	if err != nil {
+		return 0, err
+	}
+	avg, _ := Avg(column).compute(values)
+	return avg, nil
+}
+
+// Max 最大值（int64 / float64 / string / time.Time 等），无记录时返回 nil
+func (r *MemoryRepository[T]) Max(ctx context.Context, column string, query string, args ...any) (any, error) {
+	values, err := r.aggregateValues(ctx, column, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return extremum(values, 1), nil
+}
+
+// Min 最小值（int64 / float64 / string / time.Time 等），无记录时返回 nil
+func (r *MemoryRepository[T]) Min(ctx context.Context, column string, query string, args ...any) (any, error) {
+	values, err := r.aggregateValues(ctx, column, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return extremum(values, -1), nil
+}
+
+// extremum 非 NULL 值中的最大（sign=1）或最小（sign=-1）值
+func extremum(values []any, sign int) any {
+	var result any
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if result == nil {
+			result = v
+			continue
+		}
+		if c, ok := compareValues(v, result); ok && c*sign > 0 {
+			result = v
+		}
+	}
+	return result
+}
+
+// compute 在内存中计算指标，无非 NULL 值时 valid 为 false（COUNT 始终有效）
+func (m Metric) compute(values []any) (result float64, valid bool) {
+	var nums []float64
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		if f, ok := toFloat(v); ok {
+			nums = append(nums, f)
+		} else if t, ok := v.(time.Time); ok {
+			nums = append(nums, float64(t.Unix()))
+		}
+	}
+
+	switch m.Func {
+	case "COUNT":
+		if m.Column == "" {
+			return float64(len(values)), true
+		}
+		n := 0
+		for _, v := range values {
+			if v != nil {
+				n++
+			}
+		}
+		return float64(n), true
+	case "COUNT_DISTINCT":
+		seen := make(map[string]bool)
+		for _, v := range values {
+			if v != nil {
+				seen[fmt.Sprintf("%T:%v", v, v)] = true
+			}
+		}
+		return float64(len(seen)), true
+	}
+
+	if len(nums) == 0 {
+		return 0, false
+	}
+	switch m.Func {
+	case "SUM", "AVG":
+		for _, n := range nums {
+			result += n
+		}
+		if m.Func == "AVG" {
+			result /= float64(len(nums))
+		}
+	case "MAX":
+		result = slices.Max(nums)
+	case "MIN":
+		result = slices.Min(nums)
+	}
+	return result, true
+}
+
+// metricValues 读取指标列的值，COUNT(*) 时每条记录对应一个非 NULL 占位值
+func (r *MemoryRepository[T]) metricValues(ctx context.Context, models []*T, m Metric) ([]any, error) {
+	if m.Column == "" {
+		values := make([]any, len(models))
+		for i := range values {
+			values[i] = int64(1)
+		}
+		return values, nil
+	}
+	return r.columnValues(ctx, models, m.Column)
+}
+
+// memoryGroup 分组中间结果
+type memoryGroup[T any] struct {
+	keys   []any
+	models []*T
+}
+
+// Aggregate 分组聚合查询
+func (r *MemoryRepository[T]) Aggregate(ctx context.Context, spec AggregateSpec) ([]AggregateRow, error) {
+	if _, err := buildAggregateSelect(spec); err != nil {
+		return nil, err
+	}
+	q, err := r.compile(ctx, nil, whereQuery(spec.Query, spec.Args))
+	if err != nil {
+		return nil, err
+	}
+	models, err := r.find(ctx, q, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// 分组
+	groupValues := make([][]any, len(spec.GroupBy))
+	for i, col := range spec.GroupBy {
+		if groupValues[i], err = r.columnValues(ctx, models, col); err != nil {
+			return nil, err
+		}
+	}
+	var groups []*memoryGroup[T]
+	index := make(map[string]*memoryGroup[T])
+	for i, m := range models {
+		keys := make([]any, len(spec.GroupBy))
+		parts := make([]string, len(spec.GroupBy))
+		for j := range spec.GroupBy {
+			keys[j] = groupValues[j][i]
+			parts[j] = fmt.Sprintf("%T:%v", keys[j], keys[j])
+		}
+		key := strings.Join(parts, "\x00")
+		g, ok := index[key]
+		if !ok {
+			g = &memoryGroup[T]{keys: keys}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.models = append(g.models, m)
+	}
+	if len(spec.GroupBy) == 0 && len(groups) == 0 {
+		groups = append(groups, &memoryGroup[T]{}) // 整表聚合无记录时仍返回一行
+	}
+
+	// 计算指标与 HAVING
+	result := make([]AggregateRow, 0, len(groups))
+	for _, g := range groups {
+		row := AggregateRow{
+			Groups: make(map[string]any, len(spec.GroupBy)),
+			Values: make(map[string]float64, len(spec.Metrics)),
+		}
+		for i, col := range spec.GroupBy {
+			row.Groups[col] = g.keys[i]
+		}
+		for _, m := range spec.Metrics {
+			values, err := r.metricValues(ctx, g.models, m)
+			if err != nil {
+				return nil, err
+			}
+			row.Values[m.name()], _ = m.compute(values)
+		}
+		keep := true
+		for _, h := range spec.Having {
+			values, err := r.metricValues(ctx, g.models, h.Metric)
+			if err != nil {
+				return nil, err
+			}
+			v, valid := h.Metric.compute(values)
+			if !valid {
+				keep = false
+				break
+			}
+			cond := compareCond(constOperand(v), h.Op, constOperand(h.Value))
+			if t, _ := cond(memoryRecord{}); t != triTrue {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			result = append(result, row)
+		}
+	}
+
+	sortAggregateRows(result, spec)
+	if spec.Limit > 0 && len(result) > spec.Limit {
+		result = result[:spec.Limit]
+	}
+	return result, nil
+}
+
+// sortAggregateRows 默认按分组列升序，再按 OrderBy 排序
+func sortAggregateRows(rows []AggregateRow, spec AggregateSpec) {
+	value := func(row AggregateRow, col string) any {
+		if v, ok := row.Values[col]; ok {
+			return v
+		}
+		return row.Groups[col]
+	}
+	orders := make([]memoryOrder, 0, len(spec.OrderBy)+len(spec.GroupBy))
+	for _, o := range spec.OrderBy {
+		col, desc := strings.CutPrefix(o, "-")
+		orders = append(orders, memoryOrder{column: col, desc: desc})
+	}
+	for _, col := range spec.GroupBy {
+		orders = append(orders, memoryOrder{column: col})
+	}
+	slices.SortStableFunc(rows, func(a, b AggregateRow) int {
+		for _, o := range orders {
+			c := compareForSort(value(a, o.column), value(b, o.column))
+			if o.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// AggregateInto 分组聚合查询并写入自定义结构体切片（按 gorm column 标签匹配）
+func (r *MemoryRepository[T]) AggregateInto(ctx context.Context, spec AggregateSpec, dest any) error {
+	rows, err := r.Aggregate(ctx, spec)
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Slice {
+		return errors.New(errors.ErrCodeInvalidArgument, "aggregate dest must be a pointer to slice")
+	}
+	destSchema, err := schema.Parse(dest, &sync.Map{}, r.base.db.NamingStrategy)
+	if err != nil {
+		return errors.Wrap(errors.ErrCodeInternal, "failed to aggregate records", err)
+	}
+
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Pointer
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	out := reflect.MakeSlice(slice.Type(), 0, len(rows))
+	for _, row := range rows {
+		elem := reflect.New(elemType)
+		set := func(name string, v any) error {
+			if field := destSchema.LookUpField(name); field != nil && v != nil {
+				return field.Set(ctx, elem, v)
+			}
+			return nil
+		}
+		for col, v := range row.Groups {
+			if err := set(col, v); err != nil {
+				return errors.Wrap(errors.ErrCodeInternal, "failed to aggregate records", err)
+			}
+		}
+		for alias, v := range row.Values {
+			if err := set(alias, v); err != nil {
+				return errors.Wrap(errors.ErrCodeInternal, "failed to aggregate records", err)
+			}
+		}
+		if isPtr {
+			out = reflect.Append(out, elem)
+		} else {
+			out = reflect.Append(out, elem.Elem())
+		}
+	}
+	slice.Set(out)
+	return nil
+}
+
+// SumByTimeBucket 按时间分桶求和
+func (r *MemoryRepository[T]) SumByTimeBucket(ctx context.Context, column string, interval TimeBucket, rangeStart, rangeEnd time.Time, opts ...BucketOption) ([]TimeBucketPoint, error) {
+	return r.AggregateByTimeBucket(ctx, Sum(column), interval, rangeStart, rangeEnd, opts...)
+}
+
+// AggregateByTimeBucket 按时间分桶计算任意指标
+func (r *MemoryRepository[T]) AggregateByTimeBucket(ctx context.Context, metric Metric, interval TimeBucket, rangeStart, rangeEnd time.Time, opts ...BucketOption) ([]TimeBucketPoint, error) {
+	o := &bucketOptions{timeColumn: "created_at", fill: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if err := validateColumn(o.timeColumn); err != nil {
+		return nil, err
+	}
+	if _, err := metric.expr(); err != nil {
+		return nil, err
+	}
+	if !rangeEnd.After(rangeStart) {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "rangeEnd must be after rangeStart")
+	}
+	switch interval {
+	case BucketHour, BucketDay, BucketWeek, BucketMonth:
+	default:
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "invalid time bucket interval: "+string(interval))
+	}
+
+	q, err := r.compile(ctx, nil, func(db *gorm.DB) *gorm.DB {
+		db = db.Where(o.timeColumn+" >= ? AND "+o.timeColumn+" < ?", rangeStart, rangeEnd)
+		if o.query != "" {
+			db = db.Where(o.query, o.args...)
+		}
+		return db
+	})
+	if err != nil {
+		return nil, err
+	}
+	models, err := r.find(ctx, q, false)
+	if err != nil {
+		return nil, err
+	}
+	times, err := r.columnValues(ctx, models, o.timeColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := rangeStart.Location()
+	buckets := make(map[time.Time][]*T)
+	for i, m := range models {
+		t, ok := times[i].(time.Time)
+		if !ok {
+			if t, ok = parseTime(stringValue(times[i])); !ok {
+				continue
+			}
+		}
+		bucket := truncateBucket(t.In(loc), interval)
+		buckets[bucket] = append(buckets[bucket], m)
+	}
+
+	values := make(map[time.Time]float64, len(buckets))
+	points := make([]TimeBucketPoint, 0, len(buckets))
+	for bucket, group := range buckets {
+		metricValues, err := r.metricValues(ctx, group, metric)
+		if err != nil {
+			return nil, err
+		}
+		v, _ := metric.compute(metricValues)
+		values[bucket] = v
+		points = append(points, TimeBucketPoint{Bucket: bucket, Value: v})
+	}
+	slices.SortFunc(points, func(a, b TimeBucketPoint) int { return a.Bucket.Compare(b.Bucket) })
+
+	if !o.fill {
+		return points, nil
+	}
+	filled := make([]TimeBucketPoint, 0, len(points))
+	for t := truncateBucket(rangeStart, interval); t.Before(rangeEnd); t = nextBucket(t, interval) {
+		filled = append(filled, TimeBucketPoint{Bucket: t, Value: values[t]})
+	}
+	return filled, nil
+}
@@ -0,0 +1,1115 @@
+package repository
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+/* ========================================================================
+ * Memory Conditions - 内存仓储的条件求值
+ * ========================================================================
+ * 职责: 将 GORM 构建出的 WHERE 子句（clause.Expr / Eq / IN / And / Or / Not 等）
+ *       编译为可在内存记录上求值的条件，采用 SQL 三值逻辑（NULL 参与比较结果为 NULL）
+ * 字符串条件语法:
+ *   - 比较: = != <> > >= < <=
+ *   - [NOT] IN (?, ...) / [NOT] IN ?、[NOT] LIKE / ILIKE、[NOT] BETWEEN ? AND ?
+ *   - IS [NOT] NULL、AND / OR / NOT、括号、LOWER() / UPPER()
+ *   - 字面量: 数字、'字符串'、TRUE / FALSE / NULL；列名可带表名前缀与引号
+ * ======================================================================== */
+
+// tri SQL 三值逻辑
+type tri int8
+
+const (
+	triFalse tri = iota
+	triTrue
+	triNull
+)
+
+func triOf(b bool) tri {
+	if b {
+		return triTrue
+	}
+	return triFalse
+}
+
+func (t tri) not() tri {
+	switch t {
+	case triTrue:
+		return triFalse
+	case triFalse:
+		return triTrue
+	default:
+		return triNull
+	}
+}
+
+func triAnd(a, b tri) tri {
+	if a == triFalse || b == triFalse {
+		return triFalse
+	}
+	if a == triNull || b == triNull {
+		return triNull
+	}
+	return triTrue
+}
+
+func triOr(a, b tri) tri {
+	if a == triTrue || b == triTrue {
+		return triTrue
+	}
+	if a == triNull || b == triNull {
+		return triNull
+	}
+	return triFalse
+}
+
+// memoryRecord 条件求值时的单条记录
+type memoryRecord struct {
+	ctx   context.Context
+	value reflect.Value
+}
+
+// lookupColumn 按列名或字段名查找字段
+func lookupColumn(s *schema.Schema, name string) (*schema.Field, error) {
+	if name == clause.PrimaryKey {
+		if s.PrioritizedPrimaryField == nil {
+			return nil, unsupportedCondition("model has no primary key")
+		}
+		return s.PrioritizedPrimaryField, nil
+	}
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Trim(name, "`\"[]")
+	if field := s.LookUpField(name); field != nil && field.DBName != "" {
+		return field, nil
+	}
+	return nil, errors.New(errors.ErrCodeInvalidArgument, "memory repository: unknown column "+name)
+}
+
+// unsupportedCondition 不支持的查询条件
+func unsupportedCondition(detail string) error {
+	return errors.New(errors.ErrCodeInvalidArgument, "memory repository: unsupported condition: "+detail)
+}
+
+// memoryCond 编译后的条件
+type memoryCond func(rec memoryRecord) (tri, error)
+
+// memoryOperand 编译后的操作数
+type memoryOperand func(rec memoryRecord) (any, error)
+
+// compileWhere 编译 WHERE 子句（与 clause.Where.Build 的拼接规则一致）
+func compileWhere(s *schema.Schema, exprs []clause.Expression) (memoryCond, error) {
+	exprs = append([]clause.Expression(nil), exprs...)
+	if len(exprs) == 1 {
+		if and, ok := exprs[0].(clause.AndConditions); ok {
+			exprs = and.Exprs
+		}
+	}
+	// 首个条件为单个 Or 时，与第一个非 Or 条件交换位置
+	for i, expr := range exprs {
+		if or, ok := expr.(clause.OrConditions); !ok || len(or.Exprs) > 1 {
+			exprs[0], exprs[i] = exprs[i], exprs[0]
+			break
+		}
+	}
+	return compileList(s, exprs)
+}
+
+// compileList 编译条件列表：单个 Or 条件以 OR 连接，其余以 AND 连接（AND 优先）
+func compileList(s *schema.Schema, exprs []clause.Expression) (memoryCond, error) {
+	var groups [][]memoryCond
+	for i, expr := range exprs {
+		or, isOr := expr.(clause.OrConditions)
+		if i == 0 || !isOr || len(or.Exprs) != 1 {
+			if len(groups) == 0 {
+				groups = append(groups, nil)
+			}
+		} else {
+			groups = append(groups, nil)
+		}
+		cond, err := compileExpr(s, expr)
+		if err != nil {
+			return nil, err
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], cond)
+	}
+
+	return func(rec memoryRecord) (tri, error) {
+		result := triFalse
+		if len(groups) == 0 {
+			return triTrue, nil
+		}
+		for _, group := range groups {
+			g := triTrue
+			for _, cond := range group {
+				v, err := cond(rec)
+				if err != nil {
+					return triNull, err
+				}
+				if g = triAnd(g, v); g == triFalse {
+					break
+				}
+			}
+			if result = triOr(result, g); result == triTrue {
+				break
+			}
+		}
+		return result, nil
+	}, nil
+}
+
+// compileExpr 编译单个 GORM 条件表达式
+func compileExpr(s *schema.Schema, expr clause.Expression) (memoryCond, error) {
+	switch v := expr.(type) {
+	case clause.Expr:
+		return parseSQLCondition(s, v.SQL, v.Vars)
+	case clause.AndConditions:
+		return compileList(s, v.Exprs)
+	case clause.OrConditions:
+		conds := make([]memoryCond, 0, len(v.Exprs))
+		for _, e := range v.Exprs {
+			cond, err := compileExpr(s, e)
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, cond)
+		}
+		return func(rec memoryRecord) (tri, error) {
+			result := triFalse
+			for _, cond := range conds {
+				r, err := cond(rec)
+				if err != nil {
+					return triNull, err
+				}
+				result = triOr(result, r)
+			}
+			return result, nil
+		}, nil
+	case clause.NotConditions:
+		return compileNot(s, v)
+	case clause.Eq:
+		return compileCompare(s, v.Column, "=", v.Value)
+	case clause.Neq:
+		return compileCompare(s, v.Column, "<>", v.Value)
+	case clause.Gt:
+		return compileCompare(s, v.Column, ">", v.Value)
+	case clause.Gte:
+		return compileCompare(s, v.Column, ">=", v.Value)
+	case clause.Lt:
+		return compileCompare(s, v.Column, "<", v.Value)
+	case clause.Lte:
+		return compileCompare(s, v.Column, "<=", v.Value)
+	case clause.Like:
+		col, err := columnOperand(s, v.Column)
+		if err != nil {
+			return nil, err
+		}
+		return likeCond(col, constOperand(v.Value), false), nil
+	case clause.IN:
+		col, err := columnOperand(s, v.Column)
+		if err != nil {
+			return nil, err
+		}
+		return inCond(col, constList(flattenValues(v.Values)), false), nil
+	default:
+		return nil, unsupportedCondition(fmt.Sprintf("%T", expr))
+	}
+}
+
+// compileNot 编译 NOT 条件（可取反的表达式逐个取反后 AND 连接，与 GORM 生成的 SQL 一致）
+func compileNot(s *schema.Schema, not clause.NotConditions) (memoryCond, error) {
+	anyNegation := false
+	for _, e := range not.Exprs {
+		if _, ok := e.(clause.NegationExpressionBuilder); ok {
+			anyNegation = true
+			break
+		}
+	}
+	if !anyNegation {
+		inner, err := compileList(s, not.Exprs)
+		if err != nil {
+			return nil, err
+		}
+		return func(rec memoryRecord) (tri, error) {
+			v, err := inner(rec)
+			return v.not(), err
+		}, nil
+	}
+
+	conds := make([]memoryCond, 0, len(not.Exprs))
+	for _, e := range not.Exprs {
+		cond, err := compileExpr(s, e)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	return func(rec memoryRecord) (tri, error) {
+		result := triTrue
+		for _, cond := range conds {
+			v, err := cond(rec)
+			if err != nil {
+				return triNull, err
+			}
+			result = triAnd(result, v.not())
+		}
+		return result, nil
+	}, nil
+}
+
+// compileCompare 编译 clause.Eq 等比较表达式（nil 对应 IS NULL，切片对应 IN）
+func compileCompare(s *schema.Schema, column any, op string, value any) (memoryCond, error) {
+	left, err := columnOperand(s, column)
+	if err != nil {
+		return nil, err
+	}
+	if op == "=" || op == "<>" {
+		negate := op == "<>"
+		if value == nil || isNilPointer(value) {
+			return nullCond(left, negate), nil
+		}
+		if values, ok := sliceValues(value); ok {
+			return inCond(left, constList(values), negate), nil
+		}
+	}
+	return compareCond(left, op, constOperand(value)), nil
+}
+
+// columnName 提取 clause 中的列名
+func columnName(column any) (string, error) {
+	switch c := column.(type) {
+	case string:
+		return c, nil
+	case clause.Column:
+		if c.Raw {
+			return "", unsupportedCondition("raw column " + c.Name)
+		}
+		return c.Name, nil
+	default:
+		return "", unsupportedCondition(fmt.Sprintf("column type %T", column))
+	}
+}
+
+// columnOperand 列操作数（编译时解析字段，未知列立即报错）
+func columnOperand(s *schema.Schema, column any) (memoryOperand, error) {
+	name, err := columnName(column)
+	if err != nil {
+		return nil, err
+	}
+	field, err := lookupColumn(s, name)
+	if err != nil {
+		return nil, err
+	}
+	return func(rec memoryRecord) (any, error) {
+		v, _ := field.ValueOf(rec.ctx, rec.value)
+		return normalizeValue(v), nil
+	}, nil
+}
+
+func constOperand(v any) memoryOperand {
+	v = normalizeValue(v)
+	return func(memoryRecord) (any, error) { return v, nil }
+}
+
+func constList(values []any) []memoryOperand {
+	ops := make([]memoryOperand, len(values))
+	for i, v := range values {
+		ops[i] = constOperand(v)
+	}
+	return ops
+}
+
+// compareCond 比较条件，任一侧为 NULL 或类型不可比较时结果为 NULL
+func compareCond(left memoryOperand, op string, right memoryOperand) memoryCond {
+	return func(rec memoryRecord) (tri, error) {
+		l, err := left(rec)
+		if err != nil {
+			return triNull, err
+		}
+		r, err := right(rec)
+		if err != nil {
+			return triNull, err
+		}
+		c, ok := compareValues(l, r)
+		if !ok {
+			return triNull, nil
+		}
+		switch op {
+		case "=":
+			return triOf(c == 0), nil
+		case "!=", "<>":
+			return triOf(c != 0), nil
+		case ">":
+			return triOf(c > 0), nil
+		case ">=":
+			return triOf(c >= 0), nil
+		case "<":
+			return triOf(c < 0), nil
+		default: // <=
+			return triOf(c <= 0), nil
+		}
+	}
+}
+
+// nullCond IS [NOT] NULL
+func nullCond(operand memoryOperand, negate bool) memoryCond {
+	return func(rec memoryRecord) (tri, error) {
+		v, err := operand(rec)
+		if err != nil {
+			return triNull, err
+		}
+		return triOf((v == nil) != negate), nil
+	}
+}
+
+// inCond [NOT] IN，空列表恒为 false
+func inCond(operand memoryOperand, list []memoryOperand, negate bool) memoryCond {
+	return func(rec memoryRecord) (tri, error) {
+		v, err := operand(rec)
+		if err != nil {
+			return triNull, err
+		}
+		result := triFalse
+		for _, item := range list {
+			iv, err := item(rec)
+			if err != nil {
+				return triNull, err
+			}
+			c, ok := compareValues(v, iv)
+			switch {
+			case !ok:
+				result = triOr(result, triNull)
+			case c == 0:
+				result = triTrue
+			}
+			if result == triTrue {
+				break
+			}
+		}
+		if negate {
+			return result.not(), nil
+		}
+		return result, nil
+	}
+}
+
+// likeCond [NOT] LIKE（不区分大小写，与 sqlite / MySQL 默认排序规则一致）
+func likeCond(operand, pattern memoryOperand, negate bool) memoryCond {
+	return func(rec memoryRecord) (tri, error) {
+		v, err := operand(rec)
+		if err != nil {
+			return triNull, err
+		}
+		p, err := pattern(rec)
+		if err != nil {
+			return triNull, err
+		}
+		if v == nil || p == nil {
+			return triNull, nil
+		}
+		matched := likeMatch(strings.ToLower(stringValue(v)), strings.ToLower(stringValue(p)))
+		return triOf(matched != negate), nil
+	}
+}
+
+// likeMatch LIKE 模式匹配（% 任意长度，_ 单个字符）
+func likeMatch(s, pattern string) bool {
+	sr, pr := []rune(s), []rune(pattern)
+	// 动态规划：dp[j] 表示 pattern[:i] 是否匹配 s[:j]
+	dp := make([]bool, len(sr)+1)
+	dp[0] = true
+	for _, pc := range pr {
+		next := make([]bool, len(sr)+1)
+		if pc == '%' {
+			next[0] = dp[0]
+			for j := 1; j <= len(sr); j++ {
+				next[j] = next[j-1] || dp[j]
+			}
+		} else {
+			for j := 1; j <= len(sr); j++ {
+				next[j] = dp[j-1] && (pc == '_' || pc == sr[j-1])
+			}
+		}
+		dp = next
+	}
+	return dp[len(sr)]
+}
+
+// betweenCond [NOT] BETWEEN low AND high
+func betweenCond(operand, low, high memoryOperand, negate bool) memoryCond {
+	ge := compareCond(operand, ">=", low)
+	le := compareCond(operand, "<=", high)
+	return func(rec memoryRecord) (tri, error) {
+		a, err := ge(rec)
+		if err != nil {
+			return triNull, err
+		}
+		b, err := le(rec)
+		if err != nil {
+			return triNull, err
+		}
+		result := triAnd(a, b)
+		if negate {
+			return result.not(), nil
+		}
+		return result, nil
+	}
+}
+
+// truthCond 单独的操作数作为条件（如布尔列）
+func truthCond(operand memoryOperand) memoryCond {
+	return func(rec memoryRecord) (tri, error) {
+		v, err := operand(rec)
+		if err != nil || v == nil {
+			return triNull, err
+		}
+		f, ok := toFloat(v)
+		if !ok {
+			return triFalse, nil
+		}
+		return triOf(f != 0), nil
+	}
+}
+
+/* ========================================================================
+ * 字符串条件解析
+ * ======================================================================== */
+
+type sqlTokenKind int
+
+const (
+	tokIdent sqlTokenKind = iota
+	tokVar
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type sqlToken struct {
+	kind sqlTokenKind
+	text string
+}
+
+// tokenizeSQL 拆分条件字符串
+func tokenizeSQL(s string) ([]sqlToken, error) {
+	var toks []sqlToken
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		c := rs[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '?':
+			toks = append(toks, sqlToken{kind: tokVar, text: "?"})
+			i++
+		case c == '(':
+			toks = append(toks, sqlToken{kind: tokLParen, text: "("})
+			i++
+		case c == ')':
+			toks = append(toks, sqlToken{kind: tokRParen, text: ")"})
+			i++
+		case c == ',':
+			toks = append(toks, sqlToken{kind: tokComma, text: ","})
+			i++
+		case c == '\'':
+			var b strings.Builder
+			i++
+			for {
+				if i >= len(rs) {
+					return nil, unsupportedCondition("unterminated string in " + s)
+				}
+				if rs[i] == '\'' {
+					if i+1 < len(rs) && rs[i+1] == '\'' {
+						b.WriteRune('\'')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteRune(rs[i])
+				i++
+			}
+			toks = append(toks, sqlToken{kind: tokString, text: b.String()})
+		case strings.ContainsRune("=!<>", c):
+			j := i + 1
+			if j < len(rs) && strings.ContainsRune("=<>", rs[j]) {
+				j++
+			}
+			op := string(rs[i:j])
+			switch op {
+			case "=", "!=", "<>", "<", "<=", ">", ">=":
+			default:
+				return nil, unsupportedCondition("operator " + op)
+			}
+			toks = append(toks, sqlToken{kind: tokOp, text: op})
+			i = j
+		case unicode.IsDigit(c) || (c == '-' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i + 1
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			toks = append(toks, sqlToken{kind: tokNumber, text: string(rs[i:j])})
+			i = j
+		case unicode.IsLetter(c) || c == '_' || c == '`' || c == '"':
+			j := i
+			for j < len(rs) && (unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j]) || strings.ContainsRune("_.`\"", rs[j])) {
+				j++
+			}
+			toks = append(toks, sqlToken{kind: tokIdent, text: string(rs[i:j])})
+			i = j
+		case c == '@':
+			return nil, unsupportedCondition("named parameters in " + s)
+		default:
+			return nil, unsupportedCondition(fmt.Sprintf("character %q in %s", c, s))
+		}
+	}
+	return toks, nil
+}
+
+// sqlParser 递归下降解析器，解析时按顺序消费占位符参数
+type sqlParser struct {
+	schema *schema.Schema
+	sql    string
+	toks   []sqlToken
+	pos    int
+	vars   []any
+	next   int
+}
+
+// parseSQLCondition 解析字符串条件
+func parseSQLCondition(s *schema.Schema, sql string, vars []any) (memoryCond, error) {
+	if strings.TrimSpace(sql) == "" {
+		return func(memoryRecord) (tri, error) { return triTrue, nil }, nil
+	}
+	toks, err := tokenizeSQL(sql)
+	if err != nil {
+		return nil, err
+	}
+	p := &sqlParser{schema: s, sql: sql, toks: toks, vars: vars}
+	cond, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, unsupportedCondition("unexpected " + p.toks[p.pos].text + " in " + sql)
+	}
+	if p.next != len(vars) {
+		return nil, unsupportedCondition(fmt.Sprintf("%d placeholders but %d args in %s", p.next, len(vars), sql))
+	}
+	return cond, nil
+}
+
+func (p *sqlParser) peek() (sqlToken, bool) {
+	if p.pos >= len(p.toks) {
+		return sqlToken{}, false
+	}
+	return p.toks[p.pos], true
+}
+
+// keyword 当前 token 为指定关键字时消费并返回 true
+func (p *sqlParser) keyword(words ...string) bool {
+	for i, w := range words {
+		if p.pos+i >= len(p.toks) {
+			return false
+		}
+		tok := p.toks[p.pos+i]
+		if tok.kind != tokIdent || !strings.EqualFold(tok.text, w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+func (p *sqlParser) expect(kind sqlTokenKind, text string) error {
+	tok, ok := p.peek()
+	if !ok || tok.kind != kind {
+		return unsupportedCondition("expected " + text + " in " + p.sql)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *sqlParser) nextVar() (any, error) {
+	if p.next >= len(p.vars) {
+		return nil, unsupportedCondition("not enough args for " + p.sql)
+	}
+	v := p.vars[p.next]
+	p.next++
+	return v, nil
+}
+
+func (p *sqlParser) parseOr() (memoryCond, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(rec memoryRecord) (tri, error) {
+			a, err := l(rec)
+			if err != nil || a == triTrue {
+				return a, err
+			}
+			b, err := right(rec)
+			return triOr(a, b), err
+		}
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseAnd() (memoryCond, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(rec memoryRecord) (tri, error) {
+			a, err := l(rec)
+			if err != nil || a == triFalse {
+				return a, err
+			}
+			b, err := right(rec)
+			return triAnd(a, b), err
+		}
+	}
+	return left, nil
+}
+
+func (p *sqlParser) parseNot() (memoryCond, error) {
+	if p.keyword("NOT") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(rec memoryRecord) (tri, error) {
+			v, err := inner(rec)
+			return v.not(), err
+		}, nil
+	}
+	return p.parsePredicate()
+}
+
+func (p *sqlParser) parsePredicate() (memoryCond, error) {
+	if tok, ok := p.peek(); ok && tok.kind == tokLParen {
+		p.pos++
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return cond, p.expect(tokRParen, ")")
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.keyword("IS") {
+		negate := p.keyword("NOT")
+		if !p.keyword("NULL") {
+			return nil, unsupportedCondition("expected NULL after IS in " + p.sql)
+		}
+		return nullCond(left, negate), nil
+	}
+
+	negate := p.keyword("NOT")
+	switch {
+	case p.keyword("IN"):
+		list, err := p.parseInList()
+		if err != nil {
+			return nil, err
+		}
+		return inCond(left, list, negate), nil
+	case p.keyword("LIKE"), p.keyword("ILIKE"):
+		pattern, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return likeCond(left, pattern, negate), nil
+	case p.keyword("BETWEEN"):
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if !p.keyword("AND") {
+			return nil, unsupportedCondition("expected AND in BETWEEN in " + p.sql)
+		}
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return betweenCond(left, low, high, negate), nil
+	case negate:
+		return nil, unsupportedCondition("unexpected NOT in " + p.sql)
+	}
+
+	if tok, ok := p.peek(); ok && tok.kind == tokOp {
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareCond(left, tok.text, right), nil
+	}
+	return truthCond(left), nil
+}
+
+// parseInList 解析 IN 后的 ? 或 (a, b, ...)
+func (p *sqlParser) parseInList() ([]memoryOperand, error) {
+	tok, ok := p.peek()
+	if ok && tok.kind == tokVar {
+		p.pos++
+		v, err := p.nextVar()
+		if err != nil {
+			return nil, err
+		}
+		if values, ok := sliceValues(v); ok {
+			return constList(values), nil
+		}
+		return []memoryOperand{constOperand(v)}, nil
+	}
+	if err := p.expect(tokLParen, "( after IN"); err != nil {
+		return nil, err
+	}
+	var list []memoryOperand
+	for {
+		if tok, ok := p.peek(); ok && tok.kind == tokVar {
+			// (?) 中的切片参数展开
+			p.pos++
+			v, err := p.nextVar()
+			if err != nil {
+				return nil, err
+			}
+			if values, ok := sliceValues(v); ok {
+				list = append(list, constList(values)...)
+			} else {
+				list = append(list, constOperand(v))
+			}
+		} else {
+			op, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, op)
+		}
+		tok, ok := p.peek()
+		if ok && tok.kind == tokComma {
+			p.pos++
+			continue
+		}
+		return list, p.expect(tokRParen, ")")
+	}
+}
+
+func (p *sqlParser) parseOperand() (memoryOperand, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, unsupportedCondition("unexpected end of " + p.sql)
+	}
+	p.pos++
+	switch tok.kind {
+	case tokVar:
+		v, err := p.nextVar()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := v.(clause.Expression); ok {
+			return nil, unsupportedCondition("sub-expression args in " + p.sql)
+		}
+		if reflect.TypeOf(v) != nil && reflect.TypeOf(v).String() == "*gorm.DB" {
+			return nil, unsupportedCondition("sub-query in " + p.sql)
+		}
+		return constOperand(v), nil
+	case tokNumber:
+		if n, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return constOperand(n), nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, unsupportedCondition("number " + tok.text)
+		}
+		return constOperand(f), nil
+	case tokString:
+		return constOperand(tok.text), nil
+	case tokIdent:
+		switch strings.ToUpper(tok.text) {
+		case "NULL":
+			return constOperand(nil), nil
+		case "TRUE":
+			return constOperand(true), nil
+		case "FALSE":
+			return constOperand(false), nil
+		}
+		if next, ok := p.peek(); ok && next.kind == tokLParen {
+			return p.parseFunc(tok.text)
+		}
+		return columnOperand(p.schema, tok.text)
+	default:
+		return nil, unsupportedCondition("unexpected " + tok.text + " in " + p.sql)
+	}
+}
+
+// parseFunc 解析 LOWER(x) / UPPER(x)
+func (p *sqlParser) parseFunc(name string) (memoryOperand, error) {
+	var fn func(string) string
+	switch strings.ToUpper(name) {
+	case "LOWER":
+		fn = strings.ToLower
+	case "UPPER":
+		fn = strings.ToUpper
+	default:
+		return nil, unsupportedCondition("function " + name)
+	}
+	p.pos++ // (
+	arg, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(tokRParen, ")"); err != nil {
+		return nil, err
+	}
+	return func(rec memoryRecord) (any, error) {
+		v, err := arg(rec)
+		if err != nil || v == nil {
+			return nil, err
+		}
+		return fn(stringValue(v)), nil
+	}, nil
+}
+
+/* ========================================================================
+ * 值归一化与比较
+ * ======================================================================== */
+
+// normalizeValue 将字段值与参数统一为 nil / bool / int64 / float64 / string / time.Time
+// 指针解引用，fmt.Stringer（如 ULID）转为字符串，driver.Valuer 取 Value()
+func normalizeValue(v any) any {
+	for range 8 {
+		switch x := v.(type) {
+		case nil:
+			return nil
+		case time.Time:
+			return x
+		case string:
+			return x
+		case []byte:
+			return string(x)
+		case bool:
+			return x
+		}
+
+		rv := reflect.ValueOf(v)
+		switch rv.Kind() {
+		case reflect.Pointer, reflect.Interface:
+			if rv.IsNil() {
+				return nil
+			}
+			v = rv.Elem().Interface()
+			continue
+		case reflect.Bool:
+			return rv.Bool()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return rv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if u := rv.Uint(); u <= math.MaxInt64 {
+				return int64(u)
+			}
+			return float64(rv.Uint())
+		case reflect.Float32, reflect.Float64:
+			return rv.Float()
+		case reflect.String:
+			return rv.String()
+		}
+		if s, ok := v.(fmt.Stringer); ok {
+			return s.String()
+		}
+		if valuer, ok := v.(driver.Valuer); ok {
+			dv, err := valuer.Value()
+			if err != nil {
+				return fmt.Sprint(v)
+			}
+			v = dv
+			continue
+		}
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return string(rv.Bytes())
+		}
+		return fmt.Sprint(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// isNilPointer 是否为 nil 指针
+func isNilPointer(v any) bool {
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// sliceValues 切片参数展开为 []any（[]byte 与实现 driver.Valuer 的类型除外）
+func sliceValues(v any) ([]any, bool) {
+	if _, ok := v.(driver.Valuer); ok {
+		return nil, false
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	if rv.Type().Elem().Kind() == reflect.Uint8 {
+		return nil, false
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out, true
+}
+
+// flattenValues 展开 clause.IN 中嵌套的切片参数
+func flattenValues(values []any) []any {
+	out := make([]any, 0, len(values))
+	for _, v := range values {
+		if inner, ok := sliceValues(v); ok {
+			out = append(out, inner...)
+		} else {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// toFloat 数值（含布尔与数字字符串）转换为 float64
+func toFloat(v any) (float64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	case bool:
+		if x {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// stringValue 归一化值的字符串形式
+func stringValue(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// timeLayouts 字符串与时间比较时尝试的格式
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05", time.DateOnly}
+
+// compareValues 比较两个归一化值，类型不可比较或存在 NULL 时 ok 为 false
+func compareValues(a, b any) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	if ab, ok := a.(bool); ok {
+		a = boolInt(ab)
+	}
+	if bb, ok := b.(bool); ok {
+		b = boolInt(bb)
+	}
+
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return cmpOrdered(x, y), true
+		}
+	case time.Time:
+		switch y := b.(type) {
+		case time.Time:
+			return x.Compare(y), true
+		case string:
+			if t, ok := parseTime(y); ok {
+				return x.Compare(t), true
+			}
+			return 0, false
+		}
+	case string:
+		switch y := b.(type) {
+		case string:
+			return strings.Compare(x, y), true
+		case time.Time:
+			if t, ok := parseTime(x); ok {
+				return t.Compare(y), true
+			}
+			return 0, false
+		}
+	}
+
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA && okB {
+		return cmpOrdered(fa, fb), true
+	}
+	return strings.Compare(stringValue(a), stringValue(b)), true
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func cmpOrdered[V int64 | float64](a, b V) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func parseTime(s string) (time.Time, bool) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
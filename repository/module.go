@@ -19,4 +19,6 @@ var Module = fx.Module("repository",
 		// 占位
 		return nil
 	}),
+	// 跨仓储事务管理器（依赖 *gorm.DB）
+	fx.Provide(NewTxManager),
 )
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+/* ========================================================================
+ * Owner - 数据归属
+ * ========================================================================
+ * 职责: 按 owner_id / created_by 列记录数据归属用户，并支持仅本人可见的个人数据表
+ * 约定:
+ *   - 归属列优先 owner_id，其次 created_by（类型可为 ULID、*ULID 或 string）
+ *   - Create / CreateBatch / UpsertBatch 时，字段为零值则填充 TenantContext.UserID
+ *     （owner_id 与 created_by 均会填充）
+ *   - created_by 不可经 UpdateByID / UpsertBatch 修改
+ * 个人数据表:
+ *   - 模型实现 OwnerScoped 且 ScopeOwnerOnly() 返回 true 时，读取、更新与删除
+ *     只作用于归属当前用户的记录（管理员除外），缺少 UserID 时返回 Unauthenticated
+ *   - 适用于草稿、通知等；租户隔离与部门数据范围仍照常生效
+ * 说明: DataScopeSelf 使用同一归属列
+ *
+ * 使用示例:
+ *   type Draft struct {
+ *       ID       string      `gorm:"column:id;primaryKey"`
+ *       TenantID ulid.ULID   `gorm:"column:tenant_id"`
+ *       OwnerID  ulid.ULID   `gorm:"column:owner_id"`
+ *       Content  string      `gorm:"column:content"`
+ *   }
+ *
+ *   func (Draft) ScopeOwnerOnly() bool { return true }
+ * ======================================================================== */
+
+const (
+	ownerColumn     = "owner_id"
+	createdByColumn = "created_by"
+)
+
+// OwnerScoped 个人数据表，只允许归属用户访问
+type OwnerScoped interface {
+	ScopeOwnerOnly() bool
+}
+
+// ownerField 返回归属列（owner_id 优先，其次 created_by）
+func ownerField(s *schema.Schema) *schema.Field {
+	if f, ok := s.FieldsByDBName[ownerColumn]; ok {
+		return f
+	}
+	return s.FieldsByDBName[createdByColumn]
+}
+
+// isOwnerScoped 模型是否为个人数据表
+func (r *RepositoryImpl[T]) isOwnerScoped() bool {
+	scoped, ok := any(r.newModelPtr()).(OwnerScoped)
+	return ok && scoped.ScopeOwnerOnly()
+}
+
+// applyOwnerScope 个人数据表仅返回当前用户的记录
+func (r *RepositoryImpl[T]) applyOwnerScope(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if !r.isOwnerScoped() {
+		return db
+	}
+	tc, ok := TenantFromContext(ctx)
+	if !ok {
+		db.AddError(errors.ErrUnauthenticated)
+		return db
+	}
+	if tc.IsAdmin {
+		return db
+	}
+	return r.whereOwner(db, tc)
+}
+
+// whereOwner 按归属列过滤当前用户
+func (r *RepositoryImpl[T]) whereOwner(db *gorm.DB, tc TenantContext) *gorm.DB {
+	s, err := r.getSchema()
+	if err != nil {
+		db.AddError(err)
+		return db
+	}
+	field := ownerField(s)
+	if field == nil {
+		db.AddError(errors.New(errors.ErrCodeInvalidArgument, "owner scope requires an owner_id or created_by column"))
+		return db
+	}
+	if tc.UserID.IsZero() {
+		db.AddError(errors.New(errors.ErrCodeUnauthenticated, "owner scope requires user_id"))
+		return db
+	}
+	return db.Where(field.DBName+" = ?", ownerValue(field, tc))
+}
+
+// ownerValue 按列类型转换用户 ID
+func ownerValue(field *schema.Field, tc TenantContext) any {
+	if field.IndirectFieldType.Kind() == reflect.String {
+		return tc.UserID.String()
+	}
+	return tc.UserID
+}
+
+// setOwnerFields 为零值的 owner_id / created_by 填充当前用户
+func (r *RepositoryImpl[T]) setOwnerFields(ctx context.Context, model any) error {
+	tc, _ := TenantFromContext(ctx)
+	if tc.UserID.IsZero() {
+		if r.isOwnerScoped() && !tc.IsAdmin {
+			return errors.New(errors.ErrCodeUnauthenticated, "owner scope requires user_id")
+		}
+		return nil
+	}
+	s, err := r.getSchema()
+	if err != nil {
+		return err
+	}
+
+	rv := reflect.ValueOf(model)
+	for _, column := range []string{ownerColumn, createdByColumn} {
+		field, ok := s.FieldsByDBName[column]
+		if !ok {
+			continue
+		}
+		if _, zero := field.ValueOf(ctx, rv); !zero {
+			continue
+		}
+		value := ownerValue(field, tc)
+		if field.FieldType.Kind() == reflect.Ptr {
+			ptr := reflect.New(field.IndirectFieldType)
+			ptr.Elem().Set(reflect.ValueOf(value).Convert(field.IndirectFieldType))
+			value = ptr.Interface()
+		}
+		if err := field.Set(ctx, rv, value); err != nil {
+			return errors.Wrap(errors.ErrCodeInternal, "failed to set "+column, err)
+		}
+	}
+	return nil
+}
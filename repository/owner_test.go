@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+// draftModel 个人数据表
+type draftModel struct {
+	ID        string       `gorm:"column:id;type:char(26);primaryKey"`
+	TenantID  ulidv2.ULID  `gorm:"column:tenant_id;type:char(26);not null"`
+	OwnerID   string       `gorm:"column:owner_id;size:26"`
+	CreatedBy *ulidv2.ULID `gorm:"column:created_by;type:char(26)"`
+	Title     string       `gorm:"column:title"`
+}
+
+func (draftModel) ScopeOwnerOnly() bool { return true }
+
+func TestOwnerScope(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo Repository[draftModel], _ *gorm.DB) {
+		tenant, alice, bob := ulidv2.Make(), ulidv2.Make(), ulidv2.Make()
+		as := func(user ulidv2.ULID) context.Context {
+			return WithTenantContext(context.Background(), TenantContext{TenantID: tenant, UserID: user})
+		}
+
+		draft := &draftModel{ID: ulidv2.Make().String(), Title: "alice draft"}
+		if err := repo.Create(as(alice), draft); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if draft.OwnerID != alice.String() || draft.CreatedBy == nil || *draft.CreatedBy != alice {
+			t.Fatalf("owner not filled: %+v", draft)
+		}
+		if err := repo.Create(as(bob), &draftModel{ID: ulidv2.Make().String(), Title: "bob draft"}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+
+		if n, err := repo.Count(as(alice), ""); err != nil || n != 1 {
+			t.Fatalf("alice should see 1 draft, got %d %v", n, err)
+		}
+		_, err := repo.FindByID(as(bob), draft.ID)
+		expectCode(t, err, errors.ErrCodeNotFound)
+		expectCode(t, repo.UpdateByID(as(bob), draft.ID, map[string]any{"title": "x"}), errors.ErrCodeNotFound)
+		// created_by 不可修改
+		if err := repo.UpdateByID(as(alice), draft.ID, map[string]any{"title": "edited", "created_by": bob}); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+		got, err := repo.FindByID(as(alice), draft.ID)
+		if err != nil || got.Title != "edited" || *got.CreatedBy != alice {
+			t.Fatalf("unexpected draft %+v %v", got, err)
+		}
+
+		// 管理员不受限制；缺少用户时拒绝访问
+		admin := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, IsAdmin: true})
+		if n, _ := repo.Count(admin, ""); n != 2 {
+			t.Fatalf("admin should see 2 drafts, got %d", n)
+		}
+		_, err = repo.Count(as(ulidv2.ULID{}), "")
+		expectCode(t, err, errors.ErrCodeUnauthenticated)
+		expectCode(t, repo.Create(as(ulidv2.ULID{}), &draftModel{ID: ulidv2.Make().String()}), errors.ErrCodeUnauthenticated)
+	})
+}
+
+func TestDataScopeSelfUsesOwner(t *testing.T) {
+	repo := NewMemoryRepository[scopeDoc]()
+	tenant, dept, alice := ulidv2.Make(), ulidv2.Make(), ulidv2.Make()
+	ctx := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, DeptID: &dept, UserID: alice})
+	if err := repo.Create(ctx, &scopeDoc{ID: ulidv2.Make().String(), Name: "mine"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	admin := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, DeptID: &dept, IsAdmin: true})
+	if err := repo.Create(admin, &scopeDoc{ID: ulidv2.Make().String(), Name: "other"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	self := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, UserID: alice, DataScope: DataScopeSelf})
+	docs, err := repo.FindByQuery(self, "")
+	if err != nil || len(docs) != 1 || docs[0].Name != "mine" || docs[0].CreatedBy != alice {
+		t.Fatalf("unexpected docs %+v %v", docs, err)
+	}
+}
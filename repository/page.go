@@ -20,16 +20,9 @@ func (r *RepositoryImpl[T]) FindPage(ctx context.Context, page, pageSize int, qu
 
 // FindPageWithOpts 分页查询（带选项）
 func (r *RepositoryImpl[T]) FindPageWithOpts(ctx context.Context, page, pageSize int, query string, opts []Option, args ...any) (*PageResult[T], error) {
-	// 参数校验
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 {
-		pageSize = 10
-	}
-	if pageSize > 1000 {
-		pageSize = 1000 // 限制最大页大小
-	}
+	// 参数校验（限制最大页大小）
+	pr := PageRequest{Page: page, PageSize: pageSize}.Normalize()
+	page, pageSize = pr.Page, pr.PageSize
 
 	var opt *QueryOption
 	if len(opts) > 0 {
@@ -77,16 +70,9 @@ func (r *RepositoryImpl[T]) FindPageWithOpts(ctx context.Context, page, pageSize
 // FindPageByModel 根据模型条件分页查询
 // 用于复杂的 WHERE 条件场景
 func (r *RepositoryImpl[T]) FindPageByModel(ctx context.Context, page, pageSize int, model any, opts ...Option) (*PageResult[T], error) {
-	// 参数校验
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 {
-		pageSize = 10
-	}
-	if pageSize > 1000 {
-		pageSize = 1000
-	}
+	// 参数校验（限制最大页大小）
+	pr := PageRequest{Page: page, PageSize: pageSize}.Normalize()
+	page, pageSize = pr.Page, pr.PageSize
 
 	opt := ApplyOptions(opts)
 	db := r.buildQuery(ctx, opt)
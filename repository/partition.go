@@ -0,0 +1,413 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+/* ========================================================================
+ * Time Partition - 按月分表
+ * ========================================================================
+ * 职责: 为按月拆分的表（orders_202501 形式）选择读写的物理表
+ * 语义:
+ *   - 写入（Create/CreateBatch/Update/UpsertBatch）按记录时间列所在月份选择分表，
+ *     时间列为零值时按读取规则选择；批量写入跨多个分表时在同一事务中完成
+ *   - 其他操作（按 ID 查询/更新/删除、条件查询、聚合）使用 ctx 指定的月份
+ *     （WithPartitionTime），未指定时使用当前月份（GORM NowFunc）
+ *   - 月份按时间值自身的时区计算
+ *   - 跨月分页使用 PageAcrossPartitions，按时间列倒序依次读取各月分表
+ *   - 分表不会自动创建：使用 EnsurePartition 或 PartitionJob 提前创建
+ *
+ * 使用示例:
+ *   orders := repository.NewRepository[Order](db, repository.WithTableSuffixFromTime("created_at"))
+ *   err := orders.Create(ctx, order) // 写入 orders_<created_at 月份>
+ *
+ *   last := repository.WithPartitionTime(ctx, time.Now().AddDate(0, -1, 0))
+ *   o, err := orders.FindByID(last, id) // 读取上月分表
+ *
+ *   page, err := repository.PageAcrossPartitions[Order](ctx, orders, req,
+ *       time.Now().AddDate(0, -3, 0), time.Now(), repository.Eq[Order]("status", "paid"))
+ *
+ *   job := repository.NewPartitionJob(clk, 1, orders.(repository.Partitioned))
+ *   go job.Run(ctx, 24*time.Hour, func(err error) { log.Error("ensure partitions", zap.Error(err)) })
+ * ======================================================================== */
+
+// PartitionLayoutMonth 分表后缀格式（年月）
+const PartitionLayoutMonth = "200601"
+
+// RepositoryOption 仓储构造选项
+type RepositoryOption func(*repositoryOptions)
+
+type repositoryOptions struct {
+	partitionColumn string
+}
+
+// WithTableSuffixFromTime 按时间列所在月份分表，表名为 <模型表名>_<yyyymm>
+// column 须为 time.Time 或 *time.Time 类型的列
+func WithTableSuffixFromTime(column string) RepositoryOption {
+	return func(o *repositoryOptions) {
+		o.partitionColumn = column
+	}
+}
+
+// applyOptions 应用构造选项
+func (r *RepositoryImpl[T]) applyOptions(opts []RepositoryOption) {
+	var o repositoryOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	if o.partitionColumn != "" {
+		r.partition = r.newTimePartition(o.partitionColumn)
+	}
+}
+
+// timePartition 按月分表配置（解析失败时 err 在每次访问时返回）
+type timePartition struct {
+	column string
+	base   string
+	field  *schema.Field
+	err    error
+}
+
+func (r *RepositoryImpl[T]) newTimePartition(column string) *timePartition {
+	p := &timePartition{column: column}
+	s, err := r.getSchema()
+	if err != nil {
+		p.err = err
+		return p
+	}
+	p.base = s.Table
+	field := s.LookUpField(column)
+	switch {
+	case field == nil:
+		p.err = errors.New(errors.ErrCodeInvalidArgument, "unknown partition column: "+column)
+	case field.FieldType != reflect.TypeOf(time.Time{}) && field.FieldType != reflect.TypeOf(&time.Time{}):
+		p.err = errors.New(errors.ErrCodeInvalidArgument, "partition column must be time.Time: "+column)
+	default:
+		p.field = field
+		p.column = field.DBName
+	}
+	return p
+}
+
+// table 返回 t 所在月份的表名
+func (p *timePartition) table(t time.Time) string {
+	return p.base + "_" + t.Format(PartitionLayoutMonth)
+}
+
+// scope 将 db 指向 t 所在月份的分表
+func (p *timePartition) scope(db *gorm.DB, t time.Time) *gorm.DB {
+	if p.err != nil {
+		_ = db.AddError(p.err)
+		return db
+	}
+	return db.Table(p.table(t))
+}
+
+// timeOf 返回 model 时间列的值，零值时返回 ok=false
+func (p *timePartition) timeOf(ctx context.Context, model any) (time.Time, bool) {
+	if p.field == nil {
+		return time.Time{}, false
+	}
+	v, zero := p.field.ValueOf(ctx, reflect.ValueOf(model))
+	if zero {
+		return time.Time{}, false
+	}
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		return *t, t != nil
+	}
+	return time.Time{}, false
+}
+
+type partitionCtxKey struct{}
+
+// WithPartitionTime 指定读取/更新/删除使用的分表月份
+func WithPartitionTime(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, partitionCtxKey{}, t)
+}
+
+// partitionTime 返回 ctx 指定的分表时间，未指定时返回 now()
+func partitionTime(ctx context.Context, now func() time.Time) time.Time {
+	if t, ok := ctx.Value(partitionCtxKey{}).(time.Time); ok {
+		return t
+	}
+	return now()
+}
+
+// now 当前时间（与 GORM 自动时间戳一致）
+func (r *RepositoryImpl[T]) now() time.Time {
+	if r.db != nil && r.db.Config != nil && r.db.NowFunc != nil {
+		return r.db.NowFunc()
+	}
+	return time.Now()
+}
+
+// writeDB 返回写入 model 使用的 DB
+func (r *RepositoryImpl[T]) writeDB(ctx context.Context, model *T) (*gorm.DB, error) {
+	if r.partition == nil {
+		return r.withContext(ctx), nil
+	}
+	if r.partition.err != nil {
+		return nil, r.partition.err
+	}
+	t, ok := r.partition.timeOf(ctx, model)
+	if !ok {
+		t = partitionTime(ctx, r.now)
+	}
+	return r.partition.scope(r.connDB(ctx), t), nil
+}
+
+// eachPartition 按分表分组写入，跨多个分表时在同一事务中执行
+func (r *RepositoryImpl[T]) eachPartition(ctx context.Context, models []*T, fn func(db *gorm.DB, batch []*T) error) error {
+	if r.partition == nil {
+		return fn(r.withContext(ctx), models)
+	}
+	if r.partition.err != nil {
+		return r.partition.err
+	}
+
+	var tables []string
+	groups := make(map[string][]*T)
+	for _, m := range models {
+		t, ok := r.partition.timeOf(ctx, m)
+		if !ok {
+			t = partitionTime(ctx, r.now)
+		}
+		table := r.partition.table(t)
+		if _, seen := groups[table]; !seen {
+			tables = append(tables, table)
+		}
+		groups[table] = append(groups[table], m)
+	}
+	db := r.connDB(ctx)
+	if db.Error != nil {
+		return db.Error
+	}
+	if len(tables) == 1 {
+		return fn(db.Table(tables[0]), models)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, table := range tables {
+			if err := fn(tx.Table(table), groups[table]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+/* ========================================================================
+ * Partitioned - 分表管理
+ * ======================================================================== */
+
+// Partitioned 按时间分表的仓储（RepositoryImpl 实现，未配置分表时 PartitionColumn 为空）
+type Partitioned interface {
+	// PartitionColumn 分表时间列，空值表示未分表
+	PartitionColumn() string
+	// PartitionTable 返回 t 所在月份的表名
+	PartitionTable(t time.Time) (string, error)
+	// HasPartition t 所在月份的分表是否存在
+	HasPartition(ctx context.Context, t time.Time) (bool, error)
+	// EnsurePartition 创建 t 所在月份的分表（已存在时补齐表结构），返回表名
+	EnsurePartition(ctx context.Context, t time.Time) (string, error)
+}
+
+var _ Partitioned = (*RepositoryImpl[struct{}])(nil)
+
+// PartitionColumn 分表时间列
+func (r *RepositoryImpl[T]) PartitionColumn() string {
+	if r.partition == nil {
+		return ""
+	}
+	return r.partition.column
+}
+
+// PartitionTable 返回 t 所在月份的表名
+func (r *RepositoryImpl[T]) PartitionTable(t time.Time) (string, error) {
+	if r.partition == nil {
+		return "", errors.New(errors.ErrCodeInvalidArgument, "repository is not partitioned")
+	}
+	if r.partition.err != nil {
+		return "", r.partition.err
+	}
+	return r.partition.table(t), nil
+}
+
+// HasPartition t 所在月份的分表是否存在
+func (r *RepositoryImpl[T]) HasPartition(ctx context.Context, t time.Time) (bool, error) {
+	table, err := r.PartitionTable(t)
+	if err != nil {
+		return false, err
+	}
+	return r.connDB(ctx).Migrator().HasTable(table), nil
+}
+
+// EnsurePartition 创建 t 所在月份的分表
+func (r *RepositoryImpl[T]) EnsurePartition(ctx context.Context, t time.Time) (string, error) {
+	table, err := r.PartitionTable(t)
+	if err != nil {
+		return "", err
+	}
+	if err := r.connDB(ctx).Table(table).AutoMigrate(r.newModelPtr()); err != nil {
+		return "", errors.Wrap(errors.ErrCodeInternal, "failed to create partition "+table, err)
+	}
+	return table, nil
+}
+
+// monthStart 返回 t 所在月份的第一天零点
+func monthStart(t time.Time) time.Time {
+	y, m, _ := t.Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+}
+
+/* ========================================================================
+ * PageAcrossPartitions - 跨月分页
+ * ======================================================================== */
+
+// PageAcrossPartitions 在 [from, to) 范围内跨月分页查询（按分表时间列倒序）
+// 不存在的月份分表会被跳过；opts 中的排序会被时间列倒序覆盖
+func PageAcrossPartitions[T any](ctx context.Context, repo Repository[T], page PageRequest, from, to time.Time, spec Specification[T], opts ...Option) (*PageResult[T], error) {
+	p, ok := repo.(Partitioned)
+	if !ok || p.PartitionColumn() == "" {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "repository is not partitioned")
+	}
+	if !from.Before(to) {
+		return nil, errors.ErrInvalidArgument
+	}
+	page = page.Normalize()
+	column := p.PartitionColumn()
+	rangeSpec := And[T](Where[T](column+" >= ? AND "+column+" < ?", from, to), spec)
+	opts = append(slices.Clip(opts), WithOrderBy(column+" DESC"))
+
+	// 由新到旧统计各月记录数
+	type partition struct {
+		ctx   context.Context
+		count int64
+	}
+	var partitions []partition
+	var total int64
+	for month := monthStart(to.Add(-time.Nanosecond)); !month.Before(monthStart(from)); month = month.AddDate(0, -1, 0) {
+		exists, err := p.HasPartition(ctx, month)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+		monthCtx := WithPartitionTime(ctx, month)
+		n, err := repo.CountBySpec(monthCtx, rangeSpec)
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, partition{ctx: monthCtx, count: n})
+		total += n
+	}
+
+	result := &PageResult[T]{
+		List:     make([]T, 0, page.PageSize),
+		Total:    total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+		Pages:    (total + int64(page.PageSize) - 1) / int64(page.PageSize),
+	}
+	offset := int64(page.Page-1) * int64(page.PageSize)
+	for _, part := range partitions {
+		if len(result.List) == page.PageSize {
+			break
+		}
+		if offset >= part.count {
+			offset -= part.count
+			continue
+		}
+		limit, skip := page.PageSize-len(result.List), int(offset)
+		window := func(o *QueryOption) {
+			o.Scopes = append(o.Scopes, func(db *gorm.DB) *gorm.DB {
+				return db.Offset(skip).Limit(limit)
+			})
+		}
+		batch, err := repo.FindBySpec(part.ctx, rangeSpec, append(opts, window)...)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range batch {
+			result.List = append(result.List, *m)
+		}
+		offset = 0
+	}
+	return result, nil
+}
+
+/* ========================================================================
+ * PartitionJob - 预建分表
+ * ======================================================================== */
+
+// PartitionJob 定期创建当前月及之后 ahead 个月的分表
+type PartitionJob struct {
+	clock   clock.Clock
+	ahead   int
+	targets []Partitioned
+}
+
+// NewPartitionJob 创建预建分表任务（c 为 nil 时使用真实时钟）
+func NewPartitionJob(c clock.Clock, ahead int, targets ...Partitioned) *PartitionJob {
+	if ahead < 0 {
+		ahead = 0
+	}
+	return &PartitionJob{clock: clock.OrReal(c), ahead: ahead, targets: targets}
+}
+
+// RunOnce 创建当前月及之后 ahead 个月的分表，返回涉及的表名
+func (j *PartitionJob) RunOnce(ctx context.Context) ([]string, error) {
+	start := monthStart(j.clock.Now())
+	var tables []string
+	var errs []error
+	for _, target := range j.targets {
+		for i := 0; i <= j.ahead; i++ {
+			table, err := target.EnsurePartition(ctx, start.AddDate(0, i, 0))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("partition %s: %w", start.AddDate(0, i, 0).Format(PartitionLayoutMonth), err))
+				continue
+			}
+			tables = append(tables, table)
+		}
+	}
+	return tables, stderrors.Join(errs...)
+}
+
+// Run 立即执行一次，之后按 interval 周期执行，阻塞直到 ctx 取消
+func (j *PartitionJob) Run(ctx context.Context, interval time.Duration, onError func(error)) {
+	report := func(err error) {
+		if err != nil && onError != nil {
+			onError(err)
+		}
+	}
+	_, err := j.RunOnce(ctx)
+	report(err)
+
+	ticker := j.clock.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			_, err := j.RunOnce(ctx)
+			report(err)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// partitionOrder 按 created_at 月份分表的模型
+type partitionOrder struct {
+	ID        string      `gorm:"column:id;primaryKey"`
+	TenantID  ulidv2.ULID `gorm:"column:tenant_id;type:char(26)"`
+	Status    string      `gorm:"column:status"`
+	CreatedAt time.Time   `gorm:"column:created_at"`
+}
+
+func month(m time.Month, day int) time.Time {
+	return time.Date(2025, m, day, 12, 0, 0, 0, time.UTC)
+}
+
+func newPartitionRepo(t *testing.T, now time.Time) (Repository[partitionOrder], *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "partition.db")), &gorm.Config{
+		NowFunc: func() time.Time { return now },
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	return NewRepository[partitionOrder](db, WithTableSuffixFromTime("CreatedAt")), db
+}
+
+func TestTimePartition(t *testing.T) {
+	repo, db := newPartitionRepo(t, month(time.March, 20))
+	tenant := ulidv2.Make()
+	ctx := WithTenantContext(context.Background(), TenantContext{TenantID: tenant, IsAdmin: true})
+
+	clk := clock.NewFake(month(time.January, 10))
+	tables, err := NewPartitionJob(clk, 2, repo.(Partitioned)).RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("ensure partitions: %v", err)
+	}
+	want := []string{"partition_orders_202501", "partition_orders_202502", "partition_orders_202503"}
+	if !slices.Equal(tables, want) {
+		t.Fatalf("unexpected tables %v", tables)
+	}
+	if db.Migrator().HasTable("partition_orders") {
+		t.Fatal("base table must not be created")
+	}
+
+	// 批量写入按记录月份分组
+	err = repo.CreateBatch(ctx, []*partitionOrder{
+		{ID: "j1", Status: "paid", CreatedAt: month(time.January, 5)},
+		{ID: "f1", Status: "paid", CreatedAt: month(time.February, 3)},
+		{ID: "f2", Status: "open", CreatedAt: month(time.February, 9)},
+		{ID: "j2", Status: "paid", CreatedAt: month(time.January, 20)},
+	}, 10)
+	if err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	// 零值时间按当前月份（NowFunc）
+	if err := repo.Create(ctx, &partitionOrder{ID: "m1", Status: "paid"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for table, n := range map[string]int64{"partition_orders_202501": 2, "partition_orders_202502": 2, "partition_orders_202503": 1} {
+		var got int64
+		db.Table(table).Count(&got)
+		if got != n {
+			t.Fatalf("%s: expected %d rows, got %d", table, n, got)
+		}
+	}
+
+	// 读取默认当前月份，WithPartitionTime 指定月份
+	if _, err := repo.FindByID(ctx, "m1"); err != nil {
+		t.Fatalf("find current month: %v", err)
+	}
+	_, err = repo.FindByID(ctx, "f1")
+	expectCode(t, err, errors.ErrCodeNotFound)
+	feb := WithPartitionTime(ctx, month(time.February, 1))
+	got, err := repo.FindByID(feb, "f1")
+	if err != nil || got.Status != "paid" {
+		t.Fatalf("find february: %+v %v", got, err)
+	}
+	got.Status = "refunded"
+	if err := repo.Update(ctx, got); err != nil {
+		t.Fatalf("update by record month: %v", err)
+	}
+	if n, _ := repo.Count(feb, "status = ?", "refunded"); n != 1 {
+		t.Fatalf("expected update in february partition, got %d", n)
+	}
+
+	// 跨月分页：由新到旧，跳过不存在的分表
+	from, to := time.Date(2024, time.December, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC)
+	var ids []string
+	for page := 1; page <= 3; page++ {
+		result, err := PageAcrossPartitions[partitionOrder](ctx, repo, PageRequest{Page: page, PageSize: 2}, from, to, nil)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		if result.Total != 5 || result.Pages != 3 {
+			t.Fatalf("unexpected totals %+v", result)
+		}
+		for _, m := range result.List {
+			ids = append(ids, m.ID)
+		}
+	}
+	if !slices.Equal(ids, []string{"m1", "f2", "f1", "j2", "j1"}) {
+		t.Fatalf("unexpected order %v", ids)
+	}
+	result, err := PageAcrossPartitions[partitionOrder](ctx, repo, PageRequest{Page: 1, PageSize: 10},
+		month(time.January, 10), month(time.March, 1), Eq[partitionOrder]("status", "paid"))
+	if err != nil {
+		t.Fatalf("filtered page: %v", err)
+	}
+	if result.Total != 1 || result.List[0].ID != "j2" {
+		t.Fatalf("unexpected filtered page %+v", result)
+	}
+
+	_, err = PageAcrossPartitions[partitionOrder](ctx, NewMemoryRepository[partitionOrder](), PageRequest{}, from, to, nil)
+	expectCode(t, err, errors.ErrCodeInvalidArgument)
+}
+
+func TestTimePartitionInvalidColumn(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "partition.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	repo := NewRepository[partitionOrder](db, WithTableSuffixFromTime("status"))
+	ctx := WithTenantContext(context.Background(), TenantContext{TenantID: ulidv2.Make(), IsAdmin: true})
+	expectCode(t, repo.Create(ctx, &partitionOrder{ID: "x"}), errors.ErrCodeInvalidArgument)
+	_, err = repo.Count(ctx, "")
+	expectCode(t, err, errors.ErrCodeInvalidArgument)
+}
+
+func TestPartitionJobRun(t *testing.T) {
+	repo, db := newPartitionRepo(t, time.Now())
+	clk := clock.NewFake(month(time.November, 30))
+	job := NewPartitionJob(clk, 0, repo.(Partitioned))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		job.Run(ctx, 24*time.Hour, func(err error) { t.Errorf("job: %v", err) })
+	}()
+	clk.BlockUntil(1)
+	if !db.Migrator().HasTable("partition_orders_202511") {
+		t.Fatal("expected current month partition")
+	}
+	clk.Advance(24 * time.Hour)
+	deadline := time.Now().Add(5 * time.Second)
+	for !db.Migrator().HasTable("partition_orders_202512") {
+		if time.Now().After(deadline) {
+			t.Fatal("expected next month partition after tick")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 
+	"github.com/aisgo/ais-go-pkg/errors"
+
 	"gorm.io/gorm"
 )
 
@@ -60,7 +62,7 @@ func (r *RepositoryImpl[T]) FindByID(ctx context.Context, id string, opts ...Opt
 
 	query := r.buildQuery(ctx, opt)
 	if err := query.Where("id = ?", id).First(model).Error; err != nil {
-		return nil, err
+		return nil, errors.FromGORM(err)
 	}
 
 	return model, nil
@@ -77,7 +79,7 @@ func (r *RepositoryImpl[T]) FindByIDs(ctx context.Context, ids []string, opts ..
 
 	query := r.buildQuery(ctx, opt)
 	if err := query.Where("id IN ?", ids).Find(&models).Error; err != nil {
-		return nil, err
+		return nil, errors.FromGORM(err)
 	}
 
 	return models, nil
@@ -103,7 +105,7 @@ func (r *RepositoryImpl[T]) FindOneWithOpts(ctx context.Context, query string, o
 	db := r.buildQuery(ctx, opt)
 
 	if err := db.Where(query, args...).First(model).Error; err != nil {
-		return nil, err
+		return nil, errors.FromGORM(err)
 	}
 
 	return model, nil
@@ -129,7 +131,7 @@ func (r *RepositoryImpl[T]) FindByQueryWithOpts(ctx context.Context, query strin
 	db := r.buildQuery(ctx, opt)
 
 	if err := db.Where(query, args...).Find(&models).Error; err != nil {
-		return nil, err
+		return nil, errors.FromGORM(err)
 	}
 
 	return models, nil
@@ -145,7 +147,7 @@ func (r *RepositoryImpl[T]) Count(ctx context.Context, query string, args ...any
 	db := r.applyTenantScope(ctx, r.withContext(ctx))
 
 	if err := db.Model(r.newModelPtr()).Where(query, args...).Count(&count).Error; err != nil {
-		return 0, err
+		return 0, errors.FromGORM(err)
 	}
 
 	return count, nil
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * DB Router - 按请求选择物理库
+ * ========================================================================
+ * 职责: 让仓储在每次操作时按 ctx（租户）获取对应的 *gorm.DB，实现按租户分库/分 schema
+ * 语义:
+ *   - ctx 中存在事务时始终使用事务 DB（事务由对应租户的 DB 开启）
+ *   - 忽略租户的模型（TenantIgnorable）使用 Default 控制库
+ *   - 路由失败（如缺少租户上下文、租户未分配）作为本次操作的错误返回
+ * 实现: 见 database/sharding
+ *
+ * 使用示例:
+ *   router := sharding.NewRouter(resolver, registry, controlDB)
+ *   orders := repository.NewRoutedRepository[Order](router)
+ *   tm := repository.NewRoutedTxManager(router)
+ * ======================================================================== */
+
+// DBRouter 按请求选择物理库
+type DBRouter interface {
+	// Default 控制库，用于解析模型与读写不区分租户的模型
+	Default() *gorm.DB
+	// DB 返回 ctx 中租户对应的 DB
+	DB(ctx context.Context) (*gorm.DB, error)
+}
+
+// NewRoutedRepository 创建按 router 选择物理库的仓储
+func NewRoutedRepository[T any](router DBRouter, opts ...RepositoryOption) Repository[T] {
+	r := &RepositoryImpl[T]{db: router.Default(), router: router, hooks: &repoHooks[T]{}}
+	r.applyOptions(opts)
+	return r
+}
+
+// routeDB 返回 ctx 对应的 DB，路由失败时返回携带错误的 DB（基于 fallback）
+func routeDB(ctx context.Context, router DBRouter, fallback *gorm.DB) *gorm.DB {
+	db, err := router.DB(ctx)
+	if err != nil {
+		errDB := fallback.Session(&gorm.Session{NewDB: true, Context: ctx})
+		_ = errDB.AddError(err)
+		return errDB
+	}
+	return db.WithContext(ctx)
+}
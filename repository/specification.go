@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"reflect"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+/* ========================================================================
+ * Specification - 可组合的查询规约
+ * ========================================================================
+ * 职责: 将业务过滤条件封装为可组合、可测试的对象，替代拼接 SQL 字符串
+ * 组合: And / Or / Not 以括号分组组合子规约
+ * 注意:
+ *   - 参与 Or / Not 组合的规约应只添加 WHERE 条件（Joins/Order 等在分组中会被忽略）
+ *   - 类型参数无法从参数推断，组合函数需显式指定，如 And[User](...)
+ *
+ * 使用示例:
+ *   active := repository.Eq[User]("status", "active")
+ *   vip := repository.SpecFunc[User](func(db *gorm.DB) *gorm.DB {
+ *       return db.Where("level >= ?", 3)
+ *   })
+ *   spec := repository.And[User](active, repository.Or[User](vip, repository.In[User]("id", ids)))
+ *   users, err := repo.FindBySpec(ctx, spec, repository.WithOrderBy("created_at DESC"))
+ * ======================================================================== */
+
+// Specification 查询规约
+type Specification[T any] interface {
+	Apply(db *gorm.DB) *gorm.DB
+}
+
+// SpecFunc 函数形式的规约
+type SpecFunc[T any] func(db *gorm.DB) *gorm.DB
+
+// Apply 实现 Specification
+func (f SpecFunc[T]) Apply(db *gorm.DB) *gorm.DB {
+	return f(db)
+}
+
+// Where 条件规约
+func Where[T any](query any, args ...any) Specification[T] {
+	return SpecFunc[T](func(db *gorm.DB) *gorm.DB {
+		return db.Where(query, args...)
+	})
+}
+
+// Eq 等值规约（列名自动转义）
+func Eq[T any](column string, value any) Specification[T] {
+	return SpecFunc[T](func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.Eq{Column: clause.Column{Name: column}, Value: value})
+	})
+}
+
+// In IN 规约（列名自动转义）
+func In[T any](column string, values any) Specification[T] {
+	return SpecFunc[T](func(db *gorm.DB) *gorm.DB {
+		return db.Where(clause.IN{Column: clause.Column{Name: column}, Values: toValues(values)})
+	})
+}
+
+// toValues 将切片转换为 []any，非切片值视为单个元素
+func toValues(values any) []any {
+	rv := reflect.ValueOf(values)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return []any{values}
+	}
+	out := make([]any, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+// And 与组合
+func And[T any](specs ...Specification[T]) Specification[T] {
+	return SpecFunc[T](func(db *gorm.DB) *gorm.DB {
+		for _, spec := range specs {
+			if spec != nil {
+				db = db.Where(spec.Apply(newCondDB(db)))
+			}
+		}
+		return db
+	})
+}
+
+// Or 或组合
+func Or[T any](specs ...Specification[T]) Specification[T] {
+	return SpecFunc[T](func(db *gorm.DB) *gorm.DB {
+		group := newCondDB(db)
+		first := true
+		for _, spec := range specs {
+			if spec == nil {
+				continue
+			}
+			cond := spec.Apply(newCondDB(db))
+			if first {
+				group = group.Where(cond)
+				first = false
+			} else {
+				group = group.Or(cond)
+			}
+		}
+		if first {
+			return db
+		}
+		return db.Where(group)
+	})
+}
+
+// Not 取反
+func Not[T any](spec Specification[T]) Specification[T] {
+	return SpecFunc[T](func(db *gorm.DB) *gorm.DB {
+		if spec == nil {
+			return db
+		}
+		return db.Not(spec.Apply(newCondDB(db)))
+	})
+}
+
+// newCondDB 创建仅用于收集条件的 DB
+func newCondDB(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true})
+}
+
+// applySpec 应用规约，nil 表示不过滤
+func applySpec[T any](db *gorm.DB, spec Specification[T]) *gorm.DB {
+	if spec == nil {
+		return db
+	}
+	return spec.Apply(db)
+}
+
+// FindBySpec 按规约查询多条记录
+func (r *RepositoryImpl[T]) FindBySpec(ctx context.Context, spec Specification[T], opts ...Option) ([]*T, error) {
+	var models []*T
+	db := applySpec[T](r.buildQuery(ctx, ApplyOptions(opts)), spec)
+	if err := db.Find(&models).Error; err != nil {
+		return nil, errors.FromGORM(err)
+	}
+	return models, nil
+}
+
+// CountBySpec 按规约统计记录数
+func (r *RepositoryImpl[T]) CountBySpec(ctx context.Context, spec Specification[T]) (int64, error) {
+	var count int64
+	db := applySpec[T](r.applyTenantScope(ctx, r.withContext(ctx)).Model(r.newModelPtr()), spec)
+	if err := db.Count(&count).Error; err != nil {
+		return 0, errors.FromGORM(err)
+	}
+	return count, nil
+}
+
+// PageBySpec 按规约分页查询
+func (r *RepositoryImpl[T]) PageBySpec(ctx context.Context, page PageRequest, spec Specification[T], opts ...Option) (*PageResult[T], error) {
+	page = page.Normalize()
+	db := applySpec[T](r.buildQuery(ctx, ApplyOptions(opts)), spec)
+
+	var total int64
+	if err := db.Model(r.newModelPtr()).Count(&total).Error; err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInternal, "failed to count records", err)
+	}
+
+	var list []T
+	if err := db.Offset((page.Page - 1) * page.PageSize).Limit(page.PageSize).Find(&list).Error; err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInternal, "failed to find records", err)
+	}
+
+	return &PageResult[T]{
+		List:     list,
+		Total:    total,
+		Page:     page.Page,
+		PageSize: page.PageSize,
+		Pages:    int64(math.Ceil(float64(total) / float64(page.PageSize))),
+	}, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+)
+
+type specTestModel struct {
+	ID     string `gorm:"column:id;type:char(26);primaryKey"`
+	Name   string `gorm:"column:name"`
+	Status string `gorm:"column:status"`
+	Level  int    `gorm:"column:level"`
+}
+
+func (specTestModel) TenantIgnored() bool {
+	return true
+}
+
+func TestSpecificationCombinators(t *testing.T) {
+	db := openTxTestDB(t)
+	if err := db.AutoMigrate(&specTestModel{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewRepository[specTestModel](db)
+	ctx := context.Background()
+
+	seed := []specTestModel{
+		{Name: "a", Status: "active", Level: 1},
+		{Name: "b", Status: "active", Level: 5},
+		{Name: "c", Status: "disabled", Level: 5},
+		{Name: "d", Status: "active", Level: 2},
+	}
+	for i := range seed {
+		seed[i].ID = ulidv2.Make().String()
+		if err := repo.Create(ctx, &seed[i]); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	active := Eq[specTestModel]("status", "active")
+	vip := SpecFunc[specTestModel](func(db *gorm.DB) *gorm.DB {
+		return db.Where("level >= ?", 3)
+	})
+
+	// active AND (vip OR name IN (a))
+	spec := And[specTestModel](active, Or[specTestModel](vip, In[specTestModel]("name", []string{"a"})))
+	list, err := repo.FindBySpec(ctx, spec, WithOrderBy("name ASC"))
+	if err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "a" || list[1].Name != "b" {
+		t.Fatalf("unexpected result: %+v", list)
+	}
+
+	count, err := repo.CountBySpec(ctx, Not[specTestModel](active))
+	if err != nil || count != 1 {
+		t.Fatalf("expected 1 inactive row, got %d %v", count, err)
+	}
+
+	page, err := repo.PageBySpec(ctx, PageRequest{Page: 2, PageSize: 2}, active, WithOrderBy("name ASC"))
+	if err != nil {
+		t.Fatalf("page: %v", err)
+	}
+	if page.Total != 3 || page.Pages != 2 || len(page.List) != 1 || page.List[0].Name != "d" {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	if count, _ := repo.CountBySpec(ctx, nil); count != 4 {
+		t.Fatalf("expected nil spec to match all rows, got %d", count)
+	}
+}
@@ -20,6 +20,12 @@ type TenantContext struct {
 	// 管理员可以跨部门访问租户内的所有数据
 	IsAdmin bool
 
+	// DataScope 非管理员的数据范围，默认本部门（见 DataScope）
+	DataScope DataScope
+
+	// DeptIDs 自定义数据范围的部门列表（DataScopeCustom 使用）
+	DeptIDs []ulidv2.ULID
+
 	// PolicyVersion 权限策略版本号（预留字段）
 	// 用于缓存失效和权限变更检测，当前版本未使用
 	PolicyVersion int64
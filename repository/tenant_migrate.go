@@ -0,0 +1,314 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+/* ========================================================================
+ * Tenant Migration - 租户数据迁移
+ * ========================================================================
+ * 职责: 合并租户、拆分部门等管理操作，替代绕过仓储的手写 SQL
+ * 语义:
+ *   - 全部读写经由 Repository 完成：租户范围、钩子、唯一约束检查照常生效
+ *   - 按主键顺序分批处理，每批在独立事务（repo.Execute）中提交，批次完成后回调进度；
+ *     ctx 已处于事务中时整个迁移加入该事务
+ *   - 已软删除的记录不参与迁移
+ * CopyTenantData:
+ *   - 复制 fromTenant 中匹配 filter 的记录到 toTenant，部门保持不变
+ *   - 单一主键（非 tenant_id）会被清零，由模型钩子（如 BaseModel）或自增重新生成；
+ *     其他需要改写的字段（字符串主键、全局唯一编码等）通过 WithCopyTransform 处理
+ * ReassignDept:
+ *   - 将租户内 fromDept 中匹配 filter 的记录移动到 toDept（通过 Update 写入）
+ * 注意: 这些函数以管理员身份跨租户读写，调用方负责权限校验
+ *
+ * 使用示例:
+ *   n, err := repository.CopyTenantData[Dict](ctx, dictRepo, tenantA, tenantB,
+ *       repository.Eq[Dict]("builtin", true),
+ *       repository.WithMigrationProgress(func(p repository.MigrationProgress) {
+ *           log.Info("copying dicts", zap.Int64("done", p.Done), zap.Int64("total", p.Total))
+ *       }))
+ *
+ *   n, err = repository.ReassignDept[Order](ctx, orderRepo, tenantID, oldDept, newDept, nil)
+ * ======================================================================== */
+
+// DefaultMigrationBatchSize 默认迁移批大小
+const DefaultMigrationBatchSize = 500
+
+// MigrationProgress 迁移进度
+type MigrationProgress struct {
+	// Total 开始时匹配的记录数
+	Total int64
+	// Done 已提交的记录数
+	Done int64
+	// Batch 已提交的批次数
+	Batch int
+}
+
+// migrationConfig 迁移选项
+type migrationConfig struct {
+	batchSize int
+	progress  func(MigrationProgress)
+	transform func(model any) error
+}
+
+// MigrationOption 迁移选项
+type MigrationOption func(*migrationConfig)
+
+// WithMigrationBatchSize 设置每批（每个事务）处理的记录数
+func WithMigrationBatchSize(n int) MigrationOption {
+	return func(c *migrationConfig) {
+		c.batchSize = n
+	}
+}
+
+// WithMigrationProgress 设置进度回调（每批提交后调用）
+func WithMigrationProgress(fn func(MigrationProgress)) MigrationOption {
+	return func(c *migrationConfig) {
+		c.progress = fn
+	}
+}
+
+// WithCopyTransform 设置复制前对新记录的改写（仅 CopyTenantData 使用）
+func WithCopyTransform[T any](fn func(model *T) error) MigrationOption {
+	return func(c *migrationConfig) {
+		c.transform = func(model any) error {
+			m, ok := model.(*T)
+			if !ok {
+				return errors.New(errors.ErrCodeInvalidArgument, "copy transform model type mismatch")
+			}
+			return fn(m)
+		}
+	}
+}
+
+func applyMigrationOptions(opts []MigrationOption) *migrationConfig {
+	c := &migrationConfig{batchSize: DefaultMigrationBatchSize}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+	if c.batchSize <= 0 {
+		c.batchSize = DefaultMigrationBatchSize
+	}
+	return c
+}
+
+func (c *migrationConfig) report(p MigrationProgress) {
+	if c.progress != nil {
+		c.progress(p)
+	}
+}
+
+// adminContext 以指定租户管理员身份访问仓储（保留调用方 UserID 以便审计）
+func adminContext(ctx context.Context, tenant ulidv2.ULID) context.Context {
+	tc, _ := TenantFromContext(ctx)
+	return WithTenantContext(ctx, TenantContext{TenantID: tenant, IsAdmin: true, UserID: tc.UserID})
+}
+
+var (
+	migrationSchemaCache sync.Map
+	migrationNaming      = schema.NamingStrategy{}
+)
+
+// modelSchema 解析仓储模型的 Schema
+func modelSchema[T any](repo Repository[T]) (*schema.Schema, error) {
+	switch r := repo.(type) {
+	case *RepositoryImpl[T]:
+		return r.getSchema()
+	case *MemoryRepository[T]:
+		return r.schema()
+	}
+	var namer schema.Namer = migrationNaming
+	if db := repo.GetDB(); db != nil && db.Config != nil && db.NamingStrategy != nil {
+		namer = db.NamingStrategy
+	}
+	return schema.Parse(new(T), &migrationSchemaCache, namer)
+}
+
+// tenantModelSchema 校验模型为租户模型（有 tenant_id 且未忽略租户）
+func tenantModelSchema[T any](repo Repository[T]) (*schema.Schema, error) {
+	var model T
+	if ignorable, ok := any(&model).(TenantIgnorable); ok && ignorable.TenantIgnored() {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "tenant migration requires a tenant model")
+	}
+	s, err := modelSchema(repo)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := s.FieldsByDBName[tenantColumn]; !ok {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "tenant migration requires a tenant_id column")
+	}
+	if len(s.PrimaryFields) == 0 {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "tenant migration requires a primary key")
+	}
+	return s, nil
+}
+
+// primaryKeyOrder 按主键排序，保证分批稳定
+func primaryKeyOrder(s *schema.Schema) Option {
+	columns := make([]string, len(s.PrimaryFields))
+	for i, f := range s.PrimaryFields {
+		columns[i] = f.DBName
+	}
+	return WithOrderBy(strings.Join(columns, ", "))
+}
+
+func limitScope(offset, limit int) Option {
+	return WithScopes(func(db *gorm.DB) *gorm.DB {
+		return db.Offset(offset).Limit(limit)
+	})
+}
+
+// resetGeneratedKey 清零单一主键（tenant_id 除外），由钩子或自增重新生成
+func resetGeneratedKey[T any](ctx context.Context, s *schema.Schema, model *T) error {
+	if len(s.PrimaryFields) != 1 || s.PrimaryFields[0].DBName == tenantColumn {
+		return nil
+	}
+	field := s.PrimaryFields[0]
+	return field.Set(ctx, reflect.ValueOf(model), reflect.Zero(field.FieldType).Interface())
+}
+
+/* ========================================================================
+ * CopyTenantData
+ * ======================================================================== */
+
+// CopyTenantData 将 fromTenant 中匹配 filter（nil 表示全部）的记录复制到 toTenant，返回复制条数
+func CopyTenantData[T any](ctx context.Context, repo Repository[T], fromTenant, toTenant ulidv2.ULID, filter Specification[T], opts ...MigrationOption) (int64, error) {
+	if repo == nil || fromTenant == toTenant || fromTenant.IsZero() || toTenant.IsZero() {
+		return 0, errors.ErrInvalidArgument
+	}
+	s, err := tenantModelSchema(repo)
+	if err != nil {
+		return 0, err
+	}
+	cfg := applyMigrationOptions(opts)
+	srcCtx, dstCtx := adminContext(ctx, fromTenant), adminContext(ctx, toTenant)
+
+	total, err := repo.CountBySpec(srcCtx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	progress := MigrationProgress{Total: total}
+	order := primaryKeyOrder(s)
+	for {
+		// 写入目标租户不影响源租户的分页偏移
+		batch, err := repo.FindBySpec(srcCtx, filter, order, limitScope(int(progress.Done), cfg.batchSize))
+		if err != nil {
+			return progress.Done, err
+		}
+		if len(batch) == 0 {
+			return progress.Done, nil
+		}
+
+		copies := make([]*T, len(batch))
+		for i, src := range batch {
+			dst := *src
+			if err := resetGeneratedKey(ctx, s, &dst); err != nil {
+				return progress.Done, errors.Wrap(errors.ErrCodeInternal, "failed to reset primary key", err)
+			}
+			if cfg.transform != nil {
+				if err := cfg.transform(&dst); err != nil {
+					return progress.Done, err
+				}
+			}
+			copies[i] = &dst
+		}
+		if err := repo.Execute(dstCtx, func(txCtx context.Context) error {
+			return repo.CreateBatch(txCtx, copies, len(copies))
+		}); err != nil {
+			return progress.Done, err
+		}
+
+		progress.Done += int64(len(batch))
+		progress.Batch++
+		cfg.report(progress)
+		if len(batch) < cfg.batchSize {
+			return progress.Done, nil
+		}
+	}
+}
+
+/* ========================================================================
+ * ReassignDept
+ * ======================================================================== */
+
+// ReassignDept 将 tenant 内 fromDept 中匹配 filter（nil 表示全部）的记录移动到 toDept，返回移动条数
+func ReassignDept[T any](ctx context.Context, repo Repository[T], tenant, fromDept, toDept ulidv2.ULID, filter Specification[T], opts ...MigrationOption) (int64, error) {
+	if repo == nil || tenant.IsZero() || fromDept == toDept || toDept.IsZero() {
+		return 0, errors.ErrInvalidArgument
+	}
+	s, err := tenantModelSchema(repo)
+	if err != nil {
+		return 0, err
+	}
+	deptField, ok := s.FieldsByDBName[deptColumn]
+	if !ok {
+		return 0, errors.New(errors.ErrCodeInvalidArgument, "dept reassignment requires a dept_id column")
+	}
+	var deptValue any = toDept
+	if deptField.FieldType.Kind() == reflect.Ptr {
+		deptValue = &toDept
+	}
+
+	cfg := applyMigrationOptions(opts)
+	adminCtx := adminContext(ctx, tenant)
+	spec := And[T](Eq[T](deptColumn, fromDept), filter)
+
+	total, err := repo.CountBySpec(adminCtx, spec)
+	if err != nil {
+		return 0, err
+	}
+
+	progress := MigrationProgress{Total: total}
+	order := primaryKeyOrder(s)
+	var moved map[string]bool
+	for {
+		// 已移动的记录不再匹配，始终读取第一批
+		batch, err := repo.FindBySpec(adminCtx, spec, order, limitScope(0, cfg.batchSize))
+		if err != nil {
+			return progress.Done, err
+		}
+		if len(batch) == 0 {
+			return progress.Done, nil
+		}
+		// 上一批的记录仍然匹配说明更新未生效（如钩子改回了部门），避免死循环
+		keys := make(map[string]bool, len(batch))
+		for _, m := range batch {
+			key, _ := uniqueKey(ctx, s.PrimaryFields, m)
+			if moved[key] {
+				return progress.Done, errors.New(errors.ErrCodeInternal, "dept reassignment did not take effect")
+			}
+			keys[key] = true
+		}
+		moved = keys
+
+		if err := repo.Execute(adminCtx, func(txCtx context.Context) error {
+			for _, m := range batch {
+				if err := deptField.Set(txCtx, reflect.ValueOf(m), deptValue); err != nil {
+					return errors.Wrap(errors.ErrCodeInternal, "failed to set dept_id", err)
+				}
+				if err := repo.Update(txCtx, m); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return progress.Done, err
+		}
+
+		progress.Done += int64(len(batch))
+		progress.Batch++
+		cfg.report(progress)
+	}
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func runTenantMigration(t *testing.T, newRepo func(t *testing.T) Repository[conformanceModel]) {
+	t.Run("CopyTenantData", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		target := ulidv2.Make()
+		targetCtx := WithTenantContext(context.Background(), TenantContext{TenantID: target, IsAdmin: true})
+
+		var progress []MigrationProgress
+		n, err := CopyTenantData[conformanceModel](context.Background(), f.repo, f.tenantA, target,
+			Eq[conformanceModel]("status", "paid"),
+			WithMigrationBatchSize(1),
+			WithMigrationProgress(func(p MigrationProgress) { progress = append(progress, p) }),
+			WithCopyTransform(func(m *conformanceModel) error {
+				if m.ID != "" {
+					t.Errorf("expected primary key to be reset, got %q", m.ID)
+				}
+				m.ID = ulidv2.Make().String()
+				m.Code += "-copy"
+				return nil
+			}))
+		if err != nil {
+			t.Fatalf("copy: %v", err)
+		}
+		if n != 2 || len(progress) != 2 || progress[1] != (MigrationProgress{Total: 2, Done: 2, Batch: 2}) {
+			t.Fatalf("unexpected result n=%d progress=%+v", n, progress)
+		}
+
+		copied, err := f.repo.FindByQuery(targetCtx, "")
+		if err != nil {
+			t.Fatalf("find copies: %v", err)
+		}
+		if got := sortedCodes(copied); !slices.Equal(got, []string{"a1-copy", "a2-copy"}) {
+			t.Fatalf("unexpected copies: %v", got)
+		}
+		for _, m := range copied {
+			if m.TenantID != target || m.DeptID == nil {
+				t.Fatalf("copy should move tenant and keep dept: %+v", m)
+			}
+		}
+		if n, _ := f.repo.Count(f.admin, ""); n != 4 {
+			t.Fatalf("source tenant must be unchanged, got %d", n)
+		}
+
+		// 唯一约束照常生效：未改写编码时整批回滚
+		_, err = CopyTenantData[conformanceModel](context.Background(), f.repo, f.tenantA, target,
+			Eq[conformanceModel]("code", "a1"),
+			WithCopyTransform(func(m *conformanceModel) error {
+				m.ID = ulidv2.Make().String()
+				return nil
+			}))
+		expectCode(t, err, errors.ErrCodeAlreadyExists)
+
+		_, err = CopyTenantData[conformanceModel](context.Background(), f.repo, f.tenantA, f.tenantA, nil)
+		expectCode(t, err, errors.ErrCodeInvalidArgument)
+	})
+
+	t.Run("ReassignDept", func(t *testing.T) {
+		f := newConformanceFixture(t, newRepo(t))
+		target := ulidv2.Make()
+
+		var batches int
+		n, err := ReassignDept[conformanceModel](context.Background(), f.repo, f.tenantA, f.deptD1, target, nil,
+			WithMigrationBatchSize(1),
+			WithMigrationProgress(func(MigrationProgress) { batches++ }))
+		if err != nil {
+			t.Fatalf("reassign: %v", err)
+		}
+		if n != 2 || batches != 2 {
+			t.Fatalf("expected 2 records in 2 batches, got n=%d batches=%d", n, batches)
+		}
+
+		moved := WithTenantContext(context.Background(), TenantContext{TenantID: f.tenantA, DeptID: &target})
+		got, err := f.repo.FindByQuery(moved, "")
+		if err != nil {
+			t.Fatalf("find moved: %v", err)
+		}
+		if codes := sortedCodes(got); !slices.Equal(codes, []string{"a1", "a3"}) {
+			t.Fatalf("unexpected moved records: %v", codes)
+		}
+		if left, _ := f.repo.Count(f.d1, ""); left != 0 {
+			t.Fatalf("expected old dept to be empty, got %d", left)
+		}
+		if other, _ := f.repo.Count(f.d2, ""); other != 2 {
+			t.Fatalf("other dept must be unchanged, got %d", other)
+		}
+
+		_, err = ReassignDept[conformanceModel](context.Background(), f.repo, f.tenantA, target, target, nil)
+		expectCode(t, err, errors.ErrCodeInvalidArgument)
+	})
+
+	t.Run("TenantIgnoredModel", func(t *testing.T) {
+		repo := NewMemoryRepository[memoryBaseModel]()
+		_, err := CopyTenantData[memoryBaseModel](context.Background(), repo, ulidv2.Make(), ulidv2.Make(), nil)
+		expectCode(t, err, errors.ErrCodeInvalidArgument)
+	})
+}
+
+func TestTenantMigration(t *testing.T) {
+	runTenantMigration(t, func(t *testing.T) Repository[conformanceModel] {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "migrate.db")), &gorm.Config{})
+		if err != nil {
+			t.Fatalf("open db: %v", err)
+		}
+		if err := db.AutoMigrate(&conformanceModel{}); err != nil {
+			t.Fatalf("migrate: %v", err)
+		}
+		return NewRepository[conformanceModel](db)
+	})
+}
+
+func TestMemoryTenantMigration(t *testing.T) {
+	runTenantMigration(t, func(*testing.T) Repository[conformanceModel] {
+		return NewMemoryRepository[conformanceModel]()
+	})
+}
@@ -16,7 +16,7 @@ const (
 
 func (r *RepositoryImpl[T]) applyTenantScope(ctx context.Context, db *gorm.DB) *gorm.DB {
 	if r.isTenantIgnored(r.newModelPtr()) {
-		return db
+		return r.applyOwnerScope(ctx, db)
 	}
 
 	tc, ok := TenantFromContext(ctx)
@@ -34,16 +34,12 @@ func (r *RepositoryImpl[T]) applyTenantScope(ctx context.Context, db *gorm.DB) *
 	// 应用租户隔离
 	db = db.Where(tenantColumn+" = ?", tc.TenantID)
 
-	// 如果模型有部门字段，非管理员必须提供 DeptID
-	if !tc.IsAdmin && deptField != nil {
-		if tc.DeptID == nil {
-			db.AddError(errors.New(errors.ErrCodeUnauthenticated, "non-admin user must provide dept_id"))
-			return db
-		}
-		db = db.Where(deptColumn+" = ?", *tc.DeptID)
+	// 非管理员按数据范围过滤（见 DataScope）
+	if !tc.IsAdmin {
+		db = r.applyDataScope(ctx, db, tc, deptField != nil)
 	}
 
-	return db
+	return r.applyOwnerScope(ctx, db)
 }
 
 func (r *RepositoryImpl[T]) tenantFields() (*schema.Field, *schema.Field, error) {
@@ -61,7 +57,7 @@ func (r *RepositoryImpl[T]) tenantFields() (*schema.Field, *schema.Field, error)
 
 func (r *RepositoryImpl[T]) setTenantFields(ctx context.Context, model any) error {
 	if r.isTenantIgnored(model) {
-		return nil
+		return r.setUserFields(ctx, model)
 	}
 
 	tc, ok := TenantFromContext(ctx)
@@ -103,7 +99,7 @@ func (r *RepositoryImpl[T]) setTenantFields(ctx context.Context, model any) erro
 		}
 	}
 
-	return nil
+	return r.setUserFields(ctx, model)
 }
 
 func (r *RepositoryImpl[T]) isTenantIgnored(model any) bool {
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+)
+
+/* ========================================================================
+ * Time Bucket Aggregate - 时间分桶聚合
+ * ========================================================================
+ * 职责: 按小时/天/周/月对指标做时间序列汇总，供看板类接口使用
+ * 方言: postgres 使用 date_trunc，mysql 使用 DATE_FORMAT，sqlite 使用 strftime
+ *       周以周一为起点，分桶在数据库会话时区内进行
+ * 区间: [rangeStart, rangeEnd)，默认对缺失的桶补 0，返回连续序列
+ *
+ * 使用示例:
+ *   series, err := repo.SumByTimeBucket(ctx, "amount", repository.BucketDay, start, end,
+ *       repository.WithBucketTimeColumn("paid_at"),
+ *       repository.WithBucketWhere("status = ?", "paid"),
+ *   )
+ *   for _, p := range series {
+ *       fmt.Println(p.Bucket.Format(time.DateOnly), p.Value)
+ *   }
+ * ======================================================================== */
+
+// TimeBucket 分桶粒度
+type TimeBucket string
+
+const (
+	BucketHour  TimeBucket = "hour"
+	BucketDay   TimeBucket = "day"
+	BucketWeek  TimeBucket = "week"
+	BucketMonth TimeBucket = "month"
+)
+
+// bucketLayout 数据库返回的分桶键格式
+const bucketLayout = "2006-01-02 15:04:05"
+
+// TimeBucketPoint 时间序列数据点
+type TimeBucketPoint struct {
+	Bucket time.Time // 桶起始时间
+	Value  float64
+}
+
+// bucketOptions 分桶查询选项
+type bucketOptions struct {
+	timeColumn string
+	query      string
+	args       []any
+	fill       bool
+}
+
+// BucketOption 分桶查询选项
+type BucketOption func(*bucketOptions)
+
+// WithBucketTimeColumn 设置时间列（默认 created_at）
+func WithBucketTimeColumn(column string) BucketOption {
+	return func(o *bucketOptions) {
+		o.timeColumn = column
+	}
+}
+
+// WithBucketWhere 追加 WHERE 条件
+func WithBucketWhere(query string, args ...any) BucketOption {
+	return func(o *bucketOptions) {
+		o.query = query
+		o.args = args
+	}
+}
+
+// WithoutBucketFill 不补齐缺失的桶（仅返回有数据的桶）
+func WithoutBucketFill() BucketOption {
+	return func(o *bucketOptions) {
+		o.fill = false
+	}
+}
+
+// SumByTimeBucket 按时间分桶求和
+func (r *RepositoryImpl[T]) SumByTimeBucket(ctx context.Context, column string, interval TimeBucket, rangeStart, rangeEnd time.Time, opts ...BucketOption) ([]TimeBucketPoint, error) {
+	return r.AggregateByTimeBucket(ctx, Sum(column), interval, rangeStart, rangeEnd, opts...)
+}
+
+// AggregateByTimeBucket 按时间分桶计算任意指标（如 Count()、Avg("amount")）
+func (r *RepositoryImpl[T]) AggregateByTimeBucket(ctx context.Context, metric Metric, interval TimeBucket, rangeStart, rangeEnd time.Time, opts ...BucketOption) ([]TimeBucketPoint, error) {
+	o := &bucketOptions{timeColumn: "created_at", fill: true}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if err := validateColumn(o.timeColumn); err != nil {
+		return nil, err
+	}
+	expr, err := metric.expr()
+	if err != nil {
+		return nil, err
+	}
+	if !rangeEnd.After(rangeStart) {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "rangeEnd must be after rangeStart")
+	}
+	bucketExpr, err := bucketExpression(r.db.Dialector.Name(), interval, o.timeColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	db := r.applyTenantScope(ctx, r.withContext(ctx)).
+		Model(r.newModelPtr()).
+		Select(bucketExpr+" AS bucket, "+expr+" AS value").
+		Where(o.timeColumn+" >= ? AND "+o.timeColumn+" < ?", rangeStart, rangeEnd)
+	if o.query != "" {
+		db = db.Where(o.query, o.args...)
+	}
+
+	type bucketRow struct {
+		Bucket string          `gorm:"column:bucket"`
+		Value  sql.NullFloat64 `gorm:"column:value"`
+	}
+	var rows []bucketRow
+	if err := db.Group("bucket").Order("bucket").Scan(&rows).Error; err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInternal, "failed to aggregate by time bucket", err)
+	}
+
+	loc := rangeStart.Location()
+	values := make(map[time.Time]float64, len(rows))
+	points := make([]TimeBucketPoint, 0, len(rows))
+	for _, row := range rows {
+		bucket, err := time.ParseInLocation(bucketLayout, row.Bucket, loc)
+		if err != nil {
+			return nil, errors.Wrap(errors.ErrCodeInternal, "failed to parse time bucket", err)
+		}
+		values[bucket] = row.Value.Float64
+		points = append(points, TimeBucketPoint{Bucket: bucket, Value: row.Value.Float64})
+	}
+	if !o.fill {
+		return points, nil
+	}
+
+	filled := make([]TimeBucketPoint, 0, len(points))
+	for t := truncateBucket(rangeStart, interval); t.Before(rangeEnd); t = nextBucket(t, interval) {
+		filled = append(filled, TimeBucketPoint{Bucket: t, Value: values[t]})
+	}
+	return filled, nil
+}
+
+// bucketExpression 生成方言相关的分桶表达式，结果统一为 bucketLayout 格式的字符串
+func bucketExpression(dialect string, interval TimeBucket, column string) (string, error) {
+	var expr string
+	switch dialect {
+	case "postgres":
+		switch interval {
+		case BucketHour, BucketDay, BucketWeek, BucketMonth:
+			expr = "to_char(date_trunc('" + string(interval) + "', " + column + "), 'YYYY-MM-DD HH24:MI:SS')"
+		}
+	case "mysql":
+		switch interval {
+		case BucketHour:
+			expr = "DATE_FORMAT(" + column + ", '%Y-%m-%d %H:00:00')"
+		case BucketDay:
+			expr = "DATE_FORMAT(" + column + ", '%Y-%m-%d 00:00:00')"
+		case BucketWeek:
+			expr = "DATE_FORMAT(DATE_SUB(" + column + ", INTERVAL WEEKDAY(" + column + ") DAY), '%Y-%m-%d 00:00:00')"
+		case BucketMonth:
+			expr = "DATE_FORMAT(" + column + ", '%Y-%m-01 00:00:00')"
+		}
+	case "sqlite":
+		switch interval {
+		case BucketHour:
+			expr = "strftime('%Y-%m-%d %H:00:00', " + column + ")"
+		case BucketDay:
+			expr = "strftime('%Y-%m-%d 00:00:00', " + column + ")"
+		case BucketWeek:
+			expr = "strftime('%Y-%m-%d 00:00:00', " + column + ", '-6 days', 'weekday 1')"
+		case BucketMonth:
+			expr = "strftime('%Y-%m-01 00:00:00', " + column + ")"
+		}
+	default:
+		return "", errors.New(errors.ErrCodeInternal, "time bucket aggregation is not supported for dialect: "+dialect)
+	}
+	if expr == "" {
+		return "", errors.New(errors.ErrCodeInvalidArgument, "invalid time bucket interval: "+string(interval))
+	}
+	return expr, nil
+}
+
+// truncateBucket 将时间截断到桶起始
+func truncateBucket(t time.Time, interval TimeBucket) time.Time {
+	y, m, d := t.Date()
+	switch interval {
+	case BucketHour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case BucketWeek:
+		offset := (int(t.Weekday()) + 6) % 7 // 周一为 0
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case BucketMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// nextBucket 下一个桶起始
+func nextBucket(t time.Time, interval TimeBucket) time.Time {
+	switch interval {
+	case BucketHour:
+		return t.Add(time.Hour)
+	case BucketWeek:
+		return t.AddDate(0, 0, 7)
+	case BucketMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+)
+
+type bucketTestModel struct {
+	ID       string      `gorm:"column:id;type:char(26);primaryKey"`
+	TenantID ulidv2.ULID `gorm:"column:tenant_id;type:char(26);not null"`
+	Amount   float64     `gorm:"column:amount"`
+	Status   string      `gorm:"column:status"`
+	PaidAt   time.Time   `gorm:"column:paid_at"`
+}
+
+func TestSumByTimeBucket(t *testing.T) {
+	db := openTxTestDB(t)
+	if err := db.AutoMigrate(&bucketTestModel{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := NewRepository[bucketTestModel](db)
+
+	ctxA := WithTenantContext(context.Background(), TenantContext{TenantID: ulidv2.Make(), IsAdmin: true})
+	ctxB := WithTenantContext(context.Background(), TenantContext{TenantID: ulidv2.Make(), IsAdmin: true})
+
+	day := func(d, h int) time.Time { return time.Date(2026, 3, d, h, 30, 0, 0, time.UTC) }
+	seed := []struct {
+		ctx    context.Context
+		at     time.Time
+		amount float64
+		status string
+	}{
+		{ctxA, day(2, 9), 10, "paid"},  // 周一
+		{ctxA, day(2, 18), 15, "paid"}, // 周一
+		{ctxA, day(4, 8), 20, "paid"},  // 周三
+		{ctxA, day(4, 9), 99, "void"},
+		{ctxA, day(9, 1), 5, "paid"}, // 下周一
+		{ctxB, day(2, 9), 1000, "paid"},
+	}
+	for _, s := range seed {
+		m := &bucketTestModel{ID: ulidv2.Make().String(), Amount: s.amount, Status: s.status, PaidAt: s.at}
+		if err := repo.Create(s.ctx, m); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+
+	start, end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)
+	series, err := repo.SumByTimeBucket(ctxA, "amount", BucketDay, start, end,
+		WithBucketTimeColumn("paid_at"), WithBucketWhere("status = ?", "paid"))
+	if err != nil {
+		t.Fatalf("sum by day: %v", err)
+	}
+	want := []float64{25, 0, 20, 0}
+	if len(series) != len(want) {
+		t.Fatalf("expected %d buckets, got %+v", len(want), series)
+	}
+	for i, p := range series {
+		if p.Value != want[i] || !p.Bucket.Equal(time.Date(2026, 3, 2+i, 0, 0, 0, 0, time.UTC)) {
+			t.Fatalf("bucket %d: unexpected point %+v", i, p)
+		}
+	}
+
+	weekly, err := repo.AggregateByTimeBucket(ctxA, Count(), BucketWeek, day(3, 0), day(12, 0),
+		WithBucketTimeColumn("paid_at"), WithoutBucketFill())
+	if err != nil {
+		t.Fatalf("count by week: %v", err)
+	}
+	if len(weekly) != 2 || weekly[0].Value != 2 || weekly[1].Value != 1 ||
+		!weekly[0].Bucket.Equal(start) {
+		t.Fatalf("unexpected weekly series: %+v", weekly)
+	}
+
+	if _, err := repo.SumByTimeBucket(ctxA, "amount", "minute", start, end); err == nil {
+		t.Fatalf("expected invalid interval error")
+	}
+}
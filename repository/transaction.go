@@ -17,7 +17,10 @@ import (
 // Transaction 在事务中执行操作
 // Deprecated: 请使用 Execute 方法以支持隐式事务传播
 func (r *RepositoryImpl[T]) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
-	db := r.withContext(ctx)
+	db := r.connDB(ctx)
+	if db.Error != nil {
+		return db.Error
+	}
 
 	return db.Transaction(func(tx *gorm.DB) error {
 		return fn(tx)
@@ -29,7 +32,11 @@ func (r *RepositoryImpl[T]) Transaction(ctx context.Context, fn func(tx *gorm.DB
 func (r *RepositoryImpl[T]) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
 	// 使用原始 DB 开启事务（避免嵌套事务时的 context 混乱，虽然 GORM 支持嵌套，但这里从源头开启更清晰）
 	// 注意：如果 ctx 已经是事务 context，GORM 的 Transaction 方法会自动处理为 SavePoint
-	db := r.withContext(ctx)
+	db := r.connDB(ctx)
+	// 连接已带错误（路由失败、缺失 deadline）时不开启事务，避免 Begin 后未回滚
+	if db.Error != nil {
+		return db.Error
+	}
 
 	// GORM 的 Transaction 方法会自动提交或回滚
 	// 我们直接返回原始错误，由 Service 层决定如何处理
@@ -43,7 +50,7 @@ func (r *RepositoryImpl[T]) Execute(ctx context.Context, fn func(ctx context.Con
 // WithTx 创建事务版本的仓储
 // 返回的仓储实例使用传入的事务 DB
 func (r *RepositoryImpl[T]) WithTx(tx *gorm.DB) Repository[T] {
-	return &RepositoryImpl[T]{db: tx}
+	return &RepositoryImpl[T]{db: tx, hooks: r.hooks, partition: r.partition}
 }
 
 /* ========================================================================
@@ -73,12 +80,15 @@ func (tc *TransactionContext) HasTx() bool {
 
 // ExecInTransaction 在事务中执行操作（使用 TransactionContext）
 func (r *RepositoryImpl[T]) ExecInTransaction(ctx context.Context, fn func(tc *TransactionContext) error) error {
-	db := r.withContext(ctx)
+	db := r.connDB(ctx)
+	if db.Error != nil {
+		return errors.FromGORM(db.Error)
+	}
 
 	if err := db.Transaction(func(tx *gorm.DB) error {
 		return fn(&TransactionContext{tx: tx})
 	}); err != nil {
-		return errors.Wrap(errors.ErrCodeInternal, "transaction failed", err)
+		return errors.FromGORM(err)
 	}
 
 	return nil
@@ -88,7 +98,7 @@ func (r *RepositoryImpl[T]) ExecInTransaction(ctx context.Context, fn func(tc *T
 // 如果 tc 有事务，使用事务 DB；否则使用普通 DB
 func (r *RepositoryImpl[T]) WithTxContext(tc *TransactionContext) Repository[T] {
 	if tc != nil && tc.HasTx() {
-		return &RepositoryImpl[T]{db: tc.GetTx()}
+		return &RepositoryImpl[T]{db: tc.GetTx(), hooks: r.hooks, partition: r.partition}
 	}
 	return r
 }
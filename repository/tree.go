@@ -0,0 +1,420 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+/* ========================================================================
+ * Tree - 邻接表树结构
+ * ========================================================================
+ * 职责: 部门、分类等 parent_id 邻接表的子树查询与移动
+ * 约定:
+ *   - 主键列为 id，父节点列默认 parent_id（WithParentColumn），根节点的父节点为 NULL 或零值
+ *   - 可选物化路径列（WithPathColumn）保存祖先 ID 序列，不含自身：
+ *     根节点为 "/"，子节点为 父节点路径 + 父节点ID + "/"
+ * FindDescendants:
+ *   - GORM 仓储（Postgres / MySQL 8 / SQLite，未分表）使用递归 CTE，一次查询
+ *   - 其他 GORM 仓储配置了路径列时按路径前缀查询，否则（含 MemoryRepository）逐层查询
+ * MoveSubtree:
+ *   - 在事务中修改父节点并重写子树路径；目标为自身或自身后代时返回 InvalidArgument
+ * MaintainTreePath:
+ *   - 注册仓储钩子，创建时根据父节点生成路径，拒绝经 Update 直接修改父节点
+ * 说明: 全部读写经由 Repository 完成，租户范围、钩子照常生效
+ *
+ * 使用示例:
+ *   repository.MaintainTreePath[Dept](deptRepo, repository.WithPathColumn("path"))
+ *   children, err := repository.FindChildren[Dept](ctx, deptRepo, deptID)
+ *   subtree, err := repository.FindDescendants[Dept](ctx, deptRepo, deptID)
+ *   err = repository.MoveSubtree[Dept](ctx, deptRepo, deptID, newParentID)
+ * ======================================================================== */
+
+// DefaultParentColumn 默认父节点列
+const DefaultParentColumn = "parent_id"
+
+// TreePathSeparator 物化路径分隔符
+const TreePathSeparator = "/"
+
+// treeConfig 树结构选项
+type treeConfig struct {
+	parentColumn string
+	pathColumn   string
+}
+
+// TreeOption 树结构选项
+type TreeOption func(*treeConfig)
+
+// WithParentColumn 设置父节点列（默认 parent_id）
+func WithParentColumn(column string) TreeOption {
+	return func(c *treeConfig) {
+		c.parentColumn = column
+	}
+}
+
+// WithPathColumn 设置物化路径列（默认不使用）
+func WithPathColumn(column string) TreeOption {
+	return func(c *treeConfig) {
+		c.pathColumn = column
+	}
+}
+
+// treeSchema 树结构字段
+type treeSchema struct {
+	id     *schema.Field
+	parent *schema.Field
+	path   *schema.Field
+	table  string
+}
+
+func newTreeSchema[T any](repo Repository[T], opts []TreeOption) (*treeSchema, error) {
+	if repo == nil {
+		return nil, errors.ErrInvalidArgument
+	}
+	cfg := &treeConfig{parentColumn: DefaultParentColumn}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	s, err := modelSchema(repo)
+	if err != nil {
+		return nil, err
+	}
+	ts := &treeSchema{table: s.Table}
+	var ok bool
+	if ts.id, ok = s.FieldsByDBName["id"]; !ok {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "tree requires an id column")
+	}
+	if ts.parent, ok = s.FieldsByDBName[cfg.parentColumn]; !ok {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "tree parent column not found: "+cfg.parentColumn)
+	}
+	if cfg.pathColumn != "" {
+		ts.path, ok = s.FieldsByDBName[cfg.pathColumn]
+		if !ok || ts.path.FieldType.Kind() != reflect.String {
+			return nil, errors.New(errors.ErrCodeInvalidArgument, "tree path column must be a string column: "+cfg.pathColumn)
+		}
+	}
+	return ts, nil
+}
+
+// value 读取字段值（指针解引用，零值返回 nil）
+func (ts *treeSchema) value(ctx context.Context, field *schema.Field, model any) any {
+	v, zero := field.ValueOf(ctx, reflect.ValueOf(model))
+	if zero {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		return rv.Elem().Interface()
+	}
+	return v
+}
+
+// key 返回字段值的字符串形式（空值返回 ""）
+func (ts *treeSchema) key(ctx context.Context, field *schema.Field, model any) string {
+	v := ts.value(ctx, field, model)
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+func (ts *treeSchema) pathOf(ctx context.Context, model any) string {
+	p, _ := ts.value(ctx, ts.path, model).(string)
+	return p
+}
+
+// childPath 返回 parent 的子节点路径
+func (ts *treeSchema) childPath(ctx context.Context, parent any) string {
+	if parent == nil {
+		return TreePathSeparator
+	}
+	return ts.pathOf(ctx, parent) + ts.key(ctx, ts.id, parent) + TreePathSeparator
+}
+
+// rootSpec 父节点为空
+func rootSpec[T any](ts *treeSchema) Specification[T] {
+	isNull := Eq[T](ts.parent.DBName, nil)
+	if ts.parent.FieldType.Kind() == reflect.Ptr {
+		return isNull
+	}
+	return Or[T](isNull, Eq[T](ts.parent.DBName, reflect.Zero(ts.parent.FieldType).Interface()))
+}
+
+// escapeLike 转义 LIKE 通配符（ESCAPE '!'）
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
+
+/* ========================================================================
+ * 查询
+ * ======================================================================== */
+
+// FindChildren 返回 parentID 的直接子节点，parentID 为空时返回根节点
+func FindChildren[T any](ctx context.Context, repo Repository[T], parentID string, opts ...TreeOption) ([]*T, error) {
+	ts, err := newTreeSchema(repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	if parentID == "" {
+		return repo.FindBySpec(ctx, rootSpec[T](ts))
+	}
+	parent, err := repo.FindByID(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	return repo.FindBySpec(ctx, Eq[T](ts.parent.DBName, ts.value(ctx, ts.id, parent)))
+}
+
+// FindDescendants 返回 id 的全部后代节点（不含自身）
+func FindDescendants[T any](ctx context.Context, repo Repository[T], id string, opts ...TreeOption) ([]*T, error) {
+	ts, err := newTreeSchema(repo, opts)
+	if err != nil {
+		return nil, err
+	}
+	node, err := repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return findDescendants(ctx, repo, ts, node)
+}
+
+func findDescendants[T any](ctx context.Context, repo Repository[T], ts *treeSchema, node *T) ([]*T, error) {
+	nodeID := ts.value(ctx, ts.id, node)
+	r, sql := repo.(*RepositoryImpl[T])
+	if sql && r.supportsRecursiveCTE() {
+		return repo.FindBySpec(ctx, nil, WithScopes(descendantsCTE[T](ts, nodeID)))
+	}
+	if sql && ts.path != nil {
+		return repo.FindBySpec(ctx, pathPrefixSpec[T](ctx, ts, node))
+	}
+
+	// 逐层查询，visited 防止数据成环时死循环
+	var result []*T
+	visited := map[string]bool{fmt.Sprint(nodeID): true}
+	level := []any{nodeID}
+	for len(level) > 0 {
+		children, err := repo.FindBySpec(ctx, In[T](ts.parent.DBName, level))
+		if err != nil {
+			return nil, err
+		}
+		level = level[:0]
+		for _, child := range children {
+			key := ts.key(ctx, ts.id, child)
+			if visited[key] {
+				continue
+			}
+			visited[key] = true
+			result = append(result, child)
+			level = append(level, ts.value(ctx, ts.id, child))
+		}
+	}
+	return result, nil
+}
+
+// pathPrefixSpec 路径以 node 的子节点路径开头
+func pathPrefixSpec[T any](ctx context.Context, ts *treeSchema, node *T) Specification[T] {
+	prefix := escapeLike(ts.childPath(ctx, node)) + "%"
+	return Where[T]("? LIKE ? ESCAPE '!'", clause.Column{Name: ts.path.DBName}, prefix)
+}
+
+// supportsRecursiveCTE 未分表且方言支持递归 CTE
+func (r *RepositoryImpl[T]) supportsRecursiveCTE() bool {
+	if r.partition != nil || r.db == nil {
+		return false
+	}
+	switch r.db.Dialector.Name() {
+	case "postgres", "mysql", "sqlite":
+		return true
+	}
+	return false
+}
+
+// descendantsCTE 以递归 CTE 子查询限定后代节点
+// 内层查询经由 Model 构建，软删除条件自动生效；UNION 去重保证数据成环时终止
+func descendantsCTE[T any](ts *treeSchema, nodeID any) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		newDB := func() *gorm.DB {
+			return db.Session(&gorm.Session{NewDB: true}).Model(new(T))
+		}
+		id := clause.Column{Table: ts.table, Name: ts.id.DBName}
+		parent := clause.Column{Table: ts.table, Name: ts.parent.DBName}
+
+		anchor := newDB().Select("?", id).Where("? = ?", parent, nodeID)
+		step := newDB().Select("?", id).Joins("JOIN tree_cte_nodes ON ? = tree_cte_nodes.node_id", parent)
+		return db.Where("? IN (WITH RECURSIVE tree_cte_nodes(node_id) AS (? UNION ?) SELECT node_id FROM tree_cte_nodes)",
+			clause.Column{Table: clause.CurrentTable, Name: ts.id.DBName}, anchor, step)
+	}
+}
+
+/* ========================================================================
+ * 移动
+ * ======================================================================== */
+
+// MoveSubtree 将 id 及其子树移动到 newParentID 下，newParentID 为空时移动为根节点
+func MoveSubtree[T any](ctx context.Context, repo Repository[T], id, newParentID string, opts ...TreeOption) error {
+	ts, err := newTreeSchema(repo, opts)
+	if err != nil {
+		return err
+	}
+	if id == "" {
+		return errors.ErrInvalidArgument
+	}
+
+	return repo.Execute(ctx, func(txCtx context.Context) error {
+		node, err := repo.FindByID(txCtx, id)
+		if err != nil {
+			return err
+		}
+		nodeKey := ts.key(txCtx, ts.id, node)
+
+		var parent *T
+		if newParentID != "" {
+			if parent, err = repo.FindByID(txCtx, newParentID); err != nil {
+				return err
+			}
+			if err := checkTreeCycle(txCtx, repo, ts, nodeKey, parent); err != nil {
+				return err
+			}
+		}
+		if ts.key(txCtx, ts.parent, node) == keyOrEmpty(txCtx, ts, parent) {
+			return nil
+		}
+
+		var parentValue any
+		if parent != nil {
+			parentValue = ts.value(txCtx, ts.id, parent)
+		} else if ts.parent.FieldType.Kind() != reflect.Ptr {
+			parentValue = reflect.Zero(ts.parent.FieldType).Interface()
+		}
+		updates := map[string]any{ts.parent.DBName: parentValue}
+		if ts.path == nil {
+			return repo.UpdateByID(txCtx, id, updates)
+		}
+
+		// 先按旧路径读取子树，再更新节点与子树路径
+		descendants, err := findDescendants(txCtx, repo, ts, node)
+		if err != nil {
+			return err
+		}
+		newPath := ts.childPath(txCtx, nil)
+		if parent != nil {
+			newPath = ts.childPath(txCtx, parent)
+		}
+		oldPrefix := ts.pathOf(txCtx, node) + nodeKey + TreePathSeparator
+		newPrefix := newPath + nodeKey + TreePathSeparator
+
+		updates[ts.path.DBName] = newPath
+		if err := repo.UpdateByID(txCtx, id, updates); err != nil {
+			return err
+		}
+		for _, d := range descendants {
+			path := ts.pathOf(txCtx, d)
+			if !strings.HasPrefix(path, oldPrefix) {
+				return errors.New(errors.ErrCodeInternal, "tree path is inconsistent: "+ts.key(txCtx, ts.id, d))
+			}
+			path = newPrefix + strings.TrimPrefix(path, oldPrefix)
+			if err := repo.UpdateByID(txCtx, ts.key(txCtx, ts.id, d), map[string]any{ts.path.DBName: path}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func keyOrEmpty[T any](ctx context.Context, ts *treeSchema, model *T) string {
+	if model == nil {
+		return ""
+	}
+	return ts.key(ctx, ts.id, model)
+}
+
+// checkTreeCycle 沿 parent 向上查找，目标父节点为 nodeKey 或其后代时返回错误
+func checkTreeCycle[T any](ctx context.Context, repo Repository[T], ts *treeSchema, nodeKey string, parent *T) error {
+	visited := make(map[string]bool)
+	for current := parent; current != nil; {
+		key := ts.key(ctx, ts.id, current)
+		if key == nodeKey {
+			return errors.New(errors.ErrCodeInvalidArgument, "cannot move a tree node under itself or its descendant")
+		}
+		if visited[key] {
+			return errors.New(errors.ErrCodeInternal, "tree contains a cycle at "+key)
+		}
+		visited[key] = true
+
+		parentKey := ts.key(ctx, ts.parent, current)
+		if parentKey == "" {
+			return nil
+		}
+		next, err := repo.FindByID(ctx, parentKey)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		current = next
+	}
+	return nil
+}
+
+/* ========================================================================
+ * 路径维护
+ * ======================================================================== */
+
+// MaintainTreePath 注册物化路径维护钩子（需配置 WithPathColumn）
+func MaintainTreePath[T any](repo Repository[T], opts ...TreeOption) error {
+	ts, err := newTreeSchema(repo, opts)
+	if err != nil {
+		return err
+	}
+	if ts.path == nil {
+		return errors.New(errors.ErrCodeInvalidArgument, "tree path maintenance requires WithPathColumn")
+	}
+
+	repo.OnBeforeCreate(func(ctx context.Context, model *T) error {
+		var parent *T
+		if parentKey := ts.key(ctx, ts.parent, model); parentKey != "" {
+			p, err := repo.FindByID(ctx, parentKey)
+			if err != nil {
+				return err
+			}
+			parent = p
+		}
+		path := ts.childPath(ctx, nil)
+		if parent != nil {
+			path = ts.childPath(ctx, parent)
+		}
+		if err := ts.path.Set(ctx, reflect.ValueOf(model), path); err != nil {
+			return errors.Wrap(errors.ErrCodeInternal, "failed to set tree path", err)
+		}
+		return nil
+	})
+
+	// Update 忽略零值字段，只有携带非空父节点时才可能修改父节点
+	repo.OnBeforeUpdate(func(ctx context.Context, model *T) error {
+		parentKey := ts.key(ctx, ts.parent, model)
+		if parentKey == "" {
+			return nil
+		}
+		current, err := repo.FindByID(ctx, ts.key(ctx, ts.id, model))
+		if err != nil {
+			return err
+		}
+		if ts.key(ctx, ts.parent, current) != parentKey {
+			return errors.New(errors.ErrCodeInvalidArgument, "tree parent must be changed with MoveSubtree")
+		}
+		return nil
+	})
+	return nil
+}
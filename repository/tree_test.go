@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/soft_delete"
+)
+
+// deptNode 部门树模型（平台级，便于测试）
+type deptNode struct {
+	ID       string                `gorm:"column:id;primaryKey"`
+	ParentID *string               `gorm:"column:parent_id"`
+	Path     string                `gorm:"column:path"`
+	Name     string                `gorm:"column:name"`
+	Deleted  soft_delete.DeletedAt `gorm:"column:deleted;default:0;softDelete:flag"`
+}
+
+func (deptNode) TenantIgnored() bool { return true }
+
+func newTreeRepo(t *testing.T) Repository[deptNode] {
+	return NewRepository[deptNode](openTestDB(t, &deptNode{}))
+}
+
+// buildTree 创建 root -> (a -> (a1, a2 -> a21), b)
+func buildTree(t *testing.T, repo Repository[deptNode]) {
+	t.Helper()
+	parent := func(id string) *string { return &id }
+	for _, n := range []*deptNode{
+		{ID: "root"},
+		{ID: "a", ParentID: parent("root")},
+		{ID: "b", ParentID: parent("root")},
+		{ID: "a1", ParentID: parent("a")},
+		{ID: "a2", ParentID: parent("a")},
+		{ID: "a21", ParentID: parent("a2")},
+	} {
+		if err := repo.Create(context.Background(), n); err != nil {
+			t.Fatalf("create %s: %v", n.ID, err)
+		}
+	}
+}
+
+func treeIDs(nodes []*deptNode) []string {
+	ids := make([]string, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func TestTreeQueries(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo Repository[deptNode], _ *gorm.DB) {
+		buildTree(t, repo)
+		ctx := context.Background()
+
+		roots, err := FindChildren(ctx, repo, "")
+		if err != nil || !slices.Equal(treeIDs(roots), []string{"root"}) {
+			t.Fatalf("roots: %v %v", treeIDs(roots), err)
+		}
+		children, err := FindChildren(ctx, repo, "a")
+		if err != nil || !slices.Equal(treeIDs(children), []string{"a1", "a2"}) {
+			t.Fatalf("children: %v %v", treeIDs(children), err)
+		}
+		descendants, err := FindDescendants(ctx, repo, "a")
+		if err != nil || !slices.Equal(treeIDs(descendants), []string{"a1", "a2", "a21"}) {
+			t.Fatalf("descendants: %v %v", treeIDs(descendants), err)
+		}
+
+		// 已删除节点的子树不再可见
+		if err := repo.Delete(ctx, "a2"); err != nil {
+			t.Fatalf("delete: %v", err)
+		}
+		descendants, err = FindDescendants(ctx, repo, "root")
+		if err != nil || !slices.Equal(treeIDs(descendants), []string{"a", "a1", "b"}) {
+			t.Fatalf("descendants after delete: %v %v", treeIDs(descendants), err)
+		}
+		_, err = FindDescendants(ctx, repo, "missing")
+		expectCode(t, err, errors.ErrCodeNotFound)
+	})
+}
+
+func TestMoveSubtree(t *testing.T) {
+	repo := newTreeRepo(t)
+	if err := MaintainTreePath(repo, WithPathColumn("path")); err != nil {
+		t.Fatalf("maintain: %v", err)
+	}
+	buildTree(t, repo)
+	ctx := context.Background()
+	pathOf := func(id string) string {
+		n, err := repo.FindByID(ctx, id)
+		if err != nil {
+			t.Fatalf("find %s: %v", id, err)
+		}
+		return n.Path
+	}
+	if got := pathOf("a21"); got != "/root/a/a2/" {
+		t.Fatalf("unexpected path %q", got)
+	}
+
+	// 不能移动到自身或后代下
+	expectCode(t, MoveSubtree(ctx, repo, "a", "a", WithPathColumn("path")), errors.ErrCodeInvalidArgument)
+	expectCode(t, MoveSubtree(ctx, repo, "a", "a21", WithPathColumn("path")), errors.ErrCodeInvalidArgument)
+
+	if err := MoveSubtree(ctx, repo, "a2", "b", WithPathColumn("path")); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if got := pathOf("a2"); got != "/root/b/" {
+		t.Fatalf("unexpected moved path %q", got)
+	}
+	if got := pathOf("a21"); got != "/root/b/a2/" {
+		t.Fatalf("unexpected descendant path %q", got)
+	}
+	children, _ := FindChildren(ctx, repo, "b")
+	if !slices.Equal(treeIDs(children), []string{"a2"}) {
+		t.Fatalf("unexpected children of b: %v", treeIDs(children))
+	}
+
+	// 移动为根节点
+	if err := MoveSubtree(ctx, repo, "a2", "", WithPathColumn("path")); err != nil {
+		t.Fatalf("move to root: %v", err)
+	}
+	roots, _ := FindChildren(ctx, repo, "")
+	if !slices.Equal(treeIDs(roots), []string{"a2", "root"}) || pathOf("a21") != "/a2/" {
+		t.Fatalf("unexpected roots %v, path %q", treeIDs(roots), pathOf("a21"))
+	}
+
+	// 路径前缀查询与 CTE 结果一致
+	ts, _ := newTreeSchema(repo, []TreeOption{WithPathColumn("path")})
+	node, _ := repo.FindByID(ctx, "root")
+	byPath, err := repo.FindBySpec(ctx, pathPrefixSpec(ctx, ts, node))
+	if err != nil || !slices.Equal(treeIDs(byPath), []string{"a", "a1", "b"}) {
+		t.Fatalf("path prefix: %v %v", treeIDs(byPath), err)
+	}
+
+	// 直接修改父节点被拒绝
+	other := "b"
+	expectCode(t, repo.Update(ctx, &deptNode{ID: "a1", ParentID: &other}), errors.ErrCodeInvalidArgument)
+}
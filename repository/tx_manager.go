@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/aisgo/ais-go-pkg/ctxutil"
+
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * Transaction Manager - 跨仓储事务（Unit of Work）
+ * ========================================================================
+ * 职责: 在 context 中传播事务，使 fn 内使用的任意仓储共享同一事务
+ * 传播行为:
+ *   - PropagationNested（默认）: 已有事务时创建 SavePoint，fn 失败仅回滚到 SavePoint
+ *   - PropagationRequired: 已有事务时直接加入，不创建 SavePoint
+ *   - PropagationRequiresNew: 始终开启独立的新事务（独立连接）
+ * 隔离级别与只读选项仅在开启新事务时生效
+ *
+ * 使用示例:
+ *   tm := repository.NewTxManager(db)
+ *   err := tm.RunInTx(ctx, func(ctx context.Context) error {
+ *       if err := orderRepo.Create(ctx, order); err != nil {
+ *           return err // 自动回滚
+ *       }
+ *       return stockRepo.UpdateByID(ctx, order.SkuID, updates)
+ *   }, repository.WithIsolation(sql.LevelRepeatableRead))
+ * ======================================================================== */
+
+// Propagation 事务传播行为
+type Propagation int
+
+const (
+	PropagationNested      Propagation = iota // 嵌套（SavePoint）
+	PropagationRequired                       // 加入已有事务
+	PropagationRequiresNew                    // 独立新事务
+)
+
+// txConfig 事务选项
+type txConfig struct {
+	propagation Propagation
+	sqlOpts     *sql.TxOptions
+}
+
+// TxOption 事务选项
+type TxOption func(*txConfig)
+
+// WithPropagation 设置事务传播行为
+func WithPropagation(p Propagation) TxOption {
+	return func(c *txConfig) {
+		c.propagation = p
+	}
+}
+
+// WithIsolation 设置事务隔离级别
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(c *txConfig) {
+		if c.sqlOpts == nil {
+			c.sqlOpts = &sql.TxOptions{}
+		}
+		c.sqlOpts.Isolation = level
+	}
+}
+
+// WithReadOnly 设置只读事务
+func WithReadOnly() TxOption {
+	return func(c *txConfig) {
+		if c.sqlOpts == nil {
+			c.sqlOpts = &sql.TxOptions{}
+		}
+		c.sqlOpts.ReadOnly = true
+	}
+}
+
+// TxManager 事务管理器
+type TxManager struct {
+	db     *gorm.DB
+	router DBRouter
+}
+
+// NewTxManager 创建事务管理器
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// NewRoutedTxManager 创建按 router 选择物理库的事务管理器（新事务在 ctx 租户对应的 DB 上开启）
+func NewRoutedTxManager(router DBRouter) *TxManager {
+	return &TxManager{db: router.Default(), router: router}
+}
+
+// baseDB 开启新事务使用的 DB
+func (m *TxManager) baseDB(ctx context.Context) *gorm.DB {
+	if m.router != nil {
+		return routeDB(ctx, m.router, m.db)
+	}
+	return m.db.WithContext(ctx)
+}
+
+// RunInTx 在事务中执行 fn，fn 返回错误时回滚，否则提交
+// fn 收到的 ctx 携带事务，仓储方法使用该 ctx 即自动加入事务
+func (m *TxManager) RunInTx(ctx context.Context, fn func(ctx context.Context) error, opts ...TxOption) error {
+	if err := ctxutil.RequireDeadline(ctx, "repository"); err != nil {
+		return err
+	}
+	cfg := txConfig{propagation: PropagationNested}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if tx, ok := ctx.Value(ctxTxKey{}).(*gorm.DB); ok {
+		switch cfg.propagation {
+		case PropagationRequired:
+			return fn(ctx)
+		case PropagationNested:
+			// GORM 在已有事务上调用 Transaction 时自动使用 SavePoint
+			return tx.WithContext(ctx).Transaction(func(sp *gorm.DB) error {
+				return fn(context.WithValue(ctx, ctxTxKey{}, sp))
+			})
+		}
+	}
+
+	var txOpts []*sql.TxOptions
+	if cfg.sqlOpts != nil {
+		txOpts = append(txOpts, cfg.sqlOpts)
+	}
+	return m.baseDB(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, ctxTxKey{}, tx))
+	}, txOpts...)
+}
+
+// DB 返回 ctx 绑定的 DB（存在事务时返回事务 DB）
+func (m *TxManager) DB(ctx context.Context) *gorm.DB {
+	if m.router != nil && !InTx(ctx) {
+		return routeDB(ctx, m.router, m.db)
+	}
+	return getDBFromContext(ctx, m.db)
+}
+
+// InTx ctx 中是否存在事务
+func InTx(ctx context.Context) bool {
+	_, ok := ctx.Value(ctxTxKey{}).(*gorm.DB)
+	return ok
+}
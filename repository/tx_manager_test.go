@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/ctxutil"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type txTestModel struct {
+	ID   string `gorm:"column:id;type:char(26);primaryKey"`
+	Name string `gorm:"column:name"`
+}
+
+func (txTestModel) TenantIgnored() bool {
+	return true
+}
+
+func openTxTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	// 使用文件数据库，使 RequiresNew 的独立连接可见同一数据
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "tx.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&txTestModel{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func countTxModels(t *testing.T, db *gorm.DB) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(&txTestModel{}).Count(&n).Error; err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	return n
+}
+
+func TestTxManagerSpansRepositories(t *testing.T) {
+	db := openTxTestDB(t)
+	tm := NewTxManager(db)
+	repoA := NewRepository[txTestModel](db)
+	repoB := NewRepository[txTestModel](db)
+	boom := errors.New("boom")
+
+	err := tm.RunInTx(context.Background(), func(ctx context.Context) error {
+		if !InTx(ctx) {
+			t.Fatalf("expected tx in context")
+		}
+		if err := repoA.Create(ctx, &txTestModel{ID: ulidv2.Make().String(), Name: "a"}); err != nil {
+			return err
+		}
+		if err := repoB.Create(ctx, &txTestModel{ID: ulidv2.Make().String(), Name: "b"}); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+	if n := countTxModels(t, db); n != 0 {
+		t.Fatalf("expected rollback across repositories, got %d rows", n)
+	}
+}
+
+func TestTxManagerPropagation(t *testing.T) {
+	db := openTxTestDB(t)
+	tm := NewTxManager(db)
+	repo := NewRepository[txTestModel](db)
+	boom := errors.New("boom")
+
+	err := tm.RunInTx(context.Background(), func(ctx context.Context) error {
+		if err := repo.Create(ctx, &txTestModel{ID: ulidv2.Make().String(), Name: "outer"}); err != nil {
+			return err
+		}
+		// 嵌套事务失败仅回滚到 SavePoint
+		nestedErr := tm.RunInTx(ctx, func(ctx context.Context) error {
+			if err := repo.Create(ctx, &txTestModel{ID: ulidv2.Make().String(), Name: "nested"}); err != nil {
+				return err
+			}
+			return boom
+		})
+		if !errors.Is(nestedErr, boom) {
+			t.Fatalf("expected nested error, got %v", nestedErr)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("outer tx: %v", err)
+	}
+	if n := countTxModels(t, db); n != 1 {
+		t.Fatalf("expected only outer row committed, got %d", n)
+	}
+
+	// RequiresNew 独立提交，不受外层回滚影响
+	err = tm.RunInTx(context.Background(), func(ctx context.Context) error {
+		if err := tm.RunInTx(ctx, func(ctx context.Context) error {
+			return repo.Create(ctx, &txTestModel{ID: ulidv2.Make().String(), Name: "independent"})
+		}, WithPropagation(PropagationRequiresNew)); err != nil {
+			return err
+		}
+		if err := repo.Create(ctx, &txTestModel{ID: ulidv2.Make().String(), Name: "rolled back"}); err != nil {
+			return err
+		}
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected outer error, got %v", err)
+	}
+	if n := countTxModels(t, db); n != 2 {
+		t.Fatalf("expected independent row committed, got %d rows", n)
+	}
+
+	// Required 加入外层事务，失败回滚整个事务
+	err = tm.RunInTx(context.Background(), func(ctx context.Context) error {
+		outer := ctx
+		return tm.RunInTx(ctx, func(ctx context.Context) error {
+			if ctx != outer {
+				t.Fatalf("expected joined tx context")
+			}
+			_ = repo.Create(ctx, &txTestModel{ID: ulidv2.Make().String(), Name: "joined"})
+			return boom
+		}, WithPropagation(PropagationRequired))
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected joined error, got %v", err)
+	}
+	if n := countTxModels(t, db); n != 2 {
+		t.Fatalf("expected joined row rolled back, got %d rows", n)
+	}
+}
+
+func TestDeadlineEnforcement(t *testing.T) {
+	db := openTxTestDB(t)
+	repo := NewRepository[txTestModel](db)
+	if err := ctxutil.SetMode(ctxutil.ModeEnforce); err != nil {
+		t.Fatalf("set mode: %v", err)
+	}
+	t.Cleanup(func() { _ = ctxutil.SetMode(ctxutil.ModeOff) })
+
+	bg := context.Background()
+	if err := repo.Create(bg, &txTestModel{ID: ulidv2.Make().String()}); !errors.Is(err, ctxutil.ErrNoDeadline) {
+		t.Fatalf("create err = %v, want ErrNoDeadline", err)
+	}
+	called := false
+	err := repo.Execute(bg, func(context.Context) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, ctxutil.ErrNoDeadline) || called {
+		t.Fatalf("execute err = %v, called = %v", err, called)
+	}
+	if err := NewTxManager(db).RunInTx(bg, func(context.Context) error { return nil }); !errors.Is(err, ctxutil.ErrNoDeadline) {
+		t.Fatalf("run in tx err = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(bg, time.Minute)
+	defer cancel()
+	err = repo.Execute(ctx, func(ctx context.Context) error {
+		return repo.Create(ctx, &txTestModel{ID: ulidv2.Make().String()})
+	})
+	if err != nil {
+		t.Fatalf("execute with deadline: %v", err)
+	}
+	if n := countTxModels(t, db); n != 1 {
+		t.Fatalf("count = %d, want 1", n)
+	}
+}
@@ -0,0 +1,115 @@
+package request
+
+import (
+	"github.com/aisgo/ais-go-pkg/codec"
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/middleware"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Content Negotiation - 请求体编解码与内容协商
+ * ========================================================================
+ * 职责: 按路由声明允许的请求/响应格式（JSON / MsgPack / Protobuf 或自定义编解码器）
+ *   - Content-Type 不在允许列表 → 415
+ *   - Accept 无可接受格式 → 406；Accept 为空或通配时使用首个编解码器
+ *   - 请求体超过 max_body_size → 413
+ * 协商结果写入 response.SetCodec，后续 response.Ok / Error / Raw 按该格式输出；
+ * Bind 按请求 Content-Type 解码（限定在路由允许的编解码器内）
+ *
+ * 使用示例:
+ *   // YAML: content: {codecs: ["msgpack", "json"], max_body_size: 1048576}
+ *   neg, err := request.NewNegotiate(cfg.Content)
+ *   app.Post("/internal/events", neg, func(c fiber.Ctx) error {
+ *       var req EventBatch
+ *       if err := request.Bind(c, &req); err != nil {
+ *           return response.Error(c, err)
+ *       }
+ *       return response.OkWithData(c, result)
+ *   })
+ * ======================================================================== */
+
+// NegotiateConfig 路由级内容协商配置
+type NegotiateConfig struct {
+	Codecs      []string `yaml:"codecs"`        // 允许的编解码器名称（json / msgpack / protobuf），首个为默认响应格式，默认 ["json"]
+	MaxBodySize int      `yaml:"max_body_size"` // 请求体上限（字节），0 表示沿用全局限制，不能超过全局 max_body_size
+}
+
+// ErrUnsupportedMediaType 请求 Content-Type 不受支持
+var ErrUnsupportedMediaType = errors.New(errors.ErrCodeInvalidArgument, "unsupported content type")
+
+// ErrNotAcceptable 无法满足 Accept
+var ErrNotAcceptable = errors.New(errors.ErrCodeInvalidArgument, "not acceptable")
+
+const allowedCodecsLocalKey = "request_codecs"
+
+// NewNegotiate 按配置创建内容协商中间件，编解码器名称未注册时返回错误
+func NewNegotiate(cfg NegotiateConfig) (fiber.Handler, error) {
+	codecs, err := codec.ByNames(cfg.Codecs...)
+	if err != nil {
+		return nil, err
+	}
+	return negotiate(codecs, cfg.MaxBodySize), nil
+}
+
+// Negotiate 创建内容协商中间件，codecs 为空时仅允许 JSON
+func Negotiate(codecs ...codec.Codec) fiber.Handler {
+	return negotiate(codecs, 0)
+}
+
+func negotiate(codecs []codec.Codec, maxBodySize int) fiber.Handler {
+	if len(codecs) == 0 {
+		codecs = []codec.Codec{codec.JSON}
+	}
+	return func(c fiber.Ctx) error {
+		// 先协商响应格式，使后续错误响应也使用客户端可接受的格式
+		out := codec.Negotiate(c.Get(fiber.HeaderAccept), codecs...)
+		if out == nil {
+			return response.ErrorWithCode(c, fiber.StatusNotAcceptable, ErrNotAcceptable)
+		}
+		response.SetCodec(c, out)
+
+		if maxBodySize > 0 && (c.Request().Header.ContentLength() > maxBodySize || len(c.Body()) > maxBodySize) {
+			return response.ErrorWithCode(c, fiber.StatusRequestEntityTooLarge, middleware.ErrBodyTooLarge)
+		}
+		if len(c.Body()) > 0 {
+			if _, ok := codec.Match(contentType(c), codecs...); !ok {
+				return response.ErrorWithCode(c, fiber.StatusUnsupportedMediaType, ErrUnsupportedMediaType)
+			}
+		}
+
+		c.Locals(allowedCodecsLocalKey, codecs)
+		return c.Next()
+	}
+}
+
+// Bind 按请求 Content-Type 解码请求体到 v
+// 挂载 Negotiate 时限定为路由允许的编解码器，否则可使用任意已注册编解码器；
+// 未携带 Content-Type 时按 JSON 解码，解码失败返回 InvalidArgument
+func Bind(c fiber.Ctx, v any) error {
+	allowed, _ := c.Locals(allowedCodecsLocalKey).([]codec.Codec)
+
+	cd, ok := codec.Match(contentType(c), allowed...)
+	if !ok {
+		return ErrUnsupportedMediaType
+	}
+
+	body := c.Body()
+	if len(body) == 0 {
+		return errors.New(errors.ErrCodeInvalidArgument, "request body is empty")
+	}
+	if err := cd.Unmarshal(body, v); err != nil {
+		return errors.Wrap(errors.ErrCodeInvalidArgument, "invalid "+cd.Name()+" request body", err)
+	}
+	return nil
+}
+
+// contentType 请求 Content-Type，未携带时视为 JSON
+func contentType(c fiber.Ctx) string {
+	if ct := c.Get(fiber.HeaderContentType); ct != "" {
+		return ct
+	}
+	return codec.JSON.ContentType()
+}
@@ -0,0 +1,110 @@
+package request
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Request Binding - 分页/排序查询参数解析
+ * ========================================================================
+ * 职责: 统一解析 page/page_size/sort 查询参数，生成仓储分页参数与排序选项
+ * 排序语法:
+ *   - sort=created_at,-name        前缀 "-" 表示降序
+ *   - sort=created_at:desc,name    也支持 ":asc" / ":desc" 后缀
+ * 安全: 排序列必须通过 repository.IsSafeColumnName 校验且在白名单内
+ *
+ * 使用示例:
+ *   page, err := request.ParsePage(c)
+ *   sort, err := request.ParseSort(c, "created_at", "name")
+ *   result, err := repo.FindPageWithOpts(ctx, page.Page, page.PageSize, "", []repository.Option{sort})
+ * ======================================================================== */
+
+// 查询参数名
+const (
+	QueryPage     = "page"
+	QueryPageSize = "page_size"
+	QuerySort     = "sort"
+)
+
+// ParsePage 解析 page/page_size 查询参数
+// 缺省时使用默认值，非法值返回 InvalidArgument，page_size 超过上限时截断为 repository.MaxPageSize
+func ParsePage(c fiber.Ctx) (repository.PageRequest, error) {
+	page, err := positiveQuery(c, QueryPage)
+	if err != nil {
+		return repository.PageRequest{}, err
+	}
+	pageSize, err := positiveQuery(c, QueryPageSize)
+	if err != nil {
+		return repository.PageRequest{}, err
+	}
+	return repository.PageRequest{Page: page, PageSize: pageSize}.Normalize(), nil
+}
+
+// positiveQuery 读取正整数查询参数，缺省返回 0
+func positiveQuery(c fiber.Ctx, key string) (int, error) {
+	raw := strings.TrimSpace(c.Query(key))
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, errors.New(errors.ErrCodeInvalidArgument, "invalid query parameter: "+key).
+			WithDetail("param", key)
+	}
+	return n, nil
+}
+
+// ParseSort 解析 sort 查询参数并返回排序选项
+// allowedColumns 为允许排序的列；为空时仅校验列名安全性
+// 未携带 sort 参数时返回不修改排序的选项
+func ParseSort(c fiber.Ctx, allowedColumns ...string) (repository.Option, error) {
+	orderBy, err := ParseOrderBy(c.Query(QuerySort), allowedColumns...)
+	if err != nil {
+		return nil, err
+	}
+	if orderBy == "" {
+		return func(*repository.QueryOption) {}, nil
+	}
+	return repository.WithOrderBy(orderBy), nil
+}
+
+// ParseOrderBy 将排序表达式转换为 ORDER BY 子句（如 "created_at DESC, name ASC"）
+func ParseOrderBy(sort string, allowedColumns ...string) (string, error) {
+	var clauses []string
+	for _, field := range strings.Split(sort, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		column, dir := field, "ASC"
+		if rest, ok := strings.CutPrefix(column, "-"); ok {
+			column, dir = rest, "DESC"
+		} else if col, suffix, ok := strings.Cut(column, ":"); ok {
+			column = col
+			switch strings.ToLower(suffix) {
+			case "asc":
+			case "desc":
+				dir = "DESC"
+			default:
+				return "", errors.New(errors.ErrCodeInvalidArgument, "invalid sort direction: "+suffix).
+					WithDetail("param", QuerySort)
+			}
+		}
+
+		if !repository.IsSafeColumnName(column) ||
+			(len(allowedColumns) > 0 && !slices.Contains(allowedColumns, column)) {
+			return "", errors.New(errors.ErrCodeInvalidArgument, "unsupported sort column: "+column).
+				WithDetail("param", QuerySort)
+		}
+		clauses = append(clauses, column+" "+dir)
+	}
+	return strings.Join(clauses, ", "), nil
+}
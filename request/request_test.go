@@ -0,0 +1,175 @@
+package request
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/codec"
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func withCtx(t *testing.T, target string, fn func(c fiber.Ctx)) {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/", func(c fiber.Ctx) error {
+		fn(c)
+		return nil
+	})
+	if _, err := app.Test(httptest.NewRequest("GET", target, nil)); err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	cases := []struct {
+		target  string
+		want    repository.PageRequest
+		wantErr bool
+	}{
+		{"/", repository.PageRequest{Page: 1, PageSize: repository.DefaultPageSize}, false},
+		{"/?page=3&page_size=50", repository.PageRequest{Page: 3, PageSize: 50}, false},
+		{"/?page_size=5000", repository.PageRequest{Page: 1, PageSize: repository.MaxPageSize}, false},
+		{"/?page=abc", repository.PageRequest{}, true},
+		{"/?page_size=0", repository.PageRequest{}, true},
+	}
+	for _, tc := range cases {
+		withCtx(t, tc.target, func(c fiber.Ctx) {
+			got, err := ParsePage(c)
+			if tc.wantErr {
+				if errors.Code(err) != errors.ErrCodeInvalidArgument {
+					t.Errorf("%s: expected InvalidArgument, got %v", tc.target, err)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("%s: got %+v %v, want %+v", tc.target, got, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseOrderBy(t *testing.T) {
+	got, err := ParseOrderBy("created_at,-name, age:desc", "created_at", "name", "age")
+	if err != nil || got != "created_at ASC, name DESC, age DESC" {
+		t.Fatalf("unexpected order by: %q %v", got, err)
+	}
+	if _, err := ParseOrderBy("password", "name"); err == nil {
+		t.Fatalf("expected error for column outside allowlist")
+	}
+	if _, err := ParseOrderBy("name;drop table users"); err == nil {
+		t.Fatalf("expected error for unsafe column")
+	}
+	if _, err := ParseOrderBy("name:sideways"); err == nil {
+		t.Fatalf("expected error for invalid direction")
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	withCtx(t, "/?sort=-created_at", func(c fiber.Ctx) {
+		opt, err := ParseSort(c, "created_at")
+		if err != nil {
+			t.Fatalf("parse sort: %v", err)
+		}
+		if o := repository.ApplyOptions([]repository.Option{opt}); o.OrderBy != "created_at DESC" {
+			t.Errorf("unexpected order by: %q", o.OrderBy)
+		}
+	})
+	withCtx(t, "/", func(c fiber.Ctx) {
+		opt, err := ParseSort(c, "created_at")
+		if err != nil {
+			t.Fatalf("parse sort: %v", err)
+		}
+		o := repository.ApplyOptions([]repository.Option{repository.WithOrderBy("id DESC"), opt})
+		if o.OrderBy != "id DESC" {
+			t.Errorf("expected default order preserved, got %q", o.OrderBy)
+		}
+	})
+}
+
+func TestNegotiateAndBind(t *testing.T) {
+	type payload struct {
+		Name string `json:"name" msgpack:"name"`
+	}
+
+	neg, err := NewNegotiate(NegotiateConfig{Codecs: []string{"json", "msgpack"}, MaxBodySize: 64})
+	if err != nil {
+		t.Fatalf("NewNegotiate: %v", err)
+	}
+	if _, err := NewNegotiate(NegotiateConfig{Codecs: []string{"xml"}}); err == nil {
+		t.Fatal("expected unknown codec error")
+	}
+
+	app := fiber.New()
+	app.Post("/echo", neg, func(c fiber.Ctx) error {
+		var p payload
+		if err := Bind(c, &p); err != nil {
+			return response.Error(c, err)
+		}
+		return response.OkWithData(c, p)
+	})
+
+	do := func(contentType, accept string, body []byte) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/echo", bytes.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		return resp
+	}
+
+	// msgpack 请求 → msgpack 响应
+	body, _ := codec.MsgPack.Marshal(payload{Name: "alice"})
+	resp := do("application/msgpack", "application/msgpack", body)
+	raw, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get("Content-Type") != "application/msgpack" {
+		t.Fatalf("msgpack: status = %d, content-type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	var out struct {
+		Code int     `msgpack:"code"`
+		Data payload `msgpack:"data"`
+	}
+	if err := codec.MsgPack.Unmarshal(raw, &out); err != nil || out.Data.Name != "alice" || out.Code != 200 {
+		t.Fatalf("msgpack body = %+v, %v", out, err)
+	}
+
+	// 未携带 Content-Type / Accept 时按 JSON
+	resp = do("", "", []byte(`{"name":"bob"}`))
+	raw, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || !bytes.Contains(raw, []byte(`"bob"`)) {
+		t.Fatalf("json: status = %d, body = %s", resp.StatusCode, raw)
+	}
+
+	cases := []struct {
+		contentType, accept string
+		body                []byte
+		want                int
+	}{
+		{"application/x-protobuf", "", []byte{0x0a}, fiber.StatusUnsupportedMediaType},
+		{"application/json", "application/x-protobuf", []byte(`{}`), fiber.StatusNotAcceptable},
+		{"application/json", "", bytes.Repeat([]byte("a"), 65), fiber.StatusRequestEntityTooLarge},
+		{"application/json", "", []byte(`{bad`), fiber.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		resp := do(tc.contentType, tc.accept, tc.body)
+		resp.Body.Close()
+		if resp.StatusCode != tc.want {
+			t.Errorf("%s accept=%q: status = %d, want %d", tc.contentType, tc.accept, resp.StatusCode, tc.want)
+		}
+	}
+}
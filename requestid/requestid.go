@@ -0,0 +1,125 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/oklog/ulid/v2"
+)
+
+/* ========================================================================
+ * Request ID - 请求 ID 与关联 ID
+ * ========================================================================
+ * 职责: 定义请求 ID / 关联 ID 的 context 存取与跨进程透传约定
+ * 概念:
+ *   - Request ID: 单次请求标识，入口未携带时生成
+ *   - Correlation ID: 整条调用链标识，入口未携带时与 Request ID 相同，下游原样透传
+ * 透传:
+ *   - HTTP: X-Request-ID / X-Correlation-ID 请求头（httpclient 自动写入）
+ *   - gRPC: x-request-id / x-correlation-id metadata（transport/grpc 拦截器自动处理）
+ *   - MQ:   同名消息属性（mq 生产者自动写入，消费者 ctx 自动恢复）
+ *   - 日志: logger.WithContext 自动附带 request_id / correlation_id 字段
+ *
+ * 使用示例:
+ *   app.Use(middleware.RequestID(middleware.RequestIDConfig{}))
+ *   id := requestid.FromContext(c.Context())
+ *   log.WithContext(ctx).Info("order created")
+ * ======================================================================== */
+
+const (
+	// Header 请求 ID 头
+	Header = "X-Request-ID"
+	// CorrelationHeader 关联 ID 头
+	CorrelationHeader = "X-Correlation-ID"
+	// MetadataKey gRPC metadata 键（小写）
+	MetadataKey = "x-request-id"
+	// CorrelationMetadataKey gRPC metadata 关联 ID 键（小写）
+	CorrelationMetadataKey = "x-correlation-id"
+	// LocalsKey Fiber Locals 键
+	LocalsKey = "request_id"
+	// MaxLength 接受的外部 ID 最大长度
+	MaxLength = 128
+)
+
+type requestIDKey struct{}
+
+type correlationIDKey struct{}
+
+// New 生成新的请求 ID（ULID）
+func New() string {
+	return ulid.Make().String()
+}
+
+// Valid 校验外部传入的 ID：非空、不超过 MaxLength、仅可见 ASCII 字符
+// 防止日志注入与超长头
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// WithContext 将请求 ID 写入 context
+func WithContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// FromContext 从 context 读取请求 ID，不存在时返回空字符串
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithCorrelationID 将关联 ID 写入 context
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext 从 context 读取关联 ID，未设置时回退为请求 ID
+func CorrelationIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, _ := ctx.Value(correlationIDKey{}).(string); id != "" {
+		return id
+	}
+	return FromContext(ctx)
+}
+
+// Inject 将 ctx 中的 ID 写入 set 回调（已存在的值由 set 自行决定是否覆盖）
+func Inject(ctx context.Context, set func(key, value string)) {
+	if id := FromContext(ctx); id != "" {
+		set(Header, id)
+	}
+	if id := CorrelationIDFromContext(ctx); id != "" {
+		set(CorrelationHeader, id)
+	}
+}
+
+// Extract 通过 get 回调读取 ID 并写入 ctx，非法值被忽略
+func Extract(ctx context.Context, get func(key string) string) context.Context {
+	if id := get(Header); Valid(id) {
+		ctx = WithContext(ctx, id)
+	}
+	if id := get(CorrelationHeader); Valid(id) {
+		ctx = WithCorrelationID(ctx, id)
+	}
+	return ctx
+}
+
+// InjectHeader 将 ctx 中的 ID 写入 HTTP 请求头（不覆盖调用方显式设置的值）
+func InjectHeader(ctx context.Context, h http.Header) {
+	Inject(ctx, func(key, value string) {
+		if h.Get(key) == "" {
+			h.Set(key, value)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package requestid
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	for id, want := range map[string]bool{
+		"":                               false,
+		"abc-123_XYZ.:/":                 true,
+		"with space":                     false,
+		"line\nbreak":                    false,
+		"中文":                             false,
+		strings.Repeat("a", MaxLength):   true,
+		strings.Repeat("a", MaxLength+1): false,
+	} {
+		if got := Valid(id); got != want {
+			t.Fatalf("Valid(%q) = %v, want %v", id, got, want)
+		}
+	}
+	if id := New(); len(id) != 26 || !Valid(id) {
+		t.Fatalf("unexpected generated id %q", id)
+	}
+}
+
+func TestContextAndHeader(t *testing.T) {
+	ctx := WithContext(context.Background(), "req-1")
+	if CorrelationIDFromContext(ctx) != "req-1" {
+		t.Fatalf("expected correlation id to fall back to request id")
+	}
+	ctx = WithCorrelationID(ctx, "chain-1")
+
+	h := http.Header{}
+	h.Set(Header, "explicit")
+	InjectHeader(ctx, h)
+	if h.Get(Header) != "explicit" || h.Get(CorrelationHeader) != "chain-1" {
+		t.Fatalf("unexpected header: %v", h)
+	}
+
+	got := Extract(context.Background(), h.Get)
+	if FromContext(got) != "explicit" || CorrelationIDFromContext(got) != "chain-1" {
+		t.Fatalf("unexpected extracted ids: %q %q", FromContext(got), CorrelationIDFromContext(got))
+	}
+	if FromContext(context.Background()) != "" {
+		t.Fatalf("expected empty request id")
+	}
+}
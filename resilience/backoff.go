@@ -0,0 +1,67 @@
+package resilience
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+/* ========================================================================
+ * Backoff - 指数退避
+ * ========================================================================
+ * 职责: 计算带抖动的指数退避间隔，供 gRPC/HTTP 客户端重试使用
+ * 公式: min(Base * Multiplier^attempt, Max) * (1 ± Jitter)
+ * ======================================================================== */
+
+// BackoffConfig 退避配置
+type BackoffConfig struct {
+	Base       time.Duration `yaml:"base"`       // 初始间隔，默认 100ms
+	Max        time.Duration `yaml:"max"`        // 最大间隔，默认 5s
+	Multiplier float64       `yaml:"multiplier"` // 倍数，默认 2
+	Jitter     float64       `yaml:"jitter"`     // 抖动比例 [0,1]，默认 0.2
+}
+
+// DefaultBackoffConfig 默认退避配置
+func DefaultBackoffConfig() BackoffConfig {
+	return BackoffConfig{
+		Base:       100 * time.Millisecond,
+		Max:        5 * time.Second,
+		Multiplier: 2,
+		Jitter:     0.2,
+	}
+}
+
+// withDefaults 零值字段使用默认值
+func (c BackoffConfig) withDefaults() BackoffConfig {
+	def := DefaultBackoffConfig()
+	if c.Base <= 0 {
+		c.Base = def.Base
+	}
+	if c.Max <= 0 {
+		c.Max = def.Max
+	}
+	if c.Multiplier < 1 {
+		c.Multiplier = def.Multiplier
+	}
+	if c.Jitter < 0 || c.Jitter > 1 {
+		c.Jitter = def.Jitter
+	}
+	return c
+}
+
+// Delay 返回第 attempt 次重试（从 0 开始）前的等待时间
+func (c BackoffConfig) Delay(attempt int) time.Duration {
+	c = c.withDefaults()
+	if attempt < 0 {
+		attempt = 0
+	}
+
+	delay := float64(c.Base) * math.Pow(c.Multiplier, float64(attempt))
+	if delay > float64(c.Max) {
+		delay = float64(c.Max)
+	}
+	if c.Jitter > 0 {
+		delay *= 1 + c.Jitter*(2*rand.Float64()-1)
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,189 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/metrics"
+)
+
+/* ========================================================================
+ * Circuit Breaker - 熔断器
+ * ========================================================================
+ * 职责: 下游持续失败时快速失败，避免雪崩
+ * 状态机:
+ *   - Closed: 正常放行，连续失败达到 FailureThreshold 后进入 Open
+ *   - Open: 拒绝请求（ErrBreakerOpen），经过 OpenTimeout 后进入 HalfOpen
+ *   - HalfOpen: 放行最多 HalfOpenMaxCalls 个探测请求，全部成功则 Closed，任一失败则 Open
+ * 使用示例:
+ *     b := resilience.NewBreaker("user-service", resilience.BreakerConfig{})
+ *     err := b.Execute(func() error { return call() })
+ * ======================================================================== */
+
+// ErrBreakerOpen 熔断器打开
+var ErrBreakerOpen = errors.New("circuit breaker is open")
+
+// State 熔断器状态
+type State int
+
+const (
+	StateClosed   State = iota // 关闭（正常）
+	StateOpen                  // 打开（熔断）
+	StateHalfOpen              // 半开（探测）
+)
+
+// String 返回状态名称
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half_open"
+	default:
+		return "unknown"
+	}
+}
+
+var breakerState = metrics.NewGauge("app", "resilience", "breaker_state",
+	"Circuit breaker state (0 closed, 1 open, 2 half-open)", []string{"name"})
+
+// BreakerConfig 熔断器配置
+type BreakerConfig struct {
+	FailureThreshold int           `yaml:"failure_threshold"`   // 连续失败阈值，默认 5
+	OpenTimeout      time.Duration `yaml:"open_timeout"`        // 打开状态持续时间，默认 30s
+	HalfOpenMaxCalls int           `yaml:"half_open_max_calls"` // 半开状态探测请求数，默认 1
+}
+
+// withDefaults 零值字段使用默认值
+func (c BreakerConfig) withDefaults() BreakerConfig {
+	if c.FailureThreshold <= 0 {
+		c.FailureThreshold = 5
+	}
+	if c.OpenTimeout <= 0 {
+		c.OpenTimeout = 30 * time.Second
+	}
+	if c.HalfOpenMaxCalls <= 0 {
+		c.HalfOpenMaxCalls = 1
+	}
+	return c
+}
+
+// Breaker 熔断器
+type Breaker struct {
+	name string
+	cfg  BreakerConfig
+	now  func() time.Time
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	halfOpen  int // 半开状态已放行的探测数
+	successes int // 半开状态探测成功数
+}
+
+// NewBreaker 创建熔断器
+func NewBreaker(name string, cfg BreakerConfig) *Breaker {
+	b := &Breaker{
+		name: name,
+		cfg:  cfg.withDefaults(),
+		now:  time.Now,
+	}
+	breakerState.WithLabelValues(name).Set(float64(StateClosed))
+	return b
+}
+
+// Name 返回熔断器名称
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State 返回当前状态
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	return b.state
+}
+
+// Allow 申请放行，返回的 done 必须在调用结束后执行以上报结果
+func (b *Breaker) Allow() (done func(success bool), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refresh()
+	switch b.state {
+	case StateOpen:
+		return nil, ErrBreakerOpen
+	case StateHalfOpen:
+		if b.halfOpen >= b.cfg.HalfOpenMaxCalls {
+			return nil, ErrBreakerOpen
+		}
+		b.halfOpen++
+	}
+
+	var once sync.Once
+	return func(success bool) {
+		once.Do(func() { b.report(success) })
+	}, nil
+}
+
+// Execute 在熔断器保护下执行 fn，fn 返回非 nil 错误视为失败
+func (b *Breaker) Execute(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	done(err == nil)
+	return err
+}
+
+// report 上报调用结果
+func (b *Breaker) report(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		if success {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.setState(StateOpen)
+		}
+	case StateHalfOpen:
+		if !success {
+			b.setState(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenMaxCalls {
+			b.setState(StateClosed)
+		}
+	}
+}
+
+// refresh 打开状态超时后切换为半开（需持有锁）
+func (b *Breaker) refresh() {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.cfg.OpenTimeout {
+		b.setState(StateHalfOpen)
+	}
+}
+
+// setState 切换状态并重置计数（需持有锁）
+func (b *Breaker) setState(state State) {
+	b.state = state
+	b.failures = 0
+	b.halfOpen = 0
+	b.successes = 0
+	if state == StateOpen {
+		b.openedAt = b.now()
+	}
+	breakerState.WithLabelValues(b.name).Set(float64(state))
+}
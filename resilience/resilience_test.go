@@ -0,0 +1,83 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	cfg := BackoffConfig{Base: 100 * time.Millisecond, Max: time.Second, Multiplier: 2, Jitter: 0.2}
+
+	for attempt, want := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		for range 20 {
+			got := cfg.Delay(attempt)
+			lo, hi := time.Duration(float64(want)*0.8), time.Duration(float64(want)*1.2)
+			if got < lo || got > hi {
+				t.Fatalf("attempt %d: delay %v out of [%v, %v]", attempt, got, lo, hi)
+			}
+		}
+	}
+
+	if got := (BackoffConfig{Jitter: 0}).Delay(0); got != 100*time.Millisecond {
+		t.Fatalf("expected default base without jitter, got %v", got)
+	}
+}
+
+func TestBreakerTransitions(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := NewBreaker("test", BreakerConfig{FailureThreshold: 2, OpenTimeout: time.Second})
+	b.now = func() time.Time { return now }
+
+	failure := errors.New("boom")
+	for range 2 {
+		if err := b.Execute(func() error { return failure }); !errors.Is(err, failure) {
+			t.Fatalf("expected call error, got %v", err)
+		}
+	}
+	if b.State() != StateOpen {
+		t.Fatalf("expected open, got %s", b.State())
+	}
+	if err := b.Execute(func() error { return nil }); !errors.Is(err, ErrBreakerOpen) {
+		t.Fatalf("expected ErrBreakerOpen, got %v", err)
+	}
+
+	now = now.Add(time.Second)
+	if b.State() != StateHalfOpen {
+		t.Fatalf("expected half-open, got %s", b.State())
+	}
+
+	// 半开状态仅放行 HalfOpenMaxCalls 个探测
+	done, err := b.Allow()
+	if err != nil {
+		t.Fatalf("expected probe allowed, got %v", err)
+	}
+	if _, err := b.Allow(); !errors.Is(err, ErrBreakerOpen) {
+		t.Fatalf("expected second probe rejected, got %v", err)
+	}
+	done(true)
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed, got %s", b.State())
+	}
+
+	// 半开探测失败重新打开
+	_ = b.Execute(func() error { return failure })
+	_ = b.Execute(func() error { return failure })
+	now = now.Add(time.Second)
+	_ = b.Execute(func() error { return failure })
+	if b.State() != StateOpen {
+		t.Fatalf("expected reopen after failed probe, got %s", b.State())
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	b := NewBreaker("test-reset", BreakerConfig{FailureThreshold: 2})
+	failure := errors.New("boom")
+
+	_ = b.Execute(func() error { return failure })
+	_ = b.Execute(func() error { return nil })
+	_ = b.Execute(func() error { return failure })
+	if b.State() != StateClosed {
+		t.Fatalf("expected closed, got %s", b.State())
+	}
+}
@@ -0,0 +1,53 @@
+package response
+
+import (
+	stderrors "errors"
+
+	"github.com/aisgo/ais-go-pkg/codec"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Response Codec - 按内容协商结果序列化响应
+ * ========================================================================
+ * 职责: request.Negotiate 协商出响应编解码器后，Ok / Error / Raw 等按该格式输出
+ * 说明:
+ *   - 未协商或协商为 JSON 时沿用 c.JSON（使用 Fiber 配置的 JSONEncoder）
+ *   - 信封无法用 Protobuf 表达：成功响应直接输出 data（需实现 proto.Message），
+ *     其余情况回退为 JSON
+ * ======================================================================== */
+
+const codecLocalKey = "response_codec"
+
+// SetCodec 设置当前请求的响应编解码器
+func SetCodec(c fiber.Ctx, cd codec.Codec) {
+	c.Locals(codecLocalKey, cd)
+}
+
+// CodecFromCtx 获取当前请求协商的响应编解码器
+func CodecFromCtx(c fiber.Ctx) (codec.Codec, bool) {
+	cd, ok := c.Locals(codecLocalKey).(codec.Codec)
+	return cd, ok && cd != nil
+}
+
+// send 按协商的编解码器输出 body；env 非 nil 时允许以 data 代替信封输出
+func send(c fiber.Ctx, body any, env *Envelope) error {
+	cd, ok := CodecFromCtx(c)
+	if !ok || cd.Name() == codec.JSON.Name() {
+		return c.JSON(body)
+	}
+
+	data, err := cd.Marshal(body)
+	if stderrors.Is(err, codec.ErrUnsupportedType) && env != nil && env.Success() && env.Data != nil {
+		data, err = cd.Marshal(env.Data)
+	}
+	if stderrors.Is(err, codec.ErrUnsupportedType) {
+		return c.JSON(body)
+	}
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, cd.ContentType())
+	return c.Send(data)
+}
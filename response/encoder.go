@@ -0,0 +1,91 @@
+package response
+
+import (
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Response Encoder - 响应信封编码
+ * ========================================================================
+ * 职责: 将响应要素（状态码/业务码/消息/数据）编码为响应体，支持替换默认 Result 结构
+ * 作用范围: Ok / Error / PageData / 快捷响应 / NewFiberErrorHandler
+ * 使用示例:
+ *     response.SetEncoder(func(c fiber.Ctx, env response.Envelope) any {
+ *         return fiber.Map{
+ *             "success":   env.Success(),
+ *             "errorCode": strconv.Itoa(env.Code),
+ *             "message":   env.Msg,
+ *             "data":      env.Data,
+ *         }
+ *     })
+ * ======================================================================== */
+
+// Envelope 响应要素
+type Envelope struct {
+	Status    int    // HTTP 状态码
+	Code      int    // 业务响应码
+	Msg       string // 响应消息
+	Data      any    // 响应数据（为 nil 时默认编码器输出空对象）
+	RequestID string // 请求 ID（未显式设置时取 RequestID 中间件生成的值，可能为空）
+	Err       error  // 原始错误（成功响应为 nil）
+}
+
+// Success 是否为成功响应
+func (e Envelope) Success() bool {
+	return e.Err == nil && e.Status < fiber.StatusBadRequest
+}
+
+// Encoder 响应信封编码器，返回值将以 JSON 输出
+type Encoder func(c fiber.Ctx, env Envelope) any
+
+var currentEncoder atomic.Pointer[Encoder]
+
+// SetEncoder 设置全局响应编码器，传入 nil 恢复默认 Result 结构
+func SetEncoder(fn Encoder) {
+	if fn == nil {
+		currentEncoder.Store(nil)
+		return
+	}
+	currentEncoder.Store(&fn)
+}
+
+// DefaultEncoder 默认编码器，输出 Result 结构
+func DefaultEncoder(_ fiber.Ctx, env Envelope) any {
+	return newResp(env.Code, env.Msg, env.Data, env.RequestID)
+}
+
+// encode 使用当前编码器编码响应体
+func encode(c fiber.Ctx, env Envelope) any {
+	if fn := currentEncoder.Load(); fn != nil {
+		return (*fn)(c, env)
+	}
+	return DefaultEncoder(c, env)
+}
+
+// write 输出信封响应
+func write(c fiber.Ctx, env Envelope) error {
+	env.Status = normalizeHTTPStatusCode(env.Status)
+	if env.RequestID == "" {
+		env.RequestID = requestID(c)
+	}
+	c.Status(env.Status)
+	return send(c, encode(c, env), &env)
+}
+
+// Raw 输出不经信封包装的响应（Webhook 回调、文件下载等）
+// v 为 []byte / string 时原样写出，其余类型按协商的格式编码（默认 JSON）
+func Raw(c fiber.Ctx, status int, v any) error {
+	c.Status(normalizeHTTPStatusCode(status))
+	switch body := v.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return c.Send(body)
+	case string:
+		return c.SendString(body)
+	default:
+		return send(c, body, nil)
+	}
+}
@@ -0,0 +1,149 @@
+package response
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/requestid"
+	"github.com/aisgo/ais-go-pkg/validator"
+
+	playground "github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Fiber Error Handler - 统一错误处理器
+ * ========================================================================
+ * 职责: 将 handler 返回的错误统一转换为标准响应（经 Encoder 编码）
+ * 转换规则:
+ *   - BizError            -> 映射的 HTTP 状态码，data.details 携带详情
+ *   - *fiber.Error        -> 原状态码与消息（如 404 路由不存在）
+ *   - ValidationError     -> 400，data.errors 为字段错误
+ *   - PanicError（Recover）-> 500，记录调用栈
+ *   - 其他错误            -> 500，消息不外泄
+ * 使用示例:
+ *     fx.Provide(func(log *logger.Logger) fiber.ErrorHandler {
+ *         return response.NewFiberErrorHandler(log)
+ *     })
+ *     app.Use(response.Recover())
+ * ======================================================================== */
+
+// RequestIDHeader 请求 ID 头
+const RequestIDHeader = requestid.Header
+
+// PanicError handler panic 后由 Recover 转换得到的错误
+type PanicError struct {
+	Value any    // panic 值
+	Stack []byte // 调用栈
+}
+
+// Error 实现 error 接口
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Recover 返回 panic 恢复中间件，panic 转换为 *PanicError 交给 ErrorHandler 处理
+func Recover() fiber.Handler {
+	return func(c fiber.Ctx) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = &PanicError{Value: r, Stack: debug.Stack()}
+			}
+		}()
+		return c.Next()
+	}
+}
+
+// NewFiberErrorHandler 创建统一的 Fiber ErrorHandler
+func NewFiberErrorHandler(log *logger.Logger) fiber.ErrorHandler {
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	return func(c fiber.Ctx, err error) error {
+		env := resolveError(c, err)
+		env.RequestID = requestID(c)
+
+		if env.Status >= http.StatusInternalServerError {
+			fields := []zap.Field{
+				zap.Error(err),
+				zap.String("method", c.Method()),
+				zap.String("path", c.Path()),
+				zap.Int("status", env.Status),
+			}
+			if env.RequestID != "" {
+				fields = append(fields, zap.String("request_id", env.RequestID))
+			}
+			var panicErr *PanicError
+			if stderrors.As(err, &panicErr) {
+				fields = append(fields, zap.ByteString("stack", panicErr.Stack))
+			}
+			log.Error("HTTP request failed", fields...)
+		}
+
+		return write(c, env)
+	}
+}
+
+// resolveError 将错误转换为响应要素
+func resolveError(c fiber.Ctx, err error) Envelope {
+	if bizErr, ok := errors.AsBizError(err); ok {
+		status, _ := errors.ToHTTPResponse(bizErr)
+		return bizEnvelope(c, status, bizErr)
+	}
+
+	var fiberErr *fiber.Error
+	if stderrors.As(err, &fiberErr) {
+		status := normalizeHTTPStatusCode(fiberErr.Code)
+		return Envelope{Status: status, Code: status, Msg: fiberErr.Message, Err: err}
+	}
+
+	var validationErr *validator.ValidationError
+	if stderrors.As(err, &validationErr) {
+		return Envelope{
+			Status: http.StatusBadRequest,
+			Code:   int(errors.ErrCodeInvalidArgument),
+			Msg:    localize(c, errors.ErrInvalidArgument.Message),
+			Data:   fiber.Map{"errors": validationErr.Errors},
+			Err:    err,
+		}
+	}
+
+	var fieldErrs playground.ValidationErrors
+	if stderrors.As(err, &fieldErrs) {
+		fields := make(map[string][]string, len(fieldErrs))
+		for _, fe := range fieldErrs {
+			fields[fe.Field()] = append(fields[fe.Field()], fe.Tag())
+		}
+		return Envelope{
+			Status: http.StatusBadRequest,
+			Code:   int(errors.ErrCodeInvalidArgument),
+			Msg:    localize(c, errors.ErrInvalidArgument.Message),
+			Data:   fiber.Map{"errors": fields},
+			Err:    err,
+		}
+	}
+
+	return Envelope{
+		Status: http.StatusInternalServerError,
+		Code:   http.StatusInternalServerError,
+		Msg:    localize(c, "internal server error"),
+		Err:    err,
+	}
+}
+
+// requestID 获取请求 ID，优先取 Locals / 响应头（由中间件生成），其次取请求头
+func requestID(c fiber.Ctx) string {
+	if id, ok := c.Locals(requestid.LocalsKey).(string); ok && id != "" {
+		return id
+	}
+	if id := c.GetRespHeader(RequestIDHeader); id != "" {
+		return id
+	}
+	return c.Get(RequestIDHeader)
+}
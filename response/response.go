@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/i18n"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -15,15 +16,18 @@ import (
  * 特性:
  *   - 标准 JSON 响应格式
  *   - 与 errors 包集成，自动识别 BizError
+ *   - BizError 消息按请求语言（ctx 语言或 Accept-Language）翻译
  *   - 支持分页响应
+ *   - 支持自定义信封编码器（SetEncoder）与不包装的 Raw 响应
  *   - 快捷响应函数
  * ======================================================================== */
 
 // newResp 创建响应对象
-func newResp(code int, msg string, data any) *Result {
+func newResp(code int, msg string, data any, requestID string) *Result {
 	resp := &Result{
-		Code: code,
-		Msg:  msg,
+		Code:      code,
+		Msg:       msg,
+		RequestID: requestID,
 	}
 
 	// 确保 data 字段不为 nil
@@ -52,13 +56,47 @@ func respJSONWithStatusCode(c fiber.Ctx, code int, msg string, data ...any) erro
 		firstData = data[0]
 	}
 
-	// 业务响应码保持原样
-	resp := newResp(code, msg, firstData)
+	// 业务响应码保持原样，HTTP 协议层的状态码由 write 规范化到 100-599
+	return write(c, Envelope{Status: code, Code: code, Msg: msg, Data: firstData})
+}
 
-	// 确保 HTTP 协议层的状态码在有效范围内 (100-599)
-	httpStatusCode := normalizeHTTPStatusCode(code)
+// localize 按请求语言翻译消息
+// 语言优先取 ctx 中通过 i18n.WithLocale 设置的值，其次取 Accept-Language；
+// 仅在命中已支持的语言时翻译，未提供、不支持（不回退到默认语言）或目录未命中时原样返回
+func localize(c fiber.Ctx, msg string) string {
+	locale, ok := i18n.LocaleFromContext(c.Context())
+	if ok {
+		locale, ok = i18n.Default.Lookup(locale)
+	} else {
+		locale, ok = i18n.Default.Match(c.Get(fiber.HeaderAcceptLanguage))
+	}
+	if !ok {
+		return msg
+	}
 
-	return c.Status(httpStatusCode).JSON(resp)
+	if translated, ok := i18n.Default.Translate(locale, msg, nil); ok {
+		return translated
+	}
+	return msg
+}
+
+// bizEnvelope 构造 BizError 的响应要素
+func bizEnvelope(c fiber.Ctx, status int, bizErr *errors.BizError) Envelope {
+	return Envelope{
+		Status: status,
+		Code:   int(bizErr.Code),
+		Msg:    localize(c, bizErr.Message),
+		Data:   errorData(bizErr),
+		Err:    bizErr,
+	}
+}
+
+// errorData 构造错误响应的 data 字段，带 Details 时输出 {"details": ...}
+func errorData(bizErr *errors.BizError) any {
+	if len(bizErr.Details) == 0 {
+		return &struct{}{}
+	}
+	return fiber.Map{"details": bizErr.Details}
 }
 
 /* ========================================================================
@@ -99,25 +137,22 @@ func Error(c fiber.Ctx, err error) error {
 	// 检查是否为 BizError
 	if bizErr, ok := errors.AsBizError(err); ok {
 		statusCode, _ := errors.ToHTTPResponse(bizErr)
-		return c.Status(statusCode).JSON(Result{
-			Code: int(bizErr.Code),
-			Msg:  bizErr.Message,
-			Data: &struct{}{},
-		})
+		return write(c, bizEnvelope(c, statusCode, bizErr))
 	}
 
 	// 普通错误，返回 500
-	return respJSONWithStatusCode(c, http.StatusInternalServerError, err.Error())
+	return write(c, Envelope{
+		Status: http.StatusInternalServerError,
+		Code:   http.StatusInternalServerError,
+		Msg:    err.Error(),
+		Err:    err,
+	})
 }
 
 // ErrorWithCode 返回错误响应（指定 HTTP 状态码）
 func ErrorWithCode(c fiber.Ctx, code int, err error) error {
 	if err == nil {
-		return c.Status(code).JSON(Result{
-			Code: code,
-			Msg:  "ok",
-			Data: &struct{}{},
-		})
+		return write(c, Envelope{Status: code, Code: code, Msg: "ok"})
 	}
 
 	// 检查是否为 BizError
@@ -127,14 +162,10 @@ func ErrorWithCode(c fiber.Ctx, code int, err error) error {
 		if code != http.StatusInternalServerError {
 			statusCode = code
 		}
-		return c.Status(statusCode).JSON(Result{
-			Code: int(bizErr.Code),
-			Msg:  bizErr.Message,
-			Data: &struct{}{},
-		})
+		return write(c, bizEnvelope(c, statusCode, bizErr))
 	}
 
-	return respJSONWithStatusCode(c, code, err.Error())
+	return write(c, Envelope{Status: code, Code: code, Msg: err.Error(), Err: err})
 }
 
 // ErrorWithMsg 返回错误响应（自定义消息）
@@ -2,11 +2,18 @@ package response
 
 import (
 	"encoding/json"
+	stderrors "errors"
+	"io"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	aiserrors "github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/validator"
 	"github.com/gofiber/fiber/v3"
 )
 
@@ -92,3 +99,337 @@ func TestErrorWithCode_BizError(t *testing.T) {
 		t.Fatalf("unexpected status: got=%d want=%d", resp.StatusCode, fiber.StatusTeapot)
 	}
 }
+
+func TestError_LocalizedByAcceptLanguage(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/err", func(c fiber.Ctx) error {
+		return Error(c, aiserrors.ErrNotFound)
+	})
+
+	cases := map[string]string{
+		"":               "resource not found",
+		"zh-CN,zh;q=0.9": "资源不存在",
+		"en-US":          "resource not found",
+		// 不支持的语言不回退到默认的中文目录
+		"fr":       "resource not found",
+		"fr;q=0.9": "resource not found",
+	}
+	for lang, want := range cases {
+		req := httptest.NewRequest("GET", "/err", nil)
+		if lang != "" {
+			req.Header.Set("Accept-Language", lang)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+
+		var got Result
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+		resp.Body.Close()
+		if got.Msg != want {
+			t.Fatalf("lang %q: got=%q want=%q", lang, got.Msg, want)
+		}
+	}
+}
+
+func TestFiberErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New(fiber.Config{ErrorHandler: NewFiberErrorHandler(nil)})
+	app.Use(Recover())
+	app.Get("/biz", func(c fiber.Ctx) error {
+		return aiserrors.ErrAlreadyExists.WithDetail("field", "email")
+	})
+	app.Get("/fiber", func(c fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusTeapot, "teapot")
+	})
+	app.Get("/validate", func(c fiber.Ctx) error {
+		verr := &validator.ValidationError{}
+		verr.Add("Name", "required")
+		return verr
+	})
+	app.Get("/panic", func(c fiber.Ctx) error {
+		panic("boom")
+	})
+	app.Get("/plain", func(c fiber.Ctx) error {
+		return stderrors.New("db password leaked")
+	})
+
+	cases := []struct {
+		path   string
+		status int
+		code   int
+		msg    string
+	}{
+		{"/biz", fiber.StatusConflict, int(aiserrors.ErrCodeAlreadyExists), "resource already exists"},
+		{"/fiber", fiber.StatusTeapot, fiber.StatusTeapot, "teapot"},
+		{"/validate", fiber.StatusBadRequest, int(aiserrors.ErrCodeInvalidArgument), "invalid argument"},
+		{"/panic", fiber.StatusInternalServerError, fiber.StatusInternalServerError, "internal server error"},
+		{"/plain", fiber.StatusInternalServerError, fiber.StatusInternalServerError, "internal server error"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Header.Set(RequestIDHeader, "req-1")
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", tc.path, err)
+		}
+
+		var got struct {
+			Result
+			Data map[string]any `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("%s: decode response: %v", tc.path, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.status || got.Code != tc.code || got.Msg != tc.msg {
+			t.Fatalf("%s: unexpected response: status=%d code=%d msg=%q", tc.path, resp.StatusCode, got.Code, got.Msg)
+		}
+		if got.RequestID != "req-1" {
+			t.Fatalf("%s: unexpected request_id: %q", tc.path, got.RequestID)
+		}
+		switch tc.path {
+		case "/biz":
+			if details, _ := got.Data["details"].(map[string]any); details["field"] != "email" {
+				t.Fatalf("unexpected details: %v", got.Data)
+			}
+		case "/validate":
+			if _, ok := got.Data["errors"]; !ok {
+				t.Fatalf("expected field errors: %v", got.Data)
+			}
+		}
+	}
+}
+
+func TestSetEncoder(t *testing.T) {
+	SetEncoder(func(c fiber.Ctx, env Envelope) any {
+		return fiber.Map{
+			"success":   env.Success(),
+			"errorCode": strconv.Itoa(env.Code),
+			"message":   env.Msg,
+			"data":      env.Data,
+		}
+	})
+	defer SetEncoder(nil)
+
+	app := fiber.New(fiber.Config{ErrorHandler: NewFiberErrorHandler(nil)})
+	app.Get("/ok", func(c fiber.Ctx) error {
+		return OkWithData(c, fiber.Map{"id": 1})
+	})
+	app.Get("/page", func(c fiber.Ctx) error {
+		return PageData(c, []int{1}, 1, 1, 10)
+	})
+	app.Get("/err", func(c fiber.Ctx) error {
+		return aiserrors.ErrNotFound
+	})
+
+	cases := []struct {
+		path    string
+		success bool
+		code    string
+	}{
+		{"/ok", true, "200"},
+		{"/page", true, "200"},
+		{"/err", false, strconv.Itoa(int(aiserrors.ErrCodeNotFound))},
+	}
+	for _, tc := range cases {
+		resp, err := app.Test(httptest.NewRequest("GET", tc.path, nil), fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", tc.path, err)
+		}
+		var got map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+			t.Fatalf("%s: decode response: %v", tc.path, err)
+		}
+		resp.Body.Close()
+
+		if got["success"] != tc.success || got["errorCode"] != tc.code {
+			t.Fatalf("%s: unexpected envelope: %v", tc.path, got)
+		}
+		if _, ok := got["code"]; ok {
+			t.Fatalf("%s: default envelope should not be used: %v", tc.path, got)
+		}
+	}
+}
+
+func TestRaw(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Post("/webhook", func(c fiber.Ctx) error {
+		return Raw(c, fiber.StatusAccepted, "success")
+	})
+	app.Get("/json", func(c fiber.Ctx) error {
+		return Raw(c, fiber.StatusOK, fiber.Map{"id": 1})
+	})
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/webhook", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusAccepted || string(body) != "success" {
+		t.Fatalf("unexpected raw response: %d %q", resp.StatusCode, body)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/json", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"id":1}` {
+		t.Fatalf("unexpected raw json: %q", body)
+	}
+}
+
+func TestStreamResponses(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "report.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	seq := func(yield func(fiber.Map, error) bool) {
+		for i := 1; i <= 2; i++ {
+			if !yield(fiber.Map{"id": i}, nil) {
+				return
+			}
+		}
+	}
+
+	app := fiber.New(fiber.Config{ErrorHandler: NewFiberErrorHandler(nil)})
+	app.Get("/stream", func(c fiber.Ctx) error {
+		return Stream(c, "text/plain", strings.NewReader("streamed"))
+	})
+	app.Get("/file", func(c fiber.Ctx) error {
+		return File(c, path, "report.txt")
+	})
+	app.Get("/missing", func(c fiber.Ctx) error {
+		return File(c, filepath.Join(dir, "missing.txt"), "")
+	})
+	app.Get("/csv", func(c fiber.Ctx) error {
+		return CSV(c, [][]string{{"id", "name"}, {"1", "a,b"}})
+	})
+	app.Get("/ndjson", func(c fiber.Ctx) error {
+		return NDJSON(c, seq)
+	})
+
+	cases := []struct {
+		path        string
+		accept      string
+		status      int
+		contentType string
+		body        string
+	}{
+		{"/stream", "", fiber.StatusOK, "text/plain", "streamed"},
+		{"/file", "", fiber.StatusOK, "", "hello"},
+		{"/csv", "", fiber.StatusOK, MIMETextCSV, "id,name\n1,\"a,b\"\n"},
+		{"/ndjson", "", fiber.StatusOK, MIMEApplicationNDJSON, "{\"id\":1}\n{\"id\":2}\n"},
+		{"/ndjson", fiber.MIMEApplicationJSON, fiber.StatusOK, fiber.MIMEApplicationJSON, `{"code":200,"msg":"ok","data":[{"id":1},{"id":2}]}`},
+		{"/missing", "", fiber.StatusNotFound, "", ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.accept != "" {
+			req.Header.Set(fiber.HeaderAccept, tc.accept)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", tc.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.status {
+			t.Fatalf("%s: unexpected status: %d", tc.path, resp.StatusCode)
+		}
+		if tc.contentType != "" && !strings.HasPrefix(resp.Header.Get(fiber.HeaderContentType), tc.contentType) {
+			t.Fatalf("%s: unexpected content type: %q", tc.path, resp.Header.Get(fiber.HeaderContentType))
+		}
+		if tc.body != "" && string(body) != tc.body {
+			t.Fatalf("%s: unexpected body: %q", tc.path, body)
+		}
+	}
+}
+
+func TestSSE(t *testing.T) {
+	t.Parallel()
+
+	app := fiber.New()
+	app.Get("/events", func(c fiber.Ctx) error {
+		events := make(chan Event, 2)
+		events <- Event{ID: "1", Event: "greeting", Data: "hello\nworld"}
+		events <- Event{ID: "2\nid: forged", Data: fiber.Map{"n": 2}, Retry: 3 * time.Second}
+		close(events)
+		return SSE(c, events)
+	})
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/events", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if ct := resp.Header.Get(fiber.HeaderContentType); ct != MIMETextEventStream {
+		t.Fatalf("unexpected content type: %q", ct)
+	}
+	want := ": connected\n\n" +
+		"id: 1\nevent: greeting\ndata: hello\ndata: world\n\n" +
+		"id: 2id: forged\nretry: 3000\ndata: {\"n\":2}\n\n"
+	if string(body) != want {
+		t.Fatalf("unexpected body:\n%q\nwant:\n%q", body, want)
+	}
+}
+
+func TestSSEBrokerReplay(t *testing.T) {
+	t.Parallel()
+
+	broker := NewBroker(BrokerConfig{History: 10})
+	broker.Publish(Event{Data: "a"})         // id 1
+	broker.PublishTo("u2", Event{Data: "b"}) // id 2，仅 u2
+	broker.PublishTo("u1", Event{Data: "c"}) // id 3，仅 u1
+
+	app := fiber.New()
+	app.Get("/events", func(c fiber.Ctx) error {
+		return broker.Serve(c, c.Query("user"))
+	})
+
+	go func() {
+		for broker.Clients() == 0 {
+			time.Sleep(5 * time.Millisecond)
+		}
+		broker.Publish(Event{Event: "live", Data: "d"}) // id 4
+		broker.Close()
+	}()
+
+	req := httptest.NewRequest("GET", "/events?user=u1", nil)
+	req.Header.Set(HeaderLastEventID, "1")
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	want := "id: 3\ndata: c\n\n: connected\n\nid: 4\nevent: live\ndata: d\n\n"
+	if string(body) != want {
+		t.Fatalf("unexpected body:\n%q\nwant:\n%q", body, want)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/events", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil || resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Fatalf("expected 503 after close, got %v %v", resp.StatusCode, err)
+	}
+}
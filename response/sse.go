@@ -0,0 +1,293 @@
+package response
+
+import (
+	"bufio"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Server-Sent Events - SSE 推送
+ * ========================================================================
+ * 职责: 以 text/event-stream 流式推送事件，定时发送注释行保活
+ * Broker:
+ *   - 每个客户端独立缓冲，缓冲满时断开该客户端（由浏览器自动重连）
+ *   - 保留最近 History 条事件，重连时按 Last-Event-ID 补发
+ *   - 事件可广播或仅推送给指定 key（如用户 ID）的客户端
+ * 使用示例:
+ *     events := make(chan response.Event)
+ *     go produce(events) // 发送完毕后 close(events)
+ *     return response.SSE(c, events)
+ *
+ *     broker := response.NewBroker(response.BrokerConfig{})
+ *     app.Get("/notifications", func(c fiber.Ctx) error { return broker.Serve(c, userID(c)) })
+ *     broker.PublishTo(userID, response.Event{Event: "notice", Data: notice})
+ * ======================================================================== */
+
+const (
+	// MIMETextEventStream SSE 内容类型
+	MIMETextEventStream = "text/event-stream"
+	// HeaderLastEventID 重连时浏览器携带的最后事件 ID
+	HeaderLastEventID = "Last-Event-ID"
+	// DefaultSSEKeepAlive 默认保活注释间隔
+	DefaultSSEKeepAlive = 15 * time.Second
+)
+
+// Event SSE 事件
+type Event struct {
+	ID    string        // 事件 ID（用于断线重连补发）
+	Event string        // 事件类型，为空时浏览器按 message 处理
+	Data  any           // string / []byte 原样输出，其他类型 JSON 编码
+	Retry time.Duration // 建议客户端重连间隔
+}
+
+// SSE 推送事件直到 events 关闭或客户端断开
+func SSE(c fiber.Ctx, events <-chan Event) error {
+	return streamSSE(c, events, nil, DefaultSSEKeepAlive, nil)
+}
+
+// streamSSE 写出回放事件与实时事件，返回后调用 done
+func streamSSE(c fiber.Ctx, events <-chan Event, replay []Event, keepAlive time.Duration, done func()) error {
+	c.Set(fiber.HeaderContentType, MIMETextEventStream)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	// 禁用 nginx 代理缓冲
+	c.Set("X-Accel-Buffering", "no")
+
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		if done != nil {
+			defer done()
+		}
+		for _, ev := range replay {
+			if writeEvent(w, ev) != nil {
+				return
+			}
+		}
+		// 先发送一个注释，让客户端尽快收到响应头
+		if _, err := w.WriteString(": connected\n\n"); err != nil || w.Flush() != nil {
+			return
+		}
+
+		ticker := time.NewTicker(keepAlive)
+		defer ticker.Stop()
+		for {
+			select {
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				if writeEvent(w, ev) != nil {
+					return
+				}
+			case <-ticker.C:
+				if _, err := w.WriteString(": keep-alive\n\n"); err != nil || w.Flush() != nil {
+					// 客户端已断开
+					return
+				}
+			}
+		}
+	})
+}
+
+// writeEvent 按 SSE 格式写出单个事件并刷新
+func writeEvent(w *bufio.Writer, ev Event) error {
+	if ev.ID != "" {
+		w.WriteString("id: " + singleLine(ev.ID) + "\n")
+	}
+	if ev.Event != "" {
+		w.WriteString("event: " + singleLine(ev.Event) + "\n")
+	}
+	if ev.Retry > 0 {
+		w.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	var data string
+	switch v := ev.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = string(raw)
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		w.WriteString("data: " + line + "\n")
+	}
+	if err := w.WriteByte('\n'); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// singleLine 去除字段中的换行，防止事件注入
+func singleLine(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// ========================================================================
+// Broker
+// ========================================================================
+
+// BrokerConfig Broker 配置
+type BrokerConfig struct {
+	Buffer    int           // 每客户端缓冲事件数，默认 64
+	History   int           // 保留用于重连补发的事件数，默认 100，<0 表示不保留
+	KeepAlive time.Duration // 保活注释间隔，默认 15s
+}
+
+func (c BrokerConfig) withDefaults() BrokerConfig {
+	if c.Buffer <= 0 {
+		c.Buffer = 64
+	}
+	if c.History == 0 {
+		c.History = 100
+	}
+	if c.KeepAlive <= 0 {
+		c.KeepAlive = DefaultSSEKeepAlive
+	}
+	return c
+}
+
+// brokerEvent 带投递目标的历史事件
+type brokerEvent struct {
+	key   string // 为空表示广播
+	event Event
+}
+
+// sseClient 已连接的客户端
+type sseClient struct {
+	key string
+	ch  chan Event
+}
+
+// Broker SSE 事件分发器
+type Broker struct {
+	cfg BrokerConfig
+
+	mu      sync.Mutex
+	seq     uint64
+	history []brokerEvent
+	clients map[*sseClient]struct{}
+	closed  bool
+}
+
+// NewBroker 创建事件分发器
+func NewBroker(cfg BrokerConfig) *Broker {
+	return &Broker{cfg: cfg.withDefaults(), clients: make(map[*sseClient]struct{})}
+}
+
+// Publish 广播事件，ID 为空时自动分配递增 ID
+func (b *Broker) Publish(ev Event) {
+	b.PublishTo("", ev)
+}
+
+// PublishTo 推送给指定 key 的客户端，key 为空表示广播
+func (b *Broker) PublishTo(key string, ev Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+
+	b.seq++
+	if ev.ID == "" {
+		ev.ID = strconv.FormatUint(b.seq, 10)
+	}
+	if b.cfg.History > 0 {
+		b.history = append(b.history, brokerEvent{key: key, event: ev})
+		if len(b.history) > b.cfg.History {
+			b.history = b.history[len(b.history)-b.cfg.History:]
+		}
+	}
+
+	for client := range b.clients {
+		if key != "" && client.key != key {
+			continue
+		}
+		select {
+		case client.ch <- ev:
+		default:
+			// 慢客户端：断开后由浏览器携带 Last-Event-ID 重连补发
+			b.removeLocked(client)
+		}
+	}
+}
+
+// Clients 当前连接的客户端数
+func (b *Broker) Clients() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.clients)
+}
+
+// Serve 以 key 身份订阅并推送事件（key 为空时仅接收广播）
+// 请求头 Last-Event-ID（或查询参数 lastEventId）存在时先补发其后的历史事件
+func (b *Broker) Serve(c fiber.Ctx, key string) error {
+	lastID := c.Get(HeaderLastEventID)
+	if lastID == "" {
+		lastID = c.Query("lastEventId")
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return fiber.ErrServiceUnavailable
+	}
+	client := &sseClient{key: key, ch: make(chan Event, b.cfg.Buffer)}
+	replay := b.replayLocked(key, lastID)
+	b.clients[client] = struct{}{}
+	b.mu.Unlock()
+
+	return streamSSE(c, client.ch, replay, b.cfg.KeepAlive, func() {
+		b.mu.Lock()
+		b.removeLocked(client)
+		b.mu.Unlock()
+	})
+}
+
+// Close 关闭全部客户端连接，之后的发布被忽略
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for client := range b.clients {
+		b.removeLocked(client)
+	}
+}
+
+// replayLocked 返回 lastID 之后投递给 key 的历史事件；lastID 不在历史中时不补发
+func (b *Broker) replayLocked(key, lastID string) []Event {
+	if lastID == "" {
+		return nil
+	}
+	for i := len(b.history) - 1; i >= 0; i-- {
+		if b.history[i].event.ID != lastID {
+			continue
+		}
+		var replay []Event
+		for _, item := range b.history[i+1:] {
+			if item.key == "" || item.key == key {
+				replay = append(replay, item.event)
+			}
+		}
+		return replay
+	}
+	return nil
+}
+
+func (b *Broker) removeLocked(client *sseClient) {
+	if _, ok := b.clients[client]; ok {
+		delete(b.clients, client)
+		close(client.ch)
+	}
+}
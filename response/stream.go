@@ -0,0 +1,117 @@
+package response
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Stream Response - 流式与文件响应
+ * ========================================================================
+ * 职责: 提供流式数据、文件下载、CSV 与 NDJSON 导出响应
+ * 内容协商:
+ *   - NDJSON: 客户端 Accept 优先 application/json 时回退为信封包装的 JSON 数组
+ * 使用示例:
+ *     return response.File(c, "/data/report.xlsx", "月报.xlsx")
+ *     return response.CSV(c, [][]string{{"id", "name"}, {"1", "alice"}})
+ *     return response.NDJSON(c, func(yield func(User, error) bool) { ... })
+ * ======================================================================== */
+
+const (
+	// MIMEApplicationNDJSON NDJSON 内容类型
+	MIMEApplicationNDJSON = "application/x-ndjson"
+	// MIMETextCSV CSV 内容类型
+	MIMETextCSV = "text/csv; charset=utf-8"
+)
+
+// Stream 以流方式输出 reader 内容，reader 实现 io.Closer 时发送完成后自动关闭
+func Stream(c fiber.Ctx, contentType string, reader io.Reader) error {
+	if reader == nil {
+		return errors.New(errors.ErrCodeInvalidArgument, "stream reader is nil")
+	}
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.SendStream(reader)
+}
+
+// File 输出文件，downloadName 非空时以附件形式下载
+func File(c fiber.Ctx, path, downloadName string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.Wrap(errors.ErrCodeNotFound, "file not found", err)
+		}
+		return errors.Wrap(errors.ErrCodeInternal, "failed to stat file", err)
+	}
+	if info.IsDir() {
+		return errors.New(errors.ErrCodeInvalidArgument, "path is a directory")
+	}
+
+	if downloadName == "" {
+		return c.SendFile(path)
+	}
+	return c.Download(path, filepath.Base(downloadName))
+}
+
+// CSV 输出 CSV 数据（首行通常为表头），需下载时可先调用 c.Attachment(name)
+func CSV(c fiber.Ctx, rows [][]string) error {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(rows); err != nil {
+		return errors.Wrap(errors.ErrCodeInternal, "failed to encode csv", err)
+	}
+
+	c.Set(fiber.HeaderContentType, MIMETextCSV)
+	return c.Send(buf.Bytes())
+}
+
+// NDJSON 以换行分隔 JSON 流式输出迭代器中的元素
+// 迭代器返回错误时终止输出（响应头已发送，错误仅能通过截断体现）
+func NDJSON[T any](c fiber.Ctx, seq iter.Seq2[T, error]) error {
+	if c.Accepts(MIMEApplicationNDJSON, fiber.MIMEApplicationJSON) == fiber.MIMEApplicationJSON {
+		return ndjsonAsArray(c, seq)
+	}
+
+	c.Set(fiber.HeaderContentType, MIMEApplicationNDJSON)
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	return c.SendStreamWriter(func(w *bufio.Writer) {
+		enc := json.NewEncoder(w)
+		for item, err := range seq {
+			if err != nil {
+				return
+			}
+			// Encode 自动追加换行
+			if enc.Encode(item) != nil {
+				return
+			}
+			if w.Flush() != nil {
+				// 客户端已断开
+				return
+			}
+		}
+	})
+}
+
+// ndjsonAsArray 将迭代器收集为数组，以标准信封返回
+func ndjsonAsArray[T any](c fiber.Ctx, seq iter.Seq2[T, error]) error {
+	items := make([]T, 0)
+	for item, err := range seq {
+		if err != nil {
+			return Error(c, err)
+		}
+		items = append(items, item)
+	}
+	return OkWithData(c, items)
+}
@@ -8,17 +8,20 @@ package response
 
 // Result 标准 API 响应结构
 type Result struct {
-	Code int    `json:"code" example:"200" doc:"响应状态码"`
-	Msg  string `json:"msg" example:"success" doc:"响应消息"`
-	Data any    `json:"data" doc:"响应数据"`
+	Code int    `json:"code" msgpack:"code" example:"200" doc:"响应状态码"`
+	Msg  string `json:"msg" msgpack:"msg" example:"success" doc:"响应消息"`
+	Data any    `json:"data" msgpack:"data" doc:"响应数据"`
+
+	// RequestID 请求 ID（由 RequestID 中间件生成，响应时自动填充）
+	RequestID string `json:"request_id,omitempty" msgpack:"request_id,omitempty" doc:"请求 ID"`
 }
 
 // PageResult 分页响应结构
 type PageResult struct {
-	List     any   `json:"list" doc:"数据列表"`
-	Total    int64 `json:"total" example:"100" doc:"总记录数"`
-	Page     int   `json:"page" example:"1" doc:"当前页码"`
-	PageSize int   `json:"page_size" example:"10" doc:"每页大小"`
+	List     any   `json:"list" msgpack:"list" doc:"数据列表"`
+	Total    int64 `json:"total" msgpack:"total" example:"100" doc:"总记录数"`
+	Page     int   `json:"page" msgpack:"page" example:"1" doc:"当前页码"`
+	PageSize int   `json:"page_size" msgpack:"page_size" example:"10" doc:"每页大小"`
 }
 
 // Response Result 的别名，用于 Swagger 文档
@@ -0,0 +1,61 @@
+package saga
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+/* ========================================================================
+ * Saga Instance - Saga 实例持久化模型
+ * ========================================================================
+ * 职责: 记录 Saga 当前步骤、状态、业务数据（JSON）与异步步骤截止时间
+ * 并发: Version 乐观锁，回复处理与超时检查并发时仅一方生效
+ * ======================================================================== */
+
+// Status Saga 状态
+type Status string
+
+const (
+	StatusRunning      Status = "running"      // 正在执行步骤
+	StatusAwaiting     Status = "awaiting"     // 异步步骤已发出命令，等待回复
+	StatusCompensating Status = "compensating" // 正在逆序补偿
+	StatusCompleted    Status = "completed"    // 全部步骤成功
+	StatusCompensated  Status = "compensated"  // 失败且补偿完成
+	StatusFailed       Status = "failed"       // 补偿失败，需人工介入
+)
+
+// Terminal 是否为终态
+func (s Status) Terminal() bool {
+	return s == StatusCompleted || s == StatusCompensated || s == StatusFailed
+}
+
+// Instance Saga 实例
+type Instance struct {
+	ID         string     `gorm:"column:id;type:char(26);primaryKey" json:"id"`
+	Name       string     `gorm:"column:name;size:64;index" json:"name"`
+	Status     Status     `gorm:"column:status;size:16;index" json:"status"`
+	Step       int        `gorm:"column:step" json:"step"`             // 当前步骤下标（补偿时为待补偿步骤下标 +1）
+	Attempt    int        `gorm:"column:attempt" json:"attempt"`       // 当前异步步骤已超时重发次数
+	Data       string     `gorm:"column:data;type:text" json:"data"`   // 业务数据 JSON
+	Error      string     `gorm:"column:error;type:text" json:"error"` // 失败原因
+	DeadlineAt *time.Time `gorm:"column:deadline_at;index" json:"deadline_at"`
+	Version    int64      `gorm:"column:version" json:"version"`
+	CreatedAt  time.Time  `gorm:"column:created_at" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"column:updated_at" json:"updated_at"`
+}
+
+// TableName 表名
+func (Instance) TableName() string {
+	return "saga_instances"
+}
+
+// TenantIgnored Saga 实例由编排器跨租户驱动，不做租户隔离
+func (Instance) TenantIgnored() bool {
+	return true
+}
+
+// Migrate 创建/更新 saga_instances 表
+func Migrate(db *gorm.DB) error {
+	return db.AutoMigrate(&Instance{})
+}
@@ -0,0 +1,121 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/mq"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Saga MQ - 命令与回复消息
+ * ========================================================================
+ * 约定: 命令与回复通过消息属性携带 saga_id / saga_step，回复失败时带 saga_error
+ * 流程:
+ *   编排方 Action:   msg, _ := saga.NewCommand(ctx, "payment.command", req); producer.SendSync(ctx, msg)
+ *   参与方处理命令:  reply, _ := saga.NewReply(cmd, "order.fulfill.reply", result, err); producer.SendSync(ctx, reply)
+ *   编排方订阅回复:  fulfill.SubscribeReplies(consumer, "order.fulfill.reply")
+ * ======================================================================== */
+
+// 消息属性
+const (
+	PropSagaID    = "saga_id"
+	PropSagaStep  = "saga_step"
+	PropSagaError = "saga_error"
+)
+
+// StepInfo 当前执行的步骤信息（注入到 Action / Compensate 的 ctx）
+type StepInfo struct {
+	Saga    string
+	SagaID  string
+	Step    string
+	Attempt int
+}
+
+type stepInfoKey struct{}
+
+func withStepInfo(ctx context.Context, info StepInfo) context.Context {
+	return context.WithValue(ctx, stepInfoKey{}, info)
+}
+
+// StepFromContext 获取当前步骤信息
+func StepFromContext(ctx context.Context) (StepInfo, bool) {
+	info, ok := ctx.Value(stepInfoKey{}).(StepInfo)
+	return info, ok
+}
+
+// NewCommand 在步骤 Action 中创建命令消息，自动携带 saga_id / saga_step
+func NewCommand(ctx context.Context, topic string, payload any) (*mq.Message, error) {
+	info, ok := StepFromContext(ctx)
+	if !ok {
+		return nil, ErrNoSagaContext
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("saga: marshal command: %w", err)
+	}
+	return mq.NewMessage(topic, body).
+		WithKey(info.SagaID).
+		WithProperty(PropSagaID, info.SagaID).
+		WithProperty(PropSagaStep, info.Step), nil
+}
+
+// NewReply 参与方根据命令创建回复消息；stepErr 非 nil 表示步骤失败
+func NewReply(cmd *mq.ConsumedMessage, topic string, result any, stepErr error) (*mq.Message, error) {
+	if cmd == nil || cmd.Properties[PropSagaID] == "" {
+		return nil, ErrNoSagaContext
+	}
+	var body []byte
+	if result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("saga: marshal reply: %w", err)
+		}
+		body = data
+	}
+	msg := mq.NewMessage(topic, body).
+		WithKey(cmd.Properties[PropSagaID]).
+		WithProperty(PropSagaID, cmd.Properties[PropSagaID]).
+		WithProperty(PropSagaStep, cmd.Properties[PropSagaStep])
+	if stepErr != nil {
+		msg.WithProperty(PropSagaError, stepErr.Error())
+	}
+	return msg, nil
+}
+
+// MQHandler 回复消息处理器，处理失败（如乐观锁冲突）时由 MQ 重投
+func (s *Saga[D]) MQHandler() mq.MessageHandler {
+	return func(ctx context.Context, msgs []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+		for _, msg := range msgs {
+			id := msg.Properties[PropSagaID]
+			if id == "" {
+				s.log.Warn("saga: reply without saga id", zap.String("topic", msg.Topic), zap.String("msg_id", msg.MsgID))
+				continue
+			}
+			err := s.HandleReply(ctx, Reply{
+				SagaID: id,
+				Step:   msg.Properties[PropSagaStep],
+				Err:    msg.Properties[PropSagaError],
+				Data:   msg.Body,
+			})
+			if errors.IsNotFound(err) {
+				// 实例不存在（已清理或非本服务发起），不重投
+				s.log.Warn("saga: reply for unknown instance", zap.String("id", id))
+				continue
+			}
+			if err != nil {
+				return mq.ConsumeRetryLater, err
+			}
+		}
+		return mq.ConsumeSuccess, nil
+	}
+}
+
+// SubscribeReplies 订阅回复 Topic（须在 Consumer.Start 之前调用）
+func (s *Saga[D]) SubscribeReplies(consumer mq.Consumer, topic string) error {
+	return consumer.Subscribe(topic, s.MQHandler())
+}
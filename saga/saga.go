@@ -0,0 +1,398 @@
+package saga
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Saga - 跨服务流程编排（Process Manager）
+ * ========================================================================
+ * 职责: 按顺序执行步骤，任一步骤失败时逆序执行已完成步骤的补偿
+ * 特性:
+ *   - 状态经仓储持久化（saga_instances），进程重启后可 Resume
+ *   - 同步步骤: Action 直接调用，按 Timeout 限时，失败按 Retries 重试
+ *   - 异步步骤: 先持久化 awaiting 与回复截止时间，再由 Action 发送 MQ 命令，由回复消息推进；
+ *     发送失败时回滚等待状态并补偿；Action 对业务数据的修改不会持久化（请通过回复 Data 合并）
+ *     超过 Timeout 未回复时重发（最多 Retries 次），仍失败则补偿（含当前步骤）
+ *   - 补偿同样按 Retries 重试，补偿失败进入 failed 状态等待人工处理
+ * 说明: Action / Compensate 需幂等（重试、重投与超时补偿都可能重复调用）
+ *
+ * 使用示例:
+ *   fulfill, _ := saga.New[OrderData]("order.fulfill", repo, log,
+ *       saga.Step[OrderData]{Name: "reserve", Action: reserveStock, Compensate: releaseStock, Retries: 2},
+ *       saga.Step[OrderData]{Name: "pay", Action: sendPayCommand, Compensate: refund,
+ *           Async: true, Timeout: time.Minute, Retries: 1},
+ *       saga.Step[OrderData]{Name: "ship", Action: createShipment},
+ *   )
+ *   _ = fulfill.SubscribeReplies(consumer, "order.fulfill.reply")
+ *   inst, err := fulfill.Start(ctx, OrderData{OrderID: id})
+ * ======================================================================== */
+
+var (
+	// ErrConcurrentUpdate 实例已被并发修改（乐观锁冲突）
+	ErrConcurrentUpdate = errors.New("saga instance was modified concurrently")
+	// ErrNoSagaContext ctx 中没有 Saga 步骤信息
+	ErrNoSagaContext = errors.New("no saga step in context")
+)
+
+// Step 步骤定义
+type Step[D any] struct {
+	Name string
+	// Action 执行步骤；异步步骤中仅负责发送命令（见 NewCommand）
+	Action func(ctx context.Context, data *D) error
+	// Compensate 补偿步骤，可为 nil（无需补偿）
+	Compensate func(ctx context.Context, data *D) error
+	// Async 是否等待 MQ 回复后才算完成
+	Async bool
+	// Timeout 同步步骤单次执行超时；异步步骤等待回复超时（0 表示不限）
+	Timeout time.Duration
+	// Retries 失败重试次数（不含首次）；异步步骤同时用作超时重发次数
+	Retries int
+	// Backoff 同步重试间隔，默认 100ms，按次数线性递增
+	Backoff time.Duration
+}
+
+// defaultBackoff 默认重试间隔
+const defaultBackoff = 100 * time.Millisecond
+
+// Saga 一种 Saga 流程的编排器
+type Saga[D any] struct {
+	name  string
+	steps []Step[D]
+	index map[string]int
+	repo  repository.Repository[Instance]
+	log   *logger.Logger
+}
+
+// New 创建 Saga 编排器
+func New[D any](name string, repo repository.Repository[Instance], log *logger.Logger, steps ...Step[D]) (*Saga[D], error) {
+	if name == "" {
+		return nil, fmt.Errorf("saga: name is required")
+	}
+	if repo == nil {
+		return nil, fmt.Errorf("saga: repository is required")
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("saga: %s has no steps", name)
+	}
+	index := make(map[string]int, len(steps))
+	for i, step := range steps {
+		if step.Name == "" || step.Action == nil {
+			return nil, fmt.Errorf("saga: step %d of %s requires name and action", i, name)
+		}
+		if _, dup := index[step.Name]; dup {
+			return nil, fmt.Errorf("saga: duplicate step %q in %s", step.Name, name)
+		}
+		index[step.Name] = i
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &Saga[D]{name: name, steps: steps, index: index, repo: repo, log: log}, nil
+}
+
+// Name Saga 名称
+func (s *Saga[D]) Name() string {
+	return s.name
+}
+
+// Start 创建实例并执行，直到完成、补偿结束或等待异步回复
+// 步骤失败不作为错误返回，通过 Instance.Status / Instance.Error 判断
+func (s *Saga[D]) Start(ctx context.Context, data D) (*Instance, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("saga: marshal data: %w", err)
+	}
+	inst := &Instance{
+		ID:     ulid.GenerateString(),
+		Name:   s.name,
+		Status: StatusRunning,
+		Data:   string(raw),
+	}
+	if err := s.repo.Create(ctx, inst); err != nil {
+		return nil, err
+	}
+	return inst, s.advance(ctx, inst, &data)
+}
+
+// Load 加载实例与业务数据
+func (s *Saga[D]) Load(ctx context.Context, id string) (*Instance, *D, error) {
+	inst, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if inst.Name != s.name {
+		return nil, nil, fmt.Errorf("saga: instance %s belongs to %s, not %s", id, inst.Name, s.name)
+	}
+	data := new(D)
+	if inst.Data != "" {
+		if err := json.Unmarshal([]byte(inst.Data), data); err != nil {
+			return nil, nil, fmt.Errorf("saga: unmarshal data: %w", err)
+		}
+	}
+	return inst, data, nil
+}
+
+// Resume 继续执行中断的实例（running / compensating），用于进程重启后恢复
+func (s *Saga[D]) Resume(ctx context.Context, id string) (*Instance, error) {
+	inst, data, err := s.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return inst, s.advance(ctx, inst, data)
+}
+
+// Reply 异步步骤回复
+type Reply struct {
+	SagaID string
+	Step   string
+	Err    string // 非空表示步骤失败
+	Data   []byte // 可选 JSON，合并到业务数据
+}
+
+// HandleReply 处理异步步骤回复；重复或过期的回复被忽略
+func (s *Saga[D]) HandleReply(ctx context.Context, reply Reply) error {
+	inst, data, err := s.Load(ctx, reply.SagaID)
+	if err != nil {
+		return err
+	}
+	idx, ok := s.index[reply.Step]
+	if !ok || inst.Status != StatusAwaiting || inst.Step != idx {
+		s.log.Debug("saga: ignore stale reply",
+			zap.String("saga", s.name), zap.String("id", inst.ID), zap.String("step", reply.Step))
+		return nil
+	}
+
+	if len(reply.Data) > 0 {
+		if err := json.Unmarshal(reply.Data, data); err != nil {
+			return fmt.Errorf("saga: merge reply data: %w", err)
+		}
+	}
+	if reply.Err != "" {
+		s.fail(inst, errors.New(reply.Err), false)
+	} else {
+		inst.Status = StatusRunning
+		inst.Step++
+		inst.Attempt = 0
+		inst.DeadlineAt = nil
+	}
+	if err := s.save(ctx, inst, data); err != nil {
+		return err
+	}
+	return s.advance(ctx, inst, data)
+}
+
+// CheckTimeouts 处理等待回复超时的实例：未超过 Retries 时重发命令，否则补偿
+// 返回处理的实例数，可由定时任务周期调用（见 RunTimeouts）
+func (s *Saga[D]) CheckTimeouts(ctx context.Context, now time.Time) (int, error) {
+	list, err := s.repo.FindByQuery(ctx, "name = ? AND status = ? AND deadline_at <= ?", s.name, StatusAwaiting, now)
+	if err != nil {
+		return 0, err
+	}
+
+	handled := 0
+	var errs []error
+	for _, inst := range list {
+		data := new(D)
+		if err := json.Unmarshal([]byte(inst.Data), data); err != nil {
+			errs = append(errs, fmt.Errorf("saga: unmarshal data of %s: %w", inst.ID, err))
+			continue
+		}
+
+		step := s.steps[inst.Step]
+		if inst.Attempt < step.Retries {
+			inst.Attempt++
+			inst.Status = StatusRunning
+			s.log.Warn("saga: step timed out, resending",
+				zap.String("saga", s.name), zap.String("id", inst.ID),
+				zap.String("step", step.Name), zap.Int("attempt", inst.Attempt))
+		} else {
+			s.fail(inst, fmt.Errorf("step %s timed out", step.Name), true)
+		}
+		inst.DeadlineAt = nil
+
+		if err := s.save(ctx, inst, data); err != nil {
+			if !errors.Is(err, ErrConcurrentUpdate) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		handled++
+		if err := s.advance(ctx, inst, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return handled, errors.Join(errs...)
+}
+
+// RunTimeouts 按间隔周期检查超时，阻塞直到 ctx 取消
+func (s *Saga[D]) RunTimeouts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, err := s.CheckTimeouts(ctx, now); err != nil {
+				s.log.Error("saga: check timeouts failed", zap.String("saga", s.name), zap.Error(err))
+			}
+		}
+	}
+}
+
+// advance 推进实例直到终态或等待异步回复
+func (s *Saga[D]) advance(ctx context.Context, inst *Instance, data *D) error {
+	for {
+		switch inst.Status {
+		case StatusRunning:
+			if inst.Step >= len(s.steps) {
+				inst.Status = StatusCompleted
+				return s.save(ctx, inst, data)
+			}
+			step := s.steps[inst.Step]
+			if step.Async {
+				// 先落库等待状态再发送命令，避免回复早于状态保存被当作过期丢弃
+				inst.Status = StatusAwaiting
+				if step.Timeout > 0 {
+					deadline := time.Now().Add(step.Timeout)
+					inst.DeadlineAt = &deadline
+				}
+				if err := s.save(ctx, inst, data); err != nil {
+					return err
+				}
+				err := s.run(ctx, inst, step, step.Action, data, false)
+				if err == nil {
+					// 回复可能已在 Action 内同步处理并推进实例，此处不再保存
+					return nil
+				}
+				s.fail(inst, fmt.Errorf("step %s: %w", step.Name, err), false)
+			} else if err := s.run(ctx, inst, step, step.Action, data, true); err != nil {
+				s.fail(inst, fmt.Errorf("step %s: %w", step.Name, err), false)
+			} else {
+				inst.Step++
+				inst.Attempt = 0
+			}
+
+		case StatusCompensating:
+			if inst.Step <= 0 {
+				inst.Status = StatusCompensated
+				return s.save(ctx, inst, data)
+			}
+			step := s.steps[inst.Step-1]
+			if step.Compensate != nil {
+				if err := s.run(ctx, inst, step, step.Compensate, data, true); err != nil {
+					inst.Status = StatusFailed
+					inst.Error = errors.Join(errors.New(inst.Error), fmt.Errorf("compensate %s: %w", step.Name, err)).Error()
+					s.log.Error("saga: compensation failed",
+						zap.String("saga", s.name), zap.String("id", inst.ID),
+						zap.String("step", step.Name), zap.Error(err))
+					return s.save(ctx, inst, data)
+				}
+			}
+			inst.Step--
+
+		default:
+			return nil
+		}
+
+		if err := s.save(ctx, inst, data); err != nil {
+			return err
+		}
+	}
+}
+
+// run 执行 Action / Compensate，失败按 Retries 重试
+func (s *Saga[D]) run(ctx context.Context, inst *Instance, step Step[D], fn func(context.Context, *D) error, data *D, withTimeout bool) error {
+	backoff := step.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+
+	var err error
+	for attempt := 0; attempt <= step.Retries; attempt++ {
+		if attempt > 0 {
+			timer := time.NewTimer(time.Duration(attempt) * backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return errors.Join(err, ctx.Err())
+			case <-timer.C:
+			}
+		}
+		if err = s.call(ctx, inst, step, fn, data, withTimeout); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// call 单次调用，注入步骤信息并转换 panic
+func (s *Saga[D]) call(ctx context.Context, inst *Instance, step Step[D], fn func(context.Context, *D) error, data *D, withTimeout bool) (err error) {
+	ctx = withStepInfo(ctx, StepInfo{Saga: s.name, SagaID: inst.ID, Step: step.Name, Attempt: inst.Attempt})
+	if withTimeout && step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx, data)
+}
+
+// fail 标记失败并转入补偿；includeCurrent 表示当前步骤可能已生效（超时），一并补偿
+func (s *Saga[D]) fail(inst *Instance, err error, includeCurrent bool) {
+	s.log.Warn("saga: step failed, compensating",
+		zap.String("saga", s.name), zap.String("id", inst.ID), zap.Int("step", inst.Step), zap.Error(err))
+	inst.Status = StatusCompensating
+	inst.Error = err.Error()
+	inst.DeadlineAt = nil
+	if includeCurrent {
+		inst.Step++
+	}
+}
+
+// save 以版本号乐观锁保存实例
+func (s *Saga[D]) save(ctx context.Context, inst *Instance, data *D) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("saga: marshal data: %w", err)
+	}
+	now := time.Now()
+	result := repository.DBFromContext(ctx, s.repo.GetDB()).
+		Model(&Instance{}).
+		Where("id = ? AND version = ?", inst.ID, inst.Version).
+		Updates(map[string]any{
+			"status":      inst.Status,
+			"step":        inst.Step,
+			"attempt":     inst.Attempt,
+			"data":        string(raw),
+			"error":       inst.Error,
+			"deadline_at": inst.DeadlineAt,
+			"version":     inst.Version + 1,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("saga: save instance: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrConcurrentUpdate
+	}
+	inst.Version++
+	inst.Data = string(raw)
+	inst.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,262 @@
+package saga
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type orderData struct {
+	OrderID   string   `json:"order_id"`
+	PaymentID string   `json:"payment_id,omitempty"`
+	Trace     []string `json:"trace,omitempty"`
+}
+
+func newSagaRepo(t *testing.T) repository.Repository[Instance] {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	if err := Migrate(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return repository.NewRepository[Instance](db)
+}
+
+func traceStep(name string, fail error) Step[orderData] {
+	return Step[orderData]{
+		Name: name,
+		Action: func(ctx context.Context, d *orderData) error {
+			d.Trace = append(d.Trace, name)
+			return fail
+		},
+		Compensate: func(ctx context.Context, d *orderData) error {
+			d.Trace = append(d.Trace, "undo-"+name)
+			return nil
+		},
+		Backoff: time.Millisecond,
+	}
+}
+
+func TestSagaCompletesAndCompensates(t *testing.T) {
+	repo := newSagaRepo(t)
+	ctx := context.Background()
+
+	ok, err := New("order.ok", repo, nil, traceStep("reserve", nil), traceStep("ship", nil))
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	inst, err := ok.Start(ctx, orderData{OrderID: "o1"})
+	if err != nil || inst.Status != StatusCompleted {
+		t.Fatalf("expected completed, got %+v %v", inst, err)
+	}
+
+	failing := traceStep("ship", errors.New("no courier"))
+	failing.Retries = 2
+	bad, _ := New("order.bad", repo, nil, traceStep("reserve", nil), traceStep("pay", nil), failing)
+	inst, err = bad.Start(ctx, orderData{OrderID: "o2"})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if inst.Status != StatusCompensated || !strings.Contains(inst.Error, "no courier") {
+		t.Fatalf("expected compensated, got %+v", inst)
+	}
+
+	_, data, err := bad.Load(ctx, inst.ID)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	want := "reserve,pay,ship,ship,ship,undo-pay,undo-reserve"
+	if got := strings.Join(data.Trace, ","); got != want {
+		t.Fatalf("unexpected trace %s, want %s", got, want)
+	}
+
+	if _, err := New[orderData]("dup", repo, nil, traceStep("a", nil), traceStep("a", nil)); err == nil {
+		t.Fatalf("expected duplicate step error")
+	}
+}
+
+func TestSagaCompensationFailure(t *testing.T) {
+	repo := newSagaRepo(t)
+	reserve := traceStep("reserve", nil)
+	reserve.Compensate = func(ctx context.Context, d *orderData) error { return errors.New("stock service down") }
+
+	s, _ := New("order", repo, nil, reserve, traceStep("pay", errors.New("declined")))
+	inst, err := s.Start(context.Background(), orderData{})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if inst.Status != StatusFailed || !strings.Contains(inst.Error, "stock service down") || !strings.Contains(inst.Error, "declined") {
+		t.Fatalf("expected failed with both errors, got %+v", inst)
+	}
+}
+
+// fakeProducer 记录发送的命令
+type fakeProducer struct {
+	sent []*mq.Message
+}
+
+func (p *fakeProducer) SendSync(ctx context.Context, msg *mq.Message) (*mq.SendResult, error) {
+	p.sent = append(p.sent, msg)
+	return &mq.SendResult{Topic: msg.Topic}, nil
+}
+
+func (p *fakeProducer) SendAsync(ctx context.Context, msg *mq.Message, cb mq.SendCallback) error {
+	cb(p.SendSync(ctx, msg))
+	return nil
+}
+
+func (p *fakeProducer) Close() error { return nil }
+
+func asyncPayStep(p *fakeProducer) Step[orderData] {
+	step := traceStep("pay", nil)
+	step.Async = true
+	step.Timeout = time.Minute
+	step.Retries = 1
+	step.Action = func(ctx context.Context, d *orderData) error {
+		msg, err := NewCommand(ctx, "payment.command", map[string]string{"order_id": d.OrderID})
+		if err != nil {
+			return err
+		}
+		_, err = p.SendSync(ctx, msg)
+		return err
+	}
+	return step
+}
+
+func consumed(msg *mq.Message) []*mq.ConsumedMessage {
+	return []*mq.ConsumedMessage{{Topic: msg.Topic, Body: msg.Body, Properties: msg.Properties}}
+}
+
+func TestSagaAsyncReply(t *testing.T) {
+	repo := newSagaRepo(t)
+	ctx := context.Background()
+	producer := &fakeProducer{}
+
+	s, _ := New("order.fulfill", repo, nil, traceStep("reserve", nil), asyncPayStep(producer), traceStep("ship", nil))
+	inst, err := s.Start(ctx, orderData{OrderID: "o1"})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if inst.Status != StatusAwaiting || inst.DeadlineAt == nil || len(producer.sent) != 1 {
+		t.Fatalf("expected awaiting pay reply, got %+v sent=%d", inst, len(producer.sent))
+	}
+	cmd := producer.sent[0]
+	if cmd.Properties[PropSagaID] != inst.ID || cmd.Properties[PropSagaStep] != "pay" {
+		t.Fatalf("command missing saga properties: %v", cmd.Properties)
+	}
+
+	// 参与方回复成功并携带 payment_id
+	reply, err := NewReply(consumed(cmd)[0], "order.fulfill.reply", map[string]string{"payment_id": "p1"}, nil)
+	if err != nil {
+		t.Fatalf("new reply: %v", err)
+	}
+	handler := s.MQHandler()
+	for range 2 { // 重复回复被忽略
+		if res, err := handler(ctx, consumed(reply)); err != nil || res != mq.ConsumeSuccess {
+			t.Fatalf("handle reply: %v %v", res, err)
+		}
+	}
+
+	inst, data, _ := s.Load(ctx, inst.ID)
+	if inst.Status != StatusCompleted || data.PaymentID != "p1" || strings.Join(data.Trace, ",") != "reserve,ship" {
+		t.Fatalf("unexpected final state %+v %+v", inst, data)
+	}
+
+	// 失败回复触发补偿
+	inst, _ = s.Start(ctx, orderData{OrderID: "o2"})
+	reply, _ = NewReply(consumed(producer.sent[1])[0], "order.fulfill.reply", nil, errors.New("insufficient balance"))
+	if _, err := handler(ctx, consumed(reply)); err != nil {
+		t.Fatalf("handle failure reply: %v", err)
+	}
+	inst, data, _ = s.Load(ctx, inst.ID)
+	if inst.Status != StatusCompensated || strings.Join(data.Trace, ",") != "reserve,undo-reserve" {
+		t.Fatalf("unexpected compensation %+v %+v", inst, data)
+	}
+}
+
+func TestSagaAsyncReplyDuringAction(t *testing.T) {
+	repo := newSagaRepo(t)
+	ctx := context.Background()
+
+	// 参与方在 Action 返回前回复（如同步投递的进程内 MQ）
+	var s *Saga[orderData]
+	pay := traceStep("pay", nil)
+	pay.Async = true
+	pay.Timeout = time.Minute
+	pay.Action = func(ctx context.Context, d *orderData) error {
+		info, _ := StepFromContext(ctx)
+		return s.HandleReply(ctx, Reply{SagaID: info.SagaID, Step: info.Step, Data: []byte(`{"payment_id":"p1"}`)})
+	}
+	s, _ = New("order.fast", repo, nil, traceStep("reserve", nil), pay, traceStep("ship", nil))
+
+	inst, err := s.Start(ctx, orderData{OrderID: "o1"})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	inst, data, _ := s.Load(ctx, inst.ID)
+	if inst.Status != StatusCompleted || inst.DeadlineAt != nil || data.PaymentID != "p1" || strings.Join(data.Trace, ",") != "reserve,ship" {
+		t.Fatalf("expected reply inside action to complete saga, got %+v %+v", inst, data)
+	}
+
+	// 发送失败时回滚等待状态并补偿
+	sendErr := traceStep("pay", nil)
+	sendErr.Async = true
+	sendErr.Timeout = time.Minute
+	sendErr.Action = func(context.Context, *orderData) error { return errors.New("broker down") }
+	failing, _ := New("order.unsent", repo, nil, traceStep("reserve", nil), sendErr)
+	inst, err = failing.Start(ctx, orderData{OrderID: "o2"})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	inst, data, _ = failing.Load(ctx, inst.ID)
+	if inst.Status != StatusCompensated || inst.DeadlineAt != nil || strings.Join(data.Trace, ",") != "reserve,undo-reserve" {
+		t.Fatalf("expected send failure to compensate, got %+v %+v", inst, data)
+	}
+}
+
+func TestSagaTimeouts(t *testing.T) {
+	repo := newSagaRepo(t)
+	ctx := context.Background()
+	producer := &fakeProducer{}
+
+	s, _ := New("order.fulfill", repo, nil, traceStep("reserve", nil), asyncPayStep(producer))
+	inst, _ := s.Start(ctx, orderData{OrderID: "o1"})
+
+	if n, err := s.CheckTimeouts(ctx, time.Now()); err != nil || n != 0 {
+		t.Fatalf("expected no timeouts yet, got %d %v", n, err)
+	}
+
+	// 第一次超时：重发命令
+	if n, err := s.CheckTimeouts(ctx, time.Now().Add(2*time.Minute)); err != nil || n != 1 {
+		t.Fatalf("expected resend, got %d %v", n, err)
+	}
+	inst, _, _ = s.Load(ctx, inst.ID)
+	if inst.Status != StatusAwaiting || inst.Attempt != 1 || len(producer.sent) != 2 {
+		t.Fatalf("expected resent command, got %+v sent=%d", inst, len(producer.sent))
+	}
+
+	// 第二次超时：重试耗尽，补偿当前步骤与已完成步骤
+	if n, err := s.CheckTimeouts(ctx, time.Now().Add(4*time.Minute)); err != nil || n != 1 {
+		t.Fatalf("expected compensation, got %d %v", n, err)
+	}
+	inst, data, _ := s.Load(ctx, inst.ID)
+	if inst.Status != StatusCompensated || strings.Join(data.Trace, ",") != "reserve,undo-pay,undo-reserve" {
+		t.Fatalf("unexpected timeout compensation %+v %+v", inst, data)
+	}
+
+	if _, err := NewCommand(ctx, "topic", nil); !errors.Is(err, ErrNoSagaContext) {
+		t.Fatalf("expected ErrNoSagaContext, got %v", err)
+	}
+}
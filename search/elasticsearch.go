@@ -0,0 +1,184 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/httpclient"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+/* ========================================================================
+ * Elasticsearch / OpenSearch Indexer
+ * ========================================================================
+ * 职责: 基于 REST API 实现 Indexer（两者的文档与搜索 API 兼容）
+ * 接口:
+ *   - PUT    /{index}/_doc/{id}
+ *   - DELETE /{index}/_doc/{id}
+ *   - POST   /{index}/_search   bool 查询: multi_match + term/terms 过滤
+ * 配置示例:
+ *   search:
+ *     url: http://elasticsearch:9200
+ *     username: elastic
+ *     password: ${ES_PASSWORD}
+ *     refresh: wait_for   # 写入后可见性: "" | true | wait_for
+ * ======================================================================== */
+
+// Config 检索引擎配置
+type Config struct {
+	URL      string        `yaml:"url"`
+	Username string        `yaml:"username"`
+	Password string        `yaml:"password"`
+	APIKey   string        `yaml:"api_key"` // Elasticsearch API Key（优先于用户名密码）
+	Refresh  string        `yaml:"refresh"` // 写入刷新策略
+	Timeout  time.Duration `yaml:"timeout"` // 请求超时，默认 10s
+}
+
+// Elasticsearch Elasticsearch / OpenSearch 索引器
+type Elasticsearch struct {
+	client *httpclient.Client
+	cfg    Config
+}
+
+// NewElasticsearch 创建 Elasticsearch 索引器
+func NewElasticsearch(cfg Config, log *logger.Logger, opts ...httpclient.Option) *Elasticsearch {
+	if cfg.APIKey != "" || cfg.Username != "" {
+		opts = append([]httpclient.Option{httpclient.WithSigner(httpclient.SignerFunc(func(req *http.Request) error {
+			if cfg.APIKey != "" {
+				req.Header.Set("Authorization", "ApiKey "+cfg.APIKey)
+			} else {
+				req.SetBasicAuth(cfg.Username, cfg.Password)
+			}
+			return nil
+		}))}, opts...)
+	}
+	return &Elasticsearch{
+		client: httpclient.New(httpclient.Config{BaseURL: cfg.URL, Timeout: cfg.Timeout}, log, opts...),
+		cfg:    cfg,
+	}
+}
+
+// NewOpenSearch 创建 OpenSearch 索引器（REST API 与 Elasticsearch 兼容）
+func NewOpenSearch(cfg Config, log *logger.Logger, opts ...httpclient.Option) *Elasticsearch {
+	return NewElasticsearch(cfg, log, opts...)
+}
+
+func docPath(index, id string) string {
+	return "/" + url.PathEscape(index) + "/_doc/" + url.PathEscape(id)
+}
+
+func (e *Elasticsearch) withRefresh(path string) string {
+	if e.cfg.Refresh == "" {
+		return path
+	}
+	return path + "?refresh=" + url.QueryEscape(e.cfg.Refresh)
+}
+
+// Index 写入或覆盖文档
+func (e *Elasticsearch) Index(ctx context.Context, index, id string, doc any) error {
+	return e.client.DoJSON(ctx, http.MethodPut, e.withRefresh(docPath(index, id)), doc, nil)
+}
+
+// Delete 删除文档
+func (e *Elasticsearch) Delete(ctx context.Context, index, id string) error {
+	err := e.client.DoJSON(ctx, http.MethodDelete, e.withRefresh(docPath(index, id)), nil, nil)
+	var statusErr *httpclient.StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// searchResponse 搜索响应（仅解析需要的字段）
+type searchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []struct {
+			ID string `json:"_id"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+// Search 搜索文档
+func (e *Elasticsearch) Search(ctx context.Context, index string, q Query) (*Result, error) {
+	page := q.Page.Normalize()
+	body := map[string]any{
+		"from":             (page.Page - 1) * page.PageSize,
+		"size":             page.PageSize,
+		"_source":          false,
+		"track_total_hits": true,
+		"query":            buildQuery(ctx, q),
+	}
+	if sort := buildSort(q.Sort); len(sort) > 0 {
+		body["sort"] = sort
+	}
+
+	var resp searchResponse
+	if err := e.client.DoJSON(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", body, &resp); err != nil {
+		return nil, fmt.Errorf("search %s: %w", index, err)
+	}
+
+	res := &Result{Total: resp.Hits.Total.Value, Page: page, IDs: make([]string, 0, len(resp.Hits.Hits))}
+	for _, hit := range resp.Hits.Hits {
+		res.IDs = append(res.IDs, hit.ID)
+	}
+	return res, nil
+}
+
+// buildQuery 构建 bool 查询
+func buildQuery(ctx context.Context, q Query) map[string]any {
+	var must any = map[string]any{"match_all": map[string]any{}}
+	if text := strings.TrimSpace(q.Text); text != "" {
+		mm := map[string]any{"query": text}
+		if len(q.Fields) > 0 {
+			mm["fields"] = q.Fields
+		}
+		must = map[string]any{"multi_match": mm}
+	}
+
+	filters := make([]any, 0, len(q.Filters)+1)
+	for field, value := range q.Filters {
+		filters = append(filters, termFilter(field, value))
+	}
+	if tc, ok := repository.TenantFromContext(ctx); ok {
+		filters = append(filters, termFilter(TenantField, tc.TenantID.String()))
+	}
+
+	return map[string]any{"bool": map[string]any{"must": must, "filter": filters}}
+}
+
+// termFilter 单值使用 term，切片使用 terms
+func termFilter(field string, value any) map[string]any {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		return map[string]any{"terms": map[string]any{field: value}}
+	}
+	return map[string]any{"term": map[string]any{field: value}}
+}
+
+// buildSort 构建排序
+func buildSort(fields []string) []any {
+	sort := make([]any, 0, len(fields))
+	for _, f := range fields {
+		order := "asc"
+		if rest, ok := strings.CutPrefix(f, "-"); ok {
+			f, order = rest, "desc"
+		}
+		if f != "" {
+			sort = append(sort, map[string]any{f: map[string]any{"order": order}})
+		}
+	}
+	return sort
+}
+
+// 编译期检查
+var _ Indexer = (*Elasticsearch)(nil)
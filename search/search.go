@@ -0,0 +1,162 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Search - 全文检索集成
+ * ========================================================================
+ * 职责: 定义索引器接口，将仓储变更同步到检索引擎，并提供按 ID 回填的搜索 API
+ * 实现: Elasticsearch / OpenSearch（REST API 兼容）
+ * 租户: ctx 中存在 TenantContext 时，Search 自动追加 tenant_id 过滤
+ *
+ * 使用示例:
+ *   idx := search.NewElasticsearch(search.Config{URL: "http://es:9200"}, log)
+ *   search.Sync(userRepo, idx, search.SyncConfig{Index: "users"}, func(u *User) (string, any) {
+ *       return u.ID, map[string]any{"name": u.Name, "tenant_id": u.TenantID.String()}
+ *   }, log)
+ *
+ *   res, err := idx.Search(ctx, "users", search.Query{
+ *       Text: "alice", Fields: []string{"name"}, Page: page,
+ *   })
+ *   users, err := search.Hydrate(ctx, userRepo, res)
+ * ======================================================================== */
+
+// TenantField 租户过滤字段
+const TenantField = "tenant_id"
+
+// Query 搜索请求
+type Query struct {
+	Text    string                 // 全文检索关键字，空表示匹配全部
+	Fields  []string               // 检索字段，空表示引擎默认字段
+	Filters map[string]any         // 精确过滤（切片值使用 terms）
+	Sort    []string               // 排序字段，前缀 "-" 表示降序
+	Page    repository.PageRequest // 分页
+}
+
+// Result 搜索结果（仅返回 ID，通过仓储回填）
+type Result struct {
+	IDs   []string
+	Total int64
+	Page  repository.PageRequest
+}
+
+// Indexer 检索引擎
+type Indexer interface {
+	// Index 写入或覆盖文档
+	Index(ctx context.Context, index, id string, doc any) error
+	// Delete 删除文档（不存在时不报错）
+	Delete(ctx context.Context, index, id string) error
+	// Search 搜索文档
+	Search(ctx context.Context, index string, q Query) (*Result, error)
+}
+
+// DocumentFunc 将模型转换为文档
+type DocumentFunc[T any] func(model *T) (id string, doc any)
+
+// SyncConfig 仓储同步配置
+type SyncConfig struct {
+	Index string `yaml:"index"`
+	// Strict 同步失败时返回错误（事务内将回滚），默认仅记录日志
+	Strict bool `yaml:"strict"`
+}
+
+// Sync 注册仓储钩子，将 Create/Update/Delete 同步到索引
+func Sync[T any](repo repository.HookRepository[T], idx Indexer, cfg SyncConfig, toDoc DocumentFunc[T], log *logger.Logger) {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	handle := func(op, id string, err error) error {
+		if err == nil {
+			return nil
+		}
+		if cfg.Strict {
+			return err
+		}
+		log.Warn("Search index sync failed",
+			zap.String("index", cfg.Index),
+			zap.String("op", op),
+			zap.String("id", id),
+			zap.Error(err),
+		)
+		return nil
+	}
+	upsert := func(op string) repository.HookFunc[T] {
+		return func(ctx context.Context, model *T) error {
+			id, doc := toDoc(model)
+			return handle(op, id, idx.Index(ctx, cfg.Index, id, doc))
+		}
+	}
+
+	repo.OnAfterCreate(upsert("create"))
+	repo.OnAfterUpdate(upsert("update"))
+	repo.OnAfterDelete(func(ctx context.Context, ids []string) error {
+		for _, id := range ids {
+			if err := handle("delete", id, idx.Delete(ctx, cfg.Index, id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Hydrate 按搜索结果顺序回填模型，已不存在的记录被跳过
+func Hydrate[T any](ctx context.Context, repo repository.QueryRepository[T], res *Result, opts ...repository.Option) ([]*T, error) {
+	if res == nil || len(res.IDs) == 0 {
+		return []*T{}, nil
+	}
+	models, err := repo.FindByIDs(ctx, res.IDs, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*T, len(models))
+	for _, m := range models {
+		if id, ok := modelID(m); ok {
+			byID[id] = m
+		}
+	}
+	out := make([]*T, 0, len(res.IDs))
+	for _, id := range res.IDs {
+		if m, ok := byID[id]; ok {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+// Identifiable 提供 ID 的模型（未实现时通过反射读取 ID 字段）
+type Identifiable interface {
+	GetID() string
+}
+
+// modelID 读取模型 ID
+func modelID(model any) (string, bool) {
+	if m, ok := model.(Identifiable); ok {
+		return m.GetID(), true
+	}
+	rv := reflect.Indirect(reflect.ValueOf(model))
+	if rv.Kind() != reflect.Struct {
+		return "", false
+	}
+	f := rv.FieldByName("ID")
+	if !f.IsValid() {
+		return "", false
+	}
+	switch v := f.Interface().(type) {
+	case string:
+		return v, true
+	case fmt.Stringer:
+		return v.String(), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
@@ -0,0 +1,152 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type searchTestModel struct {
+	ID   string `gorm:"column:id;type:char(26);primaryKey"`
+	Name string `gorm:"column:name"`
+}
+
+func (searchTestModel) TenantIgnored() bool {
+	return true
+}
+
+// fakeES 内存版 Elasticsearch，仅支持按 name 子串检索
+type fakeES struct {
+	mu       sync.Mutex
+	docs     map[string]map[string]any
+	lastBody map[string]any
+	order    []string
+}
+
+func (f *fakeES) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodPut && len(parts) == 3:
+		var doc map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&doc)
+		if _, ok := f.docs[parts[2]]; !ok {
+			f.order = append(f.order, parts[2])
+		}
+		f.docs[parts[2]] = doc
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"result":"created"}`))
+	case r.Method == http.MethodDelete && len(parts) == 3:
+		if _, ok := f.docs[parts[2]]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"result":"not_found"}`))
+			return
+		}
+		delete(f.docs, parts[2])
+		_, _ = w.Write([]byte(`{"result":"deleted"}`))
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[1] == "_search":
+		f.lastBody = map[string]any{}
+		_ = json.NewDecoder(r.Body).Decode(&f.lastBody)
+		text := ""
+		if mm, ok := f.lastBody["query"].(map[string]any)["bool"].(map[string]any)["must"].(map[string]any)["multi_match"].(map[string]any); ok {
+			text, _ = mm["query"].(string)
+		}
+		var hits []map[string]any
+		// 倒序返回，验证 Hydrate 保持搜索结果顺序
+		for i := len(f.order) - 1; i >= 0; i-- {
+			id := f.order[i]
+			doc, ok := f.docs[id]
+			if ok && strings.Contains(doc["name"].(string), text) {
+				hits = append(hits, map[string]any{"_id": id})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"hits": map[string]any{"total": map[string]any{"value": len(hits)}, "hits": hits},
+		})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func TestSyncSearchHydrate(t *testing.T) {
+	es := &fakeES{docs: map[string]map[string]any{}}
+	srv := httptest.NewServer(es)
+	defer srv.Close()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&searchTestModel{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	repo := repository.NewRepository[searchTestModel](db)
+	idx := NewOpenSearch(Config{URL: srv.URL, Refresh: "wait_for"}, nil)
+	Sync[searchTestModel](repo, idx, SyncConfig{Index: "users", Strict: true}, func(m *searchTestModel) (string, any) {
+		return m.ID, map[string]any{"name": m.Name}
+	}, nil)
+
+	ctx := context.Background()
+	ids := make([]string, 3)
+	for i, name := range []string{"alice", "alina", "bob"} {
+		ids[i] = ulidv2.Make().String()
+		if err := repo.Create(ctx, &searchTestModel{ID: ids[i], Name: name}); err != nil {
+			t.Fatalf("create: %v", err)
+		}
+	}
+	if err := repo.UpdateByID(ctx, ids[2], map[string]any{"name": "alibob"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := repo.Delete(ctx, ids[1]); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := idx.Delete(ctx, "users", ids[1]); err != nil {
+		t.Fatalf("expected missing document delete to succeed, got %v", err)
+	}
+
+	tenantCtx := repository.WithTenantContext(ctx, repository.TenantContext{TenantID: ulidv2.Make(), IsAdmin: true})
+	res, err := idx.Search(tenantCtx, "users", Query{
+		Text:    "ali",
+		Fields:  []string{"name"},
+		Filters: map[string]any{"status": []string{"active"}},
+		Sort:    []string{"-_score"},
+		Page:    repository.PageRequest{Page: 1, PageSize: 10},
+	})
+	if err != nil {
+		t.Fatalf("search: %v", err)
+	}
+	if res.Total != 2 || len(res.IDs) != 2 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+
+	filters := es.lastBody["query"].(map[string]any)["bool"].(map[string]any)["filter"].([]any)
+	if len(filters) != 2 {
+		t.Fatalf("expected status and tenant filters, got %v", filters)
+	}
+	if _, ok := filters[0].(map[string]any)["terms"]; !ok {
+		t.Fatalf("expected slice filter to use terms, got %v", filters[0])
+	}
+	if es.lastBody["sort"] == nil || es.lastBody["size"].(float64) != 10 {
+		t.Fatalf("unexpected request body: %v", es.lastBody)
+	}
+
+	users, err := Hydrate[searchTestModel](ctx, repo, res)
+	if err != nil {
+		t.Fatalf("hydrate: %v", err)
+	}
+	if len(users) != 2 || users[0].Name != "alibob" || users[1].Name != "alice" {
+		t.Fatalf("unexpected hydrated users: %+v", users)
+	}
+}
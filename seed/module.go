@@ -0,0 +1,75 @@
+package seed
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/conf"
+	"github.com/aisgo/ais-go-pkg/logger"
+)
+
+/* ========================================================================
+ * Seed Module
+ * ========================================================================
+ * 职责: 收集 fx group 中的 Seeder，在应用启动时执行（Enabled=false 时不执行）
+ * Bundle: 从 conf.Source 的 seed 节解码 Config（缺省见 DefaultConfig）
+ *
+ * 使用示例:
+ *   fx.Provide(seed.AsSeeder(func(roles RoleRepo) seed.Seeder {
+ *       return seed.Seeder{Name: "default-roles", Order: 20, Run: ...}
+ *   }))
+ * ======================================================================== */
+
+// SeederGroup Seeder 的 fx group 名称
+const SeederGroup = "seeders"
+
+// AsSeeder 将返回 Seeder 的构造函数标注为初始化数据
+func AsSeeder(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"`+SeederGroup+`"`))
+}
+
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	Lc      fx.Lifecycle
+	Config  Config
+	DB      *gorm.DB
+	Logger  *logger.Logger `optional:"true"`
+	Seeders []Seeder       `group:"seeders"`
+}
+
+// NewFromParams 创建执行器并在启动时执行
+func NewFromParams(p Params) (*Runner, error) {
+	r := New(p.DB, p.Config, p.Logger)
+	if err := r.Register(p.Seeders...); err != nil {
+		return nil, err
+	}
+	if p.Config.Enabled {
+		p.Lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				_, err := r.Run(ctx)
+				return err
+			},
+		})
+	}
+	return r, nil
+}
+
+// Module 初始化数据模块
+// 提供: *Runner
+var Module = fx.Module("seed",
+	fx.Provide(NewFromParams),
+	// 确保执行器被构造，启动钩子得以注册
+	fx.Invoke(func(*Runner) {}),
+)
+
+// Bundle 带配置解码的初始化数据模块
+// 依赖: conf.Source, *gorm.DB
+// 提供: seed.Config, *Runner
+var Bundle = fx.Module("seed-bundle",
+	fx.Provide(conf.Section("seed", DefaultConfig)),
+	Module,
+)
@@ -0,0 +1,324 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+/* ========================================================================
+ * Seed - 声明式数据初始化
+ * ========================================================================
+ * 职责: 业务模块注册有序、幂等的初始化数据（管理员租户、默认角色、字典等），
+ *       启动时统一执行并记录到 seed_history 表
+ * 语义:
+ *   - 按 Order 升序执行（相同 Order 按 Name 排序），Name 全局唯一
+ *   - 已执行且 Version 未变化的 Seeder 跳过；Version 变化时重新执行（Run 需幂等）
+ *   - 全部待执行 Seeder 与历史记录在同一事务中提交，任一失败整体回滚
+ *     Run 收到的 ctx 携带事务，仓储方法使用该 ctx 即自动加入事务
+ *   - Envs 非空时仅在列出的环境执行（如 DevOnly 的演示数据），其他环境跳过且不记录
+ *   - DryRun 只输出执行计划，不写入数据
+ *   - Postgres 下执行期间锁定历史表，多实例同时启动时串行执行
+ *
+ * 配置示例:
+ *   seed:
+ *     enabled: true
+ *     env: staging          # 当前环境，与 Seeder.Envs 匹配
+ *     dry_run: false
+ *     table: seed_history
+ *
+ * 使用示例:
+ *   runner := seed.New(db, seed.Config{Env: seed.EnvDev}, log)
+ *   runner.Register(seed.Seeder{
+ *       Name:  "default-roles",
+ *       Order: 20,
+ *       Run: func(ctx context.Context) error {
+ *           return roleRepo.UpsertBatch(ctx, defaultRoles)
+ *       },
+ *   })
+ *   plan, err := runner.Run(ctx)
+ * ======================================================================== */
+
+// 常用环境名称
+const (
+	EnvDev     = "dev"
+	EnvStaging = "staging"
+	EnvProd    = "prod"
+)
+
+// DevOnly 仅开发与预发布环境执行
+var DevOnly = []string{EnvDev, EnvStaging}
+
+// DefaultTable 默认历史表
+const DefaultTable = "seed_history"
+
+// Seeder 初始化数据声明
+type Seeder struct {
+	// Name 唯一名称，记录在历史表中
+	Name string
+	// Order 执行顺序（升序）
+	Order int
+	// Version 版本，变化时重新执行
+	Version string
+	// Envs 允许执行的环境，空值表示全部环境
+	Envs []string
+	// Description 描述（用于执行计划输出）
+	Description string
+	// Run 写入数据，ctx 携带事务
+	Run func(ctx context.Context) error
+}
+
+// allowed 是否允许在 env 中执行
+func (s Seeder) allowed(env string) bool {
+	return len(s.Envs) == 0 || slices.Contains(s.Envs, env)
+}
+
+// Config 初始化配置
+type Config struct {
+	// Enabled 是否在启动时执行（Fx 模块使用）
+	Enabled bool `yaml:"enabled"`
+	// Env 当前环境
+	Env string `yaml:"env"`
+	// DryRun 只输出执行计划
+	DryRun bool `yaml:"dry_run"`
+	// Table 历史表名
+	Table string `yaml:"table"`
+}
+
+// DefaultConfig 返回默认配置（启用，历史表 seed_history）
+func DefaultConfig() Config {
+	return Config{
+		Enabled: true,
+		Table:   DefaultTable,
+	}
+}
+
+// History 执行历史
+type History struct {
+	Name      string    `gorm:"column:name;size:128;primaryKey"`
+	Version   string    `gorm:"column:version;size:64;not null;default:''"`
+	Env       string    `gorm:"column:env;size:32;not null;default:''"`
+	AppliedAt time.Time `gorm:"column:applied_at;not null"`
+	Duration  int64     `gorm:"column:duration_ms;not null;default:0"`
+}
+
+// Action 计划动作
+type Action string
+
+const (
+	ActionRun     Action = "run"     // 首次执行
+	ActionRerun   Action = "rerun"   // 版本变化，重新执行
+	ActionApplied Action = "applied" // 已执行，跳过
+	ActionSkipped Action = "skipped" // 环境不匹配，跳过
+)
+
+// Step 计划中的一项
+type Step struct {
+	Name        string
+	Order       int
+	Version     string
+	Description string
+	Action      Action
+}
+
+// Plan 执行计划
+type Plan struct {
+	Env   string
+	Steps []Step
+}
+
+// Pending 返回需要执行的步骤数
+func (p *Plan) Pending() int {
+	n := 0
+	for _, s := range p.Steps {
+		if s.Action == ActionRun || s.Action == ActionRerun {
+			n++
+		}
+	}
+	return n
+}
+
+// String 返回可读的执行计划
+func (p *Plan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "seed plan (env=%s, pending=%d)\n", p.Env, p.Pending())
+	for _, s := range p.Steps {
+		fmt.Fprintf(&b, "  %-8s %4d %s", s.Action, s.Order, s.Name)
+		if s.Version != "" {
+			fmt.Fprintf(&b, "@%s", s.Version)
+		}
+		if s.Description != "" {
+			fmt.Fprintf(&b, " - %s", s.Description)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Runner 初始化执行器
+type Runner struct {
+	db      *gorm.DB
+	cfg     Config
+	log     *logger.Logger
+	tm      *repository.TxManager
+	seeders []Seeder
+}
+
+// New 创建初始化执行器
+func New(db *gorm.DB, cfg Config, log *logger.Logger) *Runner {
+	if cfg.Table == "" {
+		cfg.Table = DefaultTable
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &Runner{db: db, cfg: cfg, log: log, tm: repository.NewTxManager(db)}
+}
+
+// Register 注册 Seeder（名称重复或缺少 Run 时返回错误）
+func (r *Runner) Register(seeders ...Seeder) error {
+	for _, s := range seeders {
+		if s.Name == "" || s.Run == nil {
+			return errors.New(errors.ErrCodeInvalidArgument, "seeder requires a name and a run function")
+		}
+		if slices.ContainsFunc(r.seeders, func(e Seeder) bool { return e.Name == s.Name }) {
+			return errors.New(errors.ErrCodeAlreadyExists, "duplicate seeder: "+s.Name)
+		}
+		r.seeders = append(r.seeders, s)
+	}
+	slices.SortStableFunc(r.seeders, func(a, b Seeder) int {
+		if a.Order != b.Order {
+			return a.Order - b.Order
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return nil
+}
+
+// Migrate 创建历史表
+func (r *Runner) Migrate(ctx context.Context) error {
+	return r.db.WithContext(ctx).Table(r.cfg.Table).AutoMigrate(&History{})
+}
+
+// Plan 返回执行计划（不写入数据）
+func (r *Runner) Plan(ctx context.Context) (*Plan, error) {
+	if err := r.Migrate(ctx); err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInternal, "failed to migrate seed history", err)
+	}
+	return r.plan(r.db.WithContext(ctx))
+}
+
+func (r *Runner) plan(db *gorm.DB) (*Plan, error) {
+	var rows []History
+	if err := db.Table(r.cfg.Table).Find(&rows).Error; err != nil {
+		return nil, errors.FromGORM(err)
+	}
+	applied := make(map[string]string, len(rows))
+	for _, h := range rows {
+		applied[h.Name] = h.Version
+	}
+
+	plan := &Plan{Env: r.cfg.Env, Steps: make([]Step, 0, len(r.seeders))}
+	for _, s := range r.seeders {
+		step := Step{Name: s.Name, Order: s.Order, Version: s.Version, Description: s.Description}
+		version, done := applied[s.Name]
+		switch {
+		case !s.allowed(r.cfg.Env):
+			step.Action = ActionSkipped
+		case !done:
+			step.Action = ActionRun
+		case version != s.Version:
+			step.Action = ActionRerun
+		default:
+			step.Action = ActionApplied
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	return plan, nil
+}
+
+// Run 执行待执行的 Seeder 并返回执行计划；DryRun 时只输出计划
+func (r *Runner) Run(ctx context.Context) (*Plan, error) {
+	if r.cfg.DryRun {
+		plan, err := r.Plan(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.log.Info("Seed dry run", zap.String("env", r.cfg.Env), zap.Int("pending", plan.Pending()))
+		r.log.Info(plan.String())
+		return plan, nil
+	}
+
+	if err := r.Migrate(ctx); err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInternal, "failed to migrate seed history", err)
+	}
+
+	var plan *Plan
+	err := r.tm.RunInTx(ctx, func(txCtx context.Context) error {
+		db := r.tm.DB(txCtx)
+		if db.Dialector.Name() == "postgres" {
+			if err := db.Exec(`LOCK TABLE "` + r.cfg.Table + `" IN EXCLUSIVE MODE`).Error; err != nil {
+				return errors.FromGORM(err)
+			}
+		}
+		// 加锁后重新读取历史，避免并发实例重复执行
+		p, err := r.plan(db)
+		if err != nil {
+			return err
+		}
+		plan = p
+
+		for i, step := range p.Steps {
+			if step.Action != ActionRun && step.Action != ActionRerun {
+				continue
+			}
+			start := time.Now()
+			if err := r.seeders[i].Run(txCtx); err != nil {
+				return fmt.Errorf("seed %s: %w", step.Name, err)
+			}
+			elapsed := time.Since(start)
+			if err := r.record(r.tm.DB(txCtx), step, start, elapsed); err != nil {
+				return err
+			}
+			r.log.Info("Seed applied",
+				zap.String("name", step.Name),
+				zap.String("version", step.Version),
+				zap.String("action", string(step.Action)),
+				zap.Duration("duration", elapsed),
+			)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// record 写入或更新历史记录
+func (r *Runner) record(db *gorm.DB, step Step, start time.Time, elapsed time.Duration) error {
+	row := History{
+		Name:      step.Name,
+		Version:   step.Version,
+		Env:       r.cfg.Env,
+		AppliedAt: start,
+		Duration:  elapsed.Milliseconds(),
+	}
+	var err error
+	if step.Action == ActionRerun {
+		err = db.Table(r.cfg.Table).Where("name = ?", step.Name).
+			Select("version", "env", "applied_at", "duration_ms").Updates(&row).Error
+	} else {
+		err = db.Table(r.cfg.Table).Create(&row).Error
+	}
+	return errors.FromGORM(err)
+}
@@ -0,0 +1,194 @@
+package seed
+
+import (
+	"context"
+	stderrors "errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/repository"
+)
+
+type role struct {
+	Code string `gorm:"column:code;primaryKey"`
+	Name string `gorm:"column:name"`
+}
+
+func (role) TenantIgnored() bool { return true }
+
+func openDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "seed.db")), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&role{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func roleSeeder(repo repository.Repository[role], name string, order int, roles ...*role) Seeder {
+	return Seeder{
+		Name:  name,
+		Order: order,
+		Run: func(ctx context.Context) error {
+			return repo.UpsertBatch(ctx, roles)
+		},
+	}
+}
+
+func actions(p *Plan) []string {
+	var out []string
+	for _, s := range p.Steps {
+		out = append(out, s.Name+":"+string(s.Action))
+	}
+	return out
+}
+
+func TestRunner(t *testing.T) {
+	db := openDB(t)
+	repo := repository.NewRepository[role](db)
+	var order []string
+	track := func(s Seeder) Seeder {
+		run := s.Run
+		s.Run = func(ctx context.Context) error {
+			order = append(order, s.Name)
+			return run(ctx)
+		}
+		return s
+	}
+
+	newRunner := func(env string, version string) *Runner {
+		r := New(db, Config{Env: env}, nil)
+		err := r.Register(
+			track(roleSeeder(repo, "roles", 20, &role{Code: "admin", Name: "Admin"})),
+			track(Seeder{Name: "demo", Order: 30, Envs: DevOnly, Run: func(ctx context.Context) error {
+				return repo.Create(ctx, &role{Code: "demo", Name: "Demo"})
+			}}),
+			track(Seeder{Name: "dict", Order: 10, Version: version, Run: func(ctx context.Context) error {
+				return repo.UpsertBatch(ctx, []*role{{Code: "viewer", Name: "Viewer " + version}})
+			}}),
+		)
+		if err != nil {
+			t.Fatalf("register: %v", err)
+		}
+		return r
+	}
+
+	plan, err := newRunner(EnvProd, "v1").Run(context.Background())
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if !slices.Equal(order, []string{"dict", "roles"}) {
+		t.Fatalf("unexpected order %v", order)
+	}
+	if got := actions(plan); !slices.Equal(got, []string{"dict:run", "roles:run", "demo:skipped"}) {
+		t.Fatalf("unexpected plan %v", got)
+	}
+	if n, _ := repo.Count(context.Background(), ""); n != 2 {
+		t.Fatalf("expected 2 roles, got %d", n)
+	}
+
+	// 再次执行：已执行的跳过，版本变化的重新执行，dev 环境执行演示数据
+	order = nil
+	plan, err = newRunner(EnvDev, "v2").Run(context.Background())
+	if err != nil {
+		t.Fatalf("rerun: %v", err)
+	}
+	if !slices.Equal(order, []string{"dict", "demo"}) {
+		t.Fatalf("unexpected rerun order %v", order)
+	}
+	if got := actions(plan); !slices.Equal(got, []string{"dict:rerun", "roles:applied", "demo:run"}) {
+		t.Fatalf("unexpected rerun plan %v", got)
+	}
+	if v, _ := repo.FindOne(context.Background(), "code = ?", "viewer"); v == nil || v.Name != "Viewer v2" {
+		t.Fatalf("expected dict to be reseeded, got %+v", v)
+	}
+	var history []History
+	db.Table(DefaultTable).Order("name").Find(&history)
+	if len(history) != 3 || history[1].Name != "dict" || history[1].Version != "v2" || history[0].Env != EnvDev {
+		t.Fatalf("unexpected history %+v", history)
+	}
+}
+
+func TestRunnerRollback(t *testing.T) {
+	db := openDB(t)
+	repo := repository.NewRepository[role](db)
+	boom := stderrors.New("boom")
+	r := New(db, Config{}, nil)
+	_ = r.Register(
+		roleSeeder(repo, "roles", 1, &role{Code: "admin", Name: "Admin"}),
+		Seeder{Name: "broken", Order: 2, Run: func(context.Context) error { return boom }},
+	)
+	if _, err := r.Run(context.Background()); !stderrors.Is(err, boom) {
+		t.Fatalf("expected seeder error, got %v", err)
+	}
+	if n, _ := repo.Count(context.Background(), ""); n != 0 {
+		t.Fatalf("expected rollback, got %d roles", n)
+	}
+	var n int64
+	db.Table(DefaultTable).Count(&n)
+	if n != 0 {
+		t.Fatalf("expected empty history, got %d", n)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	db := openDB(t)
+	repo := repository.NewRepository[role](db)
+	r := New(db, Config{Env: EnvProd, DryRun: true}, nil)
+	_ = r.Register(
+		roleSeeder(repo, "roles", 1, &role{Code: "admin", Name: "Admin"}),
+		Seeder{Name: "demo", Order: 2, Envs: DevOnly, Description: "demo data", Run: func(context.Context) error { return nil }},
+	)
+	plan, err := r.Run(context.Background())
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if plan.Pending() != 1 || !strings.Contains(plan.String(), "skipped     2 demo - demo data") {
+		t.Fatalf("unexpected plan:\n%s", plan)
+	}
+	if n, _ := repo.Count(context.Background(), ""); n != 0 {
+		t.Fatalf("dry run must not write, got %d roles", n)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	r := New(openDB(t), Config{}, nil)
+	noop := func(context.Context) error { return nil }
+	if err := r.Register(Seeder{Name: "a", Run: noop}); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := r.Register(Seeder{Name: "a", Run: noop}); errors.Code(err) != errors.ErrCodeAlreadyExists {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+	if err := r.Register(Seeder{Name: "b"}); errors.Code(err) != errors.ErrCodeInvalidArgument {
+		t.Fatalf("expected invalid argument, got %v", err)
+	}
+}
+
+func TestModule(t *testing.T) {
+	db := openDB(t)
+	app := fxtest.New(t,
+		fx.Supply(db, Config{Enabled: true}),
+		fx.Provide(AsSeeder(func(db *gorm.DB) Seeder {
+			return roleSeeder(repository.NewRepository[role](db), "roles", 1, &role{Code: "admin", Name: "Admin"})
+		})),
+		Module,
+	)
+	app.RequireStart().RequireStop()
+	var n int64
+	db.Model(&role{}).Count(&n)
+	if n != 1 {
+		t.Fatalf("expected seeders to run on start, got %d roles", n)
+	}
+}
@@ -16,6 +16,65 @@ type Config struct {
 	// HookTimeout 单个钩子的超时时间
 	// 超时后仅该钩子中止，其他钩子继续
 	HookTimeout time.Duration `yaml:"hook_timeout"`
+	// DrainPeriod 排空等待时间
+	// 关停开始时先将 /readyz 置为 503，等待该时间让负载均衡摘除流量后再执行钩子
+	// 0 表示不等待
+	DrainPeriod time.Duration `yaml:"drain_period"`
+	// Priorities 内置组件关停钩子优先级
+	Priorities PriorityConfig `yaml:"priorities"`
+}
+
+// PriorityConfig 内置组件关停优先级配置
+// nil 表示使用默认优先级（因 PriorityFirst 为 0，不能以零值表示未设置）；数值越小越先执行
+// 默认顺序: 停止接收流量 -> 停止消费 -> 刷新生产者 -> 关闭连接池
+type PriorityConfig struct {
+	HTTPServer *int `yaml:"http_server"`
+	GRPCServer *int `yaml:"grpc_server"`
+	MQConsumer *int `yaml:"mq_consumer"`
+	MQProducer *int `yaml:"mq_producer"`
+	Redis      *int `yaml:"redis"`
+	Database   *int `yaml:"database"`
+}
+
+// Priority 返回指向 p 的指针，便于在代码中构造 PriorityConfig
+func Priority(p int) *int {
+	return &p
+}
+
+// DefaultPriorityConfig 返回默认内置组件优先级
+func DefaultPriorityConfig() PriorityConfig {
+	return PriorityConfig{
+		HTTPServer: Priority(PriorityFirst),
+		GRPCServer: Priority(PriorityFirst),
+		MQConsumer: Priority(PriorityHigh),
+		MQProducer: Priority(PriorityNormal),
+		Redis:      Priority(PriorityLast),
+		Database:   Priority(PriorityLast),
+	}
+}
+
+// withDefaults 填充未设置的优先级
+func (p PriorityConfig) withDefaults() PriorityConfig {
+	def := DefaultPriorityConfig()
+	if p.HTTPServer == nil {
+		p.HTTPServer = def.HTTPServer
+	}
+	if p.GRPCServer == nil {
+		p.GRPCServer = def.GRPCServer
+	}
+	if p.MQConsumer == nil {
+		p.MQConsumer = def.MQConsumer
+	}
+	if p.MQProducer == nil {
+		p.MQProducer = def.MQProducer
+	}
+	if p.Redis == nil {
+		p.Redis = def.Redis
+	}
+	if p.Database == nil {
+		p.Database = def.Database
+	}
+	return p
 }
 
 // DefaultConfig 返回默认配置
@@ -23,5 +82,6 @@ func DefaultConfig() *Config {
 	return &Config{
 		Timeout:     30 * time.Second,
 		HookTimeout: 30 * time.Second,
+		Priorities:  DefaultPriorityConfig(),
 	}
 }
@@ -0,0 +1,99 @@
+package shutdown
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/fx"
+)
+
+/* ========================================================================
+ * Shutdown Integration - 组件关停集成
+ * ========================================================================
+ * 职责: 为各组件包提供接入 Manager 的公共能力，shutdown 本身不依赖任何组件
+ * 集成位置（均提供 ShutdownModule，需与 Module 一起使用）:
+ *   - transport/http: Fiber 服务器 (PriorityFirst)，并关联就绪开关
+ *   - transport/grpc: gRPC 服务器 (PriorityFirst)，并关联就绪开关
+ *   - mq:             Consumer (PriorityHigh) / Producer (PriorityNormal)
+ *   - cache/redis:    Redis 连接池 (PriorityLast)
+ *   - database:       Gorm 连接池 (PriorityLast)
+ *   - upgrade:        监听器交接完成后触发应用关停
+ * 说明:
+ *   - 默认顺序可通过 Config.Priorities 调整
+ *   - 各集成在注册钩子后调用 ShutdownOnStop，使 Manager.Shutdown 早于组件
+ *     自身的 fx OnStop 执行；Shutdown 只执行一次，重复触发无副作用
+ *   - 内置钩子均经 Once 包装，与组件自身的 OnStop 共存时不会重复关闭
+ *
+ * 使用示例:
+ *   fx.New(
+ *       shutdown.Module,
+ *       httpserver.Bundle, httpserver.ShutdownModule,
+ *       mq.Bundle, mq.ShutdownModule,
+ *   )
+ * ======================================================================== */
+
+// Priorities 返回内置组件优先级配置（未设置项已填充默认值）
+func (m *Manager) Priorities() PriorityConfig {
+	return m.config.Priorities.withDefaults()
+}
+
+// UseReadiness 关联就绪开关，排空期开始时置为未就绪
+func (m *Manager) UseReadiness(r Readiness) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.readiness = r
+}
+
+// UseUpgradeSignal 关联平滑升级完成信号：通道关闭后 Wait 立即返回并执行关停
+func (m *Manager) UseUpgradeSignal(exited <-chan struct{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.upgraded = exited
+}
+
+// ShutdownOnStop 在 fx 停止阶段触发 Shutdown
+// 调用方应在组件构造完成后调用，使 Shutdown 早于该组件自身的 OnStop 执行
+func (m *Manager) ShutdownOnStop(lc fx.Lifecycle) {
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			m.Shutdown(ctx)
+			return nil
+		},
+	})
+}
+
+// Once 保证钩子只执行一次，重复调用返回首次结果
+func Once(hook ShutdownHook) ShutdownHook {
+	var (
+		once sync.Once
+		err  error
+	)
+	return func(ctx context.Context) error {
+		once.Do(func() {
+			err = hook(ctx)
+		})
+		return err
+	}
+}
+
+// CloseHook 将关闭函数包装为只执行一次、受 ctx 限制的钩子
+func CloseHook(fn func() error) ShutdownHook {
+	return Once(func(ctx context.Context) error {
+		return closeWithContext(ctx, fn)
+	})
+}
+
+// closeWithContext 在 ctx 限制内执行关闭函数
+func closeWithContext(ctx context.Context, fn func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- fn()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -2,6 +2,7 @@ package shutdown
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"os/signal"
 	"sort"
@@ -9,6 +10,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/aisgo/ais-go-pkg/clock"
 	"github.com/aisgo/ais-go-pkg/logger"
 
 	"go.uber.org/fx"
@@ -22,29 +24,46 @@ import (
  * 特性:
  *   - 按优先级顺序执行关停钩子
  *   - 同优先级钩子并行执行
- *   - 全局超时控制
+ *   - 全局超时控制 + 单钩子超时（可按钩子配置）
+ *   - 钩子 panic 隔离
  *   - 信号监听 (SIGINT, SIGTERM, SIGQUIT)
+ *   - 排空期: 先翻转 /readyz 为 503 并等待 DrainPeriod，再执行钩子
  * ======================================================================== */
 
 // ShutdownHook 关停钩子函数类型
 type ShutdownHook func(ctx context.Context) error
 
+// Readiness 就绪开关，排空期开始时置为未就绪（如 transport/http.Readiness）
+type Readiness interface {
+	SetReady(ready bool)
+}
+
+// HookOptions 钩子选项
+type HookOptions struct {
+	// Timeout 单个钩子超时时间，<= 0 时使用 Config.HookTimeout
+	Timeout time.Duration
+}
+
 // hookEntry 钩子条目，包含名称和优先级
 type hookEntry struct {
 	name     string
 	hook     ShutdownHook
 	priority int
+	timeout  time.Duration
 }
 
 // Manager 优雅关停管理器
 type Manager struct {
-	config  *Config
-	logger  *logger.Logger
-	timeout time.Duration
-	hooks   []hookEntry
-	mu      sync.RWMutex
-	done    chan struct{}
-	once    sync.Once
+	config    *Config
+	logger    *logger.Logger
+	readiness Readiness
+	clock     clock.Clock
+	timeout   time.Duration
+	hooks     []hookEntry
+	mu        sync.RWMutex
+	done      chan struct{}
+	once      sync.Once
+	upgraded  <-chan struct{}
 }
 
 // ManagerParams 依赖参数
@@ -53,6 +72,12 @@ type ManagerParams struct {
 
 	Logger *logger.Logger
 	Config *Config
+
+	// Readiness 可选的就绪开关，未提供时由 HTTP / gRPC 的 ShutdownModule 关联
+	Readiness Readiness `optional:"true"`
+
+	// Clock 可选的时钟（排空期与超时计时），默认真实时钟
+	Clock clock.Clock `optional:"true"`
 }
 
 // NewManager 创建优雅关停管理器
@@ -63,11 +88,13 @@ func NewManager(p ManagerParams) *Manager {
 	}
 
 	return &Manager{
-		config:  cfg,
-		logger:  p.Logger,
-		timeout: cfg.Timeout,
-		hooks:   make([]hookEntry, 0),
-		done:    make(chan struct{}),
+		config:    cfg,
+		logger:    p.Logger,
+		readiness: p.Readiness,
+		clock:     clock.OrReal(p.Clock),
+		timeout:   cfg.Timeout,
+		hooks:     make([]hookEntry, 0),
+		done:      make(chan struct{}),
 	}
 }
 
@@ -79,7 +106,13 @@ func (m *Manager) RegisterHook(name string, hook ShutdownHook) {
 // RegisterHookWithPriority 注册带优先级的关停钩子
 // priority: 优先级，数值越小越先执行
 // 同优先级的钩子会并行执行
-func (m *Manager) RegisterHookWithPriority(name string, hook ShutdownHook, priority int) {
+// opts: 可选的钩子选项（如单独的超时时间）
+func (m *Manager) RegisterHookWithPriority(name string, hook ShutdownHook, priority int, opts ...HookOptions) {
+	var timeout time.Duration
+	if len(opts) > 0 {
+		timeout = opts[0].Timeout
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -87,22 +120,33 @@ func (m *Manager) RegisterHookWithPriority(name string, hook ShutdownHook, prior
 		name:     name,
 		hook:     hook,
 		priority: priority,
+		timeout:  timeout,
 	})
 
 	m.logger.Info("Registered shutdown hook",
 		zap.String("name", name),
 		zap.Int("priority", priority),
+		zap.Duration("timeout", timeout),
 	)
 }
 
 // Wait 阻塞等待关停信号
-// 监听 SIGINT, SIGTERM, SIGQUIT 信号
+// 监听 SIGINT, SIGTERM, SIGQUIT 信号；关联 Upgrader 时监听器交接完成也会触发关停
 func (m *Manager) Wait() {
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	defer signal.Stop(sigChan)
 
-	sig := <-sigChan
-	m.logger.Info("Received shutdown signal", zap.String("signal", sig.String()))
+	m.mu.RLock()
+	upgraded := m.upgraded
+	m.mu.RUnlock()
+
+	select {
+	case sig := <-sigChan:
+		m.logger.Info("Received shutdown signal", zap.String("signal", sig.String()))
+	case <-upgraded:
+		m.logger.Info("Listeners handed over to upgraded process")
+	}
 
 	m.Shutdown(context.Background())
 }
@@ -134,7 +178,9 @@ func (m *Manager) IsShutdown() bool {
 
 // performShutdown 执行实际的关停逻辑
 func (m *Manager) performShutdown(ctx context.Context) {
-	shutdownCtx, cancel := context.WithTimeout(ctx, m.timeout)
+	m.drain(ctx)
+
+	shutdownCtx, cancel := clock.WithTimeout(ctx, m.clock, m.timeout)
 	defer cancel()
 
 	// 复制钩子列表，避免在锁中执行
@@ -181,6 +227,33 @@ func (m *Manager) performShutdown(ctx context.Context) {
 	}
 }
 
+// drain 排空期：标记未就绪并等待负载均衡摘除流量
+func (m *Manager) drain(ctx context.Context) {
+	m.mu.RLock()
+	readiness := m.readiness
+	m.mu.RUnlock()
+	if readiness != nil {
+		readiness.SetReady(false)
+	}
+
+	if m.config.DrainPeriod <= 0 {
+		return
+	}
+
+	m.logger.Info("Readiness set to not ready, draining traffic",
+		zap.Duration("drain_period", m.config.DrainPeriod),
+	)
+
+	timer := m.clock.NewTimer(m.config.DrainPeriod)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+	case <-ctx.Done():
+		m.logger.Warn("Drain period interrupted", zap.Error(ctx.Err()))
+	}
+}
+
 // hookGroup 钩子分组
 type hookGroup struct {
 	priority int
@@ -224,17 +297,12 @@ func (m *Manager) executeHookGroup(ctx context.Context, hooks []hookEntry) []hoo
 		go func(entry hookEntry) {
 			defer wg.Done()
 
-			start := time.Now()
-			hookCtx, cancel := context.WithTimeout(ctx, hookTimeout)
-			err := entry.hook(hookCtx)
-			cancel()
-			duration := time.Since(start)
-
-			errChan <- hookResult{
-				name:     entry.name,
-				err:      err,
-				duration: duration,
+			timeout := hookTimeout
+			if entry.timeout > 0 {
+				timeout = entry.timeout
 			}
+
+			errChan <- m.runHook(ctx, entry, timeout)
 		}(h)
 	}
 
@@ -263,24 +331,83 @@ loop:
 	return results
 }
 
+// runHook 执行单个钩子
+// 钩子在独立 goroutine 中运行：panic 被恢复为错误，
+// 超时后立即返回，不再等待未响应 ctx 的钩子
+func (m *Manager) runHook(ctx context.Context, entry hookEntry, timeout time.Duration) hookResult {
+	start := m.clock.Now()
+	hookCtx, cancel := clock.WithTimeout(ctx, m.clock, timeout)
+	defer cancel()
+
+	done := make(chan hookResult, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				m.logger.Error("Shutdown hook panic recovered",
+					zap.String("name", entry.name),
+					zap.Any("panic", r),
+					zap.Stack("stack"),
+				)
+				done <- hookResult{err: fmt.Errorf("shutdown hook panic: %v", r), panicked: true}
+			}
+		}()
+		done <- hookResult{err: entry.hook(hookCtx)}
+	}()
+
+	var result hookResult
+	select {
+	case result = <-done:
+		if result.err != nil && hookCtx.Err() == context.DeadlineExceeded && ctx.Err() == nil {
+			result.timedOut = true
+		}
+	case <-hookCtx.Done():
+		result = hookResult{err: hookCtx.Err(), timedOut: ctx.Err() == nil}
+	}
+
+	result.name = entry.name
+	result.timeout = timeout
+	result.duration = m.clock.Since(start)
+	return result
+}
+
 // hookResult 钩子执行结果
 type hookResult struct {
 	name     string
 	err      error
 	duration time.Duration
+	timeout  time.Duration
+	timedOut bool
+	panicked bool
 }
 
 // reportResults 报告关停结果
 func (m *Manager) reportResults(results []hookResult) {
 	successCount := 0
+	timeoutCount := 0
+	panicCount := 0
 	for _, result := range results {
-		if result.err != nil {
+		switch {
+		case result.timedOut:
+			timeoutCount++
+			m.logger.Error("Shutdown hook timed out",
+				zap.String("name", result.name),
+				zap.Duration("timeout", result.timeout),
+				zap.Duration("duration", result.duration),
+			)
+		case result.panicked:
+			panicCount++
+			m.logger.Error("Shutdown hook panicked",
+				zap.String("name", result.name),
+				zap.Duration("duration", result.duration),
+				zap.Error(result.err),
+			)
+		case result.err != nil:
 			m.logger.Error("Shutdown hook failed",
 				zap.String("name", result.name),
 				zap.Duration("duration", result.duration),
 				zap.Error(result.err),
 			)
-		} else {
+		default:
 			m.logger.Info("Shutdown hook completed",
 				zap.String("name", result.name),
 				zap.Duration("duration", result.duration),
@@ -291,6 +418,9 @@ func (m *Manager) reportResults(results []hookResult) {
 
 	m.logger.Info("Shutdown summary",
 		zap.Int("succeeded", successCount),
+		zap.Int("failed", len(results)-successCount-timeoutCount-panicCount),
+		zap.Int("timed_out", timeoutCount),
+		zap.Int("panicked", panicCount),
 		zap.Int("total", len(results)),
 	)
 }
@@ -6,9 +6,23 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aisgo/ais-go-pkg/clock"
 	"github.com/aisgo/ais-go-pkg/logger"
 )
 
+// fakeReadiness 记录就绪状态
+type fakeReadiness struct{ ready atomic.Bool }
+
+func newFakeReadiness() *fakeReadiness {
+	r := &fakeReadiness{}
+	r.ready.Store(true)
+	return r
+}
+
+func (r *fakeReadiness) SetReady(ready bool) { r.ready.Store(ready) }
+
+func (r *fakeReadiness) IsReady() bool { return r.ready.Load() }
+
 func TestShutdownHookTimeout(t *testing.T) {
 	m := NewManager(ManagerParams{
 		Logger: logger.NewNop(),
@@ -40,3 +54,147 @@ func TestShutdownHookTimeout(t *testing.T) {
 		t.Fatalf("shutdown took too long: %v", elapsed)
 	}
 }
+
+func TestPriorityConfigOverride(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Priorities = PriorityConfig{MQProducer: Priority(PriorityLow), Database: Priority(PriorityFirst)}
+
+	m := NewManager(ManagerParams{Logger: logger.NewNop(), Config: cfg})
+	p := m.Priorities()
+	if *p.MQProducer != PriorityLow {
+		t.Fatalf("unexpected producer priority: %d", *p.MQProducer)
+	}
+	// PriorityFirst (0) 是合法的显式配置，不应被默认值覆盖
+	if *p.Database != PriorityFirst {
+		t.Fatalf("unexpected database priority: %d", *p.Database)
+	}
+	if *p.MQConsumer != PriorityHigh || *p.Redis != PriorityLast {
+		t.Fatalf("unexpected default priorities: %+v", p)
+	}
+}
+
+func TestShutdownHookPanicIsolated(t *testing.T) {
+	m := NewManager(ManagerParams{Logger: logger.NewNop()})
+
+	var afterCalled atomic.Bool
+	m.RegisterHookWithPriority("panic", func(ctx context.Context) error {
+		panic("boom")
+	}, PriorityFirst)
+	m.RegisterHookWithPriority("after", func(ctx context.Context) error {
+		afterCalled.Store(true)
+		return nil
+	}, PriorityNormal)
+
+	m.Shutdown(context.Background())
+
+	if !afterCalled.Load() {
+		t.Fatalf("hook after panic not executed")
+	}
+}
+
+func TestShutdownPerHookTimeoutOption(t *testing.T) {
+	m := NewManager(ManagerParams{
+		Logger: logger.NewNop(),
+		Config: &Config{Timeout: 5 * time.Second, HookTimeout: 5 * time.Second},
+	})
+
+	// 钩子忽略 ctx，依赖单独超时提前返回
+	block := make(chan struct{})
+	defer close(block)
+	m.RegisterHookWithPriority("stuck", func(ctx context.Context) error {
+		<-block
+		return nil
+	}, PriorityFirst, HookOptions{Timeout: 50 * time.Millisecond})
+
+	var nextCalled atomic.Bool
+	m.RegisterHookWithPriority("next", func(ctx context.Context) error {
+		nextCalled.Store(true)
+		return nil
+	}, PriorityNormal)
+
+	start := time.Now()
+	m.Shutdown(context.Background())
+
+	if !nextCalled.Load() {
+		t.Fatalf("next hook not executed")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("shutdown took too long: %v", elapsed)
+	}
+}
+
+func TestRunHookReportsTimeout(t *testing.T) {
+	m := NewManager(ManagerParams{Logger: logger.NewNop()})
+
+	result := m.runHook(context.Background(), hookEntry{
+		name: "slow",
+		hook: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}, 10*time.Millisecond)
+
+	if !result.timedOut {
+		t.Fatalf("expected timed out result: %+v", result)
+	}
+}
+
+func TestShutdownDrainPeriodFlipsReadiness(t *testing.T) {
+	readiness := newFakeReadiness()
+	m := NewManager(ManagerParams{
+		Logger:    logger.NewNop(),
+		Config:    &Config{Timeout: time.Second, HookTimeout: time.Second, DrainPeriod: 50 * time.Millisecond},
+		Readiness: readiness,
+	})
+
+	var readyDuringHook atomic.Bool
+	m.RegisterHook("check", func(ctx context.Context) error {
+		readyDuringHook.Store(readiness.IsReady())
+		return nil
+	})
+
+	start := time.Now()
+	m.Shutdown(context.Background())
+
+	if time.Since(start) < 50*time.Millisecond {
+		t.Fatalf("drain period not applied")
+	}
+	if readiness.IsReady() || readyDuringHook.Load() {
+		t.Fatalf("expected readiness flipped before hooks")
+	}
+}
+
+func TestShutdownWithFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	m := NewManager(ManagerParams{
+		Logger:    logger.NewNop(),
+		Config:    &Config{Timeout: time.Hour, HookTimeout: 10 * time.Minute, DrainPeriod: time.Minute},
+		Readiness: newFakeReadiness(),
+		Clock:     fake,
+	})
+
+	hookErr := make(chan error, 1)
+	m.RegisterHook("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		hookErr <- ctx.Err()
+		return ctx.Err()
+	})
+
+	go m.Shutdown(context.Background())
+
+	// 排空期定时器
+	fake.BlockUntil(1)
+	fake.Advance(time.Minute)
+	// 全局超时 + 钩子超时
+	fake.BlockUntil(2)
+	fake.Advance(10 * time.Minute)
+
+	select {
+	case <-m.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatalf("shutdown did not complete after advancing fake clock")
+	}
+	if err := <-hookErr; err != context.DeadlineExceeded {
+		t.Fatalf("expected hook deadline exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"github.com/aisgo/ais-go-pkg/logger"
+
+	"go.uber.org/fx"
+)
+
+/* ========================================================================
+ * Storage Module
+ * ========================================================================
+ * 职责: 按 Config 提供 Bucket 依赖注入模块
+ * ======================================================================== */
+
+// Params 依赖注入参数
+type Params struct {
+	fx.In
+	Config Config
+	Logger *logger.Logger `optional:"true"`
+}
+
+// NewBucket 按配置创建存储桶
+func NewBucket(p Params) (Bucket, error) {
+	b, err := New(p.Config, p.Logger)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Module 对象存储模块
+// 提供: Bucket
+var Module = fx.Module("storage",
+	fx.Provide(NewBucket),
+)
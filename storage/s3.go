@@ -0,0 +1,547 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/httpclient"
+	"github.com/aisgo/ais-go-pkg/logger"
+)
+
+// Provider 存储服务提供方
+type Provider string
+
+const (
+	ProviderS3    Provider = "s3"
+	ProviderOSS   Provider = "oss"
+	ProviderMinIO Provider = "minio"
+)
+
+// DefaultPresignExpiry 默认预签名有效期
+const DefaultPresignExpiry = 15 * time.Minute
+
+// maxPresignExpiry SigV4 预签名最长有效期
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// Config 对象存储配置
+type Config struct {
+	Provider        Provider `yaml:"provider"`          // s3（默认）/ oss / minio
+	Endpoint        string   `yaml:"endpoint"`          // 服务地址，s3/oss 按区域默认，minio 必填
+	Region          string   `yaml:"region"`            // 签名区域，s3/minio 默认 us-east-1，oss 默认 cn-hangzhou
+	Bucket          string   `yaml:"bucket"`            // 存储桶
+	AccessKeyID     string   `yaml:"access_key_id"`     // 访问密钥 ID
+	SecretAccessKey string   `yaml:"secret_access_key"` // 访问密钥
+	SessionToken    string   `yaml:"session_token"`     // STS 临时凭证
+	PathStyle       *bool    `yaml:"path_style"`        // 路径风格寻址，minio 默认 true，其余默认 false
+
+	Encryption    Encryption    `yaml:"encryption"`     // 默认服务端加密
+	PresignExpiry time.Duration `yaml:"presign_expiry"` // 预签名默认有效期，默认 15m
+
+	HTTP httpclient.Config `yaml:"http"` // HTTP 客户端配置，超时默认 5m
+}
+
+func (c Config) withDefaults() Config {
+	if c.Provider == "" {
+		c.Provider = ProviderS3
+	}
+	if c.Region == "" {
+		if c.Provider == ProviderOSS {
+			c.Region = "cn-hangzhou"
+		} else {
+			c.Region = "us-east-1"
+		}
+	}
+	if c.Endpoint == "" {
+		switch c.Provider {
+		case ProviderS3:
+			c.Endpoint = "https://s3." + c.Region + ".amazonaws.com"
+		case ProviderOSS:
+			c.Endpoint = "https://oss-" + c.Region + ".aliyuncs.com"
+		}
+	}
+	if c.PathStyle == nil {
+		pathStyle := c.Provider == ProviderMinIO
+		c.PathStyle = &pathStyle
+	}
+	if c.PresignExpiry <= 0 {
+		c.PresignExpiry = DefaultPresignExpiry
+	}
+	if c.HTTP.Timeout <= 0 {
+		c.HTTP.Timeout = 5 * time.Minute
+	}
+	return c
+}
+
+// validate 校验配置
+func (c Config) validate() error {
+	switch c.Provider {
+	case ProviderS3, ProviderOSS, ProviderMinIO:
+	default:
+		return fmt.Errorf("storage: unsupported provider %q", c.Provider)
+	}
+	if c.Endpoint == "" {
+		return fmt.Errorf("storage: endpoint is required for %s", c.Provider)
+	}
+	if c.Bucket == "" {
+		return fmt.Errorf("storage: bucket is required")
+	}
+	if c.AccessKeyID == "" || c.SecretAccessKey == "" {
+		return fmt.Errorf("storage: access key is required")
+	}
+	return c.Encryption.validate()
+}
+
+// S3Bucket 基于 S3 协议的存储桶（AWS S3 / OSS / MinIO）
+type S3Bucket struct {
+	cfg    Config
+	base   *url.URL
+	cred   credentials
+	client *httpclient.Client
+	now    func() time.Time
+}
+
+var _ Bucket = (*S3Bucket)(nil)
+
+// New 创建存储桶客户端
+func New(cfg Config, log *logger.Logger, opts ...httpclient.Option) (*S3Bucket, error) {
+	cfg = cfg.withDefaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	base, err := url.Parse(strings.TrimRight(cfg.Endpoint, "/"))
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("storage: invalid endpoint %q", cfg.Endpoint)
+	}
+	if !*cfg.PathStyle {
+		base.Host = cfg.Bucket + "." + base.Host
+	}
+
+	b := &S3Bucket{
+		cfg:  cfg,
+		base: base,
+		cred: credentials{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			SessionToken:    cfg.SessionToken,
+			Region:          cfg.Region,
+		},
+		now: time.Now,
+	}
+	// 每次发送（含重试）前重新签名
+	opts = append([]httpclient.Option{httpclient.WithSigner(httpclient.SignerFunc(func(req *http.Request) error {
+		signRequest(req, b.cred, b.now())
+		return nil
+	}))}, opts...)
+	b.client = httpclient.New(cfg.HTTP, log, opts...)
+	return b, nil
+}
+
+// Name 实现 Bucket
+func (b *S3Bucket) Name() string {
+	return b.cfg.Bucket
+}
+
+// objectURL 构造对象 URL
+func (b *S3Bucket) objectURL(key string, query url.Values) *url.URL {
+	u := *b.base
+	prefix := u.Path
+	if *b.cfg.PathStyle {
+		prefix += "/" + b.cfg.Bucket
+	}
+	u.Path = prefix + "/" + key
+	u.RawPath = uriEncode(prefix, false) + "/" + uriEncode(key, false)
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+// encryption 请求加密配置，未指定时使用默认配置
+func (b *S3Bucket) encryption(enc *Encryption) *Encryption {
+	if enc != nil {
+		return enc
+	}
+	if b.cfg.Encryption.Type == SSENone {
+		return nil
+	}
+	return &b.cfg.Encryption
+}
+
+// do 发送请求，非 2xx 响应解析为 *Error；调用方负责关闭响应体
+func (b *S3Bucket) do(ctx context.Context, method string, u *url.URL, header http.Header, body io.Reader, size int64, payloadHash string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil && size >= 0 {
+		req.ContentLength = size
+	}
+	if payloadHash == "" {
+		payloadHash = emptyPayloadHash
+	}
+	req.Header.Set(headerContentSHA256, payloadHash)
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("storage: %s %s: %w", method, u.Path, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, parseError(resp)
+	}
+	return resp, nil
+}
+
+// doXML 发送请求并解析 XML 响应；out 为 nil 时丢弃响应体
+func (b *S3Bucket) doXML(ctx context.Context, method string, u *url.URL, header http.Header, body []byte, out any) error {
+	var reader io.Reader
+	hash := emptyPayloadHash
+	if body != nil {
+		reader = bytes.NewReader(body)
+		sum := sha256.Sum256(body)
+		hash = hex.EncodeToString(sum[:])
+	}
+	resp, err := b.do(ctx, method, u, header, reader, int64(len(body)), hash)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return fmt.Errorf("storage: read response: %w", err)
+	}
+	// CompleteMultipartUpload 可能以 200 返回错误
+	if bytes.Contains(data[:min(len(data), 256)], []byte("<Error>")) {
+		return parseErrorBody(resp.StatusCode, data)
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := xml.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("storage: decode response: %w", err)
+	}
+	return nil
+}
+
+// Put 实现 Bucket
+func (b *S3Bucket) Put(ctx context.Context, key string, r io.Reader, size int64, opts *PutOptions) (*ObjectInfo, error) {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+	if err := opts.Encryption.validate(); err != nil {
+		return nil, err
+	}
+	if size < 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("storage: read body: %w", err)
+		}
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	header := b.putHeaders(opts)
+	resp, err := b.do(ctx, http.MethodPut, b.objectURL(key, nil), header, r, size, unsignedPayload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	return &ObjectInfo{
+		Key:         key,
+		Size:        size,
+		ETag:        resp.Header.Get("ETag"),
+		ContentType: opts.ContentType,
+		Metadata:    opts.Metadata,
+	}, nil
+}
+
+// putHeaders 上传请求头
+func (b *S3Bucket) putHeaders(opts *PutOptions) http.Header {
+	header := http.Header{}
+	if opts.ContentType != "" {
+		header.Set("Content-Type", opts.ContentType)
+	}
+	if opts.ContentDisposition != "" {
+		header.Set("Content-Disposition", opts.ContentDisposition)
+	}
+	if opts.CacheControl != "" {
+		header.Set("Cache-Control", opts.CacheControl)
+	}
+	for k, v := range opts.Metadata {
+		header.Set("X-Amz-Meta-"+k, v)
+	}
+	b.encryption(opts.Encryption).setHeaders(header, true)
+	return header
+}
+
+// Get 实现 Bucket
+func (b *S3Bucket) Get(ctx context.Context, key string, opts *GetOptions) (*Object, error) {
+	resp, err := b.do(ctx, http.MethodGet, b.objectURL(key, nil), b.getHeaders(opts), nil, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	return &Object{ObjectInfo: objectInfo(key, resp), Body: resp.Body}, nil
+}
+
+// Stat 实现 Bucket
+func (b *S3Bucket) Stat(ctx context.Context, key string, opts *GetOptions) (*ObjectInfo, error) {
+	resp, err := b.do(ctx, http.MethodHead, b.objectURL(key, nil), b.getHeaders(opts), nil, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	info := objectInfo(key, resp)
+	return &info, nil
+}
+
+// getHeaders 读取请求头
+func (b *S3Bucket) getHeaders(opts *GetOptions) http.Header {
+	header := http.Header{}
+	if opts == nil {
+		opts = &GetOptions{}
+	}
+	if opts.Range != "" {
+		header.Set("Range", opts.Range)
+	}
+	enc := opts.Encryption
+	if enc == nil && b.cfg.Encryption.Type == SSEC {
+		enc = &b.cfg.Encryption
+	}
+	enc.setHeaders(header, false)
+	return header
+}
+
+// Delete 实现 Bucket
+func (b *S3Bucket) Delete(ctx context.Context, key string) error {
+	resp, err := b.do(ctx, http.MethodDelete, b.objectURL(key, nil), nil, nil, 0, "")
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// listBucketResult ListObjectsV2 响应
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		LastModified time.Time `xml:"LastModified"`
+		ETag         string    `xml:"ETag"`
+		Size         int64     `xml:"Size"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+}
+
+// List 实现 Bucket（ListObjectsV2）
+func (b *S3Bucket) List(ctx context.Context, opts ListOptions) (*ListResult, error) {
+	query := url.Values{"list-type": {"2"}}
+	if opts.Prefix != "" {
+		query.Set("prefix", opts.Prefix)
+	}
+	if opts.Delimiter != "" {
+		query.Set("delimiter", opts.Delimiter)
+	}
+	if opts.ContinuationToken != "" {
+		query.Set("continuation-token", opts.ContinuationToken)
+	}
+	if opts.MaxKeys > 0 {
+		query.Set("max-keys", strconv.Itoa(opts.MaxKeys))
+	}
+
+	u := b.objectURL("", query)
+	if *b.cfg.PathStyle {
+		// 存储桶级请求路径不带结尾斜杠
+		u.Path = strings.TrimSuffix(u.Path, "/")
+		u.RawPath = strings.TrimSuffix(u.RawPath, "/")
+	}
+	var out listBucketResult
+	if err := b.doXML(ctx, http.MethodGet, u, nil, nil, &out); err != nil {
+		return nil, err
+	}
+
+	res := &ListResult{Truncated: out.IsTruncated, NextToken: out.NextContinuationToken}
+	for _, c := range out.Contents {
+		res.Objects = append(res.Objects, ObjectInfo{Key: c.Key, Size: c.Size, ETag: c.ETag, LastModified: c.LastModified})
+	}
+	for _, p := range out.CommonPrefixes {
+		res.CommonPrefixes = append(res.CommonPrefixes, p.Prefix)
+	}
+	return res, nil
+}
+
+// presignExpiry 校正预签名有效期
+func (b *S3Bucket) presignExpiry(expires time.Duration) time.Duration {
+	if expires <= 0 {
+		return b.cfg.PresignExpiry
+	}
+	return min(expires, maxPresignExpiry)
+}
+
+// PresignGet 实现 Bucket
+func (b *S3Bucket) PresignGet(_ context.Context, key string, expires time.Duration) (string, error) {
+	return presignURL(http.MethodGet, b.objectURL(key, nil), nil, b.cred, b.now(), b.presignExpiry(expires)), nil
+}
+
+// PresignPut 实现 Bucket
+func (b *S3Bucket) PresignPut(_ context.Context, key string, expires time.Duration, opts *PutOptions) (*PresignedRequest, error) {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+	if err := opts.Encryption.validate(); err != nil {
+		return nil, err
+	}
+	expires = b.presignExpiry(expires)
+	now := b.now()
+	header := b.putHeaders(opts)
+	return &PresignedRequest{
+		Method:  http.MethodPut,
+		URL:     presignURL(http.MethodPut, b.objectURL(key, nil), header, b.cred, now, expires),
+		Header:  header,
+		Expires: now.Add(expires),
+	}, nil
+}
+
+// CreateMultipartUpload 实现 Bucket
+func (b *S3Bucket) CreateMultipartUpload(ctx context.Context, key string, opts *PutOptions) (string, error) {
+	if opts == nil {
+		opts = &PutOptions{}
+	}
+	if err := opts.Encryption.validate(); err != nil {
+		return "", err
+	}
+	var out struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := b.doXML(ctx, http.MethodPost, b.objectURL(key, url.Values{"uploads": {""}}), b.putHeaders(opts), nil, &out); err != nil {
+		return "", err
+	}
+	if out.UploadID == "" {
+		return "", fmt.Errorf("storage: empty upload id")
+	}
+	return out.UploadID, nil
+}
+
+func partQuery(uploadID string, partNumber int) url.Values {
+	return url.Values{"partNumber": {strconv.Itoa(partNumber)}, "uploadId": {uploadID}}
+}
+
+// UploadPart 实现 Bucket
+func (b *S3Bucket) UploadPart(ctx context.Context, key, uploadID string, partNumber int, r io.Reader, size int64, enc *Encryption) (Part, error) {
+	header := http.Header{}
+	if enc == nil && b.cfg.Encryption.Type == SSEC {
+		enc = &b.cfg.Encryption
+	}
+	if enc != nil && enc.Type == SSEC {
+		enc.setHeaders(header, false)
+	}
+	resp, err := b.do(ctx, http.MethodPut, b.objectURL(key, partQuery(uploadID, partNumber)), header, r, size, unsignedPayload)
+	if err != nil {
+		return Part{}, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return Part{PartNumber: partNumber, ETag: resp.Header.Get("ETag")}, nil
+}
+
+// PresignUploadPart 实现 Bucket
+func (b *S3Bucket) PresignUploadPart(_ context.Context, key, uploadID string, partNumber int, expires time.Duration) (string, error) {
+	u := b.objectURL(key, partQuery(uploadID, partNumber))
+	return presignURL(http.MethodPut, u, nil, b.cred, b.now(), b.presignExpiry(expires)), nil
+}
+
+// completeMultipartUpload 合并分片请求体
+type completeMultipartUpload struct {
+	XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+	Parts   []completedPart `xml:"Part"`
+}
+
+type completedPart struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// CompleteMultipartUpload 实现 Bucket
+func (b *S3Bucket) CompleteMultipartUpload(ctx context.Context, key, uploadID string, parts []Part) (*ObjectInfo, error) {
+	var body completeMultipartUpload
+	for _, p := range parts {
+		body.Parts = append(body.Parts, completedPart{PartNumber: p.PartNumber, ETag: p.ETag})
+	}
+	data, err := xml.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var out struct {
+		ETag string `xml:"ETag"`
+	}
+	if err := b.doXML(ctx, http.MethodPost, b.objectURL(key, url.Values{"uploadId": {uploadID}}), nil, data, &out); err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{Key: key, ETag: out.ETag}, nil
+}
+
+// AbortMultipartUpload 实现 Bucket
+func (b *S3Bucket) AbortMultipartUpload(ctx context.Context, key, uploadID string) error {
+	return b.doXML(ctx, http.MethodDelete, b.objectURL(key, url.Values{"uploadId": {uploadID}}), nil, nil, nil)
+}
+
+// objectInfo 从响应头解析对象元信息
+func objectInfo(key string, resp *http.Response) ObjectInfo {
+	info := ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ETag:        resp.Header.Get("ETag"),
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = t
+	}
+	for k, v := range resp.Header {
+		if name, ok := strings.CutPrefix(k, "X-Amz-Meta-"); ok && len(v) > 0 {
+			if info.Metadata == nil {
+				info.Metadata = make(map[string]string)
+			}
+			info.Metadata[strings.ToLower(name)] = v[0]
+		}
+	}
+	return info
+}
+
+// parseError 解析错误响应
+func parseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	return parseErrorBody(resp.StatusCode, data)
+}
+
+func parseErrorBody(status int, data []byte) error {
+	var body struct {
+		Code      string `xml:"Code"`
+		Message   string `xml:"Message"`
+		RequestID string `xml:"RequestId"`
+	}
+	_ = xml.Unmarshal(data, &body)
+	if body.Code == "" {
+		body.Code = http.StatusText(status)
+	}
+	return &Error{StatusCode: status, Code: body.Code, Message: body.Message, RequestID: body.RequestID}
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/* ========================================================================
+ * AWS Signature V4
+ * ========================================================================
+ * 参考: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-authenticating-requests.html
+ * 说明:
+ *   - 请求头签名: host、range、content-type、content-md5 与全部 x-amz-* 头参与签名
+ *   - 预签名: 查询串携带签名，请求体为 UNSIGNED-PAYLOAD
+ * ======================================================================== */
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4Service    = "s3"
+	sigV4TimeFormat = "20060102T150405Z"
+	sigV4DateFormat = "20060102"
+
+	// unsignedPayload 不对请求体做哈希（流式上传 / 预签名）
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	// emptyPayloadHash 空请求体 SHA256
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	headerContentSHA256 = "X-Amz-Content-Sha256"
+	headerDate          = "X-Amz-Date"
+	headerSecurityToken = "X-Amz-Security-Token"
+)
+
+// credentials 签名凭证
+type credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string
+}
+
+// signRequest 以请求头方式签名；调用前需设置 X-Amz-Content-Sha256（为空时视为空请求体）
+func signRequest(req *http.Request, cred credentials, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(sigV4TimeFormat)
+	req.Header.Set(headerDate, amzDate)
+	if req.Header.Get(headerContentSHA256) == "" {
+		req.Header.Set(headerContentSHA256, emptyPayloadHash)
+	}
+	if cred.SessionToken != "" {
+		req.Header.Set(headerSecurityToken, cred.SessionToken)
+	}
+
+	signed, canonicalHeaders := canonicalizeHeaders(req)
+	canonical := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signed,
+		req.Header.Get(headerContentSHA256),
+	}, "\n")
+
+	scope := credentialScope(now, cred.Region)
+	signature := sign(cred, now, stringToSign(amzDate, scope, canonical))
+	req.Header.Set("Authorization", sigV4Algorithm+
+		" Credential="+cred.AccessKeyID+"/"+scope+
+		",SignedHeaders="+signed+
+		",Signature="+signature)
+}
+
+// presignURL 生成预签名 URL，header 中的请求头一并参与签名（客户端需原样携带）
+func presignURL(method string, u *url.URL, header http.Header, cred credentials, now time.Time, expires time.Duration) string {
+	now = now.UTC()
+	amzDate := now.Format(sigV4TimeFormat)
+	scope := credentialScope(now, cred.Region)
+
+	req := &http.Request{Method: method, URL: u, Host: u.Host, Header: header.Clone()}
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+	signed, canonicalHeaders := canonicalizeHeaders(req)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigV4Algorithm)
+	query.Set("X-Amz-Credential", cred.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(expires/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", signed)
+	if cred.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", cred.SessionToken)
+	}
+
+	canonical := strings.Join([]string{
+		method,
+		canonicalURI(u),
+		canonicalQuery(query),
+		canonicalHeaders,
+		signed,
+		unsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", sign(cred, now, stringToSign(amzDate, scope, canonical)))
+
+	out := *u
+	out.RawQuery = canonicalQuery(query)
+	return out.String()
+}
+
+// canonicalizeHeaders 返回参与签名的头名列表与规范化头
+func canonicalizeHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	values := map[string]string{"host": host}
+	for k, v := range req.Header {
+		name := strings.ToLower(k)
+		switch {
+		case name == "range", name == "content-type", name == "content-md5", strings.HasPrefix(name, "x-amz-"):
+			trimmed := make([]string, len(v))
+			for i, s := range v {
+				trimmed[i] = strings.Join(strings.Fields(s), " ")
+			}
+			values[name] = strings.Join(trimmed, ",")
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(values[name])
+		b.WriteByte('\n')
+	}
+	return strings.Join(names, ";"), b.String()
+}
+
+// canonicalURI 规范化路径（S3 不做二次编码）
+func canonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// canonicalQuery 规范化查询串：按键排序，键值均按 RFC 3986 编码
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		vs := append([]string(nil), query[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode 按 SigV4 规则编码：保留 A-Za-z0-9-._~，encodeSlash 为 false 时保留 '/'
+func uriEncode(s string, encodeSlash bool) string {
+	const hexChars = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteByte(hexChars[c>>4])
+			b.WriteByte(hexChars[c&0x0f])
+		}
+	}
+	return b.String()
+}
+
+func credentialScope(now time.Time, region string) string {
+	return now.Format(sigV4DateFormat) + "/" + region + "/" + sigV4Service + "/aws4_request"
+}
+
+func stringToSign(amzDate, scope, canonical string) string {
+	sum := sha256.Sum256([]byte(canonical))
+	return sigV4Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+}
+
+// sign 派生签名密钥并计算签名
+func sign(cred credentials, now time.Time, toSign string) string {
+	key := hmacSHA256([]byte("AWS4"+cred.SecretAccessKey), now.Format(sigV4DateFormat))
+	key = hmacSHA256(key, cred.Region)
+	key = hmacSHA256(key, sigV4Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, toSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,160 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+const (
+	// MinPartSize 分片最小大小（最后一片除外）
+	MinPartSize = 5 << 20
+	// DefaultPartSize 默认分片大小
+	DefaultPartSize = 8 << 20
+	// DefaultUploadConcurrency 默认并发上传分片数
+	DefaultUploadConcurrency = 4
+	// maxParts 分片数量上限
+	maxParts = 10000
+)
+
+// UploadOptions 自动分片上传选项
+type UploadOptions struct {
+	PutOptions
+	PartSize    int64 // 分片大小，默认 8MiB，最小 5MiB
+	Concurrency int   // 并发上传分片数，默认 4
+}
+
+func (o UploadOptions) withDefaults() UploadOptions {
+	if o.PartSize <= 0 {
+		o.PartSize = DefaultPartSize
+	}
+	o.PartSize = max(o.PartSize, MinPartSize)
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultUploadConcurrency
+	}
+	return o
+}
+
+// Upload 上传任意大小（含未知大小）的数据流
+// 不足一个分片时直接 Put，否则分片并发上传；任一分片失败时取消并清理分片上传
+// 内存占用约为 PartSize * (Concurrency + 1)
+func Upload(ctx context.Context, b Bucket, key string, r io.Reader, opts UploadOptions) (*ObjectInfo, error) {
+	opts = opts.withDefaults()
+
+	first, err := readPart(r, opts.PartSize)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(first)) < opts.PartSize {
+		return b.Put(ctx, key, bytes.NewReader(first), int64(len(first)), &opts.PutOptions)
+	}
+
+	uploadID, err := b.CreateMultipartUpload(ctx, key, &opts.PutOptions)
+	if err != nil {
+		return nil, err
+	}
+	info, err := uploadParts(ctx, b, key, uploadID, first, r, opts)
+	if err != nil {
+		// 使用独立 ctx 清理，避免调用方 ctx 已取消导致分片残留
+		if aerr := b.AbortMultipartUpload(context.WithoutCancel(ctx), key, uploadID); aerr != nil {
+			err = errors.Join(err, fmt.Errorf("storage: abort multipart upload: %w", aerr))
+		}
+		return nil, err
+	}
+	return info, nil
+}
+
+// uploadParts 并发上传分片并合并
+func uploadParts(ctx context.Context, b Bucket, key, uploadID string, first []byte, r io.Reader, opts UploadOptions) (*ObjectInfo, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		parts    []Part
+		firstErr error
+		wg       sync.WaitGroup
+		sem      = make(chan struct{}, opts.Concurrency)
+		size     int64
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	data := first
+	for partNumber := 1; ; partNumber++ {
+		if partNumber > maxParts {
+			fail(fmt.Errorf("storage: too many parts, increase PartSize"))
+			break
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		size += int64(len(data))
+		wg.Add(1)
+		go func(n int, data []byte) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			part, err := b.UploadPart(ctx, key, uploadID, n, bytes.NewReader(data), int64(len(data)), opts.Encryption)
+			if err != nil {
+				fail(fmt.Errorf("storage: upload part %d: %w", n, err))
+				return
+			}
+			mu.Lock()
+			parts = append(parts, part)
+			mu.Unlock()
+		}(partNumber, data)
+
+		if int64(len(data)) < opts.PartSize {
+			break
+		}
+		next, err := readPart(r, opts.PartSize)
+		if err != nil {
+			fail(err)
+			break
+		}
+		if len(next) == 0 {
+			break
+		}
+		data = next
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	info, err := b.CompleteMultipartUpload(ctx, key, uploadID, parts)
+	if err != nil {
+		return nil, err
+	}
+	info.Size = size
+	return info, nil
+}
+
+// readPart 读取至多 size 字节
+func readPart(r io.Reader, size int64) ([]byte, error) {
+	buf := make([]byte, size)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, fmt.Errorf("storage: read body: %w", err)
+	}
+	return buf[:n], nil
+}
@@ -0,0 +1,193 @@
+package testkit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/google/uuid"
+	"github.com/testcontainers/testcontainers-go"
+	tckafka "github.com/testcontainers/testcontainers-go/modules/kafka"
+	tcmysql "github.com/testcontainers/testcontainers-go/modules/mysql"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	cacheredis "github.com/aisgo/ais-go-pkg/cache/redis"
+	"github.com/aisgo/ais-go-pkg/database/mysql"
+	"github.com/aisgo/ais-go-pkg/database/postgres"
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+/* ========================================================================
+ * 容器启动
+ * ========================================================================
+ * 职责: 按服务类型启动容器并填充对应 Config
+ * 说明: Postgres / Redis 使用通用容器 + 日志等待，MySQL / Kafka 使用官方模块
+ * ======================================================================== */
+
+// 测试数据库凭据
+const (
+	testUser     = "test"
+	testPassword = "test"
+	testDBName   = "test"
+)
+
+func (e *Env) start(ctx context.Context, svc Service) error {
+	switch svc.kind {
+	case kindPostgres:
+		return e.startPostgres(ctx, svc.image)
+	case kindMySQL:
+		return e.startMySQL(ctx, svc.image)
+	case kindRedis:
+		return e.startRedis(ctx, svc.image)
+	case kindKafka:
+		return e.startKafka(ctx, svc.image)
+	default:
+		return fmt.Errorf("unknown service %q", svc.kind)
+	}
+}
+
+func (e *Env) track(c testcontainers.Container) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.containers = append(e.containers, c)
+}
+
+func (e *Env) startPostgres(ctx context.Context, image string) error {
+	c, err := testcontainers.Run(ctx, image,
+		testcontainers.WithExposedPorts("5432/tcp"),
+		testcontainers.WithEnv(map[string]string{
+			"POSTGRES_USER":     testUser,
+			"POSTGRES_PASSWORD": testPassword,
+			"POSTGRES_DB":       testDBName,
+		}),
+		// 初始化阶段会重启一次，第二次就绪日志后才可连接
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").WithOccurrence(2),
+			wait.ForListeningPort("5432/tcp"),
+		),
+	)
+	if c != nil {
+		e.track(c)
+	}
+	if err != nil {
+		return err
+	}
+
+	host, port, err := endpoint(ctx, c, "5432/tcp")
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.postgres = &postgres.Config{
+		Host:     host,
+		Port:     port,
+		User:     testUser,
+		Password: testPassword,
+		DBName:   testDBName,
+		SSLMode:  "disable",
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *Env) startMySQL(ctx context.Context, image string) error {
+	c, err := tcmysql.Run(ctx, image,
+		tcmysql.WithDatabase(testDBName),
+		tcmysql.WithUsername(testUser),
+		tcmysql.WithPassword(testPassword),
+	)
+	if c != nil {
+		e.track(c)
+	}
+	if err != nil {
+		return err
+	}
+
+	host, port, err := endpoint(ctx, c, "3306/tcp")
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.mysql = &mysql.Config{
+		Host:      host,
+		Port:      port,
+		User:      testUser,
+		Password:  testPassword,
+		DBName:    testDBName,
+		Charset:   "utf8mb4",
+		ParseTime: true,
+		Loc:       "Local",
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *Env) startRedis(ctx context.Context, image string) error {
+	c, err := testcontainers.Run(ctx, image,
+		testcontainers.WithExposedPorts("6379/tcp"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("Ready to accept connections"),
+			wait.ForListeningPort("6379/tcp"),
+		),
+	)
+	if c != nil {
+		e.track(c)
+	}
+	if err != nil {
+		return err
+	}
+
+	host, port, err := endpoint(ctx, c, "6379/tcp")
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	e.redis = &cacheredis.Config{
+		Host:     host,
+		Port:     port,
+		PoolSize: 10,
+	}
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *Env) startKafka(ctx context.Context, image string) error {
+	c, err := tckafka.Run(ctx, image, tckafka.WithClusterID("ais-testkit"))
+	if c != nil {
+		e.track(c)
+	}
+	if err != nil {
+		return err
+	}
+
+	brokers, err := c.Brokers(ctx)
+	if err != nil {
+		return err
+	}
+
+	kafkaCfg := mq.DefaultKafkaConfig()
+	kafkaCfg.Brokers = brokers
+	// 每个测试包独立消费组，从最早位点消费，避免错过测试前发送的消息
+	kafkaCfg.Consumer.GroupID = "testkit-" + uuid.NewString()
+	kafkaCfg.Consumer.InitialOffset = "oldest"
+
+	e.mu.Lock()
+	e.kafka = &mq.Config{Type: mq.TypeKafka, Kafka: kafkaCfg}
+	e.mu.Unlock()
+	return nil
+}
+
+func endpoint(ctx context.Context, c testcontainers.Container, port nat.Port) (string, int, error) {
+	host, err := c.Host(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	mapped, err := c.MappedPort(ctx, port)
+	if err != nil {
+		return "", 0, err
+	}
+	return host, mapped.Int(), nil
+}
@@ -0,0 +1,299 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+
+	cacheredis "github.com/aisgo/ais-go-pkg/cache/redis"
+	"github.com/aisgo/ais-go-pkg/database/mysql"
+	"github.com/aisgo/ais-go-pkg/database/postgres"
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+/* ========================================================================
+ * Test Kit - 基于 testcontainers 的集成测试环境
+ * ========================================================================
+ * 职责: 启动 MySQL / Postgres / Redis / Kafka 容器，并生成可直接注入
+ *       本仓库模块的 Config（mysql.Config / postgres.Config /
+ *       cacheredis.Config / mq.Config）
+ * 语义:
+ *   - Main 在 TestMain 中启动容器，同一测试包内所有测试共享，m.Run 结束后销毁
+ *   - -short 模式或 Docker 不可用时不启动容器，访问 Config 的测试自动 Skip
+ *   - 容器启动失败时访问 Config 的测试 Fatal（环境问题不应被静默跳过）
+ *   - 各容器并行启动
+ *
+ * 使用示例:
+ *   func TestMain(m *testing.M) {
+ *       testkit.Main(m, testkit.Postgres(), testkit.Redis())
+ *   }
+ *
+ *   func TestOrderRepo(t *testing.T) {
+ *       cfg := testkit.PostgresConfig(t)
+ *       db, _ := postgres.NewDB(postgres.Params{Lc: lc, Config: cfg, Logger: log})
+ *   }
+ * ======================================================================== */
+
+// 容器启动超时
+const defaultStartupTimeout = 3 * time.Minute
+
+// =============================================================================
+// Service 定义
+// =============================================================================
+
+type serviceKind string
+
+const (
+	kindPostgres serviceKind = "postgres"
+	kindMySQL    serviceKind = "mysql"
+	kindRedis    serviceKind = "redis"
+	kindKafka    serviceKind = "kafka"
+)
+
+// Service 需要启动的容器服务
+type Service struct {
+	kind  serviceKind
+	image string
+}
+
+// Postgres Postgres 服务（默认镜像 postgres:16-alpine）
+func Postgres() Service {
+	return Service{kind: kindPostgres, image: "postgres:16-alpine"}
+}
+
+// MySQL MySQL 服务（默认镜像 mysql:8.0）
+func MySQL() Service {
+	return Service{kind: kindMySQL, image: "mysql:8.0"}
+}
+
+// Redis Redis 服务（默认镜像 redis:7-alpine）
+func Redis() Service {
+	return Service{kind: kindRedis, image: "redis:7-alpine"}
+}
+
+// Kafka Kafka 服务（默认镜像 confluentinc/cp-kafka:7.5.0，KRaft 模式）
+func Kafka() Service {
+	return Service{kind: kindKafka, image: "confluentinc/cp-kafka:7.5.0"}
+}
+
+// WithImage 覆盖默认镜像
+func (s Service) WithImage(image string) Service {
+	if image != "" {
+		s.image = image
+	}
+	return s
+}
+
+// =============================================================================
+// Env
+// =============================================================================
+
+// Env 已启动的容器环境
+type Env struct {
+	mu         sync.Mutex
+	containers []testcontainers.Container
+	skipReason string
+	errs       map[serviceKind]error
+
+	postgres *postgres.Config
+	mysql    *mysql.Config
+	redis    *cacheredis.Config
+	kafka    *mq.Config
+}
+
+// Start 并行启动容器
+// 部分容器启动失败时仍返回 Env（已启动的容器需调用 Close 释放），错误汇总返回
+func Start(ctx context.Context, services ...Service) (*Env, error) {
+	env := &Env{errs: make(map[serviceKind]error)}
+
+	ctx, cancel := context.WithTimeout(ctx, defaultStartupTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	for _, svc := range services {
+		wg.Add(1)
+		go func(svc Service) {
+			defer wg.Done()
+			if err := env.start(ctx, svc); err != nil {
+				env.mu.Lock()
+				env.errs[svc.kind] = fmt.Errorf("testkit: start %s (%s): %w", svc.kind, svc.image, err)
+				env.mu.Unlock()
+			}
+		}(svc)
+	}
+	wg.Wait()
+
+	errs := make([]error, 0, len(env.errs))
+	for _, err := range env.errs {
+		errs = append(errs, err)
+	}
+	return env, errors.Join(errs...)
+}
+
+// Close 销毁全部容器
+func (e *Env) Close(ctx context.Context) error {
+	e.mu.Lock()
+	containers := e.containers
+	e.containers = nil
+	e.mu.Unlock()
+
+	var errs []error
+	for _, c := range containers {
+		if err := testcontainers.TerminateContainer(c, testcontainers.StopContext(ctx)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// PostgresConfig 返回 Postgres 连接配置
+func (e *Env) PostgresConfig(t testing.TB) postgres.Config {
+	t.Helper()
+	e.require(t, kindPostgres, e.postgres != nil)
+	return *e.postgres
+}
+
+// MySQLConfig 返回 MySQL 连接配置
+func (e *Env) MySQLConfig(t testing.TB) mysql.Config {
+	t.Helper()
+	e.require(t, kindMySQL, e.mysql != nil)
+	return *e.mysql
+}
+
+// RedisConfig 返回 Redis 连接配置
+func (e *Env) RedisConfig(t testing.TB) cacheredis.Config {
+	t.Helper()
+	e.require(t, kindRedis, e.redis != nil)
+	return *e.redis
+}
+
+// KafkaConfig 返回 Kafka MQ 配置（每次调用返回独立副本，可按测试修改 GroupID 等）
+func (e *Env) KafkaConfig(t testing.TB) *mq.Config {
+	t.Helper()
+	e.require(t, kindKafka, e.kafka != nil)
+	kafkaCfg := *e.kafka.Kafka
+	kafkaCfg.Brokers = append([]string(nil), e.kafka.Kafka.Brokers...)
+	return &mq.Config{Type: mq.TypeKafka, Kafka: &kafkaCfg}
+}
+
+func (e *Env) require(t testing.TB, kind serviceKind, ready bool) {
+	t.Helper()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.skipReason != "" {
+		t.Skip(e.skipReason)
+	}
+	if err := e.errs[kind]; err != nil {
+		t.Fatal(err)
+	}
+	if !ready {
+		t.Fatalf("testkit: %s was not requested in testkit.Main/Start", kind)
+	}
+}
+
+// =============================================================================
+// TestMain 辅助
+// =============================================================================
+
+var (
+	sharedMu  sync.RWMutex
+	sharedEnv *Env
+)
+
+// Main 在 TestMain 中启动容器、运行测试并销毁容器，以测试结果退出进程
+func Main(m *testing.M, services ...Service) {
+	os.Exit(Run(m, services...))
+}
+
+// Run 与 Main 相同但返回退出码，便于在 TestMain 中追加其他初始化/清理
+func Run(m *testing.M, services ...Service) int {
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+
+	ctx := context.Background()
+	env := &Env{errs: make(map[serviceKind]error)}
+	switch {
+	case testing.Short():
+		env.skipReason = "testkit: skip integration test in short mode"
+	default:
+		if err := providerHealth(ctx); err != nil {
+			env.skipReason = fmt.Sprintf("testkit: docker is not available: %v", err)
+			break
+		}
+		var err error
+		env, err = Start(ctx, services...)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+
+	sharedMu.Lock()
+	sharedEnv = env
+	sharedMu.Unlock()
+
+	code := m.Run()
+
+	if err := env.Close(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	return code
+}
+
+// Shared 返回 Main/Run 启动的共享环境
+func Shared(t testing.TB) *Env {
+	t.Helper()
+	sharedMu.RLock()
+	defer sharedMu.RUnlock()
+	if sharedEnv == nil {
+		t.Fatal("testkit: call testkit.Main or testkit.Run from TestMain first")
+	}
+	return sharedEnv
+}
+
+// PostgresConfig 返回共享环境的 Postgres 配置
+func PostgresConfig(t testing.TB) postgres.Config {
+	t.Helper()
+	return Shared(t).PostgresConfig(t)
+}
+
+// MySQLConfig 返回共享环境的 MySQL 配置
+func MySQLConfig(t testing.TB) mysql.Config {
+	t.Helper()
+	return Shared(t).MySQLConfig(t)
+}
+
+// RedisConfig 返回共享环境的 Redis 配置
+func RedisConfig(t testing.TB) cacheredis.Config {
+	t.Helper()
+	return Shared(t).RedisConfig(t)
+}
+
+// KafkaConfig 返回共享环境的 Kafka MQ 配置
+func KafkaConfig(t testing.TB) *mq.Config {
+	t.Helper()
+	return Shared(t).KafkaConfig(t)
+}
+
+// providerHealth 检查 Docker 是否可用（testcontainers 在无 Docker 时可能 panic）
+func providerHealth(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	provider, err := testcontainers.ProviderDocker.GetProvider()
+	if err != nil {
+		return err
+	}
+	return provider.Health(ctx)
+}
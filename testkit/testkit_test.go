@@ -0,0 +1,40 @@
+package testkit
+
+import (
+	"context"
+	"testing"
+)
+
+func TestServiceWithImage(t *testing.T) {
+	svc := Postgres().WithImage("postgres:17")
+	if svc.kind != kindPostgres || svc.image != "postgres:17" {
+		t.Fatalf("unexpected service: %+v", svc)
+	}
+	if svc := Redis().WithImage(""); svc.image != "redis:7-alpine" {
+		t.Fatalf("empty image should keep default, got %q", svc.image)
+	}
+}
+
+func TestEnvSkipsWhenUnavailable(t *testing.T) {
+	env := &Env{skipReason: "docker is not available", errs: make(map[serviceKind]error)}
+
+	var skipped bool
+	t.Run("postgres", func(t *testing.T) {
+		defer func() { skipped = t.Skipped() }()
+		_ = env.PostgresConfig(t)
+		t.Fatalf("expected skip")
+	})
+	if !skipped {
+		t.Fatalf("expected config accessor to skip the test")
+	}
+}
+
+func TestStartUnknownService(t *testing.T) {
+	env, err := Start(context.Background(), Service{kind: "etcd", image: "etcd"})
+	if err == nil {
+		t.Fatalf("expected error for unknown service")
+	}
+	if err := env.Close(context.Background()); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
@@ -0,0 +1,283 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/aisgo/ais-go-pkg/ctxutil"
+	"github.com/aisgo/ais-go-pkg/resilience"
+)
+
+/* ========================================================================
+ * gRPC Client Resiliency - 客户端超时/重试/对冲/熔断
+ * ========================================================================
+ * 职责: 为 ClientFactory 创建的连接注入统一的一元调用策略
+ * 拦截器顺序: 超时 -> 熔断 -> 重试（或对冲）
+ * 说明:
+ *   - 超时: 调用方未设置 deadline 时使用默认超时，重试/对冲共享该 deadline
+ *   - 重试: 仅对配置的状态码重试（默认 UNAVAILABLE），指数退避 + 抖动
+ *   - 对冲: 超过 delay 未返回时并行发起新请求，先成功者胜出；仅对 methods 中列出的
+ *           幂等方法生效（未列出时不对冲），对冲的方法不再执行重试，其余方法按重试策略执行
+ *   - 熔断: 每个 target 一个熔断器，打开时直接返回 UNAVAILABLE
+ * 配置示例:
+ *   grpc:
+ *     client:
+ *       default:
+ *         timeout: 3s
+ *         retry: {max_attempts: 3, codes: [UNAVAILABLE]}
+ *         breaker: {enabled: true, failure_threshold: 5, open_timeout: 30s}
+ *       targets:
+ *         report-service:9090:
+ *           timeout: 30s
+ *           hedging:
+ *             max_attempts: 2
+ *             delay: 200ms
+ *             methods: [/report.v1.ReportService/GetReport, /report.v1.QueryService/*]
+ * ======================================================================== */
+
+// ClientConfig 客户端调用策略配置
+type ClientConfig struct {
+	Default ClientPolicy            `yaml:"default"` // 默认策略
+	Targets map[string]ClientPolicy `yaml:"targets"` // 按 target 覆盖（未设置的字段继承默认策略）
+}
+
+// ClientPolicy 单个 target 的调用策略
+type ClientPolicy struct {
+	Timeout time.Duration `yaml:"timeout"` // 默认调用超时，0 表示不设置
+	Retry   RetryPolicy   `yaml:"retry"`
+	Hedging HedgingPolicy `yaml:"hedging"`
+	Breaker BreakerPolicy `yaml:"breaker"`
+}
+
+// RetryPolicy 重试策略
+type RetryPolicy struct {
+	MaxAttempts int                      `yaml:"max_attempts"` // 总尝试次数（含首次），<= 1 表示不重试
+	Codes       []string                 `yaml:"codes"`        // 可重试状态码，默认 UNAVAILABLE
+	Backoff     resilience.BackoffConfig `yaml:"backoff"`
+}
+
+// HedgingPolicy 对冲策略
+type HedgingPolicy struct {
+	MaxAttempts int           `yaml:"max_attempts"` // 最大并行请求数，<= 1 表示不启用
+	Delay       time.Duration `yaml:"delay"`        // 发起下一个对冲请求前的等待时间，默认 100ms
+	// Methods 允许对冲的幂等方法全名（/pkg.Service/Method），"/pkg.Service/*" 匹配整个服务，为空时不对冲
+	Methods []string `yaml:"methods"`
+}
+
+// hedged 判断方法是否允许对冲
+func (p HedgingPolicy) hedged(method string) bool {
+	for _, m := range p.Methods {
+		if m == method {
+			return true
+		}
+		if service, ok := strings.CutSuffix(m, "*"); ok && strings.HasSuffix(service, "/") && strings.HasPrefix(method, service) {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakerPolicy 熔断策略
+type BreakerPolicy struct {
+	Enabled          bool          `yaml:"enabled"`
+	FailureThreshold int           `yaml:"failure_threshold"`
+	OpenTimeout      time.Duration `yaml:"open_timeout"`
+	HalfOpenMaxCalls int           `yaml:"half_open_max_calls"`
+}
+
+// policyFor 返回 target 的合并策略
+func (c ClientConfig) policyFor(target string) ClientPolicy {
+	p := c.Default
+	o, ok := c.Targets[target]
+	if !ok {
+		return p
+	}
+	if o.Timeout > 0 {
+		p.Timeout = o.Timeout
+	}
+	if o.Retry.MaxAttempts > 0 {
+		p.Retry = o.Retry
+	}
+	if o.Hedging.MaxAttempts > 0 {
+		p.Hedging = o.Hedging
+	}
+	if o.Breaker.Enabled {
+		p.Breaker = o.Breaker
+	}
+	return p
+}
+
+// interceptors 构建 target 的一元拦截器链
+func (p ClientPolicy) interceptors(target string) []grpc.UnaryClientInterceptor {
+	var chain []grpc.UnaryClientInterceptor
+	if p.Timeout > 0 {
+		chain = append(chain, timeoutInterceptor(p.Timeout))
+	}
+	if p.Breaker.Enabled {
+		chain = append(chain, breakerInterceptor(resilience.NewBreaker("grpc:"+target, resilience.BreakerConfig{
+			FailureThreshold: p.Breaker.FailureThreshold,
+			OpenTimeout:      p.Breaker.OpenTimeout,
+			HalfOpenMaxCalls: p.Breaker.HalfOpenMaxCalls,
+		})))
+	}
+	var retry grpc.UnaryClientInterceptor
+	if p.Retry.MaxAttempts > 1 {
+		retry = retryInterceptor(p.Retry)
+	}
+	switch {
+	case p.Hedging.MaxAttempts > 1 && len(p.Hedging.Methods) > 0:
+		chain = append(chain, hedgingInterceptor(p.Hedging, parseCodes(p.Retry.Codes), retry))
+	case retry != nil:
+		chain = append(chain, retry)
+	}
+	return chain
+}
+
+// parseCodes 解析状态码名称（如 UNAVAILABLE），默认 UNAVAILABLE
+func parseCodes(names []string) map[codes.Code]bool {
+	set := make(map[codes.Code]bool, len(names))
+	for _, name := range names {
+		var c codes.Code
+		if err := c.UnmarshalJSON([]byte(`"` + strings.ToUpper(strings.TrimSpace(name)) + `"`)); err == nil {
+			set[c] = true
+		}
+	}
+	if len(set) == 0 {
+		set[codes.Unavailable] = true
+	}
+	return set
+}
+
+// timeoutInterceptor 调用方未设置 deadline 时应用默认超时
+func timeoutInterceptor(d time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel := ctxutil.WithTimeoutIfNone(ctx, d)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// breakerFailure 计入熔断失败的状态码（业务错误不计入）
+func breakerFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown, codes.ResourceExhausted:
+		return true
+	}
+	return false
+}
+
+// breakerInterceptor 熔断拦截器
+func breakerInterceptor(b *resilience.Breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		done, err := b.Allow()
+		if err != nil {
+			return status.Errorf(codes.Unavailable, "%s: %v", b.Name(), err)
+		}
+		err = invoker(ctx, method, req, reply, cc, opts...)
+		done(!breakerFailure(err))
+		return err
+	}
+}
+
+// retryInterceptor 重试拦截器
+func retryInterceptor(p RetryPolicy) grpc.UnaryClientInterceptor {
+	retryable := parseCodes(p.Codes)
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var err error
+		for attempt := 0; attempt < p.MaxAttempts; attempt++ {
+			if attempt > 0 {
+				timer := time.NewTimer(p.Backoff.Delay(attempt - 1))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return err
+				case <-timer.C:
+				}
+			}
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil || !retryable[status.Code(err)] {
+				return err
+			}
+		}
+		return err
+	}
+}
+
+// hedgingInterceptor 对冲拦截器
+// nonFatal 中的状态码不会终止对冲，其余错误直接返回；未列入 Methods 的方法交给 fallback（为 nil 时只调用一次）
+func hedgingInterceptor(p HedgingPolicy, nonFatal map[codes.Code]bool, fallback grpc.UnaryClientInterceptor) grpc.UnaryClientInterceptor {
+	delay := p.Delay
+	if delay <= 0 {
+		delay = 100 * time.Millisecond
+	}
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !p.hedged(method) {
+			if fallback != nil {
+				return fallback(ctx, method, req, reply, cc, invoker, opts...)
+			}
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		replyMsg, ok := reply.(proto.Message)
+		if !ok {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type result struct {
+			reply proto.Message
+			err   error
+		}
+		results := make(chan result, p.MaxAttempts)
+		launched, failed := 0, 0
+		launch := func() {
+			launched++
+			go func() {
+				out := replyMsg.ProtoReflect().New().Interface()
+				results <- result{reply: out, err: invoker(ctx, method, req, out, cc, opts...)}
+			}()
+		}
+
+		launch()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		var lastErr error
+		for failed < launched {
+			select {
+			case <-timer.C:
+				if launched < p.MaxAttempts {
+					launch()
+					timer.Reset(delay)
+				}
+			case r := <-results:
+				if r.err == nil {
+					proto.Reset(replyMsg)
+					proto.Merge(replyMsg, r.reply)
+					return nil
+				}
+				failed++
+				lastErr = r.err
+				if !nonFatal[status.Code(r.err)] {
+					return r.err
+				}
+				// 非致命错误立即发起下一次对冲
+				if launched < p.MaxAttempts {
+					launch()
+					timer.Reset(delay)
+				}
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return lastErr
+	}
+}
@@ -0,0 +1,161 @@
+package grpc
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/resilience"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestClientPolicyOverride(t *testing.T) {
+	cfg := ClientConfig{
+		Default: ClientPolicy{Timeout: time.Second, Retry: RetryPolicy{MaxAttempts: 3}},
+		Targets: map[string]ClientPolicy{"slow:9090": {Timeout: 30 * time.Second}},
+	}
+	p := cfg.policyFor("slow:9090")
+	if p.Timeout != 30*time.Second || p.Retry.MaxAttempts != 3 {
+		t.Fatalf("unexpected merged policy: %+v", p)
+	}
+	if got := cfg.policyFor("other:9090").Timeout; got != time.Second {
+		t.Fatalf("expected default timeout, got %v", got)
+	}
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	interceptor := retryInterceptor(RetryPolicy{
+		MaxAttempts: 3,
+		Codes:       []string{"unavailable"},
+		Backoff:     resilience.BackoffConfig{Base: time.Millisecond, Max: time.Millisecond},
+	})
+
+	calls := 0
+	err := interceptor(context.Background(), "/test.Service/Method", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			calls++
+			if calls < 3 {
+				return status.Error(codes.Unavailable, "down")
+			}
+			return nil
+		})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected success after 3 calls, got err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	err = interceptor(context.Background(), "/test.Service/Method", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			calls++
+			return status.Error(codes.InvalidArgument, "bad")
+		})
+	if status.Code(err) != codes.InvalidArgument || calls != 1 {
+		t.Fatalf("expected no retry for non-retryable code, got err=%v calls=%d", err, calls)
+	}
+}
+
+func TestTimeoutAndBreakerInterceptors(t *testing.T) {
+	timeout := timeoutInterceptor(50 * time.Millisecond)
+	_ = timeout(context.Background(), "/m", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Fatalf("expected default deadline")
+			}
+			return nil
+		})
+
+	chain := ClientPolicy{Breaker: BreakerPolicy{Enabled: true, FailureThreshold: 2}}.interceptors("breaker-test")
+	if len(chain) != 1 {
+		t.Fatalf("expected breaker interceptor only, got %d", len(chain))
+	}
+	calls := 0
+	failing := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		calls++
+		return status.Error(codes.Unavailable, "down")
+	}
+	for range 3 {
+		_ = chain[0](context.Background(), "/m", nil, nil, nil, failing)
+	}
+	if calls != 2 {
+		t.Fatalf("expected breaker to short-circuit after 2 failures, got %d calls", calls)
+	}
+}
+
+func TestHedgingInterceptor(t *testing.T) {
+	interceptor := hedgingInterceptor(HedgingPolicy{MaxAttempts: 2, Delay: 10 * time.Millisecond, Methods: []string{"/m"}}, parseCodes(nil), nil)
+
+	var calls atomic.Int32
+	reply := &healthpb.HealthCheckResponse{}
+	err := interceptor(context.Background(), "/m", nil, reply, nil,
+		func(ctx context.Context, method string, req, out any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			if calls.Add(1) == 1 {
+				// 首个请求阻塞，对冲请求先返回
+				<-ctx.Done()
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			out.(*healthpb.HealthCheckResponse).Status = healthpb.HealthCheckResponse_SERVING
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("hedged call: %v", err)
+	}
+	if reply.Status != healthpb.HealthCheckResponse_SERVING || calls.Load() != 2 {
+		t.Fatalf("unexpected hedged result: status=%v calls=%d", reply.Status, calls.Load())
+	}
+}
+
+func TestHedgingOnlyListedMethods(t *testing.T) {
+	policy := ClientPolicy{
+		Retry:   RetryPolicy{MaxAttempts: 3},
+		Hedging: HedgingPolicy{MaxAttempts: 3, Delay: time.Millisecond, Methods: []string{"/svc.Query/Get", "/svc.Report/*"}},
+	}
+	chain := policy.interceptors("target")
+	if len(chain) != 1 {
+		t.Fatalf("chain length = %d", len(chain))
+	}
+
+	call := func(method string, fail bool) int32 {
+		var calls atomic.Int32
+		err := chain[0](context.Background(), method, nil, &healthpb.HealthCheckResponse{}, nil,
+			func(ctx context.Context, method string, req, out any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				calls.Add(1)
+				if fail {
+					return status.Error(codes.Unavailable, "down")
+				}
+				// 慢请求：允许对冲时会触发并行请求
+				select {
+				case <-ctx.Done():
+					return status.FromContextError(ctx.Err()).Err()
+				case <-time.After(20 * time.Millisecond):
+					return nil
+				}
+			})
+		if err != nil && !fail {
+			t.Fatalf("%s: %v", method, err)
+		}
+		return calls.Load()
+	}
+
+	if got := call("/svc.Order/Create", false); got != 1 {
+		t.Fatalf("unlisted method called %d times, want 1", got)
+	}
+	// 未列出的方法按重试策略执行
+	if got := call("/svc.Order/Create", true); got != 3 {
+		t.Fatalf("unlisted method retried %d times, want 3", got)
+	}
+	if got := call("/svc.Query/Get", false); got < 2 {
+		t.Fatalf("listed method called %d times, want hedged", got)
+	}
+	if got := call("/svc.Report/List", false); got < 2 {
+		t.Fatalf("service wildcard called %d times, want hedged", got)
+	}
+
+	if chain := (ClientPolicy{Hedging: HedgingPolicy{MaxAttempts: 2}}).interceptors("target"); len(chain) != 0 {
+		t.Fatalf("hedging without methods should add no interceptor, got %d", len(chain))
+	}
+}
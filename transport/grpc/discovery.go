@@ -0,0 +1,183 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	_ "google.golang.org/grpc/balancer/leastrequest" // 注册 least_request 负载均衡策略
+	"google.golang.org/grpc/resolver"
+)
+
+/* ========================================================================
+ * gRPC Discovery - 服务发现与客户端负载均衡
+ * ========================================================================
+ * 职责: 解析 ClientFactory 的 target，配置负载均衡策略与实例健康剔除
+ * 支持的 target:
+ *   - host:port / dns:///host:port   gRPC 内置 DNS 解析
+ *   - k8s:///name.namespace:port     Kubernetes headless service（转换为集群 DNS）
+ *   - registry:///service-name       注册中心解析（需通过 WithRegistry 注入 Registry；
+ *                                    本库仅内置 Redis 实现，见 discovery.GRPCRegistry）
+ * 健康剔除:
+ *   - health_check 开启后客户端订阅实例的 grpc.health.v1 状态，剔除 NOT_SERVING 实例
+ *   - 注册中心下线的实例在下一次 Watch 推送时移除
+ * 配置示例:
+ *   grpc:
+ *     discovery:
+ *       load_balancing: round_robin   # pick_first | round_robin | least_request
+ *       health_check: true
+ *       cluster_domain: cluster.local
+ * ======================================================================== */
+
+const (
+	// K8sScheme Kubernetes headless service target 前缀
+	K8sScheme = "k8s"
+	// RegistryScheme 注册中心 target 前缀
+	RegistryScheme = "registry"
+)
+
+// DiscoveryConfig 服务发现与负载均衡配置
+type DiscoveryConfig struct {
+	// LoadBalancing 负载均衡策略: pick_first | round_robin | least_request，默认 pick_first
+	LoadBalancing string `yaml:"load_balancing"`
+
+	// HealthCheck 是否启用客户端健康检查（剔除 NOT_SERVING 实例，需配合 round_robin/least_request）
+	HealthCheck bool `yaml:"health_check"`
+
+	// ClusterDomain Kubernetes 集群域名，默认 cluster.local
+	ClusterDomain string `yaml:"cluster_domain"`
+}
+
+// Endpoint 服务实例地址
+type Endpoint struct {
+	Addr string // host:port
+}
+
+// Registry 注册中心实例来源
+// 本库仅提供 Redis 实现（discovery.NewRedisRegistry 经 discovery.GRPCRegistry 适配），
+// 使用其他注册中心时需自行实现该接口
+type Registry interface {
+	// Watch 监听服务实例，实例变化时推送全量列表；ctx 取消后关闭通道
+	Watch(ctx context.Context, service string) (<-chan []Endpoint, error)
+}
+
+// ClientOption ClientFactory 选项
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	registry Registry
+}
+
+// WithRegistry 注入注册中心，用于解析 registry:/// target
+func WithRegistry(reg Registry) ClientOption {
+	return func(o *clientOptions) {
+		o.registry = reg
+	}
+}
+
+// serviceConfig 生成负载均衡与健康检查的默认 service config
+func (c DiscoveryConfig) serviceConfig() (string, error) {
+	sc := map[string]any{}
+
+	switch strings.ToLower(c.LoadBalancing) {
+	case "", "pick_first":
+	case "round_robin":
+		sc["loadBalancingConfig"] = []map[string]any{{"round_robin": map[string]any{}}}
+	case "least_request":
+		sc["loadBalancingConfig"] = []map[string]any{{"least_request_experimental": map[string]any{}}}
+	default:
+		return "", fmt.Errorf("unknown load_balancing policy: %s", c.LoadBalancing)
+	}
+	if c.HealthCheck {
+		sc["healthCheckConfig"] = map[string]any{"serviceName": ""}
+	}
+	if len(sc) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(sc)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// resolveTarget 将 k8s:/// target 转换为集群 DNS target，其余原样返回
+func (c DiscoveryConfig) resolveTarget(target string) (string, error) {
+	rest, ok := strings.CutPrefix(target, K8sScheme+":///")
+	if !ok {
+		return target, nil
+	}
+
+	host, port, ok := strings.Cut(rest, ":")
+	if !ok || host == "" || port == "" {
+		return "", fmt.Errorf("invalid k8s target %q, expected k8s:///name.namespace:port", target)
+	}
+	name, namespace, _ := strings.Cut(host, ".")
+	if namespace == "" {
+		namespace = "default"
+	}
+	domain := c.ClusterDomain
+	if domain == "" {
+		domain = "cluster.local"
+	}
+	return fmt.Sprintf("dns:///%s.%s.svc.%s:%s", name, namespace, domain, port), nil
+}
+
+// =============================================================================
+// 注册中心 Resolver
+// =============================================================================
+
+// registryBuilder 基于 Registry 的 resolver.Builder
+type registryBuilder struct {
+	registry Registry
+}
+
+func (b *registryBuilder) Scheme() string {
+	return RegistryScheme
+}
+
+func (b *registryBuilder) Build(target resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	service := target.Endpoint()
+	if service == "" {
+		return nil, fmt.Errorf("empty service name in target %q", target.URL.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := b.registry.Watch(ctx, service)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("watch service %s: %w", service, err)
+	}
+
+	r := &registryResolver{cancel: cancel, done: make(chan struct{})}
+	go func() {
+		defer close(r.done)
+		for endpoints := range updates {
+			if len(endpoints) == 0 {
+				cc.ReportError(fmt.Errorf("no available endpoints for service %s", service))
+				continue
+			}
+			addrs := make([]resolver.Address, 0, len(endpoints))
+			for _, ep := range endpoints {
+				addrs = append(addrs, resolver.Address{Addr: ep.Addr})
+			}
+			_ = cc.UpdateState(resolver.State{Addresses: addrs})
+		}
+	}()
+	return r, nil
+}
+
+// registryResolver 注册中心 resolver，实例列表由 Watch 推送
+type registryResolver struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (r *registryResolver) ResolveNow(resolver.ResolveNowOptions) {}
+
+func (r *registryResolver) Close() {
+	r.cancel()
+	<-r.done
+}
@@ -0,0 +1,106 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type staticRegistry struct {
+	endpoints []Endpoint
+}
+
+func (r staticRegistry) Watch(ctx context.Context, service string) (<-chan []Endpoint, error) {
+	ch := make(chan []Endpoint, 1)
+	ch <- r.endpoints
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+func TestDiscoveryTargets(t *testing.T) {
+	cfg := DiscoveryConfig{}
+	got, err := cfg.resolveTarget("k8s:///user-service.prod:9090")
+	if err != nil || got != "dns:///user-service.prod.svc.cluster.local:9090" {
+		t.Fatalf("unexpected k8s target: %q %v", got, err)
+	}
+	if got, _ := cfg.resolveTarget("k8s:///user-service:9090"); got != "dns:///user-service.default.svc.cluster.local:9090" {
+		t.Fatalf("unexpected default namespace target: %q", got)
+	}
+	if _, err := cfg.resolveTarget("k8s:///user-service"); err == nil {
+		t.Fatalf("expected error for missing port")
+	}
+	if got, _ := cfg.resolveTarget("dns:///a:1"); got != "dns:///a:1" {
+		t.Fatalf("expected passthrough, got %q", got)
+	}
+
+	if sc, _ := cfg.serviceConfig(); sc != "" {
+		t.Fatalf("expected empty service config, got %s", sc)
+	}
+	sc, err := DiscoveryConfig{LoadBalancing: "least_request", HealthCheck: true}.serviceConfig()
+	if err != nil || !strings.Contains(sc, "least_request_experimental") || !strings.Contains(sc, "healthCheckConfig") {
+		t.Fatalf("unexpected service config: %s %v", sc, err)
+	}
+	if _, err := (DiscoveryConfig{LoadBalancing: "random"}).serviceConfig(); err == nil {
+		t.Fatalf("expected error for unknown policy")
+	}
+}
+
+func TestRegistryResolverEvictsUnhealthyEndpoints(t *testing.T) {
+	type backend struct {
+		addr   string
+		health *health.Server
+		calls  atomic.Int32
+	}
+	start := func() *backend {
+		b := &backend{health: health.NewServer()}
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %v", err)
+		}
+		s := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			b.calls.Add(1)
+			return handler(ctx, req)
+		}))
+		healthpb.RegisterHealthServer(s, b.health)
+		go func() { _ = s.Serve(lis) }()
+		t.Cleanup(s.Stop)
+		b.addr = lis.Addr().String()
+		return b
+	}
+	healthy, unhealthy := start(), start()
+	unhealthy.health.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+
+	factory := NewClientFactory(Config{
+		Discovery: DiscoveryConfig{LoadBalancing: "round_robin", HealthCheck: true},
+	}, nil, WithRegistry(staticRegistry{endpoints: []Endpoint{{Addr: healthy.addr}, {Addr: unhealthy.addr}}}))
+	conn, err := factory("registry:///user-service")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	client := healthpb.NewHealthClient(conn)
+	for range 10 {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.WaitForReady(true))
+		cancel()
+		if err != nil {
+			t.Fatalf("check: %v", err)
+		}
+	}
+	// 客户端健康检查本身也会调用 Watch（流式，不经过一元拦截器）
+	if healthy.calls.Load() != 10 || unhealthy.calls.Load() != 0 {
+		t.Fatalf("expected all calls on healthy endpoint, got healthy=%d unhealthy=%d",
+			healthy.calls.Load(), unhealthy.calls.Load())
+	}
+}
@@ -0,0 +1,297 @@
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/aisgo/ais-go-pkg/errors"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * gRPC Gateway - JSON 到 gRPC 的通用转码
+ * ========================================================================
+ * 职责: 将 Fiber 上的 REST/JSON 请求转码为 gRPC 一元调用
+ * 特性:
+ *   - 基于全局 protobuf 注册表解析请求/响应类型，无需生成网关代码
+ *   - 通用路由: POST {prefix}/{package.Service}/{Method}
+ *   - 自定义路由: 路径参数与查询参数映射到请求消息的同名字段
+ *   - 挂载在 Fiber 上，复用认证中间件与统一响应信封
+ *   - 白名单请求头转为 gRPC metadata（默认 Authorization、X-Request-ID 等）
+ *   - 与 ClientFactory 配合，monolith 模式下经 bufconn 进程内调用
+ * 使用示例:
+ *     conn, _ := factory("user-service:9090")
+ *     gw := grpc.NewGateway(conn)
+ *     gw.Mount(app.Group("/rpc", auth.Authenticate()))
+ *     _ = gw.Handle(app, grpc.GatewayRoute{
+ *         Method: "GET", Path: "/api/users/:id", RPC: "/user.v1.UserService/GetUser",
+ *     })
+ * ======================================================================== */
+
+// defaultGatewayHeaders 默认透传为 metadata 的请求头
+var defaultGatewayHeaders = []string{
+	fiber.HeaderAuthorization,
+	"X-Request-ID",
+	"X-API-Key",
+	fiber.HeaderAcceptLanguage,
+}
+
+// GatewayRoute 自定义转码路由
+type GatewayRoute struct {
+	Method string // HTTP 方法，如 GET
+	Path   string // Fiber 路由，如 /api/users/:id
+	RPC    string // gRPC 完整方法名，如 /user.v1.UserService/GetUser
+}
+
+// GatewayOption 网关选项
+type GatewayOption func(*Gateway)
+
+// WithGatewayHeaders 设置透传为 metadata 的请求头
+func WithGatewayHeaders(headers ...string) GatewayOption {
+	return func(g *Gateway) {
+		g.headers = headers
+	}
+}
+
+// WithGatewayResolver 设置 protobuf 描述符与类型注册表（默认使用全局注册表）
+func WithGatewayResolver(files *protoregistry.Files, types *protoregistry.Types) GatewayOption {
+	return func(g *Gateway) {
+		g.files = files
+		g.types = types
+	}
+}
+
+// Gateway JSON 到 gRPC 转码网关
+type Gateway struct {
+	conn    grpc.ClientConnInterface
+	files   *protoregistry.Files
+	types   *protoregistry.Types
+	headers []string
+
+	unmarshal protojson.UnmarshalOptions
+	marshal   protojson.MarshalOptions
+}
+
+// gatewayMethod 解析后的方法
+type gatewayMethod struct {
+	fullMethod string
+	input      protoreflect.MessageType
+	output     protoreflect.MessageType
+}
+
+// NewGateway 创建转码网关
+func NewGateway(conn grpc.ClientConnInterface, opts ...GatewayOption) *Gateway {
+	g := &Gateway{
+		conn:      conn,
+		files:     protoregistry.GlobalFiles,
+		types:     protoregistry.GlobalTypes,
+		headers:   defaultGatewayHeaders,
+		unmarshal: protojson.UnmarshalOptions{DiscardUnknown: true},
+		marshal:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Mount 注册通用转码路由 POST /:service/:method
+func (g *Gateway) Mount(router fiber.Router) {
+	router.Post("/:service/:method", func(c fiber.Ctx) error {
+		m, err := g.resolve("/" + c.Params("service") + "/" + c.Params("method"))
+		if err != nil {
+			return response.Error(c, err)
+		}
+		return g.invoke(c, m)
+	})
+}
+
+// Handle 注册自定义转码路由，方法在注册时解析
+func (g *Gateway) Handle(router fiber.Router, routes ...GatewayRoute) error {
+	for _, route := range routes {
+		m, err := g.resolve(route.RPC)
+		if err != nil {
+			return fmt.Errorf("gateway route %s %s: %w", route.Method, route.Path, err)
+		}
+		router.Add([]string{strings.ToUpper(route.Method)}, route.Path, func(c fiber.Ctx) error {
+			return g.invoke(c, m)
+		})
+	}
+	return nil
+}
+
+// resolve 解析 gRPC 方法的请求/响应类型
+func (g *Gateway) resolve(fullMethod string) (*gatewayMethod, error) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok || service == "" || method == "" {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "invalid rpc method: "+fullMethod)
+	}
+
+	desc, err := g.files.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrCodeNotFound, "rpc service not found: "+service, err)
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "rpc service not found: "+service)
+	}
+	md := sd.Methods().ByName(protoreflect.Name(method))
+	if md == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "rpc method not found: "+fullMethod)
+	}
+	if md.IsStreamingClient() || md.IsStreamingServer() {
+		return nil, errors.New(errors.ErrCodeInvalidArgument, "streaming rpc is not supported: "+fullMethod)
+	}
+
+	input, err := g.types.FindMessageByName(md.Input().FullName())
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInternal, "rpc input type not registered", err)
+	}
+	output, err := g.types.FindMessageByName(md.Output().FullName())
+	if err != nil {
+		return nil, errors.Wrap(errors.ErrCodeInternal, "rpc output type not registered", err)
+	}
+
+	return &gatewayMethod{
+		fullMethod: "/" + service + "/" + method,
+		input:      input,
+		output:     output,
+	}, nil
+}
+
+// invoke 转码并执行一元调用
+func (g *Gateway) invoke(c fiber.Ctx, m *gatewayMethod) error {
+	in := m.input.New().Interface()
+	if body := c.Body(); len(body) > 0 {
+		if err := g.unmarshal.Unmarshal(body, in); err != nil {
+			return response.Error(c, errors.Wrap(errors.ErrCodeInvalidArgument, "invalid request body", err))
+		}
+	}
+
+	// 路径参数优先于查询参数
+	for key, value := range c.Queries() {
+		if err := setField(in, key, value); err != nil {
+			return response.Error(c, err)
+		}
+	}
+	for _, key := range c.Route().Params {
+		if err := setField(in, key, c.Params(key)); err != nil {
+			return response.Error(c, err)
+		}
+	}
+
+	ctx := c.Context()
+	md := metadata.MD{}
+	for _, h := range g.headers {
+		if v := c.Get(h); v != "" {
+			md.Set(strings.ToLower(h), v)
+		}
+	}
+	if len(md) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, md)
+	}
+
+	out := m.output.New().Interface()
+	if err := g.conn.Invoke(ctx, m.fullMethod, in, out); err != nil {
+		return response.Error(c, errors.FromGRPCError(err))
+	}
+
+	data, err := g.marshal.Marshal(out)
+	if err != nil {
+		return response.Error(c, errors.Wrap(errors.ErrCodeInternal, "failed to encode rpc response", err))
+	}
+	return response.OkWithData(c, json.RawMessage(data))
+}
+
+// setField 将字符串值写入消息的同名标量字段（按 proto 名或 JSON 名匹配），未知字段忽略
+func setField(msg proto.Message, name, value string) error {
+	rm := msg.ProtoReflect()
+	fields := rm.Descriptor().Fields()
+	fd := fields.ByName(protoreflect.Name(name))
+	if fd == nil {
+		fd = fields.ByJSONName(name)
+	}
+	if fd == nil || fd.IsList() || fd.IsMap() {
+		return nil
+	}
+
+	invalid := func(err error) error {
+		return errors.Wrap(errors.ErrCodeInvalidArgument, "invalid value for field "+name, err)
+	}
+
+	var v protoreflect.Value
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		v = protoreflect.ValueOfString(value)
+	case protoreflect.BytesKind:
+		v = protoreflect.ValueOfBytes([]byte(value))
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid(err)
+		}
+		v = protoreflect.ValueOfBool(b)
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return invalid(err)
+		}
+		v = protoreflect.ValueOfInt32(int32(n))
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return invalid(err)
+		}
+		v = protoreflect.ValueOfInt64(n)
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return invalid(err)
+		}
+		v = protoreflect.ValueOfUint32(uint32(n))
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return invalid(err)
+		}
+		v = protoreflect.ValueOfUint64(n)
+	case protoreflect.FloatKind:
+		f, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return invalid(err)
+		}
+		v = protoreflect.ValueOfFloat32(float32(f))
+	case protoreflect.DoubleKind:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return invalid(err)
+		}
+		v = protoreflect.ValueOfFloat64(f)
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(value)); ev != nil {
+			v = protoreflect.ValueOfEnum(ev.Number())
+			break
+		}
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return invalid(err)
+		}
+		v = protoreflect.ValueOfEnum(protoreflect.EnumNumber(n))
+	default:
+		return nil
+	}
+
+	rm.Set(fd, v)
+	return nil
+}
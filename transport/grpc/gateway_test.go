@@ -0,0 +1,64 @@
+package grpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	httpserver "github.com/aisgo/ais-go-pkg/transport/http"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestGatewayTranscoding(t *testing.T) {
+	conn := startInProcServer(t, Config{}, httpserver.NewReadiness())
+
+	gw := NewGateway(conn)
+	app := fiber.New()
+	gw.Mount(app.Group("/rpc"))
+	if err := gw.Handle(app, GatewayRoute{
+		Method: "GET",
+		Path:   "/health/:service",
+		RPC:    "/grpc.health.v1.Health/Check",
+	}); err != nil {
+		t.Fatalf("register route: %v", err)
+	}
+	if err := gw.Handle(app, GatewayRoute{Method: "GET", Path: "/bad", RPC: "/no.Such/Method"}); err == nil {
+		t.Fatalf("expected error for unknown rpc")
+	}
+
+	do := func(req *http.Request) (int, map[string]any) {
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		defer resp.Body.Close()
+		var body map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp.StatusCode, body
+	}
+
+	status, body := do(httptest.NewRequest("POST", "/rpc/grpc.health.v1.Health/Check", strings.NewReader(`{"service":""}`)))
+	if status != fiber.StatusOK {
+		t.Fatalf("unexpected status: %d %v", status, body)
+	}
+	if data, _ := body["data"].(map[string]any); data["status"] != "SERVING" {
+		t.Fatalf("unexpected data: %v", body)
+	}
+
+	// 未注册的服务名: health 服务返回 NotFound
+	status, _ = do(httptest.NewRequest("GET", "/health/unknown.Service", nil))
+	if status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 from grpc NotFound, got %d", status)
+	}
+
+	status, _ = do(httptest.NewRequest("POST", "/rpc/no.Such/Method", strings.NewReader(`{}`)))
+	if status != fiber.StatusNotFound {
+		t.Fatalf("expected 404 for unknown rpc, got %d", status)
+	}
+}
@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+
+	httpserver "github.com/aisgo/ais-go-pkg/transport/http"
+)
+
+/* ========================================================================
+ * gRPC Health & Reflection - 健康检查与反射
+ * ========================================================================
+ * 职责: 注册标准 grpc.health.v1.Health 服务与可选的 Server Reflection
+ * 状态管理:
+ *   - 启动时将所有已注册服务（及整体 ""）置为 SERVING
+ *   - 就绪开关（httpserver.Readiness）置为未就绪时全部切为 NOT_SERVING
+ *   - 关停时先切为 NOT_SERVING 再 GracefulStop，并取消对就绪开关的订阅
+ *   - 业务可通过注入的 *health.Server 调用 SetServingStatus 单独控制服务
+ * 使用示例:
+ *     fx.Provide(grpc.NewHealthServer)
+ *     hs.SetServingStatus("user.v1.UserService", healthpb.HealthCheckResponse_NOT_SERVING)
+ * ======================================================================== */
+
+// NewHealthServer 创建 gRPC 健康检查服务
+func NewHealthServer() *health.Server {
+	return health.NewServer()
+}
+
+// registerHealth 注册健康检查服务并与就绪开关联动，返回取消联动的函数
+func registerHealth(s *grpc.Server, hs *health.Server, readiness *httpserver.Readiness) (unsubscribe func()) {
+	healthpb.RegisterHealthServer(s, hs)
+
+	return readiness.OnChange(func(ready bool) {
+		if ready {
+			hs.Resume()
+			return
+		}
+		hs.Shutdown()
+	})
+}
+
+// markServing 将已注册服务标记为 SERVING（未就绪时保持 NOT_SERVING）
+func markServing(s *grpc.Server, hs *health.Server, readiness *httpserver.Readiness) {
+	hs.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	for name := range s.GetServiceInfo() {
+		hs.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+	if !readiness.IsReady() {
+		hs.Shutdown()
+	}
+}
+
+// registerReflection 按配置注册 Server Reflection（建议仅开发环境开启）
+func registerReflection(s *grpc.Server, enabled bool) {
+	if enabled {
+		reflection.Register(s)
+	}
+}
@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	httpserver "github.com/aisgo/ais-go-pkg/transport/http"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestHealthServiceFollowsReadiness(t *testing.T) {
+	readiness := httpserver.NewReadiness()
+	conn := startInProcServer(t, Config{}, readiness)
+	client := healthpb.NewHealthClient(conn)
+
+	check := func(service string) healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("health check %q: %v", service, err)
+		}
+		return resp.GetStatus()
+	}
+
+	if got := check(""); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("unexpected overall status: %v", got)
+	}
+	if got := check(healthpb.Health_ServiceDesc.ServiceName); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("unexpected service status: %v", got)
+	}
+
+	readiness.SetReady(false)
+	if got := check(""); got != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING while draining, got %v", got)
+	}
+
+	readiness.SetReady(true)
+	if got := check(""); got != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("expected SERVING after resume, got %v", got)
+	}
+}
+
+func TestHealthUnsubscribesReadinessOnStop(t *testing.T) {
+	readiness := httpserver.NewReadiness()
+	hs := NewHealthServer()
+	lc := &testLifecycle{}
+	NewServer(ServerParams{
+		Lc:        lc,
+		Listener:  NewInProcListener().Listener,
+		Logger:    logger.NewNop(),
+		Readiness: readiness,
+		Health:    hs,
+	})
+	if err := lc.start(context.Background()); err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	lc.stop(context.Background())
+
+	// 停止后就绪开关的变化不再恢复已关闭服务器的健康状态
+	readiness.SetReady(false)
+	readiness.SetReady(true)
+	resp, err := hs.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("health check: %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("expected NOT_SERVING after stop, got %v", resp.GetStatus())
+	}
+}
@@ -0,0 +1,279 @@
+package grpc
+
+import (
+	"context"
+	stderrors "errors"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/ctxutil"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/metrics"
+	"github.com/aisgo/ais-go-pkg/middleware"
+	"github.com/aisgo/ais-go-pkg/repository"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+/* ========================================================================
+ * Server Interceptors - 服务端拦截器（Unary / Stream 对等）
+ * ========================================================================
+ * 链路顺序: 请求 ID → Panic 恢复 → 日志 → 指标 → API Key 认证（Unary 最外层另有 deadline 补齐）
+ * 说明:
+ *   - Deadline: 客户端未携带 deadline 的 Unary 请求使用 request_timeout（默认 30s），
+ *     已携带时保持不变，保证下游仓储 / Redis / MQ 调用均有截止时间
+ *   - 日志: 失败请求记录 Warn；Unary 超过 500ms、Stream 超过 1min 记为慢请求
+ *   - 指标: app_grpc_request_total / app_grpc_request_duration_seconds{method, status}
+ *   - 认证: grpc.auth.enabled 且 ServerParams 提供已启用的 APIKeyAuth 时生效（monolith 模式不校验），从 metadata 读取
+ *     x-api-key 或 authorization: Bearer <key>；健康检查与反射服务免认证
+ *     认证通过后 ctx 注入 APIKeyInfo（APIKeyInfoFromContext）与绑定的租户
+ * ======================================================================== */
+
+const (
+	// slowUnaryThreshold Unary 慢请求阈值
+	slowUnaryThreshold = 500 * time.Millisecond
+	// slowStreamThreshold Stream 慢请求阈值（流式调用通常持续更久）
+	slowStreamThreshold = time.Minute
+)
+
+// authExemptServices 免认证的服务（方法前缀）
+var authExemptServices = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// wrappedStream 替换 ServerStream 的 ctx
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
+
+// withStreamContext 返回使用新 ctx 的 ServerStream
+func withStreamContext(ss grpc.ServerStream, ctx context.Context) grpc.ServerStream {
+	if ctx == ss.Context() {
+		return ss
+	}
+	return &wrappedStream{ServerStream: ss, ctx: ctx}
+}
+
+// deadlineInterceptor 客户端未携带 deadline 时为请求设置超时，d <= 0 时不生效
+func deadlineInterceptor(d time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := ctxutil.WithTimeoutIfNone(ctx, d)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// requestIDStreamInterceptor 服务端流式请求 ID 拦截器
+func requestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, withStreamContext(ss, requestIDFromIncoming(ss.Context())))
+	}
+}
+
+// recoveryInterceptor 创建 panic 恢复拦截器
+func recoveryInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverPanic(ctx, log, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// recoveryStreamInterceptor 创建流式 panic 恢复拦截器
+func recoveryStreamInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recoverPanic(ss.Context(), log, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recoverPanic 记录 panic 并返回 Internal 错误
+func recoverPanic(ctx context.Context, log *logger.Logger, method string, r any) error {
+	log.WithContext(ctx).Error("gRPC panic recovered",
+		zap.Any("panic", r),
+		zap.String("method", method),
+		zap.String("stack", string(debug.Stack())),
+	)
+	return status.Errorf(codes.Internal, "internal server error")
+}
+
+// loggingInterceptor 创建日志拦截器
+func loggingInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		resp, err = handler(ctx, req)
+		logCall(ctx, log, "request", info.FullMethod, time.Since(start), slowUnaryThreshold, err)
+		return resp, err
+	}
+}
+
+// loggingStreamInterceptor 创建流式日志拦截器
+func loggingStreamInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), log, "stream", info.FullMethod, time.Since(start), slowStreamThreshold, err)
+		return err
+	}
+}
+
+// logCall 记录失败或慢调用
+func logCall(ctx context.Context, log *logger.Logger, kind, method string, duration, slow time.Duration, err error) {
+	if err != nil {
+		log.WithContext(ctx).Warn("gRPC "+kind+" failed",
+			zap.String("method", method),
+			zap.Duration("duration", duration),
+			zap.Error(err),
+		)
+	} else if duration > slow {
+		log.WithContext(ctx).Warn("gRPC slow "+kind,
+			zap.String("method", method),
+			zap.Duration("duration", duration),
+		)
+	}
+}
+
+// metricsInterceptor 创建指标拦截器
+func metricsInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observe(info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// metricsStreamInterceptor 创建流式指标拦截器
+func metricsStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observe(info.FullMethod, start, err)
+		return err
+	}
+}
+
+// observe 记录调用次数与耗时
+func observe(method string, start time.Time, err error) {
+	code := status.Code(err).String()
+	metrics.GRPCRequestTotal.WithLabelValues(method, code).Inc()
+	metrics.GRPCRequestDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
+
+// apiKeyInfoKey ctx 中 APIKeyInfo 的键
+type apiKeyInfoKey struct{}
+
+// APIKeyInfoFromContext 读取认证拦截器注入的 Key 信息
+func APIKeyInfoFromContext(ctx context.Context) (*middleware.APIKeyInfo, bool) {
+	info, ok := ctx.Value(apiKeyInfoKey{}).(*middleware.APIKeyInfo)
+	return info, ok && info != nil
+}
+
+// authInterceptor 创建 API Key 认证拦截器
+func authInterceptor(auth *middleware.APIKeyAuth, log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, auth, log, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// authStreamInterceptor 创建流式 API Key 认证拦截器
+func authStreamInterceptor(auth *middleware.APIKeyAuth, log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), auth, log, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, withStreamContext(ss, ctx))
+	}
+}
+
+// authenticate 校验 metadata 中的 API Key，返回注入认证信息后的 ctx
+func authenticate(ctx context.Context, auth *middleware.APIKeyAuth, log *logger.Logger, method string) (context.Context, error) {
+	for _, prefix := range authExemptServices {
+		if strings.HasPrefix(method, prefix) {
+			return ctx, nil
+		}
+	}
+
+	apiKey := apiKeyFromMetadata(ctx)
+	if apiKey == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing api key")
+	}
+
+	info, err := auth.Verify(ctx, apiKey)
+	if err != nil {
+		if !stderrors.Is(err, middleware.ErrAPIKeyInvalid) && !stderrors.Is(err, middleware.ErrAPIKeyExpired) {
+			log.WithContext(ctx).Error("API Key verification failed",
+				zap.String("method", method),
+				zap.Error(err),
+			)
+			return nil, status.Error(codes.Unavailable, "api key verification unavailable")
+		}
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	ctx = context.WithValue(ctx, apiKeyInfoKey{}, info)
+	if info.Tenant != nil {
+		ctx = repository.WithTenantContext(ctx, *info.Tenant)
+	}
+	return ctx, nil
+}
+
+// apiKeyClientInterceptor 客户端在 outgoing metadata 中携带 API Key（不覆盖已显式设置的值）
+func apiKeyClientInterceptor(key string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(apiKeyToOutgoing(ctx, key), method, req, reply, cc, opts...)
+	}
+}
+
+// apiKeyStreamClientInterceptor 流式调用携带 API Key
+func apiKeyStreamClientInterceptor(key string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(apiKeyToOutgoing(ctx, key), desc, cc, method, opts...)
+	}
+}
+
+func apiKeyToOutgoing(ctx context.Context, key string) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	if len(md.Get("x-api-key")) > 0 || len(md.Get("authorization")) > 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "x-api-key", key)
+}
+
+// apiKeyFromMetadata 从 x-api-key 或 authorization: Bearer 读取 API Key
+func apiKeyFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if vals := md.Get("x-api-key"); len(vals) > 0 && vals[0] != "" {
+		return vals[0]
+	}
+	if vals := md.Get("authorization"); len(vals) > 0 {
+		if key, ok := strings.CutPrefix(vals[0], "Bearer "); ok {
+			return key
+		}
+	}
+	return ""
+}
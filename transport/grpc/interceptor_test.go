@@ -0,0 +1,307 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+	"github.com/aisgo/ais-go-pkg/requestid"
+	httpserver "github.com/aisgo/ais-go-pkg/transport/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRequestIDInterceptors(t *testing.T) {
+	ctx := requestid.WithCorrelationID(requestid.WithContext(context.Background(), "req-1"), "chain-1")
+	var outgoing metadata.MD
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		outgoing, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	}
+	if err := requestIDClientInterceptor()(ctx, "/svc/M", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("invoke: %v", err)
+	}
+	if got := outgoing.Get(requestid.MetadataKey); len(got) != 1 || got[0] != "req-1" {
+		t.Fatalf("unexpected outgoing request id: %v", outgoing)
+	}
+	if got := outgoing.Get(requestid.CorrelationMetadataKey); len(got) != 1 || got[0] != "chain-1" {
+		t.Fatalf("unexpected outgoing correlation id: %v", outgoing)
+	}
+
+	// 已显式设置的 metadata 不被覆盖
+	explicit := metadata.AppendToOutgoingContext(ctx, requestid.MetadataKey, "explicit")
+	_ = requestIDClientInterceptor()(explicit, "/svc/M", nil, nil, nil, invoker)
+	if got := outgoing.Get(requestid.MetadataKey); len(got) != 1 || got[0] != "explicit" {
+		t.Fatalf("expected explicit metadata to win, got %v", got)
+	}
+
+	server := requestIDServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return [2]string{requestid.FromContext(ctx), requestid.CorrelationIDFromContext(ctx)}, nil
+	}
+	incoming := metadata.NewIncomingContext(context.Background(), outgoing)
+	resp, _ := server(incoming, nil, &grpc.UnaryServerInfo{FullMethod: "/svc/M"}, handler)
+	if ids := resp.([2]string); ids[0] != "explicit" || ids[1] != "chain-1" {
+		t.Fatalf("unexpected server ids: %v", ids)
+	}
+
+	resp, _ = server(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/svc/M"}, handler)
+	if ids := resp.([2]string); ids[0] == "" || ids[1] != ids[0] {
+		t.Fatalf("expected generated request id, got %v", ids)
+	}
+}
+
+func TestRecoveryInterceptor(t *testing.T) {
+	log := logger.NewNop()
+	interceptor := recoveryInterceptor(log)
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		panic("boom")
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		t.Fatalf("expected status error")
+	}
+	if st.Code() != codes.Internal {
+		t.Fatalf("unexpected code: %v", st.Code())
+	}
+}
+
+func TestDeadlineInterceptor(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+	deadlineOf := func(ctx context.Context, d time.Duration) (time.Time, bool) {
+		var (
+			deadline time.Time
+			ok       bool
+		)
+		_, _ = deadlineInterceptor(d)(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			deadline, ok = ctx.Deadline()
+			return nil, nil
+		})
+		return deadline, ok
+	}
+
+	if deadline, ok := deadlineOf(context.Background(), time.Second); !ok || time.Until(deadline) > time.Second {
+		t.Fatalf("deadline = %v, %v; want within 1s", deadline, ok)
+	}
+	// 客户端携带的 deadline 保持不变
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	want, _ := ctx.Deadline()
+	if deadline, _ := deadlineOf(ctx, time.Second); !deadline.Equal(want) {
+		t.Fatalf("deadline = %v, want %v", deadline, want)
+	}
+	if _, ok := deadlineOf(context.Background(), -1); ok {
+		t.Fatal("negative timeout should not set deadline")
+	}
+	if got := (Config{}).requestTimeout(); got != defaultRequestTimeout {
+		t.Fatalf("default request timeout = %v", got)
+	}
+}
+
+func TestLoggingInterceptor(t *testing.T) {
+	log := logger.NewNop()
+	interceptor := loggingInterceptor(log)
+
+	expectedErr := errors.New("fail")
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, expectedErr
+	})
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// fakeServerStream 测试用 ServerStream
+type fakeServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (f *fakeServerStream) Context() context.Context { return f.ctx }
+
+func TestStreamInterceptors(t *testing.T) {
+	log := logger.NewNop()
+	info := &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch", IsServerStream: true}
+
+	err := recoveryStreamInterceptor(log)(nil, &fakeServerStream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		panic("boom")
+	})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("recovery code = %v", status.Code(err))
+	}
+
+	incoming := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, "req-stream"))
+	err = requestIDStreamInterceptor()(nil, &fakeServerStream{ctx: incoming}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		if got := requestid.FromContext(ss.Context()); got != "req-stream" {
+			t.Fatalf("stream request id = %q", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("request id interceptor: %v", err)
+	}
+
+	expectedErr := errors.New("fail")
+	err = loggingStreamInterceptor(log)(nil, &fakeServerStream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return expectedErr
+	})
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("logging interceptor: %v", err)
+	}
+
+	err = metricsStreamInterceptor()(nil, &fakeServerStream{ctx: context.Background()}, info, func(srv interface{}, ss grpc.ServerStream) error {
+		return status.Error(codes.NotFound, "missing")
+	})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("metrics interceptor: %v", err)
+	}
+}
+
+func TestAuthInterceptors(t *testing.T) {
+	auth := middleware.NewAPIKeyAuth(&middleware.APIKeyConfig{
+		Enabled: true,
+		Keys:    map[string]string{"svc": "sk_svc"},
+	}, logger.NewNop())
+	unary := authInterceptor(auth, logger.NewNop())
+	stream := authStreamInterceptor(auth, logger.NewNop())
+	method := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}
+
+	call := func(ctx context.Context, info *grpc.UnaryServerInfo) (string, error) {
+		resp, err := unary(ctx, nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+			if info, ok := APIKeyInfoFromContext(ctx); ok {
+				return info.KeyID, nil
+			}
+			return "", nil
+		})
+		keyID, _ := resp.(string)
+		return keyID, err
+	}
+
+	if _, err := call(context.Background(), method); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("missing key code = %v", status.Code(err))
+	}
+	bad := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "sk_bad"))
+	if _, err := call(bad, method); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("invalid key code = %v", status.Code(err))
+	}
+	bearer := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer sk_svc"))
+	if keyID, err := call(bearer, method); err != nil || keyID != "svc" {
+		t.Fatalf("bearer key = %q, %v", keyID, err)
+	}
+	health := &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}
+	if _, err := call(context.Background(), health); err != nil {
+		t.Fatalf("health should be exempt: %v", err)
+	}
+
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/test.Service/Watch", IsServerStream: true}
+	if err := stream(nil, &fakeServerStream{ctx: context.Background()}, streamInfo, func(srv interface{}, ss grpc.ServerStream) error {
+		t.Fatal("handler should not run without api key")
+		return nil
+	}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("stream missing key code = %v", status.Code(err))
+	}
+	keyed := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", "sk_svc"))
+	if err := stream(nil, &fakeServerStream{ctx: keyed}, streamInfo, func(srv interface{}, ss grpc.ServerStream) error {
+		if info, ok := APIKeyInfoFromContext(ss.Context()); !ok || info.KeyID != "svc" {
+			t.Fatalf("stream key info = %+v", info)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("stream auth: %v", err)
+	}
+}
+
+func TestServerAuthIsOptIn(t *testing.T) {
+	auth := middleware.NewAPIKeyAuth(&middleware.APIKeyConfig{
+		Enabled: true,
+		Keys:    map[string]string{"svc": "sk_svc"},
+	}, logger.NewNop())
+
+	// 非免认证的业务服务
+	desc := grpc.ServiceDesc{
+		ServiceName: "test.Echo",
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "Check",
+			Handler: func(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := new(healthpb.HealthCheckRequest)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				handler := func(context.Context, any) (any, error) { return &healthpb.HealthCheckResponse{}, nil }
+				return interceptor(ctx, in, &grpc.UnaryServerInfo{FullMethod: "/test.Echo/Check"}, handler)
+			},
+		}},
+	}
+
+	start := func(cfg Config, auth *middleware.APIKeyAuth) (*InProcListener, error) {
+		inProc := NewInProcListener()
+		lc := &testLifecycle{}
+		s := NewServer(ServerParams{
+			Lc:         lc,
+			Listener:   inProc.Listener,
+			Logger:     logger.NewNop(),
+			Config:     cfg,
+			Readiness:  httpserver.NewReadiness(),
+			APIKeyAuth: auth,
+		})
+		s.RegisterService(&desc, struct{}{})
+		err := lc.start(context.Background())
+		t.Cleanup(func() { lc.stop(context.Background()) })
+		return inProc, err
+	}
+	call := func(inProc *InProcListener, cfg Config) error {
+		cfg.Mode = "monolith"
+		conn, err := NewClientFactory(cfg, inProc)("ignored")
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		defer conn.Close()
+		return conn.Invoke(context.Background(), "/test.Echo/Check", &healthpb.HealthCheckRequest{}, &healthpb.HealthCheckResponse{})
+	}
+
+	// 仅提供 APIKeyAuth 不开启认证
+	inProc, err := start(Config{Mode: "microservice"}, auth)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := call(inProc, Config{}); err != nil {
+		t.Fatalf("auth should be off by default: %v", err)
+	}
+
+	// monolith 模式不校验
+	inProc, err = start(Config{Mode: "monolith", Auth: AuthConfig{Enabled: true}}, auth)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := call(inProc, Config{}); err != nil {
+		t.Fatalf("monolith should skip auth: %v", err)
+	}
+
+	// 显式开启后校验，ClientFactory 携带配置的 Key
+	inProc, err = start(Config{Mode: "microservice", Auth: AuthConfig{Enabled: true}}, auth)
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if err := call(inProc, Config{}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("missing key code = %v", status.Code(err))
+	}
+	if err := call(inProc, Config{Auth: AuthConfig{APIKey: "sk_svc"}}); err != nil {
+		t.Fatalf("configured client key: %v", err)
+	}
+
+	if _, err := start(Config{Mode: "microservice", Auth: AuthConfig{Enabled: true}}, nil); err == nil {
+		t.Fatal("expected start error when auth is enabled without api key auth")
+	}
+}
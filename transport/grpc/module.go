@@ -0,0 +1,36 @@
+package grpc
+
+import (
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+
+	"github.com/aisgo/ais-go-pkg/conf"
+)
+
+/* ========================================================================
+ * gRPC Module
+ * ========================================================================
+ * 职责: 提供 gRPC 服务器依赖注入模块
+ * Module: 提供监听器与 *grpc.Server 并确保其被实例化（OnStart 开始服务）
+ * Bundle: 从 conf.Source 的 grpc 节解码 Config（缺省见 DefaultConfig）
+ * ======================================================================== */
+
+// Module gRPC 服务器模块
+// 依赖: grpc.Config, *logger.Logger
+// 提供: *InProcListener, net.Listener, *grpc.Server
+var Module = fx.Module("grpc",
+	fx.Provide(
+		NewInProcListener,
+		NewListener,
+		NewServer,
+	),
+	fx.Invoke(func(*grpc.Server) {}),
+)
+
+// Bundle 带配置解码的 gRPC 服务器模块
+// 依赖: conf.Source, *logger.Logger
+// 提供: grpc.Config, *InProcListener, net.Listener, *grpc.Server
+var Bundle = fx.Module("grpc-bundle",
+	fx.Provide(conf.Section("grpc", DefaultConfig)),
+	Module,
+)
@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/aisgo/ais-go-pkg/requestid"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+/* ========================================================================
+ * Request ID Interceptors - 请求 ID 透传
+ * ========================================================================
+ * 职责: 服务端从 metadata 恢复请求 ID / 关联 ID 到 ctx（缺失时生成），
+ *       客户端将 ctx 中的 ID 写入 outgoing metadata
+ * 说明: NewServer 与 NewClientFactory 默认启用，无需手动配置
+ * ======================================================================== */
+
+// requestIDServerInterceptor 服务端请求 ID 拦截器
+func requestIDServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(requestIDFromIncoming(ctx), req)
+	}
+}
+
+// requestIDFromIncoming 从 incoming metadata 恢复 ID，缺失时生成新的请求 ID
+func requestIDFromIncoming(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = requestid.Extract(ctx, func(key string) string {
+		if vals := md.Get(key); len(vals) > 0 {
+			return vals[0]
+		}
+		return ""
+	})
+	if requestid.FromContext(ctx) == "" {
+		ctx = requestid.WithContext(ctx, requestid.New())
+	}
+	return ctx
+}
+
+// requestIDClientInterceptor 客户端请求 ID 拦截器（不覆盖已显式设置的 metadata）
+func requestIDClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(requestIDToOutgoing(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// requestIDToOutgoing 将 ctx 中的 ID 写入 outgoing metadata
+func requestIDToOutgoing(ctx context.Context) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	var pairs []string
+	requestid.Inject(ctx, func(key, value string) {
+		if len(md.Get(key)) == 0 {
+			pairs = append(pairs, key, value)
+		}
+	})
+	if len(pairs) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
@@ -4,18 +4,22 @@ import (
 	"context"
 	"fmt"
 	"net"
-	"runtime/debug"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
-	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // 注册 gzip 压缩器，服务端可解压并以同算法响应
+	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/keepalive"
-	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
 
 	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+	httpserver "github.com/aisgo/ais-go-pkg/transport/http"
+	"github.com/aisgo/ais-go-pkg/upgrade"
 
 	"go.uber.org/fx"
 	"go.uber.org/zap"
@@ -30,15 +34,143 @@ import (
 
 const bufSize = 1024 * 1024
 
+// defaultMaxMsgSize 默认最大消息大小（防止 OOM）
+const defaultMaxMsgSize = 16 * 1024 * 1024 // 16MB
+
+// defaultRequestTimeout 默认 Unary 请求处理超时
+const defaultRequestTimeout = 30 * time.Second
+
+// 扩展拦截器的 fx 分组名，追加在内置拦截器之后（组内顺序不保证）
+const (
+	UnaryInterceptorGroup  = "grpc_unary_interceptors"
+	StreamInterceptorGroup = "grpc_stream_interceptors"
+)
+
 type Config struct {
 	Port int    `yaml:"port"`
 	Mode string `yaml:"mode"` // monolith or microservice
+
+	// Reflection 是否注册 Server Reflection（开发环境开启，生产环境关闭）
+	Reflection bool `yaml:"reflection"`
+
+	// TLS 服务端与客户端 TLS/mTLS 配置（monolith 模式下忽略）
+	TLS TLSConfig `yaml:"tls"`
+
+	// Client 客户端调用策略（超时/重试/对冲/熔断），按 target 覆盖
+	Client ClientConfig `yaml:"client"`
+
+	// Discovery 服务发现与客户端负载均衡
+	Discovery DiscoveryConfig `yaml:"discovery"`
+
+	// Auth 服务端 API Key 认证与客户端携带的 Key，默认关闭
+	Auth AuthConfig `yaml:"auth"`
+
+	// MaxRecvMsgSize / MaxSendMsgSize 最大收发消息大小（字节），服务端与客户端共用，默认 16MB
+	MaxRecvMsgSize int `yaml:"max_recv_msg_size"`
+	MaxSendMsgSize int `yaml:"max_send_msg_size"`
+
+	// Compression 客户端请求压缩算法（"" 不压缩 / gzip），服务端始终支持已注册的压缩算法
+	Compression string `yaml:"compression"`
+
+	// Keepalive 服务端 keepalive 参数
+	Keepalive KeepaliveConfig `yaml:"keepalive"`
+
+	// RequestTimeout 客户端未携带 deadline 时 Unary 请求的处理超时，默认 30s，负数表示不限制
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+}
+
+// AuthConfig gRPC API Key 认证配置
+type AuthConfig struct {
+	// Enabled 服务端对业务方法校验 API Key（依赖已启用的 middleware.APIKeyAuth），monolith 模式下忽略
+	Enabled bool `yaml:"enabled"`
+	// APIKey ClientFactory 创建的客户端在 x-api-key 中携带的 Key，为空时不携带
+	APIKey string `yaml:"api_key"`
+}
+
+// DefaultConfig 返回默认配置（microservice 模式，监听 50051）
+func DefaultConfig() Config {
+	return Config{
+		Port: 50051,
+		Mode: "microservice",
+	}
+}
+
+// KeepaliveConfig 服务端 keepalive 参数，零值使用默认值
+type KeepaliveConfig struct {
+	MaxConnectionIdle     time.Duration `yaml:"max_connection_idle"`      // 空闲连接最大时间，默认 5m
+	MaxConnectionAge      time.Duration `yaml:"max_connection_age"`       // 连接最大生命周期，默认 30m
+	MaxConnectionAgeGrace time.Duration `yaml:"max_connection_age_grace"` // 优雅关闭等待时间，默认 10s
+	Time                  time.Duration `yaml:"time"`                     // 发送 ping 的间隔，默认 30s
+	Timeout               time.Duration `yaml:"timeout"`                  // ping 超时时间，默认 10s
+	MinTime               time.Duration `yaml:"min_time"`                 // 客户端 ping 最小间隔，默认 10s
+	PermitWithoutStream   *bool         `yaml:"permit_without_stream"`    // 允许没有活跃 stream 时 ping，默认 true
+}
+
+func (c KeepaliveConfig) withDefaults() KeepaliveConfig {
+	if c.MaxConnectionIdle <= 0 {
+		c.MaxConnectionIdle = 5 * time.Minute
+	}
+	if c.MaxConnectionAge <= 0 {
+		c.MaxConnectionAge = 30 * time.Minute
+	}
+	if c.MaxConnectionAgeGrace <= 0 {
+		c.MaxConnectionAgeGrace = 10 * time.Second
+	}
+	if c.Time <= 0 {
+		c.Time = 30 * time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.MinTime <= 0 {
+		c.MinTime = 10 * time.Second
+	}
+	if c.PermitWithoutStream == nil {
+		permit := true
+		c.PermitWithoutStream = &permit
+	}
+	return c
+}
+
+// requestTimeout 返回 Unary 请求处理超时，未配置时使用默认值，负数表示不限制
+func (c Config) requestTimeout() time.Duration {
+	if c.RequestTimeout == 0 {
+		return defaultRequestTimeout
+	}
+	return c.RequestTimeout
+}
+
+// maxMsgSizes 返回最大收发消息大小，未配置时使用默认值
+func (c Config) maxMsgSizes() (recv, send int) {
+	recv, send = c.MaxRecvMsgSize, c.MaxSendMsgSize
+	if recv <= 0 {
+		recv = defaultMaxMsgSize
+	}
+	if send <= 0 {
+		send = defaultMaxMsgSize
+	}
+	return recv, send
+}
+
+// AsUnaryInterceptor 将返回 grpc.UnaryServerInterceptor 的构造函数标注为扩展拦截器
+//
+//	fx.Provide(grpc.AsUnaryInterceptor(NewAuditInterceptor))
+func AsUnaryInterceptor(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"`+UnaryInterceptorGroup+`"`))
+}
+
+// AsStreamInterceptor 将返回 grpc.StreamServerInterceptor 的构造函数标注为扩展拦截器
+func AsStreamInterceptor(constructor any) any {
+	return fx.Annotate(constructor, fx.ResultTags(`group:"`+StreamInterceptorGroup+`"`))
 }
 
 type ListenerProviderParams struct {
 	fx.In
 	Config Config
 	Logger *logger.Logger
+
+	// Upgrader 可选的平滑升级器，启用时 TCP 监听器可在升级时交接给新进程
+	Upgrader *upgrade.Upgrader `optional:"true"`
 }
 
 // InProcListener 是一个全局的 bufconn 监听器，仅在 Monolith 模式下使用
@@ -58,7 +190,7 @@ func NewListener(p ListenerProviderParams, inProc *InProcListener) (net.Listener
 	}
 
 	p.Logger.Info("Using TCP gRPC Listener", zap.Int("port", p.Config.Port))
-	return net.Listen("tcp", fmt.Sprintf(":%d", p.Config.Port))
+	return p.Upgrader.Listen("grpc", "tcp", fmt.Sprintf(":%d", p.Config.Port))
 }
 
 type ServerParams struct {
@@ -66,78 +198,115 @@ type ServerParams struct {
 	Lc       fx.Lifecycle
 	Listener net.Listener
 	Logger   *logger.Logger
-}
+	Config   Config `optional:"true"`
 
-// recoveryInterceptor 创建 panic 恢复拦截器
-func recoveryInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		defer func() {
-			if r := recover(); r != nil {
-				log.Error("gRPC panic recovered",
-					zap.Any("panic", r),
-					zap.String("method", info.FullMethod),
-					zap.String("stack", string(debug.Stack())),
-				)
-				err = status.Errorf(codes.Internal, "internal server error")
-			}
-		}()
-		return handler(ctx, req)
-	}
-}
+	// Health 可选的健康检查服务，未提供时内部创建
+	Health *health.Server `optional:"true"`
 
-// loggingInterceptor 创建日志拦截器
-func loggingInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
-		start := time.Now()
-		resp, err = handler(ctx, req)
-		duration := time.Since(start)
+	// Readiness 可选的就绪开关，未提供时使用 httpserver.DefaultReadiness
+	Readiness *httpserver.Readiness `optional:"true"`
 
-		if err != nil {
-			log.Warn("gRPC request failed",
-				zap.String("method", info.FullMethod),
-				zap.Duration("duration", duration),
-				zap.Error(err),
-			)
-		} else if duration > 500*time.Millisecond {
-			// 记录慢请求
-			log.Warn("gRPC slow request",
-				zap.String("method", info.FullMethod),
-				zap.Duration("duration", duration),
-			)
-		}
+	// APIKeyAuth 可选的 API Key 认证，仅在 auth.enabled 时对全部业务方法校验 metadata 中的 Key
+	APIKeyAuth *middleware.APIKeyAuth `optional:"true"`
 
-		return resp, err
-	}
+	// ExtraUnaryInterceptors / ExtraStreamInterceptors 应用扩展拦截器，追加在内置拦截器之后
+	ExtraUnaryInterceptors  []grpc.UnaryServerInterceptor  `group:"grpc_unary_interceptors"`
+	ExtraStreamInterceptors []grpc.StreamServerInterceptor `group:"grpc_stream_interceptors"`
 }
 
 // NewServer 创建 gRPC Server 并管理生命周期
+// 启用 TLS 时证书在 OnStart 加载，加载失败将阻止启动
 func NewServer(p ServerParams) *grpc.Server {
-	// 配置拦截器: Recovery, Logging
+	// 配置拦截器: Request ID, Recovery, Logging, Metrics, Auth（Unary 与 Stream 对等）
+	// Unary 另在最外层补齐 deadline（Stream 为长连接，不设置）
+	unary := []grpc.UnaryServerInterceptor{
+		deadlineInterceptor(p.Config.requestTimeout()), // 补齐 deadline
+		requestIDServerInterceptor(),                   // 请求 ID 恢复
+		recoveryInterceptor(p.Logger),                  // Panic 恢复
+		loggingInterceptor(p.Logger),                   // 日志记录
+		metricsInterceptor(),                           // 指标
+	}
+	stream := []grpc.StreamServerInterceptor{
+		requestIDStreamInterceptor(),
+		recoveryStreamInterceptor(p.Logger),
+		loggingStreamInterceptor(p.Logger),
+		metricsStreamInterceptor(),
+	}
+	// 认证需显式开启；monolith 模式为进程内调用，不校验
+	if p.Config.Auth.Enabled && p.Config.Mode != "monolith" {
+		if p.APIKeyAuth.Enabled() {
+			unary = append(unary, authInterceptor(p.APIKeyAuth, p.Logger))
+			stream = append(stream, authStreamInterceptor(p.APIKeyAuth, p.Logger))
+		} else {
+			// 未提供 Key 配置时拒绝启动，避免误以为已开启认证
+			p.Lc.Append(fx.Hook{OnStart: func(context.Context) error {
+				return fmt.Errorf("grpc: auth is enabled but api key auth is not configured")
+			}})
+		}
+	}
+	unary = append(unary, p.ExtraUnaryInterceptors...)
+	stream = append(stream, p.ExtraStreamInterceptors...)
+
+	ka := p.Config.Keepalive.withDefaults()
+	maxRecv, maxSend := p.Config.maxMsgSizes()
+
 	opts := []grpc.ServerOption{
-		grpc.ChainUnaryInterceptor(
-			recoveryInterceptor(p.Logger), // Panic 恢复
-			loggingInterceptor(p.Logger),  // 日志记录
-		),
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
 		// Keepalive 配置，防止空闲连接堆积
 		grpc.KeepaliveParams(keepalive.ServerParameters{
-			MaxConnectionIdle:     5 * time.Minute,  // 空闲连接最大时间
-			MaxConnectionAge:      30 * time.Minute, // 连接最大生命周期
-			MaxConnectionAgeGrace: 10 * time.Second, // 优雅关闭等待时间
-			Time:                  30 * time.Second, // 发送 ping 的间隔
-			Timeout:               10 * time.Second, // ping 超时时间
+			MaxConnectionIdle:     ka.MaxConnectionIdle,
+			MaxConnectionAge:      ka.MaxConnectionAge,
+			MaxConnectionAgeGrace: ka.MaxConnectionAgeGrace,
+			Time:                  ka.Time,
+			Timeout:               ka.Timeout,
 		}),
 		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
-			MinTime:             10 * time.Second, // 客户端 ping 最小间隔
-			PermitWithoutStream: true,             // 允许没有活跃 stream 时 ping
+			MinTime:             ka.MinTime,
+			PermitWithoutStream: *ka.PermitWithoutStream,
 		}),
 		// 限制最大消息大小（防止 OOM）
-		grpc.MaxRecvMsgSize(16 * 1024 * 1024), // 16MB
-		grpc.MaxSendMsgSize(16 * 1024 * 1024), // 16MB
+		grpc.MaxRecvMsgSize(maxRecv),
+		grpc.MaxSendMsgSize(maxSend),
+	}
+
+	var reloader *certReloader
+	if p.Config.TLS.Enabled && p.Config.Mode != "monolith" {
+		reloader = newLazyCertReloader(p.Config.TLS, p.Logger)
+		tlsCfg, err := reloader.serverTLSConfig()
+		if err != nil {
+			// 配置错误延迟到 OnStart 返回
+			reloader = nil
+			p.Lc.Append(fx.Hook{OnStart: func(context.Context) error { return err }})
+		} else {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+		}
 	}
 	s := grpc.NewServer(opts...)
 
+	hs := p.Health
+	if hs == nil {
+		hs = NewHealthServer()
+	}
+	readiness := p.Readiness
+	if readiness == nil {
+		readiness = httpserver.DefaultReadiness
+	}
+	unsubscribeHealth := registerHealth(s, hs, readiness)
+	registerReflection(s, p.Config.Reflection)
+
 	p.Lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
+			if reloader != nil {
+				if err := reloader.reload(); err != nil {
+					return err
+				}
+				reloader.start()
+			}
+
+			// 此时业务服务均已注册
+			markServing(s, hs, readiness)
+
 			// 创建 channel 用于传递启动错误
 			errChan := make(chan error, 1)
 
@@ -164,7 +333,12 @@ func NewServer(p ServerParams) *grpc.Server {
 		},
 		OnStop: func(ctx context.Context) error {
 			p.Logger.Info("Stopping gRPC Server")
+			unsubscribeHealth()
+			hs.Shutdown()
 			s.GracefulStop()
+			if reloader != nil {
+				reloader.close()
+			}
 			return nil
 		},
 	})
@@ -175,16 +349,39 @@ func NewServer(p ServerParams) *grpc.Server {
 type ClientFactory func(target string) (*grpc.ClientConn, error)
 
 // NewClientFactory 返回一个创建 ClientConn 的函数
-// 如果是 Monolith 模式，自动使用 BufConn Dialer
-func NewClientFactory(cfg Config, inProc *InProcListener) ClientFactory {
+// 如果是 Monolith 模式，自动使用 BufConn Dialer；否则按 TLS 配置选择传输凭证，
+// 并按 Discovery 配置解析 target 与负载均衡策略；配置 auth.api_key 时每次调用携带该 Key
+func NewClientFactory(cfg Config, inProc *InProcListener, options ...ClientOption) ClientFactory {
+	var o clientOptions
+	for _, opt := range options {
+		opt(&o)
+	}
+
 	return func(target string) (*grpc.ClientConn, error) {
+		creds := insecure.NewCredentials()
+		if cfg.TLS.Enabled && cfg.Mode != "monolith" {
+			tlsCreds, err := NewClientCredentials(cfg.TLS)
+			if err != nil {
+				return nil, err
+			}
+			creds = tlsCreds
+		}
+
+		maxRecv, maxSend := cfg.maxMsgSizes()
+		callOpts := []grpc.CallOption{
+			grpc.MaxCallRecvMsgSize(maxRecv),
+			grpc.MaxCallSendMsgSize(maxSend),
+		}
+		if cfg.Compression != "" {
+			if encoding.GetCompressor(cfg.Compression) == nil {
+				return nil, fmt.Errorf("grpc: unknown compressor %q", cfg.Compression)
+			}
+			callOpts = append(callOpts, grpc.UseCompressor(cfg.Compression))
+		}
+
 		opts := []grpc.DialOption{
-			grpc.WithTransportCredentials(insecure.NewCredentials()),
-			// 添加默认超时配置
-			grpc.WithDefaultCallOptions(
-				grpc.MaxCallRecvMsgSize(16*1024*1024), // 16MB
-				grpc.MaxCallSendMsgSize(16*1024*1024), // 16MB
-			),
+			grpc.WithTransportCredentials(creds),
+			grpc.WithDefaultCallOptions(callOpts...),
 			// 添加连接超时配置
 			grpc.WithConnectParams(grpc.ConnectParams{
 				Backoff: backoff.Config{
@@ -194,6 +391,13 @@ func NewClientFactory(cfg Config, inProc *InProcListener) ClientFactory {
 				MinConnectTimeout: 10 * time.Second,
 			}),
 		}
+		chain := []grpc.UnaryClientInterceptor{requestIDClientInterceptor()}
+		if cfg.Auth.APIKey != "" {
+			chain = append(chain, apiKeyClientInterceptor(cfg.Auth.APIKey))
+			opts = append(opts, grpc.WithChainStreamInterceptor(apiKeyStreamClientInterceptor(cfg.Auth.APIKey)))
+		}
+		chain = append(chain, cfg.Client.policyFor(target).interceptors(target)...)
+		opts = append(opts, grpc.WithChainUnaryInterceptor(chain...))
 
 		if cfg.Mode == "monolith" {
 			// 在 Monolith 模式下，忽略 target IP，直接连接 InProcListener
@@ -202,6 +406,23 @@ func NewClientFactory(cfg Config, inProc *InProcListener) ClientFactory {
 			}))
 			// 使用 passthrough resolver，避免默认 dns resolver 导致 "produced zero addresses"
 			target = "passthrough:///bufconn"
+		} else {
+			resolved, err := cfg.Discovery.resolveTarget(target)
+			if err != nil {
+				return nil, err
+			}
+			target = resolved
+
+			sc, err := cfg.Discovery.serviceConfig()
+			if err != nil {
+				return nil, err
+			}
+			if sc != "" {
+				opts = append(opts, grpc.WithDefaultServiceConfig(sc))
+			}
+			if o.registry != nil {
+				opts = append(opts, grpc.WithResolvers(&registryBuilder{registry: o.registry}))
+			}
 		}
 
 		return grpc.NewClient(target, opts...)
@@ -2,48 +2,21 @@ package grpc
 
 import (
 	"context"
-	"errors"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/aisgo/ais-go-pkg/logger"
+	httpserver "github.com/aisgo/ais-go-pkg/transport/http"
 
+	"go.uber.org/fx"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
 	"google.golang.org/grpc/status"
 )
 
-func TestRecoveryInterceptor(t *testing.T) {
-	log := logger.NewNop()
-	interceptor := recoveryInterceptor(log)
-
-	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, func(ctx context.Context, req interface{}) (interface{}, error) {
-		panic("boom")
-	})
-	if err == nil {
-		t.Fatalf("expected error")
-	}
-	st, ok := status.FromError(err)
-	if !ok {
-		t.Fatalf("expected status error")
-	}
-	if st.Code() != codes.Internal {
-		t.Fatalf("unexpected code: %v", st.Code())
-	}
-}
-
-func TestLoggingInterceptor(t *testing.T) {
-	log := logger.NewNop()
-	interceptor := loggingInterceptor(log)
-
-	expectedErr := errors.New("fail")
-	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test.Service/Method"}, func(ctx context.Context, req interface{}) (interface{}, error) {
-		return nil, expectedErr
-	})
-	if !errors.Is(err, expectedErr) {
-		t.Fatalf("unexpected error: %v", err)
-	}
-}
-
 func TestNewListenerMonolith(t *testing.T) {
 	inProc := NewInProcListener()
 	listener, err := NewListener(ListenerProviderParams{
@@ -69,3 +42,138 @@ func TestNewListenerTCP(t *testing.T) {
 	}
 	defer listener.Close()
 }
+
+type testLifecycle struct {
+	hooks []fx.Hook
+}
+
+func (l *testLifecycle) Append(h fx.Hook) {
+	l.hooks = append(l.hooks, h)
+}
+
+func (l *testLifecycle) start(ctx context.Context) error {
+	for _, h := range l.hooks {
+		if h.OnStart != nil {
+			if err := h.OnStart(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (l *testLifecycle) stop(ctx context.Context) {
+	for _, h := range l.hooks {
+		if h.OnStop != nil {
+			_ = h.OnStop(ctx)
+		}
+	}
+}
+
+// startInProcServer 启动 bufconn gRPC 服务并返回客户端连接
+func startInProcServer(t *testing.T, cfg Config, readiness *httpserver.Readiness) *grpc.ClientConn {
+	t.Helper()
+
+	inProc := NewInProcListener()
+	lc := &testLifecycle{}
+	NewServer(ServerParams{
+		Lc:        lc,
+		Listener:  inProc.Listener,
+		Logger:    logger.NewNop(),
+		Config:    cfg,
+		Readiness: readiness,
+	})
+	if err := lc.start(context.Background()); err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(func() { lc.stop(context.Background()) })
+
+	conn, err := NewClientFactory(Config{Mode: "monolith"}, inProc)("ignored")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestServerOptionsAndExtraInterceptors(t *testing.T) {
+	var unaryCalls atomic.Int32
+	var app struct {
+		fx.In
+		Unary []grpc.UnaryServerInterceptor `group:"grpc_unary_interceptors"`
+	}
+	fxApp := fx.New(
+		fx.NopLogger,
+		fx.Provide(AsUnaryInterceptor(func() grpc.UnaryServerInterceptor {
+			return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				unaryCalls.Add(1)
+				return handler(ctx, req)
+			}
+		})),
+		fx.Populate(&app),
+	)
+	if err := fxApp.Err(); err != nil {
+		t.Fatalf("fx: %v", err)
+	}
+
+	cfg := Config{Mode: "monolith", MaxRecvMsgSize: 1024, Compression: "gzip"}
+	inProc := NewInProcListener()
+	lc := &testLifecycle{}
+	NewServer(ServerParams{
+		Lc:                     lc,
+		Listener:               inProc.Listener,
+		Logger:                 logger.NewNop(),
+		Config:                 cfg,
+		Readiness:              httpserver.NewReadiness(),
+		ExtraUnaryInterceptors: app.Unary,
+	})
+	if err := lc.start(context.Background()); err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	t.Cleanup(func() { lc.stop(context.Background()) })
+
+	conn, err := NewClientFactory(cfg, inProc)("ignored")
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := healthpb.NewHealthClient(conn)
+
+	if _, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatalf("gzip health check: %v", err)
+	}
+	if unaryCalls.Load() != 1 {
+		t.Fatalf("extra interceptor calls = %d", unaryCalls.Load())
+	}
+
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{Service: strings.Repeat("x", 2048)})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted for oversized message, got %v", err)
+	}
+
+	if _, err := NewClientFactory(Config{Mode: "monolith", Compression: "zstd"}, inProc)("ignored"); err == nil {
+		t.Fatal("expected error for unknown compressor")
+	}
+}
+
+func TestReflectionToggle(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		conn := startInProcServer(t, Config{Reflection: enabled}, httpserver.NewReadiness())
+		stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+		if err != nil {
+			t.Fatalf("open reflection stream: %v", err)
+		}
+		if err := stream.Send(&reflectionpb.ServerReflectionRequest{
+			MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+		}); err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		_, err = stream.Recv()
+		if enabled && err != nil {
+			t.Fatalf("expected reflection enabled: %v", err)
+		}
+		if !enabled && status.Code(err) != codes.Unimplemented {
+			t.Fatalf("expected Unimplemented when reflection disabled, got %v", err)
+		}
+	}
+}
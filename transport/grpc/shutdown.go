@@ -0,0 +1,63 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"google.golang.org/grpc"
+
+	"github.com/aisgo/ais-go-pkg/shutdown"
+	httpserver "github.com/aisgo/ais-go-pkg/transport/http"
+)
+
+/* ========================================================================
+ * gRPC Shutdown - 接入优雅关停
+ * ========================================================================
+ * 职责: 将 gRPC 服务器注册到 shutdown.Manager，并关联就绪开关
+ * 说明: 按 Priorities.GRPCServer 优先 GracefulStop，超时后强制 Stop
+ * ======================================================================== */
+
+// RegisterShutdown 注册 gRPC 服务器关停钩子
+func RegisterShutdown(m *shutdown.Manager, srv *grpc.Server) {
+	m.RegisterHookWithPriority("grpc-server", shutdown.Once(func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			srv.GracefulStop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			srv.Stop()
+			return ctx.Err()
+		}
+	}), *m.Priorities().GRPCServer)
+}
+
+// ShutdownParams 关停集成依赖参数
+type ShutdownParams struct {
+	fx.In
+
+	Lc      fx.Lifecycle
+	Manager *shutdown.Manager
+	Server  *grpc.Server
+
+	// Readiness 可选的就绪开关，未提供时使用 httpserver.DefaultReadiness
+	Readiness *httpserver.Readiness `optional:"true"`
+}
+
+// ShutdownModule gRPC 服务器关停集成模块
+// 需与 shutdown.Module 一起使用
+var ShutdownModule = fx.Module("grpc-shutdown",
+	fx.Invoke(func(p ShutdownParams) {
+		readiness := p.Readiness
+		if readiness == nil {
+			readiness = httpserver.DefaultReadiness
+		}
+		p.Manager.UseReadiness(readiness)
+		RegisterShutdown(p.Manager, p.Server)
+		p.Manager.ShutdownOnStop(p.Lc)
+	}),
+)
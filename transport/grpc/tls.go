@@ -0,0 +1,322 @@
+package grpc
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * gRPC TLS - 服务端/客户端 TLS 与 mTLS
+ * ========================================================================
+ * 职责: 构建 gRPC TLS 凭证，支持证书热加载
+ * 热加载:
+ *   - 收到 SIGHUP 时重新加载证书与 CA
+ *   - reload_interval > 0 时按间隔检查文件修改时间并重新加载
+ *   - 证书与 CA 全部加载校验通过后一并替换，任一失败时保留旧证书与 CA 并记录日志
+ * 配置示例:
+ *   grpc:
+ *     tls:
+ *       enabled: true
+ *       cert_file: /etc/certs/server.crt
+ *       key_file: /etc/certs/server.key
+ *       ca_file: /etc/certs/ca.crt          # 校验客户端证书（mTLS）/ 客户端校验服务端
+ *       client_auth: require_and_verify     # none | request | require | verify_if_given | require_and_verify
+ *       reload_interval: 1m
+ * ======================================================================== */
+
+// TLSConfig gRPC TLS 配置（服务端与客户端共用）
+type TLSConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	CertFile string `yaml:"cert_file"` // 服务端证书 / 客户端证书（mTLS）
+	KeyFile  string `yaml:"key_file"`  // 证书私钥
+	CAFile   string `yaml:"ca_file"`   // 服务端: 客户端 CA；客户端: 服务端 CA
+
+	// ClientAuth 服务端客户端认证模式，默认 none（配置 ca_file 时默认 require_and_verify）
+	ClientAuth string `yaml:"client_auth"`
+
+	// ServerName 客户端校验的服务端名称（默认取 target 主机名）
+	ServerName string `yaml:"server_name"`
+
+	// InsecureSkipVerify 客户端跳过服务端证书校验（仅限测试环境）
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+
+	// MinVersion TLS 最低版本，默认 TLS 1.2（771: TLS 1.2, 772: TLS 1.3）
+	MinVersion uint16 `yaml:"min_version"`
+
+	// ReloadInterval 证书文件变更检查间隔，0 表示仅响应 SIGHUP
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+// parseClientAuth 解析客户端认证模式
+func parseClientAuth(mode string, hasCA bool) (tls.ClientAuthType, error) {
+	switch strings.ToLower(mode) {
+	case "":
+		if hasCA {
+			return tls.RequireAndVerifyClientCert, nil
+		}
+		return tls.NoClientCert, nil
+	case "none":
+		return tls.NoClientCert, nil
+	case "request":
+		return tls.RequestClientCert, nil
+	case "require":
+		return tls.RequireAnyClientCert, nil
+	case "verify_if_given":
+		return tls.VerifyClientCertIfGiven, nil
+	case "require_and_verify":
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unknown client_auth mode: %s", mode)
+	}
+}
+
+func (c TLSConfig) minVersion() uint16 {
+	if c.MinVersion == 0 {
+		return tls.VersionTLS12
+	}
+	return c.MinVersion
+}
+
+// =============================================================================
+// 证书热加载
+// =============================================================================
+
+// tlsMaterial 一次加载得到的证书与 CA，作为整体原子替换
+type tlsMaterial struct {
+	cert   *tls.Certificate
+	caPool *x509.CertPool
+}
+
+// certReloader 证书与 CA 热加载器
+type certReloader struct {
+	cfg TLSConfig
+	log *logger.Logger
+
+	material atomic.Pointer[tlsMaterial]
+
+	mu      sync.Mutex
+	modTime time.Time
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// newCertReloader 创建热加载器并完成首次加载
+func newCertReloader(cfg TLSConfig, log *logger.Logger) (*certReloader, error) {
+	r := newLazyCertReloader(cfg, log)
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// newLazyCertReloader 创建热加载器，证书在首次 reload 时加载
+func newLazyCertReloader(cfg TLSConfig, log *logger.Logger) *certReloader {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	return &certReloader{cfg: cfg, log: log}
+}
+
+// reload 重新加载证书与 CA
+// 两者均加载校验通过后才一并替换，避免新证书与旧 CA（或反之）混用
+func (r *certReloader) reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var next tlsMaterial
+	if r.cfg.CertFile != "" || r.cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load tls key pair: %w", err)
+		}
+		next.cert = &cert
+	}
+
+	if r.cfg.CAFile != "" {
+		pem, err := os.ReadFile(r.cfg.CAFile)
+		if err != nil {
+			return fmt.Errorf("failed to read ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("failed to parse ca file")
+		}
+		next.caPool = pool
+	}
+
+	r.material.Store(&next)
+	r.modTime = r.latestModTime()
+	return nil
+}
+
+// current 返回当前证书与 CA，尚未加载时返回零值
+func (r *certReloader) current() tlsMaterial {
+	if m := r.material.Load(); m != nil {
+		return *m
+	}
+	return tlsMaterial{}
+}
+
+// latestModTime 返回证书相关文件的最新修改时间
+func (r *certReloader) latestModTime() time.Time {
+	var latest time.Time
+	for _, f := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.CAFile} {
+		if f == "" {
+			continue
+		}
+		if info, err := os.Stat(f); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// changed 文件是否有更新
+func (r *certReloader) changed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.latestModTime().After(r.modTime)
+}
+
+// start 启动 SIGHUP 与文件变更监听
+func (r *certReloader) start() {
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	if r.cfg.ReloadInterval > 0 {
+		ticker = time.NewTicker(r.cfg.ReloadInterval)
+		tick = ticker.C
+	}
+
+	go func() {
+		defer close(r.done)
+		defer signal.Stop(sigCh)
+		if ticker != nil {
+			defer ticker.Stop()
+		}
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-sigCh:
+				r.reloadAndLog("sighup")
+			case <-tick:
+				if r.changed() {
+					r.reloadAndLog("file_changed")
+				}
+			}
+		}
+	}()
+}
+
+// close 停止监听
+func (r *certReloader) close() {
+	if r.stop == nil {
+		return
+	}
+	close(r.stop)
+	<-r.done
+	r.stop = nil
+}
+
+func (r *certReloader) reloadAndLog(trigger string) {
+	if err := r.reload(); err != nil {
+		r.log.Error("gRPC TLS certificate reload failed, keep previous certificate",
+			zap.String("trigger", trigger), zap.Error(err))
+		return
+	}
+	r.log.Info("gRPC TLS certificate reloaded", zap.String("trigger", trigger))
+}
+
+// =============================================================================
+// 凭证构建
+// =============================================================================
+
+// serverTLSConfig 构建服务端 tls.Config（每次握手读取最新证书与 CA）
+func (r *certReloader) serverTLSConfig() (*tls.Config, error) {
+	if r.cfg.CertFile == "" || r.cfg.KeyFile == "" {
+		return nil, errors.New("server tls requires cert_file and key_file")
+	}
+	clientAuth, err := parseClientAuth(r.cfg.ClientAuth, r.cfg.CAFile != "")
+	if err != nil {
+		return nil, err
+	}
+
+	base := &tls.Config{
+		MinVersion: r.cfg.minVersion(),
+		ClientAuth: clientAuth,
+		NextProtos: []string{"h2"},
+	}
+	return &tls.Config{
+		MinVersion: base.MinVersion,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			m := r.current()
+			if m.cert == nil {
+				return nil, errors.New("server certificate not loaded")
+			}
+			cfg := base.Clone()
+			cfg.Certificates = []tls.Certificate{*m.cert}
+			cfg.ClientCAs = m.caPool
+			return cfg, nil
+		},
+	}, nil
+}
+
+// clientTLSConfig 构建客户端 tls.Config
+func (r *certReloader) clientTLSConfig() *tls.Config {
+	m := r.current()
+	cfg := &tls.Config{
+		MinVersion:         r.cfg.minVersion(),
+		ServerName:         r.cfg.ServerName,
+		InsecureSkipVerify: r.cfg.InsecureSkipVerify, //nolint:gosec // 仅测试环境显式开启
+		RootCAs:            m.caPool,
+	}
+	if m.cert != nil {
+		cfg.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return r.current().cert, nil
+		}
+	}
+	return cfg
+}
+
+// NewServerCredentials 创建服务端 TLS 凭证（不启动热加载监听）
+func NewServerCredentials(cfg TLSConfig) (credentials.TransportCredentials, error) {
+	r, err := newCertReloader(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg, err := r.serverTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+// NewClientCredentials 创建客户端 TLS 凭证
+func NewClientCredentials(cfg TLSConfig) (credentials.TransportCredentials, error) {
+	r, err := newCertReloader(cfg, nil)
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(r.clientTLSConfig()), nil
+}
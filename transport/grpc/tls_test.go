@@ -0,0 +1,172 @@
+package grpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	httpserver "github.com/aisgo/ais-go-pkg/transport/http"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// writeTestCert 签发证书并写入 dir，返回证书与私钥路径
+func writeTestCert(t *testing.T, dir, name string, tmpl *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (string, string, *x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certPath, keyPath, cert, key
+}
+
+func TestServerMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	caPath, _, caCert, caKey := writeTestCert(t, dir, "ca", &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	serverCert, serverKey, _, _ := writeTestCert(t, dir, "server", &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, caCert, caKey)
+	clientCert, clientKey, _, _ := writeTestCert(t, dir, "client", &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "client"},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, caCert, caKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	lc := &testLifecycle{}
+	NewServer(ServerParams{
+		Lc:       lc,
+		Listener: listener,
+		Logger:   logger.NewNop(),
+		Config: Config{TLS: TLSConfig{
+			Enabled:  true,
+			CertFile: serverCert,
+			KeyFile:  serverKey,
+			CAFile:   caPath,
+		}},
+		Readiness: httpserver.NewReadiness(),
+	})
+	if err := lc.start(context.Background()); err != nil {
+		t.Fatalf("start server: %v", err)
+	}
+	defer lc.stop(context.Background())
+
+	check := func(tlsCfg TLSConfig) error {
+		conn, err := NewClientFactory(Config{TLS: tlsCfg}, nil)(listener.Addr().String())
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	if err := check(TLSConfig{Enabled: true, CertFile: clientCert, KeyFile: clientKey, CAFile: caPath, ServerName: "localhost"}); err != nil {
+		t.Fatalf("mTLS health check failed: %v", err)
+	}
+	if err := check(TLSConfig{Enabled: true, CAFile: caPath, ServerName: "localhost"}); err == nil {
+		t.Fatalf("expected handshake failure without client certificate")
+	}
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	tmpl := func(serial int64) *x509.Certificate {
+		return &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			NotBefore:    now.Add(-time.Hour),
+			NotAfter:     now.Add(time.Hour),
+		}
+	}
+	certPath, keyPath, _, _ := writeTestCert(t, dir, "server", tmpl(1), nil, nil)
+
+	r, err := newCertReloader(TLSConfig{CertFile: certPath, KeyFile: keyPath}, logger.NewNop())
+	if err != nil {
+		t.Fatalf("new reloader: %v", err)
+	}
+
+	writeTestCert(t, dir, "server", tmpl(2), nil, nil)
+	// 失败的重载保留旧证书
+	r.cfg.KeyFile = filepath.Join(dir, "missing.key")
+	if err := r.reload(); err == nil {
+		t.Fatalf("expected reload error")
+	}
+	r.cfg.KeyFile = keyPath
+	if err := r.reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	serial := func() int64 {
+		leaf, _ := x509.ParseCertificate(r.current().cert.Certificate[0])
+		return leaf.SerialNumber.Int64()
+	}
+	if got := serial(); got != 2 {
+		t.Fatalf("expected reloaded certificate, got serial %v", got)
+	}
+
+	// 证书有效但 CA 无效时整体失败，不会只替换证书
+	writeTestCert(t, dir, "server", tmpl(3), nil, nil)
+	badCA := filepath.Join(dir, "bad-ca.crt")
+	if err := os.WriteFile(badCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write ca: %v", err)
+	}
+	r.cfg.CAFile = badCA
+	if err := r.reload(); err == nil {
+		t.Fatalf("expected reload error for invalid ca")
+	}
+	if got := serial(); got != 2 || r.current().caPool != nil {
+		t.Fatalf("expected previous certificate and ca kept, got serial %v", got)
+	}
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aisgo/ais-go-pkg/metrics"
+	"github.com/aisgo/ais-go-pkg/middleware"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Admin Listener - 内部管理端口
+ * ========================================================================
+ * 职责: 在独立端口运行第二个 Fiber 应用，承载运维端点，避免暴露在公网端口
+ *   - /healthz、/readyz  健康检查
+ *   - /metrics           Prometheus 指标
+ *   - /debug/*           调试端点（debug.enabled 为 true 时，访问控制同 MountDebug）
+ * 启用后上述端点不再挂载到业务端口；Prefork 模式下仅主进程监听管理端口
+ * 生命周期: 先于业务端口启动、晚于业务端口关闭，保证探针与指标覆盖完整的启停过程
+ * 配置示例:
+ *   http:
+ *     port: 8080
+ *     admin:
+ *       enabled: true
+ *       host: 0.0.0.0
+ *       port: 8081
+ * ======================================================================== */
+
+// defaultAdminPort 默认管理端口
+const defaultAdminPort = 8081
+
+// AdminConfig 管理端口配置
+type AdminConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Host    string `yaml:"host"` // 监听地址，默认全部网卡
+	Port    int    `yaml:"port"` // 监听端口，默认 8081
+}
+
+// AdminCustomizer 在管理端口应用上注册额外路由
+type AdminCustomizer func(admin *fiber.App)
+
+// addr 返回监听地址
+func (c AdminConfig) addr() string {
+	port := c.Port
+	if port <= 0 {
+		port = defaultAdminPort
+	}
+	return fmt.Sprintf("%s:%d", c.Host, port)
+}
+
+// mountOpsEndpoints 挂载健康检查、指标与调试端点
+func mountOpsEndpoints(app *fiber.App, p ServerParams, readiness *Readiness) {
+	registerHealthEndpoints(app, p.DB, readiness)
+	metrics.RegisterMetricsEndpointFor(app, p.Gatherer)
+
+	if p.Config.Debug.Enabled {
+		if err := MountDebug(app, p.Config.Debug, p.APIKeyAuth); err != nil {
+			p.Logger.Warn("Debug endpoints not mounted", zap.Error(err))
+		}
+	}
+}
+
+// newAdminApp 创建管理端口应用并注册生命周期
+func newAdminApp(p ServerParams, appName string, readiness *Readiness) *fiber.App {
+	admin := fiber.New(fiber.Config{AppName: appName + " Admin"})
+	if !p.Config.RequestID.Disabled {
+		admin.Use(middleware.RequestID(p.Config.RequestID))
+	}
+	mountOpsEndpoints(admin, p, readiness)
+	if p.AdminCustomizer != nil {
+		p.AdminCustomizer(admin)
+	}
+
+	addr := p.Config.Admin.addr()
+	p.Lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// Prefork 子进程共享父进程配置，管理端口只由主进程监听
+			if fiber.IsChild() {
+				return nil
+			}
+			p.Logger.Info("Starting HTTP Admin Server", zap.String("addr", addr))
+			listenConfig := buildListenConfig(ListenOptions{DisableStartupMessage: true})
+			if !p.Upgrader.Enabled() {
+				return startApp(ctx, addr, func() error { return admin.Listen(addr, listenConfig) }, p.Logger)
+			}
+			ln, err := createListener(ListenOptions{}, addr, p.Upgrader, adminListenerName, nil, p.Logger)
+			if err != nil {
+				return err
+			}
+			return startApp(ctx, addr, func() error { return admin.Listener(ln, listenConfig) }, p.Logger)
+		},
+		OnStop: func(ctx context.Context) error {
+			if fiber.IsChild() {
+				return nil
+			}
+			p.Logger.Info("Stopping HTTP Admin Server")
+			return admin.ShutdownWithContext(ctx)
+		},
+	})
+	return admin
+}
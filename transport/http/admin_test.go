@@ -0,0 +1,58 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestAdminListener(t *testing.T) {
+	port := freePort(t)
+	lc := fxtest.NewLifecycle(t)
+	app := NewHTTPServer(ServerParams{
+		Lc:     lc,
+		Logger: logger.NewNop(),
+		Config: Config{
+			Port:   0,
+			Host:   "127.0.0.1",
+			Listen: ListenOptions{DisableStartupMessage: true},
+			Admin:  AdminConfig{Enabled: true, Host: "127.0.0.1", Port: port},
+		},
+		Readiness: NewReadiness(),
+		AdminCustomizer: func(admin *fiber.App) {
+			admin.Get("/admin/ping", func(c fiber.Ctx) error { return c.SendString("pong") })
+		},
+	})
+	lc.RequireStart()
+	defer lc.RequireStop()
+
+	// 业务端口不再暴露运维端点
+	for _, path := range []string{"/healthz", "/metrics"} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil), fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != fiber.StatusNotFound {
+			t.Fatalf("%s on public port: unexpected status %d", path, resp.StatusCode)
+		}
+	}
+
+	for _, path := range []string{"/healthz", "/readyz", "/metrics", "/admin/ping"} {
+		resp, err := http.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, path))
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s on admin port: unexpected status %d", path, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/aisgo/ais-go-pkg/buildinfo"
+	"github.com/aisgo/ais-go-pkg/middleware"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/expvar"
+	"github.com/gofiber/fiber/v3/middleware/pprof"
+)
+
+/* ========================================================================
+ * Debug Endpoints - 运行时调试端点
+ * ========================================================================
+ * 端点:
+ *   - /debug/pprof/*  Go pprof 性能分析
+ *   - /debug/vars     expvar 运行时变量
+ *   - /debug/build    构建信息（见 buildinfo 包）
+ * 访问控制: 客户端 IP 命中 allow_cidrs，或通过 API Key 认证（APIKeyAuth 已启用时）
+ *           两者均未配置时不挂载端点（避免生产环境裸露）
+ * 注意: 部署在代理之后时需配置 http.proxy（或 Fiber ProxyHeader / TrustProxy），保证 c.IP() 为真实客户端 IP
+ * 配置示例:
+ *   http:
+ *     debug:
+ *       enabled: true
+ *       allow_cidrs: ["10.0.0.0/8", "127.0.0.1/32"]
+ * ======================================================================== */
+
+// DebugConfig 调试端点配置
+type DebugConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	AllowCIDRs []string `yaml:"allow_cidrs"` // 允许访问的客户端网段
+}
+
+// MountDebug 挂载 /debug 调试端点
+// auth 为 nil 或未启用时仅按 AllowCIDRs 放行；两者均未配置时返回错误且不挂载
+func MountDebug(app *fiber.App, cfg DebugConfig, auth *middleware.APIKeyAuth) error {
+	guard, err := debugGuard(cfg, auth)
+	if err != nil {
+		return err
+	}
+
+	app.Get("/debug/build", guard, func(c fiber.Ctx) error {
+		return c.JSON(buildinfo.Get())
+	})
+	app.Use("/debug", guard, pprof.New(), expvar.New())
+	return nil
+}
+
+// debugGuard 构建访问控制：IP 白名单优先，未命中时要求 API Key
+func debugGuard(cfg DebugConfig, auth *middleware.APIKeyAuth) (fiber.Handler, error) {
+	nets, err := middleware.ParseCIDRs(cfg.AllowCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid debug allow_cidrs: %w", err)
+	}
+
+	useAPIKey := auth.Enabled()
+	if len(nets) == 0 && !useAPIKey {
+		return nil, fmt.Errorf("debug endpoints require allow_cidrs or an enabled api key auth")
+	}
+
+	var authHandler fiber.Handler
+	if useAPIKey {
+		authHandler = auth.Authenticate()
+	}
+
+	return func(c fiber.Ctx) error {
+		if ip := net.ParseIP(c.IP()); ip != nil {
+			for _, n := range nets {
+				if n.Contains(ip) {
+					return c.Next()
+				}
+			}
+		}
+		if authHandler != nil {
+			return authHandler(c)
+		}
+		return fiber.ErrForbidden
+	}, nil
+}
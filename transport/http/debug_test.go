@@ -0,0 +1,66 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/buildinfo"
+	"github.com/aisgo/ais-go-pkg/middleware"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestDebugEndpoints(t *testing.T) {
+	if err := MountDebug(fiber.New(), DebugConfig{Enabled: true}, nil); err == nil {
+		t.Fatalf("expected debug endpoints without guard to be rejected")
+	}
+	if err := MountDebug(fiber.New(), DebugConfig{AllowCIDRs: []string{"bad"}}, nil); err == nil {
+		t.Fatalf("expected invalid cidr error")
+	}
+
+	// app.Test 的客户端地址为 0.0.0.0
+	allowed := fiber.New()
+	if err := MountDebug(allowed, DebugConfig{AllowCIDRs: []string{"0.0.0.0"}}, nil); err != nil {
+		t.Fatalf("mount: %v", err)
+	}
+	resp, err := allowed.Test(httptest.NewRequest("GET", "/debug/build", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	var build map[string]string
+	_ = json.NewDecoder(resp.Body).Decode(&build)
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || build["version"] != buildinfo.Get().Version || build["go_version"] == "" {
+		t.Fatalf("unexpected build response: %d %v", resp.StatusCode, build)
+	}
+
+	auth := middleware.NewAPIKeyAuth(&middleware.APIKeyConfig{Enabled: true, Keys: map[string]string{"ops": "secret"}}, nil)
+	guarded := fiber.New()
+	if err := MountDebug(guarded, DebugConfig{AllowCIDRs: []string{"10.0.0.0/8"}}, auth); err != nil {
+		t.Fatalf("mount: %v", err)
+	}
+	for _, tc := range []struct {
+		path, key string
+		status    int
+	}{
+		{"/debug/vars", "", fiber.StatusUnauthorized},
+		{"/debug/vars", "secret", fiber.StatusOK},
+		{"/debug/pprof/", "secret", fiber.StatusOK},
+		{"/debug/build", "wrong", fiber.StatusUnauthorized},
+	} {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.key != "" {
+			req.Header.Set("X-API-Key", tc.key)
+		}
+		resp, err := guarded.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", tc.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Fatalf("%s with key %q: unexpected status %d", tc.path, tc.key, resp.StatusCode)
+		}
+	}
+}
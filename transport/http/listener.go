@@ -0,0 +1,236 @@
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/upgrade"
+)
+
+/* ========================================================================
+ * Listeners - Unix Socket 与 systemd socket activation
+ * ========================================================================
+ * 职责: 为 sidecar / 零停机重启场景创建监听器
+ *   - Unix Socket: 监听 unix_socket_path（忽略 host/port），启动前清理残留 socket 文件
+ *                  （路径存在且无进程监听时删除；仍在使用或不是 socket 时报错），
+ *                  创建后按 unix_socket_file_mode 设置权限
+ *   - systemd:     systemd_activation 为 true 且进程由 systemd 以 LISTEN_FDS 启动时，
+ *                  继承已打开的监听器（按 systemd_socket_name 匹配 FileDescriptorName，
+ *                  为空时取第一个）；未检测到 LISTEN_FDS 时回退为常规监听，便于本地开发
+ * 自定义监听器不支持 Prefork；配置 cert_file 时在监听器上启用 TLS
+ * 提供 upgrade.Upgrader 时监听器登记为可交接（见 upgrade 包）
+ * 配置示例:
+ *   http:
+ *     listen:
+ *       unix_socket_path: /run/app/http.sock
+ *       unix_socket_file_mode: 0660
+ *   # 或 systemd socket activation（app.socket: ListenStream=8080, FileDescriptorName=http）
+ *   http:
+ *     listen:
+ *       systemd_activation: true
+ *       systemd_socket_name: http
+ * ======================================================================== */
+
+const (
+	// listenFDsStart systemd 传入文件描述符的起始编号（SD_LISTEN_FDS_START）
+	listenFDsStart = 3
+	// defaultUnixSocketFileMode 默认 Unix Socket 文件权限
+	defaultUnixSocketFileMode = 0o770
+)
+
+// systemdFD systemd 传入的文件描述符
+type systemdFD struct {
+	fd   int
+	name string
+}
+
+// parseSystemdFDs 解析 LISTEN_PID / LISTEN_FDS / LISTEN_FDNAMES
+// LISTEN_PID 存在且不等于 pid 时视为传给其他进程，返回空
+func parseSystemdFDs(getenv func(string) string, pid int) ([]systemdFD, error) {
+	countStr := getenv("LISTEN_FDS")
+	if countStr == "" {
+		return nil, nil
+	}
+	if pidStr := getenv("LISTEN_PID"); pidStr != "" {
+		listenPID, err := strconv.Atoi(pidStr)
+		if err != nil {
+			return nil, fmt.Errorf("http: invalid LISTEN_PID %q", pidStr)
+		}
+		if listenPID != pid {
+			return nil, nil
+		}
+	}
+	count, err := strconv.Atoi(countStr)
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("http: invalid LISTEN_FDS %q", countStr)
+	}
+
+	var names []string
+	if v := getenv("LISTEN_FDNAMES"); v != "" {
+		names = strings.Split(v, ":")
+	}
+	fds := make([]systemdFD, count)
+	for i := range fds {
+		fds[i] = systemdFD{fd: listenFDsStart + i}
+		if i < len(names) {
+			fds[i].name = names[i]
+		}
+	}
+	return fds, nil
+}
+
+// systemdListeners 进程继承的监听器，首次使用时解析，每个描述符只能被取用一次
+var systemdListeners struct {
+	once sync.Once
+	mu   sync.Mutex
+	fds  []systemdFD
+	err  error
+}
+
+// takeSystemdListener 取用名称匹配的继承监听器，name 为空时取第一个
+// 未由 systemd 启动时返回 nil
+func takeSystemdListener(name string) (net.Listener, error) {
+	systemdListeners.once.Do(func() {
+		systemdListeners.fds, systemdListeners.err = parseSystemdFDs(os.Getenv, os.Getpid())
+		// 避免传递给子进程
+		for _, key := range []string{"LISTEN_PID", "LISTEN_FDS", "LISTEN_FDNAMES"} {
+			_ = os.Unsetenv(key)
+		}
+	})
+	if systemdListeners.err != nil {
+		return nil, systemdListeners.err
+	}
+
+	systemdListeners.mu.Lock()
+	defer systemdListeners.mu.Unlock()
+	fds := systemdListeners.fds
+	if len(fds) == 0 {
+		return nil, nil
+	}
+	for i, sd := range fds {
+		if name != "" && sd.name != name {
+			continue
+		}
+		systemdListeners.fds = append(fds[:i:i], fds[i+1:]...)
+		f := os.NewFile(uintptr(sd.fd), "systemd:"+sd.name)
+		defer f.Close()
+		// FileListener 复制描述符，原文件可关闭
+		return net.FileListener(f)
+	}
+	return nil, fmt.Errorf("http: systemd socket %q not found", name)
+}
+
+// listenUnix 清理残留 socket 文件后监听 Unix Socket 并设置权限
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if fi, err := os.Lstat(path); err == nil {
+		if fi.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("http: %s exists and is not a unix socket", path)
+		}
+		if conn, err := net.DialTimeout("unix", path, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("http: unix socket %s is in use", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("http: remove stale unix socket: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode == 0 {
+		mode = defaultUnixSocketFileMode
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("http: chmod unix socket: %w", err)
+	}
+	return ln, nil
+}
+
+// customListener 是否需要自行创建监听器（而非交给 Fiber 的 app.Listen）
+func customListener(opts ListenOptions, upg *upgrade.Upgrader, proxy *proxyResolver) bool {
+	return opts.UnixSocketPath != "" || opts.SystemdActivation || upg.Enabled() || (proxy != nil && proxy.enabled)
+}
+
+// createListener 创建监听器，按需解析 PROXY 头，并按证书配置启用 TLS
+// 优先级: 升级时父进程传入的同名监听器 → systemd 继承 → Unix Socket → TCP；upg 为 nil 时不登记交接
+// PROXY 头位于 TLS 握手之前，因此 PROXY 解析包装在 TLS 之下
+func createListener(opts ListenOptions, addr string, upg *upgrade.Upgrader, name string, proxy *proxyResolver, log *logger.Logger) (net.Listener, error) {
+	ln, err := upg.ListenWith(name, func() (net.Listener, error) {
+		return baseListener(opts, addr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ln = proxy.wrapListener(ln, log)
+
+	if opts.CertFile == "" {
+		return ln, nil
+	}
+	tlsCfg, err := listenerTLSConfig(opts)
+	if err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return tls.NewListener(ln, tlsCfg), nil
+}
+
+// baseListener 按优先级 systemd 继承 → Unix Socket → TCP 创建监听器
+func baseListener(opts ListenOptions, addr string) (net.Listener, error) {
+	if opts.SystemdActivation {
+		inherited, err := takeSystemdListener(opts.SystemdSocketName)
+		if err != nil || inherited != nil {
+			return inherited, err
+		}
+	}
+	if opts.UnixSocketPath != "" {
+		return listenUnix(opts.UnixSocketPath, os.FileMode(opts.UnixSocketFileMode))
+	}
+	network := opts.ListenerNetwork
+	if network == "" {
+		network = "tcp4"
+	}
+	return net.Listen(network, addr)
+}
+
+// listenerTLSConfig 按 ListenOptions 构建 TLS 配置（与 Fiber app.Listen 的证书语义一致）
+func listenerTLSConfig(opts ListenOptions) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.CertKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("http: load certificate: %w", err)
+	}
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+	if opts.TLSMinVersion > 0 {
+		cfg.MinVersion = opts.TLSMinVersion
+	}
+	if opts.CertClientFile != "" {
+		pem, err := os.ReadFile(opts.CertClientFile)
+		if err != nil {
+			return nil, fmt.Errorf("http: read client ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("http: invalid client ca %s", opts.CertClientFile)
+		}
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = pool
+	}
+	return cfg, nil
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+
+	"go.uber.org/fx/fxtest"
+)
+
+func TestParseSystemdFDs(t *testing.T) {
+	env := func(kv map[string]string) func(string) string {
+		return func(k string) string { return kv[k] }
+	}
+
+	fds, err := parseSystemdFDs(env(map[string]string{}), 100)
+	if err != nil || fds != nil {
+		t.Fatalf("expected no fds without LISTEN_FDS: %v %v", fds, err)
+	}
+	fds, err = parseSystemdFDs(env(map[string]string{"LISTEN_FDS": "2", "LISTEN_PID": "99"}), 100)
+	if err != nil || fds != nil {
+		t.Fatalf("expected fds for other pid to be ignored: %v %v", fds, err)
+	}
+	fds, err = parseSystemdFDs(env(map[string]string{"LISTEN_FDS": "2", "LISTEN_PID": "100", "LISTEN_FDNAMES": "http:admin"}), 100)
+	if err != nil || len(fds) != 2 || fds[0] != (systemdFD{fd: 3, name: "http"}) || fds[1] != (systemdFD{fd: 4, name: "admin"}) {
+		t.Fatalf("unexpected fds: %v %v", fds, err)
+	}
+	if _, err := parseSystemdFDs(env(map[string]string{"LISTEN_FDS": "x"}), 100); err == nil {
+		t.Fatal("expected invalid LISTEN_FDS error")
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run", "http.sock")
+
+	ln, err := listenUnix(path, 0o660)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o660 {
+		t.Fatalf("unexpected socket mode: %v %v", fi, err)
+	}
+	// 仍在监听时拒绝覆盖
+	if _, err := listenUnix(path, 0); err == nil {
+		t.Fatal("expected in-use error")
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+
+	// 残留的 socket 文件被清理
+	ln, err = listenUnix(path, 0)
+	if err != nil {
+		t.Fatalf("listen over stale socket: %v", err)
+	}
+	ln.Close()
+
+	regular := filepath.Join(t.TempDir(), "file")
+	_ = os.WriteFile(regular, nil, 0o600)
+	if _, err := listenUnix(regular, 0); err == nil {
+		t.Fatal("expected error for non-socket path")
+	}
+}
+
+func TestUnixSocketServer(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "http.sock")
+	lc := fxtest.NewLifecycle(t)
+	NewHTTPServer(ServerParams{
+		Lc:        lc,
+		Logger:    logger.NewNop(),
+		Config:    Config{Listen: ListenOptions{DisableStartupMessage: true, UnixSocketPath: path}},
+		Readiness: NewReadiness(),
+	})
+	lc.RequireStart()
+	defer lc.RequireStop()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		},
+	}}
+	resp, err := client.Get("http://unix/healthz")
+	if err != nil {
+		t.Fatalf("get over unix socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status: %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,32 @@
+package http
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/conf"
+)
+
+/* ========================================================================
+ * HTTP Module
+ * ========================================================================
+ * 职责: 提供 HTTP 服务器依赖注入模块
+ * Module: 提供 *fiber.App 并确保其被实例化（OnStart 开始监听）
+ * Bundle: 从 conf.Source 的 http 节解码 Config（缺省见 DefaultConfig）
+ * ======================================================================== */
+
+// Module HTTP 服务器模块
+// 依赖: http.Config, *logger.Logger
+// 提供: *fiber.App
+var Module = fx.Module("http",
+	fx.Provide(NewHTTPServer),
+	fx.Invoke(func(*fiber.App) {}),
+)
+
+// Bundle 带配置解码的 HTTP 服务器模块
+// 依赖: conf.Source, *logger.Logger
+// 提供: http.Config, *fiber.App
+var Bundle = fx.Module("http-bundle",
+	fx.Provide(conf.Section("http", DefaultConfig)),
+	Module,
+)
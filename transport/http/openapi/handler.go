@@ -0,0 +1,92 @@
+package openapi
+
+import (
+	"bytes"
+	"html/template"
+	"slices"
+
+	"github.com/aisgo/ais-go-pkg/buildinfo"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Config 文档配置
+type Config struct {
+	Enabled     bool     `yaml:"enabled"`       // fx 模块是否自动挂载文档端点
+	Title       string   `yaml:"title"`         // 默认 API
+	Version     string   `yaml:"version"`       // 默认取 buildinfo 版本
+	Description string   `yaml:"description"`   // 文档描述
+	Servers     []string `yaml:"servers"`       // 服务地址
+	Path        string   `yaml:"path"`          // 文档路径，默认 /openapi.json
+	UIPath      string   `yaml:"ui_path"`       // Swagger UI 路径，默认 /docs，"-" 表示不挂载
+	UIAssetsURL string   `yaml:"ui_assets_url"` // Swagger UI 静态资源地址，默认 unpkg CDN
+	Auth        bool     `yaml:"auth"`          // 文档端点是否要求 API Key 认证
+}
+
+const defaultUIAssetsURL = "https://unpkg.com/swagger-ui-dist@5"
+
+func (c *Config) withDefaults() Config {
+	var cfg Config
+	if c != nil {
+		cfg = *c
+	}
+	if cfg.Title == "" {
+		cfg.Title = "API"
+	}
+	if cfg.Version == "" {
+		cfg.Version = buildinfo.Get().Version
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/openapi.json"
+	}
+	if cfg.UIPath == "" {
+		cfg.UIPath = "/docs"
+	}
+	if cfg.UIAssetsURL == "" {
+		cfg.UIAssetsURL = defaultUIAssetsURL
+	}
+	return cfg
+}
+
+// Mount 在 router 上挂载文档端点，guards 为访问控制中间件（如 APIKeyAuth.Authenticate()）
+func Mount(router fiber.Router, reg *Registry, guards ...any) {
+	cfg := reg.Config()
+
+	handlers := slices.Concat(guards, []any{func(c fiber.Ctx) error {
+		data, err := reg.JSON()
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(data)
+	}})
+	router.Get(cfg.Path, handlers[0], handlers[1:]...)
+
+	if cfg.UIPath == "-" {
+		return
+	}
+	page := map[string]string{
+		"Title":  cfg.Title,
+		"Assets": cfg.UIAssetsURL,
+		"Spec":   joinPath(routerPrefix(router), cfg.Path),
+	}
+	handlers = slices.Concat(guards, []any{func(c fiber.Ctx) error {
+		var html bytes.Buffer
+		if err := uiTemplate.Execute(&html, page); err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.Send(html.Bytes())
+	}})
+	router.Get(cfg.UIPath, handlers[0], handlers[1:]...)
+}
+
+// uiTemplate Swagger UI 页面
+var uiTemplate = template.Must(template.New("swagger-ui").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<link rel="stylesheet" href="{{.Assets}}/swagger-ui.css">
+</head><body>
+<div id="swagger-ui"></div>
+<script src="{{.Assets}}/swagger-ui-bundle.js"></script>
+<script>window.ui = SwaggerUIBundle({url: {{.Spec}}, dom_id: "#swagger-ui", persistAuthorization: true});</script>
+</body></html>`))
@@ -0,0 +1,64 @@
+package openapi
+
+import (
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/fx"
+)
+
+/* ========================================================================
+ * OpenAPI FX Module - API 文档 FX 模块
+ * ========================================================================
+ * 职责: 提供 *Registry；enabled 为 true 且存在 *fiber.App 时挂载文档端点
+ * 说明: 文档在首次请求时生成，晚于挂载注册的路由同样会出现在文档中
+ * ======================================================================== */
+
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	Config *Config `optional:"true"`
+}
+
+// NewFromParams 从 FX 参数创建 Registry
+func NewFromParams(p Params) *Registry {
+	return New(p.Config)
+}
+
+// MountParams 挂载依赖参数
+type MountParams struct {
+	fx.In
+
+	App        *fiber.App `optional:"true"`
+	Registry   *Registry
+	APIKeyAuth *middleware.APIKeyAuth `optional:"true"`
+	Logger     *logger.Logger         `optional:"true"`
+}
+
+// MountFromParams 按配置挂载文档端点
+func MountFromParams(p MountParams) {
+	cfg := p.Registry.Config()
+	if !cfg.Enabled || p.App == nil {
+		return
+	}
+	if !cfg.Auth {
+		Mount(p.App, p.Registry)
+		return
+	}
+	if !p.APIKeyAuth.Enabled() {
+		// 要求认证但未启用 API Key 时不挂载，避免文档裸露
+		if p.Logger != nil {
+			p.Logger.Warn("OpenAPI endpoints not mounted: auth required but APIKeyAuth is not enabled")
+		}
+		return
+	}
+	Mount(p.App, p.Registry, p.APIKeyAuth.Authenticate())
+}
+
+// Module FX 模块
+var Module = fx.Module("openapi",
+	fx.Provide(NewFromParams),
+	fx.Invoke(MountFromParams),
+)
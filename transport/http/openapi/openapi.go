@@ -0,0 +1,412 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * OpenAPI Registry - 基于路由元数据的 API 文档
+ * ========================================================================
+ * 职责: handler 注册时声明操作元数据（方法、路径、请求/响应类型），
+ *       运行时反射生成 OpenAPI 3.0 文档，无需额外的代码生成工具链
+ * 字段映射:
+ *   - json 标签决定字段名，doc 标签为描述，example 标签为示例
+ *   - validate 标签: required → 必填；min/max/len → 长度或数值范围；oneof → 枚举；email/url/uuid → format
+ *   - 参数结构体: query / params / header 标签分别生成查询、路径、请求头参数
+ * 响应: 默认包裹 response.Result 信封（data 为声明的响应类型），Raw 为 true 时不包裹
+ *
+ * 使用示例:
+ *   type CreateUserReq struct {
+ *       Name  string `json:"name" validate:"required,max=64" doc:"用户名"`
+ *       Email string `json:"email" validate:"required,email" doc:"邮箱"`
+ *   }
+ *   reg.Route(api, openapi.Operation{
+ *       Method:   fiber.MethodPost,
+ *       Path:     "/users",
+ *       Summary:  "创建用户",
+ *       Tags:     []string{"用户"},
+ *       Body:     CreateUserReq{},
+ *       Response: User{},
+ *       Secured:  true,
+ *   }, createUser)
+ *   openapi.Mount(app, reg) // GET /openapi.json 与 GET /docs（Swagger UI）
+ * ======================================================================== */
+
+// Operation 接口操作元数据
+type Operation struct {
+	Method      string
+	Path        string // Fiber 路由路径（如 /users/:id），通过 Route 注册时为相对路径
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	Deprecated  bool
+	Secured     bool // 需要 API Key 认证
+
+	Params  any // 路径参数结构体（params 标签），未声明时按路径生成字符串参数
+	Query   any // 查询参数结构体（query 标签）
+	Headers any // 请求头参数结构体（header 标签）
+	Body    any // 请求体类型实例
+
+	Response any            // 成功响应数据类型实例，nil 表示无数据
+	Status   int            // 成功状态码，默认 200
+	Raw      bool           // 响应不包裹 response.Result 信封
+	Errors   map[int]string // 额外的错误响应（状态码 → 说明，为空时使用标准状态文本）
+}
+
+// Document OpenAPI 3.0 文档
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Tags       []Tag                `json:"tags,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+// Info 文档信息
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Server 服务地址
+type Server struct {
+	URL string `json:"url"`
+}
+
+// Tag 分组标签
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem 路径下的操作（键为小写方法名）
+type PathItem map[string]*OperationObject
+
+// OperationObject 文档中的操作
+type OperationObject struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// RequestBody 请求体
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response 响应
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType 内容类型
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components 可复用组件
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme 认证方式
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Scheme string `json:"scheme,omitempty"`
+}
+
+// 认证方式名称（与 middleware.APIKeyAuth 支持的两种传递方式对应）
+const (
+	securityAPIKey = "ApiKeyAuth"
+	securityBearer = "BearerAuth"
+)
+
+const mimeJSON = "application/json"
+
+// Registry 操作注册表
+type Registry struct {
+	cfg Config
+
+	mu     sync.RWMutex
+	ops    []Operation
+	cached []byte
+}
+
+// New 创建注册表
+func New(cfg *Config) *Registry {
+	return &Registry{cfg: cfg.withDefaults()}
+}
+
+// Config 返回生效配置
+func (r *Registry) Config() Config {
+	return r.cfg
+}
+
+// Add 注册操作元数据（Path 为完整路径）
+func (r *Registry) Add(op Operation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops = append(r.ops, op)
+	r.cached = nil
+}
+
+// Route 在 router 上注册路由并记录文档；router 为路由组时自动拼接组前缀
+func (r *Registry) Route(router fiber.Router, op Operation, handler any, handlers ...any) fiber.Router {
+	router.Add([]string{op.Method}, op.Path, handler, handlers...)
+	op.Path = joinPath(routerPrefix(router), op.Path)
+	r.Add(op)
+	return router
+}
+
+// Operations 返回已注册的操作
+func (r *Registry) Operations() []Operation {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Operation(nil), r.ops...)
+}
+
+// JSON 返回序列化后的文档（注册新操作前缓存）
+func (r *Registry) JSON() ([]byte, error) {
+	r.mu.RLock()
+	cached := r.cached
+	r.mu.RUnlock()
+	if cached != nil {
+		return cached, nil
+	}
+
+	data, err := json.Marshal(r.Document())
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.cached = data
+	r.mu.Unlock()
+	return data, nil
+}
+
+// Document 生成 OpenAPI 文档
+func (r *Registry) Document() *Document {
+	ops := r.Operations()
+	gen := newSchemaGenerator()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: r.cfg.Title, Version: r.cfg.Version, Description: r.cfg.Description},
+		Paths:   make(map[string]*PathItem),
+	}
+	for _, url := range r.cfg.Servers {
+		doc.Servers = append(doc.Servers, Server{URL: url})
+	}
+
+	tags := make(map[string]struct{})
+	secured := false
+	for _, op := range ops {
+		path, pathParams := convertPath(op.Path)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = &PathItem{}
+			doc.Paths[path] = item
+		}
+		(*item)[strings.ToLower(op.Method)] = r.operation(gen, op, pathParams)
+
+		for _, tag := range op.Tags {
+			tags[tag] = struct{}{}
+		}
+		secured = secured || op.Secured
+	}
+
+	names := make([]string, 0, len(tags))
+	for name := range tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		doc.Tags = append(doc.Tags, Tag{Name: name})
+	}
+
+	doc.Components.Schemas = gen.schemas
+	if secured {
+		doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+			securityAPIKey: {Type: "apiKey", In: "header", Name: "X-API-Key"},
+			securityBearer: {Type: "http", Scheme: "bearer"},
+		}
+	}
+	return doc
+}
+
+// operation 生成单个操作
+func (r *Registry) operation(gen *schemaGenerator, op Operation, pathParams []string) *OperationObject {
+	obj := &OperationObject{
+		OperationID: op.OperationID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Deprecated:  op.Deprecated,
+		Responses:   make(map[string]*Response),
+	}
+
+	declared := gen.parameters(op.Params, "path", "params")
+	for _, name := range pathParams {
+		found := false
+		for _, p := range declared {
+			if p.Name == name {
+				obj.Parameters = append(obj.Parameters, p)
+				found = true
+				break
+			}
+		}
+		if !found {
+			obj.Parameters = append(obj.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+	}
+	obj.Parameters = append(obj.Parameters, gen.parameters(op.Query, "query", "query")...)
+	obj.Parameters = append(obj.Parameters, gen.parameters(op.Headers, "header", "header")...)
+
+	if op.Body != nil {
+		obj.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{mimeJSON: {Schema: gen.schemaOf(reflect.TypeOf(op.Body))}},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	var data *Schema
+	if op.Response != nil {
+		data = gen.schemaOf(reflect.TypeOf(op.Response))
+	}
+	success := &Response{Description: http.StatusText(status)}
+	switch {
+	case !op.Raw:
+		success.Content = map[string]MediaType{mimeJSON: {Schema: envelope(gen, data)}}
+	case data != nil:
+		success.Content = map[string]MediaType{mimeJSON: {Schema: data}}
+	}
+	obj.Responses[strconv.Itoa(status)] = success
+
+	for code, desc := range op.Errors {
+		if desc == "" {
+			desc = http.StatusText(code)
+		}
+		obj.Responses[strconv.Itoa(code)] = &Response{
+			Description: desc,
+			Content:     map[string]MediaType{mimeJSON: {Schema: envelope(gen, nil)}},
+		}
+	}
+	if !op.Raw {
+		obj.Responses["default"] = &Response{
+			Description: "错误响应",
+			Content:     map[string]MediaType{mimeJSON: {Schema: envelope(gen, nil)}},
+		}
+	}
+
+	if op.Secured {
+		obj.Security = []map[string][]string{{securityAPIKey: {}}, {securityBearer: {}}}
+	}
+	return obj
+}
+
+// envelope 生成 response.Result 信封 Schema，data 为 nil 时保持 Result 的原始定义
+func envelope(gen *schemaGenerator, data *Schema) *Schema {
+	ref := gen.schemaOf(reflect.TypeFor[response.Result]())
+	if data == nil {
+		return ref
+	}
+	s := *gen.schemas[strings.TrimPrefix(ref.Ref, "#/components/schemas/")]
+	props := make(map[string]*Schema, len(s.Properties))
+	for k, v := range s.Properties {
+		props[k] = v
+	}
+	props["data"] = data
+	s.Properties = props
+	return &s
+}
+
+// routerPrefix 路由组前缀
+func routerPrefix(router fiber.Router) string {
+	if g, ok := router.(*fiber.Group); ok {
+		return g.Prefix
+	}
+	return ""
+}
+
+// joinPath 拼接路由前缀与路径
+func joinPath(prefix, path string) string {
+	if prefix == "" {
+		return path
+	}
+	if path == "" || path == "/" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// convertPath 将 Fiber 路径转换为 OpenAPI 路径并返回路径参数名
+// :id → {id}，忽略可选标记 ? 与约束 <int>；通配符 * / + 命名为 wildcard、wildcard2 …
+func convertPath(path string) (string, []string) {
+	var b strings.Builder
+	var params []string
+	wildcards := 0
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		switch c {
+		case ':':
+			j := i + 1
+			for j < len(path) && isParamChar(path[j]) {
+				j++
+			}
+			name := path[i+1 : j]
+			// 跳过约束 <...> 与可选标记
+			if j < len(path) && path[j] == '<' {
+				if end := strings.IndexByte(path[j:], '>'); end >= 0 {
+					j += end + 1
+				}
+			}
+			if j < len(path) && path[j] == '?' {
+				j++
+			}
+			params = append(params, name)
+			b.WriteString("{" + name + "}")
+			i = j - 1
+		case '*', '+':
+			wildcards++
+			name := "wildcard"
+			if wildcards > 1 {
+				name += strconv.Itoa(wildcards)
+			}
+			params = append(params, name)
+			b.WriteString("{" + name + "}")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), params
+}
+
+func isParamChar(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
@@ -0,0 +1,205 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type testUser struct {
+	ID        ulid.ULIDString `json:"id" doc:"用户 ID"`
+	Name      string          `json:"name" validate:"required,min=2,max=64" doc:"用户名" example:"alice"`
+	Age       int             `json:"age,omitempty" validate:"gte=0,lte=150" example:"18"`
+	Role      string          `json:"role" validate:"oneof=admin member"`
+	Tags      []string        `json:"tags" validate:"max=5,dive,required"`
+	Manager   *testUser       `json:"manager,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	DeletedAt *time.Time      `json:"deleted_at"`
+	secret    string
+	Ignored   string `json:"-"`
+}
+
+type testQuery struct {
+	Keyword string `query:"keyword" doc:"关键字"`
+	Page    int    `query:"page" validate:"required"`
+}
+
+func TestConvertPath(t *testing.T) {
+	cases := map[string]string{
+		"/users/:id":               "/users/{id}",
+		"/users/:id<int>/posts":    "/users/{id}/posts",
+		"/files/:name.:ext?":       "/files/{name}.{ext}",
+		"/static/*":                "/static/{wildcard}",
+		"/v1/:tenant_id/orders/+":  "/v1/{tenant_id}/orders/{wildcard}",
+		"/plain":                   "/plain",
+		"/a/:x-:y":                 "/a/{x}-{y}",
+		"/multi/*/and/*":           "/multi/{wildcard}/and/{wildcard2}",
+		"/users/:id<min(1)>/books": "/users/{id}/books",
+	}
+	for in, want := range cases {
+		if got, _ := convertPath(in); got != want {
+			t.Errorf("convertPath(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestDocument(t *testing.T) {
+	reg := New(&Config{Title: "Test", Version: "1.0.0"})
+	app := fiber.New()
+	api := app.Group("/api/v1")
+
+	reg.Route(api, Operation{
+		Method:   fiber.MethodGet,
+		Path:     "/users/:id",
+		Summary:  "查询用户",
+		Tags:     []string{"user"},
+		Response: testUser{},
+		Secured:  true,
+		Errors:   map[int]string{404: ""},
+	}, func(c fiber.Ctx) error { return c.SendString(c.Params("id")) })
+	reg.Route(api, Operation{
+		Method:   fiber.MethodGet,
+		Path:     "/users",
+		Query:    testQuery{},
+		Response: repository.PageResult[testUser]{},
+	}, func(c fiber.Ctx) error { return nil })
+	reg.Route(api, Operation{
+		Method: fiber.MethodPost,
+		Path:   "/users",
+		Body:   &testUser{},
+		Status: 201,
+		Raw:    true,
+	}, func(c fiber.Ctx) error { return nil })
+
+	// 路由真实注册在组前缀下
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/api/v1/users/42", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "42" {
+		t.Fatalf("route body = %q", body)
+	}
+
+	doc := reg.Document()
+	item := doc.Paths["/api/v1/users/{id}"]
+	if item == nil {
+		t.Fatalf("paths = %v", doc.Paths)
+	}
+	get := (*item)["get"]
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "id" || !get.Parameters[0].Required {
+		t.Fatalf("path params = %+v", get.Parameters)
+	}
+	if len(get.Security) == 0 || doc.Components.SecuritySchemes[securityAPIKey] == nil {
+		t.Fatal("security not declared")
+	}
+	if get.Responses["404"] == nil || get.Responses["default"] == nil {
+		t.Fatalf("responses = %v", get.Responses)
+	}
+	data := get.Responses["200"].Content[mimeJSON].Schema.Properties["data"]
+	if data == nil || data.Ref != "#/components/schemas/testUser" {
+		t.Fatalf("envelope data = %+v", data)
+	}
+
+	user := doc.Components.Schemas["testUser"]
+	if user == nil {
+		t.Fatalf("schemas = %v", doc.Components.Schemas)
+	}
+	if strings.Join(user.Required, ",") != "name" {
+		t.Errorf("required = %v", user.Required)
+	}
+	name := user.Properties["name"]
+	if name.Description != "用户名" || name.Example != "alice" || *name.MinLength != 2 || *name.MaxLength != 64 {
+		t.Errorf("name = %+v", name)
+	}
+	if age := user.Properties["age"]; age.Example != float64(18) || *age.Minimum != 0 || *age.Maximum != 150 {
+		t.Errorf("age = %+v", age)
+	}
+	if role := user.Properties["role"]; len(role.Enum) != 2 {
+		t.Errorf("role enum = %v", role.Enum)
+	}
+	if tags := user.Properties["tags"]; tags.Type != "array" || *tags.MaxItems != 5 {
+		t.Errorf("tags = %+v", tags)
+	}
+	if id := user.Properties["id"]; id.Type != "string" {
+		t.Errorf("ulid id = %+v", id)
+	}
+	if m := user.Properties["manager"]; m.Ref != "#/components/schemas/testUser" {
+		t.Errorf("recursive manager = %+v", m)
+	}
+	if d := user.Properties["deleted_at"]; d.Format != "date-time" || !d.Nullable {
+		t.Errorf("deleted_at = %+v", d)
+	}
+	for _, hidden := range []string{"secret", "Ignored"} {
+		if _, ok := user.Properties[hidden]; ok {
+			t.Errorf("unexpected property %s", hidden)
+		}
+	}
+
+	list := (*doc.Paths["/api/v1/users"])["get"]
+	if len(list.Parameters) != 2 || list.Parameters[0].In != "query" || !list.Parameters[1].Required {
+		t.Fatalf("query params = %+v", list.Parameters)
+	}
+	if doc.Components.Schemas["PageResult_testUser"] == nil {
+		t.Fatalf("generic schema name: %v", doc.Components.Schemas)
+	}
+
+	create := (*doc.Paths["/api/v1/users"])["post"]
+	if create.RequestBody == nil || create.Responses["201"] == nil || create.Responses["201"].Content != nil {
+		t.Fatalf("create = %+v", create)
+	}
+	if create.Responses["default"] != nil {
+		t.Fatal("raw operation should not declare envelope error response")
+	}
+}
+
+func TestMount(t *testing.T) {
+	reg := New(nil)
+	app := fiber.New()
+	docs := app.Group("/internal")
+	Mount(docs, reg)
+	reg.Add(Operation{Method: fiber.MethodGet, Path: "/ping", Summary: "ping"})
+
+	resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/internal/openapi.json", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	var doc Document
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	resp.Body.Close()
+	if doc.OpenAPI != "3.0.3" || doc.Info.Title != "API" || doc.Paths["/ping"] == nil {
+		t.Fatalf("doc = %+v", doc)
+	}
+
+	resp, err = app.Test(httptest.NewRequest(fiber.MethodGet, "/internal/docs", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	html, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(html), `"/internal/openapi.json"`) || !strings.Contains(string(html), defaultUIAssetsURL) {
+		t.Fatalf("ui = %s", html)
+	}
+
+	guarded := fiber.New()
+	Mount(guarded, reg, func(c fiber.Ctx) error { return fiber.ErrUnauthorized })
+	resp, err = guarded.Test(httptest.NewRequest(fiber.MethodGet, "/openapi.json", nil))
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusUnauthorized {
+		t.Fatalf("guarded status = %d", resp.StatusCode)
+	}
+}
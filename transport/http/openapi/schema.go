@@ -0,0 +1,323 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema OpenAPI 3.0 Schema 子集
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Example              any                `json:"example,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+
+	// pkgPathPattern 泛型类型名中的包路径前缀
+	pkgPathPattern = regexp.MustCompile(`[\w./-]*\.`)
+)
+
+// schemaGenerator 反射生成 Schema，具名结构体收集到 components
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+	used    map[string]reflect.Type
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+		used:    make(map[string]reflect.Type),
+	}
+}
+
+// schemaOf 生成类型的 Schema；具名结构体返回 $ref
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t, nullable = t.Elem(), true
+	}
+
+	s := g.baseSchema(t)
+	if nullable && s.Ref == "" {
+		s.Nullable = true
+	}
+	return s
+}
+
+func (g *schemaGenerator) baseSchema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case implements(t, textMarshalerType) && !implements(t, jsonMarshalerType):
+		// 实现 TextMarshaler 的类型（如 ULID）序列化为字符串
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.component(t)}
+	default:
+		// interface / any 等无法推断的类型
+		return &Schema{}
+	}
+}
+
+// implements 类型或其指针是否实现接口
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+// component 注册具名结构体并返回组件名
+func (g *schemaGenerator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	base := typeName(t)
+	name := base
+	for i := 2; ; i++ {
+		if _, ok := g.used[name]; !ok {
+			break
+		}
+		name = base + strconv.Itoa(i)
+	}
+	g.names[t] = name
+	g.used[name] = t
+	// 先占位再展开，支持递归类型
+	g.schemas[name] = &Schema{}
+	*g.schemas[name] = *g.structSchema(t)
+	return name
+}
+
+// typeName 组件名：去掉泛型参数中的包路径，如 PageResult[pkg.User] → PageResult_User
+func typeName(t reflect.Type) string {
+	name := pkgPathPattern.ReplaceAllString(t.Name(), "")
+	name = strings.NewReplacer("[", "_", "]", "", ",", "_", " ", "", "*", "").Replace(name)
+	return name
+}
+
+// structSchema 展开结构体字段
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.collectFields(t, s)
+	return s
+}
+
+func (g *schemaGenerator) collectFields(t reflect.Type, s *Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// 匿名嵌入结构体按 encoding/json 规则展开
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.collectFields(ft, s)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := g.schemaOf(f.Type)
+		if strings.Contains(opts, "string") && fs.Ref == "" {
+			// json:",string" 以字符串编码数值/布尔
+			fs.Type, fs.Format = "string", ""
+		}
+		// OpenAPI 3.0 中 $ref 不允许兄弟字段，引用类型不附加描述与示例
+		if fs.Ref == "" {
+			fs.Description = f.Tag.Get("doc")
+			if ex, ok := f.Tag.Lookup("example"); ok {
+				fs.Example = parseExample(ex, fs.Type)
+			}
+		}
+		if applyValidate(fs, f.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	}
+}
+
+// parseExample 按字段类型转换示例值
+func parseExample(raw, typ string) any {
+	switch typ {
+	case "integer", "number", "boolean", "array", "object":
+		var v any
+		if err := json.Unmarshal([]byte(raw), &v); err == nil {
+			return v
+		}
+	}
+	return raw
+}
+
+// applyValidate 将 validate 标签映射为 Schema 约束，返回是否必填
+func applyValidate(s *Schema, tag string) bool {
+	if tag == "" || s.Ref != "" {
+		return strings.Contains(","+tag+",", ",required,")
+	}
+	required := false
+	for _, rule := range strings.Split(tag, ",") {
+		if rule == "dive" {
+			// dive 之后的规则作用于元素
+			break
+		}
+		key, val, _ := strings.Cut(rule, "=")
+		switch key {
+		case "required":
+			required = true
+		case "email":
+			s.Format = "email"
+		case "url", "uri":
+			s.Format = "uri"
+		case "uuid", "uuid4":
+			s.Format = "uuid"
+		case "oneof":
+			for _, v := range strings.Fields(val) {
+				s.Enum = append(s.Enum, parseExample(v, s.Type))
+			}
+		case "min", "gte", "max", "lte", "len":
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				continue
+			}
+			setBound(s, key, n)
+		}
+	}
+	return required
+}
+
+// setBound 按类型设置长度或数值范围
+func setBound(s *Schema, key string, n float64) {
+	lower := key == "min" || key == "gte" || key == "len"
+	upper := key == "max" || key == "lte" || key == "len"
+	i := int(n)
+	switch s.Type {
+	case "string":
+		if lower {
+			s.MinLength = &i
+		}
+		if upper {
+			s.MaxLength = &i
+		}
+	case "array":
+		if lower {
+			s.MinItems = &i
+		}
+		if upper {
+			s.MaxItems = &i
+		}
+	case "integer", "number":
+		if lower {
+			s.Minimum = &n
+		}
+		if upper {
+			s.Maximum = &n
+		}
+	}
+}
+
+// Parameter OpenAPI 参数
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path / query / header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// parameters 由结构体的 query / params / header 标签生成参数
+func (g *schemaGenerator) parameters(v any, in, tagKey string) []Parameter {
+	if v == nil {
+		return nil
+	}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var params []Parameter
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get(tagKey), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		s := g.schemaOf(f.Type)
+		if ex, ok := f.Tag.Lookup("example"); ok {
+			s.Example = parseExample(ex, s.Type)
+		}
+		required := applyValidate(s, f.Tag.Get("validate"))
+		params = append(params, Parameter{
+			Name:        name,
+			In:          in,
+			Description: f.Tag.Get("doc"),
+			Required:    required || in == "path",
+			Schema:      s,
+		})
+	}
+	return params
+}
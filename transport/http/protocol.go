@@ -0,0 +1,160 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"strconv"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/upgrade"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/gofiber/fiber/v3/middleware/adaptor"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Protocols - h2c 与 HTTP/3 监听
+ * ========================================================================
+ * 职责: 突破 fasthttp 仅支持 HTTP/1.1 的限制，供网格内部与流式接口使用
+ *   - h2c:    通过 net/http 在同一端口同时提供 HTTP/1.1 与明文 HTTP/2
+ *             （Fiber 应用经 adaptor 适配，支持流式响应；不支持 TLS 与 Prefork，
+ *             且不应用 ListenConfigCustomizer）
+ *   - HTTP/3: 实验性 QUIC 监听，与 TCP 监听并存，业务响应附带 Alt-Svc 通告；
+ *             QUIC 实现由应用通过 HTTP3Server 注入（如包装 quic-go 的 http3.Server），
+ *             避免为所有服务引入 QUIC 依赖
+ * 配置示例:
+ *   http:
+ *     listen:
+ *       h2c: true
+ *       http3:
+ *         enabled: true
+ *         port: 8443
+ *         cert_file: /etc/tls/tls.crt
+ *         cert_key_file: /etc/tls/tls.key
+ * ======================================================================== */
+
+// defaultAltSvcMaxAge 默认 Alt-Svc 通告有效期
+const defaultAltSvcMaxAge = 24 * time.Hour
+
+// HTTP3Options 实验性 HTTP/3 (QUIC) 监听配置
+type HTTP3Options struct {
+	Enabled      bool          `yaml:"enabled"`
+	Port         int           `yaml:"port"`            // UDP 端口，默认与 HTTP 端口相同
+	CertFile     string        `yaml:"cert_file"`       // 证书，默认沿用 listen.cert_file
+	CertKeyFile  string        `yaml:"cert_key_file"`   // 私钥，默认沿用 listen.cert_key_file
+	AltSvcMaxAge time.Duration `yaml:"alt_svc_max_age"` // Alt-Svc 通告有效期，默认 24h，负数表示不通告
+}
+
+// HTTP3Server HTTP/3 服务实现，由应用注入
+// quic-go 示例:
+//
+//	type quicServer struct{ s *http3.Server }
+//	func (q *quicServer) Serve(addr string, tlsCfg *tls.Config, h http.Handler) error {
+//	    q.s = &http3.Server{Addr: addr, TLSConfig: http3.ConfigureTLSConfig(tlsCfg), Handler: h}
+//	    return q.s.ListenAndServe()
+//	}
+//	func (q *quicServer) Shutdown(ctx context.Context) error { return q.s.Shutdown(ctx) }
+type HTTP3Server interface {
+	// Serve 在 addr 上监听 QUIC 并阻塞处理请求
+	Serve(addr string, tlsConfig *tls.Config, handler nethttp.Handler) error
+	// Shutdown 优雅关闭
+	Shutdown(ctx context.Context) error
+}
+
+// h2cServer 创建同时支持 HTTP/1.1 与明文 HTTP/2 的 net/http 服务
+func h2cServer(app *fiber.App, readTimeout, writeTimeout, idleTimeout time.Duration) *nethttp.Server {
+	var protocols nethttp.Protocols
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return &nethttp.Server{
+		Handler:      adaptor.FiberApp(app),
+		Protocols:    &protocols,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  idleTimeout,
+	}
+}
+
+// validateH2C 检查 h2c 与其他监听选项的兼容性
+func validateH2C(opts ListenOptions) error {
+	if opts.EnablePrefork {
+		return fmt.Errorf("http: h2c does not support prefork")
+	}
+	if opts.CertFile != "" {
+		return fmt.Errorf("http: h2c is cleartext only, remove listen.cert_file")
+	}
+	return nil
+}
+
+// startH2C 在后台启动 h2c 服务（监听器创建同 createListener，支持 Unix Socket、systemd 继承、PROXY protocol 与平滑升级）
+func startH2C(ctx context.Context, srv *nethttp.Server, addr string, opts ListenOptions, upg *upgrade.Upgrader, proxy *proxyResolver, log *logger.Logger) error {
+	lis, err := createListener(opts, addr, upg, listenerName, proxy, log)
+	if err != nil {
+		return err
+	}
+	return startApp(ctx, addr, func() error { return srv.Serve(lis) }, log)
+}
+
+// http3Listener 描述已解析的 HTTP/3 监听参数
+type http3Listener struct {
+	addr     string
+	port     int
+	certFile string
+	keyFile  string
+}
+
+// resolveHTTP3 合并 HTTP/3 配置与 TCP 监听配置
+func resolveHTTP3(cfg Config) (http3Listener, error) {
+	opts := cfg.Listen.HTTP3
+	l := http3Listener{port: opts.Port, certFile: opts.CertFile, keyFile: opts.CertKeyFile}
+	if l.port <= 0 {
+		l.port = cfg.Port
+	}
+	if l.certFile == "" {
+		l.certFile = cfg.Listen.CertFile
+	}
+	if l.keyFile == "" {
+		l.keyFile = cfg.Listen.CertKeyFile
+	}
+	if l.certFile == "" || l.keyFile == "" {
+		return l, fmt.Errorf("http: http3 requires cert_file and cert_key_file")
+	}
+	l.addr = net.JoinHostPort(cfg.Host, strconv.Itoa(l.port))
+	return l, nil
+}
+
+// altSvc 返回通告 HTTP/3 的中间件，maxAge 为负数时返回 nil
+func altSvc(port int, maxAge time.Duration) fiber.Handler {
+	if maxAge < 0 {
+		return nil
+	}
+	if maxAge == 0 {
+		maxAge = defaultAltSvcMaxAge
+	}
+	value := fmt.Sprintf(`h3=":%d"; ma=%d`, port, int(maxAge.Seconds()))
+	return func(c fiber.Ctx) error {
+		c.Set(fiber.HeaderAltSvc, value)
+		return c.Next()
+	}
+}
+
+// startHTTP3 加载证书并在后台启动 HTTP/3 服务
+func startHTTP3(ctx context.Context, srv HTTP3Server, app *fiber.App, l http3Listener, minVersion uint16, log *logger.Logger) error {
+	cert, err := tls.LoadX509KeyPair(l.certFile, l.keyFile)
+	if err != nil {
+		return fmt.Errorf("http: load http3 certificate: %w", err)
+	}
+	if minVersion < tls.VersionTLS13 {
+		// QUIC 要求 TLS 1.3
+		minVersion = tls.VersionTLS13
+	}
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: minVersion}
+
+	log.Info("Starting HTTP/3 Server", zap.String("addr", l.addr))
+	return startApp(ctx, l.addr, func() error { return srv.Serve(l.addr, tlsCfg, adaptor.FiberApp(app)) }, log)
+}
@@ -0,0 +1,185 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestH2CListener(t *testing.T) {
+	port := freePort(t)
+	lc := fxtest.NewLifecycle(t)
+	app := NewHTTPServer(ServerParams{
+		Lc:        lc,
+		Logger:    logger.NewNop(),
+		Config:    Config{Host: "127.0.0.1", Port: port, Listen: ListenOptions{H2C: true}},
+		Readiness: NewReadiness(),
+	})
+	app.Get("/stream", func(c fiber.Ctx) error { return c.SendString("ok") })
+	lc.RequireStart()
+	defer lc.RequireStop()
+
+	var protocols http.Protocols
+	protocols.SetUnencryptedHTTP2(true)
+	client := &http.Client{Transport: &http.Transport{Protocols: &protocols}}
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d/stream", port))
+	if err != nil {
+		t.Fatalf("h2c get: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 || resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Fatalf("unexpected h2c response: proto=%s status=%d body=%q", resp.Proto, resp.StatusCode, body)
+	}
+
+	// HTTP/1.1 客户端仍可访问同一端口
+	resp, err = http.Get(fmt.Sprintf("http://127.0.0.1:%d/healthz", port))
+	if err != nil {
+		t.Fatalf("http/1.1 get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 1 || resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected http/1.1 response: proto=%s status=%d", resp.Proto, resp.StatusCode)
+	}
+}
+
+// fakeHTTP3Server 记录 Serve 参数并阻塞至 Shutdown，记录完成后关闭 served
+type fakeHTTP3Server struct {
+	addr    string
+	tlsCfg  *tls.Config
+	handler http.Handler
+	served  chan struct{}
+	done    chan struct{}
+}
+
+func (f *fakeHTTP3Server) Serve(addr string, tlsCfg *tls.Config, handler http.Handler) error {
+	f.addr, f.tlsCfg, f.handler = addr, tlsCfg, handler
+	close(f.served)
+	<-f.done
+	return http.ErrServerClosed
+}
+
+func (f *fakeHTTP3Server) Shutdown(context.Context) error {
+	close(f.done)
+	return nil
+}
+
+func TestHTTP3Listener(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	port := freePort(t)
+	listen := ListenOptions{DisableStartupMessage: true, HTTP3: HTTP3Options{Enabled: true, Port: 8443, CertFile: certFile, CertKeyFile: keyFile}}
+
+	// 未注入实现时拒绝启动
+	lc := fxtest.NewLifecycle(t)
+	NewHTTPServer(ServerParams{Lc: lc, Logger: logger.NewNop(), Config: Config{Port: port, Listen: listen}})
+	if err := lc.Start(context.Background()); err == nil {
+		t.Fatal("expected start error without HTTP3Server")
+	}
+
+	h3 := &fakeHTTP3Server{served: make(chan struct{}), done: make(chan struct{})}
+	lc = fxtest.NewLifecycle(t)
+	app := NewHTTPServer(ServerParams{
+		Lc:          lc,
+		Logger:      logger.NewNop(),
+		Config:      Config{Host: "127.0.0.1", Port: port, Listen: listen},
+		Readiness:   NewReadiness(),
+		HTTP3Server: h3,
+	})
+	app.Get("/ping", func(c fiber.Ctx) error { return c.SendString("pong") })
+	lc.RequireStart()
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/ping", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get(fiber.HeaderAltSvc); got != `h3=":8443"; ma=86400` {
+		t.Fatalf("unexpected Alt-Svc: %q", got)
+	}
+
+	select {
+	case <-h3.served:
+	case <-time.After(2 * time.Second):
+		t.Fatal("http3 server was not started")
+	}
+	if h3.addr != "127.0.0.1:8443" || h3.tlsCfg.MinVersion != tls.VersionTLS13 || len(h3.tlsCfg.Certificates) != 1 {
+		t.Fatalf("unexpected http3 serve args: addr=%s tls=%+v", h3.addr, h3.tlsCfg)
+	}
+	rec := httptest.NewRecorder()
+	h3.handler.ServeHTTP(rec, httptest.NewRequest("GET", "/ping", nil))
+	if rec.Body.String() != "pong" {
+		t.Fatalf("unexpected http3 handler body: %q", rec.Body.String())
+	}
+	lc.RequireStop()
+}
+
+func TestProtocolValidation(t *testing.T) {
+	if err := validateH2C(ListenOptions{H2C: true, EnablePrefork: true}); err == nil {
+		t.Fatal("expected h2c prefork error")
+	}
+	if err := validateH2C(ListenOptions{H2C: true, CertFile: "tls.crt"}); err == nil {
+		t.Fatal("expected h2c tls error")
+	}
+	if _, err := resolveHTTP3(Config{Port: 8080, Listen: ListenOptions{HTTP3: HTTP3Options{Enabled: true}}}); err == nil {
+		t.Fatal("expected missing certificate error")
+	}
+	l, err := resolveHTTP3(Config{Port: 8080, Listen: ListenOptions{CertFile: "a", CertKeyFile: "b", HTTP3: HTTP3Options{Enabled: true}}})
+	if err != nil || l.addr != ":8080" || l.certFile != "a" {
+		t.Fatalf("unexpected http3 listener: %+v %v", l, err)
+	}
+	if altSvc(443, -1) != nil {
+		t.Fatal("expected Alt-Svc disabled")
+	}
+}
+
+// writeSelfSignedCert 生成自签名证书并写入临时目录
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certFile, keyFile
+}
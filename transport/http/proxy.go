@@ -0,0 +1,385 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Proxy - 可信代理与 PROXY protocol
+ * ========================================================================
+ * 职责: 部署在负载均衡之后时还原真实客户端 IP，使 c.IP()、限流、访问日志、
+ *       审计与 IP 过滤等全部中间件看到一致的客户端地址
+ *   - PROXY protocol: proxy_protocol 为 true 时在监听器上解析 v1（文本）/ v2（二进制）头，
+ *                     连接的对端地址替换为头中的源地址；trusted_proxies 非空时仅要求
+ *                     这些对端发送 PROXY 头，其余连接按普通连接处理
+ *   - 转发头:         对端命中 trusted_proxies（或 trust_unix_socket 为 true 时的 Unix Socket 对端）时，
+ *                     按 headers 顺序取第一个存在的头，从右向左跳过可信代理，取第一个不可信地址作为客户端 IP
+ * 解析结果写回请求的对端地址（位于中间件链首位），c.IP() 直接返回真实 IP，
+ * 并通过 middleware.MarkClientIPResolved 标记，ip_filter 随之使用该结果而忽略自身的 trusted_proxies，
+ * 无需再配置 Fiber 的 ProxyHeader / TrustProxy
+ * 注意: 转发头只能由 trusted_proxies 中的代理写入，务必只填写自有负载均衡的网段；
+ *       Unix Socket 对端默认不可信，仅当 socket 只对本机反向代理开放时开启 trust_unix_socket；
+ *       管理端口（admin）不解析代理信息
+ * 配置示例:
+ *   http:
+ *     proxy:
+ *       trusted_proxies: ["10.0.0.0/8", "172.16.0.0/12"]
+ *       trust_unix_socket: false
+ *       headers: ["X-Forwarded-For", "X-Real-IP"]
+ *       proxy_protocol: true
+ *       proxy_protocol_timeout: 5s
+ * ======================================================================== */
+
+const (
+	// defaultProxyProtocolTimeout 默认读取 PROXY 头的超时
+	defaultProxyProtocolTimeout = 5 * time.Second
+	// proxyV1MaxLen PROXY v1 头最大长度（含 CRLF）
+	proxyV1MaxLen = 107
+)
+
+// proxyV2Signature PROXY v2 头签名
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// defaultProxyHeaders 默认按顺序检查的转发头
+var defaultProxyHeaders = []string{fiber.HeaderXForwardedFor, "X-Real-IP"}
+
+// ProxyConfig 可信代理配置
+type ProxyConfig struct {
+	// TrustedProxies 可信代理网段（CIDR 或单个 IP），仅信任这些对端的转发头与 PROXY 头
+	TrustedProxies []string `yaml:"trusted_proxies"`
+
+	// TrustUnixSocket 是否信任 Unix Socket 对端（如本机 sidecar）的转发头与 PROXY 头，默认 false
+	TrustUnixSocket bool `yaml:"trust_unix_socket"`
+
+	// Headers 客户端 IP 转发头，按顺序取第一个存在的，默认 [X-Forwarded-For, X-Real-IP]
+	Headers []string `yaml:"headers"`
+
+	// ProxyProtocol 是否在监听器上解析 PROXY protocol v1/v2 头，默认 false
+	ProxyProtocol bool `yaml:"proxy_protocol"`
+
+	// ProxyProtocolTimeout 读取 PROXY 头的超时，默认 5s
+	ProxyProtocolTimeout time.Duration `yaml:"proxy_protocol_timeout"`
+}
+
+// proxyResolver 预处理后的可信代理配置
+type proxyResolver struct {
+	trusted []*net.IPNet
+	unix    bool // 是否信任 Unix Socket 对端
+	headers []string
+	timeout time.Duration
+	enabled bool // 是否解析 PROXY 头
+}
+
+// newProxyResolver 解析可信代理配置，未配置时返回 nil
+func newProxyResolver(cfg ProxyConfig) (*proxyResolver, error) {
+	if len(cfg.TrustedProxies) == 0 && !cfg.TrustUnixSocket && !cfg.ProxyProtocol {
+		return nil, nil
+	}
+	trusted, err := middleware.ParseCIDRs(cfg.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("http: invalid proxy.trusted_proxies: %w", err)
+	}
+	headers := cfg.Headers
+	if len(headers) == 0 {
+		headers = defaultProxyHeaders
+	}
+	timeout := cfg.ProxyProtocolTimeout
+	if timeout <= 0 {
+		timeout = defaultProxyProtocolTimeout
+	}
+	return &proxyResolver{trusted: trusted, unix: cfg.TrustUnixSocket, headers: headers, timeout: timeout, enabled: cfg.ProxyProtocol}, nil
+}
+
+// trustedAddr 对端是否为可信代理，Unix Socket 对端仅在 trust_unix_socket 开启时可信
+func (r *proxyResolver) trustedAddr(addr net.Addr) bool {
+	switch a := addr.(type) {
+	case *net.UnixAddr:
+		return r.unix
+	case *net.TCPAddr:
+		return r.trustedIP(a.IP)
+	default:
+		return false
+	}
+}
+
+// trustedIP IP 是否命中可信代理网段
+func (r *proxyResolver) trustedIP(ip net.IP) bool {
+	return ip != nil && slices.ContainsFunc(r.trusted, func(n *net.IPNet) bool { return n.Contains(ip) })
+}
+
+// middleware 返回还原客户端 IP 的中间件，需位于中间件链首位
+func (r *proxyResolver) middleware() fiber.Handler {
+	return func(c fiber.Ctx) error {
+		middleware.MarkClientIPResolved(c)
+		fctx := c.RequestCtx()
+		// 同一连接上的请求可能来自不同客户端（代理复用上游连接），每次均以连接对端为准
+		var peer net.Addr
+		if conn := fctx.Conn(); conn != nil {
+			peer = conn.RemoteAddr()
+		}
+		if peer == nil || !r.trustedAddr(peer) {
+			fctx.SetRemoteAddr(peer)
+			return c.Next()
+		}
+		if ip := r.forwardedIP(c); ip != nil {
+			fctx.SetRemoteAddr(&net.TCPAddr{IP: ip})
+		} else {
+			fctx.SetRemoteAddr(peer)
+		}
+		return c.Next()
+	}
+}
+
+// forwardedIP 按 headers 顺序解析客户端 IP，均不存在或非法时返回 nil
+func (r *proxyResolver) forwardedIP(c fiber.Ctx) net.IP {
+	for _, name := range r.headers {
+		value := c.Get(name)
+		if value == "" {
+			continue
+		}
+		hops := strings.Split(value, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				return nil
+			}
+			if !r.trustedIP(ip) || i == 0 {
+				// 全部为可信代理时取最左侧地址
+				return ip
+			}
+		}
+	}
+	return nil
+}
+
+// wrapListener 启用 PROXY protocol 时包装监听器，否则原样返回
+func (r *proxyResolver) wrapListener(ln net.Listener, log *logger.Logger) net.Listener {
+	if r == nil || !r.enabled {
+		return ln
+	}
+	pl := &proxyListener{
+		Listener: ln,
+		resolver: r,
+		log:      log,
+		conns:    make(chan net.Conn),
+		errs:     make(chan error),
+		done:     make(chan struct{}),
+	}
+	go pl.acceptLoop()
+	return pl
+}
+
+// proxyListener 解析 PROXY 头的监听器
+// 头在独立 goroutine 中读取，避免慢连接阻塞 Accept
+type proxyListener struct {
+	net.Listener
+	resolver *proxyResolver
+	log      *logger.Logger
+
+	conns     chan net.Conn
+	errs      chan error
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// acceptLoop 接收连接并并发读取 PROXY 头
+func (l *proxyListener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			select {
+			case l.errs <- err:
+			case <-l.done:
+				return
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		go l.handshake(conn)
+	}
+}
+
+// handshake 读取 PROXY 头，失败时关闭连接
+func (l *proxyListener) handshake(conn net.Conn) {
+	pc, err := l.resolver.readHeader(conn)
+	if err != nil {
+		l.log.Warn("Rejecting connection with invalid PROXY header",
+			zap.String("remote", conn.RemoteAddr().String()), zap.Error(err))
+		conn.Close()
+		return
+	}
+	select {
+	case l.conns <- pc:
+	case <-l.done:
+		pc.Close()
+	}
+}
+
+// Accept 返回已完成 PROXY 头解析的连接
+func (l *proxyListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case err := <-l.errs:
+		return nil, err
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close 关闭监听器
+func (l *proxyListener) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+// proxyConn 对端地址来自 PROXY 头的连接
+type proxyConn struct {
+	net.Conn
+	r      *bufio.Reader
+	remote net.Addr
+}
+
+// Read 先读取缓冲区中 PROXY 头之后的数据
+func (c *proxyConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// RemoteAddr 返回 PROXY 头中的源地址，LOCAL / UNKNOWN 头时返回真实对端
+func (c *proxyConn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// readHeader 读取 PROXY 头；配置了可信对端但连接对端不在其中时原样返回连接
+func (r *proxyResolver) readHeader(conn net.Conn) (net.Conn, error) {
+	if (len(r.trusted) > 0 || r.unix) && !r.trustedAddr(conn.RemoteAddr()) {
+		return conn, nil
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(r.timeout)); err != nil {
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	remote, err := parseProxyHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	return &proxyConn{Conn: conn, r: br, remote: remote}, nil
+}
+
+// parseProxyHeader 解析 PROXY v1/v2 头，返回源地址（LOCAL / UNKNOWN 时为 nil）
+func parseProxyHeader(br *bufio.Reader) (net.Addr, error) {
+	prefix, err := br.Peek(5)
+	if err != nil {
+		return nil, fmt.Errorf("read proxy header: %w", err)
+	}
+	if string(prefix) == "PROXY" {
+		return parseProxyV1(br)
+	}
+	sig, err := br.Peek(len(proxyV2Signature))
+	if err != nil || !bytes.Equal(sig, proxyV2Signature) {
+		return nil, errors.New("missing proxy protocol header")
+	}
+	return parseProxyV2(br)
+}
+
+// parseProxyV1 解析文本格式头: PROXY TCP4|TCP6|UNKNOWN src dst sport dport\r\n
+func parseProxyV1(br *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for len(line) < proxyV1MaxLen {
+		b, err := br.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("read proxy v1 header: %w", err)
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("proxy v1 header too long or not terminated by CRLF")
+	}
+
+	fields := strings.Fields(string(line[:len(line)-2]))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("invalid proxy v1 header %q", line)
+	}
+	ip, err := netip.ParseAddr(fields[2])
+	if err != nil || ip.Is4() != (fields[1] == "TCP4") {
+		return nil, fmt.Errorf("invalid proxy v1 source address %q", fields[2])
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy v1 source port %q", fields[4])
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip, uint16(port))), nil
+}
+
+// parseProxyV2 解析二进制格式头，忽略 TLV 扩展
+func parseProxyV2(br *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, 16)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("read proxy v2 header: %w", err)
+	}
+	if header[12]>>4 != 2 {
+		return nil, fmt.Errorf("unsupported proxy v2 version %d", header[12]>>4)
+	}
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(br, payload); err != nil {
+		return nil, fmt.Errorf("read proxy v2 addresses: %w", err)
+	}
+
+	switch header[12] & 0x0f {
+	case 0x0: // LOCAL：代理自身的健康检查等连接
+		return nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, fmt.Errorf("unsupported proxy v2 command %d", header[12]&0x0f)
+	}
+
+	// 高 4 位为地址族，低 4 位为传输协议
+	switch header[13] >> 4 {
+	case 0x1: // AF_INET: src(4) dst(4) sport(2) dport(2)
+		if len(payload) < 12 {
+			return nil, errors.New("proxy v2 ipv4 address block too short")
+		}
+		ip := netip.AddrFrom4([4]byte(payload[0:4]))
+		return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip, binary.BigEndian.Uint16(payload[8:10]))), nil
+	case 0x2: // AF_INET6: src(16) dst(16) sport(2) dport(2)
+		if len(payload) < 36 {
+			return nil, errors.New("proxy v2 ipv6 address block too short")
+		}
+		ip := netip.AddrFrom16([16]byte(payload[0:16]))
+		return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip, binary.BigEndian.Uint16(payload[32:34]))), nil
+	default: // AF_UNSPEC / AF_UNIX：保留真实对端
+		return nil, nil
+	}
+}
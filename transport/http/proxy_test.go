@@ -0,0 +1,212 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestParseProxyHeader(t *testing.T) {
+	v2 := func(cmd, fam byte, addrs []byte) string {
+		h := append([]byte{}, proxyV2Signature...)
+		h = append(h, 0x20|cmd, fam, byte(len(addrs)>>8), byte(len(addrs)))
+		return string(append(h, addrs...))
+	}
+	ipv4 := []byte{198, 51, 100, 9, 10, 0, 0, 1, 0x15, 0xb3, 0x00, 0x50}
+
+	cases := []struct {
+		name    string
+		input   string
+		want    string // 空表示保留真实对端
+		wantErr bool
+	}{
+		{"v1 tcp4", "PROXY TCP4 198.51.100.9 10.0.0.1 5555 80\r\n", "198.51.100.9:5555", false},
+		{"v1 tcp6", "PROXY TCP6 2001:db8::1 2001:db8::2 5555 80\r\n", "[2001:db8::1]:5555", false},
+		{"v1 unknown", "PROXY UNKNOWN\r\n", "", false},
+		{"v1 family mismatch", "PROXY TCP4 2001:db8::1 10.0.0.1 5555 80\r\n", "", true},
+		{"v1 without crlf", "PROXY TCP4 198.51.100.9 10.0.0.1 5555 80\n", "", true},
+		{"v2 ipv4", v2(0x1, 0x11, ipv4), "198.51.100.9:5555", false},
+		{"v2 local", v2(0x0, 0x00, nil), "", false},
+		{"v2 short", v2(0x1, 0x11, ipv4[:6]), "", true},
+		{"missing", "GET / HTTP/1.1\r\n\r\n", "", true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			br := bufio.NewReader(strings.NewReader(tc.input + "GET"))
+			addr, err := parseProxyHeader(br)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", addr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got := ""
+			if addr != nil {
+				got = addr.String()
+			}
+			if got != tc.want {
+				t.Fatalf("unexpected addr: got %q want %q", got, tc.want)
+			}
+			// 头之后的数据保留给 HTTP 解析
+			if rest, _ := io.ReadAll(br); string(rest) != "GET" {
+				t.Fatalf("unexpected remaining data: %q", rest)
+			}
+		})
+	}
+}
+
+func TestTrustedProxyHeaders(t *testing.T) {
+	newApp := func(cfg ProxyConfig) *fiber.App {
+		app := NewHTTPServer(ServerParams{
+			Lc:     fxtest.NewLifecycle(t),
+			Logger: logger.NewNop(),
+			Config: Config{Proxy: cfg},
+		})
+		app.Get("/ip", func(c fiber.Ctx) error { return c.SendString(c.IP()) })
+		return app
+	}
+	get := func(app *fiber.App, headers map[string]string) string {
+		t.Helper()
+		req := httptest.NewRequest("GET", "/ip", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("app.Test: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	// app.Test 的对端地址为 0.0.0.0
+	trusted := newApp(ProxyConfig{TrustedProxies: []string{"0.0.0.0", "10.0.0.0/8"}})
+	if ip := get(trusted, map[string]string{"X-Forwarded-For": "1.1.1.1, 203.0.113.7, 10.0.0.2"}); ip != "203.0.113.7" {
+		t.Fatalf("x-forwarded-for: got %q", ip)
+	}
+	if ip := get(trusted, map[string]string{"X-Real-IP": "203.0.113.8"}); ip != "203.0.113.8" {
+		t.Fatalf("x-real-ip: got %q", ip)
+	}
+	if ip := get(trusted, nil); ip != "0.0.0.0" {
+		t.Fatalf("no header: got %q", ip)
+	}
+
+	ordered := newApp(ProxyConfig{TrustedProxies: []string{"0.0.0.0"}, Headers: []string{"CF-Connecting-IP", "X-Forwarded-For"}})
+	if ip := get(ordered, map[string]string{"CF-Connecting-IP": "203.0.113.9", "X-Forwarded-For": "203.0.113.7"}); ip != "203.0.113.9" {
+		t.Fatalf("header order: got %q", ip)
+	}
+
+	untrusted := newApp(ProxyConfig{TrustedProxies: []string{"10.0.0.0/8"}})
+	if ip := get(untrusted, map[string]string{"X-Forwarded-For": "203.0.113.7"}); ip != "0.0.0.0" {
+		t.Fatalf("untrusted peer: got %q", ip)
+	}
+
+	// ip_filter 使用代理还原后的客户端 IP，不再按自身 trusted_proxies 重复解析
+	filtered := NewHTTPServer(ServerParams{
+		Lc:     fxtest.NewLifecycle(t),
+		Logger: logger.NewNop(),
+		Config: Config{
+			Proxy:    ProxyConfig{TrustedProxies: []string{"0.0.0.0", "10.0.0.0/8"}},
+			IPFilter: middleware.IPFilterConfig{Enabled: true, Allow: []string{"203.0.113.0/24"}, TrustedProxies: []string{"0.0.0.0/0"}},
+		},
+	})
+	filtered.Get("/ip", func(c fiber.Ctx) error { return c.SendString(c.IP()) })
+	if ip := get(filtered, map[string]string{"X-Forwarded-For": "198.51.100.1, 203.0.113.7, 10.0.0.2"}); ip != "203.0.113.7" {
+		t.Fatalf("ip filter with proxy: got %q", ip)
+	}
+}
+
+func TestUnixSocketProxyTrust(t *testing.T) {
+	serve := func(cfg ProxyConfig) string {
+		t.Helper()
+		path := filepath.Join(t.TempDir(), "http.sock")
+		lc := fxtest.NewLifecycle(t)
+		app := NewHTTPServer(ServerParams{
+			Lc:        lc,
+			Logger:    logger.NewNop(),
+			Config:    Config{Listen: ListenOptions{DisableStartupMessage: true, UnixSocketPath: path}, Proxy: cfg},
+			Readiness: NewReadiness(),
+		})
+		app.Get("/ip", func(c fiber.Ctx) error { return c.SendString(c.IP()) })
+		lc.RequireStart()
+		defer lc.RequireStop()
+
+		client := &http.Client{Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", path)
+			},
+		}}
+		req, _ := http.NewRequest("GET", "http://unix/ip", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("get over unix socket: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	// Unix Socket 对端默认不可信
+	if ip := serve(ProxyConfig{TrustedProxies: []string{"10.0.0.0/8"}}); ip == "203.0.113.7" {
+		t.Fatalf("untrusted unix peer: forwarded header honored")
+	}
+	if ip := serve(ProxyConfig{TrustUnixSocket: true}); ip != "203.0.113.7" {
+		t.Fatalf("trusted unix peer: got %q", ip)
+	}
+}
+
+func TestProxyProtocolServer(t *testing.T) {
+	port := freePort(t)
+	lc := fxtest.NewLifecycle(t)
+	app := NewHTTPServer(ServerParams{
+		Lc:     lc,
+		Logger: logger.NewNop(),
+		Config: Config{
+			Host:   "127.0.0.1",
+			Port:   port,
+			Listen: ListenOptions{DisableStartupMessage: true},
+			Proxy:  ProxyConfig{TrustedProxies: []string{"127.0.0.1"}, ProxyProtocol: true},
+		},
+		Readiness: NewReadiness(),
+	})
+	app.Get("/ip", func(c fiber.Ctx) error { return c.SendString(c.IP()) })
+	lc.RequireStart()
+	defer lc.RequireStop()
+
+	conn, err := net.Dial("tcp4", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprintf(conn, "PROXY TCP4 198.51.100.9 127.0.0.1 5555 %d\r\nGET /ip HTTP/1.1\r\nHost: test\r\nConnection: close\r\n\r\n", port)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "198.51.100.9" {
+		t.Fatalf("unexpected client ip: %q", body)
+	}
+}
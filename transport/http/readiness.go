@@ -0,0 +1,77 @@
+package http
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+/* ========================================================================
+ * Readiness - 就绪状态开关
+ * ========================================================================
+ * 职责: 提供 /readyz 使用的共享就绪标记
+ * 场景: K8s 优雅下线时先将 /readyz 置为 503，等待负载均衡摘除流量
+ *       （由 shutdown.Manager 在执行关停钩子前自动翻转）
+ * 联动: OnChange 订阅状态变化（如 gRPC health 服务同步 NOT_SERVING），
+ *       返回的取消函数用于在订阅方停止时解除订阅
+ * ======================================================================== */
+
+// Readiness 就绪状态开关，零值为就绪
+type Readiness struct {
+	notReady atomic.Bool
+
+	mu        sync.Mutex
+	listeners []*readinessListener
+}
+
+// readinessListener 订阅者（以指针区分，便于取消订阅）
+type readinessListener struct {
+	fn func(ready bool)
+}
+
+// DefaultReadiness 进程级共享就绪开关
+// 未显式注入 *Readiness 时，HTTP / gRPC 服务器及其 ShutdownModule 均使用该实例
+var DefaultReadiness = NewReadiness()
+
+// NewReadiness 创建就绪开关（初始为就绪）
+func NewReadiness() *Readiness {
+	return &Readiness{}
+}
+
+// SetReady 设置就绪状态，状态变化时同步通知订阅者
+func (r *Readiness) SetReady(ready bool) {
+	if r.notReady.Swap(!ready) == !ready {
+		return
+	}
+
+	r.mu.Lock()
+	listeners := append([]*readinessListener{}, r.listeners...)
+	r.mu.Unlock()
+
+	for _, l := range listeners {
+		l.fn(ready)
+	}
+}
+
+// IsReady 是否就绪
+func (r *Readiness) IsReady() bool {
+	return !r.notReady.Load()
+}
+
+// OnChange 订阅就绪状态变化，返回取消订阅函数（可重复调用）
+// 订阅方生命周期短于 Readiness（如共享的 DefaultReadiness）时必须在停止时取消订阅
+func (r *Readiness) OnChange(fn func(ready bool)) (unsubscribe func()) {
+	if fn == nil {
+		return func() {}
+	}
+	l := &readinessListener{fn: fn}
+	r.mu.Lock()
+	r.listeners = append(r.listeners, l)
+	r.mu.Unlock()
+
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.listeners = slices.DeleteFunc(r.listeners, func(x *readinessListener) bool { return x == l })
+	}
+}
@@ -0,0 +1,33 @@
+package router
+
+import (
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+
+	"go.uber.org/fx"
+)
+
+/* ========================================================================
+ * Router FX Module - 路由预设 FX 模块
+ * ========================================================================
+ * 职责: 提供 *Router，配置与 APIKeyAuth 均可选
+ * ======================================================================== */
+
+// Params 依赖参数
+type Params struct {
+	fx.In
+
+	Config     *Config                `optional:"true"`
+	APIKeyAuth *middleware.APIKeyAuth `optional:"true"`
+	Logger     *logger.Logger         `optional:"true"`
+}
+
+// NewFromParams 从 FX 参数创建 Router
+func NewFromParams(p Params) *Router {
+	return New(p.Config, p.APIKeyAuth, p.Logger)
+}
+
+// Module FX 模块
+var Module = fx.Module("router",
+	fx.Provide(NewFromParams),
+)
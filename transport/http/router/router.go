@@ -0,0 +1,245 @@
+package router
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+	"github.com/aisgo/ais-go-pkg/request"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Route Group Builder - 基于预设的路由组
+ * ========================================================================
+ * 职责: 业务服务按名称声明路由组的中间件预设，由平台通过 YAML 统一约束
+ *       认证、IP 限制、跨域、限流、超时等默认策略
+ * 内置预设（YAML 中同名预设整体覆盖内置定义）:
+ *   - public:   无额外中间件
+ *   - authed:   API Key 认证
+ *   - internal: 仅允许内网地址（RFC 1918 / 回环 / IPv6 ULA）
+ *   - admin:    API Key 认证 + admin scope
+ * 挂载顺序: ip_filter → cors → auth → scopes → rate_limit → max_body_size → content → timeout → use → extra
+ * 失败关闭: 预设不存在、配置非法或需要认证但未提供 APIKeyAuth 时，该路由组拒绝全部请求
+ *
+ * 使用示例:
+ *   // router:
+ *   //   presets:
+ *   //     authed:
+ *   //       auth: true
+ *   //       rate_limit: {enabled: true, rate: 50, burst: 100, key_by: api_key}
+ *   //     partner:
+ *   //       auth: true
+ *   //       scopes: ["partner"]
+ *   //       cors: {enabled: true, allow_origins: ["https://partner.example.com"]}
+ *   //       use: ["audit"]
+ *   rt.Use("audit", auditHandler)
+ *   api := rt.Group(app, "/api/v1", router.PresetAuthed)
+ *   api.Get("/orders", listOrders)
+ * ======================================================================== */
+
+// 内置预设名称
+const (
+	PresetPublic   = "public"
+	PresetAuthed   = "authed"
+	PresetInternal = "internal"
+	PresetAdmin    = "admin"
+)
+
+// Config 路由预设配置
+type Config struct {
+	Presets map[string]PresetConfig `yaml:"presets"`
+}
+
+// PresetConfig 单个预设的中间件组合
+type PresetConfig struct {
+	Auth        bool                       `yaml:"auth"`          // 要求 API Key 认证
+	Scopes      []string                   `yaml:"scopes"`        // 要求的 API Key scope（隐含 auth）
+	IPFilter    middleware.IPFilterConfig  `yaml:"ip_filter"`     // enabled 为 true 时挂载
+	CORS        middleware.CORSConfig      `yaml:"cors"`          // enabled 为 true 时挂载
+	RateLimit   middleware.RateLimitConfig `yaml:"rate_limit"`    // enabled 为 true 时挂载
+	Timeout     time.Duration              `yaml:"timeout"`       // 路由组请求超时，0 表示沿用全局
+	MaxBodySize int                        `yaml:"max_body_size"` // 路由组请求体上限，0 表示沿用全局，不能超过全局 max_body_size
+	Content     request.NegotiateConfig    `yaml:"content"`       // 允许的请求/响应格式，codecs 非空时挂载
+	Use         []string                   `yaml:"use"`           // 通过 Router.Use 注册的具名中间件
+}
+
+// privateNetworks 内网地址段
+var privateNetworks = []string{
+	"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "127.0.0.0/8",
+	"::1/128", "fc00::/7",
+}
+
+// DefaultPresets 内置预设
+func DefaultPresets() map[string]PresetConfig {
+	return map[string]PresetConfig{
+		PresetPublic: {},
+		PresetAuthed: {Auth: true},
+		PresetInternal: {IPFilter: middleware.IPFilterConfig{
+			Enabled: true,
+			Allow:   slices.Clone(privateNetworks),
+		}},
+		PresetAdmin: {Auth: true, Scopes: []string{"admin"}},
+	}
+}
+
+// Router 路由组构建器
+type Router struct {
+	auth *middleware.APIKeyAuth
+	log  *logger.Logger
+
+	mu       sync.RWMutex
+	presets  map[string]PresetConfig
+	named    map[string]fiber.Handler
+	compiled map[string][]fiber.Handler
+}
+
+// New 创建路由组构建器；auth 为 nil 时需要认证的预设将拒绝全部请求
+func New(cfg *Config, auth *middleware.APIKeyAuth, log *logger.Logger) *Router {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	presets := DefaultPresets()
+	if cfg != nil {
+		maps.Copy(presets, cfg.Presets)
+	}
+	return &Router{
+		auth:     auth,
+		log:      log,
+		presets:  presets,
+		named:    make(map[string]fiber.Handler),
+		compiled: make(map[string][]fiber.Handler),
+	}
+}
+
+// Use 注册具名中间件，供预设的 use 字段引用；需在首次使用相关预设前注册
+func (r *Router) Use(name string, h fiber.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.named[name] = h
+	clear(r.compiled)
+}
+
+// Presets 返回全部预设名称（已排序）
+func (r *Router) Presets() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Sorted(maps.Keys(r.presets))
+}
+
+// Handlers 返回预设对应的中间件链，结果按预设缓存（限流等状态在同一预设的路由组间共享）
+func (r *Router) Handlers(preset string) ([]fiber.Handler, error) {
+	r.mu.RLock()
+	handlers, ok := r.compiled[preset]
+	r.mu.RUnlock()
+	if ok {
+		return handlers, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if handlers, ok := r.compiled[preset]; ok {
+		return handlers, nil
+	}
+	cfg, ok := r.presets[preset]
+	if !ok {
+		return nil, fmt.Errorf("router: unknown preset %q", preset)
+	}
+	handlers, err := r.build(preset, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("router: preset %q: %w", preset, err)
+	}
+	r.compiled[preset] = handlers
+	return handlers, nil
+}
+
+// Group 在 parent 上创建挂载预设中间件的路由组，extra 追加在预设中间件之后
+// 预设无法构建时记录错误并返回拒绝全部请求（403）的路由组
+func (r *Router) Group(parent fiber.Router, prefix, preset string, extra ...fiber.Handler) fiber.Router {
+	handlers, err := r.Handlers(preset)
+	if err != nil {
+		r.log.Error("Invalid route preset, rejecting all requests",
+			zap.String("prefix", prefix),
+			zap.String("preset", preset),
+			zap.Error(err),
+		)
+		handlers = []fiber.Handler{denyAll}
+	}
+
+	chain := make([]any, 0, len(handlers)+len(extra))
+	for _, h := range handlers {
+		chain = append(chain, h)
+	}
+	for _, h := range extra {
+		chain = append(chain, h)
+	}
+	group := parent.Group(prefix)
+	if len(chain) > 0 {
+		group.Use(chain...)
+	}
+	return group
+}
+
+// build 按固定顺序组装中间件
+func (r *Router) build(preset string, cfg PresetConfig) ([]fiber.Handler, error) {
+	var handlers []fiber.Handler
+
+	if cfg.IPFilter.Enabled {
+		filter, err := middleware.IPFilter(cfg.IPFilter)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, filter)
+	}
+	if cfg.CORS.Enabled {
+		handlers = append(handlers, middleware.CORS(cfg.CORS))
+	}
+	if cfg.Auth || len(cfg.Scopes) > 0 {
+		if r.auth == nil {
+			return nil, fmt.Errorf("auth required but APIKeyAuth is not provided")
+		}
+		handlers = append(handlers, r.auth.Authenticate())
+		if len(cfg.Scopes) > 0 {
+			handlers = append(handlers, middleware.RequireScopes(cfg.Scopes...))
+		}
+	}
+	if cfg.RateLimit.Enabled {
+		rl := cfg.RateLimit
+		if rl.Name == "" {
+			rl.Name = preset
+		}
+		handlers = append(handlers, middleware.RateLimit(rl))
+	}
+	if cfg.MaxBodySize > 0 {
+		handlers = append(handlers, middleware.MaxBodySize(cfg.MaxBodySize))
+	}
+	if len(cfg.Content.Codecs) > 0 {
+		negotiate, err := request.NewNegotiate(cfg.Content)
+		if err != nil {
+			return nil, err
+		}
+		handlers = append(handlers, negotiate)
+	}
+	if cfg.Timeout > 0 {
+		handlers = append(handlers, middleware.Timeout(cfg.Timeout))
+	}
+	for _, name := range cfg.Use {
+		h, ok := r.named[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown middleware %q", name)
+		}
+		handlers = append(handlers, h)
+	}
+	return handlers, nil
+}
+
+// denyAll 预设无效时拒绝请求
+func denyAll(fiber.Ctx) error {
+	return fiber.ErrForbidden
+}
@@ -0,0 +1,147 @@
+package router
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/middleware"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func newTestAuth() *middleware.APIKeyAuth {
+	return middleware.NewAPIKeyAuth(&middleware.APIKeyConfig{
+		Enabled: true,
+		Keys:    map[string]string{"svc": "svc-key", "ops": "ops-key"},
+		Scopes:  map[string][]string{"ops": {"admin"}},
+	}, logger.NewNop())
+}
+
+func doRequest(t *testing.T, app *fiber.App, method, path, apiKey string) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+func ok(c fiber.Ctx) error {
+	return c.SendString("ok")
+}
+
+func TestDefaultPresets(t *testing.T) {
+	rt := New(nil, newTestAuth(), logger.NewNop())
+	app := fiber.New()
+	rt.Group(app, "/pub", PresetPublic).Get("/x", ok)
+	rt.Group(app, "/api", PresetAuthed).Get("/x", ok)
+	rt.Group(app, "/admin", PresetAdmin).Get("/x", ok)
+	rt.Group(app, "/internal", PresetInternal).Get("/x", ok)
+
+	cases := []struct {
+		path, key string
+		want      int
+	}{
+		{"/pub/x", "", fiber.StatusOK},
+		{"/api/x", "", fiber.StatusUnauthorized},
+		{"/api/x", "svc-key", fiber.StatusOK},
+		{"/admin/x", "svc-key", fiber.StatusForbidden},
+		{"/admin/x", "ops-key", fiber.StatusOK},
+		// app.Test 的对端地址不在内网段内
+		{"/internal/x", "", fiber.StatusForbidden},
+	}
+	for _, tc := range cases {
+		if got := doRequest(t, app, fiber.MethodGet, tc.path, tc.key); got != tc.want {
+			t.Errorf("%s key=%q: status = %d, want %d", tc.path, tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestConfigOverridesAndNamedMiddleware(t *testing.T) {
+	rt := New(&Config{Presets: map[string]PresetConfig{
+		PresetInternal: {},
+		"partner":      {Auth: true, Use: []string{"tag"}},
+	}}, newTestAuth(), logger.NewNop())
+	rt.Use("tag", func(c fiber.Ctx) error {
+		c.Set("X-Preset", "partner")
+		return c.Next()
+	})
+
+	app := fiber.New()
+	rt.Group(app, "/internal", PresetInternal).Get("/x", ok)
+	rt.Group(app, "/partner", "partner").Get("/x", ok)
+
+	if got := doRequest(t, app, fiber.MethodGet, "/internal/x", ""); got != fiber.StatusOK {
+		t.Fatalf("overridden internal: status = %d", got)
+	}
+
+	req := httptest.NewRequest(fiber.MethodGet, "/partner/x", nil)
+	req.Header.Set("X-API-Key", "svc-key")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK || resp.Header.Get("X-Preset") != "partner" {
+		t.Fatalf("partner: status = %d, X-Preset = %q", resp.StatusCode, resp.Header.Get("X-Preset"))
+	}
+
+	if got := rt.Presets(); len(got) != 5 {
+		t.Fatalf("presets = %v", got)
+	}
+}
+
+func TestFailClosed(t *testing.T) {
+	rt := New(&Config{Presets: map[string]PresetConfig{
+		"bad-mw": {Use: []string{"missing"}},
+		"bad-ip": {IPFilter: middleware.IPFilterConfig{Enabled: true, Allow: []string{"not-an-ip"}}},
+	}}, nil, logger.NewNop())
+
+	app := fiber.New()
+	for _, preset := range []string{"unknown", PresetAuthed, "bad-mw", "bad-ip"} {
+		rt.Group(app, "/"+preset, preset).Get("/x", ok)
+		if got := doRequest(t, app, fiber.MethodGet, "/"+preset+"/x", ""); got != fiber.StatusForbidden {
+			t.Errorf("preset %q: status = %d, want 403", preset, got)
+		}
+		if _, err := rt.Handlers(preset); err == nil {
+			t.Errorf("preset %q: expected error", preset)
+		}
+	}
+}
+
+func TestRateLimitPreset(t *testing.T) {
+	rt := New(&Config{Presets: map[string]PresetConfig{
+		PresetPublic: {RateLimit: middleware.RateLimitConfig{Enabled: true, Rate: 0.001, Burst: 2}},
+	}}, nil, logger.NewNop())
+
+	app := fiber.New()
+	rt.Group(app, "/a", PresetPublic).Get("/x", ok)
+	rt.Group(app, "/b", PresetPublic).Get("/x", ok)
+
+	// 同一预设的路由组共享限流桶
+	for i, path := range []string{"/a/x", "/b/x"} {
+		if got := doRequest(t, app, fiber.MethodGet, path, ""); got != fiber.StatusOK {
+			t.Fatalf("request %d: status = %d", i, got)
+		}
+	}
+
+	req := httptest.NewRequest(fiber.MethodGet, "/a/x", nil)
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != fiber.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", resp.StatusCode)
+	}
+	if resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+		t.Fatal("missing Retry-After")
+	}
+}
@@ -2,15 +2,22 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"math"
+	nethttp "net/http"
 	"os"
 	"runtime"
 	"time"
 
+	"github.com/aisgo/ais-go-pkg/buildinfo"
 	"github.com/aisgo/ais-go-pkg/logger"
-	"github.com/aisgo/ais-go-pkg/metrics"
+	"github.com/aisgo/ais-go-pkg/middleware"
+	"github.com/aisgo/ais-go-pkg/upgrade"
 
 	"github.com/gofiber/fiber/v3"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
@@ -34,8 +41,56 @@ type Config struct {
 
 	// Listen 嵌套 ListenConfig 的可序列化配置项
 	Listen ListenOptions `yaml:"listen"`
+
+	// RequestID 请求 ID 中间件，默认挂载（request_id.disabled 为 true 时关闭）
+	RequestID middleware.RequestIDConfig `yaml:"request_id"`
+
+	// IPFilter IP 白名单/黑名单，enabled 为 true 时自动挂载
+	IPFilter middleware.IPFilterConfig `yaml:"ip_filter"`
+
+	// CORS 全局跨域配置，enabled 为 true 时自动挂载
+	CORS middleware.CORSConfig `yaml:"cors"`
+
+	// RequestTimeout 请求处理超时，默认 30s，负数表示不限制
+	RequestTimeout time.Duration `yaml:"request_timeout"`
+
+	// MaxBodySize 请求体最大字节数，默认 4MB，负数表示不限制
+	// 同时作为 Fiber BodyLimit（fasthttp 读取请求体时强制执行），路由级上限只能低于该值
+	MaxBodySize int `yaml:"max_body_size"`
+
+	// Static 静态资源与 SPA 回退，enabled 为 true 时自动挂载
+	Static StaticConfig `yaml:"static"`
+
+	// Debug pprof / expvar / 构建信息调试端点，enabled 为 true 时挂载
+	Debug DebugConfig `yaml:"debug"`
+
+	// Admin 内部管理端口，enabled 为 true 时健康检查、指标与调试端点改由该端口提供
+	Admin AdminConfig `yaml:"admin"`
+
+	// Compression 响应压缩与请求体解压，enabled 为 true 时自动挂载
+	Compression middleware.CompressionConfig `yaml:"compression"`
+
+	// Proxy 可信代理与 PROXY protocol，配置后还原真实客户端 IP（c.IP()）
+	Proxy ProxyConfig `yaml:"proxy"`
 }
 
+// DefaultConfig 返回默认配置（监听 8080，其余使用 NewHTTPServer 的内置默认值）
+func DefaultConfig() Config {
+	return Config{
+		Port:         8080,
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: 30 * time.Second,
+		IdleTimeout:  120 * time.Second,
+	}
+}
+
+const (
+	// defaultRequestTimeout 默认请求处理超时
+	defaultRequestTimeout = 30 * time.Second
+	// defaultMaxBodySize 默认请求体上限（与 Fiber 默认 BodyLimit 一致）
+	defaultMaxBodySize = 4 * 1024 * 1024
+)
+
 // ListenOptions 包含 Fiber ListenConfig 中可以通过 YAML 配置的字段
 // 对于更高级的配置（如 TLSConfigFunc、BeforeServeFunc 等函数类型），
 // 请使用 ServerParams 中的 ListenConfigCustomizer
@@ -68,9 +123,25 @@ type ListenOptions struct {
 	// Unix Socket 文件权限模式，默认 0770
 	UnixSocketFileMode uint32 `yaml:"unix_socket_file_mode"`
 
+	// Unix Socket 路径，非空时监听该路径（忽略 host/port），启动前清理残留的 socket 文件
+	UnixSocketPath string `yaml:"unix_socket_path"`
+
+	// 是否从 systemd socket activation (LISTEN_FDS) 继承监听器，未由 systemd 启动时回退为常规监听
+	SystemdActivation bool `yaml:"systemd_activation"`
+
+	// 继承的监听器名称（systemd FileDescriptorName），为空时使用第一个
+	SystemdSocketName string `yaml:"systemd_socket_name"`
+
 	// TLS 最低版本，默认 TLS 1.2
 	// 可选值: 771 (TLS 1.2), 772 (TLS 1.3)
 	TLSMinVersion uint16 `yaml:"tls_min_version"`
+
+	// 是否启用明文 HTTP/2 (h2c)，与 HTTP/1.1 共用端口，默认 false
+	// 注意：h2c 经 net/http 提供服务，不支持 Prefork 与 TLS，ListenConfigCustomizer 不生效
+	H2C bool `yaml:"h2c"`
+
+	// 实验性 HTTP/3 (QUIC) 监听，需注入 HTTP3Server
+	HTTP3 HTTP3Options `yaml:"http3"`
 }
 
 // ListenConfigCustomizer 自定义 ListenConfig 的函数类型
@@ -102,8 +173,35 @@ type ServerParams struct {
 
 	// AppConfigCustomizer 可选的 Fiber Config 自定义函数
 	AppConfigCustomizer AppConfigCustomizer `optional:"true"`
+
+	// Readiness 可选的就绪开关，未提供时使用 DefaultReadiness
+	Readiness *Readiness `optional:"true"`
+
+	// StaticFS 可选的静态资源文件系统（如 embed.FS），未提供时使用 Static.Root 目录
+	StaticFS fs.FS `name:"static_fs" optional:"true"`
+
+	// APIKeyAuth 可选的 API Key 认证，用于保护调试端点
+	APIKeyAuth *middleware.APIKeyAuth `optional:"true"`
+
+	// Gatherer 可选的指标来源（如 metrics.Module 提供的独立注册表），未提供时 /metrics 输出全局默认注册表
+	Gatherer prometheus.Gatherer `optional:"true"`
+
+	// AdminCustomizer 可选的管理端口路由注册函数，仅 admin.enabled 为 true 时生效
+	AdminCustomizer AdminCustomizer `optional:"true"`
+
+	// HTTP3Server 可选的 HTTP/3 实现，listen.http3.enabled 为 true 时必须提供
+	HTTP3Server HTTP3Server `optional:"true"`
+
+	// Upgrader 可选的平滑升级器，启用时监听器可在 SIGUSR2 升级时交接给新进程
+	Upgrader *upgrade.Upgrader `optional:"true"`
 }
 
+// 平滑升级时登记的监听器名称
+const (
+	listenerName      = "http"
+	adminListenerName = "http-admin"
+)
+
 // NewHTTPServer 创建 HTTP 服务器并注册生命周期
 func NewHTTPServer(p ServerParams) *fiber.App {
 	// 应用默认值
@@ -123,6 +221,14 @@ func NewHTTPServer(p ServerParams) *fiber.App {
 	if appName == "" {
 		appName = "AIS Go App"
 	}
+	requestTimeout := p.Config.RequestTimeout
+	if requestTimeout == 0 {
+		requestTimeout = defaultRequestTimeout
+	}
+	maxBodySize := p.Config.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = defaultMaxBodySize
+	}
 
 	appConfig := fiber.Config{
 		AppName:      appName,
@@ -130,6 +236,12 @@ func NewHTTPServer(p ServerParams) *fiber.App {
 		WriteTimeout: writeTimeout,
 		IdleTimeout:  idleTimeout,
 	}
+	if maxBodySize > 0 {
+		appConfig.BodyLimit = maxBodySize
+	} else {
+		// Fiber 将 BodyLimit <= 0 视为默认 4MB，不限制时显式设为最大值
+		appConfig.BodyLimit = math.MaxInt
+	}
 
 	if p.AppConfigCustomizer != nil {
 		p.AppConfigCustomizer(&appConfig)
@@ -140,24 +252,103 @@ func NewHTTPServer(p ServerParams) *fiber.App {
 
 	app := fiber.New(appConfig)
 
-	// 注册健康检查端点
-	registerHealthEndpoints(app, p.DB)
+	// 协议与代理配置错误延迟到 OnStart 返回，阻止启动
+	var h3 http3Listener
+	var protoErr error
+	if p.Config.Listen.H2C {
+		protoErr = validateH2C(p.Config.Listen)
+	}
+	if p.Config.Listen.HTTP3.Enabled && protoErr == nil {
+		h3, protoErr = resolveHTTP3(p.Config)
+		if protoErr == nil && p.HTTP3Server == nil {
+			protoErr = fmt.Errorf("http: http3 enabled but no HTTP3Server provided")
+		}
+		if protoErr == nil {
+			if advertise := altSvc(h3.port, p.Config.Listen.HTTP3.AltSvcMaxAge); advertise != nil {
+				app.Use(advertise)
+			}
+		}
+	}
+	var h2c *nethttp.Server
+
+	// 还原真实客户端 IP，需先于其他中间件执行
+	proxy, err := newProxyResolver(p.Config.Proxy)
+	if err != nil && protoErr == nil {
+		protoErr = err
+	}
+	if proxy != nil {
+		app.Use(proxy.middleware())
+	}
 
-	// 注册 Prometheus 指标端点
-	metrics.RegisterMetricsEndpoint(app)
+	if !p.Config.RequestID.Disabled {
+		app.Use(middleware.RequestID(p.Config.RequestID))
+	}
+	if p.Config.IPFilter.Enabled {
+		filter, err := middleware.IPFilter(p.Config.IPFilter)
+		if err != nil {
+			// 配置错误时拒绝全部请求，避免过滤规则静默失效
+			p.Logger.Error("Invalid ip_filter config, rejecting all requests", zap.Error(err))
+			filter = func(fiber.Ctx) error { return fiber.ErrForbidden }
+		}
+		app.Use(filter)
+	}
+	if p.Config.CORS.Enabled {
+		app.Use(middleware.CORS(p.Config.CORS))
+	}
+	if p.Config.Compression.Enabled {
+		// 位于业务处理器之外层，压缩包括错误响应在内的最终响应体
+		app.Use(middleware.Compress(p.Config.Compression))
+	}
+	if maxBodySize > 0 {
+		app.Use(middleware.MaxBodySize(maxBodySize))
+	}
+	if p.Config.Compression.Enabled && p.Config.Compression.Decompress {
+		// 解压后的大小同样受 max_body_size 限制
+		app.Use(middleware.Decompress(maxBodySize))
+	}
+	if requestTimeout > 0 {
+		app.Use(middleware.Timeout(requestTimeout))
+	}
+
+	readiness := p.Readiness
+	if readiness == nil {
+		readiness = DefaultReadiness
+	}
+
+	// 注册健康检查、Prometheus 指标与调试端点（启用管理端口时改由管理端口提供）
+	if p.Config.Admin.Enabled {
+		newAdminApp(p, appName, readiness)
+	} else {
+		mountOpsEndpoints(app, p, readiness)
+	}
+
+	if p.Config.Static.Enabled {
+		MountStatic(app, p.Config.Static, p.StaticFS)
+	}
 
 	p.Lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			// 创建 channel 用于传递启动错误
-			errChan := make(chan error, 1)
+			if protoErr != nil {
+				return protoErr
+			}
 
-			go func() {
-				addr := fmt.Sprintf(":%d", p.Config.Port)
-				if p.Config.Host != "" {
-					addr = fmt.Sprintf("%s:%d", p.Config.Host, p.Config.Port)
-				}
-				p.Logger.Info("Starting HTTP Server", zap.String("addr", addr))
+			// SPA 回退需位于全部业务路由之后（业务路由在 fx.Invoke 中注册，早于 OnStart）
+			if p.Config.Static.Enabled {
+				MountSPAFallback(app, p.Config.Static, p.StaticFS)
+			}
 
+			addr := fmt.Sprintf(":%d", p.Config.Port)
+			if p.Config.Host != "" {
+				addr = fmt.Sprintf("%s:%d", p.Config.Host, p.Config.Port)
+			}
+			p.Logger.Info("Starting HTTP Server", zap.String("addr", addr), zap.Bool("h2c", p.Config.Listen.H2C))
+
+			if p.Config.Listen.H2C {
+				h2c = h2cServer(app, readTimeout, writeTimeout, idleTimeout)
+				if err := startH2C(ctx, h2c, addr, p.Config.Listen, p.Upgrader, proxy, p.Logger); err != nil {
+					return err
+				}
+			} else {
 				// 构建 ListenConfig
 				listenConfig := buildListenConfig(p.Config.Listen)
 
@@ -166,34 +357,79 @@ func NewHTTPServer(p ServerParams) *fiber.App {
 					p.ListenConfigCustomizer(&listenConfig)
 				}
 
-				if err := app.Listen(addr, listenConfig); err != nil {
-					p.Logger.Error("HTTP Server failed to start", zap.Error(err))
-					errChan <- err
+				if customListener(p.Config.Listen, p.Upgrader, proxy) {
+					if p.Config.Listen.EnablePrefork {
+						return fmt.Errorf("http: prefork is not supported with unix socket, systemd activation, proxy protocol or upgrade")
+					}
+					ln, err := createListener(p.Config.Listen, addr, p.Upgrader, listenerName, proxy, p.Logger)
+					if err != nil {
+						return err
+					}
+					if err := startApp(ctx, addr, func() error { return app.Listener(ln, listenConfig) }, p.Logger); err != nil {
+						return err
+					}
+				} else if err := startApp(ctx, addr, func() error { return app.Listen(addr, listenConfig) }, p.Logger); err != nil {
+					return err
 				}
-			}()
-
-			// 等待一小段时间，确保服务器成功启动
-			select {
-			case err := <-errChan:
-				// 服务器立即启动失败
-				return err
-			case <-time.After(100 * time.Millisecond):
-				// 服务器似乎成功启动
-				return nil
-			case <-ctx.Done():
-				// 上下文被取消
-				return ctx.Err()
 			}
+
+			if p.Config.Listen.HTTP3.Enabled {
+				if err := startHTTP3(ctx, p.HTTP3Server, app, h3, p.Config.Listen.TLSMinVersion, p.Logger); err != nil {
+					// OnStart 失败时 fx 不会调用本 Hook 的 OnStop，需自行关闭 TCP 监听
+					if h2c != nil {
+						_ = h2c.Close()
+					} else {
+						_ = app.Shutdown()
+					}
+					return err
+				}
+			}
+			return nil
 		},
 		OnStop: func(ctx context.Context) error {
 			p.Logger.Info("Stopping HTTP Server")
-			return app.ShutdownWithContext(ctx)
+			var errs []error
+			if p.Config.Listen.HTTP3.Enabled {
+				errs = append(errs, p.HTTP3Server.Shutdown(ctx))
+			}
+			if h2c != nil {
+				errs = append(errs, h2c.Shutdown(ctx))
+			} else {
+				errs = append(errs, app.ShutdownWithContext(ctx))
+			}
+			return errors.Join(errs...)
 		},
 	})
 
 	return app
 }
 
+// startApp 在后台运行 serve，等待一小段时间以捕获立即发生的启动错误
+func startApp(ctx context.Context, addr string, serve func() error, log *logger.Logger) error {
+	// 创建 channel 用于传递启动错误
+	errChan := make(chan error, 1)
+
+	go func() {
+		if err := serve(); err != nil && !errors.Is(err, nethttp.ErrServerClosed) {
+			log.Error("HTTP Server failed to start", zap.String("addr", addr), zap.Error(err))
+			errChan <- err
+		}
+	}()
+
+	// 等待一小段时间，确保服务器成功启动
+	select {
+	case err := <-errChan:
+		// 服务器立即启动失败
+		return err
+	case <-time.After(100 * time.Millisecond):
+		// 服务器似乎成功启动
+		return nil
+	case <-ctx.Done():
+		// 上下文被取消
+		return ctx.Err()
+	}
+}
+
 // buildListenConfig 根据 ListenOptions 构建 Fiber ListenConfig，并应用默认值
 func buildListenConfig(opts ListenOptions) fiber.ListenConfig {
 	config := fiber.ListenConfig{
@@ -235,24 +471,33 @@ func buildListenConfig(opts ListenOptions) fiber.ListenConfig {
  * ========================================================================
  * /healthz - 存活探针 (Liveness Probe)
  *   - 用于 K8s 判断容器是否存活
- *   - 只要进程能响应就返回 200
+ *   - 只要进程能响应就返回 200，附带构建信息
  *
  * /readyz - 就绪探针 (Readiness Probe)
  *   - 用于 K8s 判断容器是否可以接收流量
  *   - 需要检查数据库等依赖是否就绪
+ *   - 关停排空期间（Readiness 置为未就绪）直接返回 503
  * ======================================================================== */
 
-func registerHealthEndpoints(app *fiber.App, db *gorm.DB) {
+func registerHealthEndpoints(app *fiber.App, db *gorm.DB, readiness *Readiness) {
 	// 存活探针 - 简单返回 OK
 	app.Get("/healthz", func(c fiber.Ctx) error {
 		return c.JSON(fiber.Map{
 			"status": "ok",
 			"time":   time.Now().Format(time.RFC3339),
+			"build":  buildinfo.Get(),
 		})
 	})
 
 	// 就绪探针 - 检查依赖
 	app.Get("/readyz", func(c fiber.Ctx) error {
+		if readiness != nil && !readiness.IsReady() {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status": "draining",
+				"time":   time.Now().Format(time.RFC3339),
+			})
+		}
+
 		checks := make(map[string]string)
 		healthy := true
 
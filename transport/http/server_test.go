@@ -2,11 +2,16 @@ package http
 
 import (
 	"encoding/json"
+	"math"
+	"net"
 	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/aisgo/ais-go-pkg/logger"
+
 	"github.com/gofiber/fiber/v3"
+	"go.uber.org/fx/fxtest"
 )
 
 func TestBuildListenConfigDefaults(t *testing.T) {
@@ -45,7 +50,7 @@ func TestBuildListenConfigOverrides(t *testing.T) {
 
 func TestHealthEndpoints(t *testing.T) {
 	app := fiber.New()
-	registerHealthEndpoints(app, nil)
+	registerHealthEndpoints(app, nil, NewReadiness())
 
 	req := httptest.NewRequest("GET", "/healthz", nil)
 	resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
@@ -75,3 +80,57 @@ func TestHealthEndpoints(t *testing.T) {
 		t.Fatalf("unexpected status body: %v", body["status"])
 	}
 }
+
+func TestReadyzDraining(t *testing.T) {
+	readiness := NewReadiness()
+	app := fiber.New()
+	registerHealthEndpoints(app, nil, readiness)
+
+	readiness.SetReady(false)
+	resp, err := app.Test(httptest.NewRequest("GET", "/readyz", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusServiceUnavailable {
+		t.Fatalf("unexpected status: %d", resp.StatusCode)
+	}
+
+	readiness.SetReady(true)
+	resp, err = app.Test(httptest.NewRequest("GET", "/readyz", nil), fiber.TestConfig{Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("unexpected status: %d", resp.StatusCode)
+	}
+}
+
+// freePort 返回一个可用的本地 TCP 端口
+func freePort(t *testing.T) int {
+	t.Helper()
+	lis, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer lis.Close()
+	return lis.Addr().(*net.TCPAddr).Port
+}
+
+func TestBodyLimitConfig(t *testing.T) {
+	cases := []struct {
+		maxBodySize int
+		want        int
+	}{
+		{0, defaultMaxBodySize},
+		{1 << 20, 1 << 20},
+		{-1, math.MaxInt},
+	}
+	for _, tc := range cases {
+		app := NewHTTPServer(ServerParams{Lc: fxtest.NewLifecycle(t), Logger: logger.NewNop(), Config: Config{MaxBodySize: tc.maxBodySize}})
+		if got := app.Config().BodyLimit; got != tc.want {
+			t.Fatalf("max_body_size=%d: BodyLimit=%d, want %d", tc.maxBodySize, got, tc.want)
+		}
+	}
+}
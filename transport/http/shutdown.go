@@ -0,0 +1,50 @@
+package http
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v3"
+	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/shutdown"
+)
+
+/* ========================================================================
+ * HTTP Shutdown - 接入优雅关停
+ * ========================================================================
+ * 职责: 将 Fiber 服务器注册到 shutdown.Manager，并关联就绪开关
+ * 说明: 排空期开始时 /readyz 置为 503，随后按 Priorities.HTTPServer 停止服务
+ * ======================================================================== */
+
+// RegisterShutdown 注册 Fiber 服务器关停钩子
+func RegisterShutdown(m *shutdown.Manager, app *fiber.App) {
+	m.RegisterHookWithPriority("http-server", shutdown.Once(func(ctx context.Context) error {
+		return app.ShutdownWithContext(ctx)
+	}), *m.Priorities().HTTPServer)
+}
+
+// ShutdownParams 关停集成依赖参数
+type ShutdownParams struct {
+	fx.In
+
+	Lc      fx.Lifecycle
+	Manager *shutdown.Manager
+	App     *fiber.App
+
+	// Readiness 可选的就绪开关，未提供时使用 DefaultReadiness
+	Readiness *Readiness `optional:"true"`
+}
+
+// ShutdownModule HTTP 服务器关停集成模块
+// 需与 shutdown.Module 一起使用
+var ShutdownModule = fx.Module("http-shutdown",
+	fx.Invoke(func(p ShutdownParams) {
+		readiness := p.Readiness
+		if readiness == nil {
+			readiness = DefaultReadiness
+		}
+		p.Manager.UseReadiness(readiness)
+		RegisterShutdown(p.Manager, p.App)
+		p.Manager.ShutdownOnStop(p.Lc)
+	}),
+)
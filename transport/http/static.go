@@ -0,0 +1,208 @@
+package http
+
+import (
+	"errors"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+/* ========================================================================
+ * Static Files - 静态资源与 SPA 回退
+ * ========================================================================
+ * 职责: 从目录或 embed.FS 提供静态资源，内嵌管理后台无需额外部署 nginx
+ * 特性:
+ *   - 预压缩: 按 Accept-Encoding 优先返回同名 .br / .gz 文件（构建时生成）
+ *   - 缓存: index.html 为 no-cache，其余资源按 max_age 设置 Cache-Control
+ *   - SPA: 未匹配任何路由的页面导航请求（Accept text/html）回退到 index.html，
+ *          回退在服务器启动时注册于全部业务路由之后，spa_exclude 前缀（如 /api）不回退
+ * 配置示例:
+ *   http:
+ *     static:
+ *       enabled: true
+ *       prefix: /admin
+ *       root: ./web/dist        # 未注入 StaticFS 时使用
+ *       spa: true
+ *       max_age: 168h
+ *       spa_exclude: ["/api"]
+ * 使用示例（embed.FS）:
+ *   //go:embed dist
+ *   var dist embed.FS
+ *   sub, _ := fs.Sub(dist, "dist")
+ *   fx.Provide(fx.Annotate(func() fs.FS { return sub }, fx.ResultTags(`name:"static_fs"`)))
+ *   // 或手动挂载: httpserver.MountStatic(app, cfg.Static, sub)
+ * ======================================================================== */
+
+// StaticConfig 静态资源配置
+type StaticConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	Prefix     string        `yaml:"prefix"`      // 挂载路径，默认 /
+	Root       string        `yaml:"root"`        // 资源目录（未提供 fs.FS 时使用）
+	Index      string        `yaml:"index"`       // 首页文件，默认 index.html
+	SPA        bool          `yaml:"spa"`         // 是否启用 SPA 回退
+	SPAExclude []string      `yaml:"spa_exclude"` // 不回退的路径前缀
+	MaxAge     time.Duration `yaml:"max_age"`     // 非首页资源缓存时长，默认 1h，负数表示 no-cache
+}
+
+func (c StaticConfig) withDefaults() StaticConfig {
+	if c.Prefix == "" {
+		c.Prefix = "/"
+	}
+	c.Prefix = "/" + strings.Trim(c.Prefix, "/")
+	if c.Index == "" {
+		c.Index = "index.html"
+	}
+	if c.MaxAge == 0 {
+		c.MaxAge = time.Hour
+	}
+	return c
+}
+
+// staticFS 返回资源文件系统，fsys 为空时使用 Root 目录
+func (c StaticConfig) staticFS(fsys fs.FS) fs.FS {
+	if fsys != nil {
+		return fsys
+	}
+	return os.DirFS(c.Root)
+}
+
+// Static 静态资源处理器：文件存在时返回，否则交给后续路由
+func Static(cfg StaticConfig, fsys fs.FS) fiber.Handler {
+	cfg = cfg.withDefaults()
+	fsys = cfg.staticFS(fsys)
+
+	return func(c fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+		name, ok := staticName(cfg.Prefix, c.Path())
+		if !ok {
+			return c.Next()
+		}
+		if name == "" {
+			name = cfg.Index
+		}
+		info, err := fs.Stat(fsys, name)
+		if err == nil && info.IsDir() {
+			name = path.Join(name, cfg.Index)
+			info, err = fs.Stat(fsys, name)
+		}
+		if err != nil || info.IsDir() {
+			return c.Next()
+		}
+		return sendStatic(c, fsys, name, cfg)
+	}
+}
+
+// SPAFallback SPA 回退处理器，应注册在全部路由之后
+func SPAFallback(cfg StaticConfig, fsys fs.FS) fiber.Handler {
+	cfg = cfg.withDefaults()
+	fsys = cfg.staticFS(fsys)
+
+	return func(c fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+		p := c.Path()
+		if _, ok := staticName(cfg.Prefix, p); !ok {
+			return c.Next()
+		}
+		for _, prefix := range cfg.SPAExclude {
+			if strings.HasPrefix(p, prefix) {
+				return c.Next()
+			}
+		}
+		// 仅回退页面导航请求：无扩展名且接受 HTML
+		if path.Ext(p) != "" || !strings.Contains(c.Get(fiber.HeaderAccept), fiber.MIMETextHTML) {
+			return c.Next()
+		}
+		return sendStatic(c, fsys, cfg.Index, cfg)
+	}
+}
+
+// MountStatic 挂载静态资源处理器，SPA 回退需在全部路由注册后调用 MountSPAFallback
+func MountStatic(app *fiber.App, cfg StaticConfig, fsys fs.FS) {
+	app.Use(Static(cfg, fsys))
+}
+
+// MountSPAFallback 挂载 SPA 回退（须在全部业务路由之后调用）
+func MountSPAFallback(app *fiber.App, cfg StaticConfig, fsys fs.FS) {
+	if cfg.SPA {
+		app.Use(SPAFallback(cfg, fsys))
+	}
+}
+
+// staticName 将请求路径转换为 fs 内的相对路径，不在 prefix 下时返回 false
+func staticName(prefix, reqPath string) (string, bool) {
+	clean := path.Clean("/" + reqPath)
+	if prefix != "/" {
+		if clean != prefix && !strings.HasPrefix(clean, prefix+"/") {
+			return "", false
+		}
+		clean = strings.TrimPrefix(clean, prefix)
+	}
+	return strings.TrimPrefix(clean, "/"), true
+}
+
+// sendStatic 输出文件，按 Accept-Encoding 优先使用预压缩版本
+func sendStatic(c fiber.Ctx, fsys fs.FS, name string, cfg StaticConfig) error {
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = fiber.MIMEOctetStream
+	}
+
+	file, encoding := name, ""
+	accept := c.Get(fiber.HeaderAcceptEncoding)
+	for _, variant := range []struct{ encoding, ext string }{{"br", ".br"}, {"gzip", ".gz"}} {
+		if !acceptsEncoding(accept, variant.encoding) {
+			continue
+		}
+		if info, err := fs.Stat(fsys, name+variant.ext); err == nil && !info.IsDir() {
+			file, encoding = name+variant.ext, variant.encoding
+			break
+		}
+	}
+
+	data, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return c.Next()
+		}
+		return err
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderVary, fiber.HeaderAcceptEncoding)
+	if encoding != "" {
+		c.Set(fiber.HeaderContentEncoding, encoding)
+	}
+	if name == cfg.Index || path.Base(name) == cfg.Index || cfg.MaxAge < 0 {
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+	} else {
+		c.Set(fiber.HeaderCacheControl, "public, max-age="+strconv.Itoa(int(cfg.MaxAge.Seconds())))
+	}
+	if c.Method() == fiber.MethodHead {
+		c.Set(fiber.HeaderContentLength, strconv.Itoa(len(data)))
+		return nil
+	}
+	return c.Send(data)
+}
+
+// acceptsEncoding Accept-Encoding 是否包含 encoding（q=0 视为不接受）
+func acceptsEncoding(header, encoding string) bool {
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(name), encoding) {
+			continue
+		}
+		q := strings.ReplaceAll(strings.TrimSpace(params), " ", "")
+		return q != "q=0" && q != "q=0.0" && q != "q=0.00" && q != "q=0.000"
+	}
+	return false
+}
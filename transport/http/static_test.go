@@ -0,0 +1,73 @@
+package http
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestStaticAndSPAFallback(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":       {Data: []byte("<html>app</html>")},
+		"assets/app.js":    {Data: []byte("console.log(1)")},
+		"assets/app.js.br": {Data: []byte("brotli")},
+		"assets/app.js.gz": {Data: []byte("gzip")},
+	}
+	cfg := StaticConfig{Enabled: true, Prefix: "/admin", SPA: true, SPAExclude: []string{"/admin/api"}}
+
+	app := fiber.New()
+	MountStatic(app, cfg, fsys)
+	app.Get("/admin/api/users", func(c fiber.Ctx) error { return c.SendString("users") })
+	MountSPAFallback(app, cfg, fsys)
+
+	cases := []struct {
+		path, accept, encoding string
+		status                 int
+		body, contentEncoding  string
+		cacheControl           string
+	}{
+		{"/admin/", "", "", fiber.StatusOK, "<html>app</html>", "", "no-cache"},
+		{"/admin/assets/app.js", "", "", fiber.StatusOK, "console.log(1)", "", "public, max-age=3600"},
+		{"/admin/assets/app.js", "", "gzip, br", fiber.StatusOK, "brotli", "br", "public, max-age=3600"},
+		{"/admin/assets/app.js", "", "br;q=0, gzip", fiber.StatusOK, "gzip", "gzip", "public, max-age=3600"},
+		{"/admin/api/users", "text/html", "", fiber.StatusOK, "users", "", ""},
+		{"/admin/orders/42", "text/html,application/xhtml+xml", "", fiber.StatusOK, "<html>app</html>", "", "no-cache"},
+		{"/admin/orders/42", "application/json", "", fiber.StatusNotFound, "", "", ""},
+		{"/admin/api/missing", "text/html", "", fiber.StatusNotFound, "", "", ""},
+		{"/admin/missing.js", "text/html", "", fiber.StatusNotFound, "", "", ""},
+		{"/admin/../index.html", "", "", fiber.StatusNotFound, "", "", ""},
+		{"/other", "text/html", "", fiber.StatusNotFound, "", "", ""},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		if tc.accept != "" {
+			req.Header.Set(fiber.HeaderAccept, tc.accept)
+		}
+		if tc.encoding != "" {
+			req.Header.Set(fiber.HeaderAcceptEncoding, tc.encoding)
+		}
+		resp, err := app.Test(req, fiber.TestConfig{Timeout: 2 * time.Second})
+		if err != nil {
+			t.Fatalf("%s: app.Test: %v", tc.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tc.status {
+			t.Fatalf("%s: unexpected status %d", tc.path, resp.StatusCode)
+		}
+		if tc.body != "" && string(body) != tc.body {
+			t.Fatalf("%s: unexpected body %q", tc.path, body)
+		}
+		if got := resp.Header.Get(fiber.HeaderContentEncoding); got != tc.contentEncoding {
+			t.Fatalf("%s: unexpected content encoding %q", tc.path, got)
+		}
+		if tc.cacheControl != "" && resp.Header.Get(fiber.HeaderCacheControl) != tc.cacheControl {
+			t.Fatalf("%s: unexpected cache control %q", tc.path, resp.Header.Get(fiber.HeaderCacheControl))
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package ws
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/shutdown"
+
+	"github.com/fasthttp/websocket"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * WebSocket Hub - 连接与房间管理
+ * ========================================================================
+ * 职责: 跟踪在线连接，提供广播/房间广播，关停时统一关闭连接
+ * 说明: 连接断开时自动退出所有房间；广播遇到慢连接时仅断开该连接
+ * ======================================================================== */
+
+// Hub 连接管理器
+type Hub struct {
+	cfg *Config
+	log *logger.Logger
+
+	mu    sync.RWMutex
+	conns map[string]*Conn
+	rooms map[string]map[string]*Conn
+	// memberOf 连接所在房间（断开时清理）
+	memberOf map[string]map[string]struct{}
+
+	wg     sync.WaitGroup
+	closed atomic.Bool
+}
+
+// NewHub 创建连接管理器
+func NewHub(cfg *Config, log *logger.Logger) *Hub {
+	return &Hub{
+		cfg:      cfg.withDefaults(),
+		log:      newLogger(log),
+		conns:    make(map[string]*Conn),
+		rooms:    make(map[string]map[string]*Conn),
+		memberOf: make(map[string]map[string]struct{}),
+	}
+}
+
+// Len 在线连接数
+func (h *Hub) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns)
+}
+
+// Conn 按 ID 获取连接
+func (h *Hub) Conn(id string) (*Conn, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	conn, ok := h.conns[id]
+	return conn, ok
+}
+
+// Conns 当前全部连接快照
+func (h *Hub) Conns() []*Conn {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	list := make([]*Conn, 0, len(h.conns))
+	for _, conn := range h.conns {
+		list = append(list, conn)
+	}
+	return list
+}
+
+// Join 加入房间
+func (h *Hub) Join(conn *Conn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.conns[conn.id]; !ok {
+		return
+	}
+	members := h.rooms[room]
+	if members == nil {
+		members = make(map[string]*Conn)
+		h.rooms[room] = members
+	}
+	members[conn.id] = conn
+	if h.memberOf[conn.id] == nil {
+		h.memberOf[conn.id] = make(map[string]struct{})
+	}
+	h.memberOf[conn.id][room] = struct{}{}
+}
+
+// Leave 离开房间
+func (h *Hub) Leave(conn *Conn, room string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.leaveLocked(conn.id, room)
+}
+
+func (h *Hub) leaveLocked(id, room string) {
+	if members := h.rooms[room]; members != nil {
+		delete(members, id)
+		if len(members) == 0 {
+			delete(h.rooms, room)
+		}
+	}
+	if rooms := h.memberOf[id]; rooms != nil {
+		delete(rooms, room)
+	}
+}
+
+// RoomSize 房间人数
+func (h *Hub) RoomSize(room string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.rooms[room])
+}
+
+// Broadcast 向全部连接广播，返回成功入队的连接数
+func (h *Hub) Broadcast(msg Message) int {
+	return sendAll(h.Conns(), msg)
+}
+
+// BroadcastRoom 向房间广播，返回成功入队的连接数
+func (h *Hub) BroadcastRoom(room string, msg Message) int {
+	h.mu.RLock()
+	members := make([]*Conn, 0, len(h.rooms[room]))
+	for _, conn := range h.rooms[room] {
+		members = append(members, conn)
+	}
+	h.mu.RUnlock()
+	return sendAll(members, msg)
+}
+
+func sendAll(conns []*Conn, msg Message) int {
+	sent := 0
+	for _, conn := range conns {
+		if conn.Send(msg) == nil {
+			sent++
+		}
+	}
+	return sent
+}
+
+// Shutdown 拒绝新连接，向全部连接发送 1001（Going Away）并等待断开
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.closed.Store(true)
+	if n := h.Len(); n > 0 {
+		h.log.Info("closing websocket connections", zap.Int("count", n))
+	}
+	return h.closeAll(ctx, websocket.CloseGoingAway, "server shutting down")
+}
+
+// RegisterShutdown 注册到关停管理器，与 HTTP 服务器同批关闭
+// （HTTP 服务器关停不会关闭已升级的连接）
+func (h *Hub) RegisterShutdown(m *shutdown.Manager) {
+	m.RegisterHookWithPriority("websocket-hub", h.Shutdown, shutdown.PriorityFirst)
+}
+
+func (h *Hub) isClosed() bool {
+	return h.closed.Load()
+}
+
+func (h *Hub) add(conn *Conn) {
+	h.wg.Add(1)
+	h.mu.Lock()
+	h.conns[conn.id] = conn
+	h.mu.Unlock()
+}
+
+func (h *Hub) remove(conn *Conn) {
+	h.mu.Lock()
+	delete(h.conns, conn.id)
+	for room := range h.memberOf[conn.id] {
+		h.leaveLocked(conn.id, room)
+	}
+	delete(h.memberOf, conn.id)
+	h.mu.Unlock()
+	h.wg.Done()
+}
+
+// drained 全部连接退出后关闭的通道
+func (h *Hub) drained() <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	return done
+}
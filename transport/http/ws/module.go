@@ -0,0 +1,46 @@
+package ws
+
+import (
+	"context"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/shutdown"
+
+	"go.uber.org/fx"
+)
+
+/* ========================================================================
+ * WebSocket FX Module - WebSocket FX 模块
+ * ========================================================================
+ * 职责: 提供 *Hub，并接入关停管理器（未提供 Manager 时挂在 fx OnStop）
+ * ======================================================================== */
+
+// HubParams 依赖参数
+type HubParams struct {
+	fx.In
+
+	Lc       fx.Lifecycle
+	Config   *Config           `optional:"true"`
+	Logger   *logger.Logger    `optional:"true"`
+	Shutdown *shutdown.Manager `optional:"true"`
+}
+
+// NewHubFromParams 从 FX 参数创建 Hub
+func NewHubFromParams(p HubParams) *Hub {
+	hub := NewHub(p.Config, p.Logger)
+	if p.Shutdown != nil {
+		hub.RegisterShutdown(p.Shutdown)
+		return hub
+	}
+	p.Lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			return hub.Shutdown(ctx)
+		},
+	})
+	return hub
+}
+
+// Module FX 模块
+var Module = fx.Module("websocket",
+	fx.Provide(NewHubFromParams),
+)
@@ -0,0 +1,394 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v3"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * WebSocket - Fiber WebSocket 连接管理
+ * ========================================================================
+ * 职责: 基于 fasthttp/websocket 升级连接，统一管理连接生命周期
+ * 特性:
+ *   - 升级前鉴权（Authenticate 钩子，可复用 API Key 等中间件写入的 Locals）
+ *   - 心跳: 定时 Ping，PongWait 内未收到 Pong/消息即断开
+ *   - 每连接独立写协程 + 有界发送队列，慢连接队列满时断开，不阻塞广播
+ *   - Hub 管理连接与房间，支持广播/房间广播，关停时发送 1001 关闭帧
+ * 配置示例:
+ *   websocket:
+ *     ping_interval: 30s
+ *     pong_wait: 60s
+ *     read_limit: 65536
+ *     allowed_origins: ["https://app.example.com"]
+ *
+ * 使用示例:
+ *   app.Get("/ws", ws.Handler(hub, ws.Handlers{
+ *       Authenticate: func(c fiber.Ctx) (any, error) { return verifyToken(c.Query("token")) },
+ *       OnConnect:    func(conn *ws.Conn) error { hub.Join(conn, "room:"+conn.Query("room")); return nil },
+ *       OnMessage:    func(conn *ws.Conn, msg ws.Message) { hub.BroadcastRoom("room:1", msg) },
+ *   }))
+ * ======================================================================== */
+
+// 消息类型（与 RFC 6455 一致）
+const (
+	TextMessage   = websocket.TextMessage
+	BinaryMessage = websocket.BinaryMessage
+)
+
+var (
+	// ErrConnClosed 连接已关闭
+	ErrConnClosed = errors.New("websocket connection is closed")
+	// ErrSendBufferFull 发送队列已满（慢连接）
+	ErrSendBufferFull = errors.New("websocket send buffer is full")
+)
+
+// Config WebSocket 配置
+type Config struct {
+	PingInterval   time.Duration `yaml:"ping_interval"`   // Ping 间隔，默认 30s
+	PongWait       time.Duration `yaml:"pong_wait"`       // 等待 Pong 超时，默认 60s（须大于 PingInterval）
+	WriteWait      time.Duration `yaml:"write_wait"`      // 单次写超时，默认 10s
+	ReadLimit      int64         `yaml:"read_limit"`      // 单条消息最大字节数，默认 64KB
+	SendBuffer     int           `yaml:"send_buffer"`     // 每连接发送队列长度，默认 64
+	AllowedOrigins []string      `yaml:"allowed_origins"` // 允许的 Origin，为空时仅允许同源
+	Subprotocols   []string      `yaml:"subprotocols"`    // 支持的子协议
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		PingInterval: 30 * time.Second,
+		PongWait:     60 * time.Second,
+		WriteWait:    10 * time.Second,
+		ReadLimit:    64 * 1024,
+		SendBuffer:   64,
+	}
+}
+
+// withDefaults 填充未设置的配置项
+func (c *Config) withDefaults() *Config {
+	def := DefaultConfig()
+	if c == nil {
+		return def
+	}
+	cfg := *c
+	if cfg.PingInterval <= 0 {
+		cfg.PingInterval = def.PingInterval
+	}
+	if cfg.PongWait <= cfg.PingInterval {
+		cfg.PongWait = 2 * cfg.PingInterval
+	}
+	if cfg.WriteWait <= 0 {
+		cfg.WriteWait = def.WriteWait
+	}
+	if cfg.ReadLimit <= 0 {
+		cfg.ReadLimit = def.ReadLimit
+	}
+	if cfg.SendBuffer <= 0 {
+		cfg.SendBuffer = def.SendBuffer
+	}
+	return &cfg
+}
+
+// Message 消息
+type Message struct {
+	Type int
+	Data []byte
+}
+
+// Text 创建文本消息
+func Text(s string) Message {
+	return Message{Type: TextMessage, Data: []byte(s)}
+}
+
+// Handlers 连接回调
+type Handlers struct {
+	// Authenticate 升级前鉴权，返回的 principal 保存在 Conn.Principal；返回错误时拒绝升级
+	Authenticate func(c fiber.Ctx) (any, error)
+	// OnConnect 连接建立后回调，返回错误时关闭连接
+	OnConnect func(conn *Conn) error
+	// OnMessage 收到消息（同一连接内串行调用）
+	OnMessage func(conn *Conn, msg Message)
+	// OnClose 连接关闭后回调，err 为断开原因（正常关闭为 nil）
+	OnClose func(conn *Conn, err error)
+}
+
+// Conn WebSocket 连接
+type Conn struct {
+	id        string
+	principal any
+	ip        string
+	query     url.Values
+	locals    sync.Map
+
+	ws     *websocket.Conn
+	hub    *Hub
+	send   chan Message
+	closed chan struct{}
+	once   sync.Once
+
+	errMu sync.Mutex
+	err   error
+}
+
+// ID 连接 ID（ULID）
+func (c *Conn) ID() string { return c.id }
+
+// Principal 鉴权主体
+func (c *Conn) Principal() any { return c.principal }
+
+// IP 客户端 IP
+func (c *Conn) IP() string { return c.ip }
+
+// Query 升级请求的查询参数
+func (c *Conn) Query(key string) string { return c.query.Get(key) }
+
+// Set 设置连接级数据
+func (c *Conn) Set(key string, value any) { c.locals.Store(key, value) }
+
+// Get 获取连接级数据
+func (c *Conn) Get(key string) (any, bool) { return c.locals.Load(key) }
+
+// Send 异步发送消息，队列满时断开该连接并返回 ErrSendBufferFull
+func (c *Conn) Send(msg Message) error {
+	select {
+	case <-c.closed:
+		return ErrConnClosed
+	default:
+	}
+	select {
+	case c.send <- msg:
+		return nil
+	case <-c.closed:
+		return ErrConnClosed
+	default:
+		c.closeWith(ErrSendBufferFull, websocket.ClosePolicyViolation, "send buffer full")
+		return ErrSendBufferFull
+	}
+}
+
+// SendText 发送文本消息
+func (c *Conn) SendText(s string) error {
+	return c.Send(Text(s))
+}
+
+// Close 正常关闭连接
+func (c *Conn) Close() {
+	c.closeWith(nil, websocket.CloseNormalClosure, "")
+}
+
+// closeWith 发送关闭帧并关闭连接（仅首次生效）
+func (c *Conn) closeWith(err error, code int, reason string) {
+	c.once.Do(func() {
+		c.setErr(err)
+		deadline := time.Now().Add(c.hub.cfg.WriteWait)
+		_ = c.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
+		close(c.closed)
+		// fasthttp 劫持连接的 Close 为空操作，通过读超时唤醒读循环
+		_ = c.ws.SetReadDeadline(time.Now())
+		_ = c.ws.Close()
+	})
+}
+
+// setErr 记录首个断开原因
+func (c *Conn) setErr(err error) {
+	if err == nil {
+		return
+	}
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// cause 断开原因
+func (c *Conn) cause() error {
+	c.errMu.Lock()
+	defer c.errMu.Unlock()
+	return c.err
+}
+
+// Handler 创建 WebSocket 路由处理器，非升级请求返回 426
+func Handler(hub *Hub, h Handlers) fiber.Handler {
+	cfg := hub.cfg
+	upgrader := websocket.FastHTTPUpgrader{
+		Subprotocols: cfg.Subprotocols,
+		CheckOrigin: func(ctx *fasthttp.RequestCtx) bool {
+			return checkOrigin(ctx, cfg.AllowedOrigins)
+		},
+	}
+
+	return func(c fiber.Ctx) error {
+		if !websocket.FastHTTPIsWebSocketUpgrade(c.RequestCtx()) {
+			return fiber.ErrUpgradeRequired
+		}
+		if hub.isClosed() {
+			return fiber.ErrServiceUnavailable
+		}
+
+		var principal any
+		if h.Authenticate != nil {
+			p, err := h.Authenticate(c)
+			if err != nil {
+				return err
+			}
+			principal = p
+		}
+
+		// 升级后 fiber.Ctx 会被回收，需提前拷贝请求数据
+		query, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+		conn := &Conn{
+			id:        ulid.GenerateString(),
+			principal: principal,
+			ip:        strings.Clone(c.IP()),
+			query:     query,
+			hub:       hub,
+			send:      make(chan Message, cfg.SendBuffer),
+			closed:    make(chan struct{}),
+		}
+
+		return upgrader.Upgrade(c.RequestCtx(), func(wsConn *websocket.Conn) {
+			conn.ws = wsConn
+			hub.serve(conn, h)
+		})
+	}
+}
+
+// checkOrigin 未配置 AllowedOrigins 时仅允许同源（或无 Origin 的非浏览器客户端）
+func checkOrigin(ctx *fasthttp.RequestCtx, allowed []string) bool {
+	origin := string(ctx.Request.Header.Peek(fiber.HeaderOrigin))
+	if origin == "" {
+		return true
+	}
+	if len(allowed) > 0 {
+		return slices.Contains(allowed, "*") || slices.Contains(allowed, origin)
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, string(ctx.Host()))
+}
+
+// readPump 读循环，阻塞直到连接关闭
+func (h *Hub) readPump(conn *Conn, handlers Handlers) {
+	cfg := h.cfg
+	conn.ws.SetReadLimit(cfg.ReadLimit)
+	_ = conn.ws.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	conn.ws.SetPongHandler(func(string) error {
+		return conn.ws.SetReadDeadline(time.Now().Add(cfg.PongWait))
+	})
+
+	for {
+		msgType, data, err := conn.ws.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseNoStatusReceived) {
+				select {
+				case <-conn.closed:
+				default:
+					conn.setErr(err)
+				}
+			}
+			return
+		}
+		_ = conn.ws.SetReadDeadline(time.Now().Add(cfg.PongWait))
+		if handlers.OnMessage != nil {
+			h.safeCall(conn, func() { handlers.OnMessage(conn, Message{Type: msgType, Data: data}) })
+		}
+	}
+}
+
+// writePump 写循环与心跳
+func (h *Hub) writePump(conn *Conn) {
+	cfg := h.cfg
+	ticker := time.NewTicker(cfg.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-conn.closed:
+			return
+		case msg := <-conn.send:
+			_ = conn.ws.SetWriteDeadline(time.Now().Add(cfg.WriteWait))
+			if err := conn.ws.WriteMessage(msg.Type, msg.Data); err != nil {
+				conn.closeWith(err, websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			if err := conn.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(cfg.WriteWait)); err != nil {
+				conn.closeWith(err, websocket.CloseAbnormalClosure, "")
+				return
+			}
+		}
+	}
+}
+
+// safeCall 调用业务回调，panic 时记录日志并断开连接
+func (h *Hub) safeCall(conn *Conn, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			h.log.Error("websocket handler panic recovered", zap.String("conn_id", conn.id), zap.Any("panic", r))
+			conn.closeWith(errors.New("handler panic"), websocket.CloseInternalServerErr, "internal error")
+		}
+	}()
+	fn()
+}
+
+// serve 管理单个连接生命周期（在升级后的协程中阻塞运行）
+func (h *Hub) serve(conn *Conn, handlers Handlers) {
+	h.add(conn)
+	defer h.remove(conn)
+
+	if handlers.OnConnect != nil {
+		var err error
+		h.safeCall(conn, func() { err = handlers.OnConnect(conn) })
+		if err != nil {
+			conn.closeWith(err, websocket.ClosePolicyViolation, err.Error())
+			h.finish(conn, handlers)
+			return
+		}
+	}
+
+	go h.writePump(conn)
+	h.readPump(conn, handlers)
+	conn.closeWith(nil, websocket.CloseNormalClosure, "")
+	h.finish(conn, handlers)
+}
+
+// finish 触发 OnClose 回调
+func (h *Hub) finish(conn *Conn, handlers Handlers) {
+	if handlers.OnClose != nil {
+		h.safeCall(conn, func() { handlers.OnClose(conn, conn.cause()) })
+	}
+}
+
+// newLogger 默认 logger
+func newLogger(log *logger.Logger) *logger.Logger {
+	if log == nil {
+		return logger.NewNop()
+	}
+	return log
+}
+
+// closeAll 以 code 关闭全部连接并等待断开
+func (h *Hub) closeAll(ctx context.Context, code int, reason string) error {
+	for _, conn := range h.Conns() {
+		conn.closeWith(nil, code, reason)
+	}
+	select {
+	case <-h.drained():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,185 @@
+package ws
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/gofiber/fiber/v3"
+)
+
+func startWSServer(t *testing.T, hub *Hub, h Handlers) string {
+	t.Helper()
+	app := fiber.New()
+	app.Get("/ws", Handler(hub, h))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	go func() {
+		_ = app.Listener(ln, fiber.ListenConfig{DisableStartupMessage: true})
+	}()
+	t.Cleanup(func() { _ = app.Shutdown() })
+	return "ws://" + ln.Addr().String() + "/ws"
+}
+
+func dial(t *testing.T, url string, header http.Header) *websocket.Conn {
+	t.Helper()
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		t.Fatalf("dial %s: %v (status %d)", url, err, status)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func readText(t *testing.T, conn *websocket.Conn) string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(data)
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHubRoomsAndAuth(t *testing.T) {
+	hub := NewHub(&Config{PingInterval: 50 * time.Millisecond}, nil)
+	closed := make(chan error, 4)
+
+	url := startWSServer(t, hub, Handlers{
+		Authenticate: func(c fiber.Ctx) (any, error) {
+			if c.Get("Authorization") != "Bearer good" {
+				return nil, fiber.ErrUnauthorized
+			}
+			return "user-" + c.Query("user"), nil
+		},
+		OnConnect: func(conn *Conn) error {
+			hub.Join(conn, conn.Query("room"))
+			return nil
+		},
+		OnMessage: func(conn *Conn, msg Message) {
+			hub.BroadcastRoom(conn.Query("room"), Text(conn.Principal().(string)+":"+string(msg.Data)))
+		},
+		OnClose: func(conn *Conn, err error) { closed <- err },
+	})
+
+	if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %v", err)
+	}
+
+	auth := http.Header{"Authorization": {"Bearer good"}}
+	a := dial(t, url+"?room=r1&user=a", auth)
+	b := dial(t, url+"?room=r1&user=b", auth)
+	c := dial(t, url+"?room=r2&user=c", auth)
+	waitFor(t, func() bool { return hub.Len() == 3 && hub.RoomSize("r1") == 2 })
+
+	if err := a.WriteMessage(websocket.TextMessage, []byte("hi")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := readText(t, a); got != "user-a:hi" {
+		t.Fatalf("unexpected echo %q", got)
+	}
+	if got := readText(t, b); got != "user-a:hi" {
+		t.Fatalf("unexpected room message %q", got)
+	}
+
+	if n := hub.Broadcast(Text("all")); n != 3 {
+		t.Fatalf("expected broadcast to 3 conns, got %d", n)
+	}
+	if got := readText(t, c); got != "all" {
+		t.Fatalf("unexpected broadcast %q", got)
+	}
+
+	_ = b.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	if err := <-closed; err != nil {
+		t.Fatalf("expected clean close, got %v", err)
+	}
+	waitFor(t, func() bool { return hub.RoomSize("r1") == 1 })
+}
+
+func TestHeartbeat(t *testing.T) {
+	hub := NewHub(&Config{PingInterval: 20 * time.Millisecond, PongWait: 60 * time.Millisecond}, nil)
+	closed := make(chan error, 2)
+	url := startWSServer(t, hub, Handlers{OnClose: func(conn *Conn, err error) { closed <- err }})
+
+	// 持续读取的客户端自动回复 Pong，连接保持
+	alive := dial(t, url, nil)
+	go func() {
+		for {
+			if _, _, err := alive.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	// 不读取的客户端无法回复 Pong，超时后被断开
+	dial(t, url, nil)
+
+	select {
+	case err := <-closed:
+		if err == nil {
+			t.Fatalf("expected pong timeout error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("silent connection was not dropped")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if hub.Len() != 1 {
+		t.Fatalf("expected responsive connection to survive heartbeats, got %d", hub.Len())
+	}
+}
+
+func TestHubShutdown(t *testing.T) {
+	hub := NewHub(nil, nil)
+	url := startWSServer(t, hub, Handlers{})
+
+	conn := dial(t, url, nil)
+	waitFor(t, func() bool { return hub.Len() == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := hub.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+		t.Fatalf("expected going away close frame, got %v", err)
+	}
+
+	if _, resp, err := websocket.DefaultDialer.Dial(url, nil); err == nil || resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 after shutdown, got %v", err)
+	}
+}
+
+func TestCheckOrigin(t *testing.T) {
+	hub := NewHub(&Config{AllowedOrigins: []string{"https://app.example.com"}}, nil)
+	url := startWSServer(t, hub, Handlers{})
+
+	if _, _, err := websocket.DefaultDialer.Dial(url, http.Header{"Origin": {"https://evil.example.com"}}); err == nil {
+		t.Fatalf("expected origin to be rejected")
+	}
+	dial(t, url, http.Header{"Origin": {"https://app.example.com"}})
+}
@@ -0,0 +1,76 @@
+package upgrade
+
+import (
+	"context"
+	"os"
+	"os/signal"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Upgrade Module
+ * ========================================================================
+ * 职责: 按 Config 提供 *Upgrader，并在应用运行期间监听 SIGUSR2 触发升级
+ * 未启用时提供 nil *Upgrader，依赖方按未启用处理
+ * ======================================================================== */
+
+// Params 依赖注入参数
+type Params struct {
+	fx.In
+	Lc     fx.Lifecycle
+	Config Config         `optional:"true"`
+	Logger *logger.Logger `optional:"true"`
+}
+
+// NewFromParams 创建 Upgrader 并注册信号监听，未启用时返回 nil
+func NewFromParams(p Params) (*Upgrader, error) {
+	if !p.Config.Enabled {
+		return nil, nil
+	}
+	u, err := New(p.Config, p.Logger)
+	if err != nil {
+		return nil, err
+	}
+	if upgradeSignal == nil {
+		u.log.Warn("Signal-triggered upgrade is not supported on this platform")
+		return u, nil
+	}
+
+	sigs := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	p.Lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			signal.Notify(sigs, upgradeSignal)
+			go func() {
+				for {
+					select {
+					case <-sigs:
+						u.log.Info("Received upgrade signal")
+						if err := u.Upgrade(); err != nil {
+							u.log.Error("Upgrade failed", zap.Error(err))
+						}
+					case <-stop:
+						return
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			signal.Stop(sigs)
+			close(stop)
+			return nil
+		},
+	})
+	return u, nil
+}
+
+// Module 平滑升级模块
+// 提供: *Upgrader（未启用时为 nil）
+var Module = fx.Module("upgrade",
+	fx.Provide(NewFromParams),
+)
@@ -0,0 +1,61 @@
+package upgrade
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"github.com/aisgo/ais-go-pkg/shutdown"
+)
+
+/* ========================================================================
+ * Upgrade Shutdown - 接入优雅关停
+ * ========================================================================
+ * 职责: 服务启动后通知父进程就绪；监听器交接完成后通过 fx.Shutdowner
+ *       停止应用，旧进程按常规流程排空并退出
+ * 说明: 需在 HTTP / gRPC 服务器模块之后引入，保证 Ready 时监听器均已就绪
+ * ======================================================================== */
+
+// ShutdownParams 关停集成依赖参数
+type ShutdownParams struct {
+	fx.In
+
+	Lc         fx.Lifecycle
+	Manager    *shutdown.Manager
+	Upgrader   *Upgrader `optional:"true"`
+	Shutdowner fx.Shutdowner
+}
+
+// RegisterShutdown 关联 Upgrader 与 Manager，未启用时不做任何处理
+func RegisterShutdown(p ShutdownParams) {
+	if !p.Upgrader.Enabled() {
+		return
+	}
+	p.Manager.UseUpgradeSignal(p.Upgrader.Exited())
+
+	stop := make(chan struct{})
+	p.Lc.Append(fx.Hook{
+		// 依赖的服务器先于本钩子启动，此时监听器均已就绪
+		OnStart: func(context.Context) error {
+			go func() {
+				select {
+				case <-p.Upgrader.Exited():
+					_ = p.Shutdowner.Shutdown()
+				case <-stop:
+				}
+			}()
+			return p.Upgrader.Ready()
+		},
+		OnStop: func(context.Context) error {
+			close(stop)
+			return nil
+		},
+	})
+	p.Manager.ShutdownOnStop(p.Lc)
+}
+
+// ShutdownModule 平滑升级关停集成模块
+// 需与 Module 及 shutdown.Module 一起使用
+var ShutdownModule = fx.Module("upgrade-shutdown",
+	fx.Invoke(RegisterShutdown),
+)
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package upgrade
+
+import "os"
+
+// upgradeSignal 非 Unix 平台不支持信号触发升级
+var upgradeSignal os.Signal
@@ -0,0 +1,11 @@
+//go:build unix
+
+package upgrade
+
+import (
+	"os"
+	"syscall"
+)
+
+// upgradeSignal 触发平滑升级的信号
+var upgradeSignal os.Signal = syscall.SIGUSR2
@@ -0,0 +1,364 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Upgrade - 监听器 FD 交接的零停机重启
+ * ========================================================================
+ * 职责: 裸机/VM 部署时通过 SIGUSR2 平滑升级，新旧进程交接期间不丢连接
+ * 流程:
+ *   1. 旧进程收到 SIGUSR2，以相同参数启动新二进制，通过 ExtraFiles 传递全部已登记的监听器
+ *   2. 新进程按名称继承监听器（ListenWith），服务启动完成后调用 Ready 通知旧进程
+ *   3. 旧进程收到就绪通知后关闭 Exited 通道，由 shutdown.Manager 排空并退出；
+ *      新进程启动失败或超时未就绪时终止新进程，旧进程继续服务
+ * 接入:
+ *   - transport/http（含管理端口）与 transport/grpc 的监听器自动登记（名称 http / http-admin / grpc）
+ *   - ShutdownModule 在服务启动后调用 Ready，并在交接完成后触发应用关停
+ * 注意: 不支持 Fiber Prefork；Unix Socket 交接后旧进程关闭时不删除 socket 文件
+ * 配置示例:
+ *   upgrade:
+ *     enabled: true
+ *     ready_timeout: 1m
+ *   # 部署: 替换二进制后执行 kill -USR2 <pid>
+ * ======================================================================== */
+
+const (
+	// envFDs 继承的监听器，格式 name=fd,name=fd
+	envFDs = "AIS_UPGRADE_FDS"
+	// envReadyFD 就绪通知管道的写端
+	envReadyFD = "AIS_UPGRADE_READY_FD"
+
+	// defaultReadyTimeout 默认等待新进程就绪的时间
+	defaultReadyTimeout = time.Minute
+)
+
+var (
+	// ErrUpgradeInProgress 已有升级在进行
+	ErrUpgradeInProgress = errors.New("upgrade: upgrade already in progress")
+	// ErrUpgraded 已完成交接，不可再次升级
+	ErrUpgraded = errors.New("upgrade: process already handed over")
+	// ErrChildNotReady 新进程在就绪前退出或超时
+	ErrChildNotReady = errors.New("upgrade: child process not ready")
+)
+
+// Config 平滑升级配置
+type Config struct {
+	Enabled      bool          `yaml:"enabled"`
+	ReadyTimeout time.Duration `yaml:"ready_timeout"` // 等待新进程就绪的时间，默认 1m
+}
+
+// filer 可导出文件描述符的监听器（*net.TCPListener / *net.UnixListener）
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Upgrader 监听器登记与交接
+// nil *Upgrader 可安全使用：ListenWith 直接创建监听器，其余方法为空操作
+type Upgrader struct {
+	cfg Config
+	log *logger.Logger
+
+	mu        sync.Mutex
+	inherited map[string]net.Listener
+	active    map[string]net.Listener
+	readyFD   int
+	upgrading bool
+	exited    chan struct{}
+	readyOnce sync.Once
+}
+
+// New 创建 Upgrader，并解析父进程传入的监听器
+func New(cfg Config, log *logger.Logger) (*Upgrader, error) {
+	if log == nil {
+		log = logger.NewNop()
+	}
+	if cfg.ReadyTimeout <= 0 {
+		cfg.ReadyTimeout = defaultReadyTimeout
+	}
+	u := &Upgrader{
+		cfg:       cfg,
+		log:       log,
+		inherited: make(map[string]net.Listener),
+		active:    make(map[string]net.Listener),
+		readyFD:   -1,
+		exited:    make(chan struct{}),
+	}
+	if err := u.inherit(os.Getenv); err != nil {
+		return nil, err
+	}
+	// 避免继续传递给后续子进程
+	_ = os.Unsetenv(envFDs)
+	_ = os.Unsetenv(envReadyFD)
+	return u, nil
+}
+
+// inherit 从环境变量恢复父进程传入的监听器与就绪管道
+func (u *Upgrader) inherit(getenv func(string) string) error {
+	if v := getenv(envFDs); v != "" {
+		for _, pair := range strings.Split(v, ",") {
+			name, fdStr, ok := strings.Cut(pair, "=")
+			fd, err := strconv.Atoi(fdStr)
+			if !ok || name == "" || err != nil {
+				return fmt.Errorf("upgrade: invalid %s entry %q", envFDs, pair)
+			}
+			f := os.NewFile(uintptr(fd), name)
+			ln, err := net.FileListener(f)
+			f.Close()
+			if err != nil {
+				return fmt.Errorf("upgrade: inherit listener %s: %w", name, err)
+			}
+			u.inherited[name] = ln
+		}
+	}
+	if v := getenv(envReadyFD); v != "" {
+		fd, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("upgrade: invalid %s %q", envReadyFD, v)
+		}
+		u.readyFD = fd
+	}
+	if len(u.inherited) > 0 {
+		u.log.Info("Inherited listeners from parent process", zap.Strings("names", u.names(u.inherited)))
+	}
+	return nil
+}
+
+// Enabled 是否启用平滑升级
+func (u *Upgrader) Enabled() bool {
+	return u != nil && u.cfg.Enabled
+}
+
+// IsChild 当前进程是否由升级启动
+func (u *Upgrader) IsChild() bool {
+	return u != nil && u.readyFD >= 0
+}
+
+// ListenWith 按名称返回监听器：优先使用父进程传入的同名监听器，否则调用 create 创建
+// 返回的监听器会被登记，升级时传递给新进程；create 返回的监听器须支持 File()（TCP / Unix）
+func (u *Upgrader) ListenWith(name string, create func() (net.Listener, error)) (net.Listener, error) {
+	if u == nil {
+		return create()
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, dup := u.active[name]; dup {
+		return nil, fmt.Errorf("upgrade: listener %q already registered", name)
+	}
+	ln, ok := u.inherited[name]
+	if ok {
+		delete(u.inherited, name)
+	} else {
+		var err error
+		if ln, err = create(); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := ln.(filer); !ok {
+		u.log.Warn("Listener cannot be handed over", zap.String("name", name))
+	}
+	u.active[name] = ln
+	return ln, nil
+}
+
+// Listen 按名称在 network/addr 上监听，语义同 ListenWith
+func (u *Upgrader) Listen(name, network, addr string) (net.Listener, error) {
+	return u.ListenWith(name, func() (net.Listener, error) {
+		return net.Listen(network, addr)
+	})
+}
+
+// Ready 通知父进程本进程已就绪，并关闭未被认领的继承监听器；非升级启动时为空操作
+func (u *Upgrader) Ready() error {
+	if u == nil {
+		return nil
+	}
+	var err error
+	u.readyOnce.Do(func() {
+		u.mu.Lock()
+		for name, ln := range u.inherited {
+			u.log.Warn("Closing unclaimed inherited listener", zap.String("name", name))
+			ln.Close()
+			delete(u.inherited, name)
+		}
+		u.mu.Unlock()
+
+		if u.readyFD < 0 {
+			return
+		}
+		f := os.NewFile(uintptr(u.readyFD), "upgrade-ready")
+		defer f.Close()
+		if _, err = f.Write([]byte{1}); err != nil {
+			err = fmt.Errorf("upgrade: notify parent: %w", err)
+			return
+		}
+		u.log.Info("Notified parent process of readiness")
+	})
+	return err
+}
+
+// Exited 交接完成后关闭，旧进程应随后排空并退出
+func (u *Upgrader) Exited() <-chan struct{} {
+	if u == nil {
+		return nil
+	}
+	return u.exited
+}
+
+// Upgrade 启动新进程并交接监听器，阻塞至新进程就绪、退出或超时
+// 成功后关闭 Exited；失败时终止新进程，本进程继续服务
+func (u *Upgrader) Upgrade() error {
+	if u == nil {
+		return errors.New("upgrade: upgrader not configured")
+	}
+
+	u.mu.Lock()
+	select {
+	case <-u.exited:
+		u.mu.Unlock()
+		return ErrUpgraded
+	default:
+	}
+	if u.upgrading {
+		u.mu.Unlock()
+		return ErrUpgradeInProgress
+	}
+	u.upgrading = true
+	files, entries, err := u.listenerFiles()
+	u.mu.Unlock()
+	defer func() {
+		u.mu.Lock()
+		u.upgrading = false
+		u.mu.Unlock()
+		for _, f := range files {
+			f.Close()
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	readR, readW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("upgrade: create ready pipe: %w", err)
+	}
+	defer readR.Close()
+
+	executable, err := os.Executable()
+	if err != nil {
+		readW.Close()
+		return fmt.Errorf("upgrade: resolve executable: %w", err)
+	}
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	// ExtraFiles 第 i 个文件在子进程中的描述符为 3+i
+	cmd.ExtraFiles = append(slices.Clone(files), readW)
+	cmd.Env = append(os.Environ(),
+		envFDs+"="+strings.Join(entries, ","),
+		envReadyFD+"="+strconv.Itoa(3+len(files)),
+	)
+
+	u.log.Info("Starting upgraded process", zap.String("executable", executable), zap.Strings("listeners", entries))
+	err = cmd.Start()
+	readW.Close()
+	if err != nil {
+		return fmt.Errorf("upgrade: start child: %w", err)
+	}
+
+	exited := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(exited)
+	}()
+
+	if err := waitReady(readR, u.cfg.ReadyTimeout); err != nil {
+		u.log.Error("Upgraded process not ready, keep serving", zap.Int("pid", cmd.Process.Pid), zap.Error(err))
+		_ = cmd.Process.Kill()
+		<-exited
+		return err
+	}
+
+	u.log.Info("Upgraded process ready, handing over", zap.Int("pid", cmd.Process.Pid))
+	u.mu.Lock()
+	// 交接后本进程关闭 Unix 监听器时保留 socket 文件
+	for _, ln := range u.active {
+		if ul, ok := ln.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+	}
+	close(u.exited)
+	u.mu.Unlock()
+	return nil
+}
+
+// listenerFiles 复制全部可交接监听器的文件描述符，返回文件与 name=fd 条目（按名称排序）
+func (u *Upgrader) listenerFiles() ([]*os.File, []string, error) {
+	var files []*os.File
+	var entries []string
+	for _, name := range u.names(u.active) {
+		fl, ok := u.active[name].(filer)
+		if !ok {
+			continue
+		}
+		f, err := fl.File()
+		if err != nil {
+			for _, opened := range files {
+				opened.Close()
+			}
+			return nil, nil, fmt.Errorf("upgrade: dup listener %s: %w", name, err)
+		}
+		entries = append(entries, name+"="+strconv.Itoa(3+len(files)))
+		files = append(files, f)
+	}
+	return files, entries, nil
+}
+
+// names 返回排序后的监听器名称
+func (u *Upgrader) names(m map[string]net.Listener) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// waitReady 等待子进程写入就绪字节；子进程退出（EOF）或超时返回 ErrChildNotReady
+func waitReady(r *os.File, timeout time.Duration) error {
+	result := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		n, err := r.Read(buf)
+		if n == 1 {
+			result <- nil
+			return
+		}
+		result <- fmt.Errorf("%w: %v", ErrChildNotReady, err)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+		// 关闭读端以结束读取 goroutine
+		r.Close()
+		return fmt.Errorf("%w: timed out after %s", ErrChildNotReady, timeout)
+	}
+}
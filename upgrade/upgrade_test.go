@@ -0,0 +1,162 @@
+package upgrade
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/logger"
+)
+
+const envTestChild = "UPGRADE_TEST_CHILD"
+
+// TestMain 作为升级后的子进程运行时：继承 http 监听器，通知就绪并响应一个连接
+func TestMain(m *testing.M) {
+	if os.Getenv(envTestChild) == "1" {
+		os.Exit(runChild())
+	}
+	os.Exit(m.Run())
+}
+
+func runChild() int {
+	u, err := New(Config{Enabled: true}, nil)
+	if err != nil || !u.IsChild() {
+		return 2
+	}
+	ln, err := u.Listen("http", "tcp", "127.0.0.1:0")
+	if err != nil {
+		return 3
+	}
+	if err := u.Ready(); err != nil {
+		return 4
+	}
+	_ = ln.(*net.TCPListener).SetDeadline(time.Now().Add(10 * time.Second))
+	conn, err := ln.Accept()
+	if err != nil {
+		return 5
+	}
+	_, _ = conn.Write([]byte("child"))
+	conn.Close()
+	return 0
+}
+
+func TestNilUpgrader(t *testing.T) {
+	var u *Upgrader
+	if u.Enabled() || u.IsChild() || u.Ready() != nil || u.Exited() != nil {
+		t.Fatal("nil upgrader should be a no-op")
+	}
+	ln, err := u.Listen("http", "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	ln.Close()
+	if err := u.Upgrade(); err == nil {
+		t.Fatal("expected error upgrading nil upgrader")
+	}
+}
+
+func TestInheritAndReady(t *testing.T) {
+	orig, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer orig.Close()
+	f, err := orig.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatalf("file: %v", err)
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	defer r.Close()
+
+	u := &Upgrader{log: logger.NewNop(), inherited: map[string]net.Listener{}, active: map[string]net.Listener{}, readyFD: -1, exited: make(chan struct{})}
+	env := map[string]string{
+		envFDs:     "http=" + strconv.Itoa(int(f.Fd())),
+		envReadyFD: strconv.Itoa(int(w.Fd())),
+	}
+	if err := u.inherit(func(k string) string { return env[k] }); err != nil {
+		t.Fatalf("inherit: %v", err)
+	}
+	f.Close()
+
+	ln, err := u.ListenWith("http", func() (net.Listener, error) {
+		t.Fatal("inherited listener should be reused")
+		return nil, nil
+	})
+	if err != nil || ln.Addr().String() != orig.Addr().String() {
+		t.Fatalf("unexpected inherited listener: %v %v", ln, err)
+	}
+	defer ln.Close()
+	if _, err := u.Listen("http", "tcp", "127.0.0.1:0"); err == nil {
+		t.Fatal("expected duplicate name error")
+	}
+
+	if err := u.Ready(); err != nil {
+		t.Fatalf("ready: %v", err)
+	}
+	if err := waitReady(r, time.Second); err != nil {
+		t.Fatalf("wait ready: %v", err)
+	}
+
+	if err := u.inherit(func(k string) string { return map[string]string{envFDs: "bad"}[k] }); err == nil {
+		t.Fatal("expected invalid entry error")
+	}
+}
+
+func TestWaitReadyFailures(t *testing.T) {
+	r, w, _ := os.Pipe()
+	w.Close()
+	if err := waitReady(r, time.Second); !errors.Is(err, ErrChildNotReady) {
+		t.Fatalf("expected not ready on EOF, got %v", err)
+	}
+
+	r, w, _ = os.Pipe()
+	defer w.Close()
+	if err := waitReady(r, 20*time.Millisecond); !errors.Is(err, ErrChildNotReady) {
+		t.Fatalf("expected not ready on timeout, got %v", err)
+	}
+}
+
+func TestUpgradeHandsOverListener(t *testing.T) {
+	t.Setenv(envTestChild, "1")
+	u, err := New(Config{Enabled: true, ReadyTimeout: 10 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	ln, err := u.Listen("http", "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+
+	if err := u.Upgrade(); err != nil {
+		t.Fatalf("upgrade: %v", err)
+	}
+	select {
+	case <-u.Exited():
+	default:
+		t.Fatal("expected Exited to be closed after handover")
+	}
+	if err := u.Upgrade(); !errors.Is(err, ErrUpgraded) {
+		t.Fatalf("expected ErrUpgraded, got %v", err)
+	}
+
+	// 旧进程停止接收后，连接由子进程处理
+	ln.Close()
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	body, _ := io.ReadAll(conn)
+	if string(body) != "child" {
+		t.Fatalf("expected response from child, got %q", body)
+	}
+}
@@ -0,0 +1,241 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+/* ========================================================================
+ * Field Encryption - AES-256-GCM 字段加密
+ * ========================================================================
+ * 职责: 基于带版本号的密钥环进行 AES-256-GCM 加解密，支持密钥轮换
+ * 密文格式: <version>$<base64url(nonce || ciphertext || tag)>
+ * 轮换流程:
+ *   1. 新增密钥版本并设为 primary，旧版本保留用于解密
+ *   2. 后台任务对 NeedsRotation 的密文调用 Rotate 重新加密
+ *   3. 全部迁移后移除旧版本
+ * 配置示例:
+ *   crypto:
+ *     primary: "2"
+ *     keys:
+ *       "1": ${FIELD_KEY_V1}   # base64 编码的 32 字节密钥
+ *       "2": ${FIELD_KEY_V2}
+ *
+ * 使用示例:
+ *   kr, err := crypto.NewKeyringFromConfig(cfg)
+ *   ct, err := kr.EncryptString("13800138000")
+ *   pt, err := kr.DecryptString(ct)
+ * ======================================================================== */
+
+// KeySize AES-256 密钥长度
+const KeySize = 32
+
+// versionSeparator 版本号与密文的分隔符
+const versionSeparator = "$"
+
+var (
+	// ErrInvalidCiphertext 密文格式错误或认证失败
+	ErrInvalidCiphertext = errors.New("crypto: invalid ciphertext")
+	// ErrUnknownKeyVersion 密钥版本不存在
+	ErrUnknownKeyVersion = errors.New("crypto: unknown key version")
+)
+
+// Config 密钥环配置
+type Config struct {
+	Primary string            `yaml:"primary"` // 加密使用的密钥版本
+	Keys    map[string]string `yaml:"keys"`    // 版本 -> base64 编码的 32 字节密钥
+}
+
+// Keyring 带版本的 AES-256-GCM 密钥环（并发安全）
+type Keyring struct {
+	mu      sync.RWMutex
+	primary string
+	aeads   map[string]cipher.AEAD
+}
+
+// NewKeyring 创建密钥环，keys 为 版本 -> 32 字节原始密钥
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	kr := &Keyring{aeads: make(map[string]cipher.AEAD, len(keys))}
+	for version, key := range keys {
+		if err := kr.AddKey(version, key); err != nil {
+			return nil, err
+		}
+	}
+	if err := kr.SetPrimary(primary); err != nil {
+		return nil, err
+	}
+	return kr, nil
+}
+
+// NewKeyringFromConfig 从配置创建密钥环
+func NewKeyringFromConfig(cfg Config) (*Keyring, error) {
+	keys := make(map[string][]byte, len(cfg.Keys))
+	for version, encoded := range cfg.Keys {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("crypto: decode key %q: %w", version, err)
+		}
+		keys[version] = key
+	}
+	return NewKeyring(cfg.Primary, keys)
+}
+
+// GenerateKey 生成随机 32 字节密钥（base64 编码，便于写入配置）
+func GenerateKey() (string, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// AddKey 添加密钥版本
+func (k *Keyring) AddKey(version string, key []byte) error {
+	if version == "" || strings.Contains(version, versionSeparator) {
+		return fmt.Errorf("crypto: invalid key version %q", version)
+	}
+	if len(key) != KeySize {
+		return fmt.Errorf("crypto: key %q must be %d bytes, got %d", version, KeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.aeads[version] = aead
+	return nil
+}
+
+// SetPrimary 切换加密使用的密钥版本
+func (k *Keyring) SetPrimary(version string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.aeads[version]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownKeyVersion, version)
+	}
+	k.primary = version
+	return nil
+}
+
+// Primary 当前加密密钥版本
+func (k *Keyring) Primary() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// Versions 已加载的密钥版本（升序）
+func (k *Keyring) Versions() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	versions := make([]string, 0, len(k.aeads))
+	for v := range k.aeads {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Encrypt 使用 primary 密钥加密，aad 为附加认证数据（可为 nil，解密时需一致）
+func (k *Keyring) Encrypt(plaintext, aad []byte) (string, error) {
+	version, sealed, err := k.Seal(plaintext, aad)
+	if err != nil {
+		return "", err
+	}
+	return version + versionSeparator + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 按密文中的版本号选择密钥解密
+func (k *Keyring) Decrypt(ciphertext string, aad []byte) ([]byte, error) {
+	version, payload, ok := strings.Cut(ciphertext, versionSeparator)
+	if !ok {
+		return nil, ErrInvalidCiphertext
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return k.Open(version, sealed, aad)
+}
+
+// Seal 使用 primary 密钥加密为二进制（nonce || ciphertext || tag），返回所用密钥版本
+// 适用于自行记录版本号的场景（如消息体加密，版本写入消息属性）
+func (k *Keyring) Seal(plaintext, aad []byte) (string, []byte, error) {
+	k.mu.RLock()
+	version, aead := k.primary, k.aeads[k.primary]
+	k.mu.RUnlock()
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return version, aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// Open 使用指定版本的密钥解密 Seal 的输出
+func (k *Keyring) Open(version string, sealed, aad []byte) ([]byte, error) {
+	k.mu.RLock()
+	aead, found := k.aeads[version]
+	k.mu.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeyVersion, version)
+	}
+
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, body := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, body, aad)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+// EncryptString 加密字符串
+func (k *Keyring) EncryptString(plaintext string) (string, error) {
+	return k.Encrypt([]byte(plaintext), nil)
+}
+
+// DecryptString 解密字符串
+func (k *Keyring) DecryptString(ciphertext string) (string, error) {
+	plaintext, err := k.Decrypt(ciphertext, nil)
+	return string(plaintext), err
+}
+
+// KeyVersion 返回密文使用的密钥版本
+func KeyVersion(ciphertext string) (string, bool) {
+	version, _, ok := strings.Cut(ciphertext, versionSeparator)
+	return version, ok
+}
+
+// NeedsRotation 密文是否由非 primary 密钥加密
+func (k *Keyring) NeedsRotation(ciphertext string) bool {
+	version, ok := KeyVersion(ciphertext)
+	return ok && version != k.Primary()
+}
+
+// Rotate 使用 primary 密钥重新加密，已是 primary 时原样返回
+func (k *Keyring) Rotate(ciphertext string, aad []byte) (string, error) {
+	if !k.NeedsRotation(ciphertext) {
+		return ciphertext, nil
+	}
+	plaintext, err := k.Decrypt(ciphertext, aad)
+	if err != nil {
+		return "", err
+	}
+	return k.Encrypt(plaintext, aad)
+}
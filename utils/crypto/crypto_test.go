@@ -0,0 +1,163 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func testKeyring(t *testing.T) *Keyring {
+	t.Helper()
+	k1, _ := GenerateKey()
+	kr, err := NewKeyringFromConfig(Config{Primary: "1", Keys: map[string]string{"1": k1}})
+	if err != nil {
+		t.Fatalf("keyring: %v", err)
+	}
+	return kr
+}
+
+func TestKeyringEncryptAndRotate(t *testing.T) {
+	kr := testKeyring(t)
+
+	ct, err := kr.Encrypt([]byte("13800138000"), []byte("users.phone"))
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if !strings.HasPrefix(ct, "1$") {
+		t.Fatalf("expected version prefix, got %q", ct)
+	}
+	other, _ := kr.Encrypt([]byte("13800138000"), []byte("users.phone"))
+	if other == ct {
+		t.Fatalf("expected random nonce per encryption")
+	}
+
+	pt, err := kr.Decrypt(ct, []byte("users.phone"))
+	if err != nil || string(pt) != "13800138000" {
+		t.Fatalf("decrypt: %q %v", pt, err)
+	}
+	if _, err := kr.Decrypt(ct, []byte("users.email")); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Fatalf("expected aad mismatch to fail, got %v", err)
+	}
+	tampered := ct[:len(ct)-2] + "AA"
+	if _, err := kr.Decrypt(tampered, []byte("users.phone")); !errors.Is(err, ErrInvalidCiphertext) {
+		t.Fatalf("expected tampered ciphertext to fail, got %v", err)
+	}
+
+	// 轮换: 新增版本 2 并设为 primary
+	if err := kr.AddKey("2", bytes.Repeat([]byte{7}, KeySize)); err != nil {
+		t.Fatalf("add key: %v", err)
+	}
+	if err := kr.SetPrimary("2"); err != nil {
+		t.Fatalf("set primary: %v", err)
+	}
+	if !kr.NeedsRotation(ct) {
+		t.Fatalf("expected v1 ciphertext to need rotation")
+	}
+	rotated, err := kr.Rotate(ct, []byte("users.phone"))
+	if err != nil || !strings.HasPrefix(rotated, "2$") || kr.NeedsRotation(rotated) {
+		t.Fatalf("rotate: %q %v", rotated, err)
+	}
+	if pt, _ := kr.Decrypt(rotated, []byte("users.phone")); string(pt) != "13800138000" {
+		t.Fatalf("unexpected rotated plaintext %q", pt)
+	}
+	if _, err := kr.Decrypt("9$abc", nil); !errors.Is(err, ErrUnknownKeyVersion) {
+		t.Fatalf("expected unknown version error, got %v", err)
+	}
+
+	if _, err := NewKeyring("1", map[string][]byte{"1": []byte("short")}); err == nil {
+		t.Fatalf("expected short key to be rejected")
+	}
+}
+
+func TestHMAC(t *testing.T) {
+	key, data := []byte("secret"), []byte("payload")
+	mac := HMACSHA256Hex(key, data)
+	if !VerifyHMACSHA256Hex(key, data, mac) {
+		t.Fatalf("expected valid mac")
+	}
+	if VerifyHMACSHA256Hex(key, []byte("other"), mac) || VerifyHMACSHA256Hex(key, data, "zz") {
+		t.Fatalf("expected invalid mac")
+	}
+}
+
+func TestPasswordHash(t *testing.T) {
+	params := Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+	hash, err := HashPasswordWithParams("p@ssw0rd", params)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("unexpected hash format %q", hash)
+	}
+	if ok, err := VerifyPassword("p@ssw0rd", hash); !ok || err != nil {
+		t.Fatalf("expected password to match: %v", err)
+	}
+	if ok, _ := VerifyPassword("wrong", hash); ok {
+		t.Fatalf("expected wrong password to fail")
+	}
+	if NeedsRehash(hash, params) || !NeedsRehash(hash, DefaultArgon2Params) {
+		t.Fatalf("unexpected rehash decision")
+	}
+	if _, err := VerifyPassword("x", "$2a$10$bcrypt"); !errors.Is(err, ErrInvalidHash) {
+		t.Fatalf("expected invalid hash error, got %v", err)
+	}
+
+	// 篡改或损坏的参数在调用 argon2 前拒绝（t/p 为 0 会 panic，超大 m 会耗尽内存）
+	parts := strings.Split(hash, "$")
+	salt, key := parts[4], parts[5]
+	short := base64.RawStdEncoding.EncodeToString([]byte("short"))
+	for _, tampered := range []string{
+		"$argon2id$v=19$m=1024,t=0,p=1$" + salt + "$" + key,
+		"$argon2id$v=19$m=1024,t=1,p=0$" + salt + "$" + key,
+		"$argon2id$v=19$m=0,t=1,p=1$" + salt + "$" + key,
+		"$argon2id$v=19$m=4194304,t=1,p=1$" + salt + "$" + key,
+		"$argon2id$v=19$m=1024,t=1000,p=1$" + salt + "$" + key,
+		"$argon2id$v=19$m=1024,t=1,p=1$" + short + "$" + key,
+		"$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$" + short,
+	} {
+		if _, err := VerifyPassword("p@ssw0rd", tampered); !errors.Is(err, ErrInvalidHash) {
+			t.Fatalf("expected invalid hash error for %q, got %v", tampered, err)
+		}
+	}
+}
+
+type cryptoTestUser struct {
+	ID    uint
+	Phone EncryptedString
+}
+
+func TestEncryptedStringColumn(t *testing.T) {
+	kr := testKeyring(t)
+	SetDefaultKeyring(kr)
+	defer SetDefaultKeyring(nil)
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := db.AutoMigrate(&cryptoTestUser{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	if err := db.Create(&[]cryptoTestUser{{Phone: "13800138000"}, {}}).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	var raw []string
+	db.Raw("SELECT phone FROM crypto_test_users ORDER BY id").Scan(&raw)
+	if len(raw) != 2 || !strings.HasPrefix(raw[0], "1$") || strings.Contains(raw[0], "13800138000") || raw[1] != "" {
+		t.Fatalf("unexpected stored values: %v", raw)
+	}
+
+	var users []cryptoTestUser
+	if err := db.Order("id").Find(&users).Error; err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if users[0].Phone != "13800138000" || users[1].Phone != "" {
+		t.Fatalf("unexpected decrypted users: %+v", users)
+	}
+}
@@ -0,0 +1,97 @@
+package crypto
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+/* ========================================================================
+ * EncryptedString - 透明加密的字符串列
+ * ========================================================================
+ * 职责: 写库时使用默认密钥环加密，读库时解密，业务代码按普通字符串使用
+ * 约定: 空字符串按原样存储（不加密），密文列建议使用 text / varchar(512)
+ * 检索: 密文不可等值查询，需要时另建 HMACSHA256Hex 结果的索引列
+ *
+ * 使用示例:
+ *   crypto.SetDefaultKeyring(kr) // 应用启动时设置
+ *
+ *   type User struct {
+ *       ID        string                 `gorm:"primaryKey"`
+ *       Phone     crypto.EncryptedString `gorm:"type:varchar(512)"`
+ *       PhoneHash string                 `gorm:"index"` // HMAC 等值索引
+ *   }
+ * ======================================================================== */
+
+// defaultKeyring 默认密钥环
+var defaultKeyring atomic.Pointer[Keyring]
+
+// ErrNoKeyring 未设置默认密钥环
+var ErrNoKeyring = errors.New("crypto: default keyring is not set")
+
+// SetDefaultKeyring 设置 EncryptedString 使用的默认密钥环
+func SetDefaultKeyring(k *Keyring) {
+	defaultKeyring.Store(k)
+}
+
+// DefaultKeyring 返回默认密钥环（未设置时为 nil）
+func DefaultKeyring() *Keyring {
+	return defaultKeyring.Load()
+}
+
+// EncryptedString 静态加密的字符串
+type EncryptedString string
+
+// String 返回明文
+func (s EncryptedString) String() string {
+	return string(s)
+}
+
+// Value 实现 driver.Valuer，写入密文
+func (s EncryptedString) Value() (driver.Value, error) {
+	if s == "" {
+		return "", nil
+	}
+	kr := DefaultKeyring()
+	if kr == nil {
+		return nil, ErrNoKeyring
+	}
+	return kr.EncryptString(string(s))
+}
+
+// Scan 实现 sql.Scanner，读取时解密
+func (s *EncryptedString) Scan(src any) error {
+	var ciphertext string
+	switch v := src.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case string:
+		ciphertext = v
+	case []byte:
+		ciphertext = string(v)
+	default:
+		return fmt.Errorf("crypto: cannot scan %T into EncryptedString", src)
+	}
+	if ciphertext == "" {
+		*s = ""
+		return nil
+	}
+
+	kr := DefaultKeyring()
+	if kr == nil {
+		return ErrNoKeyring
+	}
+	plaintext, err := kr.DecryptString(ciphertext)
+	if err != nil {
+		return err
+	}
+	*s = EncryptedString(plaintext)
+	return nil
+}
+
+// GormDataType 默认列类型
+func (EncryptedString) GormDataType() string {
+	return "text"
+}
@@ -0,0 +1,40 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+/* ========================================================================
+ * HMAC - 消息认证码
+ * ========================================================================
+ * 职责: HMAC-SHA256 签名与常量时间校验
+ * 场景: Webhook 签名、可检索的确定性哈希（如加密手机号的等值查询索引列）
+ * ======================================================================== */
+
+// HMACSHA256 计算 HMAC-SHA256
+func HMACSHA256(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// HMACSHA256Hex 计算 HMAC-SHA256 并返回十六进制字符串
+func HMACSHA256Hex(key, data []byte) string {
+	return hex.EncodeToString(HMACSHA256(key, data))
+}
+
+// VerifyHMACSHA256 常量时间校验 HMAC-SHA256
+func VerifyHMACSHA256(key, data, mac []byte) bool {
+	return hmac.Equal(HMACSHA256(key, data), mac)
+}
+
+// VerifyHMACSHA256Hex 常量时间校验十六进制 HMAC-SHA256
+func VerifyHMACSHA256Hex(key, data []byte, macHex string) bool {
+	mac, err := hex.DecodeString(macHex)
+	if err != nil {
+		return false
+	}
+	return VerifyHMACSHA256(key, data, mac)
+}
@@ -0,0 +1,139 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+/* ========================================================================
+ * Password Hashing - Argon2id 密码哈希
+ * ========================================================================
+ * 职责: 使用 Argon2id 生成/校验密码哈希，输出 PHC 字符串格式:
+ *   $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+ * 参数升级: 调整 DefaultArgon2Params 后，登录成功时用 NeedsRehash 判断并重新哈希
+ * 参数校验: 校验时拒绝参数为零或超过 MaxArgon2Params 的哈希（返回 ErrInvalidHash）
+ * ======================================================================== */
+
+// Argon2Params Argon2id 参数
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params 默认参数（64 MiB / 3 次迭代 / 2 并行度）
+var DefaultArgon2Params = Argon2Params{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// MaxArgon2Params 解析哈希时允许的参数上限，超出视为无效哈希，防止篡改或损坏的哈希耗尽内存/CPU
+// 调大 DefaultArgon2Params 时需同步调整
+var MaxArgon2Params = Argon2Params{
+	Memory:      256 * 1024,
+	Iterations:  16,
+	Parallelism: 16,
+	SaltLength:  64,
+	KeyLength:   128,
+}
+
+// 解析哈希时允许的盐与密钥最小长度
+const (
+	minArgon2SaltLength = 8
+	minArgon2KeyLength  = 16
+)
+
+// ErrInvalidHash 密码哈希格式错误
+var ErrInvalidHash = errors.New("crypto: invalid argon2id hash")
+
+// HashPassword 使用默认参数哈希密码
+func HashPassword(password string) (string, error) {
+	return HashPasswordWithParams(password, DefaultArgon2Params)
+}
+
+// HashPasswordWithParams 使用指定参数哈希密码
+func HashPasswordWithParams(password string, p Argon2Params) (string, error) {
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// VerifyPassword 校验密码（常量时间比较）
+func VerifyPassword(password, encoded string) (bool, error) {
+	p, salt, key, err := decodeHash(encoded)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash 哈希参数是否与目标参数不一致
+func NeedsRehash(encoded string, target Argon2Params) bool {
+	p, _, _, err := decodeHash(encoded)
+	if err != nil {
+		return true
+	}
+	return p.Memory != target.Memory || p.Iterations != target.Iterations ||
+		p.Parallelism != target.Parallelism || p.KeyLength != target.KeyLength
+}
+
+// decodeHash 解析 PHC 格式哈希
+func decodeHash(encoded string) (Argon2Params, []byte, []byte, error) {
+	var p Argon2Params
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, ErrInvalidHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, ErrInvalidHash
+	}
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+	if !p.valid() {
+		return p, nil, nil, ErrInvalidHash
+	}
+	return p, salt, key, nil
+}
+
+// valid 参数非零且不超过 MaxArgon2Params（argon2.IDKey 在 t 或 p 为 0 时 panic）
+func (p Argon2Params) valid() bool {
+	limit := MaxArgon2Params
+	return p.Memory > 0 && p.Memory <= limit.Memory &&
+		p.Iterations > 0 && p.Iterations <= limit.Iterations &&
+		p.Parallelism > 0 && p.Parallelism <= limit.Parallelism &&
+		p.SaltLength >= minArgon2SaltLength && p.SaltLength <= limit.SaltLength &&
+		p.KeyLength >= minArgon2KeyLength && p.KeyLength <= limit.KeyLength
+}
@@ -0,0 +1,270 @@
+package ulid
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+/* ========================================================================
+ * ULID GORM Helpers - ULID 数据库类型与序列化
+ * ========================================================================
+ * 背景: oklog ULID 的 Value() 写出 16 字节二进制，与 char(26) 列不匹配，
+ *       MySQL 以 []byte 返回 char 列时 Scan 也会失败
+ * 提供:
+ *   - ULIDString: 以 26 位字符串存储的 ULID 类型（零值 <-> NULL，JSON 零值 <-> null）
+ *   - "ulid" 序列化器: 让现有 ulid.ULID / string 字段以文本形式存储
+ *   - FillPrimaryKey / RegisterAutoID: 创建前自动填充空主键
+ *
+ * 使用示例:
+ *   type Order struct {
+ *       ID     ulid.ULIDString `gorm:"type:char(26);primaryKey"`
+ *       UserID ulidv2.ULID     `gorm:"type:char(26);serializer:ulid"`
+ *   }
+ *
+ *   func (o *Order) BeforeCreate(tx *gorm.DB) error { return ulid.FillPrimaryKey(tx) }
+ *   // 或全局注册: ulid.RegisterAutoID(db)
+ * ======================================================================== */
+
+// SerializerName ULID 文本序列化器名称
+const SerializerName = "ulid"
+
+func init() {
+	schema.RegisterSerializer(SerializerName, Serializer{})
+}
+
+// ParseLoose 宽松解析：忽略首尾空白与大小写，空字符串返回零值
+func ParseLoose(s string) (ulid.ULID, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return ulid.ULID{}, nil
+	}
+	return ulid.ParseStrict(strings.ToUpper(s))
+}
+
+// scanULID 从数据库值解析 ULID（文本或 16 字节二进制）
+func scanULID(src any) (ulid.ULID, error) {
+	switch v := src.(type) {
+	case nil:
+		return ulid.ULID{}, nil
+	case string:
+		return ParseLoose(v)
+	case []byte:
+		if len(v) == 16 {
+			var id ulid.ULID
+			copy(id[:], v)
+			return id, nil
+		}
+		return ParseLoose(string(bytes.TrimSpace(v)))
+	default:
+		return ulid.ULID{}, fmt.Errorf("ulid: cannot scan %T", src)
+	}
+}
+
+// ========================================================================
+// ULIDString
+// ========================================================================
+
+// ULIDString 以 26 位字符串存储的 ULID
+type ULIDString ulid.ULID
+
+// NewULIDString 生成新的 ULIDString
+func NewULIDString() ULIDString {
+	return ULIDString(Generate())
+}
+
+// ParseULIDString 解析 ULIDString（大小写不敏感）
+func ParseULIDString(s string) (ULIDString, error) {
+	id, err := ParseLoose(s)
+	return ULIDString(id), err
+}
+
+// ULID 转换为 oklog ULID
+func (u ULIDString) ULID() ulid.ULID {
+	return ulid.ULID(u)
+}
+
+// IsZero 是否为零值
+func (u ULIDString) IsZero() bool {
+	return IsZero(ulid.ULID(u))
+}
+
+// String 返回字符串，零值返回空字符串
+func (u ULIDString) String() string {
+	if u.IsZero() {
+		return ""
+	}
+	return ulid.ULID(u).String()
+}
+
+// Value 实现 driver.Valuer，零值写入 NULL
+func (u ULIDString) Value() (driver.Value, error) {
+	if u.IsZero() {
+		return nil, nil
+	}
+	return ulid.ULID(u).String(), nil
+}
+
+// Scan 实现 sql.Scanner，NULL 解析为零值
+func (u *ULIDString) Scan(src any) error {
+	id, err := scanULID(src)
+	if err != nil {
+		return err
+	}
+	*u = ULIDString(id)
+	return nil
+}
+
+// GormDataType 默认列类型
+func (ULIDString) GormDataType() string {
+	return "char(26)"
+}
+
+// MarshalJSON 零值输出 null
+func (u ULIDString) MarshalJSON() ([]byte, error) {
+	if u.IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + ulid.ULID(u).String() + `"`), nil
+}
+
+// UnmarshalJSON null 与空字符串解析为零值
+func (u *ULIDString) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		*u = ULIDString{}
+		return nil
+	}
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return fmt.Errorf("ulid: invalid JSON value %s", s)
+	}
+	id, err := ParseLoose(s[1 : len(s)-1])
+	if err != nil {
+		return err
+	}
+	*u = ULIDString(id)
+	return nil
+}
+
+// MarshalText 实现 encoding.TextMarshaler（用于 map key、yaml 等）
+func (u ULIDString) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText 实现 encoding.TextUnmarshaler
+func (u *ULIDString) UnmarshalText(data []byte) error {
+	id, err := ParseLoose(string(data))
+	if err != nil {
+		return err
+	}
+	*u = ULIDString(id)
+	return nil
+}
+
+// ========================================================================
+// GORM Serializer
+// ========================================================================
+
+// Serializer 以文本形式读写 ulid.ULID / string 字段（gorm:"serializer:ulid"）
+type Serializer struct{}
+
+// Scan 实现 schema.SerializerInterface
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	id, err := scanULID(dbValue)
+	if err != nil {
+		return err
+	}
+	fv := reflect.New(field.FieldType)
+	switch ptr := fv.Interface().(type) {
+	case *ulid.ULID:
+		*ptr = id
+	case *ULIDString:
+		*ptr = ULIDString(id)
+	case *string:
+		if !IsZero(id) {
+			*ptr = id.String()
+		}
+	default:
+		return fmt.Errorf("ulid serializer: unsupported field type %s", field.FieldType)
+	}
+	field.ReflectValueOf(ctx, dst).Set(fv.Elem())
+	return nil
+}
+
+// Value 实现 schema.SerializerValuerInterface，零值写入 NULL
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue any) (any, error) {
+	switch v := fieldValue.(type) {
+	case ulid.ULID:
+		return ULIDString(v).Value()
+	case ULIDString:
+		return v.Value()
+	case string:
+		id, err := ParseLoose(v)
+		if err != nil {
+			return nil, err
+		}
+		return ULIDString(id).Value()
+	default:
+		return nil, fmt.Errorf("ulid serializer: unsupported value type %T", fieldValue)
+	}
+}
+
+// ========================================================================
+// 主键自动填充
+// ========================================================================
+
+// FillPrimaryKey 为空主键生成 ULID，可在模型 BeforeCreate 中调用
+// 支持 ulid.ULID / ULIDString / string 类型主键，兼容批量创建
+func FillPrimaryKey(tx *gorm.DB) error {
+	stmt := tx.Statement
+	if stmt == nil || stmt.Schema == nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+
+	fill := func(rv reflect.Value) error {
+		if _, zero := field.ValueOf(stmt.Context, rv); !zero {
+			return nil
+		}
+		switch field.FieldType {
+		case reflect.TypeOf(ulid.ULID{}):
+			return field.Set(stmt.Context, rv, Generate())
+		case reflect.TypeOf(ULIDString{}):
+			return field.Set(stmt.Context, rv, NewULIDString())
+		default:
+			if field.FieldType.Kind() == reflect.String {
+				return field.Set(stmt.Context, rv, GenerateString())
+			}
+			return nil
+		}
+	}
+
+	rv := reflect.Indirect(stmt.ReflectValue)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if err := fill(reflect.Indirect(rv.Index(i))); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		return fill(rv)
+	}
+	return nil
+}
+
+// RegisterAutoID 注册全局创建回调，为所有模型的空主键自动生成 ULID
+func RegisterAutoID(db *gorm.DB) error {
+	return db.Callback().Create().Before("gorm:create").Register("ulid:auto_id", func(tx *gorm.DB) {
+		if err := FillPrimaryKey(tx); err != nil {
+			_ = tx.AddError(err)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package ulid
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type gormTestOrder struct {
+	ID       ULIDString `gorm:"primaryKey"`
+	UserID   ulid.ULID  `gorm:"type:char(26);serializer:ulid"`
+	RefID    ULIDString
+	ExternID string `gorm:"type:char(26);serializer:ulid"`
+}
+
+type gormTestItem struct {
+	ID   string `gorm:"type:char(26);primaryKey"`
+	Name string
+}
+
+func TestULIDStringJSONAndScan(t *testing.T) {
+	id := NewULIDString()
+
+	data, _ := json.Marshal(struct {
+		A ULIDString `json:"a"`
+		B ULIDString `json:"b"`
+	}{A: id})
+	if string(data) != `{"a":"`+id.String()+`","b":null}` {
+		t.Fatalf("unexpected json: %s", data)
+	}
+
+	var out struct {
+		A ULIDString `json:"a"`
+		B ULIDString `json:"b"`
+	}
+	lower := `{"a":"` + strings.ToLower(id.String()) + `","b":""}`
+	if err := json.Unmarshal([]byte(lower), &out); err != nil || out.A != id || !out.B.IsZero() {
+		t.Fatalf("unexpected unmarshal: %+v %v", out, err)
+	}
+
+	var scanned ULIDString
+	if err := scanned.Scan([]byte(id.String())); err != nil || scanned != id {
+		t.Fatalf("scan text bytes: %v", err)
+	}
+	if err := scanned.Scan(nil); err != nil || !scanned.IsZero() {
+		t.Fatalf("scan nil: %v", err)
+	}
+	if v, _ := scanned.Value(); v != nil {
+		t.Fatalf("expected zero value to be NULL, got %v", v)
+	}
+	if err := scanned.Scan("not-a-ulid"); err == nil {
+		t.Fatalf("expected parse error")
+	}
+}
+
+func TestGORMSerializerAndAutoID(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	if err := RegisterAutoID(db); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if err := db.AutoMigrate(&gormTestOrder{}, &gormTestItem{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	user := Generate()
+	extern := strings.ToLower(GenerateString())
+	order := &gormTestOrder{UserID: user, ExternID: extern}
+	if err := db.Create(order).Error; err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if order.ID.IsZero() {
+		t.Fatalf("expected primary key to be filled")
+	}
+
+	var raw struct {
+		UserID   string
+		RefID    *string
+		ExternID string
+	}
+	db.Raw("SELECT user_id, ref_id, extern_id FROM gorm_test_orders").Scan(&raw)
+	if raw.UserID != user.String() || raw.RefID != nil || raw.ExternID != strings.ToUpper(extern) {
+		t.Fatalf("unexpected stored values: %+v", raw)
+	}
+
+	var got gormTestOrder
+	if err := db.First(&got, "id = ?", order.ID).Error; err != nil {
+		t.Fatalf("first: %v", err)
+	}
+	if got.ID != order.ID || got.UserID != user || !got.RefID.IsZero() || got.ExternID != strings.ToUpper(extern) {
+		t.Fatalf("unexpected loaded order: %+v", got)
+	}
+
+	items := []gormTestItem{{Name: "a"}, {ID: "01ARZ3NDEKTSV4RRFFQ69G5FAV", Name: "b"}}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatalf("create batch: %v", err)
+	}
+	if len(items[0].ID) != 26 || items[1].ID != "01ARZ3NDEKTSV4RRFFQ69G5FAV" {
+		t.Fatalf("unexpected batch ids: %+v", items)
+	}
+}
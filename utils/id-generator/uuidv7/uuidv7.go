@@ -0,0 +1,153 @@
+package uuidv7
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+)
+
+/* ========================================================================
+ * UUIDv7 Generator - UUIDv7 生成器
+ * ========================================================================
+ * 职责: 生成时间有序的 UUID（RFC 9562 Version 7），适配 Postgres uuid 列
+ * ID 结构:
+ *   - 48 位时间戳（Unix 毫秒）
+ *   - 4 位版本号（0111）
+ *   - 12 位 rand_a（同一毫秒内单调递增，由 google/uuid 维护）
+ *   - 2 位变体 + 62 位 rand_b
+ *
+ * 与 ULID 互转:
+ *   - FromULID / ToULID 保留毫秒时间戳，随机位按位复制（UUIDv7 版本/变体位覆盖 6 位）
+ *   - 两者均按时间排序，转换后排序关系（跨毫秒）保持不变
+ * ======================================================================== */
+
+// Generate 生成 UUIDv7
+func Generate() uuid.UUID {
+	return uuid.Must(uuid.NewV7())
+}
+
+// GenerateString 生成 UUIDv7（字符串格式）
+func GenerateString() string {
+	return Generate().String()
+}
+
+// GenerateWithTime 使用指定时间生成 UUIDv7
+// 适用于需要精确控制时间戳的场景（如数据迁移）
+func GenerateWithTime(t time.Time) uuid.UUID {
+	var u uuid.UUID
+	if _, err := rand.Read(u[6:]); err != nil {
+		panic(err)
+	}
+	return withTimestamp(u, t.UnixMilli())
+}
+
+// GenerateBatch 批量生成 UUIDv7（严格递增）
+func GenerateBatch(count int) []uuid.UUID {
+	if count <= 0 {
+		return []uuid.UUID{}
+	}
+	ids := make([]uuid.UUID, count)
+	for i := range ids {
+		ids[i] = Generate()
+	}
+	return ids
+}
+
+// GenerateBatchString 批量生成 UUIDv7（字符串格式）
+func GenerateBatchString(count int) []string {
+	ids := GenerateBatch(count)
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = id.String()
+	}
+	return strs
+}
+
+// Parse 解析 UUIDv7 字符串，非 Version 7 返回错误
+func Parse(s string) (uuid.UUID, error) {
+	u, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if u.Version() != 7 {
+		return uuid.Nil, fmt.Errorf("uuidv7: unexpected version %d", u.Version())
+	}
+	return u, nil
+}
+
+// MustParse 解析 UUIDv7 字符串，失败时 panic
+func MustParse(s string) uuid.UUID {
+	u, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+// ========================================================================
+// 辅助函数
+// ========================================================================
+
+// Time 提取 UUIDv7 中的时间戳（毫秒精度）
+func Time(u uuid.UUID) time.Time {
+	return time.UnixMilli(timestamp(u))
+}
+
+// Compare 比较两个 UUIDv7
+// 返回值: -1 (a < b), 0 (a == b), 1 (a > b)
+func Compare(a, b uuid.UUID) int {
+	for i := range a {
+		switch {
+		case a[i] < b[i]:
+			return -1
+		case a[i] > b[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// IsZero 检查是否为零值
+func IsZero(u uuid.UUID) bool {
+	return u == uuid.Nil
+}
+
+// ========================================================================
+// ULID ⇄ UUIDv7 互转（保留时间戳）
+// ========================================================================
+
+// FromULID 将 ULID 转换为 UUIDv7，保留毫秒时间戳
+// 注意: 版本与变体位会覆盖 ULID 的 6 位随机位，转换不可逆
+func FromULID(id ulid.ULID) uuid.UUID {
+	var u uuid.UUID
+	copy(u[:], id[:])
+	return withTimestamp(u, int64(id.Time()))
+}
+
+// ToULID 将 UUIDv7 转换为 ULID，保留毫秒时间戳
+func ToULID(u uuid.UUID) ulid.ULID {
+	var id ulid.ULID
+	copy(id[:], u[:])
+	return id
+}
+
+// timestamp 读取 48 位毫秒时间戳
+func timestamp(u uuid.UUID) int64 {
+	var buf [8]byte
+	copy(buf[2:], u[:6])
+	return int64(binary.BigEndian.Uint64(buf[:]))
+}
+
+// withTimestamp 写入时间戳并设置版本与变体位
+func withTimestamp(u uuid.UUID, ms int64) uuid.UUID {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(ms))
+	copy(u[:6], buf[2:])
+	u[6] = (u[6] & 0x0f) | 0x70 // Version 7
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 9562 变体
+	return u
+}
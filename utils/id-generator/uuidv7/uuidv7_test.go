@@ -0,0 +1,78 @@
+package uuidv7
+
+import (
+	"testing"
+	"time"
+
+	ulidgen "github.com/aisgo/ais-go-pkg/utils/id-generator/ulid"
+
+	"github.com/google/uuid"
+)
+
+/* ========================================================================
+ * UUIDv7 Generator Tests
+ * ======================================================================== */
+
+func TestGenerate(t *testing.T) {
+	u := Generate()
+	if IsZero(u) || u.Version() != 7 || u.Variant() != uuid.RFC4122 {
+		t.Fatalf("生成的 UUID 不是 v7: %s", u)
+	}
+	if _, err := Parse(u.String()); err != nil {
+		t.Fatalf("生成的 UUIDv7 字符串无法解析: %v", err)
+	}
+	if d := time.Since(Time(u)); d < 0 || d > time.Second {
+		t.Fatalf("时间戳偏差过大: %v", d)
+	}
+}
+
+func TestGenerateWithTime(t *testing.T) {
+	t0 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	u := GenerateWithTime(t0)
+	if u.Version() != 7 || u.Variant() != uuid.RFC4122 {
+		t.Fatalf("版本或变体错误: %s", u)
+	}
+	if !Time(u).Equal(t0) {
+		t.Fatalf("时间戳不匹配，期望: %v, 实际: %v", t0, Time(u))
+	}
+}
+
+func TestGenerateBatchOrdered(t *testing.T) {
+	ids := GenerateBatch(1000)
+	for i := 1; i < len(ids); i++ {
+		if Compare(ids[i-1], ids[i]) >= 0 {
+			t.Fatalf("批量生成的 UUIDv7 未严格递增: %s >= %s", ids[i-1], ids[i])
+		}
+	}
+	if len(GenerateBatchString(3)) != 3 || len(GenerateBatch(0)) != 0 {
+		t.Fatalf("批量数量错误")
+	}
+}
+
+func TestParseRejectsOtherVersions(t *testing.T) {
+	if _, err := Parse(uuid.NewString()); err == nil {
+		t.Fatalf("v4 UUID 应被拒绝")
+	}
+	if _, err := Parse("not-a-uuid"); err == nil {
+		t.Fatalf("非法字符串应被拒绝")
+	}
+}
+
+func TestULIDConversionPreservesTime(t *testing.T) {
+	t0 := time.Date(2025, 6, 1, 12, 30, 45, 123e6, time.UTC)
+	id := ulidgen.GenerateWithTime(t0)
+
+	u := FromULID(id)
+	if u.Version() != 7 || !Time(u).Equal(t0) {
+		t.Fatalf("ULID -> UUIDv7 时间戳或版本错误: %s %v", u, Time(u))
+	}
+	back := ToULID(u)
+	if !ulidgen.Time(back).Equal(t0) {
+		t.Fatalf("UUIDv7 -> ULID 时间戳不匹配: %v", ulidgen.Time(back))
+	}
+
+	later := FromULID(ulidgen.GenerateWithTime(t0.Add(time.Millisecond)))
+	if Compare(u, later) >= 0 {
+		t.Fatalf("转换后应保持时间排序")
+	}
+}
@@ -0,0 +1,40 @@
+package mask
+
+import (
+	"slices"
+
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// PermissionViewPII 允许查看明文个人敏感信息的权限（角色）名
+const PermissionViewPII = "view_pii"
+
+// CanViewPII 默认权限判断：ctx 中 TenantContext.Roles 包含 view_pii
+func CanViewPII(c fiber.Ctx) bool {
+	tc, ok := repository.TenantFromContext(c.Context())
+	return ok && slices.Contains(tc.Roles, PermissionViewPII)
+}
+
+// ResponseEncoder 包装响应编码器，对无 view_pii 权限的请求脱敏响应数据
+// next 为 nil 时使用 response.DefaultEncoder，canView 为 nil 时使用 CanViewPII
+//
+// 使用示例:
+//
+//	response.SetEncoder(mask.ResponseEncoder(nil, nil))
+func ResponseEncoder(next response.Encoder, canView func(c fiber.Ctx) bool) response.Encoder {
+	if next == nil {
+		next = response.DefaultEncoder
+	}
+	if canView == nil {
+		canView = CanViewPII
+	}
+	return func(c fiber.Ctx, env response.Envelope) any {
+		if env.Data != nil && !canView(c) {
+			env.Data = Apply(env.Data)
+		}
+		return next(c, env)
+	}
+}
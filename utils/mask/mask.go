@@ -0,0 +1,136 @@
+package mask
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+/* ========================================================================
+ * Mask - 敏感数据脱敏
+ * ========================================================================
+ * 职责: 手机号/邮箱/身份证/银行卡/姓名等脱敏函数，及按类型名注册的脱敏器
+ * 规则:
+ *   - phone:    138****8000（保留前 3 后 4）
+ *   - email:    a***@example.com（保留首字符与域名）
+ *   - id_card:  1101**********1234（保留前 4 后 4）
+ *   - bank_card: ************1234（仅保留后 4）
+ *   - name:     张*、欧阳**（保留首字）
+ *   - secret:   ******（全部遮盖，固定长度不泄露原长度）
+ *
+ * 使用示例:
+ *   mask.Phone("13800138000")          // 138****8000
+ *   mask.Mask("email", "alice@a.com")  // a****@a.com
+ *   mask.Register("plate", func(s string) string { return mask.Keep(s, 2, 1) })
+ * ======================================================================== */
+
+// 内置脱敏类型
+const (
+	KindPhone    = "phone"
+	KindEmail    = "email"
+	KindIDCard   = "id_card"
+	KindBankCard = "bank_card"
+	KindName     = "name"
+	KindSecret   = "secret"
+)
+
+// maskRune 遮盖字符
+const maskRune = '*'
+
+// Masker 脱敏函数
+type Masker func(s string) string
+
+var (
+	mu      sync.RWMutex
+	maskers = map[string]Masker{
+		KindPhone:    Phone,
+		KindEmail:    Email,
+		KindIDCard:   IDCard,
+		KindBankCard: BankCard,
+		KindName:     Name,
+		KindSecret:   Secret,
+	}
+)
+
+// Register 注册（或覆盖）脱敏类型
+func Register(kind string, fn Masker) {
+	mu.Lock()
+	defer mu.Unlock()
+	maskers[kind] = fn
+}
+
+// Lookup 查找脱敏函数
+func Lookup(kind string) (Masker, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	fn, ok := maskers[kind]
+	return fn, ok
+}
+
+// Mask 按类型脱敏，未注册的类型按 Secret 处理（宁可多遮盖）
+func Mask(kind, s string) string {
+	if fn, ok := Lookup(kind); ok {
+		return fn(s)
+	}
+	return Secret(s)
+}
+
+// Keep 保留前 left 个与后 right 个字符，其余替换为 *（按字符计数）
+// 字符数不足时整体遮盖，避免短值被完整暴露
+func Keep(s string, left, right int) string {
+	n := utf8.RuneCountInString(s)
+	if n == 0 {
+		return ""
+	}
+	if n <= left+right {
+		return strings.Repeat(string(maskRune), n)
+	}
+	runes := []rune(s)
+	for i := left; i < n-right; i++ {
+		runes[i] = maskRune
+	}
+	return string(runes)
+}
+
+// Phone 手机号脱敏
+func Phone(s string) string {
+	return Keep(s, 3, 4)
+}
+
+// Email 邮箱脱敏
+func Email(s string) string {
+	local, domain, ok := strings.Cut(s, "@")
+	if !ok {
+		return Secret(s)
+	}
+	if utf8.RuneCountInString(local) <= 1 {
+		return strings.Repeat(string(maskRune), utf8.RuneCountInString(local)) + "@" + domain
+	}
+	return Keep(local, 1, 0) + "@" + domain
+}
+
+// IDCard 身份证号脱敏
+func IDCard(s string) string {
+	return Keep(s, 4, 4)
+}
+
+// BankCard 银行卡号脱敏
+func BankCard(s string) string {
+	return Keep(s, 0, 4)
+}
+
+// Name 姓名脱敏
+func Name(s string) string {
+	if utf8.RuneCountInString(s) == 1 {
+		return s
+	}
+	return Keep(s, 1, 0)
+}
+
+// Secret 全部遮盖
+func Secret(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "******"
+}
@@ -0,0 +1,146 @@
+package mask
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aisgo/ais-go-pkg/repository"
+	"github.com/aisgo/ais-go-pkg/response"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func TestMaskers(t *testing.T) {
+	cases := []struct {
+		kind, in, want string
+	}{
+		{KindPhone, "13800138000", "138****8000"},
+		{KindPhone, "1234", "****"},
+		{KindEmail, "alice@example.com", "a****@example.com"},
+		{KindEmail, "a@example.com", "*@example.com"},
+		{KindEmail, "not-an-email", "******"},
+		{KindIDCard, "110101199001011234", "1101**********1234"},
+		{KindBankCard, "6222021234567890", "************7890"},
+		{KindName, "张三", "张*"},
+		{KindName, "欧阳娜娜", "欧***"},
+		{KindName, "李", "李"},
+		{KindSecret, "p@ssw0rd", "******"},
+		{"unknown", "value", "******"},
+		{KindPhone, "", ""},
+	}
+	for _, tc := range cases {
+		if got := Mask(tc.kind, tc.in); got != tc.want {
+			t.Fatalf("Mask(%q, %q) = %q, want %q", tc.kind, tc.in, got, tc.want)
+		}
+	}
+
+	Register("plate", func(s string) string { return Keep(s, 2, 1) })
+	if got := Mask("plate", "京A12345"); got != "京A****5" {
+		t.Fatalf("unexpected custom mask: %q", got)
+	}
+}
+
+type maskContact struct {
+	Email *string `json:"email" mask:"email"`
+}
+
+type maskUser struct {
+	Name     string            `json:"name" mask:"name"`
+	Phone    string            `json:"phone" mask:"phone"`
+	Cards    []string          `json:"cards" mask:"bank_card"`
+	Nickname string            `json:"nickname"`
+	Contact  *maskContact      `json:"contact"`
+	Extra    map[string]any    `json:"extra"`
+	Labels   map[string]string `json:"labels"`
+	Parent   *maskUser         `json:"parent"`
+}
+
+func TestApplyStruct(t *testing.T) {
+	email := "bob@example.com"
+	user := maskUser{
+		Name:     "张三",
+		Phone:    "13800138000",
+		Cards:    []string{"6222021234567890"},
+		Nickname: "bob",
+		Contact:  &maskContact{Email: &email},
+		Extra:    map[string]any{"owner": maskUser{Phone: "13900139000"}},
+		Labels:   map[string]string{"k": "v"},
+		Parent:   &maskUser{Phone: "13700137000"},
+	}
+
+	masked := Apply([]maskUser{user}).([]maskUser)[0]
+	if masked.Name != "张*" || masked.Phone != "138****8000" || masked.Cards[0] != "************7890" {
+		t.Fatalf("unexpected masked fields: %+v", masked)
+	}
+	if masked.Nickname != "bob" || masked.Labels["k"] != "v" {
+		t.Fatalf("untagged fields should be kept: %+v", masked)
+	}
+	if *masked.Contact.Email != "b**@example.com" {
+		t.Fatalf("unexpected masked email: %q", *masked.Contact.Email)
+	}
+	if masked.Extra["owner"].(maskUser).Phone != "139****9000" || masked.Parent.Phone != "137****7000" {
+		t.Fatalf("nested values should be masked: %+v", masked)
+	}
+
+	// 原值不被修改
+	if user.Phone != "13800138000" || email != "bob@example.com" || user.Cards[0] != "6222021234567890" ||
+		user.Parent.Phone != "13700137000" {
+		t.Fatalf("original value was modified: %+v", user)
+	}
+
+	if Apply(nil) != nil {
+		t.Fatalf("expected nil")
+	}
+	if got := Apply(42); got != 42 {
+		t.Fatalf("unexpected scalar result: %v", got)
+	}
+}
+
+func TestResponseEncoder(t *testing.T) {
+	response.SetEncoder(ResponseEncoder(nil, nil))
+	defer response.SetEncoder(nil)
+
+	app := fiber.New()
+	app.Use(func(c fiber.Ctx) error {
+		tc := repository.TenantContext{}
+		if c.Get("X-Role") != "" {
+			tc.Roles = []string{c.Get("X-Role")}
+		}
+		c.SetContext(repository.WithTenantContext(context.Background(), tc))
+		return c.Next()
+	})
+	app.Get("/user", func(c fiber.Ctx) error {
+		return response.OkWithData(c, maskUser{Name: "张三", Phone: "13800138000"})
+	})
+
+	call := func(role string) maskUser {
+		req := httptest.NewRequest("GET", "/user", nil)
+		if role != "" {
+			req.Header.Set("X-Role", role)
+		}
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		var out struct {
+			Data maskUser `json:"data"`
+		}
+		if err := json.NewDecoder(strings.NewReader(string(body))).Decode(&out); err != nil {
+			t.Fatalf("decode %s: %v", body, err)
+		}
+		return out.Data
+	}
+
+	if got := call(""); got.Phone != "138****8000" || got.Name != "张*" {
+		t.Fatalf("expected masked response, got %+v", got)
+	}
+	if got := call(PermissionViewPII); got.Phone != "13800138000" {
+		t.Fatalf("expected plain response for view_pii, got %+v", got)
+	}
+}
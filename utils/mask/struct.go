@@ -0,0 +1,181 @@
+package mask
+
+import (
+	"reflect"
+	"sync"
+)
+
+/* ========================================================================
+ * Struct Masking - 基于结构体标签的脱敏
+ * ========================================================================
+ * 职责: 按 `mask:"<kind>"` 标签对字符串字段脱敏，递归处理嵌套结构体、指针、切片与 map
+ * 说明: Apply 返回脱敏后的副本，原值不被修改；无标签的字段原样保留
+ *
+ * 使用示例:
+ *   type User struct {
+ *       Name  string  `json:"name" mask:"name"`
+ *       Phone string  `json:"phone" mask:"phone"`
+ *       Email *string `json:"email" mask:"email"`
+ *   }
+ *   masked := mask.Apply(users) // []User 副本
+ * ======================================================================== */
+
+// TagName 结构体标签名
+const TagName = "mask"
+
+// Apply 返回对 mask 标签字段脱敏后的副本
+func Apply(v any) any {
+	if v == nil {
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	out := apply(rv, 0)
+	if !out.IsValid() {
+		return v
+	}
+	return out.Interface()
+}
+
+// maxDepth 最大递归深度（防止循环引用）
+const maxDepth = 32
+
+func apply(v reflect.Value, depth int) reflect.Value {
+	if depth > maxDepth || !v.IsValid() {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		elem := apply(v.Elem(), depth+1)
+		ptr := reflect.New(v.Type().Elem())
+		ptr.Elem().Set(elem)
+		return ptr
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(apply(v.Elem(), depth+1))
+		return out
+	case reflect.Struct:
+		if !hasMaskable(v.Type()) {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			field := out.Field(i)
+			if kind, ok := f.Tag.Lookup(TagName); ok && kind != "" && kind != "-" {
+				maskField(field, kind)
+				continue
+			}
+			field.Set(apply(v.Field(i), depth+1))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() || !hasMaskable(v.Type().Elem()) {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(apply(v.Index(i), depth+1))
+		}
+		return out
+	case reflect.Array:
+		if !hasMaskable(v.Type().Elem()) {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(apply(v.Index(i), depth+1))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() || !hasMaskable(v.Type().Elem()) {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), apply(iter.Value(), depth+1))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// maskField 对 string / *string / []string 字段脱敏
+func maskField(field reflect.Value, kind string) {
+	switch {
+	case field.Kind() == reflect.String:
+		field.SetString(Mask(kind, field.String()))
+	case field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.String:
+		if field.IsNil() {
+			return
+		}
+		ptr := reflect.New(field.Type().Elem())
+		ptr.Elem().SetString(Mask(kind, field.Elem().String()))
+		field.Set(ptr)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		if field.IsNil() {
+			return
+		}
+		out := reflect.MakeSlice(field.Type(), field.Len(), field.Len())
+		for i := 0; i < field.Len(); i++ {
+			out.Index(i).SetString(Mask(kind, field.Index(i).String()))
+		}
+		field.Set(out)
+	}
+}
+
+// maskableCache 类型是否包含 mask 标签的缓存（reflect.Type -> bool）
+var maskableCache sync.Map
+
+// hasMaskable 类型（含嵌套）是否存在 mask 标签字段，interface 视为可能包含
+func hasMaskable(t reflect.Type) bool {
+	if cached, ok := maskableCache.Load(t); ok {
+		return cached.(bool)
+	}
+	result := scanMaskable(t, map[reflect.Type]bool{})
+	maskableCache.Store(t, result)
+	return result
+}
+
+// scanMaskable 深度优先扫描类型，visiting 防止自引用类型无限递归
+func scanMaskable(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Interface:
+		return true
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return scanMaskable(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if kind := f.Tag.Get(TagName); kind != "" && kind != "-" {
+				return true
+			}
+			if scanMaskable(f.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -2,6 +2,7 @@ package validator
 
 import (
 	"reflect"
+	"strings"
 	"sync"
 )
 
@@ -13,11 +14,18 @@ import (
 
 // fieldInfo 字段信息
 type fieldInfo struct {
-	name        string // 字段名
-	validateTag string // validate 标签值
-	errorMsgTag string // error_msg 标签值
-	isStruct    bool   // 是否为结构体
-	isPtr       bool   // 是否为指针类型
+	name        string      // 字段名
+	validateTag string      // validate 标签值（已移除跨字段规则）
+	errorMsgTag string      // error_msg 标签值
+	isStruct    bool        // 是否为结构体
+	isPtr       bool        // 是否为指针类型
+	omitEmpty   bool        // 是否声明 omitempty
+	crossRules  []crossRule // 跨字段规则
+
+	isCollection  bool   // 是否为切片 / 数组 / Map
+	collectionTag string // dive 之前作用于集合本身的规则
+	elemTag       string // dive 之后作用于元素的规则
+	hasDive       bool   // 是否声明 dive
 }
 
 // typeCache 类型缓存
@@ -81,12 +89,21 @@ func (tc *typeCache) getFieldsInfo(t reflect.Type) []fieldInfo {
 			fieldType = fieldType.Elem()
 		}
 
+		validateTag, crossRules := splitCrossRules(field.Tag.Get("validate"))
+
 		info := fieldInfo{
 			name:        field.Name,
-			validateTag: field.Tag.Get("validate"),
+			validateTag: validateTag,
 			errorMsgTag: field.Tag.Get(tagCustom),
-			isStruct:    fieldType.Kind() == reflect.Struct,
-			isPtr:       isPtr,
+			// time.Time 作为值类型校验，不递归
+			isStruct:   fieldType.Kind() == reflect.Struct && fieldType != timeType,
+			isPtr:      isPtr,
+			omitEmpty:  strings.HasPrefix(validateTag, "omitempty"),
+			crossRules: crossRules,
+		}
+		if isCollectionType(fieldType) {
+			info.isCollection = true
+			info.collectionTag, info.elemTag, info.hasDive = splitDive(validateTag)
 		}
 		fields = append(fields, info)
 	}
@@ -0,0 +1,211 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+/* ========================================================================
+ * Cross-Field Rules - 跨字段规则
+ * ========================================================================
+ * 职责: 在逐字段校验模式下支持依赖同级字段的规则
+ * 说明: 逐字段校验通过 validator.Var 执行，无法访问父结构体，
+ *       因此跨字段规则在解析标签时被拆出，由本文件基于父结构体求值
+ * 支持规则:
+ *   - required_with=A B:    任一字段非零值时，当前字段必填
+ *   - required_without=A B: 任一字段为零值时，当前字段必填
+ *   - eqfield=A / nefield=A
+ *   - gtfield=A / gtefield=A / ltfield=A / ltefield=A
+ * 使用示例:
+ *     type Req struct {
+ *         StartAt time.Time
+ *         EndAt   time.Time `validate:"gtfield=StartAt" error_msg:"gtfield:结束时间必须晚于开始时间"`
+ *     }
+ * ======================================================================== */
+
+// crossRule 跨字段规则
+type crossRule struct {
+	tag   string
+	param string
+}
+
+// crossFieldTags 支持的跨字段规则
+var crossFieldTags = map[string]bool{
+	"required_with":    true,
+	"required_without": true,
+	"eqfield":          true,
+	"nefield":          true,
+	"gtfield":          true,
+	"gtefield":         true,
+	"ltfield":          true,
+	"ltefield":         true,
+}
+
+// splitCrossRules 从 validate 标签中拆出跨字段规则
+// 含 "|" 的 OR 规则保持原样交由 validator 处理
+func splitCrossRules(tag string) (string, []crossRule) {
+	if tag == "" {
+		return "", nil
+	}
+
+	var (
+		rest  []string
+		rules []crossRule
+	)
+	parts := strings.Split(tag, ",")
+	for i, part := range parts {
+		if part == diveTag {
+			// dive 之后为元素级规则，不做拆分
+			rest = append(rest, parts[i:]...)
+			break
+		}
+		name, param, _ := strings.Cut(part, "=")
+		if crossFieldTags[name] && !strings.Contains(part, "|") {
+			rules = append(rules, crossRule{tag: name, param: param})
+			continue
+		}
+		rest = append(rest, part)
+	}
+	return strings.Join(rest, ","), rules
+}
+
+// requiredState 判断 required_with / required_without 的结果
+// required: 当前字段是否必填；handled: 是否存在 required_* 规则
+func requiredState(parent reflect.Value, rules []crossRule) (required bool, failedTag string, handled bool) {
+	for _, rule := range rules {
+		switch rule.tag {
+		case "required_with":
+			handled = true
+			for _, name := range strings.Fields(rule.param) {
+				if f := parent.FieldByName(name); f.IsValid() && !f.IsZero() {
+					return true, rule.tag, true
+				}
+			}
+		case "required_without":
+			handled = true
+			for _, name := range strings.Fields(rule.param) {
+				if f := parent.FieldByName(name); !f.IsValid() || f.IsZero() {
+					return true, rule.tag, true
+				}
+			}
+		}
+	}
+	return false, "", handled
+}
+
+// checkCompareRules 执行比较类跨字段规则，返回失败的规则
+func checkCompareRules(parent, field reflect.Value, rules []crossRule) []crossRule {
+	var failed []crossRule
+	for _, rule := range rules {
+		if rule.tag == "required_with" || rule.tag == "required_without" {
+			continue
+		}
+
+		other := parent.FieldByName(rule.param)
+		if !other.IsValid() {
+			failed = append(failed, rule)
+			continue
+		}
+
+		cmp, ok := compareValues(field, other)
+		if !ok {
+			failed = append(failed, rule)
+			continue
+		}
+
+		var pass bool
+		switch rule.tag {
+		case "eqfield":
+			pass = cmp == 0
+		case "nefield":
+			pass = cmp != 0
+		case "gtfield":
+			pass = cmp > 0
+		case "gtefield":
+			pass = cmp >= 0
+		case "ltfield":
+			pass = cmp < 0
+		case "ltefield":
+			pass = cmp <= 0
+		}
+		if !pass {
+			failed = append(failed, rule)
+		}
+	}
+	return failed
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// compareValues 比较两个字段值，支持数值、字符串、time.Time 及其指针
+func compareValues(a, b reflect.Value) (int, bool) {
+	a, b = reflect.Indirect(a), reflect.Indirect(b)
+	if !a.IsValid() || !b.IsValid() {
+		return 0, false
+	}
+
+	if a.Type() == timeType && b.Type() == timeType {
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), true
+	}
+
+	switch {
+	case isInt(a) && isInt(b):
+		return compareOrdered(a.Int(), b.Int()), true
+	case isUint(a) && isUint(b):
+		return compareOrdered(a.Uint(), b.Uint()), true
+	case isNumber(a) && isNumber(b):
+		return compareOrdered(toFloat(a), toFloat(b)), true
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	}
+	return 0, false
+}
+
+func compareOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isNumber(v reflect.Value) bool {
+	return isInt(v) || isUint(v) || v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case isInt(v):
+		return float64(v.Int())
+	case isUint(v):
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+// defaultRuleMessage 默认错误消息（与 validator 风格一致）
+func defaultRuleMessage(field, tag string) string {
+	return fmt.Sprintf("Field validation for '%s' failed on the '%s' tag", field, tag)
+}
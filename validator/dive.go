@@ -0,0 +1,135 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+/* ========================================================================
+ * Collection Validation - 切片 / 数组 / Map 校验
+ * ========================================================================
+ * 职责: 为集合字段提供 dive 语义的逐元素校验
+ * 规则:
+ *   - dive 之前的规则作用于集合本身（如 required,min=1）
+ *   - dive 之后的规则作用于每个元素，可多次 dive 处理嵌套集合
+ *   - 元素为结构体（或结构体指针）时总会递归校验其字段
+ *   - 错误键: 切片 Items[2].Name，Map Tags[key]
+ * 使用示例:
+ *     type Req struct {
+ *         Items []Item            `validate:"required,min=1,dive"`
+ *         Tags  []string          `validate:"dive,required,max=20"`
+ *         Attrs map[string]string `validate:"dive,required"`
+ *     }
+ * ======================================================================== */
+
+const diveTag = "dive"
+
+// splitDive 拆分 dive 前后的规则
+func splitDive(tag string) (outer, inner string, hasDive bool) {
+	parts := strings.Split(tag, ",")
+	for i, part := range parts {
+		if part == diveTag {
+			return strings.Join(parts[:i], ","), strings.Join(parts[i+1:], ","), true
+		}
+	}
+	return tag, "", false
+}
+
+// isCollectionType 是否为需逐元素校验的集合类型（排除 []byte 与 [N]byte）
+func isCollectionType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return t.Elem().Kind() != reflect.Uint8
+	case reflect.Map:
+		return true
+	}
+	return false
+}
+
+// validateCollection 校验集合字段
+func (v *Validator) validateCollection(fieldValue reflect.Value, info fieldInfo, key string, st *validateState) {
+	if info.isPtr {
+		if fieldValue.IsNil() {
+			if info.collectionTag != "" {
+				v.validateVar(fieldValue, info.collectionTag, info, key, st)
+			}
+			return
+		}
+		fieldValue = fieldValue.Elem()
+	}
+
+	if info.omitEmpty && fieldValue.Len() == 0 {
+		return
+	}
+
+	if info.collectionTag != "" {
+		v.validateVar(fieldValue, info.collectionTag, info, key, st)
+	}
+
+	v.diveInto(fieldValue, info.elemTag, info.hasDive, info, key, st)
+}
+
+// diveInto 遍历集合元素
+func (v *Validator) diveInto(collection reflect.Value, tag string, hasDive bool, info fieldInfo, key string, st *validateState) {
+	switch collection.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < collection.Len(); i++ {
+			v.validateElem(collection.Index(i), tag, hasDive, info, fmt.Sprintf("%s[%d]", key, i), st)
+		}
+	case reflect.Map:
+		keys := collection.MapKeys()
+		// 保证错误输出顺序稳定
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, k := range keys {
+			v.validateElem(collection.MapIndex(k), tag, hasDive, info, fmt.Sprintf("%s[%v]", key, k.Interface()), st)
+		}
+	}
+}
+
+// validateElem 校验单个元素
+func (v *Validator) validateElem(elem reflect.Value, tag string, hasDive bool, info fieldInfo, key string, st *validateState) {
+	if elem.Kind() == reflect.Interface && !elem.IsNil() {
+		elem = elem.Elem()
+	}
+
+	var (
+		inner     string
+		innerDive bool
+	)
+	if hasDive && tag != "" {
+		var outer string
+		outer, inner, innerDive = splitDive(tag)
+		if outer != "" {
+			v.validateVar(elem, outer, info, key, st)
+		}
+	}
+
+	target := elem
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			return
+		}
+		target = target.Elem()
+	}
+
+	switch {
+	case target.Kind() == reflect.Struct && target.Type() != timeType:
+		if elem.Kind() == reflect.Ptr {
+			v.validateRecursive(elem.Interface(), key, st)
+			return
+		}
+		if !elem.CanAddr() {
+			// Map 元素不可寻址，复制后校验
+			ptr := reflect.New(elem.Type())
+			ptr.Elem().Set(elem)
+			elem = ptr.Elem()
+		}
+		v.validateRecursive(elem.Addr().Interface(), key, st)
+	case isCollectionType(target.Type()):
+		v.diveInto(target, inner, innerDive, info, key, st)
+	}
+}
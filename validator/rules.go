@@ -0,0 +1,183 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	ulidv2 "github.com/oklog/ulid/v2"
+)
+
+/* ========================================================================
+ * Validation Rules - 自定义规则注册与内置规则
+ * ========================================================================
+ * 职责: 提供简化的规则注册入口，以及常用业务规则
+ * 内置规则:
+ *   - ulid:      合法的 ULID 字符串或 ulid.ULID
+ *   - mobile_cn: 中国大陆手机号
+ *   - id_card:   中国居民身份证号（18 位，含校验位）
+ *   - tenant_id: 非零 ULID 租户 ID
+ *   - enum:      枚举值，如 enum=active disabled
+ * 使用示例:
+ *     validator.RegisterRule("even", func(value any, _ string) bool {
+ *         n, ok := value.(int)
+ *         return ok && n%2 == 0
+ *     })
+ * ======================================================================== */
+
+// RuleFunc 简化的规则函数
+// value: 字段值；param: 规则参数（如 enum=a b 中的 "a b"）
+type RuleFunc func(value any, param string) bool
+
+var (
+	globalRulesMu sync.RWMutex
+	globalRules   = make(map[string]RuleFunc)
+)
+
+// RegisterRule 注册全局规则，对之后通过 New 创建的验证器生效
+func RegisterRule(name string, fn RuleFunc) {
+	globalRulesMu.Lock()
+	defer globalRulesMu.Unlock()
+	globalRules[name] = fn
+}
+
+// RegisterRule 在当前验证器上注册规则
+func (v *Validator) RegisterRule(name string, fn RuleFunc) error {
+	return v.validator.RegisterValidation(name, wrapRule(fn))
+}
+
+// wrapRule 将 RuleFunc 适配为 validator.Func
+func wrapRule(fn RuleFunc) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return fn(fl.Field().Interface(), fl.Param())
+	}
+}
+
+// builtinRules 内置规则
+var builtinRules = map[string]RuleFunc{
+	"ulid":      validateULID,
+	"mobile_cn": validateMobileCN,
+	"id_card":   validateIDCard,
+	"tenant_id": validateTenantID,
+	"enum":      validateEnum,
+}
+
+// registerRules 注册内置规则与全局规则
+func (v *Validator) registerRules() {
+	for name, fn := range builtinRules {
+		_ = v.RegisterRule(name, fn)
+	}
+
+	globalRulesMu.RLock()
+	defer globalRulesMu.RUnlock()
+	for name, fn := range globalRules {
+		_ = v.RegisterRule(name, fn)
+	}
+}
+
+// =============================================================================
+// 内置规则实现
+// =============================================================================
+
+var mobileCNRegex = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// validateULID 校验 ULID（空字符串交由 required 处理）
+func validateULID(value any, _ string) bool {
+	switch val := value.(type) {
+	case string:
+		if val == "" {
+			return true
+		}
+		_, err := ulidv2.ParseStrict(val)
+		return err == nil
+	case ulidv2.ULID:
+		return true
+	case fmt.Stringer:
+		_, err := ulidv2.ParseStrict(val.String())
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// validateTenantID 校验租户 ID：必须是非零 ULID
+func validateTenantID(value any, _ string) bool {
+	switch val := value.(type) {
+	case string:
+		id, err := ulidv2.ParseStrict(val)
+		return err == nil && id.Compare(ulidv2.ULID{}) != 0
+	case ulidv2.ULID:
+		return val.Compare(ulidv2.ULID{}) != 0
+	case *ulidv2.ULID:
+		return val != nil && val.Compare(ulidv2.ULID{}) != 0
+	default:
+		return false
+	}
+}
+
+// validateMobileCN 校验中国大陆手机号
+func validateMobileCN(value any, _ string) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	return s == "" || mobileCNRegex.MatchString(s)
+}
+
+var (
+	idCardWeights = []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	idCardChecks  = "10X98765432"
+)
+
+// validateIDCard 校验 18 位居民身份证号（出生日期 + 校验位）
+func validateIDCard(value any, _ string) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+	if s == "" {
+		return true
+	}
+	if len(s) != 18 {
+		return false
+	}
+
+	s = strings.ToUpper(s)
+	sum := 0
+	for i := 0; i < 17; i++ {
+		c := s[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		sum += int(c-'0') * idCardWeights[i]
+	}
+	if s[17] != idCardChecks[sum%11] {
+		return false
+	}
+
+	birth, err := time.Parse("20060102", s[6:14])
+	if err != nil || birth.After(time.Now()) {
+		return false
+	}
+	return true
+}
+
+// validateEnum 校验枚举值，参数以空格分隔
+func validateEnum(value any, param string) bool {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() == reflect.String && rv.String() == "" {
+		return true
+	}
+
+	s := fmt.Sprint(value)
+	for _, opt := range strings.Fields(param) {
+		if opt == s {
+			return true
+		}
+	}
+	return false
+}
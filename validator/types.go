@@ -18,6 +18,8 @@ const (
 	ruleSeparator = "|"
 	// keyValueSep 键值分隔符，用于分隔规则名和错误消息
 	keyValueSep = ":"
+	// i18nKeyPrefix 错误消息引用 i18n 目录 key 的前缀，如 "required:@user.email.required"
+	i18nKeyPrefix = "@"
 )
 
 // ValidationError 按字段分组的验证错误
@@ -1,11 +1,14 @@
 package validator
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
 	"sync"
 
+	"github.com/aisgo/ais-go-pkg/i18n"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -14,8 +17,11 @@ import (
  * ========================================================================
  * 职责: 提供带自定义错误消息的结构体验证
  * 特性:
- *   - 支持 error_msg 标签定义自定义错误消息
+ *   - 支持 error_msg 标签定义自定义错误消息（"@key" 引用 i18n 目录）
+ *   - ValidateCtx + WithLocale 按语言渲染错误消息
  *   - 支持嵌套结构体验证
+ *   - 支持切片 / 数组 / Map 元素校验（dive 语义，错误键如 Items[2].Name）
+ *   - 内置业务规则（ulid/mobile_cn/id_card/tenant_id/enum）与跨字段规则
  *   - 类型缓存优化性能
  * 使用示例:
  *     type UserRequest struct {
@@ -43,11 +49,13 @@ type visitKey struct {
 
 // New 创建新的验证器
 func New() *Validator {
-	return &Validator{
+	v := &Validator{
 		validator:     validator.New(),
 		typeCache:     newTypeCache(),
 		errorMsgCache: make(map[string]map[string]string),
 	}
+	v.registerRules()
+	return v
 }
 
 // RegisterValidation 注册自定义验证规则
@@ -55,9 +63,37 @@ func (v *Validator) RegisterValidation(tag string, fn validator.Func, callValida
 	return v.validator.RegisterValidation(tag, fn, callValidationEvenIfNull...)
 }
 
+// validateState 单次校验的状态
+type validateState struct {
+	errs    *ValidationError
+	visited map[visitKey]bool
+	// locale 目标语言；localized 为 true 时默认消息也按目录翻译
+	locale    string
+	localized bool
+}
+
+// WithLocale 在 ctx 中设置校验消息语言（配合 ValidateCtx 使用）
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return i18n.WithLocale(ctx, locale)
+}
+
 // Validate 验证结构体
 // 返回 ValidationError 类型，包含按字段分组的错误消息
 func (v *Validator) Validate(s any) error {
+	return v.validate(s, i18n.Default.Fallback(), false)
+}
+
+// ValidateCtx 验证结构体，并使用 ctx 中的语言渲染错误消息
+// ctx 未设置语言时与 Validate 行为一致
+func (v *Validator) ValidateCtx(ctx context.Context, s any) error {
+	locale, ok := i18n.LocaleFromContext(ctx)
+	if !ok {
+		return v.Validate(s)
+	}
+	return v.validate(s, locale, true)
+}
+
+func (v *Validator) validate(s any, locale string, localized bool) error {
 	if s == nil {
 		return nil
 	}
@@ -70,18 +106,22 @@ func (v *Validator) Validate(s any) error {
 		s = ptr.Interface()
 	}
 
-	validationErrors := &ValidationError{Errors: make(map[string][]string)}
-	visited := make(map[visitKey]bool)
-	v.validateRecursive(s, "", validationErrors, visited)
+	st := &validateState{
+		errs:      &ValidationError{Errors: make(map[string][]string)},
+		visited:   make(map[visitKey]bool),
+		locale:    locale,
+		localized: localized,
+	}
+	v.validateRecursive(s, "", st)
 
-	if validationErrors.HasErrors() {
-		return validationErrors
+	if st.errs.HasErrors() {
+		return st.errs
 	}
 	return nil
 }
 
 // validateRecursive 递归验证结构体
-func (v *Validator) validateRecursive(s any, prefix string, validationErrors *ValidationError, visited map[visitKey]bool) {
+func (v *Validator) validateRecursive(s any, prefix string, st *validateState) {
 	value := reflect.ValueOf(s)
 
 	// 如果是指针，记录并检查是否已访问
@@ -90,10 +130,10 @@ func (v *Validator) validateRecursive(s any, prefix string, validationErrors *Va
 			return
 		}
 		key := visitKey{typ: value.Type(), ptr: value.Pointer()}
-		if visited[key] {
+		if st.visited[key] {
 			return // 防止循环引用
 		}
-		visited[key] = true
+		st.visited[key] = true
 		value = value.Elem()
 	}
 
@@ -120,44 +160,92 @@ func (v *Validator) validateRecursive(s any, prefix string, validationErrors *Va
 					continue // 跳过 nil 指针
 				}
 				// 注意：这里不需要手动 Elem()，因为下一层 validateRecursive 会处理指针
-				v.validateRecursive(fieldValue.Interface(), fullFieldName, validationErrors, visited)
+				v.validateRecursive(fieldValue.Interface(), fullFieldName, st)
 			} else {
 				// 非指针结构体，直接递归
-				v.validateRecursive(fieldValue.Addr().Interface(), fullFieldName, validationErrors, visited)
+				v.validateRecursive(fieldValue.Addr().Interface(), fullFieldName, st)
 			}
 			continue
 		}
 
+		// 跨字段规则
+		if len(fieldInfo.crossRules) > 0 {
+			required, requiredTag, handled := requiredState(value, fieldInfo.crossRules)
+			if fieldValue.IsZero() && (handled || fieldInfo.omitEmpty) {
+				// 空值：仅在 required_* 触发时报错，其余规则跳过
+				if required {
+					st.errs.Add(fullFieldName, v.ruleMessage(fieldInfo, fullFieldName, requiredTag, "", st))
+				}
+				continue
+			}
+			for _, rule := range checkCompareRules(value, fieldValue, fieldInfo.crossRules) {
+				st.errs.Add(fullFieldName, v.ruleMessage(fieldInfo, fullFieldName, rule.tag, rule.param, st))
+			}
+		}
+
+		// 切片 / 数组 / Map：集合级规则 + dive 元素级规则
+		if fieldInfo.isCollection {
+			v.validateCollection(fieldValue, fieldInfo, fullFieldName, st)
+			continue
+		}
+
 		// 跳过没有验证标签的字段
 		if fieldInfo.validateTag == "" {
 			continue
 		}
 
 		// 验证当前字段
-		err := v.validator.Var(fieldValue.Interface(), fieldInfo.validateTag)
-		if err == nil {
-			continue
-		}
+		v.validateVar(fieldValue, fieldInfo.validateTag, fieldInfo, fullFieldName, st)
+	}
+}
 
-		// 处理验证错误
-		validationErrs, ok := err.(validator.ValidationErrors)
-		if !ok {
-			// 如果不是 ValidationErrors 类型，使用原始错误消息
-			validationErrors.Add(fullFieldName, err.Error())
-			continue
+// validateVar 使用 validator.Var 校验单个值，并按 error_msg 生成错误消息
+func (v *Validator) validateVar(value reflect.Value, tag string, info fieldInfo, key string, st *validateState) {
+	err := v.validator.Var(value.Interface(), tag)
+	if err == nil {
+		return
+	}
+
+	// 处理验证错误
+	validationErrs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		// 如果不是 ValidationErrors 类型，使用原始错误消息
+		st.errs.Add(key, err.Error())
+		return
+	}
+
+	// 处理每个验证错误
+	for _, fieldErr := range validationErrs {
+		st.errs.Add(key, v.message(info, key, fieldErr.Tag(), fieldErr.Param(), fieldErr.Error(), st))
+	}
+}
+
+// ruleMessage 获取跨字段规则错误消息
+func (v *Validator) ruleMessage(info fieldInfo, fullFieldName, tag, param string, st *validateState) string {
+	return v.message(info, fullFieldName, tag, param, defaultRuleMessage(fullFieldName, tag), st)
+}
+
+// message 生成错误消息
+// 优先级: error_msg（"@key" 形式按目录翻译）> 本地化默认消息 > validator 原始消息
+func (v *Validator) message(info fieldInfo, key, tag, param, fallback string, st *validateState) string {
+	params := map[string]any{"field": key, "tag": tag, "param": param}
+
+	if msg := v.getCachedErrorMessage(info.errorMsgTag, tag); msg != "" {
+		if msgKey, ok := strings.CutPrefix(msg, i18nKeyPrefix); ok {
+			return i18n.Default.Message(st.locale, msgKey, params)
 		}
+		return msg
+	}
 
-		// 处理每个验证错误
-		for _, fieldErr := range validationErrs {
-			errorTag := fieldErr.Tag()
-			customMsg := v.getCachedErrorMessage(fieldInfo.errorMsgTag, errorTag)
-			message := customMsg
-			if customMsg == "" {
-				message = fieldErr.Error()
-			}
-			validationErrors.Add(fullFieldName, message)
+	if st.localized {
+		if msg, ok := i18n.Default.Translate(st.locale, "validator."+tag, params); ok {
+			return msg
+		}
+		if msg, ok := i18n.Default.Translate(st.locale, "validator.default", params); ok {
+			return msg
 		}
 	}
+	return fallback
 }
 
 // getCachedErrorMessage 获取缓存的错误消息
@@ -1,8 +1,14 @@
 package validator
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/aisgo/ais-go-pkg/i18n"
+
+	ulidv2 "github.com/oklog/ulid/v2"
 )
 
 func TestValidate_AllowsStructValueInput(t *testing.T) {
@@ -22,3 +28,219 @@ func TestValidate_AllowsStructValueInput(t *testing.T) {
 		t.Fatalf("expected validation error, got nil")
 	}
 }
+
+func TestValidate_BuiltinRules(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		ID       string      `validate:"required,ulid"`
+		TenantID ulidv2.ULID `validate:"tenant_id"`
+		Mobile   string      `validate:"mobile_cn" error_msg:"mobile_cn:手机号格式错误"`
+		IDCard   string      `validate:"omitempty,id_card"`
+		Status   string      `validate:"required,enum=active disabled"`
+	}
+
+	v := New()
+
+	valid := Req{
+		ID:       ulidv2.Make().String(),
+		TenantID: ulidv2.Make(),
+		Mobile:   "13800138000",
+		IDCard:   "11010519491231002X",
+		Status:   "active",
+	}
+	if err := v.Validate(&valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invalid := Req{
+		ID:     "not-a-ulid",
+		Mobile: "12345",
+		IDCard: "110105194912310021",
+		Status: "deleted",
+	}
+	err := v.Validate(&invalid)
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected ValidationError, got: %v", err)
+	}
+	for _, field := range []string{"ID", "TenantID", "Mobile", "IDCard", "Status"} {
+		if len(ve.Get(field)) == 0 {
+			t.Fatalf("expected error for %s, got: %v", field, ve.Errors)
+		}
+	}
+	if ve.Get("Mobile")[0] != "手机号格式错误" {
+		t.Fatalf("unexpected custom message: %v", ve.Get("Mobile"))
+	}
+}
+
+func TestValidate_RegisterRule(t *testing.T) {
+	RegisterRule("even", func(value any, _ string) bool {
+		n, ok := value.(int)
+		return ok && n%2 == 0
+	})
+
+	type Req struct {
+		N int `validate:"even"`
+	}
+
+	v := New()
+	if err := v.Validate(&Req{N: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := v.Validate(&Req{N: 3}); err == nil {
+		t.Fatalf("expected validation error")
+	}
+
+	if err := v.RegisterRule("positive", func(value any, _ string) bool {
+		n, ok := value.(int)
+		return ok && n > 0
+	}); err != nil {
+		t.Fatalf("register rule: %v", err)
+	}
+	type Req2 struct {
+		N int `validate:"positive"`
+	}
+	if err := v.Validate(&Req2{N: -1}); err == nil {
+		t.Fatalf("expected validation error")
+	}
+}
+
+func TestValidate_CrossFieldRules(t *testing.T) {
+	t.Parallel()
+
+	type Req struct {
+		StartAt time.Time
+		EndAt   time.Time `validate:"gtfield=StartAt" error_msg:"gtfield:结束时间必须晚于开始时间"`
+		Min     int
+		Max     int    `validate:"gtefield=Min"`
+		Phone   string `validate:"omitempty,mobile_cn"`
+		Code    string `validate:"required_with=Phone,len=6"`
+	}
+
+	v := New()
+	now := time.Now()
+
+	if err := v.Validate(&Req{StartAt: now, EndAt: now.Add(time.Hour), Min: 1, Max: 1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := v.Validate(&Req{StartAt: now, EndAt: now, Min: 2, Max: 1, Phone: "13800138000"})
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected ValidationError, got: %v", err)
+	}
+	if got := ve.Get("EndAt"); len(got) != 1 || got[0] != "结束时间必须晚于开始时间" {
+		t.Fatalf("unexpected EndAt errors: %v", got)
+	}
+	if len(ve.Get("Max")) == 0 {
+		t.Fatalf("expected Max error: %v", ve.Errors)
+	}
+	if len(ve.Get("Code")) == 0 {
+		t.Fatalf("expected Code required_with error: %v", ve.Errors)
+	}
+
+	if err := v.Validate(&Req{StartAt: now, EndAt: now.Add(time.Second), Phone: "13800138000", Code: "12"}); err == nil {
+		t.Fatalf("expected len error on Code")
+	}
+}
+
+func TestValidate_DiveCollections(t *testing.T) {
+	t.Parallel()
+
+	type Item struct {
+		Name string `validate:"required" error_msg:"required:名称必填"`
+	}
+	type Req struct {
+		Items   []Item            `validate:"required,min=1"`
+		Ptrs    []*Item           `validate:"omitempty"`
+		Tags    []string          `validate:"dive,required,max=3"`
+		Attrs   map[string]string `validate:"dive,required"`
+		Matrix  [][]int           `validate:"dive,dive,gt=0"`
+		ByKey   map[string]Item
+		Raw     []byte `validate:"omitempty,max=4"`
+		Ignored []string
+	}
+
+	v := New()
+
+	valid := Req{
+		Items:  []Item{{Name: "a"}},
+		Tags:   []string{"x"},
+		Attrs:  map[string]string{"k": "v"},
+		Matrix: [][]int{{1, 2}},
+		ByKey:  map[string]Item{"a": {Name: "a"}},
+	}
+	if err := v.Validate(&valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	invalid := Req{
+		Items:  []Item{{Name: "a"}, {}, {}},
+		Ptrs:   []*Item{nil, {}},
+		Tags:   []string{"ok", "", "toolong"},
+		Attrs:  map[string]string{"k": ""},
+		Matrix: [][]int{{1}, {1, 0}},
+		ByKey:  map[string]Item{"bad": {}},
+	}
+	err := v.Validate(&invalid)
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected ValidationError, got: %v", err)
+	}
+
+	for _, key := range []string{
+		"Items[1].Name", "Items[2].Name", "Ptrs[1].Name",
+		"Tags[1]", "Tags[2]", "Attrs[k]", "Matrix[1][1]", "ByKey[bad].Name",
+	} {
+		if len(ve.Get(key)) == 0 {
+			t.Fatalf("expected error for %s, got: %v", key, ve.Errors)
+		}
+	}
+	if ve.Get("Items[1].Name")[0] != "名称必填" {
+		t.Fatalf("unexpected message: %v", ve.Get("Items[1].Name"))
+	}
+	if len(ve.Get("Tags[0]")) != 0 || len(ve.Get("Items[0].Name")) != 0 {
+		t.Fatalf("unexpected errors for valid elements: %v", ve.Errors)
+	}
+
+	if err := v.Validate(&Req{}); err == nil || len(err.(*ValidationError).Get("Items")) == 0 {
+		t.Fatalf("expected collection level error on Items")
+	}
+}
+
+func TestValidateCtx_Localized(t *testing.T) {
+	t.Parallel()
+
+	i18n.Default.AddCatalog("en", map[string]string{"test.name.required": "name is mandatory"})
+	i18n.Default.AddCatalog("zh", map[string]string{"test.name.required": "名称不能为空"})
+
+	type Req struct {
+		Name  string `validate:"required" error_msg:"required:@test.name.required"`
+		Email string `validate:"required,email"`
+	}
+
+	v := New()
+
+	err := v.ValidateCtx(WithLocale(context.Background(), "en"), &Req{Email: "x"})
+	ve, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("expected ValidationError, got: %v", err)
+	}
+	if got := ve.Get("Name"); len(got) != 1 || got[0] != "name is mandatory" {
+		t.Fatalf("unexpected Name message: %v", got)
+	}
+	if got := ve.Get("Email"); len(got) != 1 || got[0] != "Email must be a valid email address" {
+		t.Fatalf("unexpected Email message: %v", got)
+	}
+
+	// 未设置语言：error_msg key 按回退语言翻译，默认消息保持 validator 原文
+	err = v.Validate(&Req{Email: "x"})
+	ve = err.(*ValidationError)
+	if got := ve.Get("Name"); len(got) != 1 || got[0] != "名称不能为空" {
+		t.Fatalf("unexpected Name message: %v", got)
+	}
+	if got := ve.Get("Email"); len(got) != 1 || !strings.Contains(got[0], "'email' tag") {
+		t.Fatalf("unexpected Email message: %v", got)
+	}
+}
@@ -0,0 +1,72 @@
+package worker
+
+import "time"
+
+/* ========================================================================
+ * Worker Config - 后台任务池配置
+ * ========================================================================
+ * 职责: 定义任务池的并发、队列、重试与关停配置
+ * ======================================================================== */
+
+// Config 任务池配置
+type Config struct {
+	// Name 任务池名称（用于日志与指标标签）
+	Name string `yaml:"name"`
+	// Workers 并发 worker 数量
+	Workers int `yaml:"workers"`
+	// QueueSize 内存队列容量（仅对默认内存队列生效）
+	QueueSize int `yaml:"queue_size"`
+	// MaxRetries 任务默认最大重试次数（不含首次执行）
+	MaxRetries int `yaml:"max_retries"`
+	// RetryBackoff 首次重试等待时间，后续按指数递增
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	// MaxRetryBackoff 重试等待时间上限
+	MaxRetryBackoff time.Duration `yaml:"max_retry_backoff"`
+	// DrainTimeout 关停时等待队列排空的最长时间
+	DrainTimeout time.Duration `yaml:"drain_timeout"`
+}
+
+// DefaultConfig 返回默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Name:            "default",
+		Workers:         8,
+		QueueSize:       1024,
+		MaxRetries:      3,
+		RetryBackoff:    100 * time.Millisecond,
+		MaxRetryBackoff: 10 * time.Second,
+		DrainTimeout:    30 * time.Second,
+	}
+}
+
+// withDefaults 填充未设置的配置项
+func (c *Config) withDefaults() *Config {
+	def := DefaultConfig()
+	if c == nil {
+		return def
+	}
+
+	cfg := *c
+	if cfg.Name == "" {
+		cfg.Name = def.Name
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = def.Workers
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = def.QueueSize
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = def.RetryBackoff
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = def.MaxRetryBackoff
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = def.DrainTimeout
+	}
+	return &cfg
+}
@@ -0,0 +1,23 @@
+package worker
+
+import "github.com/aisgo/ais-go-pkg/metrics"
+
+/* ========================================================================
+ * Worker Metrics - 任务池指标
+ * ========================================================================
+ * 职责: 暴露队列深度、执行中 worker 数量与任务处理结果
+ * ======================================================================== */
+
+var (
+	// queueDepth 队列深度
+	queueDepth = metrics.NewGauge("app", "worker", "queue_depth",
+		"Number of tasks waiting in the worker queue", []string{"pool"})
+
+	// inflightWorkers 执行中的 worker 数量
+	inflightWorkers = metrics.NewGauge("app", "worker", "inflight",
+		"Number of workers currently executing a task", []string{"pool"})
+
+	// taskTotal 任务处理结果
+	taskTotal = metrics.NewCounter("app", "worker", "task_total",
+		"Total number of processed worker tasks", []string{"pool", "type", "status"}) // status: success, retry, failed, panic, abandoned
+)
@@ -0,0 +1,17 @@
+package worker
+
+import "go.uber.org/fx"
+
+/* ========================================================================
+ * Worker FX Module - 后台任务池 FX 模块
+ * ========================================================================
+ * 职责: 提供 Pool 依赖注入支持
+ * 说明: 未提供 *Config 时使用 DefaultConfig，未提供 Queue 时使用内存队列
+ * ======================================================================== */
+
+// Module FX 模块
+var Module = fx.Module("worker",
+	fx.Provide(
+		NewPool,
+	),
+)
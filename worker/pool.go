@@ -0,0 +1,453 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/ctxutil"
+	"github.com/aisgo/ais-go-pkg/logger"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+/* ========================================================================
+ * Worker Pool - 有界后台任务池
+ * ========================================================================
+ * 职责: 以固定数量的 worker 并发消费任务队列
+ * 特性:
+ *   - 有界并发，队列可替换（内存 / Redis Stream / MQ）
+ *   - 失败指数退避重试，panic 自动恢复
+ *   - 关停时停止接收新任务并排空队列，排空超时被取消的任务不确认（Nacker 队列调用 Nack），重启后重投
+ *   - Prometheus 指标: 队列深度、执行中 worker 数量
+ * 使用示例:
+ *   pool.RegisterFunc("email.send", func(ctx context.Context, t *worker.Task) error {
+ *       var req SendEmailReq
+ *       if err := t.Decode(&req); err != nil { return err }
+ *       return sendEmail(ctx, req)
+ *   })
+ *   task, _ := worker.NewTask("email.send", req)
+ *   _ = pool.Submit(ctx, task)
+ * ======================================================================== */
+
+const funcTaskType = "func"
+
+// depthReportInterval 队列深度指标刷新间隔
+const depthReportInterval = time.Second
+
+// Pool 后台任务池
+type Pool struct {
+	cfg   *Config
+	log   *logger.Logger
+	queue Queue
+	clock clock.Clock
+
+	handlers map[string]Handler
+	mu       sync.RWMutex
+
+	// runCtx 任务执行上下文，排空超时后取消
+	runCtx    context.Context
+	runCancel context.CancelFunc
+	// popCtx 取任务上下文，强制停止时取消
+	popCtx    context.Context
+	popCancel context.CancelFunc
+
+	wg        sync.WaitGroup
+	inflight  atomic.Int64
+	started   atomic.Bool
+	closed    atomic.Bool
+	stopOnce  sync.Once
+	stopErr   error
+	reporterC chan struct{}
+}
+
+// PoolParams 依赖参数
+type PoolParams struct {
+	fx.In
+
+	Lc     fx.Lifecycle
+	Config *Config `optional:"true"`
+	Queue  Queue   `optional:"true"`
+	Logger *logger.Logger
+	Clock  clock.Clock `optional:"true"` // 重试退避与指标刷新计时，默认真实时钟
+}
+
+// New 创建任务池（不绑定生命周期，需手动 Start / Stop）
+// queue 为 nil 时使用容量为 QueueSize 的内存队列
+func New(cfg *Config, queue Queue, log *logger.Logger) *Pool {
+	cfg = cfg.withDefaults()
+	if queue == nil {
+		queue = NewMemoryQueue(cfg.QueueSize)
+	}
+	if log == nil {
+		log = logger.NewNop()
+	}
+
+	runCtx, runCancel := context.WithCancel(context.Background())
+	// 阻塞取任务不设 deadline，跳过 ctxutil 检查
+	popCtx, popCancel := context.WithCancel(ctxutil.WithoutDeadlineCheck(context.Background()))
+
+	return &Pool{
+		cfg:       cfg,
+		log:       log,
+		queue:     queue,
+		clock:     clock.Real(),
+		handlers:  make(map[string]Handler),
+		runCtx:    runCtx,
+		runCancel: runCancel,
+		popCtx:    popCtx,
+		popCancel: popCancel,
+		reporterC: make(chan struct{}),
+	}
+}
+
+// NewPool 创建任务池并注册到 FX 生命周期
+func NewPool(p PoolParams) *Pool {
+	pool := New(p.Config, p.Queue, p.Logger).WithClock(p.Clock)
+
+	p.Lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			pool.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return pool.Stop(ctx)
+		},
+	})
+
+	return pool
+}
+
+// WithClock 设置重试退避与指标刷新使用的时钟（nil 表示真实时钟），需在 Start 之前调用
+func (p *Pool) WithClock(clk clock.Clock) *Pool {
+	p.clock = clock.OrReal(clk)
+	return p
+}
+
+// Register 注册任务处理器
+func (p *Pool) Register(taskType string, h Handler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.handlers[taskType] = h
+}
+
+// RegisterFunc 注册函数式任务处理器
+func (p *Pool) RegisterFunc(taskType string, fn HandlerFunc) {
+	p.Register(taskType, fn)
+}
+
+// Submit 提交任务，队列满时阻塞直到 ctx 结束
+func (p *Pool) Submit(ctx context.Context, task *Task) error {
+	if p.closed.Load() {
+		return ErrPoolClosed
+	}
+	if err := p.queue.Push(ctx, task); err != nil {
+		return p.mapQueueErr(err)
+	}
+	return nil
+}
+
+// TrySubmit 非阻塞提交任务，队列满时返回 ErrQueueFull
+func (p *Pool) TrySubmit(task *Task) error {
+	if p.closed.Load() {
+		return ErrPoolClosed
+	}
+	if err := p.queue.TryPush(task); err != nil {
+		return p.mapQueueErr(err)
+	}
+	return nil
+}
+
+// SubmitFunc 提交闭包任务（仅支持内存队列）
+func (p *Pool) SubmitFunc(ctx context.Context, fn func(ctx context.Context) error) error {
+	task := &Task{
+		ID:         ulidv2.Make().String(),
+		Type:       funcTaskType,
+		MaxRetries: -1,
+		CreatedAt:  p.clock.Now(),
+		fn: func(ctx context.Context, _ *Task) error {
+			return fn(ctx)
+		},
+	}
+	return p.Submit(ctx, task)
+}
+
+// Start 启动 worker
+func (p *Pool) Start() {
+	if !p.started.CompareAndSwap(false, true) {
+		return
+	}
+
+	for i := 0; i < p.cfg.Workers; i++ {
+		p.wg.Add(1)
+		go p.loop()
+	}
+	go p.reportDepth()
+
+	p.log.Info("Worker pool started",
+		zap.String("pool", p.cfg.Name),
+		zap.Int("workers", p.cfg.Workers),
+	)
+}
+
+// Stop 停止接收新任务并等待队列排空
+// 超过 DrainTimeout 或 ctx 结束后取消执行中的任务
+func (p *Pool) Stop(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		p.stopErr = p.stop(ctx)
+	})
+	return p.stopErr
+}
+
+func (p *Pool) stop(ctx context.Context) error {
+	p.closed.Store(true)
+	_ = p.queue.Close()
+	defer close(p.reporterC)
+
+	if !p.started.Load() {
+		p.runCancel()
+		p.popCancel()
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	drainCtx, cancel := clock.WithTimeout(ctx, p.clock, p.cfg.DrainTimeout)
+	defer cancel()
+
+	select {
+	case <-done:
+		p.runCancel()
+		p.popCancel()
+		p.log.Info("Worker pool drained", zap.String("pool", p.cfg.Name))
+		return nil
+	case <-drainCtx.Done():
+	}
+
+	// 排空超时，取消执行中的任务
+	p.log.Warn("Worker pool drain timeout, cancelling in-flight tasks",
+		zap.String("pool", p.cfg.Name),
+		zap.Int64("inflight", p.inflight.Load()),
+	)
+	p.popCancel()
+	p.runCancel()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("worker pool %s stop: %w", p.cfg.Name, ctx.Err())
+	}
+}
+
+// Inflight 当前执行中的任务数量
+func (p *Pool) Inflight() int64 {
+	return p.inflight.Load()
+}
+
+// loop worker 主循环
+func (p *Pool) loop() {
+	defer p.wg.Done()
+
+	for {
+		task, err := p.queue.Pop(p.popCtx)
+		if err != nil {
+			if errors.Is(err, ErrQueueClosed) || p.popCtx.Err() != nil {
+				return
+			}
+			p.log.Warn("Failed to pop worker task",
+				zap.String("pool", p.cfg.Name),
+				zap.Error(err),
+			)
+			if !p.sleep(p.popCtx, p.cfg.RetryBackoff) {
+				return
+			}
+			continue
+		}
+
+		p.process(task)
+	}
+}
+
+// process 执行任务（含重试），最终结果确认到队列
+func (p *Pool) process(task *Task) {
+	gauge := inflightWorkers.WithLabelValues(p.cfg.Name)
+	p.inflight.Add(1)
+	gauge.Inc()
+	defer func() {
+		p.inflight.Add(-1)
+		gauge.Dec()
+	}()
+
+	maxRetries := task.MaxRetries
+	if maxRetries < 0 {
+		maxRetries = p.cfg.MaxRetries
+	}
+
+	for {
+		task.Attempts++
+		err := p.execute(task)
+		if err == nil {
+			taskTotal.WithLabelValues(p.cfg.Name, task.Type, "success").Inc()
+			break
+		}
+
+		if p.runCtx.Err() != nil {
+			// 排空超时取消的任务未完成，不确认以便重启后重投
+			p.abandon(task, err)
+			return
+		}
+
+		retryable := !errors.Is(err, ErrNoHandler)
+		if retryable && task.Attempts <= maxRetries {
+			taskTotal.WithLabelValues(p.cfg.Name, task.Type, "retry").Inc()
+			p.log.Warn("Worker task failed, retrying",
+				zap.String("pool", p.cfg.Name),
+				zap.String("task_id", task.ID),
+				zap.String("type", task.Type),
+				zap.Int("attempt", task.Attempts),
+				zap.Error(err),
+			)
+			if p.sleep(p.runCtx, p.backoff(task.Attempts)) {
+				continue
+			}
+			// 退避期间排空超时，同样留待重投
+			p.abandon(task, err)
+			return
+		}
+
+		taskTotal.WithLabelValues(p.cfg.Name, task.Type, "failed").Inc()
+		p.log.Error("Worker task failed",
+			zap.String("pool", p.cfg.Name),
+			zap.String("task_id", task.ID),
+			zap.String("type", task.Type),
+			zap.Int("attempts", task.Attempts),
+			zap.Error(err),
+		)
+		break
+	}
+
+	if err := p.queue.Ack(ctxutil.WithoutDeadlineCheck(context.Background()), task); err != nil {
+		p.log.Warn("Failed to ack worker task",
+			zap.String("pool", p.cfg.Name),
+			zap.String("task_id", task.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// abandon 放弃未完成的任务：不确认，队列实现 Nacker 时通知其重新投递
+func (p *Pool) abandon(task *Task, err error) {
+	taskTotal.WithLabelValues(p.cfg.Name, task.Type, "abandoned").Inc()
+	p.log.Warn("Worker task cancelled by drain timeout, leaving it for redelivery",
+		zap.String("pool", p.cfg.Name),
+		zap.String("task_id", task.ID),
+		zap.String("type", task.Type),
+		zap.Error(err),
+	)
+
+	nacker, ok := p.queue.(Nacker)
+	if !ok {
+		return
+	}
+	if err := nacker.Nack(ctxutil.WithoutDeadlineCheck(context.Background()), task); err != nil {
+		p.log.Warn("Failed to nack worker task",
+			zap.String("pool", p.cfg.Name),
+			zap.String("task_id", task.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// execute 执行一次任务，捕获 panic
+func (p *Pool) execute(task *Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			taskTotal.WithLabelValues(p.cfg.Name, task.Type, "panic").Inc()
+			p.log.Error("Worker task panic recovered",
+				zap.String("pool", p.cfg.Name),
+				zap.String("task_id", task.ID),
+				zap.Any("panic", r),
+				zap.Stack("stack"),
+			)
+			err = fmt.Errorf("worker task panic: %v", r)
+		}
+	}()
+
+	var h Handler
+	if task.fn != nil {
+		h = task.fn
+	} else {
+		p.mu.RLock()
+		h = p.handlers[task.Type]
+		p.mu.RUnlock()
+	}
+	if h == nil {
+		return fmt.Errorf("%w: %s", ErrNoHandler, task.Type)
+	}
+
+	return h.Handle(p.runCtx, task)
+}
+
+// backoff 计算第 attempt 次失败后的等待时间
+func (p *Pool) backoff(attempt int) time.Duration {
+	d := p.cfg.RetryBackoff
+	for i := 1; i < attempt; i++ {
+		d *= 2
+		if d >= p.cfg.MaxRetryBackoff {
+			return p.cfg.MaxRetryBackoff
+		}
+	}
+	return d
+}
+
+// sleep 可取消的等待，ctx 结束时返回 false
+func (p *Pool) sleep(ctx context.Context, d time.Duration) bool {
+	timer := p.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// reportDepth 定期刷新队列深度指标
+func (p *Pool) reportDepth() {
+	ticker := p.clock.NewTicker(depthReportInterval)
+	defer ticker.Stop()
+
+	gauge := queueDepth.WithLabelValues(p.cfg.Name)
+	for {
+		if n, err := p.queue.Len(ctxutil.WithoutDeadlineCheck(context.Background())); err == nil {
+			gauge.Set(float64(n))
+		}
+
+		select {
+		case <-ticker.C():
+		case <-p.reporterC:
+			gauge.Set(0)
+			return
+		}
+	}
+}
+
+// mapQueueErr 将队列关闭错误映射为任务池关闭错误
+func (p *Pool) mapQueueErr(err error) error {
+	if errors.Is(err, ErrQueueClosed) {
+		return ErrPoolClosed
+	}
+	return err
+}
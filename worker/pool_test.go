@@ -0,0 +1,340 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/clock"
+	"github.com/aisgo/ais-go-pkg/logger"
+	"github.com/aisgo/ais-go-pkg/mq"
+	"github.com/aisgo/ais-go-pkg/mq/mqtest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestPool(cfg *Config, queue Queue) *Pool {
+	return New(cfg, queue, logger.NewNop())
+}
+
+func TestPoolProcessesRegisteredTask(t *testing.T) {
+	pool := newTestPool(&Config{Name: "test-basic", Workers: 2}, nil)
+
+	type payload struct {
+		N int `json:"n"`
+	}
+
+	var sum atomic.Int64
+	pool.RegisterFunc("add", func(ctx context.Context, task *Task) error {
+		var p payload
+		if err := task.Decode(&p); err != nil {
+			return err
+		}
+		sum.Add(int64(p.N))
+		return nil
+	})
+	pool.Start()
+
+	for i := 1; i <= 10; i++ {
+		task, err := NewTask("add", payload{N: i})
+		if err != nil {
+			t.Fatalf("new task: %v", err)
+		}
+		if err := pool.Submit(context.Background(), task); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if got := sum.Load(); got != 55 {
+		t.Fatalf("unexpected sum: %d", got)
+	}
+}
+
+func TestPoolRetryAndPanicRecovery(t *testing.T) {
+	pool := newTestPool(&Config{
+		Name:         "test-retry",
+		Workers:      1,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	}, nil)
+	pool.Start()
+	defer pool.Stop(context.Background())
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	err := pool.SubmitFunc(context.Background(), func(ctx context.Context) error {
+		switch calls.Add(1) {
+		case 1:
+			panic("boom")
+		case 2:
+			return errors.New("transient")
+		default:
+			close(done)
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("task not retried, calls: %d", calls.Load())
+	}
+}
+
+func TestPoolRetryBackoffWithFakeClock(t *testing.T) {
+	fake := clock.NewFake(time.Time{})
+	pool := newTestPool(&Config{
+		Name:         "test-fake-clock",
+		Workers:      1,
+		MaxRetries:   1,
+		RetryBackoff: time.Hour,
+	}, nil).WithClock(fake)
+	pool.Start()
+	defer pool.Stop(context.Background())
+
+	var calls atomic.Int32
+	done := make(chan struct{})
+	err := pool.SubmitFunc(context.Background(), func(ctx context.Context) error {
+		if calls.Add(1) == 1 {
+			return errors.New("transient")
+		}
+		close(done)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	// 指标刷新 ticker + 重试退避定时器
+	fake.BlockUntil(2)
+	if calls.Load() != 1 {
+		t.Fatalf("expected retry to wait for backoff, calls: %d", calls.Load())
+	}
+	fake.Advance(time.Hour)
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("task not retried after advancing clock, calls: %d", calls.Load())
+	}
+}
+
+func TestPoolRejectsAfterStop(t *testing.T) {
+	pool := newTestPool(&Config{Name: "test-closed", Workers: 1}, nil)
+	pool.Start()
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+
+	err := pool.SubmitFunc(context.Background(), func(ctx context.Context) error { return nil })
+	if !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got: %v", err)
+	}
+}
+
+func TestPoolTrySubmitQueueFull(t *testing.T) {
+	pool := newTestPool(&Config{Name: "test-full", Workers: 1, QueueSize: 1}, nil)
+
+	if err := pool.SubmitFunc(context.Background(), func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	task, _ := NewTask("noop", nil)
+	if err := pool.TrySubmit(task); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("expected ErrQueueFull, got: %v", err)
+	}
+	_ = pool.Stop(context.Background())
+}
+
+func TestPoolDrainTimeoutCancelsTasks(t *testing.T) {
+	pool := newTestPool(&Config{
+		Name:         "test-drain",
+		Workers:      1,
+		DrainTimeout: 50 * time.Millisecond,
+	}, nil)
+	pool.Start()
+
+	started := make(chan struct{})
+	_ = pool.SubmitFunc(context.Background(), func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+
+	start := time.Now()
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("stop took too long: %v", elapsed)
+	}
+}
+
+func TestPoolDrainTimeoutLeavesTaskForRedelivery(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	newQueue := func() *RedisStreamQueue {
+		queue, err := NewRedisStreamQueue(context.Background(), rdb, RedisStreamConfig{
+			Stream:   "worker:drain",
+			Group:    "g1",
+			Consumer: "c1",
+			Block:    50 * time.Millisecond,
+		})
+		if err != nil {
+			t.Fatalf("new queue: %v", err)
+		}
+		return queue
+	}
+
+	// 首个进程: 任务阻塞至排空超时被取消
+	queue := newQueue()
+	pool := newTestPool(&Config{Name: "test-drain-redis", Workers: 1, DrainTimeout: 50 * time.Millisecond}, queue)
+	started := make(chan struct{})
+	pool.RegisterFunc("slow", func(ctx context.Context, task *Task) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	pool.Start()
+	task, _ := NewTask("slow", "payload")
+	if err := pool.Submit(context.Background(), task); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("task not started")
+	}
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if n, _ := queue.Len(context.Background()); n != 1 {
+		t.Fatalf("cancelled task should stay in stream, len: %d", n)
+	}
+
+	// 重启后重投
+	queue = newQueue()
+	pool = newTestPool(&Config{Name: "test-drain-redis", Workers: 1}, queue)
+	done := make(chan string, 1)
+	pool.RegisterFunc("slow", func(ctx context.Context, task *Task) error {
+		var s string
+		if err := task.Decode(&s); err != nil {
+			return err
+		}
+		done <- s
+		return nil
+	})
+	pool.Start()
+	select {
+	case got := <-done:
+		if got != "payload" {
+			t.Fatalf("unexpected payload: %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("task not redelivered after restart")
+	}
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if n, _ := queue.Len(context.Background()); n != 0 {
+		t.Fatalf("expected redelivered task acked, len: %d", n)
+	}
+}
+
+func TestMQQueueNackRetriesLater(t *testing.T) {
+	queue, err := NewMQQueue(mqtest.NewBroker().Producer(), nil, "worker.tasks")
+	if err != nil {
+		t.Fatalf("new queue: %v", err)
+	}
+	task, _ := NewTask("echo", "x")
+	data, _ := encodeTask(task)
+
+	results := make(chan mq.ConsumeResult, 1)
+	go func() {
+		res, _ := queue.handle(context.Background(), []*mq.ConsumedMessage{{Body: data}})
+		results <- res
+	}()
+	popped, err := queue.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("pop: %v", err)
+	}
+	if err := queue.Nack(context.Background(), popped); err != nil {
+		t.Fatalf("nack: %v", err)
+	}
+	if res := <-results; res != mq.ConsumeRetryLater {
+		t.Fatalf("consume result = %v, want retry later", res)
+	}
+}
+
+func TestRedisStreamQueue(t *testing.T) {
+	server, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("start miniredis: %v", err)
+	}
+	t.Cleanup(server.Close)
+
+	rdb := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = rdb.Close() })
+
+	queue, err := NewRedisStreamQueue(context.Background(), rdb, RedisStreamConfig{
+		Stream: "worker:test",
+		Group:  "g1",
+		Block:  50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("new queue: %v", err)
+	}
+
+	pool := newTestPool(&Config{Name: "test-redis", Workers: 1}, queue)
+	done := make(chan string, 1)
+	pool.RegisterFunc("echo", func(ctx context.Context, task *Task) error {
+		var s string
+		if err := task.Decode(&s); err != nil {
+			return err
+		}
+		done <- s
+		return nil
+	})
+	pool.Start()
+
+	task, _ := NewTask("echo", "hello")
+	if err := pool.Submit(context.Background(), task); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	select {
+	case got := <-done:
+		if got != "hello" {
+			t.Fatalf("unexpected payload: %s", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("task not consumed")
+	}
+
+	if err := pool.Stop(context.Background()); err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if n, _ := queue.Len(context.Background()); n != 0 {
+		t.Fatalf("expected acked task removed, len: %d", n)
+	}
+
+	if err := pool.SubmitFunc(context.Background(), func(ctx context.Context) error { return nil }); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got: %v", err)
+	}
+}
@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"sync"
+)
+
+/* ========================================================================
+ * Worker Queue - 任务队列抽象
+ * ========================================================================
+ * 职责: 定义任务队列接口，并提供默认的内存有界队列
+ * 实现:
+ *   - MemoryQueue: 进程内有界队列（默认）
+ *   - RedisStreamQueue: 基于 Redis Stream 的持久化队列
+ *   - MQQueue: 基于 mq.Producer / mq.Consumer 的持久化队列
+ * ======================================================================== */
+
+// Queue 任务队列
+type Queue interface {
+	// Push 入队，队列满时阻塞直到 ctx 结束
+	Push(ctx context.Context, task *Task) error
+	// TryPush 非阻塞入队，队列满时返回 ErrQueueFull
+	TryPush(task *Task) error
+	// Pop 出队，阻塞直到有任务、ctx 结束或队列关闭
+	// 队列关闭且本地无剩余任务时返回 ErrQueueClosed
+	Pop(ctx context.Context) (*Task, error)
+	// Ack 确认任务处理完成（成功或最终失败），确认后持久化队列不再重投
+	Ack(ctx context.Context, task *Task) error
+	// Len 当前待处理任务数量
+	Len(ctx context.Context) (int64, error)
+	// Close 停止接收新任务
+	Close() error
+}
+
+// Nacker 可选接口，放弃未完成的任务使其重新投递
+// 排空超时后被取消的任务调用 Nack 而非 Ack；未实现时 Pool 不确认该任务，
+// 持久化队列会在重启后重投（Redis Stream 保留在待确认列表中）
+type Nacker interface {
+	Nack(ctx context.Context, task *Task) error
+}
+
+// MemoryQueue 进程内有界队列
+type MemoryQueue struct {
+	ch     chan *Task
+	closed chan struct{}
+	once   sync.Once
+	mu     sync.RWMutex
+}
+
+// NewMemoryQueue 创建内存队列
+func NewMemoryQueue(size int) *MemoryQueue {
+	if size <= 0 {
+		size = DefaultConfig().QueueSize
+	}
+	return &MemoryQueue{
+		ch:     make(chan *Task, size),
+		closed: make(chan struct{}),
+	}
+}
+
+// Push 入队
+func (q *MemoryQueue) Push(ctx context.Context, task *Task) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.isClosed() {
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.ch <- task:
+		return nil
+	case <-q.closed:
+		return ErrQueueClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryPush 非阻塞入队
+func (q *MemoryQueue) TryPush(task *Task) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.isClosed() {
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.ch <- task:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Pop 出队，关闭后仍会返回已入队的剩余任务
+func (q *MemoryQueue) Pop(ctx context.Context) (*Task, error) {
+	select {
+	case task, ok := <-q.ch:
+		if !ok {
+			return nil, ErrQueueClosed
+		}
+		return task, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Ack 内存队列无需确认
+func (q *MemoryQueue) Ack(context.Context, *Task) error {
+	return nil
+}
+
+// Len 当前队列长度
+func (q *MemoryQueue) Len(context.Context) (int64, error) {
+	return int64(len(q.ch)), nil
+}
+
+// Close 关闭队列，剩余任务仍可被 Pop 取出
+func (q *MemoryQueue) Close() error {
+	q.once.Do(func() {
+		close(q.closed)
+		// 等待进行中的 Push 退出后再关闭通道
+		q.mu.Lock()
+		close(q.ch)
+		q.mu.Unlock()
+	})
+	return nil
+}
+
+func (q *MemoryQueue) isClosed() bool {
+	select {
+	case <-q.closed:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,181 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/aisgo/ais-go-pkg/ctxutil"
+	"github.com/aisgo/ais-go-pkg/mq"
+)
+
+/* ========================================================================
+ * MQ Queue - 基于消息队列的持久化队列
+ * ========================================================================
+ * 职责: 使用 mq.Producer / mq.Consumer 持久化任务
+ * 语义:
+ *   - Push: 同步发送到指定 Topic
+ *   - Pop: 消费回调将任务交给 worker，并阻塞至 Ack 后再确认消费
+ *   - 关闭后尚未交付的消息返回 ConsumeRetryLater，由 MQ 重投
+ *   - Nack（排空超时未完成的任务）同样返回 ConsumeRetryLater；批量消费时整批重投，
+ *     批内已完成的任务可能重复执行
+ * 说明: Producer / Consumer 的生命周期由调用方（mq.Module）管理，
+ *       须在 Consumer.Start 之前创建本队列以完成订阅
+ * ======================================================================== */
+
+// mqDelivery 一次消费投递
+type mqDelivery struct {
+	task  *Task
+	done  chan struct{}
+	retry bool // Nack 时置位，消费回调返回 ConsumeRetryLater
+}
+
+var _ Nacker = (*MQQueue)(nil)
+
+// MQQueue 基于 MQ 的任务队列
+type MQQueue struct {
+	producer mq.Producer
+	topic    string
+
+	deliveries chan *mqDelivery
+	closed     chan struct{}
+	closeOnce  sync.Once
+	pending    atomic.Int64
+}
+
+// NewMQQueue 创建 MQ 队列并订阅 Topic
+// consumer 可为 nil（仅生产场景）
+func NewMQQueue(producer mq.Producer, consumer mq.Consumer, topic string) (*MQQueue, error) {
+	if producer == nil {
+		return nil, fmt.Errorf("mq producer is required")
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("mq topic is required")
+	}
+
+	q := &MQQueue{
+		producer:   producer,
+		topic:      topic,
+		deliveries: make(chan *mqDelivery),
+		closed:     make(chan struct{}),
+	}
+
+	if consumer != nil {
+		if err := consumer.Subscribe(topic, q.handle); err != nil {
+			return nil, fmt.Errorf("failed to subscribe worker topic: %w", err)
+		}
+	}
+
+	return q, nil
+}
+
+// handle MQ 消费回调
+func (q *MQQueue) handle(ctx context.Context, msgs []*mq.ConsumedMessage) (mq.ConsumeResult, error) {
+	for _, msg := range msgs {
+		task, err := decodeTask(msg.Body)
+		if err != nil {
+			// 无法解析的消息不重投
+			continue
+		}
+
+		d := &mqDelivery{task: task, done: make(chan struct{})}
+		task.ackRef = d
+
+		q.pending.Add(1)
+		select {
+		case q.deliveries <- d:
+		case <-q.closed:
+			q.pending.Add(-1)
+			return mq.ConsumeRetryLater, ErrQueueClosed
+		case <-ctx.Done():
+			q.pending.Add(-1)
+			return mq.ConsumeRetryLater, ctx.Err()
+		}
+
+		select {
+		case <-d.done:
+			if d.retry {
+				return mq.ConsumeRetryLater, nil
+			}
+		case <-ctx.Done():
+			return mq.ConsumeRetryLater, ctx.Err()
+		}
+	}
+	return mq.ConsumeSuccess, nil
+}
+
+// Push 入队
+func (q *MQQueue) Push(ctx context.Context, task *Task) error {
+	if q.isClosed() {
+		return ErrQueueClosed
+	}
+
+	data, err := encodeTask(task)
+	if err != nil {
+		return err
+	}
+
+	msg := mq.NewMessage(q.topic, data).WithKey(task.ID).WithTag(task.Type)
+	if _, err := q.producer.SendSync(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send task: %w", err)
+	}
+	return nil
+}
+
+// TryPush 非阻塞入队（MQ 写入不受本地容量限制）
+func (q *MQQueue) TryPush(task *Task) error {
+	return q.Push(ctxutil.WithoutDeadlineCheck(context.Background()), task)
+}
+
+// Pop 出队
+func (q *MQQueue) Pop(ctx context.Context) (*Task, error) {
+	select {
+	case d := <-q.deliveries:
+		q.pending.Add(-1)
+		return d.task, nil
+	case <-q.closed:
+		return nil, ErrQueueClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Ack 确认任务，释放对应的消费回调
+func (q *MQQueue) Ack(_ context.Context, task *Task) error {
+	if d, ok := task.ackRef.(*mqDelivery); ok {
+		close(d.done)
+	}
+	return nil
+}
+
+// Nack 放弃任务，释放消费回调并由 MQ 重投
+func (q *MQQueue) Nack(_ context.Context, task *Task) error {
+	if d, ok := task.ackRef.(*mqDelivery); ok {
+		d.retry = true
+		close(d.done)
+	}
+	return nil
+}
+
+// Len 等待交付给 worker 的任务数量
+func (q *MQQueue) Len(context.Context) (int64, error) {
+	return q.pending.Load(), nil
+}
+
+// Close 停止交付任务
+func (q *MQQueue) Close() error {
+	q.closeOnce.Do(func() {
+		close(q.closed)
+	})
+	return nil
+}
+
+func (q *MQQueue) isClosed() bool {
+	select {
+	case <-q.closed:
+		return true
+	default:
+		return false
+	}
+}
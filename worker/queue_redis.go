@@ -0,0 +1,193 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/aisgo/ais-go-pkg/ctxutil"
+
+	"github.com/redis/go-redis/v9"
+)
+
+/* ========================================================================
+ * Redis Stream Queue - 基于 Redis Stream 的持久化队列
+ * ========================================================================
+ * 职责: 使用 Redis Stream + Consumer Group 持久化任务
+ * 语义:
+ *   - Push: XADD（可选 MAXLEN 近似裁剪）
+ *   - Pop: XREADGROUP，启动时优先重投本消费者未确认的任务
+ *   - Ack: XACK + XDEL；未确认的任务保留在待确认列表中，重启后由本消费者重投
+ * ======================================================================== */
+
+const redisTaskField = "task"
+
+// RedisStreamConfig Redis Stream 队列配置
+type RedisStreamConfig struct {
+	// Stream Stream 键名
+	Stream string `yaml:"stream"`
+	// Group 消费者组名称
+	Group string `yaml:"group"`
+	// Consumer 消费者名称，默认使用主机名
+	Consumer string `yaml:"consumer"`
+	// MaxLen Stream 最大长度（近似裁剪），0 表示不限制
+	MaxLen int64 `yaml:"max_len"`
+	// Block 单次 XREADGROUP 阻塞时间
+	Block time.Duration `yaml:"block"`
+}
+
+// RedisStreamQueue 基于 Redis Stream 的任务队列
+type RedisStreamQueue struct {
+	rdb     redis.UniversalClient
+	cfg     RedisStreamConfig
+	closed  atomic.Bool
+	backlog atomic.Bool
+}
+
+// NewRedisStreamQueue 创建 Redis Stream 队列，并确保消费者组存在
+func NewRedisStreamQueue(ctx context.Context, rdb redis.UniversalClient, cfg RedisStreamConfig) (*RedisStreamQueue, error) {
+	if rdb == nil {
+		return nil, fmt.Errorf("redis client is required")
+	}
+	if cfg.Stream == "" {
+		return nil, fmt.Errorf("redis stream name is required")
+	}
+	if cfg.Group == "" {
+		cfg.Group = "worker"
+	}
+	if cfg.Consumer == "" {
+		host, _ := os.Hostname()
+		cfg.Consumer = fmt.Sprintf("%s-%d", host, os.Getpid())
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = time.Second
+	}
+
+	err := rdb.XGroupCreateMkStream(ctx, cfg.Stream, cfg.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	q := &RedisStreamQueue{rdb: rdb, cfg: cfg}
+	q.backlog.Store(true)
+	return q, nil
+}
+
+// Push 入队
+func (q *RedisStreamQueue) Push(ctx context.Context, task *Task) error {
+	if q.closed.Load() {
+		return ErrQueueClosed
+	}
+
+	data, err := encodeTask(task)
+	if err != nil {
+		return err
+	}
+
+	args := &redis.XAddArgs{
+		Stream: q.cfg.Stream,
+		Values: map[string]any{redisTaskField: data},
+	}
+	if q.cfg.MaxLen > 0 {
+		args.MaxLen = q.cfg.MaxLen
+		args.Approx = true
+	}
+
+	if err := q.rdb.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("failed to add task to stream: %w", err)
+	}
+	return nil
+}
+
+// TryPush 非阻塞入队（Redis 写入本身不阻塞于容量）
+func (q *RedisStreamQueue) TryPush(task *Task) error {
+	return q.Push(ctxutil.WithoutDeadlineCheck(context.Background()), task)
+}
+
+// Pop 出队
+func (q *RedisStreamQueue) Pop(ctx context.Context) (*Task, error) {
+	for {
+		if q.closed.Load() {
+			return nil, ErrQueueClosed
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// 先处理本消费者已投递但未确认的任务，再读取新任务
+		id := ">"
+		block := q.cfg.Block
+		if q.backlog.Load() {
+			id = "0"
+			block = -1
+		}
+
+		streams, err := q.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    q.cfg.Group,
+			Consumer: q.cfg.Consumer,
+			Streams:  []string{q.cfg.Stream, id},
+			Count:    1,
+			Block:    block,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				q.backlog.Store(false)
+				continue
+			}
+			return nil, fmt.Errorf("failed to read from stream: %w", err)
+		}
+
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				return q.decodeMessage(ctx, msg)
+			}
+		}
+		q.backlog.Store(false)
+	}
+}
+
+// decodeMessage 解析 Stream 消息，无法解析的消息直接确认丢弃
+func (q *RedisStreamQueue) decodeMessage(ctx context.Context, msg redis.XMessage) (*Task, error) {
+	raw, _ := msg.Values[redisTaskField].(string)
+	task, err := decodeTask([]byte(raw))
+	if err != nil {
+		_ = q.ack(ctx, msg.ID)
+		return nil, err
+	}
+	task.ackRef = msg.ID
+	return task, nil
+}
+
+// Ack 确认任务
+func (q *RedisStreamQueue) Ack(ctx context.Context, task *Task) error {
+	id, ok := task.ackRef.(string)
+	if !ok || id == "" {
+		return nil
+	}
+	return q.ack(ctx, id)
+}
+
+func (q *RedisStreamQueue) ack(ctx context.Context, id string) error {
+	pipe := q.rdb.TxPipeline()
+	pipe.XAck(ctx, q.cfg.Stream, q.cfg.Group, id)
+	pipe.XDel(ctx, q.cfg.Stream, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to ack task: %w", err)
+	}
+	return nil
+}
+
+// Len 当前 Stream 中未确认的任务数量
+func (q *RedisStreamQueue) Len(ctx context.Context) (int64, error) {
+	return q.rdb.XLen(ctx, q.cfg.Stream).Result()
+}
+
+// Close 停止读取，未处理任务保留在 Stream 中
+func (q *RedisStreamQueue) Close() error {
+	q.closed.Store(true)
+	return nil
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	ulidv2 "github.com/oklog/ulid/v2"
+)
+
+/* ========================================================================
+ * Worker Task - 任务定义
+ * ========================================================================
+ * 职责: 定义可序列化的任务结构与处理器
+ * 说明:
+ *   - Type + Payload 形式的任务可持久化到 MQ / Redis Stream
+ *   - SubmitFunc 提交的闭包任务仅支持内存队列
+ * ======================================================================== */
+
+var (
+	// ErrPoolClosed 任务池已关闭
+	ErrPoolClosed = errors.New("worker pool is closed")
+	// ErrQueueClosed 队列已关闭
+	ErrQueueClosed = errors.New("worker queue is closed")
+	// ErrQueueFull 队列已满
+	ErrQueueFull = errors.New("worker queue is full")
+	// ErrNoHandler 任务类型未注册处理器
+	ErrNoHandler = errors.New("worker handler not registered")
+	// ErrNotSerializable 闭包任务无法持久化
+	ErrNotSerializable = errors.New("function task cannot be persisted")
+)
+
+// Task 任务
+type Task struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	MaxRetries int             `json:"max_retries"`
+	Attempts   int             `json:"attempts"`
+	CreatedAt  time.Time       `json:"created_at"`
+
+	// fn 闭包任务（不可序列化）
+	fn HandlerFunc
+	// ackRef 队列内部确认引用（如 Redis Stream 消息 ID）
+	ackRef any
+}
+
+// NewTask 创建任务，payload 会被 JSON 序列化
+func NewTask(taskType string, payload any) (*Task, error) {
+	var raw json.RawMessage
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal task payload: %w", err)
+		}
+		raw = data
+	}
+
+	return &Task{
+		ID:         ulidv2.Make().String(),
+		Type:       taskType,
+		Payload:    raw,
+		MaxRetries: -1,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// WithMaxRetries 设置任务最大重试次数（覆盖任务池默认值）
+func (t *Task) WithMaxRetries(n int) *Task {
+	t.MaxRetries = n
+	return t
+}
+
+// Decode 将 Payload 反序列化到 v
+func (t *Task) Decode(v any) error {
+	if len(t.Payload) == 0 {
+		return nil
+	}
+	return json.Unmarshal(t.Payload, v)
+}
+
+// Persistable 任务是否可持久化
+func (t *Task) Persistable() bool {
+	return t.fn == nil
+}
+
+// Handler 任务处理器
+type Handler interface {
+	Handle(ctx context.Context, task *Task) error
+}
+
+// HandlerFunc 函数式任务处理器
+type HandlerFunc func(ctx context.Context, task *Task) error
+
+// Handle 实现 Handler 接口
+func (f HandlerFunc) Handle(ctx context.Context, task *Task) error {
+	return f(ctx, task)
+}
+
+// encodeTask 序列化任务
+func encodeTask(task *Task) ([]byte, error) {
+	if !task.Persistable() {
+		return nil, ErrNotSerializable
+	}
+	return json.Marshal(task)
+}
+
+// decodeTask 反序列化任务
+func decodeTask(data []byte) (*Task, error) {
+	var task Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal task: %w", err)
+	}
+	return &task, nil
+}